@@ -0,0 +1,217 @@
+// Command loadtest drives login and order traffic against a running API at a
+// constant rate and checks the latencies against budgets, exiting non-zero
+// when one is exceeded.
+//
+//	go run ./cmd/loadtest -url http://localhost:8080 -rate 50 -duration 30s
+//
+// It registers its own users, so run it against a local or staging
+// environment with sandbox payment and notification providers. The server's
+// rate limits (RATE_LIMIT_RPS for logins, RATE_LIMIT_AUTHENTICATED_RPS for
+// orders) must be above -rate, or most requests are throttled.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/client"
+)
+
+const password = "loadtest-password"
+
+// options are the command line flags
+type options struct {
+	url         string
+	rate        int
+	duration    time.Duration
+	users       int
+	orderShare  float64
+	concurrency int
+	loginP95    time.Duration
+	orderP95    time.Duration
+	maxErrors   float64
+}
+
+// user is a registered load test user with a logged-in client
+type user struct {
+	username string
+	email    string
+	id       int
+	client   *client.Client
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.url, "url", "http://localhost:8080", "base URL of the API")
+	flag.IntVar(&opts.rate, "rate", 20, "requests per second")
+	flag.DurationVar(&opts.duration, "duration", 30*time.Second, "how long to send requests")
+	flag.IntVar(&opts.users, "users", 10, "users to register and spread requests over")
+	flag.Float64Var(&opts.orderShare, "orders", 0.5, "share of requests creating orders; the others log in")
+	flag.IntVar(&opts.concurrency, "concurrency", 100, "requests in flight at most; beyond it requests are skipped")
+	flag.DurationVar(&opts.loginP95, "login-p95", 300*time.Millisecond, "p95 latency budget of login; 0 disables")
+	flag.DurationVar(&opts.orderP95, "order-p95", 500*time.Millisecond, "p95 latency budget of order creation; 0 disables")
+	flag.Float64Var(&opts.maxErrors, "max-errors", 0.01, "share of failed requests allowed per endpoint")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, opts); err != nil {
+		fmt.Fprintln(os.Stderr, "loadtest:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, opts options) error {
+	if opts.rate <= 0 || opts.users <= 0 || opts.concurrency <= 0 {
+		return fmt.Errorf("-rate, -users and -concurrency must be positive")
+	}
+
+	users, err := setUp(ctx, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("registered %d users, sending %d requests/s for %s\n", len(users), opts.rate, opts.duration)
+
+	rec := newRecorder()
+	elapsed, skipped := generate(ctx, opts, users, rec)
+
+	summaries := rec.summaries()
+	report(os.Stdout, summaries, elapsed)
+	if skipped > 0 {
+		fmt.Printf("%d requests skipped with %d in flight; the server is not keeping up\n", skipped, opts.concurrency)
+	}
+
+	budgets := map[string]budget{
+		"login": {P95: opts.loginP95, MaxErrorRate: opts.maxErrors},
+		"order": {P95: opts.orderP95, MaxErrorRate: opts.maxErrors},
+	}
+	var violations []string
+	for _, s := range summaries {
+		violations = append(violations, s.violations(budgets[s.Endpoint])...)
+	}
+	for _, v := range violations {
+		fmt.Println("FAIL", v)
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d budgets exceeded", len(violations))
+	}
+	fmt.Println("PASS all budgets met")
+	return nil
+}
+
+// setUp registers and logs in the users
+func setUp(ctx context.Context, opts options) ([]*user, error) {
+	runID := time.Now().UnixNano()
+	users := make([]*user, opts.users)
+	for i := range users {
+		c, err := client.New(client.Config{BaseURL: opts.url, UserAgent: "loadtest"})
+		if err != nil {
+			return nil, err
+		}
+		u := &user{
+			username: fmt.Sprintf("loadtest-%d-%d", runID, i),
+			client:   c,
+		}
+		u.email = u.username + "@example.com"
+
+		if _, err := c.Register(ctx, entity.RegisterRequest{Username: u.username, Email: u.email, Password: password}); err != nil {
+			return nil, fmt.Errorf("failed to register %s: %w", u.username, err)
+		}
+		login, err := c.Login(ctx, entity.LoginRequest{Username: u.username, Password: password})
+		if err != nil {
+			return nil, fmt.Errorf("failed to log in %s: %w", u.username, err)
+		}
+		u.id = login.User.ID
+		users[i] = u
+	}
+	return users, nil
+}
+
+// generate sends requests at the rate until the duration is over or ctx is
+// done, and returns how long it ran and how many requests it skipped because
+// too many were in flight. Requests are sent on schedule whether or not
+// earlier ones completed, so a slow server shows in the latencies rather
+// than lowering the rate.
+func generate(ctx context.Context, opts options, users []*user, rec *recorder) (time.Duration, int) {
+	ctx, cancel := context.WithTimeout(ctx, opts.duration)
+	defer cancel()
+
+	ticker := time.NewTicker(time.Second / time.Duration(opts.rate))
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	inFlight := make(chan struct{}, opts.concurrency)
+	start := time.Now()
+	skipped, seq := 0, 0
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			skipped++
+			continue
+		}
+
+		seq++
+		u := users[seq%len(users)]
+		createOrder := rand.Float64() < opts.orderShare
+		orderID := fmt.Sprintf("%s-%d", u.username, seq)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			// Requests in flight when the duration ends complete normally
+			reqCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+			defer cancel()
+			start := time.Now()
+			if createOrder {
+				err := placeOrder(reqCtx, u, orderID)
+				rec.record("order", time.Since(start), err)
+			} else {
+				err := login(reqCtx, opts.url, u)
+				rec.record("login", time.Since(start), err)
+			}
+		}()
+	}
+
+	wg.Wait()
+	return time.Since(start), skipped
+}
+
+// login logs the user in with a client of its own, leaving the user's token
+// in place for concurrent order requests
+func login(ctx context.Context, url string, u *user) error {
+	c, err := client.New(client.Config{BaseURL: url, UserAgent: "loadtest"})
+	if err != nil {
+		return err
+	}
+	_, err = c.Login(ctx, entity.LoginRequest{Username: u.username, Password: password})
+	return err
+}
+
+func placeOrder(ctx context.Context, u *user, orderID string) error {
+	_, err := u.client.CreateOrder(ctx, entity.CreateOrderRequest{
+		OrderID:   orderID,
+		UserID:    u.id,
+		Items:     []entity.OrderItem{{ProductID: "loadtest-sku", Quantity: 1, UnitPrice: 10}},
+		Amount:    10,
+		Currency:  "USD",
+		UserEmail: u.email,
+	})
+	return err
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"boilerplate-go/pkg/client"
+)
+
+// recorder collects the outcome of every request, per endpoint
+type recorder struct {
+	mu        sync.Mutex
+	endpoints map[string]*endpointStats
+}
+
+type endpointStats struct {
+	latencies []time.Duration
+	statuses  map[int]int
+	errors    int
+}
+
+func newRecorder() *recorder {
+	return &recorder{endpoints: make(map[string]*endpointStats)}
+}
+
+// record adds a request that took latency and ended with err. Requests
+// rejected by the rate limiter count as throttled rather than failed.
+func (r *recorder) record(endpoint string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats, ok := r.endpoints[endpoint]
+	if !ok {
+		stats = &endpointStats{statuses: make(map[int]int)}
+		r.endpoints[endpoint] = stats
+	}
+
+	status := http.StatusOK
+	var apiErr *client.APIError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.StatusCode
+	case err != nil:
+		status = 0
+	}
+	stats.statuses[status]++
+	if err != nil && status != http.StatusTooManyRequests {
+		stats.errors++
+	}
+	if err == nil {
+		stats.latencies = append(stats.latencies, latency)
+	}
+}
+
+// summary is the result of an endpoint. Latencies are of successful requests.
+type summary struct {
+	Endpoint  string
+	Requests  int
+	Errors    int
+	Throttled int
+	P50       time.Duration
+	P95       time.Duration
+	P99       time.Duration
+	Max       time.Duration
+}
+
+// ErrorRate is the share of requests that failed, other than throttled ones
+func (s summary) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Requests)
+}
+
+func (r *recorder) summaries() []summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	summaries := make([]summary, 0, len(r.endpoints))
+	for endpoint, stats := range r.endpoints {
+		latencies := append([]time.Duration(nil), stats.latencies...)
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+		s := summary{
+			Endpoint:  endpoint,
+			Errors:    stats.errors,
+			Throttled: stats.statuses[http.StatusTooManyRequests],
+			P50:       percentile(latencies, 50),
+			P95:       percentile(latencies, 95),
+			P99:       percentile(latencies, 99),
+		}
+		for _, n := range stats.statuses {
+			s.Requests += n
+		}
+		if len(latencies) > 0 {
+			s.Max = latencies[len(latencies)-1]
+		}
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Endpoint < summaries[j].Endpoint })
+	return summaries
+}
+
+// percentile returns the p-th percentile of sorted latencies, by the
+// nearest-rank method
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// budget is the p95 latency and error rate an endpoint may not exceed
+type budget struct {
+	P95          time.Duration
+	MaxErrorRate float64
+}
+
+// violations returns the budgets s exceeds
+func (s summary) violations(b budget) []string {
+	var violations []string
+	if b.P95 > 0 && s.P95 > b.P95 {
+		violations = append(violations, fmt.Sprintf("%s: p95 %s over the budget of %s", s.Endpoint, s.P95, b.P95))
+	}
+	if s.ErrorRate() > b.MaxErrorRate {
+		violations = append(violations, fmt.Sprintf("%s: error rate %.2f%% over the budget of %.2f%%", s.Endpoint, 100*s.ErrorRate(), 100*b.MaxErrorRate))
+	}
+	return violations
+}
+
+// report writes a table of the summaries, throughput over elapsed
+func report(w io.Writer, summaries []summary, elapsed time.Duration) {
+	fmt.Fprintf(w, "%-8s %9s %8s %9s %10s %10s %10s %10s %8s\n", "endpoint", "requests", "errors", "throttled", "p50", "p95", "p99", "max", "rps")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%-8s %9d %8d %9d %10s %10s %10s %10s %8.1f\n",
+			s.Endpoint, s.Requests, s.Errors, s.Throttled,
+			s.P50.Round(time.Microsecond), s.P95.Round(time.Microsecond), s.P99.Round(time.Microsecond), s.Max.Round(time.Microsecond),
+			float64(s.Requests)/elapsed.Seconds())
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"boilerplate-go/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	assert.Equal(t, 50*time.Millisecond, percentile(latencies, 50))
+	assert.Equal(t, 95*time.Millisecond, percentile(latencies, 95))
+	assert.Equal(t, 100*time.Millisecond, percentile(latencies, 100))
+	assert.Equal(t, time.Millisecond, percentile(latencies[:1], 99))
+	assert.Zero(t, percentile(nil, 95))
+}
+
+func TestRecorder_Budgets(t *testing.T) {
+	rec := newRecorder()
+	for i := 1; i <= 18; i++ {
+		rec.record("order", time.Duration(i)*10*time.Millisecond, nil)
+	}
+	rec.record("order", time.Second, &client.APIError{StatusCode: http.StatusTooManyRequests})
+	rec.record("order", time.Second, errors.New("connection refused"))
+
+	summaries := rec.summaries()
+	require.Len(t, summaries, 1)
+	s := summaries[0]
+	assert.Equal(t, 20, s.Requests)
+	assert.Equal(t, 1, s.Errors, "throttled requests are not errors")
+	assert.Equal(t, 1, s.Throttled)
+	assert.Equal(t, 180*time.Millisecond, s.P95, "latencies are of successful requests")
+	assert.Equal(t, 180*time.Millisecond, s.Max)
+
+	assert.Empty(t, s.violations(budget{P95: 200 * time.Millisecond, MaxErrorRate: 0.1}))
+	assert.Len(t, s.violations(budget{P95: 100 * time.Millisecond, MaxErrorRate: 0.01}), 2)
+}
@@ -2,45 +2,592 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
+
+	"boilerplate-go/pkg/username"
+)
+
+// Deployment environments
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Payment provider environments
+const (
+	PaymentSandbox = "sandbox"
+	PaymentLive    = "live"
 )
 
+// paypalBaseURLs are the PayPal API endpoints of each environment
+var paypalBaseURLs = map[string]string{
+	PaymentSandbox: "https://api.sandbox.paypal.com",
+	PaymentLive:    "https://api.paypal.com",
+}
+
 // Config holds all configuration for our application.
+// Env is the deployment environment: development, staging or production.
 type Config struct {
+	Env       string
 	Server    ServerConfig
+	Startup   StartupConfig
+	Probes    ProbeConfig
 	Database  DatabaseConfig
 	JWT       JWTConfig
+	Signing   SigningConfig
+	Jobs      JobConfig
+	Sagas     SagaConfig
+	Locks     LockConfig
+	OTP       OTPConfig
+	Username  UsernameConfig
+	Password  PasswordConfig
+	Login     LoginAlertConfig
+	Avatar    AvatarConfig
+	Upload    UploadConfig
+	Email     EmailAddressConfig
+	Orgs      OrganizationConfig
+	SCIM      SCIMConfig
+	SSO       SSOConfig
+	Legal     LegalConfig
+	Cookies   AuthCookieConfig
+	Static    StaticConfig
+	I18n      I18nConfig
+	Logging   LoggingConfig
+	Metrics   MetricsConfig
+	Stats     StatsConfig
+	Reports   ReportsConfig
+	Exports   ExportConfig
+	Features  FeaturesConfig
+	Timeouts  TimeoutConfig
+	Budgets   BudgetConfig
 	Providers ProvidersConfig
 }
 
 // ServerConfig holds server configuration.
+// AccessLog is "" (disabled), "stdout", "stderr" or a file path receiving an
+// access log in AccessLogFormat, the "common" or "combined" Apache format.
+// TrustedPlatform names the platform in front of the server whose header
+// holds the client IP: "cloudflare", "appengine", "flyio" or the header
+// itself; empty trusts none.
 type ServerConfig struct {
-	Port           string
-	Host           string
-	ReadTimeout    time.Duration
-	WriteTimeout   time.Duration
-	MaxHeaderBytes int
+	Port            string
+	Host            string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	MaxHeaderBytes  int
+	SwaggerEnabled  bool
+	ProblemDetails  bool
+	DedupWindow     time.Duration
+	TrustedProxies  []string
+	TrustedPlatform string
+	RateLimit       RateLimitConfig
+	LoadShed        LoadShedConfig
+	Pipeline        PipelineConfig
+	AccessLog       string
+	AccessLogFormat string
+	// DrainPeriod is how long a shutting down instance reports not ready
+	// before it stops, for load balancers to stop sending it requests
+	DrainPeriod time.Duration
+	// ResponseCacheSize bounds the memory of the responses kept by the
+	// response cache in bytes; zero disables it
+	ResponseCacheSize int
+
+	// Network is "tcp" (Host and Port), "unix" (SocketPath, created with
+	// SocketMode) or "systemd" (the socket passed by systemd socket activation)
+	Network    string
+	SocketPath string
+	SocketMode os.FileMode
+	// HTTP2 serves HTTP/2 to TLS clients that negotiate it
+	HTTP2 bool
+	// H2C serves cleartext HTTP/2 with prior knowledge, for load balancers
+	// that speak HTTP/2 to a backend without TLS
+	H2C bool
+	TLS TLSConfig
+}
+
+// TLSConfig holds HTTPS configuration. The server uses the certificate in
+// CertFile and KeyFile, or obtains certificates for AutocertDomains from
+// Let's Encrypt and caches them in AutocertCacheDir. With RedirectAddr set, a
+// second listener redirects HTTP requests to HTTPS and answers ACME HTTP-01
+// challenges.
+type TLSConfig struct {
+	CertFile         string
+	KeyFile          string
+	AutocertDomains  []string
+	AutocertEmail    string
+	AutocertCacheDir string
+	RedirectAddr     string
+}
+
+// platformHeaders are the headers holding the client IP set by the platforms
+// known by name
+var platformHeaders = map[string]string{
+	"cloudflare": "CF-Connecting-IP",
+	"appengine":  "X-Appengine-Remote-Addr",
+	"flyio":      "Fly-Client-IP",
+}
+
+// headerName matches the name of an HTTP header
+var headerName = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// PlatformHeader returns the header holding the client IP set by the trusted
+// platform, or "" when none is trusted
+func (c ServerConfig) PlatformHeader() string {
+	if header, ok := platformHeaders[strings.ToLower(c.TrustedPlatform)]; ok {
+		return header
+	}
+	return c.TrustedPlatform
+}
+
+// Enabled reports whether the server serves HTTPS
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || len(c.AutocertDomains) > 0
+}
+
+// StartupConfig holds how long startup waits for dependencies. Checks are
+// retried with exponential backoff from InitialBackoff up to MaxBackoff, each
+// bounded by CheckTimeout. Startup fails when the database is still down after
+// MaxWait; optional dependencies such as the malware scanner are retried in
+// the background instead.
+type StartupConfig struct {
+	MaxWait        time.Duration
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	CheckTimeout   time.Duration
+}
+
+// ProbeConfig holds the health probes of external providers. Every Interval
+// the providers enabled are probed with a lightweight call bounded by
+// Timeout: Payment reads the Stripe balance or obtains a PayPal token, Email
+// asks the email service for its status. A zero interval disables probes.
+type ProbeConfig struct {
+	Interval time.Duration
+	Timeout  time.Duration
+	Payment  bool
+	Email    bool
+}
+
+// RateLimitConfig holds the limits on anonymous requests and on requests
+// carrying a valid token, each across all clients, and how long a user's
+// request in flight holds its slot on routes limiting them. The buckets and
+// slots are kept in Redis at RedisURL, under keys starting with RedisPrefix,
+// so replicas share them; without it each instance keeps its own.
+type RateLimitConfig struct {
+	Anonymous     RateLimitBucket
+	Authenticated RateLimitBucket
+	InFlightTTL   time.Duration
+	RedisURL      string
+	RedisTimeout  time.Duration
+	RedisPrefix   string
+}
+
+// RateLimitBucket holds the requests per second accepted before responding
+// 429, allowing bursts of Burst requests, and the soft limit above which
+// requests are warned about and logged. A zero limit is not applied.
+type RateLimitBucket struct {
+	RequestsPerSecond     float64
+	Burst                 int
+	SoftRequestsPerSecond float64
+}
+
+// LoadShedConfig holds the thresholds above which low-priority requests are
+// rejected with 503. A zero threshold is not checked.
+type LoadShedConfig struct {
+	MaxInFlight   int
+	MaxLatencyP99 time.Duration
+	RetryAfter    time.Duration
+}
+
+// PipelineConfig arranges the global middleware by name: Order runs the
+// named middleware first, Disabled ones are left out and Groups switch
+// middleware on ("+name") or off ("-name") under a path prefix.
+type PipelineConfig struct {
+	Order    []string
+	Disabled []string
+	Groups   map[string][]string
+}
+
+// LoggingConfig holds log shipping configuration.
+// Sink is "" (stdout only), "loki" or "elasticsearch"; logs are also shipped
+// to the server at SinkURL in batches of BatchSize, or every FlushInterval.
+// Up to BufferSize entries wait for shipping; beyond that entries are dropped
+// rather than slowing down requests. Loki streams carry Labels, Elasticsearch
+// documents go to Index. APIKey takes precedence over Username and Password.
+type LoggingConfig struct {
+	Sink          string
+	SinkURL       string
+	Index         string
+	Labels        map[string]string
+	Username      string
+	Password      string
+	APIKey        string
+	BatchSize     int
+	FlushInterval time.Duration
+	BufferSize    int
+	Timeout       time.Duration
+}
+
+// MetricsConfig bounds the label cardinality of HTTP request metrics.
+// StatusClasses records statuses as 2xx, 4xx and so on. Paths, when set,
+// lists the route templates recorded by name; other routes become "other".
+// UserBuckets adds a user_bucket label spreading authenticated users over that
+// many buckets. Exemplars attaches the correlation ID to request durations.
+type MetricsConfig struct {
+	StatusClasses bool
+	Paths         []string
+	UserBuckets   int
+	Exemplars     bool
+	SLO           SLOConfig
+}
+
+// SLOConfig defines the service level objectives tracked over all HTTP
+// requests: AvailabilityTarget is the share of requests not failing with a
+// 5xx status, LatencyTarget the share served within LatencyThreshold.
+type SLOConfig struct {
+	Enabled            bool
+	AvailabilityTarget float64
+	LatencyTarget      float64
+	LatencyThreshold   time.Duration
 }
 
 // DatabaseConfig holds database configuration.
+// QueryTimeout bounds each query the repositories run. StatementTimeout is the
+// Postgres statement_timeout of the connections, which ends statements on the
+// server even when the client has gone. Zero disables either.
+// Every PoolInterval the connection pool is sampled, warning when requests
+// waited PoolMaxWait on average for a connection; with PoolAutoscale the
+// pool then grows, up to PoolMaxOpen connections. A zero interval disables
+// sampling.
 type DatabaseConfig struct {
-	Host            string
-	Port            string
-	User            string
-	Password        string
-	DBName          string
-	SSLMode         string
-	MaxOpenConns    int
-	MaxIdleConns    int
-	ConnMaxLifetime time.Duration
+	Host             string
+	Port             string
+	User             string
+	Password         string
+	DBName           string
+	SSLMode          string
+	MaxOpenConns     int
+	MaxIdleConns     int
+	ConnMaxLifetime  time.Duration
+	QueryTimeout     time.Duration
+	StatementTimeout time.Duration
+	PoolInterval     time.Duration
+	PoolMaxWait      time.Duration
+	PoolAutoscale    bool
+	PoolMaxOpen      int
 }
 
 // JWTConfig holds JWT configuration.
+// Algorithm is one of HS256, RS256 or EdDSA. Asymmetric algorithms sign with
+// the key named by KeyID and accept every key in PrivateKeyFiles for verification.
+// AllowLegacyTokens accepts tokens issued before sessions existed (no jti)
+// until they expire.
 type JWTConfig struct {
-	SecretKey  string
-	ExpiryTime time.Duration
+	SecretKey         string
+	ExpiryTime        time.Duration
+	Algorithm         string
+	KeyID             string
+	PrivateKeyFiles   map[string]string
+	Issuer            string
+	Audience          []string
+	AllowLegacyTokens bool
+	// AccessTokenMaxAge is the longest lifetime of an access token issued
+	// with limited scopes
+	AccessTokenMaxAge time.Duration
+	// ImpersonationTTL is the lifetime of a support impersonation token
+	ImpersonationTTL time.Duration
+}
+
+// SigningConfig holds HMAC request signing configuration.
+// Webhook event IDs are kept for EventTTL so events delivered again are not
+// applied twice, and expired IDs are purged every EventPurgeInterval.
+type SigningConfig struct {
+	Enabled            bool
+	Keys               map[string]string
+	ReplayWindow       time.Duration
+	EventTTL           time.Duration
+	EventPurgeInterval time.Duration
+}
+
+// JobConfig holds background job configuration.
+// Failed jobs are retried with exponential backoff starting at RetryBackoff and
+// moved to the dead letter queue after MaxAttempts.
+// Jobs are stored in the database and run by Workers, which poll for due jobs
+// every PollInterval and hold a claimed job for at most Lease.
+// The payment events and deferred payments of an order run one at a time, on
+// one of Partitions workers picked by order ID.
+type JobConfig struct {
+	MaxAttempts  int
+	RetryBackoff time.Duration
+	Workers      int
+	PollInterval time.Duration
+	Lease        time.Duration
+	Partitions   int
+}
+
+// SagaConfig holds the persisted state of multi-step operations such as
+// placing an order. Every RecoveryInterval, sagas not updated for StaleAfter
+// were interrupted and are compensated, and finished sagas older than
+// Retention are deleted; a zero interval disables recovery.
+type SagaConfig struct {
+	RecoveryInterval time.Duration
+	StaleAfter       time.Duration
+	Retention        time.Duration
+}
+
+// LockConfig holds the locks shared by the instances, taken by scheduled
+// jobs and the processing of an order. Backend is "postgres" for advisory
+// locks, "redis" for locks in the Redis at RedisURL under keys starting with
+// RedisPrefix, or "memory" for a single instance. A lock is held for TTL at
+// most, and an order waits up to Wait for its lock. The leader of the
+// replicas holds its lock for LeaderTTL, renewed every LeaderRenew.
+type LockConfig struct {
+	Backend     string
+	RedisURL    string
+	RedisPrefix string
+	TTL         time.Duration
+	Wait        time.Duration
+	LeaderTTL   time.Duration
+	LeaderRenew time.Duration
+}
+
+// BudgetConfig holds the daily budgets of the paid providers, in cost units.
+// Usage is tracked per UTC day and shared between instances through the
+// database every FlushInterval. A warning is logged once WarnAt of a budget
+// is spent and again when all of it is; with BlockNonCritical, marketing
+// emails are then refused until the next day.
+type BudgetConfig struct {
+	Payment          ProviderBudget
+	SMS              ProviderBudget
+	Email            ProviderBudget
+	WarnAt           float64
+	BlockNonCritical bool
+	FlushInterval    time.Duration
+}
+
+// ProviderBudget is the daily budget of a paid provider. Each call costs
+// CostPerCall units, per recipient for emails; a zero Daily only tracks usage.
+type ProviderBudget struct {
+	Daily       float64
+	CostPerCall float64
+}
+
+// OTPConfig holds one-time code configuration for SMS login and phone verification.
+// A code is valid for TTL and MaxAttempts guesses; a new code for the same
+// phone can be requested after ResendInterval.
+type OTPConfig struct {
+	Length         int
+	TTL            time.Duration
+	MaxAttempts    int
+	ResendInterval time.Duration
+}
+
+// UsernameConfig holds the username policy applied at registration. Usernames
+// are MinLength to MaxLength characters matching Pattern. ReserveDefaults
+// rejects a built-in list of reserved names such as admin and api, Reserved
+// adds to them, and BannedFile lists words, one per line, usernames must not
+// contain.
+type UsernameConfig struct {
+	MinLength       int
+	MaxLength       int
+	Pattern         string
+	ReserveDefaults bool
+	Reserved        []string
+	BannedFile      string
+}
+
+// PasswordConfig holds the password policy applied at registration and
+// password change. RejectCommon rejects a built-in list of common passwords,
+// BannedFile adds one password per line to the rejected ones.
+type PasswordConfig struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	RejectCommon     bool
+	BannedFile       string
+	RejectSimilar    bool
+}
+
+// LoginAlertConfig holds login anomaly detection. A login from a device or
+// country not seen in the user's sessions of the last History is emailed to
+// the user; with RequireVerification it also needs a code texted to the
+// user's verified phone.
+type LoginAlertConfig struct {
+	Enabled             bool
+	History             time.Duration
+	RequireVerification bool
+}
+
+// AvatarConfig holds avatar upload limits. Uploads larger than MaxUploadSize
+// bytes, or with more pixels than a MaxDimension square, are rejected.
+type AvatarConfig struct {
+	MaxUploadSize int64
+	MaxDimension  int
+}
+
+// UploadConfig holds resumable upload settings. Files up to MaxSize bytes are
+// uploaded in PartSize parts, each of which may take up to PartTimeout to send;
+// uploads not completed within Expiry are aborted. Completed files are
+// downloaded through signed URLs valid for DownloadURLTTL.
+type UploadConfig struct {
+	MaxSize        int64
+	PartSize       int64
+	PartTimeout    time.Duration
+	Expiry         time.Duration
+	DownloadURLTTL time.Duration
+}
+
+// EmailAddressConfig holds the settings of account email addresses. With
+// StripPlus the plus subaddress is removed from the addresses users register
+// or change to. A new address is confirmed from the link emailed to it,
+// ConfirmURL, a page of the app with a token query parameter; without one the
+// email carries the bare token. Tokens are signed with SigningKey and valid
+// for ConfirmTTL. Without a key email addresses cannot be changed.
+type EmailAddressConfig struct {
+	StripPlus  bool
+	ConfirmTTL time.Duration
+	ConfirmURL string
+	SigningKey string
+}
+
+// OrganizationConfig holds organization invitation settings. Invitations are
+// valid for InvitationTTL. The emailed link is InvitationURL, a page of the
+// app accepting or signing up with it, with a token query parameter; without
+// one the email carries the bare token. With SigningKey the link also carries
+// its expiry and an HMAC signature, required to use the token.
+type OrganizationConfig struct {
+	InvitationTTL time.Duration
+	InvitationURL string
+	SigningKey    string
+}
+
+// SCIMConfig holds SCIM 2.0 user provisioning settings. Identity providers
+// authenticate with the bearer Token; without one the /scim/v2 routes are not
+// mounted.
+type SCIMConfig struct {
+	Token string
+}
+
+// SSOConfig holds OpenID Connect single sign-on settings. Identity providers
+// redirect back to RedirectURL, a page of the app posting the code and state
+// to the callback endpoint; without one single sign-on is off. Sign-ins must
+// complete within LoginTTL, and identity provider calls within HTTPTimeout.
+// Issuers must be https URLs on public addresses unless AllowPrivateIssuers
+// is set, for development against a local identity provider.
+type SSOConfig struct {
+	RedirectURL         string
+	LoginTTL            time.Duration
+	HTTPTimeout         time.Duration
+	AllowPrivateIssuers bool
+}
+
+// LegalConfig holds the legal documents users must accept, each published at
+// its URL. Bumping a version asks every user to accept it again before
+// placing orders; an empty version leaves the document out.
+type LegalConfig struct {
+	TermsVersion   string
+	TermsURL       string
+	PrivacyVersion string
+	PrivacyURL     string
+}
+
+// Authentication modes: how sign-ins hand the JWT to clients
+const (
+	AuthModeHeader = "header"
+	AuthModeCookie = "cookie"
+	AuthModeBoth   = "both"
+)
+
+// AuthCookieConfig holds cookie authentication for browser clients. In the
+// "cookie" and "both" modes sign-ins set an encrypted HttpOnly cookie named
+// Name carrying the JWT, and a CSRF token cookie named CSRFName that scripts
+// echo in the X-CSRF-Token header of unsafe requests. The "cookie" mode also
+// leaves the token out of responses. Authorization headers are accepted in
+// every mode. Cookies are encrypted with keys derived from Secret.
+type AuthCookieConfig struct {
+	Mode     string
+	Name     string
+	CSRFName string
+	Domain   string
+	Secure   bool
+	SameSite string
+	Secret   string
+}
+
+// Enabled reports whether sign-ins set cookies
+func (c AuthCookieConfig) Enabled() bool {
+	return c.Mode == AuthModeCookie || c.Mode == AuthModeBoth
+}
+
+// StaticConfig holds static file serving configuration. The files under Dir,
+// such as public uploads or a single-page app build, are served at Path; an
+// empty Dir serves nothing. With SPA set, browser navigations to unknown paths
+// get Dir/index.html.
+type StaticConfig struct {
+	Dir    string
+	Path   string
+	MaxAge time.Duration
+	SPA    bool
+}
+
+// I18nConfig holds localization configuration. Responses are written in the
+// Accept-Language or saved user locale, or in DefaultLocale. Translations in
+// CatalogDir, one <locale>.json file per locale, add to the built-in ones.
+type I18nConfig struct {
+	DefaultLocale string
+	CatalogDir    string
+}
+
+// StatsConfig holds the admin dashboard stats. Aggregates are cached for
+// CacheTTL; zero recomputes them on every request.
+type StatsConfig struct {
+	CacheTTL time.Duration
+}
+
+// ReportsConfig holds the reporting tables. Every RollupInterval, starting at
+// startup, the last RollupDays days of orders and sign-ups are rolled up into
+// them; a zero interval disables the scheduled rollup.
+type ReportsConfig struct {
+	RollupInterval time.Duration
+	RollupDays     int
+}
+
+// ExportConfig holds dataset exports. Streams are flushed every ChunkSize rows;
+// background exports are uploaded to file storage in parts of PartSize bytes
+// and downloaded through signed URLs valid for DownloadTTL. Users are
+// emailed a link to the archive of their personal data valid for ArchiveTTL.
+type ExportConfig struct {
+	ChunkSize   int
+	PartSize    int64
+	DownloadTTL time.Duration
+	ArchiveTTL  time.Duration
+}
+
+// FeaturesConfig holds feature toggles.
+// DeferredPayments accepts orders as payment pending while the payment
+// provider circuit is open and charges them from a background job.
+type FeaturesConfig struct {
+	DeferredPayments bool
+}
+
+// TimeoutConfig holds the per-operation deadlines applied by usecases.
+// A zero timeout leaves the operation bounded only by the caller's context.
+type TimeoutConfig struct {
+	Default      time.Duration
+	DBRead       time.Duration
+	DBWrite      time.Duration
+	Payment      time.Duration
+	Fraud        time.Duration
+	Geolocation  time.Duration
+	Notification time.Duration
+	Storage      time.Duration
 }
 
 // ProvidersConfig holds external providers configuration.
@@ -48,24 +595,52 @@ type ProvidersConfig struct {
 	Payment      PaymentConfig
 	Notification NotificationConfig
 	FileStorage  FileStorageConfig
+	Captcha      CaptchaConfig
+	Fraud        FraudConfig
+	Geolocation  GeolocationConfig
+	Scanner      ScannerConfig
 }
 
-// PaymentConfig holds payment provider configuration.
+// PaymentConfig holds payment provider configuration. Each provider runs
+// against its sandbox or live environment.
+// CircuitFailureThreshold consecutive failures open the circuit for
+// CircuitOpenTimeout; a threshold of 0 disables the circuit breaker.
+// Statuses read from the provider are cached for StatusCacheTTL; zero
+// disables the cache. The cache is kept in the Redis at StatusCacheRedisURL
+// under keys starting with StatusCacheRedisPrefix, or in PostgreSQL without
+// one. Clients may wait up to StatusMaxWait for a status to
+// change, read again every StatusRecheckInterval; a zero wait disables
+// waiting. Orders are accepted in Currencies, ISO 4217 codes; empty accepts
+// any currency.
 type PaymentConfig struct {
-	Provider string
-	Stripe   StripeConfig
-	PayPal   PayPalConfig
+	Provider                string
+	Stripe                  StripeConfig
+	PayPal                  PayPalConfig
+	Currencies              []string
+	CircuitFailureThreshold int
+	CircuitOpenTimeout      time.Duration
+	StatusCacheTTL          time.Duration
+	StatusCacheRedisURL     string
+	StatusCacheRedisPrefix  string
+	StatusMaxWait           time.Duration
+	StatusRecheckInterval   time.Duration
 }
 
 // StripeConfig holds Stripe-specific configuration.
+// Environment is "sandbox" (test mode keys) or "live". PublishableKey is
+// handed to frontends to collect payment details.
 type StripeConfig struct {
-	BaseURL string
-	APIKey  string
-	Timeout time.Duration
+	Environment    string
+	BaseURL        string
+	APIKey         string
+	PublishableKey string
+	Timeout        time.Duration
 }
 
 // PayPalConfig holds PayPal-specific configuration.
+// Environment is "sandbox" or "live", and selects the default BaseURL.
 type PayPalConfig struct {
+	Environment  string
 	BaseURL      string
 	ClientID     string
 	ClientSecret string
@@ -73,17 +648,37 @@ type PayPalConfig struct {
 }
 
 // NotificationConfig holds notification provider configuration.
+// Emails are delivered by Workers, each priority queueing at most QueueSize
+// emails; QueuePolicy "block" waits for room and "drop" rejects the email.
+// Bulk campaigns are sent BulkChunkSize recipients per provider call. Every
+// StatusSyncInterval, the delivery status of campaigns that finished sending
+// within StatusSyncWindow is fetched from the provider; 0 disables it.
+// SMS and push messages fall back to DefaultLocale when the recipient's
+// locale has no template.
 type NotificationConfig struct {
-	Email EmailConfig
-	SMS   SMSConfig
+	Email              EmailConfig
+	SMS                SMSConfig
+	Workers            int
+	QueueSize          int
+	QueuePolicy        string
+	BulkChunkSize      int
+	StatusSyncInterval time.Duration
+	StatusSyncWindow   time.Duration
+	DefaultLocale      string
 }
 
 // EmailConfig holds email service configuration.
+// MaxAttachmentsSize bounds the combined size of an email's attachments and
+// MaxAttachments their number. AllowedAttachmentTypes lists the accepted MIME
+// types; when empty, common document and image types are accepted.
 type EmailConfig struct {
-	BaseURL   string
-	APIKey    string
-	FromEmail string
-	Timeout   time.Duration
+	BaseURL                string
+	APIKey                 string
+	FromEmail              string
+	Timeout                time.Duration
+	MaxAttachmentsSize     int64
+	MaxAttachments         int
+	AllowedAttachmentTypes []string
 }
 
 // SMSConfig holds SMS service configuration.
@@ -94,11 +689,66 @@ type SMSConfig struct {
 	Timeout    time.Duration
 }
 
+// CaptchaConfig holds CAPTCHA verification configuration.
+// Mode is one of "off", "always" or "adaptive"; adaptive mode only requires a
+// CAPTCHA after FailureThreshold failed attempts from the same IP within FailureWindow.
+type CaptchaConfig struct {
+	Provider         string
+	SecretKey        string
+	MinScore         float64
+	Mode             string
+	FailureThreshold int
+	FailureWindow    time.Duration
+	Timeout          time.Duration
+}
+
+// FraudConfig holds fraud screening configuration.
+// Provider is "" (disabled), "rules" or "external". Orders scoring at or above
+// BlockThreshold are rejected; those at or above ReviewThreshold are flagged.
+type FraudConfig struct {
+	Provider          string
+	BaseURL           string
+	APIKey            string
+	Timeout           time.Duration
+	BlockThreshold    float64
+	ReviewThreshold   float64
+	HighAmount        float64
+	NewAccountAge     time.Duration
+	MaxItems          int
+	BlockedCountries  []string
+	DisposableDomains []string
+}
+
+// GeolocationConfig holds IP geolocation configuration.
+// Provider is "" (disabled) or "ipapi". With Requests, the client of every
+// request is located, caching up to CacheSize addresses for CacheTTL.
+type GeolocationConfig struct {
+	Provider  string
+	BaseURL   string
+	APIKey    string
+	Timeout   time.Duration
+	Requests  bool
+	CacheTTL  time.Duration
+	CacheSize int
+}
+
+// ScannerConfig holds malware scanning configuration.
+// Provider is "" (disabled) or "clamav", reached at ClamAVAddress as host:port
+// or a unix socket path. Timeout bounds the scan of one file.
+type ScannerConfig struct {
+	Provider      string
+	ClamAVAddress string
+	Timeout       time.Duration
+}
+
 // FileStorageConfig holds file storage configuration.
+// PublicURL is the base URL files are served from, e.g. a CDN; by default S3
+// files use the bucket URL and local files are served by the API under /files.
 type FileStorageConfig struct {
-	Provider string
-	S3       S3Config
-	Local    LocalStorageConfig
+	Provider  string
+	PublicURL string
+	S3        S3Config
+	Local     LocalStorageConfig
 }
 
 // S3Config holds AWS S3 configuration.
@@ -111,55 +761,321 @@ type S3Config struct {
 }
 
 // LocalStorageConfig holds local file storage configuration.
+// SigningKey signs download URLs; without it signed URLs are unavailable.
 type LocalStorageConfig struct {
-	BasePath string
+	BasePath   string
+	SigningKey string
 }
 
 func LoadConfig() *Config {
+	paypalEnvironment := getEnv("PAYPAL_ENVIRONMENT", PaymentSandbox)
+
 	return &Config{
+		Env: getEnv("ENV", EnvDevelopment),
 		Server: ServerConfig{
-			Port:           getEnv("SERVER_PORT", "8080"),
-			Host:           getEnv("SERVER_HOST", "localhost"),
-			ReadTimeout:    getDurationEnv("SERVER_READ_TIMEOUT", 10*time.Second),
-			WriteTimeout:   getDurationEnv("SERVER_WRITE_TIMEOUT", 10*time.Second),
-			MaxHeaderBytes: getIntEnv("SERVER_MAX_HEADER_BYTES", 1<<20),
+			Port:            getEnv("SERVER_PORT", "8080"),
+			Host:            getEnv("SERVER_HOST", "localhost"),
+			Network:         getEnv("SERVER_NETWORK", "tcp"),
+			SocketPath:      getEnv("SERVER_SOCKET_PATH", ""),
+			SocketMode:      getFileModeEnv("SERVER_SOCKET_MODE", 0o660),
+			ReadTimeout:     getDurationEnv("SERVER_READ_TIMEOUT", 10*time.Second),
+			WriteTimeout:    getDurationEnv("SERVER_WRITE_TIMEOUT", 10*time.Second),
+			MaxHeaderBytes:  getIntEnv("SERVER_MAX_HEADER_BYTES", 1<<20),
+			SwaggerEnabled:  getBoolEnv("SWAGGER_ENABLED", false),
+			ProblemDetails:  getBoolEnv("PROBLEM_DETAILS_ENABLED", false),
+			DedupWindow:     getDurationEnv("REQUEST_DEDUP_WINDOW", 5*time.Second),
+			TrustedProxies:  getSliceEnv("SERVER_TRUSTED_PROXIES"),
+			TrustedPlatform: getEnv("SERVER_TRUSTED_PLATFORM", ""),
+			AccessLog:       getEnv("ACCESS_LOG", ""),
+			AccessLogFormat: getEnv("ACCESS_LOG_FORMAT", "combined"),
+			HTTP2:           getBoolEnv("SERVER_HTTP2", true),
+			H2C:             getBoolEnv("SERVER_H2C", false),
+			TLS: TLSConfig{
+				CertFile:         getEnv("SERVER_TLS_CERT_FILE", ""),
+				KeyFile:          getEnv("SERVER_TLS_KEY_FILE", ""),
+				AutocertDomains:  getSliceEnv("SERVER_TLS_AUTOCERT_DOMAINS"),
+				AutocertEmail:    getEnv("SERVER_TLS_AUTOCERT_EMAIL", ""),
+				AutocertCacheDir: getEnv("SERVER_TLS_AUTOCERT_CACHE_DIR", "./certs"),
+				RedirectAddr:     getEnv("SERVER_TLS_REDIRECT_ADDR", ""),
+			},
+			ResponseCacheSize: getIntEnv("RESPONSE_CACHE_MAX_BYTES", 32<<20),
+			DrainPeriod:       getDurationEnv("SHUTDOWN_DRAIN_PERIOD", 0),
+			RateLimit: RateLimitConfig{
+				Anonymous: RateLimitBucket{
+					RequestsPerSecond:     getFloatEnv("RATE_LIMIT_RPS", 100),
+					Burst:                 getIntEnv("RATE_LIMIT_BURST", 1),
+					SoftRequestsPerSecond: getFloatEnv("RATE_LIMIT_SOFT_RPS", 0),
+				},
+				Authenticated: RateLimitBucket{
+					RequestsPerSecond:     getFloatEnv("RATE_LIMIT_AUTHENTICATED_RPS", 100),
+					Burst:                 getIntEnv("RATE_LIMIT_AUTHENTICATED_BURST", 1),
+					SoftRequestsPerSecond: getFloatEnv("RATE_LIMIT_AUTHENTICATED_SOFT_RPS", 0),
+				},
+				InFlightTTL:  getDurationEnv("RATE_LIMIT_IN_FLIGHT_TTL", time.Minute),
+				RedisURL:     getEnv("RATE_LIMIT_REDIS_URL", ""),
+				RedisTimeout: getDurationEnv("RATE_LIMIT_REDIS_TIMEOUT", 100*time.Millisecond),
+				RedisPrefix:  getEnv("RATE_LIMIT_REDIS_PREFIX", "ratelimit:"),
+			},
+			LoadShed: LoadShedConfig{
+				MaxInFlight:   getIntEnv("LOAD_SHED_MAX_IN_FLIGHT", 0),
+				MaxLatencyP99: getDurationEnv("LOAD_SHED_MAX_LATENCY_P99", 0),
+				RetryAfter:    getDurationEnv("LOAD_SHED_RETRY_AFTER", 5*time.Second),
+			},
+			Pipeline: PipelineConfig{
+				Order:    getSliceEnv("MIDDLEWARE_ORDER"),
+				Disabled: getSliceEnv("MIDDLEWARE_DISABLED"),
+				Groups:   getGroupsEnv("MIDDLEWARE_GROUPS"),
+			},
+		},
+		Startup: StartupConfig{
+			MaxWait:        getDurationEnv("STARTUP_MAX_WAIT", time.Minute),
+			InitialBackoff: getDurationEnv("STARTUP_RETRY_INITIAL", 500*time.Millisecond),
+			MaxBackoff:     getDurationEnv("STARTUP_RETRY_MAX", 10*time.Second),
+			CheckTimeout:   getDurationEnv("STARTUP_CHECK_TIMEOUT", 5*time.Second),
+		},
+		Probes: ProbeConfig{
+			Interval: getDurationEnv("HEALTH_PROBE_INTERVAL", time.Minute),
+			Timeout:  getDurationEnv("HEALTH_PROBE_TIMEOUT", 5*time.Second),
+			Payment:  getBoolEnv("HEALTH_PROBE_PAYMENT", true),
+			Email:    getBoolEnv("HEALTH_PROBE_EMAIL", true),
 		},
 		Database: DatabaseConfig{
-			Host:            getEnv("DB_HOST", "localhost"),
-			Port:            getEnv("DB_PORT", "5432"),
-			User:            getEnv("DB_USER", "postgres"),
-			Password:        getEnv("DB_PASSWORD", "password"),
-			DBName:          getEnv("DB_NAME", "boilerplate"),
-			SSLMode:         getEnv("DB_SSLMODE", "disable"),
-			MaxOpenConns:    getIntEnv("DB_MAX_OPEN_CONNS", 25),
-			MaxIdleConns:    getIntEnv("DB_MAX_IDLE_CONNS", 5),
-			ConnMaxLifetime: getDurationEnv("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+			Host:             getEnv("DB_HOST", "localhost"),
+			Port:             getEnv("DB_PORT", "5432"),
+			User:             getEnv("DB_USER", "postgres"),
+			Password:         getEnv("DB_PASSWORD", "password"),
+			DBName:           getEnv("DB_NAME", "boilerplate"),
+			SSLMode:          getEnv("DB_SSLMODE", "disable"),
+			MaxOpenConns:     getIntEnv("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:     getIntEnv("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime:  getDurationEnv("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+			QueryTimeout:     getDurationEnv("DB_QUERY_TIMEOUT", 10*time.Second),
+			StatementTimeout: getDurationEnv("DB_STATEMENT_TIMEOUT", 30*time.Second),
+			PoolInterval:     getDurationEnv("DB_POOL_CHECK_INTERVAL", 15*time.Second),
+			PoolMaxWait:      getDurationEnv("DB_POOL_MAX_WAIT", 50*time.Millisecond),
+			PoolAutoscale:    getBoolEnv("DB_POOL_AUTOSCALE", false),
+			PoolMaxOpen:      getIntEnv("DB_POOL_MAX_OPEN_CONNS", 50),
 		},
 		JWT: JWTConfig{
-			SecretKey:  getEnv("JWT_SECRET", "your-secret-key"),
-			ExpiryTime: getDurationEnv("JWT_EXPIRY_TIME", 24*time.Hour),
+			SecretKey:         getEnv("JWT_SECRET", "your-secret-key"),
+			ExpiryTime:        getDurationEnv("JWT_EXPIRY_TIME", 24*time.Hour),
+			Algorithm:         getEnv("JWT_ALGORITHM", "HS256"),
+			KeyID:             getEnv("JWT_KEY_ID", ""),
+			PrivateKeyFiles:   getMapEnv("JWT_PRIVATE_KEY_FILES"),
+			Issuer:            getEnv("JWT_ISSUER", ""),
+			Audience:          getSliceEnv("JWT_AUDIENCE"),
+			AllowLegacyTokens: getBoolEnv("JWT_ALLOW_LEGACY_TOKENS", true),
+			AccessTokenMaxAge: getDurationEnv("JWT_ACCESS_TOKEN_MAX_AGE", 30*24*time.Hour),
+			ImpersonationTTL:  getDurationEnv("JWT_IMPERSONATION_TTL", 15*time.Minute),
+		},
+		Signing: SigningConfig{
+			Enabled:            getBoolEnv("REQUEST_SIGNING_ENABLED", false),
+			Keys:               getMapEnv("REQUEST_SIGNING_KEYS"),
+			ReplayWindow:       getDurationEnv("REQUEST_SIGNING_REPLAY_WINDOW", 5*time.Minute),
+			EventTTL:           getDurationEnv("WEBHOOK_EVENT_TTL", 72*time.Hour),
+			EventPurgeInterval: getDurationEnv("WEBHOOK_EVENT_PURGE_INTERVAL", time.Hour),
+		},
+		Jobs: JobConfig{
+			MaxAttempts:  getIntEnv("JOB_MAX_ATTEMPTS", 5),
+			RetryBackoff: getDurationEnv("JOB_RETRY_BACKOFF", 2*time.Second),
+			Workers:      getIntEnv("JOB_WORKERS", 4),
+			PollInterval: getDurationEnv("JOB_POLL_INTERVAL", time.Second),
+			Lease:        getDurationEnv("JOB_LEASE", 5*time.Minute),
+			Partitions:   getIntEnv("JOB_PARTITIONS", 16),
+		},
+		Sagas: SagaConfig{
+			RecoveryInterval: getDurationEnv("SAGA_RECOVERY_INTERVAL", time.Minute),
+			StaleAfter:       getDurationEnv("SAGA_STALE_AFTER", 10*time.Minute),
+			Retention:        getDurationEnv("SAGA_RETENTION", 7*24*time.Hour),
+		},
+		Locks: LockConfig{
+			Backend:     getEnv("LOCK_BACKEND", "postgres"),
+			RedisURL:    getEnv("LOCK_REDIS_URL", ""),
+			RedisPrefix: getEnv("LOCK_REDIS_PREFIX", "lock:"),
+			TTL:         getDurationEnv("LOCK_TTL", 30*time.Second),
+			Wait:        getDurationEnv("LOCK_WAIT", 5*time.Second),
+			LeaderTTL:   getDurationEnv("LEADER_TTL", 15*time.Second),
+			LeaderRenew: getDurationEnv("LEADER_RENEW_INTERVAL", 5*time.Second),
+		},
+		OTP: OTPConfig{
+			Length:         getIntEnv("OTP_LENGTH", 6),
+			TTL:            getDurationEnv("OTP_TTL", 5*time.Minute),
+			MaxAttempts:    getIntEnv("OTP_MAX_ATTEMPTS", 5),
+			ResendInterval: getDurationEnv("OTP_RESEND_INTERVAL", time.Minute),
+		},
+		Username: UsernameConfig{
+			MinLength:       getIntEnv("USERNAME_MIN_LENGTH", 3),
+			MaxLength:       getIntEnv("USERNAME_MAX_LENGTH", 50),
+			Pattern:         getEnv("USERNAME_PATTERN", username.DefaultPattern),
+			ReserveDefaults: getBoolEnv("USERNAME_RESERVE_DEFAULTS", true),
+			Reserved:        getSliceEnv("USERNAME_RESERVED"),
+			BannedFile:      getEnv("USERNAME_BANNED_FILE", ""),
+		},
+		Password: PasswordConfig{
+			MinLength:        getIntEnv("PASSWORD_MIN_LENGTH", 8),
+			RequireUppercase: getBoolEnv("PASSWORD_REQUIRE_UPPERCASE", false),
+			RequireLowercase: getBoolEnv("PASSWORD_REQUIRE_LOWERCASE", false),
+			RequireDigit:     getBoolEnv("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol:    getBoolEnv("PASSWORD_REQUIRE_SYMBOL", false),
+			RejectCommon:     getBoolEnv("PASSWORD_REJECT_COMMON", true),
+			BannedFile:       getEnv("PASSWORD_BANNED_FILE", ""),
+			RejectSimilar:    getBoolEnv("PASSWORD_REJECT_SIMILAR", true),
+		},
+		Login: LoginAlertConfig{
+			Enabled:             getBoolEnv("LOGIN_ALERTS_ENABLED", true),
+			History:             getDurationEnv("LOGIN_ALERTS_HISTORY", 90*24*time.Hour),
+			RequireVerification: getBoolEnv("LOGIN_ALERTS_REQUIRE_VERIFICATION", false),
+		},
+		Avatar: AvatarConfig{
+			MaxUploadSize: int64(getIntEnv("AVATAR_MAX_UPLOAD_SIZE", 5<<20)),
+			MaxDimension:  getIntEnv("AVATAR_MAX_DIMENSION", 4096),
+		},
+		Upload: UploadConfig{
+			MaxSize:        int64(getIntEnv("UPLOAD_MAX_SIZE", 5<<30)),
+			PartSize:       int64(getIntEnv("UPLOAD_PART_SIZE", 8<<20)),
+			PartTimeout:    getDurationEnv("UPLOAD_PART_TIMEOUT", 5*time.Minute),
+			Expiry:         getDurationEnv("UPLOAD_EXPIRY", 24*time.Hour),
+			DownloadURLTTL: getDurationEnv("UPLOAD_DOWNLOAD_URL_TTL", 15*time.Minute),
+		},
+		Email: EmailAddressConfig{
+			StripPlus:  getBoolEnv("EMAIL_STRIP_PLUS_ADDRESSING", false),
+			ConfirmTTL: getDurationEnv("EMAIL_CHANGE_TTL", 24*time.Hour),
+			ConfirmURL: getEnv("EMAIL_CHANGE_URL", ""),
+			SigningKey: getEnv("EMAIL_CHANGE_SIGNING_KEY", ""),
+		},
+		Orgs: OrganizationConfig{
+			InvitationTTL: getDurationEnv("ORG_INVITATION_TTL", 7*24*time.Hour),
+			InvitationURL: getEnv("ORG_INVITATION_URL", ""),
+			SigningKey:    getEnv("ORG_INVITATION_SIGNING_KEY", ""),
+		},
+		SCIM: SCIMConfig{
+			Token: getEnv("SCIM_TOKEN", ""),
+		},
+		SSO: SSOConfig{
+			RedirectURL:         getEnv("SSO_REDIRECT_URL", ""),
+			LoginTTL:            getDurationEnv("SSO_LOGIN_TTL", 10*time.Minute),
+			HTTPTimeout:         getDurationEnv("SSO_HTTP_TIMEOUT", 10*time.Second),
+			AllowPrivateIssuers: getBoolEnv("SSO_ALLOW_PRIVATE_ISSUERS", false),
+		},
+		Legal: LegalConfig{
+			TermsVersion:   getEnv("LEGAL_TERMS_VERSION", ""),
+			TermsURL:       getEnv("LEGAL_TERMS_URL", ""),
+			PrivacyVersion: getEnv("LEGAL_PRIVACY_VERSION", ""),
+			PrivacyURL:     getEnv("LEGAL_PRIVACY_URL", ""),
+		},
+		Cookies: AuthCookieConfig{
+			Mode:     getEnv("AUTH_MODE", AuthModeHeader),
+			Name:     getEnv("AUTH_COOKIE_NAME", "session"),
+			CSRFName: getEnv("AUTH_CSRF_COOKIE_NAME", "csrf_token"),
+			Domain:   getEnv("AUTH_COOKIE_DOMAIN", ""),
+			Secure:   getBoolEnv("AUTH_COOKIE_SECURE", true),
+			SameSite: getEnv("AUTH_COOKIE_SAMESITE", "lax"),
+			Secret:   getEnv("AUTH_COOKIE_SECRET", ""),
+		},
+		Static: StaticConfig{
+			Dir:    getEnv("STATIC_DIR", ""),
+			Path:   getEnv("STATIC_PATH", "/"),
+			MaxAge: getDurationEnv("STATIC_MAX_AGE", time.Hour),
+			SPA:    getBoolEnv("STATIC_SPA", false),
+		},
+		I18n: I18nConfig{
+			DefaultLocale: getEnv("I18N_DEFAULT_LOCALE", "en"),
+			CatalogDir:    getEnv("I18N_CATALOG_DIR", ""),
+		},
+		Logging: LoggingConfig{
+			Sink:          getEnv("LOG_SINK", ""),
+			SinkURL:       getEnv("LOG_SINK_URL", ""),
+			Index:         getEnv("LOG_SINK_INDEX", "logs-boilerplate-go"),
+			Labels:        getMapEnv("LOG_SINK_LABELS"),
+			Username:      getEnv("LOG_SINK_USERNAME", ""),
+			Password:      getEnv("LOG_SINK_PASSWORD", ""),
+			APIKey:        getEnv("LOG_SINK_API_KEY", ""),
+			BatchSize:     getIntEnv("LOG_SINK_BATCH_SIZE", 500),
+			FlushInterval: getDurationEnv("LOG_SINK_FLUSH_INTERVAL", time.Second),
+			BufferSize:    getIntEnv("LOG_SINK_BUFFER_SIZE", 10000),
+			Timeout:       getDurationEnv("LOG_SINK_TIMEOUT", 10*time.Second),
+		},
+		Metrics: MetricsConfig{
+			StatusClasses: getBoolEnv("METRICS_STATUS_CLASSES", false),
+			Paths:         getSliceEnv("METRICS_PATHS"),
+			UserBuckets:   getIntEnv("METRICS_USER_BUCKETS", 0),
+			Exemplars:     getBoolEnv("METRICS_EXEMPLARS", false),
+			SLO: SLOConfig{
+				Enabled:            getBoolEnv("SLO_ENABLED", false),
+				AvailabilityTarget: getFloatEnv("SLO_AVAILABILITY_TARGET", 0.999),
+				LatencyTarget:      getFloatEnv("SLO_LATENCY_TARGET", 0.99),
+				LatencyThreshold:   getDurationEnv("SLO_LATENCY_THRESHOLD", 500*time.Millisecond),
+			},
+		},
+		Stats: StatsConfig{
+			CacheTTL: getDurationEnv("STATS_CACHE_TTL", time.Minute),
+		},
+		Reports: ReportsConfig{
+			RollupInterval: getDurationEnv("REPORTS_ROLLUP_INTERVAL", 24*time.Hour),
+			RollupDays:     getIntEnv("REPORTS_ROLLUP_DAYS", 3),
+		},
+		Exports: ExportConfig{
+			ChunkSize:   getIntEnv("EXPORT_CHUNK_SIZE", 1000),
+			PartSize:    int64(getIntEnv("EXPORT_PART_SIZE", 8<<20)),
+			DownloadTTL: getDurationEnv("EXPORT_DOWNLOAD_TTL", 15*time.Minute),
+			ArchiveTTL:  getDurationEnv("EXPORT_ARCHIVE_TTL", 24*time.Hour),
+		},
+		Features: FeaturesConfig{
+			DeferredPayments: getBoolEnv("FEATURE_DEFERRED_PAYMENTS", false),
+		},
+		Budgets: BudgetConfig{
+			Payment:          ProviderBudget{Daily: getFloatEnv("BUDGET_PAYMENT_DAILY", 0), CostPerCall: getFloatEnv("BUDGET_PAYMENT_COST", 1)},
+			SMS:              ProviderBudget{Daily: getFloatEnv("BUDGET_SMS_DAILY", 0), CostPerCall: getFloatEnv("BUDGET_SMS_COST", 1)},
+			Email:            ProviderBudget{Daily: getFloatEnv("BUDGET_EMAIL_DAILY", 0), CostPerCall: getFloatEnv("BUDGET_EMAIL_COST", 1)},
+			WarnAt:           getFloatEnv("BUDGET_WARN_AT", 0.8),
+			BlockNonCritical: getBoolEnv("BUDGET_BLOCK_NON_CRITICAL", false),
+			FlushInterval:    getDurationEnv("BUDGET_FLUSH_INTERVAL", 30*time.Second),
+		},
+		Timeouts: TimeoutConfig{
+			Default:      getDurationEnv("TIMEOUT_DEFAULT", 10*time.Second),
+			DBRead:       getDurationEnv("TIMEOUT_DB_READ", 2*time.Second),
+			DBWrite:      getDurationEnv("TIMEOUT_DB_WRITE", 5*time.Second),
+			Payment:      getDurationEnv("TIMEOUT_PAYMENT", 5*time.Second),
+			Fraud:        getDurationEnv("TIMEOUT_FRAUD", 2*time.Second),
+			Geolocation:  getDurationEnv("TIMEOUT_GEOLOCATION", time.Second),
+			Notification: getDurationEnv("TIMEOUT_NOTIFICATION", 10*time.Second),
+			Storage:      getDurationEnv("TIMEOUT_STORAGE", 10*time.Second),
 		},
 		Providers: ProvidersConfig{
 			Payment: PaymentConfig{
 				Provider: getEnv("PAYMENT_PROVIDER", "stripe"),
 				Stripe: StripeConfig{
-					BaseURL: getEnv("STRIPE_BASE_URL", "https://api.stripe.com/v1"),
-					APIKey:  getEnv("STRIPE_API_KEY", ""),
-					Timeout: getDurationEnv("STRIPE_TIMEOUT", 30*time.Second),
+					Environment:    getEnv("STRIPE_ENVIRONMENT", PaymentSandbox),
+					BaseURL:        getEnv("STRIPE_BASE_URL", "https://api.stripe.com/v1"),
+					APIKey:         getEnv("STRIPE_API_KEY", ""),
+					PublishableKey: getEnv("STRIPE_PUBLISHABLE_KEY", ""),
+					Timeout:        getDurationEnv("STRIPE_TIMEOUT", 30*time.Second),
 				},
 				PayPal: PayPalConfig{
-					BaseURL:      getEnv("PAYPAL_BASE_URL", "https://api.paypal.com"),
+					Environment:  paypalEnvironment,
+					BaseURL:      getEnv("PAYPAL_BASE_URL", paypalBaseURLs[paypalEnvironment]),
 					ClientID:     getEnv("PAYPAL_CLIENT_ID", ""),
 					ClientSecret: getEnv("PAYPAL_CLIENT_SECRET", ""),
 					Timeout:      getDurationEnv("PAYPAL_TIMEOUT", 30*time.Second),
 				},
+				Currencies:              getSliceEnv("PAYMENT_CURRENCIES"),
+				CircuitFailureThreshold: getIntEnv("PAYMENT_CIRCUIT_FAILURE_THRESHOLD", 5),
+				CircuitOpenTimeout:      getDurationEnv("PAYMENT_CIRCUIT_OPEN_TIMEOUT", 30*time.Second),
+				StatusCacheTTL:          getDurationEnv("PAYMENT_STATUS_CACHE_TTL", 30*time.Second),
+				StatusCacheRedisURL:     getEnv("PAYMENT_STATUS_CACHE_REDIS_URL", ""),
+				StatusCacheRedisPrefix:  getEnv("PAYMENT_STATUS_CACHE_REDIS_PREFIX", "payment_status:"),
+				StatusMaxWait:           getDurationEnv("PAYMENT_STATUS_MAX_WAIT", 30*time.Second),
+				StatusRecheckInterval:   getDurationEnv("PAYMENT_STATUS_RECHECK_INTERVAL", 5*time.Second),
 			},
 			Notification: NotificationConfig{
 				Email: EmailConfig{
-					BaseURL:   getEnv("EMAIL_SERVICE_URL", "https://api.mailgun.net/v3"),
-					APIKey:    getEnv("EMAIL_API_KEY", ""),
-					FromEmail: getEnv("EMAIL_FROM", "noreply@boilerplate.com"),
-					Timeout:   getDurationEnv("EMAIL_TIMEOUT", 30*time.Second),
+					BaseURL:                getEnv("EMAIL_SERVICE_URL", "https://api.mailgun.net/v3"),
+					APIKey:                 getEnv("EMAIL_API_KEY", ""),
+					FromEmail:              getEnv("EMAIL_FROM", "noreply@boilerplate.com"),
+					Timeout:                getDurationEnv("EMAIL_TIMEOUT", 30*time.Second),
+					MaxAttachmentsSize:     int64(getIntEnv("EMAIL_MAX_ATTACHMENTS_SIZE", 10<<20)),
+					MaxAttachments:         getIntEnv("EMAIL_MAX_ATTACHMENTS", 10),
+					AllowedAttachmentTypes: getSliceEnv("EMAIL_ALLOWED_ATTACHMENT_TYPES"),
 				},
 				SMS: SMSConfig{
 					BaseURL:    getEnv("SMS_SERVICE_URL", "https://api.twilio.com/2010-04-01"),
@@ -167,9 +1083,17 @@ func LoadConfig() *Config {
 					FromNumber: getEnv("SMS_FROM", "+1234567890"),
 					Timeout:    getDurationEnv("SMS_TIMEOUT", 30*time.Second),
 				},
+				Workers:            getIntEnv("NOTIFICATION_WORKERS", 4),
+				QueueSize:          getIntEnv("NOTIFICATION_QUEUE_SIZE", 100),
+				QueuePolicy:        getEnv("NOTIFICATION_QUEUE_POLICY", "block"),
+				BulkChunkSize:      getIntEnv("NOTIFICATION_BULK_CHUNK_SIZE", 100),
+				StatusSyncInterval: getDurationEnv("NOTIFICATION_STATUS_SYNC_INTERVAL", 15*time.Minute),
+				StatusSyncWindow:   getDurationEnv("NOTIFICATION_STATUS_SYNC_WINDOW", 72*time.Hour),
+				DefaultLocale:      getEnv("NOTIFICATION_DEFAULT_LOCALE", "en"),
 			},
 			FileStorage: FileStorageConfig{
-				Provider: getEnv("FILE_STORAGE_PROVIDER", "local"),
+				Provider:  getEnv("FILE_STORAGE_PROVIDER", "local"),
+				PublicURL: getEnv("FILE_STORAGE_PUBLIC_URL", ""),
 				S3: S3Config{
 					Region:          getEnv("AWS_REGION", "us-east-1"),
 					Bucket:          getEnv("AWS_S3_BUCKET", ""),
@@ -178,13 +1102,387 @@ func LoadConfig() *Config {
 					Endpoint:        getEnv("AWS_S3_ENDPOINT", ""),
 				},
 				Local: LocalStorageConfig{
-					BasePath: getEnv("LOCAL_STORAGE_PATH", "./uploads"),
+					BasePath:   getEnv("LOCAL_STORAGE_PATH", "./uploads"),
+					SigningKey: getEnv("LOCAL_STORAGE_SIGNING_KEY", ""),
 				},
 			},
+			Captcha: CaptchaConfig{
+				Provider:         getEnv("CAPTCHA_PROVIDER", ""),
+				SecretKey:        getEnv("CAPTCHA_SECRET_KEY", ""),
+				MinScore:         getFloatEnv("CAPTCHA_MIN_SCORE", 0.5),
+				Mode:             getEnv("CAPTCHA_MODE", "off"),
+				FailureThreshold: getIntEnv("CAPTCHA_FAILURE_THRESHOLD", 5),
+				FailureWindow:    getDurationEnv("CAPTCHA_FAILURE_WINDOW", 15*time.Minute),
+				Timeout:          getDurationEnv("CAPTCHA_TIMEOUT", 10*time.Second),
+			},
+			Fraud: FraudConfig{
+				Provider:          getEnv("FRAUD_PROVIDER", ""),
+				BaseURL:           getEnv("FRAUD_SERVICE_URL", ""),
+				APIKey:            getEnv("FRAUD_API_KEY", ""),
+				Timeout:           getDurationEnv("FRAUD_TIMEOUT", 5*time.Second),
+				BlockThreshold:    getFloatEnv("FRAUD_BLOCK_THRESHOLD", 80),
+				ReviewThreshold:   getFloatEnv("FRAUD_REVIEW_THRESHOLD", 50),
+				HighAmount:        getFloatEnv("FRAUD_HIGH_AMOUNT", 1000),
+				NewAccountAge:     getDurationEnv("FRAUD_NEW_ACCOUNT_AGE", 24*time.Hour),
+				MaxItems:          getIntEnv("FRAUD_MAX_ITEMS", 20),
+				BlockedCountries:  getSliceEnv("FRAUD_BLOCKED_COUNTRIES"),
+				DisposableDomains: getSliceEnv("FRAUD_DISPOSABLE_DOMAINS"),
+			},
+			Geolocation: GeolocationConfig{
+				Provider:  getEnv("GEOLOCATION_PROVIDER", ""),
+				BaseURL:   getEnv("GEOLOCATION_BASE_URL", "http://ip-api.com"),
+				APIKey:    getEnv("GEOLOCATION_API_KEY", ""),
+				Timeout:   getDurationEnv("GEOLOCATION_TIMEOUT", 2*time.Second),
+				Requests:  getBoolEnv("GEOLOCATION_REQUESTS", false),
+				CacheTTL:  getDurationEnv("GEOLOCATION_CACHE_TTL", time.Hour),
+				CacheSize: getIntEnv("GEOLOCATION_CACHE_SIZE", 10000),
+			},
+			Scanner: ScannerConfig{
+				Provider:      getEnv("SCANNER_PROVIDER", ""),
+				ClamAVAddress: getEnv("CLAMAV_ADDRESS", "localhost:3310"),
+				Timeout:       getDurationEnv("SCANNER_TIMEOUT", 5*time.Minute),
+			},
 		},
 	}
 }
 
+// Validate reports settings that cannot be acted upon.
+func (c *Config) Validate() error {
+	switch c.Env {
+	case EnvDevelopment, EnvStaging, EnvProduction:
+	default:
+		return fmt.Errorf("unsupported ENV: %q", c.Env)
+	}
+	if err := c.validatePayment(); err != nil {
+		return err
+	}
+
+	switch c.Providers.Captcha.Mode {
+	case "off", "always", "adaptive":
+	default:
+		return fmt.Errorf("unsupported CAPTCHA_MODE: %q", c.Providers.Captcha.Mode)
+	}
+
+	switch c.Providers.Notification.QueuePolicy {
+	case "block", "drop":
+	default:
+		return fmt.Errorf("unsupported NOTIFICATION_QUEUE_POLICY: %q", c.Providers.Notification.QueuePolicy)
+	}
+	if notification := c.Providers.Notification; notification.StatusSyncInterval > 0 && notification.StatusSyncWindow <= 0 {
+		return fmt.Errorf("NOTIFICATION_STATUS_SYNC_WINDOW must be positive when NOTIFICATION_STATUS_SYNC_INTERVAL is set")
+	}
+
+	switch c.Providers.FileStorage.Provider {
+	case "local", "s3":
+	default:
+		return fmt.Errorf("unsupported FILE_STORAGE_PROVIDER: %q", c.Providers.FileStorage.Provider)
+	}
+
+	for prefix, bucket := range map[string]RateLimitBucket{"RATE_LIMIT": c.Server.RateLimit.Anonymous, "RATE_LIMIT_AUTHENTICATED": c.Server.RateLimit.Authenticated} {
+		if bucket.RequestsPerSecond > 0 && bucket.SoftRequestsPerSecond >= bucket.RequestsPerSecond {
+			return fmt.Errorf("%s_SOFT_RPS must be below %s_RPS", prefix, prefix)
+		}
+	}
+
+	if (c.Legal.TermsVersion != "" && c.Legal.TermsURL == "") || (c.Legal.PrivacyVersion != "" && c.Legal.PrivacyURL == "") {
+		return fmt.Errorf("LEGAL_TERMS_URL and LEGAL_PRIVACY_URL are required with their version")
+	}
+
+	if c.Startup.InitialBackoff <= 0 || c.Startup.MaxBackoff < c.Startup.InitialBackoff {
+		return fmt.Errorf("STARTUP_RETRY_INITIAL must be positive and at most STARTUP_RETRY_MAX")
+	}
+	if c.Startup.CheckTimeout <= 0 {
+		return fmt.Errorf("STARTUP_CHECK_TIMEOUT must be positive")
+	}
+
+	if c.JWT.AccessTokenMaxAge <= 0 {
+		return fmt.Errorf("JWT_ACCESS_TOKEN_MAX_AGE must be positive")
+	}
+	if c.JWT.ImpersonationTTL <= 0 {
+		return fmt.Errorf("JWT_IMPERSONATION_TTL must be positive")
+	}
+
+	if db := c.Database; db.PoolAutoscale && (db.MaxOpenConns <= 0 || db.PoolMaxOpen < db.MaxOpenConns) {
+		return fmt.Errorf("DB_POOL_AUTOSCALE needs a positive DB_MAX_OPEN_CONNS no greater than DB_POOL_MAX_OPEN_CONNS")
+	}
+
+	if c.Probes.Interval > 0 && c.Probes.Timeout <= 0 {
+		return fmt.Errorf("HEALTH_PROBE_TIMEOUT must be positive when HEALTH_PROBE_INTERVAL is set")
+	}
+
+	// The username column holds at most 50 characters
+	if c.Username.MinLength < 1 || c.Username.MaxLength < c.Username.MinLength || c.Username.MaxLength > 50 {
+		return fmt.Errorf("USERNAME_MIN_LENGTH must be at least 1 and USERNAME_MAX_LENGTH between it and 50")
+	}
+	if _, err := regexp.Compile(c.Username.Pattern); err != nil {
+		return fmt.Errorf("USERNAME_PATTERN is not a valid regular expression: %w", err)
+	}
+	// bcrypt hashes at most 72 bytes
+	if c.Password.MinLength < 1 || c.Password.MinLength > 72 {
+		return fmt.Errorf("PASSWORD_MIN_LENGTH must be between 1 and 72")
+	}
+	if c.Login.Enabled && c.Login.History <= 0 {
+		return fmt.Errorf("LOGIN_ALERTS_HISTORY must be positive")
+	}
+	if c.Stats.CacheTTL < 0 {
+		return fmt.Errorf("STATS_CACHE_TTL must not be negative")
+	}
+	for name, budget := range map[string]ProviderBudget{"PAYMENT": c.Budgets.Payment, "SMS": c.Budgets.SMS, "EMAIL": c.Budgets.Email} {
+		if budget.Daily < 0 || budget.CostPerCall < 0 {
+			return fmt.Errorf("BUDGET_%s_DAILY and BUDGET_%s_COST must not be negative", name, name)
+		}
+	}
+	if c.Budgets.WarnAt <= 0 || c.Budgets.WarnAt > 1 {
+		return fmt.Errorf("BUDGET_WARN_AT must be greater than 0 and at most 1")
+	}
+	if c.Budgets.FlushInterval <= 0 {
+		return fmt.Errorf("BUDGET_FLUSH_INTERVAL must be positive")
+	}
+	if c.Sagas.RecoveryInterval > 0 && c.Sagas.StaleAfter <= 0 {
+		return fmt.Errorf("SAGA_STALE_AFTER must be positive")
+	}
+	switch c.Locks.Backend {
+	case "postgres", "memory":
+	case "redis":
+		if c.Locks.RedisURL == "" {
+			return fmt.Errorf("LOCK_REDIS_URL is required when LOCK_BACKEND is redis")
+		}
+	default:
+		return fmt.Errorf("LOCK_BACKEND must be postgres, redis or memory")
+	}
+	if c.Locks.TTL <= 0 || c.Locks.Wait < 0 {
+		return fmt.Errorf("LOCK_TTL must be positive and LOCK_WAIT must not be negative")
+	}
+	if header := c.Server.PlatformHeader(); header != "" && !headerName.MatchString(header) {
+		return fmt.Errorf("SERVER_TRUSTED_PLATFORM must be cloudflare, appengine, flyio or a header name, got %q", c.Server.TrustedPlatform)
+	}
+	if c.Server.DrainPeriod < 0 {
+		return fmt.Errorf("SHUTDOWN_DRAIN_PERIOD must not be negative")
+	}
+	if c.Locks.LeaderRenew <= 0 || c.Locks.LeaderRenew >= c.Locks.LeaderTTL {
+		return fmt.Errorf("LEADER_RENEW_INTERVAL must be positive and shorter than LEADER_TTL")
+	}
+	if c.Reports.RollupInterval > 0 && c.Reports.RollupDays < 1 {
+		return fmt.Errorf("REPORTS_ROLLUP_DAYS must be positive")
+	}
+	if c.Exports.ChunkSize < 1 || c.Exports.PartSize < 1 || c.Exports.DownloadTTL <= 0 || c.Exports.ArchiveTTL <= 0 {
+		return fmt.Errorf("EXPORT_CHUNK_SIZE, EXPORT_PART_SIZE, EXPORT_DOWNLOAD_TTL and EXPORT_ARCHIVE_TTL must be positive")
+	}
+
+	switch c.Server.AccessLogFormat {
+	case "common", "combined":
+	default:
+		return fmt.Errorf("unsupported ACCESS_LOG_FORMAT: %q", c.Server.AccessLogFormat)
+	}
+
+	switch c.Server.Network {
+	case "tcp", "systemd":
+	case "unix":
+		if c.Server.SocketPath == "" {
+			return fmt.Errorf("SERVER_SOCKET_PATH is required when SERVER_NETWORK is unix")
+		}
+	default:
+		return fmt.Errorf("unsupported SERVER_NETWORK: %q", c.Server.Network)
+	}
+
+	if tls := c.Server.TLS; tls.Enabled() || tls.KeyFile != "" {
+		if (tls.CertFile == "") != (tls.KeyFile == "") {
+			return fmt.Errorf("SERVER_TLS_CERT_FILE and SERVER_TLS_KEY_FILE must be set together")
+		}
+		if tls.CertFile != "" && len(tls.AutocertDomains) > 0 {
+			return fmt.Errorf("SERVER_TLS_CERT_FILE and SERVER_TLS_AUTOCERT_DOMAINS are mutually exclusive")
+		}
+		if c.Server.H2C {
+			return fmt.Errorf("SERVER_H2C is only supported without TLS")
+		}
+	} else if tls.RedirectAddr != "" {
+		return fmt.Errorf("SERVER_TLS_REDIRECT_ADDR requires TLS")
+	}
+
+	switch c.Logging.Sink {
+	case "":
+	case "loki", "elasticsearch":
+		if c.Logging.SinkURL == "" {
+			return fmt.Errorf("LOG_SINK_URL is required when LOG_SINK is %q", c.Logging.Sink)
+		}
+	default:
+		return fmt.Errorf("unsupported LOG_SINK: %q", c.Logging.Sink)
+	}
+
+	// Each bucket multiplies the series of every route
+	if c.Metrics.UserBuckets < 0 || c.Metrics.UserBuckets > 100 {
+		return fmt.Errorf("METRICS_USER_BUCKETS must be between 0 and 100, got %d", c.Metrics.UserBuckets)
+	}
+
+	if slo := c.Metrics.SLO; slo.Enabled {
+		for name, target := range map[string]float64{
+			"SLO_AVAILABILITY_TARGET": slo.AvailabilityTarget,
+			"SLO_LATENCY_TARGET":      slo.LatencyTarget,
+		} {
+			if target <= 0 || target >= 1 {
+				return fmt.Errorf("%s must be between 0 and 1 exclusive, got %g", name, target)
+			}
+		}
+		if slo.LatencyThreshold <= 0 {
+			return fmt.Errorf("SLO_LATENCY_THRESHOLD must be positive")
+		}
+	}
+
+	if c.Static.Dir != "" && !strings.HasPrefix(c.Static.Path, "/") {
+		return fmt.Errorf("STATIC_PATH must start with /, got %q", c.Static.Path)
+	}
+
+	if geo := c.Providers.Geolocation; geo.Requests && (geo.CacheSize <= 0 || geo.CacheTTL <= 0) {
+		return fmt.Errorf("GEOLOCATION_CACHE_SIZE and GEOLOCATION_CACHE_TTL must be positive with GEOLOCATION_REQUESTS")
+	}
+
+	switch c.Providers.Scanner.Provider {
+	case "", "clamav":
+	default:
+		return fmt.Errorf("unsupported SCANNER_PROVIDER: %q", c.Providers.Scanner.Provider)
+	}
+
+	if c.Email.ConfirmTTL <= 0 {
+		return fmt.Errorf("EMAIL_CHANGE_TTL must be positive")
+	}
+	if c.Orgs.InvitationTTL <= 0 {
+		return fmt.Errorf("ORG_INVITATION_TTL must be positive")
+	}
+	// Signatures travel in the link, so there is nothing to sign without one
+	if c.Orgs.SigningKey != "" && c.Orgs.InvitationURL == "" {
+		return fmt.Errorf("ORG_INVITATION_SIGNING_KEY requires ORG_INVITATION_URL")
+	}
+	if c.SSO.LoginTTL <= 0 {
+		return fmt.Errorf("SSO_LOGIN_TTL must be positive")
+	}
+	if c.SSO.HTTPTimeout <= 0 {
+		return fmt.Errorf("SSO_HTTP_TIMEOUT must be positive")
+	}
+	if err := c.Cookies.validate(); err != nil {
+		return err
+	}
+
+	// S3 rejects multipart parts other than the last below 5 MiB
+	if c.Upload.PartSize < 5<<20 {
+		return fmt.Errorf("UPLOAD_PART_SIZE must be at least 5 MiB, got %d", c.Upload.PartSize)
+	}
+
+	return nil
+}
+
+func (c AuthCookieConfig) validate() error {
+	switch c.Mode {
+	case AuthModeHeader:
+		return nil
+	case AuthModeCookie, AuthModeBoth:
+	default:
+		return fmt.Errorf("unsupported AUTH_MODE: %q", c.Mode)
+	}
+
+	if len(c.Secret) < 32 {
+		return fmt.Errorf("AUTH_COOKIE_SECRET must be at least 32 characters in AUTH_MODE=%s", c.Mode)
+	}
+	if c.Name == "" || c.CSRFName == "" || c.Name == c.CSRFName {
+		return fmt.Errorf("AUTH_COOKIE_NAME and AUTH_CSRF_COOKIE_NAME must be set and differ")
+	}
+	switch c.SameSite {
+	case "lax", "strict":
+	case "none":
+		// Browsers drop SameSite=None cookies without Secure
+		if !c.Secure {
+			return fmt.Errorf("AUTH_COOKIE_SAMESITE=none requires AUTH_COOKIE_SECURE=true")
+		}
+	default:
+		return fmt.Errorf("unsupported AUTH_COOKIE_SAMESITE: %q", c.SameSite)
+	}
+	return nil
+}
+
+// Environment returns the environment of the selected payment provider
+func (c PaymentConfig) Environment() string {
+	if c.Provider == "paypal" {
+		return c.PayPal.Environment
+	}
+	return c.Stripe.Environment
+}
+
+// validatePayment guards against charging real money by mistake: a provider
+// environment must match its credentials, and development refuses live ones.
+func (c *Config) validatePayment() error {
+	stripe, paypal := c.Providers.Payment.Stripe, c.Providers.Payment.PayPal
+	for name, environment := range map[string]string{"STRIPE_ENVIRONMENT": stripe.Environment, "PAYPAL_ENVIRONMENT": paypal.Environment} {
+		if environment != PaymentSandbox && environment != PaymentLive {
+			return fmt.Errorf("unsupported %s: %q", name, environment)
+		}
+	}
+
+	if keyEnvironment := stripeKeyEnvironment(stripe.APIKey); keyEnvironment != "" && keyEnvironment != stripe.Environment {
+		return fmt.Errorf("STRIPE_API_KEY is a %s key but STRIPE_ENVIRONMENT is %q", keyEnvironment, stripe.Environment)
+	}
+	// The publishable key is served to anyone, so it must not be a secret one
+	if stripe.PublishableKey != "" {
+		keyEnvironment := stripePublishableKeyEnvironment(stripe.PublishableKey)
+		if keyEnvironment == "" {
+			return fmt.Errorf("STRIPE_PUBLISHABLE_KEY must be a publishable key (pk_test_ or pk_live_)")
+		}
+		if keyEnvironment != stripe.Environment {
+			return fmt.Errorf("STRIPE_PUBLISHABLE_KEY is a %s key but STRIPE_ENVIRONMENT is %q", keyEnvironment, stripe.Environment)
+		}
+	}
+	for _, currency := range c.Providers.Payment.Currencies {
+		if len(currency) != 3 {
+			return fmt.Errorf("PAYMENT_CURRENCIES must hold ISO 4217 codes, got %q", currency)
+		}
+	}
+	if paypalHost := hostOf(paypal.BaseURL); paypalHost != "" {
+		sandboxHost := strings.HasSuffix(paypalHost, ".sandbox.paypal.com")
+		liveHost := paypalHost == "api.paypal.com" || paypalHost == "api-m.paypal.com"
+		if (paypal.Environment == PaymentSandbox && liveHost) || (paypal.Environment == PaymentLive && sandboxHost) {
+			return fmt.Errorf("PAYPAL_BASE_URL %q does not match PAYPAL_ENVIRONMENT %q", paypal.BaseURL, paypal.Environment)
+		}
+	}
+
+	if c.Env == EnvDevelopment {
+		if stripe.Environment == PaymentLive || paypal.Environment == PaymentLive {
+			return fmt.Errorf("live payment environments are refused when ENV=%s", EnvDevelopment)
+		}
+	}
+	return nil
+}
+
+// stripeKeyEnvironment returns the environment a Stripe key belongs to, or ""
+// when the key does not say
+func stripeKeyEnvironment(key string) string {
+	switch {
+	case strings.HasPrefix(key, "sk_live_"), strings.HasPrefix(key, "rk_live_"):
+		return PaymentLive
+	case strings.HasPrefix(key, "sk_test_"), strings.HasPrefix(key, "rk_test_"):
+		return PaymentSandbox
+	}
+	return ""
+}
+
+// stripePublishableKeyEnvironment returns the environment a Stripe
+// publishable key belongs to, or "" when it is not a publishable key
+func stripePublishableKeyEnvironment(key string) string {
+	switch {
+	case strings.HasPrefix(key, "pk_live_"):
+		return PaymentLive
+	case strings.HasPrefix(key, "pk_test_"):
+		return PaymentSandbox
+	}
+	return ""
+}
+
+// hostOf returns the lowercase host of a URL, or "" if it has none
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -202,6 +1500,16 @@ func getIntEnv(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+		fmt.Printf("Warning: invalid value for %s, using default\n", key)
+	}
+	return defaultValue
+}
+
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -211,3 +1519,75 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+		fmt.Printf("Warning: invalid boolean value for %s, using default\n", key)
+	}
+	return defaultValue
+}
+
+// getFileModeEnv parses octal permissions such as "0660".
+func getFileModeEnv(key string, defaultValue os.FileMode) os.FileMode {
+	if value := os.Getenv(key); value != "" {
+		if mode, err := strconv.ParseUint(value, 8, 32); err == nil && mode <= 0o777 {
+			return os.FileMode(mode)
+		}
+		fmt.Printf("Warning: invalid file mode value for %s, using default\n", key)
+	}
+	return defaultValue
+}
+
+// getSliceEnv parses comma separated values, ignoring empty entries.
+func getSliceEnv(key string) []string {
+	var result []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getGroupsEnv parses values in the form "/prefix1=a,b;/prefix2=c".
+func getGroupsEnv(key string) map[string][]string {
+	result := make(map[string][]string)
+	for _, group := range strings.Split(os.Getenv(key), ";") {
+		if group = strings.TrimSpace(group); group == "" {
+			continue
+		}
+		prefix, items, ok := strings.Cut(group, "=")
+		if prefix = strings.TrimSpace(prefix); !ok || prefix == "" {
+			fmt.Printf("Warning: invalid entry in %s, skipping\n", key)
+			continue
+		}
+		for _, item := range strings.Split(items, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result[prefix] = append(result[prefix], item)
+			}
+		}
+	}
+	return result
+}
+
+// getMapEnv parses values in the form "key1:value1,key2:value2".
+func getMapEnv(key string) map[string]string {
+	result := make(map[string]string)
+	value := os.Getenv(key)
+	if value == "" {
+		return result
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			fmt.Printf("Warning: invalid entry in %s, skipping\n", key)
+			continue
+		}
+		result[parts[0]] = parts[1]
+	}
+	return result
+}
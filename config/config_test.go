@@ -0,0 +1,149 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePaymentEnvironment(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		stripe  StripeConfig
+		paypal  PayPalConfig
+		wantErr string
+	}{
+		{
+			name:   "sandbox in development",
+			env:    EnvDevelopment,
+			stripe: StripeConfig{Environment: PaymentSandbox, APIKey: "sk_test_123"},
+			paypal: PayPalConfig{Environment: PaymentSandbox, BaseURL: "https://api.sandbox.paypal.com"},
+		},
+		{
+			name:   "live in production",
+			env:    EnvProduction,
+			stripe: StripeConfig{Environment: PaymentLive, APIKey: "sk_live_123"},
+			paypal: PayPalConfig{Environment: PaymentLive, BaseURL: "https://api-m.paypal.com"},
+		},
+		{
+			name:    "live key in development",
+			env:     EnvDevelopment,
+			stripe:  StripeConfig{Environment: PaymentLive, APIKey: "sk_live_123"},
+			paypal:  PayPalConfig{Environment: PaymentSandbox},
+			wantErr: "live payment environments are refused when ENV=development",
+		},
+		{
+			name:    "live key in sandbox",
+			env:     EnvProduction,
+			stripe:  StripeConfig{Environment: PaymentSandbox, APIKey: "rk_live_123"},
+			paypal:  PayPalConfig{Environment: PaymentSandbox},
+			wantErr: `STRIPE_API_KEY is a live key but STRIPE_ENVIRONMENT is "sandbox"`,
+		},
+		{
+			name:    "test key in live",
+			env:     EnvProduction,
+			stripe:  StripeConfig{Environment: PaymentLive, APIKey: "sk_test_123"},
+			paypal:  PayPalConfig{Environment: PaymentSandbox},
+			wantErr: `STRIPE_API_KEY is a sandbox key but STRIPE_ENVIRONMENT is "live"`,
+		},
+		{
+			name:    "paypal live endpoint in sandbox",
+			env:     EnvStaging,
+			stripe:  StripeConfig{Environment: PaymentSandbox},
+			paypal:  PayPalConfig{Environment: PaymentSandbox, BaseURL: "https://api.paypal.com"},
+			wantErr: `PAYPAL_BASE_URL "https://api.paypal.com" does not match PAYPAL_ENVIRONMENT "sandbox"`,
+		},
+		{
+			name:    "unknown environment",
+			env:     EnvProduction,
+			stripe:  StripeConfig{Environment: "test"},
+			paypal:  PayPalConfig{Environment: PaymentSandbox},
+			wantErr: `unsupported STRIPE_ENVIRONMENT: "test"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Env: tt.env}
+			cfg.Providers.Payment.Stripe = tt.stripe
+			cfg.Providers.Payment.PayPal = tt.paypal
+
+			err := cfg.validatePayment()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPaymentConfigEnvironment(t *testing.T) {
+	cfg := PaymentConfig{
+		Provider: "paypal",
+		Stripe:   StripeConfig{Environment: PaymentSandbox},
+		PayPal:   PayPalConfig{Environment: PaymentLive},
+	}
+	assert.Equal(t, PaymentLive, cfg.Environment())
+
+	cfg.Provider = "stripe"
+	assert.Equal(t, PaymentSandbox, cfg.Environment())
+}
+
+func TestAuthCookieConfigValidate(t *testing.T) {
+	secret := "0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name    string
+		cfg     AuthCookieConfig
+		wantErr string
+	}{
+		{name: "header mode needs nothing", cfg: AuthCookieConfig{Mode: AuthModeHeader}},
+		{
+			name: "cookie mode",
+			cfg:  AuthCookieConfig{Mode: AuthModeCookie, Name: "session", CSRFName: "csrf_token", SameSite: "lax", Secret: secret},
+		},
+		{
+			name:    "unknown mode",
+			cfg:     AuthCookieConfig{Mode: "token"},
+			wantErr: `unsupported AUTH_MODE: "token"`,
+		},
+		{
+			name:    "short secret",
+			cfg:     AuthCookieConfig{Mode: AuthModeBoth, Name: "session", CSRFName: "csrf_token", SameSite: "lax", Secret: "short"},
+			wantErr: "AUTH_COOKIE_SECRET must be at least 32 characters in AUTH_MODE=both",
+		},
+		{
+			name:    "SameSite none without Secure",
+			cfg:     AuthCookieConfig{Mode: AuthModeCookie, Name: "session", CSRFName: "csrf_token", SameSite: "none", Secret: secret},
+			wantErr: "AUTH_COOKIE_SAMESITE=none requires AUTH_COOKIE_SECURE=true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestServerConfigPlatformHeader(t *testing.T) {
+	tests := []struct {
+		platform string
+		header   string
+	}{
+		{"", ""},
+		{"cloudflare", "CF-Connecting-IP"},
+		{"AppEngine", "X-Appengine-Remote-Addr"},
+		{"flyio", "Fly-Client-IP"},
+		{"True-Client-IP", "True-Client-IP"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.header, ServerConfig{TrustedPlatform: tt.platform}.PlatformHeader(), tt.platform)
+	}
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RedactedValue replaces secrets in exported configuration. A profile setting
+// with this value keeps the current secret, so exported profiles can be
+// applied again.
+const RedactedValue = "[REDACTED]"
+
+// setting is a provider setting that can be changed at runtime, named after
+// its environment variable
+type setting struct {
+	section string
+	secret  bool
+	get     func(*ProvidersConfig) string
+	set     func(*ProvidersConfig, string) error
+}
+
+// providerSettings are the settings of the providers that can be replaced
+// while the server runs: payment, email and SMS. Other settings need a
+// restart.
+var providerSettings = map[string]setting{
+	"PAYMENT_PROVIDER":       stringSetting("payment", false, func(c *ProvidersConfig) *string { return &c.Payment.Provider }),
+	"STRIPE_ENVIRONMENT":     stringSetting("payment", false, func(c *ProvidersConfig) *string { return &c.Payment.Stripe.Environment }),
+	"STRIPE_BASE_URL":        stringSetting("payment", false, func(c *ProvidersConfig) *string { return &c.Payment.Stripe.BaseURL }),
+	"STRIPE_API_KEY":         stringSetting("payment", true, func(c *ProvidersConfig) *string { return &c.Payment.Stripe.APIKey }),
+	"STRIPE_PUBLISHABLE_KEY": stringSetting("payment", false, func(c *ProvidersConfig) *string { return &c.Payment.Stripe.PublishableKey }),
+	"STRIPE_TIMEOUT":         durationSetting("payment", func(c *ProvidersConfig) *time.Duration { return &c.Payment.Stripe.Timeout }),
+	"PAYPAL_ENVIRONMENT":     stringSetting("payment", false, func(c *ProvidersConfig) *string { return &c.Payment.PayPal.Environment }),
+	"PAYPAL_BASE_URL":        stringSetting("payment", false, func(c *ProvidersConfig) *string { return &c.Payment.PayPal.BaseURL }),
+	"PAYPAL_CLIENT_ID":       stringSetting("payment", false, func(c *ProvidersConfig) *string { return &c.Payment.PayPal.ClientID }),
+	"PAYPAL_CLIENT_SECRET":   stringSetting("payment", true, func(c *ProvidersConfig) *string { return &c.Payment.PayPal.ClientSecret }),
+	"PAYPAL_TIMEOUT":         durationSetting("payment", func(c *ProvidersConfig) *time.Duration { return &c.Payment.PayPal.Timeout }),
+	"EMAIL_SERVICE_URL":      stringSetting("email", false, func(c *ProvidersConfig) *string { return &c.Notification.Email.BaseURL }),
+	"EMAIL_API_KEY":          stringSetting("email", true, func(c *ProvidersConfig) *string { return &c.Notification.Email.APIKey }),
+	"EMAIL_FROM":             stringSetting("email", false, func(c *ProvidersConfig) *string { return &c.Notification.Email.FromEmail }),
+	"EMAIL_TIMEOUT":          durationSetting("email", func(c *ProvidersConfig) *time.Duration { return &c.Notification.Email.Timeout }),
+	"SMS_SERVICE_URL":        stringSetting("sms", false, func(c *ProvidersConfig) *string { return &c.Notification.SMS.BaseURL }),
+	"SMS_API_KEY":            stringSetting("sms", true, func(c *ProvidersConfig) *string { return &c.Notification.SMS.APIKey }),
+	"SMS_FROM":               stringSetting("sms", false, func(c *ProvidersConfig) *string { return &c.Notification.SMS.FromNumber }),
+	"SMS_TIMEOUT":            durationSetting("sms", func(c *ProvidersConfig) *time.Duration { return &c.Notification.SMS.Timeout }),
+}
+
+func stringSetting(section string, secret bool, field func(*ProvidersConfig) *string) setting {
+	return setting{
+		section: section,
+		secret:  secret,
+		get:     func(c *ProvidersConfig) string { return *field(c) },
+		set: func(c *ProvidersConfig, value string) error {
+			*field(c) = value
+			return nil
+		},
+	}
+}
+
+func durationSetting(section string, field func(*ProvidersConfig) *time.Duration) setting {
+	return setting{
+		section: section,
+		get:     func(c *ProvidersConfig) string { return field(c).String() },
+		set: func(c *ProvidersConfig, value string) error {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("must be a positive duration, got %q", value)
+			}
+			*field(c) = d
+			return nil
+		},
+	}
+}
+
+// Settings returns the provider settings that can be changed at runtime,
+// with secrets redacted
+func (c ProvidersConfig) Settings() map[string]string {
+	settings := make(map[string]string, len(providerSettings))
+	for key, s := range providerSettings {
+		settings[key] = redact(s, s.get(&c))
+	}
+	return settings
+}
+
+// WithSettings returns a copy of the configuration with the provider
+// settings applied, and the keys of the settings they changed in order.
+// Settings that are redacted keep their current value. A provider whose
+// settings change must be left with its credentials.
+func (c ProvidersConfig) WithSettings(settings map[string]string) (ProvidersConfig, []string, error) {
+	applied := c
+	var changed []string
+	sections := map[string]bool{}
+	for key, value := range settings {
+		s, ok := providerSettings[key]
+		if !ok {
+			return c, nil, fmt.Errorf("%s cannot be changed at runtime", key)
+		}
+		if value == RedactedValue || value == s.get(&c) {
+			continue
+		}
+		if err := s.set(&applied, value); err != nil {
+			return c, nil, fmt.Errorf("%s %w", key, err)
+		}
+		changed = append(changed, key)
+		sections[s.section] = true
+	}
+
+	for section := range sections {
+		if err := applied.requireCredentials(section); err != nil {
+			return c, nil, err
+		}
+	}
+	sort.Strings(changed)
+	return applied, changed, nil
+}
+
+// requireCredentials reports a provider of the section left without the
+// credentials it needs
+func (c ProvidersConfig) requireCredentials(section string) error {
+	payment, notification := c.Payment, c.Notification
+	switch {
+	case section == "payment" && payment.Provider == "stripe" && payment.Stripe.APIKey == "":
+		return fmt.Errorf("STRIPE_API_KEY is required")
+	case section == "payment" && payment.Provider == "paypal" && (payment.PayPal.ClientID == "" || payment.PayPal.ClientSecret == ""):
+		return fmt.Errorf("PAYPAL_CLIENT_ID and PAYPAL_CLIENT_SECRET are required")
+	case section == "payment" && payment.Provider != "stripe" && payment.Provider != "paypal":
+		return fmt.Errorf("unsupported PAYMENT_PROVIDER: %q", payment.Provider)
+	case section == "email" && (notification.Email.BaseURL == "" || notification.Email.APIKey == ""):
+		return fmt.Errorf("EMAIL_SERVICE_URL and EMAIL_API_KEY are required")
+	case section == "sms" && (notification.SMS.BaseURL == "" || notification.SMS.APIKey == ""):
+		return fmt.Errorf("SMS_SERVICE_URL and SMS_API_KEY are required")
+	}
+	return nil
+}
+
+func redact(s setting, value string) string {
+	if s.secret && value != "" {
+		return RedactedValue
+	}
+	return value
+}
+
+// Redacted returns a copy of the configuration with its secrets replaced by
+// RedactedValue, for display
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	secrets := []*string{
+		&redacted.Database.Password,
+		&redacted.JWT.SecretKey,
+		&redacted.Logging.Password,
+		&redacted.Logging.APIKey,
+		&redacted.Providers.Payment.Stripe.APIKey,
+		&redacted.Providers.Payment.PayPal.ClientSecret,
+		&redacted.Providers.Notification.Email.APIKey,
+		&redacted.Providers.Notification.SMS.APIKey,
+		&redacted.Providers.Captcha.SecretKey,
+		&redacted.Providers.Fraud.APIKey,
+		&redacted.Providers.Geolocation.APIKey,
+		&redacted.Providers.FileStorage.S3.SecretAccessKey,
+		&redacted.Providers.FileStorage.Local.SigningKey,
+		&redacted.Email.SigningKey,
+		&redacted.Orgs.SigningKey,
+		&redacted.SCIM.Token,
+		&redacted.Cookies.Secret,
+	}
+	for _, secret := range secrets {
+		if *secret != "" {
+			*secret = RedactedValue
+		}
+	}
+
+	if len(c.Signing.Keys) > 0 {
+		redacted.Signing.Keys = make(map[string]string, len(c.Signing.Keys))
+		for id := range c.Signing.Keys {
+			redacted.Signing.Keys[id] = RedactedValue
+		}
+	}
+	return &redacted
+}
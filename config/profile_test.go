@@ -0,0 +1,94 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testProviders() ProvidersConfig {
+	return ProvidersConfig{
+		Payment: PaymentConfig{
+			Provider: "stripe",
+			Stripe:   StripeConfig{Environment: PaymentSandbox, APIKey: "sk_test_123", Timeout: 30 * time.Second},
+		},
+		Notification: NotificationConfig{
+			Email: EmailConfig{BaseURL: "https://api.mailgun.net/v3", APIKey: "mailgun-key", FromEmail: "noreply@example.com"},
+		},
+	}
+}
+
+func TestProvidersConfigSettings(t *testing.T) {
+	settings := testProviders().Settings()
+
+	assert.Equal(t, "stripe", settings["PAYMENT_PROVIDER"])
+	assert.Equal(t, RedactedValue, settings["STRIPE_API_KEY"])
+	assert.Equal(t, RedactedValue, settings["EMAIL_API_KEY"])
+	assert.Equal(t, "", settings["SMS_API_KEY"])
+	assert.Equal(t, "30s", settings["STRIPE_TIMEOUT"])
+}
+
+func TestProvidersConfigWithSettings(t *testing.T) {
+	current := testProviders()
+
+	applied, changed, err := current.WithSettings(map[string]string{
+		"EMAIL_SERVICE_URL": "https://api.sendgrid.com/v3",
+		"EMAIL_API_KEY":     "sendgrid-key",
+		"EMAIL_FROM":        "noreply@example.com",
+		"STRIPE_API_KEY":    RedactedValue,
+		"STRIPE_TIMEOUT":    "10s",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EMAIL_API_KEY", "EMAIL_SERVICE_URL", "STRIPE_TIMEOUT"}, changed)
+	assert.Equal(t, "sendgrid-key", applied.Notification.Email.APIKey)
+	assert.Equal(t, "sk_test_123", applied.Payment.Stripe.APIKey)
+	assert.Equal(t, 10*time.Second, applied.Payment.Stripe.Timeout)
+	assert.Equal(t, "mailgun-key", current.Notification.Email.APIKey)
+
+	// The profile round trips: exported settings change nothing
+	_, changed, err = applied.WithSettings(applied.Settings())
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+}
+
+func TestProvidersConfigWithSettings_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]string
+		wantErr  string
+	}{
+		{name: "restart needed", settings: map[string]string{"FILE_STORAGE_PROVIDER": "s3"}, wantErr: "FILE_STORAGE_PROVIDER cannot be changed at runtime"},
+		{name: "bad duration", settings: map[string]string{"SMS_TIMEOUT": "soon"}, wantErr: `SMS_TIMEOUT must be a positive duration, got "soon"`},
+		{name: "no credentials", settings: map[string]string{"PAYMENT_PROVIDER": "paypal"}, wantErr: "PAYPAL_CLIENT_ID and PAYPAL_CLIENT_SECRET are required"},
+		{name: "unknown provider", settings: map[string]string{"PAYMENT_PROVIDER": "square"}, wantErr: `unsupported PAYMENT_PROVIDER: "square"`},
+		{name: "secret removed", settings: map[string]string{"EMAIL_API_KEY": ""}, wantErr: "EMAIL_SERVICE_URL and EMAIL_API_KEY are required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := testProviders().WithSettings(tt.settings)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestConfigRedacted(t *testing.T) {
+	cfg := &Config{
+		Database:  DatabaseConfig{User: "postgres", Password: "secret"},
+		Signing:   SigningConfig{Keys: map[string]string{"partner": "hmac-key"}},
+		Providers: testProviders(),
+	}
+
+	redacted := cfg.Redacted()
+
+	assert.Equal(t, "postgres", redacted.Database.User)
+	assert.Equal(t, RedactedValue, redacted.Database.Password)
+	assert.Equal(t, RedactedValue, redacted.Signing.Keys["partner"])
+	assert.Equal(t, RedactedValue, redacted.Providers.Payment.Stripe.APIKey)
+	assert.Equal(t, "", redacted.Providers.Notification.SMS.APIKey)
+	assert.Equal(t, "secret", cfg.Database.Password)
+	assert.Equal(t, "hmac-key", cfg.Signing.Keys["partner"])
+}
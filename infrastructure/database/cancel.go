@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Reasons a query was cancelled
+const (
+	CancelTimeout  = "timeout"
+	CancelCanceled = "canceled"
+)
+
+// queryCanceled is the Postgres error code of statements cancelled by the
+// statement timeout or a cancel request
+const queryCanceled = "57014"
+
+// CancelReason tells why the query failing with err was cancelled:
+// CancelTimeout when it ran out of time, on the client or on the server,
+// CancelCanceled when its caller gave up, and "" when it was not cancelled.
+func CancelReason(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.DeadlineExceeded):
+		return CancelTimeout
+	case errors.Is(err, context.Canceled):
+		return CancelCanceled
+	}
+
+	var pqErr interface{ SQLState() string }
+	if errors.As(err, &pqErr) && pqErr.SQLState() == queryCanceled {
+		if strings.Contains(err.Error(), "statement timeout") {
+			return CancelTimeout
+		}
+		return CancelCanceled
+	}
+	return ""
+}
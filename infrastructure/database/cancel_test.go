@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancelReason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "no error", err: nil, want: ""},
+		{name: "other error", err: errors.New("connection refused"), want: ""},
+		{name: "query timeout", err: fmt.Errorf("failed to get user: %w", context.DeadlineExceeded), want: CancelTimeout},
+		{name: "caller gave up", err: context.Canceled, want: CancelCanceled},
+		{name: "statement timeout", err: &pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"}, want: CancelTimeout},
+		{name: "cancel request", err: &pq.Error{Code: "57014", Message: "canceling statement due to user request"}, want: CancelCanceled},
+		{name: "unique violation", err: &pq.Error{Code: "23505", Message: "duplicate key value"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CancelReason(tt.err))
+		})
+	}
+}
+
+func TestPostgresDB_WithQueryTimeout(t *testing.T) {
+	db := &PostgresDB{queryTimeout: time.Second}
+
+	ctx, cancel := db.WithQueryTimeout(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+
+	// An earlier deadline of the caller is kept
+	short, cancelShort := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancelShort()
+	ctx, cancel = db.WithQueryTimeout(short)
+	defer cancel()
+	deadline, _ = ctx.Deadline()
+	want, _ := short.Deadline()
+	assert.Equal(t, want, deadline)
+
+	ctx, cancel = (&PostgresDB{}).WithQueryTimeout(context.Background())
+	defer cancel()
+	_, ok = ctx.Deadline()
+	assert.False(t, ok, "no timeout when disabled")
+}
@@ -13,12 +13,20 @@ import (
 // PostgresDB wraps the database connection.
 type PostgresDB struct {
 	DB *sql.DB
+
+	queryTimeout     time.Duration
+	statementTimeout time.Duration
 }
 
 // NewPostgresConnection creates a new PostgreSQL database connection with configuration.
-func NewPostgresConnection(cfg config.DatabaseConfig) (*PostgresDB, error) {
+// It fails when the database cannot be reached before ctx is done.
+func NewPostgresConnection(ctx context.Context, cfg config.DatabaseConfig) (*PostgresDB, error) {
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode)
+	if cfg.StatementTimeout > 0 {
+		// Sent as a run-time parameter when connecting, so it holds for every session
+		dsn += fmt.Sprintf(" statement_timeout=%d", cfg.StatementTimeout.Milliseconds())
+	}
 
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
@@ -30,11 +38,32 @@ func NewPostgresConnection(cfg config.DatabaseConfig) (*PostgresDB, error) {
 	db.SetMaxIdleConns(cfg.MaxIdleConns)
 	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &PostgresDB{DB: db}, nil
+	return &PostgresDB{DB: db, queryTimeout: cfg.QueryTimeout, statementTimeout: cfg.StatementTimeout}, nil
+}
+
+// WithQueryTimeout returns a context bounding a query by the query timeout.
+// An earlier deadline already set on ctx is kept.
+func (p *PostgresDB) WithQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.queryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, p.queryTimeout)
+}
+
+// LiftStatementTimeout lets the statements of the transaction run for as long
+// as their context allows, for queries such as streams whose duration depends
+// on how fast the client reads.
+func (p *PostgresDB) LiftStatementTimeout(ctx context.Context, tx *sql.Tx) error {
+	if p.statementTimeout <= 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `SET LOCAL statement_timeout = 0`)
+	return err
 }
 
 // Close closes the database connection.
@@ -0,0 +1,173 @@
+// Package dbpool watches the database connection pool, warning when requests
+// queue for connections under load and optionally growing the pool within
+// limits.
+package dbpool
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+)
+
+// calmChecks is how many checks in a row without waits it takes to shrink a
+// grown pool by a step
+const calmChecks = 5
+
+// Pool is a connection pool, such as *sql.DB
+type Pool interface {
+	Stats() sql.DBStats
+	SetMaxOpenConns(n int)
+}
+
+// Recorder records the state of the pool, such as the metrics. waits and
+// waited count the connection requests that had to wait since the previous
+// check and how long they waited in total.
+type Recorder interface {
+	RecordDatabasePool(open, inUse, idle, maxOpen int, waits int64, waited time.Duration)
+}
+
+// Config tunes an Advisor. Without Autoscale the advisor only warns.
+type Config struct {
+	Interval  time.Duration
+	MaxWait   time.Duration
+	Autoscale bool
+	// MinOpen and MaxOpen bound the max open connections of the pool when
+	// autoscaling
+	MinOpen int
+	MaxOpen int
+}
+
+// Advisor checks the pool every interval. When the connection requests of
+// an interval waited longer than MaxWait on average, it logs a warning and,
+// with Autoscale, raises the max open connections by a quarter up to
+// MaxOpen. Once the pool has stayed calm, it lowers them back step by step
+// to MinOpen.
+type Advisor struct {
+	pool     Pool
+	cfg      Config
+	recorder Recorder
+	logger   *logger.Logger
+
+	last   sql.DBStats
+	calm   int
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewAdvisor creates an advisor of the pool
+func NewAdvisor(pool Pool, cfg Config, recorder Recorder, log *logger.Logger) *Advisor {
+	return &Advisor{
+		pool:     pool,
+		cfg:      cfg,
+		recorder: recorder,
+		logger:   log,
+		last:     pool.Stats(),
+	}
+}
+
+// Start checks the pool every interval until Stop
+func (a *Advisor) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel, a.done = cancel, make(chan struct{})
+
+	go func() {
+		defer close(a.done)
+		ticker := time.NewTicker(a.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.Check()
+			}
+		}
+	}()
+}
+
+// Stop stops the checks
+func (a *Advisor) Stop(ctx context.Context) error {
+	if a.cancel == nil {
+		return nil
+	}
+	a.cancel()
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Check samples the pool once, records it and adjusts the pool when needed
+func (a *Advisor) Check() {
+	stats := a.pool.Stats()
+	waits := stats.WaitCount - a.last.WaitCount
+	waited := stats.WaitDuration - a.last.WaitDuration
+	a.last = stats
+
+	if a.recorder != nil {
+		a.recorder.RecordDatabasePool(stats.OpenConnections, stats.InUse, stats.Idle, stats.MaxOpenConnections, waits, waited)
+	}
+
+	if waits == 0 {
+		a.calm++
+		if a.calm >= calmChecks {
+			a.calm = 0
+			a.shrink(stats)
+		}
+		return
+	}
+	a.calm = 0
+
+	average := waited / time.Duration(waits)
+	if average <= a.cfg.MaxWait {
+		return
+	}
+	entry := a.logger.WithFields(map[string]interface{}{
+		"component":      "database",
+		"waits":          waits,
+		"average_wait":   average.String(),
+		"in_use":         stats.InUse,
+		"max_open_conns": stats.MaxOpenConnections,
+	})
+	if !a.cfg.Autoscale {
+		entry.Warn("Database connection pool saturated, consider raising DB_MAX_OPEN_CONNS")
+		return
+	}
+	if stats.MaxOpenConnections >= a.cfg.MaxOpen {
+		entry.Warn("Database connection pool saturated at DB_POOL_MAX_OPEN_CONNS")
+		return
+	}
+
+	grown := min(stats.MaxOpenConnections+step(stats.MaxOpenConnections), a.cfg.MaxOpen)
+	a.pool.SetMaxOpenConns(grown)
+	entry.WithField("new_max_open_conns", grown).Warn("Database connection pool saturated, raised max open connections")
+}
+
+// shrink lowers the max open connections of a pool grown by autoscaling by a
+// step, when the connections in use fit
+func (a *Advisor) shrink(stats sql.DBStats) {
+	current := stats.MaxOpenConnections
+	if !a.cfg.Autoscale || current <= a.cfg.MinOpen {
+		return
+	}
+	shrunk := max(current-step(current), a.cfg.MinOpen)
+	if stats.InUse > shrunk {
+		return
+	}
+	a.pool.SetMaxOpenConns(shrunk)
+	a.logger.WithFields(map[string]interface{}{
+		"component":          "database",
+		"max_open_conns":     current,
+		"new_max_open_conns": shrunk,
+	}).Info("Database connection pool calm, lowered max open connections")
+}
+
+// step is how many connections a pool of n grows or shrinks by at once
+func step(n int) int {
+	return max(n/4, 1)
+}
@@ -0,0 +1,100 @@
+package dbpool
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePool reports the stats set by the test
+type fakePool struct {
+	stats sql.DBStats
+}
+
+func (p *fakePool) Stats() sql.DBStats {
+	return p.stats
+}
+
+func (p *fakePool) SetMaxOpenConns(n int) {
+	p.stats.MaxOpenConnections = n
+}
+
+// wait adds waits of the given duration each to the pool stats
+func (p *fakePool) wait(waits int64, each time.Duration) {
+	p.stats.WaitCount += waits
+	p.stats.WaitDuration += time.Duration(waits) * each
+}
+
+// samples records the waits reported
+type samples struct {
+	waits  int64
+	waited time.Duration
+}
+
+func (s *samples) RecordDatabasePool(open, inUse, idle, maxOpen int, waits int64, waited time.Duration) {
+	s.waits += waits
+	s.waited += waited
+}
+
+func TestAdvisor_Check(t *testing.T) {
+	cfg := Config{Interval: time.Second, MaxWait: 50 * time.Millisecond, Autoscale: true, MinOpen: 8, MaxOpen: 12}
+
+	t.Run("grows a saturated pool up to the limit", func(t *testing.T) {
+		pool := &fakePool{stats: sql.DBStats{MaxOpenConnections: 8, InUse: 8}}
+		recorded := &samples{}
+		advisor := NewAdvisor(pool, cfg, recorded, logger.NewLogger())
+
+		pool.wait(10, 100*time.Millisecond)
+		advisor.Check()
+		assert.Equal(t, 10, pool.stats.MaxOpenConnections)
+		assert.EqualValues(t, 10, recorded.waits)
+		assert.Equal(t, time.Second, recorded.waited)
+
+		pool.wait(10, 100*time.Millisecond)
+		advisor.Check()
+		pool.wait(10, 100*time.Millisecond)
+		advisor.Check()
+		assert.Equal(t, 12, pool.stats.MaxOpenConnections, "never above the limit")
+	})
+
+	t.Run("short waits are tolerated", func(t *testing.T) {
+		pool := &fakePool{stats: sql.DBStats{MaxOpenConnections: 8}}
+		advisor := NewAdvisor(pool, cfg, nil, logger.NewLogger())
+
+		pool.wait(100, 10*time.Millisecond)
+		advisor.Check()
+
+		assert.Equal(t, 8, pool.stats.MaxOpenConnections)
+	})
+
+	t.Run("shrinks back once calm", func(t *testing.T) {
+		pool := &fakePool{stats: sql.DBStats{MaxOpenConnections: 12, InUse: 2}}
+		advisor := NewAdvisor(pool, cfg, nil, logger.NewLogger())
+
+		for i := 0; i < calmChecks-1; i++ {
+			advisor.Check()
+		}
+		assert.Equal(t, 12, pool.stats.MaxOpenConnections)
+		advisor.Check()
+		assert.Equal(t, 9, pool.stats.MaxOpenConnections)
+
+		for i := 0; i < 2*calmChecks; i++ {
+			advisor.Check()
+		}
+		assert.Equal(t, 8, pool.stats.MaxOpenConnections, "never below the configured size")
+	})
+
+	t.Run("only warns without autoscaling", func(t *testing.T) {
+		pool := &fakePool{stats: sql.DBStats{MaxOpenConnections: 8}}
+		advisor := NewAdvisor(pool, Config{Interval: time.Second, MaxWait: time.Millisecond}, nil, logger.NewLogger())
+
+		pool.wait(5, time.Second)
+		advisor.Check()
+
+		assert.Equal(t, 8, pool.stats.MaxOpenConnections)
+	})
+}
@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultMaxResponseSize bounds the body of a provider response
+	DefaultMaxResponseSize = 5 << 20
+	// DefaultResponseTimeout bounds reading a provider response body once
+	// its headers have arrived
+	DefaultResponseTimeout = 10 * time.Second
+)
+
+var (
+	// ErrResponseTooLarge is returned when a provider response exceeds the maximum size
+	ErrResponseTooLarge = errors.New("provider response too large")
+	// ErrResponseTimeout is returned when a provider response body is not read in time
+	ErrResponseTimeout = errors.New("provider response timed out")
+)
+
+// SetResponseLimits bounds the size of response bodies and how long reading
+// one may take. Zero disables a limit, such as for a transport downloading
+// files rather than decoding API responses.
+func (t *Transport) SetResponseLimits(maxSize int64, timeout time.Duration) {
+	t.maxResponseSize = maxSize
+	t.responseTimeout = timeout
+}
+
+// send performs the request and bounds its response body. A response
+// declaring a length over the limit is refused before its body is read.
+func (t *Transport) send(req *http.Request) (*http.Response, error) {
+	if t.maxResponseSize <= 0 && t.responseTimeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if t.maxResponseSize > 0 && resp.ContentLength > t.maxResponseSize {
+		resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("%s sent %d bytes: %w", t.provider, resp.ContentLength, ErrResponseTooLarge)
+	}
+
+	body := &limitedBody{
+		body:      resp.Body,
+		provider:  t.provider,
+		limited:   t.maxResponseSize > 0,
+		remaining: t.maxResponseSize,
+		cancel:    cancel,
+	}
+	if t.responseTimeout > 0 {
+		body.timer = time.AfterFunc(t.responseTimeout, func() {
+			body.timedOut.Store(true)
+			cancel()
+		})
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+// limitedBody fails reads past the maximum size, rather than truncating the
+// body into a confusing decode error, and after the read timeout cancels the
+// request.
+type limitedBody struct {
+	body      io.ReadCloser
+	provider  string
+	limited   bool
+	remaining int64
+	timer     *time.Timer
+	timedOut  atomic.Bool
+	cancel    context.CancelFunc
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.limited {
+		if b.remaining <= 0 {
+			// Read one more byte to tell a body of exactly the limit from a larger one
+			var probe [1]byte
+			n, err := b.body.Read(probe[:])
+			if n > 0 {
+				return 0, fmt.Errorf("%s sent more than the maximum: %w", b.provider, ErrResponseTooLarge)
+			}
+			return 0, b.err(err)
+		}
+		if int64(len(p)) > b.remaining {
+			p = p[:b.remaining]
+		}
+	}
+
+	n, err := b.body.Read(p)
+	b.remaining -= int64(n)
+	return n, b.err(err)
+}
+
+func (b *limitedBody) Close() error {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	err := b.body.Close()
+	b.cancel()
+	return err
+}
+
+// err reports a read interrupted by the timeout as ErrResponseTimeout
+func (b *limitedBody) err(err error) error {
+	if err != nil && err != io.EOF && b.timedOut.Load() {
+		return fmt.Errorf("%s: %w", b.provider, ErrResponseTimeout)
+	}
+	return err
+}
@@ -0,0 +1,159 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces scrubbed values
+const redacted = "[REDACTED]"
+
+// sensitiveParts mark a header, query parameter or body field as secret when
+// its normalized name contains one of them.
+var sensitiveParts = []string{
+	"authorization", "cookie", "password", "passwd", "secret", "token", "apikey",
+	"accesskey", "signature", "credential", "cardnumber", "cvc", "cvv", "expmonth",
+	"expyear", "iban", "accountnumber", "ssn",
+}
+
+// sensitiveNames mark a field as secret when its normalized name is one of them.
+var sensitiveNames = map[string]bool{
+	"key": true, "number": true, "pan": true, "pin": true,
+}
+
+var (
+	// jsonField matches a JSON string or number member
+	jsonField = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"(\s*:\s*)("(?:[^"\\]|\\.)*"|-?[0-9][0-9.eE+-]*)`)
+	// formField matches a key=value pair of a query string or form body
+	formField = regexp.MustCompile(`([A-Za-z0-9_.\[\]-]+)=([^&\s"]*)`)
+	// xmlField matches the text of a simple XML element
+	xmlField = regexp.MustCompile(`<([A-Za-z0-9_:.-]+)>([^<]*)`)
+	// cardNumber matches digit runs that may be card numbers
+	cardNumber = regexp.MustCompile(`\b(?:[0-9][ -]?){12,18}[0-9]\b`)
+)
+
+// scrubber redacts secrets from logged requests and responses.
+type scrubber struct {
+	extra map[string]bool
+}
+
+func newScrubber(extraFields []string) scrubber {
+	extra := make(map[string]bool, len(extraFields))
+	for _, field := range extraFields {
+		extra[normalize(field)] = true
+	}
+	return scrubber{extra: extra}
+}
+
+// sensitive reports whether a header, parameter or field name holds a secret.
+func (s scrubber) sensitive(name string) bool {
+	name = normalize(name)
+	if sensitiveNames[name] || s.extra[name] {
+		return true
+	}
+	for _, part := range sensitiveParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s scrubber) headers(header http.Header) map[string]string {
+	scrubbed := make(map[string]string, len(header))
+	for name, values := range header {
+		if s.sensitive(name) {
+			scrubbed[name] = redacted
+			continue
+		}
+		scrubbed[name] = strings.Join(values, ", ")
+	}
+	return scrubbed
+}
+
+func (s scrubber) url(u *url.URL) string {
+	scrubbed := *u
+	scrubbed.User = nil
+	if scrubbed.RawQuery != "" {
+		query := scrubbed.Query()
+		for name := range query {
+			if s.sensitive(name) {
+				query[name] = []string{redacted}
+			}
+		}
+		scrubbed.RawQuery = query.Encode()
+	}
+	return scrubbed.String()
+}
+
+// text redacts secret fields of JSON, form and XML bodies, and anything that
+// looks like a card number. It works on the text rather than parsing it, so
+// truncated bodies are scrubbed too.
+func (s scrubber) text(body string) string {
+	body = jsonField.ReplaceAllStringFunc(body, func(match string) string {
+		parts := jsonField.FindStringSubmatch(match)
+		if !s.sensitive(parts[1]) {
+			return match
+		}
+		return `"` + parts[1] + `"` + parts[2] + `"` + redacted + `"`
+	})
+	body = formField.ReplaceAllStringFunc(body, func(match string) string {
+		parts := formField.FindStringSubmatch(match)
+		name, err := url.QueryUnescape(parts[1])
+		if err != nil || !s.sensitive(name) {
+			return match
+		}
+		return parts[1] + "=" + redacted
+	})
+	body = xmlField.ReplaceAllStringFunc(body, func(match string) string {
+		parts := xmlField.FindStringSubmatch(match)
+		if !s.sensitive(parts[1]) {
+			return match
+		}
+		return "<" + parts[1] + ">" + redacted
+	})
+	return cardNumber.ReplaceAllStringFunc(body, func(match string) string {
+		if !luhn(match) {
+			return match
+		}
+		return redacted
+	})
+}
+
+// normalize lowercases a name and drops everything but letters and digits, so
+// "X-Api-Key", "api_key" and "card[number]" compare alike.
+func normalize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return -1
+		}
+	}, name)
+}
+
+// luhn reports whether the digits of s pass the Luhn check of card numbers.
+func luhn(s string) bool {
+	sum, digits := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
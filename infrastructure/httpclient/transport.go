@@ -0,0 +1,277 @@
+// Package httpclient instruments the HTTP clients of external service providers.
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxLoggedBody is how much of a request or response body is logged
+const maxLoggedBody = 4 << 10
+
+// maxTraceExchanges bounds the exchanges a trace keeps
+const maxTraceExchanges = 20
+
+// RequestIDHeader carries the correlation ID of the request behind a provider call
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds forwarded correlation IDs, which clients may choose
+const maxRequestIDLength = 128
+
+// Exchange is a scrubbed provider request and its response.
+type Exchange struct {
+	Provider        string            `json:"provider"`
+	Method          string            `json:"method"`
+	URL             string            `json:"url"`
+	RequestHeaders  map[string]string `json:"request_headers,omitempty"`
+	RequestBody     string            `json:"request_body,omitempty"`
+	Status          int               `json:"status,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	ResponseBody    string            `json:"response_body,omitempty"`
+	Duration        string            `json:"duration"`
+	Error           string            `json:"error,omitempty"`
+}
+
+// Trace collects the provider exchanges made with a context, so an operation
+// that fails can log what was sent and received.
+type Trace struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+}
+
+type traceKey struct{}
+
+// WithTrace returns a context whose provider exchanges are recorded in the trace.
+func WithTrace(ctx context.Context) (context.Context, *Trace) {
+	trace := &Trace{}
+	return context.WithValue(ctx, traceKey{}, trace), trace
+}
+
+// TraceFrom returns the trace of the context, or nil.
+func TraceFrom(ctx context.Context) *Trace {
+	trace, _ := ctx.Value(traceKey{}).(*Trace)
+	return trace
+}
+
+// Exchanges returns the recorded exchanges, oldest first.
+func (t *Trace) Exchanges() []Exchange {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Exchange(nil), t.exchanges...)
+}
+
+func (t *Trace) add(exchange Exchange) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.exchanges) == maxTraceExchanges {
+		t.exchanges = t.exchanges[1:]
+	}
+	t.exchanges = append(t.exchanges, exchange)
+}
+
+// Transport forwards the correlation ID of the request context to providers,
+// logs provider requests and responses at debug level and records them in the
+// trace of the request context. Authorization headers, API keys, card data and
+// the given extra fields are scrubbed first. Response bodies are bounded in
+// size and read time, so a huge or slow provider response cannot exhaust
+// memory or hold a request.
+type Transport struct {
+	base              http.RoundTripper
+	provider          string
+	idempotencyHeader string
+	maxResponseSize   int64
+	responseTimeout   time.Duration
+	scrubber          scrubber
+	logger            *logger.Logger
+}
+
+// NewTransport wraps base, or http.DefaultTransport when nil. extraFields names
+// provider specific body fields to scrub, such as a message text holding a
+// one-time code. Responses are limited to DefaultMaxResponseSize and
+// DefaultResponseTimeout.
+func NewTransport(provider string, base http.RoundTripper, log *logger.Logger, extraFields ...string) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{
+		base:            base,
+		provider:        provider,
+		maxResponseSize: DefaultMaxResponseSize,
+		responseTimeout: DefaultResponseTimeout,
+		scrubber:        newScrubber(extraFields),
+		logger:          log,
+	}
+}
+
+// SetIdempotencyHeader also sends the correlation ID in the provider's
+// idempotency header on POST requests, such as Stripe's Idempotency-Key. The
+// ID is suffixed with a hash of the request, so the distinct calls of one user
+// request get distinct keys while a repeated call is recognized.
+func (t *Transport) SetIdempotencyHeader(header string) {
+	t.idempotencyHeader = header
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = t.withRequestID(req)
+
+	trace := TraceFrom(req.Context())
+	debug := t.logger.IsLevelEnabled(logrus.DebugLevel)
+	if trace == nil && !debug {
+		return t.send(req)
+	}
+
+	exchange := Exchange{
+		Provider:       t.provider,
+		Method:         req.Method,
+		URL:            t.scrubber.url(req.URL),
+		RequestHeaders: t.scrubber.headers(req.Header),
+		RequestBody:    t.requestBody(req),
+	}
+
+	start := time.Now()
+	resp, err := t.send(req)
+	exchange.Duration = time.Since(start).String()
+	if err != nil {
+		exchange.Error = t.scrubber.text(err.Error())
+	} else {
+		exchange.Status = resp.StatusCode
+		exchange.ResponseHeaders = t.scrubber.headers(resp.Header)
+		exchange.ResponseBody = t.responseBody(resp)
+	}
+
+	if trace != nil {
+		trace.add(exchange)
+	}
+	if debug {
+		t.logger.WithContext(req.Context()).WithFields(logrus.Fields{
+			"provider":  exchange.Provider,
+			"component": "http_client",
+			"exchange":  exchange,
+		}).Debug("Provider HTTP exchange")
+	}
+	return resp, err
+}
+
+// withRequestID returns a copy of the request carrying the correlation ID of
+// its context, so support can find a user request in the provider's logs.
+func (t *Transport) withRequestID(req *http.Request) *http.Request {
+	correlationID, _ := req.Context().Value(logger.CorrelationIDKey).(string)
+	if !validRequestID(correlationID) {
+		return req
+	}
+
+	req = req.Clone(req.Context())
+	if req.Header.Get(RequestIDHeader) == "" {
+		req.Header.Set(RequestIDHeader, correlationID)
+	}
+	if t.idempotencyHeader != "" && req.Method == http.MethodPost && req.Header.Get(t.idempotencyHeader) == "" {
+		req.Header.Set(t.idempotencyHeader, correlationID+"-"+fingerprint(req))
+	}
+	return req
+}
+
+// fingerprint hashes the method, URL and body of a request.
+func fingerprint(req *http.Request) string {
+	hash := sha256.New()
+	io.WriteString(hash, req.Method+" "+req.URL.String()+"\n")
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			io.Copy(hash, body)
+			body.Close()
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// validRequestID reports whether a correlation ID is safe to send as a header.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestBody reads a copy of the request body, leaving the request untouched.
+func (t *Transport) requestBody(req *http.Request) string {
+	if req.Body == nil || req.Body == http.NoBody {
+		return ""
+	}
+	if !textual(req.Header.Get("Content-Type")) || req.GetBody == nil {
+		return fmt.Sprintf("[%d bytes]", req.ContentLength)
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	return t.body(body)
+}
+
+// responseBody reads the start of a textual response body and puts it back,
+// so the provider still reads the whole body.
+func (t *Transport) responseBody(resp *http.Response) string {
+	if !textual(resp.Header.Get("Content-Type")) {
+		return fmt.Sprintf("[%d bytes]", resp.ContentLength)
+	}
+
+	start, err := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBody+1))
+	resp.Body = readCloser{
+		Reader: io.MultiReader(bytes.NewReader(start), resp.Body),
+		Closer: resp.Body,
+	}
+	if err != nil {
+		return ""
+	}
+	return t.body(bytes.NewReader(start))
+}
+
+func (t *Transport) body(r io.Reader) string {
+	content, _ := io.ReadAll(io.LimitReader(r, maxLoggedBody+1))
+	truncated := len(content) > maxLoggedBody
+	if truncated {
+		content = content[:maxLoggedBody]
+	}
+
+	scrubbed := t.scrubber.text(string(content))
+	if truncated {
+		scrubbed += "...(truncated)"
+	}
+	return scrubbed
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// textual reports whether a content type is worth logging.
+func textual(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") ||
+		mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml") ||
+		mediaType == "application/x-www-form-urlencoded"
+}
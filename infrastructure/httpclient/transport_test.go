@@ -0,0 +1,194 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrubber_Text(t *testing.T) {
+	s := newScrubber([]string{"message"})
+
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "json fields",
+			body:     `{"amount":100,"api_key":"sk_live_1","card":{"number":"4242424242424242","cvc":123},"message":"123456 is your code"}`,
+			expected: `{"amount":100,"api_key":"[REDACTED]","card":{"number":"[REDACTED]","cvc":"[REDACTED]"},"message":"[REDACTED]"}`,
+		},
+		{
+			name:     "form fields",
+			body:     `amount=100&card%5Bnumber%5D=4242424242424242&secret=s3cr3t`,
+			expected: `amount=100&card%5Bnumber%5D=[REDACTED]&secret=[REDACTED]`,
+		},
+		{
+			name:     "xml fields",
+			body:     `<Result><AccessKeyId>AKIA</AccessKeyId><Key>a.txt</Key><Size>4</Size></Result>`,
+			expected: `<Result><AccessKeyId>[REDACTED]</AccessKeyId><Key>[REDACTED]</Key><Size>4</Size></Result>`,
+		},
+		{
+			name:     "card numbers in free text",
+			body:     `declined card 4242 4242 4242 4242 for order 1234567890123`,
+			expected: `declined card [REDACTED] for order 1234567890123`,
+		},
+		{
+			name:     "truncated json",
+			body:     `{"client_secret":"pi_1_secret_2","description":"lo`,
+			expected: `{"client_secret":"[REDACTED]","description":"lo`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, s.text(tt.body))
+		})
+	}
+}
+
+func TestTransport_Trace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusPaymentRequired)
+		w.Write([]byte(`{"error":{"message":"card declined","card_number":"4242424242424242"}}`))
+	}))
+	defer server.Close()
+
+	var logged bytes.Buffer
+	log := logger.NewLogger()
+	log.SetOutput(&logged)
+	log.SetLevel(logrus.DebugLevel)
+	client := &http.Client{Transport: NewTransport("stripe", nil, log)}
+
+	ctx, trace := WithTrace(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/charges?api_key=sk_live_1", strings.NewReader(`{"amount":100}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer sk_live_1")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "4242424242424242", "the provider still reads the whole response")
+
+	exchanges := trace.Exchanges()
+	require.Len(t, exchanges, 1)
+	assert.Equal(t, "stripe", exchanges[0].Provider)
+	assert.Equal(t, http.StatusPaymentRequired, exchanges[0].Status)
+	assert.Equal(t, "[REDACTED]", exchanges[0].RequestHeaders["Authorization"])
+	assert.Equal(t, `{"amount":100}`, exchanges[0].RequestBody)
+	assert.Contains(t, exchanges[0].URL, "api_key=%5BREDACTED%5D")
+	assert.Contains(t, exchanges[0].ResponseBody, "card declined")
+
+	assert.Contains(t, logged.String(), "Provider HTTP exchange")
+	assert.NotContains(t, logged.String(), "sk_live_1")
+	assert.NotContains(t, logged.String(), "4242424242424242")
+}
+
+func TestTransport_PassThrough(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var logged bytes.Buffer
+	log := logger.NewLogger()
+	log.SetOutput(&logged)
+	log.SetLevel(logrus.InfoLevel)
+	client := &http.Client{Transport: NewTransport("ipapi", nil, log)}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, logged.String())
+}
+
+func TestTransport_RequestID(t *testing.T) {
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+	}))
+	defer server.Close()
+
+	transport := NewTransport("stripe", nil, logger.NewLogger())
+	transport.SetIdempotencyHeader("Idempotency-Key")
+	client := &http.Client{Transport: transport}
+	post := func(ctx context.Context, body string) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/charges", strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	ctx := logger.ContextWithCorrelationID(context.Background(), "req-123")
+	post(ctx, `{"amount":100}`)
+	post(ctx, `{"amount":100}`)
+	post(ctx, `{"amount":200}`)
+
+	assert.Equal(t, "req-123", headers[0].Get(RequestIDHeader))
+	assert.Regexp(t, `^req-123-[0-9a-f]{16}$`, headers[0].Get("Idempotency-Key"))
+	assert.Equal(t, headers[0].Get("Idempotency-Key"), headers[1].Get("Idempotency-Key"), "a repeated call has the same key")
+	assert.NotEqual(t, headers[0].Get("Idempotency-Key"), headers[2].Get("Idempotency-Key"))
+
+	// Unusable correlation IDs are not forwarded
+	post(logger.ContextWithCorrelationID(context.Background(), "bad id"), `{}`)
+	post(context.Background(), `{}`)
+	assert.Empty(t, headers[3].Get(RequestIDHeader))
+	assert.Empty(t, headers[4].Get("Idempotency-Key"))
+}
+
+func TestTransport_ResponseLimits(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/declared":
+			w.Header().Set("Content-Length", "64")
+			w.Write(bytes.Repeat([]byte("a"), 64))
+		case "/chunked":
+			w.Write(bytes.Repeat([]byte("a"), 16))
+			w.(http.Flusher).Flush()
+			w.Write(bytes.Repeat([]byte("a"), 48))
+		case "/exact":
+			w.Write(bytes.Repeat([]byte("a"), 32))
+		case "/slow":
+			w.Write([]byte(`{"status":`))
+			w.(http.Flusher).Flush()
+			<-release
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	transport := NewTransport("ipapi", nil, logger.NewLogger())
+	transport.SetResponseLimits(32, 50*time.Millisecond)
+	client := &http.Client{Transport: transport}
+
+	_, err := client.Get(server.URL + "/declared")
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+
+	read := func(path string) error {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		_, err = io.ReadAll(resp.Body)
+		return err
+	}
+	assert.ErrorIs(t, read("/chunked"), ErrResponseTooLarge)
+	assert.NoError(t, read("/exact"))
+	assert.ErrorIs(t, read("/slow"), ErrResponseTimeout)
+}
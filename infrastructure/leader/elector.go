@@ -0,0 +1,163 @@
+// Package leader elects one instance among the replicas to run the singleton
+// background processes, such as the job schedules. The leader holds a lock
+// shared by the instances and renews it while it lives; when it stops
+// renewing, another instance takes the lock over.
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/pkg/lock"
+)
+
+// Recorder records whether the instance leads a role, such as the metrics
+type Recorder interface {
+	RecordLeader(role string, leader bool)
+}
+
+// Config tunes an Elector. The lock of the role is held for TTL and renewed
+// every RenewInterval, which followers also try to take it at.
+type Config struct {
+	Role          string
+	TTL           time.Duration
+	RenewInterval time.Duration
+}
+
+// Elector campaigns for the role of leader until Stop
+type Elector struct {
+	locker   lock.Locker
+	cfg      Config
+	recorder Recorder
+	logger   *logger.Logger
+
+	mu      sync.Mutex
+	held    lock.Lock
+	leading atomic.Bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewElector creates an elector taking the lock of the role from locker.
+// recorder may be nil.
+func NewElector(locker lock.Locker, cfg Config, recorder Recorder, log *logger.Logger) *Elector {
+	return &Elector{
+		locker:   locker,
+		cfg:      cfg,
+		recorder: recorder,
+		logger:   log,
+	}
+}
+
+// IsLeader reports whether the instance is the leader
+func (e *Elector) IsLeader() bool {
+	return e.leading.Load()
+}
+
+// Start campaigns once, so a single instance leads as soon as it starts, then
+// every renew interval until Stop
+func (e *Elector) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel, e.done = cancel, make(chan struct{})
+	e.Campaign(ctx)
+
+	go func() {
+		defer close(e.done)
+		ticker := time.NewTicker(e.cfg.RenewInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				e.Campaign(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops campaigning and steps down, so another instance takes over at
+// its next attempt instead of after the lock expires
+func (e *Elector) Stop(ctx context.Context) error {
+	if e.cancel == nil {
+		return nil
+	}
+	e.cancel()
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.held == nil {
+		return nil
+	}
+	err := e.held.Release(ctx)
+	e.held = nil
+	e.setLeader(false, nil)
+	if errors.Is(err, lock.ErrNotHeld) {
+		return nil
+	}
+	return err
+}
+
+// Campaign renews the lock of the leader, or tries to take it when the
+// instance follows. A leader failing to renew steps down.
+func (e *Elector) Campaign(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Leave time to try again before the lock expires
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.RenewInterval)
+	defer cancel()
+
+	if e.held != nil {
+		err := e.held.Refresh(ctx, e.cfg.TTL)
+		if err == nil {
+			return
+		}
+		e.held.Release(context.WithoutCancel(ctx))
+		e.held = nil
+		e.setLeader(false, err)
+		return
+	}
+
+	held, err := e.locker.Acquire(ctx, "leader:"+e.cfg.Role, e.cfg.TTL)
+	if err != nil {
+		if !errors.Is(err, lock.ErrNotAcquired) && ctx.Err() == nil {
+			e.logger.WithError(err).WithField("role", e.cfg.Role).Warn("Leader election failed, following")
+		}
+		e.setLeader(false, nil)
+		return
+	}
+	e.held = held
+	e.setLeader(true, nil)
+}
+
+// setLeader records and logs whether the instance leads, err being why it
+// stepped down
+func (e *Elector) setLeader(leader bool, err error) {
+	if e.recorder != nil {
+		e.recorder.RecordLeader(e.cfg.Role, leader)
+	}
+	if e.leading.Swap(leader) == leader {
+		return
+	}
+
+	entry := e.logger.WithField("role", e.cfg.Role)
+	switch {
+	case leader:
+		entry.Info("Elected leader")
+	case err != nil:
+		entry.WithError(err).Warn("Lost leadership")
+	default:
+		entry.Info("Stepped down as leader")
+	}
+}
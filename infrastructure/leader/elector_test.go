@@ -0,0 +1,67 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/pkg/lock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roles records whether the instance leads each role
+type roles map[string]bool
+
+func (r roles) RecordLeader(role string, leader bool) {
+	r[role] = leader
+}
+
+func newTestElector(locker lock.Locker, ttl time.Duration, recorder Recorder) *Elector {
+	return NewElector(locker, Config{Role: "scheduler", TTL: ttl, RenewInterval: time.Hour}, recorder, logger.NewLogger())
+}
+
+func TestElector_OneLeader(t *testing.T) {
+	locker := lock.NewMemory()
+	ctx := context.Background()
+	first, second := roles{}, roles{}
+	a := newTestElector(locker, time.Minute, first)
+	b := newTestElector(locker, time.Minute, second)
+
+	a.Start()
+	b.Start()
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+	assert.Equal(t, roles{"scheduler": true}, first)
+	assert.Equal(t, roles{"scheduler": false}, second)
+
+	a.Campaign(ctx)
+	assert.True(t, a.IsLeader(), "the leader renews")
+
+	// A leader stopping steps down for the next campaign of the others
+	require.NoError(t, a.Stop(ctx))
+	assert.False(t, a.IsLeader())
+	b.Campaign(ctx)
+	assert.True(t, b.IsLeader())
+	require.NoError(t, b.Stop(ctx))
+}
+
+func TestElector_Failover(t *testing.T) {
+	locker := lock.NewMemory()
+	ctx := context.Background()
+	a := newTestElector(locker, 10*time.Millisecond, nil)
+	b := newTestElector(locker, time.Minute, nil)
+
+	a.Campaign(ctx)
+	require.True(t, a.IsLeader())
+
+	// The leader stalls past its TTL without renewing
+	time.Sleep(20 * time.Millisecond)
+	b.Campaign(ctx)
+	assert.True(t, b.IsLeader())
+
+	a.Campaign(ctx)
+	assert.False(t, a.IsLeader(), "a stalled leader steps down once it notices")
+}
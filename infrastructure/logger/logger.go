@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"boilerplate-go/infrastructure/database"
 	"context"
 	"os"
 
@@ -12,6 +13,7 @@ type contextKey string
 const (
 	CorrelationIDKey contextKey = "correlation_id"
 	UserIDKey        contextKey = "user_id"
+	FieldsKey        contextKey = "fields"
 )
 
 // Logger wraps logrus with context-aware logging
@@ -58,6 +60,10 @@ func (l *Logger) WithContext(ctx context.Context) *logrus.Entry {
 		entry = entry.WithField("user_id", userID)
 	}
 
+	if fields, ok := ctx.Value(FieldsKey).(logrus.Fields); ok {
+		entry = entry.WithFields(fields)
+	}
+
 	return entry
 }
 
@@ -66,6 +72,28 @@ func (l *Logger) WithFields(fields logrus.Fields) *logrus.Entry {
 	return l.Logger.WithFields(fields)
 }
 
+// AddStaticFields adds the fields to every entry, such as the build version.
+// Add it before the log shipper so shipped entries carry them too.
+func (l *Logger) AddStaticFields(fields logrus.Fields) {
+	l.AddHook(staticFields(fields))
+}
+
+// staticFields is a hook adding fields to entries that do not set them
+type staticFields logrus.Fields
+
+func (f staticFields) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (f staticFields) Fire(entry *logrus.Entry) error {
+	for key, value := range f {
+		if _, ok := entry.Data[key]; !ok {
+			entry.Data[key] = value
+		}
+	}
+	return nil
+}
+
 // RequestLogger logs HTTP requests
 func (l *Logger) RequestLogger(ctx context.Context, method, path string, statusCode int, duration string) {
 	l.WithContext(ctx).WithFields(logrus.Fields{
@@ -95,7 +123,9 @@ func (l *Logger) DatabaseLogger(ctx context.Context, operation, table string, du
 		"component": "database",
 	})
 
-	if err != nil {
+	if reason := database.CancelReason(err); reason != "" {
+		entry.WithError(err).WithField("reason", reason).Warn("Database query cancelled")
+	} else if err != nil {
 		entry.WithError(err).Error("Database operation failed")
 	} else {
 		entry.Debug("Database operation completed")
@@ -111,3 +141,18 @@ func ContextWithCorrelationID(ctx context.Context, correlationID string) context
 func ContextWithUserID(ctx context.Context, userID int) context.Context {
 	return context.WithValue(ctx, UserIDKey, userID)
 }
+
+// ContextWithFields adds fields to every entry logged with the context, on
+// top of the fields already added
+func ContextWithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	merged := logrus.Fields{}
+	if existing, ok := ctx.Value(FieldsKey).(logrus.Fields); ok {
+		for key, value := range existing {
+			merged[key] = value
+		}
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return context.WithValue(ctx, FieldsKey, merged)
+}
@@ -0,0 +1,332 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Log shipping sinks
+const (
+	SinkLoki          = "loki"
+	SinkElasticsearch = "elasticsearch"
+)
+
+// shipAttempts is how many times a batch is sent before it is dropped
+const shipAttempts = 3
+
+// ShipperConfig configures log shipping. URL is the base URL of the Loki or
+// Elasticsearch server. Loki streams carry Labels plus the log level;
+// Elasticsearch documents go to Index, which may be a data stream. APIKey is
+// sent as a bearer token to Loki and as an API key to Elasticsearch; Username
+// and Password are sent as basic auth instead.
+type ShipperConfig struct {
+	Sink          string
+	URL           string
+	Index         string
+	Labels        map[string]string
+	Username      string
+	Password      string
+	APIKey        string
+	BatchSize     int
+	FlushInterval time.Duration
+	BufferSize    int
+	Timeout       time.Duration
+}
+
+// Shipper is a logrus hook that ships log entries to Loki or Elasticsearch in
+// batches from a background worker. Logging never waits on the log store:
+// when the buffer is full, entries are dropped and the number dropped is
+// shipped with the next batch. Stdout logging is unaffected.
+type Shipper struct {
+	config     ShipperConfig
+	formatter  logrus.Formatter
+	httpClient *http.Client
+	entries    chan shippedEntry
+	dropped    atomic.Int64
+	stop       chan struct{}
+	done       chan struct{}
+	closeOnce  sync.Once
+	// errorLog reports shipping failures to stderr, bypassing the hook
+	errorLog *logrus.Logger
+}
+
+type shippedEntry struct {
+	time  time.Time
+	level string
+	line  []byte
+}
+
+// NewShipper starts a log shipper. Add it to a logger with AddHook and Close
+// it on shutdown to ship the remaining entries.
+func NewShipper(config ShipperConfig) (*Shipper, error) {
+	switch config.Sink {
+	case SinkLoki, SinkElasticsearch:
+	default:
+		return nil, fmt.Errorf("unsupported log sink: %q", config.Sink)
+	}
+	if config.URL == "" {
+		return nil, fmt.Errorf("log sink URL is required")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 500
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 10000
+	}
+	if config.BufferSize < config.BatchSize {
+		config.BufferSize = config.BatchSize
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	config.URL = strings.TrimRight(config.URL, "/")
+
+	formatter := &logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano}
+	if config.Sink == SinkElasticsearch {
+		formatter.FieldMap = logrus.FieldMap{logrus.FieldKeyTime: "@timestamp"}
+	}
+
+	errorLog := logrus.New()
+	errorLog.SetOutput(os.Stderr)
+	errorLog.SetFormatter(&logrus.JSONFormatter{TimestampFormat: "2006-01-02T15:04:05.000Z07:00"})
+
+	s := &Shipper{
+		config:     config,
+		formatter:  formatter,
+		httpClient: &http.Client{Timeout: config.Timeout},
+		entries:    make(chan shippedEntry, config.BufferSize),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+		errorLog:   errorLog,
+	}
+	go s.run()
+	return s, nil
+}
+
+// Levels ships entries of every level the logger lets through.
+func (s *Shipper) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire queues an entry for shipping, dropping it when the buffer is full.
+func (s *Shipper) Fire(entry *logrus.Entry) error {
+	line, err := s.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	shipped := shippedEntry{time: entry.Time, level: entry.Level.String(), line: line}
+	select {
+	case <-s.stop:
+	case s.entries <- shipped:
+	default:
+		s.dropped.Add(1)
+	}
+	return nil
+}
+
+// Close ships the entries logged so far and stops the worker, giving up when
+// ctx is done.
+func (s *Shipper) Close(ctx context.Context) error {
+	s.closeOnce.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Shipper) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]shippedEntry, 0, s.config.BatchSize)
+	add := func(entry shippedEntry) {
+		batch = append(batch, entry)
+		if len(batch) >= s.config.BatchSize {
+			s.flush(batch)
+			batch = batch[:0]
+		}
+	}
+
+	for {
+		select {
+		case entry := <-s.entries:
+			add(entry)
+		case <-ticker.C:
+			s.flush(batch)
+			batch = batch[:0]
+		case <-s.stop:
+			// Ship what was logged before Close
+			for {
+				select {
+				case entry := <-s.entries:
+					add(entry)
+				default:
+					s.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush ships a batch, retrying with backoff, and reports entries dropped
+// since the last batch.
+func (s *Shipper) flush(batch []shippedEntry) {
+	if dropped := s.dropped.Swap(0); dropped > 0 {
+		entry := logrus.NewEntry(logrus.StandardLogger()).WithField("dropped", dropped)
+		entry.Time = time.Now()
+		entry.Level = logrus.WarnLevel
+		entry.Message = "Log shipping buffer full, entries dropped"
+		if line, err := s.formatter.Format(entry); err == nil {
+			batch = append(batch, shippedEntry{time: entry.Time, level: entry.Level.String(), line: line})
+		}
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	var err error
+	for attempt := 0; attempt < shipAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<(attempt-1)) * 500 * time.Millisecond)
+		}
+		var retry bool
+		if retry, err = s.send(batch); err == nil || !retry {
+			break
+		}
+	}
+	if err != nil {
+		s.errorLog.WithError(err).WithFields(logrus.Fields{
+			"sink":    s.config.Sink,
+			"entries": len(batch),
+		}).Error("Failed to ship logs")
+	}
+}
+
+// send ships a batch, reporting whether a failure is worth retrying.
+func (s *Shipper) send(batch []shippedEntry) (bool, error) {
+	var (
+		url         string
+		contentType string
+		body        []byte
+		err         error
+	)
+	switch s.config.Sink {
+	case SinkLoki:
+		url, contentType = s.config.URL+"/loki/api/v1/push", "application/json"
+		body, err = s.lokiPush(batch)
+	default:
+		url, contentType = s.config.URL+"/_bulk", "application/x-ndjson"
+		body = s.elasticsearchBulk(batch)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	switch {
+	case s.config.APIKey != "" && s.config.Sink == SinkElasticsearch:
+		req.Header.Set("Authorization", "ApiKey "+s.config.APIKey)
+	case s.config.APIKey != "":
+		req.Header.Set("Authorization", "Bearer "+s.config.APIKey)
+	case s.config.Username != "":
+		req.SetBasicAuth(s.config.Username, s.config.Password)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("log sink returned %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if s.config.Sink == SinkElasticsearch {
+		// Rejected documents are reported per item; resending would duplicate the rest
+		var result struct {
+			Errors bool `json:"errors"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err == nil && result.Errors {
+			return false, fmt.Errorf("elasticsearch rejected some of the documents")
+		}
+	}
+	return false, nil
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// lokiPush builds a push request with one stream per log level.
+func (s *Shipper) lokiPush(batch []shippedEntry) ([]byte, error) {
+	streams := map[string]*lokiStream{}
+	var order []string
+	for _, entry := range batch {
+		stream, ok := streams[entry.level]
+		if !ok {
+			labels := make(map[string]string, len(s.config.Labels)+1)
+			for key, value := range s.config.Labels {
+				labels[key] = value
+			}
+			labels["level"] = entry.level
+			stream = &lokiStream{Stream: labels}
+			streams[entry.level] = stream
+			order = append(order, entry.level)
+		}
+		stream.Values = append(stream.Values, [2]string{
+			strconv.FormatInt(entry.time.UnixNano(), 10),
+			string(bytes.TrimRight(entry.line, "\n")),
+		})
+	}
+
+	push := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{}
+	for _, level := range order {
+		push.Streams = append(push.Streams, streams[level])
+	}
+	return json.Marshal(push)
+}
+
+// elasticsearchBulk builds a bulk request creating one document per entry.
+func (s *Shipper) elasticsearchBulk(batch []shippedEntry) []byte {
+	action, _ := json.Marshal(map[string]map[string]string{"create": {"_index": s.config.Index}})
+
+	var body bytes.Buffer
+	for _, entry := range batch {
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(bytes.TrimRight(entry.line, "\n"))
+		body.WriteByte('\n')
+	}
+	return body.Bytes()
+}
@@ -0,0 +1,225 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sinkServer records the request bodies it receives
+type sinkServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newSinkServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) *sinkServer {
+	s := &sinkServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, body)
+		s.mu.Unlock()
+		if handler != nil {
+			handler(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestLogger(t *testing.T, config ShipperConfig) (*logrus.Logger, *Shipper) {
+	shipper, err := NewShipper(config)
+	require.NoError(t, err)
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	log.AddHook(shipper)
+	return log, shipper
+}
+
+func TestShipper_Loki(t *testing.T) {
+	server := newSinkServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	log, shipper := newTestLogger(t, ShipperConfig{
+		Sink:          SinkLoki,
+		URL:           server.URL + "/",
+		Labels:        map[string]string{"app": "api"},
+		APIKey:        "token",
+		FlushInterval: time.Hour,
+	})
+
+	log.WithField("order_id", 7).Info("Order created")
+	log.Error("Payment failed")
+	log.Info("Order paid")
+	require.NoError(t, shipper.Close(context.Background()))
+
+	require.Len(t, server.requests, 1)
+	assert.Equal(t, "/loki/api/v1/push", server.requests[0].URL.Path)
+	assert.Equal(t, "Bearer token", server.requests[0].Header.Get("Authorization"))
+
+	var push struct {
+		Streams []lokiStream `json:"streams"`
+	}
+	require.NoError(t, json.Unmarshal(server.bodies[0], &push))
+	require.Len(t, push.Streams, 2)
+	assert.Equal(t, map[string]string{"app": "api", "level": "info"}, push.Streams[0].Stream)
+	assert.Equal(t, map[string]string{"app": "api", "level": "error"}, push.Streams[1].Stream)
+	require.Len(t, push.Streams[0].Values, 2)
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(push.Streams[0].Values[0][1]), &line))
+	assert.Equal(t, "Order created", line["msg"])
+	assert.Equal(t, float64(7), line["order_id"])
+}
+
+func TestShipper_Elasticsearch(t *testing.T) {
+	server := newSinkServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors":false,"items":[]}`))
+	})
+	log, shipper := newTestLogger(t, ShipperConfig{
+		Sink:          SinkElasticsearch,
+		URL:           server.URL,
+		Index:         "logs-api",
+		Username:      "elastic",
+		Password:      "secret",
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+
+	log.Info("first")
+	log.Info("second")
+	log.Info("third")
+	require.NoError(t, shipper.Close(context.Background()))
+
+	// A full batch is shipped without waiting for the flush interval
+	require.Len(t, server.requests, 2)
+	assert.Equal(t, "/_bulk", server.requests[0].URL.Path)
+	assert.Equal(t, "application/x-ndjson", server.requests[0].Header.Get("Content-Type"))
+	username, password, ok := server.requests[0].BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "elastic", username)
+	assert.Equal(t, "secret", password)
+
+	scanner := bufio.NewScanner(bytes.NewReader(server.bodies[0]))
+	var lines []map[string]interface{}
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.Len(t, lines, 4)
+	assert.Equal(t, map[string]interface{}{"create": map[string]interface{}{"_index": "logs-api"}}, lines[0])
+	assert.Equal(t, "first", lines[1]["msg"])
+	assert.Contains(t, lines[1], "@timestamp")
+	assert.Equal(t, "second", lines[3]["msg"])
+}
+
+func TestShipper_Retry(t *testing.T) {
+	var calls atomic.Int32
+	server := newSinkServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	log, shipper := newTestLogger(t, ShipperConfig{Sink: SinkLoki, URL: server.URL, FlushInterval: time.Hour})
+
+	log.Info("retried")
+	require.NoError(t, shipper.Close(context.Background()))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// Rejected batches are not retried
+	calls.Store(0)
+	rejecting := newSinkServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	log, shipper = newTestLogger(t, ShipperConfig{Sink: SinkLoki, URL: rejecting.URL, FlushInterval: time.Hour})
+	shipper.errorLog.SetOutput(io.Discard)
+
+	log.Info("rejected")
+	require.NoError(t, shipper.Close(context.Background()))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestShipper_DropsWhenBufferFull(t *testing.T) {
+	release := make(chan struct{})
+	server := newSinkServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	})
+	log, shipper := newTestLogger(t, ShipperConfig{
+		Sink:          SinkLoki,
+		URL:           server.URL,
+		BatchSize:     1,
+		BufferSize:    1,
+		FlushInterval: time.Hour,
+	})
+
+	// The first entry blocks the worker in the sink, the second fills the buffer
+	log.Info("shipped")
+	require.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.requests) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			log.Info("overflow")
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("logging blocked on a full buffer")
+	}
+
+	close(release)
+	require.NoError(t, shipper.Close(context.Background()))
+
+	var dropped float64
+	for _, body := range server.bodies {
+		var push struct {
+			Streams []lokiStream `json:"streams"`
+		}
+		require.NoError(t, json.Unmarshal(body, &push))
+		for _, stream := range push.Streams {
+			for _, value := range stream.Values {
+				var line map[string]interface{}
+				require.NoError(t, json.Unmarshal([]byte(value[1]), &line))
+				if n, ok := line["dropped"].(float64); ok {
+					dropped += n
+				}
+			}
+		}
+	}
+	assert.Equal(t, float64(9), dropped)
+}
+
+func TestNewShipper_InvalidConfig(t *testing.T) {
+	_, err := NewShipper(ShipperConfig{Sink: "splunk", URL: "http://localhost"})
+	assert.Error(t, err)
+
+	_, err = NewShipper(ShipperConfig{Sink: SinkLoki})
+	assert.Error(t, err)
+}
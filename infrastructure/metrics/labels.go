@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"strconv"
+
+	"boilerplate-go/config"
+)
+
+// labeler bounds the cardinality of HTTP request labels. Paths are already
+// route templates; these options group them further for large deployments.
+type labeler struct {
+	statusClasses bool
+	paths         map[string]bool
+	userBuckets   int
+	exemplars     bool
+}
+
+func newLabeler(cfg config.MetricsConfig) labeler {
+	l := labeler{
+		statusClasses: cfg.StatusClasses,
+		userBuckets:   cfg.UserBuckets,
+		exemplars:     cfg.Exemplars,
+	}
+	if len(cfg.Paths) > 0 {
+		l.paths = make(map[string]bool, len(cfg.Paths))
+		for _, path := range cfg.Paths {
+			l.paths[path] = true
+		}
+	}
+	return l
+}
+
+// path returns the route template, "unknown" for unmatched requests and
+// "other" for routes outside the allowlist.
+func (l labeler) path(route string) string {
+	switch {
+	case route == "":
+		return "unknown"
+	case l.paths != nil && !l.paths[route]:
+		return "other"
+	}
+	return route
+}
+
+// status returns the status code, or its class such as "4xx".
+func (l labeler) status(code int) string {
+	if l.statusClasses {
+		return strconv.Itoa(code/100) + "xx"
+	}
+	return strconv.Itoa(code)
+}
+
+// user spreads authenticated users over a fixed number of buckets, so load
+// from a few heavy users shows without a series per user.
+func (l labeler) user(userID int) string {
+	if userID <= 0 {
+		return "anonymous"
+	}
+	return strconv.Itoa(userID % l.userBuckets)
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	latencySamples     = 1024
+	latencyMaxAge      = 30 * time.Second
+	latencyRefreshRate = time.Second
+)
+
+type latencySample struct {
+	at       time.Time
+	duration time.Duration
+}
+
+// latencyWindow keeps the most recent request durations so a quantile of
+// current latency can be read cheaply. Samples older than latencyMaxAge are
+// ignored, so the quantile recovers once traffic calms down.
+type latencyWindow struct {
+	mu       sync.Mutex
+	samples  [latencySamples]latencySample
+	next     int
+	count    int
+	p99      time.Duration
+	computed time.Time
+}
+
+func (w *latencyWindow) observe(d time.Duration, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.samples[w.next] = latencySample{at: now, duration: d}
+	w.next = (w.next + 1) % latencySamples
+	if w.count < latencySamples {
+		w.count++
+	}
+}
+
+// p99At returns the 99th percentile of recent samples, recomputed at most
+// once per latencyRefreshRate.
+func (w *latencyWindow) p99At(now time.Time) time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if now.Sub(w.computed) < latencyRefreshRate {
+		return w.p99
+	}
+
+	recent := make([]time.Duration, 0, w.count)
+	for i := 0; i < w.count; i++ {
+		if now.Sub(w.samples[i].at) <= latencyMaxAge {
+			recent = append(recent, w.samples[i].duration)
+		}
+	}
+
+	w.computed = now
+	w.p99 = 0
+	if len(recent) > 0 {
+		sort.Slice(recent, func(i, j int) bool { return recent[i] < recent[j] })
+		w.p99 = recent[(len(recent)*99-1)/100]
+	}
+	return w.p99
+}
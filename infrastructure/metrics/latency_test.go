@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyWindow_P99(t *testing.T) {
+	var w latencyWindow
+	now := time.Now()
+
+	assert.Equal(t, time.Duration(0), w.p99At(now))
+
+	for i := 1; i <= 100; i++ {
+		w.observe(time.Duration(i)*time.Millisecond, now)
+	}
+	now = now.Add(latencyRefreshRate)
+	assert.Equal(t, 99*time.Millisecond, w.p99At(now))
+
+	// Cached until the refresh interval passes
+	w.observe(time.Hour, now)
+	assert.Equal(t, 99*time.Millisecond, w.p99At(now))
+
+	// Old samples age out
+	now = now.Add(latencyMaxAge + time.Millisecond)
+	assert.Equal(t, time.Duration(0), w.p99At(now))
+}
@@ -2,9 +2,15 @@ package metrics
 
 import (
 	"net/http"
-	"strconv"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -16,28 +22,69 @@ type Metrics struct {
 	httpRequestDuration   *prometheus.HistogramVec
 	httpRequestsInFlight  prometheus.Gauge
 	databaseConnections   prometheus.Gauge
+	databasePool          *prometheus.GaugeVec
+	databasePoolWaits     prometheus.Counter
+	databasePoolWaitTime  prometheus.Counter
 	databaseQueries       *prometheus.CounterVec
 	databaseQueryDuration *prometheus.HistogramVec
+	databaseQueriesShared *prometheus.CounterVec
+	databaseCancellations *prometheus.CounterVec
 	authAttempts          *prometheus.CounterVec
+	backgroundJobs        *prometheus.CounterVec
+	deadLetterJobs        prometheus.Gauge
+	leader                *prometheus.GaugeVec
+	shedRequests          *prometheus.CounterVec
+	responseCache         *prometheus.CounterVec
+	notificationQueued    *prometheus.GaugeVec
+	notificationBusy      prometheus.Gauge
+	notificationDropped   *prometheus.CounterVec
+	emailEngagement       *prometheus.CounterVec
+	paymentRequests       *prometheus.CounterVec
+	tokenRefreshes        *prometheus.CounterVec
+	providerCalls         *prometheus.CounterVec
+	providerCost          *prometheus.CounterVec
+	providerBudgetUsed    *prometheus.GaugeVec
+
+	labels   labeler
+	slo      *sloTracker
+	gatherer prometheus.Gatherer
+	registry prometheus.Registerer
+
+	// Live load signals read by the load shedder
+	inFlight int64
+	latency  latencyWindow
 }
 
 // NewMetrics creates and registers all metrics
-func NewMetrics() *Metrics {
+func NewMetrics(cfg config.MetricsConfig) *Metrics {
+	return newMetrics(cfg, prometheus.DefaultRegisterer, prometheus.DefaultGatherer)
+}
+
+func newMetrics(cfg config.MetricsConfig, registry prometheus.Registerer, gatherer prometheus.Gatherer) *Metrics {
+	labels := newLabeler(cfg)
+	httpLabels := []string{"method", "path", "status"}
+	if labels.userBuckets > 0 {
+		httpLabels = append(httpLabels, "user_bucket")
+	}
+
 	m := &Metrics{
+		labels:   labels,
+		gatherer: gatherer,
+		registry: registry,
 		httpRequestsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "http_requests_total",
 				Help: "Total number of HTTP requests",
 			},
-			[]string{"method", "path", "status"},
+			httpLabels,
 		),
 		httpRequestDuration: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "http_request_duration_seconds",
 				Help:    "HTTP request duration in seconds",
-				Buckets: prometheus.DefBuckets,
+				Buckets: sloHistogramBuckets(cfg.SLO),
 			},
-			[]string{"method", "path", "status"},
+			httpLabels,
 		),
 		httpRequestsInFlight: prometheus.NewGauge(
 			prometheus.GaugeOpts{
@@ -51,6 +98,25 @@ func NewMetrics() *Metrics {
 				Help: "Number of active database connections",
 			},
 		),
+		databasePool: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "database_pool_connections",
+				Help: "Database connections of the pool by state: in_use, idle and the max_open limit",
+			},
+			[]string{"state"},
+		),
+		databasePoolWaits: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "database_pool_waits_total",
+				Help: "Total number of queries that waited for a database connection",
+			},
+		),
+		databasePoolWaitTime: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "database_pool_wait_seconds_total",
+				Help: "Total time queries waited for a database connection",
+			},
+		),
 		databaseQueries: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "database_queries_total",
@@ -66,6 +132,20 @@ func NewMetrics() *Metrics {
 			},
 			[]string{"operation", "table"},
 		),
+		databaseQueriesShared: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "database_queries_shared_total",
+				Help: "Total number of reads served by an identical query already in flight instead of querying",
+			},
+			[]string{"operation", "table"},
+		),
+		databaseCancellations: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "database_queries_cancelled_total",
+				Help: "Total number of database queries cancelled by a timeout or by their caller",
+			},
+			[]string{"operation", "table", "reason"},
+		),
 		authAttempts: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "auth_attempts_total",
@@ -73,22 +153,147 @@ func NewMetrics() *Metrics {
 			},
 			[]string{"type", "status"},
 		),
+		backgroundJobs: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "background_jobs_total",
+				Help: "Total number of background job attempts",
+			},
+			[]string{"type", "status"},
+		),
+		deadLetterJobs: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "dead_letter_jobs",
+				Help: "Number of background jobs in the dead letter queue",
+			},
+		),
+		leader: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "leader",
+				Help: "Whether the instance is the leader of the role: 1 when it leads, 0 when it follows",
+			},
+			[]string{"role"},
+		),
+		shedRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_requests_shed_total",
+				Help: "Total number of HTTP requests rejected by the load shedder",
+			},
+			[]string{"method", "path", "reason"},
+		),
+		responseCache: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_response_cache_total",
+				Help: "Total number of GET requests looked up in the response cache by result: hit or miss",
+			},
+			[]string{"path", "result"},
+		),
+		notificationQueued: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "notification_pool_queued",
+				Help: "Number of notifications waiting for a delivery worker",
+			},
+			[]string{"priority"},
+		),
+		notificationBusy: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "notification_pool_busy_workers",
+				Help: "Number of notification workers currently delivering",
+			},
+		),
+		notificationDropped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "notification_pool_dropped_total",
+				Help: "Total number of notifications rejected because the queue was full",
+			},
+			[]string{"priority"},
+		),
+		emailEngagement: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "email_engagement_total",
+				Help: "Total number of bulk email chunks first seen delivered, opened or clicked",
+			},
+			[]string{"event"},
+		),
+		paymentRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "payment_requests_total",
+				Help: "Total number of payment provider calls",
+			},
+			[]string{"provider", "environment", "operation", "status"},
+		),
+		tokenRefreshes: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "payment_token_refreshes_total",
+				Help: "Total number of payment provider access token refreshes",
+			},
+			[]string{"provider", "status"},
+		),
+		providerCalls: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "provider_calls_total",
+				Help: "Total number of calls to paid providers, by whether the budget allowed them",
+			},
+			[]string{"provider", "status"},
+		),
+		providerCost: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "provider_cost_total",
+				Help: "Total cost-equivalent of the calls to paid providers",
+			},
+			[]string{"provider"},
+		),
+		providerBudgetUsed: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "provider_budget_used_ratio",
+				Help: "Share of the daily budget of paid providers spent today",
+			},
+			[]string{"provider"},
+		),
 	}
 
 	// Register all metrics
-	prometheus.MustRegister(
+	registry.MustRegister(
 		m.httpRequestsTotal,
 		m.httpRequestDuration,
 		m.httpRequestsInFlight,
 		m.databaseConnections,
+		m.databasePool,
+		m.databasePoolWaits,
+		m.databasePoolWaitTime,
 		m.databaseQueries,
 		m.databaseQueryDuration,
+		m.databaseQueriesShared,
+		m.databaseCancellations,
 		m.authAttempts,
+		m.backgroundJobs,
+		m.deadLetterJobs,
+		m.leader,
+		m.shedRequests,
+		m.responseCache,
+		m.notificationQueued,
+		m.notificationBusy,
+		m.notificationDropped,
+		m.emailEngagement,
+		m.paymentRequests,
+		m.tokenRefreshes,
+		m.providerCalls,
+		m.providerCost,
+		m.providerBudgetUsed,
 	)
 
+	if cfg.SLO.Enabled {
+		m.slo = newSLOTracker(cfg.SLO)
+		registry.MustRegister(m.slo)
+	}
+
 	return m
 }
 
+// IdleTimeKey is the gin context key of the time a request spent waiting by
+// design, such as a long poll. It is left out of the latency the load shedder
+// and the latency SLO see, while the duration histogram keeps it.
+const IdleTimeKey = "idle_time"
+
 // MetricsMiddleware collects HTTP metrics
 func (m *Metrics) MetricsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -96,26 +301,86 @@ func (m *Metrics) MetricsMiddleware() gin.HandlerFunc {
 
 		// Increment in-flight requests
 		m.httpRequestsInFlight.Inc()
-		defer m.httpRequestsInFlight.Dec()
+		atomic.AddInt64(&m.inFlight, 1)
+		defer func() {
+			m.httpRequestsInFlight.Dec()
+			atomic.AddInt64(&m.inFlight, -1)
+		}()
 
 		// Process request
 		c.Next()
 
 		// Collect metrics
-		duration := time.Since(start).Seconds()
-		method := c.Request.Method
-		path := c.FullPath()
-		if path == "" {
-			path = "unknown"
+		elapsed := time.Since(start)
+		busy := elapsed
+		if idle, ok := c.Get(IdleTimeKey); ok {
+			busy -= idle.(time.Duration)
 		}
-		status := strconv.Itoa(c.Writer.Status())
+		m.latency.observe(busy, time.Now())
+		values := m.requestLabels(c.Request.Method, c.FullPath(), c.Writer.Status(), c.GetInt("user_id"))
 
 		// Record metrics
-		m.httpRequestsTotal.WithLabelValues(method, path, status).Inc()
-		m.httpRequestDuration.WithLabelValues(method, path, status).Observe(duration)
+		m.httpRequestsTotal.WithLabelValues(values...).Inc()
+		observer := m.httpRequestDuration.WithLabelValues(values...)
+		if exemplar := m.exemplar(c); exemplar != nil {
+			observer.(prometheus.ExemplarObserver).ObserveWithExemplar(elapsed.Seconds(), exemplar)
+		} else {
+			observer.Observe(elapsed.Seconds())
+		}
+		if m.slo != nil {
+			m.slo.observe(c.Writer.Status(), busy)
+		}
 	}
 }
 
+// requestLabels returns the label values of HTTP request metrics
+func (m *Metrics) requestLabels(method, route string, status, userID int) []string {
+	values := []string{method, m.labels.path(route), m.labels.status(status)}
+	if m.labels.userBuckets > 0 {
+		values = append(values, m.labels.user(userID))
+	}
+	return values
+}
+
+// exemplar ties a duration observation to the correlation ID of the request,
+// which is also logged and forwarded to providers, so a slow bucket leads to
+// the request's logs.
+func (m *Metrics) exemplar(c *gin.Context) prometheus.Labels {
+	if !m.labels.exemplars {
+		return nil
+	}
+	id, _ := c.Request.Context().Value(logger.CorrelationIDKey).(string)
+	// Exemplar labels are limited to 128 runes in total
+	if id == "" || len(id) > 64 {
+		return nil
+	}
+	return prometheus.Labels{"trace_id": id}
+}
+
+// InFlight returns the number of HTTP requests currently being processed
+func (m *Metrics) InFlight() int64 {
+	return atomic.LoadInt64(&m.inFlight)
+}
+
+// LatencyP99 returns the 99th percentile duration of recent HTTP requests
+func (m *Metrics) LatencyP99() time.Duration {
+	return m.latency.p99At(time.Now())
+}
+
+// RecordShed records a request rejected by the load shedder
+func (m *Metrics) RecordShed(method, path, reason string) {
+	m.shedRequests.WithLabelValues(method, m.labels.path(path), reason).Inc()
+}
+
+// RecordResponseCache records a lookup of the response cache
+func (m *Metrics) RecordResponseCache(path string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.responseCache.WithLabelValues(m.labels.path(path), result).Inc()
+}
+
 // RecordDatabaseQuery records database query metrics
 func (m *Metrics) RecordDatabaseQuery(operation, table string, duration time.Duration, err error) {
 	status := "success"
@@ -125,6 +390,15 @@ func (m *Metrics) RecordDatabaseQuery(operation, table string, duration time.Dur
 
 	m.databaseQueries.WithLabelValues(operation, table, status).Inc()
 	m.databaseQueryDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
+	if reason := database.CancelReason(err); reason != "" {
+		m.databaseCancellations.WithLabelValues(operation, table, reason).Inc()
+	}
+}
+
+// RecordSharedQuery records a read that shared the result of an identical
+// query already in flight instead of querying
+func (m *Metrics) RecordSharedQuery(operation, table string) {
+	m.databaseQueriesShared.WithLabelValues(operation, table).Inc()
 }
 
 // RecordAuthAttempt records authentication attempt metrics
@@ -137,14 +411,101 @@ func (m *Metrics) RecordAuthAttempt(authType string, success bool) {
 	m.authAttempts.WithLabelValues(authType, status).Inc()
 }
 
+// RecordJob records the outcome of a background job attempt
+func (m *Metrics) RecordJob(jobType, status string) {
+	m.backgroundJobs.WithLabelValues(jobType, status).Inc()
+}
+
+// SetDeadLetterDepth sets the number of jobs in the dead letter queue
+func (m *Metrics) SetDeadLetterDepth(count float64) {
+	m.deadLetterJobs.Set(count)
+}
+
+// RecordLeader records whether the instance leads the role
+func (m *Metrics) RecordLeader(role string, leader bool) {
+	value := 0.0
+	if leader {
+		value = 1
+	}
+	m.leader.WithLabelValues(role).Set(value)
+}
+
+// SetNotificationPool sets the saturation of the notification worker pool
+func (m *Metrics) SetNotificationPool(busy int, queued map[string]int) {
+	m.notificationBusy.Set(float64(busy))
+	for priority, count := range queued {
+		m.notificationQueued.WithLabelValues(priority).Set(float64(count))
+	}
+}
+
+// RecordNotificationDropped records a notification rejected by a full queue
+func (m *Metrics) RecordNotificationDropped(priority string) {
+	m.notificationDropped.WithLabelValues(priority).Inc()
+}
+
+// RecordEmailEngagement records a bulk email chunk reaching an engagement
+// event: "delivered", "opened" or "clicked"
+func (m *Metrics) RecordEmailEngagement(event string) {
+	m.emailEngagement.WithLabelValues(event).Inc()
+}
+
+// RecordPaymentRequest records a payment provider call in its environment
+func (m *Metrics) RecordPaymentRequest(provider, environment, operation string, err error) {
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	m.paymentRequests.WithLabelValues(provider, environment, operation, status).Inc()
+}
+
+// RecordTokenRefresh records a payment provider access token refresh
+func (m *Metrics) RecordTokenRefresh(provider string, err error) {
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	m.tokenRefreshes.WithLabelValues(provider, status).Inc()
+}
+
+// RecordProviderCall records a call to a paid provider and its cost, or a
+// call refused because the budget of the provider is spent
+func (m *Metrics) RecordProviderCall(provider string, cost float64, blocked bool) {
+	if blocked {
+		m.providerCalls.WithLabelValues(provider, "blocked").Inc()
+		return
+	}
+	m.providerCalls.WithLabelValues(provider, "allowed").Inc()
+	m.providerCost.WithLabelValues(provider).Add(cost)
+}
+
+// SetProviderBudgetUsed sets the share of the daily budget of a paid
+// provider spent today
+func (m *Metrics) SetProviderBudgetUsed(provider string, ratio float64) {
+	m.providerBudgetUsed.WithLabelValues(provider).Set(ratio)
+}
+
 // SetDatabaseConnections sets the number of active database connections
 func (m *Metrics) SetDatabaseConnections(count float64) {
 	m.databaseConnections.Set(count)
 }
 
-// Handler returns the Prometheus metrics HTTP handler
+// RecordDatabasePool records the connections of the database pool and the
+// waits for a connection since the previous sample
+func (m *Metrics) RecordDatabasePool(open, inUse, idle, maxOpen int, waits int64, waited time.Duration) {
+	m.databaseConnections.Set(float64(open))
+	m.databasePool.WithLabelValues("in_use").Set(float64(inUse))
+	m.databasePool.WithLabelValues("idle").Set(float64(idle))
+	m.databasePool.WithLabelValues("max_open").Set(float64(maxOpen))
+	m.databasePoolWaits.Add(float64(waits))
+	m.databasePoolWaitTime.Add(waited.Seconds())
+}
+
+// Handler returns the Prometheus metrics HTTP handler. Exemplars are only
+// exposed to scrapers negotiating the OpenMetrics format.
 func (m *Metrics) Handler() http.Handler {
-	return promhttp.Handler()
+	return promhttp.InstrumentMetricHandler(m.registry, promhttp.HandlerFor(m.gatherer, promhttp.HandlerOpts{
+		EnableOpenMetrics: m.labels.exemplars,
+	}))
 }
 
 // IncrementCounter provides a generic counter increment method
@@ -153,9 +514,9 @@ func (m *Metrics) IncrementCounter(name string) {
 	case "order_processing_failures", "order_processing_success":
 		// For now, just use a generic counter or extend the metrics struct
 		// This is a simplified implementation
-		m.httpRequestsTotal.WithLabelValues("POST", "/orders", "200").Inc()
+		m.httpRequestsTotal.WithLabelValues(m.requestLabels("POST", "/orders", http.StatusOK, 0)...).Inc()
 	case "order_refund_failures", "order_refund_success":
-		m.httpRequestsTotal.WithLabelValues("POST", "/orders/refund", "200").Inc()
+		m.httpRequestsTotal.WithLabelValues(m.requestLabels("POST", "/orders/refund", http.StatusOK, 0)...).Inc()
 	}
 }
 
@@ -164,6 +525,10 @@ type HealthMetrics struct {
 	StartTime    time.Time
 	DatabaseUp   bool
 	ExternalAPIs map[string]bool
+
+	mu       sync.Mutex
+	degraded map[string]bool
+	draining bool
 }
 
 // NewHealthMetrics creates a new health metrics instance
@@ -171,9 +536,55 @@ func NewHealthMetrics() *HealthMetrics {
 	return &HealthMetrics{
 		StartTime:    time.Now(),
 		ExternalAPIs: make(map[string]bool),
+		degraded:     make(map[string]bool),
 	}
 }
 
+// SetDegraded records whether the application runs without an optional
+// dependency. A degraded application is still healthy.
+func (h *HealthMetrics) SetDegraded(name string, degraded bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if degraded {
+		h.degraded[name] = true
+	} else {
+		delete(h.degraded, name)
+	}
+}
+
+// Degraded returns the optional dependencies and external APIs currently
+// unavailable
+func (h *HealthMetrics) Degraded() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	names := make([]string, 0, len(h.degraded))
+	for name := range h.degraded {
+		names = append(names, name)
+	}
+	for name, up := range h.ExternalAPIs {
+		if !up && !h.degraded[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetDraining records whether the instance is taken out of rotation, such as
+// before it shuts down, so it reports not ready
+func (h *HealthMetrics) SetDraining(draining bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.draining = draining
+}
+
+// Draining reports whether the instance is taken out of rotation
+func (h *HealthMetrics) Draining() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.draining
+}
+
 // Uptime returns the application uptime
 func (h *HealthMetrics) Uptime() time.Duration {
 	return time.Since(h.StartTime)
@@ -186,20 +597,24 @@ func (h *HealthMetrics) SetDatabaseStatus(up bool) {
 
 // SetExternalAPIStatus sets the status of an external API
 func (h *HealthMetrics) SetExternalAPIStatus(name string, up bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	h.ExternalAPIs[name] = up
 }
 
-// IsHealthy returns true if all systems are healthy
-func (h *HealthMetrics) IsHealthy() bool {
-	if !h.DatabaseUp {
-		return false
-	}
-
-	for _, status := range h.ExternalAPIs {
-		if !status {
-			return false
-		}
+// ExternalAPIStatus returns whether each external API probed is up
+func (h *HealthMetrics) ExternalAPIStatus() map[string]bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	status := make(map[string]bool, len(h.ExternalAPIs))
+	for name, up := range h.ExternalAPIs {
+		status[name] = up
 	}
+	return status
+}
 
-	return true
+// IsHealthy returns true if the database is up. An external API being down
+// degrades the application instead, as requests not calling it still succeed.
+func (h *HealthMetrics) IsHealthy() bool {
+	return h.DatabaseUp
 }
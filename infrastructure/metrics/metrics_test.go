@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabeler(t *testing.T) {
+	l := newLabeler(config.MetricsConfig{
+		StatusClasses: true,
+		Paths:         []string{"/api/v1/orders/:id"},
+		UserBuckets:   4,
+	})
+
+	assert.Equal(t, "/api/v1/orders/:id", l.path("/api/v1/orders/:id"))
+	assert.Equal(t, "other", l.path("/api/v1/users/:id"))
+	assert.Equal(t, "unknown", l.path(""))
+	assert.Equal(t, "4xx", l.status(http.StatusNotFound))
+	assert.Equal(t, "anonymous", l.user(0))
+	assert.Equal(t, "3", l.user(7))
+
+	l = newLabeler(config.MetricsConfig{})
+	assert.Equal(t, "/api/v1/users/:id", l.path("/api/v1/users/:id"))
+	assert.Equal(t, "404", l.status(http.StatusNotFound))
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := prometheus.NewRegistry()
+	m := newMetrics(config.MetricsConfig{StatusClasses: true, UserBuckets: 4, Exemplars: true}, registry, registry)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(logger.ContextWithCorrelationID(c.Request.Context(), "req-42"))
+		c.Set("user_id", 7)
+	}, m.MetricsMiddleware())
+	r.GET("/orders/:id", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	body, _ := io.ReadAll(w.Body)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, string(body), `http_requests_total{method="GET",path="/orders/:id",status="2xx",user_bucket="3"} 1`)
+	assert.Contains(t, string(body), `# {trace_id="req-42"}`)
+}
+
+func TestMetricsMiddleware_IdleTime(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := prometheus.NewRegistry()
+	m := newMetrics(config.MetricsConfig{}, registry, registry)
+
+	r := gin.New()
+	r.Use(m.MetricsMiddleware())
+	r.GET("/status", func(c *gin.Context) {
+		start := time.Now()
+		time.Sleep(50 * time.Millisecond)
+		c.Set(IdleTimeKey, time.Since(start))
+		c.Status(http.StatusOK)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	assert.Less(t, m.LatencyP99(), 50*time.Millisecond, "a long poll does not count as latency")
+}
@@ -0,0 +1,259 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"boilerplate-go/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// burnAlert is one multiwindow, multi-burn-rate alert from the Google SRE
+// workbook: it fires when both windows consume the error budget Factor times
+// faster than the objective allows.
+type burnAlert struct {
+	severity string
+	long     time.Duration
+	short    time.Duration
+	factor   float64
+}
+
+// burnAlerts page at 2% of a 30 day budget in an hour or 5% in six hours, and
+// open tickets at 10% in a day or 10% in three days.
+var burnAlerts = []burnAlert{
+	{severity: "page", long: time.Hour, short: 5 * time.Minute, factor: 14.4},
+	{severity: "page", long: 6 * time.Hour, short: 30 * time.Minute, factor: 6},
+	{severity: "ticket", long: 24 * time.Hour, short: 2 * time.Hour, factor: 3},
+	{severity: "ticket", long: 72 * time.Hour, short: 6 * time.Hour, factor: 1},
+}
+
+// burnWindows are the distinct windows of burnAlerts, shortest first
+var burnWindows = []time.Duration{
+	5 * time.Minute, 30 * time.Minute, time.Hour, 2 * time.Hour, 6 * time.Hour, 24 * time.Hour, 72 * time.Hour,
+}
+
+// sloBuckets keeps one bucket per minute of the longest window
+const sloBuckets = 72 * 60
+
+const (
+	sloAvailability = "availability"
+	sloLatency      = "latency"
+)
+
+type sloBucket struct {
+	minute int64
+	total  uint64
+	errors uint64
+	slow   uint64
+}
+
+// sloTracker counts requests failing the availability and latency objectives
+// per minute, so burn rates can be exposed without a Prometheus server doing
+// the math. Counts are per instance and reset on restart; the rules served by
+// SLORulesHandler compute the same rates across replicas.
+type sloTracker struct {
+	config  config.SLOConfig
+	mu      sync.Mutex
+	buckets [sloBuckets]sloBucket
+
+	objective *prometheus.Desc
+	burnRate  *prometheus.Desc
+	alert     *prometheus.Desc
+	now       func() time.Time
+}
+
+func newSLOTracker(cfg config.SLOConfig) *sloTracker {
+	return &sloTracker{
+		config: cfg,
+		objective: prometheus.NewDesc(
+			"slo_objective",
+			"Target share of requests meeting the service level objective",
+			[]string{"slo"}, nil,
+		),
+		burnRate: prometheus.NewDesc(
+			"slo_burn_rate",
+			"Rate at which the error budget is consumed over the window, 1 exhausting it exactly at the end of the SLO period",
+			[]string{"slo", "window"}, nil,
+		),
+		alert: prometheus.NewDesc(
+			"slo_burn_rate_alert",
+			"Whether a multiwindow burn rate alert condition holds (1) or not (0)",
+			[]string{"slo", "severity", "long_window", "short_window"}, nil,
+		),
+		now: time.Now,
+	}
+}
+
+func (t *sloTracker) observe(status int, duration time.Duration) {
+	minute := t.now().Unix() / 60
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	bucket := &t.buckets[minute%sloBuckets]
+	if bucket.minute != minute {
+		*bucket = sloBucket{minute: minute}
+	}
+	bucket.total++
+	if status >= http.StatusInternalServerError {
+		bucket.errors++
+	}
+	if duration > t.config.LatencyThreshold {
+		bucket.slow++
+	}
+}
+
+// burnRates returns the burn rate of each SLO over each of burnWindows
+func (t *sloTracker) burnRates() map[string]map[time.Duration]float64 {
+	now := t.now().Unix() / 60
+	totals := make([]sloBucket, len(burnWindows))
+
+	t.mu.Lock()
+	for _, bucket := range t.buckets {
+		age := time.Duration(now-bucket.minute) * time.Minute
+		if bucket.total == 0 || age < 0 {
+			continue
+		}
+		for i, window := range burnWindows {
+			if age < window {
+				totals[i].total += bucket.total
+				totals[i].errors += bucket.errors
+				totals[i].slow += bucket.slow
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	rates := map[string]map[time.Duration]float64{sloAvailability: {}, sloLatency: {}}
+	for i, window := range burnWindows {
+		rates[sloAvailability][window] = burnRate(totals[i].errors, totals[i].total, t.config.AvailabilityTarget)
+		rates[sloLatency][window] = burnRate(totals[i].slow, totals[i].total, t.config.LatencyTarget)
+	}
+	return rates
+}
+
+func burnRate(bad, total uint64, target float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(bad) / float64(total) / (1 - target)
+}
+
+// Describe implements prometheus.Collector
+func (t *sloTracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- t.objective
+	ch <- t.burnRate
+	ch <- t.alert
+}
+
+// Collect implements prometheus.Collector
+func (t *sloTracker) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(t.objective, prometheus.GaugeValue, t.config.AvailabilityTarget, sloAvailability)
+	ch <- prometheus.MustNewConstMetric(t.objective, prometheus.GaugeValue, t.config.LatencyTarget, sloLatency)
+
+	for slo, rates := range t.burnRates() {
+		for window, rate := range rates {
+			ch <- prometheus.MustNewConstMetric(t.burnRate, prometheus.GaugeValue, rate, slo, promDuration(window))
+		}
+		for _, alert := range burnAlerts {
+			firing := 0.0
+			if rates[alert.long] > alert.factor && rates[alert.short] > alert.factor {
+				firing = 1
+			}
+			ch <- prometheus.MustNewConstMetric(t.alert, prometheus.GaugeValue, firing,
+				slo, alert.severity, promDuration(alert.long), promDuration(alert.short))
+		}
+	}
+}
+
+// SLORulesHandler serves Prometheus recording and alerting rules computing the
+// SLO burn rates from the HTTP metrics of all replicas, ready to be loaded
+// with rule_files.
+func (m *Metrics) SLORulesHandler() http.Handler {
+	rules := sloRules(m.slo.config)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write([]byte(rules))
+	})
+}
+
+func sloRules(cfg config.SLOConfig) string {
+	var b strings.Builder
+	b.WriteString("groups:\n")
+
+	b.WriteString("  - name: slo-recording\n    rules:\n")
+	for _, window := range burnWindows {
+		w := promDuration(window)
+		fmt.Fprintf(&b, "      - record: slo:sli_error:ratio_rate%s\n", w)
+		fmt.Fprintf(&b, "        expr: sum(rate(http_requests_total{status=~\"5..\"}[%s])) / sum(rate(http_requests_total[%s]))\n", w, w)
+		fmt.Fprintf(&b, "        labels:\n          slo: %s\n", sloAvailability)
+		fmt.Fprintf(&b, "      - record: slo:sli_error:ratio_rate%s\n", w)
+		fmt.Fprintf(&b, "        expr: 1 - sum(rate(http_request_duration_seconds_bucket{le=~\"%s\"}[%s])) / sum(rate(http_request_duration_seconds_count[%s]))\n",
+			leMatcher(cfg.LatencyThreshold), w, w)
+		fmt.Fprintf(&b, "        labels:\n          slo: %s\n", sloLatency)
+	}
+
+	b.WriteString("  - name: slo-alerts\n    rules:\n")
+	for _, slo := range []struct {
+		name   string
+		target float64
+	}{{sloAvailability, cfg.AvailabilityTarget}, {sloLatency, cfg.LatencyTarget}} {
+		budget := strconv.FormatFloat(1-slo.target, 'g', 6, 64)
+		for _, alert := range burnAlerts {
+			fmt.Fprintf(&b, "      - alert: SLO%sBurnRate\n", strings.ToUpper(slo.name[:1])+slo.name[1:])
+			fmt.Fprintf(&b, "        expr: slo:sli_error:ratio_rate%s{slo=\"%s\"} > (%g * %s) and slo:sli_error:ratio_rate%s{slo=\"%s\"} > (%g * %s)\n",
+				promDuration(alert.long), slo.name, alert.factor, budget,
+				promDuration(alert.short), slo.name, alert.factor, budget)
+			fmt.Fprintf(&b, "        labels:\n          severity: %s\n          slo: %s\n", alert.severity, slo.name)
+			fmt.Fprintf(&b, "        annotations:\n          summary: Error budget of the %s SLO burning %gx too fast over %s\n",
+				slo.name, alert.factor, promDuration(alert.long))
+		}
+	}
+	return b.String()
+}
+
+// sloHistogramBuckets adds the latency threshold to the duration histogram buckets,
+// since the latency SLI is read from the bucket at the threshold.
+func sloHistogramBuckets(cfg config.SLOConfig) []float64 {
+	buckets := append([]float64(nil), prometheus.DefBuckets...)
+	if !cfg.Enabled {
+		return buckets
+	}
+	threshold := cfg.LatencyThreshold.Seconds()
+	for _, bucket := range buckets {
+		if bucket == threshold {
+			return buckets
+		}
+	}
+	buckets = append(buckets, threshold)
+	sort.Float64s(buckets)
+	return buckets
+}
+
+// leMatcher matches the le label of a bucket, written as "1" or "1.0"
+// depending on the exposition format.
+func leMatcher(threshold time.Duration) string {
+	le := strconv.FormatFloat(threshold.Seconds(), 'f', -1, 64)
+	if !strings.Contains(le, ".") {
+		return le + `|` + le + `\\.0`
+	}
+	return strings.ReplaceAll(le, ".", `\\.`)
+}
+
+// promDuration formats a window as a Prometheus duration such as 5m or 3d.
+func promDuration(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return strconv.Itoa(int(d/(24*time.Hour))) + "d"
+	case d%time.Hour == 0:
+		return strconv.Itoa(int(d/time.Hour)) + "h"
+	default:
+		return strconv.Itoa(int(d/time.Minute)) + "m"
+	}
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"boilerplate-go/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSLO = config.SLOConfig{
+	Enabled:            true,
+	AvailabilityTarget: 0.99,
+	LatencyTarget:      0.9,
+	LatencyThreshold:   300 * time.Millisecond,
+}
+
+func TestSLOTracker_BurnRates(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newSLOTracker(testSLO)
+	tracker.now = func() time.Time { return now }
+
+	// An hour ago: 100 good requests
+	now = now.Add(-time.Hour + time.Minute)
+	for i := 0; i < 100; i++ {
+		tracker.observe(http.StatusOK, 10*time.Millisecond)
+	}
+
+	// Now: 100 requests, 10 failing and 20 slow
+	now = now.Add(time.Hour - time.Minute)
+	for i := 0; i < 100; i++ {
+		switch {
+		case i < 10:
+			tracker.observe(http.StatusBadGateway, 10*time.Millisecond)
+		case i < 30:
+			tracker.observe(http.StatusOK, time.Second)
+		default:
+			tracker.observe(http.StatusNotFound, 10*time.Millisecond)
+		}
+	}
+
+	rates := tracker.burnRates()
+	assert.InDelta(t, 10.0, rates[sloAvailability][5*time.Minute], 1e-9)
+	assert.InDelta(t, 5.0, rates[sloAvailability][time.Hour], 1e-9)
+	assert.InDelta(t, 2.0, rates[sloLatency][5*time.Minute], 1e-9)
+	assert.InDelta(t, 1.0, rates[sloLatency][time.Hour], 1e-9)
+
+	// Old requests age out of the windows
+	now = now.Add(73 * time.Hour)
+	rates = tracker.burnRates()
+	assert.Equal(t, 0.0, rates[sloAvailability][72*time.Hour])
+}
+
+func TestSLOTracker_Alert(t *testing.T) {
+	tracker := newSLOTracker(testSLO)
+	for i := 0; i < 100; i++ {
+		status := http.StatusOK
+		if i < 20 {
+			status = http.StatusInternalServerError
+		}
+		tracker.observe(status, 10*time.Millisecond)
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(tracker)
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	// A 20x burn rate in every window trips every availability alert
+	gauges := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			switch family.GetName() {
+			case "slo_objective":
+				gauges["objective/"+labels["slo"]] = metric.GetGauge().GetValue()
+			case "slo_burn_rate_alert":
+				gauges[labels["slo"]+"/"+labels["long_window"]] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{
+		"objective/availability": 0.99, "objective/latency": 0.9,
+		"availability/1h": 1, "availability/6h": 1, "availability/1d": 1, "availability/3d": 1,
+		"latency/1h": 0, "latency/6h": 0, "latency/1d": 0, "latency/3d": 0,
+	}, gauges)
+}
+
+func TestSLORules(t *testing.T) {
+	rules := sloRules(testSLO)
+
+	assert.Contains(t, rules, `      - record: slo:sli_error:ratio_rate5m
+        expr: sum(rate(http_requests_total{status=~"5.."}[5m])) / sum(rate(http_requests_total[5m]))
+        labels:
+          slo: availability
+`)
+	assert.Contains(t, rules, `http_request_duration_seconds_bucket{le=~"0\\.3"}[3d]`)
+	assert.Contains(t, rules, `expr: slo:sli_error:ratio_rate1h{slo="availability"} > (14.4 * 0.01) and slo:sli_error:ratio_rate5m{slo="availability"} > (14.4 * 0.01)`)
+	assert.Contains(t, rules, `expr: slo:sli_error:ratio_rate3d{slo="latency"} > (1 * 0.1) and slo:sli_error:ratio_rate6h{slo="latency"} > (1 * 0.1)`)
+}
+
+func TestSLOHistogramBuckets(t *testing.T) {
+	assert.Equal(t, prometheus.DefBuckets, sloHistogramBuckets(config.SLOConfig{}))
+	assert.Equal(t, prometheus.DefBuckets, sloHistogramBuckets(config.SLOConfig{Enabled: true, LatencyThreshold: 500 * time.Millisecond}))
+
+	buckets := sloHistogramBuckets(testSLO)
+	assert.Len(t, buckets, len(prometheus.DefBuckets)+1)
+	assert.Contains(t, buckets, 0.3)
+	assert.Equal(t, `1|1\\.0`, leMatcher(time.Second))
+}
@@ -0,0 +1,129 @@
+// Package probe checks external providers on a schedule, so the health
+// endpoint reports an outage before requests fail on it.
+package probe
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+)
+
+// ErrNoProbe is returned by the check of a provider that cannot be probed,
+// such as a stand-in used in tests. Its status is left unreported.
+var ErrNoProbe = errors.New("provider has no health probe")
+
+// Probe is a provider checked on a schedule
+type Probe struct {
+	Name string
+	// Check makes a lightweight call to the provider, returning nil when it
+	// is up
+	Check func(ctx context.Context) error
+}
+
+// StatusRecorder records whether each provider is up, such as the health
+// metrics
+type StatusRecorder interface {
+	SetExternalAPIStatus(name string, up bool)
+}
+
+// Prober runs its probes concurrently every interval, each bounded by the
+// timeout, and logs the providers going down and coming back up.
+type Prober struct {
+	interval time.Duration
+	timeout  time.Duration
+	status   StatusRecorder
+	logger   *logger.Logger
+	probes   []Probe
+
+	mu     sync.Mutex
+	up     map[string]bool
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewProber creates a prober of the probes
+func NewProber(interval, timeout time.Duration, status StatusRecorder, log *logger.Logger, probes ...Probe) *Prober {
+	return &Prober{
+		interval: interval,
+		timeout:  timeout,
+		status:   status,
+		logger:   log,
+		probes:   probes,
+		up:       make(map[string]bool),
+	}
+}
+
+// Start probes now and then every interval until Stop
+func (p *Prober) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel, p.done = cancel, make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			p.ProbeAll(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop cancels the probes in flight and waits for them until ctx is done
+func (p *Prober) Stop(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+	p.cancel()
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ProbeAll runs every probe once and records the results
+func (p *Prober) ProbeAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, probe := range p.probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			probeCtx, cancel := context.WithTimeout(ctx, p.timeout)
+			defer cancel()
+			err := probe.Check(probeCtx)
+			if errors.Is(err, ErrNoProbe) || ctx.Err() != nil {
+				return
+			}
+			p.record(probe.Name, err)
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *Prober) record(name string, err error) {
+	up := err == nil
+	p.status.SetExternalAPIStatus(name, up)
+
+	p.mu.Lock()
+	previous, probed := p.up[name]
+	p.up[name] = up
+	p.mu.Unlock()
+
+	switch {
+	case !up && (!probed || previous):
+		p.logger.WithError(err).WithField("provider", name).Warn("Provider health probe failed")
+	case up && probed && !previous:
+		p.logger.WithField("provider", name).Info("Provider is reachable again")
+	}
+}
@@ -0,0 +1,82 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statuses records the status of each provider
+type statuses struct {
+	mu sync.Mutex
+	up map[string]bool
+}
+
+func (s *statuses) SetExternalAPIStatus(name string, up bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.up[name] = up
+}
+
+func (s *statuses) get(name string) (bool, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	up, ok := s.up[name]
+	return up, ok
+}
+
+func TestProber_ProbeAll(t *testing.T) {
+	status := &statuses{up: map[string]bool{}}
+	prober := NewProber(time.Minute, 50*time.Millisecond, status, logger.NewLogger(),
+		Probe{Name: "payment", Check: func(ctx context.Context) error { return nil }},
+		Probe{Name: "email", Check: func(ctx context.Context) error { return errors.New("status 503") }},
+		Probe{Name: "slow", Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+		Probe{Name: "fake", Check: func(ctx context.Context) error { return ErrNoProbe }},
+	)
+
+	prober.ProbeAll(context.Background())
+
+	assert.Equal(t, map[string]bool{"payment": true, "email": false, "slow": false}, status.up)
+}
+
+func TestProber_StartStop(t *testing.T) {
+	status := &statuses{up: map[string]bool{}}
+	var mu sync.Mutex
+	var err error
+	prober := NewProber(10*time.Millisecond, time.Second, status, logger.NewLogger(),
+		Probe{Name: "payment", Check: func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return err
+		}},
+	)
+
+	prober.Start()
+	require.Eventually(t, func() bool {
+		up, ok := status.get("payment")
+		return ok && up
+	}, time.Second, 5*time.Millisecond)
+
+	// The next probes see the outage
+	mu.Lock()
+	err = errors.New("connection refused")
+	mu.Unlock()
+	require.Eventually(t, func() bool {
+		up, _ := status.get("payment")
+		return !up
+	}, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, prober.Stop(ctx))
+}
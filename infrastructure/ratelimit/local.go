@@ -0,0 +1,68 @@
+// Package ratelimit keeps the token buckets of the rate limiter, in memory
+// or in Redis to share them between instances.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Local keeps token buckets and in-flight requests in memory, so each
+// instance limits the requests it receives on its own. It is safe for
+// concurrent use.
+type Local struct {
+	mu       sync.Mutex
+	buckets  map[string]*rate.Limiter
+	inFlight map[string]int
+}
+
+// NewLocal creates an empty set of in-memory buckets
+func NewLocal() *Local {
+	return &Local{
+		buckets:  make(map[string]*rate.Limiter),
+		inFlight: make(map[string]int),
+	}
+}
+
+// Take takes a token from the bucket of key, refilled at limit per second up
+// to burst, and returns whether there was one and the tokens left
+func (l *Local) Take(ctx context.Context, key string, limit rate.Limit, burst int) (bool, float64) {
+	l.mu.Lock()
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = rate.NewLimiter(limit, burst)
+		l.buckets[key] = bucket
+	}
+	l.mu.Unlock()
+
+	now := time.Now()
+	allowed := bucket.AllowN(now, 1)
+	return allowed, bucket.TokensAt(now)
+}
+
+// Acquire takes one of limit slots of key for a request in flight, and
+// returns whether there was one and the func releasing it. Slots are held
+// until released; ttl only applies to slots kept in Redis.
+func (l *Local) Acquire(ctx context.Context, key string, limit int, ttl time.Duration) (func(), bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight[key] >= limit {
+		return nil, false
+	}
+	l.inFlight[key]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			if l.inFlight[key]--; l.inFlight[key] <= 0 {
+				delete(l.inFlight, key)
+			}
+		})
+	}, true
+}
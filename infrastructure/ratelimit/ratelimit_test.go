@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/pkg/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal_Take(t *testing.T) {
+	l := NewLocal()
+	ctx := context.Background()
+
+	allowed, tokens := l.Take(ctx, "anonymous", 1, 2)
+	assert.True(t, allowed)
+	assert.InDelta(t, 1, tokens, 0.01)
+	allowed, _ = l.Take(ctx, "anonymous", 1, 2)
+	assert.True(t, allowed)
+	allowed, _ = l.Take(ctx, "anonymous", 1, 2)
+	assert.False(t, allowed)
+
+	allowed, _ = l.Take(ctx, "authenticated", 1, 2)
+	assert.True(t, allowed, "buckets are kept by key")
+}
+
+// serveReplies answers every command read from a connection with reply
+func serveReplies(t *testing.T, reply string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					// A command is an array of bulk strings, each a length line and a value line
+					header, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					args, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+					for i := 0; i < 2*args; i++ {
+						if _, err := r.ReadString('\n'); err != nil {
+							return
+						}
+					}
+					conn.Write([]byte(reply))
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestRedis_Take(t *testing.T) {
+	addr := serveReplies(t, "*2\r\n:1\r\n$3\r\n4.5\r\n")
+	limiter := NewRedis(redis.New(redis.Options{Addr: addr, Timeout: time.Second}), "ratelimit:", time.Minute, logger.NewLogger(), nil)
+	defer limiter.Close()
+
+	allowed, tokens := limiter.Take(context.Background(), "anonymous", 10, 5)
+
+	assert.True(t, allowed)
+	assert.Equal(t, 4.5, tokens)
+}
+
+func TestRedis_FallsBackToLocal(t *testing.T) {
+	// Nothing listens on a closed listener's address
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	var statuses []bool
+	limiter := NewRedis(redis.New(redis.Options{Addr: addr, DialTimeout: time.Second}), "ratelimit:", time.Minute, logger.NewLogger(), func(available bool) {
+		statuses = append(statuses, available)
+	})
+	defer limiter.Close()
+	ctx := context.Background()
+
+	allowed, _ := limiter.Take(ctx, "anonymous", 1, 1)
+	assert.True(t, allowed)
+	allowed, _ = limiter.Take(ctx, "anonymous", 1, 1)
+	assert.False(t, allowed, "the local bucket still limits")
+	assert.Equal(t, []bool{false}, statuses, "reported once")
+}
+
+func TestLocal_Acquire(t *testing.T) {
+	l := NewLocal()
+	ctx := context.Background()
+
+	release, ok := l.Acquire(ctx, "orders:1", 1, time.Minute)
+	require.True(t, ok)
+	_, ok = l.Acquire(ctx, "orders:1", 1, time.Minute)
+	assert.False(t, ok)
+	_, ok = l.Acquire(ctx, "orders:2", 1, time.Minute)
+	assert.True(t, ok, "slots are kept by key")
+
+	release()
+	release()
+	_, ok = l.Acquire(ctx, "orders:1", 1, time.Minute)
+	assert.True(t, ok)
+	_, ok = l.Acquire(ctx, "orders:1", 1, time.Minute)
+	assert.False(t, ok, "releasing twice frees one slot")
+}
+
+func TestRedis_Acquire(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    string
+		acquired bool
+	}{
+		{"free", "+OK\r\n", true},
+		{"held", "$-1\r\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := serveReplies(t, tt.reply)
+			limiter := NewRedis(redis.New(redis.Options{Addr: addr, Timeout: time.Second}), "ratelimit:", time.Minute, logger.NewLogger(), nil)
+			defer limiter.Close()
+
+			release, ok := limiter.Acquire(context.Background(), "orders:1", 2, time.Minute)
+
+			assert.Equal(t, tt.acquired, ok)
+			if ok {
+				release()
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/pkg/circuitbreaker"
+	"boilerplate-go/pkg/redis"
+
+	"golang.org/x/time/rate"
+)
+
+// tokenBucket refills the bucket at KEYS[1] for the time since it was last
+// taken from, at ARGV[1] tokens per second up to ARGV[2], and takes a token
+// if there is one. It returns whether it did and the tokens left, as a
+// string to keep the fraction. The clock of Redis is used, so instances
+// with skewed clocks agree. Idle buckets expire once they would be full.
+var tokenBucket = redis.NewScript(`
+redis.replicate_commands()
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'at')
+local tokens = tonumber(state[1]) or burst
+local at = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - at) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'at', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// releaseLock deletes the lock at KEYS[1] if it still holds ARGV[1], so a lock
+// that expired and was taken by another request is left alone
+var releaseLock = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Redis keeps token buckets and locks on in-flight requests in Redis, shared
+// by every instance, so the limits apply to all replicas together. While
+// Redis fails, requests are limited by the local buckets and slots of each
+// instance instead, and Redis is tried again after a pause.
+type Redis struct {
+	client   *redis.Client
+	prefix   string
+	fallback *Local
+	breaker  *circuitbreaker.Breaker
+	logger   *logger.Logger
+	// onStatus is told when Redis becomes unavailable and available again
+	onStatus    func(available bool)
+	unavailable atomic.Bool
+}
+
+// NewRedis creates buckets in Redis under keys starting with prefix. After
+// three consecutive failures Redis is left alone for retryAfter. onStatus
+// may be nil.
+func NewRedis(client *redis.Client, prefix string, retryAfter time.Duration, log *logger.Logger, onStatus func(available bool)) *Redis {
+	return &Redis{
+		client:   client,
+		prefix:   prefix,
+		fallback: NewLocal(),
+		breaker:  circuitbreaker.New(3, retryAfter),
+		logger:   log,
+		onStatus: onStatus,
+	}
+}
+
+// Take takes a token from the bucket of key, refilled at limit per second up
+// to burst, and returns whether there was one and the tokens left. It falls
+// back to the local bucket when Redis fails.
+func (r *Redis) Take(ctx context.Context, key string, limit rate.Limit, burst int) (bool, float64) {
+	var allowed bool
+	var tokens float64
+	err := r.breaker.Execute(func() error {
+		reply, err := tokenBucket.Run(ctx, r.client, []string{r.prefix + key}, float64(limit), burst)
+		if err != nil {
+			return err
+		}
+		allowed, tokens, err = parseTake(reply)
+		return err
+	})
+	if err != nil {
+		r.setAvailable(false, err)
+		return r.fallback.Take(ctx, key, limit, burst)
+	}
+	r.setAvailable(true, nil)
+	return allowed, tokens
+}
+
+// Acquire takes one of limit slots of key for a request in flight, as a lock
+// expiring after ttl in case the instance holding it dies, and returns
+// whether there was one and the func releasing it. It falls back to the
+// local slots when Redis fails.
+func (r *Redis) Acquire(ctx context.Context, key string, limit int, ttl time.Duration) (func(), bool) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return r.fallback.Acquire(ctx, key, limit, ttl)
+	}
+	holder := hex.EncodeToString(token)
+
+	for slot := 0; slot < limit; slot++ {
+		lock := fmt.Sprintf("%s%s:%d", r.prefix, key, slot)
+		var acquired bool
+		err := r.breaker.Execute(func() error {
+			reply, err := r.client.Do(ctx, "SET", lock, holder, "NX", "PX", ttl.Milliseconds())
+			acquired = reply == "OK"
+			return err
+		})
+		if err != nil {
+			r.setAvailable(false, err)
+			return r.fallback.Acquire(ctx, key, limit, ttl)
+		}
+		r.setAvailable(true, nil)
+		if acquired {
+			return func() { r.release(lock, holder) }, true
+		}
+	}
+	return nil, false
+}
+
+// release releases a lock even when the request was cancelled. A lock that
+// cannot be released expires.
+func (r *Redis) release(lock, holder string) {
+	err := r.breaker.Execute(func() error {
+		_, err := releaseLock.Run(context.Background(), r.client, []string{lock}, holder)
+		return err
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("lock", lock).Warn("Failed to release in-flight request lock, it is held until it expires")
+	}
+}
+
+// Close closes the connections to Redis
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
+
+// setAvailable logs and reports when Redis becomes unavailable or available
+// again
+func (r *Redis) setAvailable(available bool, err error) {
+	if r.unavailable.Swap(!available) == !available {
+		return
+	}
+	if available {
+		r.logger.Info("Rate limiting uses Redis again")
+	} else {
+		r.logger.WithError(err).Warn("Redis unavailable, rate limiting falls back to the buckets of each instance")
+	}
+	if r.onStatus != nil {
+		r.onStatus(available)
+	}
+}
+
+func parseTake(reply interface{}) (bool, float64, error) {
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limit reply %v", reply)
+	}
+	allowed, _ := values[0].(int64)
+	left, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(left, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("unexpected rate limit tokens %q", left)
+	}
+	return allowed == 1, tokens, nil
+}
@@ -0,0 +1,136 @@
+package startup
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+)
+
+// Dependency is a service the application needs at startup
+type Dependency struct {
+	Name string
+	// Critical dependencies abort startup when they stay unavailable. Others
+	// let the application start degraded and keep being retried.
+	Critical bool
+	// Check connects to the dependency, returning nil once it is available
+	Check func(ctx context.Context) error
+	// OnStatus, when set, is called with false when the application starts
+	// without the dependency and with true once it is available
+	OnStatus func(available bool)
+}
+
+// Pinger is implemented by providers that can check their server is reachable
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Waiter waits for dependencies to become available, so the application can
+// start before its database or brokers are up, as happens when containers are
+// started together.
+type Waiter struct {
+	config config.StartupConfig
+	logger *logger.Logger
+}
+
+// NewWaiter creates a new dependency waiter
+func NewWaiter(cfg config.StartupConfig, log *logger.Logger) *Waiter {
+	return &Waiter{
+		config: cfg,
+		logger: log,
+	}
+}
+
+// Wait checks the dependencies concurrently, retrying each with exponential
+// backoff until it is available or MaxWait has passed. It returns the names of
+// the non-critical dependencies still unavailable, which keep being retried
+// in the background until ctx is done, and an error naming the critical
+// dependencies still unavailable.
+func (w *Waiter) Wait(ctx context.Context, dependencies ...Dependency) ([]string, error) {
+	deadline := time.Now().Add(w.config.MaxWait)
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		degraded []string
+		failed   []string
+	)
+	for _, dependency := range dependencies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := w.retry(ctx, dependency, deadline)
+			if err == nil {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			entry := w.logger.WithContext(ctx).WithError(err).WithField("dependency", dependency.Name)
+			if dependency.Critical {
+				entry.Error("Critical dependency unavailable")
+				failed = append(failed, dependency.Name)
+				return
+			}
+			entry.Warn("Dependency unavailable, starting degraded")
+			degraded = append(degraded, dependency.Name)
+			if dependency.OnStatus != nil {
+				dependency.OnStatus(false)
+			}
+			go w.retry(ctx, dependency, time.Time{})
+		}()
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return degraded, fmt.Errorf("dependencies unavailable after %s: %s", w.config.MaxWait, strings.Join(failed, ", "))
+	}
+	return degraded, nil
+}
+
+// retry checks a dependency until it is available, ctx is done, or the
+// deadline passes. A zero deadline retries until ctx is done.
+func (w *Waiter) retry(ctx context.Context, dependency Dependency, deadline time.Time) error {
+	backoff := w.config.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		checkCtx, cancel := context.WithTimeout(ctx, w.config.CheckTimeout)
+		err := dependency.Check(checkCtx)
+		cancel()
+		if err == nil {
+			if attempt > 1 {
+				w.logger.WithContext(ctx).WithFields(map[string]interface{}{
+					"dependency": dependency.Name,
+					"attempts":   attempt,
+				}).Info("Dependency available")
+			}
+			if dependency.OnStatus != nil {
+				dependency.OnStatus(true)
+			}
+			return nil
+		}
+
+		// Jitter keeps replicas started together from retrying in lockstep
+		delay := backoff/2 + rand.N(backoff/2+1)
+		if !deadline.IsZero() && time.Now().Add(delay).After(deadline) {
+			return err
+		}
+		w.logger.WithContext(ctx).WithError(err).WithFields(map[string]interface{}{
+			"dependency": dependency.Name,
+			"attempt":    attempt,
+			"retry_in":   delay.String(),
+		}).Warn("Dependency not available yet")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		backoff = min(backoff*2, w.config.MaxBackoff)
+	}
+}
@@ -0,0 +1,119 @@
+package startup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = config.StartupConfig{
+	MaxWait:        200 * time.Millisecond,
+	InitialBackoff: 5 * time.Millisecond,
+	MaxBackoff:     20 * time.Millisecond,
+	CheckTimeout:   time.Second,
+}
+
+// availableAfter fails its first n checks
+func availableAfter(n int32) (func(context.Context) error, *atomic.Int32) {
+	var calls atomic.Int32
+	return func(ctx context.Context) error {
+		if calls.Add(1) <= n {
+			return errors.New("connection refused")
+		}
+		return nil
+	}, &calls
+}
+
+func TestWaiter_RetriesUntilAvailable(t *testing.T) {
+	check, calls := availableAfter(3)
+
+	degraded, err := NewWaiter(testConfig, logger.NewLogger()).Wait(context.Background(), Dependency{
+		Name:     "database",
+		Critical: true,
+		Check:    check,
+	})
+
+	assert.NoError(t, err)
+	assert.Empty(t, degraded)
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestWaiter_CriticalUnavailable(t *testing.T) {
+	check, _ := availableAfter(1000)
+	start := time.Now()
+
+	_, err := NewWaiter(testConfig, logger.NewLogger()).Wait(context.Background(), Dependency{
+		Name:     "database",
+		Critical: true,
+		Check:    check,
+	})
+
+	assert.ErrorContains(t, err, "database")
+	assert.Less(t, time.Since(start), testConfig.MaxWait+100*time.Millisecond)
+}
+
+func TestWaiter_DegradedStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		statuses []bool
+	)
+	var up atomic.Bool
+	dbCheck, _ := availableAfter(0)
+
+	degraded, err := NewWaiter(testConfig, logger.NewLogger()).Wait(ctx,
+		Dependency{Name: "database", Critical: true, Check: dbCheck},
+		Dependency{
+			Name: "scanner",
+			Check: func(ctx context.Context) error {
+				if !up.Load() {
+					return errors.New("connection refused")
+				}
+				return nil
+			},
+			OnStatus: func(available bool) {
+				mu.Lock()
+				defer mu.Unlock()
+				statuses = append(statuses, available)
+			},
+		},
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"scanner"}, degraded)
+
+	// The scanner keeps being retried after startup
+	up.Store(true)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(statuses) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []bool{false, true}, statuses)
+}
+
+func TestWaiter_NoWait(t *testing.T) {
+	check, calls := availableAfter(1)
+	cfg := testConfig
+	cfg.MaxWait = 0
+
+	_, err := NewWaiter(cfg, logger.NewLogger()).Wait(context.Background(), Dependency{
+		Name:     "database",
+		Critical: true,
+		Check:    check,
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
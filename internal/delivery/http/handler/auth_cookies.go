@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/delivery/http/middleware"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// signInCookies sets the authentication cookies of a sign-in when cookie
+// authentication is on, answering 500 when they cannot be set
+func signInCookies(c *gin.Context, cookies *middleware.AuthCookies, log *logger.Logger, login *entity.LoginResponse) bool {
+	if cookies == nil {
+		return true
+	}
+	if err := cookies.SignIn(c, login); err != nil {
+		log.ErrorLogger(c.Request.Context(), err, "Failed to set session cookies", map[string]interface{}{
+			"user_id": login.User.ID,
+		})
+		response.InternalServerError(c, "Login failed", err.Error())
+		return false
+	}
+	return true
+}
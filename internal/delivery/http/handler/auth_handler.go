@@ -3,10 +3,16 @@ package handler
 import (
 	"boilerplate-go/infrastructure/logger"
 	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/delivery/http/middleware"
+	"boilerplate-go/internal/delivery/http/route"
 	"boilerplate-go/internal/domain/entity"
 	"boilerplate-go/internal/usecase/auth"
+	"boilerplate-go/pkg/errors"
 	"boilerplate-go/pkg/response"
+	stderrors "errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,22 +20,177 @@ import (
 // AuthHandler handles authentication HTTP requests
 type AuthHandler struct {
 	authUsecase *auth.AuthUsecase
+	captcha     *CaptchaGuard
+	cookies     *middleware.AuthCookies
 	logger      *logger.Logger
 	metrics     *metrics.Metrics
 }
 
-// NewAuthHandler creates a new authentication handler
-func NewAuthHandler(authUsecase *auth.AuthUsecase, log *logger.Logger, m *metrics.Metrics) *AuthHandler {
+// NewAuthHandler creates a new authentication handler.
+// The CAPTCHA guard is optional and may be nil.
+func NewAuthHandler(authUsecase *auth.AuthUsecase, captcha *CaptchaGuard, log *logger.Logger, m *metrics.Metrics) *AuthHandler {
 	return &AuthHandler{
 		authUsecase: authUsecase,
+		captcha:     captcha,
 		logger:      log,
 		metrics:     m,
 	}
 }
 
+// SetCookies makes sign-ins set the session cookies of browser clients
+func (h *AuthHandler) SetCookies(cookies *middleware.AuthCookies) {
+	h.cookies = cookies
+}
+
+// Routes registers the public authentication routes and signing keys
+func (h *AuthHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			// Public signing keys for token verification by other services
+			Prefix: "/.well-known",
+			Cache:  5 * time.Minute,
+			Register: func(r gin.IRoutes) {
+				r.GET("/jwks.json", h.JWKS)
+			},
+		},
+		{
+			Prefix: "/api/v1/auth",
+			Register: func(r gin.IRoutes) {
+				r.POST("/register", h.Register)
+				r.POST("/register/invitation", h.RegisterWithInvitation)
+				r.POST("/login", h.Login)
+				r.POST("/otp/request", h.RequestOTP)
+				r.POST("/otp/verify", h.VerifyOTP)
+				r.GET("/csrf", h.CSRFToken)
+			},
+		},
+		{
+			Prefix: "/api/v1/user",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeSessions},
+			Dedup:  true,
+			Register: func(r gin.IRoutes) {
+				r.POST("/tokens", h.IssueAccessToken)
+			},
+		},
+		{
+			Prefix: "/api/v1/admin/users",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeAdmin, entity.ScopeImpersonate},
+			Register: func(r gin.IRoutes) {
+				r.POST("/:id/impersonate", h.Impersonate)
+			},
+		},
+	}
+}
+
+// IssueAccessToken godoc
+// @Summary      Issue a scoped access token
+// @Description  Issue a token limited to some of the scopes of the caller's token, such as a machine token that may only read orders (orders:read). The token is a session of its own and is revoked like the others
+// @Tags         authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      entity.AccessTokenRequest  true  "Token name, scopes and lifetime in seconds"
+// @Success      201      {object}  response.Response{data=entity.AccessTokenResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/user/tokens [post]
+func (h *AuthHandler) IssueAccessToken(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.AccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	granted, _ := c.Get("scopes")
+	scopes, _ := granted.([]string)
+	token, err := h.authUsecase.IssueAccessToken(ctx, c.GetInt("user_id"), scopes, &req, c.ClientIP())
+	if err != nil {
+		if errors.IsInvalidTokenRequest(err) {
+			response.BadRequest(c, "Invalid access token request", err.Error())
+			return
+		}
+		if errors.IsScopeNotGranted(err) {
+			response.Forbidden(c, "Insufficient permissions", err.Error())
+			return
+		}
+		h.logger.ErrorLogger(ctx, err, "Failed to issue access token", map[string]interface{}{
+			"user_id": c.GetInt("user_id"),
+		})
+		response.InternalServerError(c, "Failed to issue access token", err.Error())
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id": c.GetInt("user_id"),
+		"name":    token.Name,
+		"scopes":  token.Scopes,
+		"action":  "access_token_issued",
+	}).Info("Access token issued")
+
+	response.Success(c, http.StatusCreated, "Access token issued successfully", token)
+}
+
+// Impersonate godoc
+// @Summary      Impersonate a user
+// @Description  Issue a short-lived token acting as the user, for support staff holding the users:impersonate scope. The token carries the impersonator in its act claim, cannot manage sessions or tokens, and every request made with it is audit-logged. Only users with the user role can be impersonated
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "User ID"
+// @Success      201  {object}  response.Response{data=entity.ImpersonationResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/users/{id}/impersonate [post]
+func (h *AuthHandler) Impersonate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", err.Error())
+		return
+	}
+
+	impersonatorID := c.GetInt("user_id")
+	token, err := h.authUsecase.Impersonate(ctx, impersonatorID, c.GetString("username"), userID, c.ClientIP())
+	if err != nil {
+		if errors.IsUserNotFound(err) {
+			response.Error(c, http.StatusNotFound, "User not found", err.Error())
+			return
+		}
+		if errors.IsCannotImpersonate(err) {
+			response.Forbidden(c, "User cannot be impersonated", err.Error())
+			return
+		}
+		h.logger.ErrorLogger(ctx, err, "Failed to impersonate user", map[string]interface{}{
+			"impersonator_id": impersonatorID,
+			"user_id":         userID,
+		})
+		response.InternalServerError(c, "Failed to impersonate user", err.Error())
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"impersonator_id": impersonatorID,
+		"user_id":         userID,
+		"expires_at":      token.ExpiresAt,
+		"action":          "impersonation_started",
+	}).Warn("Impersonation token issued")
+
+	response.Success(c, http.StatusCreated, "Impersonation token issued successfully", token)
+}
+
 // Register godoc
 // @Summary      Register a new user
-// @Description  Create a new user account with username, email and password
+// @Description  Create a new user account with username, email and password. The username is unique regardless of case and must meet the username policy; rule violations are listed in errors
 // @Tags         authentication
 // @Accept       json
 // @Produce      json
@@ -46,7 +207,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.WithContext(ctx).WithError(err).Warn("Invalid registration request payload")
 		h.metrics.RecordAuthAttempt("register", false)
-		response.BadRequest(c, "Invalid request body", err.Error())
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	if !h.verifyCaptcha(c, req.Captcha, "register") {
 		return
 	}
 
@@ -59,6 +224,10 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	user, err := h.authUsecase.Register(ctx, &req)
 	if err != nil {
+		if invalidUsername(c, "Registration failed", err) || weakPassword(c, "Registration failed", "password", err) {
+			h.metrics.RecordAuthAttempt("register", false)
+			return
+		}
 		h.logger.ErrorLogger(ctx, err, "Registration failed", map[string]interface{}{
 			"username": req.Username,
 			"email":    req.Email,
@@ -86,9 +255,69 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	response.Success(c, http.StatusCreated, "User registered successfully", user)
 }
 
+// RegisterWithInvitation godoc
+// @Summary      Sign up with an invitation
+// @Description  Create an account for the email address of an organization invitation and join the organization with the invited role. Send the token, expires and signature parameters of the emailed link; no CAPTCHA is required. Should joining fail once the account is created, join_error is set instead of organization and the invitation stays pending: sign in and accept it with POST /api/v1/organizations/invitations/accept
+// @Tags         authentication
+// @Accept       json
+// @Produce      json
+// @Param        request  body      entity.InvitedRegisterRequest  true  "Username, password and invitation link parameters"
+// @Success      201      {object}  response.Response{data=entity.InvitedRegistration}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/auth/register/invitation [post]
+func (h *AuthHandler) RegisterWithInvitation(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.InvitedRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.metrics.RecordAuthAttempt("register", false)
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	registration, err := h.authUsecase.RegisterWithInvitation(ctx, &req)
+	if err != nil {
+		h.metrics.RecordAuthAttempt("register", false)
+		switch {
+		case invalidUsername(c, "Registration failed", err):
+		case weakPassword(c, "Registration failed", "password", err):
+		case errors.IsInvitationNotFound(err):
+			response.Error(c, http.StatusNotFound, "Invitation not found", err.Error())
+		case stderrors.Is(err, errors.ErrUserAlreadyExists):
+			response.Error(c, http.StatusConflict, "Registration failed", err.Error())
+		default:
+			h.logger.ErrorLogger(ctx, err, "Registration with invitation failed", map[string]interface{}{
+				"username": req.Username,
+			})
+			response.InternalServerError(c, "Registration failed", err.Error())
+		}
+		return
+	}
+
+	fields := map[string]interface{}{
+		"user_id":  registration.User.ID,
+		"username": registration.User.Username,
+		"action":   "register_invited",
+	}
+	if registration.Organization != nil {
+		fields["organization_id"] = registration.Organization.ID
+	}
+	h.logger.WithContext(ctx).WithFields(fields).Info("User registered with invitation")
+
+	h.metrics.RecordAuthAttempt("register", true)
+	if registration.JoinError != "" {
+		response.Success(c, http.StatusCreated, "User registered, but joining the organization failed", registration)
+		return
+	}
+	response.Success(c, http.StatusCreated, "User registered successfully", registration)
+}
+
 // Login godoc
 // @Summary      User login
-// @Description  Authenticate user and return JWT token
+// @Description  Authenticate user and return JWT token. With AUTH_MODE=cookie or both, the session and CSRF cookies are set too, and in cookie mode the token is left out. When sign-in verification is enabled, a sign-in from a new device or country of a user with a verified phone is answered with 403 and a code is texted; repeat the login with verification_code set. Deactivated users are answered with 403
 // @Tags         authentication
 // @Accept       json
 // @Produce      json
@@ -96,6 +325,8 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Success      200      {object}  response.Response{data=entity.LoginResponse}
 // @Failure      400      {object}  response.Response
 // @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      429      {object}  response.Response
 // @Failure      500      {object}  response.Response
 // @Router       /api/v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -105,7 +336,14 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.WithContext(ctx).WithError(err).Warn("Invalid login request payload")
 		h.metrics.RecordAuthAttempt("login", false)
-		response.BadRequest(c, "Invalid request body", err.Error())
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	if !h.verifyCaptcha(c, req.Captcha, "login") {
 		return
 	}
 
@@ -116,15 +354,39 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}).Info("User login attempt")
 
 	loginResponse, err := h.authUsecase.Login(ctx, &req)
+	if errors.IsLoginUnverified(err) {
+		// The credentials were right, so this is not a failure for CAPTCHA
+		h.metrics.RecordAuthAttempt("login", false)
+		response.Error(c, http.StatusForbidden, "Sign-in verification required", err.Error())
+		return
+	}
+	if errors.IsOTPRateLimited(err) {
+		h.metrics.RecordAuthAttempt("login", false)
+		response.Error(c, http.StatusTooManyRequests, "Login failed", err.Error())
+		return
+	}
+	if errors.IsUserDeactivated(err) {
+		h.metrics.RecordAuthAttempt("login", false)
+		response.Error(c, http.StatusForbidden, "Account is deactivated", err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.ErrorLogger(ctx, err, "Login failed", map[string]interface{}{
 			"username": req.Username,
 		})
 		h.metrics.RecordAuthAttempt("login", false)
+		h.captcha.RecordFailure(req.IPAddress, req.Username)
 		response.Unauthorized(c, "Login failed", err.Error())
 		return
 	}
 
+	h.captcha.Reset(req.IPAddress, req.Username)
+
+	if !signInCookies(c, h.cookies, h.logger, loginResponse) {
+		h.metrics.RecordAuthAttempt("login", false)
+		return
+	}
+
 	// Log successful login
 	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
 		"user_id":  loginResponse.User.ID,
@@ -135,3 +397,136 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	h.metrics.RecordAuthAttempt("login", true)
 	response.Success(c, http.StatusOK, "Login successful", loginResponse)
 }
+
+// RequestOTP godoc
+// @Summary      Request a login code
+// @Description  Text a one-time login code to a verified phone number. Unknown numbers are accepted without sending anything
+// @Tags         authentication
+// @Accept       json
+// @Produce      json
+// @Param        request  body      entity.OTPRequest  true  "Phone number in E.164 format"
+// @Success      202      {object}  response.Response
+// @Failure      400      {object}  response.Response
+// @Failure      429      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/auth/otp/request [post]
+func (h *AuthHandler) RequestOTP(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.OTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(ctx).WithError(err).Warn("Invalid login code request payload")
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	if err := h.authUsecase.RequestOTPLogin(ctx, &req); err != nil {
+		h.logger.ErrorLogger(ctx, err, "Failed to send login code", nil)
+		response.Error(c, otpErrorStatus(err), "Failed to send login code", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "If the number is registered, a login code has been sent", nil)
+}
+
+// VerifyOTP godoc
+// @Summary      Log in with a code
+// @Description  Exchange a one-time login code for a JWT token. With AUTH_MODE=cookie or both, the session and CSRF cookies are set too, and in cookie mode the token is left out
+// @Tags         authentication
+// @Accept       json
+// @Produce      json
+// @Param        request  body      entity.OTPVerifyRequest  true  "Phone number and code"
+// @Success      200      {object}  response.Response{data=entity.LoginResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      429      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/auth/otp/verify [post]
+func (h *AuthHandler) VerifyOTP(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.OTPVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithContext(ctx).WithError(err).Warn("Invalid login code payload")
+		h.metrics.RecordAuthAttempt("otp_login", false)
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	loginResponse, err := h.authUsecase.VerifyOTPLogin(ctx, &req)
+	if err != nil {
+		h.logger.ErrorLogger(ctx, err, "Login with code failed", nil)
+		h.metrics.RecordAuthAttempt("otp_login", false)
+		response.Error(c, otpErrorStatus(err), "Login failed", err.Error())
+		return
+	}
+
+	if !signInCookies(c, h.cookies, h.logger, loginResponse) {
+		h.metrics.RecordAuthAttempt("otp_login", false)
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id":  loginResponse.User.ID,
+		"username": loginResponse.User.Username,
+		"action":   "otp_login_success",
+	}).Info("User logged in with one-time code")
+
+	h.metrics.RecordAuthAttempt("otp_login", true)
+	response.Success(c, http.StatusOK, "Login successful", loginResponse)
+}
+
+// CSRFToken godoc
+// @Summary      Get a CSRF token
+// @Description  Get the CSRF token of the browser, also set in the CSRF cookie, to send in the X-CSRF-Token header of state-changing requests. Before signing in the token is bound to no session; signing in replaces it. Only served with AUTH_MODE=cookie or both
+// @Tags         authentication
+// @Produce      json
+// @Success      200  {object}  response.Response{data=entity.CSRFTokenResponse}
+// @Failure      404  {object}  response.Response
+// @Router       /api/v1/auth/csrf [get]
+func (h *AuthHandler) CSRFToken(c *gin.Context) {
+	if h.cookies == nil {
+		response.Error(c, http.StatusNotFound, "Cookie authentication is disabled", "set AUTH_MODE to cookie or both")
+		return
+	}
+	response.Success(c, http.StatusOK, "CSRF token retrieved successfully", entity.CSRFTokenResponse{
+		CSRFToken: c.GetString(middleware.CSRFTokenKey),
+	})
+}
+
+// JWKS godoc
+// @Summary      JSON Web Key Set
+// @Description  Public keys for verifying access tokens issued by this service
+// @Tags         authentication
+// @Produce      json
+// @Success      200  {object}  jwt.JWKS
+// @Router       /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.authUsecase.JWKS())
+}
+
+// verifyCaptcha enforces the CAPTCHA guard, writing an error response on failure
+func (h *AuthHandler) verifyCaptcha(c *gin.Context, token, action string) bool {
+	ctx := c.Request.Context()
+
+	err := h.captcha.Check(ctx, token, c.ClientIP())
+	if err == nil {
+		return true
+	}
+
+	h.metrics.RecordAuthAttempt(action, false)
+	h.logger.WithContext(ctx).WithError(err).WithFields(map[string]interface{}{
+		"action": action,
+	}).Warn("CAPTCHA verification failed")
+
+	if stderrors.Is(err, errCaptchaRequired) || stderrors.Is(err, errCaptchaInvalid) {
+		response.BadRequest(c, "CAPTCHA verification failed", err.Error())
+	} else {
+		response.Error(c, http.StatusServiceUnavailable, "CAPTCHA verification unavailable", err.Error())
+	}
+	return false
+}
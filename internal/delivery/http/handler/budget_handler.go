@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/budget"
+	"boilerplate-go/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BudgetHandler handles the admin provider budget HTTP requests
+type BudgetHandler struct {
+	budgetUsecase *budget.BudgetUsecase
+	logger        *logger.Logger
+}
+
+// NewBudgetHandler creates a new provider budget handler
+func NewBudgetHandler(budgetUsecase *budget.BudgetUsecase, log *logger.Logger) *BudgetHandler {
+	return &BudgetHandler{
+		budgetUsecase: budgetUsecase,
+		logger:        log,
+	}
+}
+
+// Routes registers the admin provider budget routes
+func (h *BudgetHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/admin",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeAdmin},
+			Register: func(r gin.IRoutes) {
+				r.GET("/provider-usage", h.GetProviderUsage)
+			},
+		},
+	}
+}
+
+// GetProviderUsage godoc
+// @Summary      Paid provider usage
+// @Description  Calls made to the payment, SMS and email providers per UTC day and their cost-equivalent, newest first, with the daily budgets. Emails count once per recipient; a zero budget is unlimited
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        days  query     int  false  "Days to return, today included (max 90)"  default(7)
+// @Success      200   {object}  response.Response{data=[]entity.ProviderUsage}
+// @Failure      400   {object}  response.Response
+// @Failure      401   {object}  response.Response
+// @Failure      403   {object}  response.Response
+// @Failure      500   {object}  response.Response
+// @Router       /api/v1/admin/provider-usage [get]
+func (h *BudgetHandler) GetProviderUsage(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var days int
+	if value := c.Query("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			response.BadRequest(c, "Invalid days", err.Error())
+			return
+		}
+		days = n
+	}
+
+	usage, err := h.budgetUsecase.Usage(ctx, days)
+	if err != nil {
+		h.logger.ErrorLogger(ctx, err, "Failed to get provider usage", nil)
+		response.InternalServerError(c, "Failed to get provider usage", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Provider usage retrieved successfully", usage)
+}
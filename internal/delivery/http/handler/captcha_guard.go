@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"boilerplate-go/internal/domain/provider"
+)
+
+const (
+	CaptchaModeOff      = "off"
+	CaptchaModeAlways   = "always"
+	CaptchaModeAdaptive = "adaptive"
+)
+
+var (
+	errCaptchaRequired = errors.New("captcha token required")
+	errCaptchaInvalid  = errors.New("captcha verification failed")
+)
+
+// CaptchaGuard decides when a CAPTCHA is required and verifies submitted tokens.
+// In adaptive mode it acts as a simple brute-force detector, tracking failed
+// attempts per client IP and only demanding a CAPTCHA once the threshold is hit.
+// Failures are remembered per account so that a successful login only clears
+// the failures against that account, not those against others from the same IP.
+type CaptchaGuard struct {
+	provider  provider.CaptchaProvider
+	mode      string
+	threshold int
+	window    time.Duration
+
+	mu        sync.Mutex
+	failures  map[string][]captchaFailure
+	lastSweep time.Time
+}
+
+type captchaFailure struct {
+	at      time.Time
+	account string
+}
+
+// NewCaptchaGuard creates a CAPTCHA guard. A nil provider or "off" mode disables it.
+func NewCaptchaGuard(captchaProvider provider.CaptchaProvider, mode string, threshold int, window time.Duration) *CaptchaGuard {
+	if captchaProvider == nil {
+		mode = CaptchaModeOff
+	}
+
+	return &CaptchaGuard{
+		provider:  captchaProvider,
+		mode:      mode,
+		threshold: threshold,
+		window:    window,
+		failures:  make(map[string][]captchaFailure),
+		lastSweep: time.Now(),
+	}
+}
+
+// Check verifies the token when the guard requires a CAPTCHA for the client.
+func (g *CaptchaGuard) Check(ctx context.Context, token, clientIP string) error {
+	if g == nil || !g.required(clientIP) {
+		return nil
+	}
+
+	if token == "" {
+		return errCaptchaRequired
+	}
+
+	result, err := g.provider.VerifyToken(ctx, token, clientIP)
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return errCaptchaInvalid
+	}
+
+	return nil
+}
+
+// RecordFailure registers a failed attempt from the client against the account.
+func (g *CaptchaGuard) RecordFailure(clientIP, account string) {
+	if g == nil || g.mode != CaptchaModeAdaptive {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	g.sweep(now)
+	g.failures[clientIP] = append(g.recentFailures(clientIP, now), captchaFailure{at: now, account: account})
+}
+
+// Reset clears the client's failures against the account after a successful attempt.
+func (g *CaptchaGuard) Reset(clientIP, account string) {
+	if g == nil || g.mode != CaptchaModeAdaptive {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	remaining := g.failures[clientIP][:0]
+	for _, f := range g.failures[clientIP] {
+		if f.account != account {
+			remaining = append(remaining, f)
+		}
+	}
+	if len(remaining) == 0 {
+		delete(g.failures, clientIP)
+	} else {
+		g.failures[clientIP] = remaining
+	}
+}
+
+func (g *CaptchaGuard) required(clientIP string) bool {
+	switch g.mode {
+	case CaptchaModeAlways:
+		return true
+	case CaptchaModeAdaptive:
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		recent := g.recentFailures(clientIP, time.Now())
+		if len(recent) == 0 {
+			delete(g.failures, clientIP)
+		} else {
+			g.failures[clientIP] = recent
+		}
+		return len(recent) >= g.threshold
+	default:
+		return false
+	}
+}
+
+// recentFailures returns the failures within the window; callers must hold mu.
+func (g *CaptchaGuard) recentFailures(clientIP string, now time.Time) []captchaFailure {
+	cutoff := now.Add(-g.window)
+	recent := g.failures[clientIP][:0]
+	for _, f := range g.failures[clientIP] {
+		if f.at.After(cutoff) {
+			recent = append(recent, f)
+		}
+	}
+	return recent
+}
+
+// sweep drops clients whose failures have all expired. It runs at most once per
+// window so that IPs that never return do not accumulate; callers must hold mu.
+func (g *CaptchaGuard) sweep(now time.Time) {
+	if now.Sub(g.lastSweep) < g.window {
+		return
+	}
+	g.lastSweep = now
+
+	for clientIP := range g.failures {
+		if recent := g.recentFailures(clientIP, now); len(recent) == 0 {
+			delete(g.failures, clientIP)
+		} else {
+			g.failures[clientIP] = recent
+		}
+	}
+}
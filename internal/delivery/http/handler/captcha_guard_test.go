@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"boilerplate-go/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCaptchaProvider is a mock implementation of CaptchaProvider
+type MockCaptchaProvider struct {
+	mock.Mock
+}
+
+func (m *MockCaptchaProvider) VerifyToken(ctx context.Context, token, remoteIP string) (*entity.CaptchaVerification, error) {
+	args := m.Called(ctx, token, remoteIP)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.CaptchaVerification), args.Error(1)
+}
+
+func TestCaptchaGuard_Modes(t *testing.T) {
+	ctx := context.Background()
+	captchaProvider := new(MockCaptchaProvider)
+	captchaProvider.On("VerifyToken", mock.Anything, "good", "198.51.100.1").Return(&entity.CaptchaVerification{Success: true}, nil)
+	captchaProvider.On("VerifyToken", mock.Anything, "bad", "198.51.100.1").Return(&entity.CaptchaVerification{Success: false}, nil)
+
+	off := NewCaptchaGuard(captchaProvider, CaptchaModeOff, 1, time.Minute)
+	assert.NoError(t, off.Check(ctx, "", "198.51.100.1"))
+
+	noProvider := NewCaptchaGuard(nil, CaptchaModeAlways, 1, time.Minute)
+	assert.NoError(t, noProvider.Check(ctx, "", "198.51.100.1"))
+
+	always := NewCaptchaGuard(captchaProvider, CaptchaModeAlways, 1, time.Minute)
+	assert.ErrorIs(t, always.Check(ctx, "", "198.51.100.1"), errCaptchaRequired)
+	assert.ErrorIs(t, always.Check(ctx, "bad", "198.51.100.1"), errCaptchaInvalid)
+	assert.NoError(t, always.Check(ctx, "good", "198.51.100.1"))
+}
+
+func TestCaptchaGuard_Adaptive(t *testing.T) {
+	ctx := context.Background()
+	guard := NewCaptchaGuard(new(MockCaptchaProvider), CaptchaModeAdaptive, 2, time.Minute)
+
+	guard.RecordFailure("198.51.100.1", "victim")
+	assert.NoError(t, guard.Check(ctx, "", "198.51.100.1"))
+
+	guard.RecordFailure("198.51.100.1", "victim")
+	assert.ErrorIs(t, guard.Check(ctx, "", "198.51.100.1"), errCaptchaRequired)
+	assert.NoError(t, guard.Check(ctx, "", "198.51.100.2"), "other clients are unaffected")
+
+	// Logging into another account from the same IP must not clear the failures
+	guard.Reset("198.51.100.1", "attacker")
+	assert.ErrorIs(t, guard.Check(ctx, "", "198.51.100.1"), errCaptchaRequired)
+
+	guard.Reset("198.51.100.1", "victim")
+	assert.NoError(t, guard.Check(ctx, "", "198.51.100.1"))
+}
+
+func TestCaptchaGuard_ExpiresFailures(t *testing.T) {
+	guard := NewCaptchaGuard(new(MockCaptchaProvider), CaptchaModeAdaptive, 1, time.Minute)
+
+	old := time.Now().Add(-2 * time.Minute)
+	guard.failures["198.51.100.1"] = []captchaFailure{{at: old, account: "user"}}
+	guard.failures["198.51.100.2"] = []captchaFailure{{at: old, account: "user"}}
+	guard.lastSweep = old
+
+	assert.NoError(t, guard.Check(context.Background(), "", "198.51.100.1"))
+
+	// Recording a failure sweeps idle clients that never came back
+	guard.RecordFailure("198.51.100.3", "user")
+	assert.NotContains(t, guard.failures, "198.51.100.1")
+	assert.NotContains(t, guard.failures, "198.51.100.2")
+	assert.Len(t, guard.failures["198.51.100.3"], 1)
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/delivery/http/middleware"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/configuration"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// publicConfigPath is the path of the public configuration
+const publicConfigPath = "/api/v1/config/public"
+
+// ConfigHandler handles the configuration HTTP requests
+type ConfigHandler struct {
+	configUsecase *configuration.ConfigUsecase
+	responses     *middleware.ResponseCache
+	logger        *logger.Logger
+}
+
+// NewConfigHandler creates a new configuration handler
+func NewConfigHandler(configUsecase *configuration.ConfigUsecase, log *logger.Logger) *ConfigHandler {
+	return &ConfigHandler{
+		configUsecase: configUsecase,
+		logger:        log,
+	}
+}
+
+// SetResponseCache lets applied provider profiles drop the cached public
+// configuration
+func (h *ConfigHandler) SetResponseCache(responses *middleware.ResponseCache) {
+	h.responses = responses
+}
+
+// Routes registers the public and admin configuration routes
+func (h *ConfigHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/config",
+			Cache:  time.Minute,
+			Register: func(r gin.IRoutes) {
+				r.GET("/public", h.GetPublicConfig)
+			},
+		},
+		{
+			Prefix: "/api/v1/admin",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeAdmin},
+			Dedup:  true,
+			Register: func(r gin.IRoutes) {
+				r.GET("/config", h.GetConfig)
+				r.GET("/config/providers", h.GetProviderProfile)
+				r.PUT("/config/providers", h.ApplyProviderProfile)
+				r.GET("/config/changes", h.ListConfigChanges)
+			},
+		},
+	}
+}
+
+// GetPublicConfig godoc
+// @Summary      Public configuration
+// @Description  The settings frontends need: the payment provider with its publishable key and supported currencies (empty accepts any), feature flags, the supported locales and the upload limits in bytes. Cached for a minute
+// @Tags         config
+// @Produce      json
+// @Success      200  {object}  response.Response{data=entity.PublicConfig}
+// @Router       /api/v1/config/public [get]
+func (h *ConfigHandler) GetPublicConfig(c *gin.Context) {
+	response.Success(c, http.StatusOK, "Public configuration retrieved successfully", h.configUsecase.Public())
+}
+
+// GetConfig godoc
+// @Summary      Effective configuration
+// @Description  The configuration in effect, including provider profiles applied at runtime, with secrets redacted. Durations are in nanoseconds
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=object}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Router       /api/v1/admin/config [get]
+func (h *ConfigHandler) GetConfig(c *gin.Context) {
+	response.Success(c, http.StatusOK, "Configuration retrieved successfully", h.configUsecase.Effective())
+}
+
+// GetProviderProfile godoc
+// @Summary      Export the provider profile
+// @Description  The payment, email and SMS provider settings in effect as a profile, keyed by environment variable, with secrets redacted. It can be edited and applied again; redacted secrets keep their value
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=entity.ProviderProfile}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Router       /api/v1/admin/config/providers [get]
+func (h *ConfigHandler) GetProviderProfile(c *gin.Context) {
+	response.Success(c, http.StatusOK, "Provider profile retrieved successfully", h.configUsecase.Profile())
+}
+
+// ApplyProviderProfile godoc
+// @Summary      Apply a provider profile
+// @Description  Validate the settings of the profile and replace the payment, email and SMS providers they change without a restart. Settings left out keep their value. The change is recorded with secrets redacted; it lasts until the server restarts
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      entity.ProviderProfile  true  "Profile name and settings"
+// @Success      200      {object}  response.Response{data=entity.ConfigChange}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/admin/config/providers [put]
+func (h *ConfigHandler) ApplyProviderProfile(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var profile entity.ProviderProfile
+	if err := c.ShouldBindJSON(&profile); err != nil {
+		response.InvalidRequest(c, "Invalid request format", err)
+		return
+	}
+
+	change, err := h.configUsecase.ApplyProfile(ctx, c.GetInt("user_id"), &profile)
+	if errors.IsInvalidSettings(err) {
+		response.BadRequest(c, "Invalid provider settings", err.Error())
+		return
+	}
+	// The profile is applied even when it could not be recorded
+	h.responses.Invalidate(publicConfigPath)
+	if err != nil {
+		h.logger.ErrorLogger(ctx, err, "Failed to apply provider profile", nil)
+		response.InternalServerError(c, "Failed to apply provider profile", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Provider profile applied successfully", change)
+}
+
+// ListConfigChanges godoc
+// @Summary      List configuration changes
+// @Description  The audit trail of provider profiles applied at runtime, newest first, with secrets redacted
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page       query     int  false  "Page number"  default(1)
+// @Param        page_size  query     int  false  "Page size (max 100)"  default(20)
+// @Success      200  {object}  response.Response{data=[]entity.ConfigChange}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/config/changes [get]
+func (h *ConfigHandler) ListConfigChanges(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var filter entity.ConfigChangeFilter
+	for param, target := range map[string]*int{
+		"page":      &filter.Page,
+		"page_size": &filter.PageSize,
+	} {
+		if value := c.Query(param); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				response.BadRequest(c, "Invalid "+param, err.Error())
+				return
+			}
+			*target = n
+		}
+	}
+
+	changes, total, err := h.configUsecase.ListChanges(ctx, &filter)
+	if err != nil {
+		h.logger.ErrorLogger(ctx, err, "Failed to list configuration changes", nil)
+		response.InternalServerError(c, "Failed to list configuration changes", err.Error())
+		return
+	}
+
+	response.SuccessWithPagination(c, http.StatusOK, "Configuration changes retrieved successfully", changes,
+		response.NewPagination(filter.Page, filter.PageSize, total))
+}
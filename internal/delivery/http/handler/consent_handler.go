@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/consent"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConsentHandler handles the legal documents and the users' consent to them
+type ConsentHandler struct {
+	consentUsecase *consent.ConsentUsecase
+	logger         *logger.Logger
+}
+
+// NewConsentHandler creates a new consent handler
+func NewConsentHandler(consentUsecase *consent.ConsentUsecase, log *logger.Logger) *ConsentHandler {
+	return &ConsentHandler{
+		consentUsecase: consentUsecase,
+		logger:         log,
+	}
+}
+
+// Routes registers the legal document and consent routes
+func (h *ConsentHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/legal",
+			Access: route.Public,
+			Cache:  5 * time.Minute,
+			Register: func(r gin.IRoutes) {
+				r.GET("/documents", h.GetDocuments)
+			},
+		},
+		{
+			Prefix: "/api/v1/user/consents",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeProfileRead},
+			Register: func(r gin.IRoutes) {
+				r.GET("", h.GetConsents)
+			},
+		},
+		{
+			Prefix: "/api/v1/user/consents",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeProfileWrite},
+			Dedup:  true,
+			Register: func(r gin.IRoutes) {
+				r.POST("", h.AcceptConsent)
+			},
+		},
+	}
+}
+
+// GetDocuments godoc
+// @Summary      Get legal documents
+// @Description  Get the current versions of the terms of service and privacy policy users must accept
+// @Tags         legal
+// @Produce      json
+// @Success      200  {object}  response.Response{data=[]entity.LegalDocument}
+// @Router       /api/v1/legal/documents [get]
+func (h *ConsentHandler) GetDocuments(c *gin.Context) {
+	response.Success(c, http.StatusOK, "Legal documents retrieved successfully", h.consentUsecase.Documents())
+}
+
+// GetConsents godoc
+// @Summary      Get consents
+// @Description  Get the current legal documents the authenticated user has yet to accept, and the versions the user accepted
+// @Tags         legal
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=entity.ConsentStatus}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/user/consents [get]
+func (h *ConsentHandler) GetConsents(c *gin.Context) {
+	status, err := h.consentUsecase.Status(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		h.handleError(c, err, "Failed to get consents")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Consents retrieved successfully", status)
+}
+
+// AcceptConsent godoc
+// @Summary      Accept legal documents
+// @Description  Record the authenticated user accepting the current versions of legal documents, with the IP address and user agent of the request
+// @Tags         legal
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      entity.AcceptConsentRequest  true  "Accepted documents"
+// @Success      201      {object}  response.Response{data=entity.ConsentStatus}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response  "Impersonating the user"
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/user/consents [post]
+func (h *ConsentHandler) AcceptConsent(c *gin.Context) {
+	var req entity.AcceptConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	status, err := h.consentUsecase.Accept(c.Request.Context(), c.GetInt("user_id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to accept legal documents")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Legal documents accepted successfully", status)
+}
+
+func (h *ConsentHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.IsInvalidConsent(err):
+		response.BadRequest(c, message, err.Error())
+	case errors.IsConsentImpersonated(err):
+		response.Forbidden(c, message, err.Error())
+	default:
+		h.logger.ErrorLogger(c.Request.Context(), err, message, nil)
+		response.InternalServerError(c, message, err.Error())
+	}
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/delivery/http/middleware"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/pkg/postman"
+	"boilerplate-go/pkg/response"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DocsHandler serves a Postman collection and curl examples of the API, built
+// from the Swagger document and the mounted routes
+type DocsHandler struct {
+	routes func() []route.Info
+	doc    func() (string, error)
+	logger *logger.Logger
+
+	// The routes are fixed once the server runs, so the collection is
+	// built on the first request
+	once       sync.Once
+	collection *postman.Collection
+	err        error
+}
+
+// NewDocsHandler creates a new docs handler. Routes lists the mounted routes
+// and doc returns the Swagger document describing them.
+func NewDocsHandler(routes func() []route.Info, doc func() (string, error), log *logger.Logger) *DocsHandler {
+	return &DocsHandler{
+		routes: routes,
+		doc:    doc,
+		logger: log,
+	}
+}
+
+// Routes registers the public API docs routes
+func (h *DocsHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/docs",
+			// The collection only changes with the routes, on restart
+			Cache: time.Hour,
+			Register: func(r gin.IRoutes) {
+				r.GET("/collection.json", h.GetCollection)
+				r.GET("/examples", h.GetExamples)
+			},
+		},
+	}
+}
+
+// GetCollection godoc
+// @Summary      API collection
+// @Description  Postman collection (format v2.1, also imported by Insomnia) of every mounted route, grouped by tag, with example parameters and bodies. The baseUrl variable defaults to the URL this collection was fetched from; set the token variable to an access token from POST /api/v1/auth/login
+// @Tags         docs
+// @Produce      json
+// @Success      200  {object}  postman.Collection
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/docs/collection.json [get]
+func (h *DocsHandler) GetCollection(c *gin.Context) {
+	collection, ok := h.build(c)
+	if !ok {
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="collection.json"`)
+	c.JSON(http.StatusOK, collection.WithBaseURL(baseURL(c)))
+}
+
+// GetExamples godoc
+// @Summary      API curl examples
+// @Description  A curl command for every request of the collection, reading the base URL and access token from the BASE_URL and TOKEN shell variables
+// @Tags         docs
+// @Produce      plain
+// @Success      200  {string}  string
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/docs/examples [get]
+func (h *DocsHandler) GetExamples(c *gin.Context) {
+	collection, ok := h.build(c)
+	if !ok {
+		return
+	}
+	c.String(http.StatusOK, "BASE_URL=%s\nTOKEN=\n\n%s", baseURL(c), collection.Curl())
+}
+
+func (h *DocsHandler) build(c *gin.Context) (*postman.Collection, bool) {
+	h.once.Do(func() {
+		doc, err := h.doc()
+		if err != nil {
+			h.err = fmt.Errorf("failed to read swagger document: %w", err)
+			return
+		}
+
+		infos := h.routes()
+		routes := make([]postman.Route, 0, len(infos))
+		for _, info := range infos {
+			r := postman.Route{
+				Method:        info.Method,
+				Path:          info.Path,
+				Authenticated: info.Access == route.Authenticated || info.Access == route.Provisioning,
+			}
+			if len(info.Scopes) > 0 {
+				r.Notes = append(r.Notes, "Requires the token scopes: "+strings.Join(info.Scopes, ", "))
+			}
+			if info.Access == route.Signed {
+				r.Notes = append(r.Notes, "Requires an HMAC request signature in the "+strings.Join([]string{
+					middleware.SignatureKeyIDHeader, middleware.SignatureTimestampHeader, middleware.SignatureHeader,
+				}, ", ")+" headers")
+			}
+			if info.Access == route.Provisioning {
+				r.Notes = append(r.Notes, "Requires the provisioning bearer token instead of an access token")
+			}
+			routes = append(routes, r)
+		}
+		h.collection, h.err = postman.Build("", []byte(doc), routes)
+	})
+	if h.err != nil {
+		h.logger.ErrorLogger(c.Request.Context(), h.err, "Failed to build API collection", nil)
+		response.InternalServerError(c, "Failed to build API collection", h.err.Error())
+		return nil, false
+	}
+	return h.collection, true
+}
+
+// baseURL returns the URL the request reached the API at
+func baseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host
+}
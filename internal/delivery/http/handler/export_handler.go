@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/export"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/ndjson"
+	"boilerplate-go/pkg/response"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler handles the admin dataset export HTTP requests
+type ExportHandler struct {
+	exportUsecase *export.ExportUsecase
+	logger        *logger.Logger
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportUsecase *export.ExportUsecase, log *logger.Logger) *ExportHandler {
+	return &ExportHandler{
+		exportUsecase: exportUsecase,
+		logger:        log,
+	}
+}
+
+// Routes registers the admin export routes
+func (h *ExportHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/admin",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeAdmin},
+			Dedup:  true,
+			Register: func(r gin.IRoutes) {
+				r.GET("/users/export", h.ExportUsers)
+				r.GET("/orders/export", h.ExportOrders)
+				r.GET("/sign-ins/export", h.ExportSignIns)
+				r.POST("/exports", h.CreateExport)
+				r.GET("/exports/:id", h.GetExport)
+			},
+		},
+	}
+}
+
+// ExportUsers godoc
+// @Summary      Export users
+// @Description  Stream users as CSV, an Excel workbook or NDJSON, oldest first. Large exports that would outlast the server's write timeout should be requested with POST /api/v1/admin/exports
+// @Tags         admin
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce      application/x-ndjson
+// @Security     BearerAuth
+// @Param        format  query     string  false  "File format"  Enums(csv, xlsx, ndjson)  default(csv)
+// @Param        from    query     string  false  "Created at or after (date or RFC3339)"
+// @Param        to      query     string  false  "Created at or before (date or RFC3339)"
+// @Param        role    query     string  false  "Role"
+// @Success      200     {file}    file
+// @Failure      400     {object}  response.Response
+// @Failure      401     {object}  response.Response
+// @Failure      403     {object}  response.Response
+// @Router       /api/v1/admin/users/export [get]
+func (h *ExportHandler) ExportUsers(c *gin.Context) {
+	h.stream(c, entity.ExportDatasetUsers)
+}
+
+// ExportOrders godoc
+// @Summary      Export orders
+// @Description  Stream orders of all users as CSV, an Excel workbook or NDJSON, oldest first. Large exports that would outlast the server's write timeout should be requested with POST /api/v1/admin/exports
+// @Tags         admin
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce      application/x-ndjson
+// @Security     BearerAuth
+// @Param        format   query     string  false  "File format"  Enums(csv, xlsx, ndjson)  default(csv)
+// @Param        from     query     string  false  "Created at or after (date or RFC3339)"
+// @Param        to       query     string  false  "Created at or before (date or RFC3339)"
+// @Param        status   query     string  false  "Order status"
+// @Param        user_id  query     int     false  "User ID"
+// @Success      200      {file}    file
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Router       /api/v1/admin/orders/export [get]
+func (h *ExportHandler) ExportOrders(c *gin.Context) {
+	h.stream(c, entity.ExportDatasetOrders)
+}
+
+// ExportSignIns godoc
+// @Summary      Export sign-ins
+// @Description  Stream the sign-in audit trail, one row per session with its IP address, device and location, as CSV, an Excel workbook or NDJSON, oldest first
+// @Tags         admin
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce      application/x-ndjson
+// @Security     BearerAuth
+// @Param        format   query     string  false  "File format"  Enums(csv, xlsx, ndjson)  default(csv)
+// @Param        from     query     string  false  "Signed in at or after (date or RFC3339)"
+// @Param        to       query     string  false  "Signed in at or before (date or RFC3339)"
+// @Param        user_id  query     int     false  "User ID"
+// @Success      200      {file}    file
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Router       /api/v1/admin/sign-ins/export [get]
+func (h *ExportHandler) ExportSignIns(c *gin.Context) {
+	h.stream(c, entity.ExportDatasetSignIns)
+}
+
+// CreateExport godoc
+// @Summary      Export in the background
+// @Description  Queue an export generated into file storage; poll it for a signed download URL. Filter dates are RFC3339 and to is excluded
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      entity.ExportRequest  true  "Dataset, format and filter"
+// @Success      202      {object}  response.Response{data=entity.Export}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Failure      503      {object}  response.Response
+// @Router       /api/v1/admin/exports [post]
+func (h *ExportHandler) CreateExport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.ExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request format", err)
+		return
+	}
+
+	created, err := h.exportUsecase.Request(ctx, c.GetInt("user_id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create export")
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Export accepted", created)
+}
+
+// GetExport godoc
+// @Summary      Get a background export
+// @Description  Status of a background export; once completed it has a signed download URL valid for EXPORT_DOWNLOAD_TTL
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Export ID"
+// @Success      200  {object}  response.Response{data=entity.Export}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/exports/{id} [get]
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	exportID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid export ID", err.Error())
+		return
+	}
+
+	found, err := h.exportUsecase.Get(ctx, exportID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get export")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Export retrieved successfully", found)
+}
+
+// stream writes the export of the dataset selected by the query string as
+// the response. Errors after the first rows are only logged, as the status
+// has been sent.
+func (h *ExportHandler) stream(c *gin.Context, dataset string) {
+	ctx := c.Request.Context()
+
+	req, err := exportRequest(c, dataset)
+	if err == nil {
+		err = h.exportUsecase.Validate(req)
+	}
+	if err != nil {
+		response.BadRequest(c, "Invalid export", err.Error())
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType(req.Format))
+	c.Header("Content-Disposition", "attachment; filename="+export.FileName(dataset, req.Format, time.Now()))
+	c.Status(http.StatusOK)
+
+	rows, err := h.exportUsecase.Stream(ctx, req, c.Writer)
+	if err != nil {
+		h.logger.ErrorLogger(ctx, err, "Failed to stream export", map[string]interface{}{
+			"dataset": dataset,
+			"rows":    rows,
+		})
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"dataset": dataset,
+		"format":  req.Format,
+		"rows":    rows,
+	}).Info("Export streamed")
+}
+
+// exportRequest reads the format and filter of an export from the query string
+func exportRequest(c *gin.Context, dataset string) (*entity.ExportRequest, error) {
+	req := &entity.ExportRequest{
+		Dataset: dataset,
+		Format:  c.Query("format"),
+		Filter: entity.ExportFilter{
+			Role:   c.Query("role"),
+			Status: c.Query("status"),
+		},
+	}
+
+	if req.Format == "" && acceptsNDJSON(c) {
+		req.Format = entity.ExportFormatNDJSON
+	}
+
+	var err error
+	if req.Filter.From, err = parseOrderDate(c.Query("from"), false); err != nil {
+		return nil, fmt.Errorf("invalid from: %w", err)
+	}
+	if req.Filter.To, err = parseOrderDate(c.Query("to"), true); err != nil {
+		return nil, fmt.Errorf("invalid to: %w", err)
+	}
+	if value := c.Query("user_id"); value != "" {
+		if req.Filter.UserID, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("invalid user_id: %w", err)
+		}
+	}
+	return req, nil
+}
+
+// acceptsNDJSON reports whether the client asks for a listing streamed as
+// NDJSON, with the format query parameter or its Accept header
+func acceptsNDJSON(c *gin.Context) bool {
+	return c.Query("format") == entity.ExportFormatNDJSON || strings.Contains(c.GetHeader("Accept"), ndjson.ContentType)
+}
+
+func (h *ExportHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.IsExportNotFound(err):
+		response.Error(c, http.StatusNotFound, "Export not found", err.Error())
+	case errors.IsInvalidExport(err):
+		response.BadRequest(c, message, err.Error())
+	case errors.IsProviderUnavailable(err):
+		response.Error(c, http.StatusServiceUnavailable, message, err.Error())
+	default:
+		h.logger.ErrorLogger(c.Request.Context(), err, message, nil)
+		response.InternalServerError(c, message, err.Error())
+	}
+}
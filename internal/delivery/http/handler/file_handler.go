@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"context"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SignedFileStore opens files addressed by a signed URL
+type SignedFileStore interface {
+	OpenSignedFile(ctx context.Context, id, expires, signature string) (*os.File, error)
+}
+
+// FileHandler serves files from local storage through signed URLs
+type FileHandler struct {
+	files  SignedFileStore
+	logger *logger.Logger
+}
+
+// NewFileHandler creates a new file handler
+func NewFileHandler(files SignedFileStore, log *logger.Logger) *FileHandler {
+	return &FileHandler{
+		files:  files,
+		logger: log,
+	}
+}
+
+// DownloadSigned godoc
+// @Summary      Download a file through a signed URL
+// @Description  Stream a file kept in local storage. The URL comes from the file storage provider and stays valid until its expiry; range requests are supported
+// @Tags         files
+// @Produce      octet-stream
+// @Param        id         path      string  true  "File ID"
+// @Param        expires    query     int     true  "Expiry as a Unix timestamp"
+// @Param        signature  query     string  true  "URL signature"
+// @Success      200        {file}    file
+// @Failure      403        {object}  response.Response
+// @Failure      404        {object}  response.Response
+// @Failure      500        {object}  response.Response
+// @Router       /files/signed/{id} [get]
+func (h *FileHandler) DownloadSigned(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := strings.TrimPrefix(c.Param("id"), "/")
+
+	file, err := h.files.OpenSignedFile(ctx, id, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		switch {
+		case errors.IsInvalidSignedURL(err):
+			response.Error(c, http.StatusForbidden, "Invalid download link", err.Error())
+		case errors.IsFileNotFound(err):
+			response.Error(c, http.StatusNotFound, "File not found", err.Error())
+		default:
+			h.logger.ErrorLogger(ctx, err, "Failed to open signed file", map[string]interface{}{
+				"file_id": id,
+			})
+			response.InternalServerError(c, "Failed to download file", err.Error())
+		}
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		response.InternalServerError(c, "Failed to download file", err.Error())
+		return
+	}
+
+	// The link is a bearer credential, so keep it out of shared caches
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(id)}))
+	http.ServeContent(c.Writer, c.Request, path.Base(id), stat.ModTime(), file)
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/provider/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadSigned(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	files := storage.NewLocalStorageProvider(storage.LocalConfig{
+		BasePath:   t.TempDir(),
+		SignedURL:  "/files/signed",
+		SigningKey: "secret",
+	}, logger.NewLogger())
+	ctx := context.Background()
+
+	_, err := files.UploadFile(ctx, &entity.FileUploadRequest{Path: "exports", FileName: "report.csv", Content: []byte("id,total\n1,10\n")})
+	require.NoError(t, err)
+	signed, err := files.GetSignedURL(ctx, "exports/report.csv", time.Minute)
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.GET("/files/signed/*id", NewFileHandler(files.(SignedFileStore), logger.NewLogger()).DownloadSigned)
+	get := func(target string, header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get(signed, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "id,total\n1,10\n", w.Body.String())
+	assert.Equal(t, "private, no-store", w.Header().Get("Cache-Control"))
+	assert.Equal(t, `attachment; filename=report.csv`, w.Header().Get("Content-Disposition"))
+
+	w = get(signed, http.Header{"Range": {"bytes=0-1"}})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "id", w.Body.String())
+
+	parsed, _ := url.Parse(signed)
+	assert.Equal(t, http.StatusForbidden, get(parsed.Path+"?expires=1&signature=bad", nil).Code)
+
+	missing, err := files.GetSignedURL(ctx, "exports/missing.csv", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, get(missing, nil).Code)
+}
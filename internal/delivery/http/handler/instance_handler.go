@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/response"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Readiness takes the instance out of rotation and back
+type Readiness interface {
+	SetDraining(draining bool)
+	Draining() bool
+}
+
+// InstanceHandler handles the admin HTTP requests about the instance serving
+// them
+type InstanceHandler struct {
+	readiness Readiness
+	instance  string
+	logger    *logger.Logger
+}
+
+// NewInstanceHandler creates a new instance handler
+func NewInstanceHandler(readiness Readiness, log *logger.Logger) *InstanceHandler {
+	instance, _ := os.Hostname()
+	return &InstanceHandler{
+		readiness: readiness,
+		instance:  instance,
+		logger:    log,
+	}
+}
+
+// Routes registers the admin instance routes
+func (h *InstanceHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/admin/instance",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeAdmin},
+			Register: func(r gin.IRoutes) {
+				r.GET("", h.GetStatus)
+				r.POST("/drain", h.Drain)
+				r.DELETE("/drain", h.Resume)
+			},
+		},
+	}
+}
+
+// GetStatus godoc
+// @Summary      Instance status
+// @Description  Whether the instance serving the request is draining, with its hostname
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=entity.InstanceStatus}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Router       /api/v1/admin/instance [get]
+func (h *InstanceHandler) GetStatus(c *gin.Context) {
+	response.Success(c, http.StatusOK, "Instance status retrieved successfully", h.status())
+}
+
+// Drain godoc
+// @Summary      Drain the instance
+// @Description  Takes the instance serving the request out of rotation: /ready answers 503 and connections are closed after each response, so load balancers stop sending it requests. The instance keeps serving until it is resumed or shut down. Call it on the instance itself, e.g. through a port-forward, as a load balancer picks any
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=entity.InstanceStatus}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Router       /api/v1/admin/instance/drain [post]
+func (h *InstanceHandler) Drain(c *gin.Context) {
+	h.readiness.SetDraining(true)
+	h.logger.WithContext(c.Request.Context()).WithFields(map[string]interface{}{
+		"instance": h.instance,
+		"admin_id": c.GetInt("user_id"),
+	}).Warn("Instance taken out of rotation")
+
+	response.Success(c, http.StatusOK, "Instance draining", h.status())
+}
+
+// Resume godoc
+// @Summary      Resume the instance
+// @Description  Puts a drained instance serving the request back in rotation
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=entity.InstanceStatus}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Router       /api/v1/admin/instance/drain [delete]
+func (h *InstanceHandler) Resume(c *gin.Context) {
+	h.readiness.SetDraining(false)
+	h.logger.WithContext(c.Request.Context()).WithFields(map[string]interface{}{
+		"instance": h.instance,
+		"admin_id": c.GetInt("user_id"),
+	}).Info("Instance back in rotation")
+
+	response.Success(c, http.StatusOK, "Instance back in rotation", h.status())
+}
+
+func (h *InstanceHandler) status() entity.InstanceStatus {
+	return entity.InstanceStatus{Instance: h.instance, Draining: h.readiness.Draining()}
+}
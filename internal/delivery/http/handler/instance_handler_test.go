@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceHandler_Drain(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	health := metrics.NewHealthMetrics()
+	h := NewInstanceHandler(health, logger.NewLogger())
+	r := gin.New()
+	r.POST("/drain", h.Drain)
+	r.DELETE("/drain", h.Resume)
+
+	request := func(method string) entity.InstanceStatus {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, "/drain", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var status entity.InstanceStatus
+		body := response.Response{Data: &status}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return status
+	}
+
+	status := request(http.MethodPost)
+	assert.True(t, status.Draining)
+	assert.True(t, health.Draining())
+
+	status = request(http.MethodDelete)
+	assert.False(t, status.Draining)
+	assert.False(t, health.Draining())
+}
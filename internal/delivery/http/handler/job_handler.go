@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/job"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobHandler handles dead letter queue administration HTTP requests
+type JobHandler struct {
+	jobUsecase *job.JobUsecase
+	logger     *logger.Logger
+	metrics    *metrics.Metrics
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(jobUsecase *job.JobUsecase, log *logger.Logger, m *metrics.Metrics) *JobHandler {
+	return &JobHandler{
+		jobUsecase: jobUsecase,
+		logger:     log,
+		metrics:    m,
+	}
+}
+
+// Routes registers the admin dead letter routes
+func (h *JobHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/admin",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeAdmin},
+			Dedup:  true,
+			Register: func(r gin.IRoutes) {
+				r.GET("/jobs/dead-letters", h.ListDeadLetters)
+				r.GET("/jobs/dead-letters/:id", h.GetDeadLetter)
+				r.POST("/jobs/dead-letters/:id/requeue", h.RequeueDeadLetter)
+				r.DELETE("/jobs/dead-letters/:id", h.DeleteDeadLetter)
+			},
+		},
+	}
+}
+
+// ListDeadLetters godoc
+// @Summary      List dead-lettered jobs
+// @Description  List background jobs that exhausted their retries, newest first
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        type       query     string  false  "Job type"
+// @Param        page       query     int     false  "Page number"  default(1)
+// @Param        page_size  query     int     false  "Page size (max 100)"  default(20)
+// @Success      200  {object}  response.Response{data=[]entity.DeadLetterJob}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/jobs/dead-letters [get]
+func (h *JobHandler) ListDeadLetters(c *gin.Context) {
+	filter := entity.DeadLetterFilter{
+		JobType: c.Query("type"),
+	}
+
+	for param, target := range map[string]*int{
+		"page":      &filter.Page,
+		"page_size": &filter.PageSize,
+	} {
+		if value := c.Query(param); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				response.BadRequest(c, "Invalid "+param, err.Error())
+				return
+			}
+			*target = n
+		}
+	}
+
+	jobs, total, err := h.jobUsecase.ListDeadLetters(c.Request.Context(), &filter)
+	if err != nil {
+		h.handleError(c, err, "Failed to list dead letter jobs")
+		return
+	}
+
+	response.SuccessWithPagination(c, http.StatusOK, "Dead letter jobs retrieved successfully", jobs,
+		response.NewPagination(filter.Page, filter.PageSize, total))
+}
+
+// GetDeadLetter godoc
+// @Summary      Get a dead-lettered job
+// @Description  Get a dead-lettered job with its payload and last error
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Dead letter job ID"
+// @Success      200  {object}  response.Response{data=entity.DeadLetterJob}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/jobs/dead-letters/{id} [get]
+func (h *JobHandler) GetDeadLetter(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid job ID", err.Error())
+		return
+	}
+
+	deadLetter, err := h.jobUsecase.GetDeadLetter(c.Request.Context(), jobID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get dead letter job")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Dead letter job retrieved successfully", deadLetter)
+}
+
+// RequeueDeadLetter godoc
+// @Summary      Requeue a dead-lettered job
+// @Description  Remove the job from the dead letter queue and run it again with a fresh set of retries
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Dead letter job ID"
+// @Success      202  {object}  response.Response{data=entity.DeadLetterJob}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      422  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/jobs/dead-letters/{id}/requeue [post]
+func (h *JobHandler) RequeueDeadLetter(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid job ID", err.Error())
+		return
+	}
+
+	requeued, err := h.jobUsecase.Requeue(c.Request.Context(), jobID)
+	if err != nil {
+		h.handleError(c, err, "Failed to requeue job")
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Job requeued successfully", requeued)
+}
+
+// DeleteDeadLetter godoc
+// @Summary      Discard a dead-lettered job
+// @Description  Delete a dead-lettered job without running it
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Dead letter job ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/jobs/dead-letters/{id} [delete]
+func (h *JobHandler) DeleteDeadLetter(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid job ID", err.Error())
+		return
+	}
+
+	if err := h.jobUsecase.DiscardDeadLetter(ctx, jobID); err != nil {
+		h.handleError(c, err, "Failed to discard job")
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"job_id": jobID,
+		"action": "discard_job",
+	}).Info("Dead letter job discarded")
+
+	response.Success(c, http.StatusOK, "Job discarded successfully", nil)
+}
+
+// handleError maps job errors to HTTP responses.
+func (h *JobHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.IsDeadLetterNotFound(err):
+		response.Error(c, http.StatusNotFound, "Dead letter job not found", err.Error())
+	case errors.IsUnknownJobType(err):
+		response.Error(c, http.StatusUnprocessableEntity, message, err.Error())
+	default:
+		h.logger.ErrorLogger(c.Request.Context(), err, message, nil)
+		response.InternalServerError(c, message, err.Error())
+	}
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/notification"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler handles bulk email campaign HTTP requests
+type NotificationHandler struct {
+	notificationUsecase *notification.NotificationUsecase
+	logger              *logger.Logger
+	metrics             *metrics.Metrics
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationUsecase *notification.NotificationUsecase, log *logger.Logger, m *metrics.Metrics) *NotificationHandler {
+	return &NotificationHandler{
+		notificationUsecase: notificationUsecase,
+		logger:              log,
+		metrics:             m,
+	}
+}
+
+// Routes registers the admin campaign routes
+func (h *NotificationHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/admin",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeAdmin},
+			Dedup:  true,
+			Register: func(r gin.IRoutes) {
+				r.POST("/notifications/campaigns", h.CreateCampaign)
+				r.GET("/notifications/campaigns/:id", h.GetCampaign)
+			},
+		},
+	}
+}
+
+// CreateCampaign godoc
+// @Summary      Send a bulk email campaign
+// @Description  Send an email to a list of recipients, and every active user with all_users, in chunks. The campaign is sent in the background; poll it by ID to follow per-chunk progress
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      entity.CampaignRequest  true  "Campaign"
+// @Success      202  {object}  response.Response{data=entity.Notification}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Failure      503  {object}  response.Response
+// @Router       /api/v1/admin/notifications/campaigns [post]
+func (h *NotificationHandler) CreateCampaign(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.CampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request format", err)
+		return
+	}
+
+	campaign, err := h.notificationUsecase.CreateCampaign(ctx, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create campaign")
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"notification_id": campaign.ID,
+		"recipients":      campaign.Total,
+		"chunks":          campaign.TotalChunks,
+		"action":          "create_campaign",
+	}).Info("Bulk email campaign created")
+
+	response.Success(c, http.StatusAccepted, "Campaign accepted", campaign)
+}
+
+// GetCampaign godoc
+// @Summary      Get a bulk email campaign
+// @Description  Get the status and per-chunk delivery counts of a campaign
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Campaign ID"
+// @Success      200  {object}  response.Response{data=entity.Notification}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/notifications/campaigns/{id} [get]
+func (h *NotificationHandler) GetCampaign(c *gin.Context) {
+	campaignID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid campaign ID", err.Error())
+		return
+	}
+
+	campaign, err := h.notificationUsecase.GetCampaign(c.Request.Context(), campaignID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get campaign")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Campaign retrieved successfully", campaign)
+}
+
+func (h *NotificationHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.IsCampaignNotFound(err):
+		response.Error(c, http.StatusNotFound, "Campaign not found", err.Error())
+	case errors.IsInvalidCampaign(err):
+		response.BadRequest(c, message, err.Error())
+	case errors.IsProviderUnavailable(err):
+		response.Error(c, http.StatusServiceUnavailable, message, err.Error())
+	default:
+		h.logger.ErrorLogger(c.Request.Context(), err, message, nil)
+		response.InternalServerError(c, message, err.Error())
+	}
+}
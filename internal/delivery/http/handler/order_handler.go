@@ -1,13 +1,20 @@
 package handler
 
 import (
+	"encoding/csv"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"boilerplate-go/infrastructure/logger"
 	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/delivery/http/middleware"
+	"boilerplate-go/internal/delivery/http/route"
 	"boilerplate-go/internal/domain/entity"
 	"boilerplate-go/internal/usecase/order"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/ndjson"
 	"boilerplate-go/pkg/response"
 
 	"github.com/gin-gonic/gin"
@@ -27,18 +34,72 @@ func NewOrderHandler(orderUsecase *order.OrderUsecase, logger *logger.Logger, me
 	}
 }
 
+// Routes registers the order routes and the payment webhook
+func (h *OrderHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/orders",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeOrdersRead},
+			Register: func(r gin.IRoutes) {
+				r.GET("", h.ListOrders)
+				r.GET("/payment/:payment_id/status", h.GetPaymentStatus)
+			},
+		},
+		{
+			Prefix: "/api/v1/orders",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeOrdersWrite},
+			Dedup:  true,
+			// Placing orders and paying require the current legal documents
+			// to be accepted; refunds and cancellations do not
+			Consent: true,
+			// One order, and one payment intent, of a user at a time, so
+			// parallel requests do not race each other at the payment provider
+			MaxInFlight: 1,
+			Register: func(r gin.IRoutes) {
+				r.POST("", h.ProcessOrder)
+				r.POST("/payment-intent", h.CreatePaymentIntent)
+			},
+		},
+		{
+			Prefix: "/api/v1/orders",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeOrdersWrite},
+			Dedup:  true,
+			Register: func(r gin.IRoutes) {
+				r.POST("/refund", h.RefundOrder)
+				r.POST("/:id/cancel", h.CancelOrder)
+			},
+		},
+		{
+			Prefix: "/api/v1/webhooks",
+			Access: route.Signed,
+			Register: func(r gin.IRoutes) {
+				r.POST("/payments", h.PaymentWebhook)
+			},
+		},
+	}
+}
+
 // ProcessOrder godoc
 // @Summary Process a new order
-// @Description Process a new order with payment
+// @Description Process a new order with payment. With dry_run or the X-Dry-Run header the order is validated, priced and screened, and its payment simulated by the mock provider, without persisting or charging it
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param request body entity.CreateOrderRequest true "Order request"
+// @Param X-Dry-Run header bool false "Simulate the order without side effects"
 // @Success 200 {object} response.Response{data=entity.OrderResponse}
+// @Success 202 {object} response.Response{data=entity.OrderResponse} "Accepted with payment pending"
 // @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response "The current legal documents must be accepted"
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response
 // @Security BearerAuth
-// @Router /orders [post]
+// @Router /api/v1/orders [post]
 func (h *OrderHandler) ProcessOrder(c *gin.Context) {
 	var req entity.CreateOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -46,7 +107,7 @@ func (h *OrderHandler) ProcessOrder(c *gin.Context) {
 			"endpoint": "/orders",
 			"method":   "POST",
 		})
-		response.BadRequest(c, "Invalid request format", err.Error())
+		response.InvalidRequest(c, "Invalid request format", err)
 		return
 	}
 
@@ -59,10 +120,33 @@ func (h *OrderHandler) ProcessOrder(c *gin.Context) {
 	}
 
 	req.UserID = userID.(int)
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+	if dryRun, err := strconv.ParseBool(c.GetHeader(middleware.DryRunHeader)); err == nil && dryRun {
+		req.DryRun = true
+	}
 
 	// Process the order
 	orderResponse, err := h.orderUsecase.ProcessOrder(c.Request.Context(), &req)
 	if err != nil {
+		if errors.IsInvalidOrder(err) || errors.IsCouponInvalid(err) {
+			response.BadRequest(c, "Invalid order", err.Error())
+			return
+		}
+		if errors.IsOrderAlreadyExists(err) {
+			response.Error(c, http.StatusConflict, "Order already exists", err.Error())
+			return
+		}
+		if errors.IsOrderBlocked(err) {
+			h.metrics.IncrementCounter("orders_blocked_fraud")
+			response.Error(c, http.StatusUnprocessableEntity, "Order rejected", "order could not be accepted")
+			return
+		}
+		if errors.IsProviderUnavailable(err) {
+			c.Header("Retry-After", "30")
+			response.Error(c, http.StatusServiceUnavailable, "Payments temporarily unavailable", err.Error())
+			return
+		}
 		h.metrics.IncrementCounter("order_processing_failures")
 		h.logger.ErrorLogger(c.Request.Context(), err, "Failed to process order", map[string]interface{}{
 			"user_id":  req.UserID,
@@ -73,6 +157,16 @@ func (h *OrderHandler) ProcessOrder(c *gin.Context) {
 		return
 	}
 
+	if req.DryRun {
+		response.Success(c, http.StatusOK, "Order dry run completed", orderResponse)
+		return
+	}
+
+	if orderResponse.Status == entity.OrderStatusPaymentPending {
+		response.Success(c, http.StatusAccepted, "Order accepted, payment pending", orderResponse)
+		return
+	}
+
 	h.metrics.IncrementCounter("order_processing_success")
 	h.logger.WithContext(c.Request.Context()).WithFields(map[string]interface{}{
 		"user_id":    req.UserID,
@@ -83,18 +177,221 @@ func (h *OrderHandler) ProcessOrder(c *gin.Context) {
 	response.Success(c, http.StatusOK, "Order processed successfully", orderResponse)
 }
 
+// ListOrders godoc
+// @Summary List order history
+// @Description List the authenticated user's orders, or with X-Organization-ID the organization's, newest first, optionally as a CSV export or streamed as NDJSON, one order per line
+// @Tags orders
+// @Accept json
+// @Produce json,text/csv,application/x-ndjson
+// @Param status query string false "Order status"
+// @Param from query string false "Created on or after (YYYY-MM-DD or RFC3339)"
+// @Param to query string false "Created on or before (YYYY-MM-DD or RFC3339)"
+// @Param min_amount query number false "Minimum amount"
+// @Param max_amount query number false "Maximum amount"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size (max 100)" default(20)
+// @Param format query string false "Set to csv to download all matching orders, or ndjson (or send Accept: application/x-ndjson) to stream them" Enums(json, csv, ndjson)
+// @Success 200 {object} response.Response{data=[]entity.Order}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/orders [get]
+func (h *OrderHandler) ListOrders(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "user_id not found in token")
+		return
+	}
+
+	filter, err := parseOrderFilter(c)
+	if err != nil {
+		response.BadRequest(c, "Invalid order filter", err.Error())
+		return
+	}
+	filter.UserID = userID.(int)
+	if org := entity.OrganizationFromContext(ctx); org != nil {
+		filter.OrganizationID = org.ID
+	}
+
+	if acceptsNDJSON(c) {
+		h.streamOrders(c, filter)
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		orders, err := h.orderUsecase.ExportOrders(ctx, filter)
+		if err != nil {
+			h.logger.ErrorLogger(ctx, err, "Failed to export orders", map[string]interface{}{
+				"user_id": filter.UserID,
+			})
+			response.InternalServerError(c, "Failed to export orders", err.Error())
+			return
+		}
+
+		h.writeOrdersCSV(c, orders)
+		return
+	}
+
+	orders, total, err := h.orderUsecase.ListOrders(ctx, &filter)
+	if err != nil {
+		h.logger.ErrorLogger(ctx, err, "Failed to list orders", map[string]interface{}{
+			"user_id": filter.UserID,
+		})
+		response.InternalServerError(c, "Failed to list orders", err.Error())
+		return
+	}
+
+	response.SuccessWithPagination(c, http.StatusOK, "Orders retrieved successfully", orders,
+		response.NewPagination(filter.Page, filter.PageSize, total))
+}
+
+// parseOrderFilter reads the order history filter from the query string.
+func parseOrderFilter(c *gin.Context) (entity.OrderFilter, error) {
+	filter := entity.OrderFilter{
+		Status: c.Query("status"),
+	}
+
+	var err error
+	if filter.From, err = parseOrderDate(c.Query("from"), false); err != nil {
+		return filter, fmt.Errorf("invalid from: %w", err)
+	}
+	if filter.To, err = parseOrderDate(c.Query("to"), true); err != nil {
+		return filter, fmt.Errorf("invalid to: %w", err)
+	}
+
+	for param, target := range map[string]**float64{
+		"min_amount": &filter.MinAmount,
+		"max_amount": &filter.MaxAmount,
+	} {
+		if value := c.Query(param); value != "" {
+			amount, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s: %w", param, err)
+			}
+			*target = &amount
+		}
+	}
+
+	for param, target := range map[string]*int{
+		"page":      &filter.Page,
+		"page_size": &filter.PageSize,
+	} {
+		if value := c.Query(param); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s: %w", param, err)
+			}
+			*target = n
+		}
+	}
+
+	return filter, nil
+}
+
+// parseOrderDate accepts a date or an RFC3339 timestamp. A date used as the end of
+// a range covers the whole day.
+func parseOrderDate(value string, endOfRange bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		if endOfRange {
+			t = t.Add(time.Nanosecond)
+		}
+		return &t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// streamOrders writes every matching order as NDJSON, one order per line,
+// flushing each chunk as it is read. Failures after the first chunk can only
+// be logged, cutting the stream short.
+func (h *OrderHandler) streamOrders(c *gin.Context, filter entity.OrderFilter) {
+	ctx := c.Request.Context()
+	writer := ndjson.NewWriter(c.Writer)
+	start := func() {
+		if !c.Writer.Written() {
+			c.Header("Content-Type", ndjson.ContentType)
+			c.Status(http.StatusOK)
+			c.Writer.WriteHeaderNow()
+		}
+	}
+
+	count, err := h.orderUsecase.StreamOrders(ctx, filter, func(orders []*entity.Order) error {
+		start()
+		for _, order := range orders {
+			if err := writer.Write(order); err != nil {
+				return err
+			}
+		}
+		return writer.Flush()
+	})
+	if err != nil {
+		h.logger.ErrorLogger(ctx, err, "Failed to stream orders", map[string]interface{}{
+			"user_id": filter.UserID,
+			"orders":  count,
+		})
+		if !c.Writer.Written() {
+			response.InternalServerError(c, "Failed to stream orders", err.Error())
+		}
+		return
+	}
+	start()
+}
+
+// writeOrdersCSV streams the orders as a CSV attachment.
+func (h *OrderHandler) writeOrdersCSV(c *gin.Context, orders []*entity.Order) {
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=orders-%s.csv", time.Now().UTC().Format("20060102")))
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	_ = writer.Write([]string{"order_id", "status", "amount", "currency", "payment_id", "created_at"})
+	for _, order := range orders {
+		_ = writer.Write([]string{
+			order.OrderID,
+			order.Status,
+			strconv.FormatFloat(order.Amount, 'f', 2, 64),
+			order.Currency,
+			order.PaymentID,
+			order.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		h.logger.ErrorLogger(c.Request.Context(), err, "Failed to write orders CSV", nil)
+	}
+}
+
 // GetPaymentStatus godoc
 // @Summary Get payment status
-// @Description Get the status of a payment by payment ID
+// @Description Get the status of the payment of one of the authenticated user's orders. With wait, the request is held until the status differs from the status given, or from the current one, and answers with the current status when wait runs out. Wait is capped by PAYMENT_STATUS_MAX_WAIT.
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param payment_id path string true "Payment ID"
+// @Param wait query string false "How long to wait for a change, e.g. 30s or 30"
+// @Param status query string false "Status the client knows, returned at once when the payment has another"
 // @Success 200 {object} response.Response{data=entity.PaymentStatus}
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Security BearerAuth
-// @Router /orders/payment/{payment_id}/status [get]
+// @Router /api/v1/orders/payment/{payment_id}/status [get]
 func (h *OrderHandler) GetPaymentStatus(c *gin.Context) {
 	paymentID := c.Param("payment_id")
 	if paymentID == "" {
@@ -102,8 +399,38 @@ func (h *OrderHandler) GetPaymentStatus(c *gin.Context) {
 		return
 	}
 
-	status, err := h.orderUsecase.GetPaymentStatus(c.Request.Context(), paymentID)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "user_id not found in token")
+		return
+	}
+
+	wait, err := parseWait(c.Query("wait"))
+	if err != nil {
+		response.BadRequest(c, "Invalid wait", err.Error())
+		return
+	}
+
+	var status *entity.PaymentStatus
+	if wait = h.orderUsecase.PaymentStatusWait(wait); wait > 0 {
+		// The wait may outlast the server's write timeout, which is sized
+		// for ordinary requests
+		http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(wait + longPollWriteMargin))
+		start := time.Now()
+		status, err = h.orderUsecase.WaitPaymentStatus(c.Request.Context(), userID.(int), paymentID, c.Query("status"), wait)
+		c.Set(metrics.IdleTimeKey, time.Since(start))
+	} else {
+		status, err = h.orderUsecase.GetPaymentStatus(c.Request.Context(), userID.(int), paymentID)
+	}
 	if err != nil {
+		if c.Request.Context().Err() != nil {
+			// The client gave up waiting
+			return
+		}
+		if errors.IsOrderNotFound(err) {
+			response.Error(c, http.StatusNotFound, "Payment not found", err.Error())
+			return
+		}
 		h.logger.ErrorLogger(c.Request.Context(), err, "Failed to get payment status", map[string]interface{}{
 			"payment_id": paymentID,
 		})
@@ -119,18 +446,72 @@ func (h *OrderHandler) GetPaymentStatus(c *gin.Context) {
 	response.Success(c, http.StatusOK, "Payment status retrieved", status)
 }
 
+// CancelOrder godoc
+// @Summary Cancel an order
+// @Description Cancel an order, voiding an uncaptured payment or refunding a captured one
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} response.Response{data=entity.OrderCancellation}
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/orders/{id}/cancel [post]
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	ctx := c.Request.Context()
+	orderID := c.Param("id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "user_id not found in token")
+		return
+	}
+
+	result, err := h.orderUsecase.CancelOrder(ctx, userID.(int), orderID)
+	if err != nil {
+		if errors.IsOrderNotFound(err) {
+			response.Error(c, http.StatusNotFound, "Order not found", err.Error())
+			return
+		}
+		if errors.IsInvalidOrderState(err) {
+			response.Error(c, http.StatusConflict, "Order cannot be cancelled", err.Error())
+			return
+		}
+		if errors.IsConflict(err) {
+			response.Error(c, http.StatusConflict, "Order was changed by another request", err.Error())
+			return
+		}
+		h.metrics.IncrementCounter("order_cancellation_failures")
+		h.logger.ErrorLogger(ctx, err, "Failed to cancel order", map[string]interface{}{
+			"user_id":  userID,
+			"order_id": orderID,
+		})
+		response.InternalServerError(c, "Failed to cancel order", err.Error())
+		return
+	}
+
+	h.metrics.IncrementCounter("order_cancellation_success")
+	response.Success(c, http.StatusOK, "Order cancelled successfully", result)
+}
+
 // RefundOrder godoc
 // @Summary Refund an order
-// @Description Process a refund for an order
+// @Description Refund the payment of one of the authenticated user's completed orders
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param request body entity.RefundOrderRequest true "Refund request"
 // @Success 200 {object} response.Response{data=entity.RefundResponse}
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Security BearerAuth
-// @Router /orders/refund [post]
+// @Router /api/v1/orders/refund [post]
 func (h *OrderHandler) RefundOrder(c *gin.Context) {
 	var req entity.RefundOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -138,7 +519,7 @@ func (h *OrderHandler) RefundOrder(c *gin.Context) {
 			"endpoint": "/orders/refund",
 			"method":   "POST",
 		})
-		response.BadRequest(c, "Invalid request format", err.Error())
+		response.InvalidRequest(c, "Invalid request format", err)
 		return
 	}
 
@@ -154,6 +535,18 @@ func (h *OrderHandler) RefundOrder(c *gin.Context) {
 	// Process the refund
 	refundResponse, err := h.orderUsecase.RefundOrder(c.Request.Context(), &req)
 	if err != nil {
+		if errors.IsOrderNotFound(err) {
+			response.Error(c, http.StatusNotFound, "Payment not found", err.Error())
+			return
+		}
+		if errors.IsInvalidOrderState(err) {
+			response.Error(c, http.StatusConflict, "Order cannot be refunded", err.Error())
+			return
+		}
+		if errors.IsConflict(err) {
+			response.Error(c, http.StatusConflict, "Order was changed by another request", err.Error())
+			return
+		}
 		h.metrics.IncrementCounter("order_refund_failures")
 		h.logger.ErrorLogger(c.Request.Context(), err, "Failed to process refund", map[string]interface{}{
 			"user_id":    req.UserID,
@@ -173,6 +566,77 @@ func (h *OrderHandler) RefundOrder(c *gin.Context) {
 	response.Success(c, http.StatusOK, "Refund processed successfully", refundResponse)
 }
 
+// paypalTransmissionIDHeader carries the ID of PayPal webhook deliveries,
+// which is the same on every retry
+const paypalTransmissionIDHeader = "PayPal-Transmission-Id"
+
+// PaymentWebhook godoc
+// @Summary Receive a payment event
+// @Description Apply a payment provider event to its order, after the events of the order received before it. Requests must be HMAC signed; the route is only mounted when request signing is enabled. An event whose ID was already applied is acknowledged without being applied again. A refund received before its payment is recorded is accepted with 202 and applied once the order is paid.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param X-Signature-Key-Id header string true "Signing key ID"
+// @Param X-Signature-Timestamp header string true "Unix timestamp in seconds"
+// @Param X-Signature header string true "Hex HMAC-SHA256 signature"
+// @Param PayPal-Transmission-Id header string false "Event ID of a relayed PayPal event, when the body has none"
+// @Param request body entity.PaymentEvent true "Payment event"
+// @Success 200 {object} response.Response
+// @Success 202 {object} response.Response "Buffered until the order is paid"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/webhooks/payments [post]
+func (h *OrderHandler) PaymentWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var event entity.PaymentEvent
+	if err := c.ShouldBindJSON(&event); err != nil {
+		response.InvalidRequest(c, "Invalid request format", err)
+		return
+	}
+
+	if event.ID == "" {
+		event.ID = c.GetHeader(paypalTransmissionIDHeader)
+	}
+
+	if err := h.orderUsecase.HandlePaymentEvent(ctx, &event); err != nil {
+		// Acknowledged, so the provider stops delivering it
+		if errors.IsDuplicateEvent(err) {
+			response.Success(c, http.StatusOK, "Payment event already processed", nil)
+			return
+		}
+		if errors.IsEventBuffered(err) {
+			response.Success(c, http.StatusAccepted, "Payment event held until the order is paid", nil)
+			return
+		}
+		if errors.IsOrderNotFound(err) {
+			response.Error(c, http.StatusNotFound, "Order not found", err.Error())
+			return
+		}
+		if errors.IsInvalidOrderState(err) {
+			response.Error(c, http.StatusConflict, "Event does not apply to the order", err.Error())
+			return
+		}
+		if errors.IsConflict(err) {
+			response.Error(c, http.StatusConflict, "Order was changed by another request", err.Error())
+			return
+		}
+		h.logger.ErrorLogger(ctx, err, "Failed to process payment event", map[string]interface{}{
+			"event":          event.Event,
+			"payment_id":     event.PaymentID,
+			"signing_key_id": c.GetString("signing_key_id"),
+		})
+		response.InternalServerError(c, "Failed to process payment event", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Payment event processed", nil)
+}
+
 // CreatePaymentIntent godoc
 // @Summary Create payment intent
 // @Description Create a payment intent for client-side payment processing
@@ -182,9 +646,11 @@ func (h *OrderHandler) RefundOrder(c *gin.Context) {
 // @Param request body entity.PaymentIntentRequest true "Payment intent request"
 // @Success 200 {object} response.Response{data=entity.PaymentIntent}
 // @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response "The current legal documents must be accepted"
+// @Failure 409 {object} response.Response "Another payment intent of the user is in progress"
 // @Failure 500 {object} response.Response
 // @Security BearerAuth
-// @Router /orders/payment-intent [post]
+// @Router /api/v1/orders/payment-intent [post]
 func (h *OrderHandler) CreatePaymentIntent(c *gin.Context) {
 	var req entity.PaymentIntentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -192,7 +658,7 @@ func (h *OrderHandler) CreatePaymentIntent(c *gin.Context) {
 			"endpoint": "/orders/payment-intent",
 			"method":   "POST",
 		})
-		response.BadRequest(c, "Invalid request format", err.Error())
+		response.InvalidRequest(c, "Invalid request format", err)
 		return
 	}
 
@@ -214,3 +680,24 @@ func (h *OrderHandler) CreatePaymentIntent(c *gin.Context) {
 		"amount":      strconv.FormatFloat(req.Amount, 'f', 2, 64),
 	})
 }
+
+// longPollWriteMargin is the time left to answer a long poll once its wait
+// runs out
+const longPollWriteMargin = 10 * time.Second
+
+// parseWait parses the wait of a long poll, a duration such as 30s or a
+// number of seconds. An empty wait is 0, answering at once.
+func parseWait(raw string) (time.Duration, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	duration := raw
+	if _, err := strconv.Atoi(raw); err == nil {
+		duration += "s"
+	}
+	wait, err := time.ParseDuration(duration)
+	if err != nil || wait < 0 {
+		return 0, fmt.Errorf("wait must be a duration such as 30s, got %q", raw)
+	}
+	return wait, nil
+}
@@ -0,0 +1,415 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/organization"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHandler handles organization, membership and invitation HTTP requests
+type OrganizationHandler struct {
+	organizationUsecase *organization.OrganizationUsecase
+	logger              *logger.Logger
+	metrics             *metrics.Metrics
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(organizationUsecase *organization.OrganizationUsecase, log *logger.Logger, m *metrics.Metrics) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationUsecase: organizationUsecase,
+		logger:              log,
+		metrics:             m,
+	}
+}
+
+// Routes registers the organization routes
+func (h *OrganizationHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/organizations",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeOrganizations},
+			Dedup:  true,
+			Register: func(r gin.IRoutes) {
+				r.POST("", h.CreateOrganization)
+				r.GET("", h.ListOrganizations)
+				r.POST("/invitations/accept", h.AcceptInvitation)
+				r.GET("/:id", h.GetOrganization)
+				r.GET("/:id/members", h.ListMembers)
+				r.PUT("/:id/members/:user_id", h.UpdateMember)
+				r.DELETE("/:id/members/:user_id", h.RemoveMember)
+				r.POST("/:id/invitations", h.InviteMember)
+			},
+		},
+		{
+			Prefix: "/api/v1/admin/organizations",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeAdmin},
+			Dedup:  true,
+			Register: func(r gin.IRoutes) {
+				r.POST("/:id/invitations", h.AdminInviteMember)
+			},
+		},
+	}
+}
+
+// CreateOrganization godoc
+// @Summary      Create an organization
+// @Description  Create an organization owned by the authenticated user
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      entity.CreateOrganizationRequest  true  "Organization"
+// @Success      201      {object}  response.Response{data=entity.Organization}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	org, err := h.organizationUsecase.Create(ctx, c.GetInt("user_id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create organization")
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"organization_id": org.ID,
+		"user_id":         c.GetInt("user_id"),
+		"action":          "create_organization",
+	}).Info("Organization created")
+
+	response.Success(c, http.StatusCreated, "Organization created successfully", org)
+}
+
+// ListOrganizations godoc
+// @Summary      List organizations
+// @Description  List the organizations of the authenticated user with the user's role in each
+// @Tags         organizations
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]entity.Organization}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/organizations [get]
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	orgs, err := h.organizationUsecase.List(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		h.handleError(c, err, "Failed to list organizations")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Organizations retrieved successfully", orgs)
+}
+
+// GetOrganization godoc
+// @Summary      Get an organization
+// @Description  Get an organization of the authenticated user with the user's role in it
+// @Tags         organizations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Organization ID"
+// @Success      200  {object}  response.Response{data=entity.Organization}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/organizations/{id} [get]
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	orgID, ok := h.pathID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	org, err := h.organizationUsecase.Get(c.Request.Context(), c.GetInt("user_id"), orgID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get organization")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Organization retrieved successfully", org)
+}
+
+// ListMembers godoc
+// @Summary      List organization members
+// @Description  List the members of an organization of the authenticated user with their roles
+// @Tags         organizations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Organization ID"
+// @Success      200  {object}  response.Response{data=[]entity.Membership}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/organizations/{id}/members [get]
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	orgID, ok := h.pathID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	members, err := h.organizationUsecase.ListMembers(c.Request.Context(), c.GetInt("user_id"), orgID)
+	if err != nil {
+		h.handleError(c, err, "Failed to list members")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Members retrieved successfully", members)
+}
+
+// UpdateMember godoc
+// @Summary      Change a member's role
+// @Description  Change the role of a member to owner, admin or member. Owners and admins change roles below owner; only owners grant or take away the owner role, and the last owner keeps it
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                         true  "Organization ID"
+// @Param        user_id  path      int                         true  "Member user ID"
+// @Param        request  body      entity.UpdateMemberRequest  true  "Role"
+// @Success      200      {object}  response.Response{data=entity.Membership}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/organizations/{id}/members/{user_id} [put]
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	orgID, ok := h.pathID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+	memberID, ok := h.pathID(c, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	var req entity.UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	member, err := h.organizationUsecase.UpdateMemberRole(ctx, c.GetInt("user_id"), orgID, memberID, req.Role)
+	if err != nil {
+		h.handleError(c, err, "Failed to update member")
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"organization_id": orgID,
+		"user_id":         c.GetInt("user_id"),
+		"member_id":       memberID,
+		"role":            member.Role,
+		"action":          "update_member_role",
+	}).Info("Organization member role changed")
+
+	response.Success(c, http.StatusOK, "Member updated successfully", member)
+}
+
+// RemoveMember godoc
+// @Summary      Remove a member
+// @Description  Remove a member from an organization. Every member may remove themselves; owners and admins remove others, only owners remove owners, and the last owner cannot leave
+// @Tags         organizations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int  true  "Organization ID"
+// @Param        user_id  path      int  true  "Member user ID"
+// @Success      200      {object}  response.Response
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/organizations/{id}/members/{user_id} [delete]
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	orgID, ok := h.pathID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+	memberID, ok := h.pathID(c, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.organizationUsecase.RemoveMember(ctx, c.GetInt("user_id"), orgID, memberID); err != nil {
+		h.handleError(c, err, "Failed to remove member")
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"organization_id": orgID,
+		"user_id":         c.GetInt("user_id"),
+		"member_id":       memberID,
+		"action":          "remove_member",
+	}).Info("Organization member removed")
+
+	response.Success(c, http.StatusOK, "Member removed successfully", nil)
+}
+
+// InviteMember godoc
+// @Summary      Invite a member
+// @Description  Email an invitation to join the organization with a role. Owners and admins invite; only owners invite owners. The invitation is accepted by the user signed in with that email address
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                         true  "Organization ID"
+// @Param        request  body      entity.InviteMemberRequest  true  "Email and role"
+// @Success      201      {object}  response.Response{data=entity.Invitation}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/organizations/{id}/invitations [post]
+func (h *OrganizationHandler) InviteMember(c *gin.Context) {
+	orgID, ok := h.pathID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	var req entity.InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	invitation, err := h.organizationUsecase.Invite(c.Request.Context(), c.GetInt("user_id"), orgID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to invite member")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Invitation sent successfully", invitation)
+}
+
+// AdminInviteMember godoc
+// @Summary      Invite a member as an administrator
+// @Description  Email an invitation to join any organization with any role, without being a member. The invitee accepts it signed in, or signs up with it
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                         true  "Organization ID"
+// @Param        request  body      entity.InviteMemberRequest  true  "Email and role"
+// @Success      201      {object}  response.Response{data=entity.Invitation}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/admin/organizations/{id}/invitations [post]
+func (h *OrganizationHandler) AdminInviteMember(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	orgID, ok := h.pathID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	var req entity.InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	invitation, err := h.organizationUsecase.AdminInvite(ctx, c.GetInt("user_id"), orgID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to invite member")
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"organization_id": orgID,
+		"admin_id":        c.GetInt("user_id"),
+		"invitation_id":   invitation.ID,
+		"action":          "admin_invite_member",
+	}).Info("Administrator invited organization member")
+
+	response.Success(c, http.StatusCreated, "Invitation sent successfully", invitation)
+}
+
+// AcceptInvitation godoc
+// @Summary      Accept an invitation
+// @Description  Join the organization of an emailed invitation with the token, expires and signature parameters of its link. The invitation must be pending, unexpired and sent to the authenticated user's email address
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      entity.AcceptInvitationRequest  true  "Invitation link parameters"
+// @Success      200      {object}  response.Response{data=entity.Organization}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/organizations/invitations/accept [post]
+func (h *OrganizationHandler) AcceptInvitation(c *gin.Context) {
+	var req entity.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	org, err := h.organizationUsecase.AcceptInvitation(c.Request.Context(), c.GetInt("user_id"), &req.InvitationLink)
+	if err != nil {
+		h.handleError(c, err, "Failed to accept invitation")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Invitation accepted successfully", org)
+}
+
+// pathID parses a positive ID path parameter, answering 400 otherwise
+func (h *OrganizationHandler) pathID(c *gin.Context, name, message string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		response.BadRequest(c, message, "must be a positive integer")
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *OrganizationHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.IsOrgNotFound(err):
+		response.Error(c, http.StatusNotFound, "Organization not found", err.Error())
+	case errors.IsNotOrgMember(err):
+		response.Error(c, http.StatusNotFound, "Member not found", err.Error())
+	case errors.IsInvitationNotFound(err):
+		response.Error(c, http.StatusNotFound, "Invitation not found", err.Error())
+	case errors.IsOrgRoleRequired(err):
+		response.Forbidden(c, message, err.Error())
+	case errors.IsLastOrgOwner(err), errors.IsAlreadyOrgMember(err):
+		response.Error(c, http.StatusConflict, message, err.Error())
+	case errors.IsInvalidOrganization(err):
+		response.BadRequest(c, message, err.Error())
+	default:
+		h.logger.ErrorLogger(c.Request.Context(), err, message, nil)
+		response.InternalServerError(c, message, err.Error())
+	}
+}
@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/promotion"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PromotionHandler handles coupon administration HTTP requests
+type PromotionHandler struct {
+	promotionUsecase *promotion.PromotionUsecase
+	logger           *logger.Logger
+	metrics          *metrics.Metrics
+}
+
+// NewPromotionHandler creates a new promotion handler
+func NewPromotionHandler(promotionUsecase *promotion.PromotionUsecase, log *logger.Logger, m *metrics.Metrics) *PromotionHandler {
+	return &PromotionHandler{
+		promotionUsecase: promotionUsecase,
+		logger:           log,
+		metrics:          m,
+	}
+}
+
+// Routes registers the admin coupon routes
+func (h *PromotionHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/admin",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeAdmin},
+			Dedup:  true,
+			Register: func(r gin.IRoutes) {
+				r.POST("/coupons", h.CreateCoupon)
+				r.GET("/coupons", h.ListCoupons)
+				r.GET("/coupons/:id", h.GetCoupon)
+				r.PUT("/coupons/:id", h.UpdateCoupon)
+				r.DELETE("/coupons/:id", h.DeleteCoupon)
+			},
+		},
+	}
+}
+
+// CreateCoupon godoc
+// @Summary      Create a coupon
+// @Description  Create a percentage or fixed amount coupon with optional usage limits and expiry
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      entity.CouponRequest  true  "Coupon"
+// @Success      201  {object}  response.Response{data=entity.Coupon}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/coupons [post]
+func (h *PromotionHandler) CreateCoupon(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.CouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request format", err)
+		return
+	}
+
+	coupon, err := h.promotionUsecase.CreateCoupon(ctx, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create coupon")
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"coupon_id": coupon.ID,
+		"code":      coupon.Code,
+		"action":    "create_coupon",
+	}).Info("Coupon created")
+
+	response.Success(c, http.StatusCreated, "Coupon created successfully", coupon)
+}
+
+// ListCoupons godoc
+// @Summary      List coupons
+// @Description  List all coupons, newest first
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]entity.Coupon}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/coupons [get]
+func (h *PromotionHandler) ListCoupons(c *gin.Context) {
+	coupons, err := h.promotionUsecase.ListCoupons(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err, "Failed to list coupons")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Coupons retrieved successfully", coupons)
+}
+
+// GetCoupon godoc
+// @Summary      Get a coupon
+// @Description  Get a coupon by ID, including its redemption count
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Coupon ID"
+// @Success      200  {object}  response.Response{data=entity.Coupon}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/coupons/{id} [get]
+func (h *PromotionHandler) GetCoupon(c *gin.Context) {
+	couponID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid coupon ID", err.Error())
+		return
+	}
+
+	coupon, err := h.promotionUsecase.GetCoupon(c.Request.Context(), couponID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get coupon")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Coupon retrieved successfully", coupon)
+}
+
+// UpdateCoupon godoc
+// @Summary      Update a coupon
+// @Description  Replace a coupon's settings; the redemption count is kept
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                   true  "Coupon ID"
+// @Param        request  body      entity.CouponRequest  true  "Coupon"
+// @Success      200  {object}  response.Response{data=entity.Coupon}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/coupons/{id} [put]
+func (h *PromotionHandler) UpdateCoupon(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	couponID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid coupon ID", err.Error())
+		return
+	}
+
+	var req entity.CouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request format", err)
+		return
+	}
+
+	coupon, err := h.promotionUsecase.UpdateCoupon(ctx, couponID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update coupon")
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"coupon_id": coupon.ID,
+		"action":    "update_coupon",
+	}).Info("Coupon updated")
+
+	response.Success(c, http.StatusOK, "Coupon updated successfully", coupon)
+}
+
+// DeleteCoupon godoc
+// @Summary      Delete a coupon
+// @Description  Delete a coupon and its redemption history
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Coupon ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/coupons/{id} [delete]
+func (h *PromotionHandler) DeleteCoupon(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	couponID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid coupon ID", err.Error())
+		return
+	}
+
+	if err := h.promotionUsecase.DeleteCoupon(ctx, couponID); err != nil {
+		h.handleError(c, err, "Failed to delete coupon")
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"coupon_id": couponID,
+		"action":    "delete_coupon",
+	}).Info("Coupon deleted")
+
+	response.Success(c, http.StatusOK, "Coupon deleted successfully", nil)
+}
+
+// handleError maps promotion errors to HTTP responses.
+func (h *PromotionHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.IsCouponNotFound(err):
+		response.Error(c, http.StatusNotFound, "Coupon not found", err.Error())
+	case errors.IsInvalidCoupon(err):
+		response.BadRequest(c, message, err.Error())
+	case errors.IsCouponAlreadyExists(err):
+		response.Error(c, http.StatusConflict, message, err.Error())
+	default:
+		h.logger.ErrorLogger(c.Request.Context(), err, message, nil)
+		response.InternalServerError(c, message, err.Error())
+	}
+}
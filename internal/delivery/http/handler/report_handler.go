@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/report"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportHandler handles the admin reporting HTTP requests
+type ReportHandler struct {
+	reportUsecase *report.ReportUsecase
+	logger        *logger.Logger
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(reportUsecase *report.ReportUsecase, log *logger.Logger) *ReportHandler {
+	return &ReportHandler{
+		reportUsecase: reportUsecase,
+		logger:        log,
+	}
+}
+
+// Routes registers the admin reporting routes
+func (h *ReportHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/admin/reports",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeAdmin},
+			Dedup:  true,
+			Register: func(r gin.IRoutes) {
+				r.GET("/revenue", h.GetRevenue)
+				r.GET("/signups", h.GetSignups)
+				r.POST("/rollup", h.Rollup)
+			},
+		},
+	}
+}
+
+// GetRevenue godoc
+// @Summary      Revenue report
+// @Description  Orders, refunds and revenue per period and currency from the daily reporting tables, which are rolled up by a background job rather than computed from the orders table
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from      query     string  false  "First day (YYYY-MM-DD, UTC); defaults to 29 days before to"
+// @Param        to        query     string  false  "Last day (YYYY-MM-DD, UTC); defaults to today"
+// @Param        group_by  query     string  false  "Period of the rows"  Enums(day, week, month)  default(day)
+// @Success      200       {object}  response.Response{data=entity.RevenueReport}
+// @Failure      400       {object}  response.Response
+// @Failure      401       {object}  response.Response
+// @Failure      403       {object}  response.Response
+// @Failure      500       {object}  response.Response
+// @Router       /api/v1/admin/reports/revenue [get]
+func (h *ReportHandler) GetRevenue(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	query := reportQuery(c)
+	revenue, err := h.reportUsecase.Revenue(ctx, &query)
+	if err != nil {
+		h.reportError(c, err, "Failed to get revenue report")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Report retrieved successfully", revenue)
+}
+
+// GetSignups godoc
+// @Summary      Sign-up report
+// @Description  New users per period from the daily reporting tables
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from      query     string  false  "First day (YYYY-MM-DD, UTC); defaults to 29 days before to"
+// @Param        to        query     string  false  "Last day (YYYY-MM-DD, UTC); defaults to today"
+// @Param        group_by  query     string  false  "Period of the rows"  Enums(day, week, month)  default(day)
+// @Success      200       {object}  response.Response{data=entity.SignupReport}
+// @Failure      400       {object}  response.Response
+// @Failure      401       {object}  response.Response
+// @Failure      403       {object}  response.Response
+// @Failure      500       {object}  response.Response
+// @Router       /api/v1/admin/reports/signups [get]
+func (h *ReportHandler) GetSignups(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	query := reportQuery(c)
+	signups, err := h.reportUsecase.Signups(ctx, &query)
+	if err != nil {
+		h.reportError(c, err, "Failed to get sign-up report")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Report retrieved successfully", signups)
+}
+
+// Rollup godoc
+// @Summary      Roll up reports
+// @Description  Queue a background job rebuilding the reporting tables for a range of at most 366 days, to backfill them or to pick up changes to older orders
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      entity.ReportRollupRequest  true  "Days to roll up (YYYY-MM-DD, UTC)"
+// @Success      202      {object}  response.Response{data=entity.ReportRollupRequest}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/admin/reports/rollup [post]
+func (h *ReportHandler) Rollup(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.ReportRollupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.reportUsecase.RequestRollup(ctx, &req); err != nil {
+		h.reportError(c, err, "Failed to queue report rollup")
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Report rollup queued", req)
+}
+
+// reportQuery reads the report range and grouping from the query string
+func reportQuery(c *gin.Context) entity.ReportQuery {
+	return entity.ReportQuery{
+		From:    c.Query("from"),
+		To:      c.Query("to"),
+		GroupBy: c.Query("group_by"),
+	}
+}
+
+// reportError writes the error response of a report request
+func (h *ReportHandler) reportError(c *gin.Context, err error, message string) {
+	if errors.IsInvalidReportQuery(err) {
+		response.BadRequest(c, message, err.Error())
+		return
+	}
+	h.logger.ErrorLogger(c.Request.Context(), err, message, nil)
+	response.InternalServerError(c, message, err.Error())
+}
@@ -0,0 +1,399 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/user"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/password"
+	"boilerplate-go/pkg/scim"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSCIMPageSize caps the users of a SCIM list response
+const maxSCIMPageSize = 100
+
+// SCIMHandler serves SCIM 2.0 user provisioning to identity providers
+type SCIMHandler struct {
+	userUsecase *user.UserUsecase
+	logger      *logger.Logger
+}
+
+// NewSCIMHandler creates a new SCIM handler
+func NewSCIMHandler(userUsecase *user.UserUsecase, log *logger.Logger) *SCIMHandler {
+	return &SCIMHandler{
+		userUsecase: userUsecase,
+		logger:      log,
+	}
+}
+
+// Routes registers the SCIM routes, authenticated with the provisioning token
+func (h *SCIMHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/scim/v2",
+			Access: route.Provisioning,
+			Register: func(r gin.IRoutes) {
+				r.GET("/ServiceProviderConfig", h.GetServiceProviderConfig)
+				r.GET("/ResourceTypes", h.ListResourceTypes)
+				r.GET("/Users", h.ListUsers)
+				r.POST("/Users", h.CreateUser)
+				r.GET("/Users/:id", h.GetUser)
+				r.PUT("/Users/:id", h.ReplaceUser)
+				r.PATCH("/Users/:id", h.PatchUser)
+				r.DELETE("/Users/:id", h.DeleteUser)
+			},
+		},
+	}
+}
+
+// GetServiceProviderConfig godoc
+// @Summary      SCIM service provider configuration
+// @Description  The SCIM features supported: PATCH, password changes and equality filters
+// @Tags         scim
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  scim.ServiceProviderConfig
+// @Failure      401  {object}  response.Response
+// @Router       /scim/v2/ServiceProviderConfig [get]
+func (h *SCIMHandler) GetServiceProviderConfig(c *gin.Context) {
+	scimJSON(c, http.StatusOK, scim.NewServiceProviderConfig(maxSCIMPageSize))
+}
+
+// ListResourceTypes godoc
+// @Summary      SCIM resource types
+// @Description  The SCIM resources served: users only
+// @Tags         scim
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  scim.ListResponse{Resources=[]scim.ResourceType}
+// @Failure      401  {object}  response.Response
+// @Router       /scim/v2/ResourceTypes [get]
+func (h *SCIMHandler) ListResourceTypes(c *gin.Context) {
+	scimJSON(c, http.StatusOK, scim.NewListResponse([]interface{}{scim.UserResourceType()}, 1, 1))
+}
+
+// ListUsers godoc
+// @Summary      List SCIM users
+// @Description  Page through users, optionally filtered by an eq filter on userName, emails.value or externalId, such as userName eq "alice"
+// @Tags         scim
+// @Produce      json
+// @Security     BearerAuth
+// @Param        filter      query     string  false  "Equality filter"
+// @Param        startIndex  query     int     false  "1-based index of the first user"  default(1)
+// @Param        count       query     int     false  "Users per page, at most 100"      default(100)
+// @Success      200         {object}  scim.ListResponse{Resources=[]scim.User}
+// @Failure      400         {object}  scim.Error
+// @Failure      401         {object}  response.Response
+// @Failure      500         {object}  scim.Error
+// @Router       /scim/v2/Users [get]
+func (h *SCIMHandler) ListUsers(c *gin.Context) {
+	filter := entity.UserFilter{Limit: maxSCIMPageSize}
+	if expression := c.Query("filter"); expression != "" {
+		parsed, err := scim.ParseFilter(expression)
+		if err != nil {
+			h.fail(c, err)
+			return
+		}
+		switch parsed.Attribute {
+		case "username":
+			filter.Username = parsed.Value
+		case "emails", "emails.value":
+			filter.Email = parsed.Value
+		case "externalid":
+			filter.ExternalID = parsed.Value
+		default:
+			scimJSON(c, http.StatusBadRequest, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidFilter,
+				"filters are supported on userName, emails.value and externalId"))
+			return
+		}
+	}
+
+	startIndex, err := strconv.Atoi(c.DefaultQuery("startIndex", "1"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+	filter.Offset = startIndex - 1
+	if count, err := strconv.Atoi(c.Query("count")); err == nil && count >= 0 && count < maxSCIMPageSize {
+		filter.Limit = count
+	}
+
+	users, total, err := h.userUsecase.ListUsers(c.Request.Context(), filter)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	resources := make([]interface{}, len(users))
+	for i, u := range users {
+		resources[i] = toSCIMUser(c, u)
+	}
+	scimJSON(c, http.StatusOK, scim.NewListResponse(resources, total, startIndex))
+}
+
+// GetUser godoc
+// @Summary      Get a SCIM user
+// @Tags         scim
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  scim.User
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  scim.Error
+// @Failure      500  {object}  scim.Error
+// @Router       /scim/v2/Users/{id} [get]
+func (h *SCIMHandler) GetUser(c *gin.Context) {
+	u, ok := h.user(c)
+	if !ok {
+		return
+	}
+	scimJSON(c, http.StatusOK, toSCIMUser(c, u))
+}
+
+// CreateUser godoc
+// @Summary      Create a SCIM user
+// @Description  Create a user with the userName, the primary email address and the name. The externalId sent is ignored: users get the external ID derived from their email address. The password is optional and must meet the password policy; users created inactive cannot sign in
+// @Tags         scim
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      scim.User  true  "User"
+// @Success      201      {object}  scim.User
+// @Failure      400      {object}  scim.Error
+// @Failure      401      {object}  response.Response
+// @Failure      409      {object}  scim.Error
+// @Failure      500      {object}  scim.Error
+// @Router       /scim/v2/Users [post]
+func (h *SCIMHandler) CreateUser(c *gin.Context) {
+	var req scim.User
+	if err := c.ShouldBindJSON(&req); err != nil {
+		scimJSON(c, http.StatusBadRequest, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, err.Error()))
+		return
+	}
+	directoryUser, ok := fromSCIMUser(c, &req)
+	if !ok {
+		return
+	}
+
+	u, err := h.userUsecase.CreateDirectoryUser(c.Request.Context(), directoryUser)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Header("Location", scimLocation(c, u))
+	scimJSON(c, http.StatusCreated, toSCIMUser(c, u))
+}
+
+// ReplaceUser godoc
+// @Summary      Replace a SCIM user
+// @Description  Replace the userName, email address, name and active state of the user, and its password when one is sent. Deactivating a user logs out all of its sessions
+// @Tags         scim
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int        true  "User ID"
+// @Param        request  body      scim.User  true  "User"
+// @Success      200      {object}  scim.User
+// @Failure      400      {object}  scim.Error
+// @Failure      401      {object}  response.Response
+// @Failure      404      {object}  scim.Error
+// @Failure      409      {object}  scim.Error
+// @Failure      500      {object}  scim.Error
+// @Router       /scim/v2/Users/{id} [put]
+func (h *SCIMHandler) ReplaceUser(c *gin.Context) {
+	id, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	var req scim.User
+	if err := c.ShouldBindJSON(&req); err != nil {
+		scimJSON(c, http.StatusBadRequest, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, err.Error()))
+		return
+	}
+	h.replace(c, id, &req)
+}
+
+// PatchUser godoc
+// @Summary      Patch a SCIM user
+// @Description  Apply add, replace and remove operations to active, userName, displayName, password, name, emails or an email selected by type, such as emails[type eq "work"].value. Deactivating a user logs out all of its sessions
+// @Tags         scim
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                true  "User ID"
+// @Param        request  body      scim.PatchRequest  true  "Operations"
+// @Success      200      {object}  scim.User
+// @Failure      400      {object}  scim.Error
+// @Failure      401      {object}  response.Response
+// @Failure      404      {object}  scim.Error
+// @Failure      409      {object}  scim.Error
+// @Failure      500      {object}  scim.Error
+// @Router       /scim/v2/Users/{id} [patch]
+func (h *SCIMHandler) PatchUser(c *gin.Context) {
+	var req scim.PatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		scimJSON(c, http.StatusBadRequest, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, err.Error()))
+		return
+	}
+	u, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	patched := toSCIMUser(c, u)
+	if err := patched.Apply(req.Operations); err != nil {
+		h.fail(c, err)
+		return
+	}
+	h.replace(c, u.ID, patched)
+}
+
+// DeleteUser godoc
+// @Summary      Deactivate a SCIM user
+// @Description  Deactivate the user and log out all of its sessions. The account and its orders are kept so the user can be reactivated
+// @Tags         scim
+// @Security     BearerAuth
+// @Param        id   path  int  true  "User ID"
+// @Success      204
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  scim.Error
+// @Failure      500  {object}  scim.Error
+// @Router       /scim/v2/Users/{id} [delete]
+func (h *SCIMHandler) DeleteUser(c *gin.Context) {
+	id, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	if err := h.userUsecase.DeactivateUser(c.Request.Context(), id); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *SCIMHandler) replace(c *gin.Context, id int, req *scim.User) {
+	directoryUser, ok := fromSCIMUser(c, req)
+	if !ok {
+		return
+	}
+	u, err := h.userUsecase.ReplaceDirectoryUser(c.Request.Context(), id, directoryUser)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	scimJSON(c, http.StatusOK, toSCIMUser(c, u))
+}
+
+// userID parses the user ID of the path; IDs that are not numbers belong to
+// no user
+func (h *SCIMHandler) userID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.fail(c, errors.ErrUserNotFound)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *SCIMHandler) user(c *gin.Context) (*entity.User, bool) {
+	id, ok := h.userID(c)
+	if !ok {
+		return nil, false
+	}
+	u, err := h.userUsecase.GetProfile(c.Request.Context(), id)
+	if err != nil {
+		h.fail(c, err)
+		return nil, false
+	}
+	return u, true
+}
+
+// fail writes the SCIM error response of err
+func (h *SCIMHandler) fail(c *gin.Context, err error) {
+	var policyErr *password.PolicyError
+	status, scimType := http.StatusInternalServerError, ""
+	switch {
+	case errors.IsUserNotFound(err):
+		status = http.StatusNotFound
+	case stderrors.Is(err, errors.ErrUserAlreadyExists):
+		status, scimType = http.StatusConflict, scim.ErrorUniqueness
+	case errors.IsConflict(err):
+		status = http.StatusConflict
+	case stderrors.As(err, &policyErr):
+		status, scimType = http.StatusBadRequest, scim.ErrorInvalidValue
+	case stderrors.Is(err, scim.ErrInvalidFilter):
+		status, scimType = http.StatusBadRequest, scim.ErrorInvalidFilter
+	case stderrors.Is(err, scim.ErrInvalidPath):
+		status, scimType = http.StatusBadRequest, scim.ErrorInvalidPath
+	case stderrors.Is(err, scim.ErrInvalidValue):
+		status, scimType = http.StatusBadRequest, scim.ErrorInvalidValue
+	default:
+		h.logger.ErrorLogger(c.Request.Context(), err, "SCIM request failed", map[string]interface{}{
+			"path": c.FullPath(),
+		})
+	}
+	scimJSON(c, status, scim.NewError(status, scimType, err.Error()))
+}
+
+// fromSCIMUser returns the user an identity provider sent, writing an error
+// response when it has no userName or email address
+func fromSCIMUser(c *gin.Context, u *scim.User) (*entity.DirectoryUser, bool) {
+	email := u.PrimaryEmail()
+	if strings.TrimSpace(u.UserName) == "" || email == "" {
+		scimJSON(c, http.StatusBadRequest, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue,
+			"userName and an email address are required"))
+		return nil, false
+	}
+
+	directoryUser := &entity.DirectoryUser{
+		Username: u.UserName,
+		Email:    email,
+		Password: u.Password,
+		Active:   u.IsActive(),
+	}
+	if u.Name != nil {
+		directoryUser.FirstName = u.Name.GivenName
+		directoryUser.LastName = u.Name.FamilyName
+	}
+	return directoryUser, true
+}
+
+func toSCIMUser(c *gin.Context, u *entity.User) *scim.User {
+	active := u.DeactivatedAt == nil
+	fullName := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	resource := &scim.User{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          strconv.Itoa(u.ID),
+		ExternalID:  u.ExternalID,
+		UserName:    u.Username,
+		DisplayName: fullName,
+		Emails:      []scim.Email{{Value: u.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      u.CreatedAt,
+			LastModified: u.UpdatedAt,
+			Location:     scimLocation(c, u),
+		},
+	}
+	if fullName != "" {
+		resource.Name = &scim.Name{Formatted: fullName, GivenName: u.FirstName, FamilyName: u.LastName}
+	}
+	return resource
+}
+
+func scimLocation(c *gin.Context, u *entity.User) string {
+	return baseURL(c) + "/scim/v2/Users/" + strconv.Itoa(u.ID)
+}
+
+// scimJSON writes a SCIM JSON response
+func scimJSON(c *gin.Context, status int, body interface{}) {
+	c.Header("Content-Type", scim.MediaType)
+	c.JSON(status, body)
+}
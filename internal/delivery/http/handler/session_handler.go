@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/delivery/http/middleware"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/session"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler handles session management HTTP requests
+type SessionHandler struct {
+	sessionUsecase *session.SessionUsecase
+	cookies        *middleware.AuthCookies
+	logger         *logger.Logger
+	metrics        *metrics.Metrics
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(sessionUsecase *session.SessionUsecase, log *logger.Logger, m *metrics.Metrics) *SessionHandler {
+	return &SessionHandler{
+		sessionUsecase: sessionUsecase,
+		logger:         log,
+		metrics:        m,
+	}
+}
+
+// SetCookies makes logging out clear the session cookies of browser clients
+func (h *SessionHandler) SetCookies(cookies *middleware.AuthCookies) {
+	h.cookies = cookies
+}
+
+// Routes registers logging out and the session management routes of the
+// current user
+func (h *SessionHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			// Any token may end its own session
+			Prefix: "/api/v1/auth",
+			Access: route.Authenticated,
+			Register: func(r gin.IRoutes) {
+				r.POST("/logout", h.Logout)
+			},
+		},
+		{
+			Prefix: "/api/v1/user",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeSessions},
+			Register: func(r gin.IRoutes) {
+				r.GET("/sessions", h.ListSessions)
+				r.DELETE("/sessions", h.RevokeAllSessions)
+				r.DELETE("/sessions/:id", h.RevokeSession)
+			},
+		},
+	}
+}
+
+// Logout godoc
+// @Summary      Log out
+// @Description  Revoke the session of the token the request is authenticated with and clear the session cookies
+// @Tags         authentication
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/auth/logout [post]
+func (h *SessionHandler) Logout(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := c.GetInt("user_id")
+	if err := h.sessionUsecase.Logout(ctx, userID, c.GetString("token_id")); err != nil && !errors.IsSessionNotFound(err) {
+		h.logger.ErrorLogger(ctx, err, "Failed to log out", map[string]interface{}{
+			"user_id": userID,
+		})
+		response.InternalServerError(c, "Failed to log out", err.Error())
+		return
+	}
+
+	if h.cookies != nil {
+		h.cookies.Clear(c)
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id": userID,
+		"action":  "logout",
+	}).Info("User logged out")
+
+	response.Success(c, http.StatusOK, "Logged out successfully", nil)
+}
+
+// ListSessions godoc
+// @Summary      List active sessions
+// @Description  List the authenticated user's active sessions and devices
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]entity.Session}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/user/sessions [get]
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := c.GetInt("user_id")
+	sessions, err := h.sessionUsecase.ListSessions(ctx, userID, c.GetString("token_id"))
+	if err != nil {
+		h.logger.ErrorLogger(ctx, err, "Failed to list sessions", map[string]interface{}{
+			"user_id": userID,
+		})
+		response.InternalServerError(c, "Failed to list sessions", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Sessions retrieved successfully", sessions)
+}
+
+// RevokeSession godoc
+// @Summary      Revoke a session
+// @Description  Revoke one of the authenticated user's sessions
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Session ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/user/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid session ID", err.Error())
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	if err := h.sessionUsecase.RevokeSession(ctx, userID, sessionID); err != nil {
+		if errors.IsSessionNotFound(err) {
+			response.Error(c, http.StatusNotFound, "Session not found", err.Error())
+			return
+		}
+		h.logger.ErrorLogger(ctx, err, "Failed to revoke session", map[string]interface{}{
+			"user_id":    userID,
+			"session_id": sessionID,
+		})
+		response.InternalServerError(c, "Failed to revoke session", err.Error())
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id":    userID,
+		"session_id": sessionID,
+		"action":     "revoke_session",
+	}).Info("Session revoked")
+
+	response.Success(c, http.StatusOK, "Session revoked successfully", nil)
+}
+
+// RevokeAllSessions godoc
+// @Summary      Log out everywhere
+// @Description  Revoke all of the authenticated user's sessions, including the current one
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/user/sessions [delete]
+func (h *SessionHandler) RevokeAllSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := c.GetInt("user_id")
+	if err := h.sessionUsecase.RevokeAllSessions(ctx, userID); err != nil {
+		h.logger.ErrorLogger(ctx, err, "Failed to revoke sessions", map[string]interface{}{
+			"user_id": userID,
+		})
+		response.InternalServerError(c, "Failed to revoke sessions", err.Error())
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id": userID,
+		"action":  "revoke_all_sessions",
+	}).Info("All sessions revoked")
+
+	response.Success(c, http.StatusOK, "Logged out of all sessions", nil)
+}
@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/delivery/http/middleware"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/auth"
+	"boilerplate-go/internal/usecase/sso"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SSOHandler handles single sign-on HTTP requests: signing in with the
+// identity provider of an organization, and its configuration by owners
+type SSOHandler struct {
+	authUsecase *auth.AuthUsecase
+	ssoUsecase  *sso.SSOUsecase
+	cookies     *middleware.AuthCookies
+	logger      *logger.Logger
+	metrics     *metrics.Metrics
+}
+
+// NewSSOHandler creates a new single sign-on handler
+func NewSSOHandler(authUsecase *auth.AuthUsecase, ssoUsecase *sso.SSOUsecase, log *logger.Logger, m *metrics.Metrics) *SSOHandler {
+	return &SSOHandler{
+		authUsecase: authUsecase,
+		ssoUsecase:  ssoUsecase,
+		logger:      log,
+		metrics:     m,
+	}
+}
+
+// SetCookies makes sign-ins set the session cookies of browser clients
+func (h *SSOHandler) SetCookies(cookies *middleware.AuthCookies) {
+	h.cookies = cookies
+}
+
+// Routes registers the single sign-on routes
+func (h *SSOHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/auth/sso",
+			Register: func(r gin.IRoutes) {
+				r.POST("/start", h.StartSSO)
+				r.POST("/callback", h.SSOCallback)
+			},
+		},
+		{
+			Prefix: "/api/v1/organizations",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeOrganizations},
+			Dedup:  true,
+			Register: func(r gin.IRoutes) {
+				r.GET("/:id/sso", h.GetSSOConnection)
+				r.PUT("/:id/sso", h.SaveSSOConnection)
+				r.DELETE("/:id/sso", h.DeleteSSOConnection)
+			},
+		},
+	}
+}
+
+// StartSSO godoc
+// @Summary      Start single sign-on
+// @Description  Start signing in with the identity provider of an organization. Send the user to the returned authorization URL; the identity provider redirects back to SSO_REDIRECT_URL with the code and state to post to the callback.
+// @Tags         authentication
+// @Accept       json
+// @Produce      json
+// @Param        request  body      entity.SSOStartRequest  true  "Organization"
+// @Success      200      {object}  response.Response{data=entity.SSOStart}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Failure      503      {object}  response.Response
+// @Router       /api/v1/auth/sso/start [post]
+func (h *SSOHandler) StartSSO(c *gin.Context) {
+	var req entity.SSOStartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	start, err := h.ssoUsecase.Start(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to start single sign-on")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Single sign-on started", start)
+}
+
+// SSOCallback godoc
+// @Summary      Complete single sign-on
+// @Description  Exchange the code and state the identity provider redirected back with for a JWT token, set in the session cookie with AUTH_MODE=cookie or both. Unknown users of the organization get an account when just-in-time provisioning is on.
+// @Tags         authentication
+// @Accept       json
+// @Produce      json
+// @Param        request  body      entity.SSOCallbackRequest  true  "Code and state"
+// @Success      200      {object}  response.Response{data=entity.LoginResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/auth/sso/callback [post]
+func (h *SSOHandler) SSOCallback(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.SSOCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.metrics.RecordAuthAttempt("sso_login", false)
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	loginResponse, err := h.authUsecase.LoginWithSSO(ctx, &req)
+	if err != nil {
+		h.metrics.RecordAuthAttempt("sso_login", false)
+		h.handleError(c, err, "Login failed")
+		return
+	}
+
+	if !signInCookies(c, h.cookies, h.logger, loginResponse) {
+		h.metrics.RecordAuthAttempt("sso_login", false)
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id":  loginResponse.User.ID,
+		"username": loginResponse.User.Username,
+		"action":   "sso_login_success",
+	}).Info("User logged in with single sign-on")
+
+	h.metrics.RecordAuthAttempt("sso_login", true)
+	response.Success(c, http.StatusOK, "Login successful", loginResponse)
+}
+
+// GetSSOConnection godoc
+// @Summary      Get single sign-on configuration
+// @Description  Get the identity provider connection of an organization the authenticated user owns. The client secret is never returned.
+// @Tags         organizations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Organization ID"
+// @Success      200  {object}  response.Response{data=entity.SSOConnection}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/organizations/{id}/sso [get]
+func (h *SSOHandler) GetSSOConnection(c *gin.Context) {
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return
+	}
+
+	conn, err := h.ssoUsecase.GetConnection(c.Request.Context(), c.GetInt("user_id"), orgID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get single sign-on configuration")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Single sign-on configuration retrieved successfully", conn)
+}
+
+// SaveSSOConnection godoc
+// @Summary      Configure single sign-on
+// @Description  Create or replace the OpenID Connect identity provider of an organization the authenticated user owns. The issuer must serve a discovery document. Changing the issuer unlinks the identities of members.
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                          true  "Organization ID"
+// @Param        request  body      entity.SSOConnectionRequest  true  "Identity provider"
+// @Success      200      {object}  response.Response{data=entity.SSOConnection}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/organizations/{id}/sso [put]
+func (h *SSOHandler) SaveSSOConnection(c *gin.Context) {
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return
+	}
+
+	var req entity.SSOConnectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	conn, err := h.ssoUsecase.SaveConnection(c.Request.Context(), c.GetInt("user_id"), orgID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to configure single sign-on")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Single sign-on configured successfully", conn)
+}
+
+// DeleteSSOConnection godoc
+// @Summary      Turn off single sign-on
+// @Description  Remove the identity provider of an organization the authenticated user owns. Members keep their accounts.
+// @Tags         organizations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Organization ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/organizations/{id}/sso [delete]
+func (h *SSOHandler) DeleteSSOConnection(c *gin.Context) {
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ssoUsecase.DeleteConnection(c.Request.Context(), c.GetInt("user_id"), orgID); err != nil {
+		h.handleError(c, err, "Failed to turn off single sign-on")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Single sign-on turned off successfully", nil)
+}
+
+// orgID parses the organization ID path parameter, answering 400 otherwise
+func (h *SSOHandler) orgID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid organization ID", "must be a positive integer")
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *SSOHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.IsSSONotConfigured(err):
+		response.Error(c, http.StatusNotFound, "Single sign-on is not configured", err.Error())
+	case errors.IsOrgNotFound(err):
+		response.Error(c, http.StatusNotFound, "Organization not found", err.Error())
+	case errors.IsInvalidSSOState(err), errors.IsInvalidOrganization(err):
+		response.BadRequest(c, message, err.Error())
+	case errors.IsSSOFailed(err):
+		h.logger.WithContext(c.Request.Context()).WithError(err).Warn(message)
+		response.Unauthorized(c, message, err.Error())
+	case errors.IsOrgRoleRequired(err), errors.IsSSONotProvisioned(err), errors.IsUserDeactivated(err):
+		response.Forbidden(c, message, err.Error())
+	case errors.IsSSOAccountConflict(err):
+		response.Error(c, http.StatusConflict, message, err.Error())
+	case errors.IsProviderUnavailable(err):
+		h.logger.WithContext(c.Request.Context()).WithError(err).Warn(message)
+		response.Error(c, http.StatusServiceUnavailable, message, err.Error())
+	default:
+		h.logger.ErrorLogger(c.Request.Context(), err, message, nil)
+		response.InternalServerError(c, message, err.Error())
+	}
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/pkg/response"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StaticOptions controls how static files are served
+type StaticOptions struct {
+	// MaxAge is how long clients and CDNs may cache a file. HTML files are
+	// always revalidated so they pick up new asset names.
+	MaxAge time.Duration
+	// Immutable marks files as never changing under the same name
+	Immutable bool
+	// SPA serves index.html for browser navigations to unknown paths
+	SPA bool
+}
+
+// StaticHandler serves the files of a directory with ETag and Cache-Control
+// headers and range support. Paths cannot leave the directory, including
+// through symlinks, and hidden files and directory listings are not served.
+type StaticHandler struct {
+	root    *os.Root
+	prefix  string
+	options StaticOptions
+	logger  *logger.Logger
+}
+
+// NewStaticHandler creates a handler serving dir at the URL prefix
+func NewStaticHandler(dir, prefix string, options StaticOptions, log *logger.Logger) (*StaticHandler, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open static directory: %w", err)
+	}
+
+	return &StaticHandler{
+		root:    root,
+		prefix:  strings.TrimSuffix(prefix, "/"),
+		options: options,
+		logger:  log,
+	}, nil
+}
+
+// Serve serves the file named by the request path below the prefix
+func (h *StaticHandler) Serve(c *gin.Context) {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		response.Error(c, http.StatusNotFound, "Not found", "")
+		return
+	}
+
+	name := path.Clean("/" + strings.TrimPrefix(c.Request.URL.Path, h.prefix))
+	if hidden(name) {
+		response.Error(c, http.StatusNotFound, "File not found", "")
+		return
+	}
+
+	file, stat, err := h.open(name)
+	if errors.Is(err, fs.ErrNotExist) && h.options.SPA && path.Ext(name) == "" && acceptsHTML(c.Request) {
+		name = "/index.html"
+		file, stat, err = h.open(name)
+	}
+	if err != nil {
+		// Other failures include symlinks leading out of the directory
+		if !errors.Is(err, fs.ErrNotExist) {
+			h.logger.ErrorLogger(c.Request.Context(), err, "Failed to open static file", map[string]interface{}{
+				"path": name,
+			})
+		}
+		response.Error(c, http.StatusNotFound, "File not found", "")
+		return
+	}
+	defer file.Close()
+
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("ETag", fmt.Sprintf(`"%x-%x"`, stat.ModTime().UnixNano(), stat.Size()))
+	c.Header("Cache-Control", h.cacheControl(stat.Name()))
+	http.ServeContent(c.Writer, c.Request, stat.Name(), stat.ModTime(), file)
+}
+
+// open opens a file, or the index.html of a directory.
+func (h *StaticHandler) open(name string) (*os.File, os.FileInfo, error) {
+	rel := strings.TrimPrefix(name, "/")
+	if rel == "" {
+		rel = "."
+	}
+
+	file, stat, err := h.openFile(rel)
+	if err != nil || !stat.IsDir() {
+		return file, stat, err
+	}
+	file.Close()
+
+	// Directories are served through their index.html and never listed
+	file, stat, err = h.openFile(path.Join(rel, "index.html"))
+	if err == nil && stat.IsDir() {
+		file.Close()
+		return nil, nil, fs.ErrNotExist
+	}
+	return file, stat, err
+}
+
+func (h *StaticHandler) openFile(name string) (*os.File, os.FileInfo, error) {
+	file, err := h.root.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, stat, nil
+}
+
+// cacheControl returns the Cache-Control header of a file.
+func (h *StaticHandler) cacheControl(name string) string {
+	if path.Ext(name) == ".html" {
+		return "no-cache"
+	}
+	cacheControl := fmt.Sprintf("public, max-age=%d", int(h.options.MaxAge.Seconds()))
+	if h.options.Immutable {
+		cacheControl += ", immutable"
+	}
+	return cacheControl
+}
+
+// hidden reports whether a cleaned path has a segment starting with a dot,
+// such as .env or the .multipart directory of local storage.
+func hidden(name string) bool {
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsHTML reports whether the request is a browser navigation rather than
+// an API or asset request.
+func acceptsHTML(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
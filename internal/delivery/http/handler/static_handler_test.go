@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "assets"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".multipart"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html></html>"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "assets", "app.js"), []byte("console.log(1)"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".multipart", "part"), []byte("secret"), 0o644))
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	require.NoError(t, os.WriteFile(outside, []byte("outside"), 0o644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "link.txt")))
+
+	static, err := NewStaticHandler(dir, "/", StaticOptions{MaxAge: time.Hour, SPA: true}, logger.NewLogger())
+	require.NoError(t, err)
+	r := gin.New()
+	r.GET("/api/v1/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+	r.NoRoute(static.Serve)
+	get := func(target string, header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/assets/app.js", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "console.log(1)", w.Body.String())
+	assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+	assert.Contains(t, w.Header().Get("Content-Type"), "javascript")
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	assert.Equal(t, http.StatusNotModified, get("/assets/app.js", http.Header{"If-None-Match": {etag}}).Code)
+	w = get("/assets/app.js", http.Header{"Range": {"bytes=0-6"}})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "console", w.Body.String())
+
+	// Browser navigations to unknown paths get the app, which is always revalidated
+	w = get("/orders/7", http.Header{"Accept": {"text/html"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<html></html>", w.Body.String())
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.Equal(t, http.StatusNotFound, get("/orders/7", http.Header{"Accept": {"application/json"}}).Code)
+	assert.Equal(t, http.StatusNotFound, get("/assets/missing.js", http.Header{"Accept": {"text/html"}}).Code)
+
+	assert.Equal(t, "pong", get("/api/v1/ping", nil).Body.String())
+	assert.Equal(t, http.StatusNotFound, get("/.multipart/part", nil).Code)
+	assert.Equal(t, http.StatusNotFound, get("/link.txt", nil).Code)
+	assert.NotEqual(t, "outside", get("/../"+filepath.Base(outside), nil).Body.String())
+}
+
+func TestStaticHandler_Prefix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.jpg"), []byte("jpeg"), 0o644))
+
+	static, err := NewStaticHandler(dir, "/files/avatars", StaticOptions{MaxAge: 365 * 24 * time.Hour, Immutable: true}, logger.NewLogger())
+	require.NoError(t, err)
+	r := gin.New()
+	r.GET("/files/avatars/*filepath", static.Serve)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/files/avatars/a.jpg", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+
+	// Directories are not listed
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/files/avatars/", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/stats"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultStatsDays is the period of the dashboard without a days parameter
+const defaultStatsDays = 30
+
+// StatsHandler handles the admin dashboard HTTP requests
+type StatsHandler struct {
+	statsUsecase *stats.StatsUsecase
+	logger       *logger.Logger
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(statsUsecase *stats.StatsUsecase, log *logger.Logger) *StatsHandler {
+	return &StatsHandler{
+		statsUsecase: statsUsecase,
+		logger:       log,
+	}
+}
+
+// Routes registers the admin stats routes
+func (h *StatsHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/admin",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeAdmin},
+			Register: func(r gin.IRoutes) {
+				r.GET("/stats", h.GetStats)
+			},
+		},
+	}
+}
+
+// GetStats godoc
+// @Summary      Dashboard statistics
+// @Description  Daily new users, orders and revenue per currency, and campaign emails sent and failed, with the refund and notification delivery rates over the period. Days are in UTC; days without activity are left out. Results are cached for STATS_CACHE_TTL
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        days  query     int  false  "Days to aggregate, today included (max 365)"  default(30)
+// @Success      200   {object}  response.Response{data=entity.DashboardStats}
+// @Failure      400   {object}  response.Response
+// @Failure      401   {object}  response.Response
+// @Failure      403   {object}  response.Response
+// @Failure      500   {object}  response.Response
+// @Router       /api/v1/admin/stats [get]
+func (h *StatsHandler) GetStats(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	days := defaultStatsDays
+	if value := c.Query("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			response.BadRequest(c, "Invalid days", err.Error())
+			return
+		}
+		days = n
+	}
+
+	dashboard, err := h.statsUsecase.Dashboard(ctx, days)
+	if err != nil {
+		if errors.IsInvalidStatsRange(err) {
+			response.BadRequest(c, "Invalid days", err.Error())
+			return
+		}
+		h.logger.ErrorLogger(ctx, err, "Failed to get stats", nil)
+		response.InternalServerError(c, "Failed to get stats", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Stats retrieved successfully", dashboard)
+}
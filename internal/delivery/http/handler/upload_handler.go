@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/upload"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadHandler handles resumable upload HTTP requests
+type UploadHandler struct {
+	uploadUsecase *upload.UploadUsecase
+	logger        *logger.Logger
+}
+
+// NewUploadHandler creates a new upload handler
+func NewUploadHandler(uploadUsecase *upload.UploadUsecase, log *logger.Logger) *UploadHandler {
+	return &UploadHandler{
+		uploadUsecase: uploadUsecase,
+		logger:        log,
+	}
+}
+
+// Routes registers the resumable upload routes. Parts are large raw bodies,
+// so they skip request deduplication.
+func (h *UploadHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/uploads",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeUploads},
+			Register: func(r gin.IRoutes) {
+				r.POST("", h.InitiateUpload)
+				r.GET("/:id", h.GetUpload)
+				r.PUT("/:id/parts/:part", h.UploadPart)
+				r.POST("/:id/complete", h.CompleteUpload)
+				r.DELETE("/:id", h.AbortUpload)
+				r.GET("/:id/download", h.GetDownloadURL)
+			},
+		},
+	}
+}
+
+// InitiateUpload godoc
+// @Summary      Start a resumable upload
+// @Description  Start uploading a file in parts. The response gives the part size and count; send every part, then complete the upload
+// @Tags         uploads
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      entity.InitiateUploadRequest  true  "File to upload"
+// @Success      201      {object}  response.Response{data=entity.Upload}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Failure      503      {object}  response.Response
+// @Router       /api/v1/uploads [post]
+func (h *UploadHandler) InitiateUpload(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.InitiateUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request format", err)
+		return
+	}
+
+	result, err := h.uploadUsecase.Initiate(ctx, c.GetInt("user_id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to start upload")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Upload started successfully", result)
+}
+
+// GetUpload godoc
+// @Summary      Get a resumable upload
+// @Description  Get an upload with the parts received so far, to resume it after an interruption
+// @Tags         uploads
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Upload ID"
+// @Success      200  {object}  response.Response{data=entity.Upload}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/uploads/{id} [get]
+func (h *UploadHandler) GetUpload(c *gin.Context) {
+	id, ok := h.uploadID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.uploadUsecase.Get(c.Request.Context(), c.GetInt("user_id"), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to get upload")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Upload retrieved successfully", result)
+}
+
+// UploadPart godoc
+// @Summary      Upload a part
+// @Description  Send one part of a resumable upload as the raw request body. Every part has the upload's part size except the last, which holds the remainder. Sending a part again replaces it
+// @Tags         uploads
+// @Accept       octet-stream
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int     true  "Upload ID"
+// @Param        part  path      int     true  "Part number, starting at 1"
+// @Param        body  body      string  true  "Part content"
+// @Success      200   {object}  response.Response{data=entity.UploadPart}
+// @Failure      400   {object}  response.Response
+// @Failure      401   {object}  response.Response
+// @Failure      404   {object}  response.Response
+// @Failure      409   {object}  response.Response
+// @Failure      500   {object}  response.Response
+// @Router       /api/v1/uploads/{id}/parts/{part} [put]
+func (h *UploadHandler) UploadPart(c *gin.Context) {
+	id, ok := h.uploadID(c)
+	if !ok {
+		return
+	}
+	partNumber, err := strconv.Atoi(c.Param("part"))
+	if err != nil {
+		response.BadRequest(c, "Invalid part number", err.Error())
+		return
+	}
+
+	// Parts from slow connections may outlast the server's read and write
+	// timeouts, which are sized for ordinary requests
+	if timeout := h.uploadUsecase.PartTimeout(); timeout > 0 {
+		controller := http.NewResponseController(c.Writer)
+		deadline := time.Now().Add(timeout)
+		controller.SetReadDeadline(deadline)
+		controller.SetWriteDeadline(deadline)
+	}
+
+	part, err := h.uploadUsecase.UploadPart(c.Request.Context(), c.GetInt("user_id"), id, partNumber, c.Request.Body)
+	if err != nil {
+		h.handleError(c, err, "Failed to upload part")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Part uploaded successfully", part)
+}
+
+// CompleteUpload godoc
+// @Summary      Complete a resumable upload
+// @Description  Assemble the file once every part has been uploaded
+// @Tags         uploads
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Upload ID"
+// @Success      200  {object}  response.Response{data=entity.Upload}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/uploads/{id}/complete [post]
+func (h *UploadHandler) CompleteUpload(c *gin.Context) {
+	id, ok := h.uploadID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.uploadUsecase.Complete(c.Request.Context(), c.GetInt("user_id"), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to complete upload")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Upload completed successfully", result)
+}
+
+// AbortUpload godoc
+// @Summary      Abort a resumable upload
+// @Description  Discard a pending upload and the parts uploaded for it
+// @Tags         uploads
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Upload ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/uploads/{id} [delete]
+func (h *UploadHandler) AbortUpload(c *gin.Context) {
+	id, ok := h.uploadID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.uploadUsecase.Abort(c.Request.Context(), c.GetInt("user_id"), id); err != nil {
+		h.handleError(c, err, "Failed to abort upload")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Upload aborted successfully", nil)
+}
+
+// GetDownloadURL godoc
+// @Summary      Get a download link
+// @Description  Get a signed, time limited URL that downloads the file of a completed upload directly from storage. When malware scanning is enabled the file is available once it has been scanned clean
+// @Tags         uploads
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Upload ID"
+// @Success      200  {object}  response.Response{data=entity.UploadDownloadResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Failure      503  {object}  response.Response
+// @Router       /api/v1/uploads/{id}/download [get]
+func (h *UploadHandler) GetDownloadURL(c *gin.Context) {
+	id, ok := h.uploadID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.uploadUsecase.DownloadURL(c.Request.Context(), c.GetInt("user_id"), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to get download link")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Download link created successfully", result)
+}
+
+// uploadID parses the upload ID path parameter.
+func (h *UploadHandler) uploadID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid upload ID", err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *UploadHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.IsUploadNotFound(err):
+		response.Error(c, http.StatusNotFound, "Upload not found", err.Error())
+	case errors.IsInvalidUpload(err):
+		response.BadRequest(c, message, err.Error())
+	case errors.IsUploadClosed(err):
+		response.Error(c, http.StatusConflict, message, err.Error())
+	case errors.IsFileNotScanned(err):
+		response.Error(c, http.StatusConflict, "File has not passed its malware scan", err.Error())
+	case errors.IsFileInfected(err):
+		response.Error(c, http.StatusForbidden, "File was quarantined", err.Error())
+	case errors.IsProviderUnavailable(err):
+		response.Error(c, http.StatusServiceUnavailable, message, err.Error())
+	default:
+		h.logger.ErrorLogger(c.Request.Context(), err, message, map[string]interface{}{
+			"user_id": c.GetInt("user_id"),
+		})
+		response.InternalServerError(c, message, err.Error())
+	}
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	stderrors "errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead allows for the multipart boundaries and headers around an avatar upload
+const multipartOverhead = 64 << 10
+
+// UploadAvatar godoc
+// @Summary      Upload avatar
+// @Description  Upload a JPEG, PNG or GIF image as a multipart "avatar" field. It is cropped to a square, resized to 64, 128, 256 and 512 pixels and stored; the 512 pixel URL is saved on the profile
+// @Tags         users
+// @Accept       mpfd
+// @Produce      json
+// @Security     BearerAuth
+// @Param        avatar  formData  file  true  "Avatar image"
+// @Success      200     {object}  response.Response{data=entity.AvatarUploadResponse}
+// @Failure      400     {object}  response.Response
+// @Failure      401     {object}  response.Response
+// @Failure      409     {object}  response.Response
+// @Failure      413     {object}  response.Response
+// @Failure      500     {object}  response.Response
+// @Failure      503     {object}  response.Response
+// @Router       /api/v1/user/avatar [post]
+func (h *UserHandler) UploadAvatar(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Bound the whole body before multipart parsing spools it to disk
+	if limit := h.userUsecase.AvatarMaxUploadSize(); limit > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit+multipartOverhead)
+	}
+
+	content, err := readAvatar(c)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if stderrors.As(err, &tooLarge) {
+			response.Error(c, http.StatusRequestEntityTooLarge, "Avatar too large", err.Error())
+			return
+		}
+		response.BadRequest(c, "Invalid avatar upload", err.Error())
+		return
+	}
+
+	result, err := h.userUsecase.UploadAvatar(ctx, userID, content)
+	if err != nil {
+		h.logger.ErrorLogger(ctx, err, "Failed to upload avatar", map[string]interface{}{
+			"user_id": userID,
+		})
+		switch {
+		case errors.IsInvalidImage(err):
+			response.BadRequest(c, "Invalid avatar image", err.Error())
+		case errors.IsImageTooLarge(err):
+			response.Error(c, http.StatusRequestEntityTooLarge, "Avatar too large", err.Error())
+		case errors.IsUserNotFound(err):
+			response.Error(c, http.StatusNotFound, "Failed to upload avatar", err.Error())
+		case errors.IsConflict(err):
+			response.Error(c, http.StatusConflict, "Profile was changed by another request", err.Error())
+		case errors.IsProviderUnavailable(err):
+			response.Error(c, http.StatusServiceUnavailable, "Avatar uploads unavailable", err.Error())
+		default:
+			response.InternalServerError(c, "Failed to upload avatar", err.Error())
+		}
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id": userID,
+		"action":  "upload_avatar",
+	}).Info("Avatar uploaded")
+
+	response.Success(c, http.StatusOK, "Avatar uploaded successfully", result)
+}
+
+// readAvatar reads the "avatar" multipart file field.
+func readAvatar(c *gin.Context) ([]byte, error) {
+	fileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
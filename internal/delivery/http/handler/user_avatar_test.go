@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/usecase/user"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadAvatar_BodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userUsecase := user.NewUserUsecase(nil, logger.NewLogger())
+	userUsecase.SetAvatarStorage(nil, config.AvatarConfig{MaxUploadSize: 1 << 10})
+	h := NewUserHandler(userUsecase, logger.NewLogger(), nil)
+
+	upload := func(size int) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile("avatar", "avatar.png")
+		require.NoError(t, err)
+		part.Write(bytes.Repeat([]byte("a"), size))
+		require.NoError(t, writer.Close())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/user/avatar", &body)
+		c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+		c.Set("user_id", 7)
+		h.UploadAvatar(c)
+		return w
+	}
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, upload(multipartOverhead+2<<10).Code)
+	// Within the body limit the usecase rejects it: no storage is configured
+	assert.Equal(t, http.StatusServiceUnavailable, upload(100).Code)
+}
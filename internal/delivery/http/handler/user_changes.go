@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListUserChanges godoc
+// @Summary      List changes of a user
+// @Description  The change history of the user, newest first: which fields changed from and to what, when, by whom (changed_by, and impersonator_id when support staff acted as a user) and through what source (api, scim or system). Password values are never recorded, and values of erased users are scrubbed
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      int     true   "User ID"
+// @Param        field      query     string  false  "Only changes of this field, such as email"
+// @Param        page       query     int     false  "Page number"  default(1)
+// @Param        page_size  query     int     false  "Page size (max 100)"  default(20)
+// @Success      200  {object}  response.Response{data=[]entity.UserChange}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/users/{id}/changes [get]
+func (h *UserHandler) ListUserChanges(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", err.Error())
+		return
+	}
+
+	filter := entity.UserChangeFilter{UserID: userID, Field: c.Query("field")}
+	for param, target := range map[string]*int{
+		"page":      &filter.Page,
+		"page_size": &filter.PageSize,
+	} {
+		if value := c.Query(param); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				response.BadRequest(c, "Invalid "+param, err.Error())
+				return
+			}
+			*target = n
+		}
+	}
+
+	changes, total, err := h.userUsecase.ListChanges(ctx, &filter)
+	if err != nil {
+		if errors.IsUserNotFound(err) {
+			response.Error(c, http.StatusNotFound, "User not found", err.Error())
+			return
+		}
+		h.logger.ErrorLogger(ctx, err, "Failed to list user changes", map[string]interface{}{
+			"user_id": userID,
+		})
+		response.InternalServerError(c, "Failed to list user changes", err.Error())
+		return
+	}
+
+	response.SuccessWithPagination(c, http.StatusOK, "User changes retrieved successfully", changes,
+		response.NewPagination(filter.Page, filter.PageSize, total))
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfirmEmail godoc
+// @Summary      Confirm email address change
+// @Description  Change the email address of the account to the pending one, with the token of the link emailed to the new address. Needs no credentials, as the link may be opened on another device
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      entity.ConfirmEmailRequest  true  "Token of the confirmation link"
+// @Success      200      {object}  response.Response{data=entity.User}
+// @Failure      400      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/user/email/confirm [post]
+func (h *UserHandler) ConfirmEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.ConfirmEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	user, err := h.userUsecase.ConfirmEmail(ctx, &req)
+	if err != nil {
+		switch {
+		case errors.IsEmailChangeNotFound(err):
+			response.Error(c, http.StatusNotFound, "Failed to confirm email address", err.Error())
+		case errors.IsEmailAlreadyInUse(err), errors.IsConflict(err):
+			response.Error(c, http.StatusConflict, "Failed to confirm email address", err.Error())
+		default:
+			h.logger.ErrorLogger(ctx, err, "Failed to confirm email address", nil)
+			response.InternalServerError(c, "Failed to confirm email address", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Email address changed", user)
+}
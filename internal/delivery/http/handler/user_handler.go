@@ -3,7 +3,10 @@ package handler
 import (
 	"boilerplate-go/infrastructure/logger"
 	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
 	"boilerplate-go/internal/usecase/user"
+	"boilerplate-go/pkg/errors"
 	"boilerplate-go/pkg/response"
 	"net/http"
 
@@ -26,6 +29,53 @@ func NewUserHandler(userUsecase *user.UserUsecase, log *logger.Logger, m *metric
 	}
 }
 
+// Routes registers the profile routes and the admin user import routes
+func (h *UserHandler) Routes() []route.Group {
+	return []route.Group{
+		{
+			Prefix: "/api/v1/user",
+			Access: route.Public,
+			Register: func(r gin.IRoutes) {
+				r.POST("/email/confirm", h.ConfirmEmail)
+			},
+		},
+		{
+			Prefix: "/api/v1/user",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeProfileRead},
+			Register: func(r gin.IRoutes) {
+				r.GET("/profile", h.GetProfile)
+				r.POST("/export", h.ExportData)
+			},
+		},
+		{
+			Prefix: "/api/v1/user",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeProfileWrite},
+			Register: func(r gin.IRoutes) {
+				r.PATCH("/profile", h.UpdateProfile)
+				r.POST("/avatar", h.UploadAvatar)
+				r.POST("/delete", h.DeleteAccount)
+				r.POST("/phone", h.UpdatePhone)
+				r.POST("/phone/verify", h.VerifyPhone)
+				r.PUT("/password", h.ChangePassword)
+			},
+		},
+		{
+			Prefix: "/api/v1/admin",
+			Access: route.Authenticated,
+			Scopes: []string{entity.ScopeAdmin},
+			Dedup:  true,
+			Register: func(r gin.IRoutes) {
+				r.PUT("/users", h.ProvisionUser)
+				r.POST("/users/import", h.ImportUsers)
+				r.GET("/users/imports/:id", h.GetUserImport)
+				r.GET("/users/:id/changes", h.ListUserChanges)
+			},
+		},
+	}
+}
+
 // GetProfile godoc
 // @Summary      Get user profile
 // @Description  Retrieve the authenticated user's profile information
@@ -41,19 +91,8 @@ func NewUserHandler(userUsecase *user.UserUsecase, log *logger.Logger, m *metric
 func (h *UserHandler) GetProfile(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	userID, exists := c.Get("user_id")
-	if !exists {
-		h.logger.WithContext(ctx).Warn("User ID not found in context")
-		response.Unauthorized(c, "User not authenticated", "user_id not found in context")
-		return
-	}
-
-	userIDInt, ok := userID.(int)
+	userIDInt, ok := h.currentUserID(c)
 	if !ok {
-		h.logger.WithContext(ctx).WithFields(map[string]interface{}{
-			"user_id_type": userID,
-		}).Error("Invalid user ID type in context")
-		response.InternalServerError(c, "Invalid user ID format", "user_id type assertion failed")
 		return
 	}
 
@@ -81,3 +120,163 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 
 	response.Success(c, http.StatusOK, "Profile retrieved successfully", user)
 }
+
+// UpdateProfile godoc
+// @Summary      Update user profile
+// @Description  Update the authenticated user's name, avatar, locale, timezone and opt-out of new sign-in emails. Omitted fields are left unchanged; an empty string clears a field. A new email address becomes pending_email until confirmed with the link emailed to it, and the current address is notified; the current address cancels a pending change. With a version, the update fails with 409 unless the profile is still at it
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      entity.UpdateProfileRequest  true  "Profile fields"
+// @Success      200      {object}  response.Response{data=entity.User}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Failure      503      {object}  response.Response
+// @Router       /api/v1/user/profile [patch]
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req entity.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	user, err := h.userUsecase.UpdateProfile(ctx, userID, &req)
+	if err != nil {
+		h.logger.ErrorLogger(ctx, err, "Failed to update user profile", map[string]interface{}{
+			"user_id": userID,
+		})
+		switch {
+		case errors.IsInvalidProfile(err):
+			response.BadRequest(c, "Failed to update user profile", err.Error())
+		case errors.IsUserNotFound(err):
+			response.Error(c, http.StatusNotFound, "Failed to update user profile", err.Error())
+		case errors.IsConflict(err):
+			response.Error(c, http.StatusConflict, "Profile was changed by another request", err.Error())
+		case errors.IsEmailAlreadyInUse(err):
+			response.Error(c, http.StatusConflict, "Failed to update user profile", err.Error())
+		case errors.IsProviderUnavailable(err):
+			response.Error(c, http.StatusServiceUnavailable, "Failed to update user profile", err.Error())
+		default:
+			response.InternalServerError(c, "Failed to update user profile", err.Error())
+		}
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id": userID,
+		"action":  "update_profile",
+	}).Info("User profile updated")
+
+	response.Success(c, http.StatusOK, "Profile updated successfully", user)
+}
+
+// ExportData godoc
+// @Summary      Export personal data
+// @Description  Schedule an archive of all personal data held for the authenticated user: profile, orders, notifications, sessions, uploads, consents, change history and memberships. A download link is emailed once it is ready
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      202  {object}  response.Response{data=entity.DataExportResponse}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Failure      503  {object}  response.Response
+// @Router       /api/v1/user/export [post]
+func (h *UserHandler) ExportData(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id": userID,
+		"action":  "export_data",
+	}).Info("User data export requested")
+
+	result, err := h.userUsecase.RequestDataExport(ctx, userID)
+	if err != nil {
+		h.logger.ErrorLogger(ctx, err, "Failed to request user data export", map[string]interface{}{
+			"user_id": userID,
+		})
+		if errors.IsProviderUnavailable(err) {
+			response.Error(c, http.StatusServiceUnavailable, "Failed to export user data", err.Error())
+			return
+		}
+		response.InternalServerError(c, "Failed to export user data", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "User data export scheduled", result)
+}
+
+// DeleteAccount godoc
+// @Summary      Delete account
+// @Description  Schedule erasure of the authenticated user's personal data: unpaid orders are cancelled, sessions revoked and the account anonymized; financial records are kept
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      202  {object}  response.Response{data=entity.AccountDeletionResponse}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/user/delete [post]
+func (h *UserHandler) DeleteAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id": userID,
+		"action":  "delete_account",
+	}).Info("Account deletion requested")
+
+	result, err := h.userUsecase.RequestDeletion(ctx, userID)
+	if err != nil {
+		h.logger.ErrorLogger(ctx, err, "Failed to request account deletion", map[string]interface{}{
+			"user_id": userID,
+		})
+		response.InternalServerError(c, "Failed to delete account", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Account deletion scheduled", result)
+}
+
+// currentUserID extracts the authenticated user ID, writing an error response when absent.
+func (h *UserHandler) currentUserID(c *gin.Context) (int, bool) {
+	ctx := c.Request.Context()
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.WithContext(ctx).Warn("User ID not found in context")
+		response.Unauthorized(c, "User not authenticated", "user_id not found in context")
+		return 0, false
+	}
+
+	userIDInt, ok := userID.(int)
+	if !ok {
+		h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+			"user_id_type": userID,
+		}).Error("Invalid user ID type in context")
+		response.InternalServerError(c, "Invalid user ID format", "user_id type assertion failed")
+		return 0, false
+	}
+
+	return userIDInt, true
+}
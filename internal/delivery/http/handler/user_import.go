@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/user"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize caps the size of an uploaded import file
+const maxImportFileSize = 20 << 20
+
+// ImportUsers godoc
+// @Summary      Bulk import users
+// @Description  Create users from a CSV (username,email,password header) or JSON array, sent as the request body or as a multipart "file" field. Files over 50 rows are imported in the background; poll the returned import ID for the report.
+// @Tags         admin
+// @Accept       json,text/csv,mpfd
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file  formData  file  false  "CSV or JSON import file"
+// @Success      200  {object}  response.Response{data=entity.UserImportReport}
+// @Success      202  {object}  response.Response{data=entity.UserImportReport}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/users/import [post]
+func (h *UserHandler) ImportUsers(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	rows, err := parseUserImport(c)
+	if err != nil {
+		response.BadRequest(c, "Invalid import file", err.Error())
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"rows":   len(rows),
+		"action": "import_users",
+	}).Info("User import requested")
+
+	report, err := h.userUsecase.ImportUsers(ctx, rows)
+	if err != nil {
+		if errors.IsInvalidImport(err) {
+			response.BadRequest(c, "Invalid import file", err.Error())
+			return
+		}
+		h.logger.ErrorLogger(ctx, err, "Failed to import users", map[string]interface{}{
+			"rows": len(rows),
+		})
+		response.InternalServerError(c, "Failed to import users", err.Error())
+		return
+	}
+
+	if report.Status == user.ImportStatusProcessing {
+		response.Success(c, http.StatusAccepted, "User import scheduled", report)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "User import completed", report)
+}
+
+// GetUserImport godoc
+// @Summary      Get a bulk user import
+// @Description  Get the status and per-row report of a background user import
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Import ID"
+// @Success      200  {object}  response.Response{data=entity.UserImportReport}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/admin/users/imports/{id} [get]
+func (h *UserHandler) GetUserImport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	importID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid import ID", err.Error())
+		return
+	}
+
+	report, err := h.userUsecase.GetImport(ctx, importID)
+	if err != nil {
+		if errors.IsImportNotFound(err) {
+			response.Error(c, http.StatusNotFound, "User import not found", err.Error())
+			return
+		}
+		h.logger.ErrorLogger(ctx, err, "Failed to get user import", map[string]interface{}{
+			"import_id": importID,
+		})
+		response.InternalServerError(c, "Failed to get user import", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "User import retrieved successfully", report)
+}
+
+// parseUserImport reads import rows from a multipart file or the raw request body.
+func parseUserImport(c *gin.Context) ([]entity.UserImportRow, error) {
+	var (
+		reader io.Reader
+		isCSV  bool
+	)
+
+	// Bound the whole body before multipart parsing spools it to disk
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+
+	contentType := c.ContentType()
+	if strings.HasPrefix(contentType, "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("missing file field: %w", err)
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		reader = file
+		isCSV = strings.EqualFold(filepath.Ext(fileHeader.Filename), ".csv")
+	} else {
+		reader = c.Request.Body
+		isCSV = contentType == "text/csv"
+	}
+
+	if isCSV {
+		return parseUserImportCSV(reader)
+	}
+
+	var rows []entity.UserImportRow
+	if err := json.NewDecoder(reader).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return rows, nil
+}
+
+// parseUserImportCSV reads rows from CSV, locating columns by the header row.
+func parseUserImportCSV(r io.Reader) ([]entity.UserImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"username", "email", "password"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing CSV column %q", required)
+		}
+	}
+
+	var rows []entity.UserImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		rows = append(rows, entity.UserImportRow{
+			Username: record[columns["username"]],
+			Email:    record[columns["email"]],
+			Password: record[columns["password"]],
+		})
+	}
+	return rows, nil
+}
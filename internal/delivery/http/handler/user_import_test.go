@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"boilerplate-go/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newImportContext(body *bytes.Buffer, contentType string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/users/import", body)
+	c.Request.Header.Set("Content-Type", contentType)
+	return c
+}
+
+func TestParseUserImportCSV(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expected      []entity.UserImportRow
+		expectedError string
+	}{
+		{
+			name:  "columns located by header",
+			input: "Email, password ,USERNAME\nalice@example.com,secret1,alice\nbob@example.com,secret2,bob\n",
+			expected: []entity.UserImportRow{
+				{Username: "alice", Email: "alice@example.com", Password: "secret1"},
+				{Username: "bob", Email: "bob@example.com", Password: "secret2"},
+			},
+		},
+		{
+			name:     "header only",
+			input:    "username,email,password\n",
+			expected: nil,
+		},
+		{
+			name:          "missing column",
+			input:         "username,email\nalice,alice@example.com\n",
+			expectedError: `missing CSV column "password"`,
+		},
+		{
+			name:          "ragged row",
+			input:         "username,email,password\nalice,alice@example.com\n",
+			expectedError: "invalid CSV",
+		},
+		{
+			name:          "empty file",
+			input:         "",
+			expectedError: "failed to read CSV header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseUserImportCSV(strings.NewReader(tt.input))
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, rows)
+		})
+	}
+}
+
+func TestParseUserImport_Body(t *testing.T) {
+	c := newImportContext(bytes.NewBufferString(`[{"username":"alice","email":"alice@example.com","password":"secret1"}]`), "application/json")
+	rows, err := parseUserImport(c)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.UserImportRow{{Username: "alice", Email: "alice@example.com", Password: "secret1"}}, rows)
+
+	c = newImportContext(bytes.NewBufferString("username,email,password\nbob,bob@example.com,secret2\n"), "text/csv")
+	rows, err = parseUserImport(c)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.UserImportRow{{Username: "bob", Email: "bob@example.com", Password: "secret2"}}, rows)
+
+	c = newImportContext(bytes.NewBufferString(`{"username":"alice"}`), "application/json")
+	_, err = parseUserImport(c)
+	assert.ErrorContains(t, err, "invalid JSON")
+}
+
+func TestParseUserImport_Multipart(t *testing.T) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "users.CSV")
+	assert.NoError(t, err)
+	_, _ = part.Write([]byte("username,email,password\nalice,alice@example.com,secret1\n"))
+	assert.NoError(t, writer.Close())
+
+	c := newImportContext(body, writer.FormDataContentType())
+	rows, err := parseUserImport(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.UserImportRow{{Username: "alice", Email: "alice@example.com", Password: "secret1"}}, rows)
+}
+
+func TestParseUserImport_BodyTooLarge(t *testing.T) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "users.csv")
+	assert.NoError(t, err)
+	_, _ = part.Write(bytes.Repeat([]byte("a"), maxImportFileSize+1))
+	assert.NoError(t, writer.Close())
+
+	c := newImportContext(body, writer.FormDataContentType())
+	_, err = parseUserImport(c)
+
+	assert.Error(t, err)
+}
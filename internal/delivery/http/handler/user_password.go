@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/password"
+	"boilerplate-go/pkg/response"
+	"boilerplate-go/pkg/username"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChangePassword godoc
+// @Summary      Change password
+// @Description  Replace the authenticated user's password after checking the current one. The new password must meet the password policy; violations are listed per rule in errors. Every other session is logged out
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      entity.ChangePasswordRequest  true  "Current and new password"
+// @Success      200      {object}  response.Response
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/user/password [put]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req entity.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	if err := h.userUsecase.ChangePassword(ctx, userID, c.GetString("token_id"), &req); err != nil {
+		if weakPassword(c, "Failed to change password", "new_password", err) {
+			return
+		}
+		if errors.IsInvalidCredentials(err) {
+			// Not 401, which clients take as an expired session
+			response.Error(c, http.StatusForbidden, "Current password is incorrect", err.Error())
+			return
+		}
+		if errors.IsConflict(err) {
+			response.Error(c, http.StatusConflict, "Profile was changed by another request", err.Error())
+			return
+		}
+		h.logger.ErrorLogger(ctx, err, "Failed to change password", map[string]interface{}{
+			"user_id": userID,
+		})
+		response.InternalServerError(c, "Failed to change password", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Password changed successfully", nil)
+}
+
+// weakPassword writes a 400 response listing the password policy violations
+// of err against field, and reports whether err was a policy violation.
+func weakPassword(c *gin.Context, message, field string, err error) bool {
+	var policyErr *password.PolicyError
+	if !stderrors.As(err, &policyErr) {
+		return false
+	}
+
+	fields := make([]response.FieldError, len(policyErr.Violations))
+	for i, v := range policyErr.Violations {
+		fields[i] = response.FieldError{
+			Field:   field,
+			Code:    v.Code,
+			Message: response.Translatef(c, v.Message, v.Args...),
+		}
+	}
+	response.ValidationFailed(c, message, fields)
+	return true
+}
+
+// invalidUsername responds with the username policy violations of err, one
+// field error each, and reports whether err was a policy error
+func invalidUsername(c *gin.Context, message string, err error) bool {
+	var policyErr *username.PolicyError
+	if !stderrors.As(err, &policyErr) {
+		return false
+	}
+
+	fields := make([]response.FieldError, len(policyErr.Violations))
+	for i, v := range policyErr.Violations {
+		fields[i] = response.FieldError{
+			Field:   "username",
+			Code:    v.Code,
+			Message: response.Translatef(c, v.Message, v.Args...),
+		}
+	}
+	response.ValidationFailed(c, message, fields)
+	return true
+}
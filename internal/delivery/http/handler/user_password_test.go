@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate-go/pkg/password"
+	"boilerplate-go/pkg/response"
+	"boilerplate-go/pkg/username"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeakPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policy := password.DefaultPolicy()
+	policy.RequireDigit = true
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", nil)
+	err := fmt.Errorf("register: %w", policy.Check("qwerty", "janedoe"))
+
+	require.True(t, weakPassword(c, "Registration failed", "password", err))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var body response.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "Registration failed", body.Message)
+	assert.Equal(t, []response.FieldError{
+		{Field: "password", Code: password.CodeTooShort, Message: "password must be at least 8 characters"},
+		{Field: "password", Code: password.CodeMissingDigit, Message: "password must contain a digit"},
+		{Field: "password", Code: password.CodeCommon, Message: "password is too common"},
+	}, body.Errors)
+
+	assert.False(t, weakPassword(c, "Registration failed", "password", errors.New("database down")))
+}
+
+func TestInvalidUsername(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", nil)
+	err := fmt.Errorf("register: %w", username.DefaultPolicy().Check("ad"))
+
+	require.True(t, invalidUsername(c, "Registration failed", err))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var body response.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []response.FieldError{
+		{Field: "username", Code: username.CodeTooShort, Message: "username must be at least 3 characters"},
+	}, body.Errors)
+
+	assert.False(t, invalidUsername(c, "Registration failed", errors.New("database down")))
+}
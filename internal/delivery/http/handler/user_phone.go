@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdatePhone godoc
+// @Summary      Set phone number
+// @Description  Set the authenticated user's phone number and text it a verification code. The number can be used to log in once verified
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      entity.PhoneRequest  true  "Phone number in E.164 format"
+// @Success      202      {object}  response.Response
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      429      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/user/phone [post]
+func (h *UserHandler) UpdatePhone(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req entity.PhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	if err := h.userUsecase.RequestPhoneVerification(ctx, userID, &req); err != nil {
+		h.logger.ErrorLogger(ctx, err, "Failed to update phone number", map[string]interface{}{
+			"user_id": userID,
+		})
+		response.Error(c, otpErrorStatus(err), "Failed to update phone number", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Verification code sent", nil)
+}
+
+// VerifyPhone godoc
+// @Summary      Verify phone number
+// @Description  Confirm the authenticated user's phone number with the code sent to it
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      entity.PhoneVerifyRequest  true  "Verification code"
+// @Success      200      {object}  response.Response{data=entity.User}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      429      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/user/phone/verify [post]
+func (h *UserHandler) VerifyPhone(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req entity.PhoneVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	user, err := h.userUsecase.VerifyPhone(ctx, userID, &req)
+	if err != nil {
+		h.logger.ErrorLogger(ctx, err, "Failed to verify phone number", map[string]interface{}{
+			"user_id": userID,
+		})
+		response.Error(c, otpErrorStatus(err), "Failed to verify phone number", err.Error())
+		return
+	}
+
+	h.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id": userID,
+		"action":  "phone_verified",
+	}).Info("Phone number verified")
+
+	response.Success(c, http.StatusOK, "Phone number verified", user)
+}
+
+// otpErrorStatus maps phone and one-time code errors to an HTTP status.
+func otpErrorStatus(err error) int {
+	switch {
+	case errors.IsInvalidPhone(err), errors.IsPhoneNotSet(err):
+		return http.StatusBadRequest
+	case errors.IsInvalidOTP(err):
+		return http.StatusUnauthorized
+	case errors.IsUserNotFound(err):
+		return http.StatusNotFound
+	case errors.IsPhoneAlreadyInUse(err):
+		return http.StatusConflict
+	case errors.IsUserDeactivated(err):
+		return http.StatusForbidden
+	case errors.IsOTPRateLimited(err):
+		return http.StatusTooManyRequests
+	case errors.IsProviderUnavailable(err):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
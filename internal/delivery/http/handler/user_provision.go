@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProvisionUser godoc
+// @Summary      Provision a user
+// @Description  Create the user with the email address, or update its username and names, so retrying or syncing from an identity provider is safe. The user's external_id is the name-based UUID (version 5, URL namespace) of "mailto:" followed by the lowercased email. The password is only set on new users and must meet the password policy; users provisioned without one cannot sign in with a password
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      entity.ProvisionUserRequest  true  "User to provision"
+// @Success      200      {object}  response.Response{data=entity.User}
+// @Success      201      {object}  response.Response{data=entity.User}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /api/v1/admin/users [put]
+func (h *UserHandler) ProvisionUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.ProvisionUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	user, created, err := h.userUsecase.Provision(ctx, &req)
+	if err != nil {
+		switch {
+		case weakPassword(c, "Failed to provision user", "password", err):
+		case stderrors.Is(err, errors.ErrUserAlreadyExists):
+			response.Error(c, http.StatusConflict, "Username is taken by another user", err.Error())
+		default:
+			h.logger.ErrorLogger(ctx, err, "Failed to provision user", map[string]interface{}{
+				"email": req.Email,
+			})
+			response.InternalServerError(c, "Failed to provision user", err.Error())
+		}
+		return
+	}
+
+	if created {
+		response.Success(c, http.StatusCreated, "User created successfully", user)
+		return
+	}
+	response.Success(c, http.StatusOK, "User updated successfully", user)
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessLogMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		format   string
+		target   string
+		header   http.Header
+		expected string
+	}{
+		{
+			name:     "combined",
+			format:   AccessLogCombined,
+			target:   "/api/v1/orders?page=2",
+			header:   http.Header{"Referer": {"https://example.com/"}, "User-Agent": {`curl/8.0 "quoted"`}},
+			expected: `^192\.0\.2\.1 - 7 \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /api/v1/orders\?page=2 HTTP/1\.1" 200 2 "https://example\.com/" "curl/8\.0 \\"quoted\\""` + "\n$",
+		},
+		{
+			name:     "common without user or body",
+			format:   AccessLogCommon,
+			target:   "/missing",
+			expected: `^192\.0\.2\.1 - - \[[^\]]+\] "GET /missing HTTP/1\.1" 404 -` + "\n$",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			r := gin.New()
+			r.Use(AccessLogMiddleware(&buf, tt.format))
+			r.GET("/api/v1/orders", func(c *gin.Context) {
+				c.Set("user_id", 7)
+				c.String(http.StatusOK, "ok")
+			})
+			r.NoRoute(func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Regexp(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestEscapeLogField(t *testing.T) {
+	assert.Equal(t, `GET /a\"b\\c\x0a\xc3\xa9`, escapeLogField("GET /a\"b\\c\né"))
+}
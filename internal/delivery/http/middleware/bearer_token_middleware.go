@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BearerTokenMiddleware admits requests carrying the static bearer token, such
+// as the one identity providers provision users with. Tokens are compared in
+// constant time. Changes made by the requests are attributed to source.
+func BearerTokenMiddleware(token, source string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			response.Unauthorized(c, "Invalid token", "expected the provisioning bearer token")
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(entity.ContextWithActor(c.Request.Context(), &entity.Actor{Source: source}))
+		c.Next()
+	}
+}
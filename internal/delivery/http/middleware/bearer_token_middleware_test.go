@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate-go/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBearerTokenMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/scim", BearerTokenMiddleware("secret", entity.ChangeSourceSCIM), func(c *gin.Context) {
+		// Changes are attributed to the identity provider
+		assert.Equal(t, entity.ChangeSourceSCIM, entity.ActorFromContext(c.Request.Context()).Source)
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"valid token", "Bearer secret", http.StatusOK},
+		{"wrong token", "Bearer other", http.StatusUnauthorized},
+		{"prefix of the token", "Bearer secr", http.StatusUnauthorized},
+		{"missing header", "", http.StatusUnauthorized},
+		{"basic auth", "Basic secret", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/scim", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/netguard"
+	"boilerplate-go/pkg/useragent"
+
+	"github.com/gin-gonic/gin"
+)
+
+// failedLocationTTL is how long an address is not looked up again after a
+// failed lookup, so an unavailable provider does not slow down every request.
+const failedLocationTTL = time.Minute
+
+// Locator resolves the location of an IP address, such as the geolocation provider.
+type Locator interface {
+	GetLocationByIP(ctx context.Context, ipAddress string) (*entity.LocationInfo, error)
+}
+
+// ClientContextConfig holds the client context configuration. Locations are
+// resolved by Locator within Timeout and cached per address for CacheTTL, up
+// to CacheSize addresses. A nil Locator skips geolocation.
+type ClientContextConfig struct {
+	Locator   Locator
+	Timeout   time.Duration
+	CacheTTL  time.Duration
+	CacheSize int
+}
+
+type cachedLocation struct {
+	location  *entity.LocationInfo
+	expiresAt time.Time
+}
+
+// locationCache remembers the location of recent client addresses
+type locationCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedLocation
+	size    int
+}
+
+func (l *locationCache) get(ip string, now time.Time) (*entity.LocationInfo, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cached, ok := l.entries[ip]
+	if !ok || now.After(cached.expiresAt) {
+		return nil, false
+	}
+	return cached.location, true
+}
+
+func (l *locationCache) put(ip string, location *entity.LocationInfo, expiresAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Make room by dropping an arbitrary address, which is cheaper than
+	// tracking recency and good enough for a cache of lookups
+	if _, exists := l.entries[ip]; !exists && len(l.entries) >= l.size {
+		for key := range l.entries {
+			delete(l.entries, key)
+			break
+		}
+	}
+	l.entries[ip] = cachedLocation{location: location, expiresAt: expiresAt}
+}
+
+// ClientContextMiddleware attaches the client of each request to its context
+// as an entity.ClientInfo: the client IP, the device, OS and browser parsed
+// from its User-Agent and, with a Locator, its location. Use cases such as
+// fraud screening read it with entity.ClientInfoFromContext, and log entries
+// written with the request context carry the client fields. Private and
+// loopback addresses are not geolocated.
+func ClientContextMiddleware(config ClientContextConfig, log *logger.Logger) gin.HandlerFunc {
+	cache := &locationCache{entries: make(map[string]cachedLocation), size: config.CacheSize}
+
+	locate := func(ctx context.Context, ip string) *entity.LocationInfo {
+		if config.Locator == nil || !netguard.Public(net.ParseIP(ip)) {
+			return nil
+		}
+
+		now := time.Now()
+		if location, ok := cache.get(ip, now); ok {
+			return location
+		}
+
+		lookupCtx, cancel := context.WithTimeout(ctx, config.Timeout)
+		location, err := config.Locator.GetLocationByIP(lookupCtx, ip)
+		cancel()
+		if err != nil {
+			log.WithContext(ctx).WithError(err).Debug("Failed to resolve client location")
+			cache.put(ip, nil, now.Add(failedLocationTTL))
+			return nil
+		}
+		cache.put(ip, location, now.Add(config.CacheTTL))
+		return location
+	}
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		agent := useragent.Parse(c.Request.UserAgent())
+		info := &entity.ClientInfo{
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			Device:    agent.Device,
+			OS:        agent.OS,
+			Browser:   agent.Browser,
+		}
+		info.Location = locate(ctx, info.IPAddress)
+
+		fields := map[string]interface{}{
+			"client_ip": info.IPAddress,
+			"device":    info.Device,
+		}
+		if info.OS != "" {
+			fields["os"] = info.OS
+		}
+		if info.Browser != "" {
+			fields["browser"] = info.Browser
+		}
+		if info.Location != nil && info.Location.CountryCode != "" {
+			fields["country_code"] = info.Location.CountryCode
+		}
+
+		ctx = entity.ContextWithClientInfo(ctx, info)
+		ctx = logger.ContextWithFields(ctx, fields)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
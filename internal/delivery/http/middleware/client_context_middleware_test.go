@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingLocator places every address in one country, or fails with err.
+type countingLocator struct {
+	calls int
+	err   error
+}
+
+func (l *countingLocator) GetLocationByIP(ctx context.Context, ipAddress string) (*entity.LocationInfo, error) {
+	l.calls++
+	if l.err != nil {
+		return nil, l.err
+	}
+	return &entity.LocationInfo{IP: ipAddress, CountryCode: "NL"}, nil
+}
+
+// newClientContextRouter records the client info of the last request.
+func newClientContextRouter(locator Locator, last **entity.ClientInfo) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ClientContextMiddleware(ClientContextConfig{
+		Locator:   locator,
+		Timeout:   time.Second,
+		CacheTTL:  time.Hour,
+		CacheSize: 10,
+	}, logger.NewLogger()))
+	r.GET("/", func(c *gin.Context) {
+		*last = entity.ClientInfoFromContext(c.Request.Context())
+	})
+	return r
+}
+
+func requestFrom(r *gin.Engine, remoteAddr, userAgent string) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("User-Agent", userAgent)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestClientContextMiddleware(t *testing.T) {
+	locator := &countingLocator{}
+	var info *entity.ClientInfo
+	r := newClientContextRouter(locator, &info)
+
+	userAgent := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+	requestFrom(r, "203.0.113.7:5000", userAgent)
+
+	require.NotNil(t, info)
+	assert.Equal(t, "203.0.113.7", info.IPAddress)
+	assert.Equal(t, userAgent, info.UserAgent)
+	assert.Equal(t, "mobile", info.Device)
+	assert.Equal(t, "iOS", info.OS)
+	assert.Equal(t, "Safari", info.Browser)
+	require.NotNil(t, info.Location)
+	assert.Equal(t, "NL", info.Location.CountryCode)
+
+	// Locations are cached per address
+	requestFrom(r, "203.0.113.7:5001", userAgent)
+	assert.Equal(t, 1, locator.calls)
+	assert.NotNil(t, info.Location)
+
+	// Private addresses are not looked up
+	requestFrom(r, "10.0.0.3:5000", userAgent)
+	assert.Equal(t, 1, locator.calls)
+	assert.Nil(t, info.Location)
+}
+
+func TestClientContextMiddleware_LookupFailure(t *testing.T) {
+	locator := &countingLocator{err: errors.New("unavailable")}
+	var info *entity.ClientInfo
+	r := newClientContextRouter(locator, &info)
+
+	requestFrom(r, "203.0.113.7:5000", "curl/8.5.0")
+	require.NotNil(t, info)
+	assert.Equal(t, "bot", info.Device)
+	assert.Nil(t, info.Location)
+
+	// Failures are remembered so an unavailable provider is not retried on every request
+	requestFrom(r, "203.0.113.7:5000", "curl/8.5.0")
+	assert.Equal(t, 1, locator.calls)
+}
+
+func TestClientContextMiddleware_WithoutLocator(t *testing.T) {
+	var info *entity.ClientInfo
+	r := newClientContextRouter(nil, &info)
+
+	requestFrom(r, "203.0.113.7:5000", "")
+	require.NotNil(t, info)
+	assert.Equal(t, "unknown", info.Device)
+	assert.Nil(t, info.Location)
+}
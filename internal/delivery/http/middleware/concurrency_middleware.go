@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"boilerplate-go/infrastructure/ratelimit"
+	"boilerplate-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultInFlightTTL is how long a request in flight holds its slot when no
+// TTL is given
+const defaultInFlightTTL = time.Minute
+
+// InFlightLimiter keeps slots for requests in flight by key, such as in
+// memory or shared by the instances in Redis. Acquire takes one of limit
+// slots of key, held for at most ttl, and returns whether there was one and
+// the func releasing it.
+type InFlightLimiter interface {
+	Acquire(ctx context.Context, key string, limit int, ttl time.Duration) (func(), bool)
+}
+
+// ConcurrencyLimit rejects a user's requests to a route with 409 while limit
+// of them are already in flight, such as a second order placed before the
+// first one completed. Slots are held for at most ttl, in case an instance
+// dies with them; zero holds them a minute. A nil limiter keeps them in
+// memory per instance. It must run after AuthenticationMiddleware, and after
+// DedupMiddleware so identical retries are replayed instead of rejected.
+func ConcurrencyLimit(limiter InFlightLimiter, limit int, ttl time.Duration) gin.HandlerFunc {
+	if limiter == nil {
+		limiter = ratelimit.NewLocal()
+	}
+	if ttl <= 0 {
+		ttl = defaultInFlightTTL
+	}
+
+	return func(c *gin.Context) {
+		key := fmt.Sprintf("inflight:%d:%s %s", c.GetInt("user_id"), c.Request.Method, c.FullPath())
+		release, ok := limiter.Acquire(c.Request.Context(), key, limit, ttl)
+		if !ok {
+			c.Header("Retry-After", "1")
+			response.Error(c, http.StatusConflict, "Request in progress", "another request of the user to this endpoint is in progress")
+			c.Abort()
+			return
+		}
+		defer release()
+		c.Next()
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"boilerplate-go/infrastructure/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	started, finish := make(chan struct{}), make(chan struct{})
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		userID, _ := strconv.Atoi(c.GetHeader("X-User"))
+		c.Set("user_id", userID)
+	})
+	r.POST("/orders", ConcurrencyLimit(ratelimit.NewLocal(), 1, 0), func(c *gin.Context) {
+		if c.GetHeader("X-Slow") != "" {
+			close(started)
+			<-finish
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	post := func(user string, slow bool) int {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.Header.Set("X-User", user)
+		if slow {
+			req.Header.Set("X-Slow", "1")
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var first int
+	go func() {
+		defer wg.Done()
+		first = post("1", true)
+	}()
+	<-started
+
+	assert.Equal(t, http.StatusConflict, post("1", false), "the user's first order is in flight")
+	assert.Equal(t, http.StatusCreated, post("2", false), "other users are not held up")
+	close(finish)
+	wg.Wait()
+	assert.Equal(t, http.StatusCreated, first)
+	assert.Equal(t, http.StatusCreated, post("1", false), "the slot is released once the first completes")
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConsentChecker returns the current legal documents a user has yet to accept
+type ConsentChecker interface {
+	PendingDocuments(ctx context.Context, userID int) ([]entity.LegalDocument, error)
+}
+
+// RequireConsent rejects the requests changing data of users who have yet to
+// accept the current versions of the legal documents, naming them. Reads are
+// let through, so users can still see their data. It must run after
+// AuthenticationMiddleware.
+func RequireConsent(checker ConsentChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		pending, err := checker.PendingDocuments(c.Request.Context(), c.GetInt("user_id"))
+		if err != nil {
+			response.InternalServerError(c, "Failed to check consent", err.Error())
+			c.Abort()
+			return
+		}
+		if len(pending) > 0 {
+			documents := make([]string, len(pending))
+			for i, document := range pending {
+				documents[i] = document.Document + " " + document.Version
+			}
+			response.Forbidden(c, "Consent required", fmt.Sprintf("%s: %s", errors.ErrConsentRequired, strings.Join(documents, ", ")))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate-go/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// pendingDocuments are the documents every user has yet to accept
+type pendingDocuments []entity.LegalDocument
+
+func (p pendingDocuments) PendingDocuments(ctx context.Context, userID int) ([]entity.LegalDocument, error) {
+	return p, nil
+}
+
+func newConsentRouter(checker ConsentChecker) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", 1) }, RequireConsent(checker))
+	r.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/orders", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	return r
+}
+
+func TestRequireConsent(t *testing.T) {
+	pending := pendingDocuments{{Document: entity.DocumentTerms, Version: "2026-01"}}
+
+	tests := []struct {
+		name    string
+		checker ConsentChecker
+		method  string
+		status  int
+	}{
+		{"pending blocks changes", pending, http.MethodPost, http.StatusForbidden},
+		{"pending allows reads", pending, http.MethodGet, http.StatusOK},
+		{"accepted", pendingDocuments{}, http.MethodPost, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newConsentRouter(tt.checker).ServeHTTP(w, httptest.NewRequest(tt.method, "/orders", nil))
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusForbidden {
+				assert.Contains(t, w.Body.String(), "terms_of_service 2026-01")
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/jwt"
+	"boilerplate-go/pkg/response"
+	"boilerplate-go/pkg/securecookie"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthCookieConfig holds the cookies browser clients authenticate with.
+// Name holds the encrypted JWT and is HttpOnly; CSRFName holds the CSRF
+// token scripts read and echo in the X-CSRF-Token header.
+type AuthCookieConfig struct {
+	Name     string
+	CSRFName string
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+	// OmitToken leaves the JWT out of sign-in responses, so scripts never see it
+	OmitToken bool
+}
+
+// AuthCookies sets and reads the authentication cookies of browser clients
+type AuthCookies struct {
+	config AuthCookieConfig
+	codec  *securecookie.Codec
+	keys   *jwt.KeySet
+}
+
+// NewAuthCookies creates the authentication cookies sealed with the codec
+func NewAuthCookies(config AuthCookieConfig, codec *securecookie.Codec, keys *jwt.KeySet) *AuthCookies {
+	return &AuthCookies{config: config, codec: codec, keys: keys}
+}
+
+// SignIn sets the session and CSRF cookies of the token, both expiring with
+// it, and leaves the token out of the response when configured to
+func (a *AuthCookies) SignIn(c *gin.Context, login *entity.LoginResponse) error {
+	claims, err := a.keys.ValidateToken(login.Token)
+	if err != nil {
+		return err
+	}
+	sealed, err := a.codec.Seal(a.config.Name, login.Token)
+	if err != nil {
+		return err
+	}
+
+	// Tokens without an expiry get cookies lasting the browser session
+	maxAge := 0
+	if claims.ExpiresAt != nil {
+		maxAge = int(time.Until(claims.ExpiresAt.Time).Seconds())
+	}
+	csrf, err := a.codec.CSRFToken(claims.ID)
+	if err != nil {
+		return err
+	}
+	a.set(c, a.config.Name, sealed, maxAge, true)
+	a.set(c, a.config.CSRFName, csrf, maxAge, false)
+
+	if a.config.OmitToken {
+		login.Token = ""
+	}
+	return nil
+}
+
+// Clear expires the session and CSRF cookies
+func (a *AuthCookies) Clear(c *gin.Context) {
+	a.set(c, a.config.Name, "", -1, true)
+	a.set(c, a.config.CSRFName, "", -1, false)
+}
+
+func (a *AuthCookies) set(c *gin.Context, name, value string, maxAge int, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   a.config.Domain,
+		MaxAge:   maxAge,
+		Secure:   a.config.Secure,
+		HttpOnly: httpOnly,
+		SameSite: a.config.SameSite,
+	})
+}
+
+// token returns the JWT of the session cookie, or "" when there is none
+func (a *AuthCookies) token(c *gin.Context) (string, error) {
+	sealed, err := c.Cookie(a.config.Name)
+	if err != nil || sealed == "" {
+		return "", nil
+	}
+	return a.codec.Open(a.config.Name, sealed)
+}
+
+// sessionID returns the ID of the session of a valid session cookie, or ""
+func (a *AuthCookies) sessionID(c *gin.Context) string {
+	token, err := a.token(c)
+	if err != nil || token == "" {
+		return ""
+	}
+	claims, err := a.keys.ValidateToken(token)
+	if err != nil {
+		return ""
+	}
+	return claims.ID
+}
+
+// CookieAuthenticationMiddleware validates JWT tokens sent in the
+// Authorization header or, failing that, in the session cookie. Requests
+// authenticated with the cookie need CSRFMiddleware as well.
+func CookieAuthenticationMiddleware(keys *jwt.KeySet, sessions SessionValidator, cookies *AuthCookies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			token, ok := bearerToken(c)
+			if !ok {
+				return
+			}
+			authenticate(c, keys, sessions, token)
+			return
+		}
+
+		token, err := cookies.token(c)
+		if err != nil {
+			response.Unauthorized(c, "Invalid token", err.Error())
+			c.Abort()
+			return
+		}
+		if token == "" {
+			response.Unauthorized(c, "Authentication required", "missing authorization header or session cookie")
+			c.Abort()
+			return
+		}
+		authenticate(c, keys, sessions, token)
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/jwt"
+	"boilerplate-go/pkg/securecookie"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieAuthenticationMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := jwt.NewHMACKeySet("test-secret")
+	codec, err := securecookie.New("0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+	cookies := NewAuthCookies(AuthCookieConfig{
+		Name:      "session",
+		CSRFName:  "csrf_token",
+		Secure:    true,
+		SameSite:  http.SameSiteLaxMode,
+		OmitToken: true,
+	}, codec, keys)
+
+	r := gin.New()
+	r.POST("/login", func(c *gin.Context) {
+		token, err := keys.Sign(&jwt.Claims{UserID: 1, Username: "alice"}, time.Hour)
+		require.NoError(t, err)
+		login := &entity.LoginResponse{Token: token}
+		require.NoError(t, cookies.SignIn(c, login))
+		c.JSON(http.StatusOK, login)
+	})
+	protected := r.Group("/", CookieAuthenticationMiddleware(keys, activeSessions{}, cookies))
+	protected.GET("/profile", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	protected.POST("/orders", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user":null}`, w.Body.String())
+
+	set := map[string]*http.Cookie{}
+	for _, cookie := range w.Result().Cookies() {
+		set[cookie.Name] = cookie
+	}
+	require.Contains(t, set, "session")
+	require.Contains(t, set, "csrf_token")
+	assert.True(t, set["session"].HttpOnly)
+	assert.True(t, set["session"].Secure)
+	assert.False(t, set["csrf_token"].HttpOnly)
+	assert.InDelta(t, time.Hour.Seconds(), set["session"].MaxAge, 5)
+
+	send := func(method, path string, session *http.Cookie) int {
+		req := httptest.NewRequest(method, path, nil)
+		if session != nil {
+			req.AddCookie(session)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("session cookie authenticates", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, send(http.MethodGet, "/profile", set["session"]))
+		assert.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/profile", nil))
+	})
+
+	t.Run("tampered cookie is rejected", func(t *testing.T) {
+		tampered := *set["session"]
+		first := "x"
+		if tampered.Value[:1] == first {
+			first = "y"
+		}
+		tampered.Value = first + tampered.Value[1:]
+		assert.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/profile", &tampered))
+	})
+
+	t.Run("bearer tokens are accepted", func(t *testing.T) {
+		token, err := keys.Sign(&jwt.Claims{UserID: 2}, time.Hour)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
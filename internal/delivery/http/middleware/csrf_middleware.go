@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"boilerplate-go/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSRFHeader carries the CSRF token of cookie-authenticated requests
+const CSRFHeader = "X-CSRF-Token"
+
+// CSRFTokenKey holds the CSRF token of the request in the gin context
+const CSRFTokenKey = "csrf_token"
+
+// CSRFMiddleware protects browsers holding the authentication cookies from
+// requests forged by other sites, which can make browsers send cookies but
+// cannot read them. State-changing requests must echo the CSRF cookie in the
+// X-CSRF-Token header, and the token must be bound to the session of the
+// session cookie, or to none without one. Safe requests without a valid
+// token are issued one.
+//
+// Requests with an Authorization header are exempt, since browsers never add
+// it on their own, and so are requests without cookies or an Origin header,
+// which browsers send with every state-changing request: such clients are not
+// browsers. Routes authenticated otherwise, such as webhooks, are mounted
+// without it.
+func CSRFMiddleware(cookies *AuthCookies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			c.Next()
+			return
+		}
+
+		sessionID := cookies.sessionID(c)
+		token, _ := c.Cookie(cookies.config.CSRFName)
+		valid := token != "" && cookies.codec.ValidCSRFToken(sessionID, token)
+
+		if !isSafeMethod(c.Request.Method) {
+			browser := sessionID != "" || token != "" || c.GetHeader("Origin") != ""
+			if browser && (!valid || c.GetHeader(CSRFHeader) != token) {
+				response.Forbidden(c, "Invalid CSRF token", "state-changing requests from browsers need the "+CSRFHeader+" header matching the CSRF cookie")
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if !valid {
+			var err error
+			if token, err = cookies.codec.CSRFToken(sessionID); err != nil {
+				response.InternalServerError(c, "Failed to issue CSRF token", err.Error())
+				c.Abort()
+				return
+			}
+			// Tokens of no session last as long as the browser session
+			cookies.set(c, cookies.config.CSRFName, token, 0, false)
+		}
+		c.Set(CSRFTokenKey, token)
+		c.Next()
+	}
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
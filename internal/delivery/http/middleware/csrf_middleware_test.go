@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/jwt"
+	"boilerplate-go/pkg/securecookie"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := jwt.NewHMACKeySet("test-secret")
+	codec, err := securecookie.New("0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+	cookies := NewAuthCookies(AuthCookieConfig{Name: "session", CSRFName: "csrf_token"}, codec, keys)
+
+	r := gin.New()
+	r.Use(CSRFMiddleware(cookies))
+	r.GET("/csrf", func(c *gin.Context) { c.String(http.StatusOK, c.GetString(CSRFTokenKey)) })
+	r.POST("/login", func(c *gin.Context) {
+		claims := &jwt.Claims{UserID: 1}
+		claims.ID = "session-1"
+		token, err := keys.Sign(claims, time.Hour)
+		require.NoError(t, err)
+		require.NoError(t, cookies.SignIn(c, &entity.LoginResponse{Token: token}))
+		c.Status(http.StatusNoContent)
+	})
+	r.POST("/orders", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	// send makes the request with the cookies, echoing the CSRF token when set
+	send := func(method, path string, jar []*http.Cookie, csrf string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for _, cookie := range jar {
+			req.AddCookie(cookie)
+		}
+		if csrf != "" {
+			req.Header.Set(CSRFHeader, csrf)
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	cookie := func(w *httptest.ResponseRecorder, name string) *http.Cookie {
+		for _, c := range w.Result().Cookies() {
+			if c.Name == name {
+				return c
+			}
+		}
+		return nil
+	}
+	browser := map[string]string{"Origin": "https://app.example.com"}
+
+	// A browser gets an anonymous token before signing in
+	w := send(http.MethodGet, "/csrf", nil, "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	anonymous := cookie(w, "csrf_token")
+	require.NotNil(t, anonymous)
+	assert.False(t, anonymous.HttpOnly)
+	assert.Equal(t, anonymous.Value, w.Body.String())
+
+	t.Run("valid token is not reissued", func(t *testing.T) {
+		w := send(http.MethodGet, "/csrf", []*http.Cookie{anonymous}, "", nil)
+		assert.Nil(t, cookie(w, "csrf_token"))
+		assert.Equal(t, anonymous.Value, w.Body.String())
+	})
+
+	t.Run("browser login needs the token", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, send(http.MethodPost, "/login", nil, "", browser).Code)
+		assert.Equal(t, http.StatusForbidden, send(http.MethodPost, "/login", []*http.Cookie{anonymous}, "", nil).Code)
+		assert.Equal(t, http.StatusNoContent, send(http.MethodPost, "/login", []*http.Cookie{anonymous}, anonymous.Value, browser).Code)
+	})
+
+	t.Run("signed-in requests need the token of the session", func(t *testing.T) {
+		w := send(http.MethodPost, "/login", []*http.Cookie{anonymous}, anonymous.Value, browser)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		session, csrf := cookie(w, "session"), cookie(w, "csrf_token")
+		require.NotNil(t, session)
+		require.NotNil(t, csrf)
+
+		jar := []*http.Cookie{session, csrf}
+		assert.Equal(t, http.StatusNoContent, send(http.MethodPost, "/orders", jar, csrf.Value, nil).Code)
+		assert.Equal(t, http.StatusForbidden, send(http.MethodPost, "/orders", jar, "", nil).Code)
+		assert.Equal(t, http.StatusForbidden, send(http.MethodPost, "/orders", jar, anonymous.Value, nil).Code)
+
+		// The token of no session does not pass for the session's
+		jar = []*http.Cookie{session, anonymous}
+		assert.Equal(t, http.StatusForbidden, send(http.MethodPost, "/orders", jar, anonymous.Value, nil).Code)
+	})
+
+	t.Run("exempt clients", func(t *testing.T) {
+		// Clients sending neither cookies nor an Origin are not browsers
+		assert.Equal(t, http.StatusNoContent, send(http.MethodPost, "/orders", nil, "", nil).Code)
+		assert.Equal(t, http.StatusNoContent, send(http.MethodPost, "/orders", []*http.Cookie{anonymous}, "", map[string]string{
+			"Authorization": "Bearer token",
+		}).Code)
+	})
+}
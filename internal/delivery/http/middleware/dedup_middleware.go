@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"boilerplate-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeduplicatedHeader marks a response replayed for a duplicate request.
+const DeduplicatedHeader = "X-Deduplicated"
+
+// DryRunHeader asks for a request to be simulated without side effects. It is
+// part of the dedup key, so a real request does not replay its dry run.
+const DryRunHeader = "X-Dry-Run"
+
+// maxDedupBodySize is the largest body buffered for deduplication. Larger
+// requests, such as bulk imports, are passed through without it.
+const maxDedupBodySize = 1 << 20
+
+// dedupEntry is the response to a request, shared with duplicates of it.
+// done is closed once the original request has completed.
+type dedupEntry struct {
+	done        chan struct{}
+	status      int
+	contentType string
+	body        []byte
+	expiresAt   time.Time
+}
+
+// dedupExpiry queues a completed entry for removal once its window has passed.
+type dedupExpiry struct {
+	key   string
+	entry *dedupEntry
+}
+
+// dedupCache remembers recent unsafe requests until the dedup window passes.
+// Completed entries are queued in completion order, which with a fixed window
+// is also expiry order, so expiring them only looks at the front of the queue.
+type dedupCache struct {
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+	expiry  []dedupExpiry
+	window  time.Duration
+}
+
+func newDedupCache(window time.Duration) *dedupCache {
+	return &dedupCache{
+		entries: make(map[string]*dedupEntry),
+		window:  window,
+	}
+}
+
+// claim returns the entry for the key and whether the caller owns it and must
+// process the request. Other callers are duplicates and replay its response.
+func (d *dedupCache) claim(key string, now time.Time) (*dedupEntry, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for len(d.expiry) > 0 && now.After(d.expiry[0].entry.expiresAt) {
+		expired := d.expiry[0]
+		d.expiry[0] = dedupExpiry{}
+		d.expiry = d.expiry[1:]
+		if d.entries[expired.key] == expired.entry {
+			delete(d.entries, expired.key)
+		}
+	}
+
+	if entry, exists := d.entries[key]; exists {
+		return entry, false
+	}
+
+	entry := &dedupEntry{done: make(chan struct{})}
+	d.entries[key] = entry
+	return entry, true
+}
+
+// complete stores the response and releases waiting duplicates. Server errors
+// are not kept so that a deliberate retry reaches the handler again.
+func (d *dedupCache) complete(key string, entry *dedupEntry, status int, contentType string, body []byte, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry.status = status
+	entry.contentType = contentType
+	entry.body = body
+	entry.expiresAt = now.Add(d.window)
+	close(entry.done)
+
+	if status >= http.StatusInternalServerError {
+		delete(d.entries, key)
+		return
+	}
+	d.expiry = append(d.expiry, dedupExpiry{key: key, entry: entry})
+}
+
+// readCloser pairs a reader with the closer of the body it was built from.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// recordingWriter copies the response body while writing it to the client.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// DedupMiddleware detects accidental double submits of unsafe requests, such as
+// a double-clicked refund. Requests from the same user with the same method,
+// path and body within the window get the original response replayed instead
+// of being processed again. It must run after authentication; anonymous
+// requests are keyed by client IP. A zero window disables deduplication.
+// Requests are remembered in memory, so duplicates are only caught when they
+// reach the same instance; behind a load balancer without sticky sessions
+// some double submits still go through.
+func DedupMiddleware(window time.Duration) gin.HandlerFunc {
+	if window <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	cache := newDedupCache(window)
+
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxDedupBodySize+1))
+		if err != nil {
+			response.BadRequest(c, "Invalid request body", err.Error())
+			c.Abort()
+			return
+		}
+		if len(body) > maxDedupBodySize {
+			c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+			c.Next()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key := dedupKey(c, body)
+		entry, owner := cache.claim(key, time.Now())
+		if !owner {
+			select {
+			case <-entry.done:
+			case <-c.Request.Context().Done():
+				c.Abort()
+				return
+			}
+
+			c.Header(DeduplicatedHeader, "true")
+			c.Data(entry.status, entry.contentType, entry.body)
+			c.Abort()
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				cache.complete(key, entry, http.StatusInternalServerError, "", nil, time.Now())
+				panic(recovered)
+			}
+			cache.complete(key, entry, writer.Status(), writer.Header().Get("Content-Type"), writer.body.Bytes(), time.Now())
+		}()
+
+		c.Next()
+	}
+}
+
+// dedupKey identifies a request by caller, method, path and body hash. The
+// caller is the user in the organization the request acts in.
+func dedupKey(c *gin.Context, body []byte) string {
+	caller := "ip:" + c.ClientIP()
+	if userID, exists := c.Get("user_id"); exists {
+		caller = fmt.Sprintf("user:%v", userID)
+	}
+	if orgID, exists := c.Get(OrganizationIDKey); exists {
+		caller += fmt.Sprintf("@org:%v", orgID)
+	}
+
+	sum := sha256.Sum256(body)
+	return caller + "|" + c.Request.Method + "|" + c.Request.URL.RequestURI() + "|" + c.GetHeader(DryRunHeader) + "|" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newDedupRouter mounts a handler answering with status that counts its calls.
+func newDedupRouter(window time.Duration, status int, calls *int32, release <-chan struct{}) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(DedupMiddleware(window))
+	r.POST("/orders", func(c *gin.Context) {
+		n := atomic.AddInt32(calls, 1)
+		if release != nil {
+			<-release
+		}
+		body, _ := io.ReadAll(c.Request.Body)
+		c.JSON(status, gin.H{"call": n, "size": len(body)})
+	})
+	return r
+}
+
+func postOrder(r *gin.Engine, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body)))
+	return w
+}
+
+func TestDedupMiddleware_ReplaysDuplicate(t *testing.T) {
+	var calls int32
+	r := newDedupRouter(time.Minute, http.StatusCreated, &calls, nil)
+
+	first := postOrder(r, `{"amount":10}`)
+	second := postOrder(r, `{"amount":10}`)
+	other := postOrder(r, `{"amount":20}`)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get(DeduplicatedHeader))
+	assert.Empty(t, first.Header().Get(DeduplicatedHeader))
+	assert.Empty(t, other.Header().Get(DeduplicatedHeader))
+}
+
+func TestDedupMiddleware_DuplicateWaitsForOriginal(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	r := newDedupRouter(time.Minute, http.StatusCreated, &calls, release)
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 2)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = postOrder(r, `{"amount":10}`)
+		}(i)
+	}
+
+	// Let both requests reach the middleware before the original completes
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&calls) == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, responses[0].Body.String(), responses[1].Body.String())
+}
+
+func TestDedupMiddleware_DryRunIsNotADuplicate(t *testing.T) {
+	var calls int32
+	r := newDedupRouter(time.Minute, http.StatusOK, &calls, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(`{"amount":10}`))
+	req.Header.Set(DryRunHeader, "true")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	real := postOrder(r, `{"amount":10}`)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Empty(t, real.Header().Get(DeduplicatedHeader))
+}
+
+func TestDedupMiddleware_ServerErrorsAreNotCached(t *testing.T) {
+	var calls int32
+	r := newDedupRouter(time.Minute, http.StatusBadGateway, &calls, nil)
+
+	postOrder(r, `{"amount":10}`)
+	retry := postOrder(r, `{"amount":10}`)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Empty(t, retry.Header().Get(DeduplicatedHeader))
+}
+
+func TestDedupMiddleware_ZeroWindowDisables(t *testing.T) {
+	var calls int32
+	r := newDedupRouter(0, http.StatusCreated, &calls, nil)
+
+	postOrder(r, `{"amount":10}`)
+	second := postOrder(r, `{"amount":10}`)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Empty(t, second.Header().Get(DeduplicatedHeader))
+}
+
+func TestDedupMiddleware_LargeBodiesPassThrough(t *testing.T) {
+	var calls int32
+	r := newDedupRouter(time.Minute, http.StatusCreated, &calls, nil)
+	body := string(bytes.Repeat([]byte("a"), maxDedupBodySize+10))
+
+	first := postOrder(r, body)
+	second := postOrder(r, body)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Contains(t, first.Body.String(), `"size":1048586`)
+	assert.Empty(t, second.Header().Get(DeduplicatedHeader))
+}
+
+func TestDedupCache_Expiry(t *testing.T) {
+	cache := newDedupCache(time.Minute)
+	now := time.Now()
+
+	entry, owner := cache.claim("a", now)
+	assert.True(t, owner)
+	cache.complete("a", entry, http.StatusOK, "", nil, now)
+
+	_, owner = cache.claim("a", now.Add(30*time.Second))
+	assert.False(t, owner, "duplicate within the window")
+
+	_, owner = cache.claim("a", now.Add(2*time.Minute))
+	assert.True(t, owner, "window has passed")
+	assert.Empty(t, cache.expiry)
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Drainer tells whether the instance is taken out of rotation
+type Drainer interface {
+	Draining() bool
+}
+
+// DrainMiddleware closes the connection after each response while the
+// instance drains, so clients keeping connections alive reconnect through
+// the load balancer to an instance still in rotation
+func DrainMiddleware(drainer Drainer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if drainer.Draining() {
+			c.Header("Connection", "close")
+		}
+		c.Next()
+	}
+}
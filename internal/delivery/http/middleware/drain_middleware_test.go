@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// draining is a Drainer reporting its value
+type draining bool
+
+func (d draining) Draining() bool { return bool(d) }
+
+func TestDrainMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, drain := range []bool{false, true} {
+		r := gin.New()
+		r.Use(DrainMiddleware(draining(drain)))
+		r.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		if drain {
+			assert.Equal(t, "close", w.Header().Get("Connection"))
+		} else {
+			assert.Empty(t, w.Header().Get("Connection"))
+		}
+	}
+}
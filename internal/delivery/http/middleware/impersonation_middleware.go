@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys of the support staff member acting through an impersonation
+// token, set by AuthenticationMiddleware from the token's actor claim
+const (
+	ImpersonatorIDKey   = "impersonator_id"
+	ImpersonatorNameKey = "impersonator_username"
+)
+
+// ImpersonationAudit logs every request made with an impersonation token,
+// naming the impersonator, the impersonated user and the outcome. It must run
+// after AuthenticationMiddleware; requests with ordinary tokens pass through.
+func ImpersonationAudit(log *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		impersonatorID, ok := c.Get(ImpersonatorIDKey)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		log.WithContext(c.Request.Context()).WithFields(map[string]interface{}{
+			"impersonator_id":       impersonatorID,
+			"impersonator_username": c.GetString(ImpersonatorNameKey),
+			"user_id":               c.GetInt("user_id"),
+			"method":                c.Request.Method,
+			"path":                  c.Request.URL.Path,
+			"status":                c.Writer.Status(),
+			"duration":              time.Since(start).String(),
+			"action":                "impersonated_request",
+		}).Warn("Request made under impersonation")
+	}
+}
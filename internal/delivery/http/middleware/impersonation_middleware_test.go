@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpersonationAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := jwt.NewHMACKeySet("test-secret")
+	log := logger.NewLogger()
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	r := gin.New()
+	r.GET("/api/v1/orders", AuthenticationMiddleware(keys, activeSessions{}), ImpersonationAudit(log), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	get := func(claims *jwt.Claims) {
+		token, err := keys.Sign(claims, time.Hour)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusNoContent, w.Code)
+	}
+
+	t.Run("ordinary token is not logged", func(t *testing.T) {
+		buf.Reset()
+		get(&jwt.Claims{UserID: 7, Username: "alice"})
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("impersonation token is logged", func(t *testing.T) {
+		buf.Reset()
+		get(&jwt.Claims{UserID: 7, Username: "alice", Actor: &jwt.Actor{UserID: 2, Username: "support"}})
+		line := buf.String()
+		assert.Contains(t, line, `"action":"impersonated_request"`)
+		assert.Contains(t, line, `"impersonator_id":2`)
+		assert.Contains(t, line, `"impersonator_username":"support"`)
+		assert.Contains(t, line, `"user_id":7`)
+		assert.Contains(t, line, `"path":"/api/v1/orders"`)
+		assert.Contains(t, line, `"status":204`)
+	})
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"boilerplate-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reasons reported when a request is shed.
+const (
+	ShedReasonInFlight = "in_flight"
+	ShedReasonLatency  = "latency"
+)
+
+// LoadSignal reports the current load of the server, such as the application metrics.
+type LoadSignal interface {
+	InFlight() int64
+	LatencyP99() time.Duration
+}
+
+// ShedRecorder records requests rejected by the load shedder.
+type ShedRecorder interface {
+	RecordShed(method, path, reason string)
+}
+
+// LoadShedConfig holds the load shedding thresholds. A zero threshold is not checked.
+// Requests whose path starts with one of CriticalPaths are never shed.
+type LoadShedConfig struct {
+	MaxInFlight   int64
+	MaxLatencyP99 time.Duration
+	RetryAfter    time.Duration
+	CriticalPaths []string
+}
+
+// DefaultCriticalPaths keeps payments, webhooks and probes served under load.
+var DefaultCriticalPaths = []string{
+	"/api/v1/orders",
+	"/api/v1/webhooks",
+	"/health",
+	"/ready",
+	"/live",
+	"/metrics",
+}
+
+// LoadShedMiddleware rejects low-priority requests with 503 while the server
+// is overloaded, so payment-critical endpoints keep their capacity. It must be
+// mounted before the metrics middleware so shed requests do not count as load.
+func LoadShedMiddleware(signal LoadSignal, recorder ShedRecorder, config LoadShedConfig) gin.HandlerFunc {
+	if config.MaxInFlight <= 0 && config.MaxLatencyP99 <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	retryAfter := strconv.Itoa(int(config.RetryAfter.Seconds()))
+	if config.RetryAfter < time.Second {
+		retryAfter = "1"
+	}
+
+	return func(c *gin.Context) {
+		if isCriticalPath(c.Request.URL.Path, config.CriticalPaths) {
+			c.Next()
+			return
+		}
+
+		reason := ""
+		switch {
+		case config.MaxInFlight > 0 && signal.InFlight() >= config.MaxInFlight:
+			reason = ShedReasonInFlight
+		case config.MaxLatencyP99 > 0 && signal.LatencyP99() > config.MaxLatencyP99:
+			reason = ShedReasonLatency
+		}
+
+		if reason != "" {
+			if recorder != nil {
+				path := c.FullPath()
+				if path == "" {
+					path = "unknown"
+				}
+				recorder.RecordShed(c.Request.Method, path, reason)
+			}
+			c.Header("Retry-After", retryAfter)
+			response.Error(c, http.StatusServiceUnavailable, "Server is overloaded", "request shed, retry later")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func isCriticalPath(path string, critical []string) bool {
+	for _, prefix := range critical {
+		if underPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
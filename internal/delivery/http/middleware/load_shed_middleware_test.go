@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeLoad struct {
+	inFlight int64
+	p99      time.Duration
+	shed     []string
+}
+
+func (f *fakeLoad) InFlight() int64           { return f.inFlight }
+func (f *fakeLoad) LatencyP99() time.Duration { return f.p99 }
+func (f *fakeLoad) RecordShed(method, path, reason string) {
+	f.shed = append(f.shed, reason)
+}
+
+func newShedRouter(load *fakeLoad, config LoadShedConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(LoadShedMiddleware(load, load, config))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/api/v1/user/profile", ok)
+	r.POST("/api/v1/orders", ok)
+	r.GET("/api/v1/orders-archive", ok)
+	return r
+}
+
+func get(r *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestLoadShedMiddleware(t *testing.T) {
+	config := LoadShedConfig{
+		MaxInFlight:   10,
+		MaxLatencyP99: time.Second,
+		RetryAfter:    5 * time.Second,
+		CriticalPaths: DefaultCriticalPaths,
+	}
+
+	tests := []struct {
+		name     string
+		load     fakeLoad
+		method   string
+		path     string
+		expected int
+		reason   string
+	}{
+		{name: "under thresholds", load: fakeLoad{inFlight: 9, p99: time.Second}, method: http.MethodGet, path: "/api/v1/user/profile", expected: http.StatusOK},
+		{name: "too many in flight", load: fakeLoad{inFlight: 10}, method: http.MethodGet, path: "/api/v1/user/profile", expected: http.StatusServiceUnavailable, reason: ShedReasonInFlight},
+		{name: "slow p99", load: fakeLoad{p99: 2 * time.Second}, method: http.MethodGet, path: "/api/v1/user/profile", expected: http.StatusServiceUnavailable, reason: ShedReasonLatency},
+		{name: "critical path is kept", load: fakeLoad{inFlight: 50, p99: time.Minute}, method: http.MethodPost, path: "/api/v1/orders", expected: http.StatusOK},
+		{name: "prefix must match a path segment", load: fakeLoad{inFlight: 50}, method: http.MethodGet, path: "/api/v1/orders-archive", expected: http.StatusServiceUnavailable, reason: ShedReasonInFlight},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			load := tt.load
+			w := get(newShedRouter(&load, config), tt.method, tt.path)
+
+			assert.Equal(t, tt.expected, w.Code)
+			if tt.reason == "" {
+				assert.Empty(t, load.shed)
+				return
+			}
+			assert.Equal(t, []string{tt.reason}, load.shed)
+			assert.Equal(t, "5", w.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestLoadShedMiddleware_DisabledWithoutThresholds(t *testing.T) {
+	load := &fakeLoad{inFlight: 1000, p99: time.Hour}
+	w := get(newShedRouter(load, LoadShedConfig{}), http.MethodGet, "/api/v1/user/profile")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"boilerplate-go/pkg/i18n"
+	"boilerplate-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware negotiates the response locale from the Accept-Language
+// header, falling back to the catalog default. AuthenticationMiddleware
+// switches to the locale saved by the user, which takes precedence.
+func LocaleMiddleware(catalog *i18n.Catalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(response.CatalogKey, catalog)
+		locale, _ := catalog.Match(c.GetHeader("Accept-Language"))
+		response.SetLocale(c, locale)
+		c.Header("Vary", "Accept-Language")
+		c.Next()
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"boilerplate-go/pkg/i18n"
+	"boilerplate-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type localeTestRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+func newLocaleRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	catalog, err := i18n.NewCatalog("en")
+	require.NoError(t, err)
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(response.JSONFieldName)
+	}
+
+	r := gin.New()
+	r.Use(LocaleMiddleware(catalog))
+	r.POST("/", func(c *gin.Context) {
+		// Stands in for the locale AuthenticationMiddleware takes from the token
+		response.PreferLocale(c, c.Query("user_locale"))
+
+		var req localeTestRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.InvalidRequest(c, "Invalid request body", err)
+			return
+		}
+		response.Success(c, http.StatusOK, "Login successful", nil)
+	})
+	return r
+}
+
+func localeRequest(r *gin.Engine, target, acceptLanguage, body string) (*httptest.ResponseRecorder, response.Response) {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if acceptLanguage != "" {
+		req.Header.Set("Accept-Language", acceptLanguage)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp response.Response
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func TestLocaleMiddleware(t *testing.T) {
+	r := newLocaleRouter(t)
+	valid := `{"email":"jane@example.com","password":"secret-password"}`
+
+	w, resp := localeRequest(r, "/", "id-ID,id;q=0.9,en;q=0.8", valid)
+	assert.Equal(t, "Login berhasil", resp.Message)
+	assert.Equal(t, "id", w.Header().Get("Content-Language"))
+	assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+
+	w, resp = localeRequest(r, "/", "fr", valid)
+	assert.Equal(t, "Login successful", resp.Message)
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+
+	// The saved user locale beats Accept-Language
+	_, resp = localeRequest(r, "/?user_locale=id", "en", valid)
+	assert.Equal(t, "Login berhasil", resp.Message)
+	_, resp = localeRequest(r, "/?user_locale=fr", "id", valid)
+	assert.Equal(t, "Login berhasil", resp.Message)
+}
+
+func TestLocaleMiddleware_ValidationErrors(t *testing.T) {
+	r := newLocaleRouter(t)
+	body := `{"email":"not-an-email","password":"short"}`
+
+	w, resp := localeRequest(r, "/", "en", body)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "Invalid request body", resp.Message)
+	assert.Equal(t, "email must be a valid email address; password must be at least 8 characters", resp.Error)
+
+	_, resp = localeRequest(r, "/", "id", body)
+	assert.Equal(t, "Isi permintaan tidak valid", resp.Message)
+	assert.Equal(t, "email harus berupa alamat email yang valid; password minimal 8 karakter", resp.Error)
+
+	_, resp = localeRequest(r, "/", "id", `{"email":`)
+	assert.Equal(t, "isi permintaan harus berupa JSON yang valid", resp.Error)
+
+	_, resp = localeRequest(r, "/", "en", `{"email":1}`)
+	assert.Equal(t, "email must be a string", resp.Error)
+}
@@ -2,9 +2,12 @@ package middleware
 
 import (
 	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/i18n"
 	"boilerplate-go/pkg/jwt"
 	"boilerplate-go/pkg/response"
-	"net/http"
+	"context"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -12,42 +15,83 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
-	"golang.org/x/time/rate"
 )
 
-// MiddlewareConfig holds middleware configuration
+// MiddlewareConfig holds middleware configuration.
+// AccessLog receives an Apache style access log in AccessLogFormat when set.
+// Catalog localizes responses when set.
+// RateLimit limits anonymous and authenticated traffic; zero limits disable
+// rate limiting.
+// Stages are middleware of the application added to the pipeline after the
+// built-in ones, arranged with them by Pipeline.
 type MiddlewareConfig struct {
-	Logger    *logger.Logger
-	JWTSecret string
+	Logger          *logger.Logger
+	JWTSecret       string
+	ProblemDetails  bool
+	AccessLog       io.Writer
+	AccessLogFormat string
+	Catalog         *i18n.Catalog
+	RateLimit       RateLimitConfig
+	Stages          []Stage
+	Pipeline        PipelineConfig
 }
 
-// SetupMiddlewares configures all application middlewares
-func SetupMiddlewares(r *gin.Engine, config MiddlewareConfig) {
-	// Request ID middleware
-	r.Use(RequestIDMiddleware())
-
-	// CORS middleware
-	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"*"},
-		ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
-
-	// Logging middleware
-	r.Use(LoggingMiddleware(config.Logger))
+// SetupMiddlewares installs the global middleware pipeline
+func SetupMiddlewares(r *gin.Engine, config MiddlewareConfig) error {
+	// Name fields in validation errors as clients send them
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(response.JSONFieldName)
+	}
 
-	// Rate limiting middleware
-	r.Use(RateLimitMiddleware(100, 1)) // 100 requests per second with burst of 1
+	handlers, err := BuildPipeline(append(DefaultStages(config), config.Stages...), config.Pipeline)
+	if err != nil {
+		return err
+	}
+	r.Use(handlers...)
+	return nil
+}
 
-	// Security headers middleware
-	r.Use(SecurityHeadersMiddleware())
+// DefaultStages returns the built-in middleware in default order, leaving
+// out the handlers of those the config does not enable
+func DefaultStages(config MiddlewareConfig) []Stage {
+	var accessLog, locale, problemDetails, rateLimit gin.HandlerFunc
+	if config.AccessLog != nil {
+		accessLog = AccessLogMiddleware(config.AccessLog, config.AccessLogFormat)
+	}
+	if config.Catalog != nil {
+		locale = LocaleMiddleware(config.Catalog)
+	}
+	if config.ProblemDetails {
+		problemDetails = ProblemDetailsMiddleware()
+	}
+	if config.RateLimit.enabled() {
+		rateLimit = RateLimitMiddleware(config.RateLimit, config.Logger)
+	}
 
-	// Recovery middleware
-	r.Use(RecoveryMiddleware(config.Logger))
+	return []Stage{
+		// Access log first, so rejected requests are logged too
+		{Name: StageAccessLog, Handler: accessLog},
+		{Name: StageRequestID, Handler: RequestIDMiddleware()},
+		// Response locale, before anything that may respond
+		{Name: StageLocale, Handler: locale},
+		// RFC 7807 error format for clients asking for it
+		{Name: StageProblemDetails, Handler: problemDetails},
+		{Name: StageCORS, Handler: cors.New(cors.Config{
+			AllowOrigins:     []string{"*"},
+			AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowHeaders:     []string{"*"},
+			ExposeHeaders:    []string{"Content-Length", "X-Request-ID", RateLimitLimitHeader, RateLimitRemainingHeader, RateLimitResetHeader, RateLimitWarningHeader, "Retry-After"},
+			AllowCredentials: true,
+			MaxAge:           12 * time.Hour,
+		})},
+		{Name: StageLogging, Handler: LoggingMiddleware(config.Logger)},
+		{Name: StageRateLimit, Handler: rateLimit},
+		{Name: StageSecurityHeaders, Handler: SecurityHeadersMiddleware()},
+		{Name: StageRecovery, Handler: RecoveryMiddleware(config.Logger)},
+	}
 }
 
 // RequestIDMiddleware generates and injects request IDs
@@ -55,16 +99,30 @@ func RequestIDMiddleware() gin.HandlerFunc {
 	return requestid.New()
 }
 
+// ProblemDetailsMiddleware switches error responses to application/problem+json,
+// or application/problem+xml, for clients that list it in their Accept header.
+// Other clients keep the legacy envelope.
+func ProblemDetailsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accept := c.GetHeader("Accept")
+		if strings.Contains(accept, response.ProblemJSONContentType) || strings.Contains(accept, response.ProblemXMLContentType) {
+			c.Set(response.ProblemDetailsKey, true)
+		}
+		c.Next()
+	}
+}
+
 // LoggingMiddleware logs all HTTP requests
 func LoggingMiddleware(log *logger.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
+		c.Set(response.RequestStartKey, start)
 
 		// Add correlation ID to context
-		correlationID := c.GetHeader("X-Request-ID")
+		correlationID := c.GetHeader(response.RequestIDHeader)
 		if correlationID == "" {
 			correlationID = uuid.New().String()
-			c.Header("X-Request-ID", correlationID)
+			c.Header(response.RequestIDHeader, correlationID)
 		}
 
 		ctx := logger.ContextWithCorrelationID(c.Request.Context(), correlationID)
@@ -85,50 +143,115 @@ func LoggingMiddleware(log *logger.Logger) gin.HandlerFunc {
 	}
 }
 
-// AuthenticationMiddleware validates JWT tokens
-func AuthenticationMiddleware(secretKey string) gin.HandlerFunc {
+// SessionValidator checks whether the session bound to a token is still active
+type SessionValidator interface {
+	IsSessionActive(ctx context.Context, tokenID string) (bool, error)
+}
+
+// AuthenticationMiddleware validates JWT tokens.
+// When sessions is non-nil, tokens belonging to revoked or unknown sessions are rejected.
+func AuthenticationMiddleware(keys *jwt.KeySet, sessions SessionValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			response.Unauthorized(c, "Authorization header required", "missing authorization header")
-			c.Abort()
+		token, ok := bearerToken(c)
+		if !ok {
 			return
 		}
+		authenticate(c, keys, sessions, token)
+	}
+}
+
+// bearerToken returns the token of the Authorization header, answering 401
+// when there is none
+func bearerToken(c *gin.Context) (string, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		response.Unauthorized(c, "Authorization header required", "missing authorization header")
+		c.Abort()
+		return "", false
+	}
+
+	tokenParts := strings.Split(authHeader, " ")
+	if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+		response.Unauthorized(c, "Invalid authorization format", "expected Bearer token")
+		c.Abort()
+		return "", false
+	}
 
-		tokenParts := strings.Split(authHeader, " ")
-		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			response.Unauthorized(c, "Invalid authorization format", "expected Bearer token")
+	return tokenParts[1], true
+}
+
+// authenticate validates the token and the session it belongs to, then adds
+// the user to the request
+func authenticate(c *gin.Context, keys *jwt.KeySet, sessions SessionValidator, token string) {
+	claims, err := keys.ValidateToken(token)
+	if err != nil {
+		response.Unauthorized(c, "Invalid token", err.Error())
+		c.Abort()
+		return
+	}
+
+	if sessions != nil {
+		active, err := sessions.IsSessionActive(c.Request.Context(), claims.ID)
+		if err != nil {
+			response.InternalServerError(c, "Failed to validate session", err.Error())
 			c.Abort()
 			return
 		}
-
-		token := tokenParts[1]
-		claims, err := jwt.ValidateToken(token, secretKey)
-		if err != nil {
-			response.Unauthorized(c, "Invalid token", err.Error())
+		if !active {
+			response.Unauthorized(c, "Invalid token", "session has been revoked or expired")
 			c.Abort()
 			return
 		}
+	}
 
-		// Add user info to context
-		ctx := logger.ContextWithUserID(c.Request.Context(), claims.UserID)
-		c.Request = c.Request.WithContext(ctx)
+	// A locale saved by the user beats Accept-Language
+	response.PreferLocale(c, claims.Locale)
 
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Next()
+	// Add user info to context
+	ctx := logger.ContextWithUserID(c.Request.Context(), claims.UserID)
+	actor := &entity.Actor{UserID: claims.UserID, Source: entity.ChangeSourceAPI}
+	if claims.Actor != nil {
+		actor.ImpersonatorID = claims.Actor.UserID
+	}
+	c.Request = c.Request.WithContext(entity.ContextWithActor(ctx, actor))
+
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("scopes", claims.Scopes)
+	c.Set("tenant_id", claims.TenantID)
+	c.Set("token_id", claims.ID)
+	if claims.Actor != nil {
+		c.Set(ImpersonatorIDKey, claims.Actor.UserID)
+		c.Set(ImpersonatorNameKey, claims.Actor.Username)
 	}
+	c.Next()
 }
 
-// RateLimitMiddleware implements rate limiting
-func RateLimitMiddleware(requestsPerSecond rate.Limit, burst int) gin.HandlerFunc {
-	limiter := rate.NewLimiter(requestsPerSecond, burst)
+// RequireScope rejects requests whose token does not grant the scope.
+// It must run after AuthenticationMiddleware.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, _ := c.Get("scopes")
+		granted, _ := scopes.([]string)
+		for _, s := range granted {
+			if s == scope {
+				c.Next()
+				return
+			}
+		}
 
+		response.Forbidden(c, "Insufficient permissions", "missing scope: "+scope)
+		c.Abort()
+	}
+}
+
+// LegacyScopes grants the scopes to tokens that carry none, issued before
+// routes required scopes. It must run after AuthenticationMiddleware.
+func LegacyScopes(scopes []string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if !limiter.Allow() {
-			response.Error(c, http.StatusTooManyRequests, "Rate limit exceeded", "too many requests")
-			c.Abort()
-			return
+		granted, _ := c.Get("scopes")
+		if held, _ := granted.([]string); len(held) == 0 {
+			c.Set("scopes", scopes)
 		}
 		c.Next()
 	}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHeader names the organization a request acts in
+const OrganizationHeader = "X-Organization-ID"
+
+// Context keys of the organization a request acts in and the caller's role in it
+const (
+	OrganizationIDKey   = "organization_id"
+	OrganizationRoleKey = "organization_role"
+)
+
+// OrganizationResolver returns a user's membership of an organization, or
+// errors.ErrNotOrgMember
+type OrganizationResolver interface {
+	GetMembership(ctx context.Context, orgID, userID int) (*entity.Membership, error)
+}
+
+// OrganizationContext resolves the organization named by the X-Organization-ID
+// header and attaches it to the request, so orders and files are made in and
+// shared with the organization. Callers who are not members are rejected.
+// Requests without the header act for the user alone. It must run after
+// AuthenticationMiddleware.
+func OrganizationContext(resolver OrganizationResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader(OrganizationHeader)
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		orgID, err := strconv.Atoi(header)
+		if err != nil || orgID <= 0 {
+			response.BadRequest(c, "Invalid organization", "X-Organization-ID must be an organization ID")
+			c.Abort()
+			return
+		}
+
+		member, err := resolver.GetMembership(c.Request.Context(), orgID, c.GetInt("user_id"))
+		if err != nil {
+			if errors.IsNotOrgMember(err) {
+				response.Forbidden(c, "Not a member of the organization", err.Error())
+			} else {
+				response.InternalServerError(c, "Failed to resolve organization", err.Error())
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set(OrganizationIDKey, member.OrganizationID)
+		c.Set(OrganizationRoleKey, member.Role)
+		c.Request = c.Request.WithContext(entity.ContextWithOrganization(c.Request.Context(), &entity.OrgContext{
+			ID:   member.OrganizationID,
+			Role: member.Role,
+		}))
+		c.Next()
+	}
+}
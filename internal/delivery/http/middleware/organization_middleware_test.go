@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// memberships resolves the memberships of user 7
+type memberships map[int]string
+
+func (m memberships) GetMembership(ctx context.Context, orgID, userID int) (*entity.Membership, error) {
+	role, ok := m[orgID]
+	if !ok || userID != 7 {
+		return nil, errors.ErrNotOrgMember
+	}
+	return &entity.Membership{OrganizationID: orgID, UserID: userID, Role: role}, nil
+}
+
+func TestOrganizationContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/api/v1/orders", func(c *gin.Context) {
+		c.Set("user_id", 7)
+	}, OrganizationContext(memberships{3: entity.OrgRoleAdmin}), func(c *gin.Context) {
+		org := entity.OrganizationFromContext(c.Request.Context())
+		if org == nil {
+			c.String(http.StatusOK, "personal")
+			return
+		}
+		c.String(http.StatusOK, "%d:%s", org.ID, org.Role)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+		body   string
+	}{
+		{name: "no header acts for the user", status: http.StatusOK, body: "personal"},
+		{name: "member acts in the organization", header: "3", status: http.StatusOK, body: "3:admin"},
+		{name: "non-member is rejected", header: "4", status: http.StatusForbidden},
+		{name: "invalid header is rejected", header: "acme", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+			if tt.header != "" {
+				req.Header.Set(OrganizationHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/pkg/jwt"
+	"boilerplate-go/pkg/perfbudget"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The global chain and authentication run in front of every API handler
+const middlewareChainBudget = 200 * time.Microsecond
+
+type activeSessions struct{}
+
+func (activeSessions) IsSessionActive(ctx context.Context, tokenID string) (bool, error) {
+	return true, nil
+}
+
+// BenchmarkMiddlewareChain measures an authenticated request through the
+// application middleware to a handler that does nothing.
+func BenchmarkMiddlewareChain(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+	log := logger.NewLogger()
+	log.SetOutput(io.Discard)
+
+	r := gin.New()
+	if err := SetupMiddlewares(r, MiddlewareConfig{Logger: log}); err != nil {
+		b.Fatal(err)
+	}
+	keys := jwt.NewHMACKeySet("benchmark-secret")
+	r.GET("/api/v1/user/profile", AuthenticationMiddleware(keys, activeSessions{}), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	token, err := keys.GenerateToken(1, "testuser", "token-1", time.Hour)
+	if err != nil {
+		b.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/user/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusNoContent {
+			b.Fatalf("status %d", w.Code)
+		}
+	}
+}
+
+func TestMiddlewareChain_LatencyBudget(t *testing.T) {
+	perfbudget.Check(t, middlewareChainBudget, BenchmarkMiddlewareChain)
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tracingStages returns stages that append their name to the X-Trace header
+func tracingStages(names ...string) []Stage {
+	stages := make([]Stage, len(names))
+	for i, name := range names {
+		stages[i] = Stage{Name: name, Handler: func(c *gin.Context) {
+			c.Writer.Header().Add("X-Trace", name)
+			c.Next()
+		}}
+	}
+	return stages
+}
+
+// trace serves a request through the pipeline and returns the stages it ran
+func trace(t *testing.T, stages []Stage, config PipelineConfig, path string) []string {
+	t.Helper()
+	handlers, err := BuildPipeline(stages, config)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(handlers...)
+	r.GET("/*path", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Header().Values("X-Trace")
+}
+
+func TestBuildPipeline_Order(t *testing.T) {
+	stages := tracingStages("a", "b", "c", "d")
+
+	assert.Equal(t, []string{"a", "b", "c", "d"}, trace(t, stages, PipelineConfig{}, "/"))
+	assert.Equal(t, []string{"c", "a", "b", "d"}, trace(t, stages, PipelineConfig{Order: []string{"c", "a"}}, "/"),
+		"stages left out of the order follow in default order")
+}
+
+func TestBuildPipeline_Disabled(t *testing.T) {
+	stages := append(tracingStages("a", "b"), Stage{Name: "unconfigured"})
+
+	assert.Equal(t, []string{"b"}, trace(t, stages, PipelineConfig{Disabled: []string{"a"}}, "/"))
+	assert.Equal(t, []string{"a", "b"}, trace(t, stages, PipelineConfig{Order: []string{"unconfigured"}}, "/"),
+		"unconfigured stages can be named but are not installed")
+}
+
+func TestBuildPipeline_Groups(t *testing.T) {
+	stages := tracingStages("a", "b")
+	config := PipelineConfig{
+		Disabled: []string{"b"},
+		Groups: map[string][]string{
+			"/api/v1/webhooks":          {"-a", "+b"},
+			"/api/v1/webhooks/payments": {"+a"},
+		},
+	}
+
+	assert.Equal(t, []string{"a"}, trace(t, stages, config, "/api/v1/orders"))
+	assert.Equal(t, []string{"b"}, trace(t, stages, config, "/api/v1/webhooks/email"))
+	assert.Equal(t, []string{"a", "b"}, trace(t, stages, config, "/api/v1/webhooks/payments"), "the longest prefix wins")
+	assert.Equal(t, []string{"a"}, trace(t, stages, config, "/api/v1/webhooksx"), "prefixes match whole segments")
+}
+
+func TestBuildPipeline_Invalid(t *testing.T) {
+	stages := tracingStages("a", "b")
+
+	for name, config := range map[string]PipelineConfig{
+		"unknown in order":    {Order: []string{"z"}},
+		"ordered twice":       {Order: []string{"a", "a"}},
+		"unknown disabled":    {Disabled: []string{"z"}},
+		"unknown in group":    {Groups: map[string][]string{"/api": {"-z"}}},
+		"change without sign": {Groups: map[string][]string{"/api": {"a"}}},
+		"group not a path":    {Groups: map[string][]string{"api": {"-a"}}},
+	} {
+		_, err := BuildPipeline(stages, config)
+		assert.Error(t, err, name)
+	}
+
+	_, err := BuildPipeline(append(stages, stages[0]), PipelineConfig{})
+	assert.True(t, err != nil && strings.Contains(err.Error(), "twice"), "duplicate stage: %v", err)
+}
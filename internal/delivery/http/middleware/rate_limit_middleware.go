@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/ratelimit"
+	"boilerplate-go/pkg/jwt"
+	"boilerplate-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// Rate limit headers. Limit is the requests accepted at once, Remaining how
+// many of them are left and Reset the seconds until all are available again.
+// Warning is set on requests over the soft limit.
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+	RateLimitWarningHeader   = "X-RateLimit-Warning"
+)
+
+// RateLimitBucket is the requests per second Limit a class of traffic is
+// accepted at, in bursts of Burst, before responding 429. Above SoftLimit
+// requests are still accepted, but warned about and logged. A zero Limit
+// does not limit the traffic; a zero SoftLimit does not warn.
+type RateLimitBucket struct {
+	Limit     rate.Limit
+	Burst     int
+	SoftLimit rate.Limit
+}
+
+// RateLimiter keeps token buckets by key, such as in memory or shared by the
+// instances in Redis. Take takes a token from the bucket of key, refilled at
+// limit per second up to burst, and returns whether there was one and the
+// tokens left. Limiters relying on a service fall back on their own when it
+// fails, so requests are always limited.
+type RateLimiter interface {
+	Take(ctx context.Context, key string, limit rate.Limit, burst int) (bool, float64)
+}
+
+// RateLimitConfig holds separate buckets for anonymous and authenticated
+// traffic, so a flood of anonymous requests does not lock out signed-in
+// users. Identify tells the authenticated requests apart; nil counts every
+// request as anonymous. Limiter keeps the buckets; nil keeps them in memory
+// per instance.
+type RateLimitConfig struct {
+	Anonymous     RateLimitBucket
+	Authenticated RateLimitBucket
+	Identify      func(c *gin.Context) bool
+	Limiter       RateLimiter
+}
+
+// enabled reports whether any traffic is limited or warned about
+func (config RateLimitConfig) enabled() bool {
+	return config.Anonymous.Limit > 0 || config.Anonymous.SoftLimit > 0 ||
+		config.Authenticated.Limit > 0 || config.Authenticated.SoftLimit > 0
+}
+
+// bucketLimiter applies a RateLimitBucket to one class of traffic
+type bucketLimiter struct {
+	traffic string
+	bucket  RateLimitBucket
+	// warnings samples the logs of requests over the soft limit
+	warnings rate.Sometimes
+}
+
+func newBucketLimiter(traffic string, bucket RateLimitBucket) *bucketLimiter {
+	bucket.Burst = max(bucket.Burst, 1)
+	return &bucketLimiter{traffic: traffic, bucket: bucket, warnings: rate.Sometimes{Interval: time.Second}}
+}
+
+// RateLimitMiddleware accepts requests up to the hard limit of their bucket
+// and responds 429 with Retry-After above it, telling clients how close they
+// are with the X-RateLimit headers. Requests over the soft limit get an
+// X-RateLimit-Warning and are logged, at most once a second per bucket.
+func RateLimitMiddleware(config RateLimitConfig, log *logger.Logger) gin.HandlerFunc {
+	anonymous := newBucketLimiter("anonymous", config.Anonymous)
+	authenticated := newBucketLimiter("authenticated", config.Authenticated)
+	limiter := config.Limiter
+	if limiter == nil {
+		limiter = ratelimit.NewLocal()
+	}
+
+	return func(c *gin.Context) {
+		traffic := anonymous
+		if config.Identify != nil && config.Identify(c) {
+			traffic = authenticated
+		}
+		bucket := traffic.bucket
+		ctx := c.Request.Context()
+
+		if bucket.Limit > 0 {
+			allowed, tokens := limiter.Take(ctx, traffic.traffic, bucket.Limit, bucket.Burst)
+			c.Header(RateLimitLimitHeader, strconv.Itoa(bucket.Burst))
+			c.Header(RateLimitRemainingHeader, strconv.Itoa(max(int(tokens), 0)))
+			c.Header(RateLimitResetHeader, strconv.Itoa(secondsUntil(float64(bucket.Burst)-tokens, bucket.Limit)))
+			if !allowed {
+				c.Header("Retry-After", strconv.Itoa(max(secondsUntil(1-tokens, bucket.Limit), 1)))
+				response.Error(c, http.StatusTooManyRequests, "Rate limit exceeded", "too many requests")
+				c.Abort()
+				return
+			}
+		}
+
+		if bucket.SoftLimit > 0 {
+			if allowed, _ := limiter.Take(ctx, traffic.traffic+":soft", bucket.SoftLimit, bucket.Burst); !allowed {
+				c.Header(RateLimitWarningHeader, "soft rate limit exceeded")
+				traffic.warnings.Do(func() {
+					log.WithContext(ctx).WithFields(map[string]interface{}{
+						"traffic":   traffic.traffic,
+						"client_ip": c.ClientIP(),
+						"path":      c.Request.URL.Path,
+					}).Warn("Soft rate limit exceeded")
+				})
+			}
+		}
+		c.Next()
+	}
+}
+
+// secondsUntil returns the whole seconds it takes to gain tokens at limit
+func secondsUntil(tokens float64, limit rate.Limit) int {
+	if tokens <= 0 {
+		return 0
+	}
+	return int(math.Ceil(tokens / float64(limit)))
+}
+
+// TokenAuthenticated reports whether a request carries a valid token in the
+// Authorization header or, with cookies, in the session cookie. Whether its
+// session was revoked is left to the authentication of the route.
+func TokenAuthenticated(keys *jwt.KeySet, cookies *AuthCookies) func(c *gin.Context) bool {
+	return func(c *gin.Context) bool {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok && cookies != nil {
+			token, _ = cookies.token(c)
+		}
+		if token == "" {
+			return false
+		}
+		_, err := keys.ValidateToken(token)
+		return err == nil
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateLimitedRouter(config RateLimitConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RateLimitMiddleware(config, logger.NewLogger()))
+	r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func ping(r *gin.Engine, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitMiddleware_HardLimit(t *testing.T) {
+	r := newRateLimitedRouter(RateLimitConfig{Anonymous: RateLimitBucket{Limit: 1, Burst: 2}})
+
+	first := ping(r, "")
+	second := ping(r, "")
+	third := ping(r, "")
+
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "2", first.Header().Get(RateLimitLimitHeader))
+	assert.Equal(t, "1", first.Header().Get(RateLimitRemainingHeader))
+	assert.Equal(t, "1", first.Header().Get(RateLimitResetHeader))
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, "0", second.Header().Get(RateLimitRemainingHeader))
+	assert.Equal(t, "2", second.Header().Get(RateLimitResetHeader))
+	assert.Equal(t, http.StatusTooManyRequests, third.Code)
+	assert.Equal(t, "0", third.Header().Get(RateLimitRemainingHeader))
+	assert.Equal(t, "1", third.Header().Get("Retry-After"))
+}
+
+func TestRateLimitMiddleware_SoftLimit(t *testing.T) {
+	// Above one request a second, well within the hard limit
+	r := newRateLimitedRouter(RateLimitConfig{Anonymous: RateLimitBucket{Limit: 1000, Burst: 1, SoftLimit: 1}})
+
+	first := ping(r, "")
+	time.Sleep(5 * time.Millisecond)
+	second := ping(r, "")
+
+	assert.Equal(t, http.StatusOK, second.Code, "the soft limit only warns")
+	assert.Empty(t, first.Header().Get(RateLimitWarningHeader))
+	assert.NotEmpty(t, second.Header().Get(RateLimitWarningHeader))
+}
+
+func TestRateLimitMiddleware_SeparateBuckets(t *testing.T) {
+	keys := jwt.NewHMACKeySet("test-secret")
+	token, err := keys.GenerateToken(1, "alice", "t1", time.Hour)
+	require.NoError(t, err)
+	r := newRateLimitedRouter(RateLimitConfig{
+		Anonymous:     RateLimitBucket{Limit: 1, Burst: 1},
+		Authenticated: RateLimitBucket{Limit: 1, Burst: 3},
+		Identify:      TokenAuthenticated(keys, nil),
+	})
+
+	assert.Equal(t, http.StatusOK, ping(r, "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, ping(r, "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, ping(r, "forged").Code, "invalid tokens count as anonymous")
+
+	authenticated := ping(r, token)
+	assert.Equal(t, http.StatusOK, authenticated.Code, "anonymous traffic does not use up the authenticated bucket")
+	assert.Equal(t, "3", authenticated.Header().Get(RateLimitLimitHeader))
+}
+
+func TestRateLimitMiddleware_UnlimitedBucket(t *testing.T) {
+	r := newRateLimitedRouter(RateLimitConfig{Authenticated: RateLimitBucket{Limit: 1, Burst: 1}})
+
+	for i := 0; i < 3; i++ {
+		w := ping(r, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(RateLimitLimitHeader))
+	}
+}
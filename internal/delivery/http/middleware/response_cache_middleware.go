@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"boilerplate-go/pkg/cache"
+	"boilerplate-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResponseCacheHeader tells whether a response was served from the response
+// cache: HIT or MISS.
+const ResponseCacheHeader = "X-Cache"
+
+// cachedHeaders are the response headers replayed from the cache. Others,
+// such as the correlation ID, belong to the request that was cached.
+var cachedHeaders = []string{"Cache-Control", "Content-Disposition", "Content-Language", "Content-Type", "ETag", "Last-Modified", "Vary"}
+
+// CacheRecorder records lookups of the response cache, such as the metrics.
+type CacheRecorder interface {
+	RecordResponseCache(path string, hit bool)
+}
+
+// cachedResponse is a response kept in the cache. Responses rendered in the
+// response envelope keep the envelope, its data frozen as JSON, so a hit gets
+// the metadata of its own request; others keep their body.
+type cachedResponse struct {
+	header   http.Header
+	envelope *response.Response
+	body     []byte
+	storedAt time.Time
+}
+
+// ResponseCache keeps the responses of public GET routes in memory, bounded
+// by their total size, so requests within a route's TTL skip the handler.
+// Responses are kept per instance; behind a load balancer each instance
+// serves its own copy until it expires.
+type ResponseCache struct {
+	store    *cache.Cache[*cachedResponse]
+	recorder CacheRecorder
+}
+
+// NewResponseCache creates a cache of responses up to maxBytes in total. The
+// recorder may be nil.
+func NewResponseCache(maxBytes int64, recorder CacheRecorder) *ResponseCache {
+	return &ResponseCache{
+		store:    cache.New[*cachedResponse](maxBytes),
+		recorder: recorder,
+	}
+}
+
+// Middleware serves GET requests from the cache, and caches their successful
+// responses for ttl. Responses setting cookies are never cached. Responses
+// are told to be cached for ttl by browsers and proxies as well, unless the
+// handler set its own Cache-Control or a cookie is set, as by the CSRF
+// middleware, which must not be shared. A nil cache or a zero ttl disables
+// it.
+func (rc *ResponseCache) Middleware(ttl time.Duration) gin.HandlerFunc {
+	if rc == nil || ttl <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	cacheControl := fmt.Sprintf("public, max-age=%d", int(ttl.Seconds()))
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		key := responseCacheKey(c)
+		if cached, ok := rc.store.Get(key); ok {
+			rc.record(c, true)
+			header := c.Writer.Header()
+			for name, values := range cached.header {
+				header[name] = values
+			}
+			if header.Get("Cache-Control") == "" && header.Get("Set-Cookie") == "" {
+				header.Set("Cache-Control", cacheControl)
+			}
+			header.Set("Age", strconv.Itoa(int(time.Since(cached.storedAt).Seconds())))
+			header.Set(ResponseCacheHeader, "HIT")
+			if cached.envelope != nil {
+				response.Replay(c, http.StatusOK, *cached.envelope)
+			} else {
+				c.Data(http.StatusOK, cached.header.Get("Content-Type"), cached.body)
+			}
+			c.Abort()
+			return
+		}
+		rc.record(c, false)
+
+		c.Header(ResponseCacheHeader, "MISS")
+		writer := &cachingWriter{recordingWriter: recordingWriter{ResponseWriter: c.Writer}, cacheControl: cacheControl}
+		c.Writer = writer
+		c.Next()
+
+		if writer.Status() != http.StatusOK || writer.Header().Get("Set-Cookie") != "" {
+			return
+		}
+		header := make(http.Header, len(cachedHeaders))
+		for _, name := range cachedHeaders {
+			if values := writer.Header().Values(name); len(values) > 0 {
+				header[name] = values
+			}
+		}
+		// The default Cache-Control is set again on each hit, unless the hit sets a cookie
+		if writer.defaultCacheControl {
+			header.Del("Cache-Control")
+		}
+		cached := &cachedResponse{header: header, storedAt: time.Now()}
+		size := int64(len(key) + writer.body.Len())
+		if rendered, ok := response.Rendered(c); ok {
+			data, err := json.Marshal(rendered.Data)
+			if err != nil {
+				return
+			}
+			rendered.Data = json.RawMessage(data)
+			cached.envelope = &rendered
+			size = int64(len(key) + len(data) + len(rendered.Message))
+		} else {
+			cached.body = bytes.Clone(writer.body.Bytes())
+		}
+		rc.store.Set(key, cached, size, ttl)
+	}
+}
+
+// Invalidate drops the cached responses of the paths starting with prefix,
+// such as after the data behind them changed, and returns how many.
+func (rc *ResponseCache) Invalidate(prefix string) int {
+	if rc == nil {
+		return 0
+	}
+	return rc.store.DeleteFunc(func(key string, _ *cachedResponse) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+func (rc *ResponseCache) record(c *gin.Context, hit bool) {
+	if rc.recorder != nil {
+		rc.recorder.RecordResponseCache(c.FullPath(), hit)
+	}
+}
+
+// responseCacheKey identifies a response by path and query, and by the request
+// headers it may vary on: the negotiated format and language, and the host
+// and scheme of the links in it.
+func responseCacheKey(c *gin.Context) string {
+	return strings.Join([]string{
+		c.Request.URL.RequestURI(),
+		c.Request.Host,
+		c.GetHeader("X-Forwarded-Proto"),
+		c.GetHeader("Accept"),
+		c.GetHeader("Accept-Language"),
+	}, "\n")
+}
+
+// cachingWriter copies the response body, and tells browsers and proxies how
+// long a successful response may be cached unless the handler did or a
+// cookie is set.
+type cachingWriter struct {
+	recordingWriter
+	cacheControl        string
+	defaultCacheControl bool
+}
+
+func (w *cachingWriter) WriteHeader(code int) {
+	header := w.Header()
+	if code == http.StatusOK && header.Get("Cache-Control") == "" && header.Get("Set-Cookie") == "" {
+		header.Set("Cache-Control", w.cacheControl)
+		w.defaultCacheControl = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"boilerplate-go/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cacheLookups counts the hits and misses recorded
+type cacheLookups struct {
+	hits, misses int
+}
+
+func (l *cacheLookups) RecordResponseCache(path string, hit bool) {
+	if hit {
+		l.hits++
+	} else {
+		l.misses++
+	}
+}
+
+// newCachedRouter mounts cached routes counting their calls
+func newCachedRouter(rc *ResponseCache, ttl time.Duration, calls *int32) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	// Stands in for the CSRF middleware, which sets its cookie before the cache runs
+	r.Use(func(c *gin.Context) {
+		if c.GetHeader("X-Set-Cookie") != "" {
+			c.SetCookie("csrf", "token", 0, "/", "", false, true)
+		}
+	})
+	r.Use(rc.Middleware(ttl))
+	r.GET("/envelope", func(c *gin.Context) {
+		n := atomic.AddInt32(calls, 1)
+		c.Header(response.RequestIDHeader, c.GetHeader(response.RequestIDHeader))
+		response.SuccessWithPagination(c, http.StatusOK, "Rates", gin.H{"call": n}, response.NewPagination(1, 10, 1))
+	})
+	r.GET("/rates", func(c *gin.Context) {
+		n := atomic.AddInt32(calls, 1)
+		c.Header("X-Correlation-ID", "request")
+		c.JSON(http.StatusOK, gin.H{"call": n, "lang": c.GetHeader("Accept-Language")})
+	})
+	r.GET("/session", func(c *gin.Context) {
+		atomic.AddInt32(calls, 1)
+		c.SetCookie("csrf", "token", 0, "/", "", false, true)
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	r.GET("/missing", func(c *gin.Context) {
+		atomic.AddInt32(calls, 1)
+		c.JSON(http.StatusNotFound, gin.H{})
+	})
+	return r
+}
+
+func getCached(r *gin.Engine, path string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestResponseCache_ServesCachedResponse(t *testing.T) {
+	var calls int32
+	lookups := &cacheLookups{}
+	r := newCachedRouter(NewResponseCache(1<<20, lookups), time.Minute, &calls)
+
+	first := getCached(r, "/rates")
+	second := getCached(r, "/rates")
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "MISS", first.Header().Get(ResponseCacheHeader))
+	assert.Equal(t, "HIT", second.Header().Get(ResponseCacheHeader))
+	assert.Equal(t, "public, max-age=60", first.Header().Get("Cache-Control"))
+	assert.Equal(t, "public, max-age=60", second.Header().Get("Cache-Control"))
+	assert.Equal(t, first.Header().Get("Content-Type"), second.Header().Get("Content-Type"))
+	assert.Equal(t, "0", second.Header().Get("Age"))
+	assert.Empty(t, second.Header().Get("X-Correlation-ID"), "headers of the cached request are not replayed")
+	assert.Equal(t, 1, lookups.hits)
+	assert.Equal(t, 1, lookups.misses)
+}
+
+func TestResponseCache_ReplaysEnvelopeWithOwnMeta(t *testing.T) {
+	var calls int32
+	r := newCachedRouter(NewResponseCache(1<<20, nil), time.Minute, &calls)
+
+	first := getCached(r, "/envelope", response.RequestIDHeader, "first")
+	second := getCached(r, "/envelope", response.RequestIDHeader, "second")
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, "HIT", second.Header().Get(ResponseCacheHeader))
+	assert.Equal(t, []string{"Accept"}, second.Header().Values("Vary"))
+	var body response.Response
+	require.NoError(t, json.Unmarshal(second.Body.Bytes(), &body))
+	assert.Equal(t, "second", body.Meta.RequestID, "the meta of the request served")
+	assert.Equal(t, 1, body.Meta.Pagination.TotalItems)
+	assert.Equal(t, map[string]interface{}{"call": float64(1)}, body.Data)
+	assert.NotEqual(t, first.Body.String(), second.Body.String())
+}
+
+func TestResponseCache_NotPublicWithCookies(t *testing.T) {
+	var calls int32
+	r := newCachedRouter(NewResponseCache(1<<20, nil), time.Minute, &calls)
+
+	miss := getCached(r, "/rates", "X-Set-Cookie", "1")
+	assert.Empty(t, miss.Header().Get("Cache-Control"))
+
+	getCached(r, "/rates")
+	hit := getCached(r, "/rates", "X-Set-Cookie", "1")
+	assert.Equal(t, "HIT", hit.Header().Get(ResponseCacheHeader))
+	assert.NotEmpty(t, hit.Header().Get("Set-Cookie"))
+	assert.Empty(t, hit.Header().Get("Cache-Control"))
+	assert.Equal(t, "public, max-age=60", getCached(r, "/rates").Header().Get("Cache-Control"))
+}
+
+func TestResponseCache_KeysOnQueryAndLanguage(t *testing.T) {
+	var calls int32
+	r := newCachedRouter(NewResponseCache(1<<20, nil), time.Minute, &calls)
+
+	getCached(r, "/rates")
+	getCached(r, "/rates?currency=EUR")
+	english := getCached(r, "/rates", "Accept-Language", "en")
+	indonesian := getCached(r, "/rates", "Accept-Language", "id")
+
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+	assert.Contains(t, english.Body.String(), `"lang":"en"`)
+	assert.Contains(t, indonesian.Body.String(), `"lang":"id"`)
+}
+
+func TestResponseCache_SkipsUncacheableResponses(t *testing.T) {
+	var calls int32
+	r := newCachedRouter(NewResponseCache(1<<20, nil), time.Minute, &calls)
+
+	getCached(r, "/session")
+	getCached(r, "/session")
+	getCached(r, "/missing")
+	missing := getCached(r, "/missing")
+
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+	assert.Empty(t, missing.Header().Get("Cache-Control"))
+}
+
+func TestResponseCache_Expiry(t *testing.T) {
+	var calls int32
+	r := newCachedRouter(NewResponseCache(1<<20, nil), 10*time.Millisecond, &calls)
+
+	getCached(r, "/rates")
+	time.Sleep(20 * time.Millisecond)
+	getCached(r, "/rates")
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestResponseCache_Invalidate(t *testing.T) {
+	var calls int32
+	rc := NewResponseCache(1<<20, nil)
+	r := newCachedRouter(rc, time.Minute, &calls)
+
+	getCached(r, "/rates")
+	getCached(r, "/rates?currency=EUR")
+	assert.Equal(t, 2, rc.Invalidate("/rates"))
+	getCached(r, "/rates")
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestResponseCache_Disabled(t *testing.T) {
+	var calls int32
+	var rc *ResponseCache
+	r := newCachedRouter(rc, time.Minute, &calls)
+
+	getCached(r, "/rates")
+	w := getCached(r, "/rates")
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Empty(t, w.Header().Get(ResponseCacheHeader))
+	assert.Equal(t, 0, rc.Invalidate("/"))
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/pkg/response"
+	"boilerplate-go/pkg/signature"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	SignatureKeyIDHeader     = "X-Signature-Key-Id"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	SignatureHeader          = "X-Signature"
+
+	// maxSignedBodySize bounds the body read into memory to compute its digest
+	maxSignedBodySize = 1 << 20
+)
+
+// ErrSigningKeyNotFound is returned when a key ID is unknown to the key store.
+var ErrSigningKeyNotFound = errors.New("signing key not found")
+
+// SigningKeyStore resolves shared secrets for request signature verification.
+// It is the extension point for backing keys by an API key store.
+type SigningKeyStore interface {
+	GetSigningSecret(ctx context.Context, keyID string) (string, error)
+}
+
+// StaticKeyStore serves signing secrets from an in-memory map.
+type StaticKeyStore map[string]string
+
+// GetSigningSecret returns the secret registered for the key ID.
+func (s StaticKeyStore) GetSigningSecret(_ context.Context, keyID string) (string, error) {
+	secret, ok := s[keyID]
+	if !ok || secret == "" {
+		return "", ErrSigningKeyNotFound
+	}
+	return secret, nil
+}
+
+// replayEntry is an accepted signature and the time it may be forgotten.
+type replayEntry struct {
+	sig       string
+	expiresAt time.Time
+}
+
+// replayCache remembers recently accepted signatures until the replay window passes.
+// Every entry lives for the same duration, so insertion order is expiry order and
+// expired entries are dropped from the front of the queue.
+type replayCache struct {
+	mu     sync.Mutex
+	seen   map[string]struct{}
+	queue  []replayEntry
+	window time.Duration
+}
+
+func newReplayCache(window time.Duration) *replayCache {
+	return &replayCache{
+		seen:   make(map[string]struct{}),
+		window: window,
+	}
+}
+
+// markSeen records the signature and reports whether it was already used.
+func (r *replayCache) markSeen(sig string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expired := 0
+	for expired < len(r.queue) && now.After(r.queue[expired].expiresAt) {
+		delete(r.seen, r.queue[expired].sig)
+		expired++
+	}
+	r.queue = r.queue[expired:]
+
+	if _, exists := r.seen[sig]; exists {
+		return true
+	}
+	r.seen[sig] = struct{}{}
+	r.queue = append(r.queue, replayEntry{sig: sig, expiresAt: now.Add(2 * r.window)})
+	return false
+}
+
+// SignatureMiddleware verifies HMAC request signatures for partner and webhook endpoints
+func SignatureMiddleware(keys SigningKeyStore, window time.Duration, log *logger.Logger) gin.HandlerFunc {
+	replays := newReplayCache(window)
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		keyID := c.GetHeader(SignatureKeyIDHeader)
+		timestamp := c.GetHeader(SignatureTimestampHeader)
+		sig := c.GetHeader(SignatureHeader)
+		if keyID == "" || timestamp == "" || sig == "" {
+			response.Unauthorized(c, "Request signature required", "missing signature headers")
+			c.Abort()
+			return
+		}
+
+		secret, err := keys.GetSigningSecret(ctx, keyID)
+		if err != nil {
+			log.WithContext(ctx).WithFields(map[string]interface{}{
+				"key_id": keyID,
+			}).Warn("Unknown signing key")
+			response.Unauthorized(c, "Invalid request signature", "unknown signing key")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSignedBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				response.Error(c, http.StatusRequestEntityTooLarge, "Request body too large", err.Error())
+				c.Abort()
+				return
+			}
+			response.BadRequest(c, "Invalid request body", err.Error())
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := time.Now()
+		if err := signature.Verify(secret, sig, timestamp, c.Request.Method, c.Request.URL.RequestURI(), body, window, now); err != nil {
+			log.WithContext(ctx).WithFields(map[string]interface{}{
+				"key_id": keyID,
+				"reason": err.Error(),
+			}).Warn("Request signature verification failed")
+			response.Unauthorized(c, "Invalid request signature", err.Error())
+			c.Abort()
+			return
+		}
+
+		if replays.markSeen(keyID+":"+sig, now) {
+			log.WithContext(ctx).WithFields(map[string]interface{}{
+				"key_id": keyID,
+			}).Warn("Replayed signed request rejected")
+			response.Unauthorized(c, "Invalid request signature", "request already processed")
+			c.Abort()
+			return
+		}
+
+		c.Set("signing_key_id", keyID)
+		c.Next()
+	}
+}
@@ -0,0 +1,210 @@
+package route
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/delivery/http/middleware"
+	"boilerplate-go/pkg/jwt"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Access is the authentication a group of routes requires
+type Access int
+
+const (
+	// Public routes need no credentials
+	Public Access = iota
+	// Authenticated routes need a bearer token of an active session
+	Authenticated
+	// Signed routes need an HMAC request signature. They are only mounted
+	// when request signing is enabled, and need no CSRF token.
+	Signed
+	// Provisioning routes need the bearer token of the identity provider
+	// provisioning users. They are only mounted when provisioning is enabled,
+	// and need no CSRF token.
+	Provisioning
+)
+
+// Group is a set of routes sharing a path prefix and middleware requirements
+type Group struct {
+	// Prefix is the absolute path of the group, such as "/api/v1/orders"
+	Prefix string
+	Access Access
+	// Scopes are required of authenticated users
+	Scopes []string
+	// Dedup replays the first response to identical requests of a user
+	Dedup bool
+	// Consent rejects changes by authenticated users who have yet to accept
+	// the current legal documents
+	Consent bool
+	// MaxInFlight rejects requests of an authenticated user to a route while
+	// this many are in flight; zero does not limit them
+	MaxInFlight int
+	// Cache keeps the responses to GET requests of Public routes for this
+	// long; zero does not cache them
+	Cache time.Duration
+	// Register adds the routes, relative to Prefix
+	Register func(r gin.IRoutes)
+}
+
+// RouteRegistrar is implemented by feature modules to declare their routes
+// and what those routes require
+type RouteRegistrar interface {
+	Routes() []Group
+}
+
+// Config holds what the router needs to enforce route requirements
+type Config struct {
+	JWTKeys          *jwt.KeySet
+	SessionValidator middleware.SessionValidator
+	DedupWindow      time.Duration
+	// LegacyScopes are granted to tokens without scopes, issued before routes
+	// required them; nil leaves those tokens only the routes without scopes
+	LegacyScopes []string
+	// AuditLogger logs the requests made with impersonation tokens
+	AuditLogger *logger.Logger
+	// Organizations resolves the organization authenticated requests act in;
+	// nil ignores the X-Organization-ID header
+	Organizations middleware.OrganizationResolver
+	// SignatureMiddleware verifies signed requests; nil leaves Signed routes unmounted
+	SignatureMiddleware gin.HandlerFunc
+	// ProvisioningMiddleware authenticates identity providers; nil leaves
+	// Provisioning routes unmounted
+	ProvisioningMiddleware gin.HandlerFunc
+	// Cookies also authenticates requests with the session cookie and
+	// protects Public and Authenticated routes from cross-site request
+	// forgery; nil accepts only the Authorization header
+	Cookies *middleware.AuthCookies
+	// ResponseCache serves the GET requests of Public groups with a Cache
+	// TTL from memory; nil does not cache responses
+	ResponseCache *middleware.ResponseCache
+	// Consents checks the legal documents users have accepted for groups
+	// requiring Consent; nil requires none
+	Consents middleware.ConsentChecker
+	// InFlight keeps the requests in flight of groups with MaxInFlight, held
+	// for at most InFlightTTL; nil keeps them in memory per instance
+	InFlight    middleware.InFlightLimiter
+	InFlightTTL time.Duration
+}
+
+// Info describes a route of the engine and what it requires
+type Info struct {
+	Method string
+	Path   string
+	Access Access
+	Scopes []string
+}
+
+// Router mounts the routes of registrars behind the middleware they require
+type Router struct {
+	engine *gin.Engine
+	config Config
+	// mounted holds the requirements of the mounted routes by method and path
+	mounted map[string]Info
+}
+
+// NewRouter creates a router mounting routes on the engine
+func NewRouter(engine *gin.Engine, config Config) *Router {
+	return &Router{
+		engine:  engine,
+		config:  config,
+		mounted: make(map[string]Info),
+	}
+}
+
+// Routes lists the routes of the engine by path, with the requirements of
+// those mounted by the router. Routes added to the engine directly, such as
+// /health, are listed as public.
+func (rt *Router) Routes() []Info {
+	routes := rt.engine.Routes()
+	infos := make([]Info, 0, len(routes))
+	for _, r := range routes {
+		info, ok := rt.mounted[r.Method+" "+r.Path]
+		if !ok {
+			info = Info{Method: r.Method, Path: r.Path}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Path != infos[j].Path {
+			return infos[i].Path < infos[j].Path
+		}
+		return infos[i].Method < infos[j].Method
+	})
+	return infos
+}
+
+// Register mounts the routes of each registrar
+func (rt *Router) Register(registrars ...RouteRegistrar) {
+	for _, registrar := range registrars {
+		for _, group := range registrar.Routes() {
+			rt.mount(group)
+		}
+	}
+}
+
+func (rt *Router) mount(group Group) {
+	var handlers []gin.HandlerFunc
+	switch group.Access {
+	case Public:
+		if rt.config.Cookies != nil {
+			handlers = append(handlers, middleware.CSRFMiddleware(rt.config.Cookies))
+		}
+		if group.Cache > 0 {
+			handlers = append(handlers, rt.config.ResponseCache.Middleware(group.Cache))
+		}
+	case Authenticated:
+		if rt.config.Cookies != nil {
+			handlers = append(handlers,
+				middleware.CookieAuthenticationMiddleware(rt.config.JWTKeys, rt.config.SessionValidator, rt.config.Cookies),
+				middleware.CSRFMiddleware(rt.config.Cookies),
+			)
+		} else {
+			handlers = append(handlers, middleware.AuthenticationMiddleware(rt.config.JWTKeys, rt.config.SessionValidator))
+		}
+		if rt.config.AuditLogger != nil {
+			handlers = append(handlers, middleware.ImpersonationAudit(rt.config.AuditLogger))
+		}
+		if rt.config.LegacyScopes != nil {
+			handlers = append(handlers, middleware.LegacyScopes(rt.config.LegacyScopes))
+		}
+		for _, scope := range group.Scopes {
+			handlers = append(handlers, middleware.RequireScope(scope))
+		}
+		if rt.config.Organizations != nil {
+			handlers = append(handlers, middleware.OrganizationContext(rt.config.Organizations))
+		}
+		if group.Consent && rt.config.Consents != nil {
+			handlers = append(handlers, middleware.RequireConsent(rt.config.Consents))
+		}
+	case Signed:
+		if rt.config.SignatureMiddleware == nil {
+			return
+		}
+		handlers = append(handlers, rt.config.SignatureMiddleware)
+	case Provisioning:
+		if rt.config.ProvisioningMiddleware == nil {
+			return
+		}
+		handlers = append(handlers, rt.config.ProvisioningMiddleware)
+	}
+	if group.Dedup {
+		handlers = append(handlers, middleware.DedupMiddleware(rt.config.DedupWindow))
+	}
+	if group.MaxInFlight > 0 && group.Access == Authenticated {
+		handlers = append(handlers, middleware.ConcurrencyLimit(rt.config.InFlight, group.MaxInFlight, rt.config.InFlightTTL))
+	}
+
+	existing := make(map[string]bool)
+	for _, r := range rt.engine.Routes() {
+		existing[r.Method+" "+r.Path] = true
+	}
+	group.Register(rt.engine.Group(group.Prefix, handlers...))
+	for _, r := range rt.engine.Routes() {
+		if key := r.Method + " " + r.Path; !existing[key] {
+			rt.mounted[key] = Info{Method: r.Method, Path: r.Path, Access: group.Access, Scopes: group.Scopes}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"boilerplate-go/internal/delivery/http/middleware"
+	"boilerplate-go/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubRegistrar registers one route per group answering 200.
+type stubRegistrar []Group
+
+func (s stubRegistrar) Routes() []Group {
+	for i := range s {
+		s[i].Register = func(r gin.IRoutes) {
+			r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+		}
+	}
+	return s
+}
+
+func serve(r *gin.Engine, path, token string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRouter_Requirements(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := jwt.NewHMACKeySet("secret")
+
+	r := gin.New()
+	NewRouter(r, Config{JWTKeys: keys, DedupWindow: time.Second}).Register(stubRegistrar{
+		{Prefix: "/public"},
+		{Prefix: "/user", Access: Authenticated},
+		{Prefix: "/admin", Access: Authenticated, Scopes: []string{"admin"}, Dedup: true},
+		{Prefix: "/webhooks", Access: Signed},
+		{Prefix: "/scim", Access: Provisioning},
+	})
+
+	user, err := keys.GenerateToken(1, "alice", "t1", time.Hour)
+	assert.NoError(t, err)
+	admin, err := keys.Sign(&jwt.Claims{UserID: 2, Username: "root", Scopes: []string{"admin"}}, time.Hour)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, serve(r, "/public/ping", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve(r, "/user/ping", ""))
+	assert.Equal(t, http.StatusOK, serve(r, "/user/ping", user))
+	assert.Equal(t, http.StatusForbidden, serve(r, "/admin/ping", user))
+	assert.Equal(t, http.StatusOK, serve(r, "/admin/ping", admin))
+	// Signed routes are not mounted without request signing
+	assert.Equal(t, http.StatusNotFound, serve(r, "/webhooks/ping", ""))
+	// Neither are provisioning routes without provisioning
+	assert.Equal(t, http.StatusNotFound, serve(r, "/scim/ping", ""))
+}
+
+func TestRouter_SignedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reject := func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) }
+
+	r := gin.New()
+	NewRouter(r, Config{SignatureMiddleware: reject}).Register(stubRegistrar{
+		{Prefix: "/webhooks", Access: Signed},
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, serve(r, "/webhooks/ping", ""))
+}
+
+func TestRouter_ProvisioningRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reject := func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) }
+
+	r := gin.New()
+	NewRouter(r, Config{ProvisioningMiddleware: reject}).Register(stubRegistrar{
+		{Prefix: "/scim", Access: Provisioning},
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, serve(r, "/scim/ping", ""))
+}
+
+func TestRouter_LegacyScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := jwt.NewHMACKeySet("secret")
+
+	r := gin.New()
+	NewRouter(r, Config{JWTKeys: keys, LegacyScopes: []string{"orders:read"}}).Register(stubRegistrar{
+		{Prefix: "/orders", Access: Authenticated, Scopes: []string{"orders:read"}},
+		{Prefix: "/admin", Access: Authenticated, Scopes: []string{"admin"}},
+	})
+
+	legacy, err := keys.GenerateToken(1, "alice", "t1", time.Hour)
+	assert.NoError(t, err)
+	scoped, err := keys.Sign(&jwt.Claims{UserID: 1, Username: "alice", Scopes: []string{"profile:read"}}, time.Hour)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, serve(r, "/orders/ping", legacy))
+	assert.Equal(t, http.StatusForbidden, serve(r, "/admin/ping", legacy))
+	// Tokens with scopes are not widened
+	assert.Equal(t, http.StatusForbidden, serve(r, "/orders/ping", scoped))
+}
+
+func TestRouter_CachedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := jwt.NewHMACKeySet("secret")
+
+	r := gin.New()
+	NewRouter(r, Config{JWTKeys: keys, ResponseCache: middleware.NewResponseCache(1<<20, nil)}).Register(stubRegistrar{
+		{Prefix: "/public", Cache: time.Minute},
+		{Prefix: "/user", Access: Authenticated, Cache: time.Minute},
+	})
+	user, err := keys.GenerateToken(1, "alice", "t1", time.Hour)
+	assert.NoError(t, err)
+
+	cacheResult := func(path, token string) string {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Header().Get(middleware.ResponseCacheHeader)
+	}
+
+	assert.Equal(t, "MISS", cacheResult("/public/ping", ""))
+	assert.Equal(t, "HIT", cacheResult("/public/ping", ""))
+	// Responses of authenticated routes are never cached
+	assert.Empty(t, cacheResult("/user/ping", user))
+}
+
+func TestRouter_Routes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	router := NewRouter(r, Config{JWTKeys: jwt.NewHMACKeySet("secret"), DedupWindow: time.Second})
+	router.Register(stubRegistrar{
+		{Prefix: "/public"},
+		{Prefix: "/admin", Access: Authenticated, Scopes: []string{"admin"}},
+		{Prefix: "/webhooks", Access: Signed},
+	})
+	r.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	assert.Equal(t, []Info{
+		{Method: http.MethodGet, Path: "/admin/ping", Access: Authenticated, Scopes: []string{"admin"}},
+		{Method: http.MethodGet, Path: "/health"},
+		{Method: http.MethodGet, Path: "/public/ping"},
+	}, router.Routes(), "unmounted signed routes are not listed")
+}
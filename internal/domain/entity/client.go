@@ -0,0 +1,27 @@
+package entity
+
+import "context"
+
+// ClientInfo describes the client behind a request: its address, the device
+// classified from its User-Agent and, when geolocation is enabled, where it is.
+type ClientInfo struct {
+	IPAddress string        `json:"ip_address"`
+	UserAgent string        `json:"user_agent"`
+	Device    string        `json:"device"`
+	OS        string        `json:"os,omitempty"`
+	Browser   string        `json:"browser,omitempty"`
+	Location  *LocationInfo `json:"location,omitempty"`
+}
+
+type clientInfoKey struct{}
+
+// ContextWithClientInfo attaches the client of a request to its context
+func ContextWithClientInfo(ctx context.Context, info *ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+// ClientInfoFromContext returns the client attached to the context, or nil
+func ClientInfoFromContext(ctx context.Context) *ClientInfo {
+	info, _ := ctx.Value(clientInfoKey{}).(*ClientInfo)
+	return info
+}
@@ -0,0 +1,71 @@
+package entity
+
+import "time"
+
+// ProfileEnvironment names the provider settings loaded from the environment
+// at startup, before any profile is applied
+const ProfileEnvironment = "environment"
+
+// ProviderProfile is a named set of provider settings, keyed by their
+// environment variable. Secrets are redacted when a profile is exported.
+type ProviderProfile struct {
+	Name     string            `json:"name" binding:"required,max=100"`
+	Settings map[string]string `json:"settings" binding:"required"`
+}
+
+// SettingChange is a provider setting changed by a profile, with secrets
+// redacted
+type SettingChange struct {
+	Key  string `json:"key"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ConfigChange is the audit record of a provider profile applied at runtime
+type ConfigChange struct {
+	ID        int             `json:"id"`
+	Profile   string          `json:"profile"`
+	Changes   []SettingChange `json:"changes"`
+	ChangedBy int             `json:"changed_by"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// ConfigChangeFilter selects a page of configuration changes
+type ConfigChangeFilter struct {
+	Page     int
+	PageSize int
+}
+
+// PublicConfig is the configuration frontends need, such as to set up the
+// payment SDK or check a file before uploading it. Every field is copied
+// from the configuration explicitly, so a new setting is never exposed
+// without being added here.
+type PublicConfig struct {
+	Payment  PublicPaymentConfig `json:"payment"`
+	Features map[string]bool     `json:"features"`
+	Locales  PublicLocales       `json:"locales"`
+	Uploads  PublicUploadLimits  `json:"uploads"`
+}
+
+// PublicPaymentConfig is the payment provider and its publishable keys.
+// An empty Currencies accepts any currency.
+type PublicPaymentConfig struct {
+	Provider             string   `json:"provider"`
+	StripePublishableKey string   `json:"stripe_publishable_key,omitempty"`
+	PayPalClientID       string   `json:"paypal_client_id,omitempty"`
+	Currencies           []string `json:"currencies"`
+}
+
+// PublicLocales are the locales responses can be written in
+type PublicLocales struct {
+	Default   string   `json:"default"`
+	Supported []string `json:"supported"`
+}
+
+// PublicUploadLimits are the largest files accepted, in bytes
+type PublicUploadLimits struct {
+	MaxFileSize        int64 `json:"max_file_size"`
+	PartSize           int64 `json:"part_size"`
+	AvatarMaxSize      int64 `json:"avatar_max_size"`
+	AvatarMaxDimension int   `json:"avatar_max_dimension"`
+}
@@ -0,0 +1,49 @@
+package entity
+
+import "time"
+
+// Legal documents users accept
+const (
+	DocumentTerms   = "terms_of_service"
+	DocumentPrivacy = "privacy_policy"
+)
+
+// LegalDocument is the current version of a legal document, published at URL
+type LegalDocument struct {
+	Document string `json:"document"`
+	Version  string `json:"version"`
+	URL      string `json:"url"`
+}
+
+// Consent records a user accepting a version of a legal document, and from
+// where. Erased users keep their consents without IP address and user agent.
+type Consent struct {
+	ID         int       `json:"id" db:"id"`
+	UserID     int       `json:"user_id" db:"user_id"`
+	Document   string    `json:"document" db:"document"`
+	Version    string    `json:"version" db:"version"`
+	IPAddress  string    `json:"ip_address" db:"ip_address"`
+	UserAgent  string    `json:"user_agent" db:"user_agent"`
+	AcceptedAt time.Time `json:"accepted_at" db:"accepted_at"`
+}
+
+// DocumentVersion names a version of a legal document
+type DocumentVersion struct {
+	Document string `json:"document" binding:"required"`
+	Version  string `json:"version" binding:"required"`
+}
+
+// AcceptConsentRequest accepts the versions of the documents the user was
+// shown, which must be the current ones
+type AcceptConsentRequest struct {
+	Documents []DocumentVersion `json:"documents" binding:"required,min=1,dive"`
+	IPAddress string            `json:"-"`
+	UserAgent string            `json:"-"`
+}
+
+// ConsentStatus lists the current documents a user has yet to accept, and
+// every version the user accepted, newest first
+type ConsentStatus struct {
+	Pending  []LegalDocument `json:"pending"`
+	Consents []*Consent      `json:"consents"`
+}
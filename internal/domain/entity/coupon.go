@@ -0,0 +1,83 @@
+package entity
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Coupon types
+const (
+	CouponTypePercentage = "percentage"
+	CouponTypeFixed      = "fixed"
+)
+
+// Coupon represents a promotion code granting a discount on orders.
+type Coupon struct {
+	ID             int        `json:"id" db:"id"`
+	Code           string     `json:"code" db:"code"`
+	Type           string     `json:"type" db:"type"`
+	Value          float64    `json:"value" db:"value"`
+	Currency       string     `json:"currency,omitempty" db:"currency"`
+	MinOrderAmount float64    `json:"min_order_amount" db:"min_order_amount"`
+	MaxRedemptions *int       `json:"max_redemptions,omitempty" db:"max_redemptions"`
+	PerUserLimit   *int       `json:"per_user_limit,omitempty" db:"per_user_limit"`
+	RedeemedCount  int        `json:"redeemed_count" db:"redeemed_count"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	Active         bool       `json:"active" db:"active"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// CouponRedemption represents a coupon applied to an order.
+type CouponRedemption struct {
+	ID             int       `json:"id" db:"id"`
+	CouponID       int       `json:"coupon_id" db:"coupon_id"`
+	UserID         int       `json:"user_id" db:"user_id"`
+	OrderID        int       `json:"order_id" db:"order_id"`
+	DiscountAmount float64   `json:"discount_amount" db:"discount_amount"`
+	RedeemedAt     time.Time `json:"redeemed_at" db:"redeemed_at"`
+}
+
+// CouponRequest represents the payload for creating or updating a coupon.
+type CouponRequest struct {
+	Code           string     `json:"code" binding:"required,max=64"`
+	Type           string     `json:"type" binding:"required,oneof=percentage fixed"`
+	Value          float64    `json:"value" binding:"required,gt=0"`
+	Currency       string     `json:"currency,omitempty" binding:"omitempty,len=3"`
+	MinOrderAmount float64    `json:"min_order_amount" binding:"gte=0"`
+	MaxRedemptions *int       `json:"max_redemptions,omitempty" binding:"omitempty,gt=0"`
+	PerUserLimit   *int       `json:"per_user_limit,omitempty" binding:"omitempty,gt=0"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	Active         *bool      `json:"active,omitempty"`
+}
+
+// NormalizeCouponCode returns the canonical form of a coupon code.
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Expired reports whether the coupon has passed its expiry time.
+func (c *Coupon) Expired(now time.Time) bool {
+	return c.ExpiresAt != nil && !now.Before(*c.ExpiresAt)
+}
+
+// Exhausted reports whether the coupon has reached its redemption limit.
+func (c *Coupon) Exhausted() bool {
+	return c.MaxRedemptions != nil && c.RedeemedCount >= *c.MaxRedemptions
+}
+
+// DiscountFor computes the discount for an order subtotal, rounded to cents and
+// never exceeding the subtotal.
+func (c *Coupon) DiscountFor(subtotal float64) float64 {
+	var discount float64
+	switch c.Type {
+	case CouponTypePercentage:
+		discount = subtotal * math.Min(c.Value, 100) / 100
+	case CouponTypeFixed:
+		discount = c.Value
+	}
+
+	discount = math.Round(discount*100) / 100
+	return math.Min(discount, subtotal)
+}
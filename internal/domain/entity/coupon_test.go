@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoupon_DiscountFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		coupon   Coupon
+		subtotal float64
+		expected float64
+	}{
+		{name: "percentage", coupon: Coupon{Type: CouponTypePercentage, Value: 10}, subtotal: 80, expected: 8},
+		{name: "percentage rounds to cents", coupon: Coupon{Type: CouponTypePercentage, Value: 15}, subtotal: 19.99, expected: 3},
+		{name: "percentage capped at 100", coupon: Coupon{Type: CouponTypePercentage, Value: 150}, subtotal: 42.5, expected: 42.5},
+		{name: "fixed", coupon: Coupon{Type: CouponTypeFixed, Value: 5}, subtotal: 80, expected: 5},
+		{name: "fixed never exceeds subtotal", coupon: Coupon{Type: CouponTypeFixed, Value: 50}, subtotal: 30, expected: 30},
+		{name: "unknown type", coupon: Coupon{Type: "bogus", Value: 5}, subtotal: 80, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.coupon.DiscountFor(tt.subtotal))
+		})
+	}
+}
+
+func TestCoupon_Expired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	assert.False(t, (&Coupon{}).Expired(now))
+	assert.True(t, (&Coupon{ExpiresAt: &past}).Expired(now))
+	assert.True(t, (&Coupon{ExpiresAt: &now}).Expired(now))
+	assert.False(t, (&Coupon{ExpiresAt: &future}).Expired(now))
+}
+
+func TestCoupon_Exhausted(t *testing.T) {
+	limit := 2
+
+	assert.False(t, (&Coupon{RedeemedCount: 100}).Exhausted())
+	assert.False(t, (&Coupon{MaxRedemptions: &limit, RedeemedCount: 1}).Exhausted())
+	assert.True(t, (&Coupon{MaxRedemptions: &limit, RedeemedCount: 2}).Exhausted())
+}
+
+func TestNormalizeCouponCode(t *testing.T) {
+	assert.Equal(t, "SUMMER10", NormalizeCouponCode("  summer10 "))
+}
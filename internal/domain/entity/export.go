@@ -0,0 +1,64 @@
+package entity
+
+import "time"
+
+// Export datasets
+const (
+	ExportDatasetUsers   = "users"
+	ExportDatasetOrders  = "orders"
+	ExportDatasetSignIns = "sign_ins"
+)
+
+// Export formats
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatXLSX   = "xlsx"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// Background export statuses
+const (
+	ExportStatusProcessing = "processing"
+	ExportStatusCompleted  = "completed"
+	ExportStatusFailed     = "failed"
+)
+
+// ExportFilter selects the rows of an export. From and To bound the creation
+// time, To excluded; Role applies to users, Status to orders and UserID to
+// orders and sign-ins.
+type ExportFilter struct {
+	From   *time.Time `json:"from,omitempty"`
+	To     *time.Time `json:"to,omitempty"`
+	Role   string     `json:"role,omitempty"`
+	Status string     `json:"status,omitempty"`
+	UserID int        `json:"user_id,omitempty"`
+}
+
+// ExportRequest is the payload to export a dataset in the background.
+type ExportRequest struct {
+	Dataset string       `json:"dataset" binding:"required" enums:"users,orders,sign_ins"`
+	Format  string       `json:"format" enums:"csv,xlsx,ndjson"`
+	Filter  ExportFilter `json:"filter"`
+}
+
+// Export is an export generated in the background and stored as a file.
+// DownloadURL is a signed URL of the file once the export has completed.
+type Export struct {
+	ID          int          `json:"id"`
+	Dataset     string       `json:"dataset"`
+	Format      string       `json:"format"`
+	Filter      ExportFilter `json:"filter"`
+	Status      string       `json:"status"`
+	Rows        int          `json:"rows"`
+	FileID      string       `json:"-"`
+	Error       string       `json:"error,omitempty"`
+	RequestedBy int          `json:"requested_by"`
+	DownloadURL string       `json:"download_url,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+}
+
+// ExportJob is the payload of a background export job.
+type ExportJob struct {
+	ExportID int `json:"export_id"`
+}
@@ -0,0 +1,7 @@
+package entity
+
+// InstanceStatus tells whether an instance of the service is in rotation
+type InstanceStatus struct {
+	Instance string `json:"instance"`
+	Draining bool   `json:"draining"`
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Background job types
+const (
+	JobTypeEmail           = "email"
+	JobTypeDeferredPayment = "deferred_payment"
+	JobTypeUserErasure     = "user_erasure"
+	JobTypeUserDataExport  = "user_data_export"
+	JobTypeUserImport      = "user_import"
+	JobTypeBulkEmail       = "bulk_email"
+	JobTypeUploadScan      = "upload_scan"
+	JobTypeEmailStatusSync = "email_status_sync"
+	JobTypeReportRollup    = "report_rollup"
+	JobTypeExport          = "export"
+	JobTypeWebhookPurge    = "webhook_event_purge"
+	JobTypeSagaRecovery    = "saga_recovery"
+)
+
+// Job is a queued background job. Jobs are stored before they run, so queued
+// and in-flight jobs survive a restart; a job whose worker died is claimed
+// again once its lease expires.
+type Job struct {
+	ID        int             `json:"id" db:"id"`
+	JobType   string          `json:"job_type" db:"job_type"`
+	Payload   json.RawMessage `json:"payload" db:"payload" swaggertype:"object"`
+	Attempts  int             `json:"attempts" db:"attempts"`
+	LastError string          `json:"last_error" db:"last_error"`
+	RunAt     time.Time       `json:"run_at" db:"run_at"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+// DeadLetterJob is a background job that exhausted its retries or could never succeed.
+type DeadLetterJob struct {
+	ID        int             `json:"id" db:"id"`
+	JobType   string          `json:"job_type" db:"job_type"`
+	Payload   json.RawMessage `json:"payload" db:"payload" swaggertype:"object"`
+	Error     string          `json:"error" db:"error"`
+	Attempts  int             `json:"attempts" db:"attempts"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+// DeadLetterFilter selects dead-lettered jobs for inspection.
+type DeadLetterFilter struct {
+	JobType  string
+	Page     int
+	PageSize int
+}
+
+// DeferredPayment is the payload of a job charging an order accepted while the
+// payment provider was unavailable.
+type DeferredPayment struct {
+	OrderID string `json:"order_id"`
+}
+
+// UserErasure is the payload of a job erasing a user's personal data.
+type UserErasure struct {
+	UserID int `json:"user_id"`
+}
+
+// UserDataExportJob is the payload of a job archiving a user's personal data.
+type UserDataExportJob struct {
+	UserID int `json:"user_id"`
+}
+
+// UserImport is the payload of a job running a stored bulk user import.
+type UserImport struct {
+	ImportID int `json:"import_id"`
+}
+
+// BulkEmail is the payload of a job sending a bulk notification in chunks.
+type BulkEmail struct {
+	NotificationID int `json:"notification_id"`
+}
@@ -0,0 +1,72 @@
+package entity
+
+import "time"
+
+// Bulk notification statuses
+const (
+	NotificationStatusProcessing = "processing"
+	NotificationStatusCompleted  = "completed"
+	NotificationStatusFailed     = "failed"
+)
+
+// Notification is a bulk email campaign sent to its recipients in chunks.
+// NextChunk is the first chunk not yet sent, so a failed send resumes there.
+// DeliveredAt, OpenedAt and ClickedAt are when any chunk was first delivered,
+// opened or clicked, as last checked with the email provider at StatusCheckedAt.
+type Notification struct {
+	ID              int                 `json:"id" db:"id"`
+	Subject         string              `json:"subject" db:"subject"`
+	Body            string              `json:"body" db:"body"`
+	BodyHTML        string              `json:"body_html,omitempty" db:"body_html"`
+	Recipients      []string            `json:"-" db:"recipients"`
+	Total           int                 `json:"total" db:"total"`
+	ChunkSize       int                 `json:"chunk_size" db:"chunk_size"`
+	TotalChunks     int                 `json:"total_chunks" db:"total_chunks"`
+	NextChunk       int                 `json:"next_chunk" db:"next_chunk"`
+	Sent            int                 `json:"sent" db:"sent"`
+	Failed          int                 `json:"failed" db:"failed"`
+	Chunks          []NotificationChunk `json:"chunks" db:"chunks"`
+	Status          string              `json:"status" db:"status"`
+	Error           string              `json:"error,omitempty" db:"error"`
+	CreatedAt       time.Time           `json:"created_at" db:"created_at"`
+	CompletedAt     *time.Time          `json:"completed_at,omitempty" db:"completed_at"`
+	DeliveredAt     *time.Time          `json:"delivered_at,omitempty" db:"delivered_at"`
+	OpenedAt        *time.Time          `json:"opened_at,omitempty" db:"opened_at"`
+	ClickedAt       *time.Time          `json:"clicked_at,omitempty" db:"clicked_at"`
+	StatusCheckedAt *time.Time          `json:"status_checked_at,omitempty" db:"status_checked_at"`
+}
+
+// ReceivedNotification is a bulk notification as one of its recipients got
+// it, without the delivery figures of the whole notification.
+type ReceivedNotification struct {
+	ID          int        `json:"id"`
+	Subject     string     `json:"subject"`
+	Body        string     `json:"body"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// NotificationChunk is the outcome of sending one chunk of a bulk notification.
+// Status and the engagement timestamps are reported by the email provider for
+// ProviderID.
+type NotificationChunk struct {
+	Index       int        `json:"index"`
+	Sent        int        `json:"sent"`
+	Failed      int        `json:"failed"`
+	ProviderID  string     `json:"provider_id,omitempty"`
+	SentAt      time.Time  `json:"sent_at"`
+	Status      string     `json:"status,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	OpenedAt    *time.Time `json:"opened_at,omitempty"`
+	ClickedAt   *time.Time `json:"clicked_at,omitempty"`
+}
+
+// CampaignRequest represents the payload to send an email to many recipients.
+// AllUsers adds every active user to the recipients.
+type CampaignRequest struct {
+	Subject    string   `json:"subject" binding:"required"`
+	Body       string   `json:"body" binding:"required"`
+	BodyHTML   string   `json:"body_html"`
+	Recipients []string `json:"recipients"`
+	AllUsers   bool     `json:"all_users"`
+}
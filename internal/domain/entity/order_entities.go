@@ -1,29 +1,198 @@
 package entity
 
-import "time"
+import (
+	"math"
+	"time"
+)
+
+// Order statuses
+const (
+	OrderStatusPending        = "pending"
+	OrderStatusPaymentPending = "payment_pending"
+	OrderStatusProcessing     = "processing"
+	OrderStatusCompleted      = "completed"
+	OrderStatusFailed         = "failed"
+	OrderStatusCancelled      = "cancelled"
+	OrderStatusRefunded       = "refunded"
+)
+
+// orderTransitions lists the statuses an order may move to from each status.
+// An order is processing while its payment is being charged and cannot be
+// cancelled until the charge has an outcome.
+var orderTransitions = map[string][]string{
+	OrderStatusPending:        {OrderStatusProcessing, OrderStatusFailed, OrderStatusCancelled, OrderStatusPaymentPending},
+	OrderStatusPaymentPending: {OrderStatusProcessing, OrderStatusFailed, OrderStatusCancelled},
+	OrderStatusProcessing:     {OrderStatusCompleted, OrderStatusFailed, OrderStatusPaymentPending},
+	OrderStatusFailed:         {OrderStatusCancelled},
+	OrderStatusCompleted:      {OrderStatusRefunded},
+}
+
+// Order represents a persisted order. Version is bumped by every update,
+// which only applies at the version read.
+type Order struct {
+	ID               int         `json:"-" db:"id"`
+	OrderID          string      `json:"order_id" db:"order_id"`
+	UserID           int         `json:"user_id" db:"user_id"`
+	OrganizationID   *int        `json:"organization_id,omitempty" db:"organization_id"`
+	Amount           float64     `json:"amount" db:"amount"`
+	Currency         string      `json:"currency" db:"currency"`
+	Status           string      `json:"status" db:"status"`
+	PaymentID        string      `json:"payment_id" db:"payment_id"`
+	PaymentIntentID  string      `json:"payment_intent_id" db:"payment_intent_id"`
+	CouponCode       string      `json:"coupon_code,omitempty" db:"coupon_code"`
+	DiscountAmount   float64     `json:"discount_amount" db:"discount_amount"`
+	RiskScore        *float64    `json:"-" db:"risk_score"`
+	FlaggedForReview bool        `json:"-" db:"flagged_for_review"`
+	CreatedAt        time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at" db:"updated_at"`
+	Version          int         `json:"version" db:"version"`
+	Items            []OrderItem `json:"items,omitempty" db:"-"`
+}
+
+// OrderItem represents a line item of an order.
+type OrderItem struct {
+	ID        int     `json:"-" db:"id"`
+	ProductID string  `json:"product_id" db:"product_id" binding:"required,max=64"`
+	Name      string  `json:"name,omitempty" db:"name" binding:"max=127"`
+	Quantity  int     `json:"quantity" db:"quantity" binding:"required,gt=0"`
+	UnitPrice float64 `json:"unit_price" db:"unit_price" binding:"gte=0"`
+}
+
+// OrderTotal computes the total of the items, summing in cents to avoid
+// floating point drift.
+func OrderTotal(items []OrderItem) float64 {
+	var cents int64
+	for _, item := range items {
+		cents += int64(math.Round(item.UnitPrice*100)) * int64(item.Quantity)
+	}
+	return float64(cents) / 100
+}
+
+// CanTransitionTo reports whether the order state machine allows moving to status.
+func (o *Order) CanTransitionTo(status string) bool {
+	for _, next := range orderTransitions[o.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// CanReach reports whether the order can still move to status, directly or
+// through other statuses.
+func (o *Order) CanReach(status string) bool {
+	seen := map[string]bool{o.Status: true}
+	queue := []string{o.Status}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range orderTransitions[current] {
+			if next == status {
+				return true
+			}
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+// OrderFilter represents the criteria for listing a user's orders. A non-zero
+// OrganizationID lists the orders of the organization instead.
+type OrderFilter struct {
+	UserID         int
+	OrganizationID int
+	Status         string
+	From           *time.Time
+	To             *time.Time
+	MinAmount      *float64
+	MaxAmount      *float64
+	Page           int
+	PageSize       int
+}
 
 // Order related entities for use case integration
+// CreateOrderRequest carries the order line items. The total is computed on the
+// server after applying any coupon; when Amount is sent it must match it.
 type CreateOrderRequest struct {
-	OrderID   string  `json:"order_id" binding:"required"`
-	UserID    int     `json:"user_id" binding:"required"`
-	Amount    float64 `json:"amount" binding:"required,gt=0"`
-	Currency  string  `json:"currency" binding:"required"`
-	UserEmail string  `json:"user_email" binding:"required,email"`
+	OrderID    string      `json:"order_id" binding:"required"`
+	UserID     int         `json:"user_id" binding:"required"`
+	Items      []OrderItem `json:"items" binding:"required,min=1,dive"`
+	Amount     float64     `json:"amount" binding:"gte=0"`
+	Currency   string      `json:"currency" binding:"required"`
+	UserEmail  string      `json:"user_email" binding:"required,email"`
+	CouponCode string      `json:"coupon_code,omitempty"`
+	// DryRun validates and prices the order and simulates its payment
+	// without persisting or charging it
+	DryRun    bool   `json:"dry_run,omitempty"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 type OrderResponse struct {
-	OrderID         string    `json:"order_id"`
-	PaymentID       string    `json:"payment_id"`
-	PaymentIntentID string    `json:"payment_intent_id"`
-	Status          string    `json:"status"`
-	Amount          float64   `json:"amount"`
-	Currency        string    `json:"currency"`
-	ProcessedAt     time.Time `json:"processed_at"`
-	User            *User     `json:"user"`
+	OrderID         string       `json:"order_id"`
+	PaymentID       string       `json:"payment_id"`
+	PaymentIntentID string       `json:"payment_intent_id"`
+	Status          string       `json:"status"`
+	Amount          float64      `json:"amount"`
+	Currency        string       `json:"currency"`
+	CouponCode      string       `json:"coupon_code,omitempty"`
+	DiscountAmount  float64      `json:"discount_amount"`
+	Items           []OrderItem  `json:"items"`
+	ProcessedAt     time.Time    `json:"processed_at"`
+	User            *User        `json:"user"`
+	DryRun          *OrderDryRun `json:"dry_run,omitempty"`
+}
+
+// OrderDryRun describes what processing an order would do. The order keeps
+// the status it would reach, with the IDs of the simulated payment.
+type OrderDryRun struct {
+	Subtotal         float64         `json:"subtotal"`
+	DiscountAmount   float64         `json:"discount_amount"`
+	Total            float64         `json:"total"`
+	RiskScore        *float64        `json:"risk_score,omitempty"`
+	FlaggedForReview bool            `json:"flagged_for_review"`
+	ProviderCalls    []SimulatedCall `json:"provider_calls"`
+}
+
+// SimulatedCall is a provider call made to the mock provider in place of the
+// real one
+type SimulatedCall struct {
+	Provider  string      `json:"provider"`
+	Operation string      `json:"operation"`
+	Request   interface{} `json:"request"`
+	Response  interface{} `json:"response"`
+}
+
+// OrderCancellation represents the outcome of cancelling an order. Action is
+// "voided", "voided_locally" when the provider has no void call and the
+// uncaptured payment is left to expire, or "refunded".
+type OrderCancellation struct {
+	Order    *Order `json:"order"`
+	Action   string `json:"action"`
+	RefundID string `json:"refund_id,omitempty"`
 }
 
 type RefundOrderRequest struct {
 	PaymentID string `json:"payment_id" binding:"required"`
-	UserID    int    `json:"user_id" binding:"required"`
+	UserID    int    `json:"-"`
 	Reason    string `json:"reason,omitempty"`
 }
+
+// Payment webhook events
+const (
+	PaymentEventRefunded = "payment.refunded"
+)
+
+// PaymentEvent is a payment notification delivered to the payment webhook.
+// ID is the provider's event ID, such as the Stripe event ID or the PayPal
+// transmission ID; events delivered again with the same ID are not applied
+// twice.
+type PaymentEvent struct {
+	ID        string `json:"id,omitempty"`
+	Event     string `json:"event" binding:"required"`
+	PaymentID string `json:"payment_id" binding:"required"`
+	RefundID  string `json:"refund_id,omitempty"`
+}
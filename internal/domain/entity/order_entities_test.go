@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderTotal(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []OrderItem
+		expected float64
+	}{
+		{name: "no items", items: nil, expected: 0},
+		{
+			name:     "quantities are multiplied",
+			items:    []OrderItem{{UnitPrice: 19.99, Quantity: 2}, {UnitPrice: 5, Quantity: 1}},
+			expected: 44.98,
+		},
+		{
+			name:     "sums in cents without float drift",
+			items:    []OrderItem{{UnitPrice: 0.1, Quantity: 1}, {UnitPrice: 0.2, Quantity: 1}},
+			expected: 0.3,
+		},
+		{
+			name:     "many small items",
+			items:    []OrderItem{{UnitPrice: 0.01, Quantity: 1000}},
+			expected: 10,
+		},
+		{
+			name:     "sub-cent prices are rounded per unit",
+			items:    []OrderItem{{UnitPrice: 1.006, Quantity: 3}, {UnitPrice: 2.994, Quantity: 1}},
+			expected: 6.02,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, OrderTotal(tt.items))
+		})
+	}
+}
+
+func TestOrder_CanTransitionTo(t *testing.T) {
+	order := &Order{Status: OrderStatusPending}
+	assert.True(t, order.CanTransitionTo(OrderStatusProcessing))
+	assert.False(t, order.CanTransitionTo(OrderStatusRefunded))
+
+	order.Status = OrderStatusCompleted
+	assert.True(t, order.CanTransitionTo(OrderStatusRefunded))
+	assert.False(t, order.CanTransitionTo(OrderStatusCancelled))
+}
+
+func TestOrder_CanReach(t *testing.T) {
+	order := &Order{Status: OrderStatusPaymentPending}
+	assert.True(t, order.CanReach(OrderStatusRefunded))
+	assert.True(t, order.CanReach(OrderStatusProcessing))
+
+	order.Status = OrderStatusFailed
+	assert.False(t, order.CanReach(OrderStatusRefunded))
+
+	order.Status = OrderStatusRefunded
+	assert.False(t, order.CanReach(OrderStatusRefunded))
+}
@@ -0,0 +1,129 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+// Organization member roles. Owners manage everything, admins manage members
+// and invitations below owner, members share the organization's orders and
+// files.
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// orgRoleRanks orders the roles by the access they grant
+var orgRoleRanks = map[string]int{
+	OrgRoleMember: 1,
+	OrgRoleAdmin:  2,
+	OrgRoleOwner:  3,
+}
+
+// ValidOrgRole reports whether the role is an organization member role
+func ValidOrgRole(role string) bool {
+	_, ok := orgRoleRanks[role]
+	return ok
+}
+
+// OrgRoleAtLeast reports whether the role grants at least the access of min
+func OrgRoleAtLeast(role, min string) bool {
+	return orgRoleRanks[role] >= orgRoleRanks[min]
+}
+
+// Organization groups users sharing orders and files. Role is the role of the
+// user it was loaded for.
+type Organization struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedBy int       `json:"created_by" db:"created_by"`
+	Role      string    `json:"role,omitempty" db:"-"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Membership is a user's role in an organization
+type Membership struct {
+	OrganizationID int       `json:"organization_id" db:"organization_id"`
+	UserID         int       `json:"user_id" db:"user_id"`
+	Username       string    `json:"username,omitempty" db:"username"`
+	Email          string    `json:"email,omitempty" db:"email"`
+	Role           string    `json:"role" db:"role"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Invitation asks the owner of an email address to join an organization. It
+// is accepted with the emailed token, of which only a hash is kept.
+type Invitation struct {
+	ID             int        `json:"id" db:"id"`
+	OrganizationID int        `json:"organization_id" db:"organization_id"`
+	Email          string     `json:"email" db:"email"`
+	Role           string     `json:"role" db:"role"`
+	TokenHash      string     `json:"-" db:"token_hash"`
+	InvitedBy      int        `json:"invited_by" db:"invited_by"`
+	ExpiresAt      time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CreateOrganizationRequest represents the payload to create an organization.
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// InviteMemberRequest represents the payload to invite a member by email.
+type InviteMemberRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+	Role  string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+// InvitationLink carries the query parameters of an emailed invitation link:
+// the token, and its expiry and signature when links are signed.
+type InvitationLink struct {
+	Token     string `json:"token" binding:"required"`
+	Expires   int64  `json:"expires,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// AcceptInvitationRequest carries the link of an emailed invitation.
+type AcceptInvitationRequest struct {
+	InvitationLink
+}
+
+// UpdateMemberRequest represents the payload to change a member's role.
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+// OrgContext is the organization a request acts in and the caller's role in it
+type OrgContext struct {
+	ID   int
+	Role string
+}
+
+type orgContextKey struct{}
+
+// ContextWithOrganization attaches the organization of a request to its context
+func ContextWithOrganization(ctx context.Context, org *OrgContext) context.Context {
+	return context.WithValue(ctx, orgContextKey{}, org)
+}
+
+// OrganizationFromContext returns the organization attached to the context,
+// or nil for requests made outside any organization
+func OrganizationFromContext(ctx context.Context) *OrgContext {
+	org, _ := ctx.Value(orgContextKey{}).(*OrgContext)
+	return org
+}
+
+// SharedWith reports whether a record of ownerID, made in the organization
+// orgID when set, is visible to userID: to its owner, and to every member
+// acting in the organization through the context.
+func SharedWith(ctx context.Context, userID, ownerID int, orgID *int) bool {
+	if ownerID == userID {
+		return true
+	}
+	org := OrganizationFromContext(ctx)
+	return org != nil && orgID != nil && *orgID == org.ID
+}
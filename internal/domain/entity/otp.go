@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// OTP purposes, each with its own code per phone number
+const (
+	OTPPurposeLogin             = "login"
+	OTPPurposePhoneVerification = "phone_verification"
+	OTPPurposeLoginVerification = "login_verification"
+)
+
+// OTPCode is a one-time code sent by SMS. Only a hash of the code is stored.
+type OTPCode struct {
+	ID        int       `json:"id" db:"id"`
+	Purpose   string    `json:"purpose" db:"purpose"`
+	Phone     string    `json:"phone" db:"phone"`
+	CodeHash  string    `json:"-" db:"code_hash"`
+	Attempts  int       `json:"attempts" db:"attempts"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
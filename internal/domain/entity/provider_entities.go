@@ -1,6 +1,9 @@
 package entity
 
-import "time"
+import (
+	"io"
+	"time"
+)
 
 // Payment related entities
 type PaymentRequest struct {
@@ -9,9 +12,19 @@ type PaymentRequest struct {
 	Currency    string                 `json:"currency"`
 	Description string                 `json:"description"`
 	CustomerID  string                 `json:"customer_id"`
+	Items       []LineItem             `json:"items,omitempty"`
+	Discount    float64                `json:"discount,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// LineItem is an itemized entry of a payment
+type LineItem struct {
+	SKU        string  `json:"sku"`
+	Name       string  `json:"name"`
+	Quantity   int     `json:"quantity"`
+	UnitAmount float64 `json:"unit_amount"`
+}
+
 type PaymentResponse struct {
 	ID            string                 `json:"id"`
 	Status        string                 `json:"status"`
@@ -50,6 +63,20 @@ type PaymentIntent struct {
 	Status       string `json:"status"`
 }
 
+// PaymentIntentStatusLocallyVoided is reported when a provider has no void call
+// and the intent is only abandoned on our side, left to expire uncaptured.
+const PaymentIntentStatusLocallyVoided = "locally_voided"
+
+// NotificationPriority ranks notifications competing for delivery.
+// Security notifications outrank transactional ones, which outrank marketing.
+type NotificationPriority string
+
+const (
+	NotificationPrioritySecurity      NotificationPriority = "security"
+	NotificationPriorityTransactional NotificationPriority = "transactional"
+	NotificationPriorityMarketing     NotificationPriority = "marketing"
+)
+
 // Notification related entities
 type EmailRequest struct {
 	To          []string               `json:"to"`
@@ -60,6 +87,7 @@ type EmailRequest struct {
 	BodyHTML    string                 `json:"body_html,omitempty"`
 	Attachments []EmailAttachment      `json:"attachments,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Priority    NotificationPriority   `json:"priority,omitempty"`
 }
 
 type EmailResponse struct {
@@ -88,6 +116,24 @@ type SMSResponse struct {
 	MessageID string    `json:"message_id"`
 }
 
+// Message types of the SMS and push message catalog
+const (
+	MessageTypeOTP          = "otp"
+	MessageTypeOrderShipped = "order_shipped"
+)
+
+// TemplatedMessage is a catalog message rendered for the recipient's locale,
+// with times shown in the recipient's timezone.
+// To is the phone number for SMS; DeviceTokens address push notifications.
+type TemplatedMessage struct {
+	Type         string                 `json:"type"`
+	Locale       string                 `json:"locale,omitempty"`
+	Timezone     string                 `json:"timezone,omitempty"`
+	Data         map[string]interface{} `json:"data,omitempty"`
+	To           string                 `json:"to,omitempty"`
+	DeviceTokens []string               `json:"device_tokens,omitempty"`
+}
+
 type PushNotificationRequest struct {
 	DeviceTokens []string               `json:"device_tokens"`
 	Title        string                 `json:"title"`
@@ -173,12 +219,14 @@ type DistanceInfo struct {
 }
 
 // File storage related entities
+// The stored file ID is Path/FileName. CacheControl, when set, is served with the file.
 type FileUploadRequest struct {
-	FileName    string            `json:"file_name"`
-	Content     []byte            `json:"content"`
-	ContentType string            `json:"content_type"`
-	Path        string            `json:"path,omitempty"`
-	Metadata    map[string]string `json:"metadata,omitempty"`
+	FileName     string            `json:"file_name"`
+	Content      []byte            `json:"content"`
+	ContentType  string            `json:"content_type"`
+	Path         string            `json:"path,omitempty"`
+	CacheControl string            `json:"cache_control,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
 }
 
 type FileUploadResponse struct {
@@ -190,6 +238,35 @@ type FileUploadResponse struct {
 	UploadedAt time.Time `json:"uploaded_at"`
 }
 
+// MultipartUploadRequest starts a file assembled from separately uploaded parts.
+type MultipartUploadRequest struct {
+	FileName     string            `json:"file_name"`
+	ContentType  string            `json:"content_type"`
+	Path         string            `json:"path,omitempty"`
+	CacheControl string            `json:"cache_control,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
+
+type MultipartUpload struct {
+	FileID   string `json:"file_id"`
+	UploadID string `json:"upload_id"`
+}
+
+// FilePartRequest uploads one part of a multipart upload. Content is read to
+// the end; uploading a part number again replaces the earlier part.
+type FilePartRequest struct {
+	FileID     string    `json:"file_id"`
+	UploadID   string    `json:"upload_id"`
+	PartNumber int       `json:"part_number"`
+	Content    io.Reader `json:"-"`
+}
+
+type FilePart struct {
+	PartNumber int    `json:"part_number"`
+	ETag       string `json:"etag"`
+	Size       int64  `json:"size"`
+}
+
 type FileDownloadResponse struct {
 	ID          string            `json:"id"`
 	FileName    string            `json:"file_name"`
@@ -210,3 +287,43 @@ type FileInfo struct {
 	UploadedAt  time.Time         `json:"uploaded_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
 }
+
+// Malware scanning related entities
+type ScanResult struct {
+	Clean     bool      `json:"clean"`
+	Threat    string    `json:"threat,omitempty"`
+	Engine    string    `json:"engine"`
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+// CAPTCHA related entities
+type CaptchaVerification struct {
+	Success     bool      `json:"success"`
+	Hostname    string    `json:"hostname"`
+	ChallengeTS time.Time `json:"challenge_ts"`
+	Score       float64   `json:"score,omitempty"`
+	ErrorCodes  []string  `json:"error_codes,omitempty"`
+}
+
+// Fraud screening related entities
+type FraudCheckRequest struct {
+	OrderID          string        `json:"order_id"`
+	UserID           int           `json:"user_id"`
+	UserEmail        string        `json:"user_email"`
+	AccountCreatedAt time.Time     `json:"account_created_at"`
+	Amount           float64       `json:"amount"`
+	Currency         string        `json:"currency"`
+	ItemCount        int           `json:"item_count"`
+	CouponCode       string        `json:"coupon_code,omitempty"`
+	IPAddress        string        `json:"ip_address,omitempty"`
+	UserAgent        string        `json:"user_agent,omitempty"`
+	Device           string        `json:"device,omitempty"`
+	Location         *LocationInfo `json:"location,omitempty"`
+}
+
+// FraudAssessment is a risk score from 0 (safe) to 100 (certain fraud) with the reasons behind it
+type FraudAssessment struct {
+	Provider string   `json:"provider"`
+	Score    float64  `json:"score"`
+	Reasons  []string `json:"reasons,omitempty"`
+}
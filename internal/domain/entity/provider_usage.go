@@ -0,0 +1,19 @@
+package entity
+
+// Paid providers tracked against a daily budget
+const (
+	BudgetPayment = "payment"
+	BudgetSMS     = "sms"
+	BudgetEmail   = "email"
+)
+
+// ProviderUsage is the number of calls made to a paid provider on one UTC
+// day and their cost-equivalent, with the daily budget; a zero budget is
+// unlimited
+type ProviderUsage struct {
+	Provider string  `json:"provider"`
+	Day      string  `json:"day"`
+	Calls    int64   `json:"calls"`
+	Cost     float64 `json:"cost"`
+	Budget   float64 `json:"budget"`
+}
@@ -0,0 +1,58 @@
+package entity
+
+// Report groupings. Weeks start on Monday.
+const (
+	ReportGroupDay   = "day"
+	ReportGroupWeek  = "week"
+	ReportGroupMonth = "month"
+)
+
+// ReportQuery selects the days of a report, as YYYY-MM-DD in UTC with both
+// ends included, and how they are grouped.
+type ReportQuery struct {
+	From    string `form:"from"`
+	To      string `form:"to"`
+	GroupBy string `form:"group_by"`
+}
+
+// RevenueReportRow aggregates the orders placed in a period in one currency.
+// Period is the first day of the period.
+type RevenueReportRow struct {
+	Period         string  `json:"period"`
+	Currency       string  `json:"currency"`
+	Orders         int     `json:"orders"`
+	Completed      int     `json:"completed"`
+	Refunded       int     `json:"refunded"`
+	Revenue        float64 `json:"revenue"`
+	RefundedAmount float64 `json:"refunded_amount"`
+}
+
+// RevenueReport is the revenue of the days from From through To.
+type RevenueReport struct {
+	From    string             `json:"from"`
+	To      string             `json:"to"`
+	GroupBy string             `json:"group_by"`
+	Rows    []RevenueReportRow `json:"rows"`
+}
+
+// SignupReportRow counts the users who signed up in a period.
+type SignupReportRow struct {
+	Period  string `json:"period"`
+	Signups int    `json:"signups"`
+}
+
+// SignupReport is the sign-ups of the days from From through To.
+type SignupReport struct {
+	From    string            `json:"from"`
+	To      string            `json:"to"`
+	GroupBy string            `json:"group_by"`
+	Rows    []SignupReportRow `json:"rows"`
+}
+
+// ReportRollupRequest is the payload to rebuild the reporting tables for the
+// days from From through To, as YYYY-MM-DD in UTC. It is also the payload of
+// the rollup job, where an empty range rolls up the recent days.
+type ReportRollupRequest struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
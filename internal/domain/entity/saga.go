@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+// Saga statuses. A running or compensating saga that stops being updated was
+// interrupted, such as by a crash, and is compensated by recovery.
+const (
+	SagaStatusRunning      = "running"
+	SagaStatusHalted       = "halted"
+	SagaStatusCompleted    = "completed"
+	SagaStatusCompensating = "compensating"
+	SagaStatusCompensated  = "compensated"
+	SagaStatusFailed       = "failed"
+)
+
+// SagaState holds what the compensations of a saga need, such as the IDs of
+// the payment intent and payment it created
+type SagaState map[string]string
+
+// Saga is the persisted progress of a multi-step operation: the steps it
+// completed, in order, and the state their compensations need. A saga whose
+// compensation failed is left failed for manual attention.
+type Saga struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Reference string    `json:"reference"`
+	Status    string    `json:"status"`
+	Steps     []string  `json:"steps"`
+	State     SagaState `json:"state"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,81 @@
+package entity
+
+import "time"
+
+// Scopes granted by access tokens. Routes require one of them, so a token
+// holding only some can call only those routes.
+const (
+	ScopeAdmin         = "admin"
+	ScopeOrdersRead    = "orders:read"
+	ScopeOrdersWrite   = "orders:write"
+	ScopeProfileRead   = "profile:read"
+	ScopeProfileWrite  = "profile:write"
+	ScopeSessions      = "sessions"
+	ScopeUploads       = "uploads"
+	ScopeOrganizations = "organizations"
+	ScopeImpersonate   = "users:impersonate"
+)
+
+// UserScopes are the scopes of every user signing in
+var UserScopes = []string{
+	ScopeOrdersRead,
+	ScopeOrdersWrite,
+	ScopeProfileRead,
+	ScopeProfileWrite,
+	ScopeSessions,
+	ScopeUploads,
+	ScopeOrganizations,
+}
+
+// ImpersonationScopes are the scopes of an impersonation token: those of the
+// user, except managing sessions and tokens, so an impersonation cannot
+// outlive its token, and managing organizations.
+var ImpersonationScopes = []string{
+	ScopeOrdersRead,
+	ScopeOrdersWrite,
+	ScopeProfileRead,
+	ScopeProfileWrite,
+	ScopeUploads,
+}
+
+// KnownScope reports whether the scope is one routes can require
+func KnownScope(scope string) bool {
+	if scope == ScopeAdmin || scope == ScopeImpersonate {
+		return true
+	}
+	for _, s := range UserScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessTokenRequest asks for a token limited to some of the caller's scopes,
+// such as a machine token that may only read orders. ExpiresIn is in seconds;
+// zero uses the login token lifetime.
+type AccessTokenRequest struct {
+	Name      string   `json:"name" binding:"required,max=64"`
+	Scopes    []string `json:"scopes" binding:"required,min=1"`
+	ExpiresIn int      `json:"expires_in,omitempty" binding:"gte=0"`
+}
+
+// AccessTokenResponse is an issued access token. It is a session of its
+// own, listed and revoked with the other sessions of the user.
+type AccessTokenResponse struct {
+	Token     string    `json:"token"`
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ImpersonationResponse is a token acting as the user on behalf of the
+// support staff member who requested it
+type ImpersonationResponse struct {
+	Token          string    `json:"token"`
+	UserID         int       `json:"user_id"`
+	Username       string    `json:"username"`
+	ImpersonatorID int       `json:"impersonator_id"`
+	Scopes         []string  `json:"scopes"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
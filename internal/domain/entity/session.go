@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// Session represents an authenticated login session bound to a token ID.
+type Session struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     int        `json:"user_id" db:"user_id"`
+	TokenID    string     `json:"-" db:"token_id"`
+	Device     string     `json:"device" db:"device"`
+	IPAddress  string     `json:"ip_address" db:"ip_address"`
+	Location   string     `json:"location" db:"location"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at" db:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	Current    bool       `json:"current" db:"-"`
+}
@@ -0,0 +1,44 @@
+package entity
+
+import "time"
+
+// DailyCount is a count for a day, as YYYY-MM-DD in UTC.
+type DailyCount struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// DailyOrderStats aggregates the orders placed on a day in one currency.
+// Revenue is the amount of the completed orders; refunded orders are not
+// counted as revenue.
+type DailyOrderStats struct {
+	Date      string  `json:"date"`
+	Currency  string  `json:"currency"`
+	Orders    int     `json:"orders"`
+	Completed int     `json:"completed"`
+	Refunded  int     `json:"refunded"`
+	Revenue   float64 `json:"revenue"`
+}
+
+// DailyDeliveryStats aggregates the campaign emails sent and failed on a day.
+type DailyDeliveryStats struct {
+	Date   string `json:"date"`
+	Sent   int    `json:"sent"`
+	Failed int    `json:"failed"`
+}
+
+// DashboardStats are the aggregates of the operations dashboard for the days
+// from From through To, in UTC. Days without activity are left out of the
+// daily series. RefundRate is the share of paid orders that were refunded and
+// NotificationDeliveryRate the share of campaign emails sent without error;
+// they are null without orders or emails to compute them from.
+type DashboardStats struct {
+	From                     time.Time            `json:"from"`
+	To                       time.Time            `json:"to"`
+	NewUsers                 []DailyCount         `json:"new_users"`
+	Orders                   []DailyOrderStats    `json:"orders"`
+	Notifications            []DailyDeliveryStats `json:"notifications"`
+	RefundRate               *float64             `json:"refund_rate"`
+	NotificationDeliveryRate *float64             `json:"notification_delivery_rate"`
+	GeneratedAt              time.Time            `json:"generated_at"`
+}
@@ -0,0 +1,100 @@
+package entity
+
+import "time"
+
+// Resumable upload statuses
+const (
+	UploadStatusPending   = "pending"
+	UploadStatusCompleted = "completed"
+	UploadStatusAborted   = "aborted"
+)
+
+// Malware scan statuses of completed uploads. Only clean and skipped files
+// can be downloaded; skipped means no scanner was configured.
+const (
+	ScanStatusSkipped  = "skipped"
+	ScanStatusPending  = "pending"
+	ScanStatusClean    = "clean"
+	ScanStatusInfected = "infected"
+	ScanStatusFailed   = "failed"
+)
+
+// Upload is a file uploaded in fixed size parts that may arrive in any order
+// and be retried, so an interrupted upload resumes with the parts still missing.
+// Every part is PartSize bytes except the last, which holds the remainder.
+// Uploads made in an organization are shared with its members.
+type Upload struct {
+	ID              int          `json:"id" db:"id"`
+	UserID          int          `json:"-" db:"user_id"`
+	OrganizationID  *int         `json:"organization_id,omitempty" db:"organization_id"`
+	FileID          string       `json:"file_id" db:"file_id"`
+	FileName        string       `json:"file_name" db:"file_name"`
+	ContentType     string       `json:"content_type" db:"content_type"`
+	Size            int64        `json:"size" db:"size"`
+	PartSize        int64        `json:"part_size" db:"part_size"`
+	TotalParts      int          `json:"total_parts" db:"total_parts"`
+	StorageUploadID string       `json:"-" db:"storage_upload_id"`
+	Status          string       `json:"status" db:"status"`
+	ScanStatus      string       `json:"scan_status" db:"scan_status"`
+	ScanThreat      string       `json:"scan_threat,omitempty" db:"scan_threat"`
+	ScannedAt       *time.Time   `json:"scanned_at,omitempty" db:"scanned_at"`
+	Parts           []UploadPart `json:"parts"`
+	CreatedAt       time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at" db:"updated_at"`
+	ExpiresAt       time.Time    `json:"expires_at" db:"expires_at"`
+	CompletedAt     *time.Time   `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// UploadPart is a part received for an upload.
+type UploadPart struct {
+	PartNumber int       `json:"part_number" db:"part_number"`
+	Size       int64     `json:"size" db:"size"`
+	ETag       string    `json:"etag" db:"etag"`
+	UploadedAt time.Time `json:"uploaded_at" db:"uploaded_at"`
+}
+
+// InitiateUploadRequest represents the payload to start a resumable upload.
+type InitiateUploadRequest struct {
+	FileName    string `json:"file_name" binding:"required,max=255"`
+	ContentType string `json:"content_type" binding:"max=255"`
+	Size        int64  `json:"size" binding:"required,min=1"`
+}
+
+// UploadScan is the payload of a job scanning a completed upload for malware.
+type UploadScan struct {
+	UploadID int `json:"upload_id"`
+}
+
+// UploadDownloadResponse carries a time limited link to a completed upload.
+type UploadDownloadResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ExpectedPartSize returns the size part n must have.
+func (u *Upload) ExpectedPartSize(n int) int64 {
+	if n == u.TotalParts {
+		return u.Size - int64(n-1)*u.PartSize
+	}
+	return u.PartSize
+}
+
+// Expired reports whether a pending upload ran out of time to complete.
+func (u *Upload) Expired(now time.Time) bool {
+	return u.Status == UploadStatusPending && !now.Before(u.ExpiresAt)
+}
+
+// MissingParts lists the part numbers not yet received, in order.
+func (u *Upload) MissingParts() []int {
+	received := make(map[int]bool, len(u.Parts))
+	for _, part := range u.Parts {
+		received[part.PartNumber] = true
+	}
+	missing := []int{}
+	for n := 1; n <= u.TotalParts; n++ {
+		if !received[n] {
+			missing = append(missing, n)
+		}
+	}
+	return missing
+}
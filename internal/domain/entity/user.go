@@ -2,31 +2,249 @@ package entity
 
 import "time"
 
+// User roles. Support staff are admins who may also impersonate users.
+const (
+	RoleUser    = "user"
+	RoleAdmin   = "admin"
+	RoleSupport = "support"
+)
+
 // User represents a user entity in the system.
+// PendingEmail is the address the user asked to change Email to, until confirmed.
+// Phone is only usable for OTP login once PhoneVerifiedAt is set.
+// Locale and Timezone personalize notifications; empty means the service defaults.
+// LoginAlertsDisabled opts out of emails about sign-ins from new devices or locations.
+// ExternalID identifies users provisioned by an identity provider sync.
+// Deactivated users, with DeactivatedAt set, cannot sign in.
+// Version is bumped by every update, which only applies at the version read.
+// UpdatedBy is the user who last updated it, if any; see UserChange.
 type User struct {
-	ID        int       `json:"id" db:"id"`
-	Username  string    `json:"username" db:"username"`
-	Email     string    `json:"email" db:"email"`
-	Password  string    `json:"-" db:"password"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID                  int        `json:"id" db:"id"`
+	Username            string     `json:"username" db:"username"`
+	Email               string     `json:"email" db:"email"`
+	PendingEmail        string     `json:"pending_email,omitempty" db:"pending_email"`
+	ExternalID          string     `json:"external_id,omitempty" db:"external_id"`
+	Password            string     `json:"-" db:"password"`
+	Role                string     `json:"role" db:"role"`
+	FirstName           string     `json:"first_name,omitempty" db:"first_name"`
+	LastName            string     `json:"last_name,omitempty" db:"last_name"`
+	Phone               string     `json:"phone,omitempty" db:"phone"`
+	PhoneVerifiedAt     *time.Time `json:"phone_verified_at,omitempty" db:"phone_verified_at"`
+	AvatarURL           string     `json:"avatar_url,omitempty" db:"avatar_url"`
+	Locale              string     `json:"locale,omitempty" db:"locale"`
+	Timezone            string     `json:"timezone,omitempty" db:"timezone"`
+	LoginAlertsDisabled bool       `json:"login_alerts_disabled" db:"login_alerts_disabled"`
+	DeactivatedAt       *time.Time `json:"deactivated_at,omitempty" db:"deactivated_at"`
+	Version             int        `json:"version" db:"version"`
+	UpdatedBy           int        `json:"updated_by,omitempty" db:"updated_by"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// DisplayName returns the name to address the user by: the first name when set,
+// otherwise the username.
+func (u *User) DisplayName() string {
+	if u.FirstName != "" {
+		return u.FirstName
+	}
+	return u.Username
+}
+
+// UserFilter selects a page of users, Limit users after the first Offset ones.
+// Username, Email and ExternalID match exactly, the username and email
+// case-insensitively; empty ones match every user.
+type UserFilter struct {
+	Username   string
+	Email      string
+	ExternalID string
+	Offset     int
+	Limit      int
+}
+
+// UpdateProfileRequest represents the payload to update the user's profile.
+// Omitted fields are left unchanged; an empty string clears a field.
+// A new Email only becomes pending, until confirmed from the new address;
+// the current one cancels a pending change.
+// A Version makes the update fail unless the profile is still at it.
+type UpdateProfileRequest struct {
+	Email               *string `json:"email,omitempty" binding:"omitempty,email,max=100"`
+	FirstName           *string `json:"first_name,omitempty" binding:"omitempty,max=100"`
+	LastName            *string `json:"last_name,omitempty" binding:"omitempty,max=100"`
+	AvatarURL           *string `json:"avatar_url,omitempty" binding:"omitempty,max=2048"`
+	Locale              *string `json:"locale,omitempty" binding:"omitempty,max=35"`
+	Timezone            *string `json:"timezone,omitempty" binding:"omitempty,max=64"`
+	LoginAlertsDisabled *bool   `json:"login_alerts_disabled,omitempty"`
+	Version             *int    `json:"version,omitempty"`
 }
 
-// LoginRequest represents the login request payload.
+// LoginRequest represents the login request payload. VerificationCode is the
+// code texted when a sign-in from a new device or location must be verified.
 type LoginRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Username         string `json:"username" binding:"required"`
+	Password         string `json:"password" binding:"required"`
+	Captcha          string `json:"captcha_token,omitempty"`
+	VerificationCode string `json:"verification_code,omitempty"`
+	IPAddress        string `json:"-"`
+	UserAgent        string `json:"-"`
 }
 
 // RegisterRequest represents the registration request payload.
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
+	Captcha  string `json:"captcha_token,omitempty"`
+}
+
+// InvitedRegisterRequest represents the payload to sign up with an emailed
+// invitation. The account gets the invited email address.
+type InvitedRegisterRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	InvitationLink
+}
+
+// InvitedRegistration is the account created with an invitation and the
+// organization it joined. JoinError is set instead of Organization when the
+// account was created but could not join; the invitation stays pending and
+// is accepted with POST /api/v1/organizations/invitations/accept once signed in.
+type InvitedRegistration struct {
+	User         *User         `json:"user"`
+	Organization *Organization `json:"organization,omitempty"`
+	JoinError    string        `json:"join_error,omitempty"`
+}
+
+// ProvisionUserRequest represents the payload to create or update a user by
+// email. Password only applies to new users.
+type ProvisionUserRequest struct {
+	Email     string `json:"email" binding:"required,email,max=100"`
+	Username  string `json:"username" binding:"required,max=50"`
+	FirstName string `json:"first_name,omitempty" binding:"omitempty,max=100"`
+	LastName  string `json:"last_name,omitempty" binding:"omitempty,max=100"`
+	Password  string `json:"password,omitempty"`
+}
+
+// DirectoryUser is a user as an identity provider manages it through SCIM
+// provisioning. Password is optional; an inactive user is deactivated.
+type DirectoryUser struct {
+	Username  string
+	Email     string
+	FirstName string
+	LastName  string
+	Password  string
+	Active    bool
+}
+
+// OTPRequest represents the payload to request a one-time login code by SMS.
+type OTPRequest struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+// OTPVerifyRequest represents the payload to log in with a one-time code.
+type OTPVerifyRequest struct {
+	Phone     string `json:"phone" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// PhoneRequest represents the payload to set the user's phone number.
+type PhoneRequest struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+// PhoneVerifyRequest represents the payload to confirm the user's phone number.
+type PhoneVerifyRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// ConfirmEmailRequest represents the payload to confirm an email address
+// change with the token of the link emailed to the new address.
+type ConfirmEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ChangePasswordRequest represents the payload to change the user's password.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
 }
 
 // LoginResponse represents the login response payload.
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token string `json:"token,omitempty"`
 	User  *User  `json:"user"`
 }
+
+// CSRFTokenResponse holds the CSRF token browser clients authenticated with
+// cookies send in the X-CSRF-Token header
+type CSRFTokenResponse struct {
+	CSRFToken string `json:"csrf_token"`
+}
+
+// AvatarUploadResponse holds the URLs of an uploaded avatar. AvatarURL, the
+// largest rendition, is saved on the profile; Sizes maps each edge length in
+// pixels to the URL of that square rendition.
+type AvatarUploadResponse struct {
+	AvatarURL string            `json:"avatar_url"`
+	Sizes     map[string]string `json:"sizes"`
+}
+
+// UserDataExport represents the personal data held for a user, written to
+// the archive of a data export request.
+type UserDataExport struct {
+	Profile       *User                   `json:"profile"`
+	Orders        []*Order                `json:"orders"`
+	Notifications []*ReceivedNotification `json:"notifications"`
+	Sessions      []*Session              `json:"sessions"`
+	Uploads       []*Upload               `json:"uploads"`
+	Consents      []*Consent              `json:"consents"`
+	Changes       []*UserChange           `json:"changes"`
+	Memberships   []*Organization         `json:"memberships"`
+	ExportedAt    time.Time               `json:"exported_at"`
+}
+
+// DataExportResponse represents the acknowledgement of a data export
+// request. The download link is emailed once the archive is ready.
+type DataExportResponse struct {
+	UserID      int       `json:"user_id"`
+	Status      string    `json:"status"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// AccountDeletionResponse represents the acknowledgement of an erasure request.
+type AccountDeletionResponse struct {
+	UserID      int       `json:"user_id"`
+	Status      string    `json:"status"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// UserImportRow represents a single user record in a bulk import file.
+type UserImportRow struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// UserImportRowResult represents the outcome of importing a single row.
+type UserImportRowResult struct {
+	Row      int    `json:"row"`
+	Username string `json:"username"`
+	Status   string `json:"status"`
+	UserID   int    `json:"user_id,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// UserImportReport represents the per-row result report of a bulk import.
+// Imports run in the background carry the ID under which the report can be fetched.
+type UserImportReport struct {
+	ID          int                   `json:"id,omitempty"`
+	Status      string                `json:"status"`
+	Total       int                   `json:"total"`
+	Created     int                   `json:"created"`
+	Failed      int                   `json:"failed"`
+	Error       string                `json:"error,omitempty"`
+	Results     []UserImportRowResult `json:"results,omitempty"`
+	CreatedAt   *time.Time            `json:"created_at,omitempty"`
+	CompletedAt *time.Time            `json:"completed_at,omitempty"`
+}
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+// Sources of user changes: requests of a signed-in user, SCIM provisioning
+// by an identity provider, and everything else such as sign-up, single
+// sign-on and background jobs.
+const (
+	ChangeSourceAPI    = "api"
+	ChangeSourceSCIM   = "scim"
+	ChangeSourceSystem = "system"
+)
+
+// Actor is who makes the changes of a request. ImpersonatorID is the support
+// user acting as UserID, if any.
+type Actor struct {
+	UserID         int
+	ImpersonatorID int
+	Source         string
+}
+
+type actorKey struct{}
+
+// ContextWithActor attaches who acts in a request to its context
+func ContextWithActor(ctx context.Context, actor *Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns who acts through the context; changes made
+// outside any request come from the system
+func ActorFromContext(ctx context.Context) *Actor {
+	if actor, ok := ctx.Value(actorKey{}).(*Actor); ok {
+		return actor
+	}
+	return &Actor{Source: ChangeSourceSystem}
+}
+
+// FieldChange is a user field changed from one value to another. Values of
+// the password, and of users since erased, are left out.
+type FieldChange struct {
+	Field string  `json:"field"`
+	From  *string `json:"from,omitempty"`
+	To    *string `json:"to,omitempty"`
+}
+
+// UserChange is the change history record of one update of a user
+type UserChange struct {
+	ID             int64         `json:"id"`
+	UserID         int           `json:"user_id"`
+	ChangedBy      int           `json:"changed_by,omitempty"`
+	ImpersonatorID int           `json:"impersonator_id,omitempty"`
+	Source         string        `json:"source"`
+	Changes        []FieldChange `json:"changes"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// UserChangeFilter selects a page of a user's changes, those of Field only
+// when set
+type UserChangeFilter struct {
+	UserID   int
+	Field    string
+	Page     int
+	PageSize int
+}
@@ -0,0 +1,11 @@
+package provider
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+)
+
+// CaptchaProvider defines the contract for CAPTCHA token verification
+type CaptchaProvider interface {
+	VerifyToken(ctx context.Context, token, remoteIP string) (*entity.CaptchaVerification, error)
+}
@@ -3,6 +3,8 @@ package provider
 import (
 	"boilerplate-go/internal/domain/entity"
 	"context"
+	"io"
+	"time"
 )
 
 // UserServiceProvider defines the contract for external user service operations
@@ -24,4 +26,26 @@ type FileStorageProvider interface {
 	DownloadFile(ctx context.Context, fileID string) (*entity.FileDownloadResponse, error)
 	DeleteFile(ctx context.Context, fileID string) error
 	GetFileInfo(ctx context.Context, fileID string) (*entity.FileInfo, error)
+	// GetSignedURL returns a URL that downloads the file directly from storage
+	// without authentication until ttl has passed.
+	GetSignedURL(ctx context.Context, fileID string, ttl time.Duration) (string, error)
+	// OpenFile streams the file's content; the caller closes the reader.
+	OpenFile(ctx context.Context, fileID string) (io.ReadCloser, error)
+	// MoveFile moves a file to a new ID, replacing any file there.
+	MoveFile(ctx context.Context, fileID, newFileID string) error
+
+	// Multipart uploads assemble a file from parts uploaded separately, so
+	// large files are never held in memory whole. The file appears only once
+	// the upload is completed; an aborted upload discards its parts.
+	CreateMultipartUpload(ctx context.Context, req *entity.MultipartUploadRequest) (*entity.MultipartUpload, error)
+	UploadPart(ctx context.Context, req *entity.FilePartRequest) (*entity.FilePart, error)
+	CompleteMultipartUpload(ctx context.Context, fileID, uploadID string, parts []entity.FilePart) (*entity.FileUploadResponse, error)
+	AbortMultipartUpload(ctx context.Context, fileID, uploadID string) error
+}
+
+// ScannerProvider defines the contract for malware scanning services
+type ScannerProvider interface {
+	// ScanFile reads content to the end and reports whether it is infected.
+	// An error means the content could not be scanned, not that it is unsafe.
+	ScanFile(ctx context.Context, content io.Reader) (*entity.ScanResult, error)
 }
@@ -0,0 +1,11 @@
+package provider
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+)
+
+// FraudProvider defines the contract for scoring the fraud risk of an order
+type FraudProvider interface {
+	ScoreOrder(ctx context.Context, req *entity.FraudCheckRequest) (*entity.FraudAssessment, error)
+}
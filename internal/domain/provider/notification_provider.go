@@ -10,6 +10,9 @@ type NotificationProvider interface {
 	SendEmail(ctx context.Context, req *entity.EmailRequest) (*entity.EmailResponse, error)
 	SendSMS(ctx context.Context, req *entity.SMSRequest) (*entity.SMSResponse, error)
 	SendPushNotification(ctx context.Context, req *entity.PushNotificationRequest) (*entity.PushNotificationResponse, error)
+	// SendTemplatedSMS and SendTemplatedPush render a catalog message before sending it
+	SendTemplatedSMS(ctx context.Context, msg *entity.TemplatedMessage) (*entity.SMSResponse, error)
+	SendTemplatedPush(ctx context.Context, msg *entity.TemplatedMessage) (*entity.PushNotificationResponse, error)
 }
 
 // EmailProvider defines specific email operations
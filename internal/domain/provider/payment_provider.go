@@ -11,4 +11,5 @@ type PaymentProvider interface {
 	RefundPayment(ctx context.Context, paymentID string) (*entity.RefundResponse, error)
 	GetPaymentStatus(ctx context.Context, paymentID string) (*entity.PaymentStatus, error)
 	CreatePaymentIntent(ctx context.Context, req *entity.PaymentIntentRequest) (*entity.PaymentIntent, error)
+	CancelPaymentIntent(ctx context.Context, intentID string) (*entity.PaymentIntent, error)
 }
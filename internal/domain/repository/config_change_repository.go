@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+)
+
+// ConfigChangeRepository defines the audit trail of provider profiles
+// applied at runtime.
+type ConfigChangeRepository interface {
+	Create(ctx context.Context, change *entity.ConfigChange) error
+	List(ctx context.Context, filter entity.ConfigChangeFilter) ([]*entity.ConfigChange, int, error)
+}
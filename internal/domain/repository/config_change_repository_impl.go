@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// configChangeRepositoryImpl implements the ConfigChangeRepository interface
+type configChangeRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewConfigChangeRepository creates a new config change repository implementation
+func NewConfigChangeRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) ConfigChangeRepository {
+	return &configChangeRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func (r *configChangeRepositoryImpl) Create(ctx context.Context, change *entity.ConfigChange) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "config_changes"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	changes, err := json.Marshal(change.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode config changes: %w", err)
+	}
+
+	query := `
+		INSERT INTO config_changes (profile, changes, changed_by, created_at)
+		VALUES ($1, $2, NULLIF($3, 0), $4)
+		RETURNING id`
+
+	change.CreatedAt = time.Now()
+	err = r.db.DB.QueryRowContext(ctx, query, change.Profile, changes, change.ChangedBy, change.CreatedAt).Scan(&change.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create config change", map[string]interface{}{
+			"profile": change.Profile,
+		})
+		return fmt.Errorf("failed to create config change: %w", err)
+	}
+
+	return nil
+}
+
+// List returns one page of configuration changes, newest first, together
+// with the total number of changes.
+func (r *configChangeRepositoryImpl) List(ctx context.Context, filter entity.ConfigChangeFilter) ([]*entity.ConfigChange, int, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "config_changes"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var total int
+	err := r.db.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM config_changes`).Scan(&total)
+
+	changes := make([]*entity.ConfigChange, 0)
+	if err == nil && total > 0 {
+		query := `
+			SELECT id, profile, changes, COALESCE(changed_by, 0), created_at
+			FROM config_changes
+			ORDER BY created_at DESC, id DESC
+			LIMIT $1 OFFSET $2`
+
+		var rows *sql.Rows
+		rows, err = r.db.DB.QueryContext(ctx, query, filter.PageSize, (filter.Page-1)*filter.PageSize)
+		if err == nil {
+			for rows.Next() {
+				change := &entity.ConfigChange{}
+				var settings []byte
+				if err = rows.Scan(&change.ID, &change.Profile, &settings, &change.ChangedBy, &change.CreatedAt); err != nil {
+					break
+				}
+				if err = json.Unmarshal(settings, &change.Changes); err != nil {
+					break
+				}
+				changes = append(changes, change)
+			}
+			if err == nil {
+				err = rows.Err()
+			}
+			rows.Close()
+		}
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to list config changes", nil)
+		return nil, 0, fmt.Errorf("failed to list config changes: %w", err)
+	}
+
+	return changes, total, nil
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+)
+
+// ConsentRepository defines the record of the legal documents users accepted.
+type ConsentRepository interface {
+	// Create records the consent. A version the user already accepted keeps
+	// its first record, which is returned in consent.
+	Create(ctx context.Context, consent *entity.Consent) error
+	// ListByUser returns the consents of the user, newest first
+	ListByUser(ctx context.Context, userID int) ([]*entity.Consent, error)
+}
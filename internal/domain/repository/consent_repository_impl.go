@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// consentRepositoryImpl implements the ConsentRepository interface
+type consentRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewConsentRepository creates a new consent repository implementation
+func NewConsentRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) ConsentRepository {
+	return &consentRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func (r *consentRepositoryImpl) Create(ctx context.Context, consent *entity.Consent) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "consents"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	// The no-op update returns the first record of a version accepted again
+	query := `
+		INSERT INTO consents (user_id, document, version, ip_address, user_agent, accepted_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, document, version) DO UPDATE SET accepted_at = consents.accepted_at
+		RETURNING id, ip_address, user_agent, accepted_at`
+
+	err := r.db.DB.QueryRowContext(ctx, query,
+		consent.UserID, consent.Document, consent.Version, consent.IPAddress, consent.UserAgent, time.Now(),
+	).Scan(&consent.ID, &consent.IPAddress, &consent.UserAgent, &consent.AcceptedAt)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create consent", map[string]interface{}{
+			"user_id":  consent.UserID,
+			"document": consent.Document,
+			"version":  consent.Version,
+		})
+		return fmt.Errorf("failed to create consent: %w", err)
+	}
+
+	return nil
+}
+
+func (r *consentRepositoryImpl) ListByUser(ctx context.Context, userID int) ([]*entity.Consent, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "consents"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, document, version, ip_address, user_agent, accepted_at
+		FROM consents
+		WHERE user_id = $1
+		ORDER BY accepted_at DESC, id DESC`
+
+	consents := make([]*entity.Consent, 0)
+	rows, err := r.db.DB.QueryContext(ctx, query, userID)
+	if err == nil {
+		for rows.Next() {
+			consent := &entity.Consent{}
+			if err = rows.Scan(&consent.ID, &consent.UserID, &consent.Document, &consent.Version,
+				&consent.IPAddress, &consent.UserAgent, &consent.AcceptedAt); err != nil {
+				break
+			}
+			consents = append(consents, consent)
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		rows.Close()
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to list consents", map[string]interface{}{
+			"user_id": userID,
+		})
+		return nil, fmt.Errorf("failed to list consents: %w", err)
+	}
+
+	return consents, nil
+}
+
+// scrubConsents drops where an erased user accepted the legal documents from,
+// keeping which versions were accepted and when
+func scrubConsents(ctx context.Context, tx *sql.Tx, userID int) error {
+	_, err := tx.ExecContext(ctx, `UPDATE consents SET ip_address = '', user_agent = '' WHERE user_id = $1`, userID)
+	return err
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+)
+
+// CouponRepository defines the contract for coupon data operations.
+type CouponRepository interface {
+	Create(ctx context.Context, coupon *entity.Coupon) error
+	GetByID(ctx context.Context, id int) (*entity.Coupon, error)
+	GetByCode(ctx context.Context, code string) (*entity.Coupon, error)
+	List(ctx context.Context) ([]*entity.Coupon, error)
+	Update(ctx context.Context, coupon *entity.Coupon) error
+	Delete(ctx context.Context, id int) error
+	CountRedemptionsByUser(ctx context.Context, couponID, userID int) (int, error)
+	Redeem(ctx context.Context, redemption *entity.CouponRedemption) error
+	ReleaseByOrderID(ctx context.Context, orderID int) error
+}
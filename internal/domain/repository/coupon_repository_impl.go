@@ -0,0 +1,439 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const couponColumns = `id, code, type, value, currency, min_order_amount, max_redemptions, per_user_limit,
+		redeemed_count, expires_at, active, created_at, updated_at`
+
+// couponRepositoryImpl implements the CouponRepository interface
+type couponRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewCouponRepository creates a new coupon repository implementation
+func NewCouponRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) CouponRepository {
+	return &couponRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCoupon(row rowScanner) (*entity.Coupon, error) {
+	coupon := &entity.Coupon{}
+	var maxRedemptions, perUserLimit sql.NullInt64
+	err := row.Scan(
+		&coupon.ID, &coupon.Code, &coupon.Type, &coupon.Value, &coupon.Currency, &coupon.MinOrderAmount,
+		&maxRedemptions, &perUserLimit, &coupon.RedeemedCount, &coupon.ExpiresAt, &coupon.Active,
+		&coupon.CreatedAt, &coupon.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if maxRedemptions.Valid {
+		n := int(maxRedemptions.Int64)
+		coupon.MaxRedemptions = &n
+	}
+	if perUserLimit.Valid {
+		n := int(perUserLimit.Int64)
+		coupon.PerUserLimit = &n
+	}
+	return coupon, nil
+}
+
+func (r *couponRepositoryImpl) Create(ctx context.Context, coupon *entity.Coupon) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "coupons"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO coupons (code, type, value, currency, min_order_amount, max_redemptions, per_user_limit,
+			expires_at, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`
+
+	now := time.Now()
+	err := r.db.DB.QueryRowContext(ctx, query,
+		coupon.Code, coupon.Type, coupon.Value, coupon.Currency, coupon.MinOrderAmount,
+		coupon.MaxRedemptions, coupon.PerUserLimit, coupon.ExpiresAt, coupon.Active, now, now).Scan(&coupon.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create coupon", map[string]interface{}{
+			"code": coupon.Code,
+		})
+		return fmt.Errorf("failed to create coupon: %w", err)
+	}
+
+	coupon.CreatedAt = now
+	coupon.UpdatedAt = now
+	return nil
+}
+
+func (r *couponRepositoryImpl) GetByID(ctx context.Context, id int) (*entity.Coupon, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "coupons"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + couponColumns + ` FROM coupons WHERE id = $1`
+	coupon, err := scanCoupon(r.db.DB.QueryRowContext(ctx, query, id))
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrCouponNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get coupon by ID", map[string]interface{}{
+			"coupon_id": id,
+		})
+		return nil, fmt.Errorf("failed to get coupon by id: %w", err)
+	}
+
+	return coupon, nil
+}
+
+func (r *couponRepositoryImpl) GetByCode(ctx context.Context, code string) (*entity.Coupon, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "coupons"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + couponColumns + ` FROM coupons WHERE code = $1`
+	coupon, err := scanCoupon(r.db.DB.QueryRowContext(ctx, query, code))
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrCouponNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get coupon by code", map[string]interface{}{
+			"code": code,
+		})
+		return nil, fmt.Errorf("failed to get coupon by code: %w", err)
+	}
+
+	return coupon, nil
+}
+
+func (r *couponRepositoryImpl) List(ctx context.Context) ([]*entity.Coupon, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "coupons"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + couponColumns + ` FROM coupons ORDER BY created_at DESC`
+
+	coupons := make([]*entity.Coupon, 0)
+	rows, err := r.db.DB.QueryContext(ctx, query)
+	if err == nil {
+		for rows.Next() {
+			var coupon *entity.Coupon
+			if coupon, err = scanCoupon(rows); err != nil {
+				break
+			}
+			coupons = append(coupons, coupon)
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		rows.Close()
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to list coupons", nil)
+		return nil, fmt.Errorf("failed to list coupons: %w", err)
+	}
+
+	return coupons, nil
+}
+
+func (r *couponRepositoryImpl) Update(ctx context.Context, coupon *entity.Coupon) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "coupons"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE coupons
+		SET code = $1, type = $2, value = $3, currency = $4, min_order_amount = $5, max_redemptions = $6,
+			per_user_limit = $7, expires_at = $8, active = $9, updated_at = $10
+		WHERE id = $11`
+
+	now := time.Now()
+	result, err := r.db.DB.ExecContext(ctx, query,
+		coupon.Code, coupon.Type, coupon.Value, coupon.Currency, coupon.MinOrderAmount, coupon.MaxRedemptions,
+		coupon.PerUserLimit, coupon.ExpiresAt, coupon.Active, now, coupon.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to update coupon", map[string]interface{}{
+			"coupon_id": coupon.ID,
+		})
+		return fmt.Errorf("failed to update coupon: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update coupon: %w", err)
+	}
+	if affected == 0 {
+		return errors.ErrCouponNotFound
+	}
+
+	coupon.UpdatedAt = now
+	return nil
+}
+
+func (r *couponRepositoryImpl) Delete(ctx context.Context, id int) error {
+	start := time.Now()
+	operation := "DELETE"
+	table := "coupons"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.DB.ExecContext(ctx, `DELETE FROM coupons WHERE id = $1`, id)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to delete coupon", map[string]interface{}{
+			"coupon_id": id,
+		})
+		return fmt.Errorf("failed to delete coupon: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete coupon: %w", err)
+	}
+	if affected == 0 {
+		return errors.ErrCouponNotFound
+	}
+
+	return nil
+}
+
+func (r *couponRepositoryImpl) CountRedemptionsByUser(ctx context.Context, couponID, userID int) (int, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "coupon_redemptions"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT COUNT(*) FROM coupon_redemptions WHERE coupon_id = $1 AND user_id = $2`
+
+	var count int
+	err := r.db.DB.QueryRowContext(ctx, query, couponID, userID).Scan(&count)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to count coupon redemptions", map[string]interface{}{
+			"coupon_id": couponID,
+			"user_id":   userID,
+		})
+		return 0, fmt.Errorf("failed to count coupon redemptions: %w", err)
+	}
+
+	return count, nil
+}
+
+// Redeem records the redemption and increments the coupon's redeemed count. The
+// coupon row is locked while its state, redemption limit and the user's
+// per-user limit are checked, so concurrent orders cannot exceed either limit
+// or redeem a coupon that was just deactivated or expired.
+func (r *couponRepositoryImpl) Redeem(ctx context.Context, redemption *entity.CouponRedemption) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "coupon_redemptions"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := r.redeem(ctx, redemption)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if errors.IsCouponInvalid(err) {
+			return err
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to redeem coupon", map[string]interface{}{
+			"coupon_id": redemption.CouponID,
+			"order_id":  redemption.OrderID,
+		})
+		return fmt.Errorf("failed to redeem coupon: %w", err)
+	}
+
+	return nil
+}
+
+func (r *couponRepositoryImpl) redeem(ctx context.Context, redemption *entity.CouponRedemption) error {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	coupon, err := scanCoupon(tx.QueryRowContext(ctx,
+		`SELECT `+couponColumns+` FROM coupons WHERE id = $1 FOR UPDATE`, redemption.CouponID))
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: unknown code", errors.ErrCouponInvalid)
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	switch {
+	case !coupon.Active:
+		return fmt.Errorf("%w: coupon is inactive", errors.ErrCouponInvalid)
+	case coupon.Expired(now):
+		return fmt.Errorf("%w: coupon has expired", errors.ErrCouponInvalid)
+	case coupon.Exhausted():
+		return fmt.Errorf("%w: redemption limit reached", errors.ErrCouponInvalid)
+	}
+
+	// Redemptions by the user are counted under the coupon lock, so they include
+	// those committed by concurrent orders that held it first
+	if coupon.PerUserLimit != nil {
+		var used int
+		err = tx.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM coupon_redemptions WHERE coupon_id = $1 AND user_id = $2`,
+			coupon.ID, redemption.UserID).Scan(&used)
+		if err != nil {
+			return err
+		}
+		if used >= *coupon.PerUserLimit {
+			return fmt.Errorf("%w: per-user limit reached", errors.ErrCouponInvalid)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE coupons
+		SET redeemed_count = redeemed_count + 1, updated_at = $1
+		WHERE id = $2`,
+		now, coupon.ID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO coupon_redemptions (coupon_id, user_id, order_id, discount_amount, redeemed_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`,
+		redemption.CouponID, redemption.UserID, redemption.OrderID, redemption.DiscountAmount, now).Scan(&redemption.ID)
+	if err != nil {
+		return err
+	}
+
+	redemption.RedeemedAt = now
+	return tx.Commit()
+}
+
+// ReleaseByOrderID removes the redemption recorded for an order, returning the
+// use to the coupon. It is a no-op when the order has no redemption.
+func (r *couponRepositoryImpl) ReleaseByOrderID(ctx context.Context, orderID int) error {
+	start := time.Now()
+	operation := "DELETE"
+	table := "coupon_redemptions"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := r.release(ctx, orderID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to release coupon redemption", map[string]interface{}{
+			"order_id": orderID,
+		})
+		return fmt.Errorf("failed to release coupon redemption: %w", err)
+	}
+
+	return nil
+}
+
+func (r *couponRepositoryImpl) release(ctx context.Context, orderID int) error {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var couponID int
+	err = tx.QueryRowContext(ctx,
+		`DELETE FROM coupon_redemptions WHERE order_id = $1 RETURNING coupon_id`, orderID).Scan(&couponID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE coupons
+		SET redeemed_count = redeemed_count - 1, updated_at = $1
+		WHERE id = $2 AND redeemed_count > 0`,
+		time.Now(), couponID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
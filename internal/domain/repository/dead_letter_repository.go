@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+)
+
+// DeadLetterRepository defines the contract for dead-lettered job data operations.
+type DeadLetterRepository interface {
+	Create(ctx context.Context, job *entity.DeadLetterJob) error
+	GetByID(ctx context.Context, id int) (*entity.DeadLetterJob, error)
+	List(ctx context.Context, filter entity.DeadLetterFilter) ([]*entity.DeadLetterJob, int, error)
+	Delete(ctx context.Context, id int) error
+	Count(ctx context.Context) (int, error)
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const deadLetterColumns = `id, job_type, payload, error, attempts, created_at`
+
+// deadLetterRepositoryImpl implements the DeadLetterRepository interface
+type deadLetterRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewDeadLetterRepository creates a new dead letter repository implementation
+func NewDeadLetterRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) DeadLetterRepository {
+	return &deadLetterRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func scanDeadLetterJob(row rowScanner) (*entity.DeadLetterJob, error) {
+	job := &entity.DeadLetterJob{}
+	err := row.Scan(&job.ID, &job.JobType, &job.Payload, &job.Error, &job.Attempts, &job.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *deadLetterRepositoryImpl) Create(ctx context.Context, job *entity.DeadLetterJob) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "dead_letter_jobs"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO dead_letter_jobs (job_type, payload, error, attempts, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	now := time.Now()
+	err := r.db.DB.QueryRowContext(ctx, query,
+		job.JobType, []byte(job.Payload), job.Error, job.Attempts, now).Scan(&job.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create dead letter job", map[string]interface{}{
+			"job_type": job.JobType,
+		})
+		return fmt.Errorf("failed to create dead letter job: %w", err)
+	}
+
+	job.CreatedAt = now
+	return nil
+}
+
+func (r *deadLetterRepositoryImpl) GetByID(ctx context.Context, id int) (*entity.DeadLetterJob, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "dead_letter_jobs"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + deadLetterColumns + ` FROM dead_letter_jobs WHERE id = $1`
+	job, err := scanDeadLetterJob(r.db.DB.QueryRowContext(ctx, query, id))
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrDeadLetterNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get dead letter job by ID", map[string]interface{}{
+			"job_id": id,
+		})
+		return nil, fmt.Errorf("failed to get dead letter job by id: %w", err)
+	}
+
+	return job, nil
+}
+
+// List returns one page of dead-lettered jobs, newest first, together with the
+// total number of matching jobs.
+func (r *deadLetterRepositoryImpl) List(ctx context.Context, filter entity.DeadLetterFilter) ([]*entity.DeadLetterJob, int, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "dead_letter_jobs"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	where := "TRUE"
+	args := []interface{}{}
+	if filter.JobType != "" {
+		args = append(args, filter.JobType)
+		where = "job_type = $1"
+	}
+
+	var total int
+	err := r.db.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM dead_letter_jobs WHERE `+where, args...).Scan(&total)
+
+	jobs := make([]*entity.DeadLetterJob, 0)
+	if err == nil && total > 0 {
+		query := `SELECT ` + deadLetterColumns + ` FROM dead_letter_jobs WHERE ` + where + fmt.Sprintf(`
+			ORDER BY created_at DESC
+			LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+		var rows *sql.Rows
+		rows, err = r.db.DB.QueryContext(ctx, query, append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)...)
+		if err == nil {
+			for rows.Next() {
+				var job *entity.DeadLetterJob
+				if job, err = scanDeadLetterJob(rows); err != nil {
+					break
+				}
+				jobs = append(jobs, job)
+			}
+			if err == nil {
+				err = rows.Err()
+			}
+			rows.Close()
+		}
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to list dead letter jobs", nil)
+		return nil, 0, fmt.Errorf("failed to list dead letter jobs: %w", err)
+	}
+
+	return jobs, total, nil
+}
+
+func (r *deadLetterRepositoryImpl) Delete(ctx context.Context, id int) error {
+	start := time.Now()
+	operation := "DELETE"
+	table := "dead_letter_jobs"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.DB.ExecContext(ctx, `DELETE FROM dead_letter_jobs WHERE id = $1`, id)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to delete dead letter job", map[string]interface{}{
+			"job_id": id,
+		})
+		return fmt.Errorf("failed to delete dead letter job: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete dead letter job: %w", err)
+	}
+	if affected == 0 {
+		return errors.ErrDeadLetterNotFound
+	}
+
+	return nil
+}
+
+func (r *deadLetterRepositoryImpl) Count(ctx context.Context) (int, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "dead_letter_jobs"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var count int
+	err := r.db.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM dead_letter_jobs`).Scan(&count)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to count dead letter jobs", nil)
+		return 0, fmt.Errorf("failed to count dead letter jobs: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"time"
+)
+
+// EventBufferRepository holds the payment events received before their order
+// could take them, until the order is paid.
+type EventBufferRepository interface {
+	// Buffer keeps the event until expiresAt.
+	Buffer(ctx context.Context, event *entity.PaymentEvent, expiresAt time.Time) error
+	// Take removes the unexpired events of the payment and returns them in
+	// the order they were buffered.
+	Take(ctx context.Context, paymentID string) ([]*entity.PaymentEvent, error)
+	// DeleteExpired removes the events past their expiry and returns how many.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// eventBufferRepositoryImpl implements the EventBufferRepository interface
+type eventBufferRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewEventBufferRepository creates a new payment event buffer repository implementation
+func NewEventBufferRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) EventBufferRepository {
+	return &eventBufferRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func (r *eventBufferRepositoryImpl) Buffer(ctx context.Context, event *entity.PaymentEvent, expiresAt time.Time) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "buffered_payment_events"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode payment event: %w", err)
+	}
+
+	query := `
+		INSERT INTO buffered_payment_events (payment_id, event, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)`
+
+	_, err = r.db.DB.ExecContext(ctx, query, event.PaymentID, payload, expiresAt, time.Now())
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to buffer payment event", map[string]interface{}{
+			"payment_id": event.PaymentID,
+		})
+		return fmt.Errorf("failed to buffer payment event: %w", err)
+	}
+
+	return nil
+}
+
+func (r *eventBufferRepositoryImpl) Take(ctx context.Context, paymentID string) ([]*entity.PaymentEvent, error) {
+	start := time.Now()
+	operation := "DELETE"
+	table := "buffered_payment_events"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		WITH taken AS (
+			DELETE FROM buffered_payment_events WHERE payment_id = $1
+			RETURNING id, event, expires_at
+		)
+		SELECT event FROM taken WHERE expires_at > $2 ORDER BY id`
+
+	events := make([]*entity.PaymentEvent, 0)
+	rows, err := r.db.DB.QueryContext(ctx, query, paymentID, time.Now())
+	if err == nil {
+		for rows.Next() {
+			var payload []byte
+			if err = rows.Scan(&payload); err != nil {
+				break
+			}
+			event := &entity.PaymentEvent{}
+			if err = json.Unmarshal(payload, event); err != nil {
+				break
+			}
+			events = append(events, event)
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		rows.Close()
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to take buffered payment events", map[string]interface{}{
+			"payment_id": paymentID,
+		})
+		return nil, fmt.Errorf("failed to take buffered payment events: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *eventBufferRepositoryImpl) DeleteExpired(ctx context.Context) (int64, error) {
+	start := time.Now()
+	operation := "DELETE"
+	table := "buffered_payment_events"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.DB.ExecContext(ctx, `DELETE FROM buffered_payment_events WHERE expires_at <= $1`, time.Now())
+	var affected int64
+	if err == nil {
+		affected, err = result.RowsAffected()
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to delete expired payment events", nil)
+		return 0, fmt.Errorf("failed to delete expired payment events: %w", err)
+	}
+
+	return affected, nil
+}
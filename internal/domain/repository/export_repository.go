@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+)
+
+// ExportRepository defines the data operations of exports. The Iterate
+// methods stream the rows matching the filter in ID order from a single
+// query, passing each to fn as it is read, so a dataset is never held whole.
+// An error returned by fn stops the iteration and is returned as is.
+type ExportRepository interface {
+	IterateUsers(ctx context.Context, filter *entity.ExportFilter, fn func(*entity.User) error) error
+	IterateOrders(ctx context.Context, filter *entity.ExportFilter, fn func(*entity.Order) error) error
+	IterateSessions(ctx context.Context, filter *entity.ExportFilter, fn func(*entity.Session) error) error
+
+	// Create stores a background export
+	Create(ctx context.Context, export *entity.Export) error
+	GetByID(ctx context.Context, id int) (*entity.Export, error)
+	// Complete saves the status, rows, file and error of a finished export
+	Complete(ctx context.Context, export *entity.Export) error
+}
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// exportRepositoryImpl implements the ExportRepository interface
+type exportRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewExportRepository creates a new export repository implementation
+func NewExportRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) ExportRepository {
+	return &exportRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func (r *exportRepositoryImpl) IterateUsers(ctx context.Context, filter *entity.ExportFilter, fn func(*entity.User) error) error {
+	where, args := exportConditions(filter, "role")
+	query := `SELECT ` + userColumns + ` FROM users WHERE ` + where
+
+	return r.iterate(ctx, "users", query, args, func(rows *sql.Rows) error {
+		user, err := scanUser(rows)
+		if err != nil {
+			return err
+		}
+		return yield(fn(user))
+	})
+}
+
+func (r *exportRepositoryImpl) IterateOrders(ctx context.Context, filter *entity.ExportFilter, fn func(*entity.Order) error) error {
+	where, args := exportConditions(filter, "status", "user_id")
+	query := `
+		SELECT id, order_id, user_id, amount, currency, status, payment_id, payment_intent_id, coupon_code, discount_amount,
+			risk_score, flagged_for_review, created_at, updated_at
+		FROM orders
+		WHERE ` + where
+
+	return r.iterate(ctx, "orders", query, args, func(rows *sql.Rows) error {
+		order := &entity.Order{}
+		if err := rows.Scan(
+			&order.ID, &order.OrderID, &order.UserID, &order.Amount, &order.Currency, &order.Status,
+			&order.PaymentID, &order.PaymentIntentID, &order.CouponCode, &order.DiscountAmount,
+			&order.RiskScore, &order.FlaggedForReview, &order.CreatedAt, &order.UpdatedAt); err != nil {
+			return err
+		}
+		return yield(fn(order))
+	})
+}
+
+func (r *exportRepositoryImpl) IterateSessions(ctx context.Context, filter *entity.ExportFilter, fn func(*entity.Session) error) error {
+	where, args := exportConditions(filter, "user_id")
+	query := `
+		SELECT id, user_id, token_id, device, ip_address, location, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE ` + where
+
+	return r.iterate(ctx, "sessions", query, args, func(rows *sql.Rows) error {
+		session := &entity.Session{}
+		if err := rows.Scan(
+			&session.ID, &session.UserID, &session.TokenID, &session.Device, &session.IPAddress,
+			&session.Location, &session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt, &session.RevokedAt); err != nil {
+			return err
+		}
+		return yield(fn(session))
+	})
+}
+
+// exportConditions builds the WHERE clause of the rows matching the filter,
+// on the creation time and the given filter columns
+func exportConditions(filter *entity.ExportFilter, columns ...string) (string, []interface{}) {
+	conditions := []string{"TRUE"}
+	var args []interface{}
+	addCondition := func(clause string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(clause, len(args)))
+	}
+
+	if filter.From != nil {
+		addCondition("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at < $%d", *filter.To)
+	}
+	for _, column := range columns {
+		switch {
+		case column == "role" && filter.Role != "":
+			addCondition("role = $%d", filter.Role)
+		case column == "status" && filter.Status != "":
+			addCondition("status = $%d", filter.Status)
+		case column == "user_id" && filter.UserID != 0:
+			addCondition("user_id = $%d", filter.UserID)
+		}
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// iterate runs the query ordered by ID, passing each row to scan as it is
+// read
+func (r *exportRepositoryImpl) iterate(ctx context.Context, table, query string, args []interface{}, scan func(*sql.Rows) error) error {
+	start := time.Now()
+	operation := "SELECT"
+
+	err := streamRows(ctx, r.db, query+" ORDER BY id", args, scan)
+	fnErr := stopped(err)
+	if fnErr != nil {
+		err = nil
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to export "+table, nil)
+		return fmt.Errorf("failed to export %s: %w", table, err)
+	}
+	return nil
+}
+
+func (r *exportRepositoryImpl) Create(ctx context.Context, export *entity.Export) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "exports"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter, err := json.Marshal(export.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to encode export filter: %w", err)
+	}
+
+	query := `
+		INSERT INTO exports (dataset, format, filter, status, requested_by, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, 0), $6)
+		RETURNING id`
+
+	export.CreatedAt = time.Now()
+	err = r.db.DB.QueryRowContext(ctx, query,
+		export.Dataset, export.Format, filter, export.Status, export.RequestedBy, export.CreatedAt).Scan(&export.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create export", map[string]interface{}{
+			"dataset": export.Dataset,
+		})
+		return fmt.Errorf("failed to create export: %w", err)
+	}
+
+	return nil
+}
+
+func (r *exportRepositoryImpl) GetByID(ctx context.Context, id int) (*entity.Export, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "exports"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, dataset, format, filter, status, rows, file_id, error, COALESCE(requested_by, 0), created_at, completed_at
+		FROM exports
+		WHERE id = $1`
+
+	export := &entity.Export{}
+	var filter []byte
+	err := r.db.DB.QueryRowContext(ctx, query, id).Scan(
+		&export.ID, &export.Dataset, &export.Format, &filter, &export.Status, &export.Rows,
+		&export.FileID, &export.Error, &export.RequestedBy, &export.CreatedAt, &export.CompletedAt)
+	if err == nil {
+		err = json.Unmarshal(filter, &export.Filter)
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrExportNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get export by ID", map[string]interface{}{
+			"export_id": id,
+		})
+		return nil, fmt.Errorf("failed to get export by id: %w", err)
+	}
+
+	return export, nil
+}
+
+func (r *exportRepositoryImpl) Complete(ctx context.Context, export *entity.Export) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "exports"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	query := `
+		UPDATE exports
+		SET status = $1, rows = $2, file_id = $3, error = $4, completed_at = $5
+		WHERE id = $6`
+
+	result, err := r.db.DB.ExecContext(ctx, query, export.Status, export.Rows, export.FileID, export.Error, now, export.ID)
+	if err == nil {
+		var affected int64
+		if affected, err = result.RowsAffected(); err == nil && affected == 0 {
+			err = errors.ErrExportNotFound
+		}
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if errors.IsExportNotFound(err) {
+			return err
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to complete export", map[string]interface{}{
+			"export_id": export.ID,
+		})
+		return fmt.Errorf("failed to complete export: %w", err)
+	}
+
+	export.CompletedAt = &now
+	return nil
+}
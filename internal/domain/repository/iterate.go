@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"context"
+	"database/sql"
+)
+
+// callbackError carries the error that stopped an iteration from the
+// function rows are passed to, telling it apart from a failed query
+type callbackError struct {
+	err error
+}
+
+func (e *callbackError) Error() string {
+	return e.err.Error()
+}
+
+// yield marks the error returned by an iteration callback
+func yield(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &callbackError{err: err}
+}
+
+// streamRows runs the query and passes each row to scan as it is read from
+// the connection, stopping at the first error. The statement timeout does not
+// apply, as reading the rows takes as long as scan does, so the query is only
+// bounded by ctx.
+func streamRows(ctx context.Context, db *database.PostgresDB, query string, args []interface{}, scan func(*sql.Rows) error) error {
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := db.LiftStatementTimeout(ctx, tx); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// stopped returns the error of the callback that stopped an iteration, or
+// nil when err is not one
+func stopped(err error) error {
+	if stop, ok := err.(*callbackError); ok {
+		return stop.err
+	}
+	return nil
+}
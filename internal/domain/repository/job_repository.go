@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"time"
+)
+
+// JobRepository defines the contract for queued job data operations.
+type JobRepository interface {
+	Create(ctx context.Context, job *entity.Job) error
+	// CreateUnlessQueued stores the job unless a job of its type is already
+	// queued, and reports whether it did.
+	CreateUnlessQueued(ctx context.Context, job *entity.Job) (bool, error)
+	// ClaimDue locks up to limit due jobs for the lease duration and counts
+	// the attempt. Jobs locked by another worker are skipped.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.Job, error)
+	Reschedule(ctx context.Context, id int, runAt time.Time, lastError string) error
+	Delete(ctx context.Context, id int) error
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const jobColumns = `id, job_type, payload, attempts, last_error, run_at, created_at`
+
+// jobRepositoryImpl implements the JobRepository interface
+type jobRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewJobRepository creates a new job repository implementation
+func NewJobRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) JobRepository {
+	return &jobRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func scanJob(row rowScanner) (*entity.Job, error) {
+	job := &entity.Job{}
+	err := row.Scan(&job.ID, &job.JobType, &job.Payload, &job.Attempts, &job.LastError, &job.RunAt, &job.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *jobRepositoryImpl) Create(ctx context.Context, job *entity.Job) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "jobs"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO jobs (job_type, payload, run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		RETURNING id`
+
+	now := time.Now()
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+	err := r.db.DB.QueryRowContext(ctx, query, job.JobType, []byte(job.Payload), job.RunAt, now).Scan(&job.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create job", map[string]interface{}{
+			"job_type": job.JobType,
+		})
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+
+	job.CreatedAt = now
+	return nil
+}
+
+func (r *jobRepositoryImpl) CreateUnlessQueued(ctx context.Context, job *entity.Job) (bool, error) {
+	start := time.Now()
+	operation := "INSERT"
+	table := "jobs"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO jobs (job_type, payload, run_at, created_at, updated_at)
+		SELECT $1, $2, $3, $4, $4
+		WHERE NOT EXISTS (SELECT 1 FROM jobs WHERE job_type = $1)
+		RETURNING id`
+
+	now := time.Now()
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+	err := r.db.DB.QueryRowContext(ctx, query, job.JobType, []byte(job.Payload), job.RunAt, now).Scan(&job.ID)
+	if err == sql.ErrNoRows {
+		err = nil
+		job.ID = 0
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create job", map[string]interface{}{
+			"job_type": job.JobType,
+		})
+		return false, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	job.CreatedAt = now
+	return job.ID != 0, nil
+}
+
+func (r *jobRepositoryImpl) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.Job, error) {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "jobs"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE jobs
+		SET attempts = attempts + 1, locked_until = $1, updated_at = $2
+		WHERE id IN (
+			SELECT id FROM jobs
+			WHERE run_at <= $2 AND (locked_until IS NULL OR locked_until < $2)
+			ORDER BY run_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED)
+		RETURNING ` + jobColumns
+
+	now := time.Now()
+	jobs := make([]*entity.Job, 0, limit)
+	rows, err := r.db.DB.QueryContext(ctx, query, now.Add(lease), now, limit)
+	if err == nil {
+		for rows.Next() {
+			var job *entity.Job
+			if job, err = scanJob(rows); err != nil {
+				break
+			}
+			jobs = append(jobs, job)
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		rows.Close()
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to claim jobs", nil)
+		return nil, fmt.Errorf("failed to claim jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (r *jobRepositoryImpl) Reschedule(ctx context.Context, id int, runAt time.Time, lastError string) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "jobs"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE jobs
+		SET run_at = $1, last_error = $2, locked_until = NULL, updated_at = $3
+		WHERE id = $4`
+
+	_, err := r.db.DB.ExecContext(ctx, query, runAt, lastError, time.Now(), id)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to reschedule job", map[string]interface{}{
+			"job_id": id,
+		})
+		return fmt.Errorf("failed to reschedule job: %w", err)
+	}
+
+	return nil
+}
+
+func (r *jobRepositoryImpl) Delete(ctx context.Context, id int) error {
+	start := time.Now()
+	operation := "DELETE"
+	table := "jobs"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.DB.ExecContext(ctx, `DELETE FROM jobs WHERE id = $1`, id)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to delete job", map[string]interface{}{
+			"job_id": id,
+		})
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"time"
+)
+
+// NotificationRepository defines the contract for bulk notification data operations.
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *entity.Notification) error
+	GetByID(ctx context.Context, id int) (*entity.Notification, error)
+	// RecordChunk stores the outcome of the next chunk and advances past it.
+	// It is a no-op if the chunk was already recorded by an earlier attempt.
+	RecordChunk(ctx context.Context, id int, chunk entity.NotificationChunk) error
+	// Complete saves the final status of the notification.
+	Complete(ctx context.Context, notification *entity.Notification) error
+	// IterateCompletedSince streams the notifications that finished sending
+	// at or after since, without their recipients, in ID order from a single
+	// query, passing each to fn as it is read. An error returned by fn stops
+	// the iteration and is returned as is.
+	IterateCompletedSince(ctx context.Context, since time.Time, fn func(*entity.Notification) error) error
+	// ListByRecipient returns the notifications sent to the email address,
+	// oldest first.
+	ListByRecipient(ctx context.Context, email string) ([]*entity.ReceivedNotification, error)
+	// UpdateEngagement saves the chunks and engagement timestamps reconciled
+	// with the email provider.
+	UpdateEngagement(ctx context.Context, notification *entity.Notification) error
+}
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const notificationColumns = `id, subject, body, body_html, total, chunk_size, total_chunks, next_chunk,
+	sent, failed, chunks, status, error, created_at, completed_at, delivered_at, opened_at, clicked_at, status_checked_at`
+
+// notificationRepositoryImpl implements the NotificationRepository interface
+type notificationRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewNotificationRepository creates a new notification repository implementation
+func NewNotificationRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) NotificationRepository {
+	return &notificationRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+// scanNotification scans notificationColumns followed by extra destinations
+func scanNotification(row rowScanner, extra ...interface{}) (*entity.Notification, error) {
+	notification := &entity.Notification{}
+	var chunks []byte
+	dest := []interface{}{
+		&notification.ID, &notification.Subject, &notification.Body, &notification.BodyHTML,
+		&notification.Total, &notification.ChunkSize, &notification.TotalChunks, &notification.NextChunk,
+		&notification.Sent, &notification.Failed, &chunks, &notification.Status, &notification.Error,
+		&notification.CreatedAt, &notification.CompletedAt, &notification.DeliveredAt, &notification.OpenedAt,
+		&notification.ClickedAt, &notification.StatusCheckedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(chunks, &notification.Chunks); err != nil {
+		return nil, fmt.Errorf("failed to decode notification chunks: %w", err)
+	}
+	return notification, nil
+}
+
+func (r *notificationRepositoryImpl) Create(ctx context.Context, notification *entity.Notification) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "notifications"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	recipients, err := json.Marshal(notification.Recipients)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification recipients: %w", err)
+	}
+
+	query := `
+		INSERT INTO notifications (subject, body, body_html, recipients, total, chunk_size, total_chunks, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+
+	notification.CreatedAt = time.Now()
+	err = r.db.DB.QueryRowContext(ctx, query,
+		notification.Subject, notification.Body, notification.BodyHTML, recipients, notification.Total,
+		notification.ChunkSize, notification.TotalChunks, notification.Status, notification.CreatedAt,
+	).Scan(&notification.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create notification", map[string]interface{}{
+			"total": notification.Total,
+		})
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return nil
+}
+
+func (r *notificationRepositoryImpl) GetByID(ctx context.Context, id int) (*entity.Notification, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "notifications"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + notificationColumns + `, recipients
+		FROM notifications
+		WHERE id = $1`
+
+	var recipients []byte
+	row := r.db.DB.QueryRowContext(ctx, query, id)
+	notification, err := scanNotification(row, &recipients)
+	if err == nil {
+		err = json.Unmarshal(recipients, &notification.Recipients)
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrCampaignNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get notification by ID", map[string]interface{}{
+			"notification_id": id,
+		})
+		return nil, fmt.Errorf("failed to get notification by id: %w", err)
+	}
+
+	return notification, nil
+}
+
+func (r *notificationRepositoryImpl) RecordChunk(ctx context.Context, id int, chunk entity.NotificationChunk) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "notifications"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	payload, err := json.Marshal([]entity.NotificationChunk{chunk})
+	if err != nil {
+		return fmt.Errorf("failed to encode notification chunk: %w", err)
+	}
+
+	// Only the expected next chunk is recorded, so a replayed chunk is not counted twice
+	query := `
+		UPDATE notifications
+		SET next_chunk = next_chunk + 1, sent = sent + $1, failed = failed + $2, chunks = chunks || $3::jsonb
+		WHERE id = $4 AND next_chunk = $5`
+
+	_, err = r.db.DB.ExecContext(ctx, query, chunk.Sent, chunk.Failed, payload, id, chunk.Index)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to record notification chunk", map[string]interface{}{
+			"notification_id": id,
+			"chunk":           chunk.Index,
+		})
+		return fmt.Errorf("failed to record notification chunk: %w", err)
+	}
+
+	return nil
+}
+
+func (r *notificationRepositoryImpl) Complete(ctx context.Context, notification *entity.Notification) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "notifications"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE notifications
+		SET status = $1, error = $2, completed_at = $3
+		WHERE id = $4`
+
+	now := time.Now()
+	_, err := r.db.DB.ExecContext(ctx, query, notification.Status, notification.Error, now, notification.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to complete notification", map[string]interface{}{
+			"notification_id": notification.ID,
+		})
+		return fmt.Errorf("failed to complete notification: %w", err)
+	}
+
+	notification.CompletedAt = &now
+	return nil
+}
+
+func (r *notificationRepositoryImpl) IterateCompletedSince(ctx context.Context, since time.Time, fn func(*entity.Notification) error) error {
+	start := time.Now()
+	operation := "SELECT"
+	table := "notifications"
+
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE completed_at >= $1 AND jsonb_array_length(chunks) > 0
+		ORDER BY id`
+
+	err := streamRows(ctx, r.db, query, []interface{}{since}, func(rows *sql.Rows) error {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return err
+		}
+		return yield(fn(notification))
+	})
+	fnErr := stopped(err)
+	if fnErr != nil {
+		err = nil
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to list completed notifications", nil)
+		return fmt.Errorf("failed to list completed notifications: %w", err)
+	}
+
+	return nil
+}
+
+func (r *notificationRepositoryImpl) ListByRecipient(ctx context.Context, email string) ([]*entity.ReceivedNotification, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "notifications"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	// ? tests whether the string is an element of the recipients array
+	query := `
+		SELECT id, subject, body, created_at, completed_at
+		FROM notifications
+		WHERE recipients ? $1
+		ORDER BY id`
+
+	notifications := make([]*entity.ReceivedNotification, 0)
+	rows, err := r.db.DB.QueryContext(ctx, query, email)
+	if err == nil {
+		for rows.Next() {
+			notification := &entity.ReceivedNotification{}
+			if err = rows.Scan(&notification.ID, &notification.Subject, &notification.Body,
+				&notification.CreatedAt, &notification.CompletedAt); err != nil {
+				break
+			}
+			notifications = append(notifications, notification)
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		rows.Close()
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to list notifications by recipient", nil)
+		return nil, fmt.Errorf("failed to list notifications by recipient: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (r *notificationRepositoryImpl) UpdateEngagement(ctx context.Context, notification *entity.Notification) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "notifications"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	chunks, err := json.Marshal(notification.Chunks)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification chunks: %w", err)
+	}
+
+	query := `
+		UPDATE notifications
+		SET chunks = $1, delivered_at = $2, opened_at = $3, clicked_at = $4, status_checked_at = $5
+		WHERE id = $6`
+
+	_, err = r.db.DB.ExecContext(ctx, query, chunks, notification.DeliveredAt, notification.OpenedAt,
+		notification.ClickedAt, notification.StatusCheckedAt, notification.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to update notification engagement", map[string]interface{}{
+			"notification_id": notification.ID,
+		})
+		return fmt.Errorf("failed to update notification engagement: %w", err)
+	}
+
+	return nil
+}
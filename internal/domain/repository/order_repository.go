@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+)
+
+// OrderRepository defines the contract for order data operations.
+type OrderRepository interface {
+	Create(ctx context.Context, order *entity.Order) error
+	GetByOrderID(ctx context.Context, orderID string) (*entity.Order, error)
+	GetByPaymentID(ctx context.Context, paymentID string) (*entity.Order, error)
+	// UpdateStatus saves the order's status and payment references only if
+	// the stored status is still fromStatus, returning ErrInvalidOrderState
+	// otherwise, and its version still the one it was read at, returning
+	// ErrConflict otherwise. It bumps the version.
+	UpdateStatus(ctx context.Context, order *entity.Order, fromStatus string) error
+	List(ctx context.Context, filter entity.OrderFilter) ([]*entity.Order, int, error)
+	// ListAfter returns up to limit orders matching the filter, ignoring its
+	// pagination, that follow after in the history, newest first. A nil after
+	// starts at the newest order.
+	ListAfter(ctx context.Context, filter entity.OrderFilter, after *entity.Order, limit int) ([]*entity.Order, error)
+}
@@ -0,0 +1,421 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the PostgreSQL error code for unique constraint violations
+const uniqueViolation = "23505"
+
+// orderColumns are the columns of an order read by scanOrder
+const orderColumns = `id, order_id, user_id, organization_id, amount, currency, status, payment_id, payment_intent_id, coupon_code,
+	discount_amount, risk_score, flagged_for_review, version, created_at, updated_at`
+
+// orderRepositoryImpl implements the OrderRepository interface
+type orderRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewOrderRepository creates a new order repository implementation
+func NewOrderRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) OrderRepository {
+	return &orderRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+// Create inserts the order and its line items in a single transaction.
+func (r *orderRepositoryImpl) Create(ctx context.Context, order *entity.Order) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "orders"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	err := r.createWithItems(ctx, order, now)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return errors.ErrOrderAlreadyExists
+	}
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create order", map[string]interface{}{
+			"order_id": order.OrderID,
+			"user_id":  order.UserID,
+		})
+		return fmt.Errorf("failed to create order: %w", err)
+	}
+
+	order.CreatedAt = now
+	order.UpdatedAt = now
+	order.Version = 1
+	return nil
+}
+
+func (r *orderRepositoryImpl) createWithItems(ctx context.Context, order *entity.Order, now time.Time) error {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO orders (order_id, user_id, organization_id, amount, currency, status, payment_id, payment_intent_id,
+			coupon_code, discount_amount, risk_score, flagged_for_review, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id`
+
+	err = tx.QueryRowContext(ctx, query,
+		order.OrderID, order.UserID, order.OrganizationID, order.Amount, order.Currency, order.Status,
+		order.PaymentID, order.PaymentIntentID, order.CouponCode, order.DiscountAmount,
+		order.RiskScore, order.FlaggedForReview, now, now).Scan(&order.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(order.Items) > 0 {
+		placeholders := make([]string, 0, len(order.Items))
+		args := make([]interface{}, 0, len(order.Items)*5)
+		for i, item := range order.Items {
+			n := i * 5
+			placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+			args = append(args, order.ID, item.ProductID, item.Name, item.Quantity, item.UnitPrice)
+		}
+
+		itemsQuery := `
+			INSERT INTO order_items (order_id, product_id, name, quantity, unit_price)
+			VALUES ` + strings.Join(placeholders, ", ") + `
+			RETURNING id`
+
+		rows, err := tx.QueryContext(ctx, itemsQuery, args...)
+		if err != nil {
+			return err
+		}
+		for i := 0; rows.Next(); i++ {
+			if err := rows.Scan(&order.Items[i].ID); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *orderRepositoryImpl) GetByOrderID(ctx context.Context, orderID string) (*entity.Order, error) {
+	return r.getBy(ctx, "order_id", orderID)
+}
+
+// GetByPaymentID returns the order charged by the payment.
+func (r *orderRepositoryImpl) GetByPaymentID(ctx context.Context, paymentID string) (*entity.Order, error) {
+	if paymentID == "" {
+		return nil, errors.ErrOrderNotFound
+	}
+	return r.getBy(ctx, "payment_id", paymentID)
+}
+
+func scanOrder(row rowScanner) (*entity.Order, error) {
+	order := &entity.Order{}
+	err := row.Scan(
+		&order.ID, &order.OrderID, &order.UserID, &order.OrganizationID, &order.Amount, &order.Currency, &order.Status,
+		&order.PaymentID, &order.PaymentIntentID, &order.CouponCode, &order.DiscountAmount,
+		&order.RiskScore, &order.FlaggedForReview, &order.Version, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// getBy returns the order with its items by a unique column.
+func (r *orderRepositoryImpl) getBy(ctx context.Context, column, value string) (*entity.Order, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "orders"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE ` + column + ` = $1`
+
+	order, err := scanOrder(r.db.DB.QueryRowContext(ctx, query, value))
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrOrderNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get order", map[string]interface{}{
+			column: value,
+		})
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	if order.Items, err = r.getItems(ctx, order.ID); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (r *orderRepositoryImpl) getItems(ctx context.Context, orderID int) ([]entity.OrderItem, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "order_items"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, product_id, name, quantity, unit_price
+		FROM order_items
+		WHERE order_id = $1
+		ORDER BY id`
+
+	items := make([]entity.OrderItem, 0)
+	rows, err := r.db.DB.QueryContext(ctx, query, orderID)
+	if err == nil {
+		for rows.Next() {
+			var item entity.OrderItem
+			if err = rows.Scan(&item.ID, &item.ProductID, &item.Name, &item.Quantity, &item.UnitPrice); err != nil {
+				break
+			}
+			items = append(items, item)
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		rows.Close()
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to get order items", map[string]interface{}{
+			"order_id": orderID,
+		})
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *orderRepositoryImpl) UpdateStatus(ctx context.Context, order *entity.Order, fromStatus string) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "orders"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE orders
+		SET status = $1, payment_id = $2, payment_intent_id = $3, updated_at = $4, version = version + 1
+		WHERE order_id = $5 AND status = $6 AND version = $7`
+
+	now := time.Now()
+	result, err := r.db.DB.ExecContext(ctx, query, order.Status, order.PaymentID, order.PaymentIntentID, now, order.OrderID, fromStatus, order.Version)
+
+	var affected int64
+	if err == nil {
+		affected, err = result.RowsAffected()
+	}
+	stored := fromStatus
+	if err == nil && affected == 0 {
+		// Tell a status change in the meantime from any other update
+		err = r.db.DB.QueryRowContext(ctx, `SELECT status FROM orders WHERE order_id = $1`, order.OrderID).Scan(&stored)
+		if err == sql.ErrNoRows {
+			stored, err = "", nil
+		}
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to update order status", map[string]interface{}{
+			"order_id": order.OrderID,
+			"status":   order.Status,
+		})
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	if affected == 0 {
+		if stored == "" {
+			return errors.ErrOrderNotFound
+		}
+		if stored != fromStatus {
+			return fmt.Errorf("%w: order is no longer %s", errors.ErrInvalidOrderState, fromStatus)
+		}
+		return fmt.Errorf("%w: order is no longer at version %d", errors.ErrConflict, order.Version)
+	}
+
+	order.UpdatedAt = now
+	order.Version++
+	return nil
+}
+
+// List returns one page of the user's orders matching the filter, newest first,
+// together with the total number of matching orders.
+func (r *orderRepositoryImpl) List(ctx context.Context, filter entity.OrderFilter) ([]*entity.Order, int, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "orders"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	where, args := orderConditions(filter)
+
+	var total int
+	err := r.db.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM orders WHERE `+where, args...).Scan(&total)
+
+	orders := make([]*entity.Order, 0)
+	if err == nil && total > 0 {
+		query := `
+			SELECT ` + orderColumns + `
+			FROM orders
+			WHERE ` + where + fmt.Sprintf(`
+			ORDER BY created_at DESC
+			LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+		pageArgs := append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+		orders, err = r.queryOrders(ctx, query, pageArgs...)
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to list orders", map[string]interface{}{
+			"user_id":         filter.UserID,
+			"organization_id": filter.OrganizationID,
+		})
+		return nil, 0, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	return orders, total, nil
+}
+
+// ListAfter returns the orders matching the filter that follow after in the
+// history, newest first, reading them by keyset rather than offset.
+func (r *orderRepositoryImpl) ListAfter(ctx context.Context, filter entity.OrderFilter, after *entity.Order, limit int) ([]*entity.Order, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "orders"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	where, args := orderConditions(filter)
+	if after != nil {
+		// Keyset pagination in history order, with the ID breaking ties
+		args = append(args, after.CreatedAt, after.ID)
+		where += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE ` + where + fmt.Sprintf(`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d`, len(args)+1)
+
+	orders, err := r.queryOrders(ctx, query, append(args, limit)...)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to list orders", map[string]interface{}{
+			"user_id":         filter.UserID,
+			"organization_id": filter.OrganizationID,
+		})
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// orderConditions returns the WHERE clause selecting the orders of the
+// filter and its arguments
+func orderConditions(filter entity.OrderFilter) (string, []interface{}) {
+	// Orders of an organization, or of the user
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{filter.UserID}
+	if filter.OrganizationID != 0 {
+		conditions = []string{"organization_id = $1"}
+		args = []interface{}{filter.OrganizationID}
+	}
+	addCondition := func(clause string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(clause, len(args)))
+	}
+
+	if filter.Status != "" {
+		addCondition("status = $%d", filter.Status)
+	}
+	if filter.From != nil {
+		addCondition("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at < $%d", *filter.To)
+	}
+	if filter.MinAmount != nil {
+		addCondition("amount >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		addCondition("amount <= $%d", *filter.MaxAmount)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+func (r *orderRepositoryImpl) queryOrders(ctx context.Context, query string, args ...interface{}) ([]*entity.Order, error) {
+	rows, err := r.db.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]*entity.Order, 0)
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
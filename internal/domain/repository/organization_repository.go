@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+)
+
+// OrganizationRepository defines the contract for organization, membership
+// and invitation data operations.
+type OrganizationRepository interface {
+	// Create inserts the organization with its creator as owner.
+	Create(ctx context.Context, org *entity.Organization) error
+	GetByID(ctx context.Context, id int) (*entity.Organization, error)
+	// ListByUser returns the organizations of the user with the user's role.
+	ListByUser(ctx context.Context, userID int) ([]*entity.Organization, error)
+
+	// GetMembership returns ErrNotOrgMember when the user is not a member.
+	GetMembership(ctx context.Context, orgID, userID int) (*entity.Membership, error)
+	ListMembers(ctx context.Context, orgID int) ([]*entity.Membership, error)
+	// AddMember inserts the membership, returning ErrAlreadyOrgMember when
+	// the user already is a member.
+	AddMember(ctx context.Context, member *entity.Membership) error
+	UpdateMemberRole(ctx context.Context, orgID, userID int, role string) error
+	RemoveMember(ctx context.Context, orgID, userID int) error
+	// CountOwners returns how many owners the organization has.
+	CountOwners(ctx context.Context, orgID int) (int, error)
+
+	CreateInvitation(ctx context.Context, invitation *entity.Invitation) error
+	// GetInvitationByTokenHash returns ErrInvitationNotFound for unknown tokens.
+	GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*entity.Invitation, error)
+	// AcceptInvitation marks the invitation accepted and adds the member in
+	// one transaction, returning ErrInvitationNotFound when it was already
+	// accepted.
+	AcceptInvitation(ctx context.Context, invitation *entity.Invitation, member *entity.Membership) error
+}
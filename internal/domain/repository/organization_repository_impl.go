@@ -0,0 +1,488 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// organizationRepositoryImpl implements the OrganizationRepository interface
+type organizationRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewOrganizationRepository creates a new organization repository implementation
+func NewOrganizationRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) OrganizationRepository {
+	return &organizationRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func (r *organizationRepositoryImpl) Create(ctx context.Context, org *entity.Organization) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "organizations"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	err := r.createWithOwner(ctx, org, now)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create organization", map[string]interface{}{
+			"created_by": org.CreatedBy,
+		})
+		return fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	org.Role = entity.OrgRoleOwner
+	org.CreatedAt = now
+	org.UpdatedAt = now
+	return nil
+}
+
+func (r *organizationRepositoryImpl) createWithOwner(ctx context.Context, org *entity.Organization, now time.Time) error {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO organizations (name, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`,
+		org.Name, org.CreatedBy, now, now).Scan(&org.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO organization_members (organization_id, user_id, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		org.ID, org.CreatedBy, entity.OrgRoleOwner, now, now)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *organizationRepositoryImpl) GetByID(ctx context.Context, id int) (*entity.Organization, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "organizations"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	org := &entity.Organization{}
+	err := r.db.DB.QueryRowContext(ctx, `
+		SELECT id, name, created_by, created_at, updated_at
+		FROM organizations
+		WHERE id = $1`, id).Scan(&org.ID, &org.Name, &org.CreatedBy, &org.CreatedAt, &org.UpdatedAt)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrOrgNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get organization", map[string]interface{}{
+			"organization_id": id,
+		})
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return org, nil
+}
+
+func (r *organizationRepositoryImpl) ListByUser(ctx context.Context, userID int) ([]*entity.Organization, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "organizations"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	orgs := make([]*entity.Organization, 0)
+	rows, err := r.db.DB.QueryContext(ctx, `
+		SELECT o.id, o.name, o.created_by, m.role, o.created_at, o.updated_at
+		FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY o.name, o.id`, userID)
+	if err == nil {
+		defer rows.Close()
+		for rows.Next() {
+			org := &entity.Organization{}
+			if err = rows.Scan(&org.ID, &org.Name, &org.CreatedBy, &org.Role, &org.CreatedAt, &org.UpdatedAt); err != nil {
+				break
+			}
+			orgs = append(orgs, org)
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to list organizations", map[string]interface{}{
+			"user_id": userID,
+		})
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	return orgs, nil
+}
+
+func (r *organizationRepositoryImpl) GetMembership(ctx context.Context, orgID, userID int) (*entity.Membership, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "organization_members"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	member := &entity.Membership{}
+	err := r.db.DB.QueryRowContext(ctx, `
+		SELECT organization_id, user_id, role, created_at, updated_at
+		FROM organization_members
+		WHERE organization_id = $1 AND user_id = $2`, orgID, userID).Scan(
+		&member.OrganizationID, &member.UserID, &member.Role, &member.CreatedAt, &member.UpdatedAt)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotOrgMember
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get membership", map[string]interface{}{
+			"organization_id": orgID,
+			"user_id":         userID,
+		})
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+
+	return member, nil
+}
+
+func (r *organizationRepositoryImpl) ListMembers(ctx context.Context, orgID int) ([]*entity.Membership, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "organization_members"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	members := make([]*entity.Membership, 0)
+	rows, err := r.db.DB.QueryContext(ctx, `
+		SELECT m.organization_id, m.user_id, u.username, u.email, m.role, m.created_at, m.updated_at
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = $1
+		ORDER BY m.created_at, m.user_id`, orgID)
+	if err == nil {
+		defer rows.Close()
+		for rows.Next() {
+			member := &entity.Membership{}
+			if err = rows.Scan(&member.OrganizationID, &member.UserID, &member.Username, &member.Email,
+				&member.Role, &member.CreatedAt, &member.UpdatedAt); err != nil {
+				break
+			}
+			members = append(members, member)
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to list members", map[string]interface{}{
+			"organization_id": orgID,
+		})
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+
+	return members, nil
+}
+
+func (r *organizationRepositoryImpl) AddMember(ctx context.Context, member *entity.Membership) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "organization_members"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	_, err := r.db.DB.ExecContext(ctx, `
+		INSERT INTO organization_members (organization_id, user_id, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		member.OrganizationID, member.UserID, member.Role, now, now)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return errors.ErrAlreadyOrgMember
+	}
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to add member", map[string]interface{}{
+			"organization_id": member.OrganizationID,
+			"user_id":         member.UserID,
+		})
+		return fmt.Errorf("failed to add member: %w", err)
+	}
+
+	member.CreatedAt = now
+	member.UpdatedAt = now
+	return nil
+}
+
+func (r *organizationRepositoryImpl) UpdateMemberRole(ctx context.Context, orgID, userID int, role string) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "organization_members"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.DB.ExecContext(ctx, `
+		UPDATE organization_members
+		SET role = $1, updated_at = $2
+		WHERE organization_id = $3 AND user_id = $4`,
+		role, time.Now(), orgID, userID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	return r.affectedMember(ctx, result, err, "Failed to update member role", orgID, userID)
+}
+
+func (r *organizationRepositoryImpl) RemoveMember(ctx context.Context, orgID, userID int) error {
+	start := time.Now()
+	operation := "DELETE"
+	table := "organization_members"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.DB.ExecContext(ctx, `
+		DELETE FROM organization_members
+		WHERE organization_id = $1 AND user_id = $2`, orgID, userID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	return r.affectedMember(ctx, result, err, "Failed to remove member", orgID, userID)
+}
+
+// affectedMember reports ErrNotOrgMember when a membership statement changed
+// no row
+func (r *organizationRepositoryImpl) affectedMember(ctx context.Context, result sql.Result, err error, message string, orgID, userID int) error {
+	if err == nil {
+		var affected int64
+		if affected, err = result.RowsAffected(); err == nil && affected == 0 {
+			return errors.ErrNotOrgMember
+		}
+	}
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, message, map[string]interface{}{
+			"organization_id": orgID,
+			"user_id":         userID,
+		})
+		return fmt.Errorf("failed to update membership: %w", err)
+	}
+	return nil
+}
+
+func (r *organizationRepositoryImpl) CountOwners(ctx context.Context, orgID int) (int, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "organization_members"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var count int
+	err := r.db.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM organization_members
+		WHERE organization_id = $1 AND role = $2`, orgID, entity.OrgRoleOwner).Scan(&count)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to count owners", map[string]interface{}{
+			"organization_id": orgID,
+		})
+		return 0, fmt.Errorf("failed to count owners: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *organizationRepositoryImpl) CreateInvitation(ctx context.Context, invitation *entity.Invitation) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "organization_invitations"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	err := r.db.DB.QueryRowContext(ctx, `
+		INSERT INTO organization_invitations (organization_id, email, role, token_hash, invited_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		invitation.OrganizationID, invitation.Email, invitation.Role, invitation.TokenHash,
+		invitation.InvitedBy, invitation.ExpiresAt, now).Scan(&invitation.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create invitation", map[string]interface{}{
+			"organization_id": invitation.OrganizationID,
+		})
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	invitation.CreatedAt = now
+	return nil
+}
+
+func (r *organizationRepositoryImpl) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*entity.Invitation, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "organization_invitations"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	invitation := &entity.Invitation{}
+	err := r.db.DB.QueryRowContext(ctx, `
+		SELECT id, organization_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at
+		FROM organization_invitations
+		WHERE token_hash = $1`, tokenHash).Scan(
+		&invitation.ID, &invitation.OrganizationID, &invitation.Email, &invitation.Role, &invitation.TokenHash,
+		&invitation.InvitedBy, &invitation.ExpiresAt, &invitation.AcceptedAt, &invitation.CreatedAt)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrInvitationNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get invitation", nil)
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+func (r *organizationRepositoryImpl) AcceptInvitation(ctx context.Context, invitation *entity.Invitation, member *entity.Membership) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "organization_invitations"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	err := r.acceptInvitation(ctx, invitation, member, now)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if errors.IsInvitationNotFound(err) {
+		return err
+	}
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return errors.ErrAlreadyOrgMember
+	}
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to accept invitation", map[string]interface{}{
+			"invitation_id": invitation.ID,
+			"user_id":       member.UserID,
+		})
+		return fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	invitation.AcceptedAt = &now
+	member.CreatedAt = now
+	member.UpdatedAt = now
+	return nil
+}
+
+func (r *organizationRepositoryImpl) acceptInvitation(ctx context.Context, invitation *entity.Invitation, member *entity.Membership, now time.Time) error {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Only the first acceptance of an invitation counts
+	result, err := tx.ExecContext(ctx, `
+		UPDATE organization_invitations
+		SET accepted_at = $1
+		WHERE id = $2 AND accepted_at IS NULL`, now, invitation.ID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errors.ErrInvitationNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO organization_members (organization_id, user_id, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		member.OrganizationID, member.UserID, member.Role, now, now)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+)
+
+// OTPRepository defines the contract for one-time code storage.
+// There is at most one pending code per purpose and phone number.
+type OTPRepository interface {
+	// Save stores the code, replacing any pending code for the purpose and phone.
+	Save(ctx context.Context, code *entity.OTPCode) error
+	Get(ctx context.Context, purpose, phone string) (*entity.OTPCode, error)
+	// ConsumeAttempt counts a verification attempt and returns the code with the
+	// updated attempt count, so concurrent guesses cannot exceed the limit.
+	ConsumeAttempt(ctx context.Context, purpose, phone string) (*entity.OTPCode, error)
+	Delete(ctx context.Context, purpose, phone string) error
+}
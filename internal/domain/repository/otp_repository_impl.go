@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const otpColumns = `id, purpose, phone, code_hash, attempts, expires_at, created_at`
+
+// otpRepositoryImpl implements the OTPRepository interface
+type otpRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewOTPRepository creates a new one-time code repository implementation
+func NewOTPRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) OTPRepository {
+	return &otpRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func scanOTP(row rowScanner) (*entity.OTPCode, error) {
+	code := &entity.OTPCode{}
+	err := row.Scan(&code.ID, &code.Purpose, &code.Phone, &code.CodeHash, &code.Attempts, &code.ExpiresAt, &code.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+func (r *otpRepositoryImpl) Save(ctx context.Context, code *entity.OTPCode) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "otp_codes"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO otp_codes (purpose, phone, code_hash, attempts, expires_at, created_at)
+		VALUES ($1, $2, $3, 0, $4, $5)
+		ON CONFLICT (purpose, phone) DO UPDATE
+		SET code_hash = EXCLUDED.code_hash, attempts = 0, expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at
+		RETURNING id`
+
+	code.CreatedAt = time.Now()
+	code.Attempts = 0
+	err := r.db.DB.QueryRowContext(ctx, query,
+		code.Purpose, code.Phone, code.CodeHash, code.ExpiresAt, code.CreatedAt).Scan(&code.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to save one-time code", map[string]interface{}{
+			"purpose": code.Purpose,
+		})
+		return fmt.Errorf("failed to save one-time code: %w", err)
+	}
+
+	return nil
+}
+
+func (r *otpRepositoryImpl) Get(ctx context.Context, purpose, phone string) (*entity.OTPCode, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "otp_codes"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + otpColumns + ` FROM otp_codes WHERE purpose = $1 AND phone = $2`
+
+	code, err := scanOTP(r.db.DB.QueryRowContext(ctx, query, purpose, phone))
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrOTPNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get one-time code", map[string]interface{}{
+			"purpose": purpose,
+		})
+		return nil, fmt.Errorf("failed to get one-time code: %w", err)
+	}
+
+	return code, nil
+}
+
+func (r *otpRepositoryImpl) ConsumeAttempt(ctx context.Context, purpose, phone string) (*entity.OTPCode, error) {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "otp_codes"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE otp_codes
+		SET attempts = attempts + 1
+		WHERE purpose = $1 AND phone = $2
+		RETURNING ` + otpColumns
+
+	code, err := scanOTP(r.db.DB.QueryRowContext(ctx, query, purpose, phone))
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrOTPNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to count one-time code attempt", map[string]interface{}{
+			"purpose": purpose,
+		})
+		return nil, fmt.Errorf("failed to count one-time code attempt: %w", err)
+	}
+
+	return code, nil
+}
+
+func (r *otpRepositoryImpl) Delete(ctx context.Context, purpose, phone string) error {
+	start := time.Now()
+	operation := "DELETE"
+	table := "otp_codes"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `DELETE FROM otp_codes WHERE purpose = $1 AND phone = $2`
+
+	_, err := r.db.DB.ExecContext(ctx, query, purpose, phone)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to delete one-time code", map[string]interface{}{
+			"purpose": purpose,
+		})
+		return fmt.Errorf("failed to delete one-time code: %w", err)
+	}
+
+	return nil
+}
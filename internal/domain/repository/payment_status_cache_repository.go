@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"time"
+)
+
+// PaymentStatusCacheRepository keeps the payment statuses read from the
+// payment provider for a short time, shared by every instance.
+type PaymentStatusCacheRepository interface {
+	// Get returns the cached status of the payment, or nil when it is not
+	// cached or has expired.
+	Get(ctx context.Context, paymentID string) (*entity.PaymentStatus, error)
+	// Set caches the status of the payment until expiresAt.
+	Set(ctx context.Context, paymentID string, status *entity.PaymentStatus, expiresAt time.Time) error
+	// Delete forgets the status of the payment, so the next read asks the provider.
+	Delete(ctx context.Context, paymentID string) error
+	// DeleteExpired removes the statuses past their expiry and returns how many.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
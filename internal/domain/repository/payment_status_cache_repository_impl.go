@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// paymentStatusCacheRepositoryImpl implements the PaymentStatusCacheRepository interface
+type paymentStatusCacheRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewPaymentStatusCacheRepository creates a new payment status cache repository implementation
+func NewPaymentStatusCacheRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) PaymentStatusCacheRepository {
+	return &paymentStatusCacheRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func (r *paymentStatusCacheRepositoryImpl) Get(ctx context.Context, paymentID string) (*entity.PaymentStatus, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "payment_status_cache"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT status, amount, updated_at
+		FROM payment_status_cache
+		WHERE payment_id = $1 AND expires_at > $2`
+
+	status := &entity.PaymentStatus{ID: paymentID}
+	err := r.db.DB.QueryRowContext(ctx, query, paymentID, time.Now()).Scan(&status.Status, &status.Amount, &status.UpdatedAt)
+	if err == sql.ErrNoRows {
+		status, err = nil, nil
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to get cached payment status", map[string]interface{}{
+			"payment_id": paymentID,
+		})
+		return nil, fmt.Errorf("failed to get cached payment status: %w", err)
+	}
+
+	return status, nil
+}
+
+func (r *paymentStatusCacheRepositoryImpl) Set(ctx context.Context, paymentID string, status *entity.PaymentStatus, expiresAt time.Time) error {
+	start := time.Now()
+	operation := "UPSERT"
+	table := "payment_status_cache"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO payment_status_cache (payment_id, status, amount, updated_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (payment_id) DO UPDATE
+		SET status = EXCLUDED.status, amount = EXCLUDED.amount,
+			updated_at = EXCLUDED.updated_at, expires_at = EXCLUDED.expires_at`
+
+	_, err := r.db.DB.ExecContext(ctx, query, paymentID, status.Status, status.Amount, status.UpdatedAt, expiresAt)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to cache payment status", map[string]interface{}{
+			"payment_id": paymentID,
+		})
+		return fmt.Errorf("failed to cache payment status: %w", err)
+	}
+
+	return nil
+}
+
+func (r *paymentStatusCacheRepositoryImpl) Delete(ctx context.Context, paymentID string) error {
+	start := time.Now()
+	operation := "DELETE"
+	table := "payment_status_cache"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.DB.ExecContext(ctx, `DELETE FROM payment_status_cache WHERE payment_id = $1`, paymentID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to delete cached payment status", map[string]interface{}{
+			"payment_id": paymentID,
+		})
+		return fmt.Errorf("failed to delete cached payment status: %w", err)
+	}
+
+	return nil
+}
+
+func (r *paymentStatusCacheRepositoryImpl) DeleteExpired(ctx context.Context) (int64, error) {
+	start := time.Now()
+	operation := "DELETE"
+	table := "payment_status_cache"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.DB.ExecContext(ctx, `DELETE FROM payment_status_cache WHERE expires_at <= $1`, time.Now())
+	var affected int64
+	if err == nil {
+		affected, err = result.RowsAffected()
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to delete expired payment statuses", nil)
+		return 0, fmt.Errorf("failed to delete expired payment statuses: %w", err)
+	}
+
+	return affected, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/redis"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// redisPaymentStatusCacheRepository keeps payment statuses in Redis as JSON
+// values expiring with the status, so nothing has to purge them
+type redisPaymentStatusCacheRepository struct {
+	client *redis.Client
+	prefix string
+	logger *logger.Logger
+}
+
+// NewRedisPaymentStatusCacheRepository creates a payment status cache keeping
+// the statuses under keys starting with prefix
+func NewRedisPaymentStatusCacheRepository(client *redis.Client, prefix string, log *logger.Logger) PaymentStatusCacheRepository {
+	return &redisPaymentStatusCacheRepository{
+		client: client,
+		prefix: prefix,
+		logger: log,
+	}
+}
+
+func (r *redisPaymentStatusCacheRepository) Get(ctx context.Context, paymentID string) (*entity.PaymentStatus, error) {
+	reply, err := r.client.Do(ctx, "GET", r.prefix+paymentID)
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to get cached payment status", map[string]interface{}{
+			"payment_id": paymentID,
+		})
+		return nil, fmt.Errorf("failed to get cached payment status: %w", err)
+	}
+	value, ok := reply.(string)
+	if !ok {
+		return nil, nil
+	}
+
+	status := &entity.PaymentStatus{}
+	if err := json.Unmarshal([]byte(value), status); err != nil {
+		return nil, fmt.Errorf("failed to decode cached payment status: %w", err)
+	}
+	status.ID = paymentID
+	return status, nil
+}
+
+func (r *redisPaymentStatusCacheRepository) Set(ctx context.Context, paymentID string, status *entity.PaymentStatus, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt).Milliseconds()
+	if ttl <= 0 {
+		return r.Delete(ctx, paymentID)
+	}
+	value, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to encode payment status: %w", err)
+	}
+
+	if _, err := r.client.Do(ctx, "SET", r.prefix+paymentID, value, "PX", ttl); err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to cache payment status", map[string]interface{}{
+			"payment_id": paymentID,
+		})
+		return fmt.Errorf("failed to cache payment status: %w", err)
+	}
+	return nil
+}
+
+func (r *redisPaymentStatusCacheRepository) Delete(ctx context.Context, paymentID string) error {
+	if _, err := r.client.Do(ctx, "DEL", r.prefix+paymentID); err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to delete cached payment status", map[string]interface{}{
+			"payment_id": paymentID,
+		})
+		return fmt.Errorf("failed to delete cached payment status: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes nothing: Redis expires the statuses itself
+func (r *redisPaymentStatusCacheRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/redis"
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveRedis answers GET, SET and DEL from a map, recording the arguments of
+// every command
+func serveRedis(t *testing.T) (string, func() [][]string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	var mu sync.Mutex
+	values := make(map[string]string)
+	var commands [][]string
+	handle := func(args []string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		commands = append(commands, args)
+		switch args[0] {
+		case "GET":
+			value, ok := values[args[1]]
+			if !ok {
+				return "$-1\r\n"
+			}
+			return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+		case "SET":
+			values[args[1]] = args[2]
+			return "+OK\r\n"
+		case "DEL":
+			delete(values, args[1])
+			return ":1\r\n"
+		default:
+			return "-ERR unknown command\r\n"
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					// A command is an array of bulk strings, each a length line and a value line
+					header, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					n, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+					args := make([]string, n)
+					for i := range args {
+						if _, err := r.ReadString('\n'); err != nil {
+							return
+						}
+						value, err := r.ReadString('\n')
+						if err != nil {
+							return
+						}
+						args[i] = strings.TrimSuffix(value, "\r\n")
+					}
+					conn.Write([]byte(handle(args)))
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), func() [][]string {
+		mu.Lock()
+		defer mu.Unlock()
+		return commands
+	}
+}
+
+func TestRedisPaymentStatusCacheRepository(t *testing.T) {
+	addr, commands := serveRedis(t)
+	client := redis.New(redis.Options{Addr: addr, Timeout: time.Second})
+	defer client.Close()
+	cache := NewRedisPaymentStatusCacheRepository(client, "payment_status:", logger.NewLogger())
+	ctx := context.Background()
+
+	status, err := cache.Get(ctx, "pi_1")
+	require.NoError(t, err)
+	assert.Nil(t, status, "not cached")
+
+	updatedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, cache.Set(ctx, "pi_1", &entity.PaymentStatus{ID: "pi_1", Status: "succeeded", Amount: 12.5, UpdatedAt: updatedAt}, time.Now().Add(time.Minute)))
+	set := commands()[1]
+	assert.Equal(t, []string{"SET", "payment_status:pi_1"}, set[:2])
+	assert.Equal(t, "PX", set[3])
+	ttl, _ := strconv.Atoi(set[4])
+	assert.InDelta(t, time.Minute.Milliseconds(), ttl, 1000, "expires with the status")
+
+	status, err = cache.Get(ctx, "pi_1")
+	require.NoError(t, err)
+	assert.Equal(t, &entity.PaymentStatus{ID: "pi_1", Status: "succeeded", Amount: 12.5, UpdatedAt: updatedAt}, status)
+
+	require.NoError(t, cache.Delete(ctx, "pi_1"))
+	status, err = cache.Get(ctx, "pi_1")
+	require.NoError(t, err)
+	assert.Nil(t, status, "deleted")
+
+	deleted, err := cache.DeleteExpired(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+)
+
+// ProviderUsageRepository keeps the daily usage of the paid providers
+type ProviderUsageRepository interface {
+	// Add adds calls and cost to the usage of the provider on the day, a
+	// YYYY-MM-DD date, and returns the day's total.
+	Add(ctx context.Context, provider, day string, calls int64, cost float64) (*entity.ProviderUsage, error)
+	// List returns the usage of the days from since on, newest first.
+	List(ctx context.Context, since string) ([]*entity.ProviderUsage, error)
+}
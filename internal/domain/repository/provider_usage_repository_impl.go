@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"fmt"
+	"time"
+)
+
+// providerUsageRepositoryImpl implements the ProviderUsageRepository interface
+type providerUsageRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewProviderUsageRepository creates a new provider usage repository implementation
+func NewProviderUsageRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) ProviderUsageRepository {
+	return &providerUsageRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func (r *providerUsageRepositoryImpl) Add(ctx context.Context, provider, day string, calls int64, cost float64) (*entity.ProviderUsage, error) {
+	start := time.Now()
+	operation := "INSERT"
+	table := "provider_usage"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO provider_usage (provider, day, calls, cost, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (provider, day) DO UPDATE
+		SET calls = provider_usage.calls + EXCLUDED.calls,
+		    cost = provider_usage.cost + EXCLUDED.cost,
+		    updated_at = EXCLUDED.updated_at
+		RETURNING calls, cost`
+
+	usage := &entity.ProviderUsage{Provider: provider, Day: day}
+	err := r.db.DB.QueryRowContext(ctx, query, provider, day, calls, cost, time.Now()).Scan(&usage.Calls, &usage.Cost)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to add provider usage", map[string]interface{}{
+			"provider": provider,
+			"day":      day,
+		})
+		return nil, fmt.Errorf("failed to add provider usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+func (r *providerUsageRepositoryImpl) List(ctx context.Context, since string) ([]*entity.ProviderUsage, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "provider_usage"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT provider, to_char(day, 'YYYY-MM-DD'), calls, cost
+		FROM provider_usage
+		WHERE day >= $1
+		ORDER BY day DESC, provider`
+
+	usage := make([]*entity.ProviderUsage, 0)
+	rows, err := r.db.DB.QueryContext(ctx, query, since)
+	if err == nil {
+		for rows.Next() {
+			u := &entity.ProviderUsage{}
+			if err = rows.Scan(&u.Provider, &u.Day, &u.Calls, &u.Cost); err != nil {
+				break
+			}
+			usage = append(usage, u)
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		rows.Close()
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to list provider usage", nil)
+		return nil, fmt.Errorf("failed to list provider usage: %w", err)
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"time"
+)
+
+// ReportRepository defines the reporting tables, daily rollups of the orders
+// and users tables. Days are UTC dates and ranges include both ends.
+type ReportRepository interface {
+	// Rollup replaces the rollups of the day with aggregates of the orders
+	// placed and users created on it
+	Rollup(ctx context.Context, day time.Time) error
+	Revenue(ctx context.Context, from, to time.Time, groupBy string) ([]entity.RevenueReportRow, error)
+	Signups(ctx context.Context, from, to time.Time, groupBy string) ([]entity.SignupReportRow, error)
+}
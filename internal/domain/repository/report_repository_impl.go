@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"fmt"
+	"time"
+)
+
+// reportRepositoryImpl implements the ReportRepository interface
+type reportRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewReportRepository creates a new report repository implementation
+func NewReportRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) ReportRepository {
+	return &reportRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func (r *reportRepositoryImpl) Rollup(ctx context.Context, day time.Time) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "daily_revenue"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := r.rollup(ctx, day)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to roll up reports", map[string]interface{}{
+			"day": day.Format("2006-01-02"),
+		})
+		return fmt.Errorf("failed to roll up reports: %w", err)
+	}
+	return nil
+}
+
+// rollup replaces the rows of the day in one transaction, so a day without
+// orders or sign-ups left after erasures has no stale rows
+func (r *reportRepositoryImpl) rollup(ctx context.Context, day time.Time) error {
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	now := time.Now()
+
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM daily_revenue WHERE day = $1`, from); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO daily_revenue (day, currency, orders, completed, refunded, revenue, refunded_amount, updated_at)
+		SELECT $1::date, currency, COUNT(*),
+			COUNT(*) FILTER (WHERE status = $4),
+			COUNT(*) FILTER (WHERE status = $5),
+			COALESCE(SUM(amount) FILTER (WHERE status = $4), 0),
+			COALESCE(SUM(amount) FILTER (WHERE status = $5), 0),
+			$3
+		FROM orders
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY currency`,
+		from, to, now, entity.OrderStatusCompleted, entity.OrderStatusRefunded)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM daily_signups WHERE day = $1`, from); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO daily_signups (day, signups, updated_at)
+		SELECT $1::date, COUNT(*), $3
+		FROM users
+		WHERE created_at >= $1 AND created_at < $2
+		HAVING COUNT(*) > 0`,
+		from, to, now)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *reportRepositoryImpl) Revenue(ctx context.Context, from, to time.Time, groupBy string) ([]entity.RevenueReportRow, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "daily_revenue"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows := make([]entity.RevenueReportRow, 0)
+	err := func() error {
+		result, err := r.db.DB.QueryContext(ctx, `
+			SELECT to_char(date_trunc($3, day), 'YYYY-MM-DD') AS period, currency,
+				SUM(orders), SUM(completed), SUM(refunded), SUM(revenue), SUM(refunded_amount)
+			FROM daily_revenue
+			WHERE day >= $1 AND day <= $2
+			GROUP BY period, currency
+			ORDER BY period, currency`,
+			from, to, groupBy)
+		if err != nil {
+			return err
+		}
+		defer result.Close()
+
+		for result.Next() {
+			var row entity.RevenueReportRow
+			if err := result.Scan(&row.Period, &row.Currency, &row.Orders, &row.Completed, &row.Refunded, &row.Revenue, &row.RefundedAmount); err != nil {
+				return err
+			}
+			rows = append(rows, row)
+		}
+		return result.Err()
+	}()
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to query revenue report", nil)
+		return nil, fmt.Errorf("failed to query revenue report: %w", err)
+	}
+	return rows, nil
+}
+
+func (r *reportRepositoryImpl) Signups(ctx context.Context, from, to time.Time, groupBy string) ([]entity.SignupReportRow, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "daily_signups"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows := make([]entity.SignupReportRow, 0)
+	err := func() error {
+		result, err := r.db.DB.QueryContext(ctx, `
+			SELECT to_char(date_trunc($3, day), 'YYYY-MM-DD') AS period, SUM(signups)
+			FROM daily_signups
+			WHERE day >= $1 AND day <= $2
+			GROUP BY period
+			ORDER BY period`,
+			from, to, groupBy)
+		if err != nil {
+			return err
+		}
+		defer result.Close()
+
+		for result.Next() {
+			var row entity.SignupReportRow
+			if err := result.Scan(&row.Period, &row.Signups); err != nil {
+				return err
+			}
+			rows = append(rows, row)
+		}
+		return result.Err()
+	}()
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to query sign-up report", nil)
+		return nil, fmt.Errorf("failed to query sign-up report: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"time"
+)
+
+// SagaRepository persists the progress of sagas, so sagas interrupted by a
+// crash can be compensated.
+type SagaRepository interface {
+	// Create records a new saga, setting its ID and timestamps.
+	Create(ctx context.Context, saga *entity.Saga) error
+	// Update records the status, steps, state and error of the saga.
+	Update(ctx context.Context, saga *entity.Saga) error
+	// ClaimStale moves up to limit running or compensating sagas last updated
+	// before the given time to compensating and returns them. Concurrent
+	// callers claim different sagas.
+	ClaimStale(ctx context.Context, before time.Time, limit int) ([]*entity.Saga, error)
+	// DeleteFinished removes the completed, compensated and halted sagas last
+	// updated before the given time and returns how many.
+	DeleteFinished(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const sagaColumns = `id, name, reference, status, steps, state, error, created_at, updated_at`
+
+// sagaRepositoryImpl implements the SagaRepository interface
+type sagaRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewSagaRepository creates a new saga repository implementation
+func NewSagaRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) SagaRepository {
+	return &sagaRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func scanSaga(row rowScanner) (*entity.Saga, error) {
+	saga := &entity.Saga{}
+	var steps, state []byte
+	err := row.Scan(&saga.ID, &saga.Name, &saga.Reference, &saga.Status, &steps, &state, &saga.Error, &saga.CreatedAt, &saga.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(steps, &saga.Steps); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(state, &saga.State); err != nil {
+		return nil, err
+	}
+	return saga, nil
+}
+
+// encodeSaga returns the steps and state of the saga as JSON
+func encodeSaga(saga *entity.Saga) ([]byte, []byte, error) {
+	steps, err := json.Marshal(saga.Steps)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode saga steps: %w", err)
+	}
+	state, err := json.Marshal(saga.State)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode saga state: %w", err)
+	}
+	return steps, state, nil
+}
+
+func (r *sagaRepositoryImpl) Create(ctx context.Context, saga *entity.Saga) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "sagas"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	steps, state, err := encodeSaga(saga)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO sagas (name, reference, status, steps, state, error, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		RETURNING id`
+
+	now := time.Now()
+	saga.CreatedAt, saga.UpdatedAt = now, now
+	err = r.db.DB.QueryRowContext(ctx, query, saga.Name, saga.Reference, saga.Status, steps, state, saga.Error, now).Scan(&saga.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create saga", map[string]interface{}{
+			"saga":      saga.Name,
+			"reference": saga.Reference,
+		})
+		return fmt.Errorf("failed to create saga: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sagaRepositoryImpl) Update(ctx context.Context, saga *entity.Saga) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "sagas"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	steps, state, err := encodeSaga(saga)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE sagas
+		SET status = $1, steps = $2, state = $3, error = $4, updated_at = $5
+		WHERE id = $6`
+
+	saga.UpdatedAt = time.Now()
+	_, err = r.db.DB.ExecContext(ctx, query, saga.Status, steps, state, saga.Error, saga.UpdatedAt, saga.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to update saga", map[string]interface{}{
+			"saga_id": saga.ID,
+			"status":  saga.Status,
+		})
+		return fmt.Errorf("failed to update saga: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sagaRepositoryImpl) ClaimStale(ctx context.Context, before time.Time, limit int) ([]*entity.Saga, error) {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "sagas"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE sagas
+		SET status = $1, updated_at = $2
+		WHERE id IN (
+			SELECT id FROM sagas
+			WHERE status IN ($3, $1) AND updated_at < $4
+			ORDER BY updated_at
+			LIMIT $5
+			FOR UPDATE SKIP LOCKED)
+		RETURNING ` + sagaColumns
+
+	sagas := make([]*entity.Saga, 0, limit)
+	rows, err := r.db.DB.QueryContext(ctx, query,
+		entity.SagaStatusCompensating, time.Now(), entity.SagaStatusRunning, before, limit)
+	if err == nil {
+		for rows.Next() {
+			var saga *entity.Saga
+			if saga, err = scanSaga(rows); err != nil {
+				break
+			}
+			sagas = append(sagas, saga)
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		rows.Close()
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to claim stale sagas", nil)
+		return nil, fmt.Errorf("failed to claim stale sagas: %w", err)
+	}
+
+	return sagas, nil
+}
+
+func (r *sagaRepositoryImpl) DeleteFinished(ctx context.Context, before time.Time) (int64, error) {
+	start := time.Now()
+	operation := "DELETE"
+	table := "sagas"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `DELETE FROM sagas WHERE status IN ($1, $2, $3) AND updated_at < $4`
+
+	result, err := r.db.DB.ExecContext(ctx, query,
+		entity.SagaStatusCompleted, entity.SagaStatusCompensated, entity.SagaStatusHalted, before)
+	var affected int64
+	if err == nil {
+		affected, err = result.RowsAffected()
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to delete finished sagas", nil)
+		return 0, fmt.Errorf("failed to delete finished sagas: %w", err)
+	}
+
+	return affected, nil
+}
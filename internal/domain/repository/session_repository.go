@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"time"
+)
+
+// SessionRepository defines the contract for session data operations.
+type SessionRepository interface {
+	Create(ctx context.Context, session *entity.Session) error
+	GetByTokenID(ctx context.Context, tokenID string) (*entity.Session, error)
+	ListActiveByUserID(ctx context.Context, userID int) ([]*entity.Session, error)
+	ListByUserIDSince(ctx context.Context, userID int, since time.Time) ([]*entity.Session, error)
+	Revoke(ctx context.Context, userID, sessionID int) error
+	RevokeAllByUserID(ctx context.Context, userID int) error
+	Touch(ctx context.Context, tokenID string, seenAt time.Time) error
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// sessionRepositoryImpl implements the SessionRepository interface
+type sessionRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewSessionRepository creates a new session repository implementation
+func NewSessionRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) SessionRepository {
+	return &sessionRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func (r *sessionRepositoryImpl) Create(ctx context.Context, session *entity.Session) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "sessions"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO sessions (user_id, token_id, device, ip_address, location, created_at, last_seen_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`
+
+	now := time.Now()
+	err := r.db.DB.QueryRowContext(ctx, query,
+		session.UserID, session.TokenID, session.Device, session.IPAddress, session.Location,
+		now, now, session.ExpiresAt).Scan(&session.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create session", map[string]interface{}{
+			"user_id": session.UserID,
+		})
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	session.CreatedAt = now
+	session.LastSeenAt = now
+	return nil
+}
+
+func (r *sessionRepositoryImpl) GetByTokenID(ctx context.Context, tokenID string) (*entity.Session, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "sessions"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, token_id, device, ip_address, location, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE token_id = $1`
+
+	session := &entity.Session{}
+	err := r.db.DB.QueryRowContext(ctx, query, tokenID).Scan(
+		&session.ID, &session.UserID, &session.TokenID, &session.Device, &session.IPAddress,
+		&session.Location, &session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt, &session.RevokedAt)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrSessionNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get session by token ID", nil)
+		return nil, fmt.Errorf("failed to get session by token id: %w", err)
+	}
+
+	return session, nil
+}
+
+func (r *sessionRepositoryImpl) ListActiveByUserID(ctx context.Context, userID int) ([]*entity.Session, error) {
+	query := `
+		SELECT id, user_id, token_id, device, ip_address, location, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_seen_at DESC`
+
+	return r.listByUserID(ctx, query, userID, time.Now())
+}
+
+// ListByUserIDSince returns the most recent sessions created since the time,
+// revoked and expired ones included, newest first.
+func (r *sessionRepositoryImpl) ListByUserIDSince(ctx context.Context, userID int, since time.Time) ([]*entity.Session, error) {
+	query := `
+		SELECT id, user_id, token_id, device, ip_address, location, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE user_id = $1 AND created_at >= $2
+		ORDER BY created_at DESC
+		LIMIT 500`
+
+	return r.listByUserID(ctx, query, userID, since)
+}
+
+func (r *sessionRepositoryImpl) listByUserID(ctx context.Context, query string, userID int, arg interface{}) ([]*entity.Session, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "sessions"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.DB.QueryContext(ctx, query, userID, arg)
+	if err != nil {
+		duration := time.Since(start)
+		r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+		r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]*entity.Session, 0)
+	for rows.Next() {
+		session := &entity.Session{}
+		if err = rows.Scan(
+			&session.ID, &session.UserID, &session.TokenID, &session.Device, &session.IPAddress,
+			&session.Location, &session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt, &session.RevokedAt); err != nil {
+			break
+		}
+		sessions = append(sessions, session)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to list sessions", map[string]interface{}{
+			"user_id": userID,
+		})
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+func (r *sessionRepositoryImpl) Revoke(ctx context.Context, userID, sessionID int) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "sessions"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE sessions
+		SET revoked_at = $1
+		WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL`
+
+	result, err := r.db.DB.ExecContext(ctx, query, time.Now(), sessionID, userID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to revoke session", map[string]interface{}{
+			"user_id":    userID,
+			"session_id": sessionID,
+		})
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if affected == 0 {
+		return errors.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *sessionRepositoryImpl) RevokeAllByUserID(ctx context.Context, userID int) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "sessions"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE sessions
+		SET revoked_at = $1
+		WHERE user_id = $2 AND revoked_at IS NULL`
+
+	_, err := r.db.DB.ExecContext(ctx, query, time.Now(), userID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to revoke sessions", map[string]interface{}{
+			"user_id": userID,
+		})
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sessionRepositoryImpl) Touch(ctx context.Context, tokenID string, seenAt time.Time) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "sessions"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE sessions
+		SET last_seen_at = $1
+		WHERE token_id = $2 AND revoked_at IS NULL`
+
+	_, err := r.db.DB.ExecContext(ctx, query, seenAt, tokenID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to update session last seen", nil)
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+)
+
+// SSORepository defines the contract for single sign-on connection, sign-in
+// and identity data operations.
+type SSORepository interface {
+	// UpsertConnection creates or replaces the connection of the
+	// organization. Identities linked through it are dropped when its
+	// issuer changes, since subjects are only unique per issuer.
+	UpsertConnection(ctx context.Context, conn *entity.SSOConnection) error
+	GetConnection(ctx context.Context, id int) (*entity.SSOConnection, error)
+	// GetConnectionByOrganization returns ErrSSONotConfigured when the
+	// organization has no connection.
+	GetConnectionByOrganization(ctx context.Context, orgID int) (*entity.SSOConnection, error)
+	// DeleteConnection removes the connection with its sign-ins and
+	// identities, returning ErrSSONotConfigured when there is none.
+	DeleteConnection(ctx context.Context, orgID int) error
+
+	// CreateLogin stores the sign-in and purges expired ones.
+	CreateLogin(ctx context.Context, login *entity.SSOLogin) error
+	// ConsumeLogin deletes and returns the unexpired sign-in with the state
+	// hash, returning ErrInvalidSSOState when there is none.
+	ConsumeLogin(ctx context.Context, stateHash string) (*entity.SSOLogin, error)
+
+	// GetIdentity returns ErrIdentityNotFound for unknown subjects.
+	GetIdentity(ctx context.Context, connectionID int, subject string) (*entity.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *entity.UserIdentity) error
+}
@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ssoRepositoryImpl implements the SSORepository interface
+type ssoRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewSSORepository creates a new single sign-on repository implementation
+func NewSSORepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) SSORepository {
+	return &ssoRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+const ssoConnectionColumns = `id, organization_id, protocol, issuer, client_id, client_secret,
+		username_claim, jit_provisioning, created_at, updated_at`
+
+func scanSSOConnection(row interface{ Scan(...interface{}) error }) (*entity.SSOConnection, error) {
+	conn := &entity.SSOConnection{}
+	err := row.Scan(&conn.ID, &conn.OrganizationID, &conn.Protocol, &conn.Issuer, &conn.ClientID, &conn.ClientSecret,
+		&conn.UsernameClaim, &conn.JITProvisioning, &conn.CreatedAt, &conn.UpdatedAt)
+	return conn, err
+}
+
+func (r *ssoRepositoryImpl) UpsertConnection(ctx context.Context, conn *entity.SSOConnection) error {
+	start := time.Now()
+	operation := "UPSERT"
+	table := "sso_connections"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	err := r.upsertConnection(ctx, conn, now)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to save SSO connection", map[string]interface{}{
+			"organization_id": conn.OrganizationID,
+		})
+		return fmt.Errorf("failed to save SSO connection: %w", err)
+	}
+
+	conn.UpdatedAt = now
+	return nil
+}
+
+func (r *ssoRepositoryImpl) upsertConnection(ctx context.Context, conn *entity.SSOConnection, now time.Time) error {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var previousIssuer sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT issuer FROM sso_connections
+		WHERE organization_id = $1
+		FOR UPDATE`, conn.OrganizationID).Scan(&previousIssuer)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO sso_connections (organization_id, protocol, issuer, client_id, client_secret,
+			username_claim, jit_provisioning, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		ON CONFLICT (organization_id) DO UPDATE
+		SET protocol = EXCLUDED.protocol, issuer = EXCLUDED.issuer, client_id = EXCLUDED.client_id,
+			client_secret = EXCLUDED.client_secret, username_claim = EXCLUDED.username_claim,
+			jit_provisioning = EXCLUDED.jit_provisioning, updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at`,
+		conn.OrganizationID, conn.Protocol, conn.Issuer, conn.ClientID, conn.ClientSecret,
+		conn.UsernameClaim, conn.JITProvisioning, now).Scan(&conn.ID, &conn.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if previousIssuer.Valid && previousIssuer.String != conn.Issuer {
+		if _, err = tx.ExecContext(ctx, `DELETE FROM user_identities WHERE connection_id = $1`, conn.ID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *ssoRepositoryImpl) GetConnection(ctx context.Context, id int) (*entity.SSOConnection, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "sso_connections"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	conn, err := scanSSOConnection(r.db.DB.QueryRowContext(ctx, `
+		SELECT `+ssoConnectionColumns+`
+		FROM sso_connections
+		WHERE id = $1`, id))
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrSSONotConfigured
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get SSO connection", map[string]interface{}{
+			"connection_id": id,
+		})
+		return nil, fmt.Errorf("failed to get SSO connection: %w", err)
+	}
+
+	return conn, nil
+}
+
+func (r *ssoRepositoryImpl) GetConnectionByOrganization(ctx context.Context, orgID int) (*entity.SSOConnection, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "sso_connections"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	conn, err := scanSSOConnection(r.db.DB.QueryRowContext(ctx, `
+		SELECT `+ssoConnectionColumns+`
+		FROM sso_connections
+		WHERE organization_id = $1`, orgID))
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrSSONotConfigured
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get SSO connection", map[string]interface{}{
+			"organization_id": orgID,
+		})
+		return nil, fmt.Errorf("failed to get SSO connection: %w", err)
+	}
+
+	return conn, nil
+}
+
+func (r *ssoRepositoryImpl) DeleteConnection(ctx context.Context, orgID int) error {
+	start := time.Now()
+	operation := "DELETE"
+	table := "sso_connections"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.DB.ExecContext(ctx, `
+		DELETE FROM sso_connections
+		WHERE organization_id = $1`, orgID)
+	if err == nil {
+		var affected int64
+		if affected, err = result.RowsAffected(); err == nil && affected == 0 {
+			err = errors.ErrSSONotConfigured
+		}
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if errors.IsSSONotConfigured(err) {
+		return err
+	}
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to delete SSO connection", map[string]interface{}{
+			"organization_id": orgID,
+		})
+		return fmt.Errorf("failed to delete SSO connection: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ssoRepositoryImpl) CreateLogin(ctx context.Context, login *entity.SSOLogin) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "sso_logins"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	// Abandoned sign-ins are purged as new ones start
+	_, err := r.db.DB.ExecContext(ctx, `
+		WITH purged AS (
+			DELETE FROM sso_logins WHERE expires_at <= $5
+		)
+		INSERT INTO sso_logins (state_hash, connection_id, nonce, code_verifier, expires_at)
+		VALUES ($1, $2, $3, $4, $6)`,
+		login.StateHash, login.ConnectionID, login.Nonce, login.CodeVerifier, time.Now(), login.ExpiresAt)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create SSO login", map[string]interface{}{
+			"connection_id": login.ConnectionID,
+		})
+		return fmt.Errorf("failed to create SSO login: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ssoRepositoryImpl) ConsumeLogin(ctx context.Context, stateHash string) (*entity.SSOLogin, error) {
+	start := time.Now()
+	operation := "DELETE"
+	table := "sso_logins"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	login := &entity.SSOLogin{}
+	err := r.db.DB.QueryRowContext(ctx, `
+		DELETE FROM sso_logins
+		WHERE state_hash = $1 AND expires_at > $2
+		RETURNING state_hash, connection_id, nonce, code_verifier, expires_at`,
+		stateHash, time.Now()).Scan(&login.StateHash, &login.ConnectionID, &login.Nonce, &login.CodeVerifier, &login.ExpiresAt)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrInvalidSSOState
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to consume SSO login", nil)
+		return nil, fmt.Errorf("failed to consume SSO login: %w", err)
+	}
+
+	return login, nil
+}
+
+func (r *ssoRepositoryImpl) GetIdentity(ctx context.Context, connectionID int, subject string) (*entity.UserIdentity, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "user_identities"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	identity := &entity.UserIdentity{}
+	err := r.db.DB.QueryRowContext(ctx, `
+		SELECT id, user_id, connection_id, subject, created_at
+		FROM user_identities
+		WHERE connection_id = $1 AND subject = $2`, connectionID, subject).Scan(
+		&identity.ID, &identity.UserID, &identity.ConnectionID, &identity.Subject, &identity.CreatedAt)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrIdentityNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get identity", map[string]interface{}{
+			"connection_id": connectionID,
+		})
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	return identity, nil
+}
+
+func (r *ssoRepositoryImpl) CreateIdentity(ctx context.Context, identity *entity.UserIdentity) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "user_identities"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	err := r.db.DB.QueryRowContext(ctx, `
+		INSERT INTO user_identities (user_id, connection_id, subject, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`,
+		identity.UserID, identity.ConnectionID, identity.Subject, now).Scan(&identity.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create identity", map[string]interface{}{
+			"connection_id": identity.ConnectionID,
+			"user_id":       identity.UserID,
+		})
+		return fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	identity.CreatedAt = now
+	return nil
+}
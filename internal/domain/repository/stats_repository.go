@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"time"
+)
+
+// StatsRepository defines the aggregate queries of the operations dashboard.
+// Each returns one row per UTC day with activity at or after since, oldest first.
+type StatsRepository interface {
+	NewUsersByDay(ctx context.Context, since time.Time) ([]entity.DailyCount, error)
+	OrdersByDay(ctx context.Context, since time.Time) ([]entity.DailyOrderStats, error)
+	NotificationsByDay(ctx context.Context, since time.Time) ([]entity.DailyDeliveryStats, error)
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// statsRepositoryImpl implements the StatsRepository interface
+type statsRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewStatsRepository creates a new stats repository implementation
+func NewStatsRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) StatsRepository {
+	return &statsRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func (r *statsRepositoryImpl) NewUsersByDay(ctx context.Context, since time.Time) ([]entity.DailyCount, error) {
+	query := `
+		SELECT to_char(created_at, 'YYYY-MM-DD') AS day, COUNT(*)
+		FROM users
+		WHERE created_at >= $1
+		GROUP BY day
+		ORDER BY day`
+
+	days := make([]entity.DailyCount, 0)
+	err := r.aggregate(ctx, "users", query, since, func(rows *sql.Rows) error {
+		var day entity.DailyCount
+		if err := rows.Scan(&day.Date, &day.Count); err != nil {
+			return err
+		}
+		days = append(days, day)
+		return nil
+	})
+	return days, err
+}
+
+func (r *statsRepositoryImpl) OrdersByDay(ctx context.Context, since time.Time) ([]entity.DailyOrderStats, error) {
+	query := `
+		SELECT to_char(created_at, 'YYYY-MM-DD') AS day, currency, COUNT(*),
+			COUNT(*) FILTER (WHERE status = $2),
+			COUNT(*) FILTER (WHERE status = $3),
+			COALESCE(SUM(amount) FILTER (WHERE status = $2), 0)
+		FROM orders
+		WHERE created_at >= $1
+		GROUP BY day, currency
+		ORDER BY day, currency`
+
+	days := make([]entity.DailyOrderStats, 0)
+	err := r.aggregate(ctx, "orders", query, since, func(rows *sql.Rows) error {
+		var day entity.DailyOrderStats
+		if err := rows.Scan(&day.Date, &day.Currency, &day.Orders, &day.Completed, &day.Refunded, &day.Revenue); err != nil {
+			return err
+		}
+		days = append(days, day)
+		return nil
+	}, entity.OrderStatusCompleted, entity.OrderStatusRefunded)
+	return days, err
+}
+
+func (r *statsRepositoryImpl) NotificationsByDay(ctx context.Context, since time.Time) ([]entity.DailyDeliveryStats, error) {
+	query := `
+		SELECT to_char(created_at, 'YYYY-MM-DD') AS day, COALESCE(SUM(sent), 0), COALESCE(SUM(failed), 0)
+		FROM notifications
+		WHERE created_at >= $1
+		GROUP BY day
+		ORDER BY day`
+
+	days := make([]entity.DailyDeliveryStats, 0)
+	err := r.aggregate(ctx, "notifications", query, since, func(rows *sql.Rows) error {
+		var day entity.DailyDeliveryStats
+		if err := rows.Scan(&day.Date, &day.Sent, &day.Failed); err != nil {
+			return err
+		}
+		days = append(days, day)
+		return nil
+	})
+	return days, err
+}
+
+// aggregate runs an aggregate query over table for rows created since,
+// passing each result row to scan
+func (r *statsRepositoryImpl) aggregate(ctx context.Context, table, query string, since time.Time, scan func(*sql.Rows) error, args ...interface{}) error {
+	start := time.Now()
+	operation := "SELECT"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.DB.QueryContext(ctx, query, append([]interface{}{since.UTC()}, args...)...)
+	if err == nil {
+		for rows.Next() {
+			if err = scan(rows); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		rows.Close()
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to aggregate "+table, nil)
+		return fmt.Errorf("failed to aggregate %s: %w", table, err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+)
+
+// UploadRepository defines the contract for resumable upload data operations.
+type UploadRepository interface {
+	Create(ctx context.Context, upload *entity.Upload) error
+	// GetByID returns the upload with the parts received so far.
+	GetByID(ctx context.Context, id int) (*entity.Upload, error)
+	// ListByUser returns the uploads of the user, without their parts, oldest first.
+	ListByUser(ctx context.Context, userID int) ([]*entity.Upload, error)
+	// SavePart records a received part, replacing an earlier copy of it.
+	SavePart(ctx context.Context, uploadID int, part *entity.UploadPart) error
+	// UpdateStatus saves the status and scan status of the upload, and its
+	// completion time once completed.
+	UpdateStatus(ctx context.Context, upload *entity.Upload) error
+	// UpdateScan saves the scan result of the upload and where its file is kept.
+	UpdateScan(ctx context.Context, upload *entity.Upload) error
+}
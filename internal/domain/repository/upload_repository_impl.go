@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// uploadRepositoryImpl implements the UploadRepository interface
+type uploadRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewUploadRepository creates a new upload repository implementation
+func NewUploadRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) UploadRepository {
+	return &uploadRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func (r *uploadRepositoryImpl) Create(ctx context.Context, upload *entity.Upload) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "uploads"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO uploads (user_id, organization_id, file_id, file_name, content_type, size, part_size, total_parts,
+			storage_upload_id, status, scan_status, created_at, updated_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id`
+
+	now := time.Now()
+	upload.CreatedAt = now
+	upload.UpdatedAt = now
+	err := r.db.DB.QueryRowContext(ctx, query,
+		upload.UserID, upload.OrganizationID, upload.FileID, upload.FileName, upload.ContentType, upload.Size, upload.PartSize,
+		upload.TotalParts, upload.StorageUploadID, upload.Status, upload.ScanStatus, upload.CreatedAt, upload.UpdatedAt,
+		upload.ExpiresAt,
+	).Scan(&upload.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create upload", map[string]interface{}{
+			"user_id": upload.UserID,
+		})
+		return fmt.Errorf("failed to create upload: %w", err)
+	}
+
+	return nil
+}
+
+func (r *uploadRepositoryImpl) GetByID(ctx context.Context, id int) (*entity.Upload, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "uploads"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, organization_id, file_id, file_name, content_type, size, part_size, total_parts,
+			storage_upload_id, status, scan_status, scan_threat, scanned_at, created_at, updated_at,
+			expires_at, completed_at
+		FROM uploads
+		WHERE id = $1`
+
+	upload := &entity.Upload{}
+	err := r.db.DB.QueryRowContext(ctx, query, id).Scan(
+		&upload.ID, &upload.UserID, &upload.OrganizationID, &upload.FileID, &upload.FileName, &upload.ContentType, &upload.Size,
+		&upload.PartSize, &upload.TotalParts, &upload.StorageUploadID, &upload.Status, &upload.ScanStatus,
+		&upload.ScanThreat, &upload.ScannedAt, &upload.CreatedAt, &upload.UpdatedAt, &upload.ExpiresAt, &upload.CompletedAt)
+	if err == nil {
+		upload.Parts, err = r.getParts(ctx, id)
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrUploadNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get upload by ID", map[string]interface{}{
+			"upload_id": id,
+		})
+		return nil, fmt.Errorf("failed to get upload by id: %w", err)
+	}
+
+	return upload, nil
+}
+
+func (r *uploadRepositoryImpl) ListByUser(ctx context.Context, userID int) ([]*entity.Upload, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "uploads"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, organization_id, file_id, file_name, content_type, size, part_size, total_parts,
+			storage_upload_id, status, scan_status, scan_threat, scanned_at, created_at, updated_at,
+			expires_at, completed_at
+		FROM uploads
+		WHERE user_id = $1
+		ORDER BY id`
+
+	uploads := make([]*entity.Upload, 0)
+	rows, err := r.db.DB.QueryContext(ctx, query, userID)
+	if err == nil {
+		for rows.Next() {
+			upload := &entity.Upload{}
+			if err = rows.Scan(&upload.ID, &upload.UserID, &upload.OrganizationID, &upload.FileID, &upload.FileName,
+				&upload.ContentType, &upload.Size, &upload.PartSize, &upload.TotalParts, &upload.StorageUploadID,
+				&upload.Status, &upload.ScanStatus, &upload.ScanThreat, &upload.ScannedAt, &upload.CreatedAt,
+				&upload.UpdatedAt, &upload.ExpiresAt, &upload.CompletedAt); err != nil {
+				break
+			}
+			uploads = append(uploads, upload)
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		rows.Close()
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to list uploads", map[string]interface{}{
+			"user_id": userID,
+		})
+		return nil, fmt.Errorf("failed to list uploads: %w", err)
+	}
+
+	return uploads, nil
+}
+
+// getParts returns the parts received for an upload, ordered by part number.
+func (r *uploadRepositoryImpl) getParts(ctx context.Context, uploadID int) ([]entity.UploadPart, error) {
+	query := `
+		SELECT part_number, size, etag, uploaded_at
+		FROM upload_parts
+		WHERE upload_id = $1
+		ORDER BY part_number`
+
+	rows, err := r.db.DB.QueryContext(ctx, query, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	parts := []entity.UploadPart{}
+	for rows.Next() {
+		var part entity.UploadPart
+		if err := rows.Scan(&part.PartNumber, &part.Size, &part.ETag, &part.UploadedAt); err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	return parts, rows.Err()
+}
+
+func (r *uploadRepositoryImpl) SavePart(ctx context.Context, uploadID int, part *entity.UploadPart) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "upload_parts"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO upload_parts (upload_id, part_number, size, etag, uploaded_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (upload_id, part_number)
+		DO UPDATE SET size = EXCLUDED.size, etag = EXCLUDED.etag, uploaded_at = EXCLUDED.uploaded_at`
+
+	part.UploadedAt = time.Now()
+	_, err := r.db.DB.ExecContext(ctx, query, uploadID, part.PartNumber, part.Size, part.ETag, part.UploadedAt)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to save upload part", map[string]interface{}{
+			"upload_id":   uploadID,
+			"part_number": part.PartNumber,
+		})
+		return fmt.Errorf("failed to save upload part: %w", err)
+	}
+
+	return nil
+}
+
+func (r *uploadRepositoryImpl) UpdateStatus(ctx context.Context, upload *entity.Upload) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "uploads"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE uploads
+		SET status = $1, scan_status = $2, updated_at = $3, completed_at = $4
+		WHERE id = $5`
+
+	now := time.Now()
+	var completedAt *time.Time
+	if upload.Status == entity.UploadStatusCompleted {
+		completedAt = &now
+	}
+	_, err := r.db.DB.ExecContext(ctx, query, upload.Status, upload.ScanStatus, now, completedAt, upload.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to update upload status", map[string]interface{}{
+			"upload_id": upload.ID,
+			"status":    upload.Status,
+		})
+		return fmt.Errorf("failed to update upload status: %w", err)
+	}
+
+	upload.UpdatedAt = now
+	upload.CompletedAt = completedAt
+	return nil
+}
+
+func (r *uploadRepositoryImpl) UpdateScan(ctx context.Context, upload *entity.Upload) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "uploads"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE uploads
+		SET file_id = $1, scan_status = $2, scan_threat = $3, scanned_at = $4, updated_at = $5
+		WHERE id = $6`
+
+	now := time.Now()
+	_, err := r.db.DB.ExecContext(ctx, query, upload.FileID, upload.ScanStatus, upload.ScanThreat, upload.ScannedAt, now, upload.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to update upload scan", map[string]interface{}{
+			"upload_id":   upload.ID,
+			"scan_status": upload.ScanStatus,
+		})
+		return fmt.Errorf("failed to update upload scan: %w", err)
+	}
+
+	upload.UpdatedAt = now
+	return nil
+}
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+)
+
+// UserChangeRepository defines the read access to the change history of
+// users, which UserRepository writes along with each update.
+type UserChangeRepository interface {
+	List(ctx context.Context, filter entity.UserChangeFilter) ([]*entity.UserChange, int, error)
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// userChangeRepositoryImpl implements the UserChangeRepository interface
+type userChangeRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewUserChangeRepository creates a new user change repository implementation
+func NewUserChangeRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) UserChangeRepository {
+	return &userChangeRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+// List returns one page of the user's changes, newest first, together with
+// the total number of matching changes.
+func (r *userChangeRepositoryImpl) List(ctx context.Context, filter entity.UserChangeFilter) ([]*entity.UserChange, int, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "user_changes"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	where := `user_id = $1`
+	args := []interface{}{filter.UserID}
+	if filter.Field != "" {
+		field, _ := json.Marshal([]map[string]string{{"field": filter.Field}})
+		args = append(args, string(field))
+		where += ` AND changes @> $2::jsonb`
+	}
+
+	var total int
+	err := r.db.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM user_changes WHERE `+where, args...).Scan(&total)
+
+	changes := make([]*entity.UserChange, 0)
+	if err == nil && total > 0 {
+		query := `
+			SELECT id, user_id, COALESCE(changed_by, 0), COALESCE(impersonator_id, 0), source, changes, created_at
+			FROM user_changes
+			WHERE ` + where + fmt.Sprintf(`
+			ORDER BY created_at DESC, id DESC
+			LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+		var rows *sql.Rows
+		rows, err = r.db.DB.QueryContext(ctx, query, append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)...)
+		if err == nil {
+			for rows.Next() {
+				change := &entity.UserChange{}
+				var fields []byte
+				if err = rows.Scan(&change.ID, &change.UserID, &change.ChangedBy, &change.ImpersonatorID,
+					&change.Source, &fields, &change.CreatedAt); err != nil {
+					break
+				}
+				if err = json.Unmarshal(fields, &change.Changes); err != nil {
+					break
+				}
+				changes = append(changes, change)
+			}
+			if err == nil {
+				err = rows.Err()
+			}
+			rows.Close()
+		}
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to list user changes", map[string]interface{}{
+			"user_id": filter.UserID,
+		})
+		return nil, 0, fmt.Errorf("failed to list user changes: %w", err)
+	}
+
+	return changes, total, nil
+}
+
+// recordUserChange adds the changes of the user made by the actor to its
+// history, in the transaction of the update. Nothing is recorded when no
+// field changed.
+func recordUserChange(ctx context.Context, tx *sql.Tx, userID int, actor *entity.Actor, changes []entity.FieldChange, now time.Time) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	fields, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO user_changes (user_id, changed_by, impersonator_id, source, changes, created_at)
+		VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), $4, $5, $6)`,
+		userID, actor.UserID, actor.ImpersonatorID, actor.Source, fields, now)
+	return err
+}
+
+// scrubUserChanges drops the values from the history of an erased user,
+// keeping which fields changed, when and by whom
+func scrubUserChanges(ctx context.Context, tx *sql.Tx, userID int) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE user_changes
+		SET changes = (SELECT COALESCE(jsonb_agg(change - 'from' - 'to'), '[]') FROM jsonb_array_elements(changes) change)
+		WHERE user_id = $1`, userID)
+	return err
+}
+
+// userChanges lists the fields written by Update that differ between the
+// stored user and the updated one
+func userChanges(stored, updated *entity.User) []entity.FieldChange {
+	var changes []entity.FieldChange
+	add := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, entity.FieldChange{Field: field, From: &from, To: &to})
+		}
+	}
+
+	add("username", stored.Username, updated.Username)
+	add("email", stored.Email, updated.Email)
+	add("pending_email", stored.PendingEmail, updated.PendingEmail)
+	if stored.Password != updated.Password {
+		changes = append(changes, entity.FieldChange{Field: "password"})
+	}
+	add("first_name", stored.FirstName, updated.FirstName)
+	add("last_name", stored.LastName, updated.LastName)
+	add("avatar_url", stored.AvatarURL, updated.AvatarURL)
+	add("locale", stored.Locale, updated.Locale)
+	add("timezone", stored.Timezone, updated.Timezone)
+	add("login_alerts_disabled", strconv.FormatBool(stored.LoginAlertsDisabled), strconv.FormatBool(updated.LoginAlertsDisabled))
+	add("external_id", stored.ExternalID, updated.ExternalID)
+	changes = addTimeChange(changes, "deactivated_at", stored.DeactivatedAt, updated.DeactivatedAt)
+	return changes
+}
+
+// addTimeChange adds the change of a nullable time field, unset ones having
+// no value
+func addTimeChange(changes []entity.FieldChange, field string, from, to *time.Time) []entity.FieldChange {
+	format := func(t *time.Time) *string {
+		if t == nil {
+			return nil
+		}
+		value := t.UTC().Format(time.RFC3339)
+		return &value
+	}
+	fromValue, toValue := format(from), format(to)
+	if (fromValue == nil) != (toValue == nil) || (fromValue != nil && *fromValue != *toValue) {
+		changes = append(changes, entity.FieldChange{Field: field, From: fromValue, To: toValue})
+	}
+	return changes
+}
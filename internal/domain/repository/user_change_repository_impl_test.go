@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserChanges(t *testing.T) {
+	deactivatedAt := time.Date(2024, 5, 1, 8, 30, 0, 0, time.FixedZone("WIB", 7*3600))
+	stored := &entity.User{Username: "alice", Email: "alice@example.com", Password: "old-hash", Locale: "en"}
+	updated := *stored
+	updated.Email = "alice@example.org"
+	updated.Password = "new-hash"
+	updated.Locale = ""
+	updated.LoginAlertsDisabled = true
+	updated.DeactivatedAt = &deactivatedAt
+
+	changes := userChanges(stored, &updated)
+
+	require.Len(t, changes, 5)
+	assert.Equal(t, "email", changes[0].Field)
+	assert.Equal(t, "alice@example.com", *changes[0].From)
+	assert.Equal(t, "alice@example.org", *changes[0].To)
+	assert.Equal(t, entity.FieldChange{Field: "password"}, changes[1], "password values are never recorded")
+	assert.Equal(t, "locale", changes[2].Field)
+	assert.Equal(t, "", *changes[2].To, "a cleared field has an empty value")
+	assert.Equal(t, "login_alerts_disabled", changes[3].Field)
+	assert.Equal(t, "true", *changes[3].To)
+	assert.Equal(t, "deactivated_at", changes[4].Field)
+	assert.Nil(t, changes[4].From)
+	assert.Equal(t, "2024-05-01T01:30:00Z", *changes[4].To)
+
+	assert.Empty(t, userChanges(stored, stored))
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+)
+
+// UserImportRepository defines the contract for background user import data operations.
+type UserImportRepository interface {
+	// Create stores a processing import together with the rows to import.
+	Create(ctx context.Context, report *entity.UserImportReport, rows []entity.UserImportRow) error
+	GetByID(ctx context.Context, id int) (*entity.UserImportReport, error)
+	GetRows(ctx context.Context, id int) ([]entity.UserImportRow, error)
+	// Complete saves the final report and discards the stored rows.
+	Complete(ctx context.Context, report *entity.UserImportReport) error
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// userImportRepositoryImpl implements the UserImportRepository interface
+type userImportRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewUserImportRepository creates a new user import repository implementation
+func NewUserImportRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) UserImportRepository {
+	return &userImportRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func (r *userImportRepositoryImpl) Create(ctx context.Context, report *entity.UserImportReport, rows []entity.UserImportRow) error {
+	start := time.Now()
+	operation := "INSERT"
+	table := "user_imports"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	payload, err := json.Marshal(rows)
+	if err != nil {
+		return fmt.Errorf("failed to encode import rows: %w", err)
+	}
+
+	query := `
+		INSERT INTO user_imports (status, total, rows, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
+
+	now := time.Now()
+	err = r.db.DB.QueryRowContext(ctx, query, report.Status, report.Total, payload, now).Scan(&report.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create user import", map[string]interface{}{
+			"total": report.Total,
+		})
+		return fmt.Errorf("failed to create user import: %w", err)
+	}
+
+	report.CreatedAt = &now
+	return nil
+}
+
+func (r *userImportRepositoryImpl) GetByID(ctx context.Context, id int) (*entity.UserImportReport, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "user_imports"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, status, total, created, failed, results, error, created_at, completed_at
+		FROM user_imports
+		WHERE id = $1`
+
+	report := &entity.UserImportReport{}
+	var results []byte
+	err := r.db.DB.QueryRowContext(ctx, query, id).Scan(
+		&report.ID, &report.Status, &report.Total, &report.Created, &report.Failed,
+		&results, &report.Error, &report.CreatedAt, &report.CompletedAt)
+	if err == nil && len(results) > 0 {
+		err = json.Unmarshal(results, &report.Results)
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrImportNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get user import by ID", map[string]interface{}{
+			"import_id": id,
+		})
+		return nil, fmt.Errorf("failed to get user import by id: %w", err)
+	}
+
+	return report, nil
+}
+
+func (r *userImportRepositoryImpl) GetRows(ctx context.Context, id int) ([]entity.UserImportRow, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "user_imports"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT rows FROM user_imports WHERE id = $1`
+
+	var payload []byte
+	err := r.db.DB.QueryRowContext(ctx, query, id).Scan(&payload)
+
+	var rows []entity.UserImportRow
+	if err == nil && len(payload) > 0 {
+		err = json.Unmarshal(payload, &rows)
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrImportNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get user import rows", map[string]interface{}{
+			"import_id": id,
+		})
+		return nil, fmt.Errorf("failed to get user import rows: %w", err)
+	}
+
+	return rows, nil
+}
+
+func (r *userImportRepositoryImpl) Complete(ctx context.Context, report *entity.UserImportReport) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "user_imports"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	results, err := json.Marshal(report.Results)
+	if err != nil {
+		return fmt.Errorf("failed to encode import results: %w", err)
+	}
+
+	query := `
+		UPDATE user_imports
+		SET status = $1, created = $2, failed = $3, results = $4, error = $5, rows = NULL, completed_at = $6
+		WHERE id = $7`
+
+	now := time.Now()
+	_, err = r.db.DB.ExecContext(ctx, query,
+		report.Status, report.Created, report.Failed, results, report.Error, now, report.ID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to complete user import", map[string]interface{}{
+			"import_id": report.ID,
+		})
+		return fmt.Errorf("failed to complete user import: %w", err)
+	}
+
+	report.CompletedAt = &now
+	return nil
+}
@@ -3,14 +3,32 @@ package repository
 import (
 	"boilerplate-go/internal/domain/entity"
 	"context"
+	"time"
 )
 
 // UserRepository defines the contract for user data operations.
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) error
+	CreateBatch(ctx context.Context, users []*entity.User) error
+	// Upsert creates the user or updates the one with the same email, keeping
+	// its password and role, and reports whether the user was created.
+	Upsert(ctx context.Context, user *entity.User) (bool, error)
 	GetByID(ctx context.Context, id int) (*entity.User, error)
+	// GetByUsername returns the user with the username, ignoring case.
 	GetByUsername(ctx context.Context, username string) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
+	// List returns a page of the users matching the filter and their number.
+	List(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int, error)
+	// IterateActiveUsers streams the users that are neither deactivated nor
+	// anonymized in ID order from a single query, passing each to fn as it is
+	// read. An error returned by fn stops the iteration and is returned as is.
+	IterateActiveUsers(ctx context.Context, fn func(*entity.User) error) error
+	// GetByPhone returns the user who verified the phone number.
+	GetByPhone(ctx context.Context, phone string) (*entity.User, error)
+	UpdatePhone(ctx context.Context, id int, phone string, verifiedAt *time.Time) error
+	// Update saves the user only if its stored version is still the one it
+	// was read at, bumping it, and returns ErrConflict otherwise.
 	Update(ctx context.Context, user *entity.User) error
 	Delete(ctx context.Context, id int) error
+	Anonymize(ctx context.Context, id int) error
 }
@@ -9,9 +9,19 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+// anonymizedEmailDomain is the domain of the addresses of anonymized users
+const anonymizedEmailDomain = "anonymized.invalid"
+
+const userColumns = `id, username, email, COALESCE(pending_email, ''), password, role, first_name, last_name, COALESCE(phone, ''), phone_verified_at,
+	avatar_url, locale, timezone, login_alerts_disabled, COALESCE(external_id, ''), deactivated_at, version, COALESCE(updated_by, 0),
+	created_at, updated_at`
+
 // userRepositoryImpl implements the UserRepository interface
 type userRepositoryImpl struct {
 	db      *database.PostgresDB
@@ -32,15 +42,19 @@ func (r *userRepositoryImpl) Create(ctx context.Context, user *entity.User) erro
 	start := time.Now()
 	operation := "INSERT"
 	table := "users"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
 
 	query := `
-		INSERT INTO users (username, email, password, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO users (username, email, password, first_name, last_name, avatar_url, locale, timezone,
+			external_id, deactivated_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11, $12)
 		RETURNING id`
 
 	now := time.Now()
 	err := r.db.DB.QueryRowContext(ctx, query,
-		user.Username, user.Email, user.Password, now, now).Scan(&user.ID)
+		user.Username, user.Email, user.Password, user.FirstName, user.LastName,
+		user.AvatarURL, user.Locale, user.Timezone, user.ExternalID, user.DeactivatedAt, now, now).Scan(&user.ID)
 
 	// Record metrics and logs
 	duration := time.Since(start)
@@ -48,6 +62,9 @@ func (r *userRepositoryImpl) Create(ctx context.Context, user *entity.User) erro
 	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
 
 	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			return errors.ErrUserAlreadyExists
+		}
 		r.logger.ErrorLogger(ctx, err, "Failed to create user", map[string]interface{}{
 			"username": user.Username,
 			"email":    user.Email,
@@ -57,23 +74,177 @@ func (r *userRepositoryImpl) Create(ctx context.Context, user *entity.User) erro
 
 	user.CreatedAt = now
 	user.UpdatedAt = now
+	user.Version = 1
+	return nil
+}
+
+// CreateBatch inserts users with a single multi-row INSERT. Rows conflicting with an
+// existing username or email are skipped and keep a zero ID.
+func (r *userRepositoryImpl) CreateBatch(ctx context.Context, users []*entity.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	operation := "INSERT"
+	table := "users"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	placeholders := make([]string, 0, len(users))
+	args := make([]interface{}, 0, len(users)*5)
+	for i, user := range users {
+		n := i * 5
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+		args = append(args, user.Username, user.Email, user.Password, now, now)
+	}
+
+	query := `
+		INSERT INTO users (username, email, password, created_at, updated_at)
+		VALUES ` + strings.Join(placeholders, ", ") + `
+		ON CONFLICT DO NOTHING
+		RETURNING id, username`
+
+	rows, err := r.db.DB.QueryContext(ctx, query, args...)
+	if err == nil {
+		created := make(map[string]int, len(users))
+		for rows.Next() {
+			var (
+				id       int
+				username string
+			)
+			if err = rows.Scan(&id, &username); err != nil {
+				break
+			}
+			created[username] = id
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		rows.Close()
+
+		for _, user := range users {
+			if id, ok := created[user.Username]; ok {
+				user.ID = id
+				user.CreatedAt = now
+				user.UpdatedAt = now
+				user.Version = 1
+			}
+		}
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to create user batch", map[string]interface{}{
+			"batch_size": len(users),
+		})
+		return fmt.Errorf("failed to create users: %w", err)
+	}
+
 	return nil
 }
 
+func scanUser(row rowScanner, extra ...interface{}) (*entity.User, error) {
+	user := &entity.User{}
+	err := row.Scan(append([]interface{}{
+		&user.ID, &user.Username, &user.Email, &user.PendingEmail, &user.Password, &user.Role, &user.FirstName, &user.LastName,
+		&user.Phone, &user.PhoneVerifiedAt, &user.AvatarURL, &user.Locale, &user.Timezone,
+		&user.LoginAlertsDisabled, &user.ExternalID, &user.DeactivatedAt, &user.Version, &user.UpdatedBy, &user.CreatedAt, &user.UpdatedAt}, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Upsert inserts the user, or updates the user with the same email address:
+// its username, external ID and names. The password and role of an existing
+// user are kept. It reports whether the user was created.
+func (r *userRepositoryImpl) Upsert(ctx context.Context, user *entity.User) (bool, error) {
+	start := time.Now()
+	operation := "UPSERT"
+	table := "users"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	stored, created, err := r.upsert(ctx, user, time.Now())
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		// Another user holds the username or external ID
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			return false, errors.ErrUserAlreadyExists
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to upsert user", map[string]interface{}{
+			"username": user.Username,
+			"email":    user.Email,
+		})
+		return false, fmt.Errorf("failed to upsert user: %w", err)
+	}
+
+	*user = *stored
+	return created, nil
+}
+
+func (r *userRepositoryImpl) upsert(ctx context.Context, user *entity.User, now time.Time) (*entity.User, bool, error) {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	previous, err := scanUser(tx.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE LOWER(email) = LOWER($1) FOR UPDATE`, user.Email))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, false, err
+	}
+
+	// xmax is zero for rows inserted by the statement
+	query := `
+		INSERT INTO users (username, email, password, external_id, first_name, last_name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		ON CONFLICT ((LOWER(email))) DO UPDATE
+		SET username = EXCLUDED.username, external_id = EXCLUDED.external_id,
+			first_name = EXCLUDED.first_name, last_name = EXCLUDED.last_name, updated_at = EXCLUDED.updated_at,
+			version = users.version + 1, updated_by = NULLIF($8, 0)
+		RETURNING ` + userColumns + `, (xmax = 0)`
+
+	actor := entity.ActorFromContext(ctx)
+	var created bool
+	stored, err := scanUser(tx.QueryRowContext(ctx, query,
+		user.Username, user.Email, user.Password, user.ExternalID, user.FirstName, user.LastName, now, actor.UserID), &created)
+	if err != nil {
+		return nil, false, err
+	}
+	if !created && previous != nil {
+		if err := recordUserChange(ctx, tx, stored.ID, actor, userChanges(previous, stored), now); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return stored, created, tx.Commit()
+}
+
 func (r *userRepositoryImpl) GetByID(ctx context.Context, id int) (*entity.User, error) {
 	start := time.Now()
 	operation := "SELECT"
 	table := "users"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
 
 	query := `
-		SELECT id, username, email, password, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1`
 
-	user := &entity.User{}
-	err := r.db.DB.QueryRowContext(ctx, query, id).Scan(
-		&user.ID, &user.Username, &user.Email, &user.Password,
-		&user.CreatedAt, &user.UpdatedAt)
+	user, err := scanUser(r.db.DB.QueryRowContext(ctx, query, id))
 
 	// Record metrics and logs
 	duration := time.Since(start)
@@ -93,20 +264,129 @@ func (r *userRepositoryImpl) GetByID(ctx context.Context, id int) (*entity.User,
 	return user, nil
 }
 
+// List returns a page of the users matching the filter, oldest first, and the
+// number of users matching it
+func (r *userRepositoryImpl) List(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int, error) {
+	start := time.Now()
+	operation := "SELECT"
+	table := "users"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	conditions := []string{"TRUE"}
+	var args []interface{}
+	addCondition := func(clause string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(clause, len(args)))
+	}
+
+	if filter.Username != "" {
+		addCondition("LOWER(username) = LOWER($%d)", filter.Username)
+	}
+	if filter.Email != "" {
+		addCondition("LOWER(email) = LOWER($%d)", filter.Email)
+	}
+	if filter.ExternalID != "" {
+		addCondition("external_id = $%d", filter.ExternalID)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	err := r.db.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE `+where, args...).Scan(&total)
+
+	users := make([]*entity.User, 0)
+	if err == nil && total > filter.Offset {
+		query := `
+			SELECT ` + userColumns + `
+			FROM users
+			WHERE ` + where + fmt.Sprintf(`
+			ORDER BY id
+			LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+		var rows *sql.Rows
+		rows, err = r.db.DB.QueryContext(ctx, query, append(args, filter.Limit, filter.Offset)...)
+		if err == nil {
+			for rows.Next() {
+				var user *entity.User
+				if user, err = scanUser(rows); err != nil {
+					break
+				}
+				users = append(users, user)
+			}
+			if err == nil {
+				err = rows.Err()
+			}
+			rows.Close()
+		}
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to list users", nil)
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// IterateActiveUsers streams the users who are neither deactivated nor
+// anonymized, oldest first
+func (r *userRepositoryImpl) IterateActiveUsers(ctx context.Context, fn func(*entity.User) error) error {
+	start := time.Now()
+	operation := "SELECT"
+	table := "users"
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE deactivated_at IS NULL AND email NOT LIKE $1
+		ORDER BY id`
+
+	err := streamRows(ctx, r.db, query, []interface{}{"%@" + anonymizedEmailDomain}, func(rows *sql.Rows) error {
+		user, err := scanUser(rows)
+		if err != nil {
+			return err
+		}
+		return yield(fn(user))
+	})
+	fnErr := stopped(err)
+	if fnErr != nil {
+		err = nil
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to iterate users", nil)
+		return fmt.Errorf("failed to iterate users: %w", err)
+	}
+
+	return nil
+}
+
 func (r *userRepositoryImpl) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
 	start := time.Now()
 	operation := "SELECT"
 	table := "users"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
 
 	query := `
-		SELECT id, username, email, password, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE username = $1`
+		WHERE LOWER(username) = LOWER($1)`
 
-	user := &entity.User{}
-	err := r.db.DB.QueryRowContext(ctx, query, username).Scan(
-		&user.ID, &user.Username, &user.Email, &user.Password,
-		&user.CreatedAt, &user.UpdatedAt)
+	user, err := scanUser(r.db.DB.QueryRowContext(ctx, query, username))
 
 	// Record metrics and logs
 	duration := time.Since(start)
@@ -130,16 +410,15 @@ func (r *userRepositoryImpl) GetByEmail(ctx context.Context, email string) (*ent
 	start := time.Now()
 	operation := "SELECT"
 	table := "users"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
 
 	query := `
-		SELECT id, username, email, password, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE email = $1`
+		WHERE LOWER(email) = LOWER($1)`
 
-	user := &entity.User{}
-	err := r.db.DB.QueryRowContext(ctx, query, email).Scan(
-		&user.ID, &user.Username, &user.Email, &user.Password,
-		&user.CreatedAt, &user.UpdatedAt)
+	user, err := scanUser(r.db.DB.QueryRowContext(ctx, query, email))
 
 	// Record metrics and logs
 	duration := time.Since(start)
@@ -159,26 +438,134 @@ func (r *userRepositoryImpl) GetByEmail(ctx context.Context, email string) (*ent
 	return user, nil
 }
 
-func (r *userRepositoryImpl) Update(ctx context.Context, user *entity.User) error {
+// GetByPhone returns the user who verified the phone number.
+func (r *userRepositoryImpl) GetByPhone(ctx context.Context, phone string) (*entity.User, error) {
 	start := time.Now()
-	operation := "UPDATE"
+	operation := "SELECT"
 	table := "users"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
 
 	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE phone = $1 AND phone_verified_at IS NOT NULL`
+
+	user, err := scanUser(r.db.DB.QueryRowContext(ctx, query, phone))
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrUserNotFound
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to get user by phone", nil)
+		return nil, fmt.Errorf("failed to get user by phone: %w", err)
+	}
+
+	return user, nil
+}
+
+// UpdatePhone sets the user's phone number; verifiedAt is nil until the number is confirmed.
+// Verifying a number already verified by another user fails with ErrPhoneAlreadyInUse.
+// It leaves the version alone, as Update never writes the phone number.
+func (r *userRepositoryImpl) UpdatePhone(ctx context.Context, id int, phone string, verifiedAt *time.Time) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "users"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := r.updatePhone(ctx, id, phone, verifiedAt, time.Now())
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			return errors.ErrPhoneAlreadyInUse
+		}
+		if errors.IsUserNotFound(err) {
+			return err
+		}
+		r.logger.ErrorLogger(ctx, err, "Failed to update user phone", map[string]interface{}{
+			"user_id": id,
+		})
+		return fmt.Errorf("failed to update user phone: %w", err)
+	}
+
+	return nil
+}
+
+func (r *userRepositoryImpl) updatePhone(ctx context.Context, id int, phone string, verifiedAt *time.Time, now time.Time) error {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var (
+		stored           string
+		storedVerifiedAt *time.Time
+	)
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(phone, ''), phone_verified_at FROM users WHERE id = $1 FOR UPDATE`, id).
+		Scan(&stored, &storedVerifiedAt)
+	if err == sql.ErrNoRows {
+		return errors.ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	actor := entity.ActorFromContext(ctx)
+	_, err = tx.ExecContext(ctx, `
 		UPDATE users
-		SET username = $1, email = $2, password = $3, updated_at = $4
-		WHERE id = $5`
+		SET phone = $1, phone_verified_at = $2, updated_at = $3, updated_by = NULLIF($4, 0)
+		WHERE id = $5`, phone, verifiedAt, now, actor.UserID, id)
+	if err != nil {
+		return err
+	}
+
+	var changes []entity.FieldChange
+	if stored != phone {
+		changes = append(changes, entity.FieldChange{Field: "phone", From: &stored, To: &phone})
+	}
+	changes = addTimeChange(changes, "phone_verified_at", storedVerifiedAt, verifiedAt)
+	if err := recordUserChange(ctx, tx, id, actor, changes, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *userRepositoryImpl) Update(ctx context.Context, user *entity.User) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "users"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
 
-	user.UpdatedAt = time.Now()
-	_, err := r.db.DB.ExecContext(ctx, query,
-		user.Username, user.Email, user.Password, user.UpdatedAt, user.ID)
+	now := time.Now()
+	actor := entity.ActorFromContext(ctx)
+	stored, err := r.update(ctx, user, actor, now)
 
 	// Record metrics and logs
 	duration := time.Since(start)
 	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
 	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
 
+	if err == sql.ErrNoRows {
+		return errors.ErrUserNotFound
+	}
 	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			return errors.ErrUserAlreadyExists
+		}
 		r.logger.ErrorLogger(ctx, err, "Failed to update user", map[string]interface{}{
 			"user_id":  user.ID,
 			"username": user.Username,
@@ -186,14 +573,58 @@ func (r *userRepositoryImpl) Update(ctx context.Context, user *entity.User) erro
 		})
 		return fmt.Errorf("failed to update user: %w", err)
 	}
+	if stored.Version != user.Version {
+		return fmt.Errorf("%w: user %d is no longer at version %d", errors.ErrConflict, user.ID, user.Version)
+	}
 
+	user.UpdatedAt = now
+	user.UpdatedBy = actor.UserID
+	user.Version++
 	return nil
 }
 
+// update saves the user unless its stored version differs, recording the
+// change, and returns the user as stored before the update
+func (r *userRepositoryImpl) update(ctx context.Context, user *entity.User, actor *entity.Actor, now time.Time) (*entity.User, error) {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stored, err := scanUser(tx.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1 FOR UPDATE`, user.ID))
+	if err != nil || stored.Version != user.Version {
+		return stored, err
+	}
+
+	query := `
+		UPDATE users
+		SET username = $1, email = $2, password = $3, first_name = $4, last_name = $5,
+			avatar_url = $6, locale = $7, timezone = $8, login_alerts_disabled = $9,
+			external_id = NULLIF($10, ''), deactivated_at = $11, updated_at = $12, updated_by = NULLIF($13, 0),
+			pending_email = NULLIF($14, ''), version = version + 1
+		WHERE id = $15`
+
+	_, err = tx.ExecContext(ctx, query,
+		user.Username, user.Email, user.Password, user.FirstName, user.LastName,
+		user.AvatarURL, user.Locale, user.Timezone, user.LoginAlertsDisabled,
+		user.ExternalID, user.DeactivatedAt, now, actor.UserID, user.PendingEmail, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordUserChange(ctx, tx, user.ID, actor, userChanges(stored, user), now); err != nil {
+		return nil, err
+	}
+
+	return stored, tx.Commit()
+}
+
 func (r *userRepositoryImpl) Delete(ctx context.Context, id int) error {
 	start := time.Now()
 	operation := "DELETE"
 	table := "users"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
 
 	query := `DELETE FROM users WHERE id = $1`
 
@@ -213,3 +644,73 @@ func (r *userRepositoryImpl) Delete(ctx context.Context, id int) error {
 
 	return nil
 }
+
+// Anonymize scrubs personally identifiable data from a user row while keeping
+// the row itself so that references from financial records stay intact. The
+// values in its change history, and where its consents were given from, are
+// scrubbed too.
+func (r *userRepositoryImpl) Anonymize(ctx context.Context, id int) error {
+	start := time.Now()
+	operation := "UPDATE"
+	table := "users"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := r.anonymize(ctx, id, time.Now())
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to anonymize user", map[string]interface{}{
+			"user_id": id,
+		})
+		return fmt.Errorf("failed to anonymize user: %w", err)
+	}
+
+	return nil
+}
+
+// anonymizedFields are the fields Anonymize erases
+var anonymizedFields = []string{"username", "email", "pending_email", "password", "first_name", "last_name", "avatar_url", "phone", "phone_verified_at"}
+
+func (r *userRepositoryImpl) anonymize(ctx context.Context, id int, now time.Time) error {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	actor := entity.ActorFromContext(ctx)
+	query := `
+		UPDATE users
+		SET username = $1, email = $2, pending_email = NULL, password = '', first_name = '', last_name = '', avatar_url = '',
+			phone = NULL, phone_verified_at = NULL, updated_at = $3, updated_by = NULLIF($4, 0), version = version + 1
+		WHERE id = $5`
+
+	_, err = tx.ExecContext(ctx, query,
+		fmt.Sprintf("deleted_user_%d", id),
+		fmt.Sprintf("deleted_user_%d@%s", id, anonymizedEmailDomain),
+		now, actor.UserID, id)
+	if err != nil {
+		return err
+	}
+	if err := scrubUserChanges(ctx, tx, id); err != nil {
+		return err
+	}
+	if err := scrubConsents(ctx, tx, id); err != nil {
+		return err
+	}
+
+	changes := make([]entity.FieldChange, len(anonymizedFields))
+	for i, field := range anonymizedFields {
+		changes[i] = entity.FieldChange{Field: field}
+	}
+	if err := recordUserChange(ctx, tx, id, actor, changes, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
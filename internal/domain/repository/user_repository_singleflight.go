@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// singleFlightUserRepository collapses concurrent reads of the same user into
+// one query to the underlying repository, whose result every caller gets a
+// copy of
+type singleFlightUserRepository struct {
+	UserRepository
+	group   singleflight.Group
+	metrics *metrics.Metrics
+}
+
+// NewSingleFlightUserRepository wraps users so that GetByID calls for a user
+// arriving while a query for it is in flight share its result instead of
+// querying again. Writes to a user make later reads query afresh. Shared
+// reads are recorded when m is non-nil.
+func NewSingleFlightUserRepository(users UserRepository, m *metrics.Metrics) UserRepository {
+	return &singleFlightUserRepository{UserRepository: users, metrics: m}
+}
+
+func (r *singleFlightUserRepository) GetByID(ctx context.Context, id int) (*entity.User, error) {
+	leader := false
+	result := r.group.DoChan(strconv.Itoa(id), func() (interface{}, error) {
+		leader = true
+		// The query outlives callers that give up on it, up to their deadline
+		queryCtx, cancel := context.WithoutCancel(ctx), context.CancelFunc(func() {})
+		if deadline, ok := ctx.Deadline(); ok {
+			queryCtx, cancel = context.WithDeadline(queryCtx, deadline)
+		}
+		defer cancel()
+		return r.UserRepository.GetByID(queryCtx, id)
+	})
+
+	select {
+	case res := <-result:
+		if !leader && r.metrics != nil {
+			r.metrics.RecordSharedQuery("SELECT", "users")
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		user := *res.Val.(*entity.User)
+		return &user, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// forget makes the next read of the user query the underlying repository,
+// rather than share a query that may have started before a write
+func (r *singleFlightUserRepository) forget(id int) {
+	r.group.Forget(strconv.Itoa(id))
+}
+
+func (r *singleFlightUserRepository) Upsert(ctx context.Context, user *entity.User) (bool, error) {
+	created, err := r.UserRepository.Upsert(ctx, user)
+	r.forget(user.ID)
+	return created, err
+}
+
+func (r *singleFlightUserRepository) Update(ctx context.Context, user *entity.User) error {
+	defer r.forget(user.ID)
+	return r.UserRepository.Update(ctx, user)
+}
+
+func (r *singleFlightUserRepository) UpdatePhone(ctx context.Context, id int, phone string, verifiedAt *time.Time) error {
+	defer r.forget(id)
+	return r.UserRepository.UpdatePhone(ctx, id, phone, verifiedAt)
+}
+
+func (r *singleFlightUserRepository) Delete(ctx context.Context, id int) error {
+	defer r.forget(id)
+	return r.UserRepository.Delete(ctx, id)
+}
+
+func (r *singleFlightUserRepository) Anonymize(ctx context.Context, id int) error {
+	defer r.forget(id)
+	return r.UserRepository.Anonymize(ctx, id)
+}
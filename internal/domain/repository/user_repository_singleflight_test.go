@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowUsers answers GetByID once release is closed, counting the queries
+type slowUsers struct {
+	UserRepository
+	queries atomic.Int32
+	release chan struct{}
+}
+
+func (r *slowUsers) GetByID(ctx context.Context, id int) (*entity.User, error) {
+	r.queries.Add(1)
+	select {
+	case <-r.release:
+		return &entity.User{ID: id, Username: "alice"}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (r *slowUsers) Update(ctx context.Context, user *entity.User) error {
+	return nil
+}
+
+func TestSingleFlightUserRepository(t *testing.T) {
+	inner := &slowUsers{release: make(chan struct{})}
+	users := NewSingleFlightUserRepository(inner, nil)
+
+	const callers = 10
+	results := make([]*entity.User, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			user, err := users.GetByID(context.Background(), 7)
+			assert.NoError(t, err)
+			results[i] = user
+		}(i)
+	}
+
+	require.Eventually(t, func() bool { return inner.queries.Load() == 1 }, time.Second, time.Millisecond)
+	// Let the other callers join the query in flight
+	time.Sleep(50 * time.Millisecond)
+	close(inner.release)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, inner.queries.Load(), "concurrent reads share one query")
+	for _, user := range results {
+		require.NotNil(t, user)
+		assert.Equal(t, "alice", user.Username)
+	}
+	results[0].Username = "mallory"
+	assert.Equal(t, "alice", results[1].Username, "every caller gets its own copy")
+
+	t.Run("a caller giving up does not cancel the query", func(t *testing.T) {
+		inner := &slowUsers{release: make(chan struct{})}
+		users := NewSingleFlightUserRepository(inner, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			_, err := users.GetByID(ctx, 7)
+			done <- err
+		}()
+		require.Eventually(t, func() bool { return inner.queries.Load() == 1 }, time.Second, time.Millisecond)
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+
+		shared := make(chan *entity.User)
+		go func() {
+			user, _ := users.GetByID(context.Background(), 7)
+			shared <- user
+		}()
+		time.Sleep(50 * time.Millisecond)
+		close(inner.release)
+		assert.Equal(t, "alice", (<-shared).Username)
+		assert.EqualValues(t, 1, inner.queries.Load())
+	})
+
+	t.Run("writes are not shared with reads in flight", func(t *testing.T) {
+		inner := &slowUsers{release: make(chan struct{})}
+		users := NewSingleFlightUserRepository(inner, nil)
+
+		go func() { _, _ = users.GetByID(context.Background(), 7) }()
+		require.Eventually(t, func() bool { return inner.queries.Load() == 1 }, time.Second, time.Millisecond)
+
+		require.NoError(t, users.Update(context.Background(), &entity.User{ID: 7}))
+		go func() { _, _ = users.GetByID(context.Background(), 7) }()
+		require.Eventually(t, func() bool { return inner.queries.Load() == 2 }, time.Second, time.Millisecond)
+		close(inner.release)
+	})
+}
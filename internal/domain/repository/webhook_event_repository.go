@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// WebhookEventRepository records the webhook events processed, so events
+// delivered again by their provider are not applied twice.
+type WebhookEventRepository interface {
+	// Claim records the event as processed until expiresAt, reporting false
+	// when it is already recorded and has not expired.
+	Claim(ctx context.Context, eventID string, expiresAt time.Time) (bool, error)
+	// Release forgets the event, so it is applied when delivered again.
+	Release(ctx context.Context, eventID string) error
+	// DeleteExpired removes the events past their expiry and returns how many.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// webhookEventRepositoryImpl implements the WebhookEventRepository interface
+type webhookEventRepositoryImpl struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+}
+
+// NewWebhookEventRepository creates a new webhook event repository implementation
+func NewWebhookEventRepository(db *database.PostgresDB, log *logger.Logger, m *metrics.Metrics) WebhookEventRepository {
+	return &webhookEventRepositoryImpl{
+		db:      db,
+		logger:  log,
+		metrics: m,
+	}
+}
+
+func (r *webhookEventRepositoryImpl) Claim(ctx context.Context, eventID string, expiresAt time.Time) (bool, error) {
+	start := time.Now()
+	operation := "INSERT"
+	table := "webhook_events"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	// An expired record is claimed again, as if it had been purged
+	query := `
+		INSERT INTO webhook_events (event_id, expires_at, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (event_id) DO UPDATE
+		SET expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at
+		WHERE webhook_events.expires_at <= EXCLUDED.created_at
+		RETURNING event_id`
+
+	var claimed string
+	err := r.db.DB.QueryRowContext(ctx, query, eventID, expiresAt, time.Now()).Scan(&claimed)
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to claim webhook event", map[string]interface{}{
+			"event_id": eventID,
+		})
+		return false, fmt.Errorf("failed to claim webhook event: %w", err)
+	}
+
+	return claimed != "", nil
+}
+
+func (r *webhookEventRepositoryImpl) Release(ctx context.Context, eventID string) error {
+	start := time.Now()
+	operation := "DELETE"
+	table := "webhook_events"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.DB.ExecContext(ctx, `DELETE FROM webhook_events WHERE event_id = $1`, eventID)
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to release webhook event", map[string]interface{}{
+			"event_id": eventID,
+		})
+		return fmt.Errorf("failed to release webhook event: %w", err)
+	}
+
+	return nil
+}
+
+func (r *webhookEventRepositoryImpl) DeleteExpired(ctx context.Context) (int64, error) {
+	start := time.Now()
+	operation := "DELETE"
+	table := "webhook_events"
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.DB.ExecContext(ctx, `DELETE FROM webhook_events WHERE expires_at <= $1`, time.Now())
+	var affected int64
+	if err == nil {
+		affected, err = result.RowsAffected()
+	}
+
+	// Record metrics and logs
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQuery(operation, table, duration, err)
+	r.logger.DatabaseLogger(ctx, operation, table, duration.String(), err)
+
+	if err != nil {
+		r.logger.ErrorLogger(ctx, err, "Failed to delete expired webhook events", nil)
+		return 0, fmt.Errorf("failed to delete expired webhook events: %w", err)
+	}
+
+	return affected, nil
+}
@@ -0,0 +1,151 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"boilerplate-go/infrastructure/httpclient"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+)
+
+const (
+	RecaptchaVerifyURL = "https://www.google.com/recaptcha/api/siteverify"
+	HCaptchaVerifyURL  = "https://api.hcaptcha.com/siteverify"
+)
+
+// SiteVerifyProvider verifies tokens against a siteverify compatible API.
+// Both reCAPTCHA and hCaptcha expose the same request and response shape.
+type SiteVerifyProvider struct {
+	httpClient *http.Client
+	name       string
+	verifyURL  string
+	secretKey  string
+	minScore   float64
+	logger     *logger.Logger
+}
+
+type Config struct {
+	VerifyURL string
+	SecretKey string
+	MinScore  float64
+	Timeout   time.Duration
+}
+
+// NewRecaptchaProvider creates a Google reCAPTCHA verifier.
+func NewRecaptchaProvider(config Config, logger *logger.Logger) provider.CaptchaProvider {
+	if config.VerifyURL == "" {
+		config.VerifyURL = RecaptchaVerifyURL
+	}
+	return newSiteVerifyProvider("recaptcha", config, logger)
+}
+
+// NewHCaptchaProvider creates an hCaptcha verifier.
+func NewHCaptchaProvider(config Config, logger *logger.Logger) provider.CaptchaProvider {
+	if config.VerifyURL == "" {
+		config.VerifyURL = HCaptchaVerifyURL
+	}
+	return newSiteVerifyProvider("hcaptcha", config, logger)
+}
+
+func newSiteVerifyProvider(name string, config Config, logger *logger.Logger) *SiteVerifyProvider {
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &SiteVerifyProvider{
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: httpclient.NewTransport(name, nil, logger, "response"),
+		},
+		name:      name,
+		verifyURL: config.VerifyURL,
+		secretKey: config.SecretKey,
+		minScore:  config.MinScore,
+		logger:    logger,
+	}
+}
+
+func (s *SiteVerifyProvider) VerifyToken(ctx context.Context, token, remoteIP string) (*entity.CaptchaVerification, error) {
+	s.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"provider":  s.name,
+		"operation": "verify_token",
+	}).Debug("Verifying CAPTCHA token")
+
+	form := url.Values{}
+	form.Set("secret", s.secretKey)
+	form.Set("response", token)
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", s.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, s.handleError(ctx, err, "create_request_failed")
+	}
+
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("User-Agent", "boilerplate-go/1.0")
+
+	resp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, s.handleError(ctx, err, "api_call_failed")
+	}
+	defer resp.Body.Close()
+
+	return s.parseVerifyResponse(ctx, resp)
+}
+
+func (s *SiteVerifyProvider) handleError(ctx context.Context, err error, operation string) error {
+	s.logger.ErrorLogger(ctx, err, "CAPTCHA operation failed", map[string]interface{}{
+		"provider":  s.name,
+		"operation": operation,
+	})
+	return fmt.Errorf("%s %s: %w", s.name, operation, err)
+}
+
+func (s *SiteVerifyProvider) parseVerifyResponse(ctx context.Context, resp *http.Response) (*entity.CaptchaVerification, error) {
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("%s API error: %d", s.name, resp.StatusCode)
+		return nil, s.handleError(ctx, err, "api_error")
+	}
+
+	var verifyResp struct {
+		Success     bool     `json:"success"`
+		Hostname    string   `json:"hostname"`
+		ChallengeTS string   `json:"challenge_ts"`
+		Score       *float64 `json:"score"`
+		ErrorCodes  []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&verifyResp); err != nil {
+		return nil, s.handleError(ctx, err, "parse_response_failed")
+	}
+
+	result := &entity.CaptchaVerification{
+		Success:    verifyResp.Success,
+		Hostname:   verifyResp.Hostname,
+		ErrorCodes: verifyResp.ErrorCodes,
+	}
+	if verifyResp.Score != nil {
+		result.Score = *verifyResp.Score
+	}
+	if ts, err := time.Parse(time.RFC3339, verifyResp.ChallengeTS); err == nil {
+		result.ChallengeTS = ts
+	}
+
+	// reCAPTCHA v3 returns a score; reject low-confidence results when a threshold is configured.
+	// A score of 0 is the most likely bot, so only a missing score skips the check.
+	if result.Success && s.minScore > 0 && verifyResp.Score != nil && *verifyResp.Score < s.minScore {
+		result.Success = false
+		result.ErrorCodes = append(result.ErrorCodes, "score-below-threshold")
+	}
+
+	return result, nil
+}
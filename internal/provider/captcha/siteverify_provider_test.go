@@ -0,0 +1,101 @@
+package captcha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate-go/infrastructure/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSiteVerifyProvider_VerifyToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		minScore      float64
+		expectedOK    bool
+		expectedScore float64
+		expectedError bool
+	}{
+		{
+			name:       "success without score",
+			status:     http.StatusOK,
+			body:       `{"success": true, "hostname": "example.com"}`,
+			minScore:   0.5,
+			expectedOK: true,
+		},
+		{
+			name:          "score above threshold",
+			status:        http.StatusOK,
+			body:          `{"success": true, "score": 0.9}`,
+			minScore:      0.5,
+			expectedOK:    true,
+			expectedScore: 0.9,
+		},
+		{
+			name:          "score below threshold",
+			status:        http.StatusOK,
+			body:          `{"success": true, "score": 0.3}`,
+			minScore:      0.5,
+			expectedScore: 0.3,
+		},
+		{
+			name:     "zero score is rejected",
+			status:   http.StatusOK,
+			body:     `{"success": true, "score": 0}`,
+			minScore: 0.5,
+		},
+		{
+			name:          "no threshold configured",
+			status:        http.StatusOK,
+			body:          `{"success": true, "score": 0.1}`,
+			expectedOK:    true,
+			expectedScore: 0.1,
+		},
+		{
+			name:   "failed verification",
+			status: http.StatusOK,
+			body:   `{"success": false, "error-codes": ["invalid-input-response"]}`,
+		},
+		{
+			name:          "API error",
+			status:        http.StatusInternalServerError,
+			body:          `{}`,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.NoError(t, r.ParseForm())
+				assert.Equal(t, "secret", r.PostForm.Get("secret"))
+				assert.Equal(t, "token", r.PostForm.Get("response"))
+				assert.Equal(t, "198.51.100.1", r.PostForm.Get("remoteip"))
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p := NewRecaptchaProvider(Config{
+				VerifyURL: server.URL,
+				SecretKey: "secret",
+				MinScore:  tt.minScore,
+			}, logger.NewLogger())
+
+			result, err := p.VerifyToken(context.Background(), "token", "198.51.100.1")
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedOK, result.Success)
+			assert.Equal(t, tt.expectedScore, result.Score)
+		})
+	}
+}
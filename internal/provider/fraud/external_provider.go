@@ -0,0 +1,112 @@
+package fraud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"boilerplate-go/infrastructure/httpclient"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+)
+
+// ExternalProvider scores orders with an external fraud detection service.
+// The service receives the check request as JSON on POST {BaseURL}/score and
+// answers with {"score": 0-100, "reasons": [...]}.
+type ExternalProvider struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	logger     *logger.Logger
+}
+
+type ExternalConfig struct {
+	BaseURL string
+	APIKey  string
+	Timeout time.Duration
+}
+
+func NewExternalProvider(config ExternalConfig, logger *logger.Logger) provider.FraudProvider {
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &ExternalProvider{
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: httpclient.NewTransport("fraud_service", nil, logger),
+		},
+		baseURL: config.BaseURL,
+		apiKey:  config.APIKey,
+		logger:  logger,
+	}
+}
+
+func (e *ExternalProvider) ScoreOrder(ctx context.Context, req *entity.FraudCheckRequest) (*entity.FraudAssessment, error) {
+	e.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"provider":  "external",
+		"order_id":  req.OrderID,
+		"operation": "score_order",
+	}).Info("Scoring order")
+
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return nil, e.handleError(ctx, err, "json_marshal_failed")
+	}
+
+	url := fmt.Sprintf("%s/score", e.baseURL)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, e.handleError(ctx, err, "create_request_failed")
+	}
+
+	e.setHeaders(httpReq)
+
+	resp, err := e.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, e.handleError(ctx, err, "api_call_failed")
+	}
+	defer resp.Body.Close()
+
+	return e.parseScoreResponse(ctx, resp)
+}
+
+func (e *ExternalProvider) setHeaders(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "boilerplate-go/1.0")
+}
+
+func (e *ExternalProvider) handleError(ctx context.Context, err error, operation string) error {
+	e.logger.ErrorLogger(ctx, err, "Fraud service operation failed", map[string]interface{}{
+		"provider":  "external",
+		"operation": operation,
+	})
+	return fmt.Errorf("fraud service %s: %w", operation, err)
+}
+
+func (e *ExternalProvider) parseScoreResponse(ctx context.Context, resp *http.Response) (*entity.FraudAssessment, error) {
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("fraud service API error: %d", resp.StatusCode)
+		return nil, e.handleError(ctx, err, "api_error")
+	}
+
+	var scoreResp struct {
+		Score   float64  `json:"score"`
+		Reasons []string `json:"reasons"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&scoreResp); err != nil {
+		return nil, e.handleError(ctx, err, "parse_response_failed")
+	}
+
+	return &entity.FraudAssessment{
+		Provider: "external",
+		Score:    scoreResp.Score,
+		Reasons:  scoreResp.Reasons,
+	}, nil
+}
@@ -0,0 +1,121 @@
+package fraud
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/pkg/useragent"
+)
+
+// RulesProvider scores orders with built-in heuristics. Each matching rule adds
+// its weight to the score, which is capped at 100.
+type RulesProvider struct {
+	highAmount        float64
+	newAccountAge     time.Duration
+	maxItems          int
+	blockedCountries  map[string]bool
+	disposableDomains map[string]bool
+	logger            *logger.Logger
+}
+
+type RulesConfig struct {
+	HighAmount        float64
+	NewAccountAge     time.Duration
+	MaxItems          int
+	BlockedCountries  []string
+	DisposableDomains []string
+}
+
+// Rule weights
+const (
+	weightHighAmount       = 30
+	weightVeryHighAmount   = 50
+	weightNewAccount       = 25
+	weightManyItems        = 15
+	weightMissingIP        = 10
+	weightBlockedCountry   = 60
+	weightDisposableEmail  = 30
+	weightCouponNewAccount = 10
+	weightAutomatedClient  = 20
+)
+
+func NewRulesProvider(config RulesConfig, logger *logger.Logger) provider.FraudProvider {
+	p := &RulesProvider{
+		highAmount:        config.HighAmount,
+		newAccountAge:     config.NewAccountAge,
+		maxItems:          config.MaxItems,
+		blockedCountries:  make(map[string]bool, len(config.BlockedCountries)),
+		disposableDomains: make(map[string]bool, len(config.DisposableDomains)),
+		logger:            logger,
+	}
+	for _, country := range config.BlockedCountries {
+		p.blockedCountries[strings.ToUpper(strings.TrimSpace(country))] = true
+	}
+	for _, domain := range config.DisposableDomains {
+		p.disposableDomains[strings.ToLower(strings.TrimSpace(domain))] = true
+	}
+	return p
+}
+
+func (p *RulesProvider) ScoreOrder(ctx context.Context, req *entity.FraudCheckRequest) (*entity.FraudAssessment, error) {
+	assessment := &entity.FraudAssessment{Provider: "rules"}
+	add := func(weight float64, reason string) {
+		assessment.Score += weight
+		assessment.Reasons = append(assessment.Reasons, reason)
+	}
+
+	if p.highAmount > 0 {
+		switch {
+		case req.Amount >= p.highAmount*5:
+			add(weightVeryHighAmount, "very_high_amount")
+		case req.Amount >= p.highAmount:
+			add(weightHighAmount, "high_amount")
+		}
+	}
+
+	newAccount := p.newAccountAge > 0 && !req.AccountCreatedAt.IsZero() && time.Since(req.AccountCreatedAt) < p.newAccountAge
+	if newAccount {
+		add(weightNewAccount, "new_account")
+		if req.CouponCode != "" {
+			add(weightCouponNewAccount, "coupon_on_new_account")
+		}
+	}
+
+	if p.maxItems > 0 && req.ItemCount > p.maxItems {
+		add(weightManyItems, "many_items")
+	}
+
+	if req.IPAddress == "" {
+		add(weightMissingIP, "missing_ip")
+	}
+
+	// Shoppers use browsers; scripts and crawlers placing orders are suspicious
+	if req.Device == useragent.DeviceBot {
+		add(weightAutomatedClient, "automated_client")
+	}
+
+	if req.Location != nil && p.blockedCountries[strings.ToUpper(req.Location.CountryCode)] {
+		add(weightBlockedCountry, "blocked_country")
+	}
+
+	if at := strings.LastIndex(req.UserEmail, "@"); at >= 0 && p.disposableDomains[strings.ToLower(req.UserEmail[at+1:])] {
+		add(weightDisposableEmail, "disposable_email")
+	}
+
+	assessment.Score = math.Min(assessment.Score, 100)
+
+	p.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"provider":  "rules",
+		"order_id":  req.OrderID,
+		"score":     assessment.Score,
+		"reasons":   assessment.Reasons,
+		"operation": "score_order",
+	}).Debug("Order scored")
+
+	return assessment, nil
+}
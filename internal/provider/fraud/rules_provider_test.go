@@ -0,0 +1,128 @@
+package fraud
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRulesProvider() *RulesProvider {
+	return NewRulesProvider(RulesConfig{
+		HighAmount:        1000,
+		NewAccountAge:     24 * time.Hour,
+		MaxItems:          20,
+		BlockedCountries:  []string{" xx "},
+		DisposableDomains: []string{"Mailinator.com"},
+	}, logger.NewLogger()).(*RulesProvider)
+}
+
+func TestRulesProvider_ScoreOrder(t *testing.T) {
+	established := time.Now().Add(-365 * 24 * time.Hour)
+
+	tests := []struct {
+		name            string
+		request         *entity.FraudCheckRequest
+		expectedScore   float64
+		expectedReasons []string
+	}{
+		{
+			name: "clean order",
+			request: &entity.FraudCheckRequest{
+				UserEmail:        "user@example.com",
+				AccountCreatedAt: established,
+				Amount:           100,
+				ItemCount:        1,
+				IPAddress:        "203.0.113.1",
+			},
+			expectedScore: 0,
+		},
+		{
+			name: "high amount",
+			request: &entity.FraudCheckRequest{
+				AccountCreatedAt: established,
+				Amount:           1000,
+				IPAddress:        "203.0.113.1",
+			},
+			expectedScore:   weightHighAmount,
+			expectedReasons: []string{"high_amount"},
+		},
+		{
+			name: "very high amount replaces high amount",
+			request: &entity.FraudCheckRequest{
+				AccountCreatedAt: established,
+				Amount:           5000,
+				IPAddress:        "203.0.113.1",
+			},
+			expectedScore:   weightVeryHighAmount,
+			expectedReasons: []string{"very_high_amount"},
+		},
+		{
+			name: "coupon on new account",
+			request: &entity.FraudCheckRequest{
+				AccountCreatedAt: time.Now().Add(-time.Hour),
+				CouponCode:       "WELCOME",
+				IPAddress:        "203.0.113.1",
+			},
+			expectedScore:   weightNewAccount + weightCouponNewAccount,
+			expectedReasons: []string{"new_account", "coupon_on_new_account"},
+		},
+		{
+			name: "many items and missing IP",
+			request: &entity.FraudCheckRequest{
+				AccountCreatedAt: established,
+				ItemCount:        21,
+			},
+			expectedScore:   weightManyItems + weightMissingIP,
+			expectedReasons: []string{"many_items", "missing_ip"},
+		},
+		{
+			name: "automated client",
+			request: &entity.FraudCheckRequest{
+				AccountCreatedAt: established,
+				IPAddress:        "203.0.113.1",
+				Device:           "bot",
+			},
+			expectedScore:   weightAutomatedClient,
+			expectedReasons: []string{"automated_client"},
+		},
+		{
+			name: "blocked country and disposable email are case insensitive",
+			request: &entity.FraudCheckRequest{
+				UserEmail:        "user@MAILINATOR.com",
+				AccountCreatedAt: established,
+				IPAddress:        "203.0.113.1",
+				Location:         &entity.LocationInfo{CountryCode: "xx"},
+			},
+			expectedScore:   weightBlockedCountry + weightDisposableEmail,
+			expectedReasons: []string{"blocked_country", "disposable_email"},
+		},
+		{
+			name: "score is capped at 100",
+			request: &entity.FraudCheckRequest{
+				UserEmail:        "user@mailinator.com",
+				AccountCreatedAt: time.Now(),
+				Amount:           5000,
+				Location:         &entity.LocationInfo{CountryCode: "XX"},
+			},
+			expectedScore:   100,
+			expectedReasons: []string{"very_high_amount", "new_account", "missing_ip", "blocked_country", "disposable_email"},
+		},
+	}
+
+	p := newTestRulesProvider()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assessment, err := p.ScoreOrder(context.Background(), tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "rules", assessment.Provider)
+			assert.Equal(t, tt.expectedScore, assessment.Score)
+			assert.Equal(t, tt.expectedReasons, assessment.Reasons)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package geolocation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"boilerplate-go/infrastructure/httpclient"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+)
+
+// earthRadiusKm is the mean Earth radius used for great-circle distances.
+const earthRadiusKm = 6371.0
+
+// IPAPIProvider resolves IP addresses with an ip-api.com compatible service.
+// Lookups are GET {BaseURL}/json/{ip}; the API key, when set, is sent as the
+// "key" query parameter used by the commercial endpoint.
+type IPAPIProvider struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	logger     *logger.Logger
+}
+
+type IPAPIConfig struct {
+	BaseURL string
+	APIKey  string
+	Timeout time.Duration
+}
+
+func NewIPAPIProvider(config IPAPIConfig, logger *logger.Logger) provider.GeolocationProvider {
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 2 * time.Second
+	}
+
+	return &IPAPIProvider{
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: httpclient.NewTransport("ipapi", nil, logger),
+		},
+		baseURL: config.BaseURL,
+		apiKey:  config.APIKey,
+		logger:  logger,
+	}
+}
+
+func (p *IPAPIProvider) GetLocationByIP(ctx context.Context, ip string) (*entity.LocationInfo, error) {
+	query := url.Values{}
+	query.Set("fields", "status,message,country,countryCode,regionName,city,lat,lon,timezone,query")
+	if p.apiKey != "" {
+		query.Set("key", p.apiKey)
+	}
+
+	endpoint := fmt.Sprintf("%s/json/%s?%s", p.baseURL, url.PathEscape(ip), query.Encode())
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, p.handleError(ctx, err, "create_request_failed")
+	}
+	httpReq.Header.Set("User-Agent", "boilerplate-go/1.0")
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, p.handleError(ctx, err, "api_call_failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("geolocation API error: %d", resp.StatusCode)
+		return nil, p.handleError(ctx, err, "api_error")
+	}
+
+	var lookup struct {
+		Status      string  `json:"status"`
+		Message     string  `json:"message"`
+		Country     string  `json:"country"`
+		CountryCode string  `json:"countryCode"`
+		RegionName  string  `json:"regionName"`
+		City        string  `json:"city"`
+		Lat         float64 `json:"lat"`
+		Lon         float64 `json:"lon"`
+		Timezone    string  `json:"timezone"`
+		Query       string  `json:"query"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&lookup); err != nil {
+		return nil, p.handleError(ctx, err, "parse_response_failed")
+	}
+	if lookup.Status != "success" {
+		err := fmt.Errorf("geolocation lookup failed: %s", lookup.Message)
+		return nil, p.handleError(ctx, err, "lookup_failed")
+	}
+
+	return &entity.LocationInfo{
+		IP:          lookup.Query,
+		Country:     lookup.Country,
+		CountryCode: lookup.CountryCode,
+		City:        lookup.City,
+		Region:      lookup.RegionName,
+		Coordinates: entity.Coordinates{
+			Latitude:  lookup.Lat,
+			Longitude: lookup.Lon,
+		},
+		Timezone: lookup.Timezone,
+	}, nil
+}
+
+// GetDistanceBetween returns the great-circle distance between two points.
+// It is computed locally; Duration is left at zero as no routing is involved.
+func (p *IPAPIProvider) GetDistanceBetween(ctx context.Context, from, to *entity.Coordinates) (*entity.DistanceInfo, error) {
+	if from == nil || to == nil {
+		return nil, fmt.Errorf("both coordinates are required")
+	}
+
+	lat1 := from.Latitude * math.Pi / 180
+	lat2 := to.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (to.Longitude - from.Longitude) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	distance := 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+
+	return &entity.DistanceInfo{
+		Distance: distance,
+		Unit:     "km",
+	}, nil
+}
+
+func (p *IPAPIProvider) handleError(ctx context.Context, err error, operation string) error {
+	p.logger.ErrorLogger(ctx, err, "Geolocation operation failed", map[string]interface{}{
+		"provider":  "ipapi",
+		"operation": operation,
+	})
+	return fmt.Errorf("geolocation %s: %w", operation, err)
+}
@@ -0,0 +1,159 @@
+// Package metered counts the calls to paid providers against their daily
+// budgets. A call refused by its budget fails with errors.ErrBudgetExceeded
+// without reaching the provider.
+package metered
+
+import (
+	"context"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+)
+
+// Meter records the calls to paid providers, refusing the calls that are not
+// critical once the budget of their provider is spent
+type Meter interface {
+	Spend(ctx context.Context, provider string, units int, critical bool) error
+}
+
+// PaymentProvider counts every payment provider call. Payment calls are
+// critical and never refused.
+type PaymentProvider struct {
+	next  provider.PaymentProvider
+	meter Meter
+}
+
+func NewPaymentProvider(next provider.PaymentProvider, meter Meter) *PaymentProvider {
+	return &PaymentProvider{next: next, meter: meter}
+}
+
+// Unwrap returns the metered payment provider
+func (p *PaymentProvider) Unwrap() provider.PaymentProvider {
+	return p.next
+}
+
+func (p *PaymentProvider) spend(ctx context.Context) error {
+	return p.meter.Spend(ctx, entity.BudgetPayment, 1, true)
+}
+
+func (p *PaymentProvider) ProcessPayment(ctx context.Context, req *entity.PaymentRequest) (*entity.PaymentResponse, error) {
+	if err := p.spend(ctx); err != nil {
+		return nil, err
+	}
+	return p.next.ProcessPayment(ctx, req)
+}
+
+func (p *PaymentProvider) RefundPayment(ctx context.Context, paymentID string) (*entity.RefundResponse, error) {
+	if err := p.spend(ctx); err != nil {
+		return nil, err
+	}
+	return p.next.RefundPayment(ctx, paymentID)
+}
+
+func (p *PaymentProvider) GetPaymentStatus(ctx context.Context, paymentID string) (*entity.PaymentStatus, error) {
+	if err := p.spend(ctx); err != nil {
+		return nil, err
+	}
+	return p.next.GetPaymentStatus(ctx, paymentID)
+}
+
+func (p *PaymentProvider) CreatePaymentIntent(ctx context.Context, req *entity.PaymentIntentRequest) (*entity.PaymentIntent, error) {
+	if err := p.spend(ctx); err != nil {
+		return nil, err
+	}
+	return p.next.CreatePaymentIntent(ctx, req)
+}
+
+func (p *PaymentProvider) CancelPaymentIntent(ctx context.Context, intentID string) (*entity.PaymentIntent, error) {
+	if err := p.spend(ctx); err != nil {
+		return nil, err
+	}
+	return p.next.CancelPaymentIntent(ctx, intentID)
+}
+
+// NotificationProvider counts the emails, per recipient, and the SMS sent.
+// Marketing emails are not critical; push notifications are not counted.
+type NotificationProvider struct {
+	next  provider.NotificationProvider
+	meter Meter
+}
+
+func NewNotificationProvider(next provider.NotificationProvider, meter Meter) *NotificationProvider {
+	return &NotificationProvider{next: next, meter: meter}
+}
+
+func (p *NotificationProvider) SendEmail(ctx context.Context, req *entity.EmailRequest) (*entity.EmailResponse, error) {
+	if err := p.meter.Spend(ctx, entity.BudgetEmail, recipients(req), critical(req)); err != nil {
+		return nil, err
+	}
+	return p.next.SendEmail(ctx, req)
+}
+
+func (p *NotificationProvider) SendSMS(ctx context.Context, req *entity.SMSRequest) (*entity.SMSResponse, error) {
+	if err := p.meter.Spend(ctx, entity.BudgetSMS, 1, true); err != nil {
+		return nil, err
+	}
+	return p.next.SendSMS(ctx, req)
+}
+
+func (p *NotificationProvider) SendPushNotification(ctx context.Context, req *entity.PushNotificationRequest) (*entity.PushNotificationResponse, error) {
+	return p.next.SendPushNotification(ctx, req)
+}
+
+func (p *NotificationProvider) SendTemplatedSMS(ctx context.Context, msg *entity.TemplatedMessage) (*entity.SMSResponse, error) {
+	if err := p.meter.Spend(ctx, entity.BudgetSMS, 1, true); err != nil {
+		return nil, err
+	}
+	return p.next.SendTemplatedSMS(ctx, msg)
+}
+
+func (p *NotificationProvider) SendTemplatedPush(ctx context.Context, msg *entity.TemplatedMessage) (*entity.PushNotificationResponse, error) {
+	return p.next.SendTemplatedPush(ctx, msg)
+}
+
+// EmailProvider counts the emails sent through the bulk email provider, per
+// recipient. A bulk email is critical unless all its emails are marketing.
+type EmailProvider struct {
+	next  provider.EmailProvider
+	meter Meter
+}
+
+func NewEmailProvider(next provider.EmailProvider, meter Meter) *EmailProvider {
+	return &EmailProvider{next: next, meter: meter}
+}
+
+// Unwrap returns the metered email provider
+func (p *EmailProvider) Unwrap() provider.EmailProvider {
+	return p.next
+}
+
+func (p *EmailProvider) SendEmail(ctx context.Context, req *entity.EmailRequest) (*entity.EmailResponse, error) {
+	if err := p.meter.Spend(ctx, entity.BudgetEmail, recipients(req), critical(req)); err != nil {
+		return nil, err
+	}
+	return p.next.SendEmail(ctx, req)
+}
+
+func (p *EmailProvider) SendBulkEmail(ctx context.Context, req *entity.BulkEmailRequest) (*entity.BulkEmailResponse, error) {
+	units, isCritical := 0, false
+	for i := range req.Emails {
+		units += recipients(&req.Emails[i])
+		isCritical = isCritical || critical(&req.Emails[i])
+	}
+	if err := p.meter.Spend(ctx, entity.BudgetEmail, units, isCritical); err != nil {
+		return nil, err
+	}
+	return p.next.SendBulkEmail(ctx, req)
+}
+
+func (p *EmailProvider) GetEmailStatus(ctx context.Context, emailID string) (*entity.EmailStatus, error) {
+	return p.next.GetEmailStatus(ctx, emailID)
+}
+
+func recipients(req *entity.EmailRequest) int {
+	return len(req.To) + len(req.CC) + len(req.BCC)
+}
+
+func critical(req *entity.EmailRequest) bool {
+	return req.Priority != entity.NotificationPriorityMarketing
+}
@@ -0,0 +1,141 @@
+package notification
+
+import (
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+)
+
+// maxAttachmentNameLength is the longest attachment file name, in bytes
+const maxAttachmentNameLength = 255
+
+// DefaultAttachmentTypes are the attachment MIME types allowed when none are configured.
+var DefaultAttachmentTypes = []string{
+	"application/pdf",
+	"text/plain",
+	"text/csv",
+	"text/calendar",
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+}
+
+// AttachmentPolicy limits the attachments of an email. MaxTotalSize bounds the
+// attachments' combined size before encoding and MaxCount their number; zero
+// disables a limit. AllowedTypes lists MIME types, or "type/*" for a whole
+// top-level type.
+type AttachmentPolicy struct {
+	MaxTotalSize int64
+	MaxCount     int
+	AllowedTypes []string
+}
+
+// Validate checks attachments against the policy and returns copies with
+// sanitized file names and normalized MIME types. A missing MIME type is taken
+// from the file extension. Every problem found is listed in the error.
+func (p AttachmentPolicy) Validate(attachments []entity.EmailAttachment) ([]entity.EmailAttachment, error) {
+	if len(attachments) == 0 {
+		return nil, nil
+	}
+
+	var problems []string
+	if p.MaxCount > 0 && len(attachments) > p.MaxCount {
+		problems = append(problems, fmt.Sprintf("%d attachments, at most %d allowed", len(attachments), p.MaxCount))
+	}
+
+	allowed := p.AllowedTypes
+	if len(allowed) == 0 {
+		allowed = DefaultAttachmentTypes
+	}
+
+	validated := make([]entity.EmailAttachment, len(attachments))
+	var total int64
+	for i, att := range attachments {
+		total += int64(len(att.Content))
+
+		filename := sanitizeFilename(att.Filename)
+		if filename == "" {
+			problems = append(problems, fmt.Sprintf("attachment %d: file name is required", i+1))
+		}
+		if len(att.Content) == 0 {
+			problems = append(problems, fmt.Sprintf("attachment %d: content is empty", i+1))
+		}
+
+		mimeType, err := attachmentType(att.MimeType, filename)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("attachment %d: %v", i+1, err))
+		case !typeAllowed(mimeType, allowed):
+			problems = append(problems, fmt.Sprintf("attachment %d: type %s is not allowed", i+1, mimeType))
+		}
+
+		validated[i] = entity.EmailAttachment{Filename: filename, Content: att.Content, MimeType: mimeType}
+	}
+
+	if p.MaxTotalSize > 0 && total > p.MaxTotalSize {
+		problems = append(problems, fmt.Sprintf("attachments total %d bytes, at most %d allowed", total, p.MaxTotalSize))
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", errors.ErrInvalidAttachment, strings.Join(problems, "; "))
+	}
+	return validated, nil
+}
+
+// sanitizeFilename reduces a file name to its base name without control
+// characters, quotes or leading dots, so it is safe in a MIME header.
+func sanitizeFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimLeft(strings.TrimSpace(name), ".")
+
+	for len(name) > maxAttachmentNameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "/" {
+		return ""
+	}
+	return name
+}
+
+// attachmentType returns the normalized MIME type of an attachment, without
+// parameters, falling back to the type of the file extension.
+func attachmentType(declared, filename string) (string, error) {
+	if declared == "" {
+		declared = mime.TypeByExtension(path.Ext(filename))
+		if declared == "" {
+			return "", fmt.Errorf("MIME type is required")
+		}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(declared)
+	if err != nil {
+		return "", fmt.Errorf("MIME type %q is invalid", declared)
+	}
+	return mediaType, nil
+}
+
+// typeAllowed reports whether the MIME type is in the allowed list.
+func typeAllowed(mimeType string, allowed []string) bool {
+	for _, candidate := range allowed {
+		candidate = strings.ToLower(candidate)
+		if candidate == mimeType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(candidate, "/*"); ok && strings.HasPrefix(mimeType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachmentPolicy_Validate(t *testing.T) {
+	policy := AttachmentPolicy{MaxTotalSize: 10, MaxCount: 2, AllowedTypes: []string{"application/pdf", "image/*"}}
+
+	validated, err := policy.Validate([]entity.EmailAttachment{
+		{Filename: `C:\Users\me\..\"invoice"` + "\r\n.pdf", Content: []byte("%PDF"), MimeType: "Application/PDF; name=x"},
+		{Filename: "photo.png", Content: []byte("png")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "invoice.pdf", validated[0].Filename)
+	assert.Equal(t, "application/pdf", validated[0].MimeType)
+	assert.Equal(t, "image/png", validated[1].MimeType, "taken from the extension")
+
+	_, err = policy.Validate([]entity.EmailAttachment{
+		{Filename: "../..", Content: []byte("x"), MimeType: "application/pdf"},
+		{Filename: "run.exe", Content: []byte("MZ"), MimeType: "application/x-msdownload"},
+		{Filename: "big.pdf", Content: []byte("0123456789"), MimeType: "application/pdf"},
+	})
+	require.True(t, errors.IsInvalidAttachment(err))
+	for _, problem := range []string{
+		"3 attachments, at most 2 allowed",
+		"attachment 1: file name is required",
+		"attachment 2: type application/x-msdownload is not allowed",
+		"attachments total 13 bytes, at most 10 allowed",
+	} {
+		assert.Contains(t, err.Error(), problem)
+	}
+
+	_, err = policy.Validate([]entity.EmailAttachment{{Filename: "notes", Content: []byte("x")}})
+	assert.ErrorContains(t, err, "attachment 1: MIME type is required")
+
+	// Without configured types the defaults apply
+	_, err = AttachmentPolicy{}.Validate([]entity.EmailAttachment{{Filename: "page.html", Content: []byte("<p>"), MimeType: "text/html"}})
+	assert.True(t, errors.IsInvalidAttachment(err))
+}
+
+func TestEmailProvider_Attachments(t *testing.T) {
+	var sent map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&sent))
+		json.NewEncoder(w).Encode(map[string]string{"id": "1", "status": "queued", "message_id": "m1"})
+	}))
+	defer server.Close()
+
+	provider := NewEmailProvider(EmailConfig{BaseURL: server.URL, Attachments: AttachmentPolicy{MaxTotalSize: 100}}, logger.NewLogger())
+	ctx := context.Background()
+
+	_, err := provider.SendEmail(ctx, &entity.EmailRequest{
+		To:          []string{"alice@example.com"},
+		Attachments: []entity.EmailAttachment{{Filename: "report.csv", Content: []byte("a,b"), MimeType: "text/csv"}},
+	})
+	require.NoError(t, err)
+	attachment := sent["attachments"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "YSxi", attachment["content"])
+	assert.Equal(t, "report.csv", attachment["filename"])
+
+	// Rejected attachments never reach the API
+	sent = nil
+	_, err = provider.SendEmail(ctx, &entity.EmailRequest{
+		To:          []string{"alice@example.com"},
+		Attachments: []entity.EmailAttachment{{Filename: "big.csv", Content: []byte(strings.Repeat("a", 101)), MimeType: "text/csv"}},
+	})
+	assert.True(t, errors.IsInvalidAttachment(err))
+	assert.Nil(t, sent)
+}
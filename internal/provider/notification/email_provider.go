@@ -3,29 +3,33 @@ package notification
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
+	"boilerplate-go/infrastructure/httpclient"
 	"boilerplate-go/infrastructure/logger"
 	"boilerplate-go/internal/domain/entity"
 	"boilerplate-go/internal/domain/provider"
 )
 
 type EmailProvider struct {
-	httpClient *http.Client
-	baseURL    string
-	apiKey     string
-	fromEmail  string
-	logger     *logger.Logger
+	httpClient  *http.Client
+	baseURL     string
+	apiKey      string
+	fromEmail   string
+	attachments AttachmentPolicy
+	logger      *logger.Logger
 }
 
 type EmailConfig struct {
-	BaseURL   string
-	APIKey    string
-	FromEmail string
-	Timeout   time.Duration
+	BaseURL     string
+	APIKey      string
+	FromEmail   string
+	Timeout     time.Duration
+	Attachments AttachmentPolicy
 }
 
 func NewEmailProvider(config EmailConfig, logger *logger.Logger) provider.EmailProvider {
@@ -36,16 +40,24 @@ func NewEmailProvider(config EmailConfig, logger *logger.Logger) provider.EmailP
 
 	return &EmailProvider{
 		httpClient: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: httpclient.NewTransport("email_service", nil, logger, "text", "html"),
 		},
-		baseURL:   config.BaseURL,
-		apiKey:    config.APIKey,
-		fromEmail: config.FromEmail,
-		logger:    logger,
+		baseURL:     config.BaseURL,
+		apiKey:      config.APIKey,
+		fromEmail:   config.FromEmail,
+		attachments: config.Attachments,
+		logger:      logger,
 	}
 }
 
 func (e *EmailProvider) SendEmail(ctx context.Context, req *entity.EmailRequest) (*entity.EmailResponse, error) {
+	// Reject attachments the API would refuse before sending anything
+	attachments, err := e.attachments.Validate(req.Attachments)
+	if err != nil {
+		return nil, err
+	}
+
 	e.logger.WithContext(ctx).WithFields(map[string]interface{}{
 		"provider":  "email_service",
 		"to_count":  len(req.To),
@@ -75,16 +87,17 @@ func (e *EmailProvider) SendEmail(ctx context.Context, req *entity.EmailRequest)
 		emailReq["text"] = req.Body
 	}
 
-	if req.Attachments != nil && len(req.Attachments) > 0 {
-		attachments := make([]map[string]interface{}, 0, len(req.Attachments))
-		for _, att := range req.Attachments {
-			attachments = append(attachments, map[string]interface{}{
+	if len(attachments) > 0 {
+		// The API takes attachment content as standard base64
+		encoded := make([]map[string]interface{}, 0, len(attachments))
+		for _, att := range attachments {
+			encoded = append(encoded, map[string]interface{}{
 				"filename": att.Filename,
-				"content":  att.Content,
+				"content":  base64.StdEncoding.EncodeToString(att.Content),
 				"type":     att.MimeType,
 			})
 		}
-		emailReq["attachments"] = attachments
+		emailReq["attachments"] = encoded
 	}
 
 	if req.Metadata != nil {
@@ -204,6 +217,27 @@ func (e *EmailProvider) GetEmailStatus(ctx context.Context, emailID string) (*en
 	return e.parseEmailStatusResponse(ctx, resp)
 }
 
+// Ping checks that the email service is reachable and accepts the API key.
+// Any answer but a server error or a rejected key counts as up.
+func (e *EmailProvider) Ping(ctx context.Context) error {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", e.baseURL+"/status", nil)
+	if err != nil {
+		return err
+	}
+	e.setHeaders(httpReq)
+
+	resp, err := e.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("status check failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
 func (e *EmailProvider) setHeaders(req *http.Request) {
 	req.Header.Set("Authorization", "Bearer "+e.apiKey)
 	req.Header.Set("Content-Type", "application/json")
@@ -0,0 +1,281 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+)
+
+const (
+	// smsMaxSegments caps how many parts a single SMS may be split into
+	smsMaxSegments = 3
+	// pushMaxTitleLength and pushMaxBodyLength are the characters shown by most devices
+	pushMaxTitleLength = 65
+	pushMaxBodyLength  = 240
+)
+
+// MessageTemplate holds the templates of one message type in one locale.
+// Templates use text/template syntax over the message data; referencing a
+// missing key is an error, so optional values are read with index. Times in the
+// data are shown in the recipient's timezone, see LocalizeTimes.
+type MessageTemplate struct {
+	SMS       string
+	PushTitle string
+	PushBody  string
+}
+
+type compiledMessage struct {
+	sms       *template.Template
+	pushTitle *template.Template
+	pushBody  *template.Template
+}
+
+// MessageCatalog renders named SMS and push messages for a locale. A locale
+// without a template falls back to its base language, then to the default locale.
+type MessageCatalog struct {
+	messages      map[string]map[string]*compiledMessage
+	defaultLocale string
+}
+
+// defaultMessages are the built-in message types, by type and locale.
+var defaultMessages = map[string]map[string]MessageTemplate{
+	entity.MessageTypeOTP: {
+		"en": {
+			SMS:       "{{.Code}} is your verification code. It expires in {{.ExpiresIn}}. Never share it with anyone.",
+			PushTitle: "Your verification code",
+			PushBody:  "{{.Code}} is your verification code. It expires in {{.ExpiresIn}}.",
+		},
+		"id": {
+			SMS:       "{{.Code}} adalah kode verifikasi Anda. Berlaku selama {{.ExpiresIn}}. Jangan berikan kepada siapa pun.",
+			PushTitle: "Kode verifikasi Anda",
+			PushBody:  "{{.Code}} adalah kode verifikasi Anda. Berlaku selama {{.ExpiresIn}}.",
+		},
+	},
+	entity.MessageTypeOrderShipped: {
+		"en": {
+			SMS:       `Your order {{.OrderID}} has shipped.{{with index . "TrackingNumber"}} Tracking number: {{.}}{{end}}{{with index . "EstimatedDelivery"}} Estimated delivery: {{.Format "Jan 2"}}.{{end}}`,
+			PushTitle: "Your order is on its way",
+			PushBody:  `Order {{.OrderID}} has shipped.{{with index . "TrackingNumber"}} Tracking number: {{.}}{{end}}`,
+		},
+		"id": {
+			SMS:       `Pesanan {{.OrderID}} Anda telah dikirim.{{with index . "TrackingNumber"}} Nomor resi: {{.}}{{end}}{{with index . "EstimatedDelivery"}} Perkiraan tiba: {{.Format "02/01"}}.{{end}}`,
+			PushTitle: "Pesanan Anda dalam perjalanan",
+			PushBody:  `Pesanan {{.OrderID}} telah dikirim.{{with index . "TrackingNumber"}} Nomor resi: {{.}}{{end}}`,
+		},
+	},
+}
+
+// NewMessageCatalog creates a catalog with the built-in message types.
+func NewMessageCatalog(defaultLocale string) (*MessageCatalog, error) {
+	if defaultLocale == "" {
+		defaultLocale = "en"
+	}
+
+	catalog := &MessageCatalog{
+		messages:      make(map[string]map[string]*compiledMessage),
+		defaultLocale: normalizeLocale(defaultLocale),
+	}
+	for messageType, locales := range defaultMessages {
+		for locale, tmpl := range locales {
+			if err := catalog.Register(messageType, locale, tmpl); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return catalog, nil
+}
+
+// Register adds or replaces the templates of a message type in a locale.
+func (c *MessageCatalog) Register(messageType, locale string, tmpl MessageTemplate) error {
+	parse := func(part, text string) (*template.Template, error) {
+		if text == "" {
+			return nil, nil
+		}
+		parsed, err := template.New(messageType + "." + part).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s template for %s/%s: %w", part, messageType, locale, err)
+		}
+		return parsed, nil
+	}
+
+	compiled := &compiledMessage{}
+	var err error
+	if compiled.sms, err = parse("sms", tmpl.SMS); err != nil {
+		return err
+	}
+	if compiled.pushTitle, err = parse("push_title", tmpl.PushTitle); err != nil {
+		return err
+	}
+	if compiled.pushBody, err = parse("push_body", tmpl.PushBody); err != nil {
+		return err
+	}
+
+	if c.messages[messageType] == nil {
+		c.messages[messageType] = make(map[string]*compiledMessage)
+	}
+	c.messages[messageType][normalizeLocale(locale)] = compiled
+	return nil
+}
+
+// RenderSMS renders the SMS text of a message type and checks it fits in smsMaxSegments.
+func (c *MessageCatalog) RenderSMS(messageType, locale string, data map[string]interface{}) (string, error) {
+	message, err := c.lookup(messageType, locale, func(m *compiledMessage) bool { return m.sms != nil })
+	if err != nil {
+		return "", err
+	}
+
+	text, err := execute(message.sms, data)
+	if err != nil {
+		return "", err
+	}
+	if segments, _ := SMSSegments(text); segments > smsMaxSegments {
+		return "", fmt.Errorf("%w: %s SMS needs %d segments, the limit is %d", errors.ErrMessageTooLong, messageType, segments, smsMaxSegments)
+	}
+	return text, nil
+}
+
+// RenderPush renders the push title and body of a message type and checks their length.
+func (c *MessageCatalog) RenderPush(messageType, locale string, data map[string]interface{}) (string, string, error) {
+	message, err := c.lookup(messageType, locale, func(m *compiledMessage) bool { return m.pushBody != nil })
+	if err != nil {
+		return "", "", err
+	}
+
+	title, err := execute(message.pushTitle, data)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := execute(message.pushBody, data)
+	if err != nil {
+		return "", "", err
+	}
+	if utf8.RuneCountInString(title) > pushMaxTitleLength || utf8.RuneCountInString(body) > pushMaxBodyLength {
+		return "", "", fmt.Errorf("%w: %s push exceeds %d title or %d body characters", errors.ErrMessageTooLong, messageType, pushMaxTitleLength, pushMaxBodyLength)
+	}
+	return title, body, nil
+}
+
+// lookup finds the message for the locale, its base language or the default
+// locale, skipping locales that lack the channel.
+func (c *MessageCatalog) lookup(messageType, locale string, hasChannel func(*compiledMessage) bool) (*compiledMessage, error) {
+	locales, ok := c.messages[messageType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errors.ErrUnknownMessageType, messageType)
+	}
+
+	for _, candidate := range localeFallbacks(locale, c.defaultLocale) {
+		if message, ok := locales[candidate]; ok && hasChannel(message) {
+			return message, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s has no template for locale %q", errors.ErrUnknownMessageType, messageType, locale)
+}
+
+// localeFallbacks lists the locales to try in order, e.g. pt-br, pt, en.
+func localeFallbacks(locale, defaultLocale string) []string {
+	locale = normalizeLocale(locale)
+
+	var candidates []string
+	if locale != "" {
+		candidates = append(candidates, locale)
+		if base, _, found := strings.Cut(locale, "-"); found {
+			candidates = append(candidates, base)
+		}
+	}
+	return append(candidates, defaultLocale)
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// LocalizeTimes returns a copy of the message data with time values converted
+// to the timezone, so templates show them in the recipient's local time. An
+// empty or unknown timezone shows times in UTC.
+func LocalizeTimes(data map[string]interface{}, timezone string) map[string]interface{} {
+	location, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "Local" {
+		location = time.UTC
+	}
+
+	localized := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		switch v := value.(type) {
+		case time.Time:
+			localized[key] = v.In(location)
+		case *time.Time:
+			if v != nil {
+				local := v.In(location)
+				localized[key] = &local
+			} else {
+				localized[key] = v
+			}
+		default:
+			localized[key] = value
+		}
+	}
+	return localized
+}
+
+func execute(tmpl *template.Template, data map[string]interface{}) (string, error) {
+	if tmpl == nil {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", tmpl.Name(), err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// gsm7Basic and gsm7Extended are the GSM 03.38 characters. Extended characters
+// take two septets.
+const (
+	gsm7Basic    = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+	gsm7Extended = "^{}\\[~]|€\f"
+)
+
+// SMSSegments returns how many SMS parts the message is sent as, and whether it
+// needs UCS-2 encoding because it has characters outside the GSM 7-bit alphabet.
+func SMSSegments(message string) (int, bool) {
+	septets := 0
+	unicode := false
+	for _, r := range message {
+		switch {
+		case strings.ContainsRune(gsm7Basic, r):
+			septets++
+		case strings.ContainsRune(gsm7Extended, r):
+			septets += 2
+		default:
+			unicode = true
+		}
+	}
+
+	if unicode {
+		// UCS-2 counts UTF-16 code units: 70 in a single part, 67 per concatenated part
+		units := 0
+		for _, r := range message {
+			if r >= 0x10000 {
+				units += 2
+			} else {
+				units++
+			}
+		}
+		return segments(units, 70, 67), true
+	}
+	return segments(septets, 160, 153), false
+}
+
+func segments(length, single, multi int) int {
+	if length <= single {
+		return 1
+	}
+	return (length + multi - 1) / multi
+}
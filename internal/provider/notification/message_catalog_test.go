@@ -0,0 +1,114 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageCatalog_LocaleFallback(t *testing.T) {
+	catalog, err := NewMessageCatalog("en")
+	require.NoError(t, err)
+	data := map[string]interface{}{"Code": "123456", "ExpiresIn": "5 minutes"}
+
+	tests := []struct {
+		locale   string
+		expected string
+	}{
+		{locale: "id", expected: "123456 adalah kode"},
+		{locale: "id_ID", expected: "123456 adalah kode"},
+		{locale: "fr-FR", expected: "123456 is your verification code"},
+		{locale: "", expected: "123456 is your verification code"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			text, err := catalog.RenderSMS(entity.MessageTypeOTP, tt.locale, data)
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(text, tt.expected), text)
+		})
+	}
+}
+
+func TestMessageCatalog_RenderPush(t *testing.T) {
+	catalog, err := NewMessageCatalog("en")
+	require.NoError(t, err)
+
+	title, body, err := catalog.RenderPush(entity.MessageTypeOrderShipped, "en", map[string]interface{}{"OrderID": "ORD-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Your order is on its way", title)
+	assert.Equal(t, "Order ORD-1 has shipped.", body)
+
+	_, body, err = catalog.RenderPush(entity.MessageTypeOrderShipped, "en", map[string]interface{}{"OrderID": "ORD-1", "TrackingNumber": "TRK9"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Order ORD-1 has shipped. Tracking number: TRK9", body)
+}
+
+func TestMessageCatalog_Errors(t *testing.T) {
+	catalog, err := NewMessageCatalog("en")
+	require.NoError(t, err)
+
+	_, err = catalog.RenderSMS("welcome", "en", nil)
+	assert.True(t, errors.IsUnknownMessageType(err))
+
+	// Required data is missing
+	_, err = catalog.RenderSMS(entity.MessageTypeOTP, "en", map[string]interface{}{"Code": "1"})
+	assert.Error(t, err)
+
+	require.NoError(t, catalog.Register("long", "en", MessageTemplate{SMS: "{{.Text}}"}))
+	_, err = catalog.RenderSMS("long", "en", map[string]interface{}{"Text": strings.Repeat("a", 153*3+1)})
+	assert.True(t, errors.IsMessageTooLong(err))
+
+	// SMS only types have no push template
+	_, _, err = catalog.RenderPush("long", "en", nil)
+	assert.True(t, errors.IsUnknownMessageType(err))
+
+	assert.Error(t, catalog.Register("broken", "en", MessageTemplate{SMS: "{{.Code"}))
+}
+
+func TestSMSSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		segments int
+		unicode  bool
+	}{
+		{name: "single GSM part", message: strings.Repeat("a", 160), segments: 1},
+		{name: "concatenated GSM", message: strings.Repeat("a", 161), segments: 2},
+		{name: "extended characters take two septets", message: strings.Repeat("€", 80), segments: 1},
+		{name: "extended characters overflow", message: strings.Repeat("€", 81), segments: 2},
+		{name: "single UCS-2 part", message: strings.Repeat("é", 10) + strings.Repeat("ש", 60), segments: 1, unicode: true},
+		{name: "concatenated UCS-2", message: strings.Repeat("ש", 71), segments: 2, unicode: true},
+		{name: "emoji take two code units", message: strings.Repeat("😀", 35), segments: 1, unicode: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments, unicode := SMSSegments(tt.message)
+			assert.Equal(t, tt.segments, segments)
+			assert.Equal(t, tt.unicode, unicode)
+		})
+	}
+}
+
+func TestLocalizeTimes(t *testing.T) {
+	catalog, err := NewMessageCatalog("en")
+	require.NoError(t, err)
+	delivery := time.Date(2026, 3, 31, 20, 0, 0, 0, time.UTC)
+	data := map[string]interface{}{"OrderID": "ORD-1", "EstimatedDelivery": delivery}
+
+	// 20:00 UTC is already the next day in Jakarta
+	text, err := catalog.RenderSMS(entity.MessageTypeOrderShipped, "en", LocalizeTimes(data, "Asia/Jakarta"))
+	assert.NoError(t, err)
+	assert.Contains(t, text, "Estimated delivery: Apr 1.")
+
+	text, err = catalog.RenderSMS(entity.MessageTypeOrderShipped, "en", LocalizeTimes(data, "Not/AZone"))
+	assert.NoError(t, err)
+	assert.Contains(t, text, "Estimated delivery: Mar 31.")
+	assert.Equal(t, delivery, data["EstimatedDelivery"], "the original data is not modified")
+}
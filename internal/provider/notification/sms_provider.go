@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"boilerplate-go/infrastructure/httpclient"
 	"boilerplate-go/infrastructure/logger"
 	"boilerplate-go/internal/domain/entity"
 )
@@ -35,7 +36,8 @@ func NewSMSProvider(config SMSConfig, logger *logger.Logger) *SMSProvider {
 
 	return &SMSProvider{
 		httpClient: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: httpclient.NewTransport("sms_service", nil, logger, "message"),
 		},
 		baseURL:    config.BaseURL,
 		apiKey:     config.APIKey,
@@ -2,6 +2,7 @@ package notification
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"boilerplate-go/infrastructure/logger"
@@ -14,23 +15,31 @@ import (
 type UnifiedNotificationProvider struct {
 	emailProvider provider.EmailProvider
 	smsProvider   *SMSProvider
+	catalog       *MessageCatalog
 	logger        *logger.Logger
 }
 
 type UnifiedConfig struct {
-	EmailConfig EmailConfig
-	SMSConfig   SMSConfig
+	EmailConfig   EmailConfig
+	SMSConfig     SMSConfig
+	DefaultLocale string
 }
 
-func NewUnifiedNotificationProvider(config UnifiedConfig, logger *logger.Logger) provider.NotificationProvider {
+func NewUnifiedNotificationProvider(config UnifiedConfig, logger *logger.Logger) (provider.NotificationProvider, error) {
 	emailProvider := NewEmailProvider(config.EmailConfig, logger)
 	smsProvider := NewSMSProvider(config.SMSConfig, logger)
 
+	catalog, err := NewMessageCatalog(config.DefaultLocale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load message catalog: %w", err)
+	}
+
 	return &UnifiedNotificationProvider{
 		emailProvider: emailProvider,
 		smsProvider:   smsProvider,
+		catalog:       catalog,
 		logger:        logger,
-	}
+	}, nil
 }
 
 func (u *UnifiedNotificationProvider) SendEmail(ctx context.Context, req *entity.EmailRequest) (*entity.EmailResponse, error) {
@@ -72,3 +81,39 @@ func (u *UnifiedNotificationProvider) SendPushNotification(ctx context.Context,
 
 	return response, nil
 }
+
+// SendTemplatedSMS renders the catalog message for the recipient's locale and sends it by SMS.
+func (u *UnifiedNotificationProvider) SendTemplatedSMS(ctx context.Context, msg *entity.TemplatedMessage) (*entity.SMSResponse, error) {
+	text, err := u.catalog.RenderSMS(msg.Type, msg.Locale, LocalizeTimes(msg.Data, msg.Timezone))
+	if err != nil {
+		return nil, err
+	}
+
+	segments, unicode := SMSSegments(text)
+	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"provider":     "unified_notification",
+		"channel":      "sms",
+		"message_type": msg.Type,
+		"locale":       msg.Locale,
+		"segments":     segments,
+		"unicode":      unicode,
+		"operation":    "send_templated_sms",
+	}).Info("Routing templated SMS through unified provider")
+
+	return u.smsProvider.SendSMS(ctx, &entity.SMSRequest{To: msg.To, Message: text})
+}
+
+// SendTemplatedPush renders the catalog message for the recipient's locale and sends it as a push notification.
+func (u *UnifiedNotificationProvider) SendTemplatedPush(ctx context.Context, msg *entity.TemplatedMessage) (*entity.PushNotificationResponse, error) {
+	title, body, err := u.catalog.RenderPush(msg.Type, msg.Locale, LocalizeTimes(msg.Data, msg.Timezone))
+	if err != nil {
+		return nil, err
+	}
+
+	return u.SendPushNotification(ctx, &entity.PushNotificationRequest{
+		DeviceTokens: msg.DeviceTokens,
+		Title:        title,
+		Body:         body,
+		Data:         map[string]interface{}{"type": msg.Type},
+	})
+}
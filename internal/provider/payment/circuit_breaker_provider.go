@@ -0,0 +1,91 @@
+package payment
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/pkg/circuitbreaker"
+	"boilerplate-go/pkg/errors"
+)
+
+// CircuitBreakerProvider guards a payment provider with a circuit breaker.
+// While the circuit is open calls fail fast with errors.ErrProviderUnavailable.
+type CircuitBreakerProvider struct {
+	next    provider.PaymentProvider
+	breaker *circuitbreaker.Breaker
+	logger  *logger.Logger
+}
+
+func NewCircuitBreakerProvider(next provider.PaymentProvider, breaker *circuitbreaker.Breaker, logger *logger.Logger) provider.PaymentProvider {
+	return &CircuitBreakerProvider{
+		next:    next,
+		breaker: breaker,
+		logger:  logger,
+	}
+}
+
+// Unwrap returns the guarded provider
+func (p *CircuitBreakerProvider) Unwrap() provider.PaymentProvider {
+	return p.next
+}
+
+func (p *CircuitBreakerProvider) ProcessPayment(ctx context.Context, req *entity.PaymentRequest) (*entity.PaymentResponse, error) {
+	var resp *entity.PaymentResponse
+	err := p.call(ctx, "process_payment", func() (err error) {
+		resp, err = p.next.ProcessPayment(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (p *CircuitBreakerProvider) RefundPayment(ctx context.Context, paymentID string) (*entity.RefundResponse, error) {
+	var resp *entity.RefundResponse
+	err := p.call(ctx, "refund_payment", func() (err error) {
+		resp, err = p.next.RefundPayment(ctx, paymentID)
+		return err
+	})
+	return resp, err
+}
+
+func (p *CircuitBreakerProvider) GetPaymentStatus(ctx context.Context, paymentID string) (*entity.PaymentStatus, error) {
+	var resp *entity.PaymentStatus
+	err := p.call(ctx, "get_payment_status", func() (err error) {
+		resp, err = p.next.GetPaymentStatus(ctx, paymentID)
+		return err
+	})
+	return resp, err
+}
+
+func (p *CircuitBreakerProvider) CreatePaymentIntent(ctx context.Context, req *entity.PaymentIntentRequest) (*entity.PaymentIntent, error) {
+	var resp *entity.PaymentIntent
+	err := p.call(ctx, "create_payment_intent", func() (err error) {
+		resp, err = p.next.CreatePaymentIntent(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (p *CircuitBreakerProvider) CancelPaymentIntent(ctx context.Context, intentID string) (*entity.PaymentIntent, error) {
+	var resp *entity.PaymentIntent
+	err := p.call(ctx, "cancel_payment_intent", func() (err error) {
+		resp, err = p.next.CancelPaymentIntent(ctx, intentID)
+		return err
+	})
+	return resp, err
+}
+
+func (p *CircuitBreakerProvider) call(ctx context.Context, operation string, fn func() error) error {
+	err := p.breaker.Execute(fn)
+	if stderrors.Is(err, circuitbreaker.ErrOpen) {
+		p.logger.WithContext(ctx).WithFields(map[string]interface{}{
+			"operation": operation,
+			"circuit":   p.breaker.State().String(),
+		}).Warn("Payment provider circuit open, failing fast")
+		return fmt.Errorf("%w: payment %s: %v", errors.ErrProviderUnavailable, operation, err)
+	}
+	return err
+}
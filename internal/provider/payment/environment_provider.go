@@ -0,0 +1,98 @@
+package payment
+
+import (
+	"context"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+)
+
+// Recorder records the outcome of payment provider calls and access token
+// refreshes, such as the application metrics.
+type Recorder interface {
+	RecordPaymentRequest(provider, environment, operation string, err error)
+	RecordTokenRefresh(provider string, err error)
+}
+
+// EnvironmentProvider annotates the calls to a payment provider with its name
+// and environment (sandbox or live): log entries written with the call
+// context carry payment_provider and payment_environment fields, and each
+// call is recorded with them when a Recorder is set.
+type EnvironmentProvider struct {
+	next        provider.PaymentProvider
+	name        string
+	environment string
+	recorder    Recorder
+}
+
+func NewEnvironmentProvider(next provider.PaymentProvider, name, environment string, recorder Recorder) provider.PaymentProvider {
+	return &EnvironmentProvider{
+		next:        next,
+		name:        name,
+		environment: environment,
+		recorder:    recorder,
+	}
+}
+
+// Unwrap returns the annotated provider
+func (p *EnvironmentProvider) Unwrap() provider.PaymentProvider {
+	return p.next
+}
+
+func (p *EnvironmentProvider) ProcessPayment(ctx context.Context, req *entity.PaymentRequest) (*entity.PaymentResponse, error) {
+	var resp *entity.PaymentResponse
+	err := p.call(ctx, "process_payment", func(ctx context.Context) (err error) {
+		resp, err = p.next.ProcessPayment(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (p *EnvironmentProvider) RefundPayment(ctx context.Context, paymentID string) (*entity.RefundResponse, error) {
+	var resp *entity.RefundResponse
+	err := p.call(ctx, "refund_payment", func(ctx context.Context) (err error) {
+		resp, err = p.next.RefundPayment(ctx, paymentID)
+		return err
+	})
+	return resp, err
+}
+
+func (p *EnvironmentProvider) GetPaymentStatus(ctx context.Context, paymentID string) (*entity.PaymentStatus, error) {
+	var resp *entity.PaymentStatus
+	err := p.call(ctx, "get_payment_status", func(ctx context.Context) (err error) {
+		resp, err = p.next.GetPaymentStatus(ctx, paymentID)
+		return err
+	})
+	return resp, err
+}
+
+func (p *EnvironmentProvider) CreatePaymentIntent(ctx context.Context, req *entity.PaymentIntentRequest) (*entity.PaymentIntent, error) {
+	var resp *entity.PaymentIntent
+	err := p.call(ctx, "create_payment_intent", func(ctx context.Context) (err error) {
+		resp, err = p.next.CreatePaymentIntent(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (p *EnvironmentProvider) CancelPaymentIntent(ctx context.Context, intentID string) (*entity.PaymentIntent, error) {
+	var resp *entity.PaymentIntent
+	err := p.call(ctx, "cancel_payment_intent", func(ctx context.Context) (err error) {
+		resp, err = p.next.CancelPaymentIntent(ctx, intentID)
+		return err
+	})
+	return resp, err
+}
+
+func (p *EnvironmentProvider) call(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	ctx = logger.ContextWithFields(ctx, map[string]interface{}{
+		"payment_provider":    p.name,
+		"payment_environment": p.environment,
+	})
+	err := fn(ctx)
+	if p.recorder != nil {
+		p.recorder.RecordPaymentRequest(p.name, p.environment, operation, err)
+	}
+	return err
+}
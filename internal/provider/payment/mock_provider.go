@@ -0,0 +1,67 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+)
+
+// MockProvider simulates a payment provider that accepts every call without
+// contacting anyone or moving money. Dry-run orders are charged through it.
+type MockProvider struct{}
+
+func NewMockProvider() provider.PaymentProvider {
+	return &MockProvider{}
+}
+
+func (p *MockProvider) ProcessPayment(ctx context.Context, req *entity.PaymentRequest) (*entity.PaymentResponse, error) {
+	return &entity.PaymentResponse{
+		ID:            mockID("mock_pay_"),
+		Status:        "succeeded",
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		TransactionID: mockID("mock_txn_"),
+		CreatedAt:     time.Now(),
+		Metadata:      req.Metadata,
+	}, nil
+}
+
+func (p *MockProvider) RefundPayment(ctx context.Context, paymentID string) (*entity.RefundResponse, error) {
+	return &entity.RefundResponse{
+		ID:        mockID("mock_re_"),
+		PaymentID: paymentID,
+		Status:    "succeeded",
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func (p *MockProvider) GetPaymentStatus(ctx context.Context, paymentID string) (*entity.PaymentStatus, error) {
+	return &entity.PaymentStatus{
+		ID:        paymentID,
+		Status:    "succeeded",
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+func (p *MockProvider) CreatePaymentIntent(ctx context.Context, req *entity.PaymentIntentRequest) (*entity.PaymentIntent, error) {
+	id := mockID("mock_pi_")
+	return &entity.PaymentIntent{
+		ID:           id,
+		ClientSecret: id + "_secret",
+		Status:       "requires_payment_method",
+	}, nil
+}
+
+func (p *MockProvider) CancelPaymentIntent(ctx context.Context, intentID string) (*entity.PaymentIntent, error) {
+	return &entity.PaymentIntent{ID: intentID, Status: "canceled"}, nil
+}
+
+func mockID(prefix string) string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return prefix + hex.EncodeToString(b)
+}
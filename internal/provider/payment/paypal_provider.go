@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"boilerplate-go/infrastructure/httpclient"
 	"boilerplate-go/infrastructure/logger"
 	"boilerplate-go/internal/domain/entity"
 	"boilerplate-go/internal/domain/provider"
@@ -19,15 +20,17 @@ type PayPalProvider struct {
 	clientID     string
 	clientSecret string
 	logger       *logger.Logger
-	accessToken  string
-	tokenExpiry  time.Time
+	tokens       *TokenManager
 }
 
+// PayPalConfig configures the PayPal provider. Access token refreshes are
+// recorded by Recorder when set.
 type PayPalConfig struct {
 	BaseURL      string
 	ClientID     string
 	ClientSecret string
 	Timeout      time.Duration
+	Recorder     Recorder
 }
 
 func NewPayPalProvider(config PayPalConfig, logger *logger.Logger) provider.PaymentProvider {
@@ -36,15 +39,21 @@ func NewPayPalProvider(config PayPalConfig, logger *logger.Logger) provider.Paym
 		timeout = 30 * time.Second
 	}
 
-	return &PayPalProvider{
+	transport := httpclient.NewTransport("paypal", nil, logger)
+	transport.SetIdempotencyHeader("PayPal-Request-Id")
+
+	p := &PayPalProvider{
 		httpClient: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: transport,
 		},
 		baseURL:      config.BaseURL,
 		clientID:     config.ClientID,
 		clientSecret: config.ClientSecret,
 		logger:       logger,
 	}
+	p.tokens = NewTokenManager("paypal", p.fetchAccessToken, config.Recorder)
+	return p
 }
 
 func (p *PayPalProvider) ProcessPayment(ctx context.Context, req *entity.PaymentRequest) (*entity.PaymentResponse, error) {
@@ -57,23 +66,40 @@ func (p *PayPalProvider) ProcessPayment(ctx context.Context, req *entity.Payment
 	}).Info("Processing payment")
 
 	// Ensure we have a valid access token
-	if err := p.ensureValidToken(ctx); err != nil {
+	token, err := p.tokens.Token(ctx)
+	if err != nil {
 		return nil, p.handleError(ctx, err, "token_refresh_failed")
 	}
 
 	// Create PayPal order
-	orderReq := map[string]interface{}{
-		"intent": "CAPTURE",
-		"purchase_units": []map[string]interface{}{
-			{
-				"amount": map[string]interface{}{
-					"currency_code": req.Currency,
-					"value":         fmt.Sprintf("%.2f", req.Amount),
-				},
-				"description":  req.Description,
-				"reference_id": req.OrderID,
-			},
+	purchaseUnit := map[string]interface{}{
+		"amount": map[string]interface{}{
+			"currency_code": req.Currency,
+			"value":         fmt.Sprintf("%.2f", req.Amount),
 		},
+		"description":  req.Description,
+		"reference_id": req.OrderID,
+	}
+	if len(req.Items) > 0 {
+		purchaseUnit["items"] = paypalItems(req.Items, req.Currency)
+		breakdown := map[string]interface{}{
+			"item_total": map[string]interface{}{
+				"currency_code": req.Currency,
+				"value":         fmt.Sprintf("%.2f", req.Amount+req.Discount),
+			},
+		}
+		if req.Discount > 0 {
+			breakdown["discount"] = map[string]interface{}{
+				"currency_code": req.Currency,
+				"value":         fmt.Sprintf("%.2f", req.Discount),
+			}
+		}
+		purchaseUnit["amount"].(map[string]interface{})["breakdown"] = breakdown
+	}
+
+	orderReq := map[string]interface{}{
+		"intent":         "CAPTURE",
+		"purchase_units": []map[string]interface{}{purchaseUnit},
 	}
 
 	jsonData, err := json.Marshal(orderReq)
@@ -88,7 +114,7 @@ func (p *PayPalProvider) ProcessPayment(ctx context.Context, req *entity.Payment
 		return nil, p.handleError(ctx, err, "create_request_failed")
 	}
 
-	p.setHeaders(httpReq)
+	p.setHeaders(httpReq, token)
 
 	resp, err := p.httpClient.Do(httpReq)
 	if err != nil {
@@ -102,7 +128,7 @@ func (p *PayPalProvider) ProcessPayment(ctx context.Context, req *entity.Payment
 	}
 
 	// Capture the order (for demonstration, in real scenario this would be done after user approval)
-	return p.captureOrder(ctx, orderResp["id"].(string), req)
+	return p.captureOrder(ctx, token, orderResp["id"].(string), req)
 }
 
 func (p *PayPalProvider) RefundPayment(ctx context.Context, paymentID string) (*entity.RefundResponse, error) {
@@ -112,7 +138,8 @@ func (p *PayPalProvider) RefundPayment(ctx context.Context, paymentID string) (*
 		"operation":  "refund_payment",
 	}).Info("Processing refund")
 
-	if err := p.ensureValidToken(ctx); err != nil {
+	token, err := p.tokens.Token(ctx)
+	if err != nil {
 		return nil, p.handleError(ctx, err, "token_refresh_failed")
 	}
 
@@ -122,7 +149,7 @@ func (p *PayPalProvider) RefundPayment(ctx context.Context, paymentID string) (*
 		return nil, p.handleError(ctx, err, "create_request_failed")
 	}
 
-	p.setHeaders(httpReq)
+	p.setHeaders(httpReq, token)
 
 	resp, err := p.httpClient.Do(httpReq)
 	if err != nil {
@@ -140,7 +167,8 @@ func (p *PayPalProvider) GetPaymentStatus(ctx context.Context, paymentID string)
 		"operation":  "get_payment_status",
 	}).Info("Getting payment status")
 
-	if err := p.ensureValidToken(ctx); err != nil {
+	token, err := p.tokens.Token(ctx)
+	if err != nil {
 		return nil, p.handleError(ctx, err, "token_refresh_failed")
 	}
 
@@ -150,7 +178,7 @@ func (p *PayPalProvider) GetPaymentStatus(ctx context.Context, paymentID string)
 		return nil, p.handleError(ctx, err, "create_request_failed")
 	}
 
-	p.setHeaders(httpReq)
+	p.setHeaders(httpReq, token)
 
 	resp, err := p.httpClient.Do(httpReq)
 	if err != nil {
@@ -171,7 +199,8 @@ func (p *PayPalProvider) CreatePaymentIntent(ctx context.Context, req *entity.Pa
 	}).Info("Creating payment intent")
 
 	// For PayPal, payment intent is similar to creating an order
-	if err := p.ensureValidToken(ctx); err != nil {
+	token, err := p.tokens.Token(ctx)
+	if err != nil {
 		return nil, p.handleError(ctx, err, "token_refresh_failed")
 	}
 
@@ -199,7 +228,7 @@ func (p *PayPalProvider) CreatePaymentIntent(ctx context.Context, req *entity.Pa
 		return nil, p.handleError(ctx, err, "create_request_failed")
 	}
 
-	p.setHeaders(httpReq)
+	p.setHeaders(httpReq, token)
 
 	resp, err := p.httpClient.Do(httpReq)
 	if err != nil {
@@ -210,21 +239,37 @@ func (p *PayPalProvider) CreatePaymentIntent(ctx context.Context, req *entity.Pa
 	return p.parsePaymentIntentResponse(ctx, resp)
 }
 
-func (p *PayPalProvider) ensureValidToken(ctx context.Context) error {
-	if p.accessToken != "" && time.Now().Before(p.tokenExpiry) {
-		return nil
-	}
+// CancelPaymentIntent abandons an uncaptured PayPal order. PayPal has no cancel call
+// for checkout orders; an order that is never captured expires on its own, so nothing
+// is sent to the API and the intent is reported as locally voided.
+func (p *PayPalProvider) CancelPaymentIntent(ctx context.Context, intentID string) (*entity.PaymentIntent, error) {
+	p.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"provider":          "paypal",
+		"payment_intent_id": intentID,
+		"operation":         "cancel_payment_intent",
+	}).Info("Cancelling payment intent")
+
+	return &entity.PaymentIntent{
+		ID:     intentID,
+		Status: entity.PaymentIntentStatusLocallyVoided,
+	}, nil
+}
 
-	return p.refreshAccessToken(ctx)
+// Ping checks that the API is reachable and accepts the client credentials
+// by obtaining an access token, leaving the cached token alone
+func (p *PayPalProvider) Ping(ctx context.Context) error {
+	_, _, err := p.fetchAccessToken(ctx)
+	return err
 }
 
-func (p *PayPalProvider) refreshAccessToken(ctx context.Context) error {
+// fetchAccessToken obtains an access token with the client credentials
+func (p *PayPalProvider) fetchAccessToken(ctx context.Context) (string, time.Duration, error) {
 	tokenReq := "grant_type=client_credentials"
 
 	url := fmt.Sprintf("%s/v1/oauth2/token", p.baseURL)
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBufferString(tokenReq))
 	if err != nil {
-		return err
+		return "", 0, err
 	}
 
 	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -232,30 +277,36 @@ func (p *PayPalProvider) refreshAccessToken(ctx context.Context) error {
 
 	resp, err := p.httpClient.Do(httpReq)
 	if err != nil {
-		return err
+		return "", 0, err
 	}
 	defer resp.Body.Close()
 
-	var tokenResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return err
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("token request failed with status %d", resp.StatusCode)
 	}
 
-	p.accessToken = tokenResp["access_token"].(string)
-	expiresIn := int64(tokenResp["expires_in"].(float64))
-	p.tokenExpiry = time.Now().Add(time.Duration(expiresIn-60) * time.Second) // Refresh 60s before expiry
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", 0, err
+	}
+	if tokenResp.AccessToken == "" || tokenResp.ExpiresIn <= 0 {
+		return "", 0, fmt.Errorf("token response has no access token")
+	}
 
-	return nil
+	return tokenResp.AccessToken, time.Duration(tokenResp.ExpiresIn) * time.Second, nil
 }
 
-func (p *PayPalProvider) captureOrder(ctx context.Context, orderID string, req *entity.PaymentRequest) (*entity.PaymentResponse, error) {
+func (p *PayPalProvider) captureOrder(ctx context.Context, token, orderID string, req *entity.PaymentRequest) (*entity.PaymentResponse, error) {
 	url := fmt.Sprintf("%s/v2/checkout/orders/%s/capture", p.baseURL, orderID)
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer([]byte("{}")))
 	if err != nil {
 		return nil, p.handleError(ctx, err, "create_capture_request_failed")
 	}
 
-	p.setHeaders(httpReq)
+	p.setHeaders(httpReq, token)
 
 	resp, err := p.httpClient.Do(httpReq)
 	if err != nil {
@@ -266,8 +317,8 @@ func (p *PayPalProvider) captureOrder(ctx context.Context, orderID string, req *
 	return p.parseCaptureResponse(ctx, resp)
 }
 
-func (p *PayPalProvider) setHeaders(req *http.Request) {
-	req.Header.Set("Authorization", "Bearer "+p.accessToken)
+func (p *PayPalProvider) setHeaders(req *http.Request, token string) {
+	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "boilerplate-go/1.0")
@@ -405,6 +456,23 @@ func (p *PayPalProvider) parsePaymentIntentResponse(ctx context.Context, resp *h
 	return intentResp, nil
 }
 
+// paypalItems converts line items to PayPal purchase unit items.
+func paypalItems(items []entity.LineItem, currency string) []map[string]interface{} {
+	paypalItems := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		paypalItems = append(paypalItems, map[string]interface{}{
+			"name":     item.Name,
+			"sku":      item.SKU,
+			"quantity": fmt.Sprintf("%d", item.Quantity),
+			"unit_amount": map[string]interface{}{
+				"currency_code": currency,
+				"value":         fmt.Sprintf("%.2f", item.UnitAmount),
+			},
+		})
+	}
+	return paypalItems
+}
+
 func parseFloat(s string) float64 {
 	var f float64
 	fmt.Sscanf(s, "%f", &f)
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"boilerplate-go/infrastructure/httpclient"
 	"boilerplate-go/infrastructure/logger"
 	"boilerplate-go/internal/domain/entity"
 	"boilerplate-go/internal/domain/provider"
@@ -32,9 +33,13 @@ func NewStripeProvider(config StripeConfig, logger *logger.Logger) provider.Paym
 		timeout = 30 * time.Second
 	}
 
+	transport := httpclient.NewTransport("stripe", nil, logger)
+	transport.SetIdempotencyHeader("Idempotency-Key")
+
 	return &StripeProvider{
 		httpClient: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: transport,
 		},
 		baseURL: config.BaseURL,
 		apiKey:  config.APIKey,
@@ -63,6 +68,21 @@ func (s *StripeProvider) ProcessPayment(ctx context.Context, req *entity.Payment
 		stripeReq["customer"] = req.CustomerID
 	}
 
+	// Charges have no line item support, so the itemization travels as metadata
+	if len(req.Items) > 0 {
+		metadata := make(map[string]interface{}, len(req.Metadata)+len(req.Items))
+		for k, v := range req.Metadata {
+			metadata[k] = v
+		}
+		for i, item := range req.Items {
+			metadata[fmt.Sprintf("item_%d", i+1)] = fmt.Sprintf("%s x%d @ %.2f", item.SKU, item.Quantity, item.UnitAmount)
+		}
+		if req.Discount > 0 {
+			metadata["discount"] = fmt.Sprintf("%.2f", req.Discount)
+		}
+		stripeReq["metadata"] = metadata
+	}
+
 	jsonData, err := json.Marshal(stripeReq)
 	if err != nil {
 		return nil, s.handleError(ctx, err, "json_marshal_failed")
@@ -186,6 +206,51 @@ func (s *StripeProvider) CreatePaymentIntent(ctx context.Context, req *entity.Pa
 	return s.parsePaymentIntentResponse(ctx, resp)
 }
 
+func (s *StripeProvider) CancelPaymentIntent(ctx context.Context, intentID string) (*entity.PaymentIntent, error) {
+	s.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"provider":          "stripe",
+		"payment_intent_id": intentID,
+		"operation":         "cancel_payment_intent",
+	}).Info("Cancelling payment intent")
+
+	url := fmt.Sprintf("%s/payment_intents/%s/cancel", s.baseURL, intentID)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer([]byte("{}")))
+	if err != nil {
+		return nil, s.handleError(ctx, err, "create_request_failed")
+	}
+
+	s.setHeaders(httpReq)
+
+	resp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, s.handleError(ctx, err, "api_call_failed")
+	}
+	defer resp.Body.Close()
+
+	return s.parsePaymentIntentResponse(ctx, resp)
+}
+
+// Ping checks that the API is reachable and accepts the key by reading the
+// account balance
+func (s *StripeProvider) Ping(ctx context.Context) error {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+"/balance", nil)
+	if err != nil {
+		return err
+	}
+	s.setHeaders(httpReq)
+
+	resp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("balance check failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
 func (s *StripeProvider) setHeaders(req *http.Request) {
 	req.Header.Set("Authorization", "Bearer "+s.apiKey)
 	req.Header.Set("Content-Type", "application/json")
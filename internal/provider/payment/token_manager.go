@@ -0,0 +1,123 @@
+package payment
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// tokenExpiryMargin is how long before its expiry a token is no longer
+	// used, so it does not expire in flight
+	tokenExpiryMargin = time.Minute
+	// tokenRefreshTimeout bounds a refresh, which outlives the callers waiting on it
+	tokenRefreshTimeout = 30 * time.Second
+	// tokenRenewRetry is how long a failed background renewal waits to be retried
+	tokenRenewRetry = 30 * time.Second
+)
+
+// TokenFetcher obtains a new access token and how long it is valid for.
+type TokenFetcher func(ctx context.Context) (token string, lifetime time.Duration, err error)
+
+// TokenManager caches an OAuth access token for concurrent callers. A single
+// refresh runs at a time, shared by every caller waiting for a token. Once
+// three quarters of its lifetime has passed the token is renewed in the
+// background, so callers rarely wait on a refresh.
+type TokenManager struct {
+	provider string
+	fetch    TokenFetcher
+	recorder Recorder
+	now      func() time.Time
+	group    singleflight.Group
+	renewing atomic.Bool
+
+	mu        sync.RWMutex
+	token     string
+	renewAt   time.Time
+	expiresAt time.Time
+}
+
+// NewTokenManager creates a token manager for the named provider. Refreshes
+// are recorded when recorder is non-nil.
+func NewTokenManager(provider string, fetch TokenFetcher, recorder Recorder) *TokenManager {
+	return &TokenManager{
+		provider: provider,
+		fetch:    fetch,
+		recorder: recorder,
+		now:      time.Now,
+	}
+}
+
+// Token returns a valid access token, refreshing it first if there is none.
+func (m *TokenManager) Token(ctx context.Context) (string, error) {
+	m.mu.RLock()
+	token, renewAt, expiresAt := m.token, m.renewAt, m.expiresAt
+	m.mu.RUnlock()
+
+	now := m.now()
+	if token != "" && now.Before(expiresAt) {
+		if !now.Before(renewAt) && m.renewing.CompareAndSwap(false, true) {
+			go m.renew(ctx)
+		}
+		return token, nil
+	}
+
+	result := m.group.DoChan("token", func() (interface{}, error) {
+		return m.refreshToken(ctx)
+	})
+	select {
+	case res := <-result:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(string), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// renew refreshes the token in the background, joining a refresh in progress
+func (m *TokenManager) renew(ctx context.Context) {
+	defer m.renewing.Store(false)
+	_, err, _ := m.group.Do("token", func() (interface{}, error) {
+		return m.refreshToken(ctx)
+	})
+	if err != nil {
+		// Keep using the current token until it expires, retrying meanwhile
+		m.mu.Lock()
+		m.renewAt = m.now().Add(tokenRenewRetry)
+		m.mu.Unlock()
+	}
+}
+
+// refreshToken fetches a new token and caches it. It is detached from the
+// cancellation of the caller that started it, since other callers share it.
+func (m *TokenManager) refreshToken(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tokenRefreshTimeout)
+	defer cancel()
+
+	token, lifetime, err := m.fetch(ctx)
+	if m.recorder != nil {
+		m.recorder.RecordTokenRefresh(m.provider, err)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	margin := tokenExpiryMargin
+	if margin > lifetime/2 {
+		margin = lifetime / 2
+	}
+	fetchedAt := m.now()
+
+	m.mu.Lock()
+	m.token = token
+	m.renewAt = fetchedAt.Add(lifetime * 3 / 4)
+	m.expiresAt = fetchedAt.Add(lifetime - margin)
+	m.mu.Unlock()
+
+	return token, nil
+}
@@ -0,0 +1,125 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refreshRecorder counts recorded token refreshes
+type refreshRecorder struct {
+	mu       sync.Mutex
+	success  int
+	failures int
+}
+
+func (r *refreshRecorder) RecordPaymentRequest(provider, environment, operation string, err error) {}
+
+func (r *refreshRecorder) RecordTokenRefresh(provider string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.failures++
+		return
+	}
+	r.success++
+}
+
+func TestTokenManager_SharesRefresh(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) (string, time.Duration, error) {
+		n := fetches.Add(1)
+		<-release
+		return fmt.Sprintf("token-%d", n), time.Hour, nil
+	}
+	recorder := &refreshRecorder{}
+	m := NewTokenManager("paypal", fetch, recorder)
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 20)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			token, err := m.Token(context.Background())
+			assert.NoError(t, err)
+			tokens[i] = token
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), fetches.Load())
+	for _, token := range tokens {
+		assert.Equal(t, "token-1", token)
+	}
+	assert.Equal(t, 1, recorder.success)
+}
+
+func TestTokenManager_RenewsInBackground(t *testing.T) {
+	var fetches atomic.Int32
+	renewed := make(chan struct{}, 1)
+	fetch := func(ctx context.Context) (string, time.Duration, error) {
+		n := fetches.Add(1)
+		if n > 1 {
+			renewed <- struct{}{}
+		}
+		return fmt.Sprintf("token-%d", n), time.Hour, nil
+	}
+	m := NewTokenManager("paypal", fetch, nil)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	token, err := m.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	// Past three quarters of its lifetime the current token is still served
+	// while a new one is fetched
+	now = now.Add(50 * time.Minute)
+	token, err = m.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	select {
+	case <-renewed:
+	case <-time.After(time.Second):
+		t.Fatal("token was not renewed")
+	}
+	require.Eventually(t, func() bool {
+		token, err := m.Token(context.Background())
+		return err == nil && token == "token-2"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestTokenManager_RefreshFailure(t *testing.T) {
+	fail := true
+	fetch := func(ctx context.Context) (string, time.Duration, error) {
+		if fail {
+			return "", 0, errors.New("unauthorized")
+		}
+		return "token", time.Hour, nil
+	}
+	recorder := &refreshRecorder{}
+	m := NewTokenManager("paypal", fetch, recorder)
+
+	_, err := m.Token(context.Background())
+	assert.Error(t, err)
+
+	// A failed refresh is not cached
+	fail = false
+	token, err := m.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+	assert.Equal(t, 1, recorder.failures)
+	assert.Equal(t, 1, recorder.success)
+}
@@ -0,0 +1,169 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+)
+
+// clamAVChunkSize is the size of the chunks streamed to clamd
+const clamAVChunkSize = 64 << 10
+
+// ClamAVProvider scans files with a clamd daemon using the INSTREAM command,
+// so content is streamed to the daemon without being written to shared disk.
+// clamd rejects streams longer than its StreamMaxLength setting (25 MB by
+// default); raise it to the largest file that should be scanned.
+type ClamAVProvider struct {
+	network string
+	address string
+	timeout time.Duration
+	logger  *logger.Logger
+}
+
+type ClamAVConfig struct {
+	// Address is host:port for TCP or an absolute unix socket path
+	Address string
+	Timeout time.Duration
+}
+
+func NewClamAVProvider(config ClamAVConfig, logger *logger.Logger) provider.ScannerProvider {
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Minute
+	}
+
+	network := "tcp"
+	if strings.HasPrefix(config.Address, "/") {
+		network = "unix"
+	}
+
+	return &ClamAVProvider{
+		network: network,
+		address: config.Address,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+func (p *ClamAVProvider) ScanFile(ctx context.Context, content io.Reader) (*entity.ScanResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, p.network, p.address)
+	if err != nil {
+		return nil, p.handleError(ctx, err, "connect_failed")
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if err := stream(conn, content); err != nil {
+		return nil, p.handleError(ctx, err, "stream_failed")
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		return nil, p.handleError(ctx, err, "read_reply_failed")
+	}
+	result, err := parseReply(strings.TrimSuffix(reply, "\x00"))
+	if err != nil {
+		return nil, p.handleError(ctx, err, "scan_failed")
+	}
+
+	if !result.Clean {
+		p.logger.WithContext(ctx).WithFields(map[string]interface{}{
+			"provider": "clamav",
+			"threat":   result.Threat,
+		}).Warn("Malware detected")
+	}
+	return result, nil
+}
+
+// Ping checks that clamd is reachable and answering commands
+func (p *ClamAVProvider) Ping(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, p.network, p.address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := io.WriteString(conn, "zPING\x00"); err != nil {
+		return err
+	}
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		return err
+	}
+	if reply = strings.TrimSuffix(reply, "\x00"); reply != "PONG" {
+		return fmt.Errorf("unexpected clamd reply: %q", reply)
+	}
+	return nil
+}
+
+// stream sends content with the INSTREAM command: length prefixed chunks
+// ended by a zero length chunk.
+func stream(w io.Writer, content io.Reader) error {
+	if _, err := io.WriteString(w, "zINSTREAM\x00"); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 4+clamAVChunkSize)
+	for {
+		n, err := io.ReadFull(content, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, werr := w.Write(buf[:4+n]); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read content: %w", err)
+		}
+	}
+
+	_, err := w.Write([]byte{0, 0, 0, 0})
+	return err
+}
+
+// parseReply reads a clamd reply such as "stream: OK" or
+// "stream: Eicar-Test-Signature FOUND".
+func parseReply(reply string) (*entity.ScanResult, error) {
+	result := &entity.ScanResult{Engine: "clamav", ScannedAt: time.Now()}
+	verdict := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+
+	switch {
+	case verdict == "OK":
+		result.Clean = true
+	case strings.HasSuffix(verdict, " FOUND"):
+		result.Threat = strings.TrimSuffix(verdict, " FOUND")
+	default:
+		return nil, fmt.Errorf("clamd error: %s", reply)
+	}
+	return result, nil
+}
+
+func (p *ClamAVProvider) handleError(ctx context.Context, err error, operation string) error {
+	p.logger.ErrorLogger(ctx, err, "Malware scan failed", map[string]interface{}{
+		"provider":  "clamav",
+		"operation": operation,
+	})
+	return fmt.Errorf("clamav %s: %w", operation, err)
+}
@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"boilerplate-go/infrastructure/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClamd answers PING, and INSTREAM commands with the reply for the
+// streamed content.
+func fakeClamd(t *testing.T, reply func(content []byte) string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				command, err := r.ReadString(0)
+				if err != nil {
+					return
+				}
+				if command == "zPING\x00" {
+					conn.Write([]byte("PONG\x00"))
+					return
+				}
+				if command != "zINSTREAM\x00" {
+					return
+				}
+				var content bytes.Buffer
+				for {
+					var size uint32
+					if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+						return
+					}
+					if size == 0 {
+						break
+					}
+					io.CopyN(&content, r, int64(size))
+				}
+				conn.Write([]byte(reply(content.Bytes()) + "\x00"))
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestClamAVProvider_ScanFile(t *testing.T) {
+	address := fakeClamd(t, func(content []byte) string {
+		if bytes.Contains(content, []byte("EICAR")) {
+			return "stream: Eicar-Test-Signature FOUND"
+		}
+		return "stream: OK"
+	})
+	p := NewClamAVProvider(ClamAVConfig{Address: address}, logger.NewLogger())
+	ctx := context.Background()
+
+	// Content spanning several chunks is streamed whole
+	result, err := p.ScanFile(ctx, strings.NewReader(strings.Repeat("a", 3*clamAVChunkSize+10)))
+	require.NoError(t, err)
+	assert.True(t, result.Clean)
+	assert.Equal(t, "clamav", result.Engine)
+
+	result, err = p.ScanFile(ctx, strings.NewReader(strings.Repeat("a", clamAVChunkSize)+"EICAR"))
+	require.NoError(t, err)
+	assert.False(t, result.Clean)
+	assert.Equal(t, "Eicar-Test-Signature", result.Threat)
+}
+
+func TestClamAVProvider_Errors(t *testing.T) {
+	address := fakeClamd(t, func([]byte) string { return "INSTREAM size limit exceeded. ERROR" })
+	p := NewClamAVProvider(ClamAVConfig{Address: address}, logger.NewLogger())
+
+	_, err := p.ScanFile(context.Background(), strings.NewReader("content"))
+	assert.ErrorContains(t, err, "size limit exceeded")
+
+	// A daemon that is down fails the scan rather than passing the file
+	unreachable := NewClamAVProvider(ClamAVConfig{Address: "127.0.0.1:1"}, logger.NewLogger())
+	_, err = unreachable.ScanFile(context.Background(), strings.NewReader("content"))
+	assert.Error(t, err)
+}
+
+func TestClamAVProvider_Ping(t *testing.T) {
+	address := fakeClamd(t, func(content []byte) string { return "stream: OK" })
+
+	p := NewClamAVProvider(ClamAVConfig{Address: address}, logger.NewLogger()).(*ClamAVProvider)
+	assert.NoError(t, p.Ping(context.Background()))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed := listener.Addr().String()
+	listener.Close()
+
+	p = NewClamAVProvider(ClamAVConfig{Address: closed}, logger.NewLogger()).(*ClamAVProvider)
+	assert.Error(t, p.Ping(context.Background()))
+}
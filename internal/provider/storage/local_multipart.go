@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+)
+
+// multipartDir holds the parts of unfinished multipart uploads under BasePath,
+// one directory per upload and one file per part.
+const multipartDir = ".multipart"
+
+var localUploadIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+func (p *LocalStorageProvider) CreateMultipartUpload(ctx context.Context, req *entity.MultipartUploadRequest) (*entity.MultipartUpload, error) {
+	id, err := fileID(req.Path, req.FileName)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, p.handleError(ctx, err, "create_multipart_failed")
+	}
+	uploadID := hex.EncodeToString(buf)
+
+	if err := os.MkdirAll(filepath.Join(p.basePath, multipartDir, uploadID), 0o755); err != nil {
+		return nil, p.handleError(ctx, err, "create_multipart_failed")
+	}
+
+	return &entity.MultipartUpload{FileID: id, UploadID: uploadID}, nil
+}
+
+func (p *LocalStorageProvider) UploadPart(ctx context.Context, req *entity.FilePartRequest) (*entity.FilePart, error) {
+	dir, err := p.partsDir(req.UploadID)
+	if err != nil {
+		return nil, err
+	}
+	if req.PartNumber < 1 {
+		return nil, fmt.Errorf("invalid part number %d", req.PartNumber)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".part-*")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.ErrUploadNotFound
+		}
+		return nil, p.handleError(ctx, err, "upload_part_failed")
+	}
+
+	hash := md5.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), req.Content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(dir, strconv.Itoa(req.PartNumber)))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, p.handleError(ctx, err, "upload_part_failed")
+	}
+
+	return &entity.FilePart{
+		PartNumber: req.PartNumber,
+		ETag:       hex.EncodeToString(hash.Sum(nil)),
+		Size:       size,
+	}, nil
+}
+
+func (p *LocalStorageProvider) CompleteMultipartUpload(ctx context.Context, id, uploadID string, parts []entity.FilePart) (*entity.FileUploadResponse, error) {
+	dir, err := p.partsDir(uploadID)
+	if err != nil {
+		return nil, err
+	}
+	id, err = fileID(id, "")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, errors.ErrUploadNotFound
+	}
+
+	target := filepath.Join(p.basePath, filepath.FromSlash(id))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return nil, p.handleError(ctx, err, "create_directory_failed")
+	}
+
+	// Join the parts into a temporary file and rename it so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return nil, p.handleError(ctx, err, "create_file_failed")
+	}
+	var size int64
+	for _, part := range parts {
+		var n int64
+		n, err = appendFile(tmp, filepath.Join(dir, strconv.Itoa(part.PartNumber)))
+		if err != nil {
+			break
+		}
+		size += n
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), target)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, p.handleError(ctx, err, "complete_multipart_failed")
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		p.handleError(ctx, err, "remove_parts_failed")
+	}
+
+	return &entity.FileUploadResponse{
+		ID:         id,
+		URL:        p.publicURL + "/" + id,
+		Path:       id,
+		Size:       size,
+		UploadedAt: time.Now(),
+	}, nil
+}
+
+func (p *LocalStorageProvider) AbortMultipartUpload(ctx context.Context, id, uploadID string) error {
+	dir, err := p.partsDir(uploadID)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return p.handleError(ctx, err, "abort_multipart_failed")
+	}
+	return nil
+}
+
+// partsDir returns the directory holding the parts of an upload.
+func (p *LocalStorageProvider) partsDir(uploadID string) (string, error) {
+	if !localUploadIDPattern.MatchString(uploadID) {
+		return "", errors.ErrUploadNotFound
+	}
+	return filepath.Join(p.basePath, multipartDir, uploadID), nil
+}
+
+// appendFile copies the file at name to the end of dst.
+func appendFile(dst io.Writer, name string) (int64, error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+	return io.Copy(dst, src)
+}
@@ -0,0 +1,277 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/pkg/errors"
+)
+
+// LocalStorageProvider stores files on the local filesystem under BasePath.
+// File URLs are PublicURL/{id}; the application or a CDN in front of it serves
+// them. Upload metadata is not kept.
+//
+// Signed URLs are SignedURL/{id}?expires={unix}&signature={hmac}, signed with
+// SigningKey and checked by OpenSignedFile when the application serves them.
+type LocalStorageProvider struct {
+	basePath   string
+	publicURL  string
+	signedURL  string
+	signingKey []byte
+	logger     *logger.Logger
+	now        func() time.Time
+}
+
+type LocalConfig struct {
+	BasePath   string
+	PublicURL  string
+	SignedURL  string
+	SigningKey string
+}
+
+func NewLocalStorageProvider(config LocalConfig, logger *logger.Logger) provider.FileStorageProvider {
+	return &LocalStorageProvider{
+		basePath:   config.BasePath,
+		publicURL:  strings.TrimRight(config.PublicURL, "/"),
+		signedURL:  strings.TrimRight(config.SignedURL, "/"),
+		signingKey: []byte(config.SigningKey),
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+func (p *LocalStorageProvider) UploadFile(ctx context.Context, req *entity.FileUploadRequest) (*entity.FileUploadResponse, error) {
+	id, err := fileID(req.Path, req.FileName)
+	if err != nil {
+		return nil, err
+	}
+
+	target := filepath.Join(p.basePath, filepath.FromSlash(id))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return nil, p.handleError(ctx, err, "create_directory_failed")
+	}
+
+	// Write to a temporary file and rename it so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return nil, p.handleError(ctx, err, "create_file_failed")
+	}
+	_, err = tmp.Write(req.Content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), target)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, p.handleError(ctx, err, "write_file_failed")
+	}
+
+	return &entity.FileUploadResponse{
+		ID:         id,
+		URL:        p.publicURL + "/" + id,
+		Path:       id,
+		Size:       int64(len(req.Content)),
+		MimeType:   req.ContentType,
+		UploadedAt: time.Now(),
+	}, nil
+}
+
+func (p *LocalStorageProvider) DownloadFile(ctx context.Context, id string) (*entity.FileDownloadResponse, error) {
+	info, err := p.GetFileInfo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(filepath.Join(p.basePath, filepath.FromSlash(info.ID)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.ErrFileNotFound
+		}
+		return nil, p.handleError(ctx, err, "read_file_failed")
+	}
+
+	return &entity.FileDownloadResponse{
+		ID:          info.ID,
+		FileName:    info.FileName,
+		Content:     content,
+		ContentType: info.ContentType,
+		Size:        int64(len(content)),
+	}, nil
+}
+
+func (p *LocalStorageProvider) DeleteFile(ctx context.Context, id string) error {
+	id, err := fileID(id, "")
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filepath.Join(p.basePath, filepath.FromSlash(id))); err != nil && !os.IsNotExist(err) {
+		return p.handleError(ctx, err, "delete_file_failed")
+	}
+	return nil
+}
+
+func (p *LocalStorageProvider) GetFileInfo(ctx context.Context, id string) (*entity.FileInfo, error) {
+	id, err := fileID(id, "")
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(filepath.Join(p.basePath, filepath.FromSlash(id)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.ErrFileNotFound
+		}
+		return nil, p.handleError(ctx, err, "stat_file_failed")
+	}
+	if stat.IsDir() {
+		return nil, errors.ErrFileNotFound
+	}
+
+	return &entity.FileInfo{
+		ID:          id,
+		FileName:    path.Base(id),
+		Path:        id,
+		Size:        stat.Size(),
+		ContentType: mime.TypeByExtension(path.Ext(id)),
+		URL:         p.publicURL + "/" + id,
+		UploadedAt:  stat.ModTime(),
+		UpdatedAt:   stat.ModTime(),
+	}, nil
+}
+
+func (p *LocalStorageProvider) OpenFile(ctx context.Context, id string) (io.ReadCloser, error) {
+	id, err := fileID(id, "")
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := p.open(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (p *LocalStorageProvider) MoveFile(ctx context.Context, id, newID string) error {
+	id, err := fileID(id, "")
+	if err != nil {
+		return err
+	}
+	newID, err = fileID(newID, "")
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(p.basePath, filepath.FromSlash(newID))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return p.handleError(ctx, err, "create_directory_failed")
+	}
+	if err := os.Rename(filepath.Join(p.basePath, filepath.FromSlash(id)), target); err != nil {
+		if os.IsNotExist(err) {
+			return errors.ErrFileNotFound
+		}
+		return p.handleError(ctx, err, "move_file_failed")
+	}
+	return nil
+}
+
+func (p *LocalStorageProvider) GetSignedURL(ctx context.Context, id string, ttl time.Duration) (string, error) {
+	if len(p.signingKey) == 0 {
+		return "", fmt.Errorf("local storage signing key not configured: %w", errors.ErrProviderUnavailable)
+	}
+	if ttl <= 0 {
+		return "", fmt.Errorf("signed URL ttl must be positive, got %s", ttl)
+	}
+	id, err := fileID(id, "")
+	if err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(p.now().Add(ttl).Unix(), 10)
+	query := url.Values{
+		"expires":   {expires},
+		"signature": {p.sign(id, expires)},
+	}
+	return p.signedURL + "/" + escapePath(id) + "?" + query.Encode(), nil
+}
+
+// OpenSignedFile checks a signed URL issued by GetSignedURL and opens the file
+// it points to. The caller closes the file.
+func (p *LocalStorageProvider) OpenSignedFile(ctx context.Context, id, expires, signature string) (*os.File, error) {
+	if len(p.signingKey) == 0 {
+		return nil, errors.ErrInvalidSignedURL
+	}
+	id, err := fileID(id, "")
+	if err != nil {
+		return nil, errors.ErrInvalidSignedURL
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(p.sign(id, expires))) {
+		return nil, errors.ErrInvalidSignedURL
+	}
+	if p.now().Unix() >= expiresAt {
+		return nil, errors.ErrInvalidSignedURL
+	}
+
+	return p.open(ctx, id)
+}
+
+// open opens the regular file with the given, already checked, ID.
+func (p *LocalStorageProvider) open(ctx context.Context, id string) (*os.File, error) {
+	file, err := os.Open(filepath.Join(p.basePath, filepath.FromSlash(id)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.ErrFileNotFound
+		}
+		return nil, p.handleError(ctx, err, "open_file_failed")
+	}
+	if stat, err := file.Stat(); err != nil || stat.IsDir() {
+		file.Close()
+		return nil, errors.ErrFileNotFound
+	}
+	return file, nil
+}
+
+// sign returns the hex HMAC-SHA256 of the file ID and expiry.
+func (p *LocalStorageProvider) sign(id, expires string) string {
+	return hex.EncodeToString(hmacSHA256(p.signingKey, id+"\n"+expires))
+}
+
+func (p *LocalStorageProvider) handleError(ctx context.Context, err error, operation string) error {
+	p.logger.ErrorLogger(ctx, err, "File storage operation failed", map[string]interface{}{
+		"provider":  "local",
+		"operation": operation,
+	})
+	return fmt.Errorf("file storage %s: %w", operation, err)
+}
+
+// fileID joins a directory and file name into a relative, slash separated ID,
+// rejecting IDs that would escape the storage root or reach multipart parts.
+func fileID(dir, name string) (string, error) {
+	id := path.Clean(path.Join(dir, name))
+	if id == "." || id == ".." || strings.HasPrefix(id, "../") || strings.HasPrefix(id, "/") ||
+		id == multipartDir || strings.HasPrefix(id, multipartDir+"/") {
+		return "", fmt.Errorf("invalid file id %q", path.Join(dir, name))
+	}
+	return id, nil
+}
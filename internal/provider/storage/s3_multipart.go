@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+)
+
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name                `xml:"CompleteMultipartUpload"`
+	Parts   []completeMultipartPart `xml:"Part"`
+}
+
+type completeMultipartPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// s3Error is the error document S3 returns, with a 200 status on a failed
+// CompleteMultipartUpload.
+type s3Error struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+func (p *S3StorageProvider) CreateMultipartUpload(ctx context.Context, req *entity.MultipartUploadRequest) (*entity.MultipartUpload, error) {
+	id, err := fileID(req.Path, req.FileName)
+	if err != nil {
+		return nil, err
+	}
+
+	header := objectHeader(req.ContentType, req.CacheControl, req.Metadata)
+	resp, err := p.do(ctx, http.MethodPost, id, url.Values{"uploads": {""}}, header, nil)
+	if err != nil {
+		return nil, p.handleError(ctx, err, "create_multipart_failed")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, p.handleError(ctx, fmt.Errorf("S3 API error: %d", resp.StatusCode), "create_multipart_failed")
+	}
+
+	var result initiateMultipartUploadResult
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil || result.UploadID == "" {
+		if err == nil {
+			err = fmt.Errorf("S3 returned no upload id")
+		}
+		return nil, p.handleError(ctx, err, "create_multipart_failed")
+	}
+
+	return &entity.MultipartUpload{FileID: id, UploadID: result.UploadID}, nil
+}
+
+// UploadPart buffers the part to sign its payload, so memory use is bounded by
+// the part size rather than the file size.
+func (p *S3StorageProvider) UploadPart(ctx context.Context, req *entity.FilePartRequest) (*entity.FilePart, error) {
+	content, err := io.ReadAll(req.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read part %d: %w", req.PartNumber, err)
+	}
+
+	query := url.Values{
+		"partNumber": {strconv.Itoa(req.PartNumber)},
+		"uploadId":   {req.UploadID},
+	}
+	resp, err := p.do(ctx, http.MethodPut, req.FileID, query, nil, content)
+	if err != nil {
+		return nil, p.handleError(ctx, err, "upload_part_failed")
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.ErrUploadNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, p.handleError(ctx, fmt.Errorf("S3 API error: %d", resp.StatusCode), "upload_part_failed")
+	}
+
+	return &entity.FilePart{
+		PartNumber: req.PartNumber,
+		ETag:       resp.Header.Get("ETag"),
+		Size:       int64(len(content)),
+	}, nil
+}
+
+func (p *S3StorageProvider) CompleteMultipartUpload(ctx context.Context, id, uploadID string, parts []entity.FilePart) (*entity.FileUploadResponse, error) {
+	body := completeMultipartUpload{}
+	var size int64
+	for _, part := range parts {
+		body.Parts = append(body.Parts, completeMultipartPart{PartNumber: part.PartNumber, ETag: part.ETag})
+		size += part.Size
+	}
+	payload, err := xml.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode multipart completion: %w", err)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/xml")
+	resp, err := p.do(ctx, http.MethodPost, id, url.Values{"uploadId": {uploadID}}, header, payload)
+	if err != nil {
+		return nil, p.handleError(ctx, err, "complete_multipart_failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.ErrUploadNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, p.handleError(ctx, fmt.Errorf("S3 API error: %d", resp.StatusCode), "complete_multipart_failed")
+	}
+
+	// A failure after the request was accepted is reported in the body
+	result, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, p.handleError(ctx, err, "complete_multipart_failed")
+	}
+	var s3Err s3Error
+	if xml.Unmarshal(result, &s3Err) == nil {
+		return nil, p.handleError(ctx, fmt.Errorf("S3 API error: %s: %s", s3Err.Code, s3Err.Message), "complete_multipart_failed")
+	}
+
+	return &entity.FileUploadResponse{
+		ID:         id,
+		URL:        p.publicURL + "/" + escapePath(id),
+		Path:       id,
+		Size:       size,
+		UploadedAt: time.Now(),
+	}, nil
+}
+
+func (p *S3StorageProvider) AbortMultipartUpload(ctx context.Context, id, uploadID string) error {
+	resp, err := p.do(ctx, http.MethodDelete, id, url.Values{"uploadId": {uploadID}}, nil, nil)
+	if err != nil {
+		return p.handleError(ctx, err, "abort_multipart_failed")
+	}
+	resp.Body.Close()
+
+	// An upload that is already gone needs no aborting
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		return p.handleError(ctx, fmt.Errorf("S3 API error: %d", resp.StatusCode), "abort_multipart_failed")
+	}
+	return nil
+}
@@ -0,0 +1,330 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"boilerplate-go/infrastructure/httpclient"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/pkg/errors"
+)
+
+// metadataHeaderPrefix carries user metadata on S3 objects
+const metadataHeaderPrefix = "X-Amz-Meta-"
+
+// maxPresignTTL is the longest validity S3 accepts for a presigned URL
+const maxPresignTTL = 7 * 24 * time.Hour
+
+// S3StorageProvider stores files in an S3 bucket, signing requests with AWS
+// Signature Version 4. With an Endpoint (e.g. MinIO) objects are addressed
+// path-style as {Endpoint}/{bucket}/{id}, otherwise virtual-hosted style.
+// File URLs use PublicURL, typically a CDN in front of the bucket, when set.
+type S3StorageProvider struct {
+	httpClient   *http.Client
+	streamClient *http.Client
+	signer       *signer
+	bucket       string
+	bucketURL    string
+	publicURL    string
+	logger       *logger.Logger
+}
+
+type S3Config struct {
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Endpoint        string
+	PublicURL       string
+	Timeout         time.Duration
+}
+
+func NewS3StorageProvider(config S3Config, logger *logger.Logger) provider.FileStorageProvider {
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	bucketURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com", config.Bucket, config.Region)
+	if config.Endpoint != "" {
+		bucketURL = strings.TrimRight(config.Endpoint, "/") + "/" + config.Bucket
+	}
+	publicURL := strings.TrimRight(config.PublicURL, "/")
+	if publicURL == "" {
+		publicURL = bucketURL
+	}
+	transport := httpclient.NewTransport("s3", nil, logger)
+	// Object bodies are files rather than API responses; downloads are
+	// bounded by the client timeout or the stream reader's context
+	transport.SetResponseLimits(0, 0)
+
+	return &S3StorageProvider{
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+		// Streamed downloads take as long as the reader needs, bounded by its context
+		streamClient: &http.Client{Transport: transport},
+		signer: &signer{
+			accessKeyID:     config.AccessKeyID,
+			secretAccessKey: config.SecretAccessKey,
+			region:          config.Region,
+			service:         "s3",
+		},
+		bucket:    config.Bucket,
+		bucketURL: bucketURL,
+		publicURL: publicURL,
+		logger:    logger,
+	}
+}
+
+func (p *S3StorageProvider) UploadFile(ctx context.Context, req *entity.FileUploadRequest) (*entity.FileUploadResponse, error) {
+	id, err := fileID(req.Path, req.FileName)
+	if err != nil {
+		return nil, err
+	}
+
+	header := objectHeader(req.ContentType, req.CacheControl, req.Metadata)
+	resp, err := p.do(ctx, http.MethodPut, id, nil, header, req.Content)
+	if err != nil {
+		return nil, p.handleError(ctx, err, "upload_failed")
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, p.handleError(ctx, fmt.Errorf("S3 API error: %d", resp.StatusCode), "upload_failed")
+	}
+
+	return &entity.FileUploadResponse{
+		ID:         id,
+		URL:        p.publicURL + "/" + escapePath(id),
+		Path:       id,
+		Size:       int64(len(req.Content)),
+		MimeType:   req.ContentType,
+		UploadedAt: time.Now(),
+	}, nil
+}
+
+func (p *S3StorageProvider) DownloadFile(ctx context.Context, id string) (*entity.FileDownloadResponse, error) {
+	resp, err := p.do(ctx, http.MethodGet, id, nil, nil, nil)
+	if err != nil {
+		return nil, p.handleError(ctx, err, "download_failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.ErrFileNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, p.handleError(ctx, fmt.Errorf("S3 API error: %d", resp.StatusCode), "download_failed")
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, p.handleError(ctx, err, "download_failed")
+	}
+
+	return &entity.FileDownloadResponse{
+		ID:          id,
+		FileName:    path.Base(id),
+		Content:     content,
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        int64(len(content)),
+		Metadata:    objectMetadata(resp.Header),
+	}, nil
+}
+
+func (p *S3StorageProvider) DeleteFile(ctx context.Context, id string) error {
+	resp, err := p.do(ctx, http.MethodDelete, id, nil, nil, nil)
+	if err != nil {
+		return p.handleError(ctx, err, "delete_failed")
+	}
+	resp.Body.Close()
+
+	// Deleting a missing object also succeeds with 204
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return p.handleError(ctx, fmt.Errorf("S3 API error: %d", resp.StatusCode), "delete_failed")
+	}
+	return nil
+}
+
+func (p *S3StorageProvider) GetFileInfo(ctx context.Context, id string) (*entity.FileInfo, error) {
+	resp, err := p.do(ctx, http.MethodHead, id, nil, nil, nil)
+	if err != nil {
+		return nil, p.handleError(ctx, err, "head_failed")
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.ErrFileNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, p.handleError(ctx, fmt.Errorf("S3 API error: %d", resp.StatusCode), "head_failed")
+	}
+
+	size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	modified, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &entity.FileInfo{
+		ID:          id,
+		FileName:    path.Base(id),
+		Path:        id,
+		Size:        size,
+		ContentType: resp.Header.Get("Content-Type"),
+		URL:         p.publicURL + "/" + escapePath(id),
+		Metadata:    objectMetadata(resp.Header),
+		UploadedAt:  modified,
+		UpdatedAt:   modified,
+	}, nil
+}
+
+// GetSignedURL presigns a GET for the object against the bucket itself, since a
+// CDN at PublicURL would not pass the signature through.
+func (p *S3StorageProvider) GetSignedURL(ctx context.Context, id string, ttl time.Duration) (string, error) {
+	if ttl < time.Second || ttl > maxPresignTTL {
+		return "", fmt.Errorf("signed URL ttl must be between 1s and %s, got %s", maxPresignTTL, ttl)
+	}
+	id, err := fileID(id, "")
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.bucketURL+"/"+escapePath(id), nil)
+	if err != nil {
+		return "", p.handleError(ctx, err, "presign_failed")
+	}
+	return p.signer.presign(req, ttl, time.Now()), nil
+}
+
+func (p *S3StorageProvider) OpenFile(ctx context.Context, id string) (io.ReadCloser, error) {
+	req, err := p.request(ctx, http.MethodGet, id, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.streamClient.Do(req)
+	if err != nil {
+		return nil, p.handleError(ctx, err, "open_failed")
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, errors.ErrFileNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, p.handleError(ctx, fmt.Errorf("S3 API error: %d", resp.StatusCode), "open_failed")
+	}
+	return resp.Body, nil
+}
+
+// MoveFile copies the object server-side and deletes the original. S3 copies
+// objects of up to 5 GB in one request.
+func (p *S3StorageProvider) MoveFile(ctx context.Context, id, newID string) error {
+	id, err := fileID(id, "")
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set("X-Amz-Copy-Source", "/"+p.bucket+"/"+escapePath(id))
+	resp, err := p.do(ctx, http.MethodPut, newID, nil, header, nil)
+	if err != nil {
+		return p.handleError(ctx, err, "copy_failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errors.ErrFileNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return p.handleError(ctx, fmt.Errorf("S3 API error: %d", resp.StatusCode), "copy_failed")
+	}
+	// A failure after the request was accepted is reported in the body
+	result, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return p.handleError(ctx, err, "copy_failed")
+	}
+	var s3Err s3Error
+	if xml.Unmarshal(result, &s3Err) == nil {
+		return p.handleError(ctx, fmt.Errorf("S3 API error: %s: %s", s3Err.Code, s3Err.Message), "copy_failed")
+	}
+
+	return p.DeleteFile(ctx, id)
+}
+
+// do sends a signed request for the object.
+func (p *S3StorageProvider) do(ctx context.Context, method, id string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	httpReq, err := p.request(ctx, method, id, query, header, body)
+	if err != nil {
+		return nil, err
+	}
+	return p.httpClient.Do(httpReq)
+}
+
+// request builds a signed request for the object.
+func (p *S3StorageProvider) request(ctx context.Context, method, id string, query url.Values, header http.Header, body []byte) (*http.Request, error) {
+	id, err := fileID(id, "")
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, p.bucketURL+"/"+escapePath(id), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.URL.RawQuery = query.Encode()
+	for key, values := range header {
+		httpReq.Header[key] = values
+	}
+
+	sum := sha256.Sum256(body)
+	p.signer.sign(httpReq, hex.EncodeToString(sum[:]), time.Now())
+
+	return httpReq, nil
+}
+
+func (p *S3StorageProvider) handleError(ctx context.Context, err error, operation string) error {
+	p.logger.ErrorLogger(ctx, err, "File storage operation failed", map[string]interface{}{
+		"provider":  "s3",
+		"operation": operation,
+	})
+	return fmt.Errorf("file storage %s: %w", operation, err)
+}
+
+// objectHeader returns the headers that set an object's content type, cache
+// control and user metadata.
+func objectHeader(contentType, cacheControl string, metadata map[string]string) http.Header {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	if cacheControl != "" {
+		header.Set("Cache-Control", cacheControl)
+	}
+	for key, value := range metadata {
+		header.Set(metadataHeaderPrefix+key, value)
+	}
+	return header
+}
+
+// objectMetadata extracts the user metadata of an object from response headers.
+func objectMetadata(header http.Header) map[string]string {
+	metadata := make(map[string]string)
+	for key := range header {
+		if strings.HasPrefix(key, metadataHeaderPrefix) {
+			metadata[strings.ToLower(strings.TrimPrefix(key, metadataHeaderPrefix))] = header.Get(key)
+		}
+	}
+	return metadata
+}
@@ -0,0 +1,134 @@
+// Package switchable holds providers that can be replaced while the
+// application runs, such as when an administrator applies a configuration
+// profile. Calls in flight finish on the provider they started with.
+package switchable
+
+import (
+	"context"
+	"sync/atomic"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+)
+
+// PaymentProvider passes calls to the current payment provider
+type PaymentProvider struct {
+	current atomic.Pointer[provider.PaymentProvider]
+}
+
+func NewPaymentProvider(p provider.PaymentProvider) *PaymentProvider {
+	s := &PaymentProvider{}
+	s.Set(p)
+	return s
+}
+
+// Set replaces the payment provider
+func (s *PaymentProvider) Set(p provider.PaymentProvider) {
+	s.current.Store(&p)
+}
+
+func (s *PaymentProvider) get() provider.PaymentProvider {
+	return *s.current.Load()
+}
+
+// Unwrap returns the current payment provider
+func (s *PaymentProvider) Unwrap() provider.PaymentProvider {
+	return s.get()
+}
+
+func (s *PaymentProvider) ProcessPayment(ctx context.Context, req *entity.PaymentRequest) (*entity.PaymentResponse, error) {
+	return s.get().ProcessPayment(ctx, req)
+}
+
+func (s *PaymentProvider) RefundPayment(ctx context.Context, paymentID string) (*entity.RefundResponse, error) {
+	return s.get().RefundPayment(ctx, paymentID)
+}
+
+func (s *PaymentProvider) GetPaymentStatus(ctx context.Context, paymentID string) (*entity.PaymentStatus, error) {
+	return s.get().GetPaymentStatus(ctx, paymentID)
+}
+
+func (s *PaymentProvider) CreatePaymentIntent(ctx context.Context, req *entity.PaymentIntentRequest) (*entity.PaymentIntent, error) {
+	return s.get().CreatePaymentIntent(ctx, req)
+}
+
+func (s *PaymentProvider) CancelPaymentIntent(ctx context.Context, intentID string) (*entity.PaymentIntent, error) {
+	return s.get().CancelPaymentIntent(ctx, intentID)
+}
+
+// NotificationProvider passes calls to the current notification provider
+type NotificationProvider struct {
+	current atomic.Pointer[provider.NotificationProvider]
+}
+
+func NewNotificationProvider(p provider.NotificationProvider) *NotificationProvider {
+	s := &NotificationProvider{}
+	s.Set(p)
+	return s
+}
+
+// Set replaces the notification provider
+func (s *NotificationProvider) Set(p provider.NotificationProvider) {
+	s.current.Store(&p)
+}
+
+func (s *NotificationProvider) get() provider.NotificationProvider {
+	return *s.current.Load()
+}
+
+func (s *NotificationProvider) SendEmail(ctx context.Context, req *entity.EmailRequest) (*entity.EmailResponse, error) {
+	return s.get().SendEmail(ctx, req)
+}
+
+func (s *NotificationProvider) SendSMS(ctx context.Context, req *entity.SMSRequest) (*entity.SMSResponse, error) {
+	return s.get().SendSMS(ctx, req)
+}
+
+func (s *NotificationProvider) SendPushNotification(ctx context.Context, req *entity.PushNotificationRequest) (*entity.PushNotificationResponse, error) {
+	return s.get().SendPushNotification(ctx, req)
+}
+
+func (s *NotificationProvider) SendTemplatedSMS(ctx context.Context, msg *entity.TemplatedMessage) (*entity.SMSResponse, error) {
+	return s.get().SendTemplatedSMS(ctx, msg)
+}
+
+func (s *NotificationProvider) SendTemplatedPush(ctx context.Context, msg *entity.TemplatedMessage) (*entity.PushNotificationResponse, error) {
+	return s.get().SendTemplatedPush(ctx, msg)
+}
+
+// EmailProvider passes calls to the current bulk email provider
+type EmailProvider struct {
+	current atomic.Pointer[provider.EmailProvider]
+}
+
+func NewEmailProvider(p provider.EmailProvider) *EmailProvider {
+	s := &EmailProvider{}
+	s.Set(p)
+	return s
+}
+
+// Set replaces the email provider
+func (s *EmailProvider) Set(p provider.EmailProvider) {
+	s.current.Store(&p)
+}
+
+func (s *EmailProvider) get() provider.EmailProvider {
+	return *s.current.Load()
+}
+
+// Unwrap returns the current email provider
+func (s *EmailProvider) Unwrap() provider.EmailProvider {
+	return s.get()
+}
+
+func (s *EmailProvider) SendEmail(ctx context.Context, req *entity.EmailRequest) (*entity.EmailResponse, error) {
+	return s.get().SendEmail(ctx, req)
+}
+
+func (s *EmailProvider) SendBulkEmail(ctx context.Context, req *entity.BulkEmailRequest) (*entity.BulkEmailResponse, error) {
+	return s.get().SendBulkEmail(ctx, req)
+}
+
+func (s *EmailProvider) GetEmailStatus(ctx context.Context, emailID string) (*entity.EmailStatus, error) {
+	return s.get().GetEmailStatus(ctx, emailID)
+}
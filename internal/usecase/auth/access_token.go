@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/jwt"
+	"boilerplate-go/pkg/timeout"
+
+	"github.com/google/uuid"
+)
+
+// IssueAccessToken issues the user a token limited to the requested scopes,
+// which must be among the scopes granted to the caller's own token, so a
+// token cannot be used to widen its access. The token is a session of its
+// own: it is listed and revoked with the user's other sessions.
+func (uc *AuthUsecase) IssueAccessToken(ctx context.Context, userID int, granted []string, req *entity.AccessTokenRequest, ipAddress string) (*entity.AccessTokenResponse, error) {
+	for _, scope := range req.Scopes {
+		if !entity.KnownScope(scope) {
+			return nil, fmt.Errorf("%w: unknown scope %q", errors.ErrInvalidTokenRequest, scope)
+		}
+		if !hasScope(granted, scope) {
+			return nil, fmt.Errorf("%w: %s", errors.ErrScopeNotGranted, scope)
+		}
+	}
+
+	lifetime := uc.jwtConfig.ExpiryTime
+	if req.ExpiresIn > 0 {
+		lifetime = time.Duration(req.ExpiresIn) * time.Second
+	}
+	if maxAge := uc.jwtConfig.AccessTokenMaxAge; maxAge > 0 && lifetime > maxAge {
+		return nil, fmt.Errorf("%w: expires_in is longer than %s", errors.ErrInvalidTokenRequest, maxAge)
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := uc.userRepo.GetByID(readCtx, userID)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	tokenID := uuid.New().String()
+	claims := &jwt.Claims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Scopes:   req.Scopes,
+		Locale:   user.Locale,
+	}
+	claims.ID = tokenID
+
+	token, err := uc.jwtKeys.Sign(claims, lifetime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(lifetime)
+	session := &entity.Session{
+		UserID:    user.ID,
+		TokenID:   tokenID,
+		Device:    "Access token: " + req.Name,
+		IPAddress: ipAddress,
+		ExpiresAt: expiresAt,
+	}
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.sessionRepo.Create(writeCtx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return &entity.AccessTokenResponse{
+		Token:     token,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -2,31 +2,134 @@ package auth
 
 import (
 	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
 	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
 	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/internal/usecase/otp"
+	"boilerplate-go/pkg/emailaddr"
 	"boilerplate-go/pkg/errors"
 	"boilerplate-go/pkg/hash"
 	"boilerplate-go/pkg/jwt"
+	"boilerplate-go/pkg/password"
+	"boilerplate-go/pkg/timeout"
+	"boilerplate-go/pkg/username"
 	"context"
 	"fmt"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+// ClaimsHook customizes the claims of a token before it is signed, e.g. to add
+// scopes, a tenant ID or service specific custom claims.
+type ClaimsHook func(ctx context.Context, user *entity.User, claims *jwt.Claims) error
+
+// OTPService issues and verifies one-time codes sent by SMS.
+type OTPService interface {
+	Send(ctx context.Context, purpose, phone, locale string) error
+	Verify(ctx context.Context, purpose, phone, code string) error
+}
+
 // AuthUsecase handles authentication business logic.
 type AuthUsecase struct {
-	userRepo  repository.UserRepository
-	jwtConfig config.JWTConfig
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+	geoProvider provider.GeolocationProvider
+	jwtKeys     *jwt.KeySet
+	jwtConfig   config.JWTConfig
+	claimsHook  ClaimsHook
+	otp         OTPService
+	passwords   *password.Policy
+	usernames   *username.Policy
+	stripPlus   bool
+	timeouts    *timeout.Policy
+	loginAlerts config.LoginAlertConfig
+	jobs        JobQueue
+	invitations InvitationService
+	sso         SSOService
+	logger      *logger.Logger
 }
 
 // NewAuthUsecase creates a new authentication use case.
-func NewAuthUsecase(userRepo repository.UserRepository, jwtConfig config.JWTConfig) *AuthUsecase {
+// The geolocation provider is optional and may be nil.
+func NewAuthUsecase(
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	geoProvider provider.GeolocationProvider,
+	jwtKeys *jwt.KeySet,
+	jwtConfig config.JWTConfig,
+) *AuthUsecase {
 	return &AuthUsecase{
-		userRepo:  userRepo,
-		jwtConfig: jwtConfig,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		geoProvider: geoProvider,
+		jwtKeys:     jwtKeys,
+		jwtConfig:   jwtConfig,
+	}
+}
+
+// RoleScopes returns a claims hook granting the scopes mapped to the user's role.
+func RoleScopes(scopesByRole map[string][]string) ClaimsHook {
+	return func(_ context.Context, user *entity.User, claims *jwt.Claims) error {
+		claims.Scopes = append(claims.Scopes, scopesByRole[user.Role]...)
+		return nil
 	}
 }
 
+// SetClaimsHook registers a hook invoked for every issued token.
+func (uc *AuthUsecase) SetClaimsHook(hook ClaimsHook) {
+	uc.claimsHook = hook
+}
+
+// SetOTP enables passwordless login with one-time codes sent by SMS.
+func (uc *AuthUsecase) SetOTP(otp OTPService) {
+	uc.otp = otp
+}
+
+// SetPasswordPolicy rejects registrations whose password does not meet the
+// policy. Without it any password is accepted.
+func (uc *AuthUsecase) SetPasswordPolicy(policy *password.Policy) {
+	uc.passwords = policy
+}
+
+// SetUsernamePolicy rejects registrations whose username does not meet the
+// policy. Without it any username is accepted.
+func (uc *AuthUsecase) SetUsernamePolicy(policy *username.Policy) {
+	uc.usernames = policy
+}
+
+// SetEmailAddresses sets how registered email addresses are normalized: with
+// cfg.StripPlus they lose their plus subaddress.
+func (uc *AuthUsecase) SetEmailAddresses(cfg config.EmailAddressConfig) {
+	uc.stripPlus = cfg.StripPlus
+}
+
+// SetTimeouts bounds repository and provider calls with per-operation deadlines.
+func (uc *AuthUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	uc.timeouts = timeouts
+}
+
+// Register creates a user. The username and email are normalized, and the
+// username is checked like the password against the policy when one is set.
 func (uc *AuthUsecase) Register(ctx context.Context, req *entity.RegisterRequest) (*entity.User, error) {
-	existingUser, err := uc.userRepo.GetByUsername(ctx, req.Username)
+	req.Username = username.Normalize(req.Username)
+	req.Email = emailaddr.Normalize(req.Email, uc.stripPlus)
+	if uc.usernames != nil {
+		if err := uc.usernames.Check(req.Username); err != nil {
+			return nil, err
+		}
+	}
+	if uc.passwords != nil {
+		if err := uc.passwords.Check(req.Password, req.Username, req.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	existingUser, err := uc.userRepo.GetByUsername(readCtx, req.Username)
 	if err != nil && !errors.IsUserNotFound(err) {
 		return nil, fmt.Errorf("failed to check username: %w", err)
 	}
@@ -34,13 +137,14 @@ func (uc *AuthUsecase) Register(ctx context.Context, req *entity.RegisterRequest
 		return nil, errors.ErrUserAlreadyExists
 	}
 
-	existingUser, err = uc.userRepo.GetByEmail(ctx, req.Email)
+	existingUser, err = uc.userRepo.GetByEmail(readCtx, req.Email)
 	if err != nil && !errors.IsUserNotFound(err) {
 		return nil, fmt.Errorf("failed to check email: %w", err)
 	}
 	if existingUser != nil {
 		return nil, errors.ErrUserAlreadyExists
 	}
+	cancel()
 
 	hashedPassword, err := hash.HashPassword(req.Password)
 	if err != nil {
@@ -51,9 +155,12 @@ func (uc *AuthUsecase) Register(ctx context.Context, req *entity.RegisterRequest
 		Username: req.Username,
 		Email:    req.Email,
 		Password: hashedPassword,
+		Role:     entity.RoleUser,
 	}
 
-	err = uc.userRepo.Create(ctx, user)
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	err = uc.userRepo.Create(writeCtx, user)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
@@ -62,7 +169,9 @@ func (uc *AuthUsecase) Register(ctx context.Context, req *entity.RegisterRequest
 }
 
 func (uc *AuthUsecase) Login(ctx context.Context, req *entity.LoginRequest) (*entity.LoginResponse, error) {
-	user, err := uc.userRepo.GetByUsername(ctx, req.Username)
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := uc.userRepo.GetByUsername(readCtx, username.Normalize(req.Username))
+	cancel()
 	if err != nil {
 		if errors.IsUserNotFound(err) {
 			return nil, errors.ErrInvalidCredentials
@@ -74,13 +183,138 @@ func (uc *AuthUsecase) Login(ctx context.Context, req *entity.LoginRequest) (*en
 		return nil, errors.ErrInvalidCredentials
 	}
 
-	token, err := jwt.GenerateToken(user.ID, user.Username, uc.jwtConfig.SecretKey, uc.jwtConfig.ExpiryTime)
+	return uc.signIn(ctx, user, req.IPAddress, req.UserAgent, req.VerificationCode, true)
+}
+
+// RequestOTPLogin texts a login code to the verified phone number. Unknown
+// numbers are accepted silently so the endpoint cannot be used to find accounts.
+func (uc *AuthUsecase) RequestOTPLogin(ctx context.Context, req *entity.OTPRequest) error {
+	if uc.otp == nil {
+		return errors.ErrProviderUnavailable
+	}
+
+	phone, err := otp.NormalizePhone(req.Phone)
+	if err != nil {
+		return err
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := uc.userRepo.GetByPhone(readCtx, phone)
+	cancel()
+	if err != nil {
+		if errors.IsUserNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return uc.otp.Send(ctx, entity.OTPPurposeLogin, phone, user.Locale)
+}
+
+// VerifyOTPLogin signs the user in with a code sent by RequestOTPLogin.
+func (uc *AuthUsecase) VerifyOTPLogin(ctx context.Context, req *entity.OTPVerifyRequest) (*entity.LoginResponse, error) {
+	if uc.otp == nil {
+		return nil, errors.ErrProviderUnavailable
+	}
+
+	phone, err := otp.NormalizePhone(req.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.otp.Verify(ctx, entity.OTPPurposeLogin, phone, req.Code); err != nil {
+		return nil, err
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := uc.userRepo.GetByPhone(readCtx, phone)
+	cancel()
+	if err != nil {
+		if errors.IsUserNotFound(err) {
+			return nil, errors.ErrInvalidOTP
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// The code already proved possession of the phone
+	return uc.signIn(ctx, user, req.IPAddress, req.UserAgent, "", false)
+}
+
+// issueToken signs an access token for the user and records the session.
+func (uc *AuthUsecase) issueToken(ctx context.Context, user *entity.User, ipAddress, userAgent, location string) (*entity.LoginResponse, error) {
+	tokenID := uuid.New().String()
+	claims := &jwt.Claims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Locale:   user.Locale,
+	}
+	claims.ID = tokenID
+
+	if uc.claimsHook != nil {
+		if err := uc.claimsHook(ctx, user, claims); err != nil {
+			return nil, fmt.Errorf("failed to build token claims: %w", err)
+		}
+	}
+
+	token, err := uc.jwtKeys.Sign(claims, uc.jwtConfig.ExpiryTime)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
+	session := &entity.Session{
+		UserID:    user.ID,
+		TokenID:   tokenID,
+		Device:    userAgent,
+		IPAddress: ipAddress,
+		Location:  location,
+		ExpiresAt: time.Now().Add(uc.jwtConfig.ExpiryTime),
+	}
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.sessionRepo.Create(writeCtx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
 	return &entity.LoginResponse{
 		Token: token,
 		User:  user,
 	}, nil
 }
+
+// JWKS returns the public keys used to sign access tokens.
+func (uc *AuthUsecase) JWKS() jwt.JWKS {
+	return uc.jwtKeys.JWKS()
+}
+
+// resolveLocation looks up a human readable location for the IP address,
+// reusing the location of the request client when it was already resolved.
+// Lookup failures are not fatal for login and yield an empty location.
+func (uc *AuthUsecase) resolveLocation(ctx context.Context, ipAddress string) string {
+	location := uc.lookupLocation(ctx, ipAddress)
+	if location == nil {
+		return ""
+	}
+
+	if location.City != "" {
+		return fmt.Sprintf("%s, %s", location.City, location.Country)
+	}
+	return location.Country
+}
+
+func (uc *AuthUsecase) lookupLocation(ctx context.Context, ipAddress string) *entity.LocationInfo {
+	if client := entity.ClientInfoFromContext(ctx); client != nil && client.IPAddress == ipAddress && client.Location != nil {
+		return client.Location
+	}
+	if uc.geoProvider == nil || ipAddress == "" {
+		return nil
+	}
+
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationGeolocation)
+	defer cancel()
+
+	location, err := uc.geoProvider.GetLocationByIP(ctx, ipAddress)
+	if err != nil {
+		return nil
+	}
+	return location
+}
@@ -2,13 +2,20 @@ package auth
 
 import (
 	"boilerplate-go/config"
+	"boilerplate-go/internal/delivery/http/middleware"
 	"boilerplate-go/internal/domain/entity"
 	"boilerplate-go/pkg/errors"
 	"boilerplate-go/pkg/hash"
+	"boilerplate-go/pkg/jwt"
+	"boilerplate-go/pkg/password"
+	"boilerplate-go/pkg/username"
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -23,6 +30,11 @@ func (m *MockUserRepository) Create(ctx context.Context, user *entity.User) erro
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) CreateBatch(ctx context.Context, users []*entity.User) error {
+	args := m.Called(ctx, users)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) GetByID(ctx context.Context, id int) (*entity.User, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(*entity.User), args.Error(1)
@@ -44,16 +56,98 @@ func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*ent
 	return args.Get(0).(*entity.User), args.Error(1)
 }
 
+func (m *MockUserRepository) List(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*entity.User), args.Int(1), args.Error(2)
+}
+
+func (m *MockUserRepository) IterateActiveUsers(ctx context.Context, fn func(*entity.User) error) error {
+	args := m.Called(ctx, fn)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) Update(ctx context.Context, user *entity.User) error {
 	args := m.Called(ctx, user)
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) Upsert(ctx context.Context, user *entity.User) (bool, error) {
+	args := m.Called(ctx, user)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockUserRepository) Delete(ctx context.Context, id int) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) Anonymize(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetByPhone(ctx context.Context, phone string) (*entity.User, error) {
+	args := m.Called(ctx, phone)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdatePhone(ctx context.Context, id int, phone string, verifiedAt *time.Time) error {
+	args := m.Called(ctx, id, phone, verifiedAt)
+	return args.Error(0)
+}
+
+// MockSessionRepository is a mock implementation of SessionRepository
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Create(ctx context.Context, session *entity.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) GetByTokenID(ctx context.Context, tokenID string) (*entity.Session, error) {
+	args := m.Called(ctx, tokenID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) ListActiveByUserID(ctx context.Context, userID int) ([]*entity.Session, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) ListByUserIDSince(ctx context.Context, userID int, since time.Time) ([]*entity.Session, error) {
+	args := m.Called(ctx, userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Revoke(ctx context.Context, userID, sessionID int) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) RevokeAllByUserID(ctx context.Context, userID int) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) Touch(ctx context.Context, tokenID string, seenAt time.Time) error {
+	args := m.Called(ctx, tokenID, seenAt)
+	return args.Error(0)
+}
+
 func TestAuthUsecase_Register(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -123,7 +217,7 @@ func TestAuthUsecase_Register(t *testing.T) {
 				ExpiryTime: 24 * time.Hour,
 			}
 
-			authUsecase := NewAuthUsecase(mockRepo, jwtConfig)
+			authUsecase := NewAuthUsecase(mockRepo, new(MockSessionRepository), nil, jwt.NewHMACKeySet(jwtConfig.SecretKey), jwtConfig)
 			ctx := context.Background()
 
 			// Execute
@@ -152,7 +246,7 @@ func TestAuthUsecase_Login(t *testing.T) {
 	tests := []struct {
 		name          string
 		request       *entity.LoginRequest
-		setupMock     func(*MockUserRepository)
+		setupMock     func(*MockUserRepository, *MockSessionRepository)
 		expectedError string
 	}{
 		{
@@ -161,7 +255,7 @@ func TestAuthUsecase_Login(t *testing.T) {
 				Username: "testuser",
 				Password: "password123",
 			},
-			setupMock: func(repo *MockUserRepository) {
+			setupMock: func(repo *MockUserRepository, sessionRepo *MockSessionRepository) {
 				// Use a properly generated bcrypt hash
 				hashedPassword, _ := hash.HashPassword("password123")
 				user := &entity.User{
@@ -171,6 +265,7 @@ func TestAuthUsecase_Login(t *testing.T) {
 					Password: hashedPassword,
 				}
 				repo.On("GetByUsername", mock.Anything, "testuser").Return(user, nil)
+				sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Session")).Return(nil)
 			},
 			expectedError: "",
 		},
@@ -180,25 +275,40 @@ func TestAuthUsecase_Login(t *testing.T) {
 				Username: "nonexistent",
 				Password: "password123",
 			},
-			setupMock: func(repo *MockUserRepository) {
+			setupMock: func(repo *MockUserRepository, _ *MockSessionRepository) {
 				repo.On("GetByUsername", mock.Anything, "nonexistent").Return(nil, errors.ErrUserNotFound)
 			},
 			expectedError: "invalid credentials",
 		},
+		{
+			name: "deactivated user",
+			request: &entity.LoginRequest{
+				Username: "leaver",
+				Password: "password123",
+			},
+			setupMock: func(repo *MockUserRepository, _ *MockSessionRepository) {
+				hashedPassword, _ := hash.HashPassword("password123")
+				deactivatedAt := time.Now()
+				user := &entity.User{ID: 2, Username: "leaver", Password: hashedPassword, DeactivatedAt: &deactivatedAt}
+				repo.On("GetByUsername", mock.Anything, "leaver").Return(user, nil)
+			},
+			expectedError: "user is deactivated",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockRepo := new(MockUserRepository)
-			tt.setupMock(mockRepo)
+			mockSessionRepo := new(MockSessionRepository)
+			tt.setupMock(mockRepo, mockSessionRepo)
 
 			jwtConfig := config.JWTConfig{
 				SecretKey:  "test-secret",
 				ExpiryTime: 24 * time.Hour,
 			}
 
-			authUsecase := NewAuthUsecase(mockRepo, jwtConfig)
+			authUsecase := NewAuthUsecase(mockRepo, mockSessionRepo, nil, jwt.NewHMACKeySet(jwtConfig.SecretKey), jwtConfig)
 			ctx := context.Background()
 
 			// Execute
@@ -218,6 +328,423 @@ func TestAuthUsecase_Login(t *testing.T) {
 			}
 
 			mockRepo.AssertExpectations(t)
+			mockSessionRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthUsecase_AdminTokenReachesAdminRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		role           string
+		expectedStatus int
+	}{
+		{name: "admin role", role: entity.RoleAdmin, expectedStatus: http.StatusOK},
+		{name: "user role", role: entity.RoleUser, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			hashedPassword, _ := hash.HashPassword("password123")
+			mockRepo := new(MockUserRepository)
+			mockRepo.On("GetByUsername", mock.Anything, "testuser").Return(&entity.User{
+				ID:       1,
+				Username: "testuser",
+				Password: hashedPassword,
+				Role:     tt.role,
+			}, nil)
+			mockSessionRepo := new(MockSessionRepository)
+			mockSessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Session")).Return(nil)
+
+			jwtConfig := config.JWTConfig{
+				SecretKey:  "test-secret",
+				ExpiryTime: 24 * time.Hour,
+			}
+			keys := jwt.NewHMACKeySet(jwtConfig.SecretKey)
+
+			authUsecase := NewAuthUsecase(mockRepo, mockSessionRepo, nil, keys, jwtConfig)
+			authUsecase.SetClaimsHook(RoleScopes(map[string][]string{entity.RoleAdmin: {"admin"}}))
+
+			r := gin.New()
+			r.GET("/api/v1/admin/coupons", middleware.AuthenticationMiddleware(keys, nil), middleware.RequireScope("admin"),
+				func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			// Execute
+			loginResponse, err := authUsecase.Login(context.Background(), &entity.LoginRequest{
+				Username: "testuser",
+				Password: "password123",
+			})
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/coupons", nil)
+			req.Header.Set("Authorization", "Bearer "+loginResponse.Token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// MockOTPService is a mock implementation of OTPService
+type MockOTPService struct {
+	mock.Mock
+}
+
+func (m *MockOTPService) Send(ctx context.Context, purpose, phone, locale string) error {
+	args := m.Called(ctx, purpose, phone, locale)
+	return args.Error(0)
+}
+
+func (m *MockOTPService) Verify(ctx context.Context, purpose, phone, code string) error {
+	args := m.Called(ctx, purpose, phone, code)
+	return args.Error(0)
+}
+
+func TestAuthUsecase_OTPLogin(t *testing.T) {
+	jwtConfig := config.JWTConfig{SecretKey: "test-secret", ExpiryTime: time.Hour}
+	user := &entity.User{ID: 1, Username: "testuser", Phone: "+6281234567890"}
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByPhone", mock.Anything, "+6281234567890").Return(user, nil)
+	userRepo.On("GetByPhone", mock.Anything, "+6289999999999").Return(nil, errors.ErrUserNotFound)
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Session")).Return(nil)
+	otpService := new(MockOTPService)
+	otpService.On("Send", mock.Anything, entity.OTPPurposeLogin, "+6281234567890", mock.Anything).Return(nil)
+	otpService.On("Verify", mock.Anything, entity.OTPPurposeLogin, "+6281234567890", "123456").Return(nil)
+	otpService.On("Verify", mock.Anything, entity.OTPPurposeLogin, "+6281234567890", "654321").Return(errors.ErrInvalidOTP)
+
+	authUsecase := NewAuthUsecase(userRepo, sessionRepo, nil, jwt.NewHMACKeySet(jwtConfig.SecretKey), jwtConfig)
+	authUsecase.SetOTP(otpService)
+	ctx := context.Background()
+
+	assert.NoError(t, authUsecase.RequestOTPLogin(ctx, &entity.OTPRequest{Phone: "+62 812 3456 7890"}))
+	// Unknown numbers are accepted without sending a code
+	assert.NoError(t, authUsecase.RequestOTPLogin(ctx, &entity.OTPRequest{Phone: "+6289999999999"}))
+	assert.True(t, errors.IsInvalidPhone(authUsecase.RequestOTPLogin(ctx, &entity.OTPRequest{Phone: "0812"})))
+	otpService.AssertNumberOfCalls(t, "Send", 1)
+
+	resp, err := authUsecase.VerifyOTPLogin(ctx, &entity.OTPVerifyRequest{Phone: "+6281234567890", Code: "123456"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+	assert.Equal(t, user, resp.User)
+
+	_, err = authUsecase.VerifyOTPLogin(ctx, &entity.OTPVerifyRequest{Phone: "+6281234567890", Code: "654321"})
+	assert.True(t, errors.IsInvalidOTP(err))
+	sessionRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestAuthUsecase_Register_PasswordPolicy(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	jwtConfig := config.JWTConfig{SecretKey: "test-secret", ExpiryTime: 24 * time.Hour}
+	authUsecase := NewAuthUsecase(mockRepo, new(MockSessionRepository), nil, jwt.NewHMACKeySet(jwtConfig.SecretKey), jwtConfig)
+	authUsecase.SetPasswordPolicy(password.DefaultPolicy())
+
+	user, err := authUsecase.Register(context.Background(), &entity.RegisterRequest{
+		Username: "testuser",
+		Email:    "test@example.com",
+		Password: "password123",
+	})
+
+	assert.Nil(t, user)
+	assert.True(t, errors.IsWeakPassword(err))
+	// The password is checked before the user repository is queried
+	mockRepo.AssertNotCalled(t, "GetByUsername", mock.Anything, mock.Anything)
+}
+
+func TestAuthUsecase_Register_UsernamePolicy(t *testing.T) {
+	jwtConfig := config.JWTConfig{SecretKey: "test-secret", ExpiryTime: 24 * time.Hour}
+
+	t.Run("reserved name", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		authUsecase := NewAuthUsecase(mockRepo, new(MockSessionRepository), nil, jwt.NewHMACKeySet(jwtConfig.SecretKey), jwtConfig)
+		authUsecase.SetUsernamePolicy(username.DefaultPolicy())
+
+		user, err := authUsecase.Register(context.Background(), &entity.RegisterRequest{
+			Username: "Admin",
+			Email:    "test@example.com",
+			Password: "password123",
+		})
+
+		assert.Nil(t, user)
+		assert.True(t, errors.IsInvalidUsername(err))
+		mockRepo.AssertNotCalled(t, "GetByUsername", mock.Anything, mock.Anything)
+	})
+
+	t.Run("normalized before the lookup", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockRepo.On("GetByUsername", mock.Anything, "Jane").Return(nil, errors.ErrUserNotFound)
+		mockRepo.On("GetByEmail", mock.Anything, "jane@example.com").Return(nil, errors.ErrUserNotFound)
+		mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(user *entity.User) bool { return user.Username == "Jane" })).Return(nil)
+		authUsecase := NewAuthUsecase(mockRepo, new(MockSessionRepository), nil, jwt.NewHMACKeySet(jwtConfig.SecretKey), jwtConfig)
+		authUsecase.SetUsernamePolicy(username.DefaultPolicy())
+
+		user, err := authUsecase.Register(context.Background(), &entity.RegisterRequest{
+			Username: " Ｊａｎｅ ",
+			Email:    "jane@example.com",
+			Password: "password123",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Jane", user.Username)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestAuthUsecase_Register_EmailNormalized(t *testing.T) {
+	jwtConfig := config.JWTConfig{SecretKey: "test-secret", ExpiryTime: 24 * time.Hour}
+
+	tests := []struct {
+		name      string
+		stripPlus bool
+		expected  string
+	}{
+		{name: "lowercased", expected: "jane+news@example.com"},
+		{name: "plus address stripped", stripPlus: true, expected: "jane@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockRepo.On("GetByUsername", mock.Anything, "jane").Return(nil, errors.ErrUserNotFound)
+			mockRepo.On("GetByEmail", mock.Anything, tt.expected).Return(nil, errors.ErrUserNotFound)
+			mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(user *entity.User) bool { return user.Email == tt.expected })).Return(nil)
+			authUsecase := NewAuthUsecase(mockRepo, new(MockSessionRepository), nil, jwt.NewHMACKeySet(jwtConfig.SecretKey), jwtConfig)
+			authUsecase.SetEmailAddresses(config.EmailAddressConfig{StripPlus: tt.stripPlus})
+
+			user, err := authUsecase.Register(context.Background(), &entity.RegisterRequest{
+				Username: "jane",
+				Email:    " Jane+News@Example.COM ",
+				Password: "password123",
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, user.Email)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthUsecase_IssueAccessToken(t *testing.T) {
+	granted := []string{entity.ScopeOrdersRead, entity.ScopeOrdersWrite, entity.ScopeSessions}
+
+	tests := []struct {
+		name      string
+		req       entity.AccessTokenRequest
+		checkErr  func(error) bool
+		expectErr bool
+	}{
+		{name: "subset of the granted scopes", req: entity.AccessTokenRequest{Name: "ci", Scopes: []string{entity.ScopeOrdersRead}, ExpiresIn: 3600}},
+		{name: "scope not granted", req: entity.AccessTokenRequest{Name: "ci", Scopes: []string{entity.ScopeAdmin}}, checkErr: errors.IsScopeNotGranted, expectErr: true},
+		{name: "unknown scope", req: entity.AccessTokenRequest{Name: "ci", Scopes: []string{"orders:*"}}, checkErr: errors.IsInvalidTokenRequest, expectErr: true},
+		{name: "lifetime over the maximum", req: entity.AccessTokenRequest{Name: "ci", Scopes: []string{entity.ScopeOrdersRead}, ExpiresIn: 7200}, checkErr: errors.IsInvalidTokenRequest, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			mockRepo := new(MockUserRepository)
+			mockRepo.On("GetByID", mock.Anything, 1).Return(&entity.User{ID: 1, Username: "testuser"}, nil)
+			mockSessionRepo := new(MockSessionRepository)
+			mockSessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Session")).Return(nil)
+
+			jwtConfig := config.JWTConfig{SecretKey: "test-secret", ExpiryTime: time.Hour, AccessTokenMaxAge: time.Hour}
+			keys := jwt.NewHMACKeySet(jwtConfig.SecretKey)
+			authUsecase := NewAuthUsecase(mockRepo, mockSessionRepo, nil, keys, jwtConfig)
+
+			// Execute
+			token, err := authUsecase.IssueAccessToken(context.Background(), 1, granted, &tt.req, "127.0.0.1")
+
+			// Assert
+			if tt.expectErr {
+				assert.True(t, tt.checkErr(err), "got %v", err)
+				mockSessionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			claims, err := keys.ValidateToken(token.Token)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.req.Scopes, claims.Scopes)
+			mockSessionRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(s *entity.Session) bool {
+				return s.TokenID == claims.ID && s.Device == "Access token: ci"
+			}))
+		})
+	}
+}
+
+func TestAuthUsecase_Impersonate(t *testing.T) {
+	deactivatedAt := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name     string
+		userID   int
+		user     *entity.User
+		getErr   error
+		checkErr func(error) bool
+	}{
+		{
+			name:   "user is impersonated",
+			userID: 7,
+			user:   &entity.User{ID: 7, Username: "alice", Role: entity.RoleUser},
+		},
+		{
+			name:     "staff cannot be impersonated",
+			userID:   7,
+			user:     &entity.User{ID: 7, Username: "bob", Role: entity.RoleAdmin},
+			checkErr: errors.IsCannotImpersonate,
+		},
+		{
+			name:     "deactivated user cannot be impersonated",
+			userID:   7,
+			user:     &entity.User{ID: 7, Username: "carol", Role: entity.RoleUser, DeactivatedAt: &deactivatedAt},
+			checkErr: errors.IsCannotImpersonate,
+		},
+		{
+			name:     "impersonating yourself",
+			userID:   2,
+			checkErr: errors.IsCannotImpersonate,
+		},
+		{
+			name:     "unknown user",
+			userID:   7,
+			getErr:   errors.ErrUserNotFound,
+			checkErr: errors.IsUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			mockRepo := new(MockUserRepository)
+			mockRepo.On("GetByID", mock.Anything, tt.userID).Return(tt.user, tt.getErr)
+			mockSessionRepo := new(MockSessionRepository)
+			mockSessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Session")).Return(nil)
+
+			jwtConfig := config.JWTConfig{SecretKey: "test-secret", ExpiryTime: time.Hour, ImpersonationTTL: 15 * time.Minute}
+			keys := jwt.NewHMACKeySet(jwtConfig.SecretKey)
+			authUsecase := NewAuthUsecase(mockRepo, mockSessionRepo, nil, keys, jwtConfig)
+
+			// Execute
+			token, err := authUsecase.Impersonate(context.Background(), 2, "support", tt.userID, "127.0.0.1")
+
+			// Assert
+			if tt.checkErr != nil {
+				assert.True(t, tt.checkErr(err), "got %v", err)
+				mockSessionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 2, token.ImpersonatorID)
+			assert.WithinDuration(t, time.Now().Add(15*time.Minute), token.ExpiresAt, time.Minute)
+
+			claims, err := keys.ValidateToken(token.Token)
+			assert.NoError(t, err)
+			assert.Equal(t, 7, claims.UserID)
+			assert.Equal(t, &jwt.Actor{UserID: 2, Username: "support"}, claims.Actor)
+			assert.Equal(t, entity.ImpersonationScopes, claims.Scopes)
+			assert.False(t, claims.HasScope(entity.ScopeSessions))
+			mockSessionRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(s *entity.Session) bool {
+				return s.TokenID == claims.ID && s.UserID == 7 && s.Device == "Impersonation by support"
+			}))
 		})
 	}
 }
+
+// stubInvitations accepts a single token and records redemptions
+type stubInvitations struct {
+	invitation *entity.Invitation
+	redeemed   []int
+	redeemErr  error
+}
+
+func (s *stubInvitations) VerifyInvitation(ctx context.Context, link *entity.InvitationLink) (*entity.Invitation, error) {
+	if link.Token != "valid" {
+		return nil, errors.ErrInvitationNotFound
+	}
+	return s.invitation, nil
+}
+
+func (s *stubInvitations) RedeemInvitation(ctx context.Context, invitation *entity.Invitation, userID int) (*entity.Organization, error) {
+	s.redeemed = append(s.redeemed, userID)
+	if s.redeemErr != nil {
+		return nil, s.redeemErr
+	}
+	return &entity.Organization{ID: invitation.OrganizationID, Role: invitation.Role}, nil
+}
+
+func TestAuthUsecase_RegisterWithInvitation(t *testing.T) {
+	jwtConfig := config.JWTConfig{SecretKey: "test-secret", ExpiryTime: 24 * time.Hour}
+
+	t.Run("invalid link creates no account", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		invitations := &stubInvitations{}
+		authUsecase := NewAuthUsecase(mockRepo, new(MockSessionRepository), nil, jwt.NewHMACKeySet(jwtConfig.SecretKey), jwtConfig)
+		authUsecase.SetInvitations(invitations)
+
+		_, err := authUsecase.RegisterWithInvitation(context.Background(), &entity.InvitedRegisterRequest{
+			Username:       "alice",
+			Password:       "password123",
+			InvitationLink: entity.InvitationLink{Token: "forged"},
+		})
+
+		assert.True(t, errors.IsInvitationNotFound(err))
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("account gets the invited email and joins the organization", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockRepo.On("GetByUsername", mock.Anything, "alice").Return(nil, errors.ErrUserNotFound)
+		mockRepo.On("GetByEmail", mock.Anything, "alice@example.com").Return(nil, errors.ErrUserNotFound)
+		mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(user *entity.User) bool {
+			user.ID = 42
+			return user.Email == "alice@example.com" && user.Role == entity.RoleUser
+		})).Return(nil)
+		invitations := &stubInvitations{invitation: &entity.Invitation{
+			ID: 1, OrganizationID: 5, Email: "alice@example.com", Role: entity.OrgRoleAdmin,
+		}}
+		authUsecase := NewAuthUsecase(mockRepo, new(MockSessionRepository), nil, jwt.NewHMACKeySet(jwtConfig.SecretKey), jwtConfig)
+		authUsecase.SetInvitations(invitations)
+
+		registration, err := authUsecase.RegisterWithInvitation(context.Background(), &entity.InvitedRegisterRequest{
+			Username:       "alice",
+			Password:       "password123",
+			InvitationLink: entity.InvitationLink{Token: "valid"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "alice@example.com", registration.User.Email)
+		if assert.NotNil(t, registration.Organization) {
+			assert.Equal(t, 5, registration.Organization.ID)
+			assert.Equal(t, entity.OrgRoleAdmin, registration.Organization.Role)
+		}
+		assert.Equal(t, []int{42}, invitations.redeemed)
+		assert.Empty(t, registration.JoinError)
+	})
+
+	t.Run("failure to join is reported", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockRepo.On("GetByUsername", mock.Anything, "alice").Return(nil, errors.ErrUserNotFound)
+		mockRepo.On("GetByEmail", mock.Anything, "alice@example.com").Return(nil, errors.ErrUserNotFound)
+		mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		invitations := &stubInvitations{
+			invitation: &entity.Invitation{ID: 1, OrganizationID: 5, Email: "alice@example.com", Role: entity.OrgRoleMember},
+			redeemErr:  errors.ErrInvitationNotFound,
+		}
+		authUsecase := NewAuthUsecase(mockRepo, new(MockSessionRepository), nil, jwt.NewHMACKeySet(jwtConfig.SecretKey), jwtConfig)
+		authUsecase.SetInvitations(invitations)
+
+		registration, err := authUsecase.RegisterWithInvitation(context.Background(), &entity.InvitedRegisterRequest{
+			Username:       "alice",
+			Password:       "password123",
+			InvitationLink: entity.InvitationLink{Token: "valid"},
+		})
+
+		assert.NoError(t, err, "the account exists")
+		assert.Nil(t, registration.Organization)
+		assert.NotEmpty(t, registration.JoinError)
+	})
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+)
+
+// InvitationService verifies and redeems organization invitations.
+type InvitationService interface {
+	VerifyInvitation(ctx context.Context, link *entity.InvitationLink) (*entity.Invitation, error)
+	RedeemInvitation(ctx context.Context, invitation *entity.Invitation, userID int) (*entity.Organization, error)
+}
+
+// SetInvitations enables signing up with organization invitations.
+func (uc *AuthUsecase) SetInvitations(invitations InvitationService) {
+	uc.invitations = invitations
+}
+
+// RegisterWithInvitation creates an account for the invited email address
+// and adds it to the organization of the invitation with the invited role.
+// The invitation is verified before the account is created, so an invalid
+// link creates nothing. Should joining fail once the account exists, the
+// registration reports it in JoinError and the invitation stays pending, to
+// be accepted after signing in.
+func (uc *AuthUsecase) RegisterWithInvitation(ctx context.Context, req *entity.InvitedRegisterRequest) (*entity.InvitedRegistration, error) {
+	if uc.invitations == nil {
+		return nil, errors.ErrInvitationNotFound
+	}
+	invitation, err := uc.invitations.VerifyInvitation(ctx, &req.InvitationLink)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := uc.Register(ctx, &entity.RegisterRequest{
+		Username: req.Username,
+		Email:    invitation.Email,
+		Password: req.Password,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	registration := &entity.InvitedRegistration{User: user}
+	registration.Organization, err = uc.invitations.RedeemInvitation(ctx, invitation, user.ID)
+	if err != nil {
+		registration.Organization = nil
+		registration.JoinError = "joining the organization failed; sign in and accept the invitation again"
+		if uc.logger != nil {
+			uc.logger.ErrorLogger(ctx, err, "Failed to redeem invitation at signup", map[string]interface{}{
+				"user_id":         user.ID,
+				"invitation_id":   invitation.ID,
+				"organization_id": invitation.OrganizationID,
+			})
+		}
+	}
+	return registration, nil
+}
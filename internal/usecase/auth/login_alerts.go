@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// versionPattern matches version numbers in user agents, which change with
+// every browser or OS update of the same device
+var versionPattern = regexp.MustCompile(`[0-9]+([._][0-9]+)*`)
+
+// JobQueue runs background jobs with retries.
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobType string, payload interface{}) error
+}
+
+// newSignIn is what is new about a sign-in compared with the user's recent sessions
+type newSignIn struct {
+	device   bool
+	location bool
+}
+
+// SetLoginAlerts compares sign-ins with the user's recent sessions. Sign-ins
+// from a new device or country are emailed to the user through the job
+// queue and, when cfg.RequireVerification is set, need a code texted to the
+// user's verified phone.
+func (uc *AuthUsecase) SetLoginAlerts(cfg config.LoginAlertConfig, jobs JobQueue, log *logger.Logger) {
+	uc.loginAlerts = cfg
+	uc.jobs = jobs
+	uc.logger = log
+}
+
+// signIn issues a token for a user who proved who they are. When verify is
+// set and verification is required, a sign-in from a new device or country
+// also needs verificationCode; without it a code is texted and
+// ErrLoginUnverified returned. Deactivated users get ErrUserDeactivated.
+func (uc *AuthUsecase) signIn(ctx context.Context, user *entity.User, ipAddress, userAgent, verificationCode string, verify bool) (*entity.LoginResponse, error) {
+	if user.DeactivatedAt != nil {
+		return nil, errors.ErrUserDeactivated
+	}
+
+	location := uc.resolveLocation(ctx, ipAddress)
+
+	signIn, err := uc.detectNewSignIn(ctx, user, userAgent, location)
+	if err != nil {
+		return nil, err
+	}
+	if signIn != nil && verify && uc.loginAlerts.RequireVerification {
+		if err := uc.verifySignIn(ctx, user, verificationCode); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := uc.issueToken(ctx, user, ipAddress, userAgent, location)
+	if err != nil {
+		return nil, err
+	}
+
+	if signIn != nil {
+		uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+			"user_id":      user.ID,
+			"new_device":   signIn.device,
+			"new_location": signIn.location,
+			"action":       "new_sign_in",
+		}).Warn("Sign-in from a new device or location")
+		uc.sendSignInAlert(ctx, user, ipAddress, userAgent, location)
+	}
+	return resp, nil
+}
+
+// detectNewSignIn returns what is new about the sign-in, or nil when the
+// device and country were seen before. Without recent sessions there is
+// nothing to compare with, so the sign-in is not reported.
+func (uc *AuthUsecase) detectNewSignIn(ctx context.Context, user *entity.User, userAgent, location string) (*newSignIn, error) {
+	if !uc.loginAlerts.Enabled {
+		return nil, nil
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+	history, err := uc.sessionRepo.ListByUserIDSince(readCtx, user.ID, time.Now().Add(-uc.loginAlerts.History))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sign-in history: %w", err)
+	}
+	if len(history) == 0 {
+		return nil, nil
+	}
+
+	device, country := deviceKey(userAgent), locationCountry(location)
+	signIn := &newSignIn{device: true, location: country != ""}
+	for _, session := range history {
+		if deviceKey(session.Device) == device {
+			signIn.device = false
+		}
+		if locationCountry(session.Location) == country {
+			signIn.location = false
+		}
+	}
+	if !signIn.device && !signIn.location {
+		return nil, nil
+	}
+	return signIn, nil
+}
+
+// verifySignIn checks the code texted to the user's verified phone, texting
+// one when code is empty. Users without a verified phone cannot be verified
+// and are only alerted.
+func (uc *AuthUsecase) verifySignIn(ctx context.Context, user *entity.User, code string) error {
+	if uc.otp == nil || user.Phone == "" || user.PhoneVerifiedAt == nil {
+		return nil
+	}
+
+	if code == "" {
+		// A code sent moments ago is still valid
+		if err := uc.otp.Send(ctx, entity.OTPPurposeLoginVerification, user.Phone, user.Locale); err != nil && !errors.IsOTPRateLimited(err) {
+			return err
+		}
+		return fmt.Errorf("%w: enter the code texted to your phone", errors.ErrLoginUnverified)
+	}
+	return uc.otp.Verify(ctx, entity.OTPPurposeLoginVerification, user.Phone, code)
+}
+
+// sendSignInAlert queues a security email about the sign-in, unless the user
+// opted out. Failures are logged: the sign-in itself succeeded.
+func (uc *AuthUsecase) sendSignInAlert(ctx context.Context, user *entity.User, ipAddress, userAgent, location string) {
+	if user.LoginAlertsDisabled || uc.jobs == nil {
+		return
+	}
+
+	signedInAt := time.Now().UTC()
+	if tz, err := time.LoadLocation(user.Timezone); err == nil && user.Timezone != "" {
+		signedInAt = signedInAt.In(tz)
+	}
+	if location == "" {
+		location = "Unknown"
+	}
+
+	emailReq := &entity.EmailRequest{
+		To:      []string{user.Email},
+		Subject: "New sign-in to your account",
+		Body: fmt.Sprintf(`
+Hello %s,
+
+Your account was just signed in to from a new device or location.
+
+- Time: %s
+- Device: %s
+- Location: %s
+- IP address: %s
+
+If this was you, you can ignore this email.
+
+If it was not, change your password now and log out all other sessions.
+You can turn off these emails in your profile settings.
+
+Best regards,
+Boilerplate Team
+		`, user.DisplayName(), signedInAt.Format("Jan 2, 2006 15:04 MST"), userAgent, location, ipAddress),
+		Metadata: map[string]interface{}{
+			"user_id": user.ID,
+			"type":    "security_new_sign_in",
+		},
+	}
+	if user.Locale != "" {
+		emailReq.Metadata["locale"] = user.Locale
+	}
+	if user.Timezone != "" {
+		emailReq.Metadata["timezone"] = user.Timezone
+	}
+
+	if err := uc.jobs.Enqueue(ctx, entity.JobTypeEmail, emailReq); err != nil {
+		uc.logger.ErrorLogger(ctx, err, "Failed to send sign-in alert", map[string]interface{}{
+			"user_id": user.ID,
+		})
+	}
+}
+
+// deviceKey identifies a device by its user agent without version numbers
+func deviceKey(userAgent string) string {
+	return strings.ToLower(strings.TrimSpace(versionPattern.ReplaceAllString(userAgent, "")))
+}
+
+// locationCountry returns the country of a location resolved by
+// resolveLocation: "City, Country" or "Country"
+func locationCountry(location string) string {
+	if i := strings.LastIndex(location, ", "); i >= 0 {
+		return location[i+2:]
+	}
+	return location
+}
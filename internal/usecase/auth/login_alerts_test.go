@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/hash"
+	"boilerplate-go/pkg/jwt"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	chromeMac    = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	chromeMac2   = "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_1) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/121.0.6167.85 Safari/537.36"
+	firefoxLinux = "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0"
+)
+
+// MockJobQueue is a mock implementation of JobQueue
+type MockJobQueue struct {
+	mock.Mock
+}
+
+func (m *MockJobQueue) Enqueue(ctx context.Context, jobType string, payload interface{}) error {
+	args := m.Called(ctx, jobType, payload)
+	return args.Error(0)
+}
+
+func TestDeviceKey_IgnoresVersions(t *testing.T) {
+	assert.Equal(t, deviceKey(chromeMac), deviceKey(chromeMac2))
+	assert.NotEqual(t, deviceKey(chromeMac), deviceKey(firefoxLinux))
+}
+
+func TestLocationCountry(t *testing.T) {
+	assert.Equal(t, "Indonesia", locationCountry("Jakarta, Indonesia"))
+	assert.Equal(t, "Indonesia", locationCountry("Indonesia"))
+	assert.Equal(t, "", locationCountry(""))
+}
+
+// loginFrom logs in from the user agent and a client located in the country
+func loginFrom(uc *AuthUsecase, userAgent, country, code string) (*entity.LoginResponse, error) {
+	ctx := entity.ContextWithClientInfo(context.Background(), &entity.ClientInfo{
+		IPAddress: "203.0.113.7",
+		Location:  &entity.LocationInfo{City: "Somewhere", Country: country},
+	})
+	return uc.Login(ctx, &entity.LoginRequest{
+		Username:         "testuser",
+		Password:         "secret-password",
+		IPAddress:        "203.0.113.7",
+		UserAgent:        userAgent,
+		VerificationCode: code,
+	})
+}
+
+func newAlertingUsecase(t *testing.T, user *entity.User, history []*entity.Session, cfg config.LoginAlertConfig) (*AuthUsecase, *MockJobQueue, *MockSessionRepository) {
+	hashed, err := hash.HashPassword("secret-password")
+	require.NoError(t, err)
+	user.Password = hashed
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByUsername", mock.Anything, "testuser").Return(user, nil)
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("ListByUserIDSince", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).Return(history, nil)
+	sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Session")).Return(nil)
+	jobs := new(MockJobQueue)
+	jobs.On("Enqueue", mock.Anything, entity.JobTypeEmail, mock.AnythingOfType("*entity.EmailRequest")).Return(nil)
+
+	jwtConfig := config.JWTConfig{SecretKey: "test-secret", ExpiryTime: time.Hour}
+	uc := NewAuthUsecase(userRepo, sessionRepo, nil, jwt.NewHMACKeySet(jwtConfig.SecretKey), jwtConfig)
+	uc.SetLoginAlerts(cfg, jobs, logger.NewLogger())
+	return uc, jobs, sessionRepo
+}
+
+func TestAuthUsecase_LoginAlerts(t *testing.T) {
+	cfg := config.LoginAlertConfig{Enabled: true, History: 90 * 24 * time.Hour}
+	history := []*entity.Session{{UserID: 1, Device: chromeMac, Location: "Jakarta, Indonesia"}}
+
+	tests := []struct {
+		name      string
+		user      *entity.User
+		history   []*entity.Session
+		userAgent string
+		country   string
+		alerted   bool
+	}{
+		{name: "known device and country", user: &entity.User{ID: 1}, history: history, userAgent: chromeMac2, country: "Indonesia"},
+		{name: "new device", user: &entity.User{ID: 1}, history: history, userAgent: firefoxLinux, country: "Indonesia", alerted: true},
+		{name: "new country", user: &entity.User{ID: 1}, history: history, userAgent: chromeMac, country: "Brazil", alerted: true},
+		{name: "first sign-in", user: &entity.User{ID: 1}, userAgent: firefoxLinux, country: "Brazil"},
+		{name: "opted out", user: &entity.User{ID: 1, LoginAlertsDisabled: true}, history: history, userAgent: firefoxLinux, country: "Brazil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.user.Username, tt.user.Email = "testuser", "test@example.com"
+			uc, jobs, _ := newAlertingUsecase(t, tt.user, tt.history, cfg)
+
+			resp, err := loginFrom(uc, tt.userAgent, tt.country, "")
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, resp.Token)
+			if tt.alerted {
+				jobs.AssertCalled(t, "Enqueue", mock.Anything, entity.JobTypeEmail, mock.MatchedBy(func(email *entity.EmailRequest) bool {
+					return email.To[0] == "test@example.com" && email.Subject == "New sign-in to your account"
+				}))
+			} else {
+				jobs.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestAuthUsecase_LoginAlerts_RequireVerification(t *testing.T) {
+	cfg := config.LoginAlertConfig{Enabled: true, History: 90 * 24 * time.Hour, RequireVerification: true}
+	history := []*entity.Session{{UserID: 1, Device: chromeMac, Location: "Jakarta, Indonesia"}}
+	verifiedAt := time.Now()
+	user := &entity.User{ID: 1, Username: "testuser", Email: "test@example.com", Phone: "+6281234567890", PhoneVerifiedAt: &verifiedAt}
+
+	uc, jobs, sessionRepo := newAlertingUsecase(t, user, history, cfg)
+	otpService := new(MockOTPService)
+	otpService.On("Send", mock.Anything, entity.OTPPurposeLoginVerification, "+6281234567890", mock.Anything).Return(nil)
+	otpService.On("Verify", mock.Anything, entity.OTPPurposeLoginVerification, "+6281234567890", "123456").Return(nil)
+	otpService.On("Verify", mock.Anything, entity.OTPPurposeLoginVerification, "+6281234567890", "000000").Return(errors.ErrInvalidOTP)
+	uc.SetOTP(otpService)
+
+	// Known devices sign in without a code
+	_, err := loginFrom(uc, chromeMac, "Indonesia", "")
+	require.NoError(t, err)
+
+	_, err = loginFrom(uc, firefoxLinux, "Brazil", "")
+	assert.True(t, errors.IsLoginUnverified(err))
+	otpService.AssertNumberOfCalls(t, "Send", 1)
+
+	_, err = loginFrom(uc, firefoxLinux, "Brazil", "000000")
+	assert.True(t, errors.IsInvalidOTP(err))
+
+	resp, err := loginFrom(uc, firefoxLinux, "Brazil", "123456")
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+	sessionRepo.AssertNumberOfCalls(t, "Create", 2)
+	jobs.AssertNumberOfCalls(t, "Enqueue", 1)
+}
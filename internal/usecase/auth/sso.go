@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"context"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+)
+
+// SSOService authenticates users with the identity provider of their
+// organization.
+type SSOService interface {
+	Authenticate(ctx context.Context, req *entity.SSOCallbackRequest) (*entity.User, error)
+}
+
+// SetSSO enables signing in with single sign-on.
+func (uc *AuthUsecase) SetSSO(sso SSOService) {
+	uc.sso = sso
+}
+
+// LoginWithSSO signs in the user the identity provider redirected back with.
+// Like OTP logins, sign-ins vouched for by the identity provider are not
+// verified again, though new devices and locations are still alerted.
+func (uc *AuthUsecase) LoginWithSSO(ctx context.Context, req *entity.SSOCallbackRequest) (*entity.LoginResponse, error) {
+	if uc.sso == nil {
+		return nil, errors.ErrSSONotConfigured
+	}
+	user, err := uc.sso.Authenticate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return uc.signIn(ctx, user, req.IPAddress, req.UserAgent, "", false)
+}
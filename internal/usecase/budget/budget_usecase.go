@@ -0,0 +1,279 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+)
+
+const (
+	defaultUsageDays = 7
+	maxUsageDays     = 90
+	dayLayout        = "2006-01-02"
+)
+
+// Recorder records the calls to paid providers, such as the metrics
+type Recorder interface {
+	RecordProviderCall(provider string, cost float64, blocked bool)
+	SetProviderBudgetUsed(provider string, ratio float64)
+}
+
+// usageKey identifies the usage of a provider on a day
+type usageKey struct {
+	provider string
+	day      string
+}
+
+// usage is the usage of a provider on a day as known to this instance: the
+// total last read from the database and the calls made since
+type usage struct {
+	flushedCalls int64
+	flushedCost  float64
+	calls        int64
+	cost         float64
+	warned       bool
+	exceeded     bool
+}
+
+func (u *usage) totalCost() float64 {
+	return u.flushedCost + u.cost
+}
+
+// BudgetUsecase counts the calls to paid providers and their cost each UTC
+// day against the configured budgets. Usage is shared between instances
+// through the database every flush interval, so an instance learns about the
+// calls of the others with that delay.
+type BudgetUsecase struct {
+	usageRepo        repository.ProviderUsageRepository
+	budgets          map[string]config.ProviderBudget
+	warnAt           float64
+	blockNonCritical bool
+	flushInterval    time.Duration
+	recorder         Recorder
+	timeouts         *timeout.Policy
+	logger           *logger.Logger
+
+	mu    sync.Mutex
+	usage map[usageKey]*usage
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewBudgetUsecase creates a new budget use case. The recorder may be nil.
+func NewBudgetUsecase(usageRepo repository.ProviderUsageRepository, cfg config.BudgetConfig, recorder Recorder, log *logger.Logger) *BudgetUsecase {
+	return &BudgetUsecase{
+		usageRepo: usageRepo,
+		budgets: map[string]config.ProviderBudget{
+			entity.BudgetPayment: cfg.Payment,
+			entity.BudgetSMS:     cfg.SMS,
+			entity.BudgetEmail:   cfg.Email,
+		},
+		warnAt:           cfg.WarnAt,
+		blockNonCritical: cfg.BlockNonCritical,
+		flushInterval:    cfg.FlushInterval,
+		recorder:         recorder,
+		logger:           log,
+		usage:            make(map[usageKey]*usage),
+	}
+}
+
+// SetTimeouts bounds repository calls with per-operation deadlines.
+func (u *BudgetUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	u.timeouts = timeouts
+}
+
+// Spend records a call to the provider costing units, such as the recipients
+// of an email. Once the daily budget of the provider is spent, a call that is
+// not critical is refused with ErrBudgetExceeded if blocking is enabled.
+func (u *BudgetUsecase) Spend(ctx context.Context, provider string, units int, critical bool) error {
+	budget, ok := u.budgets[provider]
+	if !ok {
+		return nil
+	}
+	cost := float64(units) * budget.CostPerCall
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	current := u.current(provider)
+	if budget.Daily > 0 && current.totalCost() >= budget.Daily && u.blockNonCritical && !critical {
+		if u.recorder != nil {
+			u.recorder.RecordProviderCall(provider, 0, true)
+		}
+		return fmt.Errorf("%w: daily budget of %s spent", errors.ErrBudgetExceeded, provider)
+	}
+
+	current.calls += int64(units)
+	current.cost += cost
+	if u.recorder != nil {
+		u.recorder.RecordProviderCall(provider, cost, false)
+	}
+	u.check(ctx, provider, current)
+	return nil
+}
+
+// current returns today's usage of the provider. It is called with the lock
+// held.
+func (u *BudgetUsecase) current(provider string) *usage {
+	key := usageKey{provider: provider, day: time.Now().UTC().Format(dayLayout)}
+	current, ok := u.usage[key]
+	if !ok {
+		current = &usage{}
+		u.usage[key] = current
+	}
+	return current
+}
+
+// check reports the usage of the provider against its budget, warning once
+// per day when the usage reaches the warning share and when it reaches the
+// budget. It is called with the lock held.
+func (u *BudgetUsecase) check(ctx context.Context, provider string, current *usage) {
+	budget := u.budgets[provider]
+	if budget.Daily <= 0 {
+		return
+	}
+	spent := current.totalCost() / budget.Daily
+	if u.recorder != nil {
+		u.recorder.SetProviderBudgetUsed(provider, spent)
+	}
+
+	fields := map[string]interface{}{
+		"provider": provider,
+		"cost":     current.totalCost(),
+		"budget":   budget.Daily,
+	}
+	switch {
+	case spent >= 1 && !current.exceeded:
+		current.exceeded, current.warned = true, true
+		u.logger.WithContext(ctx).WithFields(fields).WithField("blocking", u.blockNonCritical).
+			Error("Provider daily budget spent")
+	case spent >= u.warnAt && !current.warned:
+		current.warned = true
+		u.logger.WithContext(ctx).WithFields(fields).Warn("Provider daily budget nearly spent")
+	}
+}
+
+// Start flushes the usage every flush interval until Stop
+func (u *BudgetUsecase) Start() {
+	u.stop, u.done = make(chan struct{}), make(chan struct{})
+
+	go func() {
+		defer close(u.done)
+		ticker := time.NewTicker(u.flushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-u.stop:
+				return
+			case <-ticker.C:
+				if err := u.Flush(context.Background()); err != nil {
+					u.logger.WithError(err).Warn("Failed to flush provider usage")
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the flushes and flushes the usage recorded since the last one
+func (u *BudgetUsecase) Stop(ctx context.Context) error {
+	if u.stop != nil {
+		close(u.stop)
+		select {
+		case <-u.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return u.Flush(ctx)
+}
+
+// Flush adds the calls recorded since the last flush to the daily usage in
+// the database and reads back the totals of all instances. Calls that fail
+// to flush are kept for the next one.
+func (u *BudgetUsecase) Flush(ctx context.Context) error {
+	if u.usageRepo == nil {
+		return nil
+	}
+
+	u.mu.Lock()
+	pending := make(map[usageKey]usage, len(u.usage))
+	today := time.Now().UTC().Format(dayLayout)
+	for key, current := range u.usage {
+		// Today's usage is flushed to learn about the other instances
+		if current.calls > 0 || key.day == today {
+			pending[key] = *current
+			current.calls, current.cost = 0, 0
+		}
+		if key.day != today {
+			delete(u.usage, key)
+		}
+	}
+	u.mu.Unlock()
+
+	var firstErr error
+	for key, flushed := range pending {
+		writeCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+		total, err := u.usageRepo.Add(writeCtx, key.provider, key.day, flushed.calls, flushed.cost)
+		cancel()
+
+		u.mu.Lock()
+		current, ok := u.usage[key]
+		if !ok {
+			current = &flushed
+			current.calls, current.cost = 0, 0
+			if err != nil || key.day == today {
+				u.usage[key] = current
+			}
+		}
+		if err != nil {
+			current.calls += flushed.calls
+			current.cost += flushed.cost
+			if firstErr == nil {
+				firstErr = err
+			}
+		} else {
+			current.flushedCalls, current.flushedCost = total.Calls, total.Cost
+			if key.day == today {
+				u.check(ctx, key.provider, current)
+			}
+		}
+		u.mu.Unlock()
+	}
+	return firstErr
+}
+
+// Usage returns the usage of the paid providers over the last days, newest
+// first, with their budgets. The days are clamped to the allowed range.
+func (u *BudgetUsecase) Usage(ctx context.Context, days int) ([]*entity.ProviderUsage, error) {
+	if days < 1 {
+		days = defaultUsageDays
+	}
+	if days > maxUsageDays {
+		days = maxUsageDays
+	}
+	if err := u.Flush(ctx); err != nil {
+		return nil, fmt.Errorf("failed to flush provider usage: %w", err)
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, 1-days).Format(dayLayout)
+	readCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+	usage, err := u.usageRepo.List(readCtx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, day := range usage {
+		day.Budget = u.budgets[day.Provider].Daily
+	}
+	return usage, nil
+}
@@ -0,0 +1,110 @@
+package budget
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// usageStore is an in-memory ProviderUsageRepository shared by instances
+type usageStore struct {
+	mu    sync.Mutex
+	usage map[usageKey]entity.ProviderUsage
+}
+
+func newUsageStore() *usageStore {
+	return &usageStore{usage: make(map[usageKey]entity.ProviderUsage)}
+}
+
+func (s *usageStore) Add(ctx context.Context, provider, day string, calls int64, cost float64) (*entity.ProviderUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := usageKey{provider: provider, day: day}
+	usage := s.usage[key]
+	usage.Provider, usage.Day = provider, day
+	usage.Calls += calls
+	usage.Cost += cost
+	s.usage[key] = usage
+	return &usage, nil
+}
+
+func (s *usageStore) List(ctx context.Context, since string) ([]*entity.ProviderUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []*entity.ProviderUsage
+	for _, usage := range s.usage {
+		if usage.Day >= since {
+			list = append(list, &usage)
+		}
+	}
+	return list, nil
+}
+
+func budgetConfig(block bool) config.BudgetConfig {
+	return config.BudgetConfig{
+		Email:            config.ProviderBudget{Daily: 10, CostPerCall: 0.5},
+		SMS:              config.ProviderBudget{CostPerCall: 1},
+		WarnAt:           0.8,
+		BlockNonCritical: block,
+		FlushInterval:    time.Minute,
+	}
+}
+
+func TestBudgetUsecase_Spend(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("non-critical calls are refused once the budget is spent", func(t *testing.T) {
+		u := NewBudgetUsecase(nil, budgetConfig(true), nil, logger.NewLogger())
+
+		require.NoError(t, u.Spend(ctx, entity.BudgetEmail, 20, false))
+		err := u.Spend(ctx, entity.BudgetEmail, 1, false)
+		assert.True(t, errors.IsBudgetExceeded(err), "got %v", err)
+		assert.NoError(t, u.Spend(ctx, entity.BudgetEmail, 1, true), "critical calls go through")
+	})
+
+	t.Run("without blocking calls over budget are only tracked", func(t *testing.T) {
+		u := NewBudgetUsecase(nil, budgetConfig(false), nil, logger.NewLogger())
+
+		require.NoError(t, u.Spend(ctx, entity.BudgetEmail, 20, false))
+		assert.NoError(t, u.Spend(ctx, entity.BudgetEmail, 1, false))
+	})
+
+	t.Run("providers without a budget are never refused", func(t *testing.T) {
+		u := NewBudgetUsecase(nil, budgetConfig(true), nil, logger.NewLogger())
+
+		for i := 0; i < 100; i++ {
+			require.NoError(t, u.Spend(ctx, entity.BudgetSMS, 1, false))
+		}
+	})
+}
+
+func TestBudgetUsecase_Flush(t *testing.T) {
+	ctx := context.Background()
+	store := newUsageStore()
+	first := NewBudgetUsecase(store, budgetConfig(true), nil, logger.NewLogger())
+	second := NewBudgetUsecase(store, budgetConfig(true), nil, logger.NewLogger())
+
+	require.NoError(t, first.Spend(ctx, entity.BudgetEmail, 12, false))
+	require.NoError(t, second.Spend(ctx, entity.BudgetEmail, 12, false))
+	require.NoError(t, first.Flush(ctx))
+	require.NoError(t, second.Flush(ctx))
+
+	// The second instance learned the usage of the first at its flush
+	assert.True(t, errors.IsBudgetExceeded(second.Spend(ctx, entity.BudgetEmail, 1, false)))
+
+	usage, err := first.Usage(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	assert.Equal(t, int64(24), usage[0].Calls)
+	assert.Equal(t, 12.0, usage[0].Cost)
+	assert.Equal(t, 10.0, usage[0].Budget)
+}
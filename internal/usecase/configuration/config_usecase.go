@@ -0,0 +1,189 @@
+package configuration
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultChangesPageSize = 20
+	maxChangesPageSize     = 100
+)
+
+// ProviderSwitcher replaces the running providers with ones created from the
+// configuration, failing without replacing any when one cannot be created
+type ProviderSwitcher interface {
+	SwitchProviders(cfg *config.Config) error
+}
+
+// ConfigUsecase exports the effective configuration and applies provider
+// profiles at runtime, keeping an audit trail of the changes. Applied
+// profiles last until the server restarts.
+type ConfigUsecase struct {
+	mu         sync.Mutex
+	config     *config.Config
+	profile    string
+	changeRepo repository.ConfigChangeRepository
+	switcher   ProviderSwitcher
+	locales    []string
+	timeouts   *timeout.Policy
+	logger     *logger.Logger
+}
+
+// NewConfigUsecase creates a new configuration use case starting from cfg
+func NewConfigUsecase(cfg *config.Config, changeRepo repository.ConfigChangeRepository, switcher ProviderSwitcher, log *logger.Logger) *ConfigUsecase {
+	return &ConfigUsecase{
+		config:     cfg,
+		profile:    entity.ProfileEnvironment,
+		changeRepo: changeRepo,
+		switcher:   switcher,
+		logger:     log,
+	}
+}
+
+// SetTimeouts bounds repository calls with per-operation deadlines.
+func (uc *ConfigUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	uc.timeouts = timeouts
+}
+
+// SetLocales sets the locales responses can be written in, starting with the
+// default one. Without them only the default locale is listed.
+func (uc *ConfigUsecase) SetLocales(locales []string) {
+	uc.locales = locales
+}
+
+// Public returns the settings in effect that frontends need. They are picked
+// one by one, so nothing is exposed unless it is listed here.
+func (uc *ConfigUsecase) Public() *entity.PublicConfig {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	cfg := uc.config
+
+	payment := entity.PublicPaymentConfig{
+		Provider:   cfg.Providers.Payment.Provider,
+		Currencies: make([]string, 0, len(cfg.Providers.Payment.Currencies)),
+	}
+	switch payment.Provider {
+	case "stripe":
+		payment.StripePublishableKey = cfg.Providers.Payment.Stripe.PublishableKey
+	case "paypal":
+		payment.PayPalClientID = cfg.Providers.Payment.PayPal.ClientID
+	}
+	for _, currency := range cfg.Providers.Payment.Currencies {
+		payment.Currencies = append(payment.Currencies, strings.ToUpper(currency))
+	}
+
+	locales := uc.locales
+	if len(locales) == 0 {
+		locales = []string{cfg.I18n.DefaultLocale}
+	}
+
+	return &entity.PublicConfig{
+		Payment: payment,
+		Features: map[string]bool{
+			"deferred_payments": cfg.Features.DeferredPayments,
+			"captcha":           cfg.Providers.Captcha.Provider != "",
+			"cookie_auth":       cfg.Cookies.Enabled(),
+			"sso":               cfg.SSO.RedirectURL != "",
+		},
+		Locales: entity.PublicLocales{Default: cfg.I18n.DefaultLocale, Supported: locales},
+		Uploads: entity.PublicUploadLimits{
+			MaxFileSize:        cfg.Upload.MaxSize,
+			PartSize:           cfg.Upload.PartSize,
+			AvatarMaxSize:      cfg.Avatar.MaxUploadSize,
+			AvatarMaxDimension: cfg.Avatar.MaxDimension,
+		},
+	}
+}
+
+// Effective returns the configuration in effect with its secrets redacted
+func (uc *ConfigUsecase) Effective() *config.Config {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	return uc.config.Redacted()
+}
+
+// Profile returns the provider settings in effect as a profile, with its
+// secrets redacted
+func (uc *ConfigUsecase) Profile() *entity.ProviderProfile {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	return &entity.ProviderProfile{Name: uc.profile, Settings: uc.config.Providers.Settings()}
+}
+
+// ApplyProfile validates the profile, replaces the providers its settings
+// change and records the change made by the user. Settings left out of the
+// profile keep their current value.
+func (uc *ConfigUsecase) ApplyProfile(ctx context.Context, userID int, profile *entity.ProviderProfile) (*entity.ConfigChange, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	providers, changed, err := uc.config.Providers.WithSettings(profile.Settings)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidSettings, err)
+	}
+
+	change := &entity.ConfigChange{Profile: profile.Name, ChangedBy: userID, Changes: []entity.SettingChange{}}
+	if len(changed) == 0 {
+		uc.profile = profile.Name
+		return change, nil
+	}
+
+	next := *uc.config
+	next.Providers = providers
+	if err := next.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidSettings, err)
+	}
+	if err := uc.switcher.SwitchProviders(&next); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidSettings, err)
+	}
+
+	before, after := uc.config.Providers.Settings(), next.Providers.Settings()
+	for _, key := range changed {
+		change.Changes = append(change.Changes, entity.SettingChange{Key: key, From: before[key], To: after[key]})
+	}
+	uc.config, uc.profile = &next, profile.Name
+
+	// The log entry is the audit record of last resort
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"audit":      true,
+		"profile":    change.Profile,
+		"changes":    change.Changes,
+		"changed_by": userID,
+	}).Info("Provider profile applied")
+
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+
+	if err := uc.changeRepo.Create(ctx, change); err != nil {
+		return nil, fmt.Errorf("profile applied but not recorded: %w", err)
+	}
+	return change, nil
+}
+
+// ListChanges returns one page of the applied profiles, newest first, and the
+// total count
+func (uc *ConfigUsecase) ListChanges(ctx context.Context, filter *entity.ConfigChangeFilter) ([]*entity.ConfigChange, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = defaultChangesPageSize
+	}
+	if filter.PageSize > maxChangesPageSize {
+		filter.PageSize = maxChangesPageSize
+	}
+
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	return uc.changeRepo.List(ctx, *filter)
+}
@@ -0,0 +1,143 @@
+package configuration
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryChangeRepository keeps the configuration changes in memory
+type memoryChangeRepository struct {
+	changes []entity.ConfigChange
+}
+
+func (r *memoryChangeRepository) Create(ctx context.Context, change *entity.ConfigChange) error {
+	change.ID = len(r.changes) + 1
+	r.changes = append(r.changes, *change)
+	return nil
+}
+
+func (r *memoryChangeRepository) List(ctx context.Context, filter entity.ConfigChangeFilter) ([]*entity.ConfigChange, int, error) {
+	changes := make([]*entity.ConfigChange, 0)
+	for i := range r.changes {
+		changes = append(changes, &r.changes[i])
+	}
+	return changes, len(r.changes), nil
+}
+
+// switcher records the configurations providers are switched to
+type switcher struct {
+	switched []*config.Config
+	err      error
+}
+
+func (s *switcher) SwitchProviders(cfg *config.Config) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.switched = append(s.switched, cfg)
+	return nil
+}
+
+func newTestUsecase() (*ConfigUsecase, *memoryChangeRepository, *switcher) {
+	cfg := config.LoadConfig()
+	cfg.Providers.Payment.Stripe.APIKey = "sk_test_123"
+	cfg.Providers.Notification.Email.APIKey = "mailgun-key"
+
+	repo, s := &memoryChangeRepository{}, &switcher{}
+	return NewConfigUsecase(cfg, repo, s, logger.NewLogger()), repo, s
+}
+
+func TestConfigUsecase_ApplyProfile(t *testing.T) {
+	uc, repo, s := newTestUsecase()
+
+	change, err := uc.ApplyProfile(context.Background(), 7, &entity.ProviderProfile{
+		Name: "sendgrid",
+		Settings: map[string]string{
+			"EMAIL_SERVICE_URL": "https://api.sendgrid.com/v3",
+			"EMAIL_API_KEY":     "sendgrid-key",
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []entity.SettingChange{
+		{Key: "EMAIL_API_KEY", From: config.RedactedValue, To: config.RedactedValue},
+		{Key: "EMAIL_SERVICE_URL", From: "https://api.mailgun.net/v3", To: "https://api.sendgrid.com/v3"},
+	}, change.Changes)
+	require.Len(t, s.switched, 1)
+	assert.Equal(t, "sendgrid-key", s.switched[0].Providers.Notification.Email.APIKey)
+	require.Len(t, repo.changes, 1)
+	assert.Equal(t, 7, repo.changes[0].ChangedBy)
+
+	profile := uc.Profile()
+	assert.Equal(t, "sendgrid", profile.Name)
+	assert.Equal(t, "https://api.sendgrid.com/v3", profile.Settings["EMAIL_SERVICE_URL"])
+	assert.Equal(t, config.RedactedValue, uc.Effective().Providers.Notification.Email.APIKey)
+}
+
+func TestConfigUsecase_ApplyProfile_Unchanged(t *testing.T) {
+	uc, repo, s := newTestUsecase()
+
+	change, err := uc.ApplyProfile(context.Background(), 7, &entity.ProviderProfile{Name: "current", Settings: uc.Profile().Settings})
+
+	require.NoError(t, err)
+	assert.Empty(t, change.Changes)
+	assert.Empty(t, s.switched)
+	assert.Empty(t, repo.changes)
+}
+
+func TestConfigUsecase_Public(t *testing.T) {
+	uc, _, _ := newTestUsecase()
+	uc.SetLocales([]string{"en", "id"})
+
+	public := uc.Public()
+	assert.Equal(t, "stripe", public.Payment.Provider)
+	assert.Empty(t, public.Payment.StripePublishableKey)
+	assert.Equal(t, entity.PublicLocales{Default: "en", Supported: []string{"en", "id"}}, public.Locales)
+	assert.Contains(t, public.Features, "deferred_payments")
+
+	_, err := uc.ApplyProfile(context.Background(), 7, &entity.ProviderProfile{
+		Name:     "stripe frontend",
+		Settings: map[string]string{"STRIPE_PUBLISHABLE_KEY": "pk_test_456"},
+	})
+	require.NoError(t, err)
+
+	public = uc.Public()
+	assert.Equal(t, "pk_test_456", public.Payment.StripePublishableKey)
+	assert.Empty(t, public.Payment.PayPalClientID)
+}
+
+func TestConfigUsecase_ApplyProfile_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]string
+		err      error
+	}{
+		{name: "not at runtime", settings: map[string]string{"DB_PASSWORD": "secret"}},
+		// A live key is refused in development
+		{name: "invalid configuration", settings: map[string]string{"STRIPE_ENVIRONMENT": config.PaymentLive, "STRIPE_API_KEY": "sk_live_123"}},
+		// The publishable key is served to anyone
+		{name: "secret publishable key", settings: map[string]string{"STRIPE_PUBLISHABLE_KEY": "sk_test_123"}},
+		{name: "provider not created", settings: map[string]string{"SMS_API_KEY": "twilio-key"}, err: stderrors.New("unsupported")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, repo, s := newTestUsecase()
+			s.err = tt.err
+
+			_, err := uc.ApplyProfile(context.Background(), 7, &entity.ProviderProfile{Name: "broken", Settings: tt.settings})
+
+			assert.True(t, errors.IsInvalidSettings(err), err)
+			assert.Empty(t, repo.changes)
+			assert.Equal(t, entity.ProfileEnvironment, uc.Profile().Name)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package consent
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"fmt"
+)
+
+// ConsentUsecase records users accepting the terms of service and privacy
+// policy, and tells which of their current versions a user has yet to
+// accept. The current versions come from the configuration, so publishing a
+// new one takes a restart.
+type ConsentUsecase struct {
+	consentRepo repository.ConsentRepository
+	documents   []entity.LegalDocument
+	timeouts    *timeout.Policy
+	logger      *logger.Logger
+}
+
+// NewConsentUsecase creates a new consent use case for the documents with a
+// version configured
+func NewConsentUsecase(consentRepo repository.ConsentRepository, cfg config.LegalConfig, log *logger.Logger) *ConsentUsecase {
+	var documents []entity.LegalDocument
+	if cfg.TermsVersion != "" {
+		documents = append(documents, entity.LegalDocument{Document: entity.DocumentTerms, Version: cfg.TermsVersion, URL: cfg.TermsURL})
+	}
+	if cfg.PrivacyVersion != "" {
+		documents = append(documents, entity.LegalDocument{Document: entity.DocumentPrivacy, Version: cfg.PrivacyVersion, URL: cfg.PrivacyURL})
+	}
+
+	return &ConsentUsecase{
+		consentRepo: consentRepo,
+		documents:   documents,
+		logger:      log,
+	}
+}
+
+// SetTimeouts bounds repository calls with per-operation deadlines.
+func (uc *ConsentUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	uc.timeouts = timeouts
+}
+
+// Documents returns the current versions of the legal documents. Without any
+// no consent is asked for.
+func (uc *ConsentUsecase) Documents() []entity.LegalDocument {
+	return uc.documents
+}
+
+// Status returns the current documents the user has yet to accept and the
+// versions the user accepted.
+func (uc *ConsentUsecase) Status(ctx context.Context, userID int) (*entity.ConsentStatus, error) {
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	consents, err := uc.consentRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ConsentStatus{Pending: uc.pending(consents), Consents: consents}, nil
+}
+
+// PendingDocuments returns the current documents the user has yet to accept.
+func (uc *ConsentUsecase) PendingDocuments(ctx context.Context, userID int) ([]entity.LegalDocument, error) {
+	if len(uc.documents) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	consents, err := uc.consentRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.pending(consents), nil
+}
+
+// Accept records the user accepting the documents, which must be their
+// current versions. Consent is the user's own, so it cannot be given while
+// impersonating. Accepting a version again keeps when it was first accepted.
+func (uc *ConsentUsecase) Accept(ctx context.Context, userID int, req *entity.AcceptConsentRequest) (*entity.ConsentStatus, error) {
+	if entity.ActorFromContext(ctx).ImpersonatorID != 0 {
+		return nil, errors.ErrConsentImpersonated
+	}
+	for _, accepted := range req.Documents {
+		document, ok := uc.document(accepted.Document)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown document %q", errors.ErrInvalidConsent, accepted.Document)
+		}
+		if accepted.Version != document.Version {
+			return nil, fmt.Errorf("%w: %s version %q is not the current one", errors.ErrInvalidConsent, accepted.Document, accepted.Version)
+		}
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	for _, accepted := range req.Documents {
+		consent := &entity.Consent{
+			UserID:    userID,
+			Document:  accepted.Document,
+			Version:   accepted.Version,
+			IPAddress: req.IPAddress,
+			UserAgent: req.UserAgent,
+		}
+		if err := uc.consentRepo.Create(writeCtx, consent); err != nil {
+			cancel()
+			return nil, err
+		}
+
+		uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+			"user_id":    userID,
+			"document":   consent.Document,
+			"version":    consent.Version,
+			"ip_address": consent.IPAddress,
+		}).Info("Legal document accepted")
+	}
+	cancel()
+
+	return uc.Status(ctx, userID)
+}
+
+func (uc *ConsentUsecase) document(name string) (entity.LegalDocument, bool) {
+	for _, document := range uc.documents {
+		if document.Document == name {
+			return document, true
+		}
+	}
+	return entity.LegalDocument{}, false
+}
+
+// pending returns the current documents missing from the consents
+func (uc *ConsentUsecase) pending(consents []*entity.Consent) []entity.LegalDocument {
+	pending := []entity.LegalDocument{}
+	for _, document := range uc.documents {
+		accepted := false
+		for _, consent := range consents {
+			if consent.Document == document.Document && consent.Version == document.Version {
+				accepted = true
+				break
+			}
+		}
+		if !accepted {
+			pending = append(pending, document)
+		}
+	}
+	return pending
+}
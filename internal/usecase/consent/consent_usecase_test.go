@@ -0,0 +1,146 @@
+package consent
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryConsentRepository keeps consents in a slice, oldest first.
+type memoryConsentRepository struct {
+	consents []*entity.Consent
+}
+
+func (r *memoryConsentRepository) Create(ctx context.Context, consent *entity.Consent) error {
+	for _, existing := range r.consents {
+		if existing.UserID == consent.UserID && existing.Document == consent.Document && existing.Version == consent.Version {
+			*consent = *existing
+			return nil
+		}
+	}
+	consent.ID = len(r.consents) + 1
+	consent.AcceptedAt = time.Now()
+	copied := *consent
+	r.consents = append(r.consents, &copied)
+	return nil
+}
+
+func (r *memoryConsentRepository) ListByUser(ctx context.Context, userID int) ([]*entity.Consent, error) {
+	consents := []*entity.Consent{}
+	for i := len(r.consents) - 1; i >= 0; i-- {
+		if r.consents[i].UserID == userID {
+			consents = append(consents, r.consents[i])
+		}
+	}
+	return consents, nil
+}
+
+func newTestUsecase(cfg config.LegalConfig) (*ConsentUsecase, *memoryConsentRepository) {
+	repo := &memoryConsentRepository{}
+	return NewConsentUsecase(repo, cfg, logger.NewLogger()), repo
+}
+
+var legal = config.LegalConfig{
+	TermsVersion:   "2026-01",
+	TermsURL:       "https://example.com/terms",
+	PrivacyVersion: "2026-03",
+	PrivacyURL:     "https://example.com/privacy",
+}
+
+func TestConsentUsecase_Accept(t *testing.T) {
+	uc, repo := newTestUsecase(legal)
+	ctx := context.Background()
+
+	pending, err := uc.PendingDocuments(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	status, err := uc.Accept(ctx, 1, &entity.AcceptConsentRequest{
+		Documents: []entity.DocumentVersion{{Document: entity.DocumentTerms, Version: "2026-01"}},
+		IPAddress: "203.0.113.7",
+		UserAgent: "Mozilla/5.0",
+	})
+	require.NoError(t, err)
+	require.Len(t, status.Pending, 1)
+	assert.Equal(t, entity.DocumentPrivacy, status.Pending[0].Document)
+	require.Len(t, status.Consents, 1)
+	assert.Equal(t, "203.0.113.7", status.Consents[0].IPAddress)
+	assert.Equal(t, "Mozilla/5.0", status.Consents[0].UserAgent)
+
+	_, err = uc.Accept(ctx, 1, &entity.AcceptConsentRequest{
+		Documents: []entity.DocumentVersion{{Document: entity.DocumentPrivacy, Version: "2026-03"}},
+	})
+	require.NoError(t, err)
+	pending, err = uc.PendingDocuments(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	assert.Len(t, repo.consents, 2)
+
+	pending, err = uc.PendingDocuments(ctx, 2)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2, "consent is per user")
+}
+
+func TestConsentUsecase_NewVersion(t *testing.T) {
+	uc, repo := newTestUsecase(legal)
+	repo.consents = []*entity.Consent{
+		{ID: 1, UserID: 1, Document: entity.DocumentTerms, Version: "2025-06"},
+		{ID: 2, UserID: 1, Document: entity.DocumentPrivacy, Version: "2026-03"},
+	}
+
+	pending, err := uc.PendingDocuments(context.Background(), 1)
+
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, entity.LegalDocument{Document: entity.DocumentTerms, Version: "2026-01", URL: "https://example.com/terms"}, pending[0])
+}
+
+func TestConsentUsecase_AcceptInvalid(t *testing.T) {
+	uc, repo := newTestUsecase(legal)
+
+	tests := []struct {
+		name     string
+		document entity.DocumentVersion
+	}{
+		{"unknown document", entity.DocumentVersion{Document: "cookie_policy", Version: "1"}},
+		{"outdated version", entity.DocumentVersion{Document: entity.DocumentTerms, Version: "2025-06"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.Accept(context.Background(), 1, &entity.AcceptConsentRequest{
+				Documents: []entity.DocumentVersion{{Document: entity.DocumentPrivacy, Version: "2026-03"}, tt.document},
+			})
+			assert.True(t, errors.IsInvalidConsent(err))
+		})
+	}
+	assert.Empty(t, repo.consents, "nothing is recorded when a document is invalid")
+}
+
+func TestConsentUsecase_AcceptImpersonated(t *testing.T) {
+	uc, repo := newTestUsecase(legal)
+	ctx := entity.ContextWithActor(context.Background(), &entity.Actor{UserID: 1, ImpersonatorID: 9})
+
+	_, err := uc.Accept(ctx, 1, &entity.AcceptConsentRequest{
+		Documents: []entity.DocumentVersion{{Document: entity.DocumentTerms, Version: "2026-01"}},
+	})
+
+	assert.True(t, errors.IsConsentImpersonated(err))
+	assert.Empty(t, repo.consents)
+}
+
+func TestConsentUsecase_NoDocuments(t *testing.T) {
+	uc, _ := newTestUsecase(config.LegalConfig{})
+
+	pending, err := uc.PendingDocuments(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	assert.Empty(t, uc.Documents())
+}
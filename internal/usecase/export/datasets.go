@@ -0,0 +1,149 @@
+package export
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/ndjson"
+	"boilerplate-go/pkg/xlsx"
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// columns are the header rows of the datasets
+var columns = map[string][]string{
+	entity.ExportDatasetUsers: {
+		"id", "username", "email", "role", "first_name", "last_name", "phone", "phone_verified_at",
+		"locale", "timezone", "created_at",
+	},
+	entity.ExportDatasetOrders: {
+		"order_id", "user_id", "status", "amount", "currency", "coupon_code", "discount_amount",
+		"payment_id", "created_at", "updated_at",
+	},
+	entity.ExportDatasetSignIns: {
+		"id", "user_id", "ip_address", "device", "location", "created_at", "last_seen_at",
+		"expires_at", "revoked_at",
+	},
+}
+
+// iterate passes the rows of the dataset to fn as records, in ID order, as
+// they are read from the database
+func (uc *ExportUsecase) iterate(ctx context.Context, req *entity.ExportRequest, fn func(record []string) error) error {
+	switch req.Dataset {
+	case entity.ExportDatasetUsers:
+		return uc.exportRepo.IterateUsers(ctx, &req.Filter, func(user *entity.User) error {
+			return fn([]string{
+				strconv.Itoa(user.ID), user.Username, user.Email, user.Role, user.FirstName, user.LastName,
+				user.Phone, formatTime(user.PhoneVerifiedAt), user.Locale, user.Timezone, formatTime(&user.CreatedAt),
+			})
+		})
+	case entity.ExportDatasetOrders:
+		return uc.exportRepo.IterateOrders(ctx, &req.Filter, func(order *entity.Order) error {
+			return fn([]string{
+				order.OrderID, strconv.Itoa(order.UserID), order.Status, formatAmount(order.Amount), order.Currency,
+				order.CouponCode, formatAmount(order.DiscountAmount), order.PaymentID,
+				formatTime(&order.CreatedAt), formatTime(&order.UpdatedAt),
+			})
+		})
+	case entity.ExportDatasetSignIns:
+		return uc.exportRepo.IterateSessions(ctx, &req.Filter, func(session *entity.Session) error {
+			return fn([]string{
+				strconv.Itoa(session.ID), strconv.Itoa(session.UserID), session.IPAddress, session.Device, session.Location,
+				formatTime(&session.CreatedAt), formatTime(&session.LastSeenAt), formatTime(&session.ExpiresAt),
+				formatTime(session.RevokedAt),
+			})
+		})
+	}
+	return nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// table writes the records of an export in its format
+type table interface {
+	Write(record []string) error
+	Flush() error
+	Close() error
+}
+
+func newTable(format, sheetName string, w io.Writer) table {
+	switch format {
+	case entity.ExportFormatXLSX:
+		return xlsx.NewWriter(w, sheetName)
+	case entity.ExportFormatNDJSON:
+		return &ndjsonTable{Writer: ndjson.NewWriter(w)}
+	}
+	return &csvTable{csv.NewWriter(w)}
+}
+
+// csvTable writes CSV, prefixing cells that spreadsheets would run as
+// formulas with a quote. Workbook cells are always text and need no quoting.
+type csvTable struct {
+	*csv.Writer
+}
+
+func (t *csvTable) Write(record []string) error {
+	for i, value := range record {
+		if isFormula(value) {
+			record[i] = "'" + value
+		}
+	}
+	return t.Writer.Write(record)
+}
+
+func (t *csvTable) Flush() error {
+	t.Writer.Flush()
+	return t.Writer.Error()
+}
+
+func (t *csvTable) Close() error {
+	return t.Flush()
+}
+
+// ndjsonTable writes every record as a JSON object keyed by the columns of
+// the header, the first record. Empty cells are null.
+type ndjsonTable struct {
+	*ndjson.Writer
+	columns []string
+}
+
+func (t *ndjsonTable) Write(record []string) error {
+	if t.columns == nil {
+		t.columns = record
+		return nil
+	}
+	row := make(map[string]interface{}, len(t.columns))
+	for i, column := range t.columns {
+		if record[i] == "" {
+			row[column] = nil
+			continue
+		}
+		row[column] = record[i]
+	}
+	return t.Writer.Write(row)
+}
+
+func (t *ndjsonTable) Close() error {
+	return t.Flush()
+}
+
+// isFormula reports whether a spreadsheet would read the value as a formula.
+// Signed numbers, such as phone numbers, are left as they are.
+func isFormula(value string) bool {
+	if value == "" || !strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return false
+	}
+	_, err := strconv.ParseFloat(value, 64)
+	return err != nil
+}
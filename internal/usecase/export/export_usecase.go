@@ -0,0 +1,352 @@
+package export
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/internal/usecase/job"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/ndjson"
+	"boilerplate-go/pkg/timeout"
+	"boilerplate-go/pkg/xlsx"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"time"
+)
+
+// JobQueue queues background jobs
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobType string, payload interface{}) error
+}
+
+// ExportUsecase exports datasets as CSV or Excel workbooks, streamed to the
+// caller or generated in the background into file storage. Rows are read
+// and written in chunks, so exports never hold a dataset in memory.
+type ExportUsecase struct {
+	exportRepo repository.ExportRepository
+	storage    provider.FileStorageProvider
+	config     config.ExportConfig
+	jobs       JobQueue
+	timeouts   *timeout.Policy
+	logger     *logger.Logger
+}
+
+// NewExportUsecase creates a new export use case. Background exports need
+// the file storage and a job queue.
+func NewExportUsecase(exportRepo repository.ExportRepository, storage provider.FileStorageProvider, cfg config.ExportConfig, log *logger.Logger) *ExportUsecase {
+	return &ExportUsecase{
+		exportRepo: exportRepo,
+		storage:    storage,
+		config:     cfg,
+		logger:     log,
+	}
+}
+
+// SetTimeouts bounds repository and storage calls with per-operation deadlines.
+func (uc *ExportUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	uc.timeouts = timeouts
+}
+
+// SetJobQueue enables background exports, run as jobs on jobs.
+func (uc *ExportUsecase) SetJobQueue(jobs JobQueue) {
+	uc.jobs = jobs
+}
+
+// FileName returns the name of an export file of the dataset created at
+func FileName(dataset, format string, at time.Time) string {
+	return fmt.Sprintf("%s-%s.%s", dataset, at.UTC().Format("20060102-150405"), format)
+}
+
+// ContentType returns the media type of the export format
+func ContentType(format string) string {
+	switch format {
+	case entity.ExportFormatXLSX:
+		return xlsx.ContentType
+	case entity.ExportFormatNDJSON:
+		return ndjson.ContentType
+	}
+	return "text/csv"
+}
+
+// Validate checks the export request, defaulting the format to CSV
+func (uc *ExportUsecase) Validate(req *entity.ExportRequest) error {
+	if _, ok := columns[req.Dataset]; !ok {
+		return fmt.Errorf("%w: dataset must be users, orders or sign_ins", errors.ErrInvalidExport)
+	}
+
+	switch req.Format {
+	case "":
+		req.Format = entity.ExportFormatCSV
+	case entity.ExportFormatCSV, entity.ExportFormatXLSX, entity.ExportFormatNDJSON:
+	default:
+		return fmt.Errorf("%w: format must be csv, xlsx or ndjson", errors.ErrInvalidExport)
+	}
+
+	filter := req.Filter
+	switch {
+	case filter.Role != "" && req.Dataset != entity.ExportDatasetUsers:
+		return fmt.Errorf("%w: role only filters users", errors.ErrInvalidExport)
+	case filter.Status != "" && req.Dataset != entity.ExportDatasetOrders:
+		return fmt.Errorf("%w: status only filters orders", errors.ErrInvalidExport)
+	case filter.UserID != 0 && req.Dataset == entity.ExportDatasetUsers:
+		return fmt.Errorf("%w: user_id only filters orders and sign-ins", errors.ErrInvalidExport)
+	case filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To):
+		return fmt.Errorf("%w: from must be before to", errors.ErrInvalidExport)
+	}
+	return nil
+}
+
+// Stream writes the export to w as the rows are read from the database,
+// flushing it every ChunkSize rows, and returns the number of rows written.
+// The query stays open until the export is written, bounded by ctx alone.
+func (uc *ExportUsecase) Stream(ctx context.Context, req *entity.ExportRequest, w io.Writer) (int, error) {
+	if err := uc.Validate(req); err != nil {
+		return 0, err
+	}
+
+	table := newTable(req.Format, req.Dataset, w)
+	if err := table.Write(columns[req.Dataset]); err != nil {
+		return 0, err
+	}
+
+	flush := func() error {
+		if err := table.Flush(); err != nil {
+			return err
+		}
+		if flusher, ok := w.(interface{ Flush() }); ok {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	rows := 0
+	err := uc.iterate(ctx, req, func(record []string) error {
+		if err := table.Write(record); err != nil {
+			return err
+		}
+		rows++
+		if rows%uc.config.ChunkSize == 0 {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return rows, err
+	}
+
+	return rows, table.Close()
+}
+
+// Request queues a background export, for exports too large to stream
+// within a request
+func (uc *ExportUsecase) Request(ctx context.Context, userID int, req *entity.ExportRequest) (*entity.Export, error) {
+	if err := uc.Validate(req); err != nil {
+		return nil, err
+	}
+	if uc.storage == nil || uc.jobs == nil {
+		return nil, errors.ErrProviderUnavailable
+	}
+
+	export := &entity.Export{
+		Dataset:     req.Dataset,
+		Format:      req.Format,
+		Filter:      req.Filter,
+		Status:      entity.ExportStatusProcessing,
+		RequestedBy: userID,
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	err := uc.exportRepo.Create(writeCtx, export)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.jobs.Enqueue(ctx, entity.JobTypeExport, entity.ExportJob{ExportID: export.ID}); err != nil {
+		uc.fail(ctx, export, err)
+		return nil, fmt.Errorf("failed to queue export: %w", err)
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"export_id": export.ID,
+		"dataset":   export.Dataset,
+		"format":    export.Format,
+		"user_id":   userID,
+	}).Info("Export queued")
+
+	return export, nil
+}
+
+// Get returns a background export, with a signed download URL once it has
+// completed
+func (uc *ExportUsecase) Get(ctx context.Context, id int) (*entity.Export, error) {
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	export, err := uc.exportRepo.GetByID(readCtx, id)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	if export.Status == entity.ExportStatusCompleted && uc.storage != nil {
+		storageCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationStorage)
+		defer cancel()
+		if export.DownloadURL, err = uc.storage.GetSignedURL(storageCtx, export.FileID, uc.config.DownloadTTL); err != nil {
+			return nil, fmt.Errorf("failed to sign export download URL: %w", err)
+		}
+	}
+
+	return export, nil
+}
+
+// Generate runs a background export, an entity.ExportJob, as a job. The
+// export is uploaded to file storage in parts while it is written.
+func (uc *ExportUsecase) Generate(ctx context.Context, payload json.RawMessage) error {
+	var exportJob entity.ExportJob
+	if err := json.Unmarshal(payload, &exportJob); err != nil {
+		return fmt.Errorf("%w: invalid export payload: %v", job.ErrPermanent, err)
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	export, err := uc.exportRepo.GetByID(readCtx, exportJob.ExportID)
+	cancel()
+	if err != nil {
+		if errors.IsExportNotFound(err) {
+			return fmt.Errorf("%w: %v", job.ErrPermanent, err)
+		}
+		return err
+	}
+	if export.Status != entity.ExportStatusProcessing {
+		return nil
+	}
+	if uc.storage == nil {
+		return fmt.Errorf("%w: %v", job.ErrPermanent, errors.ErrProviderUnavailable)
+	}
+
+	storageCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationStorage)
+	multipart, err := uc.storage.CreateMultipartUpload(storageCtx, &entity.MultipartUploadRequest{
+		Path:        path.Join("exports", strconv.Itoa(export.ID)),
+		FileName:    FileName(export.Dataset, export.Format, export.CreatedAt),
+		ContentType: ContentType(export.Format),
+		Metadata:    map[string]string{"export-id": strconv.Itoa(export.ID)},
+	})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to start export upload: %w", err)
+	}
+
+	parts := &partWriter{ctx: ctx, uc: uc, upload: multipart}
+	req := &entity.ExportRequest{Dataset: export.Dataset, Format: export.Format, Filter: export.Filter}
+	rows, err := uc.Stream(ctx, req, parts)
+	if err == nil {
+		err = parts.Close()
+	}
+	if err == nil {
+		storageCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationStorage)
+		_, err = uc.storage.CompleteMultipartUpload(storageCtx, multipart.FileID, multipart.UploadID, parts.parts)
+		cancel()
+	}
+	if err != nil {
+		storageCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationStorage)
+		if abortErr := uc.storage.AbortMultipartUpload(storageCtx, multipart.FileID, multipart.UploadID); abortErr != nil {
+			uc.logger.ErrorLogger(ctx, abortErr, "Failed to abort export upload", map[string]interface{}{
+				"export_id": export.ID,
+			})
+		}
+		cancel()
+		return err
+	}
+
+	export.Status = entity.ExportStatusCompleted
+	export.Rows = rows
+	export.FileID = multipart.FileID
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.exportRepo.Complete(writeCtx, export); err != nil {
+		return err
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"export_id": export.ID,
+		"rows":      rows,
+	}).Info("Export completed")
+	return nil
+}
+
+// FailExport marks the export of a dead-lettered job as failed
+func (uc *ExportUsecase) FailExport(ctx context.Context, payload json.RawMessage, jobErr error) {
+	var exportJob entity.ExportJob
+	if err := json.Unmarshal(payload, &exportJob); err != nil {
+		return
+	}
+
+	uc.fail(ctx, &entity.Export{ID: exportJob.ExportID}, jobErr)
+}
+
+func (uc *ExportUsecase) fail(ctx context.Context, export *entity.Export, cause error) {
+	export.Status = entity.ExportStatusFailed
+	export.Error = cause.Error()
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.exportRepo.Complete(writeCtx, export); err != nil {
+		uc.logger.ErrorLogger(ctx, err, "Failed to mark export as failed", map[string]interface{}{
+			"export_id": export.ID,
+		})
+	}
+}
+
+// partWriter uploads what is written to it as the parts of a multipart
+// upload, holding at most one part in memory
+type partWriter struct {
+	ctx    context.Context
+	uc     *ExportUsecase
+	upload *entity.MultipartUpload
+	buf    bytes.Buffer
+	parts  []entity.FilePart
+}
+
+func (w *partWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for int64(w.buf.Len()) >= w.uc.config.PartSize {
+		if err := w.uploadPart(w.buf.Next(int(w.uc.config.PartSize))); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close uploads the rest of the file as the last part
+func (w *partWriter) Close() error {
+	if w.buf.Len() == 0 && len(w.parts) > 0 {
+		return nil
+	}
+	return w.uploadPart(w.buf.Next(w.buf.Len()))
+}
+
+func (w *partWriter) uploadPart(content []byte) error {
+	storageCtx, cancel := w.uc.timeouts.WithTimeout(w.ctx, timeout.OperationStorage)
+	defer cancel()
+
+	part, err := w.uc.storage.UploadPart(storageCtx, &entity.FilePartRequest{
+		FileID:     w.upload.FileID,
+		UploadID:   w.upload.UploadID,
+		PartNumber: len(w.parts) + 1,
+		Content:    bytes.NewReader(content),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload export part: %w", err)
+	}
+	w.parts = append(w.parts, *part)
+	return nil
+}
@@ -0,0 +1,248 @@
+package export
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/internal/provider/storage"
+	"boilerplate-go/pkg/errors"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryExportRepository keeps the datasets and exports in memory, counting
+// the queries run
+type memoryExportRepository struct {
+	users   []*entity.User
+	orders  []*entity.Order
+	exports map[int]*entity.Export
+	queries int
+}
+
+func (r *memoryExportRepository) IterateUsers(ctx context.Context, filter *entity.ExportFilter, fn func(*entity.User) error) error {
+	r.queries++
+	for _, user := range r.users {
+		if filter.Role != "" && user.Role != filter.Role {
+			continue
+		}
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memoryExportRepository) IterateOrders(ctx context.Context, filter *entity.ExportFilter, fn func(*entity.Order) error) error {
+	r.queries++
+	for _, order := range r.orders {
+		if err := fn(order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memoryExportRepository) IterateSessions(ctx context.Context, filter *entity.ExportFilter, fn func(*entity.Session) error) error {
+	r.queries++
+	return nil
+}
+
+func (r *memoryExportRepository) Create(ctx context.Context, export *entity.Export) error {
+	export.ID = len(r.exports) + 1
+	export.CreatedAt = time.Now()
+	stored := *export
+	r.exports[export.ID] = &stored
+	return nil
+}
+
+func (r *memoryExportRepository) GetByID(ctx context.Context, id int) (*entity.Export, error) {
+	export, ok := r.exports[id]
+	if !ok {
+		return nil, errors.ErrExportNotFound
+	}
+	copied := *export
+	return &copied, nil
+}
+
+func (r *memoryExportRepository) Complete(ctx context.Context, export *entity.Export) error {
+	stored, ok := r.exports[export.ID]
+	if !ok {
+		return errors.ErrExportNotFound
+	}
+	stored.Status, stored.Rows, stored.FileID, stored.Error = export.Status, export.Rows, export.FileID, export.Error
+	return nil
+}
+
+// queue runs jobs as soon as they are queued
+type queue struct {
+	run func(ctx context.Context, payload json.RawMessage) error
+}
+
+func (q *queue) Enqueue(ctx context.Context, jobType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return q.run(ctx, data)
+}
+
+func newTestUsecase(t *testing.T) (*ExportUsecase, *memoryExportRepository, provider.FileStorageProvider) {
+	created := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	repo := &memoryExportRepository{exports: map[int]*entity.Export{}}
+	for i := 1; i <= 5; i++ {
+		repo.users = append(repo.users, &entity.User{ID: i, Username: "user" + string(rune('0'+i)), Email: "user@example.com", Role: entity.RoleUser, CreatedAt: created})
+	}
+	repo.users[0].Phone = "+6281234567890"
+	repo.users[1].FirstName = "=HYPERLINK(\"http://evil.example\")"
+	repo.users[4].Role = entity.RoleAdmin
+
+	files := storage.NewLocalStorageProvider(storage.LocalConfig{
+		BasePath:   t.TempDir(),
+		SignedURL:  "/files/signed",
+		SigningKey: "secret",
+	}, logger.NewLogger())
+	uc := NewExportUsecase(repo, files, config.ExportConfig{ChunkSize: 2, PartSize: 64, DownloadTTL: time.Minute}, logger.NewLogger())
+	uc.SetJobQueue(&queue{run: uc.Generate})
+	return uc, repo, files
+}
+
+// flushCounter is a response writer counting the flushes of a stream
+type flushCounter struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (w *flushCounter) Flush() {
+	w.flushes++
+}
+
+func TestExportUsecase_Stream_CSV(t *testing.T) {
+	uc, repo, _ := newTestUsecase(t)
+	var buf flushCounter
+
+	rows, err := uc.Stream(context.Background(), &entity.ExportRequest{Dataset: entity.ExportDatasetUsers}, &buf)
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, rows)
+	assert.Equal(t, 1, repo.queries, "rows are streamed from a single query")
+	assert.Equal(t, 3, buf.flushes, "flushed every chunk of rows and at the end")
+
+	records, err := csv.NewReader(&buf.Buffer).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	assert.Equal(t, columns[entity.ExportDatasetUsers], records[0])
+	assert.Equal(t, "+6281234567890", records[1][6])
+	assert.Equal(t, `'=HYPERLINK("http://evil.example")`, records[2][4])
+	assert.Equal(t, "2024-03-01T10:00:00Z", records[1][10])
+}
+
+func TestExportUsecase_Stream_NDJSON(t *testing.T) {
+	uc, repo, _ := newTestUsecase(t)
+	var buf bytes.Buffer
+
+	rows, err := uc.Stream(context.Background(), &entity.ExportRequest{
+		Dataset: entity.ExportDatasetUsers,
+		Format:  entity.ExportFormatNDJSON,
+	}, &buf)
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, rows)
+	assert.Equal(t, 1, repo.queries)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 5)
+	var first, second map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, "1", first["id"])
+	assert.Equal(t, "+6281234567890", first["phone"])
+	assert.Nil(t, first["phone_verified_at"])
+	assert.Equal(t, "2024-03-01T10:00:00Z", first["created_at"])
+	// Formulas only need quoting in spreadsheets
+	assert.Equal(t, `=HYPERLINK("http://evil.example")`, second["first_name"])
+	assert.Equal(t, "application/x-ndjson", ContentType(entity.ExportFormatNDJSON))
+}
+
+func TestExportUsecase_Stream_Filter(t *testing.T) {
+	uc, _, _ := newTestUsecase(t)
+	var buf bytes.Buffer
+
+	rows, err := uc.Stream(context.Background(), &entity.ExportRequest{
+		Dataset: entity.ExportDatasetUsers,
+		Filter:  entity.ExportFilter{Role: entity.RoleAdmin},
+	}, &buf)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, rows)
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+}
+
+func TestExportUsecase_Validate(t *testing.T) {
+	uc, _, _ := newTestUsecase(t)
+	from := time.Now()
+
+	tests := []struct {
+		name string
+		req  entity.ExportRequest
+	}{
+		{name: "unknown dataset", req: entity.ExportRequest{Dataset: "payments"}},
+		{name: "unknown format", req: entity.ExportRequest{Dataset: entity.ExportDatasetOrders, Format: "pdf"}},
+		{name: "role of orders", req: entity.ExportRequest{Dataset: entity.ExportDatasetOrders, Filter: entity.ExportFilter{Role: "admin"}}},
+		{name: "status of users", req: entity.ExportRequest{Dataset: entity.ExportDatasetUsers, Filter: entity.ExportFilter{Status: "completed"}}},
+		{name: "user of users", req: entity.ExportRequest{Dataset: entity.ExportDatasetUsers, Filter: entity.ExportFilter{UserID: 1}}},
+		{name: "empty range", req: entity.ExportRequest{Dataset: entity.ExportDatasetSignIns, Filter: entity.ExportFilter{From: &from, To: &from}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, errors.IsInvalidExport(uc.Validate(&tt.req)))
+		})
+	}
+
+	req := entity.ExportRequest{Dataset: entity.ExportDatasetSignIns}
+	require.NoError(t, uc.Validate(&req))
+	assert.Equal(t, entity.ExportFormatCSV, req.Format)
+}
+
+func TestExportUsecase_Background(t *testing.T) {
+	uc, repo, files := newTestUsecase(t)
+	ctx := context.Background()
+
+	// The workbook is larger than a part, so it is uploaded in several
+	created, err := uc.Request(ctx, 9, &entity.ExportRequest{Dataset: entity.ExportDatasetUsers, Format: entity.ExportFormatXLSX})
+	require.NoError(t, err)
+
+	export, err := uc.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.ExportStatusCompleted, export.Status)
+	assert.Equal(t, 5, export.Rows)
+	assert.Equal(t, 9, export.RequestedBy)
+	assert.Contains(t, export.DownloadURL, "/files/signed")
+	assert.True(t, strings.HasPrefix(repo.exports[created.ID].FileID, "exports/1/users-"))
+
+	file, err := files.OpenFile(ctx, export.FileID)
+	require.NoError(t, err)
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Greater(t, len(content), 64)
+	assert.Equal(t, "PK", string(content[:2]))
+}
+
+func TestExportUsecase_Request_NoStorage(t *testing.T) {
+	uc := NewExportUsecase(&memoryExportRepository{}, nil, config.ExportConfig{ChunkSize: 10}, logger.NewLogger())
+
+	_, err := uc.Request(context.Background(), 1, &entity.ExportRequest{Dataset: entity.ExportDatasetOrders})
+
+	assert.True(t, errors.IsProviderUnavailable(err))
+}
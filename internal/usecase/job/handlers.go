@@ -0,0 +1,36 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+)
+
+// EmailSender delivers an email, such as the notification provider or the
+// notification worker pool.
+type EmailSender interface {
+	SendEmail(ctx context.Context, req *entity.EmailRequest) (*entity.EmailResponse, error)
+}
+
+// EmailHandler sends an entity.EmailRequest payload through the sender.
+// A full notification queue fails the attempt, so the email is retried later.
+func EmailHandler(notificationProvider EmailSender) Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var req entity.EmailRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return fmt.Errorf("%w: invalid email payload: %v", ErrPermanent, err)
+		}
+
+		if _, err := notificationProvider.SendEmail(ctx, &req); err != nil {
+			// Rejected attachments and emails over budget are rejected again on every retry
+			if errors.IsInvalidAttachment(err) || errors.IsBudgetExceeded(err) {
+				return fmt.Errorf("%w: %v", ErrPermanent, err)
+			}
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,503 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/lock"
+)
+
+const (
+	defaultDeadLetterPageSize = 20
+	maxDeadLetterPageSize     = 100
+	defaultPollInterval       = time.Second
+	defaultLease              = 5 * time.Minute
+)
+
+// ErrPermanent marks a job failure that retrying cannot fix, such as a payload
+// that does not decode. Handlers wrap it to send the job straight to the dead
+// letter queue.
+var ErrPermanent = stderrors.New("permanent job failure")
+
+// Handler processes the payload of a background job.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// DeadLetterHook is called after a job of its type was moved to the dead letter
+// queue, to undo the effects of the job never completing.
+type DeadLetterHook func(ctx context.Context, payload json.RawMessage, jobErr error)
+
+// Leader tells whether the instance was elected to run the singleton
+// processes of the replicas
+type Leader interface {
+	IsLeader() bool
+}
+
+// retryPolicy overrides the default attempts and backoff for a job type.
+type retryPolicy struct {
+	maxAttempts  int
+	retryBackoff time.Duration
+}
+
+// JobUsecase runs persisted background jobs on a pool of workers with retries,
+// and keeps the dead letter queue of jobs that exhausted them.
+type JobUsecase struct {
+	jobRepo        repository.JobRepository
+	deadLetterRepo repository.DeadLetterRepository
+	handlers       map[string]Handler
+	hooks          map[string]DeadLetterHook
+	policies       map[string]retryPolicy
+	schedules      map[string]time.Duration
+	mu             sync.RWMutex
+	maxAttempts    int
+	retryBackoff   time.Duration
+	workers        int
+	pollInterval   time.Duration
+	lease          time.Duration
+	wake           chan struct{}
+	stop           chan struct{}
+	stopOnce       sync.Once
+	wg             sync.WaitGroup
+	locker         lock.Locker
+	leader         Leader
+	logger         *logger.Logger
+	metrics        *metrics.Metrics
+}
+
+// NewJobUsecase creates a new job use case. Call Start to run the workers.
+func NewJobUsecase(jobRepo repository.JobRepository, deadLetterRepo repository.DeadLetterRepository, cfg config.JobConfig, log *logger.Logger, m *metrics.Metrics) *JobUsecase {
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	pollInterval := cfg.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+	lease := cfg.Lease
+	if lease <= 0 {
+		lease = defaultLease
+	}
+
+	return &JobUsecase{
+		jobRepo:        jobRepo,
+		deadLetterRepo: deadLetterRepo,
+		handlers:       make(map[string]Handler),
+		hooks:          make(map[string]DeadLetterHook),
+		policies:       make(map[string]retryPolicy),
+		schedules:      make(map[string]time.Duration),
+		maxAttempts:    maxAttempts,
+		retryBackoff:   cfg.RetryBackoff,
+		workers:        workers,
+		pollInterval:   pollInterval,
+		lease:          lease,
+		wake:           make(chan struct{}, 1),
+		stop:           make(chan struct{}),
+		logger:         log,
+		metrics:        m,
+	}
+}
+
+// Register sets the handler for a job type.
+func (u *JobUsecase) Register(jobType string, handler Handler) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.handlers[jobType] = handler
+}
+
+// SetRetryPolicy overrides the attempts and retry backoff for a job type, e.g.
+// to keep retrying for longer than a provider outage lasts.
+func (u *JobUsecase) SetRetryPolicy(jobType string, maxAttempts int, retryBackoff time.Duration) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.policies[jobType] = retryPolicy{maxAttempts: maxAttempts, retryBackoff: retryBackoff}
+}
+
+// Schedule queues a job of the type, with an empty payload, every interval
+// while the workers run, starting when they start. A job of the type that is
+// still queued is not queued again, so instances sharing the queue do not pile
+// up runs. Call it before Start.
+func (u *JobUsecase) Schedule(jobType string, interval time.Duration) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.schedules[jobType] = interval
+}
+
+// SetLocker makes scheduled jobs run on one instance at a time, holding a
+// lock shared by the instances while they run. Without it, a scheduled job
+// queued again while its previous run is still going may run concurrently
+// on another instance.
+func (u *JobUsecase) SetLocker(locker lock.Locker) {
+	u.locker = locker
+}
+
+// SetLeader queues scheduled jobs only on the leader, so the replicas queue
+// each scheduled job once per interval between them. Jobs still run on any
+// instance.
+func (u *JobUsecase) SetLeader(leader Leader) {
+	u.leader = leader
+}
+
+// OnDeadLetter sets the hook called when a job of the type is dead-lettered.
+func (u *JobUsecase) OnDeadLetter(jobType string, hook DeadLetterHook) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.hooks[jobType] = hook
+}
+
+// Enqueue stores the job for the workers to run. The payload is JSON encoded so
+// that it can be persisted and dead-lettered if every attempt fails.
+func (u *JobUsecase) Enqueue(ctx context.Context, jobType string, payload interface{}) error {
+	if _, ok := u.handler(jobType); !ok {
+		return fmt.Errorf("%w: %s", errors.ErrUnknownJobType, jobType)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	return u.enqueue(ctx, jobType, data)
+}
+
+func (u *JobUsecase) enqueue(ctx context.Context, jobType string, payload json.RawMessage) error {
+	if err := u.jobRepo.Create(ctx, &entity.Job{JobType: jobType, Payload: payload}); err != nil {
+		return err
+	}
+
+	select {
+	case u.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start runs the workers and scheduled jobs until Shutdown is called.
+func (u *JobUsecase) Start() {
+	for i := 0; i < u.workers; i++ {
+		u.wg.Add(1)
+		go u.work()
+	}
+
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	for jobType, interval := range u.schedules {
+		u.wg.Add(1)
+		go u.schedule(jobType, interval)
+	}
+}
+
+// Shutdown stops claiming jobs and waits for in-flight jobs to finish. Jobs
+// still running when ctx expires are claimed again after their lease.
+func (u *JobUsecase) Shutdown(ctx context.Context) error {
+	u.stopOnce.Do(func() { close(u.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		u.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ListDeadLetters returns one page of dead-lettered jobs and the total count.
+func (u *JobUsecase) ListDeadLetters(ctx context.Context, filter *entity.DeadLetterFilter) ([]*entity.DeadLetterJob, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = defaultDeadLetterPageSize
+	}
+	if filter.PageSize > maxDeadLetterPageSize {
+		filter.PageSize = maxDeadLetterPageSize
+	}
+
+	return u.deadLetterRepo.List(ctx, *filter)
+}
+
+// GetDeadLetter returns a dead-lettered job by ID.
+func (u *JobUsecase) GetDeadLetter(ctx context.Context, id int) (*entity.DeadLetterJob, error) {
+	return u.deadLetterRepo.GetByID(ctx, id)
+}
+
+// Requeue removes the job from the dead letter queue and runs it again with a
+// fresh set of attempts. If it fails again it is dead-lettered as a new entry.
+func (u *JobUsecase) Requeue(ctx context.Context, id int) (*entity.DeadLetterJob, error) {
+	job, err := u.deadLetterRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := u.handler(job.JobType); !ok {
+		return nil, fmt.Errorf("%w: %s", errors.ErrUnknownJobType, job.JobType)
+	}
+
+	if err := u.enqueue(ctx, job.JobType, job.Payload); err != nil {
+		return nil, err
+	}
+
+	// The job is queued again; a failed delete only leaves a stale entry behind
+	if err := u.deadLetterRepo.Delete(ctx, id); err != nil {
+		u.logger.ErrorLogger(ctx, err, "Failed to remove requeued dead letter job", map[string]interface{}{
+			"job_id": id,
+		})
+	}
+	u.RefreshDeadLetterDepth(ctx)
+
+	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"job_id":   job.ID,
+		"job_type": job.JobType,
+		"action":   "requeue_job",
+	}).Info("Dead letter job requeued")
+
+	return job, nil
+}
+
+// DiscardDeadLetter deletes a dead-lettered job without running it.
+func (u *JobUsecase) DiscardDeadLetter(ctx context.Context, id int) error {
+	if err := u.deadLetterRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	u.RefreshDeadLetterDepth(ctx)
+	return nil
+}
+
+// RefreshDeadLetterDepth updates the dead letter queue depth metric.
+func (u *JobUsecase) RefreshDeadLetterDepth(ctx context.Context) {
+	count, err := u.deadLetterRepo.Count(ctx)
+	if err != nil {
+		return
+	}
+	u.metrics.SetDeadLetterDepth(float64(count))
+}
+
+func (u *JobUsecase) handler(jobType string) (Handler, bool) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	handler, ok := u.handlers[jobType]
+	return handler, ok
+}
+
+func (u *JobUsecase) retryPolicy(jobType string) retryPolicy {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	if policy, ok := u.policies[jobType]; ok {
+		return policy
+	}
+	return retryPolicy{maxAttempts: u.maxAttempts, retryBackoff: u.retryBackoff}
+}
+
+// work claims and runs due jobs, waiting for new ones when the queue is empty.
+func (u *JobUsecase) work() {
+	defer u.wg.Done()
+
+	for {
+		select {
+		case <-u.stop:
+			return
+		default:
+		}
+
+		jobs, err := u.jobRepo.ClaimDue(context.Background(), 1, u.lease)
+		if err != nil || len(jobs) == 0 {
+			select {
+			case <-u.stop:
+				return
+			case <-u.wake:
+			case <-time.After(u.pollInterval):
+			}
+			continue
+		}
+
+		u.run(jobs[0])
+	}
+}
+
+// schedule queues the job type now and every interval until Shutdown, while
+// the instance leads.
+func (u *JobUsecase) schedule(jobType string, interval time.Duration) {
+	defer u.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if u.leader == nil || u.leader.IsLeader() {
+			u.queueScheduled(jobType, interval)
+		}
+
+		select {
+		case <-u.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// queueScheduled queues a run of the scheduled job type unless one is queued
+func (u *JobUsecase) queueScheduled(jobType string, interval time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), interval)
+	defer cancel()
+
+	queued, err := u.jobRepo.CreateUnlessQueued(ctx, &entity.Job{JobType: jobType, Payload: json.RawMessage("{}")})
+	if err != nil {
+		u.logger.ErrorLogger(ctx, err, "Failed to queue scheduled job", map[string]interface{}{
+			"job_type": jobType,
+		})
+	} else if queued {
+		select {
+		case u.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run makes one attempt at the job, then deletes it, schedules a retry or
+// moves it to the dead letter queue.
+func (u *JobUsecase) run(job *entity.Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), u.lease)
+	defer cancel()
+
+	var err error
+	if handler, ok := u.handler(job.JobType); ok {
+		err = u.execute(ctx, job, handler)
+	} else {
+		err = fmt.Errorf("%w: %v: %s", ErrPermanent, errors.ErrUnknownJobType, job.JobType)
+	}
+
+	if err == nil {
+		u.metrics.RecordJob(job.JobType, "success")
+		if err := u.jobRepo.Delete(ctx, job.ID); err != nil {
+			u.logger.ErrorLogger(ctx, err, "Failed to remove completed job", map[string]interface{}{
+				"job_id":   job.ID,
+				"job_type": job.JobType,
+			})
+		}
+		return
+	}
+
+	u.metrics.RecordJob(job.JobType, "failure")
+	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"job_id":   job.ID,
+		"job_type": job.JobType,
+		"attempt":  job.Attempts,
+		"error":    err.Error(),
+	}).Warn("Background job attempt failed")
+
+	policy := u.retryPolicy(job.JobType)
+	if stderrors.Is(err, ErrPermanent) || job.Attempts >= policy.maxAttempts {
+		u.deadLetter(ctx, job, err)
+		return
+	}
+
+	runAt := time.Now().Add(policy.retryBackoff * time.Duration(1<<(job.Attempts-1)))
+	if err := u.jobRepo.Reschedule(ctx, job.ID, runAt, err.Error()); err != nil {
+		u.logger.ErrorLogger(ctx, err, "Failed to reschedule job", map[string]interface{}{
+			"job_id":   job.ID,
+			"job_type": job.JobType,
+		})
+	}
+}
+
+// execute runs the handler of the job, holding the lock of its type when it
+// is scheduled. A scheduled job whose lock is held is completed without
+// running, as another instance is running the same job.
+func (u *JobUsecase) execute(ctx context.Context, job *entity.Job, handler Handler) error {
+	if !u.scheduled(job.JobType) || u.locker == nil {
+		return execute(ctx, handler, job.Payload)
+	}
+
+	err := lock.WithLock(ctx, u.locker, "job:"+job.JobType, lock.Options{TTL: u.lease}, func(ctx context.Context) error {
+		return execute(ctx, handler, job.Payload)
+	})
+	if stderrors.Is(err, lock.ErrNotAcquired) {
+		u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+			"job_id":   job.ID,
+			"job_type": job.JobType,
+		}).Info("Scheduled job already running on another instance, skipped")
+		return nil
+	}
+	return err
+}
+
+// scheduled reports whether the job type is queued by Schedule
+func (u *JobUsecase) scheduled(jobType string) bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	_, ok := u.schedules[jobType]
+	return ok
+}
+
+// execute runs the handler, converting a panic into a permanent failure so a
+// poison message cannot crash the process or be retried forever.
+func execute(ctx context.Context, handler Handler, payload json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: panic: %v", ErrPermanent, r)
+		}
+	}()
+	return handler(ctx, payload)
+}
+
+// deadLetter moves the job to the dead letter queue. If storing it fails the
+// job stays queued and is dead-lettered again once its lease expires.
+func (u *JobUsecase) deadLetter(ctx context.Context, job *entity.Job, jobErr error) {
+	deadLetter := &entity.DeadLetterJob{
+		JobType:  job.JobType,
+		Payload:  job.Payload,
+		Error:    jobErr.Error(),
+		Attempts: job.Attempts,
+	}
+
+	if err := u.deadLetterRepo.Create(ctx, deadLetter); err != nil {
+		u.logger.ErrorLogger(ctx, err, "Failed to dead-letter background job", map[string]interface{}{
+			"job_id":   job.ID,
+			"job_type": job.JobType,
+		})
+		return
+	}
+	if err := u.jobRepo.Delete(ctx, job.ID); err != nil {
+		u.logger.ErrorLogger(ctx, err, "Failed to remove dead-lettered job", map[string]interface{}{
+			"job_id":   job.ID,
+			"job_type": job.JobType,
+		})
+	}
+	u.metrics.RecordJob(job.JobType, "dead_lettered")
+	u.RefreshDeadLetterDepth(ctx)
+
+	u.mu.RLock()
+	hook := u.hooks[job.JobType]
+	u.mu.RUnlock()
+	if hook != nil {
+		hook(ctx, job.Payload, jobErr)
+	}
+
+	u.logger.ErrorLogger(ctx, jobErr, "Background job moved to dead letter queue", map[string]interface{}{
+		"job_id":   deadLetter.ID,
+		"job_type": job.JobType,
+		"attempts": job.Attempts,
+	})
+}
@@ -0,0 +1,266 @@
+package notification
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/job"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultCampaignChunkSize is the number of recipients per bulk send
+	defaultCampaignChunkSize = 100
+	// campaignMaxRecipients caps the size of a single campaign
+	campaignMaxRecipients = 100000
+)
+
+// CreateCampaign stores a bulk email and sends it in chunks of recipients,
+// in the background when a job queue is configured.
+func (u *NotificationUsecase) CreateCampaign(ctx context.Context, req *entity.CampaignRequest) (*entity.Notification, error) {
+	if u.notificationRepo == nil || u.emailProvider == nil {
+		return nil, fmt.Errorf("%w: bulk email is not configured", errors.ErrProviderUnavailable)
+	}
+
+	recipients := req.Recipients
+	var err error
+	if req.AllUsers {
+		if recipients, err = u.addActiveUsers(ctx, recipients); err != nil {
+			return nil, err
+		}
+	}
+	if recipients, err = normalizeRecipients(recipients); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Subject) == "" || strings.TrimSpace(req.Body) == "" {
+		return nil, fmt.Errorf("%w: subject and body are required", errors.ErrInvalidCampaign)
+	}
+
+	chunkSize := u.chunkSize
+	if chunkSize < 1 {
+		chunkSize = defaultCampaignChunkSize
+	}
+
+	notification := &entity.Notification{
+		Subject:     strings.TrimSpace(req.Subject),
+		Body:        req.Body,
+		BodyHTML:    req.BodyHTML,
+		Recipients:  recipients,
+		Total:       len(recipients),
+		ChunkSize:   chunkSize,
+		TotalChunks: (len(recipients) + chunkSize - 1) / chunkSize,
+		Chunks:      []entity.NotificationChunk{},
+		Status:      entity.NotificationStatusProcessing,
+	}
+
+	writeCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	err = u.notificationRepo.Create(writeCtx, notification)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to store campaign: %w", err)
+	}
+
+	if u.jobs == nil {
+		if err := u.sendCampaign(ctx, notification); err != nil {
+			u.failCampaign(ctx, notification, err)
+		}
+		return notification, nil
+	}
+
+	if err := u.jobs.Enqueue(ctx, entity.JobTypeBulkEmail, entity.BulkEmail{NotificationID: notification.ID}); err != nil {
+		u.failCampaign(ctx, notification, err)
+		return nil, fmt.Errorf("failed to schedule campaign: %w", err)
+	}
+
+	return notification, nil
+}
+
+// GetCampaign returns a bulk email with its per-chunk progress.
+func (u *NotificationUsecase) GetCampaign(ctx context.Context, id int) (*entity.Notification, error) {
+	if u.notificationRepo == nil {
+		return nil, errors.ErrCampaignNotFound
+	}
+
+	ctx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	return u.notificationRepo.GetByID(ctx, id)
+}
+
+// SendCampaign sends a stored bulk email as a background job. A retried job
+// resumes after the last chunk that was sent.
+func (u *NotificationUsecase) SendCampaign(ctx context.Context, payload json.RawMessage) error {
+	var bulkEmail entity.BulkEmail
+	if err := json.Unmarshal(payload, &bulkEmail); err != nil {
+		return fmt.Errorf("%w: invalid bulk email payload: %v", job.ErrPermanent, err)
+	}
+
+	notification, err := u.GetCampaign(ctx, bulkEmail.NotificationID)
+	if err != nil {
+		if errors.IsCampaignNotFound(err) {
+			return fmt.Errorf("%w: %v", job.ErrPermanent, err)
+		}
+		return err
+	}
+	if notification.Status != entity.NotificationStatusProcessing {
+		return nil
+	}
+
+	return u.sendCampaign(ctx, notification)
+}
+
+// FailCampaign marks a bulk email whose job exhausted its retries as failed.
+// Chunks sent before the failure stay recorded.
+func (u *NotificationUsecase) FailCampaign(ctx context.Context, payload json.RawMessage, jobErr error) {
+	var bulkEmail entity.BulkEmail
+	if err := json.Unmarshal(payload, &bulkEmail); err != nil {
+		return
+	}
+
+	u.failCampaign(ctx, &entity.Notification{ID: bulkEmail.NotificationID}, jobErr)
+}
+
+// sendCampaign sends the chunks from NextChunk on, recording each one before
+// moving to the next, and completes the notification once all are sent.
+func (u *NotificationUsecase) sendCampaign(ctx context.Context, notification *entity.Notification) error {
+	for index := notification.NextChunk; index < notification.TotalChunks; index++ {
+		from := index * notification.ChunkSize
+		to := from + notification.ChunkSize
+		if to > len(notification.Recipients) {
+			to = len(notification.Recipients)
+		}
+
+		bulkReq := &entity.BulkEmailRequest{Emails: make([]entity.EmailRequest, 0, to-from)}
+		for _, recipient := range notification.Recipients[from:to] {
+			bulkReq.Emails = append(bulkReq.Emails, entity.EmailRequest{
+				To:       []string{recipient},
+				Subject:  notification.Subject,
+				Body:     notification.Body,
+				BodyHTML: notification.BodyHTML,
+				Priority: entity.NotificationPriorityMarketing,
+				Metadata: map[string]interface{}{
+					"notification_id": notification.ID,
+					"type":            "campaign",
+				},
+			})
+		}
+
+		callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationNotification)
+		resp, err := u.emailProvider.SendBulkEmail(callCtx, bulkReq)
+		cancel()
+		if err != nil {
+			// Retries within the day are refused again
+			if errors.IsBudgetExceeded(err) {
+				return fmt.Errorf("%w: chunk %d of campaign %d: %v", job.ErrPermanent, index, notification.ID, err)
+			}
+			return fmt.Errorf("failed to send chunk %d of campaign %d: %w", index, notification.ID, err)
+		}
+
+		chunk := entity.NotificationChunk{
+			Index:      index,
+			Sent:       resp.SentEmails,
+			Failed:     resp.FailedEmails,
+			ProviderID: resp.ID,
+			SentAt:     time.Now(),
+		}
+
+		writeCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+		err = u.notificationRepo.RecordChunk(writeCtx, notification.ID, chunk)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		notification.NextChunk = index + 1
+		notification.Sent += chunk.Sent
+		notification.Failed += chunk.Failed
+		notification.Chunks = append(notification.Chunks, chunk)
+	}
+
+	notification.Status = entity.NotificationStatusCompleted
+	writeCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := u.notificationRepo.Complete(writeCtx, notification); err != nil {
+		return err
+	}
+
+	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"notification_id": notification.ID,
+		"total":           notification.Total,
+		"sent":            notification.Sent,
+		"failed":          notification.Failed,
+		"action":          "campaign_completed",
+	}).Info("Bulk email campaign completed")
+
+	return nil
+}
+
+func (u *NotificationUsecase) failCampaign(ctx context.Context, notification *entity.Notification, cause error) {
+	notification.Status = entity.NotificationStatusFailed
+	notification.Error = cause.Error()
+
+	writeCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := u.notificationRepo.Complete(writeCtx, notification); err != nil {
+		u.logger.ErrorLogger(ctx, err, "Failed to mark campaign as failed", map[string]interface{}{
+			"notification_id": notification.ID,
+		})
+	}
+}
+
+// addActiveUsers appends the email addresses of the active users to the
+// recipients, reading the users as they are streamed from the database.
+func (u *NotificationUsecase) addActiveUsers(ctx context.Context, recipients []string) ([]string, error) {
+	if u.userRepo == nil {
+		return nil, fmt.Errorf("%w: campaigns to all users are not configured", errors.ErrProviderUnavailable)
+	}
+
+	readCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	recipients = append([]string(nil), recipients...)
+	err := u.userRepo.IterateActiveUsers(readCtx, func(user *entity.User) error {
+		if len(recipients) >= campaignMaxRecipients {
+			return fmt.Errorf("%w: recipients exceed the limit of %d", errors.ErrInvalidCampaign, campaignMaxRecipients)
+		}
+		recipients = append(recipients, user.Email)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recipients, nil
+}
+
+// normalizeRecipients validates the addresses and removes duplicates.
+func normalizeRecipients(recipients []string) ([]string, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("%w: no recipients", errors.ErrInvalidCampaign)
+	}
+	if len(recipients) > campaignMaxRecipients {
+		return nil, fmt.Errorf("%w: %d recipients exceeds the limit of %d", errors.ErrInvalidCampaign, len(recipients), campaignMaxRecipients)
+	}
+
+	seen := make(map[string]bool, len(recipients))
+	normalized := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		email := strings.TrimSpace(recipient)
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			return nil, fmt.Errorf("%w: invalid recipient %q", errors.ErrInvalidCampaign, email)
+		}
+
+		key := strings.ToLower(email)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, email)
+	}
+
+	return normalized, nil
+}
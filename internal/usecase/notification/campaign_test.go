@@ -0,0 +1,248 @@
+package notification
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockNotificationRepository is a mock implementation of NotificationRepository
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) Create(ctx context.Context, notification *entity.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetByID(ctx context.Context, id int) (*entity.Notification, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) RecordChunk(ctx context.Context, id int, chunk entity.NotificationChunk) error {
+	args := m.Called(ctx, id, chunk)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) Complete(ctx context.Context, notification *entity.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+// IterateCompletedSince passes the notifications the call returns to fn
+func (m *MockNotificationRepository) IterateCompletedSince(ctx context.Context, since time.Time, fn func(*entity.Notification) error) error {
+	args := m.Called(ctx, since)
+	if args.Get(0) != nil {
+		for _, notification := range args.Get(0).([]*entity.Notification) {
+			if err := fn(notification); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockNotificationRepository) UpdateEngagement(ctx context.Context, notification *entity.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ListByRecipient(ctx context.Context, email string) ([]*entity.ReceivedNotification, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.ReceivedNotification), args.Error(1)
+}
+
+// MockEmailProvider is a mock implementation of EmailProvider
+type MockEmailProvider struct {
+	mock.Mock
+}
+
+func (m *MockEmailProvider) SendEmail(ctx context.Context, req *entity.EmailRequest) (*entity.EmailResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EmailResponse), args.Error(1)
+}
+
+func (m *MockEmailProvider) SendBulkEmail(ctx context.Context, req *entity.BulkEmailRequest) (*entity.BulkEmailResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.BulkEmailResponse), args.Error(1)
+}
+
+func (m *MockEmailProvider) GetEmailStatus(ctx context.Context, emailID string) (*entity.EmailStatus, error) {
+	args := m.Called(ctx, emailID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EmailStatus), args.Error(1)
+}
+
+// MockJobQueue is a mock implementation of JobQueue
+type MockJobQueue struct {
+	mock.Mock
+}
+
+func (m *MockJobQueue) Enqueue(ctx context.Context, jobType string, payload interface{}) error {
+	args := m.Called(ctx, jobType, payload)
+	return args.Error(0)
+}
+
+func newCampaignUsecase(repo *MockNotificationRepository, email *MockEmailProvider, chunkSize int) *NotificationUsecase {
+	uc := NewNotificationUsecase(new(MockNotificationProvider), config.NotificationConfig{}, logger.NewLogger(), nil)
+	uc.SetCampaigns(repo, email, chunkSize)
+	return uc
+}
+
+// sentTo matches a bulk request addressed to exactly the given recipients.
+func sentTo(recipients ...string) interface{} {
+	return mock.MatchedBy(func(req *entity.BulkEmailRequest) bool {
+		if len(req.Emails) != len(recipients) {
+			return false
+		}
+		for i, email := range req.Emails {
+			if email.To[0] != recipients[i] || email.Priority != entity.NotificationPriorityMarketing {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestNotificationUsecase_CreateCampaign(t *testing.T) {
+	repo := new(MockNotificationRepository)
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(n *entity.Notification) bool {
+		return n.Total == 3 && n.ChunkSize == 2 && n.TotalChunks == 2 && n.Status == entity.NotificationStatusProcessing
+	})).Run(func(args mock.Arguments) { args.Get(1).(*entity.Notification).ID = 5 }).Return(nil)
+	jobs := new(MockJobQueue)
+	jobs.On("Enqueue", mock.Anything, entity.JobTypeBulkEmail, entity.BulkEmail{NotificationID: 5}).Return(nil)
+
+	uc := newCampaignUsecase(repo, new(MockEmailProvider), 2)
+	uc.SetJobQueue(jobs)
+
+	campaign, err := uc.CreateCampaign(context.Background(), &entity.CampaignRequest{
+		Subject:    "Summer sale",
+		Body:       "20% off",
+		Recipients: []string{"a@example.com", " b@example.com ", "A@example.com", "c@example.com"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, campaign.ID)
+	assert.Equal(t, []string{"a@example.com", "b@example.com", "c@example.com"}, campaign.Recipients)
+	repo.AssertExpectations(t)
+	jobs.AssertExpectations(t)
+}
+
+func TestNotificationUsecase_CreateCampaign_Invalid(t *testing.T) {
+	uc := newCampaignUsecase(new(MockNotificationRepository), new(MockEmailProvider), 2)
+
+	_, err := uc.CreateCampaign(context.Background(), &entity.CampaignRequest{Subject: "s", Body: "b"})
+	assert.True(t, errors.IsInvalidCampaign(err))
+
+	_, err = uc.CreateCampaign(context.Background(), &entity.CampaignRequest{Subject: "s", Body: "b", Recipients: []string{"nope"}})
+	assert.True(t, errors.IsInvalidCampaign(err))
+}
+
+// activeUsers streams its users as the active users of the repository
+type activeUsers struct {
+	repository.UserRepository
+	users []*entity.User
+}
+
+func (r activeUsers) IterateActiveUsers(ctx context.Context, fn func(*entity.User) error) error {
+	for _, user := range r.users {
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestNotificationUsecase_CreateCampaign_AllUsers(t *testing.T) {
+	repo := new(MockNotificationRepository)
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	repo.On("RecordChunk", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	repo.On("Complete", mock.Anything, mock.Anything).Return(nil)
+	email := new(MockEmailProvider)
+	email.On("SendBulkEmail", mock.Anything, mock.Anything).Return(&entity.BulkEmailResponse{SentEmails: 3}, nil)
+
+	uc := newCampaignUsecase(repo, email, 10)
+	req := &entity.CampaignRequest{Subject: "News", Body: "Hello", Recipients: []string{"guest@example.com"}, AllUsers: true}
+
+	_, err := uc.CreateCampaign(context.Background(), req)
+	assert.True(t, errors.IsProviderUnavailable(err), "without a user repository: %v", err)
+
+	uc.SetAudience(activeUsers{users: []*entity.User{{Email: "a@example.com"}, {Email: "Guest@example.com"}, {Email: "b@example.com"}}})
+	campaign, err := uc.CreateCampaign(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"guest@example.com", "a@example.com", "b@example.com"}, campaign.Recipients)
+}
+
+func TestNotificationUsecase_SendCampaign_ResumesAfterLastChunk(t *testing.T) {
+	errProvider := stderrors.New("provider down")
+	campaign := func() *entity.Notification {
+		return &entity.Notification{
+			ID:          5,
+			Recipients:  []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"},
+			Total:       5,
+			ChunkSize:   2,
+			TotalChunks: 3,
+			Status:      entity.NotificationStatusProcessing,
+		}
+	}
+	payload, _ := json.Marshal(entity.BulkEmail{NotificationID: 5})
+
+	// First attempt sends chunk 0, then the provider fails on chunk 1
+	repo := new(MockNotificationRepository)
+	repo.On("GetByID", mock.Anything, 5).Return(campaign(), nil).Once()
+	repo.On("RecordChunk", mock.Anything, 5, mock.MatchedBy(func(c entity.NotificationChunk) bool {
+		return c.Index == 0 && c.Sent == 2
+	})).Return(nil).Once()
+	email := new(MockEmailProvider)
+	email.On("SendBulkEmail", mock.Anything, sentTo("a@example.com", "b@example.com")).Return(&entity.BulkEmailResponse{SentEmails: 2}, nil).Once()
+	email.On("SendBulkEmail", mock.Anything, sentTo("c@example.com", "d@example.com")).Return(nil, errProvider).Once()
+
+	uc := newCampaignUsecase(repo, email, 2)
+	assert.ErrorIs(t, uc.SendCampaign(context.Background(), payload), errProvider)
+
+	// The retry starts from chunk 1
+	resumed := campaign()
+	resumed.NextChunk = 1
+	resumed.Sent = 2
+	repo.On("GetByID", mock.Anything, 5).Return(resumed, nil).Once()
+	repo.On("RecordChunk", mock.Anything, 5, mock.MatchedBy(func(c entity.NotificationChunk) bool { return c.Index == 1 })).Return(nil).Once()
+	repo.On("RecordChunk", mock.Anything, 5, mock.MatchedBy(func(c entity.NotificationChunk) bool {
+		return c.Index == 2 && c.Sent == 0 && c.Failed == 1
+	})).Return(nil).Once()
+	repo.On("Complete", mock.Anything, mock.MatchedBy(func(n *entity.Notification) bool {
+		return n.Status == entity.NotificationStatusCompleted && n.Sent == 4 && n.Failed == 1
+	})).Return(nil).Once()
+	email.On("SendBulkEmail", mock.Anything, sentTo("c@example.com", "d@example.com")).Return(&entity.BulkEmailResponse{SentEmails: 2}, nil).Once()
+	email.On("SendBulkEmail", mock.Anything, sentTo("e@example.com")).Return(&entity.BulkEmailResponse{FailedEmails: 1}, nil).Once()
+
+	assert.NoError(t, uc.SendCampaign(context.Background(), payload))
+	repo.AssertExpectations(t)
+	email.AssertExpectations(t)
+}
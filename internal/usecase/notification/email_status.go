@@ -0,0 +1,105 @@
+package notification
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Engagement events recorded in the email_engagement_total metric
+const (
+	engagementDelivered = "delivered"
+	engagementOpened    = "opened"
+	engagementClicked   = "clicked"
+)
+
+// SetStatusSync enables SyncEmailStatus for campaigns that finished sending
+// within the window.
+func (u *NotificationUsecase) SetStatusSync(window time.Duration) {
+	u.statusSyncWindow = window
+}
+
+// SyncEmailStatus reconciles the delivery, open and click status of recently
+// sent campaigns with the email provider, as a scheduled background job.
+// A chunk that fails to sync is logged and tried again on the next run.
+func (u *NotificationUsecase) SyncEmailStatus(ctx context.Context, _ json.RawMessage) error {
+	if u.notificationRepo == nil || u.emailProvider == nil || u.statusSyncWindow <= 0 {
+		return nil
+	}
+
+	// Campaigns are reconciled as they are read, so the query stays open
+	// for the whole run
+	return u.notificationRepo.IterateCompletedSince(ctx, time.Now().Add(-u.statusSyncWindow), func(notification *entity.Notification) error {
+		if !u.syncCampaignStatus(ctx, notification) {
+			return nil
+		}
+
+		writeCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+		defer cancel()
+		return u.notificationRepo.UpdateEngagement(writeCtx, notification)
+	})
+}
+
+// syncCampaignStatus updates the chunks of the campaign with their status at
+// the provider and reports whether any was checked. Clicked chunks have no
+// further status to reach and are not checked again.
+func (u *NotificationUsecase) syncCampaignStatus(ctx context.Context, notification *entity.Notification) bool {
+	checked := false
+	for i := range notification.Chunks {
+		chunk := &notification.Chunks[i]
+		if chunk.ProviderID == "" || chunk.ClickedAt != nil {
+			continue
+		}
+
+		callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationNotification)
+		status, err := u.emailProvider.GetEmailStatus(callCtx, chunk.ProviderID)
+		cancel()
+		if err != nil {
+			u.logger.ErrorLogger(ctx, err, "Failed to get email status", map[string]interface{}{
+				"notification_id": notification.ID,
+				"chunk":           chunk.Index,
+			})
+			continue
+		}
+
+		checked = true
+		chunk.Status = status.Status
+		u.recordEngagement(&chunk.DeliveredAt, status.DeliveredAt, engagementDelivered)
+		u.recordEngagement(&chunk.OpenedAt, status.OpenedAt, engagementOpened)
+		u.recordEngagement(&chunk.ClickedAt, status.ClickedAt, engagementClicked)
+	}
+	if !checked {
+		return false
+	}
+
+	for _, chunk := range notification.Chunks {
+		notification.DeliveredAt = earliest(notification.DeliveredAt, chunk.DeliveredAt)
+		notification.OpenedAt = earliest(notification.OpenedAt, chunk.OpenedAt)
+		notification.ClickedAt = earliest(notification.ClickedAt, chunk.ClickedAt)
+	}
+	now := time.Now()
+	notification.StatusCheckedAt = &now
+	return true
+}
+
+// recordEngagement stores the time an event was reported at, counting it
+// the first time it is seen.
+func (u *NotificationUsecase) recordEngagement(field **time.Time, reported *time.Time, event string) {
+	if reported == nil {
+		return
+	}
+	if *field == nil && u.metrics != nil {
+		u.metrics.RecordEmailEngagement(event)
+	}
+	*field = reported
+}
+
+// earliest returns the earlier of two optional times
+func earliest(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.Before(*a)) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNotificationUsecase_SyncEmailStatus(t *testing.T) {
+	delivered := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	opened := delivered.Add(time.Hour)
+	clicked := delivered.Add(-time.Minute)
+
+	campaign := &entity.Notification{
+		ID: 7,
+		Chunks: []entity.NotificationChunk{
+			{Index: 0, ProviderID: "bulk-1"},
+			{Index: 1, ProviderID: "bulk-2"},
+			{Index: 2, ProviderID: "bulk-3", ClickedAt: &clicked},
+			{Index: 3, ProviderID: "bulk-4"},
+		},
+	}
+
+	repo := new(MockNotificationRepository)
+	repo.On("IterateCompletedSince", mock.Anything, mock.AnythingOfType("time.Time")).Return([]*entity.Notification{campaign}, nil)
+	repo.On("UpdateEngagement", mock.Anything, campaign).Return(nil)
+	email := new(MockEmailProvider)
+	email.On("GetEmailStatus", mock.Anything, "bulk-1").Return(&entity.EmailStatus{Status: "opened", DeliveredAt: &delivered, OpenedAt: &opened}, nil)
+	email.On("GetEmailStatus", mock.Anything, "bulk-2").Return(&entity.EmailStatus{Status: "sent"}, nil)
+	email.On("GetEmailStatus", mock.Anything, "bulk-4").Return(nil, stderrors.New("provider down"))
+
+	uc := newCampaignUsecase(repo, email, 100)
+	uc.SetStatusSync(72 * time.Hour)
+
+	err := uc.SyncEmailStatus(context.Background(), nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "opened", campaign.Chunks[0].Status)
+	assert.Equal(t, &opened, campaign.Chunks[0].OpenedAt)
+	assert.Equal(t, "sent", campaign.Chunks[1].Status)
+	assert.Nil(t, campaign.Chunks[1].DeliveredAt)
+	assert.Empty(t, campaign.Chunks[3].Status)
+	assert.Equal(t, &delivered, campaign.DeliveredAt)
+	assert.Equal(t, &opened, campaign.OpenedAt)
+	assert.Equal(t, &clicked, campaign.ClickedAt)
+	assert.NotNil(t, campaign.StatusCheckedAt)
+	// Clicked chunks are final and not checked again
+	email.AssertNotCalled(t, "GetEmailStatus", mock.Anything, "bulk-3")
+	repo.AssertExpectations(t)
+	email.AssertExpectations(t)
+}
+
+func TestNotificationUsecase_SyncEmailStatus_Disabled(t *testing.T) {
+	repo := new(MockNotificationRepository)
+	uc := newCampaignUsecase(repo, new(MockEmailProvider), 100)
+
+	assert.NoError(t, uc.SyncEmailStatus(context.Background(), nil))
+	repo.AssertNotCalled(t, "IterateCompletedSince", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,160 @@
+package notification
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/timeout"
+	"boilerplate-go/pkg/workerpool"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+)
+
+// NotificationUsecase delivers emails on a bounded pool of workers, so a burst
+// of marketing email cannot hold up security or transactional notifications.
+// Bulk campaigns bypass the pool and are sent in chunks through the email provider.
+type NotificationUsecase struct {
+	notificationProvider provider.NotificationProvider
+	pool                 *workerpool.Pool
+	notificationRepo     repository.NotificationRepository
+	emailProvider        provider.EmailProvider
+	userRepo             repository.UserRepository
+	chunkSize            int
+	statusSyncWindow     time.Duration
+	jobs                 JobQueue
+	timeouts             *timeout.Policy
+	logger               *logger.Logger
+	metrics              *metrics.Metrics
+}
+
+// NewNotificationUsecase starts the delivery workers. Call Shutdown to drain them.
+func NewNotificationUsecase(notificationProvider provider.NotificationProvider, cfg config.NotificationConfig, log *logger.Logger, m *metrics.Metrics) *NotificationUsecase {
+	policy := workerpool.PolicyBlock
+	if cfg.QueuePolicy == "drop" {
+		policy = workerpool.PolicyDrop
+	}
+
+	return &NotificationUsecase{
+		notificationProvider: notificationProvider,
+		pool:                 workerpool.New(cfg.Workers, cfg.QueueSize, policy),
+		logger:               log,
+		metrics:              m,
+	}
+}
+
+// JobQueue runs background jobs with retries.
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobType string, payload interface{}) error
+}
+
+// SetCampaigns enables bulk email campaigns, sent chunkSize recipients at a time.
+func (u *NotificationUsecase) SetCampaigns(notificationRepo repository.NotificationRepository, emailProvider provider.EmailProvider, chunkSize int) {
+	u.notificationRepo = notificationRepo
+	u.emailProvider = emailProvider
+	u.chunkSize = chunkSize
+}
+
+// SetAudience lets campaigns be addressed to every active user.
+func (u *NotificationUsecase) SetAudience(userRepo repository.UserRepository) {
+	u.userRepo = userRepo
+}
+
+// SetJobQueue sends campaigns from a background job instead of during the request.
+func (u *NotificationUsecase) SetJobQueue(jobs JobQueue) {
+	u.jobs = jobs
+}
+
+// SetTimeouts bounds provider calls with per-operation deadlines.
+func (u *NotificationUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	u.timeouts = timeouts
+}
+
+// SendEmail queues the email at its priority and waits for it to be delivered.
+// When the queue is full it blocks or fails with workerpool.ErrQueueFull,
+// depending on the configured policy.
+func (u *NotificationUsecase) SendEmail(ctx context.Context, req *entity.EmailRequest) (*entity.EmailResponse, error) {
+	type result struct {
+		resp *entity.EmailResponse
+		err  error
+	}
+	done := make(chan result, 1)
+
+	priority := priorityOf(req)
+	err := u.pool.Submit(ctx, poolPriority(priority), func() {
+		defer u.reportSaturation()
+
+		// The caller gave up while the email was queued
+		if err := ctx.Err(); err != nil {
+			done <- result{err: err}
+			return
+		}
+
+		callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationNotification)
+		defer cancel()
+		resp, err := u.notificationProvider.SendEmail(callCtx, req)
+		done <- result{resp: resp, err: err}
+	})
+	u.reportSaturation()
+	if err != nil {
+		if stderrors.Is(err, workerpool.ErrQueueFull) && u.metrics != nil {
+			u.metrics.RecordNotificationDropped(string(priority))
+		}
+		return nil, fmt.Errorf("failed to queue email: %w", err)
+	}
+
+	select {
+	case r := <-done:
+		return r.resp, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Shutdown stops accepting emails and waits for queued ones to be delivered.
+func (u *NotificationUsecase) Shutdown(ctx context.Context) error {
+	return u.pool.Shutdown(ctx)
+}
+
+// reportSaturation publishes the pool load to the metrics.
+func (u *NotificationUsecase) reportSaturation() {
+	if u.metrics == nil {
+		return
+	}
+
+	stats := u.pool.Stats()
+	queued := make(map[string]int, len(stats.Queued))
+	for _, p := range []entity.NotificationPriority{
+		entity.NotificationPrioritySecurity,
+		entity.NotificationPriorityTransactional,
+		entity.NotificationPriorityMarketing,
+	} {
+		queued[string(p)] = stats.Queued[poolPriority(p)]
+	}
+	u.metrics.SetNotificationPool(stats.Busy, queued)
+}
+
+// priorityOf returns the priority of the email, defaulting to transactional.
+func priorityOf(req *entity.EmailRequest) entity.NotificationPriority {
+	switch req.Priority {
+	case entity.NotificationPrioritySecurity, entity.NotificationPriorityMarketing:
+		return req.Priority
+	default:
+		return entity.NotificationPriorityTransactional
+	}
+}
+
+func poolPriority(priority entity.NotificationPriority) workerpool.Priority {
+	switch priority {
+	case entity.NotificationPrioritySecurity:
+		return workerpool.PriorityHigh
+	case entity.NotificationPriorityMarketing:
+		return workerpool.PriorityLow
+	default:
+		return workerpool.PriorityNormal
+	}
+}
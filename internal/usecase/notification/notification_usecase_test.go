@@ -0,0 +1,143 @@
+package notification
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/workerpool"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockNotificationProvider is a mock implementation of NotificationProvider
+type MockNotificationProvider struct {
+	mock.Mock
+}
+
+func (m *MockNotificationProvider) SendEmail(ctx context.Context, req *entity.EmailRequest) (*entity.EmailResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EmailResponse), args.Error(1)
+}
+
+func (m *MockNotificationProvider) SendSMS(ctx context.Context, req *entity.SMSRequest) (*entity.SMSResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.SMSResponse), args.Error(1)
+}
+
+func (m *MockNotificationProvider) SendPushNotification(ctx context.Context, req *entity.PushNotificationRequest) (*entity.PushNotificationResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PushNotificationResponse), args.Error(1)
+}
+
+func (m *MockNotificationProvider) SendTemplatedSMS(ctx context.Context, msg *entity.TemplatedMessage) (*entity.SMSResponse, error) {
+	args := m.Called(ctx, msg)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.SMSResponse), args.Error(1)
+}
+
+func (m *MockNotificationProvider) SendTemplatedPush(ctx context.Context, msg *entity.TemplatedMessage) (*entity.PushNotificationResponse, error) {
+	args := m.Called(ctx, msg)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PushNotificationResponse), args.Error(1)
+}
+
+func TestNotificationUsecase_SendEmail(t *testing.T) {
+	req := &entity.EmailRequest{To: []string{"user@example.com"}, Subject: "Receipt"}
+	provider := new(MockNotificationProvider)
+	provider.On("SendEmail", mock.Anything, req).Return(&entity.EmailResponse{ID: "email_1"}, nil)
+
+	uc := NewNotificationUsecase(provider, config.NotificationConfig{Workers: 2, QueueSize: 10, QueuePolicy: "block"}, logger.NewLogger(), nil)
+	resp, err := uc.SendEmail(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "email_1", resp.ID)
+	assert.NoError(t, uc.Shutdown(context.Background()))
+	provider.AssertExpectations(t)
+}
+
+func TestNotificationUsecase_SecurityOutranksMarketing(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var mu sync.Mutex
+	var sent []string
+
+	provider := new(MockNotificationProvider)
+	provider.On("SendEmail", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		req := args.Get(1).(*entity.EmailRequest)
+		if req.Subject == "blocker" {
+			close(started)
+			<-release
+			return
+		}
+		mu.Lock()
+		sent = append(sent, req.Subject)
+		mu.Unlock()
+	}).Return(&entity.EmailResponse{}, nil)
+
+	uc := NewNotificationUsecase(provider, config.NotificationConfig{Workers: 1, QueueSize: 10, QueuePolicy: "block"}, logger.NewLogger(), nil)
+
+	var wg sync.WaitGroup
+	send := func(subject string, priority entity.NotificationPriority) {
+		defer wg.Done()
+		_, err := uc.SendEmail(context.Background(), &entity.EmailRequest{Subject: subject, Priority: priority})
+		assert.NoError(t, err)
+	}
+
+	wg.Add(1)
+	go send("blocker", entity.NotificationPriorityTransactional)
+	<-started
+
+	wg.Add(1)
+	go send("newsletter", entity.NotificationPriorityMarketing)
+	assert.Eventually(t, func() bool { return uc.pool.Stats().Queued[workerpool.PriorityLow] == 1 }, time.Second, time.Millisecond)
+	wg.Add(1)
+	go send("password reset", entity.NotificationPrioritySecurity)
+	assert.Eventually(t, func() bool { return uc.pool.Stats().Queued[workerpool.PriorityHigh] == 1 }, time.Second, time.Millisecond)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []string{"password reset", "newsletter"}, sent)
+}
+
+func TestNotificationUsecase_DropWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	provider := new(MockNotificationProvider)
+	provider.On("SendEmail", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		close(started)
+		<-release
+	}).Return(&entity.EmailResponse{}, nil).Once()
+	provider.On("SendEmail", mock.Anything, mock.Anything).Return(&entity.EmailResponse{}, nil)
+
+	uc := NewNotificationUsecase(provider, config.NotificationConfig{Workers: 1, QueueSize: 1, QueuePolicy: "drop"}, logger.NewLogger(), nil)
+
+	go func() { _, _ = uc.SendEmail(context.Background(), &entity.EmailRequest{Subject: "first"}) }()
+	<-started
+	go func() { _, _ = uc.SendEmail(context.Background(), &entity.EmailRequest{Subject: "queued"}) }()
+	assert.Eventually(t, func() bool { return uc.pool.Stats().Queued[workerpool.PriorityNormal] == 1 }, time.Second, time.Millisecond)
+
+	_, err := uc.SendEmail(context.Background(), &entity.EmailRequest{Subject: "dropped"})
+	assert.ErrorIs(t, err, workerpool.ErrQueueFull)
+
+	close(release)
+	assert.NoError(t, uc.Shutdown(context.Background()))
+}
@@ -0,0 +1,70 @@
+package order
+
+import (
+	"context"
+	"fmt"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+)
+
+// dryRunOrder reports what processing the validated and priced order would
+// do. Nothing is persisted, the coupon is not redeemed, the payment goes
+// through the dry-run provider and no notification is sent.
+func (u *OrderUsecase) dryRunOrder(ctx context.Context, order *entity.Order, user *entity.User, subtotal float64, assessment *entity.FraudAssessment) (*entity.OrderResponse, error) {
+	// A reused order ID would be rejected when the order is created
+	readCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	_, err := u.orderRepo.GetByOrderID(readCtx, order.OrderID)
+	cancel()
+	if err == nil {
+		return nil, errors.ErrOrderAlreadyExists
+	}
+	if !errors.IsOrderNotFound(err) {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	dryRun := &entity.OrderDryRun{
+		Subtotal:         subtotal,
+		DiscountAmount:   order.DiscountAmount,
+		Total:            order.Amount,
+		FlaggedForReview: order.FlaggedForReview,
+		ProviderCalls:    []entity.SimulatedCall{},
+	}
+	if assessment != nil {
+		dryRun.RiskScore = &assessment.Score
+	}
+
+	if u.dryRunPayments != nil {
+		intentReq := paymentIntentRequest(order, user)
+		intent, err := u.dryRunPayments.CreatePaymentIntent(ctx, intentReq)
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate payment intent: %w", err)
+		}
+		order.PaymentIntentID = intent.ID
+		dryRun.ProviderCalls = append(dryRun.ProviderCalls, entity.SimulatedCall{
+			Provider: "payment", Operation: "create_payment_intent", Request: intentReq, Response: intent,
+		})
+
+		paymentReq := paymentRequest(order, user)
+		payment, err := u.dryRunPayments.ProcessPayment(ctx, paymentReq)
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate payment: %w", err)
+		}
+		order.PaymentID = payment.ID
+		dryRun.ProviderCalls = append(dryRun.ProviderCalls, entity.SimulatedCall{
+			Provider: "payment", Operation: "process_payment", Request: paymentReq, Response: payment,
+		})
+	}
+	order.Status = entity.OrderStatusCompleted
+
+	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id":  user.ID,
+		"order_id": order.OrderID,
+		"amount":   order.Amount,
+	}).Info("Order dry run completed")
+
+	resp := orderResponse(order, user)
+	resp.DryRun = dryRun
+	return resp, nil
+}
@@ -0,0 +1,241 @@
+package order
+
+import (
+	"context"
+	"fmt"
+
+	"boilerplate-go/infrastructure/httpclient"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/saga"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+)
+
+// orderSagaName names the saga of placing an order
+const orderSagaName = "process_order"
+
+// State keys of the order saga
+const (
+	sagaOrderID   = "order_id"
+	sagaIntentID  = "payment_intent_id"
+	sagaPaymentID = "payment_id"
+)
+
+// SetSagas persists the progress of placing orders through the orchestrator,
+// so orders interrupted by a crash are compensated when it recovers.
+func (u *OrderUsecase) SetSagas(sagas *saga.Orchestrator) {
+	u.sagas = sagas
+	sagas.Register(u.orderSaga(nil))
+}
+
+// placement is an order being placed by ProcessOrder
+type placement struct {
+	u        *OrderUsecase
+	order    *entity.Order
+	user     *entity.User
+	coupon   *entity.Coupon
+	discount float64
+	trace    *httpclient.Trace
+}
+
+// orderSaga defines the steps of placing an order and their compensations:
+// the order is failed, its coupon use released, its payment intent voided and
+// its payment refunded. Compensations only read the saga state, so they also
+// undo placements interrupted by a crash. Without a placement the steps have
+// no actions, as registered for recovery.
+func (u *OrderUsecase) orderSaga(p *placement) *saga.Definition {
+	step := func(name string, action func(*placement, context.Context, entity.SagaState) error, compensate saga.Func) saga.Step {
+		s := saga.Step{Name: name, Compensate: compensate}
+		if p != nil {
+			s.Action = func(ctx context.Context, state entity.SagaState) error {
+				return action(p, ctx, state)
+			}
+		}
+		return s
+	}
+
+	return &saga.Definition{
+		Name: orderSagaName,
+		Steps: []saga.Step{
+			step("create_order", (*placement).createOrder, u.failSagaOrder),
+			step("redeem_coupon", (*placement).redeemCoupon, u.releaseSagaCoupon),
+			step("create_payment_intent", (*placement).createPaymentIntent, u.voidSagaPaymentIntent),
+			step("claim_order", (*placement).claimOrder, nil),
+			step("capture_payment", (*placement).capturePayment, u.refundSagaPayment),
+			step("complete_order", (*placement).completeOrder, nil),
+		},
+		Completed: u.sagaOrderCompleted,
+	}
+}
+
+// createOrder persists the order first, so a reused order ID is rejected
+// before any payment is set up
+func (p *placement) createOrder(ctx context.Context, state entity.SagaState) error {
+	writeCtx, cancel := p.u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	err := p.u.orderRepo.Create(writeCtx, p.order)
+	cancel()
+	if err != nil {
+		if errors.IsOrderAlreadyExists(err) {
+			return err
+		}
+		return fmt.Errorf("failed to create order: %w", err)
+	}
+	state[sagaOrderID] = p.order.OrderID
+	return nil
+}
+
+// redeemCoupon reserves the use of the coupon before charging
+func (p *placement) redeemCoupon(ctx context.Context, state entity.SagaState) error {
+	if p.coupon == nil {
+		return nil
+	}
+	writeCtx, cancel := p.u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	return p.u.couponRepo.Redeem(writeCtx, &entity.CouponRedemption{
+		CouponID:       p.coupon.ID,
+		UserID:         p.user.ID,
+		OrderID:        p.order.ID,
+		DiscountAmount: p.discount,
+	})
+}
+
+// createPaymentIntent sets up the payment, halting the saga to defer it while
+// the provider is unavailable
+func (p *placement) createPaymentIntent(ctx context.Context, state entity.SagaState) error {
+	callCtx, cancel := p.u.timeouts.WithTimeout(ctx, timeout.OperationPayment)
+	paymentIntent, err := p.u.paymentProvider.CreatePaymentIntent(callCtx, paymentIntentRequest(p.order, p.user))
+	cancel()
+	if err != nil {
+		if p.u.canDeferPayment(err) {
+			return fmt.Errorf("%w: %w", saga.ErrHalted, err)
+		}
+
+		p.u.logger.ErrorLogger(ctx, err, "Failed to create payment intent", map[string]interface{}{
+			"user_id":            p.user.ID,
+			"amount":             p.order.Amount,
+			"provider_exchanges": p.trace.Exchanges(),
+		})
+		return fmt.Errorf("failed to create payment intent: %w", err)
+	}
+	p.order.PaymentIntentID = paymentIntent.ID
+	state[sagaIntentID] = paymentIntent.ID
+	return nil
+}
+
+// claimOrder moves the order to processing. The order may have been cancelled
+// while the intent was created.
+func (p *placement) claimOrder(ctx context.Context, state entity.SagaState) error {
+	return p.u.transitionOrder(ctx, p.order, entity.OrderStatusProcessing)
+}
+
+// capturePayment charges the order, halting the saga to defer the payment
+// while the provider is unavailable
+func (p *placement) capturePayment(ctx context.Context, state entity.SagaState) error {
+	callCtx, cancel := p.u.timeouts.WithTimeout(ctx, timeout.OperationPayment)
+	payment, err := p.u.paymentProvider.ProcessPayment(callCtx, paymentRequest(p.order, p.user))
+	cancel()
+	if err != nil {
+		if p.u.canDeferPayment(err) {
+			return fmt.Errorf("%w: %w", saga.ErrHalted, err)
+		}
+
+		p.u.logger.ErrorLogger(ctx, err, "Payment processing failed", map[string]interface{}{
+			"user_id":            p.user.ID,
+			"order_id":           p.order.OrderID,
+			"provider_exchanges": p.trace.Exchanges(),
+		})
+
+		// Send failure notification
+		go p.u.sendPaymentFailureNotification(context.Background(), p.user, p.order.OrderID, err)
+
+		return fmt.Errorf("payment processing failed: %w", err)
+	}
+	p.order.PaymentID = payment.ID
+	state[sagaPaymentID] = payment.ID
+	return nil
+}
+
+// completeOrder records the payment on the order
+func (p *placement) completeOrder(ctx context.Context, state entity.SagaState) error {
+	if err := p.u.transitionOrder(ctx, p.order, entity.OrderStatusCompleted); err != nil {
+		return fmt.Errorf("failed to complete order: %w", err)
+	}
+	return nil
+}
+
+// sagaOrder returns the order of the saga state
+func (u *OrderUsecase) sagaOrder(ctx context.Context, state entity.SagaState) (*entity.Order, error) {
+	readCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+	return u.orderRepo.GetByOrderID(readCtx, state[sagaOrderID])
+}
+
+// failSagaOrder fails the order, unless it was cancelled meanwhile, which
+// undoes it already
+func (u *OrderUsecase) failSagaOrder(ctx context.Context, state entity.SagaState) error {
+	order, err := u.sagaOrder(ctx, state)
+	if err != nil {
+		if errors.IsOrderNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !order.CanTransitionTo(entity.OrderStatusFailed) {
+		return nil
+	}
+	return u.transitionOrder(ctx, order, entity.OrderStatusFailed)
+}
+
+// releaseSagaCoupon returns the coupon use of the order
+func (u *OrderUsecase) releaseSagaCoupon(ctx context.Context, state entity.SagaState) error {
+	order, err := u.sagaOrder(ctx, state)
+	if err != nil {
+		return err
+	}
+	if order.CouponCode == "" {
+		return nil
+	}
+	writeCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	return u.couponRepo.ReleaseByOrderID(writeCtx, order.ID)
+}
+
+// voidSagaPaymentIntent cancels the payment intent, unless it was captured,
+// which the refund undoes instead
+func (u *OrderUsecase) voidSagaPaymentIntent(ctx context.Context, state entity.SagaState) error {
+	if state[sagaIntentID] == "" || state[sagaPaymentID] != "" {
+		return nil
+	}
+	callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationPayment)
+	defer cancel()
+	if _, err := u.paymentProvider.CancelPaymentIntent(callCtx, state[sagaIntentID]); err != nil {
+		return fmt.Errorf("failed to void payment intent: %w", err)
+	}
+	return nil
+}
+
+// refundSagaPayment refunds the captured payment
+func (u *OrderUsecase) refundSagaPayment(ctx context.Context, state entity.SagaState) error {
+	callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationPayment)
+	defer cancel()
+	if _, err := u.paymentProvider.RefundPayment(callCtx, state[sagaPaymentID]); err != nil {
+		return fmt.Errorf("failed to refund payment: %w", err)
+	}
+	return nil
+}
+
+// sagaOrderCompleted reports whether the order recorded the payment of the
+// saga, in which case the placement completed
+func (u *OrderUsecase) sagaOrderCompleted(ctx context.Context, state entity.SagaState) (bool, error) {
+	if state[sagaPaymentID] == "" {
+		return false, nil
+	}
+	order, err := u.sagaOrder(ctx, state)
+	if err != nil {
+		if errors.IsOrderNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return order.PaymentID == state[sagaPaymentID], nil
+}
@@ -0,0 +1,137 @@
+package order
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"testing"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// paymentStub charges payments unless chargeErr is set, recording the voided
+// intents and refunded payments and counting the status lookups
+type paymentStub struct {
+	mu        sync.Mutex
+	chargeErr error
+	voided    []string
+	refunded  []string
+	lookups   int
+}
+
+func (p *paymentStub) ProcessPayment(ctx context.Context, req *entity.PaymentRequest) (*entity.PaymentResponse, error) {
+	if p.chargeErr != nil {
+		return nil, p.chargeErr
+	}
+	return &entity.PaymentResponse{ID: "pay_1", Status: "succeeded", Amount: req.Amount}, nil
+}
+
+func (p *paymentStub) RefundPayment(ctx context.Context, paymentID string) (*entity.RefundResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.refunded = append(p.refunded, paymentID)
+	return &entity.RefundResponse{ID: "re_1", PaymentID: paymentID}, nil
+}
+
+func (p *paymentStub) GetPaymentStatus(ctx context.Context, paymentID string) (*entity.PaymentStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lookups++
+	return &entity.PaymentStatus{ID: paymentID, Status: "succeeded"}, nil
+}
+
+func (p *paymentStub) CreatePaymentIntent(ctx context.Context, req *entity.PaymentIntentRequest) (*entity.PaymentIntent, error) {
+	return &entity.PaymentIntent{ID: "pi_1"}, nil
+}
+
+func (p *paymentStub) CancelPaymentIntent(ctx context.Context, intentID string) (*entity.PaymentIntent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.voided = append(p.voided, intentID)
+	return &entity.PaymentIntent{ID: intentID, Status: "canceled"}, nil
+}
+
+// jobStub accepts every job
+type jobStub struct{}
+
+func (jobStub) Enqueue(ctx context.Context, jobType string, payload interface{}) error {
+	return nil
+}
+
+func TestOrderUsecase_ProcessOrder_Compensation(t *testing.T) {
+	ctx := context.Background()
+	req := func() *entity.CreateOrderRequest {
+		return &entity.CreateOrderRequest{
+			UserID:   1,
+			OrderID:  "ord_1",
+			Currency: "USD",
+			Items:    []entity.OrderItem{{ProductID: "sku_1", Quantity: 1, UnitPrice: 10}},
+		}
+	}
+	setup := func(payments *paymentStub) (*OrderUsecase, *MockOrderRepository) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetByID", mock.Anything, 1).Return(&entity.User{ID: 1, Email: "user@example.com"}, nil)
+		orderRepo := new(MockOrderRepository)
+		orderRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("UpdateStatus", mock.Anything, mock.Anything, entity.OrderStatusPending).Return(nil)
+		uc := NewOrderUsecase(userRepo, orderRepo, nil, payments, nil, logger.NewLogger())
+		uc.SetJobQueue(jobStub{})
+		return uc, orderRepo
+	}
+	failed := func(order *entity.Order) bool { return order.Status == entity.OrderStatusFailed }
+
+	t.Run("a declined payment voids the intent and fails the order", func(t *testing.T) {
+		payments := &paymentStub{chargeErr: stderrors.New("card declined")}
+		uc, orderRepo := setup(payments)
+		orderRepo.On("GetByOrderID", mock.Anything, "ord_1").
+			Return(&entity.Order{OrderID: "ord_1", Status: entity.OrderStatusProcessing}, nil)
+		orderRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(failed), entity.OrderStatusProcessing).Return(nil).Once()
+
+		_, err := uc.ProcessOrder(ctx, req())
+		require.Error(t, err)
+		assert.Equal(t, []string{"pi_1"}, payments.voided)
+		assert.Empty(t, payments.refunded)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("a payment the order cannot record is refunded", func(t *testing.T) {
+		payments := &paymentStub{}
+		uc, orderRepo := setup(payments)
+		completed := func(order *entity.Order) bool { return order.Status == entity.OrderStatusCompleted }
+		orderRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(completed), entity.OrderStatusProcessing).
+			Return(stderrors.New("connection reset")).Once()
+		orderRepo.On("GetByOrderID", mock.Anything, "ord_1").
+			Return(&entity.Order{OrderID: "ord_1", Status: entity.OrderStatusProcessing}, nil)
+		orderRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(failed), entity.OrderStatusProcessing).Return(nil).Once()
+
+		_, err := uc.ProcessOrder(ctx, req())
+		assert.ErrorContains(t, err, "failed to complete order")
+		assert.Equal(t, []string{"pay_1"}, payments.refunded)
+		assert.Empty(t, payments.voided)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("an order cancelled during payment setup has its intent voided", func(t *testing.T) {
+		payments := &paymentStub{}
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetByID", mock.Anything, 1).Return(&entity.User{ID: 1}, nil)
+		orderRepo := new(MockOrderRepository)
+		orderRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("UpdateStatus", mock.Anything, mock.Anything, entity.OrderStatusPending).
+			Return(errors.ErrInvalidOrderState).Once()
+		orderRepo.On("GetByOrderID", mock.Anything, "ord_1").
+			Return(&entity.Order{OrderID: "ord_1", Status: entity.OrderStatusCancelled}, nil)
+		uc := NewOrderUsecase(userRepo, orderRepo, nil, payments, nil, logger.NewLogger())
+
+		_, err := uc.ProcessOrder(ctx, req())
+		require.Error(t, err)
+		assert.Equal(t, []string{"pi_1"}, payments.voided)
+		orderRepo.AssertExpectations(t)
+	})
+}
@@ -2,46 +2,146 @@ package order
 
 import (
 	"context"
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"math"
+	"slices"
+	"strings"
 	"time"
 
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/httpclient"
 	"boilerplate-go/infrastructure/logger"
 	"boilerplate-go/internal/domain/entity"
 	"boilerplate-go/internal/domain/provider"
 	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/internal/usecase/job"
+	"boilerplate-go/internal/usecase/saga"
 	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/lock"
+	"boilerplate-go/pkg/timeout"
+	"boilerplate-go/pkg/workerpool"
+)
+
+const (
+	defaultOrderPageSize = 20
+	maxOrderPageSize     = 100
+	// exportPageSize is the page size used when reading orders for export
+	exportPageSize = 500
 )
 
 type OrderUsecase struct {
 	userRepo             repository.UserRepository
+	orderRepo            repository.OrderRepository
+	couponRepo           repository.CouponRepository
 	paymentProvider      provider.PaymentProvider
 	notificationProvider provider.NotificationProvider
+	fraudProvider        provider.FraudProvider
+	geoProvider          provider.GeolocationProvider
+	fraudConfig          config.FraudConfig
+	jobs                 JobQueue
+	deferredPayments     bool
+	currencies           []string
+	webhookEvents        repository.WebhookEventRepository
+	eventBuffer          repository.EventBufferRepository
+	webhookEventTTL      time.Duration
+	paymentStatuses      repository.PaymentStatusCacheRepository
+	paymentStatusTTL     time.Duration
+	paymentStatusWait    time.Duration
+	paymentRecheck       time.Duration
+	paymentSignals       paymentSignals
+	ordering             *workerpool.Partitioned
+	sagas                *saga.Orchestrator
+	locker               lock.Locker
+	lockOptions          lock.Options
+	dryRunPayments       provider.PaymentProvider
+	timeouts             *timeout.Policy
 	logger               *logger.Logger
 }
 
+// JobQueue runs background jobs with retries.
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobType string, payload interface{}) error
+}
+
 func NewOrderUsecase(
 	userRepo repository.UserRepository,
+	orderRepo repository.OrderRepository,
+	couponRepo repository.CouponRepository,
 	paymentProvider provider.PaymentProvider,
 	notificationProvider provider.NotificationProvider,
 	logger *logger.Logger,
 ) *OrderUsecase {
 	return &OrderUsecase{
 		userRepo:             userRepo,
+		orderRepo:            orderRepo,
+		couponRepo:           couponRepo,
 		paymentProvider:      paymentProvider,
 		notificationProvider: notificationProvider,
+		sagas:                saga.NewOrchestrator(nil, config.SagaConfig{}, logger),
 		logger:               logger,
 	}
 }
 
+// SetFraudScreening enables risk scoring of orders before payment.
+// The geolocation provider is optional and only enriches the check with the client location.
+func (u *OrderUsecase) SetFraudScreening(fraud provider.FraudProvider, geo provider.GeolocationProvider, cfg config.FraudConfig) {
+	u.fraudProvider = fraud
+	u.geoProvider = geo
+	u.fraudConfig = cfg
+}
+
+// SetJobQueue routes notification emails through the job queue so failed
+// deliveries are retried and dead-lettered instead of dropped.
+func (u *OrderUsecase) SetJobQueue(jobs JobQueue) {
+	u.jobs = jobs
+}
+
+// SetTimeouts bounds repository and provider calls with per-operation deadlines.
+func (u *OrderUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	u.timeouts = timeouts
+}
+
+// SetDryRunProvider sets the payment provider dry-run orders are charged
+// through, such as the mock provider. Without it dry runs simulate no
+// provider calls.
+func (u *OrderUsecase) SetDryRunProvider(payments provider.PaymentProvider) {
+	u.dryRunPayments = payments
+}
+
+// SetCurrencies limits orders to the currencies, ISO 4217 codes. Without
+// them any currency is accepted.
+func (u *OrderUsecase) SetCurrencies(currencies []string) {
+	u.currencies = currencies
+}
+
+// SetDeferredPayments toggles accepting orders with payment pending while the
+// payment provider is unavailable. It requires a job queue.
+func (u *OrderUsecase) SetDeferredPayments(enabled bool) {
+	u.deferredPayments = enabled
+}
+
 func (u *OrderUsecase) ProcessOrder(ctx context.Context, req *entity.CreateOrderRequest) (*entity.OrderResponse, error) {
+	subtotal := entity.OrderTotal(req.Items)
+	if subtotal <= 0 {
+		return nil, fmt.Errorf("%w: order total must be greater than zero", errors.ErrInvalidOrder)
+	}
+	if len(u.currencies) > 0 && !slices.ContainsFunc(u.currencies, func(c string) bool { return strings.EqualFold(c, req.Currency) }) {
+		return nil, fmt.Errorf("%w: currency %s is not supported", errors.ErrInvalidOrder, req.Currency)
+	}
+
 	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
 		"user_id":   req.UserID,
 		"amount":    req.Amount,
+		"dry_run":   req.DryRun,
 		"operation": "process_order",
 	}).Info("Processing order")
 
 	// 1. Validate user exists
-	user, err := u.userRepo.GetByID(ctx, req.UserID)
+	callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := u.userRepo.GetByID(callCtx, req.UserID)
+	cancel()
 	if err != nil {
 		if errors.IsUserNotFound(err) {
 			return nil, fmt.Errorf("user not found: %w", err)
@@ -49,92 +149,482 @@ func (u *OrderUsecase) ProcessOrder(ctx context.Context, req *entity.CreateOrder
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	// 2. Create payment intent
-	paymentIntentReq := &entity.PaymentIntentRequest{
-		Amount:      req.Amount,
-		Currency:    req.Currency,
-		CustomerID:  fmt.Sprintf("%d", user.ID),
-		Description: fmt.Sprintf("Order for user %s", user.Username),
+	// 2. Apply coupon and compute the total
+	var (
+		coupon   *entity.Coupon
+		discount float64
+	)
+	if req.CouponCode != "" {
+		coupon, discount, err = u.applyCoupon(ctx, req.CouponCode, user.ID, subtotal, req.Currency)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	total := math.Round((subtotal-discount)*100) / 100
+	if total <= 0 {
+		return nil, fmt.Errorf("%w: order total must be greater than zero after discounts", errors.ErrInvalidOrder)
+	}
+	if req.Amount != 0 && req.Amount != total {
+		return nil, fmt.Errorf("%w: amount %.2f does not match order total %.2f", errors.ErrInvalidOrder, req.Amount, total)
+	}
+	req.Amount = total
+
+	// 3. Screen the order for fraud
+	assessment, err := u.screenOrder(ctx, req, user)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Build the order
+	order := &entity.Order{
+		OrderID:        req.OrderID,
+		UserID:         user.ID,
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		Status:         entity.OrderStatusPending,
+		DiscountAmount: discount,
+		Items:          req.Items,
+	}
+	if org := entity.OrganizationFromContext(ctx); org != nil {
+		order.OrganizationID = &org.ID
+	}
+	if assessment != nil {
+		order.RiskScore = &assessment.Score
+		order.FlaggedForReview = assessment.Score >= u.fraudConfig.ReviewThreshold
+	}
+	if coupon != nil {
+		order.CouponCode = coupon.Code
+	}
+	if req.DryRun {
+		return u.dryRunOrder(ctx, order, user, subtotal, assessment)
+	}
+
+	// 5. Persist the order, redeem its coupon and charge it, undoing the steps
+	// taken when one fails. Provider exchanges are traced so a failed payment
+	// can be debugged from its log
+	sagaCtx, trace := httpclient.WithTrace(ctx)
+	p := &placement{u: u, order: order, user: user, coupon: coupon, discount: discount, trace: trace}
+	if err := u.sagas.Run(sagaCtx, u.orderSaga(p), order.OrderID, entity.SagaState{}); err != nil {
+		// Payment is deferred while the provider is unavailable
+		if stderrors.Is(err, saga.ErrHalted) {
+			u.updateOrderStatus(ctx, order, entity.OrderStatusPaymentPending)
+			return u.deferPayment(ctx, order, user)
+		}
+		return nil, err
+	}
+	u.applyBufferedEventsInOrder(ctx, order)
+
+	// 6. Send success notification
+	go u.sendOrderConfirmationNotification(context.Background(), user, req.OrderID, order.PaymentID, req.Amount)
+
+	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id":    req.UserID,
+		"order_id":   req.OrderID,
+		"payment_id": order.PaymentID,
+		"amount":     req.Amount,
+	}).Info("Order processed successfully")
+
+	// 7. Return order response
+	return orderResponse(order, user), nil
+}
+
+// canDeferPayment reports whether a payment provider error should put the
+// order in payment pending mode instead of failing it.
+func (u *OrderUsecase) canDeferPayment(err error) bool {
+	return u.deferredPayments && u.jobs != nil && errors.IsProviderUnavailable(err)
+}
+
+// deferPayment queues the payment of an accepted order for when the payment
+// provider is available again.
+func (u *OrderUsecase) deferPayment(ctx context.Context, order *entity.Order, user *entity.User) (*entity.OrderResponse, error) {
+	if err := u.jobs.Enqueue(ctx, entity.JobTypeDeferredPayment, entity.DeferredPayment{OrderID: order.OrderID}); err != nil {
+		u.updateOrderStatus(ctx, order, entity.OrderStatusFailed)
+		u.releaseCoupon(ctx, order)
+		return nil, fmt.Errorf("failed to defer payment: %w", err)
+	}
+
+	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id":  user.ID,
+		"order_id": order.OrderID,
+		"amount":   order.Amount,
+	}).Warn("Payment provider unavailable, order accepted with payment pending")
+
+	return orderResponse(order, user), nil
+}
+
+// ProcessDeferredPayment charges an order accepted while the payment provider
+// was unavailable. It runs as a background job: provider outages are returned
+// so the job is retried, while payment failures fail the order as they would
+// have synchronously.
+func (u *OrderUsecase) ProcessDeferredPayment(ctx context.Context, payload json.RawMessage) error {
+	var deferred entity.DeferredPayment
+	if err := json.Unmarshal(payload, &deferred); err != nil {
+		return fmt.Errorf("%w: invalid deferred payment payload: %v", job.ErrPermanent, err)
+	}
+
+	return u.inOrder(ctx, deferred.OrderID, func(ctx context.Context) error {
+		return u.processDeferredPayment(ctx, deferred.OrderID)
+	})
+}
+
+// processDeferredPayment charges the order in its turn
+func (u *OrderUsecase) processDeferredPayment(ctx context.Context, orderID string) error {
+	callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	order, err := u.orderRepo.GetByOrderID(callCtx, orderID)
+	cancel()
+	if err != nil {
+		if errors.IsOrderNotFound(err) {
+			return fmt.Errorf("%w: %v", job.ErrPermanent, err)
+		}
+		return err
+	}
+
+	// The order was cancelled or already charged in the meantime
+	if order.Status != entity.OrderStatusPaymentPending {
+		return nil
+	}
+
+	callCtx, cancel = u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := u.userRepo.GetByID(callCtx, order.UserID)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Claim the order so a concurrent cancellation cannot slip in before the charge
+	if err := u.transitionOrder(ctx, order, entity.OrderStatusProcessing); err != nil {
+		if errors.IsInvalidOrderState(err) {
+			return nil
+		}
+		return err
+	}
+
+	paymentCtx, trace := httpclient.WithTrace(ctx)
+	if order.PaymentIntentID == "" {
+		callCtx, cancel := u.timeouts.WithTimeout(paymentCtx, timeout.OperationPayment)
+		paymentIntent, err := u.paymentProvider.CreatePaymentIntent(callCtx, paymentIntentRequest(order, user))
+		cancel()
+		if err != nil {
+			u.updateOrderStatus(ctx, order, entity.OrderStatusPaymentPending)
+			return fmt.Errorf("failed to create payment intent: %w", err)
+		}
+		order.PaymentIntentID = paymentIntent.ID
+	}
+
+	callCtx, cancel = u.timeouts.WithTimeout(paymentCtx, timeout.OperationPayment)
+	payment, err := u.paymentProvider.ProcessPayment(callCtx, paymentRequest(order, user))
+	cancel()
+	if err != nil {
+		if errors.IsProviderUnavailable(err) {
+			u.updateOrderStatus(ctx, order, entity.OrderStatusPaymentPending)
+			return err
+		}
+
+		u.logger.ErrorLogger(ctx, err, "Deferred payment failed", map[string]interface{}{
+			"user_id":            user.ID,
+			"order_id":           order.OrderID,
+			"provider_exchanges": trace.Exchanges(),
+		})
+
+		u.updateOrderStatus(ctx, order, entity.OrderStatusFailed)
+		u.releaseCoupon(ctx, order)
+		u.sendPaymentFailureNotification(ctx, user, order.OrderID, err)
+		return nil
+	}
+
+	order.PaymentID = payment.ID
+	u.updateOrderStatus(ctx, order, entity.OrderStatusCompleted)
+	u.applyBufferedEvents(ctx, order)
+	u.sendOrderConfirmationNotification(ctx, user, order.OrderID, payment.ID, order.Amount)
+
+	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id":    user.ID,
+		"order_id":   order.OrderID,
+		"payment_id": payment.ID,
+		"amount":     order.Amount,
+	}).Info("Deferred payment processed successfully")
+
+	return nil
+}
+
+// FailDeferredPayment gives up on an order whose deferred payment job was
+// dead-lettered. The order is failed, its coupon use released and the customer
+// notified, instead of leaving it payment pending indefinitely.
+func (u *OrderUsecase) FailDeferredPayment(ctx context.Context, payload json.RawMessage, jobErr error) {
+	var deferred entity.DeferredPayment
+	if err := json.Unmarshal(payload, &deferred); err != nil {
+		u.logger.ErrorLogger(ctx, err, "Invalid dead-lettered deferred payment payload", nil)
+		return
 	}
 
-	paymentIntent, err := u.paymentProvider.CreatePaymentIntent(ctx, paymentIntentReq)
+	readCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	order, err := u.orderRepo.GetByOrderID(readCtx, deferred.OrderID)
+	cancel()
 	if err != nil {
-		u.logger.ErrorLogger(ctx, err, "Failed to create payment intent", map[string]interface{}{
-			"user_id": req.UserID,
-			"amount":  req.Amount,
+		u.logger.ErrorLogger(ctx, err, "Failed to get order of dead-lettered deferred payment", map[string]interface{}{
+			"order_id": deferred.OrderID,
+		})
+		return
+	}
+	if order.Status != entity.OrderStatusPaymentPending {
+		return
+	}
+
+	if err := u.transitionOrder(ctx, order, entity.OrderStatusFailed); err != nil {
+		u.logger.ErrorLogger(ctx, err, "Failed to fail order of dead-lettered deferred payment", map[string]interface{}{
+			"order_id": order.OrderID,
 		})
-		return nil, fmt.Errorf("failed to create payment intent: %w", err)
+		return
+	}
+	u.releaseCoupon(ctx, order)
+
+	u.logger.ErrorLogger(ctx, jobErr, "Deferred payment abandoned, order failed", map[string]interface{}{
+		"order_id": order.OrderID,
+		"user_id":  order.UserID,
+	})
+
+	readCtx, cancel = u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := u.userRepo.GetByID(readCtx, order.UserID)
+	cancel()
+	if err != nil {
+		return
+	}
+	u.sendPaymentFailureNotification(ctx, user, order.OrderID, jobErr)
+}
+
+func paymentIntentRequest(order *entity.Order, user *entity.User) *entity.PaymentIntentRequest {
+	return &entity.PaymentIntentRequest{
+		Amount:      order.Amount,
+		Currency:    order.Currency,
+		CustomerID:  fmt.Sprintf("%d", user.ID),
+		Description: fmt.Sprintf("Order for user %s", user.Username),
 	}
+}
 
-	// 3. Process payment
-	paymentReq := &entity.PaymentRequest{
-		OrderID:     req.OrderID,
-		Amount:      req.Amount,
-		Currency:    req.Currency,
-		Description: fmt.Sprintf("Order %s for %s", req.OrderID, user.Username),
+func paymentRequest(order *entity.Order, user *entity.User) *entity.PaymentRequest {
+	return &entity.PaymentRequest{
+		OrderID:     order.OrderID,
+		Amount:      order.Amount,
+		Currency:    order.Currency,
+		Description: fmt.Sprintf("Order %s for %s", order.OrderID, user.Username),
 		CustomerID:  fmt.Sprintf("%d", user.ID),
+		Items:       lineItems(order.Items),
+		Discount:    order.DiscountAmount,
 		Metadata: map[string]interface{}{
 			"user_id":  user.ID,
 			"username": user.Username,
-			"order_id": req.OrderID,
+			"order_id": order.OrderID,
 		},
 	}
+}
+
+func orderResponse(order *entity.Order, user *entity.User) *entity.OrderResponse {
+	return &entity.OrderResponse{
+		OrderID:         order.OrderID,
+		PaymentID:       order.PaymentID,
+		PaymentIntentID: order.PaymentIntentID,
+		Status:          order.Status,
+		Amount:          order.Amount,
+		Currency:        order.Currency,
+		CouponCode:      order.CouponCode,
+		DiscountAmount:  order.DiscountAmount,
+		Items:           order.Items,
+		ProcessedAt:     time.Now(),
+		User:            user,
+	}
+}
 
-	payment, err := u.paymentProvider.ProcessPayment(ctx, paymentReq)
+// applyCoupon validates the coupon for the order and returns it with the discount.
+func (u *OrderUsecase) applyCoupon(ctx context.Context, code string, userID int, subtotal float64, currency string) (*entity.Coupon, float64, error) {
+	ctx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	coupon, err := u.couponRepo.GetByCode(ctx, entity.NormalizeCouponCode(code))
 	if err != nil {
-		u.logger.ErrorLogger(ctx, err, "Payment processing failed", map[string]interface{}{
-			"user_id":  req.UserID,
-			"order_id": req.OrderID,
+		if errors.IsCouponNotFound(err) {
+			return nil, 0, fmt.Errorf("%w: unknown code", errors.ErrCouponInvalid)
+		}
+		return nil, 0, fmt.Errorf("failed to get coupon: %w", err)
+	}
+
+	switch {
+	case !coupon.Active:
+		return nil, 0, fmt.Errorf("%w: coupon is inactive", errors.ErrCouponInvalid)
+	case coupon.Expired(time.Now()):
+		return nil, 0, fmt.Errorf("%w: coupon has expired", errors.ErrCouponInvalid)
+	case coupon.Exhausted():
+		return nil, 0, fmt.Errorf("%w: redemption limit reached", errors.ErrCouponInvalid)
+	case coupon.Type == entity.CouponTypeFixed && coupon.Currency != "" && !strings.EqualFold(coupon.Currency, currency):
+		return nil, 0, fmt.Errorf("%w: coupon is only valid for %s orders", errors.ErrCouponInvalid, coupon.Currency)
+	case subtotal < coupon.MinOrderAmount:
+		return nil, 0, fmt.Errorf("%w: order total must be at least %.2f", errors.ErrCouponInvalid, coupon.MinOrderAmount)
+	}
+
+	if coupon.PerUserLimit != nil {
+		used, err := u.couponRepo.CountRedemptionsByUser(ctx, coupon.ID, userID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to check coupon usage: %w", err)
+		}
+		if used >= *coupon.PerUserLimit {
+			return nil, 0, fmt.Errorf("%w: per-user limit reached", errors.ErrCouponInvalid)
+		}
+	}
+
+	return coupon, coupon.DiscountFor(subtotal), nil
+}
+
+// releaseCoupon returns the order's coupon use when the order does not go ahead.
+func (u *OrderUsecase) releaseCoupon(ctx context.Context, order *entity.Order) {
+	if order.CouponCode == "" {
+		return
+	}
+	writeCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := u.couponRepo.ReleaseByOrderID(writeCtx, order.ID); err != nil {
+		u.logger.ErrorLogger(ctx, err, "Failed to release coupon redemption", map[string]interface{}{
+			"order_id":    order.OrderID,
+			"coupon_code": order.CouponCode,
+		})
+	}
+}
+
+// lineItems converts order items to itemized payment line items.
+func lineItems(items []entity.OrderItem) []entity.LineItem {
+	lines := make([]entity.LineItem, 0, len(items))
+	for _, item := range items {
+		name := item.Name
+		if name == "" {
+			name = item.ProductID
+		}
+		lines = append(lines, entity.LineItem{
+			SKU:        item.ProductID,
+			Name:       name,
+			Quantity:   item.Quantity,
+			UnitAmount: item.UnitPrice,
 		})
+	}
+	return lines
+}
 
-		// Send failure notification
-		go u.sendPaymentFailureNotification(context.Background(), user, req.OrderID, err)
+// transitionOrder moves the order to status if its stored status is still the
+// one it was read with, so concurrent cancellations, refunds and payments of the
+// same order cannot all apply. The order keeps its old status on failure.
+func (u *OrderUsecase) transitionOrder(ctx context.Context, order *entity.Order, status string) error {
+	from := order.Status
+	order.Status = status
 
-		return nil, fmt.Errorf("payment processing failed: %w", err)
+	writeCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := u.orderRepo.UpdateStatus(writeCtx, order, from); err != nil {
+		order.Status = from
+		return err
 	}
+	return nil
+}
 
-	// 4. Send success notification
-	go u.sendOrderConfirmationNotification(context.Background(), user, req.OrderID, payment.ID, req.Amount)
+// updateOrderStatus records the payment outcome on the order. The payment has already
+// happened at this point, so a failed update is logged rather than failing the request.
+func (u *OrderUsecase) updateOrderStatus(ctx context.Context, order *entity.Order, status string) {
+	if err := u.transitionOrder(ctx, order, status); err != nil {
+		u.logger.ErrorLogger(ctx, err, "Failed to update order status", map[string]interface{}{
+			"order_id":   order.OrderID,
+			"status":     status,
+			"payment_id": order.PaymentID,
+		})
+	}
+}
 
-	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
-		"user_id":    req.UserID,
-		"order_id":   req.OrderID,
-		"payment_id": payment.ID,
-		"amount":     req.Amount,
-	}).Info("Order processed successfully")
+// ListOrders returns one page of the user's order history matching the filter,
+// together with the total number of matching orders. Out of range pagination
+// values on the filter are replaced with the defaults.
+func (u *OrderUsecase) ListOrders(ctx context.Context, filter *entity.OrderFilter) ([]*entity.Order, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = defaultOrderPageSize
+	}
+	if filter.PageSize > maxOrderPageSize {
+		filter.PageSize = maxOrderPageSize
+	}
 
-	// 5. Return order response
-	orderResponse := &entity.OrderResponse{
-		OrderID:         req.OrderID,
-		PaymentID:       payment.ID,
-		PaymentIntentID: paymentIntent.ID,
-		Status:          "completed",
-		Amount:          req.Amount,
-		Currency:        req.Currency,
-		ProcessedAt:     time.Now(),
-		User:            user,
+	readCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+	orders, total, err := u.orderRepo.List(readCtx, *filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list orders: %w", err)
 	}
 
-	return orderResponse, nil
+	return orders, total, nil
 }
 
-func (u *OrderUsecase) GetPaymentStatus(ctx context.Context, paymentID string) (*entity.PaymentStatus, error) {
+// StreamOrders passes every order of the user matching the filter, newest
+// first and ignoring pagination, to emit one chunk at a time as it is read,
+// so the history is never held in memory whole. It returns the number of
+// orders emitted.
+func (u *OrderUsecase) StreamOrders(ctx context.Context, filter entity.OrderFilter, emit func([]*entity.Order) error) (int, error) {
+	var after *entity.Order
+	count := 0
+	for {
+		callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+		chunk, err := u.orderRepo.ListAfter(callCtx, filter, after, exportPageSize)
+		cancel()
+		if err != nil {
+			return count, fmt.Errorf("failed to stream orders: %w", err)
+		}
+		if len(chunk) == 0 {
+			return count, nil
+		}
+
+		if err := emit(chunk); err != nil {
+			return count, err
+		}
+		count += len(chunk)
+		if len(chunk) < exportPageSize {
+			return count, nil
+		}
+		after = chunk[len(chunk)-1]
+	}
+}
+
+// ExportOrders returns every order of the user matching the filter, ignoring pagination.
+func (u *OrderUsecase) ExportOrders(ctx context.Context, filter entity.OrderFilter) ([]*entity.Order, error) {
+	filter.PageSize = exportPageSize
+
+	var orders []*entity.Order
+	for filter.Page = 1; ; filter.Page++ {
+		callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+		page, total, err := u.orderRepo.List(callCtx, filter)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to export orders: %w", err)
+		}
+
+		orders = append(orders, page...)
+		if len(page) == 0 || len(orders) >= total {
+			return orders, nil
+		}
+	}
+}
+
+// GetPaymentStatus returns the provider status of a payment for one of the
+// user's orders, from the cache when it was read recently.
+func (u *OrderUsecase) GetPaymentStatus(ctx context.Context, userID int, paymentID string) (*entity.PaymentStatus, error) {
 	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
 		"payment_id": paymentID,
+		"user_id":    userID,
 		"operation":  "get_payment_status",
 	}).Info("Getting payment status")
 
-	status, err := u.paymentProvider.GetPaymentStatus(ctx, paymentID)
-	if err != nil {
-		u.logger.ErrorLogger(ctx, err, "Failed to get payment status", map[string]interface{}{
-			"payment_id": paymentID,
-		})
-		return nil, fmt.Errorf("failed to get payment status: %w", err)
+	if _, err := u.getOrderByPayment(ctx, userID, paymentID); err != nil {
+		return nil, err
 	}
-
-	return status, nil
+	return u.readPaymentStatus(ctx, paymentID)
 }
 
+// RefundOrder refunds the payment of one of the user's completed orders and
+// moves the order to refunded, so it cannot be refunded again.
 func (u *OrderUsecase) RefundOrder(ctx context.Context, req *entity.RefundOrderRequest) (*entity.RefundResponse, error) {
 	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
 		"payment_id": req.PaymentID,
@@ -142,14 +632,44 @@ func (u *OrderUsecase) RefundOrder(ctx context.Context, req *entity.RefundOrderR
 		"operation":  "refund_order",
 	}).Info("Processing refund")
 
-	// 1. Validate user exists
-	user, err := u.userRepo.GetByID(ctx, req.UserID)
+	// 1. Validate the order is shared with the user and can be refunded
+	order, err := u.getOrderByPayment(ctx, req.UserID, req.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var refund *entity.RefundResponse
+	err = u.withOrderLock(ctx, order.OrderID, func(ctx context.Context) error {
+		if u.locker != nil {
+			// Read again, the order may have changed while waiting for its lock
+			if order, err = u.getOrderByPayment(ctx, req.UserID, req.PaymentID); err != nil {
+				return err
+			}
+		}
+		refund, err = u.refundLockedOrder(ctx, req, order)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// refundLockedOrder refunds the order holding its lock
+func (u *OrderUsecase) refundLockedOrder(ctx context.Context, req *entity.RefundOrderRequest, order *entity.Order) (*entity.RefundResponse, error) {
+	if !order.CanTransitionTo(entity.OrderStatusRefunded) {
+		return nil, fmt.Errorf("%w: %s", errors.ErrInvalidOrderState, order.Status)
+	}
+
+	callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := u.userRepo.GetByID(callCtx, order.UserID)
+	cancel()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
 	// 2. Process refund
-	refund, err := u.paymentProvider.RefundPayment(ctx, req.PaymentID)
+	refund, err := u.refundOrder(ctx, order)
 	if err != nil {
 		u.logger.ErrorLogger(ctx, err, "Refund processing failed", map[string]interface{}{
 			"payment_id": req.PaymentID,
@@ -157,6 +677,7 @@ func (u *OrderUsecase) RefundOrder(ctx context.Context, req *entity.RefundOrderR
 		})
 		return nil, fmt.Errorf("refund processing failed: %w", err)
 	}
+	u.releaseCoupon(ctx, order)
 
 	// 3. Send refund notification
 	go u.sendRefundNotification(context.Background(), user, req.PaymentID, refund.ID)
@@ -165,12 +686,255 @@ func (u *OrderUsecase) RefundOrder(ctx context.Context, req *entity.RefundOrderR
 		"payment_id": req.PaymentID,
 		"refund_id":  refund.ID,
 		"user_id":    req.UserID,
+		"order_id":   order.OrderID,
 	}).Info("Refund processed successfully")
 
 	return refund, nil
 }
 
+// refundOrder moves the order to refunded before refunding its payment, so that
+// concurrent refunds or cancellations of the same order cannot both reach the
+// provider. The status is restored when the provider rejects the refund.
+func (u *OrderUsecase) refundOrder(ctx context.Context, order *entity.Order) (*entity.RefundResponse, error) {
+	if err := u.transitionOrder(ctx, order, entity.OrderStatusRefunded); err != nil {
+		return nil, err
+	}
+
+	callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationPayment)
+	refund, err := u.paymentProvider.RefundPayment(callCtx, order.PaymentID)
+	cancel()
+	if err != nil {
+		u.updateOrderStatus(ctx, order, entity.OrderStatusCompleted)
+		return nil, err
+	}
+	u.paymentChanged(ctx, order.PaymentID)
+	return refund, nil
+}
+
+// applyPaymentEvent applies a payment webhook event to the order it concerns,
+// after the events of the order received before it. Refunds issued outside
+// the API, e.g. from the provider dashboard, move the order to refunded; other
+// events are acknowledged and ignored. A refund received before its payment
+// is recorded is buffered until the order is paid.
+func (u *OrderUsecase) applyPaymentEvent(ctx context.Context, event *entity.PaymentEvent) error {
+	// Every event is a change of the payment, including those ignored
+	u.paymentChanged(ctx, event.PaymentID)
+
+	if event.Event != entity.PaymentEventRefunded {
+		u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+			"event":      event.Event,
+			"payment_id": event.PaymentID,
+		}).Info("Ignoring payment event")
+		return nil
+	}
+
+	readCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	order, err := u.orderRepo.GetByPaymentID(readCtx, event.PaymentID)
+	cancel()
+	if errors.IsOrderNotFound(err) {
+		if err := u.bufferPaymentEvent(ctx, event, err); !errors.IsEventBuffered(err) {
+			return err
+		}
+
+		// The payment may have been recorded while the event was buffered
+		readCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+		order, err = u.orderRepo.GetByPaymentID(readCtx, event.PaymentID)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%w: %s", errors.ErrEventBuffered, event.PaymentID)
+		}
+		u.applyBufferedEventsInOrder(ctx, order)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return u.inOrder(ctx, order.OrderID, func(ctx context.Context) error {
+		return u.applyOrderEvent(ctx, event)
+	})
+}
+
+// applyOrderEvent applies a refund event to its order in the order's turn
+func (u *OrderUsecase) applyOrderEvent(ctx context.Context, event *entity.PaymentEvent) error {
+	// Read again, the order may have changed while the event waited its turn
+	readCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	order, err := u.orderRepo.GetByPaymentID(readCtx, event.PaymentID)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	// Already recorded, e.g. when the refund was made through the API
+	if order.Status == entity.OrderStatusRefunded {
+		return nil
+	}
+	if !order.CanTransitionTo(entity.OrderStatusRefunded) {
+		invalid := fmt.Errorf("%w: %s", errors.ErrInvalidOrderState, order.Status)
+		if order.CanReach(entity.OrderStatusRefunded) {
+			return u.bufferPaymentEvent(ctx, event, invalid)
+		}
+		return invalid
+	}
+
+	if err := u.transitionOrder(ctx, order, entity.OrderStatusRefunded); err != nil {
+		return err
+	}
+	u.releaseCoupon(ctx, order)
+
+	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"order_id":   order.OrderID,
+		"payment_id": event.PaymentID,
+		"refund_id":  event.RefundID,
+	}).Info("Order refunded by payment event")
+
+	return nil
+}
+
+// getOrderByPayment returns the order charged by the payment, reporting orders
+// of other users as not found unless they were made in the organization the
+// request acts in.
+func (u *OrderUsecase) getOrderByPayment(ctx context.Context, userID int, paymentID string) (*entity.Order, error) {
+	readCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	order, err := u.orderRepo.GetByPaymentID(readCtx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if !entity.SharedWith(ctx, userID, order.UserID, order.OrganizationID) {
+		return nil, errors.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+// CancelOrder cancels one of the user's orders, or of the organization the
+// request acts in. Uncaptured payments are voided and captured ones are refunded. The order is moved to the matching status before
+// the provider is called, so a concurrent payment or cancellation of it fails.
+func (u *OrderUsecase) CancelOrder(ctx context.Context, userID int, orderID string) (*entity.OrderCancellation, error) {
+	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id":   userID,
+		"order_id":  orderID,
+		"operation": "cancel_order",
+	}).Info("Cancelling order")
+
+	var result *entity.OrderCancellation
+	err := u.withOrderLock(ctx, orderID, func(ctx context.Context) error {
+		var err error
+		result, err = u.cancelOrder(ctx, userID, orderID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// cancelOrder cancels the order holding its lock
+func (u *OrderUsecase) cancelOrder(ctx context.Context, userID int, orderID string) (*entity.OrderCancellation, error) {
+	callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	order, err := u.orderRepo.GetByOrderID(callCtx, orderID)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	if !entity.SharedWith(ctx, userID, order.UserID, order.OrganizationID) {
+		return nil, errors.ErrOrderNotFound
+	}
+
+	callCtx, cancel = u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := u.userRepo.GetByID(callCtx, order.UserID)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	result := &entity.OrderCancellation{Order: order}
+
+	switch {
+	case order.CanTransitionTo(entity.OrderStatusCancelled):
+		previous := order.Status
+		if err := u.transitionOrder(ctx, order, entity.OrderStatusCancelled); err != nil {
+			return nil, err
+		}
+		result.Action = "voided"
+
+		if order.PaymentIntentID != "" {
+			callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationPayment)
+			intent, err := u.paymentProvider.CancelPaymentIntent(callCtx, order.PaymentIntentID)
+			cancel()
+			if err != nil {
+				u.updateOrderStatus(ctx, order, previous)
+				return nil, fmt.Errorf("failed to void payment: %w", err)
+			}
+			if intent.Status == entity.PaymentIntentStatusLocallyVoided {
+				result.Action = "voided_locally"
+			}
+		}
+
+	case order.CanTransitionTo(entity.OrderStatusRefunded):
+		refund, err := u.refundOrder(ctx, order)
+		if err != nil {
+			if errors.IsInvalidOrderState(err) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to refund payment: %w", err)
+		}
+		result.Action = "refunded"
+		result.RefundID = refund.ID
+
+	default:
+		return nil, fmt.Errorf("%w: %s", errors.ErrInvalidOrderState, order.Status)
+	}
+
+	u.releaseCoupon(ctx, order)
+
+	go u.sendOrderCancellationNotification(context.Background(), user, order, result.Action)
+
+	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id":  userID,
+		"order_id": orderID,
+		"action":   result.Action,
+		"status":   order.Status,
+	}).Info("Order cancelled successfully")
+
+	return result, nil
+}
+
+// CancelOpenOrders cancels the user's orders that have not been paid yet, voiding
+// their payment intents. Paid orders are kept as financial records, and orders
+// in processing are left to complete or fail on their own.
+func (u *OrderUsecase) CancelOpenOrders(ctx context.Context, userID int) error {
+	for _, status := range []string{entity.OrderStatusPending, entity.OrderStatusPaymentPending} {
+		orders, err := u.ExportOrders(ctx, entity.OrderFilter{UserID: userID, Status: status})
+		if err != nil {
+			return err
+		}
+
+		for _, order := range orders {
+			// An order moving on concurrently is no longer open
+			if _, err := u.CancelOrder(ctx, userID, order.OrderID); err != nil && !errors.IsInvalidOrderState(err) {
+				return fmt.Errorf("failed to cancel order %s: %w", order.OrderID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // Private helper methods for notifications
+
+// addRecipientPreferences passes the user's locale and timezone to the email
+// provider so it can localize the message and the times in it.
+func addRecipientPreferences(emailReq *entity.EmailRequest, user *entity.User) {
+	if user.Locale != "" {
+		emailReq.Metadata["locale"] = user.Locale
+	}
+	if user.Timezone != "" {
+		emailReq.Metadata["timezone"] = user.Timezone
+	}
+}
+
 func (u *OrderUsecase) sendOrderConfirmationNotification(ctx context.Context, user *entity.User, orderID, paymentID string, amount float64) {
 	emailReq := &entity.EmailRequest{
 		To:      []string{user.Email},
@@ -190,7 +954,7 @@ Thank you for your business!
 
 Best regards,
 Boilerplate Team
-		`, user.Username, orderID, paymentID, amount),
+		`, user.DisplayName(), orderID, paymentID, amount),
 		Metadata: map[string]interface{}{
 			"user_id":    user.ID,
 			"order_id":   orderID,
@@ -199,12 +963,11 @@ Boilerplate Team
 		},
 	}
 
-	if _, err := u.notificationProvider.SendEmail(ctx, emailReq); err != nil {
-		u.logger.ErrorLogger(ctx, err, "Failed to send order confirmation email", map[string]interface{}{
-			"user_id":  user.ID,
-			"order_id": orderID,
-		})
-	}
+	addRecipientPreferences(emailReq, user)
+	u.sendEmail(ctx, emailReq, "Failed to send order confirmation email", map[string]interface{}{
+		"user_id":  user.ID,
+		"order_id": orderID,
+	})
 }
 
 func (u *OrderUsecase) sendPaymentFailureNotification(ctx context.Context, user *entity.User, orderID string, paymentErr error) {
@@ -222,7 +985,7 @@ Error: %s
 
 Best regards,
 Boilerplate Team
-		`, user.Username, orderID, paymentErr.Error()),
+		`, user.DisplayName(), orderID, paymentErr.Error()),
 		Metadata: map[string]interface{}{
 			"user_id":  user.ID,
 			"order_id": orderID,
@@ -230,12 +993,11 @@ Boilerplate Team
 		},
 	}
 
-	if _, err := u.notificationProvider.SendEmail(ctx, emailReq); err != nil {
-		u.logger.ErrorLogger(ctx, err, "Failed to send payment failure email", map[string]interface{}{
-			"user_id":  user.ID,
-			"order_id": orderID,
-		})
-	}
+	addRecipientPreferences(emailReq, user)
+	u.sendEmail(ctx, emailReq, "Failed to send payment failure email", map[string]interface{}{
+		"user_id":  user.ID,
+		"order_id": orderID,
+	})
 }
 
 func (u *OrderUsecase) sendRefundNotification(ctx context.Context, user *entity.User, paymentID, refundID string) {
@@ -255,7 +1017,7 @@ The refund will appear in your account within 3-5 business days.
 
 Best regards,
 Boilerplate Team
-		`, user.Username, paymentID, refundID),
+		`, user.DisplayName(), paymentID, refundID),
 		Metadata: map[string]interface{}{
 			"user_id":    user.ID,
 			"payment_id": paymentID,
@@ -264,10 +1026,136 @@ Boilerplate Team
 		},
 	}
 
-	if _, err := u.notificationProvider.SendEmail(ctx, emailReq); err != nil {
-		u.logger.ErrorLogger(ctx, err, "Failed to send refund notification email", map[string]interface{}{
-			"user_id":    user.ID,
-			"payment_id": paymentID,
+	addRecipientPreferences(emailReq, user)
+	u.sendEmail(ctx, emailReq, "Failed to send refund notification email", map[string]interface{}{
+		"user_id":    user.ID,
+		"payment_id": paymentID,
+	})
+}
+
+func (u *OrderUsecase) sendOrderCancellationNotification(ctx context.Context, user *entity.User, order *entity.Order, action string) {
+	paymentNote := "No payment was taken for this order."
+	if action == "refunded" {
+		paymentNote = "Your payment has been refunded and will appear in your account within 3-5 business days."
+	}
+
+	emailReq := &entity.EmailRequest{
+		To:      []string{user.Email},
+		Subject: "Order Cancelled",
+		Body: fmt.Sprintf(`
+Hello %s,
+
+Your order %s has been cancelled.
+
+Order Details:
+- Order ID: %s
+- Amount: %.2f %s
+
+%s
+
+Best regards,
+Boilerplate Team
+		`, user.DisplayName(), order.OrderID, order.OrderID, order.Amount, order.Currency, paymentNote),
+		Metadata: map[string]interface{}{
+			"user_id":  user.ID,
+			"order_id": order.OrderID,
+			"type":     "order_cancellation",
+		},
+	}
+
+	addRecipientPreferences(emailReq, user)
+	u.sendEmail(ctx, emailReq, "Failed to send order cancellation email", map[string]interface{}{
+		"user_id":  user.ID,
+		"order_id": order.OrderID,
+	})
+}
+
+// screenOrder scores the order with the fraud provider and rejects it when the
+// score reaches the block threshold. Screening fails open: when the provider is
+// unavailable the order proceeds flagged for manual review.
+func (u *OrderUsecase) screenOrder(ctx context.Context, req *entity.CreateOrderRequest, user *entity.User) (*entity.FraudAssessment, error) {
+	if u.fraudProvider == nil {
+		return nil, nil
+	}
+
+	checkReq := &entity.FraudCheckRequest{
+		OrderID:          req.OrderID,
+		UserID:           user.ID,
+		UserEmail:        user.Email,
+		AccountCreatedAt: user.CreatedAt,
+		Amount:           req.Amount,
+		Currency:         req.Currency,
+		ItemCount:        len(req.Items),
+		CouponCode:       req.CouponCode,
+		IPAddress:        req.IPAddress,
+		UserAgent:        req.UserAgent,
+	}
+
+	// The request context middleware may have classified and located the client
+	if client := entity.ClientInfoFromContext(ctx); client != nil {
+		checkReq.Device = client.Device
+		checkReq.Location = client.Location
+	}
+
+	if checkReq.Location == nil && u.geoProvider != nil && req.IPAddress != "" {
+		callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationGeolocation)
+		location, err := u.geoProvider.GetLocationByIP(callCtx, req.IPAddress)
+		cancel()
+		if err != nil {
+			u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+				"order_id": req.OrderID,
+				"error":    err.Error(),
+			}).Warn("Failed to resolve order location")
+		} else {
+			checkReq.Location = location
+		}
+	}
+
+	callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationFraud)
+	assessment, err := u.fraudProvider.ScoreOrder(callCtx, checkReq)
+	cancel()
+	if err != nil {
+		u.logger.ErrorLogger(ctx, err, "Fraud screening failed, flagging order for review", map[string]interface{}{
+			"order_id": req.OrderID,
+			"user_id":  user.ID,
 		})
+		return &entity.FraudAssessment{
+			Score:   u.fraudConfig.ReviewThreshold,
+			Reasons: []string{"fraud screening unavailable"},
+		}, nil
+	}
+
+	fields := map[string]interface{}{
+		"order_id":   req.OrderID,
+		"user_id":    user.ID,
+		"provider":   assessment.Provider,
+		"risk_score": assessment.Score,
+		"reasons":    strings.Join(assessment.Reasons, ", "),
+	}
+
+	if assessment.Score >= u.fraudConfig.BlockThreshold {
+		u.logger.WithContext(ctx).WithFields(fields).Warn("Order blocked by fraud screening")
+		return nil, fmt.Errorf("%w: risk score %.0f", errors.ErrOrderBlocked, assessment.Score)
+	}
+	if assessment.Score >= u.fraudConfig.ReviewThreshold {
+		u.logger.WithContext(ctx).WithFields(fields).Warn("Order flagged for review")
+	}
+
+	return assessment, nil
+}
+
+// sendEmail queues the email when a job queue is configured and sends it directly otherwise.
+func (u *OrderUsecase) sendEmail(ctx context.Context, emailReq *entity.EmailRequest, message string, fields map[string]interface{}) {
+	var err error
+	if u.jobs != nil {
+		err = u.jobs.Enqueue(ctx, entity.JobTypeEmail, emailReq)
+	} else {
+		callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationNotification)
+		_, err = u.notificationProvider.SendEmail(callCtx, emailReq)
+		cancel()
+	}
+
+	if err != nil {
+		u.logger.ErrorLogger(ctx, err, message, fields)
 	}
 }
@@ -0,0 +1,560 @@
+package order
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockFraudProvider is a mock implementation of FraudProvider
+type MockFraudProvider struct {
+	mock.Mock
+}
+
+func (m *MockFraudProvider) ScoreOrder(ctx context.Context, req *entity.FraudCheckRequest) (*entity.FraudAssessment, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.FraudAssessment), args.Error(1)
+}
+
+// MockGeolocationProvider is a mock implementation of GeolocationProvider
+type MockGeolocationProvider struct {
+	mock.Mock
+}
+
+func (m *MockGeolocationProvider) GetLocationByIP(ctx context.Context, ipAddress string) (*entity.LocationInfo, error) {
+	args := m.Called(ctx, ipAddress)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.LocationInfo), args.Error(1)
+}
+
+func (m *MockGeolocationProvider) GetDistanceBetween(ctx context.Context, from, to *entity.Coordinates) (*entity.DistanceInfo, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.DistanceInfo), args.Error(1)
+}
+
+// MockUserRepository is a mock implementation of UserRepository
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(ctx context.Context, user *entity.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) CreateBatch(ctx context.Context, users []*entity.User) error {
+	args := m.Called(ctx, users)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetByID(ctx context.Context, id int) (*entity.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) List(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*entity.User), args.Int(1), args.Error(2)
+}
+
+func (m *MockUserRepository) IterateActiveUsers(ctx context.Context, fn func(*entity.User) error) error {
+	args := m.Called(ctx, fn)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Update(ctx context.Context, user *entity.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Upsert(ctx context.Context, user *entity.User) (bool, error) {
+	args := m.Called(ctx, user)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Anonymize(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetByPhone(ctx context.Context, phone string) (*entity.User, error) {
+	args := m.Called(ctx, phone)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdatePhone(ctx context.Context, id int, phone string, verifiedAt *time.Time) error {
+	args := m.Called(ctx, id, phone, verifiedAt)
+	return args.Error(0)
+}
+
+// MockOrderRepository is a mock implementation of OrderRepository
+type MockOrderRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderRepository) Create(ctx context.Context, order *entity.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) GetByOrderID(ctx context.Context, orderID string) (*entity.Order, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetByPaymentID(ctx context.Context, paymentID string) (*entity.Order, error) {
+	args := m.Called(ctx, paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) UpdateStatus(ctx context.Context, order *entity.Order, fromStatus string) error {
+	args := m.Called(ctx, order, fromStatus)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) List(ctx context.Context, filter entity.OrderFilter) ([]*entity.Order, int, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*entity.Order), args.Int(1), args.Error(2)
+}
+
+func (m *MockOrderRepository) ListAfter(ctx context.Context, filter entity.OrderFilter, after *entity.Order, limit int) ([]*entity.Order, error) {
+	args := m.Called(ctx, filter, after, limit)
+	return args.Get(0).([]*entity.Order), args.Error(1)
+}
+
+// MockCouponRepository is a mock implementation of CouponRepository
+type MockCouponRepository struct {
+	mock.Mock
+}
+
+func (m *MockCouponRepository) Create(ctx context.Context, coupon *entity.Coupon) error {
+	args := m.Called(ctx, coupon)
+	return args.Error(0)
+}
+
+func (m *MockCouponRepository) GetByID(ctx context.Context, id int) (*entity.Coupon, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Coupon), args.Error(1)
+}
+
+func (m *MockCouponRepository) GetByCode(ctx context.Context, code string) (*entity.Coupon, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Coupon), args.Error(1)
+}
+
+func (m *MockCouponRepository) List(ctx context.Context) ([]*entity.Coupon, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*entity.Coupon), args.Error(1)
+}
+
+func (m *MockCouponRepository) Update(ctx context.Context, coupon *entity.Coupon) error {
+	args := m.Called(ctx, coupon)
+	return args.Error(0)
+}
+
+func (m *MockCouponRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockCouponRepository) CountRedemptionsByUser(ctx context.Context, couponID, userID int) (int, error) {
+	args := m.Called(ctx, couponID, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCouponRepository) Redeem(ctx context.Context, redemption *entity.CouponRedemption) error {
+	args := m.Called(ctx, redemption)
+	return args.Error(0)
+}
+
+func (m *MockCouponRepository) ReleaseByOrderID(ctx context.Context, orderID int) error {
+	args := m.Called(ctx, orderID)
+	return args.Error(0)
+}
+
+func TestOrderUsecase_ProcessOrder_Amount(t *testing.T) {
+	items := []entity.OrderItem{
+		{ProductID: "sku-1", Quantity: 3, UnitPrice: 0.1},
+		{ProductID: "sku-2", Quantity: 1, UnitPrice: 19.99},
+	}
+
+	tests := []struct {
+		name           string
+		items          []entity.OrderItem
+		amount         float64
+		expectedAmount float64
+		expectedError  string
+	}{
+		{name: "amount omitted uses the computed total", items: items, expectedAmount: 20.29},
+		{name: "matching amount", items: items, amount: 20.29, expectedAmount: 20.29},
+		{name: "amount below the total", items: items, amount: 20.28, expectedError: "amount 20.28 does not match order total 20.29"},
+		{name: "amount above the total", items: items, amount: 25, expectedError: "amount 25.00 does not match order total 20.29"},
+		{name: "free items", items: []entity.OrderItem{{ProductID: "sku-1", Quantity: 1}}, expectedError: "order total must be greater than zero"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			userRepo.On("GetByID", mock.Anything, 7).Return(&entity.User{ID: 7, Email: "user@example.com"}, nil)
+
+			// Stop the flow once the order is persisted; its amount is what matters here
+			orderRepo := new(MockOrderRepository)
+			var saved *entity.Order
+			orderRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Order")).
+				Run(func(args mock.Arguments) { saved = args.Get(1).(*entity.Order) }).
+				Return(errors.ErrOrderAlreadyExists)
+
+			u := NewOrderUsecase(userRepo, orderRepo, nil, nil, nil, logger.NewLogger())
+			_, err := u.ProcessOrder(context.Background(), &entity.CreateOrderRequest{
+				OrderID:  "order-1",
+				UserID:   7,
+				Items:    tt.items,
+				Amount:   tt.amount,
+				Currency: "USD",
+			})
+
+			if tt.expectedError != "" {
+				assert.True(t, errors.IsInvalidOrder(err))
+				assert.ErrorContains(t, err, tt.expectedError)
+				orderRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			assert.True(t, errors.IsOrderAlreadyExists(err))
+			assert.Equal(t, tt.expectedAmount, saved.Amount)
+		})
+	}
+}
+
+func TestOrderUsecase_ProcessOrder_Currency(t *testing.T) {
+	for currency, supported := range map[string]bool{"EUR": true, "usd": true, "JPY": false} {
+		t.Run(currency, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			userRepo.On("GetByID", mock.Anything, 7).Return(&entity.User{ID: 7, Email: "user@example.com"}, nil)
+			orderRepo := new(MockOrderRepository)
+			orderRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Order")).Return(errors.ErrOrderAlreadyExists)
+
+			u := NewOrderUsecase(userRepo, orderRepo, nil, nil, nil, logger.NewLogger())
+			u.SetCurrencies([]string{"USD", "EUR"})
+			_, err := u.ProcessOrder(context.Background(), &entity.CreateOrderRequest{
+				OrderID:  "order-1",
+				UserID:   7,
+				Items:    []entity.OrderItem{{ProductID: "sku-1", Quantity: 1, UnitPrice: 10}},
+				Currency: currency,
+			})
+
+			if !supported {
+				assert.True(t, errors.IsInvalidOrder(err))
+				assert.ErrorContains(t, err, "currency JPY is not supported")
+				return
+			}
+			assert.True(t, errors.IsOrderAlreadyExists(err))
+		})
+	}
+}
+
+func TestOrderUsecase_ApplyCoupon(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	one := 1
+
+	tests := []struct {
+		name             string
+		coupon           *entity.Coupon
+		redemptions      int
+		subtotal         float64
+		currency         string
+		expectedDiscount float64
+		expectedError    string
+	}{
+		{
+			name:             "valid percentage coupon",
+			coupon:           &entity.Coupon{ID: 1, Code: "SAVE10", Type: entity.CouponTypePercentage, Value: 10, Active: true},
+			subtotal:         50,
+			currency:         "USD",
+			expectedDiscount: 5,
+		},
+		{
+			name:          "unknown code",
+			subtotal:      50,
+			expectedError: "unknown code",
+		},
+		{
+			name:          "inactive",
+			coupon:        &entity.Coupon{ID: 1, Code: "SAVE10", Type: entity.CouponTypePercentage, Value: 10},
+			subtotal:      50,
+			expectedError: "coupon is inactive",
+		},
+		{
+			name:          "expired",
+			coupon:        &entity.Coupon{ID: 1, Code: "SAVE10", Type: entity.CouponTypePercentage, Value: 10, Active: true, ExpiresAt: &past},
+			subtotal:      50,
+			expectedError: "coupon has expired",
+		},
+		{
+			name:          "exhausted",
+			coupon:        &entity.Coupon{ID: 1, Code: "SAVE10", Type: entity.CouponTypePercentage, Value: 10, Active: true, MaxRedemptions: &one, RedeemedCount: 1},
+			subtotal:      50,
+			expectedError: "redemption limit reached",
+		},
+		{
+			name:          "fixed coupon in another currency",
+			coupon:        &entity.Coupon{ID: 1, Code: "FIVE", Type: entity.CouponTypeFixed, Value: 5, Currency: "EUR", Active: true},
+			subtotal:      50,
+			currency:      "USD",
+			expectedError: "only valid for EUR orders",
+		},
+		{
+			name:             "fixed coupon currency is case insensitive",
+			coupon:           &entity.Coupon{ID: 1, Code: "FIVE", Type: entity.CouponTypeFixed, Value: 5, Currency: "EUR", Active: true},
+			subtotal:         50,
+			currency:         "eur",
+			expectedDiscount: 5,
+		},
+		{
+			name:          "below minimum order amount",
+			coupon:        &entity.Coupon{ID: 1, Code: "SAVE10", Type: entity.CouponTypePercentage, Value: 10, Active: true, MinOrderAmount: 100},
+			subtotal:      50,
+			expectedError: "order total must be at least 100.00",
+		},
+		{
+			name:          "per-user limit reached",
+			coupon:        &entity.Coupon{ID: 1, Code: "SAVE10", Type: entity.CouponTypePercentage, Value: 10, Active: true, PerUserLimit: &one},
+			redemptions:   1,
+			subtotal:      50,
+			expectedError: "per-user limit reached",
+		},
+		{
+			name:             "below per-user limit",
+			coupon:           &entity.Coupon{ID: 1, Code: "SAVE10", Type: entity.CouponTypePercentage, Value: 10, Active: true, PerUserLimit: &one},
+			subtotal:         50,
+			expectedDiscount: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			couponRepo := new(MockCouponRepository)
+			if tt.coupon != nil {
+				couponRepo.On("GetByCode", mock.Anything, tt.coupon.Code).Return(tt.coupon, nil)
+				couponRepo.On("CountRedemptionsByUser", mock.Anything, tt.coupon.ID, 7).Return(tt.redemptions, nil).Maybe()
+			} else {
+				couponRepo.On("GetByCode", mock.Anything, "MISSING").Return(nil, errors.ErrCouponNotFound)
+			}
+
+			code := " missing "
+			if tt.coupon != nil {
+				code = tt.coupon.Code
+			}
+
+			u := NewOrderUsecase(nil, nil, couponRepo, nil, nil, logger.NewLogger())
+			coupon, discount, err := u.applyCoupon(context.Background(), code, 7, tt.subtotal, tt.currency)
+
+			if tt.expectedError != "" {
+				assert.True(t, errors.IsCouponInvalid(err))
+				assert.ErrorContains(t, err, tt.expectedError)
+				assert.Nil(t, coupon)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.coupon, coupon)
+			assert.Equal(t, tt.expectedDiscount, discount)
+			couponRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOrderUsecase_ScreenOrder(t *testing.T) {
+	fraudConfig := config.FraudConfig{
+		ReviewThreshold: 50,
+		BlockThreshold:  80,
+	}
+	user := &entity.User{ID: 1, Email: "user@example.com"}
+
+	tests := []struct {
+		name            string
+		score           float64
+		scoreErr        error
+		expectedScore   float64
+		expectedFlagged bool
+		expectedBlocked bool
+	}{
+		{name: "below review threshold", score: 49, expectedScore: 49},
+		{name: "at review threshold is flagged", score: 50, expectedScore: 50, expectedFlagged: true},
+		{name: "below block threshold is flagged", score: 79, expectedScore: 79, expectedFlagged: true},
+		{name: "at block threshold is blocked", score: 80, expectedBlocked: true},
+		{name: "unavailable scorer flags the order", scoreErr: assert.AnError, expectedScore: 50, expectedFlagged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fraud := new(MockFraudProvider)
+			geo := new(MockGeolocationProvider)
+			geo.On("GetLocationByIP", mock.Anything, "203.0.113.1").Return(&entity.LocationInfo{CountryCode: "US"}, nil)
+
+			withLocation := mock.MatchedBy(func(req *entity.FraudCheckRequest) bool {
+				return req.Location != nil && req.Location.CountryCode == "US"
+			})
+			if tt.scoreErr != nil {
+				fraud.On("ScoreOrder", mock.Anything, withLocation).Return(nil, tt.scoreErr)
+			} else {
+				fraud.On("ScoreOrder", mock.Anything, withLocation).Return(&entity.FraudAssessment{Provider: "rules", Score: tt.score}, nil)
+			}
+
+			u := NewOrderUsecase(nil, nil, nil, nil, nil, logger.NewLogger())
+			u.SetFraudScreening(fraud, geo, fraudConfig)
+
+			assessment, err := u.screenOrder(context.Background(), &entity.CreateOrderRequest{
+				OrderID:   "order-1",
+				Amount:    100,
+				IPAddress: "203.0.113.1",
+			}, user)
+
+			if tt.expectedBlocked {
+				assert.True(t, errors.IsOrderBlocked(err))
+				assert.Nil(t, assessment)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedScore, assessment.Score)
+				assert.Equal(t, tt.expectedFlagged, assessment.Score >= fraudConfig.ReviewThreshold)
+			}
+			fraud.AssertExpectations(t)
+			geo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOrderUsecase_ScreenOrder_ClientContext(t *testing.T) {
+	fraud := new(MockFraudProvider)
+	geo := new(MockGeolocationProvider)
+	fraud.On("ScoreOrder", mock.Anything, mock.MatchedBy(func(req *entity.FraudCheckRequest) bool {
+		return req.Device == "bot" && req.Location != nil && req.Location.CountryCode == "NL"
+	})).Return(&entity.FraudAssessment{Provider: "rules", Score: 20}, nil)
+
+	u := NewOrderUsecase(nil, nil, nil, nil, nil, logger.NewLogger())
+	u.SetFraudScreening(fraud, geo, config.FraudConfig{ReviewThreshold: 50, BlockThreshold: 80})
+
+	// The client located by the middleware is not looked up again
+	ctx := entity.ContextWithClientInfo(context.Background(), &entity.ClientInfo{
+		IPAddress: "203.0.113.1",
+		Device:    "bot",
+		Location:  &entity.LocationInfo{CountryCode: "NL"},
+	})
+	assessment, err := u.screenOrder(ctx, &entity.CreateOrderRequest{
+		OrderID:   "order-1",
+		IPAddress: "203.0.113.1",
+	}, &entity.User{ID: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, float64(20), assessment.Score)
+	fraud.AssertExpectations(t)
+	geo.AssertNotCalled(t, "GetLocationByIP", mock.Anything, mock.Anything)
+}
+
+func TestOrderUsecase_ScreenOrder_GeolocationFailure(t *testing.T) {
+	fraud := new(MockFraudProvider)
+	geo := new(MockGeolocationProvider)
+	geo.On("GetLocationByIP", mock.Anything, "203.0.113.1").Return(nil, assert.AnError)
+	fraud.On("ScoreOrder", mock.Anything, mock.MatchedBy(func(req *entity.FraudCheckRequest) bool {
+		return req.Location == nil
+	})).Return(&entity.FraudAssessment{Provider: "rules", Score: 10}, nil)
+
+	u := NewOrderUsecase(nil, nil, nil, nil, nil, logger.NewLogger())
+	u.SetFraudScreening(fraud, geo, config.FraudConfig{ReviewThreshold: 50, BlockThreshold: 80})
+
+	assessment, err := u.screenOrder(context.Background(), &entity.CreateOrderRequest{
+		OrderID:   "order-1",
+		IPAddress: "203.0.113.1",
+	}, &entity.User{ID: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, float64(10), assessment.Score)
+	fraud.AssertExpectations(t)
+}
+
+func TestOrderUsecase_StreamOrders(t *testing.T) {
+	filter := entity.OrderFilter{UserID: 1, Status: entity.OrderStatusCompleted}
+	first := make([]*entity.Order, exportPageSize)
+	for i := range first {
+		first[i] = &entity.Order{ID: exportPageSize + 1 - i}
+	}
+	last := first[len(first)-1]
+
+	orderRepo := new(MockOrderRepository)
+	orderRepo.On("ListAfter", mock.Anything, filter, (*entity.Order)(nil), exportPageSize).Return(first, nil)
+	orderRepo.On("ListAfter", mock.Anything, filter, last, exportPageSize).Return([]*entity.Order{{ID: 0}}, nil)
+
+	u := NewOrderUsecase(nil, orderRepo, nil, nil, nil, logger.NewLogger())
+	var chunks []int
+	count, err := u.StreamOrders(context.Background(), filter, func(orders []*entity.Order) error {
+		chunks = append(chunks, len(orders))
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, exportPageSize+1, count)
+	assert.Equal(t, []int{exportPageSize, 1}, chunks)
+	orderRepo.AssertExpectations(t)
+
+	t.Run("emit error stops the stream", func(t *testing.T) {
+		count, err := u.StreamOrders(context.Background(), filter, func([]*entity.Order) error { return assert.AnError })
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Zero(t, count)
+	})
+}
@@ -0,0 +1,205 @@
+package order
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SetPaymentStatusCache serves payment statuses read from the provider within
+// ttl from the cache, so clients polling a payment do not call the provider
+// each time. Payment events and refunds drop the status of their payment.
+func (u *OrderUsecase) SetPaymentStatusCache(cache repository.PaymentStatusCacheRepository, ttl time.Duration) {
+	u.paymentStatuses = cache
+	u.paymentStatusTTL = ttl
+}
+
+// SetPaymentStatusWait lets clients wait up to maxWait for a payment status
+// to change. Payment events and refunds handled by this instance wake them
+// at once; changes handled by other instances are seen when the status is
+// read again every recheck.
+func (u *OrderUsecase) SetPaymentStatusWait(maxWait, recheck time.Duration) {
+	u.paymentStatusWait = maxWait
+	u.paymentRecheck = recheck
+}
+
+// PaymentStatusWait returns how long a client asking to wait for wait can
+// wait for a payment status to change, or 0 when waiting is disabled.
+func (u *OrderUsecase) PaymentStatusWait(wait time.Duration) time.Duration {
+	return min(wait, u.paymentStatusWait)
+}
+
+// WaitPaymentStatus returns the status of a payment for one of the user's
+// orders once it differs from known, or the status when wait runs out. An
+// empty known waits for the status to change from the current one.
+func (u *OrderUsecase) WaitPaymentStatus(ctx context.Context, userID int, paymentID, known string, wait time.Duration) (*entity.PaymentStatus, error) {
+	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"payment_id": paymentID,
+		"user_id":    userID,
+		"known":      known,
+		"wait":       wait.String(),
+		"operation":  "wait_payment_status",
+	}).Info("Waiting for payment status")
+
+	if _, err := u.getOrderByPayment(ctx, userID, paymentID); err != nil {
+		return nil, err
+	}
+
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	var recheck <-chan time.Time
+	if u.paymentRecheck > 0 {
+		ticker := time.NewTicker(u.paymentRecheck)
+		defer ticker.Stop()
+		recheck = ticker.C
+	}
+
+	for {
+		// Listen before reading, so a change in between is not missed
+		changed, release := u.paymentSignals.listen(paymentID)
+		status, err := u.readPaymentStatus(ctx, paymentID)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		if known == "" {
+			known = status.Status
+		}
+		if status.Status != known {
+			release()
+			return status, nil
+		}
+
+		select {
+		case <-changed:
+		case <-recheck:
+		case <-deadline.C:
+			release()
+			return status, nil
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+		release()
+	}
+}
+
+// readPaymentStatus returns the status of the payment from the cache, or from
+// the provider when it was not read recently.
+func (u *OrderUsecase) readPaymentStatus(ctx context.Context, paymentID string) (*entity.PaymentStatus, error) {
+	if status := u.cachedPaymentStatus(ctx, paymentID); status != nil {
+		return status, nil
+	}
+
+	callCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationPayment)
+	defer cancel()
+	status, err := u.paymentProvider.GetPaymentStatus(callCtx, paymentID)
+	if err != nil {
+		u.logger.ErrorLogger(ctx, err, "Failed to get payment status", map[string]interface{}{
+			"payment_id": paymentID,
+		})
+		return nil, fmt.Errorf("failed to get payment status: %w", err)
+	}
+	u.cachePaymentStatus(ctx, paymentID, status)
+
+	return status, nil
+}
+
+// cachedPaymentStatus returns the cached status of the payment, or nil. A
+// cache that cannot be read is skipped.
+func (u *OrderUsecase) cachedPaymentStatus(ctx context.Context, paymentID string) *entity.PaymentStatus {
+	if u.paymentStatuses == nil {
+		return nil
+	}
+
+	readCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+	status, err := u.paymentStatuses.Get(readCtx, paymentID)
+	if err != nil {
+		return nil
+	}
+	return status
+}
+
+// cachePaymentStatus keeps the status read from the provider for the ttl
+func (u *OrderUsecase) cachePaymentStatus(ctx context.Context, paymentID string, status *entity.PaymentStatus) {
+	if u.paymentStatuses == nil {
+		return
+	}
+
+	writeCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	// Failures are logged by the repository; the next read asks the provider again
+	_ = u.paymentStatuses.Set(writeCtx, paymentID, status, time.Now().Add(u.paymentStatusTTL))
+}
+
+// paymentChanged drops the cached status of a payment that changed, so the
+// next read asks the provider, and wakes the requests waiting for it
+func (u *OrderUsecase) paymentChanged(ctx context.Context, paymentID string) {
+	if paymentID == "" {
+		return
+	}
+	if u.paymentStatuses != nil {
+		// Forget the status even when the request was cancelled
+		writeCtx, cancel := u.timeouts.WithTimeout(context.WithoutCancel(ctx), timeout.OperationDBWrite)
+		defer cancel()
+		if err := u.paymentStatuses.Delete(writeCtx, paymentID); err != nil {
+			u.logger.WithContext(ctx).WithError(err).WithField("payment_id", paymentID).
+				Error("Cached payment status stays until it expires")
+		}
+	}
+	u.paymentSignals.signal(paymentID)
+}
+
+// paymentSignals wakes the requests of this instance waiting for a payment
+// to change. The zero value is ready to use.
+type paymentSignals struct {
+	mu      sync.Mutex
+	waiting map[string]*paymentWaiters
+}
+
+// paymentWaiters are the requests waiting for a payment, woken by closing
+// changed
+type paymentWaiters struct {
+	changed chan struct{}
+	count   int
+}
+
+// listen returns a channel closed at the next change of the payment, and the
+// function to call once no longer listening
+func (s *paymentSignals) listen(paymentID string) (<-chan struct{}, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.waiting == nil {
+		s.waiting = make(map[string]*paymentWaiters)
+	}
+	waiters, ok := s.waiting[paymentID]
+	if !ok {
+		waiters = &paymentWaiters{changed: make(chan struct{})}
+		s.waiting[paymentID] = waiters
+	}
+	waiters.count++
+
+	return waiters.changed, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		waiters.count--
+		if waiters.count == 0 && s.waiting[paymentID] == waiters {
+			delete(s.waiting, paymentID)
+		}
+	}
+}
+
+// signal wakes the requests waiting for the payment
+func (s *paymentSignals) signal(paymentID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if waiters, ok := s.waiting[paymentID]; ok {
+		close(waiters.changed)
+		delete(s.waiting, paymentID)
+	}
+}
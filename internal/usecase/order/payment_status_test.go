@@ -0,0 +1,220 @@
+package order
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// paymentStatusStore is an in-memory PaymentStatusCacheRepository
+type paymentStatusStore struct {
+	mu       sync.Mutex
+	statuses map[string]entity.PaymentStatus
+	expiry   map[string]time.Time
+}
+
+func newPaymentStatusStore() *paymentStatusStore {
+	return &paymentStatusStore{statuses: make(map[string]entity.PaymentStatus), expiry: make(map[string]time.Time)}
+}
+
+func (s *paymentStatusStore) Get(ctx context.Context, paymentID string) (*entity.PaymentStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status, ok := s.statuses[paymentID]
+	if !ok || !s.expiry[paymentID].After(time.Now()) {
+		return nil, nil
+	}
+	return &status, nil
+}
+
+func (s *paymentStatusStore) Set(ctx context.Context, paymentID string, status *entity.PaymentStatus, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[paymentID], s.expiry[paymentID] = *status, expiresAt
+	return nil
+}
+
+func (s *paymentStatusStore) Delete(ctx context.Context, paymentID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.statuses, paymentID)
+	delete(s.expiry, paymentID)
+	return nil
+}
+
+func (s *paymentStatusStore) DeleteExpired(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var purged int64
+	for id, expiry := range s.expiry {
+		if !expiry.After(time.Now()) {
+			delete(s.statuses, id)
+			delete(s.expiry, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func TestOrderUsecase_GetPaymentStatus_Cache(t *testing.T) {
+	ctx := context.Background()
+	setup := func(ttl time.Duration) (*OrderUsecase, *paymentStub) {
+		orderRepo := new(MockOrderRepository)
+		orderRepo.On("GetByPaymentID", mock.Anything, "pay_1").
+			Return(&entity.Order{OrderID: "ord_1", UserID: 1, PaymentID: "pay_1", Status: entity.OrderStatusCompleted}, nil)
+		payments := &paymentStub{}
+		uc := NewOrderUsecase(nil, orderRepo, nil, payments, nil, logger.NewLogger())
+		uc.SetPaymentStatusCache(newPaymentStatusStore(), ttl)
+		return uc, payments
+	}
+
+	t.Run("polling within the ttl reads the cache", func(t *testing.T) {
+		uc, payments := setup(time.Minute)
+
+		for i := 0; i < 3; i++ {
+			status, err := uc.GetPaymentStatus(ctx, 1, "pay_1")
+			require.NoError(t, err)
+			assert.Equal(t, "succeeded", status.Status)
+		}
+		assert.Equal(t, 1, payments.lookups)
+	})
+
+	t.Run("an expired status is read again", func(t *testing.T) {
+		uc, payments := setup(-time.Second)
+
+		_, err := uc.GetPaymentStatus(ctx, 1, "pay_1")
+		require.NoError(t, err)
+		_, err = uc.GetPaymentStatus(ctx, 1, "pay_1")
+		require.NoError(t, err)
+		assert.Equal(t, 2, payments.lookups)
+	})
+
+	t.Run("a payment event drops the status", func(t *testing.T) {
+		uc, payments := setup(time.Minute)
+
+		_, err := uc.GetPaymentStatus(ctx, 1, "pay_1")
+		require.NoError(t, err)
+		require.NoError(t, uc.HandlePaymentEvent(ctx, &entity.PaymentEvent{Event: "payment.succeeded", PaymentID: "pay_1"}))
+		_, err = uc.GetPaymentStatus(ctx, 1, "pay_1")
+		require.NoError(t, err)
+		assert.Equal(t, 2, payments.lookups)
+	})
+
+	t.Run("other users do not read the cache", func(t *testing.T) {
+		uc, _ := setup(time.Minute)
+
+		_, err := uc.GetPaymentStatus(ctx, 1, "pay_1")
+		require.NoError(t, err)
+		_, err = uc.GetPaymentStatus(ctx, 2, "pay_1")
+		assert.Error(t, err)
+	})
+}
+
+// changingPayments reports the status set by the test
+type changingPayments struct {
+	paymentStub
+	status string
+}
+
+func (p *changingPayments) GetPaymentStatus(ctx context.Context, paymentID string) (*entity.PaymentStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lookups++
+	return &entity.PaymentStatus{ID: paymentID, Status: p.status}, nil
+}
+
+func (p *changingPayments) set(status string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status = status
+}
+
+func TestOrderUsecase_WaitPaymentStatus(t *testing.T) {
+	ctx := context.Background()
+	setup := func(recheck time.Duration) (*OrderUsecase, *changingPayments) {
+		orderRepo := new(MockOrderRepository)
+		orderRepo.On("GetByPaymentID", mock.Anything, "pay_1").
+			Return(&entity.Order{OrderID: "ord_1", UserID: 1, PaymentID: "pay_1", Status: entity.OrderStatusCompleted}, nil)
+		payments := &changingPayments{status: "pending"}
+		uc := NewOrderUsecase(nil, orderRepo, nil, payments, nil, logger.NewLogger())
+		uc.SetPaymentStatusCache(newPaymentStatusStore(), time.Minute)
+		uc.SetPaymentStatusWait(time.Minute, recheck)
+		return uc, payments
+	}
+
+	t.Run("a payment event wakes the request", func(t *testing.T) {
+		uc, payments := setup(0)
+
+		done := make(chan *entity.PaymentStatus)
+		go func() {
+			status, err := uc.WaitPaymentStatus(ctx, 1, "pay_1", "", time.Minute)
+			assert.NoError(t, err)
+			done <- status
+		}()
+		require.Eventually(t, func() bool {
+			payments.mu.Lock()
+			defer payments.mu.Unlock()
+			return payments.lookups == 1
+		}, time.Second, time.Millisecond)
+
+		payments.set("succeeded")
+		require.NoError(t, uc.HandlePaymentEvent(ctx, &entity.PaymentEvent{Event: "payment.succeeded", PaymentID: "pay_1"}))
+
+		select {
+		case status := <-done:
+			assert.Equal(t, "succeeded", status.Status)
+		case <-time.After(time.Second):
+			t.Fatal("the request was not woken")
+		}
+		assert.Empty(t, uc.paymentSignals.waiting)
+	})
+
+	t.Run("a status other than the known one returns at once", func(t *testing.T) {
+		uc, _ := setup(0)
+
+		status, err := uc.WaitPaymentStatus(ctx, 1, "pay_1", "requires_action", time.Minute)
+
+		require.NoError(t, err)
+		assert.Equal(t, "pending", status.Status)
+	})
+
+	t.Run("the current status is returned when the wait runs out", func(t *testing.T) {
+		uc, _ := setup(0)
+
+		status, err := uc.WaitPaymentStatus(ctx, 1, "pay_1", "", 20*time.Millisecond)
+
+		require.NoError(t, err)
+		assert.Equal(t, "pending", status.Status)
+		assert.Empty(t, uc.paymentSignals.waiting)
+	})
+
+	t.Run("a change handled elsewhere is seen at the next recheck", func(t *testing.T) {
+		uc, payments := setup(10 * time.Millisecond)
+		// No event reaches this instance, and no cached status hides the change
+		uc.SetPaymentStatusCache(nil, 0)
+
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			payments.set("succeeded")
+		}()
+		status, err := uc.WaitPaymentStatus(ctx, 1, "pay_1", "pending", time.Minute)
+
+		require.NoError(t, err)
+		assert.Equal(t, "succeeded", status.Status)
+	})
+
+	t.Run("the wait is capped", func(t *testing.T) {
+		uc, _ := setup(0)
+
+		assert.Equal(t, time.Minute, uc.PaymentStatusWait(time.Hour))
+		assert.Equal(t, time.Second, uc.PaymentStatusWait(time.Second))
+	})
+}
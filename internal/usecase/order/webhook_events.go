@@ -0,0 +1,210 @@
+package order
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/lock"
+	"boilerplate-go/pkg/timeout"
+	"boilerplate-go/pkg/workerpool"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"time"
+)
+
+// SetWebhookEvents records the IDs of the payment events applied for ttl, so
+// an event delivered again within ttl is rejected as a duplicate, and keeps
+// events received before their order is paid in the buffer for up to ttl.
+// Providers deliver at least once and retry for days, so ttl should outlast
+// their retries.
+func (u *OrderUsecase) SetWebhookEvents(events repository.WebhookEventRepository, buffer repository.EventBufferRepository, ttl time.Duration) {
+	u.webhookEvents = events
+	u.eventBuffer = buffer
+	u.webhookEventTTL = ttl
+}
+
+// SetEventOrdering runs the payment events and deferred payments of an order
+// one at a time in the order they arrive, on the partitions of the pool.
+// Ordering holds within an instance; across instances, orders only move
+// between statuses the state machine allows.
+func (u *OrderUsecase) SetEventOrdering(ordering *workerpool.Partitioned) {
+	u.ordering = ordering
+}
+
+// SetOrderLocks processes an order on one instance at a time: its payment
+// events, deferred payment, cancellation and refund hold the order's lock,
+// shared by the instances, for at most ttl, waiting up to wait for it.
+func (u *OrderUsecase) SetOrderLocks(locker lock.Locker, ttl, wait time.Duration) {
+	u.locker = locker
+	u.lockOptions = lock.Options{TTL: ttl, Wait: wait}
+}
+
+// inOrder runs fn after the events of the order submitted before it, holding
+// the order's lock. It must not be called from a function already running in
+// the order's turn.
+func (u *OrderUsecase) inOrder(ctx context.Context, orderID string, fn func(ctx context.Context) error) error {
+	if u.ordering == nil {
+		return u.withOrderLock(ctx, orderID, fn)
+	}
+	return u.ordering.Do(ctx, orderID, func(ctx context.Context) error {
+		return u.withOrderLock(ctx, orderID, fn)
+	})
+}
+
+// withOrderLock runs fn holding the order's lock. It returns ErrConflict when
+// another instance kept processing the order for longer than the wait.
+func (u *OrderUsecase) withOrderLock(ctx context.Context, orderID string, fn func(ctx context.Context) error) error {
+	if u.locker == nil {
+		return fn(ctx)
+	}
+
+	err := lock.WithLock(ctx, u.locker, "order:"+orderID, u.lockOptions, fn)
+	if stderrors.Is(err, lock.ErrNotAcquired) {
+		return fmt.Errorf("%w: order %s is being processed", errors.ErrConflict, orderID)
+	}
+	return err
+}
+
+// HandlePaymentEvent applies a payment webhook event once. An event whose ID
+// was already applied returns ErrDuplicateEvent; an event that fails is
+// forgotten, so the provider's next delivery of it is applied. Events without
+// an ID are applied every time. An event received before its order is paid
+// returns ErrEventBuffered and is applied once the order is paid.
+func (u *OrderUsecase) HandlePaymentEvent(ctx context.Context, event *entity.PaymentEvent) error {
+	if u.webhookEvents == nil || event.ID == "" {
+		return u.applyPaymentEvent(ctx, event)
+	}
+
+	writeCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	claimed, err := u.webhookEvents.Claim(writeCtx, event.ID, time.Now().Add(u.webhookEventTTL))
+	cancel()
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+			"event_id":   event.ID,
+			"event":      event.Event,
+			"payment_id": event.PaymentID,
+		}).Info("Ignoring duplicate payment event")
+		return fmt.Errorf("%w: %s", errors.ErrDuplicateEvent, event.ID)
+	}
+
+	// A buffered event counts as applied
+	err = u.applyPaymentEvent(ctx, event)
+	if err != nil && !errors.IsEventBuffered(err) {
+		// Forget the event even when the request was cancelled
+		releaseCtx, cancel := u.timeouts.WithTimeout(context.WithoutCancel(ctx), timeout.OperationDBWrite)
+		defer cancel()
+		if releaseErr := u.webhookEvents.Release(releaseCtx, event.ID); releaseErr != nil {
+			u.logger.WithContext(ctx).WithError(releaseErr).WithField("event_id", event.ID).
+				Error("Failed payment event stays recorded; its next delivery will be ignored")
+		}
+	}
+	return err
+}
+
+// bufferPaymentEvent keeps an event its order cannot take yet, returning
+// ErrEventBuffered. Without a buffer it returns unbuffered instead.
+func (u *OrderUsecase) bufferPaymentEvent(ctx context.Context, event *entity.PaymentEvent, unbuffered error) error {
+	if u.eventBuffer == nil {
+		return unbuffered
+	}
+
+	writeCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := u.eventBuffer.Buffer(writeCtx, event, time.Now().Add(u.webhookEventTTL)); err != nil {
+		return err
+	}
+
+	u.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"event_id":   event.ID,
+		"event":      event.Event,
+		"payment_id": event.PaymentID,
+		"reason":     unbuffered.Error(),
+	}).Info("Payment event buffered until its order is paid")
+	return fmt.Errorf("%w: %s", errors.ErrEventBuffered, event.PaymentID)
+}
+
+// applyBufferedEventsInOrder applies the events buffered for the order in the
+// order's turn.
+func (u *OrderUsecase) applyBufferedEventsInOrder(ctx context.Context, order *entity.Order) {
+	err := u.inOrder(ctx, order.OrderID, func(ctx context.Context) error {
+		u.applyBufferedEvents(ctx, order)
+		return nil
+	})
+	if err != nil {
+		u.logger.ErrorLogger(ctx, err, "Buffered payment events not applied", map[string]interface{}{
+			"order_id":   order.OrderID,
+			"payment_id": order.PaymentID,
+		})
+	}
+}
+
+// applyBufferedEvents applies the events buffered for the order's payment in
+// the order they arrived. It runs in the order's turn, once the order is paid.
+func (u *OrderUsecase) applyBufferedEvents(ctx context.Context, order *entity.Order) {
+	if u.eventBuffer == nil || order.PaymentID == "" {
+		return
+	}
+
+	writeCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	events, err := u.eventBuffer.Take(writeCtx, order.PaymentID)
+	cancel()
+	if err != nil {
+		u.logger.ErrorLogger(ctx, err, "Failed to take buffered payment events", map[string]interface{}{
+			"order_id":   order.OrderID,
+			"payment_id": order.PaymentID,
+		})
+		return
+	}
+
+	for _, event := range events {
+		if err := u.applyOrderEvent(ctx, event); err != nil && !errors.IsEventBuffered(err) {
+			u.logger.ErrorLogger(ctx, err, "Failed to apply buffered payment event", map[string]interface{}{
+				"event_id":   event.ID,
+				"event":      event.Event,
+				"order_id":   order.OrderID,
+				"payment_id": event.PaymentID,
+				"refund_id":  event.RefundID,
+			})
+		}
+	}
+}
+
+// PurgeWebhookEvents is the job handler deleting the expired event IDs,
+// buffered events and cached payment statuses.
+func (u *OrderUsecase) PurgeWebhookEvents(ctx context.Context, _ json.RawMessage) error {
+	if u.webhookEvents == nil {
+		return nil
+	}
+
+	writeCtx, cancel := u.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+
+	purged, err := u.webhookEvents.DeleteExpired(writeCtx)
+	if err != nil {
+		return err
+	}
+	if u.eventBuffer != nil {
+		expired, err := u.eventBuffer.DeleteExpired(writeCtx)
+		if err != nil {
+			return err
+		}
+		if expired > 0 {
+			// Their orders were never paid
+			u.logger.WithContext(ctx).WithField("expired", expired).Warn("Dropped buffered payment events that expired")
+		}
+	}
+	if u.paymentStatuses != nil {
+		if _, err := u.paymentStatuses.DeleteExpired(writeCtx); err != nil {
+			return err
+		}
+	}
+	if purged > 0 {
+		u.logger.WithContext(ctx).WithField("purged", purged).Info("Purged expired webhook events")
+	}
+	return nil
+}
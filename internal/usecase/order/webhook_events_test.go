@@ -0,0 +1,229 @@
+package order
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/lock"
+	"boilerplate-go/pkg/workerpool"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookEventStore is an in-memory WebhookEventRepository
+type webhookEventStore struct {
+	mu     sync.Mutex
+	events map[string]time.Time
+}
+
+func newWebhookEventStore() *webhookEventStore {
+	return &webhookEventStore{events: make(map[string]time.Time)}
+}
+
+func (s *webhookEventStore) Claim(ctx context.Context, eventID string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if expiry, ok := s.events[eventID]; ok && expiry.After(time.Now()) {
+		return false, nil
+	}
+	s.events[eventID] = expiresAt
+	return true, nil
+}
+
+func (s *webhookEventStore) Release(ctx context.Context, eventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.events, eventID)
+	return nil
+}
+
+func (s *webhookEventStore) DeleteExpired(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var purged int64
+	for id, expiry := range s.events {
+		if !expiry.After(time.Now()) {
+			delete(s.events, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// eventBufferStore is an in-memory EventBufferRepository
+type eventBufferStore struct {
+	mu     sync.Mutex
+	events []*entity.PaymentEvent
+}
+
+func (s *eventBufferStore) Buffer(ctx context.Context, event *entity.PaymentEvent, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buffered := *event
+	s.events = append(s.events, &buffered)
+	return nil
+}
+
+func (s *eventBufferStore) Take(ctx context.Context, paymentID string) ([]*entity.PaymentEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var taken, kept []*entity.PaymentEvent
+	for _, event := range s.events {
+		if event.PaymentID == paymentID {
+			taken = append(taken, event)
+		} else {
+			kept = append(kept, event)
+		}
+	}
+	s.events = kept
+	return taken, nil
+}
+
+func (s *eventBufferStore) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestOrderUsecase_HandlePaymentEvent_Duplicates(t *testing.T) {
+	ctx := context.Background()
+	refunded := &entity.PaymentEvent{ID: "evt_1", Event: entity.PaymentEventRefunded, PaymentID: "pay_1"}
+
+	t.Run("an event delivered again is not applied twice", func(t *testing.T) {
+		orderRepo := new(MockOrderRepository)
+		orderRepo.On("GetByPaymentID", mock.Anything, "pay_1").
+			Return(&entity.Order{OrderID: "ord_1", Status: entity.OrderStatusRefunded}, nil).Twice()
+		uc := NewOrderUsecase(nil, orderRepo, nil, nil, nil, logger.NewLogger())
+		uc.SetWebhookEvents(newWebhookEventStore(), &eventBufferStore{}, time.Hour)
+
+		require.NoError(t, uc.HandlePaymentEvent(ctx, refunded))
+		err := uc.HandlePaymentEvent(ctx, refunded)
+		assert.True(t, errors.IsDuplicateEvent(err), "second delivery: %v", err)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("a failed event is applied when delivered again", func(t *testing.T) {
+		orderRepo := new(MockOrderRepository)
+		orderRepo.On("GetByPaymentID", mock.Anything, "pay_1").Return(nil, errors.ErrOrderNotFound).Once()
+		orderRepo.On("GetByPaymentID", mock.Anything, "pay_1").
+			Return(&entity.Order{OrderID: "ord_1", Status: entity.OrderStatusRefunded}, nil).Twice()
+		uc := NewOrderUsecase(nil, orderRepo, nil, nil, nil, logger.NewLogger())
+		uc.SetWebhookEvents(newWebhookEventStore(), nil, time.Hour)
+
+		assert.True(t, errors.IsOrderNotFound(uc.HandlePaymentEvent(ctx, refunded)))
+		assert.NoError(t, uc.HandlePaymentEvent(ctx, refunded))
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("an expired event is applied again", func(t *testing.T) {
+		events := newWebhookEventStore()
+		uc := NewOrderUsecase(nil, nil, nil, nil, nil, logger.NewLogger())
+		uc.SetWebhookEvents(events, &eventBufferStore{}, -time.Second)
+		ignored := &entity.PaymentEvent{ID: "evt_2", Event: "payment.created", PaymentID: "pay_2"}
+
+		require.NoError(t, uc.HandlePaymentEvent(ctx, ignored))
+		assert.NoError(t, uc.HandlePaymentEvent(ctx, ignored))
+
+		require.NoError(t, uc.PurgeWebhookEvents(ctx, nil))
+		assert.Empty(t, events.events)
+	})
+
+	t.Run("events without an ID are applied every time", func(t *testing.T) {
+		uc := NewOrderUsecase(nil, nil, nil, nil, nil, logger.NewLogger())
+		uc.SetWebhookEvents(newWebhookEventStore(), &eventBufferStore{}, time.Hour)
+		anonymous := &entity.PaymentEvent{Event: "payment.created", PaymentID: "pay_3"}
+
+		require.NoError(t, uc.HandlePaymentEvent(ctx, anonymous))
+		assert.NoError(t, uc.HandlePaymentEvent(ctx, anonymous))
+	})
+}
+
+func TestOrderUsecase_HandlePaymentEvent_RefundBeforeCapture(t *testing.T) {
+	ctx := context.Background()
+	order := &entity.Order{OrderID: "ord_1", Status: entity.OrderStatusProcessing}
+
+	orderRepo := new(MockOrderRepository)
+	// Until the payment is recorded, the order is not found by its payment ID
+	orderRepo.On("GetByPaymentID", mock.Anything, "pay_1").Return(nil, errors.ErrOrderNotFound).Twice()
+	uc := NewOrderUsecase(nil, orderRepo, nil, nil, nil, logger.NewLogger())
+	buffer := &eventBufferStore{}
+	uc.SetWebhookEvents(newWebhookEventStore(), buffer, time.Hour)
+	uc.SetEventOrdering(workerpool.NewPartitioned(2, 10))
+
+	refund := &entity.PaymentEvent{ID: "evt_1", Event: entity.PaymentEventRefunded, PaymentID: "pay_1", RefundID: "re_1"}
+	err := uc.HandlePaymentEvent(ctx, refund)
+	assert.True(t, errors.IsEventBuffered(err), "refund before capture: %v", err)
+	assert.Len(t, buffer.events, 1)
+
+	// The retry of a buffered event is a duplicate
+	assert.True(t, errors.IsDuplicateEvent(uc.HandlePaymentEvent(ctx, refund)))
+
+	// The payment is recorded, then the buffered refund applies
+	order.PaymentID, order.Status = "pay_1", entity.OrderStatusCompleted
+	orderRepo.On("GetByPaymentID", mock.Anything, "pay_1").
+		Return(&entity.Order{OrderID: "ord_1", PaymentID: "pay_1", Status: entity.OrderStatusCompleted}, nil).Once()
+	orderRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(o *entity.Order) bool {
+		return o.Status == entity.OrderStatusRefunded
+	}), entity.OrderStatusCompleted).Return(nil).Once()
+
+	uc.applyBufferedEventsInOrder(ctx, order)
+	assert.Empty(t, buffer.events)
+	orderRepo.AssertExpectations(t)
+}
+
+func TestOrderUsecase_HandlePaymentEvent_BuffersUntilPaid(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := new(MockOrderRepository)
+	orderRepo.On("GetByPaymentID", mock.Anything, "pay_1").
+		Return(&entity.Order{OrderID: "ord_1", PaymentID: "pay_1", Status: entity.OrderStatusProcessing}, nil)
+	uc := NewOrderUsecase(nil, orderRepo, nil, nil, nil, logger.NewLogger())
+
+	refund := &entity.PaymentEvent{Event: entity.PaymentEventRefunded, PaymentID: "pay_1"}
+
+	// Without a buffer, the early event is rejected as before
+	assert.True(t, errors.IsInvalidOrderState(uc.HandlePaymentEvent(ctx, refund)))
+
+	buffer := &eventBufferStore{}
+	uc.SetWebhookEvents(newWebhookEventStore(), buffer, time.Hour)
+	assert.True(t, errors.IsEventBuffered(uc.HandlePaymentEvent(ctx, refund)))
+	assert.Len(t, buffer.events, 1)
+}
+
+func TestOrderUsecase_HandlePaymentEvent_FailedOrderRejectsRefund(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := new(MockOrderRepository)
+	orderRepo.On("GetByPaymentID", mock.Anything, "pay_1").
+		Return(&entity.Order{OrderID: "ord_1", PaymentID: "pay_1", Status: entity.OrderStatusFailed}, nil)
+	uc := NewOrderUsecase(nil, orderRepo, nil, nil, nil, logger.NewLogger())
+	buffer := &eventBufferStore{}
+	uc.SetWebhookEvents(newWebhookEventStore(), buffer, time.Hour)
+
+	err := uc.HandlePaymentEvent(ctx, &entity.PaymentEvent{Event: entity.PaymentEventRefunded, PaymentID: "pay_1"})
+	assert.True(t, errors.IsInvalidOrderState(err), "refund of a failed order: %v", err)
+	assert.Empty(t, buffer.events)
+}
+
+func TestOrderUsecase_HandlePaymentEvent_OrderLock(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := new(MockOrderRepository)
+	orderRepo.On("GetByPaymentID", mock.Anything, "pay_1").
+		Return(&entity.Order{OrderID: "ord_1", PaymentID: "pay_1", Status: entity.OrderStatusRefunded}, nil)
+	uc := NewOrderUsecase(nil, orderRepo, nil, nil, nil, logger.NewLogger())
+	locker := lock.NewMemory()
+	uc.SetOrderLocks(locker, time.Minute, 0)
+	refund := &entity.PaymentEvent{Event: entity.PaymentEventRefunded, PaymentID: "pay_1"}
+
+	// Another instance processes the order
+	held, err := locker.Acquire(ctx, "order:ord_1", time.Minute)
+	require.NoError(t, err)
+	err = uc.HandlePaymentEvent(ctx, refund)
+	assert.True(t, errors.IsConflict(err), "order locked elsewhere: %v", err)
+
+	require.NoError(t, held.Release(ctx))
+	assert.NoError(t, uc.HandlePaymentEvent(ctx, refund))
+}
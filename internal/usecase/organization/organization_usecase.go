@@ -0,0 +1,382 @@
+package organization
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/emailaddr"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JobQueue runs background jobs with retries.
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobType string, payload interface{}) error
+}
+
+// OrganizationUsecase manages organizations, their members and invitations.
+// Owners and admins manage members; only owners grant or take away the owner
+// role, and an organization always keeps at least one owner.
+type OrganizationUsecase struct {
+	orgRepo              repository.OrganizationRepository
+	userRepo             repository.UserRepository
+	notificationProvider provider.NotificationProvider
+	jobs                 JobQueue
+	config               config.OrganizationConfig
+	stripPlus            bool
+	timeouts             *timeout.Policy
+	logger               *logger.Logger
+	now                  func() time.Time
+}
+
+// NewOrganizationUsecase creates a new organization use case. Invitations
+// are emailed through the notification provider.
+func NewOrganizationUsecase(orgRepo repository.OrganizationRepository, userRepo repository.UserRepository, notificationProvider provider.NotificationProvider, cfg config.OrganizationConfig, log *logger.Logger) *OrganizationUsecase {
+	return &OrganizationUsecase{
+		orgRepo:              orgRepo,
+		userRepo:             userRepo,
+		notificationProvider: notificationProvider,
+		config:               cfg,
+		logger:               log,
+		now:                  time.Now,
+	}
+}
+
+// SetTimeouts bounds repository and provider calls with per-operation deadlines.
+func (uc *OrganizationUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	uc.timeouts = timeouts
+}
+
+// SetEmailAddresses sets how invited email addresses are normalized: with
+// cfg.StripPlus they lose their plus subaddress, like registered ones.
+func (uc *OrganizationUsecase) SetEmailAddresses(cfg config.EmailAddressConfig) {
+	uc.stripPlus = cfg.StripPlus
+}
+
+// SetJobQueue sends invitation emails through the job queue, with retries,
+// instead of calling the notification provider directly.
+func (uc *OrganizationUsecase) SetJobQueue(jobs JobQueue) {
+	uc.jobs = jobs
+}
+
+// Create creates an organization owned by the user.
+func (uc *OrganizationUsecase) Create(ctx context.Context, userID int, req *entity.CreateOrganizationRequest) (*entity.Organization, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", errors.ErrInvalidOrganization)
+	}
+
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+
+	org := &entity.Organization{Name: name, CreatedBy: userID}
+	if err := uc.orgRepo.Create(ctx, org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// List returns the organizations of the user with the user's role in each.
+func (uc *OrganizationUsecase) List(ctx context.Context, userID int) ([]*entity.Organization, error) {
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	return uc.orgRepo.ListByUser(ctx, userID)
+}
+
+// Get returns an organization of the user with the user's role in it.
+// Organizations the user is not a member of are reported as not found.
+func (uc *OrganizationUsecase) Get(ctx context.Context, userID, orgID int) (*entity.Organization, error) {
+	member, err := uc.membership(ctx, orgID, userID, entity.OrgRoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	org, err := uc.orgRepo.GetByID(readCtx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	org.Role = member.Role
+	return org, nil
+}
+
+// GetMembership returns the user's membership of the organization, or
+// ErrNotOrgMember. It resolves the organization requests act in.
+func (uc *OrganizationUsecase) GetMembership(ctx context.Context, orgID, userID int) (*entity.Membership, error) {
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	return uc.orgRepo.GetMembership(ctx, orgID, userID)
+}
+
+// ListMembers returns the members of an organization of the user.
+func (uc *OrganizationUsecase) ListMembers(ctx context.Context, userID, orgID int) ([]*entity.Membership, error) {
+	if _, err := uc.membership(ctx, orgID, userID, entity.OrgRoleMember); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	return uc.orgRepo.ListMembers(ctx, orgID)
+}
+
+// Invite emails an invitation to join the organization with the role. Owners
+// and admins invite; only owners invite owners.
+func (uc *OrganizationUsecase) Invite(ctx context.Context, userID, orgID int, req *entity.InviteMemberRequest) (*entity.Invitation, error) {
+	if !entity.ValidOrgRole(req.Role) {
+		return nil, fmt.Errorf("%w: unknown role %q", errors.ErrInvalidOrganization, req.Role)
+	}
+	inviter, err := uc.membership(ctx, orgID, userID, entity.OrgRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !entity.OrgRoleAtLeast(inviter.Role, req.Role) {
+		return nil, fmt.Errorf("%w: only owners invite owners", errors.ErrOrgRoleRequired)
+	}
+	return uc.invite(ctx, userID, orgID, req)
+}
+
+// AdminInvite emails an invitation on behalf of a platform administrator,
+// who needs not be a member of the organization and may invite any role.
+func (uc *OrganizationUsecase) AdminInvite(ctx context.Context, adminID, orgID int, req *entity.InviteMemberRequest) (*entity.Invitation, error) {
+	if !entity.ValidOrgRole(req.Role) {
+		return nil, fmt.Errorf("%w: unknown role %q", errors.ErrInvalidOrganization, req.Role)
+	}
+	return uc.invite(ctx, adminID, orgID, req)
+}
+
+func (uc *OrganizationUsecase) invite(ctx context.Context, inviterID, orgID int, req *entity.InviteMemberRequest) (*entity.Invitation, error) {
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	org, err := uc.orgRepo.GetByID(readCtx, orgID)
+	var inviter *entity.User
+	if err == nil {
+		inviter, err = uc.userRepo.GetByID(readCtx, inviterID)
+	}
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+	invitation := &entity.Invitation{
+		OrganizationID: orgID,
+		Email:          emailaddr.Normalize(req.Email, uc.stripPlus),
+		Role:           req.Role,
+		TokenHash:      hashToken(token),
+		InvitedBy:      inviterID,
+		// Links carry the expiry in whole seconds
+		ExpiresAt: uc.now().Add(uc.config.InvitationTTL).Truncate(time.Second),
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	err = uc.orgRepo.CreateInvitation(writeCtx, invitation)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	uc.sendInvitation(ctx, org, inviter.DisplayName(), invitation, token)
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"organization_id": orgID,
+		"invitation_id":   invitation.ID,
+		"invited_by":      inviterID,
+		"role":            invitation.Role,
+	}).Info("Organization invitation sent")
+
+	return invitation, nil
+}
+
+// AcceptInvitation adds the user to the organization of the invitation. The
+// invitation must be pending, unexpired and sent to the user's email address;
+// other tokens are reported as not found.
+func (uc *OrganizationUsecase) AcceptInvitation(ctx context.Context, userID int, link *entity.InvitationLink) (*entity.Organization, error) {
+	invitation, err := uc.VerifyInvitation(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := uc.userRepo.GetByID(readCtx, userID)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	if emailaddr.Normalize(invitation.Email, uc.stripPlus) != emailaddr.Normalize(user.Email, uc.stripPlus) {
+		return nil, errors.ErrInvitationNotFound
+	}
+
+	return uc.RedeemInvitation(ctx, invitation, userID)
+}
+
+// UpdateMemberRole changes a member's role. Owners and admins change roles
+// below owner; only owners grant or take away the owner role.
+func (uc *OrganizationUsecase) UpdateMemberRole(ctx context.Context, userID, orgID, memberID int, role string) (*entity.Membership, error) {
+	if !entity.ValidOrgRole(role) {
+		return nil, fmt.Errorf("%w: unknown role %q", errors.ErrInvalidOrganization, role)
+	}
+	caller, err := uc.membership(ctx, orgID, userID, entity.OrgRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	member, err := uc.GetMembership(ctx, orgID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if !entity.OrgRoleAtLeast(caller.Role, member.Role) || !entity.OrgRoleAtLeast(caller.Role, role) {
+		return nil, fmt.Errorf("%w: only owners grant or take away the owner role", errors.ErrOrgRoleRequired)
+	}
+	if member.Role == entity.OrgRoleOwner && role != entity.OrgRoleOwner {
+		if err := uc.keepOwner(ctx, orgID); err != nil {
+			return nil, err
+		}
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.orgRepo.UpdateMemberRole(writeCtx, orgID, memberID, role); err != nil {
+		return nil, err
+	}
+	member.Role = role
+	return member, nil
+}
+
+// RemoveMember removes a member from the organization. Every member may
+// leave; owners and admins remove others, and only owners remove owners.
+func (uc *OrganizationUsecase) RemoveMember(ctx context.Context, userID, orgID, memberID int) error {
+	minRole := entity.OrgRoleAdmin
+	if memberID == userID {
+		minRole = entity.OrgRoleMember
+	}
+	caller, err := uc.membership(ctx, orgID, userID, minRole)
+	if err != nil {
+		return err
+	}
+	member := caller
+	if memberID != userID {
+		if member, err = uc.GetMembership(ctx, orgID, memberID); err != nil {
+			return err
+		}
+		if !entity.OrgRoleAtLeast(caller.Role, member.Role) {
+			return fmt.Errorf("%w: only owners remove owners", errors.ErrOrgRoleRequired)
+		}
+	}
+	if member.Role == entity.OrgRoleOwner {
+		if err := uc.keepOwner(ctx, orgID); err != nil {
+			return err
+		}
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	return uc.orgRepo.RemoveMember(writeCtx, orgID, memberID)
+}
+
+// membership returns the user's membership when it grants at least minRole.
+// Non-members are told the organization does not exist.
+func (uc *OrganizationUsecase) membership(ctx context.Context, orgID, userID int, minRole string) (*entity.Membership, error) {
+	member, err := uc.GetMembership(ctx, orgID, userID)
+	if errors.IsNotOrgMember(err) {
+		return nil, errors.ErrOrgNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !entity.OrgRoleAtLeast(member.Role, minRole) {
+		return nil, fmt.Errorf("%w: %s role required", errors.ErrOrgRoleRequired, minRole)
+	}
+	return member, nil
+}
+
+// keepOwner fails when the organization has a single owner, who is about to
+// lose the role
+func (uc *OrganizationUsecase) keepOwner(ctx context.Context, orgID int) error {
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	owners, err := uc.orgRepo.CountOwners(readCtx, orgID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return errors.ErrLastOrgOwner
+	}
+	return nil
+}
+
+// sendInvitation emails the invitation token, through the job queue when
+// one is configured
+func (uc *OrganizationUsecase) sendInvitation(ctx context.Context, org *entity.Organization, inviterName string, invitation *entity.Invitation, token string) {
+	accept := "Accept it in the app with this invitation code:\n\n" + token
+	if link := uc.invitationLink(token, invitation.ExpiresAt); link != "" {
+		accept = "Accept it here:\n\n" + link
+	}
+
+	emailReq := &entity.EmailRequest{
+		To:      []string{invitation.Email},
+		Subject: fmt.Sprintf("You are invited to join %s", org.Name),
+		Body: fmt.Sprintf(`
+Hello,
+
+%s invited you to join %s as %s.
+
+%s
+
+Sign in with this email address, or sign up if you have no account yet, to accept. The invitation expires on %s.
+
+Best regards,
+Boilerplate Team
+		`, inviterName, org.Name, invitation.Role, accept, invitation.ExpiresAt.UTC().Format("Jan 2, 2006 15:04 MST")),
+		Metadata: map[string]interface{}{
+			"organization_id": org.ID,
+			"invitation_id":   invitation.ID,
+			"type":            "organization_invitation",
+		},
+		Priority: entity.NotificationPriorityTransactional,
+	}
+
+	var err error
+	if uc.jobs != nil {
+		err = uc.jobs.Enqueue(ctx, entity.JobTypeEmail, emailReq)
+	} else {
+		callCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationNotification)
+		_, err = uc.notificationProvider.SendEmail(callCtx, emailReq)
+		cancel()
+	}
+	if err != nil {
+		uc.logger.ErrorLogger(ctx, err, "Failed to send organization invitation", map[string]interface{}{
+			"organization_id": org.ID,
+			"invitation_id":   invitation.ID,
+		})
+	}
+}
+
+// newInvitationToken returns a random token of 32 bytes, hex encoded
+func newInvitationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,297 @@
+package organization
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryOrgRepository keeps organizations, members and invitations in maps.
+type memoryOrgRepository struct {
+	repository.OrganizationRepository
+	orgs        map[int]*entity.Organization
+	members     map[[2]int]entity.Membership
+	invitations []*entity.Invitation
+}
+
+func newMemoryOrgRepository() *memoryOrgRepository {
+	return &memoryOrgRepository{
+		orgs:    make(map[int]*entity.Organization),
+		members: make(map[[2]int]entity.Membership),
+	}
+}
+
+func (r *memoryOrgRepository) Create(ctx context.Context, org *entity.Organization) error {
+	org.ID = len(r.orgs) + 1
+	r.orgs[org.ID] = org
+	r.members[[2]int{org.ID, org.CreatedBy}] = entity.Membership{OrganizationID: org.ID, UserID: org.CreatedBy, Role: entity.OrgRoleOwner}
+	return nil
+}
+
+func (r *memoryOrgRepository) GetByID(ctx context.Context, id int) (*entity.Organization, error) {
+	org, ok := r.orgs[id]
+	if !ok {
+		return nil, errors.ErrOrgNotFound
+	}
+	copied := *org
+	return &copied, nil
+}
+
+func (r *memoryOrgRepository) GetMembership(ctx context.Context, orgID, userID int) (*entity.Membership, error) {
+	member, ok := r.members[[2]int{orgID, userID}]
+	if !ok {
+		return nil, errors.ErrNotOrgMember
+	}
+	return &member, nil
+}
+
+func (r *memoryOrgRepository) AddMember(ctx context.Context, member *entity.Membership) error {
+	r.members[[2]int{member.OrganizationID, member.UserID}] = *member
+	return nil
+}
+
+func (r *memoryOrgRepository) UpdateMemberRole(ctx context.Context, orgID, userID int, role string) error {
+	member := r.members[[2]int{orgID, userID}]
+	member.Role = role
+	r.members[[2]int{orgID, userID}] = member
+	return nil
+}
+
+func (r *memoryOrgRepository) RemoveMember(ctx context.Context, orgID, userID int) error {
+	delete(r.members, [2]int{orgID, userID})
+	return nil
+}
+
+func (r *memoryOrgRepository) CountOwners(ctx context.Context, orgID int) (int, error) {
+	owners := 0
+	for key, member := range r.members {
+		if key[0] == orgID && member.Role == entity.OrgRoleOwner {
+			owners++
+		}
+	}
+	return owners, nil
+}
+
+func (r *memoryOrgRepository) CreateInvitation(ctx context.Context, invitation *entity.Invitation) error {
+	invitation.ID = len(r.invitations) + 1
+	r.invitations = append(r.invitations, invitation)
+	return nil
+}
+
+func (r *memoryOrgRepository) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*entity.Invitation, error) {
+	for _, invitation := range r.invitations {
+		if invitation.TokenHash == tokenHash {
+			copied := *invitation
+			return &copied, nil
+		}
+	}
+	return nil, errors.ErrInvitationNotFound
+}
+
+func (r *memoryOrgRepository) AcceptInvitation(ctx context.Context, invitation *entity.Invitation, member *entity.Membership) error {
+	now := time.Now()
+	r.invitations[invitation.ID-1].AcceptedAt = &now
+	return r.AddMember(ctx, member)
+}
+
+// stubUserRepository returns users by ID.
+type stubUserRepository struct {
+	repository.UserRepository
+	users map[int]*entity.User
+}
+
+func (r *stubUserRepository) GetByID(ctx context.Context, id int) (*entity.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, errors.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// recordingNotifier records the emails it sends.
+type recordingNotifier struct {
+	provider.NotificationProvider
+	emails []*entity.EmailRequest
+}
+
+func (n *recordingNotifier) SendEmail(ctx context.Context, req *entity.EmailRequest) (*entity.EmailResponse, error) {
+	n.emails = append(n.emails, req)
+	return &entity.EmailResponse{}, nil
+}
+
+func newTestUsecase() (*OrganizationUsecase, *memoryOrgRepository, *recordingNotifier) {
+	repo := newMemoryOrgRepository()
+	users := &stubUserRepository{users: map[int]*entity.User{
+		1: {ID: 1, Username: "owner", Email: "owner@example.com"},
+		2: {ID: 2, Username: "alice", Email: "alice@example.com"},
+		3: {ID: 3, Username: "mallory", Email: "mallory@example.com"},
+	}}
+	notifier := &recordingNotifier{}
+	uc := NewOrganizationUsecase(repo, users, notifier, config.OrganizationConfig{
+		InvitationTTL: time.Hour,
+		InvitationURL: "https://app.example.com/invitations/",
+	}, logger.NewLogger())
+	return uc, repo, notifier
+}
+
+// invitationLink extracts the parameters of the emailed invitation link
+func invitationLink(t *testing.T, email *entity.EmailRequest) *entity.InvitationLink {
+	_, rest, found := strings.Cut(email.Body, "https://app.example.com/invitations/?")
+	require.True(t, found)
+	query, err := url.ParseQuery(strings.Fields(rest)[0])
+	require.NoError(t, err)
+
+	link := &entity.InvitationLink{Token: query.Get("token"), Signature: query.Get("signature")}
+	if expires := query.Get("expires"); expires != "" {
+		link.Expires, err = strconv.ParseInt(expires, 10, 64)
+		require.NoError(t, err)
+	}
+	return link
+}
+
+func TestOrganizationUsecase_InviteAndAccept(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, notifier := newTestUsecase()
+
+	org, err := uc.Create(ctx, 1, &entity.CreateOrganizationRequest{Name: "Acme"})
+	require.NoError(t, err)
+
+	invitation, err := uc.Invite(ctx, 1, org.ID, &entity.InviteMemberRequest{Email: "Alice@Example.com", Role: entity.OrgRoleAdmin})
+	require.NoError(t, err)
+	require.Len(t, notifier.emails, 1)
+	assert.Equal(t, []string{"alice@example.com"}, notifier.emails[0].To)
+	link := invitationLink(t, notifier.emails[0])
+	assert.NotEqual(t, link.Token, invitation.TokenHash, "only the token hash is stored")
+
+	t.Run("other users cannot accept", func(t *testing.T) {
+		_, err := uc.AcceptInvitation(ctx, 3, link)
+		assert.True(t, errors.IsInvitationNotFound(err))
+	})
+
+	t.Run("invitee joins with the invited role", func(t *testing.T) {
+		joined, err := uc.AcceptInvitation(ctx, 2, link)
+		require.NoError(t, err)
+		assert.Equal(t, org.ID, joined.ID)
+		assert.Equal(t, entity.OrgRoleAdmin, joined.Role)
+		assert.Equal(t, entity.OrgRoleAdmin, repo.members[[2]int{org.ID, 2}].Role)
+	})
+
+	t.Run("invitation is used once", func(t *testing.T) {
+		_, err := uc.AcceptInvitation(ctx, 2, link)
+		assert.True(t, errors.IsInvitationNotFound(err))
+	})
+
+	t.Run("expired invitation is rejected", func(t *testing.T) {
+		_, err := uc.Invite(ctx, 1, org.ID, &entity.InviteMemberRequest{Email: "mallory@example.com", Role: entity.OrgRoleMember})
+		require.NoError(t, err)
+		uc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		defer func() { uc.now = time.Now }()
+
+		_, err = uc.AcceptInvitation(ctx, 3, invitationLink(t, notifier.emails[1]))
+		assert.True(t, errors.IsInvitationNotFound(err))
+	})
+}
+
+func TestOrganizationUsecase_SignedLinks(t *testing.T) {
+	ctx := context.Background()
+	uc, _, notifier := newTestUsecase()
+	uc.config.SigningKey = "invitation-secret"
+
+	org, err := uc.Create(ctx, 1, &entity.CreateOrganizationRequest{Name: "Acme"})
+	require.NoError(t, err)
+	_, err = uc.Invite(ctx, 1, org.ID, &entity.InviteMemberRequest{Email: "alice@example.com", Role: entity.OrgRoleMember})
+	require.NoError(t, err)
+	link := invitationLink(t, notifier.emails[0])
+	require.NotEmpty(t, link.Signature)
+
+	tests := []struct {
+		name string
+		link entity.InvitationLink
+	}{
+		{name: "bare token", link: entity.InvitationLink{Token: link.Token}},
+		{name: "extended expiry", link: entity.InvitationLink{Token: link.Token, Expires: link.Expires + 3600, Signature: link.Signature}},
+		{name: "forged signature", link: entity.InvitationLink{Token: link.Token, Expires: link.Expires, Signature: strings.Repeat("0", 64)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.VerifyInvitation(ctx, &tt.link)
+			assert.True(t, errors.IsInvitationNotFound(err))
+		})
+	}
+
+	t.Run("signed link", func(t *testing.T) {
+		invitation, err := uc.VerifyInvitation(ctx, link)
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com", invitation.Email)
+	})
+}
+
+func TestOrganizationUsecase_AdminInvite(t *testing.T) {
+	ctx := context.Background()
+	uc, _, notifier := newTestUsecase()
+
+	org, err := uc.Create(ctx, 1, &entity.CreateOrganizationRequest{Name: "Acme"})
+	require.NoError(t, err)
+
+	// User 3 is not a member, yet invites an owner as an administrator
+	invitation, err := uc.AdminInvite(ctx, 3, org.ID, &entity.InviteMemberRequest{Email: "new@example.com", Role: entity.OrgRoleOwner})
+	require.NoError(t, err)
+	assert.Equal(t, 3, invitation.InvitedBy)
+	require.Len(t, notifier.emails, 1)
+
+	_, err = uc.AdminInvite(ctx, 3, 99, &entity.InviteMemberRequest{Email: "new@example.com", Role: entity.OrgRoleMember})
+	assert.True(t, errors.IsOrgNotFound(err))
+}
+
+func TestOrganizationUsecase_Roles(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, _ := newTestUsecase()
+
+	org, err := uc.Create(ctx, 1, &entity.CreateOrganizationRequest{Name: "Acme"})
+	require.NoError(t, err)
+	repo.members[[2]int{org.ID, 2}] = entity.Membership{OrganizationID: org.ID, UserID: 2, Role: entity.OrgRoleAdmin}
+
+	t.Run("non-members do not see the organization", func(t *testing.T) {
+		_, err := uc.Get(ctx, 3, org.ID)
+		assert.True(t, errors.IsOrgNotFound(err))
+		_, err = uc.Invite(ctx, 3, org.ID, &entity.InviteMemberRequest{Email: "x@example.com", Role: entity.OrgRoleMember})
+		assert.True(t, errors.IsOrgNotFound(err))
+	})
+
+	t.Run("admins cannot invite owners", func(t *testing.T) {
+		_, err := uc.Invite(ctx, 2, org.ID, &entity.InviteMemberRequest{Email: "x@example.com", Role: entity.OrgRoleOwner})
+		assert.True(t, errors.IsOrgRoleRequired(err))
+	})
+
+	t.Run("admins cannot demote owners", func(t *testing.T) {
+		_, err := uc.UpdateMemberRole(ctx, 2, org.ID, 1, entity.OrgRoleMember)
+		assert.True(t, errors.IsOrgRoleRequired(err))
+	})
+
+	t.Run("the last owner cannot leave or be demoted", func(t *testing.T) {
+		err := uc.RemoveMember(ctx, 1, org.ID, 1)
+		assert.True(t, errors.IsLastOrgOwner(err))
+		_, err = uc.UpdateMemberRole(ctx, 1, org.ID, 1, entity.OrgRoleAdmin)
+		assert.True(t, errors.IsLastOrgOwner(err))
+	})
+
+	t.Run("owners hand over ownership", func(t *testing.T) {
+		_, err := uc.UpdateMemberRole(ctx, 1, org.ID, 2, entity.OrgRoleOwner)
+		require.NoError(t, err)
+		require.NoError(t, uc.RemoveMember(ctx, 1, org.ID, 1))
+		_, ok := repo.members[[2]int{org.ID, 1}]
+		assert.False(t, ok)
+	})
+}
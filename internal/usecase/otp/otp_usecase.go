@@ -0,0 +1,174 @@
+package otp
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// phonePattern accepts E.164 numbers, e.g. +6281234567890
+var phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// OTPUsecase issues one-time codes by SMS and verifies them.
+type OTPUsecase struct {
+	otpRepo              repository.OTPRepository
+	notificationProvider provider.NotificationProvider
+	config               config.OTPConfig
+	timeouts             *timeout.Policy
+	logger               *logger.Logger
+	now                  func() time.Time
+}
+
+// NewOTPUsecase creates a new one-time code use case.
+func NewOTPUsecase(otpRepo repository.OTPRepository, notificationProvider provider.NotificationProvider, cfg config.OTPConfig, log *logger.Logger) *OTPUsecase {
+	if cfg.Length < 4 {
+		cfg.Length = 6
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+
+	return &OTPUsecase{
+		otpRepo:              otpRepo,
+		notificationProvider: notificationProvider,
+		config:               cfg,
+		logger:               log,
+		now:                  time.Now,
+	}
+}
+
+// SetTimeouts bounds repository and provider calls with per-operation deadlines.
+func (uc *OTPUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	uc.timeouts = timeouts
+}
+
+// NormalizePhone strips formatting from a phone number and checks it is in E.164 format.
+func NormalizePhone(phone string) (string, error) {
+	normalized := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "").Replace(strings.TrimSpace(phone))
+	if !phonePattern.MatchString(normalized) {
+		return "", fmt.Errorf("%w: use the international format, e.g. +6281234567890", errors.ErrInvalidPhone)
+	}
+	return normalized, nil
+}
+
+// Send issues a new code for the purpose and texts it to the phone, replacing
+// any pending code. A new code can only be requested once per resend interval.
+func (uc *OTPUsecase) Send(ctx context.Context, purpose, phone, locale string) error {
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	pending, err := uc.otpRepo.Get(readCtx, purpose, phone)
+	cancel()
+	if err != nil && !errors.IsOTPNotFound(err) {
+		return fmt.Errorf("failed to check pending code: %w", err)
+	}
+	if pending != nil && uc.now().Sub(pending.CreatedAt) < uc.config.ResendInterval {
+		return fmt.Errorf("%w: wait before requesting a new code", errors.ErrOTPRateLimited)
+	}
+
+	code, err := generateCode(uc.config.Length)
+	if err != nil {
+		return fmt.Errorf("failed to generate code: %w", err)
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	err = uc.otpRepo.Save(writeCtx, &entity.OTPCode{
+		Purpose:   purpose,
+		Phone:     phone,
+		CodeHash:  hashCode(code),
+		ExpiresAt: uc.now().Add(uc.config.TTL),
+	})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to store code: %w", err)
+	}
+
+	callCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationNotification)
+	defer cancel()
+	_, err = uc.notificationProvider.SendTemplatedSMS(callCtx, &entity.TemplatedMessage{
+		Type:   entity.MessageTypeOTP,
+		Locale: locale,
+		To:     phone,
+		Data: map[string]interface{}{
+			"Code":      code,
+			"ExpiresIn": uc.config.TTL.String(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send code: %w", err)
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"purpose": purpose,
+		"action":  "otp_sent",
+	}).Info("One-time code sent")
+
+	return nil
+}
+
+// Verify checks the code for the purpose and phone. A correct code is used up;
+// once the attempts are exhausted the code is discarded and a new one is needed.
+func (uc *OTPUsecase) Verify(ctx context.Context, purpose, phone, code string) error {
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+
+	pending, err := uc.otpRepo.ConsumeAttempt(writeCtx, purpose, phone)
+	if err != nil {
+		if errors.IsOTPNotFound(err) {
+			return errors.ErrInvalidOTP
+		}
+		return fmt.Errorf("failed to check code: %w", err)
+	}
+
+	if !uc.now().Before(pending.ExpiresAt) {
+		uc.discard(writeCtx, purpose, phone)
+		return errors.ErrInvalidOTP
+	}
+	if pending.Attempts > uc.config.MaxAttempts {
+		uc.discard(writeCtx, purpose, phone)
+		return fmt.Errorf("%w: request a new code", errors.ErrOTPRateLimited)
+	}
+	if subtle.ConstantTimeCompare([]byte(hashCode(strings.TrimSpace(code))), []byte(pending.CodeHash)) != 1 {
+		return errors.ErrInvalidOTP
+	}
+
+	uc.discard(writeCtx, purpose, phone)
+	return nil
+}
+
+// discard deletes a used, expired or exhausted code. Failures are only logged:
+// the code still expires on its own.
+func (uc *OTPUsecase) discard(ctx context.Context, purpose, phone string) {
+	if err := uc.otpRepo.Delete(ctx, purpose, phone); err != nil {
+		uc.logger.ErrorLogger(ctx, err, "Failed to discard one-time code", map[string]interface{}{
+			"purpose": purpose,
+		})
+	}
+}
+
+// generateCode returns a random numeric code of the given length.
+func generateCode(length int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(length)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", length, n), nil
+}
+
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,173 @@
+package otp
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// memoryOTPRepository is an in-memory OTPRepository
+type memoryOTPRepository struct {
+	codes map[string]*entity.OTPCode
+}
+
+func newMemoryOTPRepository() *memoryOTPRepository {
+	return &memoryOTPRepository{codes: map[string]*entity.OTPCode{}}
+}
+
+func (r *memoryOTPRepository) Save(ctx context.Context, code *entity.OTPCode) error {
+	stored := *code
+	stored.Attempts = 0
+	stored.CreatedAt = time.Now()
+	r.codes[code.Purpose+code.Phone] = &stored
+	return nil
+}
+
+func (r *memoryOTPRepository) Get(ctx context.Context, purpose, phone string) (*entity.OTPCode, error) {
+	code, ok := r.codes[purpose+phone]
+	if !ok {
+		return nil, errors.ErrOTPNotFound
+	}
+	copied := *code
+	return &copied, nil
+}
+
+func (r *memoryOTPRepository) ConsumeAttempt(ctx context.Context, purpose, phone string) (*entity.OTPCode, error) {
+	code, ok := r.codes[purpose+phone]
+	if !ok {
+		return nil, errors.ErrOTPNotFound
+	}
+	code.Attempts++
+	copied := *code
+	return &copied, nil
+}
+
+func (r *memoryOTPRepository) Delete(ctx context.Context, purpose, phone string) error {
+	delete(r.codes, purpose+phone)
+	return nil
+}
+
+// MockNotificationProvider is a mock implementation of NotificationProvider
+type MockNotificationProvider struct {
+	mock.Mock
+}
+
+func (m *MockNotificationProvider) SendEmail(ctx context.Context, req *entity.EmailRequest) (*entity.EmailResponse, error) {
+	args := m.Called(ctx, req)
+	return nil, args.Error(1)
+}
+
+func (m *MockNotificationProvider) SendSMS(ctx context.Context, req *entity.SMSRequest) (*entity.SMSResponse, error) {
+	args := m.Called(ctx, req)
+	return nil, args.Error(1)
+}
+
+func (m *MockNotificationProvider) SendPushNotification(ctx context.Context, req *entity.PushNotificationRequest) (*entity.PushNotificationResponse, error) {
+	args := m.Called(ctx, req)
+	return nil, args.Error(1)
+}
+
+func (m *MockNotificationProvider) SendTemplatedSMS(ctx context.Context, msg *entity.TemplatedMessage) (*entity.SMSResponse, error) {
+	args := m.Called(ctx, msg)
+	return &entity.SMSResponse{}, args.Error(1)
+}
+
+func (m *MockNotificationProvider) SendTemplatedPush(ctx context.Context, msg *entity.TemplatedMessage) (*entity.PushNotificationResponse, error) {
+	args := m.Called(ctx, msg)
+	return nil, args.Error(1)
+}
+
+const testPhone = "+6281234567890"
+
+// newTestOTPUsecase returns a use case whose sent codes are written to sent.
+func newTestOTPUsecase(sent *string) (*OTPUsecase, *memoryOTPRepository) {
+	repo := newMemoryOTPRepository()
+	notifier := new(MockNotificationProvider)
+	notifier.On("SendTemplatedSMS", mock.Anything, mock.MatchedBy(func(msg *entity.TemplatedMessage) bool {
+		return msg.Type == entity.MessageTypeOTP && msg.To == testPhone
+	})).Run(func(args mock.Arguments) {
+		*sent = args.Get(1).(*entity.TemplatedMessage).Data["Code"].(string)
+	}).Return(nil, nil)
+
+	uc := NewOTPUsecase(repo, notifier, config.OTPConfig{
+		Length:         6,
+		TTL:            5 * time.Minute,
+		MaxAttempts:    3,
+		ResendInterval: time.Minute,
+	}, logger.NewLogger())
+	return uc, repo
+}
+
+func TestOTPUsecase_SendAndVerify(t *testing.T) {
+	var sent string
+	uc, repo := newTestOTPUsecase(&sent)
+
+	assert.NoError(t, uc.Send(context.Background(), entity.OTPPurposeLogin, testPhone, "en"))
+	assert.Len(t, sent, 6)
+
+	stored, err := repo.Get(context.Background(), entity.OTPPurposeLogin, testPhone)
+	assert.NoError(t, err)
+	assert.NotEqual(t, sent, stored.CodeHash, "codes are stored hashed")
+
+	// Another purpose does not accept the code
+	assert.True(t, errors.IsInvalidOTP(uc.Verify(context.Background(), entity.OTPPurposePhoneVerification, testPhone, sent)))
+
+	assert.NoError(t, uc.Verify(context.Background(), entity.OTPPurposeLogin, testPhone, sent))
+	assert.True(t, errors.IsInvalidOTP(uc.Verify(context.Background(), entity.OTPPurposeLogin, testPhone, sent)), "codes are single use")
+}
+
+func TestOTPUsecase_ResendInterval(t *testing.T) {
+	var sent string
+	uc, _ := newTestOTPUsecase(&sent)
+
+	assert.NoError(t, uc.Send(context.Background(), entity.OTPPurposeLogin, testPhone, ""))
+	assert.True(t, errors.IsOTPRateLimited(uc.Send(context.Background(), entity.OTPPurposeLogin, testPhone, "")))
+
+	uc.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	assert.NoError(t, uc.Send(context.Background(), entity.OTPPurposeLogin, testPhone, ""))
+}
+
+func TestOTPUsecase_MaxAttempts(t *testing.T) {
+	var sent string
+	uc, _ := newTestOTPUsecase(&sent)
+	assert.NoError(t, uc.Send(context.Background(), entity.OTPPurposeLogin, testPhone, ""))
+
+	wrong := "000000"
+	if sent == wrong {
+		wrong = "111111"
+	}
+	for i := 0; i < 3; i++ {
+		assert.True(t, errors.IsInvalidOTP(uc.Verify(context.Background(), entity.OTPPurposeLogin, testPhone, wrong)))
+	}
+
+	// The correct code no longer works once the attempts are used up
+	assert.True(t, errors.IsOTPRateLimited(uc.Verify(context.Background(), entity.OTPPurposeLogin, testPhone, sent)))
+	assert.True(t, errors.IsInvalidOTP(uc.Verify(context.Background(), entity.OTPPurposeLogin, testPhone, sent)))
+}
+
+func TestOTPUsecase_Expired(t *testing.T) {
+	var sent string
+	uc, _ := newTestOTPUsecase(&sent)
+	assert.NoError(t, uc.Send(context.Background(), entity.OTPPurposeLogin, testPhone, ""))
+
+	uc.now = func() time.Time { return time.Now().Add(10 * time.Minute) }
+	assert.True(t, errors.IsInvalidOTP(uc.Verify(context.Background(), entity.OTPPurposeLogin, testPhone, sent)))
+}
+
+func TestNormalizePhone(t *testing.T) {
+	phone, err := NormalizePhone(" +62 812-3456-7890 ")
+	assert.NoError(t, err)
+	assert.Equal(t, testPhone, phone)
+
+	for _, invalid := range []string{"", "081234567890", "+0812345678", "+12", "+62abc4567890"} {
+		_, err := NormalizePhone(invalid)
+		assert.True(t, errors.IsInvalidPhone(err), invalid)
+	}
+}
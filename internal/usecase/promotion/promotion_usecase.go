@@ -0,0 +1,124 @@
+package promotion
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PromotionUsecase handles coupon administration.
+type PromotionUsecase struct {
+	couponRepo repository.CouponRepository
+	timeouts   *timeout.Policy
+}
+
+// NewPromotionUsecase creates a new promotion use case.
+func NewPromotionUsecase(couponRepo repository.CouponRepository) *PromotionUsecase {
+	return &PromotionUsecase{
+		couponRepo: couponRepo,
+	}
+}
+
+// SetTimeouts bounds repository calls with per-operation deadlines.
+func (uc *PromotionUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	uc.timeouts = timeouts
+}
+
+// CreateCoupon creates a coupon. Codes are stored upper-cased and must be unique.
+func (uc *PromotionUsecase) CreateCoupon(ctx context.Context, req *entity.CouponRequest) (*entity.Coupon, error) {
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+
+	coupon := &entity.Coupon{Active: true}
+	if err := uc.applyRequest(ctx, coupon, req); err != nil {
+		return nil, err
+	}
+
+	if err := uc.couponRepo.Create(ctx, coupon); err != nil {
+		return nil, fmt.Errorf("failed to create coupon: %w", err)
+	}
+
+	return coupon, nil
+}
+
+// GetCoupon returns a coupon by ID.
+func (uc *PromotionUsecase) GetCoupon(ctx context.Context, id int) (*entity.Coupon, error) {
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	return uc.couponRepo.GetByID(ctx, id)
+}
+
+// ListCoupons returns all coupons, newest first.
+func (uc *PromotionUsecase) ListCoupons(ctx context.Context) ([]*entity.Coupon, error) {
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	return uc.couponRepo.List(ctx)
+}
+
+// UpdateCoupon replaces the coupon's settings. The redeemed count is kept.
+func (uc *PromotionUsecase) UpdateCoupon(ctx context.Context, id int, req *entity.CouponRequest) (*entity.Coupon, error) {
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+
+	coupon, err := uc.couponRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.applyRequest(ctx, coupon, req); err != nil {
+		return nil, err
+	}
+
+	if err := uc.couponRepo.Update(ctx, coupon); err != nil {
+		return nil, fmt.Errorf("failed to update coupon: %w", err)
+	}
+
+	return coupon, nil
+}
+
+// DeleteCoupon deletes a coupon together with its redemption history.
+func (uc *PromotionUsecase) DeleteCoupon(ctx context.Context, id int) error {
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+
+	return uc.couponRepo.Delete(ctx, id)
+}
+
+// applyRequest validates the request and copies it onto the coupon.
+func (uc *PromotionUsecase) applyRequest(ctx context.Context, coupon *entity.Coupon, req *entity.CouponRequest) error {
+	code := entity.NormalizeCouponCode(req.Code)
+	if code == "" {
+		return fmt.Errorf("%w: code is required", errors.ErrInvalidCoupon)
+	}
+	if req.Type == entity.CouponTypePercentage && req.Value > 100 {
+		return fmt.Errorf("%w: percentage must be at most 100", errors.ErrInvalidCoupon)
+	}
+
+	existing, err := uc.couponRepo.GetByCode(ctx, code)
+	if err != nil && !errors.IsCouponNotFound(err) {
+		return fmt.Errorf("failed to check coupon code: %w", err)
+	}
+	if existing != nil && existing.ID != coupon.ID {
+		return errors.ErrCouponAlreadyExists
+	}
+
+	coupon.Code = code
+	coupon.Type = req.Type
+	coupon.Value = req.Value
+	coupon.Currency = strings.ToUpper(req.Currency)
+	coupon.MinOrderAmount = req.MinOrderAmount
+	coupon.MaxRedemptions = req.MaxRedemptions
+	coupon.PerUserLimit = req.PerUserLimit
+	coupon.ExpiresAt = req.ExpiresAt
+	if req.Active != nil {
+		coupon.Active = *req.Active
+	}
+
+	return nil
+}
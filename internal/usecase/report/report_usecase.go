@@ -0,0 +1,205 @@
+package report
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultDays is the period of a report without a range
+	DefaultDays = 30
+	// MaxRollupDays is the longest range rolled up by one job
+	MaxRollupDays = 366
+
+	dayLayout = "2006-01-02"
+)
+
+// JobQueue queues background jobs
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobType string, payload interface{}) error
+}
+
+// ReportUsecase serves reports from the reporting tables and keeps them up to
+// date by rolling up orders and sign-ups in background jobs.
+type ReportUsecase struct {
+	reportRepo repository.ReportRepository
+	rollupDays int
+	jobs       JobQueue
+	timeouts   *timeout.Policy
+	logger     *logger.Logger
+	now        func() time.Time
+}
+
+// NewReportUsecase creates a new report use case
+func NewReportUsecase(reportRepo repository.ReportRepository, cfg config.ReportsConfig, log *logger.Logger) *ReportUsecase {
+	return &ReportUsecase{
+		reportRepo: reportRepo,
+		rollupDays: cfg.RollupDays,
+		logger:     log,
+		now:        time.Now,
+	}
+}
+
+// SetTimeouts bounds repository calls with per-operation deadlines.
+func (uc *ReportUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	uc.timeouts = timeouts
+}
+
+// SetJobQueue enables RequestRollup, which queues rollup jobs on jobs.
+func (uc *ReportUsecase) SetJobQueue(jobs JobQueue) {
+	uc.jobs = jobs
+}
+
+// Revenue returns the orders and revenue per period and currency
+func (uc *ReportUsecase) Revenue(ctx context.Context, query *entity.ReportQuery) (*entity.RevenueReport, error) {
+	from, to, groupBy, err := uc.parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	rows, err := uc.reportRepo.Revenue(ctx, from, to, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.RevenueReport{From: from.Format(dayLayout), To: to.Format(dayLayout), GroupBy: groupBy, Rows: rows}, nil
+}
+
+// Signups returns the sign-ups per period
+func (uc *ReportUsecase) Signups(ctx context.Context, query *entity.ReportQuery) (*entity.SignupReport, error) {
+	from, to, groupBy, err := uc.parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	rows, err := uc.reportRepo.Signups(ctx, from, to, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.SignupReport{From: from.Format(dayLayout), To: to.Format(dayLayout), GroupBy: groupBy, Rows: rows}, nil
+}
+
+// RequestRollup queues a job rebuilding the reporting tables for the range,
+// to backfill them or to include changes to older orders such as refunds.
+func (uc *ReportUsecase) RequestRollup(ctx context.Context, req *entity.ReportRollupRequest) error {
+	from, to, err := parseRange(req.From, req.To)
+	if err != nil {
+		return err
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > MaxRollupDays {
+		return fmt.Errorf("%w: at most %d days can be rolled up at once", errors.ErrInvalidReportQuery, MaxRollupDays)
+	}
+	if uc.jobs == nil {
+		return fmt.Errorf("report rollup jobs are not configured")
+	}
+	return uc.jobs.Enqueue(ctx, entity.JobTypeReportRollup, req)
+}
+
+// Rollup rolls up the days of the payload, an entity.ReportRollupRequest, as
+// a background job. The scheduled job has no range and rolls up the last
+// days, today included. Rolling up a day again replaces its rows, so the job
+// can be retried.
+func (uc *ReportUsecase) Rollup(ctx context.Context, payload json.RawMessage) error {
+	var req entity.ReportRollupRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return fmt.Errorf("failed to decode rollup payload: %w", err)
+	}
+
+	var from, to time.Time
+	if req.From == "" && req.To == "" {
+		to = uc.today()
+		from = to.AddDate(0, 0, 1-max(uc.rollupDays, 1))
+	} else {
+		var err error
+		if from, to, err = parseRange(req.From, req.To); err != nil {
+			return err
+		}
+	}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+		err := uc.reportRepo.Rollup(writeCtx, day)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"from": from.Format(dayLayout),
+		"to":   to.Format(dayLayout),
+	}).Info("Reports rolled up")
+	return nil
+}
+
+// parseQuery validates the query, defaulting to the last DefaultDays days
+// grouped by day
+func (uc *ReportUsecase) parseQuery(query *entity.ReportQuery) (time.Time, time.Time, string, error) {
+	groupBy := query.GroupBy
+	switch groupBy {
+	case "":
+		groupBy = entity.ReportGroupDay
+	case entity.ReportGroupDay, entity.ReportGroupWeek, entity.ReportGroupMonth:
+	default:
+		return time.Time{}, time.Time{}, "", fmt.Errorf("%w: group_by must be day, week or month", errors.ErrInvalidReportQuery)
+	}
+
+	toValue := query.To
+	if toValue == "" {
+		toValue = uc.today().Format(dayLayout)
+	}
+	fromValue := query.From
+	if fromValue == "" {
+		to, err := parseDay("to", toValue)
+		if err != nil {
+			return time.Time{}, time.Time{}, "", err
+		}
+		fromValue = to.AddDate(0, 0, 1-DefaultDays).Format(dayLayout)
+	}
+
+	from, to, err := parseRange(fromValue, toValue)
+	return from, to, groupBy, err
+}
+
+// today returns the current UTC date
+func (uc *ReportUsecase) today() time.Time {
+	now := uc.now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// parseRange parses the days of a range and checks it is in order
+func parseRange(fromValue, toValue string) (time.Time, time.Time, error) {
+	from, err := parseDay("from", fromValue)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseDay("to", toValue)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from is after to", errors.ErrInvalidReportQuery)
+	}
+	return from, to, nil
+}
+
+func parseDay(name, value string) (time.Time, error) {
+	day, err := time.Parse(dayLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be a date (YYYY-MM-DD)", errors.ErrInvalidReportQuery, name)
+	}
+	return day, nil
+}
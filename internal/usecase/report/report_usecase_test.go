@@ -0,0 +1,138 @@
+package report
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockReportRepository is a mock implementation of ReportRepository
+type MockReportRepository struct {
+	mock.Mock
+}
+
+func (m *MockReportRepository) Rollup(ctx context.Context, day time.Time) error {
+	args := m.Called(ctx, day)
+	return args.Error(0)
+}
+
+func (m *MockReportRepository) Revenue(ctx context.Context, from, to time.Time, groupBy string) ([]entity.RevenueReportRow, error) {
+	args := m.Called(ctx, from, to, groupBy)
+	return args.Get(0).([]entity.RevenueReportRow), args.Error(1)
+}
+
+func (m *MockReportRepository) Signups(ctx context.Context, from, to time.Time, groupBy string) ([]entity.SignupReportRow, error) {
+	args := m.Called(ctx, from, to, groupBy)
+	return args.Get(0).([]entity.SignupReportRow), args.Error(1)
+}
+
+// MockJobQueue is a mock implementation of JobQueue
+type MockJobQueue struct {
+	mock.Mock
+}
+
+func (m *MockJobQueue) Enqueue(ctx context.Context, jobType string, payload interface{}) error {
+	args := m.Called(ctx, jobType, payload)
+	return args.Error(0)
+}
+
+func date(value string) time.Time {
+	t, _ := time.Parse(dayLayout, value)
+	return t
+}
+
+func newReportUsecase(repo *MockReportRepository) *ReportUsecase {
+	uc := NewReportUsecase(repo, config.ReportsConfig{RollupInterval: 24 * time.Hour, RollupDays: 3}, logger.NewLogger())
+	uc.now = func() time.Time { return time.Date(2024, 3, 10, 23, 0, 0, 0, time.UTC) }
+	return uc
+}
+
+func TestReportUsecase_Revenue(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    entity.ReportQuery
+		wantFrom string
+		wantTo   string
+		wantBy   string
+	}{
+		{name: "defaults", wantFrom: "2024-02-10", wantTo: "2024-03-10", wantBy: entity.ReportGroupDay},
+		{name: "range", query: entity.ReportQuery{From: "2024-01-01", To: "2024-01-31", GroupBy: entity.ReportGroupWeek}, wantFrom: "2024-01-01", wantTo: "2024-01-31", wantBy: entity.ReportGroupWeek},
+		{name: "open start", query: entity.ReportQuery{To: "2024-01-31", GroupBy: entity.ReportGroupMonth}, wantFrom: "2024-01-02", wantTo: "2024-01-31", wantBy: entity.ReportGroupMonth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockReportRepository)
+			rows := []entity.RevenueReportRow{{Period: tt.wantFrom, Currency: "USD", Orders: 2, Completed: 2, Revenue: 50}}
+			repo.On("Revenue", mock.Anything, date(tt.wantFrom), date(tt.wantTo), tt.wantBy).Return(rows, nil)
+
+			report, err := newReportUsecase(repo).Revenue(context.Background(), &tt.query)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFrom, report.From)
+			assert.Equal(t, tt.wantTo, report.To)
+			assert.Equal(t, tt.wantBy, report.GroupBy)
+			assert.Equal(t, rows, report.Rows)
+		})
+	}
+}
+
+func TestReportUsecase_InvalidQuery(t *testing.T) {
+	uc := newReportUsecase(new(MockReportRepository))
+
+	for _, query := range []entity.ReportQuery{
+		{GroupBy: "year"},
+		{From: "01/02/2024"},
+		{From: "2024-02-01", To: "2024-01-01"},
+	} {
+		_, err := uc.Signups(context.Background(), &query)
+		assert.True(t, errors.IsInvalidReportQuery(err), "%+v: %v", query, err)
+	}
+}
+
+func TestReportUsecase_Rollup(t *testing.T) {
+	t.Run("scheduled rolls up the last days", func(t *testing.T) {
+		repo := new(MockReportRepository)
+		repo.On("Rollup", mock.Anything, mock.Anything).Return(nil)
+
+		require.NoError(t, newReportUsecase(repo).Rollup(context.Background(), json.RawMessage("{}")))
+
+		repo.AssertNumberOfCalls(t, "Rollup", 3)
+		repo.AssertCalled(t, "Rollup", mock.Anything, date("2024-03-08"))
+		repo.AssertCalled(t, "Rollup", mock.Anything, date("2024-03-10"))
+	})
+
+	t.Run("requested range", func(t *testing.T) {
+		repo := new(MockReportRepository)
+		repo.On("Rollup", mock.Anything, mock.Anything).Return(nil)
+		payload, _ := json.Marshal(entity.ReportRollupRequest{From: "2024-01-30", To: "2024-02-02"})
+
+		require.NoError(t, newReportUsecase(repo).Rollup(context.Background(), payload))
+
+		repo.AssertNumberOfCalls(t, "Rollup", 4)
+		repo.AssertCalled(t, "Rollup", mock.Anything, date("2024-02-02"))
+	})
+}
+
+func TestReportUsecase_RequestRollup(t *testing.T) {
+	jobs := new(MockJobQueue)
+	jobs.On("Enqueue", mock.Anything, entity.JobTypeReportRollup, mock.Anything).Return(nil)
+	uc := newReportUsecase(new(MockReportRepository))
+	uc.SetJobQueue(jobs)
+
+	require.NoError(t, uc.RequestRollup(context.Background(), &entity.ReportRollupRequest{From: "2023-03-11", To: "2024-03-10"}))
+	jobs.AssertNumberOfCalls(t, "Enqueue", 1)
+
+	err := uc.RequestRollup(context.Background(), &entity.ReportRollupRequest{From: "2023-03-09", To: "2024-03-10"})
+	assert.True(t, errors.IsInvalidReportQuery(err))
+	jobs.AssertNumberOfCalls(t, "Enqueue", 1)
+}
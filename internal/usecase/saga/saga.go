@@ -0,0 +1,272 @@
+// Package saga runs operations made of steps that cannot share a transaction,
+// such as ones calling a payment provider, and undoes the completed steps when
+// a later one fails. Progress is persisted after every step, so sagas
+// interrupted by a crash are compensated by Recover.
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/timeout"
+)
+
+// recoverBatchSize is the number of interrupted sagas claimed at a time
+const recoverBatchSize = 100
+
+// ErrHalted marks a step failure that stops the saga without compensating,
+// such as a payment deferred until the provider is available again. Steps
+// wrap it together with the error that halted them.
+var ErrHalted = stderrors.New("saga halted")
+
+// Func is a step action or compensation. It reads and records what the
+// compensations need in the saga state.
+type Func func(ctx context.Context, state entity.SagaState) error
+
+// Step is one step of a saga
+type Step struct {
+	Name   string
+	Action Func
+	// Compensate undoes the completed step when a later one fails; nil when
+	// there is nothing to undo. After a crash it may run again, so it should
+	// tolerate the step being undone already.
+	Compensate Func
+}
+
+// Definition is a named sequence of steps
+type Definition struct {
+	Name  string
+	Steps []Step
+	// Completed reports whether a saga interrupted after its last action ran
+	// had reached its outcome, so recovery does not undo it. Optional.
+	Completed func(ctx context.Context, state entity.SagaState) (bool, error)
+}
+
+func (d *Definition) step(name string) (Step, bool) {
+	for _, step := range d.Steps {
+		if step.Name == name {
+			return step, true
+		}
+	}
+	return Step{}, false
+}
+
+// Orchestrator runs sagas, persisting their progress, and compensates the
+// sagas interrupted before they completed. Without a repository, progress is
+// kept in memory only.
+type Orchestrator struct {
+	repo        repository.SagaRepository
+	definitions map[string]*Definition
+	staleAfter  time.Duration
+	retention   time.Duration
+	timeouts    *timeout.Policy
+	logger      *logger.Logger
+}
+
+// NewOrchestrator creates a saga orchestrator persisting to repo, which may
+// be nil
+func NewOrchestrator(repo repository.SagaRepository, cfg config.SagaConfig, log *logger.Logger) *Orchestrator {
+	return &Orchestrator{
+		repo:        repo,
+		definitions: make(map[string]*Definition),
+		staleAfter:  cfg.StaleAfter,
+		retention:   cfg.Retention,
+		logger:      log,
+	}
+}
+
+// SetTimeouts bounds repository calls with per-operation deadlines.
+func (o *Orchestrator) SetTimeouts(timeouts *timeout.Policy) {
+	o.timeouts = timeouts
+}
+
+// Register makes the sagas of the definition recoverable. Only its
+// compensations run on recovery.
+func (o *Orchestrator) Register(def *Definition) {
+	o.definitions[def.Name] = def
+}
+
+// Run runs the steps of the saga in order. When a step fails, the steps
+// completed before it are compensated in reverse order and the step error is
+// returned; a step error wrapping ErrHalted is returned without compensating.
+func (o *Orchestrator) Run(ctx context.Context, def *Definition, reference string, state entity.SagaState) error {
+	saga := &entity.Saga{
+		Name:      def.Name,
+		Reference: reference,
+		Status:    entity.SagaStatusRunning,
+		Steps:     []string{},
+		State:     state,
+	}
+	if o.repo != nil {
+		writeCtx, cancel := o.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+		err := o.repo.Create(writeCtx, saga)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, step := range def.Steps {
+		if err := step.Action(ctx, saga.State); err != nil {
+			saga.Error = fmt.Sprintf("%s: %v", step.Name, err)
+			if stderrors.Is(err, ErrHalted) {
+				saga.Status = entity.SagaStatusHalted
+				o.save(ctx, saga)
+				return err
+			}
+
+			// Compensate even when the request was cancelled
+			o.compensate(context.WithoutCancel(ctx), def, saga)
+			return err
+		}
+		saga.Steps = append(saga.Steps, step.Name)
+		o.save(ctx, saga)
+	}
+
+	saga.Status = entity.SagaStatusCompleted
+	o.save(ctx, saga)
+	return nil
+}
+
+// compensate undoes the completed steps of the saga in reverse order,
+// recording each one undone. A compensation that fails leaves the saga failed.
+func (o *Orchestrator) compensate(ctx context.Context, def *Definition, saga *entity.Saga) {
+	saga.Status = entity.SagaStatusCompensating
+	o.save(ctx, saga)
+
+	for len(saga.Steps) > 0 {
+		name := saga.Steps[len(saga.Steps)-1]
+		step, ok := def.step(name)
+		var err error
+		switch {
+		case !ok:
+			err = fmt.Errorf("unknown step")
+		case step.Compensate != nil:
+			err = step.Compensate(ctx, saga.State)
+		}
+		if err != nil {
+			saga.Status = entity.SagaStatusFailed
+			saga.Error = fmt.Sprintf("%s; compensating %s: %v", saga.Error, name, err)
+			o.save(ctx, saga)
+			o.logger.WithContext(ctx).WithError(err).WithFields(map[string]interface{}{
+				"saga":      saga.Name,
+				"reference": saga.Reference,
+				"step":      name,
+				"state":     saga.State,
+			}).Error("Saga compensation failed, needs manual attention")
+			return
+		}
+		saga.Steps = saga.Steps[:len(saga.Steps)-1]
+		o.save(ctx, saga)
+	}
+
+	saga.Status = entity.SagaStatusCompensated
+	o.save(ctx, saga)
+	o.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"saga":      saga.Name,
+		"reference": saga.Reference,
+		"error":     saga.Error,
+	}).Warn("Saga compensated")
+}
+
+// save records the progress of the saga. A saga whose progress is not
+// recorded may be compensated again on recovery, which compensations tolerate.
+func (o *Orchestrator) save(ctx context.Context, saga *entity.Saga) {
+	if o.repo == nil {
+		return
+	}
+	writeCtx, cancel := o.timeouts.WithTimeout(context.WithoutCancel(ctx), timeout.OperationDBWrite)
+	defer cancel()
+	if err := o.repo.Update(writeCtx, saga); err != nil {
+		o.logger.ErrorLogger(ctx, err, "Failed to record saga progress", map[string]interface{}{
+			"saga":      saga.Name,
+			"reference": saga.Reference,
+			"status":    saga.Status,
+		})
+	}
+}
+
+// Recover compensates the sagas not updated for the stale period, which were
+// interrupted by a crash, unless they had completed, and deletes the finished
+// sagas past retention. It runs as a scheduled job.
+func (o *Orchestrator) Recover(ctx context.Context, _ json.RawMessage) error {
+	if o.repo == nil {
+		return nil
+	}
+
+	for {
+		writeCtx, cancel := o.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+		sagas, err := o.repo.ClaimStale(writeCtx, time.Now().Add(-o.staleAfter), recoverBatchSize)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		for _, saga := range sagas {
+			o.recover(ctx, saga)
+		}
+		if len(sagas) < recoverBatchSize {
+			break
+		}
+	}
+
+	if o.retention <= 0 {
+		return nil
+	}
+	writeCtx, cancel := o.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	deleted, err := o.repo.DeleteFinished(writeCtx, time.Now().Add(-o.retention))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		o.logger.WithContext(ctx).WithField("deleted", deleted).Info("Finished sagas deleted")
+	}
+	return nil
+}
+
+// recover compensates a claimed saga
+func (o *Orchestrator) recover(ctx context.Context, saga *entity.Saga) {
+	log := o.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"saga":      saga.Name,
+		"reference": saga.Reference,
+		"steps":     saga.Steps,
+	})
+
+	def, ok := o.definitions[saga.Name]
+	if !ok {
+		saga.Status = entity.SagaStatusFailed
+		saga.Error = "no definition registered for recovery"
+		o.save(ctx, saga)
+		log.Error("Interrupted saga cannot be recovered, needs manual attention")
+		return
+	}
+
+	if def.Completed != nil {
+		completed, err := def.Completed(ctx, saga.State)
+		if err != nil {
+			// Left compensating, so it is claimed again once stale
+			log.WithError(err).Error("Failed to check interrupted saga")
+			return
+		}
+		if completed {
+			saga.Status = entity.SagaStatusCompleted
+			o.save(ctx, saga)
+			log.Info("Interrupted saga had completed")
+			return
+		}
+	}
+
+	log.Warn("Compensating interrupted saga")
+	if saga.Error == "" {
+		saga.Error = "interrupted"
+	}
+	o.compensate(ctx, def, saga)
+}
@@ -0,0 +1,212 @@
+package saga
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sagaStore is an in-memory SagaRepository
+type sagaStore struct {
+	mu    sync.Mutex
+	sagas map[int]entity.Saga
+}
+
+func newSagaStore() *sagaStore {
+	return &sagaStore{sagas: make(map[int]entity.Saga)}
+}
+
+func (s *sagaStore) put(saga *entity.Saga) {
+	stored := *saga
+	stored.Steps = append([]string(nil), saga.Steps...)
+	stored.State = make(entity.SagaState, len(saga.State))
+	for k, v := range saga.State {
+		stored.State[k] = v
+	}
+	s.sagas[saga.ID] = stored
+}
+
+func (s *sagaStore) Create(ctx context.Context, saga *entity.Saga) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saga.ID = len(s.sagas) + 1
+	saga.CreatedAt, saga.UpdatedAt = time.Now(), time.Now()
+	s.put(saga)
+	return nil
+}
+
+func (s *sagaStore) Update(ctx context.Context, saga *entity.Saga) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saga.UpdatedAt = time.Now()
+	s.put(saga)
+	return nil
+}
+
+func (s *sagaStore) ClaimStale(ctx context.Context, before time.Time, limit int) ([]*entity.Saga, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var claimed []*entity.Saga
+	for id, saga := range s.sagas {
+		stale := saga.Status == entity.SagaStatusRunning || saga.Status == entity.SagaStatusCompensating
+		if stale && saga.UpdatedAt.Before(before) && len(claimed) < limit {
+			saga.Status, saga.UpdatedAt = entity.SagaStatusCompensating, time.Now()
+			s.sagas[id] = saga
+			claimed = append(claimed, &saga)
+		}
+	}
+	return claimed, nil
+}
+
+func (s *sagaStore) DeleteFinished(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (s *sagaStore) get(id int) entity.Saga {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sagas[id]
+}
+
+// recorder builds steps that record their actions and compensations
+type recorder struct {
+	calls []string
+}
+
+func (r *recorder) step(name string, fail error) Step {
+	return Step{
+		Name: name,
+		Action: func(ctx context.Context, state entity.SagaState) error {
+			r.calls = append(r.calls, name)
+			if fail != nil {
+				return fail
+			}
+			state[name] = "done"
+			return nil
+		},
+		Compensate: func(ctx context.Context, state entity.SagaState) error {
+			r.calls = append(r.calls, "undo "+name)
+			return nil
+		},
+	}
+}
+
+func TestOrchestrator_Run(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("completed steps are compensated in reverse order", func(t *testing.T) {
+		store, rec := newSagaStore(), &recorder{}
+		o := NewOrchestrator(store, config.SagaConfig{}, logger.NewLogger())
+		failure := stderrors.New("card declined")
+		def := &Definition{Name: "test", Steps: []Step{
+			rec.step("reserve", nil), rec.step("charge", nil), rec.step("ship", failure),
+		}}
+
+		err := o.Run(ctx, def, "ref_1", entity.SagaState{})
+		assert.ErrorIs(t, err, failure)
+		assert.Equal(t, []string{"reserve", "charge", "ship", "undo charge", "undo reserve"}, rec.calls)
+
+		saga := store.get(1)
+		assert.Equal(t, entity.SagaStatusCompensated, saga.Status)
+		assert.Empty(t, saga.Steps)
+		assert.Equal(t, "ship: card declined", saga.Error)
+	})
+
+	t.Run("a halted saga is not compensated", func(t *testing.T) {
+		store, rec := newSagaStore(), &recorder{}
+		o := NewOrchestrator(store, config.SagaConfig{}, logger.NewLogger())
+		def := &Definition{Name: "test", Steps: []Step{
+			rec.step("reserve", nil), rec.step("charge", fmt.Errorf("%w: provider down", ErrHalted)),
+		}}
+
+		assert.ErrorIs(t, o.Run(ctx, def, "ref_1", entity.SagaState{}), ErrHalted)
+		assert.Equal(t, []string{"reserve", "charge"}, rec.calls)
+		assert.Equal(t, entity.SagaStatusHalted, store.get(1).Status)
+	})
+
+	t.Run("a failed compensation leaves the saga failed", func(t *testing.T) {
+		store, rec := newSagaStore(), &recorder{}
+		o := NewOrchestrator(store, config.SagaConfig{}, logger.NewLogger())
+		reserve := rec.step("reserve", nil)
+		charge := rec.step("charge", nil)
+		charge.Compensate = func(ctx context.Context, state entity.SagaState) error {
+			return stderrors.New("refund rejected")
+		}
+		def := &Definition{Name: "test", Steps: []Step{reserve, charge, rec.step("ship", stderrors.New("no stock"))}}
+
+		require.Error(t, o.Run(ctx, def, "ref_1", entity.SagaState{}))
+		saga := store.get(1)
+		assert.Equal(t, entity.SagaStatusFailed, saga.Status)
+		assert.Equal(t, []string{"reserve", "charge"}, saga.Steps)
+		assert.Contains(t, saga.Error, "compensating charge: refund rejected")
+	})
+
+	t.Run("without a repository progress is kept in memory", func(t *testing.T) {
+		rec := &recorder{}
+		o := NewOrchestrator(nil, config.SagaConfig{}, logger.NewLogger())
+		def := &Definition{Name: "test", Steps: []Step{rec.step("reserve", nil), rec.step("charge", nil)}}
+
+		state := entity.SagaState{}
+		require.NoError(t, o.Run(ctx, def, "ref_1", state))
+		assert.Equal(t, entity.SagaState{"reserve": "done", "charge": "done"}, state)
+	})
+}
+
+func TestOrchestrator_Recover(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("an interrupted saga is compensated", func(t *testing.T) {
+		store, rec := newSagaStore(), &recorder{}
+		o := NewOrchestrator(store, config.SagaConfig{StaleAfter: time.Millisecond}, logger.NewLogger())
+		o.Register(&Definition{Name: "test", Steps: []Step{rec.step("reserve", nil), rec.step("charge", nil)}})
+		interrupted := &entity.Saga{Name: "test", Status: entity.SagaStatusRunning, Steps: []string{"reserve", "charge"}}
+		require.NoError(t, store.Create(ctx, interrupted))
+		time.Sleep(5 * time.Millisecond)
+
+		require.NoError(t, o.Recover(ctx, nil))
+		assert.Equal(t, []string{"undo charge", "undo reserve"}, rec.calls)
+		assert.Equal(t, entity.SagaStatusCompensated, store.get(interrupted.ID).Status)
+	})
+
+	t.Run("a saga that had completed is not undone", func(t *testing.T) {
+		store, rec := newSagaStore(), &recorder{}
+		o := NewOrchestrator(store, config.SagaConfig{StaleAfter: time.Millisecond}, logger.NewLogger())
+		o.Register(&Definition{
+			Name:  "test",
+			Steps: []Step{rec.step("reserve", nil)},
+			Completed: func(ctx context.Context, state entity.SagaState) (bool, error) {
+				return state["shipped"] != "", nil
+			},
+		})
+		interrupted := &entity.Saga{Name: "test", Status: entity.SagaStatusRunning, Steps: []string{"reserve"},
+			State: entity.SagaState{"shipped": "yes"}}
+		require.NoError(t, store.Create(ctx, interrupted))
+		time.Sleep(5 * time.Millisecond)
+
+		require.NoError(t, o.Recover(ctx, nil))
+		assert.Empty(t, rec.calls)
+		assert.Equal(t, entity.SagaStatusCompleted, store.get(interrupted.ID).Status)
+	})
+
+	t.Run("sagas still in progress are left alone", func(t *testing.T) {
+		store, rec := newSagaStore(), &recorder{}
+		o := NewOrchestrator(store, config.SagaConfig{StaleAfter: time.Hour}, logger.NewLogger())
+		o.Register(&Definition{Name: "test", Steps: []Step{rec.step("reserve", nil)}})
+		running := &entity.Saga{Name: "test", Status: entity.SagaStatusRunning, Steps: []string{"reserve"}}
+		require.NoError(t, store.Create(ctx, running))
+
+		require.NoError(t, o.Recover(ctx, nil))
+		assert.Empty(t, rec.calls)
+		assert.Equal(t, entity.SagaStatusRunning, store.get(running.ID).Status)
+	})
+}
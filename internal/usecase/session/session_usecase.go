@@ -0,0 +1,123 @@
+package session
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"fmt"
+	"time"
+)
+
+// touchInterval limits how often a session's last_seen_at is written.
+const touchInterval = time.Minute
+
+// SessionUsecase handles session listing and revocation.
+type SessionUsecase struct {
+	sessionRepo repository.SessionRepository
+	timeouts    *timeout.Policy
+	allowLegacy bool
+}
+
+// NewSessionUsecase creates a new session use case.
+func NewSessionUsecase(sessionRepo repository.SessionRepository) *SessionUsecase {
+	return &SessionUsecase{
+		sessionRepo: sessionRepo,
+	}
+}
+
+// SetTimeouts bounds repository calls with per-operation deadlines.
+func (uc *SessionUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	uc.timeouts = timeouts
+}
+
+// AllowLegacyTokens controls whether tokens without a session ID, issued
+// before sessions were introduced, are still accepted.
+func (uc *SessionUsecase) AllowLegacyTokens(allow bool) {
+	uc.allowLegacy = allow
+}
+
+// ListSessions returns the user's active sessions, flagging the one tied to currentTokenID.
+func (uc *SessionUsecase) ListSessions(ctx context.Context, userID int, currentTokenID string) ([]*entity.Session, error) {
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	sessions, err := uc.sessionRepo.ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	for _, s := range sessions {
+		s.Current = s.TokenID == currentTokenID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes a single session owned by the user.
+func (uc *SessionUsecase) RevokeSession(ctx context.Context, userID, sessionID int) error {
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+
+	return uc.sessionRepo.Revoke(ctx, userID, sessionID)
+}
+
+// Logout revokes the session of the token the user signed in with. Tokens
+// issued before sessions were tracked have no session to revoke.
+func (uc *SessionUsecase) Logout(ctx context.Context, userID int, tokenID string) error {
+	if tokenID == "" {
+		return nil
+	}
+
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+
+	s, err := uc.sessionRepo.GetByTokenID(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if s.UserID != userID {
+		return errors.ErrSessionNotFound
+	}
+	return uc.sessionRepo.Revoke(ctx, userID, s.ID)
+}
+
+// RevokeAllSessions logs the user out everywhere.
+func (uc *SessionUsecase) RevokeAllSessions(ctx context.Context, userID int) error {
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+
+	return uc.sessionRepo.RevokeAllByUserID(ctx, userID)
+}
+
+// IsSessionActive reports whether the token ID belongs to a live, unrevoked session
+// and records the activity on it.
+func (uc *SessionUsecase) IsSessionActive(ctx context.Context, tokenID string) (bool, error) {
+	if tokenID == "" {
+		return uc.allowLegacy, nil
+	}
+
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	s, err := uc.sessionRepo.GetByTokenID(ctx, tokenID)
+	if err != nil {
+		if errors.IsSessionNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	now := time.Now()
+	if s.RevokedAt != nil || !now.Before(s.ExpiresAt) {
+		return false, nil
+	}
+
+	// Touching is best effort: a failed write must not log the user out.
+	if now.Sub(s.LastSeenAt) >= touchInterval {
+		_ = uc.sessionRepo.Touch(ctx, tokenID, now)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,201 @@
+package session
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSessionRepository is a mock implementation of SessionRepository
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Create(ctx context.Context, session *entity.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) GetByTokenID(ctx context.Context, tokenID string) (*entity.Session, error) {
+	args := m.Called(ctx, tokenID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) ListActiveByUserID(ctx context.Context, userID int) ([]*entity.Session, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) ListByUserIDSince(ctx context.Context, userID int, since time.Time) ([]*entity.Session, error) {
+	args := m.Called(ctx, userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Revoke(ctx context.Context, userID, sessionID int) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) RevokeAllByUserID(ctx context.Context, userID int) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) Touch(ctx context.Context, tokenID string, seenAt time.Time) error {
+	args := m.Called(ctx, tokenID, seenAt)
+	return args.Error(0)
+}
+
+func TestSessionUsecase_ListSessions(t *testing.T) {
+	repo := new(MockSessionRepository)
+	repo.On("ListActiveByUserID", mock.Anything, 1).Return([]*entity.Session{
+		{ID: 1, UserID: 1, TokenID: "current"},
+		{ID: 2, UserID: 1, TokenID: "other"},
+	}, nil)
+
+	uc := NewSessionUsecase(repo)
+	sessions, err := uc.ListSessions(context.Background(), 1, "current")
+
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.True(t, sessions[0].Current)
+	assert.False(t, sessions[1].Current)
+}
+
+func TestSessionUsecase_IsSessionActive(t *testing.T) {
+	now := time.Now()
+	revokedAt := now.Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		tokenID     string
+		allowLegacy bool
+		setupMock   func(*MockSessionRepository)
+		expected    bool
+	}{
+		{
+			name:    "recently seen session",
+			tokenID: "active",
+			setupMock: func(repo *MockSessionRepository) {
+				repo.On("GetByTokenID", mock.Anything, "active").Return(&entity.Session{
+					TokenID:    "active",
+					LastSeenAt: now,
+					ExpiresAt:  now.Add(time.Hour),
+				}, nil)
+			},
+			expected: true,
+		},
+		{
+			name:    "stale session is touched",
+			tokenID: "stale",
+			setupMock: func(repo *MockSessionRepository) {
+				repo.On("GetByTokenID", mock.Anything, "stale").Return(&entity.Session{
+					TokenID:    "stale",
+					LastSeenAt: now.Add(-time.Hour),
+					ExpiresAt:  now.Add(time.Hour),
+				}, nil)
+				repo.On("Touch", mock.Anything, "stale", mock.AnythingOfType("time.Time")).Return(nil)
+			},
+			expected: true,
+		},
+		{
+			name:    "failed touch keeps the session active",
+			tokenID: "stale",
+			setupMock: func(repo *MockSessionRepository) {
+				repo.On("GetByTokenID", mock.Anything, "stale").Return(&entity.Session{
+					TokenID:    "stale",
+					LastSeenAt: now.Add(-time.Hour),
+					ExpiresAt:  now.Add(time.Hour),
+				}, nil)
+				repo.On("Touch", mock.Anything, "stale", mock.AnythingOfType("time.Time")).Return(assert.AnError)
+			},
+			expected: true,
+		},
+		{
+			name:    "revoked session",
+			tokenID: "revoked",
+			setupMock: func(repo *MockSessionRepository) {
+				repo.On("GetByTokenID", mock.Anything, "revoked").Return(&entity.Session{
+					TokenID:   "revoked",
+					ExpiresAt: now.Add(time.Hour),
+					RevokedAt: &revokedAt,
+				}, nil)
+			},
+			expected: false,
+		},
+		{
+			name:    "expired session",
+			tokenID: "expired",
+			setupMock: func(repo *MockSessionRepository) {
+				repo.On("GetByTokenID", mock.Anything, "expired").Return(&entity.Session{
+					TokenID:   "expired",
+					ExpiresAt: now.Add(-time.Minute),
+				}, nil)
+			},
+			expected: false,
+		},
+		{
+			name:    "unknown session",
+			tokenID: "missing",
+			setupMock: func(repo *MockSessionRepository) {
+				repo.On("GetByTokenID", mock.Anything, "missing").Return(nil, errors.ErrSessionNotFound)
+			},
+			expected: false,
+		},
+		{
+			name:        "legacy token allowed",
+			tokenID:     "",
+			allowLegacy: true,
+			setupMock:   func(repo *MockSessionRepository) {},
+			expected:    true,
+		},
+		{
+			name:      "legacy token rejected",
+			tokenID:   "",
+			setupMock: func(repo *MockSessionRepository) {},
+			expected:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockSessionRepository)
+			tt.setupMock(repo)
+
+			uc := NewSessionUsecase(repo)
+			uc.AllowLegacyTokens(tt.allowLegacy)
+
+			active, err := uc.IsSessionActive(context.Background(), tt.tokenID)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, active)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSessionUsecase_Logout(t *testing.T) {
+	repo := new(MockSessionRepository)
+	repo.On("GetByTokenID", mock.Anything, "mine").Return(&entity.Session{ID: 3, UserID: 1, TokenID: "mine"}, nil)
+	repo.On("GetByTokenID", mock.Anything, "theirs").Return(&entity.Session{ID: 4, UserID: 2, TokenID: "theirs"}, nil)
+	repo.On("Revoke", mock.Anything, 1, 3).Return(nil)
+
+	uc := NewSessionUsecase(repo)
+	ctx := context.Background()
+
+	assert.NoError(t, uc.Logout(ctx, 1, "mine"))
+	assert.True(t, errors.IsSessionNotFound(uc.Logout(ctx, 1, "theirs")))
+	assert.NoError(t, uc.Logout(ctx, 1, ""))
+	repo.AssertNumberOfCalls(t, "Revoke", 1)
+}
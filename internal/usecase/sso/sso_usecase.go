@@ -0,0 +1,456 @@
+package sso
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/emailaddr"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/netguard"
+	"boilerplate-go/pkg/oidc"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxUsernameLength is the longest username an account gets
+const maxUsernameLength = 50
+
+// maxUsernameAttempts bounds the numbered usernames tried when the one
+// derived from the claims is taken
+const maxUsernameAttempts = 10
+
+// SSOUsecase signs the members of organizations in with their identity
+// provider over OpenID Connect. Identity provider subjects are linked to
+// local users; users the service does not know yet are provisioned on first
+// sign-in when the connection allows it.
+type SSOUsecase struct {
+	ssoRepo  repository.SSORepository
+	userRepo repository.UserRepository
+	orgRepo  repository.OrganizationRepository
+	config   config.SSOConfig
+	timeouts *timeout.Policy
+	logger   *logger.Logger
+	now      func() time.Time
+
+	mu      sync.Mutex
+	clients map[int]*cachedClient
+}
+
+// cachedClient is the client of a connection as it was last updated
+type cachedClient struct {
+	updatedAt time.Time
+	client    *oidc.Client
+}
+
+// NewSSOUsecase creates a new single sign-on use case
+func NewSSOUsecase(ssoRepo repository.SSORepository, userRepo repository.UserRepository, orgRepo repository.OrganizationRepository, cfg config.SSOConfig, log *logger.Logger) *SSOUsecase {
+	return &SSOUsecase{
+		ssoRepo:  ssoRepo,
+		userRepo: userRepo,
+		orgRepo:  orgRepo,
+		config:   cfg,
+		logger:   log,
+		now:      time.Now,
+		clients:  make(map[int]*cachedClient),
+	}
+}
+
+// SetTimeouts bounds repository calls with per-operation deadlines.
+func (uc *SSOUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	uc.timeouts = timeouts
+}
+
+// GetConnection returns the single sign-on connection of an organization the
+// user owns.
+func (uc *SSOUsecase) GetConnection(ctx context.Context, userID, orgID int) (*entity.SSOConnection, error) {
+	if err := uc.requireOwner(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	return uc.ssoRepo.GetConnectionByOrganization(ctx, orgID)
+}
+
+// SaveConnection configures the single sign-on of an organization the user
+// owns. The issuer must be an https URL publishing a discovery document, so
+// a mistyped one is caught before members are sent to it.
+func (uc *SSOUsecase) SaveConnection(ctx context.Context, userID, orgID int, req *entity.SSOConnectionRequest) (*entity.SSOConnection, error) {
+	if err := uc.requireOwner(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	if req.Protocol != entity.SSOProtocolOIDC {
+		return nil, fmt.Errorf("%w: unsupported protocol %q", errors.ErrInvalidOrganization, req.Protocol)
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	conn, err := uc.ssoRepo.GetConnectionByOrganization(readCtx, orgID)
+	cancel()
+	if errors.IsSSONotConfigured(err) {
+		conn, err = &entity.SSOConnection{OrganizationID: orgID, JITProvisioning: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	conn.Protocol = req.Protocol
+	conn.Issuer = strings.TrimSuffix(strings.TrimSpace(req.Issuer), "/")
+	conn.ClientID = strings.TrimSpace(req.ClientID)
+	conn.UsernameClaim = strings.TrimSpace(req.UsernameClaim)
+	if req.ClientSecret != nil {
+		conn.ClientSecret = *req.ClientSecret
+	}
+	if req.JITProvisioning != nil {
+		conn.JITProvisioning = *req.JITProvisioning
+	}
+
+	if err := uc.validateIssuer(conn.Issuer); err != nil {
+		return nil, err
+	}
+	if _, err := uc.newClient(conn).Discover(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidOrganization, err)
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.ssoRepo.UpsertConnection(writeCtx, conn); err != nil {
+		return nil, err
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"organization_id": orgID,
+		"user_id":         userID,
+		"issuer":          conn.Issuer,
+		"action":          "sso_connection_saved",
+	}).Info("Single sign-on connection saved")
+	return conn, nil
+}
+
+// DeleteConnection turns off the single sign-on of an organization the user
+// owns. Members keep their accounts but lose the links to their identities.
+func (uc *SSOUsecase) DeleteConnection(ctx context.Context, userID, orgID int) error {
+	if err := uc.requireOwner(ctx, orgID, userID); err != nil {
+		return err
+	}
+
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.ssoRepo.DeleteConnection(ctx, orgID); err != nil {
+		return err
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"organization_id": orgID,
+		"user_id":         userID,
+		"action":          "sso_connection_deleted",
+	}).Info("Single sign-on connection deleted")
+	return nil
+}
+
+// Start begins signing in with the identity provider of the organization.
+// The returned state must come back with the authorization code within the
+// login TTL; only its hash is kept, with the nonce and PKCE verifier.
+func (uc *SSOUsecase) Start(ctx context.Context, req *entity.SSOStartRequest) (*entity.SSOStart, error) {
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	conn, err := uc.ssoRepo.GetConnectionByOrganization(readCtx, req.OrganizationID)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	login := &entity.SSOLogin{ConnectionID: conn.ID, ExpiresAt: uc.now().Add(uc.config.LoginTTL)}
+	state, err := oidc.RandomString(32)
+	if err == nil {
+		login.Nonce, err = oidc.RandomString(32)
+	}
+	if err == nil {
+		login.CodeVerifier, err = oidc.RandomString(32)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate state: %w", err)
+	}
+	login.StateHash = hashState(state)
+
+	authURL, err := uc.client(conn).AuthCodeURL(ctx, state, login.Nonce, login.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrProviderUnavailable, err)
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.ssoRepo.CreateLogin(writeCtx, login); err != nil {
+		return nil, err
+	}
+
+	return &entity.SSOStart{AuthorizationURL: authURL, State: state, ExpiresAt: login.ExpiresAt}, nil
+}
+
+// Authenticate completes a sign-in started with Start and returns the signed
+// in user. The identity is matched by its subject first. An unlinked identity
+// is linked to the member of the organization with its email address, which
+// the identity provider must report verified; an address of a user outside
+// the organization, or of a member of other organizations too, is a
+// conflict, so an owner cannot take over an account used elsewhere. Otherwise the user is provisioned
+// and joins the organization as a member, when the connection allows it.
+func (uc *SSOUsecase) Authenticate(ctx context.Context, req *entity.SSOCallbackRequest) (*entity.User, error) {
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	login, err := uc.ssoRepo.ConsumeLogin(writeCtx, hashState(req.State))
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	conn, err := uc.ssoRepo.GetConnection(readCtx, login.ConnectionID)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	client := uc.client(conn)
+	tokens, err := client.Exchange(ctx, req.Code, login.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrSSOFailed, err)
+	}
+	claims, err := client.VerifyIDToken(ctx, tokens.IDToken, login.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrSSOFailed, err)
+	}
+
+	user, err := uc.resolveUser(ctx, conn, claims)
+	if err != nil {
+		uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+			"organization_id": conn.OrganizationID,
+			"subject":         claims.Subject,
+			"error":           err.Error(),
+			"action":          "sso_login_rejected",
+		}).Warn("Single sign-on rejected")
+		return nil, err
+	}
+	return user, nil
+}
+
+// resolveUser returns the user of the identity, linking or provisioning it
+func (uc *SSOUsecase) resolveUser(ctx context.Context, conn *entity.SSOConnection, claims *oidc.Claims) (*entity.User, error) {
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	identity, err := uc.ssoRepo.GetIdentity(readCtx, conn.ID, claims.Subject)
+	if err == nil {
+		return uc.userRepo.GetByID(readCtx, identity.UserID)
+	}
+	if !errors.IsIdentityNotFound(err) {
+		return nil, err
+	}
+
+	email := emailaddr.Normalize(claims.Email, false)
+	if email == "" {
+		return nil, fmt.Errorf("%w: the ID token has no email", errors.ErrSSOFailed)
+	}
+	if claims.EmailVerified == nil || !*claims.EmailVerified {
+		return nil, fmt.Errorf("%w: the identity provider has not verified %s", errors.ErrSSOFailed, email)
+	}
+
+	user, err := uc.userRepo.GetByEmail(readCtx, email)
+	switch {
+	case err == nil:
+		if _, err := uc.orgRepo.GetMembership(readCtx, conn.OrganizationID, user.ID); err != nil {
+			if errors.IsNotOrgMember(err) {
+				return nil, errors.ErrSSOAccountConflict
+			}
+			return nil, err
+		}
+		orgs, err := uc.orgRepo.ListByUser(readCtx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(orgs) > 1 {
+			return nil, errors.ErrSSOAccountConflict
+		}
+	case errors.IsUserNotFound(err):
+		if !conn.JITProvisioning {
+			return nil, errors.ErrSSONotProvisioned
+		}
+		if user, err = uc.provision(ctx, conn, claims, email); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.ssoRepo.CreateIdentity(writeCtx, &entity.UserIdentity{
+		UserID:       user.ID,
+		ConnectionID: conn.ID,
+		Subject:      claims.Subject,
+	}); err != nil {
+		return nil, err
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"organization_id": conn.OrganizationID,
+		"user_id":         user.ID,
+		"subject":         claims.Subject,
+		"action":          "sso_identity_linked",
+	}).Info("Single sign-on identity linked")
+	return user, nil
+}
+
+// provision creates a user without a password for the identity and adds it
+// to the organization as a member. The user is deleted again when it cannot
+// join, so the next sign-in is not refused as a conflict.
+func (uc *SSOUsecase) provision(ctx context.Context, conn *entity.SSOConnection, claims *oidc.Claims, email string) (*entity.User, error) {
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+
+	base := usernameFromClaims(conn, claims, email)
+	user := &entity.User{
+		Email:     email,
+		FirstName: claims.GivenName,
+		LastName:  claims.FamilyName,
+	}
+	for attempt := 1; ; attempt++ {
+		user.Username = numberedUsername(base, attempt)
+		_, err := uc.userRepo.GetByUsername(writeCtx, user.Username)
+		if errors.IsUserNotFound(err) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if attempt == maxUsernameAttempts {
+			return nil, fmt.Errorf("%w: username %s is taken", errors.ErrSSOFailed, base)
+		}
+	}
+	if err := uc.userRepo.Create(writeCtx, user); err != nil {
+		return nil, err
+	}
+	if err := uc.orgRepo.AddMember(writeCtx, &entity.Membership{
+		OrganizationID: conn.OrganizationID,
+		UserID:         user.ID,
+		Role:           entity.OrgRoleMember,
+	}); err != nil {
+		if deleteErr := uc.userRepo.Delete(context.WithoutCancel(writeCtx), user.ID); deleteErr != nil {
+			uc.logger.ErrorLogger(ctx, deleteErr, "Failed to delete user after failed organization join", map[string]interface{}{
+				"organization_id": conn.OrganizationID,
+				"user_id":         user.ID,
+			})
+		}
+		return nil, err
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"organization_id": conn.OrganizationID,
+		"user_id":         user.ID,
+		"action":          "sso_user_provisioned",
+	}).Info("User provisioned by single sign-on")
+	return user, nil
+}
+
+// usernameFromClaims returns the username claim of the connection, or the
+// local part of the email address
+func usernameFromClaims(conn *entity.SSOConnection, claims *oidc.Claims, email string) string {
+	username := ""
+	if conn.UsernameClaim != "" {
+		username = strings.TrimSpace(claims.String(conn.UsernameClaim))
+	}
+	if username == "" {
+		username, _, _ = strings.Cut(email, "@")
+	}
+	return username
+}
+
+// numberedUsername returns the username for the attempt, numbered from the
+// second one on and cut to the longest username
+func numberedUsername(base string, attempt int) string {
+	suffix := ""
+	if attempt > 1 {
+		suffix = strconv.Itoa(attempt)
+	}
+	if len(base)+len(suffix) > maxUsernameLength {
+		base = base[:maxUsernameLength-len(suffix)]
+	}
+	return base + suffix
+}
+
+// requireOwner fails unless the user owns the organization. Organizations
+// the user is not a member of are reported as not found.
+func (uc *SSOUsecase) requireOwner(ctx context.Context, orgID, userID int) error {
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	member, err := uc.orgRepo.GetMembership(ctx, orgID, userID)
+	if errors.IsNotOrgMember(err) {
+		return errors.ErrOrgNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if member.Role != entity.OrgRoleOwner {
+		return fmt.Errorf("%w: %s role required", errors.ErrOrgRoleRequired, entity.OrgRoleOwner)
+	}
+	return nil
+}
+
+// client returns the cached client of the connection, replacing it when the
+// connection changed since
+func (uc *SSOUsecase) client(conn *entity.SSOConnection) *oidc.Client {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if cached, ok := uc.clients[conn.ID]; ok && cached.updatedAt.Equal(conn.UpdatedAt) {
+		return cached.client
+	}
+	client := uc.newClient(conn)
+	uc.clients[conn.ID] = &cachedClient{updatedAt: conn.UpdatedAt, client: client}
+	return client
+}
+
+func (uc *SSOUsecase) newClient(conn *entity.SSOConnection) *oidc.Client {
+	return oidc.NewClient(oidc.Config{
+		Issuer:       conn.Issuer,
+		ClientID:     conn.ClientID,
+		ClientSecret: conn.ClientSecret,
+		RedirectURL:  uc.config.RedirectURL,
+		HTTPClient:   uc.httpClient(),
+	})
+}
+
+// httpClient returns the client of identity provider requests, which only
+// connects to public addresses unless private issuers are allowed
+func (uc *SSOUsecase) httpClient() *http.Client {
+	if uc.config.AllowPrivateIssuers {
+		return &http.Client{Timeout: uc.config.HTTPTimeout}
+	}
+	return netguard.Client(uc.config.HTTPTimeout)
+}
+
+// validateIssuer requires an https issuer unless private issuers are allowed
+func (uc *SSOUsecase) validateIssuer(issuer string) error {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("%w: invalid issuer %q", errors.ErrInvalidOrganization, issuer)
+	}
+	if u.Scheme != "https" && (u.Scheme != "http" || !uc.config.AllowPrivateIssuers) {
+		return fmt.Errorf("%w: issuer must be an https URL", errors.ErrInvalidOrganization)
+	}
+	return nil
+}
+
+// hashState returns the hex SHA-256 hash a state is stored under
+func hashState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,382 @@
+package sso
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/oidc/oidctest"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySSORepository keeps connections, sign-ins and identities in maps.
+type memorySSORepository struct {
+	connections map[int]*entity.SSOConnection
+	logins      map[string]*entity.SSOLogin
+	identities  []*entity.UserIdentity
+}
+
+func (r *memorySSORepository) UpsertConnection(ctx context.Context, conn *entity.SSOConnection) error {
+	if conn.ID == 0 {
+		conn.ID = len(r.connections) + 1
+	}
+	conn.UpdatedAt = time.Now()
+	copied := *conn
+	r.connections[conn.ID] = &copied
+	return nil
+}
+
+func (r *memorySSORepository) GetConnection(ctx context.Context, id int) (*entity.SSOConnection, error) {
+	conn, ok := r.connections[id]
+	if !ok {
+		return nil, errors.ErrSSONotConfigured
+	}
+	copied := *conn
+	return &copied, nil
+}
+
+func (r *memorySSORepository) GetConnectionByOrganization(ctx context.Context, orgID int) (*entity.SSOConnection, error) {
+	for id, conn := range r.connections {
+		if conn.OrganizationID == orgID {
+			return r.GetConnection(ctx, id)
+		}
+	}
+	return nil, errors.ErrSSONotConfigured
+}
+
+func (r *memorySSORepository) DeleteConnection(ctx context.Context, orgID int) error {
+	conn, err := r.GetConnectionByOrganization(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	delete(r.connections, conn.ID)
+	return nil
+}
+
+func (r *memorySSORepository) CreateLogin(ctx context.Context, login *entity.SSOLogin) error {
+	r.logins[login.StateHash] = login
+	return nil
+}
+
+func (r *memorySSORepository) ConsumeLogin(ctx context.Context, stateHash string) (*entity.SSOLogin, error) {
+	login, ok := r.logins[stateHash]
+	delete(r.logins, stateHash)
+	if !ok {
+		return nil, errors.ErrInvalidSSOState
+	}
+	return login, nil
+}
+
+func (r *memorySSORepository) GetIdentity(ctx context.Context, connectionID int, subject string) (*entity.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.ConnectionID == connectionID && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, errors.ErrIdentityNotFound
+}
+
+func (r *memorySSORepository) CreateIdentity(ctx context.Context, identity *entity.UserIdentity) error {
+	identity.ID = len(r.identities) + 1
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+// memoryUserRepository keeps users in a map.
+type memoryUserRepository struct {
+	repository.UserRepository
+	users map[int]*entity.User
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *entity.User) error {
+	user.ID = len(r.users) + 1
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *memoryUserRepository) GetByID(ctx context.Context, id int) (*entity.User, error) {
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, errors.ErrUserNotFound
+}
+
+func (r *memoryUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return nil, errors.ErrUserNotFound
+}
+
+func (r *memoryUserRepository) Delete(ctx context.Context, id int) error {
+	delete(r.users, id)
+	return nil
+}
+
+func (r *memoryUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, errors.ErrUserNotFound
+}
+
+// memoryOrgRepository keeps memberships in a map. Adding members fails with
+// addErr when set.
+type memoryOrgRepository struct {
+	repository.OrganizationRepository
+	members map[[2]int]string
+	addErr  error
+}
+
+func (r *memoryOrgRepository) GetMembership(ctx context.Context, orgID, userID int) (*entity.Membership, error) {
+	role, ok := r.members[[2]int{orgID, userID}]
+	if !ok {
+		return nil, errors.ErrNotOrgMember
+	}
+	return &entity.Membership{OrganizationID: orgID, UserID: userID, Role: role}, nil
+}
+
+func (r *memoryOrgRepository) ListByUser(ctx context.Context, userID int) ([]*entity.Organization, error) {
+	orgs := []*entity.Organization{}
+	for key, role := range r.members {
+		if key[1] == userID {
+			orgs = append(orgs, &entity.Organization{ID: key[0], Role: role})
+		}
+	}
+	return orgs, nil
+}
+
+func (r *memoryOrgRepository) AddMember(ctx context.Context, member *entity.Membership) error {
+	if r.addErr != nil {
+		return r.addErr
+	}
+	r.members[[2]int{member.OrganizationID, member.UserID}] = member.Role
+	return nil
+}
+
+const orgID = 7
+
+type fixture struct {
+	uc       *SSOUsecase
+	provider *oidctest.Provider
+	sso      *memorySSORepository
+	users    *memoryUserRepository
+	orgs     *memoryOrgRepository
+}
+
+// newFixture returns a use case with organization orgID owned by user 1,
+// whose member user 2 is alice@example.com, and mallory@example.com, user 3,
+// outside it. The organization signs in with the provider.
+func newFixture(t *testing.T) *fixture {
+	f := &fixture{
+		provider: oidctest.NewProvider("app"),
+		sso: &memorySSORepository{
+			connections: make(map[int]*entity.SSOConnection),
+			logins:      make(map[string]*entity.SSOLogin),
+		},
+		users: &memoryUserRepository{users: map[int]*entity.User{
+			1: {ID: 1, Username: "owner", Email: "owner@example.com"},
+			2: {ID: 2, Username: "alice", Email: "alice@example.com"},
+			3: {ID: 3, Username: "mallory", Email: "mallory@example.com"},
+		}},
+		orgs: &memoryOrgRepository{members: map[[2]int]string{
+			{orgID, 1}: entity.OrgRoleOwner,
+			{orgID, 2}: entity.OrgRoleMember,
+		}},
+	}
+	t.Cleanup(f.provider.Close)
+
+	f.uc = NewSSOUsecase(f.sso, f.users, f.orgs, config.SSOConfig{
+		RedirectURL:         "https://app.example.com/sso",
+		LoginTTL:            time.Minute,
+		HTTPTimeout:         time.Second,
+		AllowPrivateIssuers: true,
+	}, logger.NewLogger())
+
+	_, err := f.uc.SaveConnection(context.Background(), 1, orgID, &entity.SSOConnectionRequest{
+		Protocol: entity.SSOProtocolOIDC,
+		Issuer:   f.provider.URL,
+		ClientID: "app",
+	})
+	require.NoError(t, err)
+	return f
+}
+
+// login signs in through the provider as the user with the claims
+func (f *fixture) login(t *testing.T, claims map[string]interface{}) (*entity.User, error) {
+	ctx := context.Background()
+	start, err := f.uc.Start(ctx, &entity.SSOStartRequest{OrganizationID: orgID})
+	require.NoError(t, err)
+	code, err := f.provider.Authorize(start.AuthorizationURL, claims)
+	require.NoError(t, err)
+	return f.uc.Authenticate(ctx, &entity.SSOCallbackRequest{State: start.State, Code: code})
+}
+
+func TestSSOUsecase_SaveConnection(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	t.Run("owners only", func(t *testing.T) {
+		_, err := f.uc.SaveConnection(ctx, 2, orgID, &entity.SSOConnectionRequest{
+			Protocol: entity.SSOProtocolOIDC, Issuer: f.provider.URL, ClientID: "app",
+		})
+		assert.True(t, errors.IsOrgRoleRequired(err))
+
+		_, err = f.uc.GetConnection(ctx, 3, orgID)
+		assert.True(t, errors.IsOrgNotFound(err))
+	})
+
+	t.Run("issuer must serve discovery", func(t *testing.T) {
+		_, err := f.uc.SaveConnection(ctx, 1, orgID, &entity.SSOConnectionRequest{
+			Protocol: entity.SSOProtocolOIDC, Issuer: f.provider.URL + "/other", ClientID: "app",
+		})
+		assert.True(t, errors.IsInvalidOrganization(err))
+	})
+
+	t.Run("private issuers refused", func(t *testing.T) {
+		uc := NewSSOUsecase(f.sso, f.users, f.orgs, config.SSOConfig{HTTPTimeout: time.Second}, logger.NewLogger())
+
+		_, err := uc.SaveConnection(ctx, 1, orgID, &entity.SSOConnectionRequest{
+			Protocol: entity.SSOProtocolOIDC, Issuer: f.provider.URL, ClientID: "app",
+		})
+		assert.True(t, errors.IsInvalidOrganization(err))
+		assert.ErrorContains(t, err, "https")
+
+		_, err = uc.SaveConnection(ctx, 1, orgID, &entity.SSOConnectionRequest{
+			Protocol: entity.SSOProtocolOIDC, Issuer: "https://169.254.169.254", ClientID: "app",
+		})
+		assert.True(t, errors.IsInvalidOrganization(err))
+		assert.ErrorContains(t, err, "not public")
+	})
+
+	t.Run("omitted secret is kept", func(t *testing.T) {
+		secret := "s3cret"
+		_, err := f.uc.SaveConnection(ctx, 1, orgID, &entity.SSOConnectionRequest{
+			Protocol: entity.SSOProtocolOIDC, Issuer: f.provider.URL, ClientID: "app", ClientSecret: &secret,
+		})
+		require.NoError(t, err)
+		conn, err := f.uc.SaveConnection(ctx, 1, orgID, &entity.SSOConnectionRequest{
+			Protocol: entity.SSOProtocolOIDC, Issuer: f.provider.URL, ClientID: "app",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", conn.ClientSecret)
+		assert.True(t, conn.JITProvisioning)
+	})
+}
+
+func TestSSOUsecase_Authenticate(t *testing.T) {
+	t.Run("links the member with the email", func(t *testing.T) {
+		f := newFixture(t)
+		user, err := f.login(t, map[string]interface{}{"sub": "a-1", "email": "Alice@example.com", "email_verified": true})
+		require.NoError(t, err)
+		assert.Equal(t, 2, user.ID)
+
+		// The link holds even once the email changes at the provider
+		user, err = f.login(t, map[string]interface{}{"sub": "a-1", "email": "alice@new.example"})
+		require.NoError(t, err)
+		assert.Equal(t, 2, user.ID)
+	})
+
+	t.Run("provisions new users", func(t *testing.T) {
+		f := newFixture(t)
+		f.users.users[4] = &entity.User{ID: 4, Username: "bob", Email: "bob@elsewhere.example"}
+
+		user, err := f.login(t, map[string]interface{}{
+			"sub": "b-1", "email": "bob@example.com", "email_verified": true, "given_name": "Bob", "family_name": "Builder",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "bob2", user.Username)
+		assert.Equal(t, "Bob", user.FirstName)
+		assert.Empty(t, user.Password)
+		assert.Equal(t, entity.OrgRoleMember, f.orgs.members[[2]int{orgID, user.ID}])
+	})
+
+	t.Run("username claim", func(t *testing.T) {
+		f := newFixture(t)
+		conn, _ := f.sso.GetConnectionByOrganization(context.Background(), orgID)
+		conn.UsernameClaim = "preferred_username"
+		require.NoError(t, f.sso.UpsertConnection(context.Background(), conn))
+
+		user, err := f.login(t, map[string]interface{}{"sub": "c-1", "email": "carol@example.com", "email_verified": true, "preferred_username": "cjones"})
+		require.NoError(t, err)
+		assert.Equal(t, "cjones", user.Username)
+	})
+
+	t.Run("rejected identities", func(t *testing.T) {
+		f := newFixture(t)
+
+		_, err := f.login(t, map[string]interface{}{"sub": "m-1", "email": "mallory@example.com", "email_verified": true})
+		assert.True(t, errors.IsSSOAccountConflict(err))
+
+		_, err = f.login(t, map[string]interface{}{"sub": "a-2", "email": "alice@example.com", "email_verified": false})
+		assert.True(t, errors.IsSSOFailed(err))
+
+		_, err = f.login(t, map[string]interface{}{"sub": "a-2", "email": "alice@example.com"})
+		assert.True(t, errors.IsSSOFailed(err))
+
+		_, err = f.login(t, map[string]interface{}{"sub": "n-1"})
+		assert.True(t, errors.IsSSOFailed(err))
+
+		conn, _ := f.sso.GetConnectionByOrganization(context.Background(), orgID)
+		conn.JITProvisioning = false
+		require.NoError(t, f.sso.UpsertConnection(context.Background(), conn))
+		_, err = f.login(t, map[string]interface{}{"sub": "d-1", "email": "dave@example.com", "email_verified": true})
+		assert.True(t, errors.IsSSONotProvisioned(err))
+	})
+
+	t.Run("members of other organizations are not linked", func(t *testing.T) {
+		f := newFixture(t)
+		f.orgs.members[[2]int{8, 2}] = entity.OrgRoleMember
+
+		_, err := f.login(t, map[string]interface{}{"sub": "a-1", "email": "alice@example.com", "email_verified": true})
+		assert.True(t, errors.IsSSOAccountConflict(err))
+	})
+
+	t.Run("failed join deletes the new user", func(t *testing.T) {
+		f := newFixture(t)
+		f.orgs.addErr = errors.ErrOrgNotFound
+
+		_, err := f.login(t, map[string]interface{}{"sub": "b-1", "email": "bob@example.com", "email_verified": true})
+		require.Error(t, err)
+		_, err = f.users.GetByEmail(context.Background(), "bob@example.com")
+		assert.True(t, errors.IsUserNotFound(err))
+
+		f.orgs.addErr = nil
+		user, err := f.login(t, map[string]interface{}{"sub": "b-1", "email": "bob@example.com", "email_verified": true})
+		require.NoError(t, err)
+		assert.Equal(t, "bob", user.Username)
+	})
+
+	t.Run("state is redeemed once", func(t *testing.T) {
+		f := newFixture(t)
+		ctx := context.Background()
+		start, err := f.uc.Start(ctx, &entity.SSOStartRequest{OrganizationID: orgID})
+		require.NoError(t, err)
+		code, err := f.provider.Authorize(start.AuthorizationURL, map[string]interface{}{"sub": "a-1", "email": "alice@example.com", "email_verified": true})
+		require.NoError(t, err)
+
+		req := &entity.SSOCallbackRequest{State: start.State, Code: code}
+		_, err = f.uc.Authenticate(ctx, req)
+		require.NoError(t, err)
+		_, err = f.uc.Authenticate(ctx, req)
+		assert.True(t, errors.IsInvalidSSOState(err))
+	})
+}
+
+func TestNumberedUsername(t *testing.T) {
+	assert.Equal(t, "bob", numberedUsername("bob", 1))
+	assert.Equal(t, "bob3", numberedUsername("bob", 3))
+
+	long := "abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwx"
+	assert.Len(t, numberedUsername(long, 10), maxUsernameLength)
+}
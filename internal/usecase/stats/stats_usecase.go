@@ -0,0 +1,116 @@
+package stats
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MaxDays is the longest period the dashboard aggregates
+const MaxDays = 365
+
+// StatsUsecase computes the aggregates of the operations dashboard.
+// Results are cached per period for the cache TTL, so a dashboard polled by
+// many operators runs the aggregate queries once per TTL.
+type StatsUsecase struct {
+	statsRepo repository.StatsRepository
+	cacheTTL  time.Duration
+	timeouts  *timeout.Policy
+	now       func() time.Time
+
+	// mu is held while computing, so concurrent misses query once
+	mu    sync.Mutex
+	cache map[int]*entity.DashboardStats
+}
+
+// NewStatsUsecase creates a new stats use case. A zero cacheTTL disables caching.
+func NewStatsUsecase(statsRepo repository.StatsRepository, cacheTTL time.Duration) *StatsUsecase {
+	return &StatsUsecase{
+		statsRepo: statsRepo,
+		cacheTTL:  cacheTTL,
+		now:       time.Now,
+		cache:     make(map[int]*entity.DashboardStats),
+	}
+}
+
+// SetTimeouts bounds repository calls with per-operation deadlines.
+func (uc *StatsUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	uc.timeouts = timeouts
+}
+
+// Dashboard returns the aggregates of the last days, today included.
+func (uc *StatsUsecase) Dashboard(ctx context.Context, days int) (*entity.DashboardStats, error) {
+	if days < 1 || days > MaxDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", errors.ErrInvalidStatsRange, MaxDays)
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	now := uc.now().UTC()
+	if cached, ok := uc.cache[days]; ok && now.Sub(cached.GeneratedAt) < uc.cacheTTL {
+		return cached, nil
+	}
+
+	stats, err := uc.compute(ctx, now, days)
+	if err != nil {
+		return nil, err
+	}
+	if uc.cacheTTL > 0 {
+		uc.cache[days] = stats
+	}
+	return stats, nil
+}
+
+func (uc *StatsUsecase) compute(ctx context.Context, now time.Time, days int) (*entity.DashboardStats, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	stats := &entity.DashboardStats{
+		From:        today.AddDate(0, 0, 1-days),
+		To:          today,
+		GeneratedAt: now,
+	}
+
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	var err error
+	if stats.NewUsers, err = uc.statsRepo.NewUsersByDay(ctx, stats.From); err != nil {
+		return nil, fmt.Errorf("failed to count new users: %w", err)
+	}
+	if stats.Orders, err = uc.statsRepo.OrdersByDay(ctx, stats.From); err != nil {
+		return nil, fmt.Errorf("failed to aggregate orders: %w", err)
+	}
+	if stats.Notifications, err = uc.statsRepo.NotificationsByDay(ctx, stats.From); err != nil {
+		return nil, fmt.Errorf("failed to aggregate notifications: %w", err)
+	}
+
+	var completed, refunded int
+	for _, day := range stats.Orders {
+		completed += day.Completed
+		refunded += day.Refunded
+	}
+	stats.RefundRate = ratio(refunded, completed+refunded)
+
+	var sent, failed int
+	for _, day := range stats.Notifications {
+		sent += day.Sent
+		failed += day.Failed
+	}
+	stats.NotificationDeliveryRate = ratio(sent, sent+failed)
+
+	return stats, nil
+}
+
+// ratio returns part/total, or nil without a total
+func ratio(part, total int) *float64 {
+	if total == 0 {
+		return nil
+	}
+	r := float64(part) / float64(total)
+	return &r
+}
@@ -0,0 +1,114 @@
+package stats
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockStatsRepository is a mock implementation of StatsRepository
+type MockStatsRepository struct {
+	mock.Mock
+}
+
+func (m *MockStatsRepository) NewUsersByDay(ctx context.Context, since time.Time) ([]entity.DailyCount, error) {
+	args := m.Called(ctx, since)
+	return args.Get(0).([]entity.DailyCount), args.Error(1)
+}
+
+func (m *MockStatsRepository) OrdersByDay(ctx context.Context, since time.Time) ([]entity.DailyOrderStats, error) {
+	args := m.Called(ctx, since)
+	return args.Get(0).([]entity.DailyOrderStats), args.Error(1)
+}
+
+func (m *MockStatsRepository) NotificationsByDay(ctx context.Context, since time.Time) ([]entity.DailyDeliveryStats, error) {
+	args := m.Called(ctx, since)
+	return args.Get(0).([]entity.DailyDeliveryStats), args.Error(1)
+}
+
+func newStatsUsecase(repo *MockStatsRepository, now *time.Time) *StatsUsecase {
+	uc := NewStatsUsecase(repo, time.Minute)
+	uc.now = func() time.Time { return *now }
+	return uc
+}
+
+func TestStatsUsecase_Dashboard(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+	since := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	repo := new(MockStatsRepository)
+	repo.On("NewUsersByDay", mock.Anything, since).Return([]entity.DailyCount{{Date: "2024-03-05", Count: 3}}, nil)
+	repo.On("OrdersByDay", mock.Anything, since).Return([]entity.DailyOrderStats{
+		{Date: "2024-03-05", Currency: "USD", Orders: 5, Completed: 3, Refunded: 1, Revenue: 75},
+		{Date: "2024-03-06", Currency: "EUR", Orders: 1, Refunded: 1},
+	}, nil)
+	repo.On("NotificationsByDay", mock.Anything, since).Return([]entity.DailyDeliveryStats{{Date: "2024-03-05", Sent: 9, Failed: 1}}, nil)
+
+	stats, err := newStatsUsecase(repo, &now).Dashboard(context.Background(), 7)
+
+	require.NoError(t, err)
+	assert.Equal(t, since, stats.From)
+	assert.Equal(t, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), stats.To)
+	assert.Len(t, stats.NewUsers, 1)
+	assert.Len(t, stats.Orders, 2)
+	require.NotNil(t, stats.RefundRate)
+	assert.InDelta(t, 0.4, *stats.RefundRate, 1e-9)
+	require.NotNil(t, stats.NotificationDeliveryRate)
+	assert.InDelta(t, 0.9, *stats.NotificationDeliveryRate, 1e-9)
+}
+
+func TestStatsUsecase_Dashboard_NoActivity(t *testing.T) {
+	now := time.Now()
+	repo := new(MockStatsRepository)
+	repo.On("NewUsersByDay", mock.Anything, mock.Anything).Return([]entity.DailyCount{}, nil)
+	repo.On("OrdersByDay", mock.Anything, mock.Anything).Return([]entity.DailyOrderStats{}, nil)
+	repo.On("NotificationsByDay", mock.Anything, mock.Anything).Return([]entity.DailyDeliveryStats{}, nil)
+
+	stats, err := newStatsUsecase(repo, &now).Dashboard(context.Background(), 30)
+
+	require.NoError(t, err)
+	assert.Nil(t, stats.RefundRate)
+	assert.Nil(t, stats.NotificationDeliveryRate)
+}
+
+func TestStatsUsecase_Dashboard_Cache(t *testing.T) {
+	now := time.Now()
+	repo := new(MockStatsRepository)
+	repo.On("NewUsersByDay", mock.Anything, mock.Anything).Return([]entity.DailyCount{}, nil)
+	repo.On("OrdersByDay", mock.Anything, mock.Anything).Return([]entity.DailyOrderStats{}, nil)
+	repo.On("NotificationsByDay", mock.Anything, mock.Anything).Return([]entity.DailyDeliveryStats{}, nil)
+	uc := newStatsUsecase(repo, &now)
+
+	first, err := uc.Dashboard(context.Background(), 30)
+	require.NoError(t, err)
+	second, err := uc.Dashboard(context.Background(), 30)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+	repo.AssertNumberOfCalls(t, "OrdersByDay", 1)
+
+	// Each period is cached separately
+	_, err = uc.Dashboard(context.Background(), 7)
+	require.NoError(t, err)
+	repo.AssertNumberOfCalls(t, "OrdersByDay", 2)
+
+	now = now.Add(time.Minute)
+	third, err := uc.Dashboard(context.Background(), 30)
+	require.NoError(t, err)
+	assert.NotSame(t, first, third)
+	repo.AssertNumberOfCalls(t, "OrdersByDay", 3)
+}
+
+func TestStatsUsecase_Dashboard_InvalidRange(t *testing.T) {
+	uc := NewStatsUsecase(new(MockStatsRepository), time.Minute)
+
+	for _, days := range []int{0, -1, MaxDays + 1} {
+		_, err := uc.Dashboard(context.Background(), days)
+		assert.True(t, errors.IsInvalidStatsRange(err), "days=%d: %v", days, err)
+	}
+}
@@ -0,0 +1,221 @@
+package upload
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/internal/usecase/job"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+)
+
+// quarantinePrefix is where infected files are moved, away from the uploader's files
+const quarantinePrefix = "quarantine"
+
+// JobQueue runs background jobs with retries.
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobType string, payload interface{}) error
+}
+
+// SetScanner scans completed uploads for malware before they can be downloaded.
+// Scans run in a background job when jobs is set, and during completion otherwise.
+func (uc *UploadUsecase) SetScanner(scanner provider.ScannerProvider, jobs JobQueue) {
+	uc.scanner = scanner
+	uc.jobs = jobs
+}
+
+// SetNotifications emails uploaders when their file is quarantined.
+func (uc *UploadUsecase) SetNotifications(userRepo repository.UserRepository, notificationProvider provider.NotificationProvider) {
+	uc.userRepo = userRepo
+	uc.notificationProvider = notificationProvider
+}
+
+// initialScanStatus is the scan status of a newly completed upload.
+func (uc *UploadUsecase) initialScanStatus() string {
+	if uc.scanner == nil {
+		return entity.ScanStatusSkipped
+	}
+	return entity.ScanStatusPending
+}
+
+// startScan queues the scan of a completed upload. A scan that cannot be
+// queued leaves the upload pending, so its file stays unavailable.
+func (uc *UploadUsecase) startScan(ctx context.Context, upload *entity.Upload) {
+	if uc.scanner == nil {
+		return
+	}
+
+	var err error
+	if uc.jobs != nil {
+		err = uc.jobs.Enqueue(ctx, entity.JobTypeUploadScan, entity.UploadScan{UploadID: upload.ID})
+	} else {
+		err = uc.scan(ctx, upload)
+	}
+	if err != nil {
+		uc.logger.ErrorLogger(ctx, err, "Failed to scan upload", map[string]interface{}{
+			"upload_id": upload.ID,
+		})
+	}
+}
+
+// ScanUpload runs a queued malware scan of a completed upload.
+func (uc *UploadUsecase) ScanUpload(ctx context.Context, payload json.RawMessage) error {
+	var scan entity.UploadScan
+	if err := json.Unmarshal(payload, &scan); err != nil {
+		return fmt.Errorf("%w: invalid upload scan payload: %v", job.ErrPermanent, err)
+	}
+	if uc.scanner == nil {
+		return fmt.Errorf("%w: %v", job.ErrPermanent, errors.ErrProviderUnavailable)
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	upload, err := uc.uploadRepo.GetByID(readCtx, scan.UploadID)
+	cancel()
+	if err != nil {
+		if errors.IsUploadNotFound(err) {
+			return fmt.Errorf("%w: %v", job.ErrPermanent, err)
+		}
+		return err
+	}
+
+	// A retried job may find the scan already recorded
+	if upload.ScanStatus != entity.ScanStatusPending {
+		return nil
+	}
+	return uc.scan(ctx, upload)
+}
+
+// FailScan marks the scan failed when its job exhausted its retries. The file
+// stays unavailable for download.
+func (uc *UploadUsecase) FailScan(ctx context.Context, payload json.RawMessage, jobErr error) {
+	var scan entity.UploadScan
+	if err := json.Unmarshal(payload, &scan); err != nil {
+		return
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	upload, err := uc.uploadRepo.GetByID(readCtx, scan.UploadID)
+	cancel()
+	if err != nil || upload.ScanStatus != entity.ScanStatusPending {
+		return
+	}
+
+	upload.ScanStatus = entity.ScanStatusFailed
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.uploadRepo.UpdateScan(writeCtx, upload); err != nil {
+		uc.logger.ErrorLogger(ctx, err, "Failed to mark upload scan as failed", map[string]interface{}{
+			"upload_id": upload.ID,
+		})
+	}
+}
+
+// scan streams the upload's file to the scanner. Infected files are moved to
+// quarantine and the uploader is told. The scanner bounds its own duration,
+// which depends on the file size.
+func (uc *UploadUsecase) scan(ctx context.Context, upload *entity.Upload) error {
+	file, err := uc.storage.OpenFile(ctx, upload.FileID)
+	if err != nil {
+		return fmt.Errorf("failed to open upload: %w", err)
+	}
+	result, err := uc.scanner.ScanFile(ctx, file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to scan upload: %w", err)
+	}
+
+	upload.ScannedAt = &result.ScannedAt
+	if result.Clean {
+		upload.ScanStatus = entity.ScanStatusClean
+	} else {
+		quarantined := path.Join(quarantinePrefix, upload.FileID)
+		storageCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationStorage)
+		err = uc.storage.MoveFile(storageCtx, upload.FileID, quarantined)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to quarantine upload: %w", err)
+		}
+		upload.FileID = quarantined
+		upload.ScanStatus = entity.ScanStatusInfected
+		upload.ScanThreat = result.Threat
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	err = uc.uploadRepo.UpdateScan(writeCtx, upload)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]interface{}{
+		"upload_id":   upload.ID,
+		"user_id":     upload.UserID,
+		"scan_status": upload.ScanStatus,
+		"engine":      result.Engine,
+	}
+	if result.Clean {
+		uc.logger.WithContext(ctx).WithFields(fields).Info("Upload scanned clean")
+		return nil
+	}
+
+	fields["threat"] = result.Threat
+	uc.logger.WithContext(ctx).WithFields(fields).Warn("Upload quarantined")
+	uc.notifyQuarantined(ctx, upload)
+	return nil
+}
+
+// notifyQuarantined emails the uploader that their file was quarantined.
+// Failures are only logged; the scan result is already recorded.
+func (uc *UploadUsecase) notifyQuarantined(ctx context.Context, upload *entity.Upload) {
+	if uc.userRepo == nil || uc.notificationProvider == nil {
+		return
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := uc.userRepo.GetByID(readCtx, upload.UserID)
+	cancel()
+	if err != nil {
+		uc.logger.ErrorLogger(ctx, err, "Failed to load uploader for quarantine notice", map[string]interface{}{
+			"upload_id": upload.ID,
+		})
+		return
+	}
+
+	emailReq := &entity.EmailRequest{
+		To:      []string{user.Email},
+		Subject: "Your upload was quarantined",
+		Body: fmt.Sprintf(`
+Hello %s,
+
+The file %q you uploaded was found to contain malware (%s) and has been quarantined.
+It can no longer be downloaded. If you believe this is a mistake, please contact our support team.
+
+Best regards,
+Boilerplate Team
+		`, user.DisplayName(), upload.FileName, upload.ScanThreat),
+		Metadata: map[string]interface{}{
+			"user_id":   user.ID,
+			"upload_id": upload.ID,
+			"type":      "upload_quarantined",
+		},
+		Priority: entity.NotificationPriorityTransactional,
+	}
+
+	if uc.jobs != nil {
+		err = uc.jobs.Enqueue(ctx, entity.JobTypeEmail, emailReq)
+	} else {
+		callCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationNotification)
+		_, err = uc.notificationProvider.SendEmail(callCtx, emailReq)
+		cancel()
+	}
+	if err != nil {
+		uc.logger.ErrorLogger(ctx, err, "Failed to send quarantine notice", map[string]interface{}{
+			"upload_id": upload.ID,
+			"user_id":   user.ID,
+		})
+	}
+}
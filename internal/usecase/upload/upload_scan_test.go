@@ -0,0 +1,149 @@
+package upload
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/internal/usecase/job"
+	"boilerplate-go/pkg/errors"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScanner reports files containing "EVIL" as infected.
+type fakeScanner struct{}
+
+func (fakeScanner) ScanFile(ctx context.Context, content io.Reader) (*entity.ScanResult, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+	result := &entity.ScanResult{Clean: true, Engine: "fake", ScannedAt: time.Now()}
+	if strings.Contains(string(data), "EVIL") {
+		result.Clean = false
+		result.Threat = "Eicar-Test-Signature"
+	}
+	return result, nil
+}
+
+// fakeJobQueue records enqueued jobs.
+type fakeJobQueue struct {
+	jobs []string
+}
+
+func (q *fakeJobQueue) Enqueue(ctx context.Context, jobType string, payload interface{}) error {
+	q.jobs = append(q.jobs, jobType)
+	return nil
+}
+
+// stubUserRepository returns a single user.
+type stubUserRepository struct {
+	repository.UserRepository
+	user *entity.User
+}
+
+func (r *stubUserRepository) GetByID(ctx context.Context, id int) (*entity.User, error) {
+	return r.user, nil
+}
+
+// recordingNotifier records the emails it sends.
+type recordingNotifier struct {
+	provider.NotificationProvider
+	emails []*entity.EmailRequest
+}
+
+func (n *recordingNotifier) SendEmail(ctx context.Context, req *entity.EmailRequest) (*entity.EmailResponse, error) {
+	n.emails = append(n.emails, req)
+	return &entity.EmailResponse{}, nil
+}
+
+// completeUpload uploads content of at most one part and completes the upload.
+func completeUpload(t *testing.T, uc *UploadUsecase, content string) *entity.Upload {
+	ctx := context.Background()
+	upload, err := uc.Initiate(ctx, 7, &entity.InitiateUploadRequest{FileName: "report.txt", Size: int64(len(content))})
+	require.NoError(t, err)
+	_, err = uc.UploadPart(ctx, 7, upload.ID, 1, bytes.NewBufferString(content))
+	require.NoError(t, err)
+	completed, err := uc.Complete(ctx, 7, upload.ID)
+	require.NoError(t, err)
+	return completed
+}
+
+func TestUploadUsecase_ScanClean(t *testing.T) {
+	uc, repo, _ := newTestUsecase(t)
+	jobs := &fakeJobQueue{}
+	uc.SetScanner(fakeScanner{}, jobs)
+	ctx := context.Background()
+
+	upload := completeUpload(t, uc, "safe")
+	assert.Equal(t, []string{entity.JobTypeUploadScan}, jobs.jobs)
+	assert.Equal(t, entity.ScanStatusPending, repo.uploads[upload.ID].ScanStatus)
+
+	// The file is held back until the scan has run
+	_, err := uc.DownloadURL(ctx, 7, upload.ID)
+	assert.True(t, errors.IsFileNotScanned(err))
+
+	payload, _ := json.Marshal(entity.UploadScan{UploadID: upload.ID})
+	require.NoError(t, uc.ScanUpload(ctx, payload))
+	assert.Equal(t, entity.ScanStatusClean, repo.uploads[upload.ID].ScanStatus)
+	assert.NotNil(t, repo.uploads[upload.ID].ScannedAt)
+
+	_, err = uc.DownloadURL(ctx, 7, upload.ID)
+	assert.NoError(t, err)
+}
+
+func TestUploadUsecase_ScanInfected(t *testing.T) {
+	uc, repo, basePath := newTestUsecase(t)
+	notifier := &recordingNotifier{}
+	uc.SetScanner(fakeScanner{}, nil)
+	uc.SetNotifications(&stubUserRepository{user: &entity.User{ID: 7, Email: "alice@example.com"}}, notifier)
+	ctx := context.Background()
+
+	// Without a job queue the scan runs on completion
+	upload := completeUpload(t, uc, "EVIL")
+	stored := repo.uploads[upload.ID]
+	assert.Equal(t, entity.ScanStatusInfected, stored.ScanStatus)
+	assert.Equal(t, "Eicar-Test-Signature", stored.ScanThreat)
+	require.True(t, strings.HasPrefix(stored.FileID, "quarantine/uploads/7/"))
+
+	original := strings.TrimPrefix(stored.FileID, "quarantine/")
+	_, err := os.Stat(filepath.Join(basePath, filepath.FromSlash(original)))
+	assert.True(t, os.IsNotExist(err), "the file is moved out of the uploads")
+	_, err = os.Stat(filepath.Join(basePath, filepath.FromSlash(stored.FileID)))
+	assert.NoError(t, err)
+
+	require.Len(t, notifier.emails, 1)
+	assert.Equal(t, []string{"alice@example.com"}, notifier.emails[0].To)
+
+	_, err = uc.DownloadURL(ctx, 7, upload.ID)
+	assert.True(t, errors.IsFileInfected(err))
+}
+
+func TestUploadUsecase_ScanUpload_Failures(t *testing.T) {
+	uc, repo, _ := newTestUsecase(t)
+	uc.SetScanner(fakeScanner{}, &fakeJobQueue{})
+	ctx := context.Background()
+
+	assert.ErrorIs(t, uc.ScanUpload(ctx, json.RawMessage(`{`)), job.ErrPermanent)
+	payload, _ := json.Marshal(entity.UploadScan{UploadID: 99})
+	assert.ErrorIs(t, uc.ScanUpload(ctx, payload), job.ErrPermanent)
+
+	// A scan that keeps failing leaves the file unavailable
+	upload := completeUpload(t, uc, "safe")
+	payload, _ = json.Marshal(entity.UploadScan{UploadID: upload.ID})
+	uc.FailScan(ctx, payload, assert.AnError)
+	assert.Equal(t, entity.ScanStatusFailed, repo.uploads[upload.ID].ScanStatus)
+
+	_, err := uc.DownloadURL(ctx, 7, upload.ID)
+	assert.True(t, errors.IsFileNotScanned(err))
+}
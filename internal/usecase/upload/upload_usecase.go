@@ -0,0 +1,342 @@
+package upload
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxParts is the most parts a multipart upload may have in S3
+const maxParts = 10000
+
+// UploadUsecase runs resumable uploads: a file is uploaded in parts through the
+// file storage provider's multipart API and tracked in the database, so a client
+// can retry failed parts and resume after reconnecting.
+type UploadUsecase struct {
+	uploadRepo           repository.UploadRepository
+	storage              provider.FileStorageProvider
+	scanner              provider.ScannerProvider
+	jobs                 JobQueue
+	userRepo             repository.UserRepository
+	notificationProvider provider.NotificationProvider
+	config               config.UploadConfig
+	timeouts             *timeout.Policy
+	logger               *logger.Logger
+	now                  func() time.Time
+}
+
+// NewUploadUsecase creates a new resumable upload use case.
+func NewUploadUsecase(uploadRepo repository.UploadRepository, storage provider.FileStorageProvider, cfg config.UploadConfig, log *logger.Logger) *UploadUsecase {
+	if cfg.PartSize <= 0 {
+		cfg.PartSize = 8 << 20
+	}
+
+	return &UploadUsecase{
+		uploadRepo: uploadRepo,
+		storage:    storage,
+		config:     cfg,
+		logger:     log,
+		now:        time.Now,
+	}
+}
+
+// SetTimeouts bounds repository and storage calls with per-operation deadlines.
+func (uc *UploadUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	uc.timeouts = timeouts
+}
+
+// PartTimeout returns how long a client may take to send one part, or 0 for
+// the server's read timeout.
+func (uc *UploadUsecase) PartTimeout() time.Duration {
+	return uc.config.PartTimeout
+}
+
+// Initiate starts an upload of a file of the given size, split into parts of
+// the configured size. Uploads started in an organization are shared with its
+// members.
+func (uc *UploadUsecase) Initiate(ctx context.Context, userID int, req *entity.InitiateUploadRequest) (*entity.Upload, error) {
+	if uc.storage == nil {
+		return nil, errors.ErrProviderUnavailable
+	}
+
+	fileName := path.Base(strings.ReplaceAll(strings.TrimSpace(req.FileName), `\`, "/"))
+	if fileName == "." || fileName == ".." || fileName == "/" {
+		return nil, fmt.Errorf("%w: file name is invalid", errors.ErrInvalidUpload)
+	}
+	if req.Size < 1 || (uc.config.MaxSize > 0 && req.Size > uc.config.MaxSize) {
+		return nil, fmt.Errorf("%w: size must be between 1 and %d bytes", errors.ErrInvalidUpload, uc.config.MaxSize)
+	}
+	totalParts := int((req.Size + uc.config.PartSize - 1) / uc.config.PartSize)
+	if totalParts > maxParts {
+		return nil, fmt.Errorf("%w: file needs more than %d parts", errors.ErrInvalidUpload, maxParts)
+	}
+
+	// A random directory keeps uploads of the same file name apart
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate upload path: %w", err)
+	}
+
+	storageCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationStorage)
+	multipart, err := uc.storage.CreateMultipartUpload(storageCtx, &entity.MultipartUploadRequest{
+		Path:        path.Join("uploads", strconv.Itoa(userID), hex.EncodeToString(buf)),
+		FileName:    fileName,
+		ContentType: req.ContentType,
+		Metadata:    map[string]string{"user-id": strconv.Itoa(userID)},
+	})
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start upload: %w", err)
+	}
+
+	upload := &entity.Upload{
+		UserID:          userID,
+		OrganizationID:  organizationID(ctx),
+		FileID:          multipart.FileID,
+		FileName:        fileName,
+		ContentType:     req.ContentType,
+		Size:            req.Size,
+		PartSize:        uc.config.PartSize,
+		TotalParts:      totalParts,
+		StorageUploadID: multipart.UploadID,
+		Status:          entity.UploadStatusPending,
+		ScanStatus:      uc.initialScanStatus(),
+		Parts:           []entity.UploadPart{},
+		ExpiresAt:       uc.now().Add(uc.config.Expiry),
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	err = uc.uploadRepo.Create(writeCtx, upload)
+	cancel()
+	if err != nil {
+		uc.abortStorage(ctx, upload)
+		return nil, err
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"upload_id":   upload.ID,
+		"user_id":     userID,
+		"size":        upload.Size,
+		"total_parts": upload.TotalParts,
+	}).Info("Upload started")
+
+	return upload, nil
+}
+
+// organizationID returns the ID of the organization the request acts in, or nil
+func organizationID(ctx context.Context) *int {
+	if org := entity.OrganizationFromContext(ctx); org != nil {
+		return &org.ID
+	}
+	return nil
+}
+
+// Get returns an upload of the user, or of the organization the request acts
+// in, with the parts received so far.
+func (uc *UploadUsecase) Get(ctx context.Context, userID, id int) (*entity.Upload, error) {
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	upload, err := uc.uploadRepo.GetByID(readCtx, id)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	// Uploads of other users are reported as missing rather than forbidden,
+	// unless they were made in the organization the request acts in
+	if !entity.SharedWith(ctx, userID, upload.UserID, upload.OrganizationID) {
+		return nil, errors.ErrUploadNotFound
+	}
+
+	if upload.Expired(uc.now()) {
+		uc.expire(ctx, upload)
+	}
+	return upload, nil
+}
+
+// UploadPart stores part n of a pending upload. The part must have exactly its
+// expected size; sending a part again replaces it.
+func (uc *UploadUsecase) UploadPart(ctx context.Context, userID, id, n int, content io.Reader) (*entity.UploadPart, error) {
+	upload, err := uc.pending(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if n < 1 || n > upload.TotalParts {
+		return nil, fmt.Errorf("%w: part number must be between 1 and %d", errors.ErrInvalidUpload, upload.TotalParts)
+	}
+
+	// The body is streamed from the client, so its duration depends on the
+	// connection rather than on storage and no storage deadline is applied
+	expected := upload.ExpectedPartSize(n)
+	stored, err := uc.storage.UploadPart(ctx, &entity.FilePartRequest{
+		FileID:     upload.FileID,
+		UploadID:   upload.StorageUploadID,
+		PartNumber: n,
+		Content:    io.LimitReader(content, expected+1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store part %d: %w", n, err)
+	}
+	if stored.Size != expected {
+		return nil, fmt.Errorf("%w: part %d must be %d bytes, got %d", errors.ErrInvalidUpload, n, expected, stored.Size)
+	}
+
+	part := &entity.UploadPart{PartNumber: n, Size: stored.Size, ETag: stored.ETag}
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	err = uc.uploadRepo.SavePart(writeCtx, upload.ID, part)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	return part, nil
+}
+
+// Complete assembles the file once every part has been received. With a
+// scanner set, the file cannot be downloaded until it has been scanned clean.
+func (uc *UploadUsecase) Complete(ctx context.Context, userID, id int) (*entity.Upload, error) {
+	upload, err := uc.pending(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if missing := upload.MissingParts(); len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %d of %d parts are missing, starting with part %d",
+			errors.ErrInvalidUpload, len(missing), upload.TotalParts, missing[0])
+	}
+
+	parts := make([]entity.FilePart, len(upload.Parts))
+	for i, part := range upload.Parts {
+		parts[i] = entity.FilePart{PartNumber: part.PartNumber, ETag: part.ETag, Size: part.Size}
+	}
+
+	storageCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationStorage)
+	_, err = uc.storage.CompleteMultipartUpload(storageCtx, upload.FileID, upload.StorageUploadID, parts)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete upload: %w", err)
+	}
+
+	upload.Status = entity.UploadStatusCompleted
+	upload.ScanStatus = uc.initialScanStatus()
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	err = uc.uploadRepo.UpdateStatus(writeCtx, upload)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	uc.startScan(ctx, upload)
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"upload_id": upload.ID,
+		"user_id":   userID,
+		"size":      upload.Size,
+	}).Info("Upload completed")
+
+	return upload, nil
+}
+
+// Abort discards a pending upload and the parts received for it.
+func (uc *UploadUsecase) Abort(ctx context.Context, userID, id int) error {
+	upload, err := uc.pending(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	storageCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationStorage)
+	err = uc.storage.AbortMultipartUpload(storageCtx, upload.FileID, upload.StorageUploadID)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to abort upload: %w", err)
+	}
+
+	upload.Status = entity.UploadStatusAborted
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	return uc.uploadRepo.UpdateStatus(writeCtx, upload)
+}
+
+// DownloadURL returns a signed link to the file of a completed upload that
+// passed its malware scan.
+func (uc *UploadUsecase) DownloadURL(ctx context.Context, userID, id int) (*entity.UploadDownloadResponse, error) {
+	upload, err := uc.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Status != entity.UploadStatusCompleted {
+		return nil, fmt.Errorf("%w: upload is not completed", errors.ErrInvalidUpload)
+	}
+	switch upload.ScanStatus {
+	case entity.ScanStatusClean, entity.ScanStatusSkipped:
+	case entity.ScanStatusInfected:
+		return nil, errors.ErrFileInfected
+	default:
+		return nil, fmt.Errorf("%w: scan is %s", errors.ErrFileNotScanned, upload.ScanStatus)
+	}
+
+	storageCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationStorage)
+	url, err := uc.storage.GetSignedURL(storageCtx, upload.FileID, uc.config.DownloadURLTTL)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign download URL: %w", err)
+	}
+
+	return &entity.UploadDownloadResponse{URL: url, ExpiresAt: uc.now().Add(uc.config.DownloadURLTTL)}, nil
+}
+
+// pending returns an upload of the user that still accepts parts.
+func (uc *UploadUsecase) pending(ctx context.Context, userID, id int) (*entity.Upload, error) {
+	if uc.storage == nil {
+		return nil, errors.ErrProviderUnavailable
+	}
+
+	upload, err := uc.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Status != entity.UploadStatusPending {
+		return nil, fmt.Errorf("%w: upload is %s", errors.ErrUploadClosed, upload.Status)
+	}
+	return upload, nil
+}
+
+// expire aborts an upload that was not completed in time. Failures are only
+// logged; the upload is retried the next time it is read.
+func (uc *UploadUsecase) expire(ctx context.Context, upload *entity.Upload) {
+	if uc.storage != nil && !uc.abortStorage(ctx, upload) {
+		return
+	}
+
+	upload.Status = entity.UploadStatusAborted
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.uploadRepo.UpdateStatus(writeCtx, upload); err != nil {
+		uc.logger.ErrorLogger(ctx, err, "Failed to expire upload", map[string]interface{}{
+			"upload_id": upload.ID,
+		})
+	}
+}
+
+// abortStorage discards the parts of an upload in storage, reporting success.
+func (uc *UploadUsecase) abortStorage(ctx context.Context, upload *entity.Upload) bool {
+	storageCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationStorage)
+	defer cancel()
+	if err := uc.storage.AbortMultipartUpload(storageCtx, upload.FileID, upload.StorageUploadID); err != nil {
+		uc.logger.ErrorLogger(ctx, err, "Failed to abort upload in storage", map[string]interface{}{
+			"upload_id": upload.ID,
+			"file_id":   upload.FileID,
+		})
+		return false
+	}
+	return true
+}
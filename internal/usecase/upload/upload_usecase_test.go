@@ -0,0 +1,194 @@
+package upload
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/provider/storage"
+	"boilerplate-go/pkg/errors"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryUploadRepository keeps uploads in memory.
+type memoryUploadRepository struct {
+	uploads map[int]*entity.Upload
+}
+
+func newMemoryUploadRepository() *memoryUploadRepository {
+	return &memoryUploadRepository{uploads: map[int]*entity.Upload{}}
+}
+
+func (r *memoryUploadRepository) Create(ctx context.Context, upload *entity.Upload) error {
+	upload.ID = len(r.uploads) + 1
+	stored := *upload
+	r.uploads[upload.ID] = &stored
+	return nil
+}
+
+func (r *memoryUploadRepository) GetByID(ctx context.Context, id int) (*entity.Upload, error) {
+	upload, ok := r.uploads[id]
+	if !ok {
+		return nil, errors.ErrUploadNotFound
+	}
+	copied := *upload
+	copied.Parts = append([]entity.UploadPart{}, upload.Parts...)
+	return &copied, nil
+}
+
+func (r *memoryUploadRepository) ListByUser(ctx context.Context, userID int) ([]*entity.Upload, error) {
+	var uploads []*entity.Upload
+	for _, upload := range r.uploads {
+		if upload.UserID == userID {
+			copied := *upload
+			copied.Parts = nil
+			uploads = append(uploads, &copied)
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool { return uploads[i].ID < uploads[j].ID })
+	return uploads, nil
+}
+
+func (r *memoryUploadRepository) SavePart(ctx context.Context, uploadID int, part *entity.UploadPart) error {
+	upload := r.uploads[uploadID]
+	for i := range upload.Parts {
+		if upload.Parts[i].PartNumber == part.PartNumber {
+			upload.Parts[i] = *part
+			return nil
+		}
+	}
+	upload.Parts = append(upload.Parts, *part)
+	sort.Slice(upload.Parts, func(i, j int) bool { return upload.Parts[i].PartNumber < upload.Parts[j].PartNumber })
+	return nil
+}
+
+func (r *memoryUploadRepository) UpdateStatus(ctx context.Context, upload *entity.Upload) error {
+	r.uploads[upload.ID].Status = upload.Status
+	r.uploads[upload.ID].ScanStatus = upload.ScanStatus
+	return nil
+}
+
+func (r *memoryUploadRepository) UpdateScan(ctx context.Context, upload *entity.Upload) error {
+	stored := r.uploads[upload.ID]
+	stored.FileID = upload.FileID
+	stored.ScanStatus = upload.ScanStatus
+	stored.ScanThreat = upload.ScanThreat
+	stored.ScannedAt = upload.ScannedAt
+	return nil
+}
+
+func newTestUsecase(t *testing.T) (*UploadUsecase, *memoryUploadRepository, string) {
+	basePath := t.TempDir()
+	files := storage.NewLocalStorageProvider(storage.LocalConfig{
+		BasePath:   basePath,
+		SignedURL:  "/files/signed",
+		SigningKey: "secret",
+	}, logger.NewLogger())
+	repo := newMemoryUploadRepository()
+	uc := NewUploadUsecase(repo, files, config.UploadConfig{
+		MaxSize:        100,
+		PartSize:       4,
+		Expiry:         time.Hour,
+		DownloadURLTTL: time.Minute,
+	}, logger.NewLogger())
+	return uc, repo, basePath
+}
+
+func TestUploadUsecase_ResumableUpload(t *testing.T) {
+	uc, _, basePath := newTestUsecase(t)
+	ctx := context.Background()
+
+	upload, err := uc.Initiate(ctx, 7, &entity.InitiateUploadRequest{FileName: `C:\reports\big.csv`, Size: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "big.csv", upload.FileName)
+	assert.Equal(t, 3, upload.TotalParts)
+	assert.Regexp(t, `^uploads/7/[0-9a-f]{16}/big\.csv$`, upload.FileID)
+
+	// Parts may arrive in any order, and a retried part replaces the earlier one
+	_, err = uc.UploadPart(ctx, 7, upload.ID, 3, bytes.NewBufferString("89"))
+	require.NoError(t, err)
+	_, err = uc.UploadPart(ctx, 7, upload.ID, 1, bytes.NewBufferString("xxxx"))
+	require.NoError(t, err)
+	_, err = uc.UploadPart(ctx, 7, upload.ID, 1, bytes.NewBufferString("0123"))
+	require.NoError(t, err)
+
+	// Resuming shows what is still missing
+	resumed, err := uc.Get(ctx, 7, upload.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2}, resumed.MissingParts())
+	_, err = uc.Complete(ctx, 7, upload.ID)
+	assert.True(t, errors.IsInvalidUpload(err))
+
+	_, err = uc.UploadPart(ctx, 7, upload.ID, 2, bytes.NewBufferString("4567"))
+	require.NoError(t, err)
+	completed, err := uc.Complete(ctx, 7, upload.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.UploadStatusCompleted, completed.Status)
+
+	content, err := os.ReadFile(filepath.Join(basePath, filepath.FromSlash(upload.FileID)))
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", string(content))
+
+	download, err := uc.DownloadURL(ctx, 7, upload.ID)
+	require.NoError(t, err)
+	assert.Contains(t, download.URL, "/files/signed/"+upload.FileID+"?expires=")
+
+	// A completed upload accepts no more parts
+	_, err = uc.UploadPart(ctx, 7, upload.ID, 1, bytes.NewBufferString("0123"))
+	assert.True(t, errors.IsUploadClosed(err))
+}
+
+func TestUploadUsecase_Validation(t *testing.T) {
+	uc, _, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	_, err := uc.Initiate(ctx, 7, &entity.InitiateUploadRequest{FileName: "big.csv", Size: 101})
+	assert.True(t, errors.IsInvalidUpload(err))
+	_, err = uc.Initiate(ctx, 7, &entity.InitiateUploadRequest{FileName: "..", Size: 10})
+	assert.True(t, errors.IsInvalidUpload(err))
+
+	upload, err := uc.Initiate(ctx, 7, &entity.InitiateUploadRequest{FileName: "big.csv", Size: 10})
+	require.NoError(t, err)
+
+	_, err = uc.UploadPart(ctx, 7, upload.ID, 4, bytes.NewBufferString("89"))
+	assert.True(t, errors.IsInvalidUpload(err), "part number out of range")
+	_, err = uc.UploadPart(ctx, 7, upload.ID, 1, bytes.NewBufferString("012"))
+	assert.True(t, errors.IsInvalidUpload(err), "short part")
+	_, err = uc.UploadPart(ctx, 7, upload.ID, 3, bytes.NewBufferString("890"))
+	assert.True(t, errors.IsInvalidUpload(err), "last part too long")
+
+	_, err = uc.Get(ctx, 8, upload.ID)
+	assert.True(t, errors.IsUploadNotFound(err), "uploads of other users are hidden")
+
+	require.NoError(t, uc.Abort(ctx, 7, upload.ID))
+	_, err = uc.Complete(ctx, 7, upload.ID)
+	assert.True(t, errors.IsUploadClosed(err))
+}
+
+func TestUploadUsecase_Expiry(t *testing.T) {
+	uc, repo, basePath := newTestUsecase(t)
+	ctx := context.Background()
+
+	upload, err := uc.Initiate(ctx, 7, &entity.InitiateUploadRequest{FileName: "big.csv", Size: 10})
+	require.NoError(t, err)
+	_, err = uc.UploadPart(ctx, 7, upload.ID, 1, bytes.NewBufferString("0123"))
+	require.NoError(t, err)
+
+	uc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = uc.UploadPart(ctx, 7, upload.ID, 2, bytes.NewBufferString("4567"))
+	assert.True(t, errors.IsUploadClosed(err))
+	assert.Equal(t, entity.UploadStatusAborted, repo.uploads[upload.ID].Status)
+
+	// The parts received so far are discarded
+	entries, err := os.ReadDir(filepath.Join(basePath, ".multipart"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,141 @@
+package user
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/imaging"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// avatarQuality is the JPEG quality of the resized avatars
+	avatarQuality = 85
+	// avatarCacheControl lets CDNs and browsers cache avatars forever; a new
+	// upload gets new file names since they are derived from the content
+	avatarCacheControl = "public, max-age=31536000, immutable"
+)
+
+// avatarSizes are the square renditions generated for every avatar, smallest first.
+// The largest is saved on the profile.
+var avatarSizes = []int{64, 128, 256, 512}
+
+// avatarFilePattern matches the largest rendition's file name and captures its content key
+var avatarFilePattern = regexp.MustCompile(`^([0-9a-f]{16})-512\.jpg$`)
+
+// AvatarMaxUploadSize returns the largest accepted avatar upload in bytes, or 0 for no limit.
+func (uc *UserUsecase) AvatarMaxUploadSize() int64 {
+	return uc.avatarCfg.MaxUploadSize
+}
+
+// UploadAvatar resizes the uploaded image into square JPEG renditions, stores
+// them and saves the largest as the user's avatar. The previous avatar's files
+// are deleted.
+func (uc *UserUsecase) UploadAvatar(ctx context.Context, userID int, content []byte) (*entity.AvatarUploadResponse, error) {
+	if uc.avatars == nil {
+		return nil, errors.ErrProviderUnavailable
+	}
+	if uc.avatarCfg.MaxUploadSize > 0 && int64(len(content)) > uc.avatarCfg.MaxUploadSize {
+		return nil, fmt.Errorf("%w: the limit is %d bytes", errors.ErrImageTooLarge, uc.avatarCfg.MaxUploadSize)
+	}
+
+	img, _, err := imaging.Decode(content, uc.avatarCfg.MaxDimension*uc.avatarCfg.MaxDimension)
+	if err != nil {
+		if stderrors.Is(err, imaging.ErrTooLarge) {
+			return nil, fmt.Errorf("%w: %v", errors.ErrImageTooLarge, err)
+		}
+		return nil, fmt.Errorf("%w: use a JPEG, PNG or GIF image", errors.ErrInvalidImage)
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := uc.userRepo.GetByID(readCtx, userID)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(content)
+	key := hex.EncodeToString(sum[:8])
+
+	result := &entity.AvatarUploadResponse{Sizes: make(map[string]string, len(avatarSizes))}
+	for _, size := range avatarSizes {
+		data, err := imaging.EncodeJPEG(imaging.Thumbnail(img, size), avatarQuality)
+		if err != nil {
+			return nil, err
+		}
+
+		callCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationStorage)
+		uploaded, err := uc.avatars.UploadFile(callCtx, &entity.FileUploadRequest{
+			FileName:     avatarFileName(key, size),
+			Path:         avatarDir(userID),
+			Content:      data,
+			ContentType:  "image/jpeg",
+			CacheControl: avatarCacheControl,
+			Metadata:     map[string]string{"user-id": strconv.Itoa(userID)},
+		})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to store avatar: %w", err)
+		}
+
+		result.Sizes[strconv.Itoa(size)] = uploaded.URL
+		result.AvatarURL = uploaded.URL
+	}
+
+	previous := user.AvatarURL
+	user.AvatarURL = result.AvatarURL
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	err = uc.userRepo.Update(writeCtx, user)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	if previous != result.AvatarURL {
+		if err := uc.deleteAvatar(ctx, userID, previous); err != nil {
+			uc.logger.ErrorLogger(ctx, err, "Failed to delete previous avatar", map[string]interface{}{
+				"user_id": userID,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// deleteAvatar deletes all renditions of the avatar at avatarURL. URLs not
+// pointing to an avatar uploaded for the user, e.g. one set on the profile
+// directly, are left alone.
+func (uc *UserUsecase) deleteAvatar(ctx context.Context, userID int, avatarURL string) error {
+	dir, file := path.Split(avatarURL)
+	dir = strings.TrimSuffix(dir, "/")
+	match := avatarFilePattern.FindStringSubmatch(file)
+	if match == nil || path.Base(dir) != strconv.Itoa(userID) || path.Base(path.Dir(dir)) != "avatars" {
+		return nil
+	}
+
+	for _, size := range avatarSizes {
+		callCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationStorage)
+		err := uc.avatars.DeleteFile(callCtx, path.Join(avatarDir(userID), avatarFileName(match[1], size)))
+		cancel()
+		if err != nil && !errors.IsFileNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func avatarDir(userID int) string {
+	return fmt.Sprintf("avatars/%d", userID)
+}
+
+func avatarFileName(key string, size int) string {
+	return fmt.Sprintf("%s-%d.jpg", key, size)
+}
@@ -0,0 +1,157 @@
+package user
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockFileStorageProvider is a mock implementation of FileStorageProvider
+type MockFileStorageProvider struct {
+	mock.Mock
+}
+
+func (m *MockFileStorageProvider) UploadFile(ctx context.Context, req *entity.FileUploadRequest) (*entity.FileUploadResponse, error) {
+	args := m.Called(ctx, req)
+	if fn, ok := args.Get(0).(func(context.Context, *entity.FileUploadRequest) *entity.FileUploadResponse); ok {
+		return fn(ctx, req), args.Error(1)
+	}
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.FileUploadResponse), args.Error(1)
+}
+
+func (m *MockFileStorageProvider) DownloadFile(ctx context.Context, fileID string) (*entity.FileDownloadResponse, error) {
+	args := m.Called(ctx, fileID)
+	return nil, args.Error(1)
+}
+
+func (m *MockFileStorageProvider) DeleteFile(ctx context.Context, fileID string) error {
+	args := m.Called(ctx, fileID)
+	return args.Error(0)
+}
+
+func (m *MockFileStorageProvider) GetFileInfo(ctx context.Context, fileID string) (*entity.FileInfo, error) {
+	args := m.Called(ctx, fileID)
+	return nil, args.Error(1)
+}
+
+func (m *MockFileStorageProvider) GetSignedURL(ctx context.Context, fileID string, ttl time.Duration) (string, error) {
+	args := m.Called(ctx, fileID, ttl)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockFileStorageProvider) OpenFile(ctx context.Context, fileID string) (io.ReadCloser, error) {
+	args := m.Called(ctx, fileID)
+	return nil, args.Error(1)
+}
+
+func (m *MockFileStorageProvider) MoveFile(ctx context.Context, fileID, newFileID string) error {
+	args := m.Called(ctx, fileID, newFileID)
+	return args.Error(0)
+}
+
+func (m *MockFileStorageProvider) CreateMultipartUpload(ctx context.Context, req *entity.MultipartUploadRequest) (*entity.MultipartUpload, error) {
+	args := m.Called(ctx, req)
+	return nil, args.Error(1)
+}
+
+func (m *MockFileStorageProvider) UploadPart(ctx context.Context, req *entity.FilePartRequest) (*entity.FilePart, error) {
+	args := m.Called(ctx, req)
+	return nil, args.Error(1)
+}
+
+func (m *MockFileStorageProvider) CompleteMultipartUpload(ctx context.Context, fileID, uploadID string, parts []entity.FilePart) (*entity.FileUploadResponse, error) {
+	args := m.Called(ctx, fileID, uploadID, parts)
+	return nil, args.Error(1)
+}
+
+func (m *MockFileStorageProvider) AbortMultipartUpload(ctx context.Context, fileID, uploadID string) error {
+	args := m.Called(ctx, fileID, uploadID)
+	return args.Error(0)
+}
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestUserUsecase_UploadAvatar(t *testing.T) {
+	previous := "https://cdn.example.com/avatars/7/0123456789abcdef-512.jpg"
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", mock.Anything, 7).Return(&entity.User{ID: 7, AvatarURL: previous}, nil)
+	userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *entity.User) bool {
+		return strings.HasPrefix(user.AvatarURL, "https://cdn.example.com/avatars/7/") && strings.HasSuffix(user.AvatarURL, "-512.jpg")
+	})).Return(nil)
+
+	storage := new(MockFileStorageProvider)
+	storage.On("UploadFile", mock.Anything, mock.MatchedBy(func(req *entity.FileUploadRequest) bool {
+		return req.Path == "avatars/7" && req.ContentType == "image/jpeg" && req.CacheControl != ""
+	})).Return(func(_ context.Context, req *entity.FileUploadRequest) *entity.FileUploadResponse {
+		return &entity.FileUploadResponse{URL: "https://cdn.example.com/" + req.Path + "/" + req.FileName}
+	}, nil)
+	for _, size := range []string{"64", "128", "256", "512"} {
+		storage.On("DeleteFile", mock.Anything, "avatars/7/0123456789abcdef-"+size+".jpg").Return(nil)
+	}
+
+	uc := NewUserUsecase(userRepo, logger.NewLogger())
+	uc.SetAvatarStorage(storage, config.AvatarConfig{MaxUploadSize: 1 << 20, MaxDimension: 1000})
+
+	result, err := uc.UploadAvatar(context.Background(), 7, encodeTestPNG(t, 600, 400))
+
+	require.NoError(t, err)
+	assert.Len(t, result.Sizes, 4)
+	assert.Equal(t, result.Sizes["512"], result.AvatarURL)
+	storage.AssertNumberOfCalls(t, "UploadFile", 4)
+	storage.AssertNumberOfCalls(t, "DeleteFile", 4)
+	userRepo.AssertExpectations(t)
+}
+
+func TestUserUsecase_UploadAvatar_Invalid(t *testing.T) {
+	uc := NewUserUsecase(new(MockUserRepository), logger.NewLogger())
+
+	_, err := uc.UploadAvatar(context.Background(), 7, encodeTestPNG(t, 10, 10))
+	assert.True(t, errors.IsProviderUnavailable(err))
+
+	uc.SetAvatarStorage(new(MockFileStorageProvider), config.AvatarConfig{MaxUploadSize: 1 << 20, MaxDimension: 100})
+
+	_, err = uc.UploadAvatar(context.Background(), 7, []byte("<svg/>"))
+	assert.True(t, errors.IsInvalidImage(err))
+
+	_, err = uc.UploadAvatar(context.Background(), 7, encodeTestPNG(t, 200, 100))
+	assert.True(t, errors.IsImageTooLarge(err), "dimensions beyond the pixel budget")
+
+	uc.SetAvatarStorage(new(MockFileStorageProvider), config.AvatarConfig{MaxUploadSize: 10, MaxDimension: 100})
+	_, err = uc.UploadAvatar(context.Background(), 7, encodeTestPNG(t, 10, 10))
+	assert.True(t, errors.IsImageTooLarge(err), "bytes beyond the upload limit")
+}
+
+func TestUserUsecase_DeleteAvatar_IgnoresForeignURLs(t *testing.T) {
+	uc := NewUserUsecase(new(MockUserRepository), logger.NewLogger())
+	storage := new(MockFileStorageProvider)
+	uc.SetAvatarStorage(storage, config.AvatarConfig{})
+
+	for _, url := range []string{
+		"",
+		"https://gravatar.example.com/avatar/0123456789abcdef-512.jpg",
+		"https://cdn.example.com/avatars/8/0123456789abcdef-512.jpg",
+	} {
+		assert.NoError(t, uc.deleteAvatar(context.Background(), 7, url))
+	}
+	storage.AssertNotCalled(t, "DeleteFile", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,45 @@
+package user
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/timeout"
+	"context"
+)
+
+// Page sizes of a user's change history
+const (
+	defaultChangesPageSize = 20
+	maxChangesPageSize     = 100
+)
+
+// SetChangeHistory enables reading the change history of users.
+func (uc *UserUsecase) SetChangeHistory(changes repository.UserChangeRepository) {
+	uc.changes = changes
+}
+
+// ListChanges returns one page of the changes of the user, newest first, and
+// the total count.
+func (uc *UserUsecase) ListChanges(ctx context.Context, filter *entity.UserChangeFilter) ([]*entity.UserChange, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = defaultChangesPageSize
+	}
+	if filter.PageSize > maxChangesPageSize {
+		filter.PageSize = maxChangesPageSize
+	}
+
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	// The history of a deleted user is gone with it
+	if _, err := uc.userRepo.GetByID(ctx, filter.UserID); err != nil {
+		return nil, 0, err
+	}
+	if uc.changes == nil {
+		return []*entity.UserChange{}, 0, nil
+	}
+	return uc.changes.List(ctx, *filter)
+}
@@ -0,0 +1,44 @@
+package user
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// userChanges returns the history it holds, remembering the filter
+type userChanges struct {
+	changes []*entity.UserChange
+	filter  entity.UserChangeFilter
+}
+
+func (r *userChanges) List(ctx context.Context, filter entity.UserChangeFilter) ([]*entity.UserChange, int, error) {
+	r.filter = filter
+	return r.changes, len(r.changes), nil
+}
+
+func TestUserUsecase_ListChanges(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", mock.Anything, 1).Return(&entity.User{ID: 1}, nil)
+	userRepo.On("GetByID", mock.Anything, 2).Return(nil, errors.ErrUserNotFound)
+	history := &userChanges{changes: []*entity.UserChange{{ID: 7, UserID: 1, ChangedBy: 3, Source: entity.ChangeSourceAPI}}}
+
+	uc := NewUserUsecase(userRepo, logger.NewLogger())
+	uc.SetChangeHistory(history)
+
+	filter := entity.UserChangeFilter{UserID: 1, Field: "email", PageSize: 500}
+	changes, total, err := uc.ListChanges(context.Background(), &filter)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, 3, changes[0].ChangedBy)
+	assert.Equal(t, entity.UserChangeFilter{UserID: 1, Field: "email", Page: 1, PageSize: maxChangesPageSize}, history.filter)
+
+	_, _, err = uc.ListChanges(context.Background(), &entity.UserChangeFilter{UserID: 2})
+	assert.True(t, errors.IsUserNotFound(err))
+}
@@ -0,0 +1,260 @@
+package user
+
+import (
+	"archive/zip"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/internal/usecase/job"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DataExportSources are the repositories of the personal data exported
+// besides the profile, orders, sessions and change history. Data of a nil
+// repository is exported empty.
+type DataExportSources struct {
+	Notifications repository.NotificationRepository
+	Uploads       repository.UploadRepository
+	Consents      repository.ConsentRepository
+	Organizations repository.OrganizationRepository
+}
+
+// SetDataExport lets users export their personal data. The archive is built
+// by a background job, stored in storage and emailed as a link valid for ttl.
+func (uc *UserUsecase) SetDataExport(storage provider.FileStorageProvider, sources DataExportSources, ttl time.Duration) {
+	uc.exports = storage
+	uc.exportSources = sources
+	uc.exportTTL = ttl
+}
+
+// RequestDataExport queues the archive of the user's personal data. The user
+// is emailed a download link once it is ready.
+func (uc *UserUsecase) RequestDataExport(ctx context.Context, userID int) (*entity.DataExportResponse, error) {
+	if uc.exports == nil || uc.jobs == nil {
+		return nil, fmt.Errorf("%w: data exports are not enabled", errors.ErrProviderUnavailable)
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	_, err := uc.userRepo.GetByID(readCtx, userID)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := uc.jobs.Enqueue(ctx, entity.JobTypeUserDataExport, entity.UserDataExportJob{UserID: userID}); err != nil {
+		return nil, fmt.Errorf("failed to queue data export: %w", err)
+	}
+
+	return &entity.DataExportResponse{
+		UserID:      userID,
+		Status:      "processing",
+		RequestedAt: time.Now().UTC(),
+	}, nil
+}
+
+// ExportUserData runs a data export request as a background job: the archive
+// is written to file storage and a signed link to it emailed to the user. A
+// retried job builds a new archive.
+func (uc *UserUsecase) ExportUserData(ctx context.Context, payload json.RawMessage) error {
+	var exportJob entity.UserDataExportJob
+	if err := json.Unmarshal(payload, &exportJob); err != nil {
+		return fmt.Errorf("%w: invalid user data export payload: %v", job.ErrPermanent, err)
+	}
+	if uc.exports == nil {
+		return fmt.Errorf("%w: %v", job.ErrPermanent, errors.ErrProviderUnavailable)
+	}
+
+	export, err := uc.ExportData(ctx, exportJob.UserID)
+	if errors.IsUserNotFound(err) {
+		return fmt.Errorf("%w: %v", job.ErrPermanent, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	archive, err := dataArchive(export)
+	if err != nil {
+		return fmt.Errorf("failed to build data archive: %w", err)
+	}
+
+	storageCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationStorage)
+	defer cancel()
+	stored, err := uc.exports.UploadFile(storageCtx, &entity.FileUploadRequest{
+		FileName:    fmt.Sprintf("user-%d-export-%s.zip", export.Profile.ID, export.ExportedAt.Format("20060102-150405")),
+		Path:        "data-exports/" + strconv.Itoa(export.Profile.ID),
+		Content:     archive,
+		ContentType: "application/zip",
+		Metadata:    map[string]string{"user-id": strconv.Itoa(export.Profile.ID)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store data archive: %w", err)
+	}
+	link, err := uc.exports.GetSignedURL(storageCtx, stored.ID, uc.exportTTL)
+	if err != nil {
+		return fmt.Errorf("failed to sign data archive URL: %w", err)
+	}
+
+	if err := uc.sendDataExport(ctx, export.Profile, link); err != nil {
+		return err
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id": export.Profile.ID,
+		"file_id": stored.ID,
+	}).Info("User data exported")
+	return nil
+}
+
+// ExportData collects all personal data held for the user.
+func (uc *UserUsecase) ExportData(ctx context.Context, userID int) (*entity.UserDataExport, error) {
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+	user, err := uc.userRepo.GetByID(readCtx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	export := &entity.UserDataExport{
+		Profile:       user,
+		Orders:        []*entity.Order{},
+		Notifications: []*entity.ReceivedNotification{},
+		Sessions:      []*entity.Session{},
+		Uploads:       []*entity.Upload{},
+		Consents:      []*entity.Consent{},
+		Changes:       []*entity.UserChange{},
+		Memberships:   []*entity.Organization{},
+		ExportedAt:    time.Now().UTC(),
+	}
+
+	if uc.orders != nil {
+		if export.Orders, err = uc.orders.ExportOrders(ctx, entity.OrderFilter{UserID: userID}); err != nil {
+			return nil, fmt.Errorf("failed to get orders: %w", err)
+		}
+	}
+	if uc.sessionRepo != nil {
+		if export.Sessions, err = uc.sessionRepo.ListByUserIDSince(readCtx, userID, time.Time{}); err != nil {
+			return nil, fmt.Errorf("failed to get sessions: %w", err)
+		}
+	}
+	if uc.changes != nil {
+		if export.Changes, err = uc.allChanges(readCtx, userID); err != nil {
+			return nil, fmt.Errorf("failed to get change history: %w", err)
+		}
+	}
+
+	sources := uc.exportSources
+	if sources.Notifications != nil {
+		if export.Notifications, err = sources.Notifications.ListByRecipient(readCtx, user.Email); err != nil {
+			return nil, fmt.Errorf("failed to get notifications: %w", err)
+		}
+	}
+	if sources.Uploads != nil {
+		if export.Uploads, err = sources.Uploads.ListByUser(readCtx, userID); err != nil {
+			return nil, fmt.Errorf("failed to get uploads: %w", err)
+		}
+	}
+	if sources.Consents != nil {
+		if export.Consents, err = sources.Consents.ListByUser(readCtx, userID); err != nil {
+			return nil, fmt.Errorf("failed to get consents: %w", err)
+		}
+	}
+	if sources.Organizations != nil {
+		if export.Memberships, err = sources.Organizations.ListByUser(readCtx, userID); err != nil {
+			return nil, fmt.Errorf("failed to get memberships: %w", err)
+		}
+	}
+
+	return export, nil
+}
+
+// allChanges reads every page of the change history of the user
+func (uc *UserUsecase) allChanges(ctx context.Context, userID int) ([]*entity.UserChange, error) {
+	changes := []*entity.UserChange{}
+	for page := 1; ; page++ {
+		batch, total, err := uc.changes.List(ctx, entity.UserChangeFilter{UserID: userID, Page: page, PageSize: maxChangesPageSize})
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, batch...)
+		if len(batch) == 0 || len(changes) >= total {
+			return changes, nil
+		}
+	}
+}
+
+// dataArchive writes the export as a ZIP archive with a JSON file per kind
+// of data
+func dataArchive(export *entity.UserDataExport) ([]byte, error) {
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"orders.json", export.Orders},
+		{"notifications.json", export.Notifications},
+		{"sessions.json", export.Sessions},
+		{"uploads.json", export.Uploads},
+		{"consents.json", export.Consents},
+		{"changes.json", export.Changes},
+		{"memberships.json", export.Memberships},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sendDataExport emails the user the link to download the archive
+func (uc *UserUsecase) sendDataExport(ctx context.Context, user *entity.User, link string) error {
+	expiresAt := time.Now().Add(uc.exportTTL).UTC()
+	if tz, err := time.LoadLocation(user.Timezone); err == nil && user.Timezone != "" {
+		expiresAt = expiresAt.In(tz)
+	}
+
+	emailReq := &entity.EmailRequest{
+		To:      []string{user.Email},
+		Subject: "Your data export is ready",
+		Body: fmt.Sprintf(`
+Hello %s,
+
+The archive of your personal data you asked for is ready. Download it here:
+
+%s
+
+The link expires on %s. If you did not ask for this export, please contact our support team.
+
+Best regards,
+Boilerplate Team
+		`, user.DisplayName(), link, expiresAt.Format("Jan 2, 2006 15:04 MST")),
+		Metadata: map[string]interface{}{
+			"user_id": user.ID,
+			"type":    "data_export_ready",
+		},
+		Priority: entity.NotificationPriorityTransactional,
+	}
+	if err := uc.jobs.Enqueue(ctx, entity.JobTypeEmail, emailReq); err != nil {
+		return fmt.Errorf("failed to queue data export email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package user
+
+import (
+	"archive/zip"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/internal/usecase/job"
+	"boilerplate-go/pkg/errors"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type exportNotifications struct {
+	repository.NotificationRepository
+	email string
+}
+
+func (r exportNotifications) ListByRecipient(ctx context.Context, email string) ([]*entity.ReceivedNotification, error) {
+	if email != r.email {
+		return []*entity.ReceivedNotification{}, nil
+	}
+	return []*entity.ReceivedNotification{{ID: 4, Subject: "Spring sale"}}, nil
+}
+
+type exportUploads struct{ repository.UploadRepository }
+
+func (exportUploads) ListByUser(ctx context.Context, userID int) ([]*entity.Upload, error) {
+	return []*entity.Upload{{ID: 5, UserID: userID, FileName: "report.pdf"}}, nil
+}
+
+type exportConsents struct{ repository.ConsentRepository }
+
+func (exportConsents) ListByUser(ctx context.Context, userID int) ([]*entity.Consent, error) {
+	return []*entity.Consent{{ID: 6, UserID: userID, Document: entity.DocumentTerms, Version: "2024-01"}}, nil
+}
+
+type exportOrganizations struct {
+	repository.OrganizationRepository
+}
+
+func (exportOrganizations) ListByUser(ctx context.Context, userID int) ([]*entity.Organization, error) {
+	return []*entity.Organization{{ID: 7, Name: "Acme", Role: entity.OrgRoleAdmin}}, nil
+}
+
+// readArchive returns the JSON files of a data archive by name
+func readArchive(t *testing.T, content []byte) map[string]json.RawMessage {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+
+	files := make(map[string]json.RawMessage, len(archive.File))
+	for _, file := range archive.File {
+		r, err := file.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		r.Close()
+		files[file.Name] = data
+	}
+	return files
+}
+
+func TestUserUsecase_RequestDataExport(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("queued", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetByID", mock.Anything, 1).Return(&entity.User{ID: 1}, nil)
+		jobs := new(MockJobQueue)
+		jobs.On("Enqueue", mock.Anything, entity.JobTypeUserDataExport, entity.UserDataExportJob{UserID: 1}).Return(nil)
+
+		uc := NewUserUsecase(userRepo, logger.NewLogger())
+		uc.SetJobQueue(jobs)
+		uc.SetDataExport(new(MockFileStorageProvider), DataExportSources{}, time.Hour)
+		result, err := uc.RequestDataExport(ctx, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, "processing", result.Status)
+		jobs.AssertExpectations(t)
+	})
+
+	t.Run("without file storage", func(t *testing.T) {
+		uc := NewUserUsecase(new(MockUserRepository), logger.NewLogger())
+		uc.SetJobQueue(new(MockJobQueue))
+		_, err := uc.RequestDataExport(ctx, 1)
+
+		assert.True(t, errors.IsProviderUnavailable(err), err)
+	})
+}
+
+func TestUserUsecase_ExportUserData(t *testing.T) {
+	user := &entity.User{ID: 1, Username: "alice", Email: "alice@example.com"}
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", mock.Anything, 1).Return(user, nil)
+	sessions := new(MockSessionRepository)
+	sessions.On("ListByUserIDSince", mock.Anything, 1, time.Time{}).Return([]*entity.Session{{ID: 3, UserID: 1, Device: "Firefox"}}, nil)
+
+	var archive *entity.FileUploadRequest
+	storage := new(MockFileStorageProvider)
+	storage.On("UploadFile", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		archive = args.Get(1).(*entity.FileUploadRequest)
+	}).Return(&entity.FileUploadResponse{ID: "data-exports/1/export.zip"}, nil)
+	storage.On("GetSignedURL", mock.Anything, "data-exports/1/export.zip", 24*time.Hour).Return("https://files.example.com/signed", nil)
+
+	var sent *entity.EmailRequest
+	jobs := new(MockJobQueue)
+	jobs.On("Enqueue", mock.Anything, entity.JobTypeEmail, mock.Anything).Run(func(args mock.Arguments) {
+		sent = args.Get(2).(*entity.EmailRequest)
+	}).Return(nil)
+
+	uc := NewUserUsecase(userRepo, logger.NewLogger())
+	uc.SetErasure(sessions, nil)
+	uc.SetJobQueue(jobs)
+	uc.SetChangeHistory(&userChanges{changes: []*entity.UserChange{{ID: 2, UserID: 1, Changes: []entity.FieldChange{{Field: "email"}}}}})
+	uc.SetDataExport(storage, DataExportSources{
+		Notifications: exportNotifications{email: "alice@example.com"},
+		Uploads:       exportUploads{},
+		Consents:      exportConsents{},
+		Organizations: exportOrganizations{},
+	}, 24*time.Hour)
+
+	require.NoError(t, uc.ExportUserData(context.Background(), json.RawMessage(`{"user_id":1}`)))
+
+	require.NotNil(t, archive)
+	assert.Equal(t, "application/zip", archive.ContentType)
+	assert.Equal(t, "data-exports/1", archive.Path)
+	files := readArchive(t, archive.Content)
+	assert.ElementsMatch(t, []string{
+		"profile.json", "orders.json", "notifications.json", "sessions.json",
+		"uploads.json", "consents.json", "changes.json", "memberships.json",
+	}, keys(files))
+	assert.JSONEq(t, `[]`, string(files["orders.json"]))
+	assert.Contains(t, string(files["profile.json"]), `"email": "alice@example.com"`)
+	assert.Contains(t, string(files["notifications.json"]), `"subject": "Spring sale"`)
+	assert.Contains(t, string(files["sessions.json"]), `"device": "Firefox"`)
+	assert.Contains(t, string(files["uploads.json"]), `"file_name": "report.pdf"`)
+	assert.Contains(t, string(files["consents.json"]), `"version": "2024-01"`)
+	assert.Contains(t, string(files["changes.json"]), `"field": "email"`)
+	assert.Contains(t, string(files["memberships.json"]), `"name": "Acme"`)
+
+	require.NotNil(t, sent)
+	assert.Equal(t, []string{"alice@example.com"}, sent.To)
+	assert.Contains(t, sent.Body, "https://files.example.com/signed")
+}
+
+func TestUserUsecase_ExportUserData_DeletedUser(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", mock.Anything, 1).Return(nil, errors.ErrUserNotFound)
+
+	uc := NewUserUsecase(userRepo, logger.NewLogger())
+	uc.SetDataExport(new(MockFileStorageProvider), DataExportSources{}, time.Hour)
+	err := uc.ExportUserData(context.Background(), json.RawMessage(`{"user_id":1}`))
+
+	assert.ErrorIs(t, err, job.ErrPermanent)
+}
+
+func keys(files map[string]json.RawMessage) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,146 @@
+package user
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/emailaddr"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ListUsers returns a page of the users matching the filter and their number.
+func (uc *UserUsecase) ListUsers(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int, error) {
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	return uc.userRepo.List(ctx, filter)
+}
+
+// CreateDirectoryUser creates a user managed by an identity provider, with
+// the external ID of its email address. It fails with ErrUserAlreadyExists
+// when the username or email address is taken.
+func (uc *UserUsecase) CreateDirectoryUser(ctx context.Context, req *entity.DirectoryUser) (*entity.User, error) {
+	user := &entity.User{}
+	if err := uc.applyDirectoryUser(user, req); err != nil {
+		return nil, err
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.userRepo.Create(writeCtx, user); err != nil {
+		return nil, err
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id":     user.ID,
+		"external_id": user.ExternalID,
+		"active":      req.Active,
+		"action":      "directory_user_created",
+	}).Info("Directory user created")
+	return user, nil
+}
+
+// ReplaceDirectoryUser replaces the username, email address and names of the
+// user, and its password when one is given. Deactivating the user logs out
+// all of its sessions.
+func (uc *UserUsecase) ReplaceDirectoryUser(ctx context.Context, id int, req *entity.DirectoryUser) (*entity.User, error) {
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := uc.userRepo.GetByID(readCtx, id)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	wasActive := user.DeactivatedAt == nil
+	if err := uc.applyDirectoryUser(user, req); err != nil {
+		return nil, err
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.userRepo.Update(writeCtx, user); err != nil {
+		return nil, err
+	}
+	if wasActive && !req.Active {
+		if err := uc.revokeAllSessions(writeCtx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id": id,
+		"active":  req.Active,
+		"action":  "directory_user_replaced",
+	}).Info("Directory user replaced")
+	return user, nil
+}
+
+// DeactivateUser deactivates the user and logs out all of its sessions, so
+// it can no longer sign in. Its data is kept.
+func (uc *UserUsecase) DeactivateUser(ctx context.Context, id int) error {
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := uc.userRepo.GetByID(readCtx, id)
+	cancel()
+	if err != nil {
+		return err
+	}
+	if user.DeactivatedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	user.DeactivatedAt = &now
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.userRepo.Update(writeCtx, user); err != nil {
+		return err
+	}
+	if err := uc.revokeAllSessions(writeCtx, id); err != nil {
+		return err
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id": id,
+		"action":  "user_deactivated",
+	}).Info("User deactivated")
+	return nil
+}
+
+// applyDirectoryUser sets the attributes of the user managed by the identity
+// provider, keeping when it was deactivated
+func (uc *UserUsecase) applyDirectoryUser(user *entity.User, req *entity.DirectoryUser) error {
+	user.Username = strings.TrimSpace(req.Username)
+	user.Email = emailaddr.Normalize(req.Email, false)
+	user.ExternalID = ExternalID(user.Email)
+	user.FirstName = req.FirstName
+	user.LastName = req.LastName
+
+	if req.Password != "" {
+		hashedPassword, err := uc.newPassword(req.Password, user.Username, user.Email)
+		if err != nil {
+			return err
+		}
+		user.Password = hashedPassword
+	}
+
+	switch {
+	case req.Active:
+		user.DeactivatedAt = nil
+	case user.DeactivatedAt == nil:
+		now := time.Now()
+		user.DeactivatedAt = &now
+	}
+	return nil
+}
+
+func (uc *UserUsecase) revokeAllSessions(ctx context.Context, userID int) error {
+	if uc.sessionRepo == nil {
+		return nil
+	}
+	if err := uc.sessionRepo.RevokeAllByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
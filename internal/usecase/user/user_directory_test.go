@@ -0,0 +1,95 @@
+package user
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/hash"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserUsecase_CreateDirectoryUser(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	userRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.User")).Return(nil)
+	uc := NewUserUsecase(userRepo, logger.NewLogger())
+
+	user, err := uc.CreateDirectoryUser(context.Background(), &entity.DirectoryUser{
+		Username: "alice",
+		Email:    "Alice@Example.com",
+		Password: "correct horse battery",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", user.Email)
+	assert.Equal(t, ExternalID("alice@example.com"), user.ExternalID)
+	assert.True(t, hash.CheckPassword("correct horse battery", user.Password))
+	assert.NotNil(t, user.DeactivatedAt, "users created inactive are deactivated")
+}
+
+func TestUserUsecase_ReplaceDirectoryUser(t *testing.T) {
+	newUsecase := func(user *entity.User) (*UserUsecase, *MockUserRepository, *MockSessionRepository) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		userRepo.On("Update", mock.Anything, user).Return(nil)
+		sessionRepo := new(MockSessionRepository)
+		uc := NewUserUsecase(userRepo, logger.NewLogger())
+		uc.SetErasure(sessionRepo, nil)
+		return uc, userRepo, sessionRepo
+	}
+
+	t.Run("deactivating logs out", func(t *testing.T) {
+		user := &entity.User{ID: 1, Username: "alice", Email: "alice@example.com", Password: "hash"}
+		uc, _, sessionRepo := newUsecase(user)
+		sessionRepo.On("RevokeAllByUserID", mock.Anything, 1).Return(nil).Once()
+
+		replaced, err := uc.ReplaceDirectoryUser(context.Background(), 1, &entity.DirectoryUser{
+			Username:  "alice.l",
+			Email:     "alice@example.com",
+			FirstName: "Alice",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "alice.l", replaced.Username)
+		assert.Equal(t, "Alice", replaced.FirstName)
+		assert.Equal(t, "hash", replaced.Password, "the password is kept without a new one")
+		assert.NotNil(t, replaced.DeactivatedAt)
+		sessionRepo.AssertExpectations(t)
+	})
+
+	t.Run("reactivating", func(t *testing.T) {
+		deactivatedAt := time.Now()
+		user := &entity.User{ID: 2, Username: "bob", Email: "bob@example.com", DeactivatedAt: &deactivatedAt}
+		uc, _, sessionRepo := newUsecase(user)
+
+		replaced, err := uc.ReplaceDirectoryUser(context.Background(), 2, &entity.DirectoryUser{
+			Username: "bob",
+			Email:    "bob@example.com",
+			Active:   true,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, replaced.DeactivatedAt)
+		sessionRepo.AssertNotCalled(t, "RevokeAllByUserID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserUsecase_DeactivateUser(t *testing.T) {
+	user := &entity.User{ID: 1, Username: "alice"}
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", mock.Anything, 1).Return(user, nil)
+	userRepo.On("Update", mock.Anything, user).Return(nil).Once()
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("RevokeAllByUserID", mock.Anything, 1).Return(nil).Once()
+	uc := NewUserUsecase(userRepo, logger.NewLogger())
+	uc.SetErasure(sessionRepo, nil)
+
+	require.NoError(t, uc.DeactivateUser(context.Background(), 1))
+	assert.NotNil(t, user.DeactivatedAt)
+
+	// Deactivating again changes nothing
+	require.NoError(t, uc.DeactivateUser(context.Background(), 1))
+	userRepo.AssertExpectations(t)
+	sessionRepo.AssertExpectations(t)
+}
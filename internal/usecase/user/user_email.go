@@ -0,0 +1,196 @@
+package user
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/emailaddr"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// requestEmailChange makes email the user's pending email address, or cancels
+// the pending change when it is the current one. It reports whether the
+// confirmation link must be sent.
+func (uc *UserUsecase) requestEmailChange(ctx context.Context, user *entity.User, email string) (bool, error) {
+	email = emailaddr.Normalize(email, uc.emailChange.StripPlus)
+	if strings.EqualFold(email, user.Email) {
+		user.PendingEmail = ""
+		return false, nil
+	}
+	if uc.emailChange.SigningKey == "" || uc.jobs == nil {
+		return false, fmt.Errorf("%w: email addresses cannot be changed", errors.ErrProviderUnavailable)
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	owner, err := uc.userRepo.GetByEmail(readCtx, email)
+	cancel()
+	if err != nil && !errors.IsUserNotFound(err) {
+		return false, fmt.Errorf("failed to check email: %w", err)
+	}
+	if owner != nil && owner.ID != user.ID {
+		return false, errors.ErrEmailAlreadyInUse
+	}
+
+	user.PendingEmail = email
+	return true, nil
+}
+
+// ConfirmEmail changes the email address of the user to the pending one, with
+// the token of the link emailed to it. Tokens of other links, expired ones and
+// ones issued before the pending address or the email changed again are
+// reported as not found.
+func (uc *UserUsecase) ConfirmEmail(ctx context.Context, req *entity.ConfirmEmailRequest) (*entity.User, error) {
+	userID, expires, signature, ok := parseEmailToken(req.Token)
+	if !ok || uc.emailChange.SigningKey == "" || !time.Now().Before(time.Unix(expires, 0)) {
+		return nil, errors.ErrEmailChangeNotFound
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := uc.userRepo.GetByID(readCtx, userID)
+	cancel()
+	if errors.IsUserNotFound(err) {
+		return nil, errors.ErrEmailChangeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.PendingEmail == "" || !hmac.Equal([]byte(signature), []byte(uc.signEmailChange(user, expires))) {
+		return nil, errors.ErrEmailChangeNotFound
+	}
+
+	user.Email, user.PendingEmail = user.PendingEmail, ""
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	err = uc.userRepo.Update(writeCtx, user)
+	cancel()
+	if errors.IsUserAlreadyExists(err) {
+		// Another account took the address since the change was requested
+		return nil, errors.ErrEmailAlreadyInUse
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id": user.ID,
+		"action":  "email_changed",
+	}).Info("Email address changed")
+
+	return user, nil
+}
+
+// sendEmailChange emails the link confirming the pending address to it, and
+// a notice of the change to the current address so the owner learns about a
+// change they did not ask for
+func (uc *UserUsecase) sendEmailChange(ctx context.Context, user *entity.User) error {
+	expires := time.Now().Add(uc.emailChange.ConfirmTTL).Unix()
+	token := fmt.Sprintf("%d.%d.%s", user.ID, expires, uc.signEmailChange(user, expires))
+	confirm := "Confirm it in the app with this code:\n\n" + token
+	if link := uc.emailChangeLink(token); link != "" {
+		confirm = "Confirm it here:\n\n" + link
+	}
+	expiresAt := time.Unix(expires, 0).UTC()
+	if tz, err := time.LoadLocation(user.Timezone); err == nil && user.Timezone != "" {
+		expiresAt = expiresAt.In(tz)
+	}
+
+	confirmation := &entity.EmailRequest{
+		To:      []string{user.PendingEmail},
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf(`
+Hello %s,
+
+You asked to use this email address for your account.
+
+%s
+
+Until you confirm it, your account keeps its current address. The link expires on %s.
+
+If you did not ask for this, you can ignore this email.
+
+Best regards,
+Boilerplate Team
+		`, user.DisplayName(), confirm, expiresAt.Format("Jan 2, 2006 15:04 MST")),
+		Metadata: map[string]interface{}{
+			"user_id": user.ID,
+			"type":    "email_change_confirmation",
+		},
+		Priority: entity.NotificationPriorityTransactional,
+	}
+	notice := &entity.EmailRequest{
+		To:      []string{user.Email},
+		Subject: "Your email address is being changed",
+		Body: fmt.Sprintf(`
+Hello %s,
+
+A change of your account's email address to %s was requested. It takes effect once confirmed from the new address.
+
+If this was you, you can ignore this email.
+
+If it was not, change your password now and set your email address back in your profile settings.
+
+Best regards,
+Boilerplate Team
+		`, user.DisplayName(), user.PendingEmail),
+		Metadata: map[string]interface{}{
+			"user_id": user.ID,
+			"type":    "security_email_change",
+		},
+		Priority: entity.NotificationPriorityTransactional,
+	}
+
+	for _, emailReq := range []*entity.EmailRequest{confirmation, notice} {
+		if user.Locale != "" {
+			emailReq.Metadata["locale"] = user.Locale
+		}
+		if err := uc.jobs.Enqueue(ctx, entity.JobTypeEmail, emailReq); err != nil {
+			return fmt.Errorf("failed to send email change confirmation: %w", err)
+		}
+	}
+	return nil
+}
+
+// emailChangeLink returns the link emailed to confirm an address, or ""
+// without a confirmation URL
+func (uc *UserUsecase) emailChangeLink(token string) string {
+	if uc.emailChange.ConfirmURL == "" {
+		return ""
+	}
+	separator := "?"
+	if strings.Contains(uc.emailChange.ConfirmURL, "?") {
+		separator = "&"
+	}
+	return uc.emailChange.ConfirmURL + separator + url.Values{"token": {token}}.Encode()
+}
+
+// signEmailChange signs the change of the user's email to the pending one,
+// until expires
+func (uc *UserUsecase) signEmailChange(user *entity.User, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(uc.emailChange.SigningKey))
+	fmt.Fprintf(mac, "%d\n%s\n%s\n%d", user.ID, user.Email, user.PendingEmail, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// parseEmailToken splits a token of the form <user ID>.<expiry>.<signature>
+func parseEmailToken(token string) (userID int, expires int64, signature string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return 0, 0, "", false
+	}
+	userID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, "", false
+	}
+	expires, err = strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, "", false
+	}
+	return userID, expires, parts[2], true
+}
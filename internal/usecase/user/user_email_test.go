@@ -0,0 +1,135 @@
+package user
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var emailChangeConfig = config.EmailAddressConfig{
+	ConfirmTTL: time.Hour,
+	ConfirmURL: "https://app.example.com/email/confirm",
+	SigningKey: "email-change-key",
+}
+
+// confirmationToken returns the token of the link in a confirmation email
+func confirmationToken(t *testing.T, emailReq *entity.EmailRequest) string {
+	for _, line := range strings.Split(emailReq.Body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, emailChangeConfig.ConfirmURL) {
+			link, err := url.Parse(line)
+			require.NoError(t, err)
+			return link.Query().Get("token")
+		}
+	}
+	t.Fatal("no confirmation link in the email")
+	return ""
+}
+
+func TestUserUsecase_ChangeEmail(t *testing.T) {
+	ctx := context.Background()
+	userRepo := new(MockUserRepository)
+	jobs := new(MockJobQueue)
+	userRepo.On("GetByID", mock.Anything, 1).Return(&entity.User{ID: 1, Username: "alice", Email: "alice@example.com"}, nil).Once()
+	userRepo.On("GetByEmail", mock.Anything, "alice@example.org").Return(nil, errors.ErrUserNotFound)
+	userRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	var sent []*entity.EmailRequest
+	jobs.On("Enqueue", mock.Anything, entity.JobTypeEmail, mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(2).(*entity.EmailRequest))
+	}).Return(nil)
+
+	uc := NewUserUsecase(userRepo, logger.NewLogger())
+	uc.SetJobQueue(jobs)
+	uc.SetEmailChange(emailChangeConfig)
+
+	user, err := uc.UpdateProfile(ctx, 1, &entity.UpdateProfileRequest{Email: stringPtr(" Alice@Example.org ")})
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", user.Email, "the email in use is kept until confirmed")
+	assert.Equal(t, "alice@example.org", user.PendingEmail)
+
+	require.Len(t, sent, 2)
+	assert.Equal(t, []string{"alice@example.org"}, sent[0].To)
+	assert.Equal(t, []string{"alice@example.com"}, sent[1].To, "the current address is told about the change")
+	assert.Contains(t, sent[1].Body, "alice@example.org")
+	token := confirmationToken(t, sent[0])
+
+	userRepo.On("GetByID", mock.Anything, 1).Return(&entity.User{ID: 1, Username: "alice", Email: "alice@example.com", PendingEmail: "alice@example.org"}, nil)
+
+	_, err = uc.ConfirmEmail(ctx, &entity.ConfirmEmailRequest{Token: token + "0"})
+	assert.True(t, errors.IsEmailChangeNotFound(err), "tampered token: %v", err)
+
+	user, err = uc.ConfirmEmail(ctx, &entity.ConfirmEmailRequest{Token: token})
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.org", user.Email)
+	assert.Empty(t, user.PendingEmail)
+	userRepo.AssertCalled(t, "Update", mock.Anything, mock.MatchedBy(func(user *entity.User) bool {
+		return user.Email == "alice@example.org" && user.PendingEmail == ""
+	}))
+}
+
+func TestUserUsecase_ChangeEmail_Rejected(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("address of another user", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetByID", mock.Anything, 1).Return(&entity.User{ID: 1, Email: "alice@example.com"}, nil)
+		userRepo.On("GetByEmail", mock.Anything, "bob@example.com").Return(&entity.User{ID: 2, Email: "bob@example.com"}, nil)
+
+		uc := NewUserUsecase(userRepo, logger.NewLogger())
+		uc.SetJobQueue(new(MockJobQueue))
+		uc.SetEmailChange(emailChangeConfig)
+		_, err := uc.UpdateProfile(ctx, 1, &entity.UpdateProfileRequest{Email: stringPtr("bob@example.com")})
+
+		assert.True(t, errors.IsEmailAlreadyInUse(err), err)
+		userRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("without a signing key", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetByID", mock.Anything, 1).Return(&entity.User{ID: 1, Email: "alice@example.com"}, nil)
+
+		uc := NewUserUsecase(userRepo, logger.NewLogger())
+		uc.SetJobQueue(new(MockJobQueue))
+		_, err := uc.UpdateProfile(ctx, 1, &entity.UpdateProfileRequest{Email: stringPtr("alice@example.org")})
+
+		assert.True(t, errors.IsProviderUnavailable(err), err)
+	})
+
+	t.Run("current address cancels the pending change", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetByID", mock.Anything, 1).Return(&entity.User{ID: 1, Email: "alice@example.com", PendingEmail: "alice@example.org"}, nil)
+		userRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+		uc := NewUserUsecase(userRepo, logger.NewLogger())
+		user, err := uc.UpdateProfile(ctx, 1, &entity.UpdateProfileRequest{Email: stringPtr("Alice@example.com")})
+
+		require.NoError(t, err)
+		assert.Empty(t, user.PendingEmail)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		user := &entity.User{ID: 1, Email: "alice@example.com", PendingEmail: "alice@example.org"}
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetByID", mock.Anything, 1).Return(user, nil)
+
+		uc := NewUserUsecase(userRepo, logger.NewLogger())
+		uc.SetEmailChange(emailChangeConfig)
+		expires := time.Now().Add(-time.Minute).Unix()
+		token := "1." + strconv.FormatInt(expires, 10) + "." + uc.signEmailChange(user, expires)
+		_, err := uc.ConfirmEmail(ctx, &entity.ConfirmEmailRequest{Token: token})
+
+		assert.True(t, errors.IsEmailChangeNotFound(err), err)
+		userRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
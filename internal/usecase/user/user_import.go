@@ -0,0 +1,273 @@
+package user
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/job"
+	"boilerplate-go/pkg/emailaddr"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/hash"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+const (
+	// importBatchSize is the number of users inserted per statement
+	importBatchSize = 500
+	// importAsyncThreshold is the row count above which imports run in the background.
+	// Hashing a password takes tens of milliseconds, so larger files would outlast
+	// the HTTP write timeout.
+	importAsyncThreshold = 50
+	// importMaxRows caps the size of a single import file
+	importMaxRows = 50000
+
+	ImportStatusCompleted  = "completed"
+	ImportStatusProcessing = "processing"
+	ImportStatusFailed     = "failed"
+
+	importRowCreated = "created"
+	importRowFailed  = "failed"
+)
+
+// ImportUsers validates and creates users in batches, returning a per-row report.
+// Files larger than importAsyncThreshold are stored and imported by a background
+// job; the returned report then only carries the ID to fetch the outcome with.
+func (uc *UserUsecase) ImportUsers(ctx context.Context, rows []entity.UserImportRow) (*entity.UserImportReport, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no rows", errors.ErrInvalidImport)
+	}
+	if len(rows) > importMaxRows {
+		return nil, fmt.Errorf("%w: %d rows exceeds the limit of %d", errors.ErrInvalidImport, len(rows), importMaxRows)
+	}
+
+	if len(rows) > importAsyncThreshold && uc.imports != nil && uc.jobs != nil {
+		return uc.scheduleImport(ctx, rows)
+	}
+
+	return uc.importUsers(ctx, rows)
+}
+
+// GetImport returns the report of a background import.
+func (uc *UserUsecase) GetImport(ctx context.Context, importID int) (*entity.UserImportReport, error) {
+	if uc.imports == nil {
+		return nil, errors.ErrImportNotFound
+	}
+
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
+	return uc.imports.GetByID(ctx, importID)
+}
+
+func (uc *UserUsecase) scheduleImport(ctx context.Context, rows []entity.UserImportRow) (*entity.UserImportReport, error) {
+	report := &entity.UserImportReport{
+		Status: ImportStatusProcessing,
+		Total:  len(rows),
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	err := uc.imports.Create(writeCtx, report, rows)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to store import: %w", err)
+	}
+
+	if err := uc.jobs.Enqueue(ctx, entity.JobTypeUserImport, entity.UserImport{ImportID: report.ID}); err != nil {
+		uc.failImport(ctx, report, err)
+		return nil, fmt.Errorf("failed to schedule import: %w", err)
+	}
+
+	return report, nil
+}
+
+// RunImport runs a stored import as a background job. A retried job reports
+// users created by an earlier attempt as already existing.
+func (uc *UserUsecase) RunImport(ctx context.Context, payload json.RawMessage) error {
+	var userImport entity.UserImport
+	if err := json.Unmarshal(payload, &userImport); err != nil {
+		return fmt.Errorf("%w: invalid user import payload: %v", job.ErrPermanent, err)
+	}
+
+	report, err := uc.GetImport(ctx, userImport.ImportID)
+	if err != nil {
+		if errors.IsImportNotFound(err) {
+			return fmt.Errorf("%w: %v", job.ErrPermanent, err)
+		}
+		return err
+	}
+	if report.Status != ImportStatusProcessing {
+		return nil
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	rows, err := uc.imports.GetRows(readCtx, report.ID)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	result, err := uc.importUsers(ctx, rows)
+	if err != nil {
+		return err
+	}
+	result.ID = report.ID
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.imports.Complete(writeCtx, result); err != nil {
+		return err
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"import_id": result.ID,
+		"total":     result.Total,
+		"created":   result.Created,
+		"failed":    result.Failed,
+		"action":    "user_import_completed",
+	}).Info("Background user import completed")
+
+	return nil
+}
+
+// FailImport marks an import whose job exhausted its retries as failed.
+func (uc *UserUsecase) FailImport(ctx context.Context, payload json.RawMessage, jobErr error) {
+	var userImport entity.UserImport
+	if err := json.Unmarshal(payload, &userImport); err != nil {
+		return
+	}
+
+	uc.failImport(ctx, &entity.UserImportReport{ID: userImport.ImportID}, jobErr)
+}
+
+func (uc *UserUsecase) failImport(ctx context.Context, report *entity.UserImportReport, cause error) {
+	report.Status = ImportStatusFailed
+	report.Error = cause.Error()
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.imports.Complete(writeCtx, report); err != nil {
+		uc.logger.ErrorLogger(ctx, err, "Failed to mark user import as failed", map[string]interface{}{
+			"import_id": report.ID,
+		})
+	}
+}
+
+func (uc *UserUsecase) importUsers(ctx context.Context, rows []entity.UserImportRow) (*entity.UserImportReport, error) {
+	report := &entity.UserImportReport{
+		Status:  ImportStatusCompleted,
+		Total:   len(rows),
+		Results: make([]entity.UserImportRowResult, len(rows)),
+	}
+
+	seenUsernames := make(map[string]bool, len(rows))
+	seenEmails := make(map[string]bool, len(rows))
+
+	var (
+		batch    []*entity.User
+		batchIdx []int
+	)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := uc.userRepo.CreateBatch(ctx, batch); err != nil {
+			return err
+		}
+		for i, user := range batch {
+			result := &report.Results[batchIdx[i]]
+			if user.ID == 0 {
+				result.Status = importRowFailed
+				result.Error = errors.ErrUserAlreadyExists.Error()
+				continue
+			}
+			result.Status = importRowCreated
+			result.UserID = user.ID
+		}
+		batch, batchIdx = batch[:0], batchIdx[:0]
+		return nil
+	}
+
+	for i, row := range rows {
+		username := strings.TrimSpace(row.Username)
+		email := emailaddr.Normalize(row.Email, false)
+
+		result := &report.Results[i]
+		result.Row = i + 1
+		result.Username = username
+
+		if err := validateImportRow(username, email, row.Password); err != nil {
+			result.Status = importRowFailed
+			result.Error = err.Error()
+			continue
+		}
+
+		if seenUsernames[username] || seenEmails[email] {
+			result.Status = importRowFailed
+			result.Error = "duplicate username or email in file"
+			continue
+		}
+		seenUsernames[username] = true
+		seenEmails[email] = true
+
+		hashedPassword, err := hash.HashPassword(row.Password)
+		if err != nil {
+			result.Status = importRowFailed
+			result.Error = "failed to hash password"
+			continue
+		}
+
+		batch = append(batch, &entity.User{
+			Username: username,
+			Email:    email,
+			Password: hashedPassword,
+		})
+		batchIdx = append(batchIdx, i)
+
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return nil, fmt.Errorf("failed to import users: %w", err)
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return nil, fmt.Errorf("failed to import users: %w", err)
+	}
+
+	for _, result := range report.Results {
+		if result.Status == importRowCreated {
+			report.Created++
+		} else {
+			report.Failed++
+		}
+	}
+
+	return report, nil
+}
+
+// validateImportRow applies the same rules as the registration request.
+func validateImportRow(username, email, password string) error {
+	if username == "" {
+		return fmt.Errorf("username is required")
+	}
+	if len(username) > 50 {
+		return fmt.Errorf("username must be at most 50 characters")
+	}
+	if email == "" {
+		return fmt.Errorf("email is required")
+	}
+	if len(email) > 100 {
+		return fmt.Errorf("email must be at most 100 characters")
+	}
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return fmt.Errorf("email is invalid")
+	}
+	if len(password) < 6 {
+		return fmt.Errorf("password must be at least 6 characters")
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package user
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/job"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockUserRepository is a mock implementation of UserRepository
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(ctx context.Context, user *entity.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) CreateBatch(ctx context.Context, users []*entity.User) error {
+	args := m.Called(ctx, users)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetByID(ctx context.Context, id int) (*entity.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) List(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*entity.User), args.Int(1), args.Error(2)
+}
+
+func (m *MockUserRepository) IterateActiveUsers(ctx context.Context, fn func(*entity.User) error) error {
+	args := m.Called(ctx, fn)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Update(ctx context.Context, user *entity.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Upsert(ctx context.Context, user *entity.User) (bool, error) {
+	args := m.Called(ctx, user)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Anonymize(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetByPhone(ctx context.Context, phone string) (*entity.User, error) {
+	args := m.Called(ctx, phone)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdatePhone(ctx context.Context, id int, phone string, verifiedAt *time.Time) error {
+	args := m.Called(ctx, id, phone, verifiedAt)
+	return args.Error(0)
+}
+
+// MockUserImportRepository is a mock implementation of UserImportRepository
+type MockUserImportRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserImportRepository) Create(ctx context.Context, report *entity.UserImportReport, rows []entity.UserImportRow) error {
+	args := m.Called(ctx, report, rows)
+	return args.Error(0)
+}
+
+func (m *MockUserImportRepository) GetByID(ctx context.Context, id int) (*entity.UserImportReport, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.UserImportReport), args.Error(1)
+}
+
+func (m *MockUserImportRepository) GetRows(ctx context.Context, id int) ([]entity.UserImportRow, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]entity.UserImportRow), args.Error(1)
+}
+
+func (m *MockUserImportRepository) Complete(ctx context.Context, report *entity.UserImportReport) error {
+	args := m.Called(ctx, report)
+	return args.Error(0)
+}
+
+// MockJobQueue is a mock implementation of JobQueue
+type MockJobQueue struct {
+	mock.Mock
+}
+
+func (m *MockJobQueue) Enqueue(ctx context.Context, jobType string, payload interface{}) error {
+	args := m.Called(ctx, jobType, payload)
+	return args.Error(0)
+}
+
+// createAllExcept mimics CreateBatch, leaving users with a taken username unsaved.
+func createAllExcept(taken string) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		for i, user := range args.Get(1).([]*entity.User) {
+			if user.Username != taken {
+				user.ID = 100 + i
+			}
+		}
+	}
+}
+
+func TestUserUsecase_ImportUsers_RowErrors(t *testing.T) {
+	rows := []entity.UserImportRow{
+		{Username: " alice ", Email: "alice@example.com", Password: "secret1"},
+		{Username: "", Email: "nobody@example.com", Password: "secret1"},
+		{Username: "bob", Email: "not-an-email", Password: "secret1"},
+		{Username: "carol", Email: "carol@example.com", Password: "short"},
+		{Username: "alice", Email: "other@example.com", Password: "secret1"},
+		{Username: "dave", Email: "ALICE@example.com", Password: "secret1"},
+		{Username: "existing", Email: "existing@example.com", Password: "secret1"},
+	}
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(users []*entity.User) bool {
+		return len(users) == 2 && users[0].Username == "alice" && users[0].Password != "secret1"
+	})).Run(createAllExcept("existing")).Return(nil)
+
+	uc := NewUserUsecase(userRepo, logger.NewLogger())
+	report, err := uc.ImportUsers(context.Background(), rows)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ImportStatusCompleted, report.Status)
+	assert.Equal(t, 7, report.Total)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 6, report.Failed)
+
+	expected := []entity.UserImportRowResult{
+		{Row: 1, Username: "alice", Status: importRowCreated, UserID: 100},
+		{Row: 2, Username: "", Status: importRowFailed, Error: "username is required"},
+		{Row: 3, Username: "bob", Status: importRowFailed, Error: "email is invalid"},
+		{Row: 4, Username: "carol", Status: importRowFailed, Error: "password must be at least 6 characters"},
+		{Row: 5, Username: "alice", Status: importRowFailed, Error: "duplicate username or email in file"},
+		{Row: 6, Username: "dave", Status: importRowFailed, Error: "duplicate username or email in file"},
+		{Row: 7, Username: "existing", Status: importRowFailed, Error: errors.ErrUserAlreadyExists.Error()},
+	}
+	assert.Equal(t, expected, report.Results)
+	userRepo.AssertExpectations(t)
+}
+
+func TestUserUsecase_ImportUsers_InvalidFile(t *testing.T) {
+	uc := NewUserUsecase(new(MockUserRepository), logger.NewLogger())
+
+	_, err := uc.ImportUsers(context.Background(), nil)
+	assert.True(t, errors.IsInvalidImport(err))
+
+	_, err = uc.ImportUsers(context.Background(), make([]entity.UserImportRow, importMaxRows+1))
+	assert.True(t, errors.IsInvalidImport(err))
+}
+
+func TestUserUsecase_ImportUsers_Background(t *testing.T) {
+	rows := make([]entity.UserImportRow, importAsyncThreshold+1)
+	for i := range rows {
+		rows[i] = entity.UserImportRow{
+			Username: fmt.Sprintf("user%d", i),
+			Email:    fmt.Sprintf("user%d@example.com", i),
+			Password: "secret1",
+		}
+	}
+
+	imports := new(MockUserImportRepository)
+	imports.On("Create", mock.Anything, mock.AnythingOfType("*entity.UserImportReport"), rows).
+		Run(func(args mock.Arguments) { args.Get(1).(*entity.UserImportReport).ID = 7 }).
+		Return(nil)
+	jobs := new(MockJobQueue)
+	jobs.On("Enqueue", mock.Anything, entity.JobTypeUserImport, entity.UserImport{ImportID: 7}).Return(nil)
+
+	uc := NewUserUsecase(new(MockUserRepository), logger.NewLogger())
+	uc.SetImports(imports)
+	uc.SetJobQueue(jobs)
+
+	report, err := uc.ImportUsers(context.Background(), rows)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, report.ID)
+	assert.Equal(t, ImportStatusProcessing, report.Status)
+	assert.Equal(t, len(rows), report.Total)
+	imports.AssertExpectations(t)
+	jobs.AssertExpectations(t)
+}
+
+func TestUserUsecase_RunImport(t *testing.T) {
+	rows := []entity.UserImportRow{
+		{Username: "alice", Email: "alice@example.com", Password: "secret1"},
+		{Username: "bob", Email: "bob", Password: "secret1"},
+	}
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("CreateBatch", mock.Anything, mock.Anything).Run(createAllExcept("")).Return(nil)
+	imports := new(MockUserImportRepository)
+	imports.On("GetByID", mock.Anything, 7).Return(&entity.UserImportReport{ID: 7, Status: ImportStatusProcessing, Total: 2}, nil)
+	imports.On("GetRows", mock.Anything, 7).Return(rows, nil)
+	imports.On("Complete", mock.Anything, mock.MatchedBy(func(report *entity.UserImportReport) bool {
+		return report.ID == 7 && report.Status == ImportStatusCompleted && report.Created == 1 && report.Failed == 1
+	})).Return(nil)
+
+	uc := NewUserUsecase(userRepo, logger.NewLogger())
+	uc.SetImports(imports)
+
+	payload, _ := json.Marshal(entity.UserImport{ImportID: 7})
+	assert.NoError(t, uc.RunImport(context.Background(), payload))
+	imports.AssertExpectations(t)
+
+	// Unknown imports cannot succeed on retry
+	imports.On("GetByID", mock.Anything, 8).Return(nil, errors.ErrImportNotFound)
+	payload, _ = json.Marshal(entity.UserImport{ImportID: 8})
+	assert.ErrorIs(t, uc.RunImport(context.Background(), payload), job.ErrPermanent)
+}
@@ -0,0 +1,78 @@
+package user
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/hash"
+	"boilerplate-go/pkg/password"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"fmt"
+)
+
+// ChangePassword replaces the user's password after checking the current one.
+// Every other session is revoked, so a stolen session does not outlive the
+// change; the session with currentTokenID stays logged in.
+func (uc *UserUsecase) ChangePassword(ctx context.Context, userID int, currentTokenID string, req *entity.ChangePasswordRequest) error {
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := uc.userRepo.GetByID(readCtx, userID)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	if !hash.CheckPassword(req.CurrentPassword, user.Password) {
+		return errors.ErrInvalidCredentials
+	}
+	if uc.passwords != nil {
+		if err := uc.passwords.Check(req.NewPassword, user.Username, user.Email); err != nil {
+			return err
+		}
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return &password.PolicyError{Violations: []password.Violation{{
+			Code:    password.CodeReused,
+			Message: "password must differ from the current password",
+		}}}
+	}
+
+	hashedPassword, err := hash.HashPassword(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.Password = hashedPassword
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.userRepo.Update(writeCtx, user); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if uc.sessionRepo != nil {
+		if err := uc.revokeOtherSessions(writeCtx, userID, currentTokenID); err != nil {
+			return err
+		}
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id": userID,
+		"action":  "password_changed",
+	}).Info("Password changed")
+	return nil
+}
+
+func (uc *UserUsecase) revokeOtherSessions(ctx context.Context, userID int, currentTokenID string) error {
+	sessions, err := uc.sessionRepo.ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	for _, session := range sessions {
+		if session.TokenID == currentTokenID {
+			continue
+		}
+		if err := uc.sessionRepo.Revoke(ctx, userID, session.ID); err != nil && !errors.IsSessionNotFound(err) {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+	}
+	return nil
+}
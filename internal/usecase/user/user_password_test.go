@@ -0,0 +1,138 @@
+package user
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/hash"
+	"boilerplate-go/pkg/password"
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSessionRepository is a mock implementation of SessionRepository
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Create(ctx context.Context, session *entity.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) GetByTokenID(ctx context.Context, tokenID string) (*entity.Session, error) {
+	args := m.Called(ctx, tokenID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) ListActiveByUserID(ctx context.Context, userID int) ([]*entity.Session, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) ListByUserIDSince(ctx context.Context, userID int, since time.Time) ([]*entity.Session, error) {
+	args := m.Called(ctx, userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Revoke(ctx context.Context, userID, sessionID int) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) RevokeAllByUserID(ctx context.Context, userID int) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) Touch(ctx context.Context, tokenID string, seenAt time.Time) error {
+	args := m.Called(ctx, tokenID, seenAt)
+	return args.Error(0)
+}
+
+func newPasswordUsecase(t *testing.T, current string) (*UserUsecase, *MockUserRepository, *MockSessionRepository) {
+	hashed, err := hash.HashPassword(current)
+	require.NoError(t, err)
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", mock.Anything, 1).Return(&entity.User{ID: 1, Username: "janedoe", Email: "jane@example.com", Password: hashed}, nil)
+	sessionRepo := new(MockSessionRepository)
+
+	uc := NewUserUsecase(userRepo, logger.NewLogger())
+	uc.SetErasure(sessionRepo, nil)
+	uc.SetPasswordPolicy(password.DefaultPolicy())
+	return uc, userRepo, sessionRepo
+}
+
+func TestUserUsecase_ChangePassword(t *testing.T) {
+	uc, userRepo, sessionRepo := newPasswordUsecase(t, "old correct horse")
+	userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *entity.User) bool {
+		return hash.CheckPassword("new battery staple", user.Password)
+	})).Return(nil)
+	sessionRepo.On("ListActiveByUserID", mock.Anything, 1).Return([]*entity.Session{
+		{ID: 10, UserID: 1, TokenID: "current"},
+		{ID: 11, UserID: 1, TokenID: "stolen"},
+	}, nil)
+	sessionRepo.On("Revoke", mock.Anything, 1, 11).Return(nil)
+
+	err := uc.ChangePassword(context.Background(), 1, "current", &entity.ChangePasswordRequest{
+		CurrentPassword: "old correct horse",
+		NewPassword:     "new battery staple",
+	})
+
+	require.NoError(t, err)
+	userRepo.AssertExpectations(t)
+	sessionRepo.AssertExpectations(t)
+	sessionRepo.AssertNotCalled(t, "Revoke", mock.Anything, 1, 10)
+}
+
+func TestUserUsecase_ChangePassword_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		new     string
+		code    string
+	}{
+		{name: "wrong current password", current: "not the password", new: "new battery staple"},
+		{name: "weak", current: "old correct horse", new: "janedoe1", code: password.CodeSimilar},
+		{name: "reused", current: "old correct horse", new: "old correct horse", code: password.CodeReused},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, userRepo, sessionRepo := newPasswordUsecase(t, "old correct horse")
+
+			err := uc.ChangePassword(context.Background(), 1, "current", &entity.ChangePasswordRequest{
+				CurrentPassword: tt.current,
+				NewPassword:     tt.new,
+			})
+
+			require.Error(t, err)
+			if tt.code == "" {
+				assert.True(t, errors.IsInvalidCredentials(err))
+			} else {
+				assert.True(t, errors.IsWeakPassword(err))
+				var policyErr *password.PolicyError
+				require.True(t, stderrors.As(err, &policyErr))
+				assert.Equal(t, tt.code, policyErr.Violations[0].Code)
+			}
+			userRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			sessionRepo.AssertNotCalled(t, "ListActiveByUserID", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package user
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/usecase/otp"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"fmt"
+	"time"
+)
+
+// RequestPhoneVerification sets the user's phone number, unverified, and texts
+// it a verification code. The number cannot be used to log in until verified.
+func (uc *UserUsecase) RequestPhoneVerification(ctx context.Context, userID int, req *entity.PhoneRequest) error {
+	if uc.otp == nil {
+		return errors.ErrProviderUnavailable
+	}
+
+	phone, err := otp.NormalizePhone(req.Phone)
+	if err != nil {
+		return err
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := uc.userRepo.GetByID(readCtx, userID)
+	if err != nil {
+		cancel()
+		return err
+	}
+	owner, err := uc.userRepo.GetByPhone(readCtx, phone)
+	cancel()
+	if err != nil && !errors.IsUserNotFound(err) {
+		return fmt.Errorf("failed to check phone: %w", err)
+	}
+	if owner != nil && owner.ID != userID {
+		return errors.ErrPhoneAlreadyInUse
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	err = uc.userRepo.UpdatePhone(writeCtx, userID, phone, nil)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	return uc.otp.Send(ctx, entity.OTPPurposePhoneVerification, phone, user.Locale)
+}
+
+// VerifyPhone marks the user's phone number verified with the code sent by
+// RequestPhoneVerification.
+func (uc *UserUsecase) VerifyPhone(ctx context.Context, userID int, req *entity.PhoneVerifyRequest) (*entity.User, error) {
+	if uc.otp == nil {
+		return nil, errors.ErrProviderUnavailable
+	}
+
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := uc.userRepo.GetByID(readCtx, userID)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	if user.Phone == "" {
+		return nil, errors.ErrPhoneNotSet
+	}
+
+	if err := uc.otp.Verify(ctx, entity.OTPPurposePhoneVerification, user.Phone, req.Code); err != nil {
+		return nil, err
+	}
+
+	verifiedAt := time.Now()
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+	if err := uc.userRepo.UpdatePhone(writeCtx, userID, user.Phone, &verifiedAt); err != nil {
+		return nil, err
+	}
+
+	user.PhoneVerifiedAt = &verifiedAt
+	return user, nil
+}
@@ -0,0 +1,120 @@
+package user
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// localePattern accepts BCP 47 style tags such as en, id or pt-BR
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// UpdateProfile applies the fields set in the request to the user's profile.
+// A new email address is only saved as pending, and a link to confirm it is
+// emailed to it. It fails with ErrConflict when the profile changed since the request's
+// version, or since it was read here.
+func (uc *UserUsecase) UpdateProfile(ctx context.Context, userID int, req *entity.UpdateProfileRequest) (*entity.User, error) {
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	user, err := uc.userRepo.GetByID(readCtx, userID)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	if req.Version != nil && *req.Version != user.Version {
+		return nil, fmt.Errorf("%w: profile is at version %d", errors.ErrConflict, user.Version)
+	}
+
+	if req.FirstName != nil {
+		user.FirstName = strings.TrimSpace(*req.FirstName)
+	}
+	if req.LastName != nil {
+		user.LastName = strings.TrimSpace(*req.LastName)
+	}
+	if req.AvatarURL != nil {
+		if user.AvatarURL, err = normalizeAvatarURL(*req.AvatarURL); err != nil {
+			return nil, err
+		}
+	}
+	if req.Locale != nil {
+		if user.Locale, err = normalizeLocale(*req.Locale); err != nil {
+			return nil, err
+		}
+	}
+	if req.Timezone != nil {
+		if user.Timezone, err = normalizeTimezone(*req.Timezone); err != nil {
+			return nil, err
+		}
+	}
+	if req.LoginAlertsDisabled != nil {
+		user.LoginAlertsDisabled = *req.LoginAlertsDisabled
+	}
+	confirm := false
+	if req.Email != nil {
+		if confirm, err = uc.requestEmailChange(ctx, user, *req.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	err = uc.userRepo.Update(writeCtx, user)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	if confirm {
+		if err := uc.sendEmailChange(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// normalizeAvatarURL accepts an absolute http(s) URL, or an empty string to clear the avatar.
+func normalizeAvatarURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%w: avatar_url must be an http or https URL", errors.ErrInvalidProfile)
+	}
+	return raw, nil
+}
+
+// normalizeLocale accepts a language tag such as pt-BR or pt_BR, or an empty string for the default.
+func normalizeLocale(locale string) (string, error) {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	if locale == "" {
+		return "", nil
+	}
+
+	if !localePattern.MatchString(locale) {
+		return "", fmt.Errorf("%w: locale must be a language tag such as en or pt-BR", errors.ErrInvalidProfile)
+	}
+	return locale, nil
+}
+
+// normalizeTimezone accepts an IANA time zone name such as Asia/Jakarta, or an empty string for UTC.
+func normalizeTimezone(timezone string) (string, error) {
+	timezone = strings.TrimSpace(timezone)
+	if timezone == "" {
+		return "", nil
+	}
+
+	if timezone == "Local" {
+		return "", fmt.Errorf("%w: timezone must be an IANA time zone such as Asia/Jakarta", errors.ErrInvalidProfile)
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return "", fmt.Errorf("%w: timezone must be an IANA time zone such as Asia/Jakarta", errors.ErrInvalidProfile)
+	}
+	return timezone, nil
+}
@@ -0,0 +1,86 @@
+package user
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestUserUsecase_UpdateProfile(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", mock.Anything, 1).Return(&entity.User{ID: 1, Username: "alice", LastName: "Smith", Locale: "en"}, nil)
+	userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *entity.User) bool {
+		return user.FirstName == "Alice" && user.LastName == "Smith" && user.Locale == "pt-BR" && user.Timezone == "Asia/Jakarta"
+	})).Return(nil)
+
+	uc := NewUserUsecase(userRepo, logger.NewLogger())
+	user, err := uc.UpdateProfile(context.Background(), 1, &entity.UpdateProfileRequest{
+		FirstName: stringPtr(" Alice "),
+		Locale:    stringPtr("pt_BR"),
+		Timezone:  stringPtr("Asia/Jakarta"),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", user.DisplayName())
+	userRepo.AssertExpectations(t)
+}
+
+func TestUserUsecase_UpdateProfile_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *entity.UpdateProfileRequest
+	}{
+		{name: "avatar scheme", req: &entity.UpdateProfileRequest{AvatarURL: stringPtr("javascript:alert(1)")}},
+		{name: "relative avatar", req: &entity.UpdateProfileRequest{AvatarURL: stringPtr("/avatar.png")}},
+		{name: "locale", req: &entity.UpdateProfileRequest{Locale: stringPtr("english!")}},
+		{name: "timezone", req: &entity.UpdateProfileRequest{Timezone: stringPtr("Mars/Olympus")}},
+		{name: "server local timezone", req: &entity.UpdateProfileRequest{Timezone: stringPtr("Local")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			userRepo.On("GetByID", mock.Anything, 1).Return(&entity.User{ID: 1}, nil)
+
+			uc := NewUserUsecase(userRepo, logger.NewLogger())
+			_, err := uc.UpdateProfile(context.Background(), 1, tt.req)
+
+			assert.True(t, errors.IsInvalidProfile(err), err)
+			userRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUserUsecase_UpdateProfile_Conflict(t *testing.T) {
+	t.Run("stale version in the request", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetByID", mock.Anything, 1).Return(&entity.User{ID: 1, Version: 3}, nil)
+
+		uc := NewUserUsecase(userRepo, logger.NewLogger())
+		version := 2
+		_, err := uc.UpdateProfile(context.Background(), 1, &entity.UpdateProfileRequest{FirstName: stringPtr("Alice"), Version: &version})
+
+		assert.True(t, errors.IsConflict(err), err)
+		userRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("updated since it was read", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetByID", mock.Anything, 1).Return(&entity.User{ID: 1, Version: 3}, nil)
+		userRepo.On("Update", mock.Anything, mock.Anything).Return(errors.ErrConflict)
+
+		uc := NewUserUsecase(userRepo, logger.NewLogger())
+		_, err := uc.UpdateProfile(context.Background(), 1, &entity.UpdateProfileRequest{FirstName: stringPtr("Alice")})
+
+		assert.True(t, errors.IsConflict(err), err)
+	})
+}
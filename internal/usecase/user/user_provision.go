@@ -0,0 +1,75 @@
+package user
+
+import (
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/emailaddr"
+	"boilerplate-go/pkg/hash"
+	"boilerplate-go/pkg/timeout"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ExternalID returns the external ID of the user with the email address: the
+// name-based UUID (version 5) of its mailto: URL, so an identity provider
+// sync can compute it too.
+func ExternalID(email string) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("mailto:"+emailaddr.Normalize(email, false))).String()
+}
+
+// Provision creates the user with the email address, or updates its username
+// and names, so syncing the same user again changes nothing. New users get
+// the password when one is given and meets the policy; without one they
+// cannot sign in with a password. Existing users keep their password and
+// role. It reports whether the user was created.
+func (uc *UserUsecase) Provision(ctx context.Context, req *entity.ProvisionUserRequest) (*entity.User, bool, error) {
+	email := emailaddr.Normalize(req.Email, false)
+	user := &entity.User{
+		Username:   strings.TrimSpace(req.Username),
+		Email:      email,
+		ExternalID: ExternalID(email),
+		FirstName:  req.FirstName,
+		LastName:   req.LastName,
+	}
+
+	if req.Password != "" {
+		hashedPassword, err := uc.newPassword(req.Password, user.Username, email)
+		if err != nil {
+			return nil, false, err
+		}
+		user.Password = hashedPassword
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	defer cancel()
+
+	created, err := uc.userRepo.Upsert(writeCtx, user)
+	if err != nil {
+		return nil, false, err
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id":     user.ID,
+		"external_id": user.ExternalID,
+		"created":     created,
+		"action":      "provision_user",
+	}).Info("User provisioned")
+
+	return user, created, nil
+}
+
+// newPassword checks the password of the user against the policy and hashes it
+func (uc *UserUsecase) newPassword(password, username, email string) (string, error) {
+	if uc.passwords != nil {
+		if err := uc.passwords.Check(password, username, email); err != nil {
+			return "", err
+		}
+	}
+	hashedPassword, err := hash.HashPassword(password)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return hashedPassword, nil
+}
@@ -0,0 +1,59 @@
+package user
+
+import (
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/hash"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalID(t *testing.T) {
+	id := ExternalID("Alice@Example.com ")
+	assert.Equal(t, id, ExternalID("alice@example.com"), "the email is normalized")
+	assert.NotEqual(t, id, ExternalID("bob@example.com"))
+	assert.Len(t, id, 36)
+}
+
+func TestUserUsecase_Provision(t *testing.T) {
+	t.Run("created with password", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("Upsert", mock.Anything, mock.AnythingOfType("*entity.User")).
+			Run(func(args mock.Arguments) { args.Get(1).(*entity.User).ID = 7 }).
+			Return(true, nil)
+		uc := NewUserUsecase(userRepo, logger.NewLogger())
+
+		user, created, err := uc.Provision(context.Background(), &entity.ProvisionUserRequest{
+			Email:    "Alice@Example.com",
+			Username: "alice",
+			Password: "correct horse battery",
+		})
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, 7, user.ID)
+		assert.Equal(t, "alice@example.com", user.Email)
+		assert.Equal(t, ExternalID("alice@example.com"), user.ExternalID)
+		assert.True(t, hash.CheckPassword("correct horse battery", user.Password))
+	})
+
+	t.Run("updated without password", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("Upsert", mock.Anything, mock.MatchedBy(func(u *entity.User) bool {
+			return u.Password == "" && u.FirstName == "Alice"
+		})).Return(false, nil)
+		uc := NewUserUsecase(userRepo, logger.NewLogger())
+
+		_, created, err := uc.Provision(context.Background(), &entity.ProvisionUserRequest{
+			Email:     "alice@example.com",
+			Username:  "alice",
+			FirstName: "Alice",
+		})
+		require.NoError(t, err)
+		assert.False(t, created)
+		userRepo.AssertExpectations(t)
+	})
+}
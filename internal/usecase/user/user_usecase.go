@@ -1,25 +1,210 @@
 package user
 
 import (
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
 	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/domain/provider"
 	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/internal/usecase/job"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/pkg/password"
+	"boilerplate-go/pkg/timeout"
 	"context"
+	"encoding/json"
+	"fmt"
+	"time"
 )
 
 type UserUsecase struct {
-	userRepo repository.UserRepository
+	userRepo      repository.UserRepository
+	sessionRepo   repository.SessionRepository
+	imports       repository.UserImportRepository
+	changes       repository.UserChangeRepository
+	orders        OrderStore
+	jobs          JobQueue
+	otp           OTPService
+	avatars       provider.FileStorageProvider
+	avatarCfg     config.AvatarConfig
+	exports       provider.FileStorageProvider
+	exportSources DataExportSources
+	exportTTL     time.Duration
+	passwords     *password.Policy
+	emailChange   config.EmailAddressConfig
+	logger        *logger.Logger
+	timeouts      *timeout.Policy
 }
 
-func NewUserUsecase(userRepo repository.UserRepository) *UserUsecase {
+// OrderStore is the access to the user's orders needed for export and erasure.
+type OrderStore interface {
+	ExportOrders(ctx context.Context, filter entity.OrderFilter) ([]*entity.Order, error)
+	CancelOpenOrders(ctx context.Context, userID int) error
+}
+
+// JobQueue runs background jobs with retries.
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobType string, payload interface{}) error
+}
+
+// OTPService issues and verifies one-time codes sent by SMS.
+type OTPService interface {
+	Send(ctx context.Context, purpose, phone, locale string) error
+	Verify(ctx context.Context, purpose, phone, code string) error
+}
+
+func NewUserUsecase(userRepo repository.UserRepository, logger *logger.Logger) *UserUsecase {
 	return &UserUsecase{
 		userRepo: userRepo,
+		logger:   logger,
 	}
 }
 
+// SetTimeouts bounds repository calls with per-operation deadlines.
+func (uc *UserUsecase) SetTimeouts(timeouts *timeout.Policy) {
+	uc.timeouts = timeouts
+}
+
+// SetErasure provides what data export and erasure act on besides the user row:
+// sessions are revoked and open orders cancelled when the user is erased.
+func (uc *UserUsecase) SetErasure(sessionRepo repository.SessionRepository, orders OrderStore) {
+	uc.sessionRepo = sessionRepo
+	uc.orders = orders
+}
+
+// SetJobQueue runs data exports, erasure requests and large imports as retried
+// background jobs.
+func (uc *UserUsecase) SetJobQueue(jobs JobQueue) {
+	uc.jobs = jobs
+}
+
+// SetOTP enables phone number verification with one-time codes sent by SMS.
+func (uc *UserUsecase) SetOTP(otp OTPService) {
+	uc.otp = otp
+}
+
+// SetAvatarStorage enables avatar uploads, storing the resized images in storage.
+func (uc *UserUsecase) SetAvatarStorage(storage provider.FileStorageProvider, cfg config.AvatarConfig) {
+	uc.avatars = storage
+	uc.avatarCfg = cfg
+}
+
+// SetEmailChange lets users change their email address, once confirmed
+// from the new one. Confirmation emails go through the job queue. With
+// cfg.StripPlus new addresses lose their plus subaddress.
+func (uc *UserUsecase) SetEmailChange(cfg config.EmailAddressConfig) {
+	uc.emailChange = cfg
+}
+
+// SetPasswordPolicy rejects password changes to passwords that do not meet
+// the policy. Without it any new password is accepted.
+func (uc *UserUsecase) SetPasswordPolicy(policy *password.Policy) {
+	uc.passwords = policy
+}
+
+// SetImports stores large bulk imports so they can run as background jobs.
+// Without it, or without a job queue, every import runs inline.
+func (uc *UserUsecase) SetImports(imports repository.UserImportRepository) {
+	uc.imports = imports
+}
+
 func (uc *UserUsecase) GetProfile(ctx context.Context, userID int) (*entity.User, error) {
+	ctx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+
 	return uc.userRepo.GetByID(ctx, userID)
 }
 
-func (uc *UserUsecase) UpdateProfile(ctx context.Context, user *entity.User) error {
-	return uc.userRepo.Update(ctx, user)
+// RequestDeletion schedules the right-to-erasure process for the user.
+// Personal data is anonymized rather than deleted so that financial records
+// referencing the user remain consistent.
+func (uc *UserUsecase) RequestDeletion(ctx context.Context, userID int) (*entity.AccountDeletionResponse, error) {
+	readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+	defer cancel()
+	if _, err := uc.userRepo.GetByID(readCtx, userID); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	status := "pending"
+	if uc.jobs != nil {
+		if err := uc.jobs.Enqueue(ctx, entity.JobTypeUserErasure, entity.UserErasure{UserID: userID}); err != nil {
+			return nil, fmt.Errorf("failed to schedule erasure: %w", err)
+		}
+	} else {
+		if err := uc.eraseUser(ctx, userID); err != nil {
+			return nil, err
+		}
+		status = "completed"
+	}
+
+	return &entity.AccountDeletionResponse{
+		UserID:      userID,
+		Status:      status,
+		RequestedAt: time.Now().UTC(),
+	}, nil
+}
+
+// EraseUser runs an erasure request as a background job. Every step is
+// idempotent, so a retried job picks up where a failed attempt stopped.
+func (uc *UserUsecase) EraseUser(ctx context.Context, payload json.RawMessage) error {
+	var erasure entity.UserErasure
+	if err := json.Unmarshal(payload, &erasure); err != nil {
+		return fmt.Errorf("%w: invalid user erasure payload: %v", job.ErrPermanent, err)
+	}
+
+	err := uc.eraseUser(ctx, erasure.UserID)
+	if errors.IsUserNotFound(err) {
+		return fmt.Errorf("%w: %v", job.ErrPermanent, err)
+	}
+	return err
+}
+
+// eraseUser cancels the user's open orders while their contact details still
+// exist, logs them out everywhere and then anonymizes the user row.
+func (uc *UserUsecase) eraseUser(ctx context.Context, userID int) error {
+	if uc.orders != nil {
+		if err := uc.orders.CancelOpenOrders(ctx, userID); err != nil {
+			return fmt.Errorf("failed to cancel open orders: %w", err)
+		}
+	}
+
+	if uc.sessionRepo != nil {
+		writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+		err := uc.sessionRepo.RevokeAllByUserID(writeCtx, userID)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+
+	// Avatar files are public; delete them before the profile forgets their URL
+	if uc.avatars != nil {
+		readCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBRead)
+		user, err := uc.userRepo.GetByID(readCtx, userID)
+		cancel()
+		if err != nil && !errors.IsUserNotFound(err) {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if user != nil {
+			if err := uc.deleteAvatar(ctx, userID, user.AvatarURL); err != nil {
+				return fmt.Errorf("failed to delete avatar: %w", err)
+			}
+		}
+	}
+
+	writeCtx, cancel := uc.timeouts.WithTimeout(ctx, timeout.OperationDBWrite)
+	err := uc.userRepo.Anonymize(writeCtx, userID)
+	cancel()
+	if err != nil {
+		uc.logger.ErrorLogger(ctx, err, "Failed to anonymize user", map[string]interface{}{
+			"user_id": userID,
+		})
+		return err
+	}
+
+	uc.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"user_id": userID,
+		"action":  "user_anonymized",
+	}).Info("User data erased")
+
+	return nil
 }
@@ -0,0 +1,12 @@
+-- Create order_items table
+CREATE TABLE IF NOT EXISTS order_items (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    product_id VARCHAR(64) NOT NULL,
+    name VARCHAR(127) NOT NULL DEFAULT '',
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price NUMERIC(12, 2) NOT NULL CHECK (unit_price >= 0)
+);
+
+-- Create index on order_id for loading an order's items
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items(order_id);
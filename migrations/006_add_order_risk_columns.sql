@@ -0,0 +1,6 @@
+-- Record the fraud screening outcome on orders
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS risk_score NUMERIC(5, 2);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS flagged_for_review BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Create partial index for the manual review queue
+CREATE INDEX IF NOT EXISTS idx_orders_flagged_for_review ON orders(created_at) WHERE flagged_for_review;
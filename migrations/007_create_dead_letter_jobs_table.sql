@@ -0,0 +1,13 @@
+-- Create dead_letter_jobs table for background jobs that exhausted their retries
+CREATE TABLE IF NOT EXISTS dead_letter_jobs (
+    id SERIAL PRIMARY KEY,
+    job_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    error TEXT NOT NULL,
+    attempts INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for inspection
+CREATE INDEX IF NOT EXISTS idx_dead_letter_jobs_job_type ON dead_letter_jobs(job_type);
+CREATE INDEX IF NOT EXISTS idx_dead_letter_jobs_created_at ON dead_letter_jobs(created_at);
@@ -0,0 +1,2 @@
+-- Create index for looking up an order by its payment
+CREATE INDEX IF NOT EXISTS idx_orders_payment_id ON orders(payment_id) WHERE payment_id <> '';
@@ -0,0 +1,2 @@
+-- Add a role to users; admins are granted the admin token scope
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
@@ -0,0 +1,14 @@
+-- Create user_imports table tracking bulk user imports run in the background.
+-- The uploaded rows are kept only until the import has run.
+CREATE TABLE IF NOT EXISTS user_imports (
+    id SERIAL PRIMARY KEY,
+    status VARCHAR(20) NOT NULL,
+    total INTEGER NOT NULL,
+    created INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    rows JSONB,
+    results JSONB,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
@@ -0,0 +1,20 @@
+-- Create notifications table tracking bulk email campaigns sent in chunks.
+-- next_chunk records progress so a failed send resumes after the last sent chunk.
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    subject VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    body_html TEXT NOT NULL DEFAULT '',
+    recipients JSONB NOT NULL,
+    total INTEGER NOT NULL,
+    chunk_size INTEGER NOT NULL,
+    total_chunks INTEGER NOT NULL,
+    next_chunk INTEGER NOT NULL DEFAULT 0,
+    sent INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    chunks JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
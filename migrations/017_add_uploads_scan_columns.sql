@@ -0,0 +1,5 @@
+-- Add malware scan results to uploads. Infected files are moved under the
+-- quarantine/ prefix and file_id is updated to point there.
+ALTER TABLE uploads ADD COLUMN IF NOT EXISTS scan_status VARCHAR(20) NOT NULL DEFAULT 'skipped';
+ALTER TABLE uploads ADD COLUMN IF NOT EXISTS scan_threat VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE uploads ADD COLUMN IF NOT EXISTS scanned_at TIMESTAMP;
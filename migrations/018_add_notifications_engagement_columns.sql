@@ -0,0 +1,10 @@
+-- Add email engagement to notifications, reconciled from the email provider by
+-- the email_status_sync job. Each is the first time any chunk reached the state;
+-- per-chunk timestamps are kept in chunks.
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMP;
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS opened_at TIMESTAMP;
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS clicked_at TIMESTAMP;
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS status_checked_at TIMESTAMP;
+
+-- Create index for finding recently completed campaigns to reconcile
+CREATE INDEX IF NOT EXISTS idx_notifications_completed_at ON notifications(completed_at);
@@ -0,0 +1,5 @@
+-- Let users opt out of emails about sign-ins from new devices or locations,
+-- and index the session history those sign-ins are compared with.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS login_alerts_disabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id_created_at ON sessions(user_id, created_at);
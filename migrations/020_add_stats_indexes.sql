@@ -0,0 +1,4 @@
+-- Create indexes for the daily aggregates of the admin dashboard
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at);
+CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders(created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_created_at ON notifications(created_at);
@@ -0,0 +1,19 @@
+-- Create daily rollups of orders and sign-ups for reporting, so reports do not
+-- aggregate the transactional tables
+CREATE TABLE IF NOT EXISTS daily_revenue (
+    day DATE NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    orders INTEGER NOT NULL DEFAULT 0,
+    completed INTEGER NOT NULL DEFAULT 0,
+    refunded INTEGER NOT NULL DEFAULT 0,
+    revenue NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    refunded_amount NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (day, currency)
+);
+
+CREATE TABLE IF NOT EXISTS daily_signups (
+    day DATE PRIMARY KEY,
+    signups INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,17 @@
+-- Create exports table tracking exports generated in the background
+CREATE TABLE IF NOT EXISTS exports (
+    id SERIAL PRIMARY KEY,
+    dataset VARCHAR(20) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    filter JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL,
+    rows INTEGER NOT NULL DEFAULT 0,
+    file_id VARCHAR(512) NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    requested_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+-- Create indexes for exporting sessions in ID order per user
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id_id ON sessions(user_id, id);
@@ -0,0 +1,11 @@
+-- Create config_changes table, the audit trail of provider profiles applied
+-- at runtime. Secrets in changes are redacted.
+CREATE TABLE IF NOT EXISTS config_changes (
+    id SERIAL PRIMARY KEY,
+    profile VARCHAR(100) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '[]',
+    changed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_config_changes_created_at ON config_changes(created_at);
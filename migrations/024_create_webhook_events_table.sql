@@ -0,0 +1,10 @@
+-- Create webhook_events table, the provider event IDs already processed, so
+-- events delivered again are not applied twice. Rows are kept until
+-- expires_at and then purged.
+CREATE TABLE IF NOT EXISTS webhook_events (
+    event_id VARCHAR(255) PRIMARY KEY,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_events_expires_at ON webhook_events(expires_at);
@@ -0,0 +1,14 @@
+-- Create buffered_payment_events table, the payment events received before
+-- their order could take them, such as a refund of a payment still being
+-- charged. They are applied in arrival order once the order is paid, and
+-- purged after expires_at.
+CREATE TABLE IF NOT EXISTS buffered_payment_events (
+    id SERIAL PRIMARY KEY,
+    payment_id VARCHAR(255) NOT NULL,
+    event JSONB NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_buffered_payment_events_payment_id ON buffered_payment_events(payment_id);
+CREATE INDEX IF NOT EXISTS idx_buffered_payment_events_expires_at ON buffered_payment_events(expires_at);
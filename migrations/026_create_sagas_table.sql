@@ -0,0 +1,17 @@
+-- Create sagas table, the progress of multi-step operations such as placing
+-- an order: the steps completed and the state needed to undo them. Sagas
+-- left running by a crash are compensated by recovery.
+CREATE TABLE IF NOT EXISTS sagas (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    reference VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    steps JSONB NOT NULL DEFAULT '[]',
+    state JSONB NOT NULL DEFAULT '{}',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sagas_status_updated_at ON sagas(status, updated_at);
+CREATE INDEX IF NOT EXISTS idx_sagas_reference ON sagas(reference);
@@ -0,0 +1,13 @@
+-- Create provider_usage table, the calls made to each paid provider per UTC
+-- day and their cost-equivalent, shared by the instances to enforce the
+-- daily budgets.
+CREATE TABLE IF NOT EXISTS provider_usage (
+    provider VARCHAR(50) NOT NULL,
+    day DATE NOT NULL,
+    calls BIGINT NOT NULL DEFAULT 0,
+    cost DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_provider_usage_day ON provider_usage(day);
@@ -0,0 +1,4 @@
+-- Add a deterministic external ID to users, derived from the email address of
+-- users provisioned by an identity provider sync
+ALTER TABLE users ADD COLUMN IF NOT EXISTS external_id VARCHAR(64);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_external_id ON users(external_id);
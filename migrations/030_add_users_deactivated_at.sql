@@ -0,0 +1,3 @@
+-- Deactivated users cannot sign in. Identity providers deactivate users
+-- through SCIM provisioning instead of deleting them.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP;
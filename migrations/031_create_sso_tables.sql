@@ -0,0 +1,36 @@
+-- Create per-organization single sign-on connections, sign-ins in progress
+-- and the identities linking users to identity provider subjects
+CREATE TABLE IF NOT EXISTS sso_connections (
+    id SERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL UNIQUE REFERENCES organizations(id) ON DELETE CASCADE,
+    protocol VARCHAR(20) NOT NULL,
+    issuer VARCHAR(255) NOT NULL,
+    client_id VARCHAR(255) NOT NULL,
+    client_secret TEXT NOT NULL DEFAULT '',
+    username_claim VARCHAR(100) NOT NULL DEFAULT '',
+    jit_provisioning BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Sign-ins are looked up by the SHA-256 hash of their state
+CREATE TABLE IF NOT EXISTS sso_logins (
+    state_hash VARCHAR(64) PRIMARY KEY,
+    connection_id INTEGER NOT NULL REFERENCES sso_connections(id) ON DELETE CASCADE,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sso_logins_expires_at ON sso_logins(expires_at);
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    connection_id INTEGER NOT NULL REFERENCES sso_connections(id) ON DELETE CASCADE,
+    subject VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (connection_id, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
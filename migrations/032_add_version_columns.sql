@@ -0,0 +1,5 @@
+-- Row versions for optimistic locking: every update of a user or order bumps
+-- its version and only applies when the row is still at the version it was
+-- read at, so concurrent updates cannot overwrite each other.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,13 @@
+-- Create payment_status_cache table, the payment statuses last read from the
+-- payment provider, served to clients polling a payment until expires_at.
+-- Payment webhook events delete the status of their payment. Unlogged, as a
+-- cache need not survive a crash.
+CREATE UNLOGGED TABLE IF NOT EXISTS payment_status_cache (
+    payment_id VARCHAR(255) PRIMARY KEY,
+    status VARCHAR(50) NOT NULL,
+    amount NUMERIC(12, 2) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_status_cache_expires_at ON payment_status_cache(expires_at);
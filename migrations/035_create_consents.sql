@@ -0,0 +1,13 @@
+-- Create consents table, the versions of the legal documents each user
+-- accepted, when and from where. Rows are kept as proof of consent; erased
+-- users keep theirs without IP address and user agent.
+CREATE TABLE IF NOT EXISTS consents (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    document VARCHAR(50) NOT NULL,
+    version VARCHAR(50) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    accepted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, document, version)
+);
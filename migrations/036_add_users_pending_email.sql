@@ -0,0 +1,3 @@
+-- Email address a user asked to change to, kept apart from the email in use
+-- until the user confirms it with the link emailed to the new address.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pending_email VARCHAR(100);
@@ -0,0 +1,6 @@
+-- Usernames are unique regardless of case, and looked up by LOWER(username).
+-- Creating the index fails while two users share a username in different
+-- cases; rename one of them first:
+--   SELECT LOWER(username) FROM users GROUP BY 1 HAVING COUNT(*) > 1;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower ON users (LOWER(username));
+DROP INDEX IF EXISTS idx_users_username;
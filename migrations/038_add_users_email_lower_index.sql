@@ -0,0 +1,10 @@
+-- Email addresses are unique regardless of case, and looked up by
+-- LOWER(email). Creating the index fails while two users share an address in
+-- different cases; merge or change one of them first:
+--   SELECT LOWER(email) FROM users GROUP BY 1 HAVING COUNT(*) > 1;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (LOWER(email));
+DROP INDEX IF EXISTS idx_users_email;
+
+-- New addresses are stored lowercased; lowercase the existing ones
+UPDATE users SET email = LOWER(email) WHERE email <> LOWER(email);
+UPDATE users SET pending_email = LOWER(pending_email) WHERE pending_email <> LOWER(pending_email);
@@ -0,0 +1,88 @@
+// Package buildinfo describes the running build. The version, commit and build
+// time are set at link time:
+//
+//	go build -ldflags "-X boilerplate-go/pkg/buildinfo.Version=v1.2.3 \
+//	  -X boilerplate-go/pkg/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X boilerplate-go/pkg/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Without them, the commit and build time come from the version control
+// information the go command embeds, when there is any.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Service names the application in releases
+const Service = "boilerplate-api"
+
+// Set with -ldflags "-X"
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info describes a build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+	// Release identifies the build to error trackers, such as
+	// "boilerplate-api@v1.2.3+abc1234"
+	Release string `json:"release"`
+}
+
+// Get returns the running build
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range build.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = s.Value
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = "unknown"
+	}
+
+	info.Release = release(info.Version, info.Commit)
+	return info
+}
+
+// Fields returns the build as log fields
+func (i Info) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"version":    i.Version,
+		"commit":     i.Commit,
+		"build_time": i.BuildTime,
+		"go_version": i.GoVersion,
+	}
+}
+
+// release is Service@version, with the short commit as build metadata
+func release(version, commit string) string {
+	r := Service + "@" + version
+	if commit != "unknown" {
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		r += "+" + commit
+	}
+	return r
+}
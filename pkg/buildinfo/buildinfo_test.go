@@ -0,0 +1,35 @@
+package buildinfo
+
+import "testing"
+
+func TestGet_LinkedValues(t *testing.T) {
+	defer func(version, commit, buildTime string) {
+		Version, Commit, BuildTime = version, commit, buildTime
+	}(Version, Commit, BuildTime)
+	Version, Commit, BuildTime = "v1.2.3", "0123456789abcdef", "2024-01-02T03:04:05Z"
+
+	info := Get()
+	if info.Version != "v1.2.3" || info.Commit != "0123456789abcdef" || info.BuildTime != "2024-01-02T03:04:05Z" {
+		t.Errorf("Get() = %+v, want the linked values", info)
+	}
+	if info.Release != "boilerplate-api@v1.2.3+0123456" {
+		t.Errorf("Release = %q, want %q", info.Release, "boilerplate-api@v1.2.3+0123456")
+	}
+	if info.GoVersion == "" {
+		t.Error("GoVersion is empty")
+	}
+}
+
+func TestRelease(t *testing.T) {
+	tests := []struct {
+		version, commit, want string
+	}{
+		{"v1.0.0", "abc", "boilerplate-api@v1.0.0+abc"},
+		{"dev", "unknown", "boilerplate-api@dev"},
+	}
+	for _, tt := range tests {
+		if got := release(tt.version, tt.commit); got != tt.want {
+			t.Errorf("release(%q, %q) = %q, want %q", tt.version, tt.commit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Package cache is an in-memory cache bounded by the total cost of its
+// entries, such as their size in bytes. Entries expire after their TTL, and
+// the least recently used are evicted to make room for new ones.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache holds values by key. It is safe for concurrent use.
+type Cache[V any] struct {
+	mu      sync.Mutex
+	maxCost int64
+	cost    int64
+	items   map[string]*list.Element
+	// order holds the entries from most to least recently used
+	order     *list.List
+	evictions int64
+}
+
+type entry[V any] struct {
+	key       string
+	value     V
+	cost      int64
+	expiresAt time.Time
+}
+
+// New creates a cache holding entries of at most maxCost in total
+func New[V any](maxCost int64) *Cache[V] {
+	return &Cache[V]{
+		maxCost: maxCost,
+		items:   make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Get returns the value of the key, unless it is missing or has expired
+func (c *Cache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	element, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	e := element.Value.(*entry[V])
+	if !time.Now().Before(e.expiresAt) {
+		c.remove(element)
+		return zero, false
+	}
+	c.order.MoveToFront(element)
+	return e.value, true
+}
+
+// Set stores the value of the key for ttl, evicting the least recently used
+// entries until it fits. A value costing more than the whole cache is not
+// stored, and Set reports false.
+func (c *Cache[V]) Set(key string, value V, cost int64, ttl time.Duration) bool {
+	if cost > c.maxCost || ttl <= 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.remove(element)
+	}
+	for c.cost+cost > c.maxCost {
+		c.remove(c.order.Back())
+		c.evictions++
+	}
+	c.items[key] = c.order.PushFront(&entry[V]{key: key, value: value, cost: cost, expiresAt: time.Now().Add(ttl)})
+	c.cost += cost
+	return true
+}
+
+// Delete removes the key
+func (c *Cache[V]) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.remove(element)
+	}
+}
+
+// DeleteFunc removes the entries match reports true for and returns how many
+func (c *Cache[V]) DeleteFunc(match func(key string, value V) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	deleted := 0
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		if e := element.Value.(*entry[V]); match(e.key, e.value) {
+			c.remove(element)
+			deleted++
+		}
+		element = next
+	}
+	return deleted
+}
+
+// Stats returns the number of entries, their total cost and how many entries
+// were evicted to make room since the cache was created
+func (c *Cache[V]) Stats() (entries int, cost, evictions int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items), c.cost, c.evictions
+}
+
+func (c *Cache[V]) remove(element *list.Element) {
+	e := c.order.Remove(element).(*entry[V])
+	delete(c.items, e.key)
+	c.cost -= e.cost
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	c := New[string](10)
+
+	assert.True(t, c.Set("a", "alpha", 4, time.Minute))
+	assert.True(t, c.Set("b", "beta", 4, time.Minute))
+	value, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "alpha", value)
+
+	// b is the least recently used, so it makes room for c
+	assert.True(t, c.Set("c", "gamma", 4, time.Minute))
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	entries, cost, evictions := c.Stats()
+	assert.Equal(t, 2, entries)
+	assert.EqualValues(t, 8, cost)
+	assert.EqualValues(t, 1, evictions)
+
+	assert.False(t, c.Set("d", "too large", 11, time.Minute), "larger than the whole cache")
+
+	c.Delete("a")
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+}
+
+func TestCache_Expiry(t *testing.T) {
+	c := New[int](10)
+
+	c.Set("short", 1, 1, time.Millisecond)
+	c.Set("long", 2, 1, time.Minute)
+	time.Sleep(5 * time.Millisecond)
+
+	_, ok := c.Get("short")
+	assert.False(t, ok)
+	value, ok := c.Get("long")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+	entries, cost, _ := c.Stats()
+	assert.Equal(t, 1, entries)
+	assert.EqualValues(t, 1, cost)
+}
+
+func TestCache_DeleteFunc(t *testing.T) {
+	c := New[int](10)
+	c.Set("/docs/a", 1, 1, time.Minute)
+	c.Set("/docs/b", 2, 1, time.Minute)
+	c.Set("/jwks", 3, 1, time.Minute)
+
+	deleted := c.DeleteFunc(func(key string, _ int) bool { return strings.HasPrefix(key, "/docs/") })
+
+	assert.Equal(t, 2, deleted)
+	_, ok := c.Get("/jwks")
+	assert.True(t, ok)
+}
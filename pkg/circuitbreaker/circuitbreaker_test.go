@@ -0,0 +1,43 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	errUpstream := errors.New("upstream unavailable")
+	fail := func() error { return errUpstream }
+	succeed := func() error { return nil }
+
+	now := time.Unix(1700000000, 0)
+	b := New(2, 30*time.Second)
+	b.now = func() time.Time { return now }
+
+	// Failures below the threshold keep the circuit closed
+	assert.ErrorIs(t, b.Execute(fail), errUpstream)
+	assert.Equal(t, StateClosed, b.State())
+
+	// Reaching the threshold opens it and calls fail fast
+	assert.ErrorIs(t, b.Execute(fail), errUpstream)
+	assert.Equal(t, StateOpen, b.State())
+	called := false
+	assert.ErrorIs(t, b.Execute(func() error { called = true; return nil }), ErrOpen)
+	assert.False(t, called)
+
+	// After the timeout a failed trial re-opens the circuit
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.ErrorIs(t, b.Execute(fail), errUpstream)
+	assert.Equal(t, StateOpen, b.State())
+
+	// A successful trial closes it
+	now = now.Add(30 * time.Second)
+	assert.NoError(t, b.Execute(succeed))
+	assert.Equal(t, StateClosed, b.State())
+	assert.ErrorIs(t, b.Execute(fail), errUpstream)
+	assert.Equal(t, StateClosed, b.State())
+}
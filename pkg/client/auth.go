@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"boilerplate-go/internal/domain/entity"
+)
+
+// Register creates a user account
+func (c *Client) Register(ctx context.Context, req entity.RegisterRequest) (*entity.User, error) {
+	var user entity.User
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/auth/register", body: req}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Login logs in with a username and password. The client then sends the
+// obtained token with its requests.
+func (c *Client) Login(ctx context.Context, req entity.LoginRequest) (*entity.LoginResponse, error) {
+	var login entity.LoginResponse
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/auth/login", body: req}, &login); err != nil {
+		return nil, err
+	}
+	c.SetToken(login.Token)
+	return &login, nil
+}
+
+// RequestOTP sends a one-time login code by SMS
+func (c *Client) RequestOTP(ctx context.Context, phone string) error {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/auth/otp/request", body: entity.OTPRequest{Phone: phone}}, nil)
+	return err
+}
+
+// VerifyOTP logs in with a one-time code. The client then sends the obtained
+// token with its requests.
+func (c *Client) VerifyOTP(ctx context.Context, phone, code string) (*entity.LoginResponse, error) {
+	var login entity.LoginResponse
+	req := entity.OTPVerifyRequest{Phone: phone, Code: code}
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/auth/otp/verify", body: req}, &login); err != nil {
+		return nil, err
+	}
+	c.SetToken(login.Token)
+	return &login, nil
+}
+
+// Logout ends the session of the client's token, which is then forgotten
+func (c *Client) Logout(ctx context.Context) error {
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/auth/logout"}, nil); err != nil {
+		return err
+	}
+	c.SetToken("")
+	return nil
+}
@@ -0,0 +1,274 @@
+// Package client is a Go client for the API, for services integrating with it
+// without hand-rolling HTTP calls. Requests and responses use the same entity
+// types as the server; error responses are returned as *APIError.
+//
+// The client is maintained by hand. Its endpoints are checked against the
+// OpenAPI spec in docs/swagger.json, so a route changed without updating the
+// client fails the tests; run `make sdk` after changing the API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultTimeout bounds requests of clients created without an HTTP client
+const defaultTimeout = 30 * time.Second
+
+// Config configures a client. HTTPClient defaults to a client with a 30
+// second timeout, and Token, when set, authenticates every request.
+type Config struct {
+	BaseURL    string
+	Token      string
+	UserAgent  string
+	HTTPClient *http.Client
+}
+
+// Client calls the API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	userAgent  string
+	httpClient *http.Client
+
+	mu    sync.RWMutex
+	token string
+}
+
+// New creates a client for the API at config.BaseURL, such as
+// https://api.example.com.
+func New(config Config) (*Client, error) {
+	base, err := url.Parse(config.BaseURL)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q", config.BaseURL)
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(config.BaseURL, "/"),
+		userAgent:  config.UserAgent,
+		httpClient: httpClient,
+		token:      config.Token,
+	}, nil
+}
+
+// SetToken sets the bearer token sent with requests. Login and VerifyOTP set
+// it to the token they obtain.
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// Token returns the bearer token sent with requests
+func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// APIError is an error response of the API
+type APIError struct {
+	StatusCode int
+	Message    string
+	Detail     string
+	RequestID  string
+	// Fields lists the invalid request fields of a validation error
+	Fields []FieldError
+}
+
+// FieldError describes why a request field is invalid. Code identifies the
+// rule, such as a password policy violation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	message := fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+	if e.Detail != "" {
+		message += ": " + e.Detail
+	}
+	return message
+}
+
+// IsNotFound reports whether err is an API error with status 404
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is an API error with status 401, such as
+// for an expired or revoked token
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is an API error with status 403, such as
+// for a token without the scope the route requires
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsConflict reports whether err is an API error with status 409
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// Pagination describes the page returned by a list call
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	TotalItems int `json:"total_items"`
+	TotalPages int `json:"total_pages"`
+}
+
+// envelope is the body of API responses
+type envelope struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Error   string          `json:"error"`
+	Errors  []FieldError    `json:"errors"`
+	Meta    *struct {
+		RequestID  string      `json:"request_id"`
+		Pagination *Pagination `json:"pagination"`
+	} `json:"meta"`
+}
+
+// problem is an RFC 7807 error response
+type problem struct {
+	Title     string       `json:"title"`
+	Detail    string       `json:"detail"`
+	RequestID string       `json:"request_id"`
+	Errors    []FieldError `json:"errors"`
+}
+
+// request describes an API call. A JSON body is encoded from body unless
+// rawBody is set.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        interface{}
+	rawBody     io.Reader
+	contentType string
+}
+
+// do performs the call and decodes the data of the response into out, when
+// non-nil. It returns the pagination of list responses.
+func (c *Client) do(ctx context.Context, req request, out interface{}) (*Pagination, error) {
+	httpReq, err := c.newRequest(ctx, req, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, decodeError(resp)
+	}
+
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if out != nil && len(env.Data) > 0 {
+		if err := json.Unmarshal(env.Data, out); err != nil {
+			return nil, fmt.Errorf("failed to decode response data: %w", err)
+		}
+	}
+	if env.Meta != nil {
+		return env.Meta.Pagination, nil
+	}
+	return nil, nil
+}
+
+// newRequest builds the HTTP request of req, authenticated with the token
+func (c *Client) newRequest(ctx context.Context, req request, accept string) (*http.Request, error) {
+	body := req.rawBody
+	contentType := req.contentType
+	if req.body != nil {
+		encoded, err := json.Marshal(req.body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(encoded)
+		contentType = "application/json"
+	}
+
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", accept)
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if c.userAgent != "" {
+		httpReq.Header.Set("User-Agent", c.userAgent)
+	}
+	if token := c.Token(); token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	return httpReq, nil
+}
+
+// decodeError reads an error response, in the envelope or RFC 7807 format
+func decodeError(resp *http.Response) error {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    http.StatusText(resp.StatusCode),
+		RequestID:  resp.Header.Get("X-Request-ID"),
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/problem+json":
+		var p problem
+		if json.NewDecoder(resp.Body).Decode(&p) == nil {
+			apiErr.Message, apiErr.Detail, apiErr.Fields = p.Title, p.Detail, p.Errors
+			if p.RequestID != "" {
+				apiErr.RequestID = p.RequestID
+			}
+		}
+	case "application/json":
+		var env envelope
+		if json.NewDecoder(resp.Body).Decode(&env) == nil {
+			if env.Message != "" {
+				apiErr.Message = env.Message
+			}
+			apiErr.Detail, apiErr.Fields = env.Error, env.Errors
+			if env.Meta != nil && env.Meta.RequestID != "" {
+				apiErr.RequestID = env.Meta.RequestID
+			}
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,227 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"boilerplate-go/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reply writes a successful API response with data
+func reply(w http.ResponseWriter, status int, data interface{}, meta map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": status < http.StatusBadRequest,
+		"message": "ok",
+		"data":    data,
+		"meta":    meta,
+	})
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New(Config{BaseURL: server.URL + "/"})
+	require.NoError(t, err)
+	return c
+}
+
+// specRoutes returns a pattern per operation of the OpenAPI spec, keyed by
+// method and path.
+func specRoutes(t *testing.T) map[string]*regexp.Regexp {
+	data, err := os.ReadFile("../../docs/swagger.json")
+	require.NoError(t, err)
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(data, &spec))
+
+	param := regexp.MustCompile(`\\\{[^/]+\\\}`)
+	routes := make(map[string]*regexp.Regexp)
+	for path, operations := range spec.Paths {
+		pattern := param.ReplaceAllString(regexp.QuoteMeta(path), `[^/]+`)
+		for method := range operations {
+			routes[strings.ToUpper(method)+" "+path] = regexp.MustCompile("^" + pattern + "$")
+		}
+	}
+	return routes
+}
+
+// TestEndpointsInSpec calls every client method and checks that each request
+// matches an operation of the OpenAPI spec, so the client follows API changes.
+func TestEndpointsInSpec(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		io.Copy(io.Discard, r.Body)
+		reply(w, http.StatusOK, map[string]interface{}{"id": 1, "total_parts": 0}, nil)
+	})
+
+	ctx := context.Background()
+	c.GetPublicConfig(ctx)
+	c.Register(ctx, entity.RegisterRequest{})
+	c.Login(ctx, entity.LoginRequest{})
+	c.RequestOTP(ctx, "+31600000000")
+	c.VerifyOTP(ctx, "+31600000000", "123456")
+	c.GetProfile(ctx)
+	c.UpdateProfile(ctx, entity.UpdateProfileRequest{})
+	c.ConfirmEmail(ctx, "token")
+	c.ChangePassword(ctx, "old", "new")
+	c.UploadAvatar(ctx, "avatar.png", strings.NewReader("png"))
+	c.ExportData(ctx)
+	c.DeleteAccount(ctx)
+	c.UpdatePhone(ctx, "+31600000000")
+	c.VerifyPhone(ctx, "123456")
+	c.ListSessions(ctx)
+	c.RevokeSession(ctx, 1)
+	c.RevokeAllSessions(ctx)
+	c.GetLegalDocuments(ctx)
+	c.GetConsents(ctx)
+	c.AcceptConsent(ctx, entity.LegalDocument{Document: entity.DocumentTerms, Version: "1"})
+	c.CreateOrder(ctx, entity.CreateOrderRequest{})
+	c.ListOrders(ctx, ListOrdersOptions{})
+	c.GetPaymentStatus(ctx, "pay_1")
+	c.WaitPaymentStatus(ctx, "pay_1", "pending", time.Second)
+	c.CancelOrder(ctx, "ord_1")
+	c.RefundOrder(ctx, entity.RefundOrderRequest{})
+	c.CreatePaymentIntent(ctx, entity.PaymentIntentRequest{})
+	c.InitiateUpload(ctx, entity.InitiateUploadRequest{})
+	c.GetUpload(ctx, 1)
+	c.UploadPart(ctx, 1, 1, strings.NewReader("part"))
+	c.CompleteUpload(ctx, 1)
+	c.AbortUpload(ctx, 1)
+	c.GetDownloadURL(ctx, 1)
+
+	routes := specRoutes(t)
+	require.Len(t, calls, 33)
+	for _, call := range calls {
+		method, path, _ := strings.Cut(call, " ")
+		found := false
+		for operation, pattern := range routes {
+			if strings.HasPrefix(operation, method+" ") && pattern.MatchString(path) {
+				found = true
+				break
+			}
+		}
+		assert.True(t, found, "%s is not in the OpenAPI spec", call)
+	}
+}
+
+func TestClient_LoginAuthenticatesRequests(t *testing.T) {
+	var authorization string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/auth/login":
+			var req entity.LoginRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "alice", req.Username)
+			reply(w, http.StatusOK, entity.LoginResponse{Token: "token-1", User: &entity.User{ID: 7}}, nil)
+		default:
+			authorization = r.Header.Get("Authorization")
+			reply(w, http.StatusOK, []*entity.Order{{OrderID: "ord_1"}}, map[string]interface{}{
+				"pagination": Pagination{Page: 2, PageSize: 1, TotalItems: 3, TotalPages: 3},
+			})
+		}
+	})
+
+	login, err := c.Login(context.Background(), entity.LoginRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, 7, login.User.ID)
+	assert.Equal(t, "token-1", c.Token())
+
+	orders, pagination, err := c.ListOrders(context.Background(), ListOrdersOptions{Page: 2, PageSize: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token-1", authorization)
+	require.Len(t, orders, 1)
+	assert.Equal(t, "ord_1", orders[0].OrderID)
+	require.NotNil(t, pagination)
+	assert.Equal(t, 3, pagination.TotalPages)
+}
+
+func TestClient_Errors(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/user/profile":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"success":false,"message":"User not found","error":"user not found","meta":{"request_id":"req-1"}}`))
+		case "/api/v1/user/password":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"success":false,"message":"Failed to change password","errors":[{"field":"new_password","code":"common","message":"password is too common"}]}`))
+		default:
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"type":"about:blank","title":"Order already cancelled","status":409,"request_id":"req-2"}`))
+		}
+	})
+
+	_, err := c.GetProfile(context.Background())
+	require.Error(t, err)
+	assert.True(t, IsNotFound(err))
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "User not found", apiErr.Message)
+	assert.Equal(t, "user not found", apiErr.Detail)
+	assert.Equal(t, "req-1", apiErr.RequestID)
+
+	_, err = c.CancelOrder(context.Background(), "ord_1")
+	assert.True(t, IsConflict(err))
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "Order already cancelled", apiErr.Message)
+	assert.Equal(t, "req-2", apiErr.RequestID)
+
+	err = c.ChangePassword(context.Background(), "old", "password")
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, []FieldError{{Field: "new_password", Code: "common", Message: "password is too common"}}, apiErr.Fields)
+}
+
+func TestClient_UploadFileResumes(t *testing.T) {
+	content := []byte("0123456789")
+	var mu sync.Mutex
+	parts := map[string]string{}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		upload := entity.Upload{ID: 5, Size: 10, PartSize: 4, TotalParts: 3}
+		switch {
+		case r.Method == http.MethodGet:
+			// Part 1 arrived before the interruption
+			upload.Parts = []entity.UploadPart{{PartNumber: 1, Size: 4}}
+			reply(w, http.StatusOK, upload, nil)
+		case r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			parts[r.URL.Path] = string(body)
+			mu.Unlock()
+			reply(w, http.StatusOK, entity.UploadPart{}, nil)
+		default:
+			upload.Status = "completed"
+			reply(w, http.StatusOK, upload, nil)
+		}
+	})
+
+	upload, err := c.UploadFile(context.Background(), entity.InitiateUploadRequest{}, bytes.NewReader(content), &entity.Upload{ID: 5})
+	require.NoError(t, err)
+	assert.Equal(t, "completed", upload.Status)
+	assert.Equal(t, map[string]string{
+		"/api/v1/uploads/5/parts/2": "4567",
+		"/api/v1/uploads/5/parts/3": "89",
+	}, parts)
+}
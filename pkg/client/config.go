@@ -0,0 +1,18 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"boilerplate-go/internal/domain/entity"
+)
+
+// GetPublicConfig returns the settings a frontend needs, such as the payment
+// provider, supported currencies and upload limits. It needs no login.
+func (c *Client) GetPublicConfig(ctx context.Context) (*entity.PublicConfig, error) {
+	var public entity.PublicConfig
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/config/public"}, &public); err != nil {
+		return nil, err
+	}
+	return &public, nil
+}
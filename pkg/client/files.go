@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"boilerplate-go/internal/domain/entity"
+)
+
+// InitiateUpload starts a resumable upload. Its parts are then uploaded with
+// UploadPart, in any order, and assembled with CompleteUpload.
+func (c *Client) InitiateUpload(ctx context.Context, req entity.InitiateUploadRequest) (*entity.Upload, error) {
+	var upload entity.Upload
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/uploads", body: req}, &upload); err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// GetUpload returns an upload and the parts received so far
+func (c *Client) GetUpload(ctx context.Context, id int) (*entity.Upload, error) {
+	var upload entity.Upload
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: uploadPath(id, "")}, &upload); err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// UploadPart uploads part n, starting at 1, of an upload. Every part is
+// PartSize bytes except the last, which holds the remainder.
+func (c *Client) UploadPart(ctx context.Context, id, n int, content io.Reader) (*entity.UploadPart, error) {
+	var part entity.UploadPart
+	req := request{
+		method:      http.MethodPut,
+		path:        uploadPath(id, fmt.Sprintf("/parts/%d", n)),
+		rawBody:     content,
+		contentType: "application/octet-stream",
+	}
+	if _, err := c.do(ctx, req, &part); err != nil {
+		return nil, err
+	}
+	return &part, nil
+}
+
+// CompleteUpload assembles the file once every part has been uploaded
+func (c *Client) CompleteUpload(ctx context.Context, id int) (*entity.Upload, error) {
+	var upload entity.Upload
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: uploadPath(id, "/complete")}, &upload); err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// AbortUpload discards an upload and its parts
+func (c *Client) AbortUpload(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: uploadPath(id, "")}, nil)
+	return err
+}
+
+// GetDownloadURL returns a time limited link to a completed upload
+func (c *Client) GetDownloadURL(ctx context.Context, id int) (*entity.UploadDownloadResponse, error) {
+	var download entity.UploadDownloadResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: uploadPath(id, "/download")}, &download); err != nil {
+		return nil, err
+	}
+	return &download, nil
+}
+
+// UploadFile uploads req.Size bytes of content as a file and completes the
+// upload. A failed call returns the upload along with the error; passing it
+// as resume to a later call uploads only the parts still missing.
+func (c *Client) UploadFile(ctx context.Context, req entity.InitiateUploadRequest, content io.ReaderAt, resume *entity.Upload) (*entity.Upload, error) {
+	var upload *entity.Upload
+	var err error
+	if resume == nil {
+		upload, err = c.InitiateUpload(ctx, req)
+	} else {
+		upload, err = c.GetUpload(ctx, resume.ID)
+	}
+	if err != nil {
+		return resume, err
+	}
+
+	received := make(map[int]bool, len(upload.Parts))
+	for _, part := range upload.Parts {
+		received[part.PartNumber] = true
+	}
+	for n := 1; n <= upload.TotalParts; n++ {
+		if received[n] {
+			continue
+		}
+		offset := int64(n-1) * upload.PartSize
+		section := io.NewSectionReader(content, offset, upload.ExpectedPartSize(n))
+		if _, err := c.UploadPart(ctx, upload.ID, n, section); err != nil {
+			return upload, fmt.Errorf("failed to upload part %d: %w", n, err)
+		}
+	}
+
+	completed, err := c.CompleteUpload(ctx, upload.ID)
+	if err != nil {
+		return upload, err
+	}
+	return completed, nil
+}
+
+func uploadPath(id int, suffix string) string {
+	return fmt.Sprintf("/api/v1/uploads/%d%s", id, suffix)
+}
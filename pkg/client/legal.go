@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"boilerplate-go/internal/domain/entity"
+)
+
+// GetLegalDocuments returns the current versions of the legal documents users
+// must accept. It needs no login.
+func (c *Client) GetLegalDocuments(ctx context.Context) ([]entity.LegalDocument, error) {
+	var documents []entity.LegalDocument
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/legal/documents"}, &documents); err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+// GetConsents returns the current legal documents the user has yet to accept
+// and the versions the user accepted
+func (c *Client) GetConsents(ctx context.Context) (*entity.ConsentStatus, error) {
+	var status entity.ConsentStatus
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/user/consents"}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// AcceptConsent accepts the documents, which must be their current versions
+// as returned by GetLegalDocuments
+func (c *Client) AcceptConsent(ctx context.Context, documents ...entity.LegalDocument) (*entity.ConsentStatus, error) {
+	body := entity.AcceptConsentRequest{Documents: make([]entity.DocumentVersion, len(documents))}
+	for i, document := range documents {
+		body.Documents[i] = entity.DocumentVersion{Document: document.Document, Version: document.Version}
+	}
+
+	var status entity.ConsentStatus
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/user/consents", body: body}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
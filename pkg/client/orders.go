@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/ndjson"
+)
+
+// ListOrdersOptions filters and pages the orders listed. Zero fields are not
+// filtered on; the server defaults to the first page of 20 orders.
+type ListOrdersOptions struct {
+	Status    string
+	From      *time.Time
+	To        *time.Time
+	MinAmount *float64
+	MaxAmount *float64
+	Page      int
+	PageSize  int
+}
+
+func (o ListOrdersOptions) query() url.Values {
+	query := url.Values{}
+	if o.Status != "" {
+		query.Set("status", o.Status)
+	}
+	if o.From != nil {
+		query.Set("from", o.From.Format(time.RFC3339))
+	}
+	if o.To != nil {
+		query.Set("to", o.To.Format(time.RFC3339))
+	}
+	if o.MinAmount != nil {
+		query.Set("min_amount", strconv.FormatFloat(*o.MinAmount, 'f', -1, 64))
+	}
+	if o.MaxAmount != nil {
+		query.Set("max_amount", strconv.FormatFloat(*o.MaxAmount, 'f', -1, 64))
+	}
+	if o.Page > 0 {
+		query.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.PageSize > 0 {
+		query.Set("page_size", strconv.Itoa(o.PageSize))
+	}
+	return query
+}
+
+// CreateOrder places an order and pays for it. An order whose payment is
+// still pending is returned with the payment_pending status.
+func (c *Client) CreateOrder(ctx context.Context, req entity.CreateOrderRequest) (*entity.OrderResponse, error) {
+	var order entity.OrderResponse
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/orders", body: req}, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ListOrders returns a page of the authenticated user's orders
+func (c *Client) ListOrders(ctx context.Context, opts ListOrdersOptions) ([]*entity.Order, *Pagination, error) {
+	var orders []*entity.Order
+	pagination, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/orders", query: opts.query()}, &orders)
+	if err != nil {
+		return nil, nil, err
+	}
+	return orders, pagination, nil
+}
+
+// StreamOrders calls fn with every order matching the options, newest first,
+// as the server streams them, ignoring the page options. It stops at the
+// first error fn returns.
+func (c *Client) StreamOrders(ctx context.Context, opts ListOrdersOptions, fn func(*entity.Order) error) error {
+	opts.Page, opts.PageSize = 0, 0
+	httpReq, err := c.newRequest(ctx, request{method: http.MethodGet, path: "/api/v1/orders", query: opts.query()}, ndjson.ContentType)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var order entity.Order
+		if err := dec.Decode(&order); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to decode order: %w", err)
+		}
+		if err := fn(&order); err != nil {
+			return err
+		}
+	}
+}
+
+// GetPaymentStatus returns the status of a payment
+func (c *Client) GetPaymentStatus(ctx context.Context, paymentID string) (*entity.PaymentStatus, error) {
+	var status entity.PaymentStatus
+	req := request{method: http.MethodGet, path: "/api/v1/orders/payment/" + url.PathEscape(paymentID) + "/status"}
+	if _, err := c.do(ctx, req, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// WaitPaymentStatus returns the status of a payment once it differs from
+// known, or from its current status when known is empty, or its status when
+// wait runs out. The server caps the wait, and the HTTP client's timeout must
+// outlast it.
+func (c *Client) WaitPaymentStatus(ctx context.Context, paymentID, known string, wait time.Duration) (*entity.PaymentStatus, error) {
+	query := url.Values{"wait": {wait.String()}}
+	if known != "" {
+		query.Set("status", known)
+	}
+
+	var status entity.PaymentStatus
+	req := request{method: http.MethodGet, path: "/api/v1/orders/payment/" + url.PathEscape(paymentID) + "/status", query: query}
+	if _, err := c.do(ctx, req, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// CancelOrder cancels an order, voiding its payment or refunding it once captured
+func (c *Client) CancelOrder(ctx context.Context, orderID string) (*entity.OrderCancellation, error) {
+	var cancellation entity.OrderCancellation
+	req := request{method: http.MethodPost, path: "/api/v1/orders/" + url.PathEscape(orderID) + "/cancel"}
+	if _, err := c.do(ctx, req, &cancellation); err != nil {
+		return nil, err
+	}
+	return &cancellation, nil
+}
+
+// RefundOrder refunds the payment of an order
+func (c *Client) RefundOrder(ctx context.Context, req entity.RefundOrderRequest) (*entity.RefundResponse, error) {
+	var refund entity.RefundResponse
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/orders/refund", body: req}, &refund); err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// CreatePaymentIntent creates a payment intent to confirm on the client side
+func (c *Client) CreatePaymentIntent(ctx context.Context, req entity.PaymentIntentRequest) (*entity.PaymentIntent, error) {
+	var intent entity.PaymentIntent
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/orders/payment-intent", body: req}, &intent); err != nil {
+		return nil, err
+	}
+	return &intent, nil
+}
@@ -0,0 +1,145 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"boilerplate-go/internal/domain/entity"
+)
+
+// GetProfile returns the profile of the authenticated user
+func (c *Client) GetProfile(ctx context.Context) (*entity.User, error) {
+	var user entity.User
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/user/profile"}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateProfile changes the fields set in req and returns the updated profile
+func (c *Client) UpdateProfile(ctx context.Context, req entity.UpdateProfileRequest) (*entity.User, error) {
+	var user entity.User
+	if _, err := c.do(ctx, request{method: http.MethodPatch, path: "/api/v1/user/profile", body: req}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ConfirmEmail changes the account's email address to the pending one with
+// the token of the link emailed to it. It needs no credentials.
+func (c *Client) ConfirmEmail(ctx context.Context, token string) (*entity.User, error) {
+	var user entity.User
+	body := entity.ConfirmEmailRequest{Token: token}
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/user/email/confirm", body: body}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ChangePassword replaces the user's password. Other sessions are logged
+// out; policy violations are listed in the Fields of the *APIError.
+func (c *Client) ChangePassword(ctx context.Context, currentPassword, newPassword string) error {
+	body := entity.ChangePasswordRequest{CurrentPassword: currentPassword, NewPassword: newPassword}
+	_, err := c.do(ctx, request{method: http.MethodPut, path: "/api/v1/user/password", body: body}, nil)
+	return err
+}
+
+// UploadAvatar uploads an avatar image read from content
+func (c *Client) UploadAvatar(ctx context.Context, fileName string, content io.Reader) (*entity.AvatarUploadResponse, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("avatar", fileName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return nil, fmt.Errorf("failed to read avatar: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	var avatar entity.AvatarUploadResponse
+	req := request{
+		method:      http.MethodPost,
+		path:        "/api/v1/user/avatar",
+		rawBody:     &body,
+		contentType: form.FormDataContentType(),
+	}
+	if _, err := c.do(ctx, req, &avatar); err != nil {
+		return nil, err
+	}
+	return &avatar, nil
+}
+
+// ExportData schedules an archive of the personal data held about the
+// authenticated user; a download link is emailed once it is ready
+func (c *Client) ExportData(ctx context.Context) (*entity.DataExportResponse, error) {
+	var result entity.DataExportResponse
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/user/export"}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteAccount requests the erasure of the authenticated user's account
+func (c *Client) DeleteAccount(ctx context.Context) (*entity.AccountDeletionResponse, error) {
+	var deletion entity.AccountDeletionResponse
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/user/delete"}, &deletion); err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// UpdatePhone sets the user's phone number, which is confirmed with the code
+// sent to it by SMS
+func (c *Client) UpdatePhone(ctx context.Context, phone string) error {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/user/phone", body: entity.PhoneRequest{Phone: phone}}, nil)
+	return err
+}
+
+// VerifyPhone confirms the user's phone number with the code sent to it
+func (c *Client) VerifyPhone(ctx context.Context, code string) (*entity.User, error) {
+	var user entity.User
+	req := request{method: http.MethodPost, path: "/api/v1/user/phone/verify", body: entity.PhoneVerifyRequest{Code: code}}
+	if _, err := c.do(ctx, req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListSessions returns the active sessions of the authenticated user
+func (c *Client) ListSessions(ctx context.Context) ([]*entity.Session, error) {
+	var sessions []*entity.Session
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/user/sessions"}, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeSession logs out a session of the authenticated user
+func (c *Client) RevokeSession(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/api/v1/user/sessions/%d", id)}, nil)
+	return err
+}
+
+// RevokeAllSessions logs out every session of the authenticated user
+func (c *Client) RevokeAllSessions(ctx context.Context) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/api/v1/user/sessions"}, nil)
+	return err
+}
+
+// IssueAccessToken issues a token limited to some of the scopes of the
+// client's token, such as a machine token that may only read orders. The
+// client keeps its own token.
+func (c *Client) IssueAccessToken(ctx context.Context, req entity.AccessTokenRequest) (*entity.AccessTokenResponse, error) {
+	var token entity.AccessTokenResponse
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/user/tokens", body: req}, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
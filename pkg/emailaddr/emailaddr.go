@@ -0,0 +1,26 @@
+// Package emailaddr normalizes email addresses, so one mailbox is one
+// account: addresses are trimmed and lowercased before they are stored or
+// looked up, and optionally lose their plus subaddress.
+package emailaddr
+
+import "strings"
+
+// Normalize returns the form an address is stored and looked up in: trimmed
+// and lowercased. With stripPlus the subaddress of the local part is removed
+// too, so user+tag@example.com becomes user@example.com. Only strip it when
+// the mail servers of your users deliver subaddresses to the same mailbox.
+func Normalize(address string, stripPlus bool) string {
+	address = strings.ToLower(strings.TrimSpace(address))
+	if !stripPlus {
+		return address
+	}
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return address
+	}
+	local, domain := address[:at], address[at:]
+	if plus := strings.Index(local, "+"); plus > 0 {
+		local = local[:plus]
+	}
+	return local + domain
+}
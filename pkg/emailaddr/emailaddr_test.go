@@ -0,0 +1,28 @@
+package emailaddr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		address   string
+		stripPlus bool
+		expected  string
+	}{
+		{name: "case and spaces", address: " User@Example.COM ", expected: "user@example.com"},
+		{name: "plus kept", address: "User+News@example.com", expected: "user+news@example.com"},
+		{name: "plus stripped", address: "User+News@example.com", stripPlus: true, expected: "user@example.com"},
+		{name: "leading plus kept", address: "+news@example.com", stripPlus: true, expected: "+news@example.com"},
+		{name: "not an address", address: "User+News", stripPlus: true, expected: "user+news"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Normalize(tt.address, tt.stripPlus))
+		})
+	}
+}
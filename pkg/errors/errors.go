@@ -4,14 +4,444 @@ import "errors"
 
 // Common application errors
 var (
-	ErrUserNotFound       = errors.New("user not found")
-	ErrUserAlreadyExists  = errors.New("user already exists")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrUnauthorized       = errors.New("unauthorized")
-	ErrInternalServer     = errors.New("internal server error")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrUserAlreadyExists   = errors.New("user already exists")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrUnauthorized        = errors.New("unauthorized")
+	ErrInternalServer      = errors.New("internal server error")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrInvalidImport       = errors.New("invalid import file")
+	ErrImportNotFound      = errors.New("user import not found")
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrOrderAlreadyExists  = errors.New("order already exists")
+	ErrInvalidOrderState   = errors.New("order cannot be changed in its current state")
+	ErrInvalidOrder        = errors.New("invalid order")
+	ErrCouponNotFound      = errors.New("coupon not found")
+	ErrCouponInvalid       = errors.New("coupon cannot be applied")
+	ErrCouponAlreadyExists = errors.New("coupon already exists")
+	ErrInvalidCoupon       = errors.New("invalid coupon")
+	ErrOrderBlocked        = errors.New("order blocked by fraud screening")
+	ErrDeadLetterNotFound  = errors.New("dead letter job not found")
+	ErrUnknownJobType      = errors.New("unknown job type")
+	ErrProviderUnavailable = errors.New("provider unavailable")
+	ErrInvalidCampaign     = errors.New("invalid campaign")
+	ErrCampaignNotFound    = errors.New("campaign not found")
+	ErrUnknownMessageType  = errors.New("unknown message type")
+	ErrMessageTooLong      = errors.New("message too long")
+	ErrInvalidPhone        = errors.New("invalid phone number")
+	ErrPhoneAlreadyInUse   = errors.New("phone number already in use")
+	ErrPhoneNotSet         = errors.New("no phone number to verify")
+	ErrOTPNotFound         = errors.New("one-time code not found")
+	ErrInvalidOTP          = errors.New("invalid or expired one-time code")
+	ErrOTPRateLimited      = errors.New("too many one-time code requests or attempts")
+	ErrLoginUnverified     = errors.New("sign-in from a new device or location must be verified")
+	ErrInvalidProfile      = errors.New("invalid profile")
+	ErrEmailAlreadyInUse   = errors.New("email address already in use")
+	ErrEmailChangeNotFound = errors.New("email change not found or expired")
+	ErrWeakPassword        = errors.New("password does not meet the requirements")
+	ErrInvalidUsername     = errors.New("username does not meet the requirements")
+	ErrFileNotFound        = errors.New("file not found")
+	ErrInvalidImage        = errors.New("invalid image")
+	ErrImageTooLarge       = errors.New("image too large")
+	ErrInvalidSignedURL    = errors.New("invalid or expired signed URL")
+	ErrUploadNotFound      = errors.New("upload not found")
+	ErrInvalidUpload       = errors.New("invalid upload")
+	ErrUploadClosed        = errors.New("upload is no longer in progress")
+	ErrFileNotScanned      = errors.New("file has not passed a malware scan")
+	ErrFileInfected        = errors.New("file is infected and quarantined")
+	ErrInvalidAttachment   = errors.New("invalid email attachment")
+	ErrInvalidStatsRange   = errors.New("invalid stats range")
+	ErrInvalidReportQuery  = errors.New("invalid report query")
+	ErrInvalidExport       = errors.New("invalid export")
+	ErrExportNotFound      = errors.New("export not found")
+	ErrInvalidSettings     = errors.New("invalid provider settings")
+	ErrDuplicateEvent      = errors.New("webhook event already processed")
+	ErrEventBuffered       = errors.New("webhook event buffered until its order can take it")
+	ErrBudgetExceeded      = errors.New("provider budget exceeded")
+	ErrInvalidTokenRequest = errors.New("invalid access token request")
+	ErrScopeNotGranted     = errors.New("scope not granted")
+	ErrCannotImpersonate   = errors.New("user cannot be impersonated")
+	ErrOrgNotFound         = errors.New("organization not found")
+	ErrInvalidOrganization = errors.New("invalid organization request")
+	ErrNotOrgMember        = errors.New("not a member of the organization")
+	ErrOrgRoleRequired     = errors.New("organization role does not allow this")
+	ErrLastOrgOwner        = errors.New("organization must keep an owner")
+	ErrAlreadyOrgMember    = errors.New("user is already a member of the organization")
+	ErrInvitationNotFound  = errors.New("invitation not found")
+	ErrUserDeactivated     = errors.New("user is deactivated")
+	ErrSSONotConfigured    = errors.New("single sign-on is not configured")
+	ErrInvalidSSOState     = errors.New("invalid or expired single sign-on state")
+	ErrSSOFailed           = errors.New("single sign-on failed")
+	ErrIdentityNotFound    = errors.New("identity not found")
+	ErrSSOAccountConflict  = errors.New("email belongs to an account outside the organization")
+	ErrSSONotProvisioned   = errors.New("no account for the identity and provisioning is off")
+	ErrConflict            = errors.New("modified concurrently by another request")
+	ErrConsentRequired     = errors.New("the latest legal documents must be accepted")
+	ErrInvalidConsent      = errors.New("invalid consent")
+	ErrConsentImpersonated = errors.New("consent must be given by the user, not while impersonating")
 )
 
 // IsUserNotFound checks if the error is a user not found error.
 func IsUserNotFound(err error) bool {
 	return errors.Is(err, ErrUserNotFound)
 }
+
+// IsUserAlreadyExists checks if the error is a user already exists error.
+func IsUserAlreadyExists(err error) bool {
+	return errors.Is(err, ErrUserAlreadyExists)
+}
+
+// IsInvalidCredentials checks if the error is an invalid credentials error.
+func IsInvalidCredentials(err error) bool {
+	return errors.Is(err, ErrInvalidCredentials)
+}
+
+// IsSessionNotFound checks if the error is a session not found error.
+func IsSessionNotFound(err error) bool {
+	return errors.Is(err, ErrSessionNotFound)
+}
+
+// IsOrderNotFound checks if the error is an order not found error.
+func IsOrderNotFound(err error) bool {
+	return errors.Is(err, ErrOrderNotFound)
+}
+
+// IsOrderAlreadyExists checks if the error is an order already exists error.
+func IsOrderAlreadyExists(err error) bool {
+	return errors.Is(err, ErrOrderAlreadyExists)
+}
+
+// IsInvalidOrderState checks if the error is an invalid order state error.
+func IsInvalidOrderState(err error) bool {
+	return errors.Is(err, ErrInvalidOrderState)
+}
+
+// IsInvalidOrder checks if the error is an invalid order error.
+func IsInvalidOrder(err error) bool {
+	return errors.Is(err, ErrInvalidOrder)
+}
+
+// IsOrderBlocked checks if the error is an order blocked by fraud screening error.
+func IsOrderBlocked(err error) bool {
+	return errors.Is(err, ErrOrderBlocked)
+}
+
+// IsCouponNotFound checks if the error is a coupon not found error.
+func IsCouponNotFound(err error) bool {
+	return errors.Is(err, ErrCouponNotFound)
+}
+
+// IsCouponInvalid checks if the error is a coupon cannot be applied error.
+func IsCouponInvalid(err error) bool {
+	return errors.Is(err, ErrCouponInvalid)
+}
+
+// IsCouponAlreadyExists checks if the error is a coupon already exists error.
+func IsCouponAlreadyExists(err error) bool {
+	return errors.Is(err, ErrCouponAlreadyExists)
+}
+
+// IsInvalidCoupon checks if the error is an invalid coupon definition error.
+func IsInvalidCoupon(err error) bool {
+	return errors.Is(err, ErrInvalidCoupon)
+}
+
+// IsInvalidImport checks if the error is an invalid import error.
+func IsInvalidImport(err error) bool {
+	return errors.Is(err, ErrInvalidImport)
+}
+
+// IsImportNotFound checks if the error is a user import not found error.
+func IsImportNotFound(err error) bool {
+	return errors.Is(err, ErrImportNotFound)
+}
+
+// IsDeadLetterNotFound checks if the error is a dead letter job not found error.
+func IsDeadLetterNotFound(err error) bool {
+	return errors.Is(err, ErrDeadLetterNotFound)
+}
+
+// IsUnknownJobType checks if the error is an unknown job type error.
+func IsUnknownJobType(err error) bool {
+	return errors.Is(err, ErrUnknownJobType)
+}
+
+// IsProviderUnavailable checks if the error is a provider unavailable error.
+func IsProviderUnavailable(err error) bool {
+	return errors.Is(err, ErrProviderUnavailable)
+}
+
+// IsInvalidCampaign checks if the error is an invalid campaign error.
+func IsInvalidCampaign(err error) bool {
+	return errors.Is(err, ErrInvalidCampaign)
+}
+
+// IsCampaignNotFound checks if the error is a campaign not found error.
+func IsCampaignNotFound(err error) bool {
+	return errors.Is(err, ErrCampaignNotFound)
+}
+
+// IsUnknownMessageType checks if the error is an unknown message type error.
+func IsUnknownMessageType(err error) bool {
+	return errors.Is(err, ErrUnknownMessageType)
+}
+
+// IsMessageTooLong checks if the error is a message too long error.
+func IsMessageTooLong(err error) bool {
+	return errors.Is(err, ErrMessageTooLong)
+}
+
+// IsInvalidPhone checks if the error is an invalid phone number error.
+func IsInvalidPhone(err error) bool {
+	return errors.Is(err, ErrInvalidPhone)
+}
+
+// IsPhoneAlreadyInUse checks if the error is a phone number already in use error.
+func IsPhoneAlreadyInUse(err error) bool {
+	return errors.Is(err, ErrPhoneAlreadyInUse)
+}
+
+// IsPhoneNotSet checks if the error is a no phone number to verify error.
+func IsPhoneNotSet(err error) bool {
+	return errors.Is(err, ErrPhoneNotSet)
+}
+
+// IsOTPNotFound checks if the error is a one-time code not found error.
+func IsOTPNotFound(err error) bool {
+	return errors.Is(err, ErrOTPNotFound)
+}
+
+// IsInvalidOTP checks if the error is an invalid or expired one-time code error.
+func IsInvalidOTP(err error) bool {
+	return errors.Is(err, ErrInvalidOTP)
+}
+
+// IsOTPRateLimited checks if the error is a one-time code rate limit error.
+func IsOTPRateLimited(err error) bool {
+	return errors.Is(err, ErrOTPRateLimited)
+}
+
+// IsLoginUnverified checks if the error is a sign-in needing verification error.
+func IsLoginUnverified(err error) bool {
+	return errors.Is(err, ErrLoginUnverified)
+}
+
+// IsInvalidProfile checks if the error is an invalid profile error.
+func IsInvalidProfile(err error) bool {
+	return errors.Is(err, ErrInvalidProfile)
+}
+
+// IsEmailAlreadyInUse checks if the error is an email address already in use error.
+func IsEmailAlreadyInUse(err error) bool {
+	return errors.Is(err, ErrEmailAlreadyInUse)
+}
+
+// IsEmailChangeNotFound checks if the error is an email change not found error.
+func IsEmailChangeNotFound(err error) bool {
+	return errors.Is(err, ErrEmailChangeNotFound)
+}
+
+// IsWeakPassword checks if the error is a password policy violation.
+func IsWeakPassword(err error) bool {
+	return errors.Is(err, ErrWeakPassword)
+}
+
+// IsInvalidUsername checks if the error is an invalid username error.
+func IsInvalidUsername(err error) bool {
+	return errors.Is(err, ErrInvalidUsername)
+}
+
+// IsFileNotFound checks if the error is a file not found error.
+func IsFileNotFound(err error) bool {
+	return errors.Is(err, ErrFileNotFound)
+}
+
+// IsInvalidImage checks if the error is an invalid image error.
+func IsInvalidImage(err error) bool {
+	return errors.Is(err, ErrInvalidImage)
+}
+
+// IsImageTooLarge checks if the error is an image too large error.
+func IsImageTooLarge(err error) bool {
+	return errors.Is(err, ErrImageTooLarge)
+}
+
+// IsInvalidSignedURL checks if the error is an invalid signed URL error.
+func IsInvalidSignedURL(err error) bool {
+	return errors.Is(err, ErrInvalidSignedURL)
+}
+
+// IsUploadNotFound checks if the error is an upload not found error.
+func IsUploadNotFound(err error) bool {
+	return errors.Is(err, ErrUploadNotFound)
+}
+
+// IsInvalidUpload checks if the error is an invalid upload error.
+func IsInvalidUpload(err error) bool {
+	return errors.Is(err, ErrInvalidUpload)
+}
+
+// IsUploadClosed checks if the error is an upload closed error.
+func IsUploadClosed(err error) bool {
+	return errors.Is(err, ErrUploadClosed)
+}
+
+// IsFileNotScanned checks if the error is a file not scanned error.
+func IsFileNotScanned(err error) bool {
+	return errors.Is(err, ErrFileNotScanned)
+}
+
+// IsFileInfected checks if the error is a file infected error.
+func IsFileInfected(err error) bool {
+	return errors.Is(err, ErrFileInfected)
+}
+
+// IsInvalidAttachment checks if the error is an invalid email attachment error.
+func IsInvalidAttachment(err error) bool {
+	return errors.Is(err, ErrInvalidAttachment)
+}
+
+// IsInvalidStatsRange checks if the error is an invalid stats range error.
+func IsInvalidStatsRange(err error) bool {
+	return errors.Is(err, ErrInvalidStatsRange)
+}
+
+// IsInvalidReportQuery checks if the error is an invalid report query error.
+func IsInvalidReportQuery(err error) bool {
+	return errors.Is(err, ErrInvalidReportQuery)
+}
+
+// IsInvalidExport checks if the error is an invalid export error.
+func IsInvalidExport(err error) bool {
+	return errors.Is(err, ErrInvalidExport)
+}
+
+// IsExportNotFound checks if the error is an export not found error.
+func IsExportNotFound(err error) bool {
+	return errors.Is(err, ErrExportNotFound)
+}
+
+// IsInvalidSettings checks if the error is an invalid provider settings error.
+func IsInvalidSettings(err error) bool {
+	return errors.Is(err, ErrInvalidSettings)
+}
+
+// IsDuplicateEvent checks if the error is a webhook event already processed error.
+func IsDuplicateEvent(err error) bool {
+	return errors.Is(err, ErrDuplicateEvent)
+}
+
+// IsEventBuffered checks if the error is a webhook event buffered until its order can take it error.
+func IsEventBuffered(err error) bool {
+	return errors.Is(err, ErrEventBuffered)
+}
+
+// IsBudgetExceeded checks if the error is a provider budget exceeded error.
+func IsBudgetExceeded(err error) bool {
+	return errors.Is(err, ErrBudgetExceeded)
+}
+
+// IsInvalidTokenRequest checks if the error is an invalid access token request error.
+func IsInvalidTokenRequest(err error) bool {
+	return errors.Is(err, ErrInvalidTokenRequest)
+}
+
+// IsScopeNotGranted checks if the error is a scope not granted error.
+func IsScopeNotGranted(err error) bool {
+	return errors.Is(err, ErrScopeNotGranted)
+}
+
+// IsCannotImpersonate checks if the error is a user cannot be impersonated error.
+func IsCannotImpersonate(err error) bool {
+	return errors.Is(err, ErrCannotImpersonate)
+}
+
+// IsOrgNotFound checks if the error is an organization not found error.
+func IsOrgNotFound(err error) bool {
+	return errors.Is(err, ErrOrgNotFound)
+}
+
+// IsInvalidOrganization checks if the error is an invalid organization request error.
+func IsInvalidOrganization(err error) bool {
+	return errors.Is(err, ErrInvalidOrganization)
+}
+
+// IsNotOrgMember checks if the error is a not an organization member error.
+func IsNotOrgMember(err error) bool {
+	return errors.Is(err, ErrNotOrgMember)
+}
+
+// IsOrgRoleRequired checks if the error is an insufficient organization role error.
+func IsOrgRoleRequired(err error) bool {
+	return errors.Is(err, ErrOrgRoleRequired)
+}
+
+// IsLastOrgOwner checks if the error is a last organization owner error.
+func IsLastOrgOwner(err error) bool {
+	return errors.Is(err, ErrLastOrgOwner)
+}
+
+// IsAlreadyOrgMember checks if the error is an already a member error.
+func IsAlreadyOrgMember(err error) bool {
+	return errors.Is(err, ErrAlreadyOrgMember)
+}
+
+// IsInvitationNotFound checks if the error is an invitation not found error.
+func IsInvitationNotFound(err error) bool {
+	return errors.Is(err, ErrInvitationNotFound)
+}
+
+// IsUserDeactivated checks if the error is a user is deactivated error.
+func IsUserDeactivated(err error) bool {
+	return errors.Is(err, ErrUserDeactivated)
+}
+
+// IsSSONotConfigured checks if the error is a single sign-on not configured error.
+func IsSSONotConfigured(err error) bool {
+	return errors.Is(err, ErrSSONotConfigured)
+}
+
+// IsInvalidSSOState checks if the error is an invalid single sign-on state error.
+func IsInvalidSSOState(err error) bool {
+	return errors.Is(err, ErrInvalidSSOState)
+}
+
+// IsSSOFailed checks if the error is a single sign-on failed error.
+func IsSSOFailed(err error) bool {
+	return errors.Is(err, ErrSSOFailed)
+}
+
+// IsIdentityNotFound checks if the error is an identity not found error.
+func IsIdentityNotFound(err error) bool {
+	return errors.Is(err, ErrIdentityNotFound)
+}
+
+// IsSSOAccountConflict checks if the error is a single sign-on account conflict error.
+func IsSSOAccountConflict(err error) bool {
+	return errors.Is(err, ErrSSOAccountConflict)
+}
+
+// IsSSONotProvisioned checks if the error is a single sign-on not provisioned error.
+func IsSSONotProvisioned(err error) bool {
+	return errors.Is(err, ErrSSONotProvisioned)
+}
+
+// IsConflict checks if the error is a concurrent modification conflict error.
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// IsConsentRequired checks if the error is a consent required error.
+func IsConsentRequired(err error) bool {
+	return errors.Is(err, ErrConsentRequired)
+}
+
+// IsInvalidConsent checks if the error is an invalid consent error.
+func IsInvalidConsent(err error) bool {
+	return errors.Is(err, ErrInvalidConsent)
+}
+
+// IsConsentImpersonated checks if the error is a consent given while impersonating error.
+func IsConsentImpersonated(err error) bool {
+	return errors.Is(err, ErrConsentImpersonated)
+}
@@ -0,0 +1,58 @@
+package hash
+
+import (
+	"testing"
+	"time"
+
+	"boilerplate-go/pkg/perfbudget"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Hashing runs on registration and checking on every login, so the bcrypt
+// cost trades brute-force resistance against their latency.
+const passwordBudget = 500 * time.Millisecond
+
+func TestPassword_RoundTrip(t *testing.T) {
+	hashed, err := HashPassword("correct horse")
+	require.NoError(t, err)
+
+	assert.True(t, CheckPassword("correct horse", hashed))
+	assert.False(t, CheckPassword("wrong horse", hashed))
+
+	cost, err := bcrypt.Cost([]byte(hashed))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, cost, bcrypt.DefaultCost, "hashing cost lowered below the bcrypt default")
+}
+
+func BenchmarkHashPassword(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := HashPassword("correct horse"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCheckPassword(b *testing.B) {
+	hashed, err := HashPassword("correct horse")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !CheckPassword("correct horse", hashed) {
+			b.Fatal("password rejected")
+		}
+	}
+}
+
+func TestPassword_LatencyBudget(t *testing.T) {
+	t.Run("hash", func(t *testing.T) {
+		perfbudget.Check(t, passwordBudget, BenchmarkHashPassword)
+	})
+	t.Run("check", func(t *testing.T) {
+		perfbudget.Check(t, passwordBudget, BenchmarkCheckPassword)
+	})
+}
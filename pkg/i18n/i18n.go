@@ -0,0 +1,166 @@
+// Package i18n translates API messages. Messages are written in English,
+// which doubles as the lookup key, so code keeps readable literals and a
+// message without a translation is shown in English.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// SourceLocale is the language messages are written in
+const SourceLocale = "en"
+
+// Catalog holds the translations of messages by locale. Translations are
+// registered at startup; a catalog is safe for concurrent lookups after that.
+type Catalog struct {
+	messages      map[string]map[string]string
+	defaultLocale string
+	locales       []string
+	matcher       language.Matcher
+}
+
+// NewCatalog creates a catalog with the built-in translations. The default
+// locale is used for clients that state no supported language.
+func NewCatalog(defaultLocale string) (*Catalog, error) {
+	if defaultLocale == "" {
+		defaultLocale = SourceLocale
+	}
+	if _, err := language.Parse(defaultLocale); err != nil {
+		return nil, fmt.Errorf("invalid default locale %q: %w", defaultLocale, err)
+	}
+
+	c := &Catalog{
+		messages:      make(map[string]map[string]string),
+		defaultLocale: normalizeLocale(defaultLocale),
+	}
+	for locale, messages := range defaultMessages {
+		c.Register(locale, messages)
+	}
+	c.buildMatcher()
+	return c, nil
+}
+
+// Register adds translations to a locale, replacing existing ones of the
+// same messages
+func (c *Catalog) Register(locale string, messages map[string]string) {
+	locale = normalizeLocale(locale)
+	if c.messages[locale] == nil {
+		c.messages[locale] = make(map[string]string, len(messages))
+	}
+	for message, translation := range messages {
+		c.messages[locale][message] = translation
+	}
+	c.buildMatcher()
+}
+
+// LoadDir registers the translations in the JSON files of dir. Each file is
+// named after its locale, such as id.json or pt-BR.json, and maps English
+// messages to their translation.
+func (c *Catalog) LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read catalog: %w", err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("invalid catalog %s: %w", filepath.Base(file), err)
+		}
+
+		locale := strings.TrimSuffix(filepath.Base(file), ".json")
+		if _, err := language.Parse(locale); err != nil {
+			return fmt.Errorf("invalid catalog locale %q: %w", locale, err)
+		}
+		c.Register(locale, messages)
+	}
+	return nil
+}
+
+// Locales returns the supported locales, starting with the default
+func (c *Catalog) Locales() []string {
+	return c.locales
+}
+
+// Match returns the supported locale best matching the first preference that
+// matches any, and whether one did. A preference is a locale or an
+// Accept-Language header. Without a match it returns the default locale.
+func (c *Catalog) Match(preferences ...string) (string, bool) {
+	for _, preference := range preferences {
+		tags, _, err := language.ParseAcceptLanguage(preference)
+		if err != nil || len(tags) == 0 {
+			continue
+		}
+		if _, index, confidence := c.matcher.Match(tags...); confidence != language.No {
+			return c.locales[index], true
+		}
+	}
+	return c.defaultLocale, false
+}
+
+// Translate returns the message in the locale, or in its base language, such
+// as pt for pt-br. Messages without a translation are returned unchanged.
+func (c *Catalog) Translate(locale, message string) string {
+	if locale == "" {
+		locale = c.defaultLocale
+	}
+	for _, candidate := range localeFallbacks(locale) {
+		if translation, ok := c.messages[candidate][message]; ok {
+			return translation
+		}
+	}
+	return message
+}
+
+// Translatef translates a format and formats it with args
+func (c *Catalog) Translatef(locale, format string, args ...interface{}) string {
+	return fmt.Sprintf(c.Translate(locale, format), args...)
+}
+
+// buildMatcher lists the supported locales with the default first, since the
+// matcher falls back to the first one.
+func (c *Catalog) buildMatcher() {
+	supported := map[string]bool{c.defaultLocale: true, SourceLocale: true}
+	for locale := range c.messages {
+		supported[locale] = true
+	}
+	delete(supported, c.defaultLocale)
+
+	c.locales = []string{c.defaultLocale}
+	others := make([]string, 0, len(supported))
+	for locale := range supported {
+		others = append(others, locale)
+	}
+	sort.Strings(others)
+	c.locales = append(c.locales, others...)
+
+	tags := make([]language.Tag, len(c.locales))
+	for i, locale := range c.locales {
+		tags[i] = language.Make(locale)
+	}
+	c.matcher = language.NewMatcher(tags)
+}
+
+// localeFallbacks lists the locales to try in order, e.g. pt-br, pt.
+func localeFallbacks(locale string) []string {
+	locale = normalizeLocale(locale)
+	candidates := []string{locale}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		candidates = append(candidates, base)
+	}
+	return candidates
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
@@ -0,0 +1,72 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogMatch(t *testing.T) {
+	catalog, err := NewCatalog("en")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		preferences []string
+		want        string
+		matched     bool
+	}{
+		{"exact", []string{"id"}, "id", true},
+		{"regional variant", []string{"id-ID"}, "id", true},
+		{"quality order", []string{"fr;q=0.5, id;q=0.9"}, "id", true},
+		{"unsupported", []string{"fr"}, "en", false},
+		{"empty", []string{""}, "en", false},
+		{"first matching preference", []string{"", "id"}, "id", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locale, matched := catalog.Match(tt.preferences...)
+			assert.Equal(t, tt.want, locale)
+			assert.Equal(t, tt.matched, matched)
+		})
+	}
+}
+
+func TestCatalogTranslate(t *testing.T) {
+	catalog, err := NewCatalog("id")
+	require.NoError(t, err)
+	catalog.Register("pt", map[string]string{"Login successful": "Login realizado com sucesso"})
+
+	assert.Equal(t, "Login berhasil", catalog.Translate("id", "Login successful"))
+	assert.Equal(t, "Login realizado com sucesso", catalog.Translate("pt-BR", "Login successful"))
+	// An empty locale uses the default
+	assert.Equal(t, "Login berhasil", catalog.Translate("", "Login successful"))
+	// English and untranslated messages are returned unchanged
+	assert.Equal(t, "Login successful", catalog.Translate("en", "Login successful"))
+	assert.Equal(t, "Something new", catalog.Translate("id", "Something new"))
+
+	assert.Equal(t, "email wajib diisi", catalog.Translatef("id", "%s is required", "email"))
+}
+
+func TestCatalogLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nl.json"), []byte(`{"Login successful": "Inloggen gelukt"}`), 0o600))
+
+	catalog, err := NewCatalog("en")
+	require.NoError(t, err)
+	require.NoError(t, catalog.LoadDir(dir))
+
+	assert.Equal(t, "Inloggen gelukt", catalog.Translate("nl", "Login successful"))
+	assert.Equal(t, []string{"en", "id", "nl"}, catalog.Locales())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{`), 0o600))
+	assert.Error(t, catalog.LoadDir(dir))
+}
+
+func TestNewCatalogInvalidLocale(t *testing.T) {
+	_, err := NewCatalog("not a locale")
+	assert.Error(t, err)
+}
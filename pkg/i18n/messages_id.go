@@ -0,0 +1,259 @@
+package i18n
+
+// defaultMessages are the built-in translations, by locale
+var defaultMessages = map[string]map[string]string{
+	"id": messagesID,
+}
+
+// messagesID are the Indonesian translations
+var messagesID = map[string]string{
+	// Authentication
+	"User registered successfully":                            "Pengguna berhasil didaftarkan",
+	"Registration failed":                                     "Pendaftaran gagal",
+	"Login successful":                                        "Login berhasil",
+	"Login failed":                                            "Login gagal",
+	"Sign-in verification required":                           "Verifikasi login diperlukan",
+	"Login with code failed":                                  "Login dengan kode gagal",
+	"Failed to send login code":                               "Gagal mengirim kode login",
+	"Verification code sent":                                  "Kode verifikasi telah dikirim",
+	"If the number is registered, a login code has been sent": "Jika nomor terdaftar, kode login telah dikirim",
+	"CAPTCHA verification failed":                             "Verifikasi CAPTCHA gagal",
+	"CAPTCHA verification unavailable":                        "Verifikasi CAPTCHA tidak tersedia",
+	"Authentication required":                                 "Autentikasi diperlukan",
+	"Authorization header required":                           "Header Authorization diperlukan",
+	"Invalid authorization format":                            "Format otorisasi tidak valid",
+	"Invalid token":                                           "Token tidak valid",
+	"Insufficient permissions":                                "Izin tidak mencukupi",
+	"Failed to validate session":                              "Gagal memvalidasi sesi",
+	"User not authenticated":                                  "Pengguna belum terautentikasi",
+
+	// Profile and account
+	"Profile retrieved successfully":     "Profil berhasil diambil",
+	"Profile updated successfully":       "Profil berhasil diperbarui",
+	"Failed to get user profile":         "Gagal mengambil profil pengguna",
+	"Failed to update user profile":      "Gagal memperbarui profil pengguna",
+	"Avatar uploaded successfully":       "Avatar berhasil diunggah",
+	"Avatar too large":                   "Avatar terlalu besar",
+	"Avatar uploads unavailable":         "Unggah avatar tidak tersedia",
+	"Invalid avatar image":               "Gambar avatar tidak valid",
+	"Invalid avatar upload":              "Unggahan avatar tidak valid",
+	"Failed to upload avatar":            "Gagal mengunggah avatar",
+	"User data exported successfully":    "Data pengguna berhasil diekspor",
+	"Failed to export user data":         "Gagal mengekspor data pengguna",
+	"Account deletion scheduled":         "Penghapusan akun dijadwalkan",
+	"Failed to delete account":           "Gagal menghapus akun",
+	"Phone number verified":              "Nomor telepon terverifikasi",
+	"Failed to update phone number":      "Gagal memperbarui nomor telepon",
+	"Failed to verify phone number":      "Gagal memverifikasi nomor telepon",
+	"Invalid user ID format":             "Format ID pengguna tidak valid",
+	"Sessions retrieved successfully":    "Sesi berhasil diambil",
+	"Session revoked successfully":       "Sesi berhasil dicabut",
+	"Logged out of all sessions":         "Berhasil keluar dari semua sesi",
+	"Session not found":                  "Sesi tidak ditemukan",
+	"Invalid session ID":                 "ID sesi tidak valid",
+	"Failed to list sessions":            "Gagal menampilkan daftar sesi",
+	"Failed to revoke session":           "Gagal mencabut sesi",
+	"Failed to revoke sessions":          "Gagal mencabut sesi-sesi",
+	"User import scheduled":              "Impor pengguna dijadwalkan",
+	"User import completed":              "Impor pengguna selesai",
+	"User import retrieved successfully": "Impor pengguna berhasil diambil",
+	"User import not found":              "Impor pengguna tidak ditemukan",
+	"Invalid import ID":                  "ID impor tidak valid",
+	"Invalid import file":                "Berkas impor tidak valid",
+	"Failed to import users":             "Gagal mengimpor pengguna",
+	"Failed to get user import":          "Gagal mengambil impor pengguna",
+	"Password changed successfully":      "Kata sandi berhasil diubah",
+	"Failed to change password":          "Gagal mengubah kata sandi",
+	"Current password is incorrect":      "Kata sandi saat ini salah",
+
+	// Orders and payments
+	"Order processed successfully":                  "Pesanan berhasil diproses",
+	"Order accepted, payment pending":               "Pesanan diterima, menunggu pembayaran",
+	"Order already exists":                          "Pesanan sudah ada",
+	"Order cancelled successfully":                  "Pesanan berhasil dibatalkan",
+	"Order cannot be cancelled":                     "Pesanan tidak dapat dibatalkan",
+	"Order cannot be refunded":                      "Pesanan tidak dapat dikembalikan dananya",
+	"Order not found":                               "Pesanan tidak ditemukan",
+	"Order rejected":                                "Pesanan ditolak",
+	"Orders retrieved successfully":                 "Pesanan berhasil diambil",
+	"Invalid order":                                 "Pesanan tidak valid",
+	"Invalid order request":                         "Permintaan pesanan tidak valid",
+	"Invalid order filter":                          "Filter pesanan tidak valid",
+	"Invalid refund request":                        "Permintaan pengembalian dana tidak valid",
+	"Invalid payment intent request":                "Permintaan payment intent tidak valid",
+	"Failed to process order":                       "Gagal memproses pesanan",
+	"Failed to cancel order":                        "Gagal membatalkan pesanan",
+	"Failed to list orders":                         "Gagal menampilkan daftar pesanan",
+	"Failed to export orders":                       "Gagal mengekspor pesanan",
+	"Failed to process refund":                      "Gagal memproses pengembalian dana",
+	"Refund processed successfully":                 "Pengembalian dana berhasil diproses",
+	"Payment ID is required":                        "ID pembayaran wajib diisi",
+	"Payment not found":                             "Pembayaran tidak ditemukan",
+	"Payment status retrieved":                      "Status pembayaran berhasil diambil",
+	"Payment status retrieved successfully":         "Status pembayaran berhasil diambil",
+	"Failed to get payment status":                  "Gagal mengambil status pembayaran",
+	"Payments temporarily unavailable":              "Pembayaran sementara tidak tersedia",
+	"Payment event processed":                       "Peristiwa pembayaran telah diproses",
+	"Payment event already processed":               "Peristiwa pembayaran sudah pernah diproses",
+	"Payment event held until the order is paid":    "Peristiwa pembayaran ditahan hingga pesanan dibayar",
+	"Failed to process payment event":               "Gagal memproses peristiwa pembayaran",
+	"Event does not apply to the order":             "Peristiwa tidak berlaku untuk pesanan ini",
+	"Invalid request signature":                     "Tanda tangan permintaan tidak valid",
+	"Request signature required":                    "Tanda tangan permintaan diperlukan",
+	"Payment intent creation not fully implemented": "Pembuatan payment intent belum sepenuhnya diimplementasikan",
+
+	// Uploads and files
+	"Upload started successfully":          "Unggahan berhasil dimulai",
+	"Upload retrieved successfully":        "Unggahan berhasil diambil",
+	"Part uploaded successfully":           "Bagian berhasil diunggah",
+	"Upload completed successfully":        "Unggahan berhasil diselesaikan",
+	"Upload aborted successfully":          "Unggahan berhasil dibatalkan",
+	"Upload not found":                     "Unggahan tidak ditemukan",
+	"Invalid upload ID":                    "ID unggahan tidak valid",
+	"Invalid part number":                  "Nomor bagian tidak valid",
+	"Download link created successfully":   "Tautan unduhan berhasil dibuat",
+	"Invalid download link":                "Tautan unduhan tidak valid",
+	"File not found":                       "Berkas tidak ditemukan",
+	"File has not passed its malware scan": "Berkas belum lolos pemindaian malware",
+	"File was quarantined":                 "Berkas telah dikarantina",
+	"Failed to start upload":               "Gagal memulai unggahan",
+	"Failed to get upload":                 "Gagal mengambil unggahan",
+	"Failed to upload part":                "Gagal mengunggah bagian",
+	"Failed to complete upload":            "Gagal menyelesaikan unggahan",
+	"Failed to abort upload":               "Gagal membatalkan unggahan",
+	"Failed to get download link":          "Gagal mengambil tautan unduhan",
+	"Failed to download file":              "Gagal mengunduh berkas",
+
+	// Administration
+	"Coupon created successfully":             "Kupon berhasil dibuat",
+	"Coupon updated successfully":             "Kupon berhasil diperbarui",
+	"Coupon deleted successfully":             "Kupon berhasil dihapus",
+	"Coupon retrieved successfully":           "Kupon berhasil diambil",
+	"Coupons retrieved successfully":          "Kupon berhasil diambil",
+	"Coupon not found":                        "Kupon tidak ditemukan",
+	"Invalid coupon ID":                       "ID kupon tidak valid",
+	"Failed to create coupon":                 "Gagal membuat kupon",
+	"Failed to update coupon":                 "Gagal memperbarui kupon",
+	"Failed to delete coupon":                 "Gagal menghapus kupon",
+	"Failed to get coupon":                    "Gagal mengambil kupon",
+	"Failed to list coupons":                  "Gagal menampilkan daftar kupon",
+	"Dead letter jobs retrieved successfully": "Job dead letter berhasil diambil",
+	"Dead letter job retrieved successfully":  "Job dead letter berhasil diambil",
+	"Dead letter job not found":               "Job dead letter tidak ditemukan",
+	"Job requeued successfully":               "Job berhasil dimasukkan kembali ke antrean",
+	"Job discarded successfully":              "Job berhasil dibuang",
+	"Invalid job ID":                          "ID job tidak valid",
+	"Invalid page":                            "Halaman tidak valid",
+	"Invalid page_size":                       "page_size tidak valid",
+	"Failed to list dead letter jobs":         "Gagal menampilkan daftar job dead letter",
+	"Stats retrieved successfully":            "Statistik berhasil diambil",
+	"Failed to get stats":                     "Gagal mengambil statistik",
+	"Invalid days":                            "Jumlah hari tidak valid",
+	"Report retrieved successfully":           "Laporan berhasil diambil",
+	"Report rollup queued":                    "Rekap laporan dijadwalkan",
+	"Failed to get revenue report":            "Gagal mengambil laporan pendapatan",
+	"Failed to get sign-up report":            "Gagal mengambil laporan pendaftaran",
+	"Failed to queue report rollup":           "Gagal menjadwalkan rekap laporan",
+	"Invalid export":                          "Ekspor tidak valid",
+	"Invalid export ID":                       "ID ekspor tidak valid",
+	"Export accepted":                         "Ekspor diterima",
+	"Export retrieved successfully":           "Ekspor berhasil diambil",
+	"Export not found":                        "Ekspor tidak ditemukan",
+	"Failed to create export":                 "Gagal membuat ekspor",
+	"Failed to get export":                    "Gagal mengambil ekspor",
+	"Failed to get dead letter job":           "Gagal mengambil job dead letter",
+	"Failed to requeue job":                   "Gagal memasukkan kembali job ke antrean",
+	"Failed to discard job":                   "Gagal membuang job",
+	"Campaign accepted":                       "Kampanye diterima",
+	"Campaign retrieved successfully":         "Kampanye berhasil diambil",
+	"Campaign not found":                      "Kampanye tidak ditemukan",
+	"Invalid campaign ID":                     "ID kampanye tidak valid",
+	"Failed to create campaign":               "Gagal membuat kampanye",
+	"Failed to get campaign":                  "Gagal mengambil kampanye",
+
+	// Configuration
+	"Configuration retrieved successfully":         "Konfigurasi berhasil diambil",
+	"Provider profile retrieved successfully":      "Profil penyedia berhasil diambil",
+	"Provider profile applied successfully":        "Profil penyedia berhasil diterapkan",
+	"Invalid provider settings":                    "Pengaturan penyedia tidak valid",
+	"Failed to apply provider profile":             "Gagal menerapkan profil penyedia",
+	"Configuration changes retrieved successfully": "Perubahan konfigurasi berhasil diambil",
+	"Failed to list configuration changes":         "Gagal mengambil perubahan konfigurasi",
+	"Provider usage retrieved successfully":        "Penggunaan penyedia berhasil diambil",
+	"Failed to get provider usage":                 "Gagal mengambil penggunaan penyedia",
+
+	// Requests
+	"Invalid request body":   "Isi permintaan tidak valid",
+	"Invalid request format": "Format permintaan tidak valid",
+	"Request body too large": "Isi permintaan terlalu besar",
+	"Rate limit exceeded":    "Batas permintaan terlampaui",
+	"Server is overloaded":   "Server sedang sibuk",
+	"Internal server error":  "Terjadi kesalahan pada server",
+	"Not found":              "Tidak ditemukan",
+
+	// Error details
+	"missing authorization header":                "header authorization tidak ada",
+	"expected Bearer token":                       "token Bearer diperlukan",
+	"session has been revoked or expired":         "sesi telah dicabut atau kedaluwarsa",
+	"too many requests":                           "terlalu banyak permintaan",
+	"request shed, retry later":                   "permintaan ditolak, coba lagi nanti",
+	"an unexpected error occurred":                "terjadi kesalahan yang tidak terduga",
+	"user not found":                              "pengguna tidak ditemukan",
+	"user already exists":                         "pengguna sudah ada",
+	"invalid credentials":                         "kredensial tidak valid",
+	"unauthorized":                                "tidak diizinkan",
+	"session not found":                           "sesi tidak ditemukan",
+	"order not found":                             "pesanan tidak ditemukan",
+	"order already exists":                        "pesanan sudah ada",
+	"coupon not found":                            "kupon tidak ditemukan",
+	"campaign not found":                          "kampanye tidak ditemukan",
+	"invalid phone number":                        "nomor telepon tidak valid",
+	"phone number already in use":                 "nomor telepon sudah digunakan",
+	"no phone number to verify":                   "tidak ada nomor telepon untuk diverifikasi",
+	"invalid or expired one-time code":            "kode sekali pakai tidak valid atau kedaluwarsa",
+	"too many one-time code requests or attempts": "terlalu banyak permintaan atau percobaan kode sekali pakai",
+	"file not found":                              "berkas tidak ditemukan",
+	"upload not found":                            "unggahan tidak ditemukan",
+	"invalid or expired signed URL":               "URL bertanda tangan tidak valid atau kedaluwarsa",
+	"missing signature headers":                   "header tanda tangan tidak ada",
+	"unknown signing key":                         "kunci penandatanganan tidak dikenal",
+	"request already processed":                   "permintaan sudah diproses",
+	"order could not be accepted":                 "pesanan tidak dapat diterima",
+	"payment_id parameter is missing":             "parameter payment_id tidak ada",
+	"user_id not found in token":                  "user_id tidak ditemukan di token",
+
+	// Validation
+	"%s is required":                    "%s wajib diisi",
+	"%s must be a valid email address":  "%s harus berupa alamat email yang valid",
+	"%s must be at least %s characters": "%s minimal %s karakter",
+	"%s must be at most %s characters":  "%s maksimal %s karakter",
+	"%s must have at least %s items":    "%s minimal berisi %s item",
+	"%s must have at most %s items":     "%s maksimal berisi %s item",
+	"%s must be exactly %s characters":  "%s harus tepat %s karakter",
+	"%s must be at least %s":            "%s minimal %s",
+	"%s must be at most %s":             "%s maksimal %s",
+	"%s must be greater than %s":        "%s harus lebih besar dari %s",
+	"%s must be less than %s":           "%s harus lebih kecil dari %s",
+	"%s must be one of: %s":             "%s harus salah satu dari: %s",
+	"%s is invalid":                     "%s tidak valid",
+	"request body must be valid JSON":   "isi permintaan harus berupa JSON yang valid",
+	"%s must be a %s":                   "%s harus bertipe %s",
+
+	// Password policy
+	"password must be at least %d characters":          "kata sandi minimal %d karakter",
+	"password must be at most %d bytes":                "kata sandi maksimal %d byte",
+	"password must contain an uppercase letter":        "kata sandi harus berisi huruf besar",
+	"password must contain a lowercase letter":         "kata sandi harus berisi huruf kecil",
+	"password must contain a digit":                    "kata sandi harus berisi angka",
+	"password must contain a symbol":                   "kata sandi harus berisi simbol",
+	"password is too common":                           "kata sandi terlalu umum",
+	"password must not resemble the username or email": "kata sandi tidak boleh mirip dengan nama pengguna atau email",
+	"password must differ from the current password":   "kata sandi harus berbeda dari kata sandi saat ini",
+
+	// Username policy
+	"username must be at least %d characters":           "nama pengguna minimal %d karakter",
+	"username must be at most %d characters":            "nama pengguna maksimal %d karakter",
+	"username contains characters that are not allowed": "nama pengguna berisi karakter yang tidak diizinkan",
+	"username is reserved":                              "nama pengguna sudah dicadangkan",
+	"username contains a word that is not allowed":      "nama pengguna berisi kata yang tidak diizinkan",
+}
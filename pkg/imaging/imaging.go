@@ -0,0 +1,126 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // register the GIF decoder
+	"image/jpeg"
+	_ "image/png" // register the PNG decoder
+)
+
+var (
+	// ErrUnsupportedFormat is returned for data that is not a JPEG, PNG or GIF image.
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	// ErrTooLarge is returned for images with more pixels than allowed.
+	ErrTooLarge = errors.New("image dimensions too large")
+)
+
+// Formats are the image formats Decode accepts, by name as reported by image.Decode.
+var Formats = map[string]string{
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+	"gif":  "image/gif",
+}
+
+// Decode decodes a JPEG, PNG or GIF image and returns it with its format name.
+// The dimensions are checked before decoding so an image that decompresses to
+// more than maxPixels is rejected without allocating it.
+func Decode(data []byte, maxPixels int) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupportedFormat
+	}
+	if _, ok := Formats[format]; !ok {
+		return nil, "", ErrUnsupportedFormat
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, "", ErrUnsupportedFormat
+	}
+	if maxPixels > 0 && config.Width*config.Height > maxPixels {
+		return nil, "", fmt.Errorf("%w: %dx%d", ErrTooLarge, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	return img, format, nil
+}
+
+// Thumbnail crops the center square of the image and resizes it to size x size.
+// Transparent areas are flattened onto white. Downscaling averages the source
+// pixels covered by each output pixel, so thumbnails stay sharp without aliasing.
+func Thumbnail(src image.Image, size int) *image.RGBA {
+	bounds := src.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2,
+	))
+
+	// Flatten the crop onto white so every pixel is opaque
+	flat := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, crop.Min, draw.Over)
+
+	return resize(flat, size)
+}
+
+// resize scales a square image to size x size with a box filter.
+func resize(src *image.RGBA, size int) *image.RGBA {
+	side := src.Bounds().Dx()
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	for y := 0; y < size; y++ {
+		y0, y1 := span(y, side, size)
+		for x := 0; x < size; x++ {
+			x0, x1 := span(x, side, size)
+
+			var r, g, b, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4:]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					b += uint64(p[2])
+					n++
+				}
+			}
+
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0] = uint8(r / n)
+			d[1] = uint8(g / n)
+			d[2] = uint8(b / n)
+			d[3] = 0xff
+		}
+	}
+	return dst
+}
+
+// span returns the source pixel range [from, to) covered by output pixel i.
+func span(i, src, dst int) (int, int) {
+	from := i * src / dst
+	to := (i + 1) * src / dst
+	if to <= from {
+		to = from + 1
+	}
+	return from, to
+}
+
+// EncodeJPEG encodes the image as a JPEG. Re-encoding also drops any metadata
+// such as EXIF location data from the upload.
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("failed to encode jpeg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,62 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodePNG returns a PNG whose left half is red and right half transparent.
+func encodePNG(t *testing.T, width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width/2; x++ {
+			img.Set(x, y, color.NRGBA{R: 0xff, A: 0xff})
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	img, format, err := Decode(encodePNG(t, 40, 20), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, 40, img.Bounds().Dx())
+
+	_, _, err = Decode(encodePNG(t, 40, 30), 1000)
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, _, err = Decode([]byte("<svg xmlns='http://www.w3.org/2000/svg'/>"), 1000)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestThumbnail(t *testing.T) {
+	img, _, err := Decode(encodePNG(t, 300, 100), 0)
+	require.NoError(t, err)
+
+	// The center square spans both halves: red on the left, white (flattened) on the right
+	thumb := Thumbnail(img, 10)
+	assert.Equal(t, image.Rect(0, 0, 10, 10), thumb.Bounds())
+	assert.Equal(t, color.RGBA{R: 0xff, A: 0xff}, thumb.RGBAAt(0, 5))
+	assert.Equal(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, thumb.RGBAAt(9, 5))
+
+	// Small images are scaled up
+	assert.Equal(t, 64, Thumbnail(img, 64).Bounds().Dx())
+	small, _, err := Decode(encodePNG(t, 8, 8), 0)
+	require.NoError(t, err)
+	assert.Equal(t, 64, Thumbnail(small, 64).Bounds().Dx())
+
+	data, err := EncodeJPEG(thumb, 85)
+	require.NoError(t, err)
+	_, format, err := Decode(data, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+}
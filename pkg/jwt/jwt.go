@@ -1,46 +1,47 @@
 package jwt
 
 import (
-	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Claims are the claims of an access token. Locale is the language the user
+// prefers responses in. Actor is set on impersonation tokens.
 type Claims struct {
+	UserID   int                    `json:"user_id"`
+	Username string                 `json:"username"`
+	Scopes   []string               `json:"scopes,omitempty"`
+	TenantID string                 `json:"tenant_id,omitempty"`
+	Locale   string                 `json:"locale,omitempty"`
+	Custom   map[string]interface{} `json:"ext,omitempty"`
+	Actor    *Actor                 `json:"act,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Actor is the user acting as the subject of an impersonation token, after
+// the actor claim of RFC 8693.
+type Actor struct {
 	UserID   int    `json:"user_id"`
 	Username string `json:"username"`
-	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID int, username, secretKey string, expiryTime time.Duration) (string, error) {
-	claims := &Claims{
-		UserID:   userID,
-		Username: username,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiryTime)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+// HasScope reports whether the claims grant the scope.
+func (c *Claims) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
 	}
+	return false
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secretKey))
+// GenerateToken signs an HS256 token with a shared secret.
+func GenerateToken(userID int, username, tokenID, secretKey string, expiryTime time.Duration) (string, error) {
+	return NewHMACKeySet(secretKey).GenerateToken(userID, username, tokenID, expiryTime)
 }
 
+// ValidateToken validates an HS256 token signed with a shared secret.
 func ValidateToken(tokenString, secretKey string) (*Claims, error) {
-	claims := &Claims{}
-
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secretKey), nil
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	if !token.Valid {
-		return nil, errors.New("invalid token")
-	}
-
-	return claims, nil
+	return NewHMACKeySet(secretKey).ValidateToken(tokenString)
 }
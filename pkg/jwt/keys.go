@@ -0,0 +1,237 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var (
+	ErrUnknownKeyID         = errors.New("unknown key id")
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+)
+
+// KeySet holds the keys used to sign and verify tokens.
+// Tokens are signed with the active key; every key in the set is accepted for
+// verification, which allows rotating keys without invalidating issued tokens.
+type KeySet struct {
+	method     jwt.SigningMethod
+	activeKID  string
+	signingKey interface{}
+	verifyKeys map[string]interface{}
+	issuer     string
+	audience   []string
+}
+
+// JWK is a JSON Web Key as served from the JWKS endpoint.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewHMACKeySet creates a key set signing with a shared HS256 secret.
+func NewHMACKeySet(secretKey string) *KeySet {
+	return &KeySet{
+		method:     jwt.SigningMethodHS256,
+		signingKey: []byte(secretKey),
+		verifyKeys: map[string]interface{}{"": []byte(secretKey)},
+	}
+}
+
+// NewKeySetFromPEM creates an asymmetric key set from PEM encoded private keys indexed by key ID.
+func NewKeySetFromPEM(algorithm, activeKID string, privateKeys map[string][]byte) (*KeySet, error) {
+	ks := &KeySet{
+		activeKID:  activeKID,
+		verifyKeys: make(map[string]interface{}, len(privateKeys)),
+	}
+
+	for kid, pemData := range privateKeys {
+		var (
+			private interface{}
+			public  interface{}
+		)
+
+		switch algorithm {
+		case AlgorithmRS256:
+			ks.method = jwt.SigningMethodRS256
+			key, err := jwt.ParseRSAPrivateKeyFromPEM(pemData)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse RSA key %q: %w", kid, err)
+			}
+			private, public = key, &key.PublicKey
+		case AlgorithmEdDSA:
+			ks.method = jwt.SigningMethodEdDSA
+			key, err := jwt.ParseEdPrivateKeyFromPEM(pemData)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse Ed25519 key %q: %w", kid, err)
+			}
+			private, public = key, key.(crypto.Signer).Public()
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+		}
+
+		ks.verifyKeys[kid] = public
+		if kid == activeKID {
+			ks.signingKey = private
+		}
+	}
+
+	if ks.signingKey == nil {
+		return nil, fmt.Errorf("%w: active key %q not loaded", ErrUnknownKeyID, activeKID)
+	}
+
+	return ks, nil
+}
+
+// WithClaimsValidation sets the issuer stamped on new tokens and the issuer and
+// audience required when validating. Empty values disable the respective check.
+func (k *KeySet) WithClaimsValidation(issuer string, audience []string) *KeySet {
+	k.issuer = issuer
+	k.audience = audience
+	return k
+}
+
+// GenerateToken signs a token for the user with the active key.
+func (k *KeySet) GenerateToken(userID int, username, tokenID string, expiryTime time.Duration) (string, error) {
+	return k.Sign(&Claims{
+		UserID:   userID,
+		Username: username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID: tokenID,
+		},
+	}, expiryTime)
+}
+
+// Sign signs the claims with the active key, filling in the issuer, audience
+// and timestamps when they are not already set.
+func (k *KeySet) Sign(claims *Claims, expiryTime time.Duration) (string, error) {
+	now := time.Now()
+	if claims.Issuer == "" {
+		claims.Issuer = k.issuer
+	}
+	if len(claims.Audience) == 0 && len(k.audience) > 0 {
+		claims.Audience = jwt.ClaimStrings(k.audience)
+	}
+	if claims.IssuedAt == nil {
+		claims.IssuedAt = jwt.NewNumericDate(now)
+	}
+	if claims.ExpiresAt == nil {
+		claims.ExpiresAt = jwt.NewNumericDate(now.Add(expiryTime))
+	}
+
+	token := jwt.NewWithClaims(k.method, claims)
+	if k.activeKID != "" {
+		token.Header["kid"] = k.activeKID
+	}
+	return token.SignedString(k.signingKey)
+}
+
+// ValidateToken parses the token and verifies it against the key named by its kid header.
+func (k *KeySet) ValidateToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods([]string{k.method.Alg()})}
+	if k.issuer != "" {
+		options = append(options, jwt.WithIssuer(k.issuer))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			kid = k.activeKID
+		}
+
+		key, ok := k.verifyKeys[kid]
+		if !ok {
+			return nil, ErrUnknownKeyID
+		}
+		return key, nil
+	}, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	if len(k.audience) > 0 && !audienceMatches(claims.Audience, k.audience) {
+		return nil, jwt.ErrTokenInvalidAudience
+	}
+
+	return claims, nil
+}
+
+// audienceMatches reports whether any token audience is among the accepted ones.
+func audienceMatches(tokenAudience jwt.ClaimStrings, accepted []string) bool {
+	for _, aud := range tokenAudience {
+		for _, want := range accepted {
+			if aud == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// JWKS returns the public keys of the set. Symmetric keys are never published.
+func (k *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0, len(k.verifyKeys))}
+
+	kids := make([]string, 0, len(k.verifyKeys))
+	for kid := range k.verifyKeys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	for _, kid := range kids {
+		switch key := k.verifyKeys[kid].(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     kid,
+				Use:       "sig",
+				Algorithm: AlgorithmRS256,
+				N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     kid,
+				Use:       "sig",
+				Algorithm: AlgorithmEdDSA,
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(key),
+			})
+		}
+	}
+
+	return jwks
+}
@@ -0,0 +1,115 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pkcs8PEM(t testing.TB, key interface{}) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestKeySet_AsymmetricRoundTrip(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		algorithm string
+		pem       []byte
+		keyType   string
+	}{
+		{name: "RS256", algorithm: AlgorithmRS256, pem: pkcs8PEM(t, rsaKey), keyType: "RSA"},
+		{name: "EdDSA", algorithm: AlgorithmEdDSA, pem: pkcs8PEM(t, edKey), keyType: "OKP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := NewKeySetFromPEM(tt.algorithm, "key-1", map[string][]byte{"key-1": tt.pem})
+			require.NoError(t, err)
+
+			token, err := keys.GenerateToken(1, "testuser", "token-1", time.Hour)
+			require.NoError(t, err)
+
+			claims, err := keys.ValidateToken(token)
+			require.NoError(t, err)
+			assert.Equal(t, 1, claims.UserID)
+			assert.Equal(t, "token-1", claims.ID)
+
+			jwks := keys.JWKS()
+			require.Len(t, jwks.Keys, 1)
+			assert.Equal(t, "key-1", jwks.Keys[0].KeyID)
+			assert.Equal(t, tt.keyType, jwks.Keys[0].KeyType)
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	before, err := NewKeySetFromPEM(AlgorithmRS256, "old", map[string][]byte{"old": pkcs8PEM(t, oldKey)})
+	require.NoError(t, err)
+	token, err := before.GenerateToken(1, "testuser", "token-1", time.Hour)
+	require.NoError(t, err)
+
+	after, err := NewKeySetFromPEM(AlgorithmRS256, "new", map[string][]byte{
+		"old": pkcs8PEM(t, oldKey),
+		"new": pkcs8PEM(t, newKey),
+	})
+	require.NoError(t, err)
+
+	_, err = after.ValidateToken(token)
+	assert.NoError(t, err, "tokens signed with a retired key remain valid while it is in the set")
+
+	retired, err := NewKeySetFromPEM(AlgorithmRS256, "new", map[string][]byte{"new": pkcs8PEM(t, newKey)})
+	require.NoError(t, err)
+	_, err = retired.ValidateToken(token)
+	assert.ErrorIs(t, err, ErrUnknownKeyID)
+}
+
+func TestKeySet_HMACNotPublished(t *testing.T) {
+	keys := NewHMACKeySet("secret")
+
+	token, err := keys.GenerateToken(1, "testuser", "token-1", time.Hour)
+	require.NoError(t, err)
+
+	_, err = keys.ValidateToken(token)
+	assert.NoError(t, err)
+	assert.Empty(t, keys.JWKS().Keys)
+}
+
+func TestKeySet_IssuerAndAudience(t *testing.T) {
+	issuer := NewHMACKeySet("secret").WithClaimsValidation("boilerplate-api", []string{"orders"})
+
+	token, err := issuer.Sign(&Claims{UserID: 1, Scopes: []string{"orders:read"}, TenantID: "acme"}, time.Hour)
+	require.NoError(t, err)
+
+	claims, err := issuer.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "boilerplate-api", claims.Issuer)
+	assert.True(t, claims.HasScope("orders:read"))
+	assert.Equal(t, "acme", claims.TenantID)
+
+	otherAudience := NewHMACKeySet("secret").WithClaimsValidation("boilerplate-api", []string{"billing"})
+	_, err = otherAudience.ValidateToken(token)
+	assert.Error(t, err)
+
+	otherIssuer := NewHMACKeySet("secret").WithClaimsValidation("someone-else", nil)
+	_, err = otherIssuer.ValidateToken(token)
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"boilerplate-go/pkg/perfbudget"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Every authenticated request validates a token
+const (
+	hmacValidationBudget = 50 * time.Microsecond
+	rsaValidationBudget  = 500 * time.Microsecond
+)
+
+func benchmarkValidateToken(keys *KeySet) func(b *testing.B) {
+	return func(b *testing.B) {
+		token, err := keys.GenerateToken(1, "testuser", "token-1", time.Hour)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := keys.ValidateToken(token); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func rsaKeySet(tb testing.TB) *KeySet {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(tb, err)
+	keys, err := NewKeySetFromPEM(AlgorithmRS256, "key-1", map[string][]byte{"key-1": pkcs8PEM(tb, key)})
+	require.NoError(tb, err)
+	return keys
+}
+
+func BenchmarkValidateToken_HMAC(b *testing.B) {
+	benchmarkValidateToken(NewHMACKeySet("benchmark-secret"))(b)
+}
+
+func BenchmarkValidateToken_RS256(b *testing.B) {
+	benchmarkValidateToken(rsaKeySet(b))(b)
+}
+
+func TestValidateToken_LatencyBudget(t *testing.T) {
+	t.Run("HMAC", func(t *testing.T) {
+		perfbudget.Check(t, hmacValidationBudget, benchmarkValidateToken(NewHMACKeySet("benchmark-secret")))
+	})
+	t.Run("RS256", func(t *testing.T) {
+		perfbudget.Check(t, rsaValidationBudget, benchmarkValidateToken(rsaKeySet(t)))
+	})
+}
@@ -0,0 +1,86 @@
+// Package lock provides named locks shared by the instances of the service,
+// such as to run a reconciliation on one instance at a time or to process an
+// order on one of them. Locks are held for a TTL at most, so a lock of an
+// instance that died is eventually taken again.
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	minRetryDelay = 25 * time.Millisecond
+	maxRetryDelay = time.Second
+)
+
+var (
+	// ErrNotAcquired is returned when the lock is held by another owner
+	ErrNotAcquired = errors.New("lock held by another owner")
+	// ErrNotHeld is returned by Release when the lock expired before it
+	ErrNotHeld = errors.New("lock expired before it was released")
+)
+
+// Locker acquires named locks
+type Locker interface {
+	// Acquire takes the lock of name for at most ttl without waiting. It
+	// returns ErrNotAcquired when the lock is held.
+	Acquire(ctx context.Context, name string, ttl time.Duration) (Lock, error)
+}
+
+// Lock is an acquired lock
+type Lock interface {
+	// Release releases the lock. It returns ErrNotHeld when the lock had
+	// expired, in which case another owner may have held it meanwhile.
+	Release(ctx context.Context) error
+	// Refresh holds the lock for ttl from now. It returns ErrNotHeld when the
+	// lock had expired, in which case the lock is no longer held.
+	Refresh(ctx context.Context, ttl time.Duration) error
+}
+
+// Options tell WithLock how long to hold a lock and to wait for it
+type Options struct {
+	// TTL is how long the lock is held at most, when fn runs longer
+	TTL time.Duration
+	// Wait is how long to wait for a held lock; zero tries once
+	Wait time.Duration
+}
+
+// WithLock runs fn holding the lock of name, waiting up to opts.Wait for it.
+// It returns ErrNotAcquired when the lock is still held after that, or fn's
+// error. A lock that fails to be released is left to expire.
+func WithLock(ctx context.Context, locker Locker, name string, opts Options, fn func(ctx context.Context) error) error {
+	l, err := acquire(ctx, locker, name, opts)
+	if err != nil {
+		return err
+	}
+	defer l.Release(context.WithoutCancel(ctx))
+
+	return fn(ctx)
+}
+
+// acquire retries acquiring the lock, backing off, until it is taken or
+// opts.Wait ran out
+func acquire(ctx context.Context, locker Locker, name string, opts Options) (Lock, error) {
+	deadline := time.Now().Add(opts.Wait)
+	delay := minRetryDelay
+	for {
+		l, err := locker.Acquire(ctx, name, opts.TTL)
+		if !errors.Is(err, ErrNotAcquired) {
+			return l, err
+		}
+		if time.Until(deadline) < delay {
+			return nil, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+		delay = min(2*delay, maxRetryDelay)
+	}
+}
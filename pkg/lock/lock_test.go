@@ -0,0 +1,170 @@
+package lock
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"boilerplate-go/pkg/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_Acquire(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+
+	l, err := m.Acquire(ctx, "order:1", time.Minute)
+	require.NoError(t, err)
+	_, err = m.Acquire(ctx, "order:1", time.Minute)
+	assert.ErrorIs(t, err, ErrNotAcquired)
+	_, err = m.Acquire(ctx, "order:2", time.Minute)
+	assert.NoError(t, err, "locks are kept by name")
+
+	require.NoError(t, l.Release(ctx))
+	assert.ErrorIs(t, l.Release(ctx), ErrNotHeld)
+	_, err = m.Acquire(ctx, "order:1", time.Minute)
+	assert.NoError(t, err)
+}
+
+func TestMemory_Expires(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+
+	expired, err := m.Acquire(ctx, "reconcile", time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = m.Acquire(ctx, "reconcile", time.Minute)
+	require.NoError(t, err, "an expired lock is taken again")
+	assert.ErrorIs(t, expired.Release(ctx), ErrNotHeld)
+	_, err = m.Acquire(ctx, "reconcile", time.Minute)
+	assert.ErrorIs(t, err, ErrNotAcquired, "releasing an expired lock leaves the new owner's alone")
+}
+
+func TestWithLock(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+
+	held, err := m.Acquire(ctx, "order:1", time.Minute)
+	require.NoError(t, err)
+
+	ran := false
+	err = WithLock(ctx, m, "order:1", Options{TTL: time.Minute}, func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrNotAcquired)
+	assert.False(t, ran)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		held.Release(ctx)
+	}()
+	err = WithLock(ctx, m, "order:1", Options{TTL: time.Minute, Wait: time.Second}, func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	require.NoError(t, err)
+	assert.True(t, ran, "waits for the lock to be released")
+
+	_, err = m.Acquire(ctx, "order:1", time.Minute)
+	assert.NoError(t, err, "released after fn")
+}
+
+// serveRedis answers the commands read from a connection with handle
+func serveRedis(t *testing.T, handle func(args []string) string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					// A command is an array of bulk strings, each a length line and a value line
+					header, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					n, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+					args := make([]string, n)
+					for i := range args {
+						if _, err := r.ReadString('\n'); err != nil {
+							return
+						}
+						value, err := r.ReadString('\n')
+						if err != nil {
+							return
+						}
+						args[i] = strings.TrimSuffix(value, "\r\n")
+					}
+					conn.Write([]byte(handle(args)))
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestRedis(t *testing.T) {
+	keys := make(chan string, 10)
+	addr := serveRedis(t, func(args []string) string {
+		switch args[0] {
+		case "SET":
+			keys <- args[1]
+			if args[1] == "lock:held" {
+				return "$-1\r\n"
+			}
+			return "+OK\r\n"
+		case "EVALSHA":
+			return ":1\r\n"
+		default:
+			return "-ERR unknown command\r\n"
+		}
+	})
+	locker := NewRedis(redis.New(redis.Options{Addr: addr, Timeout: time.Second}), "lock:")
+	defer locker.Close()
+	ctx := context.Background()
+
+	l, err := locker.Acquire(ctx, "order:1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "lock:order:1", <-keys)
+	assert.NoError(t, l.Release(ctx))
+
+	_, err = locker.Acquire(ctx, "held", time.Minute)
+	assert.ErrorIs(t, err, ErrNotAcquired)
+}
+
+func TestAdvisoryKey(t *testing.T) {
+	assert.Equal(t, advisoryKey("order:1"), advisoryKey("order:1"))
+	assert.NotEqual(t, advisoryKey("order:1"), advisoryKey("order:2"))
+}
+
+func TestMemory_Refresh(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+
+	l, err := m.Acquire(ctx, "leader", 10*time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, l.Refresh(ctx, time.Minute))
+	time.Sleep(20 * time.Millisecond)
+	_, err = m.Acquire(ctx, "leader", time.Minute)
+	assert.ErrorIs(t, err, ErrNotAcquired, "a refreshed lock is held for the new TTL")
+
+	expired, err := m.Acquire(ctx, "stalled", time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	assert.ErrorIs(t, expired.Refresh(ctx, time.Minute), ErrNotHeld)
+}
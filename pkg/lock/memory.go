@@ -0,0 +1,63 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory keeps locks in memory, shared only by the callers in the process,
+// for a single instance and tests
+type Memory struct {
+	mu    sync.Mutex
+	locks map[string]*memoryLock
+}
+
+// NewMemory creates a locker keeping its locks in memory
+func NewMemory() *Memory {
+	return &Memory{locks: make(map[string]*memoryLock)}
+}
+
+// Acquire takes the lock of name unless it is held and has not expired
+func (m *Memory) Acquire(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if held, ok := m.locks[name]; ok && time.Now().Before(held.expires) {
+		return nil, ErrNotAcquired
+	}
+	l := &memoryLock{memory: m, name: name, expires: time.Now().Add(ttl)}
+	m.locks[name] = l
+	return l, nil
+}
+
+type memoryLock struct {
+	memory  *Memory
+	name    string
+	expires time.Time
+}
+
+func (l *memoryLock) Release(ctx context.Context) error {
+	l.memory.mu.Lock()
+	defer l.memory.mu.Unlock()
+
+	if l.memory.locks[l.name] != l {
+		return ErrNotHeld
+	}
+	delete(l.memory.locks, l.name)
+	if time.Now().After(l.expires) {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+func (l *memoryLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	l.memory.mu.Lock()
+	defer l.memory.mu.Unlock()
+
+	if l.memory.locks[l.name] != l || time.Now().After(l.expires) {
+		return ErrNotHeld
+	}
+	l.expires = time.Now().Add(ttl)
+	return nil
+}
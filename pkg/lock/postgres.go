@@ -0,0 +1,109 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Postgres takes session advisory locks, keyed by a 64-bit hash of the name,
+// on a connection held out of the pool while the lock is. A lock is released
+// after its TTL, or by PostgreSQL when the connection of a dead instance
+// drops.
+type Postgres struct {
+	db *sql.DB
+}
+
+// NewPostgres creates a locker taking advisory locks in db
+func NewPostgres(db *sql.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+// Acquire takes the advisory lock of name unless another session holds it
+func (p *Postgres) Acquire(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := advisoryKey(name)
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		discard(conn)
+		return nil, err
+	}
+	if !acquired {
+		conn.Close()
+		return nil, ErrNotAcquired
+	}
+
+	l := &postgresLock{conn: conn, key: key}
+	l.expiry = time.AfterFunc(ttl, func() {
+		l.unlock(context.Background())
+	})
+	return l, nil
+}
+
+// advisoryKey hashes the name of a lock to a key of pg_try_advisory_lock
+func advisoryKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// discard closes the connection instead of returning it to the pool, where it
+// could still hold the lock
+func discard(conn *sql.Conn) {
+	conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	conn.Close()
+}
+
+type postgresLock struct {
+	conn   *sql.Conn
+	key    int64
+	expiry *time.Timer
+	once   sync.Once
+}
+
+func (l *postgresLock) Release(ctx context.Context) error {
+	if !l.expiry.Stop() {
+		// The lock expired, or is being released as it does
+		return ErrNotHeld
+	}
+	return l.unlock(ctx)
+}
+
+// Refresh checks that the session holding the lock is alive and moves the
+// expiry of the lock
+func (l *postgresLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	if !l.expiry.Stop() {
+		return ErrNotHeld
+	}
+	if err := l.conn.PingContext(ctx); err != nil {
+		// The lock is gone with the session, or cannot be told to be held
+		l.unlock(context.Background())
+		return err
+	}
+	l.expiry.Reset(ttl)
+	return nil
+}
+
+// unlock releases the lock once and returns the connection to the pool
+func (l *postgresLock) unlock(ctx context.Context) error {
+	var err error
+	l.once.Do(func() {
+		var released bool
+		if err = l.conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1)", l.key).Scan(&released); err != nil {
+			discard(l.conn)
+			return
+		}
+		if !released {
+			err = ErrNotHeld
+		}
+		l.conn.Close()
+	})
+	return err
+}
@@ -0,0 +1,92 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"boilerplate-go/pkg/redis"
+)
+
+// releaseLock deletes the lock at KEYS[1] if it still holds ARGV[1], so a lock
+// that expired and was taken by another owner is left alone
+var releaseLock = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// refreshLock sets the expiry of the lock at KEYS[1] to ARGV[2] milliseconds
+// if it still holds ARGV[1]
+var refreshLock = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Redis keeps locks in a single Redis server as keys holding a random token
+// of their owner, expiring after the TTL. Unlike Redlock it does not survive
+// the loss of that server: a failover may let two owners hold a lock.
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedis creates a locker keeping its locks under keys starting with prefix
+func NewRedis(client *redis.Client, prefix string) *Redis {
+	return &Redis{client: client, prefix: prefix}
+}
+
+// Acquire sets the key of the lock unless it exists
+func (r *Redis) Acquire(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+	l := &redisLock{client: r.client, key: r.prefix + name, token: hex.EncodeToString(token)}
+
+	reply, err := r.client.Do(ctx, "SET", l.key, l.token, "NX", "PX", max(ttl.Milliseconds(), 1))
+	if err != nil {
+		return nil, err
+	}
+	if reply != "OK" {
+		return nil, ErrNotAcquired
+	}
+	return l, nil
+}
+
+// Close closes the connections to Redis
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
+
+type redisLock struct {
+	client *redis.Client
+	key    string
+	token  string
+}
+
+func (l *redisLock) Release(ctx context.Context) error {
+	reply, err := releaseLock.Run(ctx, l.client, []string{l.key}, l.token)
+	if err != nil {
+		return err
+	}
+	if reply != int64(1) {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+func (l *redisLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	reply, err := refreshLock.Run(ctx, l.client, []string{l.key}, l.token, max(ttl.Milliseconds(), 1))
+	if err != nil {
+		return err
+	}
+	if reply != int64(1) {
+		return ErrNotHeld
+	}
+	return nil
+}
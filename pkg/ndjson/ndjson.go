@@ -0,0 +1,44 @@
+// Package ndjson writes newline-delimited JSON, one value per line, so
+// clients can process long listings as they arrive.
+package ndjson
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// ContentType is the media type of NDJSON streams
+const ContentType = "application/x-ndjson"
+
+// Writer writes values as lines of JSON, buffering them until Flush
+type Writer struct {
+	w   io.Writer
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+// NewWriter returns a Writer writing to w
+func NewWriter(w io.Writer) *Writer {
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	return &Writer{w: w, buf: buf, enc: enc}
+}
+
+// Write writes v as one line
+func (w *Writer) Write(v interface{}) error {
+	return w.enc.Encode(v)
+}
+
+// Flush writes the buffered lines to the underlying writer, and sends them
+// to the client when it is an http.Flusher
+func (w *Writer) Flush() error {
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	if flusher, ok := w.w.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+	return nil
+}
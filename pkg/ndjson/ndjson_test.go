@@ -0,0 +1,23 @@
+package ndjson
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	w := httptest.NewRecorder()
+	writer := NewWriter(w)
+
+	require.NoError(t, writer.Write(map[string]interface{}{"id": 1, "name": "<a & b>"}))
+	require.NoError(t, writer.Write(map[string]interface{}{"id": 2, "name": nil}))
+	assert.Empty(t, w.Body.String(), "lines are buffered until flushed")
+	assert.False(t, w.Flushed)
+
+	require.NoError(t, writer.Flush())
+	assert.Equal(t, "{\"id\":1,\"name\":\"<a & b>\"}\n{\"id\":2,\"name\":null}\n", w.Body.String())
+	assert.True(t, w.Flushed)
+}
@@ -0,0 +1,55 @@
+// Package netguard keeps requests to user supplied URLs off the private
+// network of the service: connections are only made to public addresses,
+// checked after the host name is resolved so DNS cannot point past it.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrNotPublic is returned when dialing an address that is not public
+var ErrNotPublic = errors.New("address is not public")
+
+// sharedAddressSpace is the carrier-grade NAT range, private in practice
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Public reports whether ip is a public unicast address: not private,
+// loopback, link-local (where cloud metadata services listen), multicast or
+// unspecified.
+func Public(ip net.IP) bool {
+	return ip != nil && !ip.IsPrivate() && !ip.IsLoopback() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsMulticast() && !ip.IsUnspecified() && !sharedAddressSpace.Contains(ip)
+}
+
+// Control refuses connections to addresses that are not public. It is the
+// Control of a net.Dialer.
+func Control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if !Public(net.ParseIP(host)) {
+		return fmt.Errorf("%w: %s", ErrNotPublic, host)
+	}
+	return nil
+}
+
+// Client returns an HTTP client that only connects to public addresses.
+// Redirects are checked like the first request. Proxies are not used, as
+// they would connect on the client's behalf.
+func Client(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   Control,
+	}).DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,48 @@
+package netguard
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublic(t *testing.T) {
+	tests := []struct {
+		ip       string
+		expected bool
+	}{
+		{ip: "93.184.216.34", expected: true},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", expected: true},
+		{ip: "10.0.0.1"},
+		{ip: "192.168.1.1"},
+		{ip: "127.0.0.1"},
+		{ip: "::1"},
+		{ip: "169.254.169.254"},
+		{ip: "fe80::1"},
+		{ip: "100.64.0.1"},
+		{ip: "0.0.0.0"},
+		{ip: "224.0.0.1"},
+		{ip: "::ffff:127.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Public(net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestClient_RefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := Client(time.Second).Get(server.URL)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrNotPublic)
+}
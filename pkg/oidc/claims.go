@@ -0,0 +1,48 @@
+package oidc
+
+import "fmt"
+
+// Claims are the standard claims of an ID token identifying the user
+type Claims struct {
+	Subject           string
+	Email             string
+	EmailVerified     *bool
+	Name              string
+	GivenName         string
+	FamilyName        string
+	PreferredUsername string
+	Nonce             string
+	// Raw holds every claim, for mapping other claims to local attributes
+	Raw map[string]interface{}
+}
+
+func newClaims(raw map[string]interface{}) *Claims {
+	claims := &Claims{Raw: raw}
+	claims.Subject = claims.String("sub")
+	claims.Email = claims.String("email")
+	claims.Name = claims.String("name")
+	claims.GivenName = claims.String("given_name")
+	claims.FamilyName = claims.String("family_name")
+	claims.PreferredUsername = claims.String("preferred_username")
+	claims.Nonce = claims.String("nonce")
+	// Some providers send booleans as strings
+	switch verified := raw["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = &verified
+	case string:
+		b := verified == "true"
+		claims.EmailVerified = &b
+	}
+	return claims
+}
+
+// String returns the claim as a string, or "" when it is missing or an object
+func (c *Claims) String(name string) string {
+	switch v := c.Raw[name].(type) {
+	case string:
+		return v
+	case float64, bool:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"boilerplate-go/pkg/oidc"
+	"boilerplate-go/pkg/oidc/oidctest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_AuthorizationCodeFlow(t *testing.T) {
+	provider := oidctest.NewProvider("app")
+	defer provider.Close()
+	ctx := context.Background()
+
+	client := oidc.NewClient(oidc.Config{
+		Issuer:       provider.URL + "/",
+		ClientID:     "app",
+		ClientSecret: "secret",
+		RedirectURL:  "https://app.example.com/sso/callback",
+	})
+
+	verifier, err := oidc.RandomString(32)
+	require.NoError(t, err)
+	authURL, err := client.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	require.NoError(t, err)
+
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, provider.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "state-1", u.Query().Get("state"))
+	assert.Equal(t, oidc.Challenge(verifier), u.Query().Get("code_challenge"))
+	assert.Equal(t, "openid profile email", u.Query().Get("scope"))
+
+	code, err := provider.Authorize(authURL, map[string]interface{}{
+		"sub":            "user-1",
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"given_name":     "Alice",
+	})
+	require.NoError(t, err)
+
+	t.Run("wrong verifier", func(t *testing.T) {
+		code, err := provider.Authorize(authURL, map[string]interface{}{"sub": "user-1"})
+		require.NoError(t, err)
+		_, err = client.Exchange(ctx, code, "other-verifier")
+		assert.ErrorIs(t, err, oidc.ErrProvider)
+	})
+
+	tokens, err := client.Exchange(ctx, code, verifier)
+	require.NoError(t, err)
+
+	t.Run("nonce mismatch", func(t *testing.T) {
+		_, err := client.VerifyIDToken(ctx, tokens.IDToken, "nonce-2")
+		assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+	})
+
+	t.Run("another audience", func(t *testing.T) {
+		other := oidc.NewClient(oidc.Config{Issuer: provider.URL, ClientID: "other"})
+		_, err := other.VerifyIDToken(ctx, tokens.IDToken, "nonce-1")
+		assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+	})
+
+	claims, err := client.VerifyIDToken(ctx, tokens.IDToken, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, "alice@example.com", claims.Email)
+	assert.Equal(t, "Alice", claims.GivenName)
+	require.NotNil(t, claims.EmailVerified)
+	assert.True(t, *claims.EmailVerified)
+}
+
+func TestClient_DiscoverIssuerMismatch(t *testing.T) {
+	provider := oidctest.NewProvider("app")
+	defer provider.Close()
+
+	client := oidc.NewClient(oidc.Config{Issuer: provider.URL + "/tenant", ClientID: "app"})
+	_, err := client.Discover(context.Background())
+	assert.ErrorIs(t, err, oidc.ErrProvider)
+}
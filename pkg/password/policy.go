@@ -0,0 +1,216 @@
+// Package password checks passwords against a configurable policy: length,
+// character classes, a list of banned common passwords and similarity to the
+// account's username or email. Every violation is reported, with a code for
+// clients and an English message that can be translated.
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"boilerplate-go/pkg/errors"
+)
+
+// MaxLength is the longest password, in bytes, bcrypt hashes. Longer ones are
+// rejected rather than silently truncated.
+const MaxLength = 72
+
+// Violation codes
+const (
+	CodeTooShort         = "too_short"
+	CodeTooLong          = "too_long"
+	CodeMissingUppercase = "missing_uppercase"
+	CodeMissingLowercase = "missing_lowercase"
+	CodeMissingDigit     = "missing_digit"
+	CodeMissingSymbol    = "missing_symbol"
+	CodeCommon           = "common"
+	CodeSimilar          = "similar_to_account"
+	CodeReused           = "reused"
+)
+
+// minSimilarLength is the shortest part of a username or email compared with
+// the password; shorter parts would match too many passwords
+const minSimilarLength = 3
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// Violation is a requirement a password does not meet. Message is an English
+// format string for Args, so it can be translated before formatting.
+type Violation struct {
+	Code    string
+	Message string
+	Args    []interface{}
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf(v.Message, v.Args...)
+}
+
+// PolicyError reports the requirements a password does not meet. It matches
+// errors.ErrWeakPassword.
+type PolicyError struct {
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.String()
+	}
+	return fmt.Sprintf("%s: %s", errors.ErrWeakPassword, strings.Join(messages, "; "))
+}
+
+func (e *PolicyError) Unwrap() error {
+	return errors.ErrWeakPassword
+}
+
+// Policy is the requirements passwords must meet. The zero value only
+// enforces MaxLength.
+type Policy struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// RejectSimilar rejects passwords containing, or contained in, the
+	// username or the email address
+	RejectSimilar bool
+
+	banned map[string]bool
+}
+
+// DefaultPolicy requires 8 characters, rejects the built-in common passwords
+// and passwords similar to the account, without character class rules.
+func DefaultPolicy() *Policy {
+	p := &Policy{MinLength: 8, RejectSimilar: true}
+	p.BanCommon()
+	return p
+}
+
+// BanCommon rejects the built-in list of common passwords
+func (p *Policy) BanCommon() {
+	// The embedded list is valid; reading a string cannot fail
+	_ = p.BanList(strings.NewReader(commonPasswords))
+}
+
+// Ban rejects the passwords, compared case-insensitively
+func (p *Policy) Ban(passwords ...string) {
+	if p.banned == nil {
+		p.banned = make(map[string]bool, len(passwords))
+	}
+	for _, password := range passwords {
+		p.banned[strings.ToLower(password)] = true
+	}
+}
+
+// BanList rejects the passwords in r, one per line. Blank lines and lines
+// starting with # are skipped.
+func (p *Policy) BanList(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.Ban(line)
+	}
+	return scanner.Err()
+}
+
+// Check returns a *PolicyError listing every requirement the password does
+// not meet, or nil. identifiers are the username and email address of the
+// account, compared with the password when RejectSimilar is set.
+func (p *Policy) Check(password string, identifiers ...string) error {
+	if violations := p.Violations(password, identifiers...); len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// Violations returns the requirements the password does not meet
+func (p *Policy) Violations(password string, identifiers ...string) []Violation {
+	var violations []Violation
+	add := func(code, message string, args ...interface{}) {
+		violations = append(violations, Violation{Code: code, Message: message, Args: args})
+	}
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		add(CodeTooShort, "password must be at least %d characters", p.MinLength)
+	}
+	if len(password) > MaxLength {
+		add(CodeTooLong, "password must be at most %d bytes", MaxLength)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUppercase && !upper {
+		add(CodeMissingUppercase, "password must contain an uppercase letter")
+	}
+	if p.RequireLowercase && !lower {
+		add(CodeMissingLowercase, "password must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		add(CodeMissingDigit, "password must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		add(CodeMissingSymbol, "password must contain a symbol")
+	}
+
+	if p.isBanned(password) {
+		add(CodeCommon, "password is too common")
+	}
+	if p.RejectSimilar && isSimilar(password, identifiers) {
+		add(CodeSimilar, "password must not resemble the username or email")
+	}
+	return violations
+}
+
+// isBanned reports whether the password is banned, also with trailing digits
+// and symbols removed, so "monkey123!" is rejected like "monkey"
+func (p *Policy) isBanned(password string) bool {
+	if len(p.banned) == 0 {
+		return false
+	}
+	lower := strings.ToLower(password)
+	base := strings.TrimRightFunc(lower, func(r rune) bool { return !unicode.IsLetter(r) })
+	return p.banned[lower] || (base != "" && p.banned[base])
+}
+
+// isSimilar reports whether the password contains or is contained in an
+// identifier, or the local part of an email address
+func isSimilar(password string, identifiers []string) bool {
+	lower := strings.ToLower(password)
+	for _, identifier := range identifiers {
+		identifier = strings.ToLower(strings.TrimSpace(identifier))
+		parts := []string{identifier}
+		if local, _, ok := strings.Cut(identifier, "@"); ok {
+			parts = append(parts, local)
+		}
+		for _, part := range parts {
+			if len(part) < minSimilarLength {
+				continue
+			}
+			if strings.Contains(lower, part) || (len(lower) >= minSimilarLength && strings.Contains(part, lower)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"boilerplate-go/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func codes(violations []Violation) []string {
+	result := make([]string, len(violations))
+	for i, v := range violations {
+		result[i] = v.Code
+	}
+	return result
+}
+
+func TestPolicy_Violations(t *testing.T) {
+	strict := DefaultPolicy()
+	strict.MinLength = 10
+	strict.RequireUppercase = true
+	strict.RequireLowercase = true
+	strict.RequireDigit = true
+	strict.RequireSymbol = true
+
+	tests := []struct {
+		name     string
+		policy   *Policy
+		password string
+		expected []string
+	}{
+		{name: "strong", policy: DefaultPolicy(), password: "correct horse battery", expected: []string{}},
+		{name: "too short", policy: DefaultPolicy(), password: "xk7#q", expected: []string{CodeTooShort}},
+		{name: "length in characters", policy: DefaultPolicy(), password: "ŝŝŝŝŝŝŝŝ", expected: []string{}},
+		{name: "too long for bcrypt", policy: DefaultPolicy(), password: strings.Repeat("a", MaxLength+1), expected: []string{CodeTooLong}},
+		{name: "common", policy: DefaultPolicy(), password: "Password", expected: []string{CodeCommon}},
+		{name: "common with suffix", policy: DefaultPolicy(), password: "Monkey2024!", expected: []string{CodeCommon}},
+		{name: "contains username", policy: DefaultPolicy(), password: "janedoe-rocks", expected: []string{CodeSimilar}},
+		{name: "contains email local part", policy: DefaultPolicy(), password: "xx-jane.doe-xx", expected: []string{CodeSimilar}},
+		{
+			name:     "character classes",
+			policy:   strict,
+			password: "lowercase only here",
+			expected: []string{CodeMissingUppercase, CodeMissingDigit},
+		},
+		{
+			name:     "every class missing",
+			policy:   strict,
+			password: "",
+			expected: []string{CodeTooShort, CodeMissingUppercase, CodeMissingLowercase, CodeMissingDigit, CodeMissingSymbol},
+		},
+		{name: "zero policy", policy: &Policy{}, password: "janedoe", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := tt.policy.Violations(tt.password, "janedoe", "jane.doe@example.com")
+			assert.Equal(t, tt.expected, codes(violations))
+		})
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	policy := DefaultPolicy()
+	require.NoError(t, policy.Check("correct horse battery", "janedoe"))
+
+	err := policy.Check("qwerty", "janedoe")
+	require.Error(t, err)
+	assert.True(t, errors.IsWeakPassword(err))
+	var policyErr *PolicyError
+	require.ErrorAs(t, err, &policyErr)
+	assert.Equal(t, []string{CodeTooShort, CodeCommon}, codes(policyErr.Violations))
+	assert.Equal(t, "password does not meet the requirements: password must be at least 8 characters; password is too common", err.Error())
+}
+
+func TestPolicy_BanList(t *testing.T) {
+	policy := &Policy{}
+	require.NoError(t, policy.BanList(strings.NewReader("# company words\n\nAcmeCorp\n  widgets  \n")))
+
+	assert.Equal(t, []string{CodeCommon}, codes(policy.Violations("acmecorp")))
+	assert.Equal(t, []string{CodeCommon}, codes(policy.Violations("Widgets99")))
+	assert.Empty(t, policy.Violations("password"), "the built-in list is not banned")
+}
@@ -0,0 +1,34 @@
+// Package perfbudget enforces latency budgets in tests: an operation is
+// benchmarked and the test fails when it takes longer on average than its
+// budget, so a regression is caught in CI rather than in production.
+//
+// Budgets leave room for slow CI machines and coverage instrumentation; they
+// catch regressions of several times, not a few percent. They are not checked
+// with -short or the race detector, whose overhead makes timings meaningless.
+package perfbudget
+
+import (
+	"testing"
+	"time"
+)
+
+// Check benchmarks op and fails t when one operation takes longer than budget
+func Check(t *testing.T, budget time.Duration, op func(b *testing.B)) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("latency budgets are not checked in short mode")
+	}
+	if raceEnabled {
+		t.Skip("latency budgets are not checked with the race detector")
+	}
+
+	result := testing.Benchmark(op)
+	if result.N == 0 {
+		t.Fatal("benchmark did not run")
+	}
+	perOp := time.Duration(result.NsPerOp())
+	t.Logf("%s per operation, budget %s", perOp, budget)
+	if perOp > budget {
+		t.Errorf("%s per operation is over the budget of %s", perOp, budget)
+	}
+}
@@ -0,0 +1,368 @@
+// Package postman builds Postman collections (format v2.1) of an API from its
+// Swagger 2.0 document and mounted routes. Insomnia imports the same format.
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// SchemaURL identifies the collection format
+const SchemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// Variables of every collection: the API base URL and the bearer token sent
+// to authenticated routes
+const (
+	BaseURLVariable = "baseUrl"
+	TokenVariable   = "token"
+)
+
+// Collection is a Postman collection of requests grouped in folders
+type Collection struct {
+	Info     Info       `json:"info"`
+	Item     []Item     `json:"item"`
+	Variable []Variable `json:"variable,omitempty"`
+}
+
+// Info names a collection
+type Info struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// Item is a request, or a folder of requests
+type Item struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Item        []Item   `json:"item,omitempty"`
+	Request     *Request `json:"request,omitempty"`
+}
+
+// Request is an example request of a route
+type Request struct {
+	Method string   `json:"method"`
+	Header []Header `json:"header"`
+	Auth   *Auth    `json:"auth,omitempty"`
+	Body   *Body    `json:"body,omitempty"`
+	URL    URL      `json:"url"`
+}
+
+// Header is a request header. Optional ones are disabled.
+type Header struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// Auth is the authentication of a request
+type Auth struct {
+	Type   string     `json:"type"`
+	Bearer []Variable `json:"bearer,omitempty"`
+}
+
+// Body is a raw JSON or multipart form request body
+type Body struct {
+	Mode     string      `json:"mode"`
+	Raw      string      `json:"raw,omitempty"`
+	Formdata []FormParam `json:"formdata,omitempty"`
+	Options  *Options    `json:"options,omitempty"`
+}
+
+// FormParam is a multipart form field
+type FormParam struct {
+	Key         string `json:"key"`
+	Value       string `json:"value,omitempty"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+}
+
+// Options tells Postman the language of a raw body
+type Options struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+// URL is a request URL with its query and path parameters
+type URL struct {
+	Raw      string       `json:"raw"`
+	Host     []string     `json:"host"`
+	Path     []string     `json:"path"`
+	Query    []QueryParam `json:"query,omitempty"`
+	Variable []Variable   `json:"variable,omitempty"`
+}
+
+// QueryParam is a query parameter. Optional ones are disabled.
+type QueryParam struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// Variable is a collection variable, path parameter or auth attribute
+type Variable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Route is a mounted route to include in a collection
+type Route struct {
+	Method string
+	// Path uses gin syntax, with :name parameters
+	Path string
+	// Authenticated routes send the bearer token variable
+	Authenticated bool
+	// Notes are added to the description, such as the scopes the route requires
+	Notes []string
+}
+
+// Build returns a collection of the routes, described by the operations of
+// the Swagger 2.0 document where they are documented. Requests are grouped in
+// folders named after their first tag; undocumented routes go to "other".
+// Routes with wildcards, such as static files, are left out.
+func Build(name string, swaggerDoc []byte, routes []Route) (*Collection, error) {
+	var doc document
+	if err := json.Unmarshal(swaggerDoc, &doc); err != nil {
+		return nil, fmt.Errorf("invalid swagger document: %w", err)
+	}
+
+	collection := &Collection{
+		Info: Info{Name: name, Description: doc.Info.Description, Schema: SchemaURL},
+		Item: []Item{},
+	}
+	if collection.Info.Name == "" {
+		collection.Info.Name = doc.Info.Title
+	}
+
+	folders := make(map[string]int)
+	for _, route := range routes {
+		if strings.Contains(route.Path, "*") {
+			continue
+		}
+		op := doc.Paths[swaggerPath(route.Path)][strings.ToLower(route.Method)]
+		item := doc.item(route, op)
+
+		folder := "other"
+		if op != nil && len(op.Tags) > 0 {
+			folder = op.Tags[0]
+		}
+		i, ok := folders[folder]
+		if !ok {
+			i = len(collection.Item)
+			folders[folder] = i
+			collection.Item = append(collection.Item, Item{Name: folder})
+		}
+		collection.Item[i].Item = append(collection.Item[i].Item, item)
+	}
+	sort.SliceStable(collection.Item, func(i, j int) bool {
+		return collection.Item[i].Name < collection.Item[j].Name
+	})
+	return collection, nil
+}
+
+// WithBaseURL returns a copy of the collection whose variables default to
+// the base URL and an empty token
+func (c *Collection) WithBaseURL(baseURL string) *Collection {
+	copied := *c
+	copied.Variable = []Variable{
+		{Key: BaseURLVariable, Value: strings.TrimSuffix(baseURL, "/"), Type: "string"},
+		{Key: TokenVariable, Value: "", Type: "string", Description: "Access token from POST /api/v1/auth/login"},
+	}
+	return &copied
+}
+
+// Requests returns the requests of the collection in folder order
+func (c *Collection) Requests() []Item {
+	var items []Item
+	for _, folder := range c.Item {
+		for _, item := range folder.Item {
+			if item.Request != nil {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+func (doc *document) item(route Route, op *operation) Item {
+	path := strings.Trim(route.Path, "/")
+	request := &Request{
+		Method: strings.ToUpper(route.Method),
+		Header: []Header{},
+		URL: URL{
+			Raw:  "{{" + BaseURLVariable + "}}/" + path,
+			Host: []string{"{{" + BaseURLVariable + "}}"},
+			Path: strings.Split(path, "/"),
+		},
+	}
+	item := Item{Name: request.Method + " " + route.Path, Request: request}
+
+	authenticated := route.Authenticated
+	var description []string
+	if op != nil {
+		if op.Summary != "" {
+			item.Name = op.Summary
+		}
+		if op.Description != "" {
+			description = append(description, op.Description)
+		} else if op.Summary != "" {
+			description = append(description, op.Summary)
+		}
+		authenticated = authenticated || len(op.Security) > 0
+		doc.addParameters(request, op.Parameters)
+	}
+	for _, segment := range request.URL.Path {
+		if name, ok := strings.CutPrefix(segment, ":"); ok && !request.URL.hasVariable(name) {
+			request.URL.Variable = append(request.URL.Variable, Variable{Key: name, Value: "1"})
+		}
+	}
+	if authenticated {
+		request.Auth = &Auth{
+			Type:   "bearer",
+			Bearer: []Variable{{Key: "token", Value: "{{" + TokenVariable + "}}", Type: "string"}},
+		}
+	}
+	if len(request.URL.Query) > 0 {
+		query := make([]string, 0, len(request.URL.Query))
+		for _, param := range request.URL.Query {
+			if !param.Disabled {
+				query = append(query, param.Key+"="+param.Value)
+			}
+		}
+		if len(query) > 0 {
+			request.URL.Raw += "?" + strings.Join(query, "&")
+		}
+	}
+
+	description = append(description, route.Notes...)
+	description = append(description, "Example:\n\n```sh\n"+Curl(request)+"\n```")
+	item.Description = strings.Join(description, "\n\n")
+	return item
+}
+
+func (u *URL) hasVariable(name string) bool {
+	for _, v := range u.Variable {
+		if v.Key == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (doc *document) addParameters(request *Request, params []parameter) {
+	for _, param := range params {
+		switch param.In {
+		case "path":
+			request.URL.Variable = append(request.URL.Variable, Variable{
+				Key:         param.Name,
+				Value:       exampleString(doc.example(&param.schema, 0)),
+				Description: param.Description,
+			})
+		case "query":
+			request.URL.Query = append(request.URL.Query, QueryParam{
+				Key:         param.Name,
+				Value:       exampleString(doc.example(&param.schema, 0)),
+				Description: param.Description,
+				Disabled:    !param.Required,
+			})
+		case "header":
+			request.Header = append(request.Header, Header{
+				Key:         param.Name,
+				Value:       exampleString(doc.example(&param.schema, 0)),
+				Description: param.Description,
+				Disabled:    !param.Required,
+			})
+		case "body":
+			raw, _ := json.MarshalIndent(doc.example(param.Schema, 0), "", "  ")
+			request.Header = append(request.Header, Header{Key: "Content-Type", Value: "application/json"})
+			request.Body = &Body{Mode: "raw", Raw: string(raw), Options: &Options{}}
+			request.Body.Options.Raw.Language = "json"
+		case "formData":
+			if request.Body == nil {
+				request.Body = &Body{Mode: "formdata"}
+			}
+			field := FormParam{Key: param.Name, Type: "text", Description: param.Description}
+			if param.Type == "file" {
+				field.Type = "file"
+			} else {
+				field.Value = exampleString(doc.example(&param.schema, 0))
+			}
+			request.Body.Formdata = append(request.Body.Formdata, field)
+		}
+	}
+}
+
+// Curl returns a curl command sending the request, reading the base URL and
+// token from the BASE_URL and TOKEN shell variables
+func Curl(request *Request) string {
+	url := request.URL.Raw
+	for _, v := range request.URL.Variable {
+		url = strings.ReplaceAll(url, ":"+v.Key, v.Value)
+	}
+	url = strings.Replace(url, "{{"+BaseURLVariable+"}}", "${BASE_URL}", 1)
+
+	lines := []string{"curl"}
+	if request.Method != http.MethodGet || request.Body != nil {
+		lines[0] += " -X " + request.Method
+	}
+	lines[0] += ` "` + url + `"`
+	if request.Auth != nil {
+		lines = append(lines, `-H "Authorization: Bearer ${TOKEN}"`)
+	}
+	for _, header := range request.Header {
+		if !header.Disabled {
+			lines = append(lines, "-H "+shellQuote(header.Key+": "+header.Value))
+		}
+	}
+	if body := request.Body; body != nil {
+		switch body.Mode {
+		case "raw":
+			lines = append(lines, "-d "+shellQuote(body.Raw))
+		case "formdata":
+			for _, field := range body.Formdata {
+				if field.Type == "file" {
+					lines = append(lines, "-F "+shellQuote(field.Key+"=@path/to/file"))
+				} else {
+					lines = append(lines, "-F "+shellQuote(field.Key+"="+field.Value))
+				}
+			}
+		}
+	}
+	return strings.Join(lines, " \\\n  ")
+}
+
+// Curl returns the curl commands of every request of the collection as a
+// shell script
+func (c *Collection) Curl() string {
+	var b strings.Builder
+	b.WriteString("# " + c.Info.Name + "\n")
+	b.WriteString("# Set BASE_URL and TOKEN (from POST /api/v1/auth/login) before running a command.\n")
+	for _, item := range c.Requests() {
+		b.WriteString("\n# " + item.Name + "\n")
+		b.WriteString(Curl(item.Request) + "\n")
+	}
+	return b.String()
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// swaggerPath converts a gin path to a Swagger path, :id becoming {id}
+func swaggerPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
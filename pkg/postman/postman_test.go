@@ -0,0 +1,125 @@
+package postman
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDoc = `{
+  "info": {"title": "Test API", "description": "An API"},
+  "paths": {
+    "/api/v1/orders/{id}/cancel": {
+      "post": {
+        "summary": "Cancel an order",
+        "tags": ["orders"],
+        "security": [{"BearerAuth": []}],
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "type": "string"},
+          {"name": "request", "in": "body", "required": true, "schema": {"$ref": "#/definitions/entity.CancelRequest"}}
+        ]
+      }
+    },
+    "/api/v1/orders": {
+      "get": {
+        "summary": "List orders",
+        "tags": ["orders"],
+        "security": [{"BearerAuth": []}],
+        "parameters": [
+          {"name": "status", "in": "query", "type": "string", "enum": ["pending", "paid"]},
+          {"name": "page", "in": "query", "required": true, "type": "integer"}
+        ]
+      }
+    }
+  },
+  "definitions": {
+    "entity.CancelRequest": {
+      "type": "object",
+      "properties": {
+        "reason": {"type": "string", "example": "Changed my mind"},
+        "notify": {"type": "boolean"},
+        "items": {"type": "array", "items": {"$ref": "#/definitions/entity.CancelRequest"}}
+      }
+    }
+  }
+}`
+
+func TestBuild(t *testing.T) {
+	collection, err := Build("", []byte(testDoc), []Route{
+		{Method: "GET", Path: "/api/v1/orders", Authenticated: true, Notes: []string{"Requires the token scopes: orders:read"}},
+		{Method: "POST", Path: "/api/v1/orders/:id/cancel", Authenticated: true},
+		{Method: "GET", Path: "/health"},
+		{Method: "GET", Path: "/swagger/*any"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Test API", collection.Info.Name)
+	assert.Equal(t, SchemaURL, collection.Info.Schema)
+	require.Len(t, collection.Item, 2, "wildcard routes are left out")
+	assert.Equal(t, "orders", collection.Item[0].Name)
+	assert.Equal(t, "other", collection.Item[1].Name)
+
+	t.Run("query parameters", func(t *testing.T) {
+		list := collection.Item[0].Item[0]
+		assert.Equal(t, "List orders", list.Name)
+		assert.Contains(t, list.Description, "Requires the token scopes: orders:read")
+		assert.Equal(t, "{{baseUrl}}/api/v1/orders?page=1", list.Request.URL.Raw)
+		assert.Equal(t, []QueryParam{
+			{Key: "status", Value: "pending", Disabled: true},
+			{Key: "page", Value: "1"},
+		}, list.Request.URL.Query)
+		require.NotNil(t, list.Request.Auth)
+		assert.Equal(t, "bearer", list.Request.Auth.Type)
+	})
+
+	t.Run("path parameters and body", func(t *testing.T) {
+		cancel := collection.Item[0].Item[1]
+		assert.Equal(t, []string{"api", "v1", "orders", ":id", "cancel"}, cancel.Request.URL.Path)
+		assert.Equal(t, []Variable{{Key: "id", Value: "string"}}, cancel.Request.URL.Variable)
+		require.NotNil(t, cancel.Request.Body)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(cancel.Request.Body.Raw), &body))
+		assert.Equal(t, "Changed my mind", body["reason"])
+		assert.Equal(t, false, body["notify"])
+		assert.Contains(t, body, "items", "self references stop at the depth limit")
+	})
+
+	t.Run("undocumented routes", func(t *testing.T) {
+		health := collection.Item[1].Item[0]
+		assert.Equal(t, "GET /health", health.Name)
+		assert.Nil(t, health.Request.Auth)
+	})
+
+	t.Run("curl examples", func(t *testing.T) {
+		cancel := collection.Item[0].Item[1]
+		assert.Equal(t, "curl -X POST \"${BASE_URL}/api/v1/orders/string/cancel\" \\\n"+
+			"  -H \"Authorization: Bearer ${TOKEN}\" \\\n"+
+			"  -H 'Content-Type: application/json' \\\n"+
+			"  -d '"+cancel.Request.Body.Raw+"'", Curl(cancel.Request))
+		assert.Contains(t, cancel.Description, Curl(cancel.Request))
+		assert.Contains(t, collection.Curl(), "# Cancel an order\ncurl -X POST")
+	})
+
+	t.Run("base URL", func(t *testing.T) {
+		withURL := collection.WithBaseURL("https://api.example.com/")
+		assert.Equal(t, "https://api.example.com", withURL.Variable[0].Value)
+		assert.Empty(t, collection.Variable, "the built collection is not changed")
+	})
+}
+
+func TestBuild_InvalidDocument(t *testing.T) {
+	_, err := Build("", []byte("not json"), nil)
+	assert.Error(t, err)
+}
+
+func TestCurl_QuotesBody(t *testing.T) {
+	request := &Request{
+		Method: "POST",
+		URL:    URL{Raw: "{{baseUrl}}/notes"},
+		Body:   &Body{Mode: "raw", Raw: `{"text":"it's"}`},
+	}
+	assert.Equal(t, "curl -X POST \"${BASE_URL}/notes\" \\\n  -d '{\"text\":\"it'\\''s\"}'", Curl(request))
+}
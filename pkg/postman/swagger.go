@@ -0,0 +1,127 @@
+package postman
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxExampleDepth bounds the nesting of example values, as definitions may
+// refer to themselves
+const maxExampleDepth = 5
+
+// document is the part of a Swagger 2.0 document a collection is built from
+type document struct {
+	Info struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	} `json:"info"`
+	Paths       map[string]map[string]*operation `json:"paths"`
+	Definitions map[string]*schema               `json:"definitions"`
+}
+
+type operation struct {
+	Summary     string                `json:"summary"`
+	Description string                `json:"description"`
+	Tags        []string              `json:"tags"`
+	Parameters  []parameter           `json:"parameters"`
+	Security    []map[string][]string `json:"security"`
+}
+
+// parameter is an operation parameter. Body parameters have a schema, the
+// others describe their type inline.
+type parameter struct {
+	schema
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Format     string             `json:"format"`
+	Enum       []interface{}      `json:"enum"`
+	Default    interface{}        `json:"default"`
+	Example    interface{}        `json:"example"`
+	Items      *schema            `json:"items"`
+	Properties map[string]*schema `json:"properties"`
+	AllOf      []*schema          `json:"allOf"`
+}
+
+// example returns an example value of the schema
+func (doc *document) example(s *schema, depth int) interface{} {
+	if s == nil || depth > maxExampleDepth {
+		return nil
+	}
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case s.Ref != "":
+		return doc.example(doc.Definitions[strings.TrimPrefix(s.Ref, "#/definitions/")], depth+1)
+	case len(s.AllOf) > 0:
+		merged := make(map[string]interface{})
+		for _, part := range s.AllOf {
+			if fields, ok := doc.example(part, depth+1).(map[string]interface{}); ok {
+				for k, v := range fields {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+
+	switch s.Type {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return "2024-01-01T00:00:00Z"
+		case "date":
+			return "2024-01-01"
+		case "email":
+			return "user@example.com"
+		}
+		return "string"
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "array":
+		if item := doc.example(s.Items, depth+1); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	}
+
+	fields := make(map[string]interface{}, len(s.Properties))
+	for name, property := range s.Properties {
+		fields[name] = doc.example(property, depth+1)
+	}
+	return fields
+}
+
+// exampleString formats an example value of a path, query, header or form
+// parameter
+func exampleString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = exampleString(item)
+		}
+		return strings.Join(parts, ",")
+	case int:
+		// Identifiers start at 1
+		if v == 0 {
+			return "1"
+		}
+	}
+	return fmt.Sprint(value)
+}
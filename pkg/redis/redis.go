@@ -0,0 +1,323 @@
+// Package redis is a minimal Redis client speaking RESP2 over a pool of
+// connections: enough to run commands and Lua scripts.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrClosed is returned by commands run after Close
+var ErrClosed = errors.New("redis: client closed")
+
+// Error is an error reply of the server, such as a wrong command. The
+// connection stays usable.
+type Error string
+
+func (e Error) Error() string { return string(e) }
+
+// Options configures the connections of a client
+type Options struct {
+	Addr     string
+	Username string
+	Password string
+	DB       int
+	// TLS connects over TLS, as rediss:// URLs do
+	TLS         bool
+	DialTimeout time.Duration
+	// Timeout bounds writing a command and reading its reply, unless the
+	// context has an earlier deadline
+	Timeout time.Duration
+	// PoolSize is the number of idle connections kept
+	PoolSize int
+}
+
+// ParseURL parses redis://[[user]:password@]host[:port][/db], or rediss://
+// for TLS, into options with default timeouts
+func ParseURL(rawURL string) (Options, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Options{}, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return Options{}, fmt.Errorf("invalid redis URL scheme %q", u.Scheme)
+	}
+
+	opts := Options{
+		Addr:        u.Host,
+		TLS:         u.Scheme == "rediss",
+		DialTimeout: 5 * time.Second,
+		Timeout:     3 * time.Second,
+		PoolSize:    10,
+	}
+	if u.Port() == "" {
+		opts.Addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		opts.Username = u.User.Username()
+		opts.Password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if opts.DB, err = strconv.Atoi(db); err != nil {
+			return Options{}, fmt.Errorf("invalid redis database %q", db)
+		}
+	}
+	return opts, nil
+}
+
+// Client runs commands on pooled connections. It is safe for concurrent use.
+type Client struct {
+	opts Options
+	idle chan *conn
+	done chan struct{}
+}
+
+// New creates a client. Connections are made when commands need them.
+func New(opts Options) *Client {
+	return &Client{
+		opts: opts,
+		idle: make(chan *conn, max(opts.PoolSize, 1)),
+		done: make(chan struct{}),
+	}
+}
+
+// Do runs a command and returns its reply: a string, an int64, nil, an Error
+// or a []interface{} of those. Error replies are returned as the error.
+func (c *Client) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := cn.do(ctx, c.opts.Timeout, args)
+	var replyErr Error
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection may hold half a reply
+		cn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// Close closes the idle connections. Commands in flight close theirs when
+// they complete.
+func (c *Client) Close() error {
+	select {
+	case <-c.done:
+		return nil
+	default:
+	}
+	close(c.done)
+	for {
+		select {
+		case cn := <-c.idle:
+			cn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	select {
+	case <-c.done:
+		return nil, ErrClosed
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: c.opts.DialTimeout}
+	var netConn net.Conn
+	var err error
+	if c.opts.TLS {
+		host, _, _ := net.SplitHostPort(c.opts.Addr)
+		netConn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}).DialContext(ctx, "tcp", c.opts.Addr)
+	} else {
+		netConn, err = dialer.DialContext(ctx, "tcp", c.opts.Addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cn := &conn{Conn: netConn, r: bufio.NewReader(netConn), w: bufio.NewWriter(netConn)}
+	if c.opts.Password != "" {
+		args := []interface{}{"AUTH", c.opts.Password}
+		if c.opts.Username != "" {
+			args = []interface{}{"AUTH", c.opts.Username, c.opts.Password}
+		}
+		if _, err := cn.do(ctx, c.opts.Timeout, args); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("redis authentication failed: %w", err)
+		}
+	}
+	if c.opts.DB != 0 {
+		if _, err := cn.do(ctx, c.opts.Timeout, []interface{}{"SELECT", c.opts.DB}); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("redis database selection failed: %w", err)
+		}
+	}
+	return cn, nil
+}
+
+func (c *Client) put(cn *conn) {
+	select {
+	case <-c.done:
+		cn.Close()
+		return
+	default:
+	}
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+}
+
+// conn is a connection with buffered reads and writes
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+func (cn *conn) do(ctx context.Context, timeout time.Duration, args []interface{}) (interface{}, error) {
+	deadline := time.Time{}
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+	if err := cn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if err := writeCommand(cn.w, args); err != nil {
+		return nil, err
+	}
+	if err := cn.w.Flush(); err != nil {
+		return nil, err
+	}
+	return readReply(cn.r)
+}
+
+// writeCommand writes args as an array of bulk strings
+func writeCommand(w *bufio.Writer, args []interface{}) error {
+	fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		var s string
+		switch v := arg.(type) {
+		case string:
+			s = v
+		case []byte:
+			s = string(v)
+		case int:
+			s = strconv.Itoa(v)
+		case int64:
+			s = strconv.FormatInt(v, 10)
+		case float64:
+			s = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			s = fmt.Sprint(v)
+		}
+		if _, err := fmt.Fprintf(w, "$%d\r\n%s\r\n", len(s), s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readReply reads a RESP2 reply
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, Error(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			item, err := readReply(r)
+			var replyErr Error
+			if err != nil && !errors.As(err, &replyErr) {
+				return nil, err
+			}
+			if err != nil {
+				item = replyErr
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unknown reply type %q", kind)
+	}
+}
+
+// Script is a Lua script run by its SHA1, loading it on first use
+type Script struct {
+	src  string
+	hash string
+}
+
+// NewScript creates a script from its source
+func NewScript(src string) *Script {
+	sum := sha1.Sum([]byte(src))
+	return &Script{src: src, hash: hex.EncodeToString(sum[:])}
+}
+
+// Run runs the script with keys and args, sending its source only when the
+// server does not have it cached
+func (s *Script) Run(ctx context.Context, c *Client, keys []string, args ...interface{}) (interface{}, error) {
+	command := make([]interface{}, 0, 3+len(keys)+len(args))
+	command = append(command, "EVALSHA", s.hash, len(keys))
+	for _, key := range keys {
+		command = append(command, key)
+	}
+	command = append(command, args...)
+
+	reply, err := c.Do(ctx, command...)
+	var replyErr Error
+	if errors.As(err, &replyErr) && strings.HasPrefix(string(replyErr), "NOSCRIPT") {
+		command[0], command[1] = "EVAL", s.src
+		return c.Do(ctx, command...)
+	}
+	return reply, err
+}
@@ -0,0 +1,293 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugorji/go/codec"
+)
+
+// Media types of the built-in encoders
+const (
+	JSONContentType    = "application/json"
+	XMLContentType     = "application/xml"
+	MsgPackContentType = "application/msgpack"
+	// ProblemXMLContentType is the media type of RFC 7807 error responses in XML
+	ProblemXMLContentType = "application/problem+xml"
+)
+
+// Encoder writes response bodies in a media type. Values are a Response or
+// ProblemDetails.
+type Encoder interface {
+	Encode(w io.Writer, v interface{}) error
+}
+
+// EncoderFunc adapts a function to an Encoder
+type EncoderFunc func(w io.Writer, v interface{}) error
+
+// Encode calls f(w, v)
+func (f EncoderFunc) Encode(w io.Writer, v interface{}) error {
+	return f(w, v)
+}
+
+var (
+	encodersMu sync.RWMutex
+	// encoders are the media types responses are available in besides JSON
+	encoders = map[string]Encoder{
+		XMLContentType:            EncoderFunc(encodeXML),
+		"text/xml":                EncoderFunc(encodeXML),
+		ProblemXMLContentType:     EncoderFunc(encodeXML),
+		MsgPackContentType:        EncoderFunc(encodeMsgPack),
+		"application/x-msgpack":   EncoderFunc(encodeMsgPack),
+		"application/vnd.msgpack": EncoderFunc(encodeMsgPack),
+	}
+)
+
+// RegisterEncoder makes responses available in the media type to clients
+// asking for it in their Accept header. Registering a media type again
+// replaces its encoder; JSON cannot be replaced.
+func RegisterEncoder(mediaType string, encoder Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[strings.ToLower(mediaType)] = encoder
+}
+
+// negotiate returns the media type and encoder of the response preferred by
+// the Accept header, or JSON with a nil encoder. Browsers asking for HTML
+// get JSON: they list XML above */* without meaning it.
+func negotiate(c *gin.Context) (string, Encoder) {
+	accept := c.GetHeader("Accept")
+	if accept == "" || strings.Contains(accept, "text/html") {
+		return JSONContentType, nil
+	}
+
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	best, bestQ := JSONContentType, 0.0
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+		if _, ok := encoders[mediaType]; ok || mediaType == JSONContentType || mediaType == "*/*" || mediaType == "application/*" {
+			best, bestQ = mediaType, q
+		}
+	}
+	if encoder, ok := encoders[best]; ok && best != JSONContentType {
+		return best, encoder
+	}
+	return JSONContentType, nil
+}
+
+// render writes the body in the media type the client prefers. contentType
+// overrides the media type of JSON responses, such as for problem details.
+func render(c *gin.Context, statusCode int, contentType string, body interface{}) {
+	if !varies(c.Writer.Header(), "Accept") {
+		c.Writer.Header().Add("Vary", "Accept")
+	}
+
+	mediaType, encoder := negotiate(c)
+	if encoder == nil {
+		if contentType != "" {
+			c.Header("Content-Type", contentType)
+		}
+		c.JSON(statusCode, body)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := encoder.Encode(&buf, body); err != nil {
+		// JSON encodes every response body
+		_ = c.Error(fmt.Errorf("encoding %s response: %w", mediaType, err))
+		c.JSON(statusCode, body)
+		return
+	}
+	_, problem := body.(ProblemDetails)
+	switch {
+	case problem && strings.HasSuffix(mediaType, "xml"):
+		mediaType = ProblemXMLContentType
+	case !problem && mediaType == ProblemXMLContentType:
+		mediaType = XMLContentType
+	}
+	if strings.HasSuffix(mediaType, "xml") {
+		mediaType += "; charset=utf-8"
+	}
+	c.Data(statusCode, mediaType, buf.Bytes())
+}
+
+// varies reports whether the Vary header lists the request header
+func varies(header http.Header, name string) bool {
+	for _, value := range header.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonModel returns v as decoded from its JSON encoding, so other formats
+// carry exactly the fields, names and values of JSON responses
+func jsonModel(v interface{}) (*json.Decoder, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec, nil
+}
+
+// encodeXML writes the JSON model of v as XML: objects become elements named
+// after their keys, array items <item> elements and null values empty
+// elements. Keys that are not XML names become <entry key="...">. Problem
+// details use the RFC 7807 <problem> element.
+func encodeXML(w io.Writer, v interface{}) error {
+	dec, err := jsonModel(v)
+	if err != nil {
+		return err
+	}
+
+	root := xml.StartElement{Name: xml.Name{Local: "response"}}
+	if _, ok := v.(ProblemDetails); ok {
+		root = xml.StartElement{Name: xml.Name{Space: "urn:ietf:rfc:7807", Local: "problem"}}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err := writeXMLValue(dec, enc, root); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+func writeXMLValue(dec *json.Decoder, enc *xml.Encoder, start xml.StartElement) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		for dec.More() {
+			child := xml.StartElement{Name: xml.Name{Local: "item"}}
+			if value == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child = xmlElement(key.(string))
+			}
+			if err := writeXMLValue(dec, enc, child); err != nil {
+				return err
+			}
+		}
+		// The closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	case string:
+		err = enc.EncodeToken(xml.CharData(value))
+	case json.Number:
+		err = enc.EncodeToken(xml.CharData(value.String()))
+	case bool:
+		err = enc.EncodeToken(xml.CharData(strconv.FormatBool(value)))
+	}
+	if err != nil {
+		return err
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// xmlElement returns the element of an object key
+func xmlElement(key string) xml.StartElement {
+	if isXMLName(key) {
+		return xml.StartElement{Name: xml.Name{Local: key}}
+	}
+	return xml.StartElement{
+		Name: xml.Name{Local: "entry"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: key}},
+	}
+}
+
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case i > 0 && (r == '-' || r == '.' || r >= '0' && r <= '9'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// msgpackHandle encodes strings with the current MessagePack spec and map
+// keys in sorted order
+var msgpackHandle = func() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{WriteExt: true}
+	h.Canonical = true
+	return h
+}()
+
+// encodeMsgPack writes the JSON model of v as MessagePack, with integers
+// kept as integers
+func encodeMsgPack(w io.Writer, v interface{}) error {
+	dec, err := jsonModel(v)
+	if err != nil {
+		return err
+	}
+	var model interface{}
+	if err := dec.Decode(&model); err != nil {
+		return err
+	}
+	return codec.NewEncoder(w, msgpackHandle).Encode(msgpackValue(model))
+}
+
+func msgpackValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			return n
+		}
+		f, _ := value.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = msgpackValue(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = msgpackValue(item)
+		}
+	}
+	return v
+}
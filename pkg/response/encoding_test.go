@@ -0,0 +1,141 @@
+package response
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+)
+
+type sample struct {
+	ID    int               `json:"id"`
+	Name  string            `json:"name"`
+	Price float64           `json:"price"`
+	Tags  []string          `json:"tags"`
+	Sizes map[string]string `json:"sizes,omitempty"`
+	Note  *string           `json:"note"`
+}
+
+func serve(t *testing.T, accept string, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", handler)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestNegotiation(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", "application/json; charset=utf-8"},
+		{"*/*", "application/json; charset=utf-8"},
+		{"application/json", "application/json; charset=utf-8"},
+		{"text/csv", "application/json; charset=utf-8"},
+		{"application/xml", "application/xml; charset=utf-8"},
+		{"text/xml", "text/xml; charset=utf-8"},
+		{"application/json;q=0.5, application/xml", "application/xml; charset=utf-8"},
+		{"application/xml;q=0.5, application/json", "application/json; charset=utf-8"},
+		{"application/msgpack", "application/msgpack"},
+		{"application/x-msgpack, */*;q=0.1", "application/x-msgpack"},
+		// Browsers list XML above */*
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "application/json; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			w := serve(t, tt.accept, func(c *gin.Context) { Success(c, http.StatusOK, "ok", nil) })
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestEncodeXML(t *testing.T) {
+	data := sample{ID: 7, Name: "Mug & co", Price: 9.5, Tags: []string{"a", "b"}, Sizes: map[string]string{"64": "small"}}
+	w := serve(t, "application/xml", func(c *gin.Context) { Success(c, http.StatusCreated, "Created", data) })
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "<?xml"))
+	assert.Contains(t, body, "<response><success>true</success><message>Created</message><data>")
+	assert.Contains(t, body, "<id>7</id><name>Mug &amp; co</name><price>9.5</price>")
+	assert.Contains(t, body, "<tags><item>a</item><item>b</item></tags>")
+	assert.Contains(t, body, `<sizes><entry key="64">small</entry></sizes><note></note>`)
+
+	t.Run("problem details", func(t *testing.T) {
+		w := serve(t, "application/xml", func(c *gin.Context) {
+			c.Set(ProblemDetailsKey, true)
+			Error(c, http.StatusNotFound, "Not found", "no such order")
+		})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "application/problem+xml; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `<problem xmlns="urn:ietf:rfc:7807"><type>about:blank</type><title>Not found</title><status>404</status>`)
+	})
+
+	t.Run("problem media type asked for a success", func(t *testing.T) {
+		w := serve(t, "application/problem+xml", func(c *gin.Context) { Success(c, http.StatusOK, "ok", nil) })
+		assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+	})
+}
+
+func TestEncodeMsgPack(t *testing.T) {
+	data := sample{ID: 7, Name: "Mug", Price: 9.5, Tags: []string{"a"}}
+	w := serve(t, "application/msgpack", func(c *gin.Context) { Success(c, http.StatusOK, "ok", data) })
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var decoded map[string]interface{}
+	handle := &codec.MsgpackHandle{}
+	handle.RawToString = true
+	require.NoError(t, codec.NewDecoderBytes(w.Body.Bytes(), handle).Decode(&decoded))
+
+	assert.Equal(t, true, decoded["success"])
+	item := decoded["data"].(map[interface{}]interface{})
+	assert.EqualValues(t, 7, item["id"])
+	assert.Equal(t, "Mug", item["name"])
+	assert.Equal(t, 9.5, item["price"])
+	assert.Nil(t, item["note"])
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	RegisterEncoder("text/plain", EncoderFunc(func(w io.Writer, v interface{}) error {
+		_, err := io.WriteString(w, v.(Response).Message)
+		return err
+	}))
+	defer func() {
+		encodersMu.Lock()
+		delete(encoders, "text/plain")
+		encodersMu.Unlock()
+	}()
+
+	w := serve(t, "text/plain", func(c *gin.Context) { Success(c, http.StatusOK, "plain text", nil) })
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, "plain text", w.Body.String())
+
+	// JSON stays built in
+	RegisterEncoder(JSONContentType, EncoderFunc(func(w io.Writer, v interface{}) error { return nil }))
+	defer func() {
+		encodersMu.Lock()
+		delete(encoders, JSONContentType)
+		encodersMu.Unlock()
+	}()
+	w = serve(t, "application/json", func(c *gin.Context) { Success(c, http.StatusOK, "ok", nil) })
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Success)
+}
@@ -0,0 +1,154 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"boilerplate-go/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// LocaleKey is the gin context key holding the locale of the response
+	LocaleKey = "locale"
+	// CatalogKey is the gin context key holding the *i18n.Catalog translating responses
+	CatalogKey = "i18n_catalog"
+)
+
+// SetLocale sets the locale responses to the request are written in
+func SetLocale(c *gin.Context, locale string) {
+	c.Set(LocaleKey, locale)
+	c.Header("Content-Language", locale)
+}
+
+// PreferLocale switches the response locale to a preference, such as the
+// locale saved by the user, when the catalog supports it.
+func PreferLocale(c *gin.Context, preference string) {
+	catalog := catalogFrom(c)
+	if catalog == nil || preference == "" {
+		return
+	}
+	if locale, ok := catalog.Match(preference); ok {
+		SetLocale(c, locale)
+	}
+}
+
+// Translate returns the message in the response locale. Without a catalog
+// the message is returned unchanged.
+func Translate(c *gin.Context, message string) string {
+	catalog := catalogFrom(c)
+	if catalog == nil || message == "" {
+		return message
+	}
+	return catalog.Translate(c.GetString(LocaleKey), message)
+}
+
+// Translatef translates a format and formats it with args
+func Translatef(c *gin.Context, format string, args ...interface{}) string {
+	catalog := catalogFrom(c)
+	if catalog == nil {
+		return fmt.Sprintf(format, args...)
+	}
+	return catalog.Translatef(c.GetString(LocaleKey), format, args...)
+}
+
+// InvalidRequest writes a 400 response for a request that failed binding.
+// Validation errors are listed per field, by JSON name and validation tag, in
+// the response locale; other binding errors are described by their message.
+func InvalidRequest(c *gin.Context, message string, err error) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			fields = append(fields, FieldError{
+				Field:   fieldErr.Field(),
+				Code:    fieldErr.Tag(),
+				Message: describeFieldError(c, fieldErr),
+			})
+		}
+		ValidationFailed(c, message, fields)
+		return
+	}
+	BadRequest(c, message, describeBindingError(c, err))
+}
+
+func describeBindingError(c *gin.Context, err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return Translatef(c, "%s must be a %s", typeErr.Field, typeErr.Type.Kind().String())
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return Translate(c, "request body must be valid JSON")
+	}
+	return err.Error()
+}
+
+// describeFieldError explains the validation tag a field failed
+func describeFieldError(c *gin.Context, fieldErr validator.FieldError) string {
+	field, param := fieldErr.Field(), fieldErr.Param()
+	kind := fieldErr.Kind()
+	sized := kind == reflect.String || kind == reflect.Slice || kind == reflect.Map || kind == reflect.Array
+
+	switch fieldErr.Tag() {
+	case "required", "required_if", "required_with", "required_without":
+		return Translatef(c, "%s is required", field)
+	case "email":
+		return Translatef(c, "%s must be a valid email address", field)
+	case "min", "gte":
+		if kind == reflect.String {
+			return Translatef(c, "%s must be at least %s characters", field, param)
+		}
+		if sized {
+			return Translatef(c, "%s must have at least %s items", field, param)
+		}
+		return Translatef(c, "%s must be at least %s", field, param)
+	case "max", "lte":
+		if kind == reflect.String {
+			return Translatef(c, "%s must be at most %s characters", field, param)
+		}
+		if sized {
+			return Translatef(c, "%s must have at most %s items", field, param)
+		}
+		return Translatef(c, "%s must be at most %s", field, param)
+	case "len":
+		if kind == reflect.String {
+			return Translatef(c, "%s must be exactly %s characters", field, param)
+		}
+	case "gt":
+		return Translatef(c, "%s must be greater than %s", field, param)
+	case "lt":
+		return Translatef(c, "%s must be less than %s", field, param)
+	case "oneof":
+		return Translatef(c, "%s must be one of: %s", field, strings.ReplaceAll(param, " ", ", "))
+	}
+	return Translatef(c, "%s is invalid", field)
+}
+
+// JSONFieldName names struct fields in validation errors by their JSON name,
+// for registering with the validator via RegisterTagNameFunc.
+func JSONFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+func catalogFrom(c *gin.Context) *i18n.Catalog {
+	value, ok := c.Get(CatalogKey)
+	if !ok {
+		return nil
+	}
+	catalog, _ := value.(*i18n.Catalog)
+	return catalog
+}
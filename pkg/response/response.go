@@ -2,33 +2,208 @@ package response
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// RequestIDHeader is the header carrying the request correlation ID
+	RequestIDHeader = "X-Request-ID"
+	// RequestStartKey is the gin context key holding the time the request started
+	RequestStartKey = "request_start"
+	// ProblemDetailsKey is the gin context key enabling RFC 7807 error responses
+	ProblemDetailsKey = "problem_details"
+	// ProblemJSONContentType is the media type of RFC 7807 error responses
+	ProblemJSONContentType = "application/problem+json"
+	// renderedKey is the gin context key holding the successful response
+	// rendered for the request
+	renderedKey = "response_rendered"
+)
+
 type Response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Data    interface{}  `json:"data,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Errors  []FieldError `json:"errors,omitempty"`
+	Meta    *Meta        `json:"meta,omitempty"`
+}
+
+// FieldError describes why a request field is invalid. Code identifies the
+// rule for clients, Message explains it in the response locale.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Meta carries request metadata used to correlate responses with server logs
+type Meta struct {
+	RequestID  string      `json:"request_id,omitempty"`
+	ServerTime time.Time   `json:"server_time"`
+	DurationMs int64       `json:"duration_ms"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// ProblemDetails is an RFC 7807 error response
+type ProblemDetails struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// Pagination describes the page returned in a list response
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	TotalItems int `json:"total_items"`
+	TotalPages int `json:"total_pages"`
+}
+
+// NewPagination builds pagination info from the page, page size and total item count
+func NewPagination(page, pageSize, totalItems int) *Pagination {
+	totalPages := 0
+	if pageSize > 0 {
+		totalPages = (totalItems + pageSize - 1) / pageSize
+	}
+	return &Pagination{
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+	}
+}
+
+// newMeta populates metadata from the request context
+func newMeta(c *gin.Context) *Meta {
+	now := time.Now()
+	meta := &Meta{
+		RequestID:  c.Writer.Header().Get(RequestIDHeader),
+		ServerTime: now.UTC(),
+	}
+	if meta.RequestID == "" {
+		meta.RequestID = c.GetHeader(RequestIDHeader)
+	}
+	if start, ok := c.Get(RequestStartKey); ok {
+		if startTime, ok := start.(time.Time); ok {
+			meta.DurationMs = now.Sub(startTime).Milliseconds()
+		}
+	}
+	return meta
 }
 
 func Success(c *gin.Context, statusCode int, message string, data interface{}) {
-	c.JSON(statusCode, Response{
+	renderSuccess(c, statusCode, Response{
+		Success: true,
+		Message: Translate(c, message),
+		Data:    data,
+		Meta:    newMeta(c),
+	})
+}
+
+// SuccessWithPagination writes a list response including pagination metadata
+func SuccessWithPagination(c *gin.Context, statusCode int, message string, data interface{}, pagination *Pagination) {
+	meta := newMeta(c)
+	meta.Pagination = pagination
+	renderSuccess(c, statusCode, Response{
 		Success: true,
-		Message: message,
+		Message: Translate(c, message),
 		Data:    data,
+		Meta:    meta,
 	})
 }
 
+// Rendered returns the successful response rendered for the request, such as
+// for a cache to keep and Replay later
+func Rendered(c *gin.Context) (Response, bool) {
+	value, ok := c.Get(renderedKey)
+	if !ok {
+		return Response{}, false
+	}
+	rendered, ok := value.(Response)
+	return rendered, ok
+}
+
+// Replay renders a successful response rendered for an earlier request with
+// the metadata of this one. Its pagination is kept.
+func Replay(c *gin.Context, statusCode int, rendered Response) {
+	meta := newMeta(c)
+	if rendered.Meta != nil {
+		meta.Pagination = rendered.Meta.Pagination
+	}
+	rendered.Meta = meta
+	render(c, statusCode, "", rendered)
+}
+
+// renderSuccess renders a successful response and keeps it for Rendered
+func renderSuccess(c *gin.Context, statusCode int, body Response) {
+	c.Set(renderedKey, body)
+	render(c, statusCode, "", body)
+}
+
 func Error(c *gin.Context, statusCode int, message string, err string) {
-	c.JSON(statusCode, Response{
+	if c.GetBool(ProblemDetailsKey) {
+		Problem(c, statusCode, message, err)
+		return
+	}
+
+	render(c, statusCode, "", Response{
 		Success: false,
-		Message: message,
-		Error:   err,
+		Message: Translate(c, message),
+		Error:   Translate(c, err),
+		Meta:    newMeta(c),
 	})
 }
 
+// ValidationFailed writes a 400 response listing the invalid fields. The
+// messages of fields are expected in the response locale already; the error
+// joins them for clients reading only the error string.
+func ValidationFailed(c *gin.Context, message string, fields []FieldError) {
+	details := make([]string, len(fields))
+	for i, field := range fields {
+		details[i] = field.Message
+	}
+	detail := strings.Join(details, "; ")
+
+	if c.GetBool(ProblemDetailsKey) {
+		problem := newProblem(c, http.StatusBadRequest, message, detail)
+		problem.Errors = fields
+		render(c, http.StatusBadRequest, ProblemJSONContentType, problem)
+		return
+	}
+
+	render(c, http.StatusBadRequest, "", Response{
+		Success: false,
+		Message: Translate(c, message),
+		Error:   detail,
+		Errors:  fields,
+		Meta:    newMeta(c),
+	})
+}
+
+// Problem writes an RFC 7807 application/problem+json error response, or
+// application/problem+xml to clients preferring XML
+func Problem(c *gin.Context, statusCode int, title string, detail string) {
+	render(c, statusCode, ProblemJSONContentType, newProblem(c, statusCode, title, Translate(c, detail)))
+}
+
+func newProblem(c *gin.Context, statusCode int, title string, detail string) ProblemDetails {
+	return ProblemDetails{
+		Type:      "about:blank",
+		Title:     Translate(c, title),
+		Status:    statusCode,
+		Detail:    detail,
+		Instance:  c.Request.URL.RequestURI(),
+		RequestID: newMeta(c).RequestID,
+	}
+}
+
 func BadRequest(c *gin.Context, message string, err string) {
 	Error(c, http.StatusBadRequest, message, err)
 }
@@ -37,6 +212,10 @@ func Unauthorized(c *gin.Context, message string, err string) {
 	Error(c, http.StatusUnauthorized, message, err)
 }
 
+func Forbidden(c *gin.Context, message string, err string) {
+	Error(c, http.StatusForbidden, message, err)
+}
+
 func InternalServerError(c *gin.Context, message string, err string) {
 	Error(c, http.StatusInternalServerError, message, err)
 }
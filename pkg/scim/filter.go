@@ -0,0 +1,42 @@
+package scim
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Errors of filters and PATCH operations, answered with 400 and the matching
+// error type
+var (
+	ErrInvalidFilter = errors.New("invalid filter")
+	ErrInvalidPath   = errors.New("invalid path")
+	ErrInvalidValue  = errors.New("invalid value")
+)
+
+// Filter is an equality filter, the only kind identity providers send to
+// look up users, such as userName eq "alice"
+type Filter struct {
+	// Attribute is the lowercased attribute path, such as "username"
+	Attribute string
+	Value     string
+}
+
+// ParseFilter parses an attribute eq "value" filter. Other operators and
+// logical expressions fail with ErrInvalidFilter.
+func ParseFilter(filter string) (*Filter, error) {
+	attribute, rest, ok := strings.Cut(strings.TrimSpace(filter), " ")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidFilter, filter)
+	}
+	operator, value, ok := strings.Cut(strings.TrimSpace(rest), " ")
+	if !ok || !strings.EqualFold(operator, "eq") {
+		return nil, fmt.Errorf("%w: only eq filters are supported", ErrInvalidFilter)
+	}
+	value, err := strconv.Unquote(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("%w: the value must be a quoted string", ErrInvalidFilter)
+	}
+	return &Filter{Attribute: strings.ToLower(attribute), Value: value}, nil
+}
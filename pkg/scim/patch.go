@@ -0,0 +1,186 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PatchRequest is a PATCH request, applying operations in order
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations" binding:"required,min=1"`
+}
+
+// PatchOperation adds, replaces or removes the attribute at Path. Without a
+// path, Value is an object of attributes to add or replace.
+type PatchOperation struct {
+	Op    string          `json:"op" binding:"required"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty" swaggertype:"object"`
+}
+
+// Apply applies the operations to the user. The supported paths are active,
+// userName, externalId, displayName, password, name and its sub-attributes,
+// emails and the value of an email selected by type, such as
+// emails[type eq "work"].value.
+func (u *User) Apply(operations []PatchOperation) error {
+	for _, op := range operations {
+		var err error
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			err = u.replace(op.Path, op.Value)
+		case "remove":
+			err = u.remove(op.Path)
+		default:
+			err = fmt.Errorf("%w: unknown operation %q", ErrInvalidValue, op.Op)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *User) replace(path string, value json.RawMessage) error {
+	if path == "" {
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(value, &attributes); err != nil {
+			return fmt.Errorf("%w: an operation without a path needs an object value", ErrInvalidValue)
+		}
+		for attribute, v := range attributes {
+			if err := u.replace(attribute, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Attributes may be qualified by the schema URN
+	path = strings.TrimPrefix(path, SchemaUser+":")
+	if selector, ok := emailSelector(path); ok {
+		return u.replaceEmail(selector, value)
+	}
+
+	switch strings.ToLower(path) {
+	case "active":
+		active, err := boolValue(value)
+		if err != nil {
+			return err
+		}
+		u.Active = &active
+		return nil
+	case "emails":
+		return decode(value, &u.Emails)
+	case "name":
+		return decode(value, &u.Name)
+	}
+
+	target, err := u.stringAttribute(path)
+	if err != nil {
+		return err
+	}
+	return decode(value, target)
+}
+
+func (u *User) remove(path string) error {
+	path = strings.TrimPrefix(path, SchemaUser+":")
+	switch strings.ToLower(path) {
+	case "username", "emails", "":
+		return fmt.Errorf("%w: %q cannot be removed", ErrInvalidPath, path)
+	case "name":
+		u.Name = nil
+		return nil
+	}
+	target, err := u.stringAttribute(path)
+	if err != nil {
+		return err
+	}
+	*target = ""
+	return nil
+}
+
+// stringAttribute returns the string attribute at the path
+func (u *User) stringAttribute(path string) (*string, error) {
+	switch strings.ToLower(path) {
+	case "username":
+		return &u.UserName, nil
+	case "externalid":
+		return &u.ExternalID, nil
+	case "displayname":
+		return &u.DisplayName, nil
+	case "password":
+		return &u.Password, nil
+	}
+
+	if sub, ok := strings.CutPrefix(strings.ToLower(path), "name."); ok {
+		if u.Name == nil {
+			u.Name = &Name{}
+		}
+		switch sub {
+		case "givenname":
+			return &u.Name.GivenName, nil
+		case "familyname":
+			return &u.Name.FamilyName, nil
+		case "formatted":
+			return &u.Name.Formatted, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrInvalidPath, path)
+}
+
+// emailSelector returns the type of the email selected by a path such as
+// emails[type eq "work"].value
+func emailSelector(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, "emails[")
+	if !ok {
+		return "", false
+	}
+	expression, attribute, ok := strings.Cut(rest, "]")
+	if !ok || (attribute != "" && attribute != ".value") {
+		return "", false
+	}
+	filter, err := ParseFilter(expression)
+	if err != nil || filter.Attribute != "type" {
+		return "", false
+	}
+	return filter.Value, true
+}
+
+func (u *User) replaceEmail(emailType string, value json.RawMessage) error {
+	var address string
+	if err := decode(value, &address); err != nil {
+		return err
+	}
+	for i := range u.Emails {
+		if u.Emails[i].Type == emailType {
+			u.Emails[i].Value = address
+			return nil
+		}
+	}
+	u.Emails = append(u.Emails, Email{Value: address, Type: emailType, Primary: len(u.Emails) == 0})
+	return nil
+}
+
+// boolValue decodes a boolean. Azure AD sends booleans as "True" and "False".
+func boolValue(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("%w: %s is not a boolean", ErrInvalidValue, value)
+}
+
+func decode(value json.RawMessage, target interface{}) error {
+	if err := json.Unmarshal(value, target); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidValue, value)
+	}
+	return nil
+}
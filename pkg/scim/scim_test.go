@@ -0,0 +1,98 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   *Filter
+	}{
+		{`userName eq "alice"`, &Filter{Attribute: "username", Value: "alice"}},
+		{`emails.value EQ "a b@example.com"`, &Filter{Attribute: "emails.value", Value: "a b@example.com"}},
+		{`userName sw "al"`, nil},
+		{`userName eq alice`, nil},
+		{`userName`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := ParseFilter(tt.filter)
+			if tt.want == nil {
+				assert.ErrorIs(t, err, ErrInvalidFilter)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, filter)
+		})
+	}
+}
+
+func patch(t *testing.T, u *User, operations string) error {
+	var ops []PatchOperation
+	require.NoError(t, json.Unmarshal([]byte(operations), &ops))
+	return u.Apply(ops)
+}
+
+func TestUser_Apply(t *testing.T) {
+	newUser := func() *User {
+		return &User{
+			UserName: "alice",
+			Name:     &Name{GivenName: "Alice", FamilyName: "Liddell"},
+			Emails:   []Email{{Value: "alice@example.com", Type: "work", Primary: true}},
+		}
+	}
+
+	t.Run("replace with a path", func(t *testing.T) {
+		u := newUser()
+		require.NoError(t, patch(t, u, `[
+			{"op": "replace", "path": "active", "value": false},
+			{"op": "replace", "path": "name.familyName", "value": "Smith"},
+			{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "alice@corp.example"}
+		]`))
+		assert.False(t, u.IsActive())
+		assert.Equal(t, "Smith", u.Name.FamilyName)
+		assert.Equal(t, "alice@corp.example", u.PrimaryEmail())
+	})
+
+	t.Run("replace without a path", func(t *testing.T) {
+		u := newUser()
+		// As sent by Azure AD
+		require.NoError(t, patch(t, u, `[
+			{"op": "Replace", "value": {"active": "False", "userName": "alice2", "name.givenName": "Al"}}
+		]`))
+		assert.False(t, u.IsActive())
+		assert.Equal(t, "alice2", u.UserName)
+		assert.Equal(t, "Al", u.Name.GivenName)
+	})
+
+	t.Run("add an email of another type", func(t *testing.T) {
+		u := newUser()
+		require.NoError(t, patch(t, u, `[{"op": "add", "path": "emails[type eq \"home\"].value", "value": "a@home.example"}]`))
+		assert.Len(t, u.Emails, 2)
+		assert.Equal(t, "alice@example.com", u.PrimaryEmail())
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		u := newUser()
+		require.NoError(t, patch(t, u, `[{"op": "remove", "path": "name.givenName"}]`))
+		assert.Empty(t, u.Name.GivenName)
+		assert.ErrorIs(t, patch(t, u, `[{"op": "remove", "path": "userName"}]`), ErrInvalidPath)
+	})
+
+	t.Run("invalid operations", func(t *testing.T) {
+		assert.ErrorIs(t, patch(t, newUser(), `[{"op": "replace", "path": "nickName", "value": "al"}]`), ErrInvalidPath)
+		assert.ErrorIs(t, patch(t, newUser(), `[{"op": "replace", "path": "active", "value": "maybe"}]`), ErrInvalidValue)
+		assert.ErrorIs(t, patch(t, newUser(), `[{"op": "move", "path": "active"}]`), ErrInvalidValue)
+	})
+}
+
+func TestUser_PrimaryEmail(t *testing.T) {
+	u := &User{Emails: []Email{{Value: "home@example.com"}, {Value: "work@example.com", Primary: true}}}
+	assert.Equal(t, "work@example.com", u.PrimaryEmail())
+	assert.Empty(t, (&User{}).PrimaryEmail())
+}
@@ -0,0 +1,72 @@
+package securecookie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodec_SealOpen(t *testing.T) {
+	codec, err := New("a-secret-of-at-least-thirty-two-bytes")
+	require.NoError(t, err)
+
+	sealed, err := codec.Seal("session", "header.payload.signature")
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "payload")
+
+	value, err := codec.Open("session", sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "header.payload.signature", value)
+
+	again, err := codec.Seal("session", "header.payload.signature")
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "each seal uses a fresh nonce")
+
+	t.Run("other cookie", func(t *testing.T) {
+		_, err := codec.Open("other", sealed)
+		assert.ErrorIs(t, err, ErrInvalidCookie)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := []byte(sealed)
+		tampered[len(tampered)-1] ^= 1
+		_, err := codec.Open("session", string(tampered))
+		assert.ErrorIs(t, err, ErrInvalidCookie)
+
+		_, err = codec.Open("session", "not base64!")
+		assert.ErrorIs(t, err, ErrInvalidCookie)
+	})
+
+	t.Run("other secret", func(t *testing.T) {
+		other, err := New("another-secret-of-thirty-two-bytes!")
+		require.NoError(t, err)
+		_, err = other.Open("session", sealed)
+		assert.ErrorIs(t, err, ErrInvalidCookie)
+	})
+}
+
+func TestCodec_CSRFToken(t *testing.T) {
+	codec, err := New("a-secret-of-at-least-thirty-two-bytes")
+	require.NoError(t, err)
+
+	token, err := codec.CSRFToken("session-1")
+	require.NoError(t, err)
+	assert.True(t, codec.ValidCSRFToken("session-1", token))
+	assert.False(t, codec.ValidCSRFToken("session-2", token))
+	assert.False(t, codec.ValidCSRFToken("", token))
+	assert.False(t, codec.ValidCSRFToken("session-1", ""))
+	assert.False(t, codec.ValidCSRFToken("session-1", token+"x"))
+
+	again, err := codec.CSRFToken("session-1")
+	require.NoError(t, err)
+	assert.NotEqual(t, token, again)
+
+	anonymous, err := codec.CSRFToken("")
+	require.NoError(t, err)
+	assert.True(t, codec.ValidCSRFToken("", anonymous))
+	assert.False(t, codec.ValidCSRFToken("session-1", anonymous))
+
+	_, err = New("")
+	assert.Error(t, err)
+}
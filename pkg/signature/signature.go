@@ -0,0 +1,63 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+	ErrExpiredTimestamp = errors.New("timestamp outside of allowed window")
+)
+
+// BodyDigest returns the hex encoded SHA-256 digest of the request body.
+func BodyDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// CanonicalString builds the string that is signed for a request.
+func CanonicalString(timestamp, method, path string, body []byte) string {
+	return strings.Join([]string{
+		timestamp,
+		strings.ToUpper(method),
+		path,
+		BodyDigest(body),
+	}, "\n")
+}
+
+// Sign computes the hex encoded HMAC-SHA256 signature of a request.
+func Sign(secret, timestamp, method, path string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(CanonicalString(timestamp, method, path, body)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and that the timestamp is within the replay window.
+func Verify(secret, signature, timestamp, method, path string, body []byte, window time.Duration, now time.Time) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+
+	skew := now.Sub(time.Unix(unix, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > window {
+		return ErrExpiredTimestamp
+	}
+
+	expected := Sign(secret, timestamp, method, path, body)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package signature
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"event":"payment.succeeded"}`)
+	valid := Sign("secret", timestamp, "POST", "/api/v1/webhooks", body)
+
+	tests := []struct {
+		name          string
+		secret        string
+		signature     string
+		timestamp     string
+		body          []byte
+		expectedError error
+	}{
+		{
+			name:      "valid signature",
+			secret:    "secret",
+			signature: valid,
+			timestamp: timestamp,
+			body:      body,
+		},
+		{
+			name:          "wrong secret",
+			secret:        "other",
+			signature:     valid,
+			timestamp:     timestamp,
+			body:          body,
+			expectedError: ErrInvalidSignature,
+		},
+		{
+			name:          "tampered body",
+			secret:        "secret",
+			signature:     valid,
+			timestamp:     timestamp,
+			body:          []byte(`{"event":"refund"}`),
+			expectedError: ErrInvalidSignature,
+		},
+		{
+			name:          "expired timestamp",
+			secret:        "secret",
+			signature:     valid,
+			timestamp:     strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10),
+			body:          body,
+			expectedError: ErrExpiredTimestamp,
+		},
+		{
+			name:          "malformed timestamp",
+			secret:        "secret",
+			signature:     valid,
+			timestamp:     "yesterday",
+			body:          body,
+			expectedError: ErrInvalidTimestamp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.signature, tt.timestamp, "POST", "/api/v1/webhooks", tt.body, 5*time.Minute, now)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package timeout
+
+import (
+	"context"
+	"time"
+)
+
+// Operation names
+const (
+	OperationDefault      = "default"
+	OperationDBRead       = "db_read"
+	OperationDBWrite      = "db_write"
+	OperationPayment      = "payment"
+	OperationFraud        = "fraud"
+	OperationGeolocation  = "geolocation"
+	OperationNotification = "notification"
+	OperationStorage      = "storage"
+)
+
+// Policy assigns deadlines to operations so that one slow dependency cannot
+// hold a request for longer than its budget.
+type Policy struct {
+	timeouts map[string]time.Duration
+}
+
+// NewPolicy creates a policy from timeouts indexed by operation name.
+// Operations without an entry use the OperationDefault timeout.
+func NewPolicy(timeouts map[string]time.Duration) *Policy {
+	return &Policy{timeouts: timeouts}
+}
+
+// For returns the timeout of the operation, or zero when it has none.
+func (p *Policy) For(operation string) time.Duration {
+	if p == nil {
+		return 0
+	}
+	if d, ok := p.timeouts[operation]; ok {
+		return d
+	}
+	return p.timeouts[OperationDefault]
+}
+
+// WithTimeout returns a context bounded by the operation's timeout. A nil
+// policy or zero timeout leaves the context unbounded; an earlier deadline
+// already set on ctx is kept.
+func (p *Policy) WithTimeout(ctx context.Context, operation string) (context.Context, context.CancelFunc) {
+	d := p.For(operation)
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
@@ -0,0 +1,52 @@
+package timeout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyWithTimeout(t *testing.T) {
+	policy := NewPolicy(map[string]time.Duration{
+		OperationDefault: 10 * time.Second,
+		OperationPayment: 5 * time.Second,
+		OperationDBRead:  0,
+	})
+
+	tests := []struct {
+		name         string
+		policy       *Policy
+		parent       time.Duration
+		operation    string
+		wantDeadline bool
+		maxRemaining time.Duration
+	}{
+		{name: "configured operation", policy: policy, operation: OperationPayment, wantDeadline: true, maxRemaining: 5 * time.Second},
+		{name: "falls back to default", policy: policy, operation: OperationFraud, wantDeadline: true, maxRemaining: 10 * time.Second},
+		{name: "zero timeout disables", policy: policy, operation: OperationDBRead},
+		{name: "nil policy", operation: OperationPayment},
+		{name: "keeps earlier parent deadline", policy: policy, parent: time.Second, operation: OperationPayment, wantDeadline: true, maxRemaining: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := context.Background()
+			if tt.parent > 0 {
+				var cancel context.CancelFunc
+				parent, cancel = context.WithTimeout(parent, tt.parent)
+				defer cancel()
+			}
+
+			ctx, cancel := tt.policy.WithTimeout(parent, tt.operation)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			assert.Equal(t, tt.wantDeadline, ok)
+			if ok {
+				assert.LessOrEqual(t, time.Until(deadline), tt.maxRemaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Package useragent classifies User-Agent headers into device type, operating
+// system and browser. It recognizes the common browsers and platforms rather
+// than every version detail, which is enough for session lists, audit logs and
+// fraud signals.
+package useragent
+
+import "strings"
+
+// Device types
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+	DeviceUnknown = "unknown"
+)
+
+// Agent is a classified User-Agent. OS and Browser are empty when unknown.
+type Agent struct {
+	Device  string
+	OS      string
+	Browser string
+}
+
+// String describes the agent for people, such as "Chrome on macOS"
+func (a Agent) String() string {
+	switch {
+	case a.Browser != "" && a.OS != "":
+		return a.Browser + " on " + a.OS
+	case a.Browser != "":
+		return a.Browser
+	case a.OS != "":
+		return a.OS
+	}
+	return "Unknown device"
+}
+
+// match pairs a substring of the User-Agent with the name it identifies
+type match struct {
+	token string
+	name  string
+}
+
+// Checked in order, since many agents also carry the tokens of others: iPads
+// claim to be "like Mac OS X", Android is Linux, and Edge and Opera are Chrome.
+var (
+	operatingSystems = []match{
+		{"iphone", "iOS"},
+		{"ipad", "iOS"},
+		{"ipod", "iOS"},
+		{"android", "Android"},
+		{"windows", "Windows"},
+		{"cros ", "ChromeOS"},
+		{"mac os x", "macOS"},
+		{"macintosh", "macOS"},
+		{"linux", "Linux"},
+	}
+	browsers = []match{
+		{"edg/", "Edge"},
+		{"edge/", "Edge"},
+		{"edga/", "Edge"},
+		{"edgios/", "Edge"},
+		{"opr/", "Opera"},
+		{"opera", "Opera"},
+		{"samsungbrowser", "Samsung Internet"},
+		{"firefox/", "Firefox"},
+		{"fxios", "Firefox"},
+		{"crios", "Chrome"},
+		{"chrome/", "Chrome"},
+		{"safari/", "Safari"},
+		{"trident/", "Internet Explorer"},
+		{"msie", "Internet Explorer"},
+	}
+	bots = []string{"bot", "crawler", "spider", "slurp", "curl/", "wget/", "python-requests", "go-http-client", "headless"}
+)
+
+// Parse classifies a User-Agent header
+func Parse(userAgent string) Agent {
+	ua := strings.ToLower(userAgent)
+	agent := Agent{
+		OS:      find(ua, operatingSystems),
+		Browser: find(ua, browsers),
+	}
+
+	switch {
+	case ua == "":
+		agent.Device = DeviceUnknown
+	case containsAny(ua, bots):
+		agent.Device = DeviceBot
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet") ||
+		(agent.OS == "Android" && !strings.Contains(ua, "mobile")):
+		agent.Device = DeviceTablet
+	case strings.Contains(ua, "mobi") || agent.OS == "iOS" || agent.OS == "Android":
+		agent.Device = DeviceMobile
+	case agent.OS != "":
+		agent.Device = DeviceDesktop
+	default:
+		agent.Device = DeviceUnknown
+	}
+	return agent
+}
+
+func find(ua string, matches []match) string {
+	for _, m := range matches {
+		if strings.Contains(ua, m.token) {
+			return m.name
+		}
+	}
+	return ""
+}
+
+func containsAny(ua string, tokens []string) bool {
+	for _, token := range tokens {
+		if strings.Contains(ua, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package useragent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		expected  Agent
+	}{
+		{
+			"chrome on windows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			Agent{Device: DeviceDesktop, OS: "Windows", Browser: "Chrome"},
+		},
+		{
+			"edge on windows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.51",
+			Agent{Device: DeviceDesktop, OS: "Windows", Browser: "Edge"},
+		},
+		{
+			"safari on macos",
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_4) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+			Agent{Device: DeviceDesktop, OS: "macOS", Browser: "Safari"},
+		},
+		{
+			"firefox on linux",
+			"Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			Agent{Device: DeviceDesktop, OS: "Linux", Browser: "Firefox"},
+		},
+		{
+			"safari on iphone",
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			Agent{Device: DeviceMobile, OS: "iOS", Browser: "Safari"},
+		},
+		{
+			"chrome on ipad",
+			"Mozilla/5.0 (iPad; CPU OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/124.0.6367.88 Mobile/15E148 Safari/604.1",
+			Agent{Device: DeviceTablet, OS: "iOS", Browser: "Chrome"},
+		},
+		{
+			"samsung internet on android phone",
+			"Mozilla/5.0 (Linux; Android 14; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/24.0 Chrome/117.0.0.0 Mobile Safari/537.36",
+			Agent{Device: DeviceMobile, OS: "Android", Browser: "Samsung Internet"},
+		},
+		{
+			"chrome on android tablet",
+			"Mozilla/5.0 (Linux; Android 13; SM-X710) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			Agent{Device: DeviceTablet, OS: "Android", Browser: "Chrome"},
+		},
+		{
+			"crawler",
+			"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			Agent{Device: DeviceBot},
+		},
+		{
+			"command line client",
+			"curl/8.5.0",
+			Agent{Device: DeviceBot},
+		},
+		{
+			"missing",
+			"",
+			Agent{Device: DeviceUnknown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Parse(tt.userAgent))
+		})
+	}
+}
+
+func TestAgent_String(t *testing.T) {
+	assert.Equal(t, "Chrome on macOS", Agent{OS: "macOS", Browser: "Chrome"}.String())
+	assert.Equal(t, "Android", Agent{OS: "Android"}.String())
+	assert.Equal(t, "Unknown device", Agent{}.String())
+}
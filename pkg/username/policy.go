@@ -0,0 +1,192 @@
+// Package username checks usernames against a configurable policy: length,
+// allowed characters, reserved names and banned words. Usernames are
+// normalized before they are checked, stored or looked up, and are unique
+// regardless of case. Every violation is reported, with a code for clients
+// and an English message that can be translated.
+package username
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"boilerplate-go/pkg/errors"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Violation codes
+const (
+	CodeTooShort          = "too_short"
+	CodeTooLong           = "too_long"
+	CodeInvalidCharacters = "invalid_characters"
+	CodeReserved          = "reserved"
+	CodeBannedWord        = "banned_word"
+)
+
+// DefaultPattern allows letters and digits, joined by single dots,
+// underscores or hyphens
+const DefaultPattern = `^[A-Za-z0-9]+([._-][A-Za-z0-9]+)*$`
+
+//go:embed reserved_names.txt
+var reservedNames string
+
+// Violation is a requirement a username does not meet. Message is an English
+// format string for Args, so it can be translated before formatting.
+type Violation struct {
+	Code    string
+	Message string
+	Args    []interface{}
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf(v.Message, v.Args...)
+}
+
+// PolicyError reports the requirements a username does not meet. It matches
+// errors.ErrInvalidUsername.
+type PolicyError struct {
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.String()
+	}
+	return fmt.Sprintf("%s: %s", errors.ErrInvalidUsername, strings.Join(messages, "; "))
+}
+
+func (e *PolicyError) Unwrap() error {
+	return errors.ErrInvalidUsername
+}
+
+// Policy is the requirements usernames must meet. The zero value accepts
+// any username.
+type Policy struct {
+	MinLength int
+	MaxLength int
+	// Pattern is the expression usernames must match; nil allows any
+	Pattern *regexp.Regexp
+
+	reserved map[string]bool
+	banned   []string
+}
+
+// DefaultPolicy requires 3 to 50 characters matching DefaultPattern and
+// rejects the built-in reserved names.
+func DefaultPolicy() *Policy {
+	p := &Policy{MinLength: 3, MaxLength: 50, Pattern: regexp.MustCompile(DefaultPattern)}
+	p.ReserveDefaults()
+	return p
+}
+
+// Normalize returns the form a username is checked, stored and looked up in:
+// NFKC normalized, so look-alike forms of a character are one, and trimmed
+func Normalize(username string) string {
+	return strings.TrimSpace(norm.NFKC.String(username))
+}
+
+// key folds a username for comparison with reserved names and banned words:
+// lowercased, without the separators . _ -
+func key(username string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '_', '-':
+			return -1
+		}
+		return r
+	}, strings.ToLower(Normalize(username)))
+}
+
+// ReserveDefaults rejects the built-in list of reserved names, such as admin
+// and api
+func (p *Policy) ReserveDefaults() {
+	// The embedded list is valid; reading a string cannot fail
+	_ = readList(strings.NewReader(reservedNames), p.Reserve)
+}
+
+// Reserve rejects the names, compared ignoring case and separators
+func (p *Policy) Reserve(names ...string) {
+	if p.reserved == nil {
+		p.reserved = make(map[string]bool, len(names))
+	}
+	for _, name := range names {
+		if k := key(name); k != "" {
+			p.reserved[k] = true
+		}
+	}
+}
+
+// Ban rejects usernames containing the words, compared ignoring case and
+// separators
+func (p *Policy) Ban(words ...string) {
+	for _, word := range words {
+		if k := key(word); k != "" {
+			p.banned = append(p.banned, k)
+		}
+	}
+}
+
+// BanList rejects usernames containing the words in r, one per line. Blank
+// lines and lines starting with # are skipped.
+func (p *Policy) BanList(r io.Reader) error {
+	return readList(r, p.Ban)
+}
+
+func readList(r io.Reader, add func(...string)) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		add(line)
+	}
+	return scanner.Err()
+}
+
+// Check returns a *PolicyError listing every requirement the normalized
+// username does not meet, or nil
+func (p *Policy) Check(username string) error {
+	if violations := p.Violations(username); len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// Violations returns the requirements the normalized username does not meet
+func (p *Policy) Violations(username string) []Violation {
+	var violations []Violation
+	add := func(code, message string, args ...interface{}) {
+		violations = append(violations, Violation{Code: code, Message: message, Args: args})
+	}
+
+	username = Normalize(username)
+	length := utf8.RuneCountInString(username)
+	if length < p.MinLength {
+		add(CodeTooShort, "username must be at least %d characters", p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		add(CodeTooLong, "username must be at most %d characters", p.MaxLength)
+	}
+	if p.Pattern != nil && !p.Pattern.MatchString(username) {
+		add(CodeInvalidCharacters, "username contains characters that are not allowed")
+	}
+
+	k := key(username)
+	if p.reserved[k] {
+		add(CodeReserved, "username is reserved")
+	}
+	for _, word := range p.banned {
+		if strings.Contains(k, word) {
+			add(CodeBannedWord, "username contains a word that is not allowed")
+			break
+		}
+	}
+	return violations
+}
@@ -0,0 +1,66 @@
+package username
+
+import (
+	"strings"
+	"testing"
+
+	"boilerplate-go/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func codes(violations []Violation) []string {
+	result := make([]string, len(violations))
+	for i, v := range violations {
+		result[i] = v.Code
+	}
+	return result
+}
+
+func TestPolicy_Violations(t *testing.T) {
+	banned := DefaultPolicy()
+	require.NoError(t, banned.BanList(strings.NewReader("# offensive words\n\nbadword\n")))
+
+	tests := []struct {
+		name     string
+		policy   *Policy
+		username string
+		expected []string
+	}{
+		{name: "valid", policy: DefaultPolicy(), username: "jane.doe_1", expected: []string{}},
+		{name: "too short", policy: DefaultPolicy(), username: "jd", expected: []string{CodeTooShort}},
+		{name: "too long", policy: DefaultPolicy(), username: strings.Repeat("a", 51), expected: []string{CodeTooLong}},
+		{name: "spaces", policy: DefaultPolicy(), username: "jane doe", expected: []string{CodeInvalidCharacters}},
+		{name: "leading separator", policy: DefaultPolicy(), username: "_jane", expected: []string{CodeInvalidCharacters}},
+		{name: "reserved", policy: DefaultPolicy(), username: "Admin", expected: []string{CodeReserved}},
+		{name: "reserved with separators", policy: DefaultPolicy(), username: "no-reply", expected: []string{CodeReserved}},
+		{name: "fullwidth reserved", policy: DefaultPolicy(), username: "ａｄｍｉｎ", expected: []string{CodeReserved}},
+		{name: "banned word", policy: banned, username: "the_BadWord_99", expected: []string{CodeBannedWord}},
+		{name: "banned word across separators", policy: banned, username: "bad.word", expected: []string{CodeBannedWord}},
+		{name: "zero value accepts anything", policy: &Policy{}, username: "ad min!", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, codes(tt.policy.Violations(tt.username)))
+		})
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.Reserve("boilerplate")
+
+	assert.NoError(t, policy.Check(" jane "), "checked once normalized")
+
+	err := policy.Check("Boiler_Plate")
+	require.Error(t, err)
+	assert.True(t, errors.IsInvalidUsername(err))
+	assert.Equal(t, "username does not meet the requirements: username is reserved", err.Error())
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "jane", Normalize("  jane\t"))
+	assert.Equal(t, "Jane1", Normalize("Ｊａｎｅ１"), "fullwidth forms fold to ASCII")
+}
@@ -0,0 +1,134 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// Partitioned runs tasks in order per key. Keys are spread by hash over a
+// fixed number of partitions, each with a single worker, so the tasks of a
+// key run one at a time in the order they were submitted while other keys run
+// concurrently. Keys sharing a partition also wait for each other.
+type Partitioned struct {
+	partitions []chan func()
+	stop       chan struct{}
+	stopOnce   sync.Once
+	wg         sync.WaitGroup
+	// done is closed once every worker has exited
+	done chan struct{}
+}
+
+// NewPartitioned starts a worker per partition, each with a queue of
+// queueSize tasks.
+func NewPartitioned(partitions, queueSize int) *Partitioned {
+	if partitions < 1 {
+		partitions = 1
+	}
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	p := &Partitioned{
+		partitions: make([]chan func(), partitions),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	for i := range p.partitions {
+		p.partitions[i] = make(chan func(), queueSize)
+		p.wg.Add(1)
+		go p.work(p.partitions[i])
+	}
+	go func() {
+		p.wg.Wait()
+		close(p.done)
+	}()
+	return p
+}
+
+// Do runs the task after the tasks submitted before it with the same key and
+// returns its error. It waits for room in the partition's queue until ctx is
+// done; a task whose ctx is done by the time its turn comes is skipped with
+// ctx.Err().
+func (p *Partitioned) Do(ctx context.Context, key string, task func(ctx context.Context) error) error {
+	select {
+	case <-p.stop:
+		return ErrClosed
+	default:
+	}
+
+	result := make(chan error, 1)
+	run := func() {
+		if err := ctx.Err(); err != nil {
+			result <- err
+			return
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("task panicked: %v", r)
+			}
+		}()
+		result <- task(ctx)
+	}
+
+	select {
+	case p.partitions[p.partition(key)] <- run:
+	case <-p.stop:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-result:
+		return err
+	case <-p.done:
+		// Queued as the workers exited
+		select {
+		case err := <-result:
+			return err
+		default:
+			return ErrClosed
+		}
+	}
+}
+
+// Shutdown stops accepting tasks and waits for queued and running tasks to finish.
+func (p *Partitioned) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.stop) })
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Partitioned) partition(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(p.partitions)))
+}
+
+// work runs the tasks of a partition until the pool is shut down and drained.
+func (p *Partitioned) work(queue chan func()) {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case run := <-queue:
+			run()
+		case <-p.stop:
+			for {
+				select {
+				case run := <-queue:
+					run()
+				default:
+					return
+				}
+			}
+		}
+	}
+}
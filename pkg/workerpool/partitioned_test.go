@@ -0,0 +1,132 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockPartition occupies the worker of the key's partition until release is
+// closed, returning the result of the blocking task.
+func blockPartition(p *Partitioned, key string, release <-chan struct{}) <-chan error {
+	started := make(chan struct{})
+	result := make(chan error, 1)
+	go func() {
+		result <- p.Do(context.Background(), key, func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+	return result
+}
+
+func TestPartitioned_RunsKeyInSubmissionOrder(t *testing.T) {
+	p := NewPartitioned(1, 10)
+	release := make(chan struct{})
+	blocked := blockPartition(p, "order-1", release)
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	for i, event := range []string{"captured", "refunded", "disputed"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, p.Do(context.Background(), "order-1", func(ctx context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, event)
+				return nil
+			}))
+		}()
+		// Submit the next event once this one is queued
+		assert.Eventually(t, func() bool { return len(p.partitions[0]) == i+1 }, time.Second, time.Millisecond)
+	}
+
+	close(release)
+	wg.Wait()
+	assert.NoError(t, <-blocked)
+	assert.Equal(t, []string{"captured", "refunded", "disputed"}, order)
+}
+
+func TestPartitioned_OtherPartitionsRunConcurrently(t *testing.T) {
+	p := NewPartitioned(2, 10)
+	release := make(chan struct{})
+	blocked := blockPartition(p, "order-1", release)
+
+	other := "order-2"
+	for i := 3; p.partition(other) == p.partition("order-1"); i++ {
+		other = "order-" + string(rune('0'+i))
+	}
+	assert.NoError(t, p.Do(context.Background(), other, func(ctx context.Context) error { return nil }))
+
+	close(release)
+	assert.NoError(t, <-blocked)
+}
+
+func TestPartitioned_ReturnsTaskError(t *testing.T) {
+	p := NewPartitioned(1, 1)
+	failure := errors.New("order not found")
+
+	assert.ErrorIs(t, p.Do(context.Background(), "order-1", func(ctx context.Context) error { return failure }), failure)
+	assert.ErrorContains(t, p.Do(context.Background(), "order-1", func(ctx context.Context) error { panic("boom") }), "task panicked: boom")
+}
+
+func TestPartitioned_SkipsCancelledTasks(t *testing.T) {
+	p := NewPartitioned(1, 10)
+	release := make(chan struct{})
+	blocked := blockPartition(p, "order-1", release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	ran := false
+	go func() {
+		result <- p.Do(ctx, "order-1", func(ctx context.Context) error {
+			ran = true
+			return nil
+		})
+	}()
+	assert.Eventually(t, func() bool { return len(p.partitions[0]) == 1 }, time.Second, time.Millisecond)
+	cancel()
+
+	close(release)
+	assert.ErrorIs(t, <-result, context.Canceled)
+	assert.NoError(t, <-blocked)
+	assert.False(t, ran)
+}
+
+func TestPartitioned_Shutdown(t *testing.T) {
+	p := NewPartitioned(1, 10)
+	release := make(chan struct{})
+	blocked := blockPartition(p, "order-1", release)
+
+	queued := make(chan error, 1)
+	go func() {
+		queued <- p.Do(context.Background(), "order-1", func(ctx context.Context) error { return nil })
+	}()
+	assert.Eventually(t, func() bool { return len(p.partitions[0]) == 1 }, time.Second, time.Millisecond)
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- p.Shutdown(context.Background()) }()
+	assert.Eventually(t, func() bool {
+		select {
+		case <-p.stop:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond)
+	assert.ErrorIs(t, p.Do(context.Background(), "order-2", func(ctx context.Context) error { return nil }), ErrClosed)
+
+	// Queued tasks still run
+	close(release)
+	assert.NoError(t, <-stopped)
+	assert.NoError(t, <-blocked)
+	assert.NoError(t, <-queued)
+}
@@ -0,0 +1,203 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// Priority orders queued tasks; workers always take the highest priority task first.
+type Priority int
+
+const (
+	PriorityHigh Priority = iota
+	PriorityNormal
+	PriorityLow
+	numPriorities
+)
+
+// String returns the priority name.
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityLow:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// Policy decides what Submit does when the queue of a priority is full.
+type Policy int
+
+const (
+	// PolicyBlock waits for room in the queue until the context is done.
+	PolicyBlock Policy = iota
+	// PolicyDrop rejects the task with ErrQueueFull.
+	PolicyDrop
+)
+
+var (
+	// ErrQueueFull is returned by Submit when the queue is full and the policy drops tasks.
+	ErrQueueFull = errors.New("worker pool queue is full")
+	// ErrClosed is returned by Submit after Shutdown was called.
+	ErrClosed = errors.New("worker pool is closed")
+)
+
+// Task is a unit of work run by the pool.
+type Task func()
+
+// Stats is a snapshot of the pool saturation.
+type Stats struct {
+	Workers int
+	Busy    int
+	Queued  map[Priority]int
+}
+
+// Pool runs tasks on a fixed number of workers. Each priority has its own
+// bounded queue so a flood of low priority tasks cannot delay high priority ones.
+type Pool struct {
+	queues   [numPriorities]chan Task
+	ready    chan struct{}
+	workers  int
+	policy   Policy
+	busy     int64
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// New starts a pool with the given number of workers and queue length per priority.
+func New(workers, queueSize int, policy Policy) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	p := &Pool{
+		// One token per queued task, so a worker holding a token always finds a task
+		ready:   make(chan struct{}, queueSize*int(numPriorities)),
+		workers: workers,
+		policy:  policy,
+		stop:    make(chan struct{}),
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan Task, queueSize)
+	}
+
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues the task at the given priority. When the queue is full it
+// blocks or returns ErrQueueFull depending on the pool policy.
+func (p *Pool) Submit(ctx context.Context, priority Priority, task Task) error {
+	if priority < PriorityHigh || priority >= numPriorities {
+		priority = PriorityNormal
+	}
+
+	select {
+	case <-p.stop:
+		return ErrClosed
+	default:
+	}
+
+	queue := p.queues[priority]
+	if p.policy == PolicyDrop {
+		select {
+		case queue <- task:
+		default:
+			return ErrQueueFull
+		}
+	} else {
+		select {
+		case queue <- task:
+		case <-p.stop:
+			return ErrClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	p.ready <- struct{}{}
+	return nil
+}
+
+// Stats returns the current saturation of the pool.
+func (p *Pool) Stats() Stats {
+	stats := Stats{
+		Workers: p.workers,
+		Busy:    int(atomic.LoadInt64(&p.busy)),
+		Queued:  make(map[Priority]int, numPriorities),
+	}
+	for i, queue := range p.queues {
+		stats.Queued[Priority(i)] = len(queue)
+	}
+	return stats
+}
+
+// Shutdown stops accepting tasks and waits for queued and running tasks to finish.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work runs queued tasks until the pool is shut down and drained.
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-p.ready:
+			p.runNext()
+		case <-p.stop:
+			for {
+				select {
+				case <-p.ready:
+					p.runNext()
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// runNext runs the highest priority queued task.
+func (p *Pool) runNext() {
+	for _, queue := range p.queues {
+		select {
+		case task := <-queue:
+			p.run(task)
+			return
+		default:
+		}
+	}
+}
+
+// run executes the task, recovering from a panic so the worker survives it.
+func (p *Pool) run(task Task) {
+	atomic.AddInt64(&p.busy, 1)
+	defer atomic.AddInt64(&p.busy, -1)
+	defer func() { _ = recover() }()
+	task()
+}
@@ -0,0 +1,93 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockWorker occupies the only worker of the pool until release is closed.
+func blockWorker(t *testing.T, p *Pool, release <-chan struct{}) {
+	started := make(chan struct{})
+	assert.NoError(t, p.Submit(context.Background(), PriorityNormal, func() {
+		close(started)
+		<-release
+	}))
+	<-started
+}
+
+func TestPool_RunsHigherPriorityFirst(t *testing.T) {
+	p := New(1, 10, PolicyBlock)
+	release := make(chan struct{})
+	blockWorker(t, p, release)
+
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) Task {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+		}
+	}
+
+	assert.NoError(t, p.Submit(context.Background(), PriorityLow, record("marketing")))
+	assert.NoError(t, p.Submit(context.Background(), PriorityNormal, record("receipt")))
+	assert.NoError(t, p.Submit(context.Background(), PriorityHigh, record("security")))
+
+	stats := p.Stats()
+	assert.Equal(t, 1, stats.Busy)
+	assert.Equal(t, 1, stats.Queued[PriorityHigh])
+	assert.Equal(t, 1, stats.Queued[PriorityLow])
+
+	close(release)
+	assert.NoError(t, p.Shutdown(context.Background()))
+	assert.Equal(t, []string{"security", "receipt", "marketing"}, order)
+}
+
+func TestPool_DropPolicy(t *testing.T) {
+	p := New(1, 1, PolicyDrop)
+	release := make(chan struct{})
+	blockWorker(t, p, release)
+
+	assert.NoError(t, p.Submit(context.Background(), PriorityLow, func() {}))
+	assert.ErrorIs(t, p.Submit(context.Background(), PriorityLow, func() {}), ErrQueueFull)
+	// Each priority has its own queue
+	assert.NoError(t, p.Submit(context.Background(), PriorityHigh, func() {}))
+
+	close(release)
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestPool_BlockPolicyHonoursContext(t *testing.T) {
+	p := New(1, 1, PolicyBlock)
+	release := make(chan struct{})
+	blockWorker(t, p, release)
+	assert.NoError(t, p.Submit(context.Background(), PriorityNormal, func() {}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, p.Submit(ctx, PriorityNormal, func() {}), context.DeadlineExceeded)
+
+	close(release)
+	assert.NoError(t, p.Shutdown(context.Background()))
+	assert.ErrorIs(t, p.Submit(context.Background(), PriorityNormal, func() {}), ErrClosed)
+}
+
+func TestPool_SurvivesPanics(t *testing.T) {
+	p := New(1, 1, PolicyBlock)
+	done := make(chan struct{})
+
+	assert.NoError(t, p.Submit(context.Background(), PriorityNormal, func() { panic("boom") }))
+	assert.NoError(t, p.Submit(context.Background(), PriorityNormal, func() { close(done) }))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not survive the panic")
+	}
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
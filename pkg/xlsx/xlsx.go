@@ -0,0 +1,141 @@
+// Package xlsx writes single-sheet Excel workbooks row by row, like
+// encoding/csv, so large tables are never held in memory whole. Every cell
+// is written as text.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// ContentType is the media type of the workbooks
+const ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+const (
+	contentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	rootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	workbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+	sheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	sheetEnd = `</sheetData></worksheet>`
+)
+
+// Writer writes the rows of a sheet to a workbook
+type Writer struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	name  string
+	err   error
+}
+
+// NewWriter returns a writer of a workbook with one sheet of the name
+func NewWriter(w io.Writer, sheetName string) *Writer {
+	return &Writer{zip: zip.NewWriter(w), name: sheetName}
+}
+
+// Write writes a row of text cells
+func (w *Writer) Write(record []string) error {
+	if w.sheet == nil && w.err == nil {
+		w.start()
+	}
+	if w.err != nil {
+		return w.err
+	}
+
+	w.sheet.WriteString("<row>")
+	for _, value := range record {
+		w.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(w.sheet, []byte(value)); err != nil {
+			w.err = err
+			return err
+		}
+		w.sheet.WriteString("</t></is></c>")
+	}
+	_, w.err = w.sheet.WriteString("</row>")
+	return w.err
+}
+
+// Flush writes the buffered rows to the underlying writer
+func (w *Writer) Flush() error {
+	if w.sheet == nil || w.err != nil {
+		return w.err
+	}
+	if w.err = w.sheet.Flush(); w.err != nil {
+		return w.err
+	}
+	w.err = w.zip.Flush()
+	return w.err
+}
+
+// Close completes the workbook. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.sheet == nil && w.err == nil {
+		w.start()
+	}
+	if w.err != nil {
+		return w.err
+	}
+	w.sheet.WriteString(sheetEnd)
+	if w.err = w.sheet.Flush(); w.err != nil {
+		return w.err
+	}
+	w.err = w.zip.Close()
+	return w.err
+}
+
+// start writes the workbook parts and opens the sheet
+func (w *Writer) start() {
+	workbook := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="` +
+		escape(w.name) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", workbookRels},
+	} {
+		if w.err = w.writePart(part.name, part.content); w.err != nil {
+			return
+		}
+	}
+
+	sheet, err := w.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		w.err = err
+		return
+	}
+	w.sheet = bufio.NewWriter(sheet)
+	_, w.err = w.sheet.WriteString(sheetStart)
+}
+
+func (w *Writer) writePart(name, content string) error {
+	part, err := w.zip.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(part, content)
+	return err
+}
+
+// escape escapes the text for an XML attribute
+func escape(text string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sheet reads the rows of the sheet of a workbook
+func sheet(t *testing.T, workbook []byte) [][]string {
+	archive, err := zip.NewReader(bytes.NewReader(workbook), int64(len(workbook)))
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(archive.File))
+	var content []byte
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+		if file.Name == "xl/worksheets/sheet1.xml" {
+			r, err := file.Open()
+			require.NoError(t, err)
+			content, err = io.ReadAll(r)
+			require.NoError(t, err)
+		}
+	}
+	assert.ElementsMatch(t, []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"}, names)
+
+	var parsed struct {
+		Rows []struct {
+			Cells []string `xml:"c>is>t"`
+		} `xml:"sheetData>row"`
+	}
+	require.NoError(t, xml.Unmarshal(content, &parsed))
+
+	rows := make([][]string, 0, len(parsed.Rows))
+	for _, row := range parsed.Rows {
+		rows = append(rows, row.Cells)
+	}
+	return rows
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, "users")
+
+	require.NoError(t, w.Write([]string{"id", "name"}))
+	require.NoError(t, w.Flush())
+	require.NoError(t, w.Write([]string{"1", `<Jane> & "Joe"`}))
+	require.NoError(t, w.Write([]string{"2", " padded "}))
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, [][]string{{"id", "name"}, {"1", `<Jane> & "Joe"`}, {"2", " padded "}}, sheet(t, buf.Bytes()))
+}
+
+func TestWriter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, NewWriter(&buf, "empty").Close())
+
+	assert.Empty(t, sheet(t, buf.Bytes()))
+}
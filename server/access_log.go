@@ -0,0 +1,26 @@
+package server
+
+import (
+	"io"
+	"os"
+)
+
+// openAccessLog opens the access log target: stdout, stderr, or a file that is
+// appended to so logrotate's copytruncate works. It returns nil when the
+// access log is disabled.
+func openAccessLog(target string) (io.Writer, error) {
+	switch target {
+	case "":
+		return nil, nil
+	case "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+
+	file, err := os.OpenFile(target, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
@@ -0,0 +1,438 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"boilerplate-go/config"
+	"boilerplate-go/internal/delivery/http/handler"
+	"boilerplate-go/internal/delivery/http/middleware"
+	"boilerplate-go/internal/delivery/http/route"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/buildinfo"
+	"boilerplate-go/pkg/i18n"
+	"boilerplate-go/pkg/jwt"
+	"boilerplate-go/pkg/securecookie"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/swaggo/swag"
+	"golang.org/x/time/rate"
+)
+
+// newRouter creates the handlers and mounts them with the middleware stack,
+// followed by the extra middleware. The routes of the extra registrars are
+// mounted after the built-in ones. A nil responses caches no responses.
+func newRouter(cfg *config.Config, infra *infrastructure, providers *Providers, uc *usecases, jwtKeys *jwt.KeySet, responses *middleware.ResponseCache, extra []gin.HandlerFunc, registrars []route.RouteRegistrar) (*gin.Engine, error) {
+	appLogger, appMetrics, healthMetrics := infra.logger, infra.metrics, infra.health
+
+	captchaGuard := handler.NewCaptchaGuard(
+		providers.Captcha,
+		cfg.Providers.Captcha.Mode,
+		cfg.Providers.Captcha.FailureThreshold,
+		cfg.Providers.Captcha.FailureWindow,
+	)
+
+	// Initialize handlers with dependencies
+	authHandler := handler.NewAuthHandler(uc.auth, captchaGuard, appLogger, appMetrics)
+	userHandler := handler.NewUserHandler(uc.user, appLogger, appMetrics)
+	sessionHandler := handler.NewSessionHandler(uc.session, appLogger, appMetrics)
+	orderHandler := handler.NewOrderHandler(uc.order, appLogger, appMetrics)
+	promotionHandler := handler.NewPromotionHandler(uc.promotion, appLogger, appMetrics)
+	jobHandler := handler.NewJobHandler(uc.job, appLogger, appMetrics)
+	notificationHandler := handler.NewNotificationHandler(uc.notification, appLogger, appMetrics)
+	uploadHandler := handler.NewUploadHandler(uc.upload, appLogger)
+	statsHandler := handler.NewStatsHandler(uc.stats, appLogger)
+	reportHandler := handler.NewReportHandler(uc.report, appLogger)
+	exportHandler := handler.NewExportHandler(uc.export, appLogger)
+	configHandler := handler.NewConfigHandler(uc.config, appLogger)
+	configHandler.SetResponseCache(responses)
+	budgetHandler := handler.NewBudgetHandler(uc.budget, appLogger)
+	organizationHandler := handler.NewOrganizationHandler(uc.organization, appLogger, appMetrics)
+	scimHandler := handler.NewSCIMHandler(uc.user, appLogger)
+	ssoHandler := handler.NewSSOHandler(uc.auth, uc.sso, appLogger, appMetrics)
+	consentHandler := handler.NewConsentHandler(uc.consent, appLogger)
+	instanceHandler := handler.NewInstanceHandler(healthMetrics, appLogger)
+
+	// Browser clients may hold the token in an encrypted session cookie
+	authCookies, err := newAuthCookies(cfg.Cookies, jwtKeys)
+	if err != nil {
+		return nil, err
+	}
+	if authCookies != nil {
+		authHandler.SetCookies(authCookies)
+		sessionHandler.SetCookies(authCookies)
+		ssoHandler.SetCookies(authCookies)
+	}
+
+	// Setup Gin router
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	// Only honour X-Forwarded-For from known proxies; ClientIP feeds the CAPTCHA and fraud checks
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid SERVER_TRUSTED_PROXIES: %w", err)
+	}
+	// Behind a CDN or platform, its header holds the client IP whatever the proxies
+	r.TrustedPlatform = cfg.Server.PlatformHeader()
+
+	// Translations of response messages
+	catalog, err := i18n.NewCatalog(cfg.I18n.DefaultLocale)
+	if err != nil {
+		return nil, fmt.Errorf("invalid I18N_DEFAULT_LOCALE: %w", err)
+	}
+	if cfg.I18n.CatalogDir != "" {
+		if err := catalog.LoadDir(cfg.I18n.CatalogDir); err != nil {
+			return nil, fmt.Errorf("failed to load message catalogs: %w", err)
+		}
+	}
+	uc.config.SetLocales(catalog.Locales())
+
+	// Access log in Apache format, alongside the JSON application logs
+	accessLog, err := openAccessLog(cfg.Server.AccessLog)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open access log: %w", err)
+	}
+
+	// Client device and location for audit logs and fraud screening
+	clientContext := middleware.ClientContextConfig{
+		Timeout:   cfg.Timeouts.Geolocation,
+		CacheTTL:  cfg.Providers.Geolocation.CacheTTL,
+		CacheSize: cfg.Providers.Geolocation.CacheSize,
+	}
+	if cfg.Providers.Geolocation.Requests && providers.Geolocation != nil {
+		clientContext.Locator = providers.Geolocation
+	}
+
+	// Setup professional middleware stack, arranged by the pipeline configuration
+	middlewareConfig := middleware.MiddlewareConfig{
+		Logger:          appLogger,
+		JWTSecret:       cfg.JWT.SecretKey,
+		ProblemDetails:  cfg.Server.ProblemDetails,
+		AccessLog:       accessLog,
+		AccessLogFormat: cfg.Server.AccessLogFormat,
+		Catalog:         catalog,
+		RateLimit: middleware.RateLimitConfig{
+			Anonymous:     rateLimitBucket(cfg.Server.RateLimit.Anonymous),
+			Authenticated: rateLimitBucket(cfg.Server.RateLimit.Authenticated),
+			Identify:      middleware.TokenAuthenticated(jwtKeys, authCookies),
+			Limiter:       infra.rateLimiter,
+		},
+		Stages: []middleware.Stage{
+			// Shed low-priority traffic under load; before metrics so shed requests are not counted as load
+			{Name: "load_shed", Handler: middleware.LoadShedMiddleware(appMetrics, appMetrics, middleware.LoadShedConfig{
+				MaxInFlight:   int64(cfg.Server.LoadShed.MaxInFlight),
+				MaxLatencyP99: cfg.Server.LoadShed.MaxLatencyP99,
+				RetryAfter:    cfg.Server.LoadShed.RetryAfter,
+				CriticalPaths: middleware.DefaultCriticalPaths,
+			})},
+			{Name: "metrics", Handler: appMetrics.MetricsMiddleware()},
+			{Name: "client_context", Handler: middleware.ClientContextMiddleware(clientContext, appLogger)},
+		},
+		Pipeline: middleware.PipelineConfig{
+			Order:    cfg.Server.Pipeline.Order,
+			Disabled: cfg.Server.Pipeline.Disabled,
+			Groups:   cfg.Server.Pipeline.Groups,
+		},
+	}
+	if err := middleware.SetupMiddlewares(r, middlewareConfig); err != nil {
+		return nil, fmt.Errorf("invalid middleware pipeline: %w", err)
+	}
+
+	// Clients reconnect to instances in rotation while this one drains
+	r.Use(middleware.DrainMiddleware(healthMetrics))
+
+	// Middleware of embedding programs
+	r.Use(extra...)
+
+	// Request signing for webhooks
+	var signatureMiddleware gin.HandlerFunc
+	if cfg.Signing.Enabled {
+		if len(cfg.Signing.Keys) == 0 {
+			return nil, fmt.Errorf("REQUEST_SIGNING_ENABLED requires REQUEST_SIGNING_KEYS")
+		}
+		signatureMiddleware = middleware.SignatureMiddleware(middleware.StaticKeyStore(cfg.Signing.Keys), cfg.Signing.ReplayWindow, appLogger)
+	}
+
+	// Identity providers provision users over SCIM with a static token
+	var provisioningMiddleware gin.HandlerFunc
+	if cfg.SCIM.Token != "" {
+		provisioningMiddleware = middleware.BearerTokenMiddleware(cfg.SCIM.Token, entity.ChangeSourceSCIM)
+	}
+
+	// Tokens issued before route scopes keep the access of a user until they expire
+	var legacyScopes []string
+	if cfg.JWT.AllowLegacyTokens {
+		legacyScopes = entity.UserScopes
+	}
+
+	// Users accept the legal documents once any is published
+	var consents middleware.ConsentChecker
+	if len(uc.consent.Documents()) > 0 {
+		consents = uc.consent
+	}
+
+	// Each feature module registers its own routes
+	router := route.NewRouter(r, route.Config{
+		JWTKeys:                jwtKeys,
+		SessionValidator:       uc.session,
+		DedupWindow:            cfg.Server.DedupWindow,
+		LegacyScopes:           legacyScopes,
+		AuditLogger:            appLogger,
+		Organizations:          uc.organization,
+		SignatureMiddleware:    signatureMiddleware,
+		ProvisioningMiddleware: provisioningMiddleware,
+		Cookies:                authCookies,
+		ResponseCache:          responses,
+		Consents:               consents,
+		InFlight:               infra.inFlight,
+		InFlightTTL:            cfg.Server.RateLimit.InFlightTTL,
+	})
+	router.Register(authHandler, userHandler, sessionHandler, orderHandler, promotionHandler, jobHandler, notificationHandler, uploadHandler, statsHandler, reportHandler, exportHandler, configHandler, budgetHandler, organizationHandler, scimHandler, instanceHandler)
+	// Organizations sign in with their identity provider once the app has a
+	// page to redirect back to
+	if cfg.SSO.RedirectURL != "" {
+		router.Register(ssoHandler)
+	}
+	if consents != nil {
+		router.Register(consentHandler)
+	}
+	router.Register(registrars...)
+
+	// Serve avatars kept in local storage unless a CDN serves them. A new
+	// avatar gets a new name, so they are cached indefinitely
+	if cfg.Providers.FileStorage.Provider == "local" && cfg.Providers.FileStorage.PublicURL == "" {
+		avatarDir := filepath.Join(cfg.Providers.FileStorage.Local.BasePath, "avatars")
+		if err := os.MkdirAll(avatarDir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create avatar directory: %w", err)
+		}
+		avatarHandler, err := handler.NewStaticHandler(avatarDir, localFilesPath+"/avatars", handler.StaticOptions{
+			MaxAge:    365 * 24 * time.Hour,
+			Immutable: true,
+		}, appLogger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serve avatars: %w", err)
+		}
+		r.GET(localFilesPath+"/avatars/*filepath", avatarHandler.Serve)
+		r.HEAD(localFilesPath+"/avatars/*filepath", avatarHandler.Serve)
+	}
+
+	// Serve public files or a single-page app
+	if cfg.Static.Dir != "" {
+		staticHandler, err := handler.NewStaticHandler(cfg.Static.Dir, cfg.Static.Path, handler.StaticOptions{
+			MaxAge: cfg.Static.MaxAge,
+			SPA:    cfg.Static.SPA,
+		}, appLogger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serve static files: %w", err)
+		}
+		if staticPath := strings.TrimSuffix(cfg.Static.Path, "/"); staticPath != "" {
+			r.GET(staticPath+"/*filepath", staticHandler.Serve)
+			r.HEAD(staticPath+"/*filepath", staticHandler.Serve)
+		} else {
+			// Files at the root are served for paths no route matches
+			r.NoRoute(staticHandler.Serve)
+		}
+	}
+
+	// Serve signed download URLs issued by local storage
+	if signedFiles, ok := providers.FileStorage.(handler.SignedFileStore); ok {
+		fileHandler := handler.NewFileHandler(signedFiles, appLogger)
+		r.GET(localSignedFilesPath+"/*id", fileHandler.DownloadSigned)
+	}
+
+	// Add metrics endpoint
+	r.GET("/metrics", gin.WrapH(appMetrics.Handler()))
+	if cfg.Metrics.SLO.Enabled {
+		r.GET("/metrics/slo-rules", gin.WrapH(appMetrics.SLORulesHandler()))
+	}
+
+	// API documentation
+	if cfg.Server.SwaggerEnabled {
+		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+		router.Register(handler.NewDocsHandler(router.Routes, func() (string, error) {
+			return swag.ReadDoc()
+		}, appLogger))
+	}
+
+	// Build information
+	build := buildinfo.Get()
+	r.GET("/version", func(c *gin.Context) {
+		c.JSON(http.StatusOK, build)
+	})
+
+	// Enhanced health check endpoint
+	r.GET("/health", func(c *gin.Context) {
+		status := "ok"
+		httpStatus := http.StatusOK
+
+		degraded := healthMetrics.Degraded()
+		if !healthMetrics.IsHealthy() {
+			status = "unhealthy"
+			httpStatus = http.StatusServiceUnavailable
+		} else if len(degraded) > 0 {
+			status = "degraded"
+		}
+
+		c.JSON(httpStatus, map[string]interface{}{
+			"status":    status,
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+			"uptime":    healthMetrics.Uptime().String(),
+			"version":   build.Version,
+			"commit":    build.Commit,
+			"env":       cfg.Env,
+			"payment": map[string]string{
+				"provider":    cfg.Providers.Payment.Provider,
+				"environment": cfg.Providers.Payment.Environment(),
+			},
+			"checks": map[string]interface{}{
+				"database":      healthMetrics.DatabaseUp,
+				"external_apis": healthMetrics.ExternalAPIStatus(),
+				"degraded":      degraded,
+			},
+		})
+	})
+
+	// Readiness probe
+	r.GET("/ready", func(c *gin.Context) {
+		if healthMetrics.Draining() {
+			c.JSON(http.StatusServiceUnavailable, map[string]string{"status": "draining"})
+		} else if healthMetrics.DatabaseUp {
+			c.JSON(http.StatusOK, map[string]string{"status": "ready"})
+		} else {
+			c.JSON(http.StatusServiceUnavailable, map[string]string{"status": "not ready"})
+		}
+	})
+
+	// Liveness probe
+	r.GET("/live", func(c *gin.Context) {
+		c.JSON(http.StatusOK, map[string]string{"status": "alive"})
+	})
+
+	return r, nil
+}
+
+// newHTTPServer serves the router once the application starts. It is
+// registered last, so it stops first and in-flight requests still have every
+// other component available. The listener is opened on start, so a port in
+// use fails Start. With TLS, a second listener on RedirectAddr redirects to
+// HTTPS.
+func newHTTPServer(cfg config.ServerConfig, router http.Handler, infra *infrastructure, lc *lifecycle) (*http.Server, error) {
+	// Configure HTTP server with production settings
+	srv := &http.Server{
+		Addr:           fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+		Handler:        router,
+		ReadTimeout:    cfg.ReadTimeout,
+		WriteTimeout:   cfg.WriteTimeout,
+		MaxHeaderBytes: cfg.MaxHeaderBytes,
+		IdleTimeout:    60 * time.Second,
+		Protocols:      newProtocols(cfg),
+	}
+
+	if cfg.TLS.Enabled() {
+		tlsConfig, redirect, err := newTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		srv.TLSConfig = tlsConfig
+
+		if cfg.TLS.RedirectAddr != "" {
+			redirectSrv := &http.Server{
+				Addr:              cfg.TLS.RedirectAddr,
+				Handler:           redirect,
+				ReadHeaderTimeout: cfg.ReadTimeout,
+				IdleTimeout:       60 * time.Second,
+			}
+			lc.append(hook{
+				name: "HTTP redirect server",
+				start: func() error {
+					go func() {
+						infra.logger.WithField("addr", redirectSrv.Addr).Info("Starting HTTP redirect server")
+						if err := redirectSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+							infra.logger.WithError(err).Fatal("Failed to start HTTP redirect server")
+						}
+					}()
+					return nil
+				},
+				stop: redirectSrv.Shutdown,
+			})
+		}
+	}
+
+	lc.append(hook{
+		name: "HTTP server",
+		start: func() error {
+			listener, err := newListener(cfg)
+			if err != nil {
+				return fmt.Errorf("failed to listen: %w", err)
+			}
+
+			go func() {
+				infra.logger.WithFields(map[string]interface{}{
+					"network":       cfg.Network,
+					"addr":          listener.Addr().String(),
+					"read_timeout":  cfg.ReadTimeout,
+					"write_timeout": cfg.WriteTimeout,
+					"tls":           srv.TLSConfig != nil,
+					"http2":         cfg.HTTP2,
+				}).Info("Starting HTTP server")
+
+				var err error
+				if srv.TLSConfig != nil {
+					// Certificates come from the TLS configuration
+					err = srv.ServeTLS(listener, "", "")
+				} else {
+					err = srv.Serve(listener)
+				}
+				if err != nil && err != http.ErrServerClosed {
+					infra.logger.WithError(err).Fatal("HTTP server failed")
+				}
+			}()
+			return nil
+		},
+		// Shutdown closes the listener, which removes a unix socket
+		stop: srv.Shutdown,
+	})
+	return srv, nil
+}
+
+// newAuthCookies returns the session cookies of browser clients, or nil when
+// sign-ins hand out tokens only
+func newAuthCookies(cfg config.AuthCookieConfig, keys *jwt.KeySet) (*middleware.AuthCookies, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	codec, err := securecookie.New(cfg.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie codec: %w", err)
+	}
+
+	sameSite := http.SameSiteLaxMode
+	switch cfg.SameSite {
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	}
+	return middleware.NewAuthCookies(middleware.AuthCookieConfig{
+		Name:      cfg.Name,
+		CSRFName:  cfg.CSRFName,
+		Domain:    cfg.Domain,
+		Secure:    cfg.Secure,
+		SameSite:  sameSite,
+		OmitToken: cfg.Mode == config.AuthModeCookie,
+	}, codec, keys), nil
+}
+
+// rateLimitBucket converts the limits of a class of traffic for the middleware
+func rateLimitBucket(cfg config.RateLimitBucket) middleware.RateLimitBucket {
+	return middleware.RateLimitBucket{
+		Limit:     rate.Limit(cfg.RequestsPerSecond),
+		Burst:     cfg.Burst,
+		SoftLimit: rate.Limit(cfg.SoftRequestsPerSecond),
+	}
+}
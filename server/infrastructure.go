@@ -0,0 +1,250 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/database"
+	"boilerplate-go/infrastructure/dbpool"
+	"boilerplate-go/infrastructure/leader"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/infrastructure/ratelimit"
+	"boilerplate-go/infrastructure/startup"
+	"boilerplate-go/internal/delivery/http/middleware"
+	"boilerplate-go/pkg/lock"
+	"boilerplate-go/pkg/redis"
+
+	"github.com/sirupsen/logrus"
+)
+
+// infrastructure holds the connections and observability shared by every layer
+type infrastructure struct {
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+	health  *metrics.HealthMetrics
+	db      *database.PostgresDB
+	// rateLimiter and inFlight share the rate and concurrency limits of the
+	// instances; nil keeps them per instance
+	rateLimiter middleware.RateLimiter
+	inFlight    middleware.InFlightLimiter
+	// locker holds the locks shared by the instances
+	locker lock.Locker
+	// leader tells whether the instance runs the singleton processes
+	leader *leader.Elector
+}
+
+// startLogShipping ships logs to Loki or Elasticsearch in addition to stdout.
+// The shipper is stopped last so shutdown logs are shipped too.
+func startLogShipping(cfg config.LoggingConfig, log *logger.Logger, lc *lifecycle) error {
+	if cfg.Sink == "" {
+		return nil
+	}
+
+	shipper, err := logger.NewShipper(logger.ShipperConfig{
+		Sink:          cfg.Sink,
+		URL:           cfg.SinkURL,
+		Index:         cfg.Index,
+		Labels:        cfg.Labels,
+		Username:      cfg.Username,
+		Password:      cfg.Password,
+		APIKey:        cfg.APIKey,
+		BatchSize:     cfg.BatchSize,
+		FlushInterval: cfg.FlushInterval,
+		BufferSize:    cfg.BufferSize,
+		Timeout:       cfg.Timeout,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize log shipping: %w", err)
+	}
+	log.AddHook(shipper)
+
+	// Ship the reason of a fatal exit too
+	logrus.RegisterExitHandler(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shipper.Close(ctx)
+	})
+	lc.append(hook{
+		name: "log shipping",
+		stop: func(ctx context.Context) error {
+			if err := shipper.Close(ctx); err != nil {
+				// The logger no longer ships, so report to stderr as well
+				fmt.Fprintf(os.Stderr, "Failed to ship remaining logs: %v\n", err)
+				return err
+			}
+			return nil
+		},
+	})
+	return nil
+}
+
+// newInfrastructure connects to the database, waiting for it and for the
+// optional services in providers to come up. Without connectDB the database
+// is left out, as the repositories do not use it.
+func newInfrastructure(cfg *config.Config, log *logger.Logger, m *metrics.Metrics, providers *Providers, connectDB bool, lc *lifecycle) (*infrastructure, error) {
+	infra := &infrastructure{
+		logger:  log,
+		metrics: m,
+		health:  metrics.NewHealthMetrics(),
+	}
+
+	// Wait for the database and optional services started alongside the application
+	startupCtx, stopStartupRetries := context.WithCancel(context.Background())
+	var dependencies []startup.Dependency
+	if connectDB {
+		dependencies = append(dependencies, startup.Dependency{
+			Name:     "database",
+			Critical: true,
+			Check: func(ctx context.Context) error {
+				conn, err := database.NewPostgresConnection(ctx, cfg.Database)
+				if err != nil {
+					return err
+				}
+				infra.db = conn
+				return nil
+			},
+		})
+	}
+	if pinger, ok := providers.Scanner.(startup.Pinger); ok {
+		dependencies = append(dependencies, startup.Dependency{
+			Name:     "scanner",
+			Check:    pinger.Ping,
+			OnStatus: func(available bool) { infra.health.SetDegraded("scanner", !available) },
+		})
+	}
+	if _, err := startup.NewWaiter(cfg.Startup, log).Wait(startupCtx, dependencies...); err != nil {
+		stopStartupRetries()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	lc.append(hook{
+		name: "database",
+		stop: func(ctx context.Context) error {
+			stopStartupRetries()
+			if infra.db == nil {
+				return nil
+			}
+			return infra.db.Close()
+		},
+	})
+	if infra.db == nil {
+		infra.health.SetDatabaseStatus(true)
+		return infra, nil
+	}
+
+	// Test database connection and update health metrics
+	if err := infra.db.Ping(); err != nil {
+		log.WithError(err).Error("Database health check failed")
+		infra.health.SetDatabaseStatus(false)
+	} else {
+		log.Info("Database connection healthy")
+		infra.health.SetDatabaseStatus(true)
+	}
+
+	// Update database connection metrics
+	stats := infra.db.DB.Stats()
+	infra.metrics.SetDatabaseConnections(float64(stats.OpenConnections))
+	newPoolAdvisor(cfg.Database, infra, lc)
+
+	return infra, nil
+}
+
+// newPoolAdvisor samples the database connection pool on a schedule,
+// recording it in the metrics and warning, or growing the pool, when queries
+// wait for connections
+func newPoolAdvisor(cfg config.DatabaseConfig, infra *infrastructure, lc *lifecycle) {
+	if cfg.PoolInterval <= 0 {
+		return
+	}
+
+	advisor := dbpool.NewAdvisor(infra.db.DB, dbpool.Config{
+		Interval:  cfg.PoolInterval,
+		MaxWait:   cfg.PoolMaxWait,
+		Autoscale: cfg.PoolAutoscale,
+		MinOpen:   cfg.MaxOpenConns,
+		MaxOpen:   cfg.PoolMaxOpen,
+	}, infra.metrics, infra.logger)
+	lc.append(hook{
+		name: "database pool advisor",
+		start: func() error {
+			advisor.Start()
+			return nil
+		},
+		stop: advisor.Stop,
+	})
+}
+
+// rateLimitRedisRetry is how long Redis is left alone after failing
+const rateLimitRedisRetry = 10 * time.Second
+
+// newRateLimiter keeps the rate and concurrency limits in Redis when
+// configured, so replicas share them. Redis is not waited for: while it is unavailable each instance
+// limits requests on its own and the application is reported degraded.
+func newRateLimiter(cfg config.RateLimitConfig, infra *infrastructure, lc *lifecycle) error {
+	if cfg.RedisURL == "" {
+		return nil
+	}
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return fmt.Errorf("invalid RATE_LIMIT_REDIS_URL: %w", err)
+	}
+	opts.DialTimeout, opts.Timeout = cfg.RedisTimeout, cfg.RedisTimeout
+
+	limiter := ratelimit.NewRedis(redis.New(opts), cfg.RedisPrefix, rateLimitRedisRetry, infra.logger, func(available bool) {
+		infra.health.SetDegraded("rate_limit_redis", !available)
+	})
+	infra.rateLimiter, infra.inFlight = limiter, limiter
+	lc.append(hook{
+		name: "rate limit redis",
+		stop: func(ctx context.Context) error {
+			return limiter.Close()
+		},
+	})
+	return nil
+}
+
+// newLocker creates the locks shared by the instances in the configured
+// backend, and elects the leader of the instances with them. Without a
+// database, as with an in-memory datastore, advisory locks fall back to
+// memory.
+func newLocker(cfg config.LockConfig, infra *infrastructure, lc *lifecycle) error {
+	switch {
+	case cfg.Backend == "redis":
+		opts, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			return fmt.Errorf("invalid LOCK_REDIS_URL: %w", err)
+		}
+		locker := lock.NewRedis(redis.New(opts), cfg.RedisPrefix)
+		infra.locker = locker
+		lc.append(hook{
+			name: "lock redis",
+			stop: func(ctx context.Context) error {
+				return locker.Close()
+			},
+		})
+	case cfg.Backend == "postgres" && infra.db != nil:
+		infra.locker = lock.NewPostgres(infra.db.DB)
+	default:
+		infra.locker = lock.NewMemory()
+	}
+
+	// The leader is elected before the job schedules start and steps down
+	// after they stopped
+	infra.leader = leader.NewElector(infra.locker, leader.Config{
+		Role:          "scheduler",
+		TTL:           cfg.LeaderTTL,
+		RenewInterval: cfg.LeaderRenew,
+	}, infra.metrics, infra.logger)
+	lc.append(hook{
+		name: "leader election",
+		start: func() error {
+			infra.leader.Start()
+			return nil
+		},
+		stop: infra.leader.Stop,
+	})
+	return nil
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"fmt"
+	"os"
+
+	"boilerplate-go/config"
+	"boilerplate-go/pkg/jwt"
+)
+
+// loadJWTKeySet builds the token signing key set from configuration
+func loadJWTKeySet(cfg config.JWTConfig) (*jwt.KeySet, error) {
+	if cfg.Algorithm == "" || cfg.Algorithm == jwt.AlgorithmHS256 {
+		return jwt.NewHMACKeySet(cfg.SecretKey).WithClaimsValidation(cfg.Issuer, cfg.Audience), nil
+	}
+
+	if len(cfg.PrivateKeyFiles) == 0 {
+		return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILES is required for %s", cfg.Algorithm)
+	}
+
+	keys := make(map[string][]byte, len(cfg.PrivateKeyFiles))
+	for kid, path := range cfg.PrivateKeyFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT key %q: %w", kid, err)
+		}
+		keys[kid] = data
+	}
+
+	keySet, err := jwt.NewKeySetFromPEM(cfg.Algorithm, cfg.KeyID, keys)
+	if err != nil {
+		return nil, err
+	}
+	return keySet.WithClaimsValidation(cfg.Issuer, cfg.Audience), nil
+}
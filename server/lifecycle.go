@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+
+	"boilerplate-go/infrastructure/logger"
+)
+
+// hook is the start and stop functions of a long-running component. Either
+// may be nil.
+type hook struct {
+	name  string
+	start func() error
+	stop  func(ctx context.Context) error
+}
+
+// lifecycle starts components in the order they registered and stops them in
+// reverse, so a component is stopped before the ones it depends on. Modules
+// register their components as they build them.
+type lifecycle struct {
+	hooks  []hook
+	logger *logger.Logger
+}
+
+func newLifecycle(log *logger.Logger) *lifecycle {
+	return &lifecycle{logger: log}
+}
+
+func (l *lifecycle) append(h hook) {
+	l.hooks = append(l.hooks, h)
+}
+
+// start starts the components, stopping the ones already started when one
+// fails.
+func (l *lifecycle) start(ctx context.Context) error {
+	for i, h := range l.hooks {
+		if h.start == nil {
+			continue
+		}
+		if err := h.start(); err != nil {
+			l.stopHooks(ctx, l.hooks[:i])
+			return err
+		}
+	}
+	return nil
+}
+
+// stop stops every component, logging the ones that did not stop in time.
+func (l *lifecycle) stop(ctx context.Context) {
+	l.stopHooks(ctx, l.hooks)
+}
+
+func (l *lifecycle) stopHooks(ctx context.Context, hooks []hook) {
+	for i := len(hooks) - 1; i >= 0; i-- {
+		h := hooks[i]
+		if h.stop == nil {
+			continue
+		}
+		entry := l.logger.WithField("component", h.name)
+		if err := h.stop(ctx); err != nil {
+			entry.WithError(err).Error("Component did not stop cleanly")
+		} else {
+			entry.Info("Component stopped")
+		}
+	}
+}
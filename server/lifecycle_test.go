@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"boilerplate-go/infrastructure/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recordingHook(name string, calls *[]string, startErr error) hook {
+	return hook{
+		name: name,
+		start: func() error {
+			*calls = append(*calls, "start "+name)
+			return startErr
+		},
+		stop: func(ctx context.Context) error {
+			*calls = append(*calls, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestLifecycle_Order(t *testing.T) {
+	var calls []string
+	lc := newLifecycle(logger.NewLogger())
+	lc.append(recordingHook("database", &calls, nil))
+	lc.append(hook{name: "log shipping"})
+	lc.append(recordingHook("server", &calls, nil))
+
+	assert.NoError(t, lc.start(context.Background()))
+	lc.stop(context.Background())
+
+	assert.Equal(t, []string{"start database", "start server", "stop server", "stop database"}, calls)
+}
+
+func TestLifecycle_StartFailure(t *testing.T) {
+	var calls []string
+	lc := newLifecycle(logger.NewLogger())
+	lc.append(recordingHook("database", &calls, nil))
+	lc.append(recordingHook("jobs", &calls, errors.New("boom")))
+	lc.append(recordingHook("server", &calls, nil))
+
+	assert.EqualError(t, lc.start(context.Background()), "boom")
+
+	// Only the components already started are stopped
+	assert.Equal(t, []string{"start database", "start jobs", "stop database"}, calls)
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"boilerplate-go/config"
+)
+
+// listenFDsStart is the first file descriptor systemd passes sockets on
+const listenFDsStart = 3
+
+// newListener opens the listener of the HTTP server: a TCP address, a unix
+// socket, or the socket systemd passed to the process.
+func newListener(cfg config.ServerConfig) (net.Listener, error) {
+	switch cfg.Network {
+	case "unix":
+		return listenUnix(cfg.SocketPath, cfg.SocketMode)
+	case "systemd":
+		return systemdListener()
+	default:
+		return net.Listen("tcp", net.JoinHostPort(cfg.Host, cfg.Port))
+	}
+}
+
+// listenUnix listens on a unix socket, replacing a socket left behind by a
+// process that did not shut down. The socket is removed when the listener
+// closes.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		// A socket that accepts connections belongs to a running instance
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return listener, nil
+}
+
+// systemdListener returns the socket passed by systemd socket activation.
+// systemd keeps the socket open across restarts, so connections arriving
+// while the server restarts wait instead of being refused.
+func systemdListener() (net.Listener, error) {
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return nil, fmt.Errorf("no socket passed by systemd: LISTEN_PID is not this process")
+	}
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds != 1 {
+		return nil, fmt.Errorf("expected 1 socket passed by systemd, got LISTEN_FDS=%q", os.Getenv("LISTEN_FDS"))
+	}
+
+	// Child processes must not take the socket as theirs
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	file := os.NewFile(listenFDsStart, "systemd socket")
+	defer file.Close()
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("socket passed by systemd is not a listener: %w", err)
+	}
+	return listener, nil
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+
+	listener, err := listenUnix(path, 0o600)
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// A running instance keeps its socket
+	_, err = listenUnix(path, 0o600)
+	assert.ErrorContains(t, err, "in use")
+
+	// Closing removes the socket
+	require.NoError(t, listener.Close())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestListenUnix_StaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+
+	// A socket left behind by a crashed process
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	listener, err := listenUnix(path, 0o660)
+	require.NoError(t, err)
+	listener.Close()
+}
+
+func TestListenUnix_NotASocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0o600))
+
+	_, err := listenUnix(path, 0o660)
+	assert.ErrorContains(t, err, "not a socket")
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+}
+
+func TestSystemdListener_NotActivated(t *testing.T) {
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+
+	_, err := systemdListener()
+	assert.ErrorContains(t, err, "LISTEN_PID")
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "2")
+	_, err = systemdListener()
+	assert.ErrorContains(t, err, "expected 1 socket")
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"fmt"
+	"os"
+
+	"boilerplate-go/config"
+	"boilerplate-go/pkg/password"
+)
+
+// loadPasswordPolicy builds the password policy from configuration
+func loadPasswordPolicy(cfg config.PasswordConfig) (*password.Policy, error) {
+	policy := &password.Policy{
+		MinLength:        cfg.MinLength,
+		RequireUppercase: cfg.RequireUppercase,
+		RequireLowercase: cfg.RequireLowercase,
+		RequireDigit:     cfg.RequireDigit,
+		RequireSymbol:    cfg.RequireSymbol,
+		RejectSimilar:    cfg.RejectSimilar,
+	}
+	if cfg.RejectCommon {
+		policy.BanCommon()
+	}
+
+	if cfg.BannedFile != "" {
+		file, err := os.Open(cfg.BannedFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open banned password list: %w", err)
+		}
+		defer file.Close()
+		if err := policy.BanList(file); err != nil {
+			return nil, fmt.Errorf("failed to read banned password list: %w", err)
+		}
+	}
+	return policy, nil
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/probe"
+	"boilerplate-go/infrastructure/startup"
+	"boilerplate-go/internal/domain/provider"
+)
+
+// newProviderProbes probes the payment and email providers enabled in cfg on
+// a schedule, reporting them in the health checks. The probes follow the
+// providers through profile switches.
+func newProviderProbes(cfg config.ProbeConfig, providers *Providers, infra *infrastructure, lc *lifecycle) {
+	if cfg.Interval <= 0 {
+		return
+	}
+
+	var probes []probe.Probe
+	if cfg.Payment {
+		probes = append(probes, probe.Probe{Name: "payment", Check: func(ctx context.Context) error {
+			return ping(ctx, providers.Payment)
+		}})
+	}
+	if cfg.Email {
+		probes = append(probes, probe.Probe{Name: "email", Check: func(ctx context.Context) error {
+			return ping(ctx, providers.Email)
+		}})
+	}
+	if len(probes) == 0 {
+		return
+	}
+
+	prober := probe.NewProber(cfg.Interval, cfg.Timeout, infra.health, infra.logger, probes...)
+	lc.append(hook{
+		name: "provider health probes",
+		start: func() error {
+			prober.Start()
+			return nil
+		},
+		stop: prober.Stop,
+	})
+}
+
+// paymentWrapper and emailWrapper are decorators of providers, such as the
+// circuit breaker
+type paymentWrapper interface {
+	Unwrap() provider.PaymentProvider
+}
+
+type emailWrapper interface {
+	Unwrap() provider.EmailProvider
+}
+
+// ping probes the provider, or the one it decorates
+func ping(ctx context.Context, p interface{}) error {
+	for p != nil {
+		if pinger, ok := p.(startup.Pinger); ok {
+			return pinger.Ping(ctx)
+		}
+		switch wrapper := p.(type) {
+		case paymentWrapper:
+			p = wrapper.Unwrap()
+		case emailWrapper:
+			p = wrapper.Unwrap()
+		default:
+			return probe.ErrNoProbe
+		}
+	}
+	return probe.ErrNoProbe
+}
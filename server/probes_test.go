@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/probe"
+	"boilerplate-go/internal/provider/switchable"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPing_ThroughDecorators(t *testing.T) {
+	status := http.StatusOK
+	var paths []string
+	stripe := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	defer stripe.Close()
+
+	cfg := config.LoadConfig()
+	cfg.Providers.Payment.Provider = "stripe"
+	cfg.Providers.Payment.Stripe.BaseURL = stripe.URL
+	cfg.Providers.Payment.CircuitFailureThreshold = 1
+
+	// The circuit breaker and environment decorators are unwrapped
+	payment, err := NewProviderFactory(cfg, logger.NewLogger()).CreatePaymentProvider()
+	require.NoError(t, err)
+	current := switchable.NewPaymentProvider(payment)
+
+	require.NoError(t, ping(context.Background(), current))
+	assert.Equal(t, []string{"/balance"}, paths)
+
+	status = http.StatusUnauthorized
+	assert.ErrorContains(t, ping(context.Background(), current), "status 401")
+}
+
+func TestPing_NoProbe(t *testing.T) {
+	var fake struct{}
+	assert.ErrorIs(t, ping(context.Background(), fake), probe.ErrNoProbe)
+}
@@ -0,0 +1,301 @@
+package server
+
+import (
+	"fmt"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/internal/provider/captcha"
+	"boilerplate-go/internal/provider/fraud"
+	"boilerplate-go/internal/provider/geolocation"
+	"boilerplate-go/internal/provider/notification"
+	"boilerplate-go/internal/provider/payment"
+	"boilerplate-go/internal/provider/scanner"
+	"boilerplate-go/internal/provider/storage"
+	"boilerplate-go/pkg/circuitbreaker"
+)
+
+// ProviderFactory handles the creation of providers based on configuration
+type ProviderFactory struct {
+	config   *config.Config
+	logger   *logger.Logger
+	recorder payment.Recorder
+}
+
+func NewProviderFactory(config *config.Config, logger *logger.Logger) *ProviderFactory {
+	return &ProviderFactory{
+		config: config,
+		logger: logger,
+	}
+}
+
+// SetPaymentRecorder records the calls to the payment provider, e.g. in metrics.
+func (f *ProviderFactory) SetPaymentRecorder(recorder payment.Recorder) {
+	f.recorder = recorder
+}
+
+// CreatePaymentProvider creates and returns the configured payment provider,
+// guarded by a circuit breaker unless it is disabled. Its calls are annotated
+// with the provider environment.
+func (f *ProviderFactory) CreatePaymentProvider() (provider.PaymentProvider, error) {
+	var paymentProvider provider.PaymentProvider
+	switch f.config.Providers.Payment.Provider {
+	case "stripe":
+		paymentProvider = f.createStripeProvider()
+	case "paypal":
+		paymentProvider = f.createPayPalProvider()
+	default:
+		return nil, fmt.Errorf("unsupported payment provider: %s", f.config.Providers.Payment.Provider)
+	}
+
+	if f.config.Providers.Payment.CircuitFailureThreshold > 0 {
+		breaker := circuitbreaker.New(f.config.Providers.Payment.CircuitFailureThreshold, f.config.Providers.Payment.CircuitOpenTimeout)
+		paymentProvider = payment.NewCircuitBreakerProvider(paymentProvider, breaker, f.logger)
+	}
+
+	paymentConfig := f.config.Providers.Payment
+	return payment.NewEnvironmentProvider(paymentProvider, paymentConfig.Provider, paymentConfig.Environment(), f.recorder), nil
+}
+
+// CreateNotificationProvider creates and returns the unified notification provider
+func (f *ProviderFactory) CreateNotificationProvider() (provider.NotificationProvider, error) {
+	notificationConfig := notification.UnifiedConfig{
+		EmailConfig: f.emailConfig(),
+		SMSConfig: notification.SMSConfig{
+			BaseURL:    f.config.Providers.Notification.SMS.BaseURL,
+			APIKey:     f.config.Providers.Notification.SMS.APIKey,
+			FromNumber: f.config.Providers.Notification.SMS.FromNumber,
+			Timeout:    f.config.Providers.Notification.SMS.Timeout,
+		},
+		DefaultLocale: f.config.Providers.Notification.DefaultLocale,
+	}
+
+	return notification.NewUnifiedNotificationProvider(notificationConfig, f.logger)
+}
+
+// CreateEmailProvider creates the email provider used for bulk sends.
+func (f *ProviderFactory) CreateEmailProvider() provider.EmailProvider {
+	return notification.NewEmailProvider(f.emailConfig(), f.logger)
+}
+
+func (f *ProviderFactory) emailConfig() notification.EmailConfig {
+	emailConfig := f.config.Providers.Notification.Email
+
+	return notification.EmailConfig{
+		BaseURL:   emailConfig.BaseURL,
+		APIKey:    emailConfig.APIKey,
+		FromEmail: emailConfig.FromEmail,
+		Timeout:   emailConfig.Timeout,
+		Attachments: notification.AttachmentPolicy{
+			MaxTotalSize: emailConfig.MaxAttachmentsSize,
+			MaxCount:     emailConfig.MaxAttachments,
+			AllowedTypes: emailConfig.AllowedAttachmentTypes,
+		},
+	}
+}
+
+// CreateCaptchaProvider creates the configured CAPTCHA provider.
+// It returns nil when no CAPTCHA provider is configured.
+func (f *ProviderFactory) CreateCaptchaProvider() (provider.CaptchaProvider, error) {
+	captchaConfig := captcha.Config{
+		SecretKey: f.config.Providers.Captcha.SecretKey,
+		MinScore:  f.config.Providers.Captcha.MinScore,
+		Timeout:   f.config.Providers.Captcha.Timeout,
+	}
+
+	switch f.config.Providers.Captcha.Provider {
+	case "":
+		return nil, nil
+	case "recaptcha":
+		return captcha.NewRecaptchaProvider(captchaConfig, f.logger), nil
+	case "hcaptcha":
+		return captcha.NewHCaptchaProvider(captchaConfig, f.logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported captcha provider: %s", f.config.Providers.Captcha.Provider)
+	}
+}
+
+// CreateFraudProvider creates the configured fraud scoring provider.
+// It returns nil when fraud screening is disabled.
+func (f *ProviderFactory) CreateFraudProvider() (provider.FraudProvider, error) {
+	fraudConfig := f.config.Providers.Fraud
+
+	switch fraudConfig.Provider {
+	case "":
+		return nil, nil
+	case "rules":
+		return fraud.NewRulesProvider(fraud.RulesConfig{
+			HighAmount:        fraudConfig.HighAmount,
+			NewAccountAge:     fraudConfig.NewAccountAge,
+			MaxItems:          fraudConfig.MaxItems,
+			BlockedCountries:  fraudConfig.BlockedCountries,
+			DisposableDomains: fraudConfig.DisposableDomains,
+		}, f.logger), nil
+	case "external":
+		return fraud.NewExternalProvider(fraud.ExternalConfig{
+			BaseURL: fraudConfig.BaseURL,
+			APIKey:  fraudConfig.APIKey,
+			Timeout: fraudConfig.Timeout,
+		}, f.logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported fraud provider: %s", fraudConfig.Provider)
+	}
+}
+
+// CreateGeolocationProvider creates the configured IP geolocation provider.
+// It returns nil when geolocation is disabled.
+func (f *ProviderFactory) CreateGeolocationProvider() (provider.GeolocationProvider, error) {
+	geoConfig := f.config.Providers.Geolocation
+
+	switch geoConfig.Provider {
+	case "":
+		return nil, nil
+	case "ipapi":
+		return geolocation.NewIPAPIProvider(geolocation.IPAPIConfig{
+			BaseURL: geoConfig.BaseURL,
+			APIKey:  geoConfig.APIKey,
+			Timeout: geoConfig.Timeout,
+		}, f.logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported geolocation provider: %s", geoConfig.Provider)
+	}
+}
+
+// CreateScannerProvider creates the configured malware scanning provider.
+// It returns nil when scanning is disabled.
+func (f *ProviderFactory) CreateScannerProvider() (provider.ScannerProvider, error) {
+	scannerConfig := f.config.Providers.Scanner
+
+	switch scannerConfig.Provider {
+	case "":
+		return nil, nil
+	case "clamav":
+		return scanner.NewClamAVProvider(scanner.ClamAVConfig{
+			Address: scannerConfig.ClamAVAddress,
+			Timeout: scannerConfig.Timeout,
+		}, f.logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported scanner provider: %s", scannerConfig.Provider)
+	}
+}
+
+// localFilesPath is where the API serves files kept in local storage
+const localFilesPath = "/files"
+
+// localSignedFilesPath is where the API serves local files through signed URLs
+const localSignedFilesPath = localFilesPath + "/signed"
+
+// CreateFileStorageProvider creates the configured file storage provider.
+func (f *ProviderFactory) CreateFileStorageProvider() (provider.FileStorageProvider, error) {
+	storageConfig := f.config.Providers.FileStorage
+
+	switch storageConfig.Provider {
+	case "local":
+		publicURL := storageConfig.PublicURL
+		if publicURL == "" {
+			publicURL = localFilesPath
+		}
+		return storage.NewLocalStorageProvider(storage.LocalConfig{
+			BasePath:   storageConfig.Local.BasePath,
+			PublicURL:  publicURL,
+			SignedURL:  localSignedFilesPath,
+			SigningKey: storageConfig.Local.SigningKey,
+		}, f.logger), nil
+	case "s3":
+		return storage.NewS3StorageProvider(storage.S3Config{
+			Region:          storageConfig.S3.Region,
+			Bucket:          storageConfig.S3.Bucket,
+			AccessKeyID:     storageConfig.S3.AccessKeyID,
+			SecretAccessKey: storageConfig.S3.SecretAccessKey,
+			Endpoint:        storageConfig.S3.Endpoint,
+			PublicURL:       storageConfig.PublicURL,
+		}, f.logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported file storage provider: %s", storageConfig.Provider)
+	}
+}
+
+func (f *ProviderFactory) createStripeProvider() provider.PaymentProvider {
+	stripeConfig := payment.StripeConfig{
+		BaseURL: f.config.Providers.Payment.Stripe.BaseURL,
+		APIKey:  f.config.Providers.Payment.Stripe.APIKey,
+		Timeout: f.config.Providers.Payment.Stripe.Timeout,
+	}
+
+	f.logger.WithFields(map[string]interface{}{
+		"provider":    "stripe",
+		"environment": f.config.Providers.Payment.Stripe.Environment,
+		"base_url":    stripeConfig.BaseURL,
+		"timeout":     stripeConfig.Timeout.String(),
+	}).Info("Initializing Stripe payment provider")
+
+	return payment.NewStripeProvider(stripeConfig, f.logger)
+}
+
+func (f *ProviderFactory) createPayPalProvider() provider.PaymentProvider {
+	paypalConfig := payment.PayPalConfig{
+		BaseURL:      f.config.Providers.Payment.PayPal.BaseURL,
+		ClientID:     f.config.Providers.Payment.PayPal.ClientID,
+		ClientSecret: f.config.Providers.Payment.PayPal.ClientSecret,
+		Timeout:      f.config.Providers.Payment.PayPal.Timeout,
+		Recorder:     f.recorder,
+	}
+
+	f.logger.WithFields(map[string]interface{}{
+		"provider":    "paypal",
+		"environment": f.config.Providers.Payment.PayPal.Environment,
+		"base_url":    paypalConfig.BaseURL,
+		"timeout":     paypalConfig.Timeout.String(),
+	}).Info("Initializing PayPal payment provider")
+
+	return payment.NewPayPalProvider(paypalConfig, f.logger)
+}
+
+// ValidateProviderConfiguration validates that all required provider configurations are present
+func (f *ProviderFactory) ValidateProviderConfiguration() error {
+	// Validate payment provider configuration
+	switch f.config.Providers.Payment.Provider {
+	case "stripe":
+		if f.config.Providers.Payment.Stripe.APIKey == "" {
+			return fmt.Errorf("Stripe API key is required")
+		}
+	case "paypal":
+		if f.config.Providers.Payment.PayPal.ClientID == "" || f.config.Providers.Payment.PayPal.ClientSecret == "" {
+			return fmt.Errorf("PayPal client ID and secret are required")
+		}
+	case "":
+		f.logger.Warn("No payment provider configured, payment features will be disabled")
+	default:
+		return fmt.Errorf("unsupported payment provider: %s", f.config.Providers.Payment.Provider)
+	}
+
+	// Validate notification provider configuration
+	if f.config.Providers.Notification.Email.APIKey == "" {
+		f.logger.Warn("Email API key not configured, email notifications will be disabled")
+	}
+
+	if f.config.Providers.Notification.SMS.APIKey == "" {
+		f.logger.Warn("SMS API key not configured, SMS notifications will be disabled")
+	}
+
+	// Validate file storage configuration
+	if f.config.Providers.FileStorage.Provider == "s3" && f.config.Providers.FileStorage.S3.Bucket == "" {
+		return fmt.Errorf("S3 bucket is required")
+	}
+
+	// Validate CAPTCHA provider configuration
+	if f.config.Providers.Captcha.Provider != "" && f.config.Providers.Captcha.SecretKey == "" {
+		return fmt.Errorf("CAPTCHA secret key is required")
+	}
+
+	// Validate fraud provider configuration
+	if f.config.Providers.Fraud.Provider == "external" && f.config.Providers.Fraud.BaseURL == "" {
+		return fmt.Errorf("fraud service URL is required")
+	}
+	if f.config.Providers.Fraud.ReviewThreshold > f.config.Providers.Fraud.BlockThreshold {
+		return fmt.Errorf("fraud review threshold must not exceed the block threshold")
+	}
+
+	return nil
+}
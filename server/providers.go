@@ -0,0 +1,98 @@
+package server
+
+import (
+	"fmt"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/provider"
+	"boilerplate-go/internal/provider/payment"
+	"boilerplate-go/internal/provider/switchable"
+)
+
+// Providers holds the external service providers chosen by configuration.
+// Optional providers are nil when disabled.
+type Providers struct {
+	Geolocation  provider.GeolocationProvider
+	Captcha      provider.CaptchaProvider
+	Payment      provider.PaymentProvider
+	Notification provider.NotificationProvider
+	Email        provider.EmailProvider
+	FileStorage  provider.FileStorageProvider
+	Scanner      provider.ScannerProvider
+	Fraud        provider.FraudProvider
+}
+
+// newProviders creates every provider through the factory
+func newProviders(factory *ProviderFactory) (*Providers, error) {
+	p := &Providers{Email: factory.CreateEmailProvider()}
+
+	var err error
+	if p.Geolocation, err = factory.CreateGeolocationProvider(); err != nil {
+		return nil, fmt.Errorf("failed to initialize geolocation provider: %w", err)
+	}
+	if p.Captcha, err = factory.CreateCaptchaProvider(); err != nil {
+		return nil, fmt.Errorf("failed to initialize CAPTCHA provider: %w", err)
+	}
+	if p.Payment, err = factory.CreatePaymentProvider(); err != nil {
+		return nil, fmt.Errorf("failed to initialize payment provider: %w", err)
+	}
+	if p.Notification, err = factory.CreateNotificationProvider(); err != nil {
+		return nil, fmt.Errorf("failed to initialize notification provider: %w", err)
+	}
+	if p.FileStorage, err = factory.CreateFileStorageProvider(); err != nil {
+		return nil, fmt.Errorf("failed to initialize file storage provider: %w", err)
+	}
+	if p.Scanner, err = factory.CreateScannerProvider(); err != nil {
+		return nil, fmt.Errorf("failed to initialize scanner provider: %w", err)
+	}
+	if p.Fraud, err = factory.CreateFraudProvider(); err != nil {
+		return nil, fmt.Errorf("failed to initialize fraud provider: %w", err)
+	}
+	return p, nil
+}
+
+// providerSwitcher replaces the providers that profiles can change at
+// runtime: payment, notification and bulk email
+type providerSwitcher struct {
+	logger       *logger.Logger
+	recorder     payment.Recorder
+	payment      *switchable.PaymentProvider
+	notification *switchable.NotificationProvider
+	email        *switchable.EmailProvider
+}
+
+// newProviderSwitcher makes the payment, notification and email providers
+// switchable, so use cases created afterwards follow the switches
+func newProviderSwitcher(providers *Providers, log *logger.Logger, recorder payment.Recorder) *providerSwitcher {
+	s := &providerSwitcher{
+		logger:       log,
+		recorder:     recorder,
+		payment:      switchable.NewPaymentProvider(providers.Payment),
+		notification: switchable.NewNotificationProvider(providers.Notification),
+		email:        switchable.NewEmailProvider(providers.Email),
+	}
+	providers.Payment, providers.Notification, providers.Email = s.payment, s.notification, s.email
+	return s
+}
+
+// SwitchProviders creates the switchable providers from cfg and replaces the
+// running ones once all are created
+func (s *providerSwitcher) SwitchProviders(cfg *config.Config) error {
+	factory := NewProviderFactory(cfg, s.logger)
+	factory.SetPaymentRecorder(s.recorder)
+
+	paymentProvider, err := factory.CreatePaymentProvider()
+	if err != nil {
+		return err
+	}
+	notificationProvider, err := factory.CreateNotificationProvider()
+	if err != nil {
+		return err
+	}
+
+	s.payment.Set(paymentProvider)
+	s.notification.Set(notificationProvider)
+	s.email.Set(factory.CreateEmailProvider())
+	return nil
+}
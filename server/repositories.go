@@ -0,0 +1,89 @@
+package server
+
+import (
+	"boilerplate-go/config"
+	"boilerplate-go/internal/domain/repository"
+	"boilerplate-go/pkg/redis"
+	"context"
+	"fmt"
+)
+
+// Repositories holds the PostgreSQL repositories
+type Repositories struct {
+	User         repository.UserRepository
+	UserChange   repository.UserChangeRepository
+	Session      repository.SessionRepository
+	Order        repository.OrderRepository
+	Coupon       repository.CouponRepository
+	DeadLetter   repository.DeadLetterRepository
+	Job          repository.JobRepository
+	UserImport   repository.UserImportRepository
+	Notification repository.NotificationRepository
+	OTP          repository.OTPRepository
+	Upload       repository.UploadRepository
+	Stats        repository.StatsRepository
+	Report       repository.ReportRepository
+	Export       repository.ExportRepository
+	ConfigChange repository.ConfigChangeRepository
+	WebhookEvent repository.WebhookEventRepository
+	EventBuffer  repository.EventBufferRepository
+	PaymentCache repository.PaymentStatusCacheRepository
+	Saga         repository.SagaRepository
+	Usage        repository.ProviderUsageRepository
+	Organization repository.OrganizationRepository
+	SSO          repository.SSORepository
+	Consent      repository.ConsentRepository
+}
+
+// newRepositories creates the repositories on the database connection
+func newRepositories(infra *infrastructure) *Repositories {
+	db, log, metrics := infra.db, infra.logger, infra.metrics
+	return &Repositories{
+		// Concurrent reads of the same user share one query
+		User:         repository.NewSingleFlightUserRepository(repository.NewUserRepository(db, log, metrics), metrics),
+		UserChange:   repository.NewUserChangeRepository(db, log, metrics),
+		Session:      repository.NewSessionRepository(db, log, metrics),
+		Order:        repository.NewOrderRepository(db, log, metrics),
+		Coupon:       repository.NewCouponRepository(db, log, metrics),
+		DeadLetter:   repository.NewDeadLetterRepository(db, log, metrics),
+		Job:          repository.NewJobRepository(db, log, metrics),
+		UserImport:   repository.NewUserImportRepository(db, log, metrics),
+		Notification: repository.NewNotificationRepository(db, log, metrics),
+		OTP:          repository.NewOTPRepository(db, log, metrics),
+		Upload:       repository.NewUploadRepository(db, log, metrics),
+		Stats:        repository.NewStatsRepository(db, log, metrics),
+		Report:       repository.NewReportRepository(db, log, metrics),
+		Export:       repository.NewExportRepository(db, log, metrics),
+		ConfigChange: repository.NewConfigChangeRepository(db, log, metrics),
+		WebhookEvent: repository.NewWebhookEventRepository(db, log, metrics),
+		EventBuffer:  repository.NewEventBufferRepository(db, log, metrics),
+		PaymentCache: repository.NewPaymentStatusCacheRepository(db, log, metrics),
+		Saga:         repository.NewSagaRepository(db, log, metrics),
+		Usage:        repository.NewProviderUsageRepository(db, log, metrics),
+		Organization: repository.NewOrganizationRepository(db, log, metrics),
+		SSO:          repository.NewSSORepository(db, log, metrics),
+		Consent:      repository.NewConsentRepository(db, log, metrics),
+	}
+}
+
+// usePaymentStatusRedis moves the payment status cache to Redis when
+// configured. Statuses are read far more often than the rest of the data and
+// expire on their own there, sparing the database the writes and the purge.
+func usePaymentStatusRedis(cfg config.PaymentConfig, infra *infrastructure, repos *Repositories, lc *lifecycle) error {
+	if cfg.StatusCacheRedisURL == "" {
+		return nil
+	}
+	opts, err := redis.ParseURL(cfg.StatusCacheRedisURL)
+	if err != nil {
+		return fmt.Errorf("invalid PAYMENT_STATUS_CACHE_REDIS_URL: %w", err)
+	}
+	client := redis.New(opts)
+	repos.PaymentCache = repository.NewRedisPaymentStatusCacheRepository(client, cfg.StatusCacheRedisPrefix, infra.logger)
+	lc.append(hook{
+		name: "payment status redis",
+		stop: func(ctx context.Context) error {
+			return client.Close()
+		},
+	})
+	return nil
+}
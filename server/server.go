@@ -0,0 +1,280 @@
+// Package server builds the API server from configuration, so it can be run
+// by cmd/api or embedded in another program with some of its parts replaced.
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/infrastructure/metrics"
+	"boilerplate-go/internal/delivery/http/middleware"
+	"boilerplate-go/internal/delivery/http/route"
+
+	"github.com/gin-gonic/gin"
+)
+
+// shutdownTimeout bounds a graceful shutdown started by Run
+const shutdownTimeout = 30 * time.Second
+
+// Server is the API server with its background workers
+type Server struct {
+	config    *config.Config
+	logger    *logger.Logger
+	router    *gin.Engine
+	responses *middleware.ResponseCache
+	health    *metrics.HealthMetrics
+	lc        *lifecycle
+}
+
+type options struct {
+	config       *config.Config
+	logger       *logger.Logger
+	providers    []func(*Providers)
+	repositories []func(*Repositories)
+	datastore    *Repositories
+	middleware   []gin.HandlerFunc
+	routes       []func(*gin.Engine)
+	registrars   []route.RouteRegistrar
+	hooks        []hook
+}
+
+// Option customizes the server built by New
+type Option func(*options)
+
+// WithConfig uses cfg instead of loading the configuration from the environment
+func WithConfig(cfg *config.Config) Option {
+	return func(o *options) { o.config = cfg }
+}
+
+// WithLogger uses log instead of a logger configured from the environment
+func WithLogger(log *logger.Logger) Option {
+	return func(o *options) { o.logger = log }
+}
+
+// WithProviders lets override replace providers after they are created from
+// the configuration and before anything uses them.
+func WithProviders(override func(*Providers)) Option {
+	return func(o *options) { o.providers = append(o.providers, override) }
+}
+
+// WithRepositories lets override replace repositories after they are created
+// and before any use case uses them.
+func WithRepositories(override func(*Repositories)) Option {
+	return func(o *options) { o.repositories = append(o.repositories, override) }
+}
+
+// WithDatastore uses repos instead of repositories on PostgreSQL, and the
+// database is not connected, such as for end-to-end tests with in-memory
+// repositories. Every repository must be set.
+func WithDatastore(repos *Repositories) Option {
+	return func(o *options) { o.datastore = repos }
+}
+
+// WithMiddleware adds middleware after the built-in stack, so it runs for
+// every route with the request ID, logging and metrics already in place.
+func WithMiddleware(handlers ...gin.HandlerFunc) Option {
+	return func(o *options) { o.middleware = append(o.middleware, handlers...) }
+}
+
+// WithRoutes lets register add routes next to the built-in ones
+func WithRoutes(register func(r *gin.Engine)) Option {
+	return func(o *options) { o.routes = append(o.routes, register) }
+}
+
+// WithRouteRegistrars mounts the routes of feature modules behind the
+// authentication, scopes and deduplication they declare
+func WithRouteRegistrars(registrars ...route.RouteRegistrar) Option {
+	return func(o *options) { o.registrars = append(o.registrars, registrars...) }
+}
+
+// WithHook runs start when the server starts and stop when it stops. Hooks
+// start before the HTTP server and stop after it; either may be nil.
+func WithHook(name string, start func() error, stop func(ctx context.Context) error) Option {
+	return func(o *options) { o.hooks = append(o.hooks, hook{name: name, start: start, stop: stop}) }
+}
+
+// New builds the server one layer at a time. Each layer registers its
+// long-running components, which Start starts in that order and Stop stops in
+// reverse. New connects to the database, waiting for it as configured,
+// unless a datastore is given.
+func New(opts ...Option) (*Server, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.config == nil {
+		o.config = config.LoadConfig()
+	}
+	if o.logger == nil {
+		o.logger = logger.NewLogger()
+	}
+	cfg, appLogger := o.config, o.logger
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	s := &Server{config: cfg, logger: appLogger, lc: newLifecycle(appLogger)}
+	if err := startLogShipping(cfg.Logging, appLogger, s.lc); err != nil {
+		return nil, err
+	}
+
+	appMetrics := metrics.NewMetrics(cfg.Metrics)
+	factory := NewProviderFactory(cfg, appLogger)
+	factory.SetPaymentRecorder(appMetrics)
+	providers, err := newProviders(factory)
+	if err != nil {
+		return nil, err
+	}
+	for _, override := range o.providers {
+		override(providers)
+	}
+	switcher := newProviderSwitcher(providers, appLogger, appMetrics)
+
+	infra, err := newInfrastructure(cfg, appLogger, appMetrics, providers, o.datastore == nil, s.lc)
+	if err != nil {
+		return nil, err
+	}
+	s.health = infra.health
+	newProviderProbes(cfg.Probes, providers, infra, s.lc)
+	if err := newRateLimiter(cfg.Server.RateLimit, infra, s.lc); err != nil {
+		return nil, err
+	}
+	if err := newLocker(cfg.Locks, infra, s.lc); err != nil {
+		return nil, err
+	}
+
+	jwtKeys, err := loadJWTKeySet(cfg.JWT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWT signing keys: %w", err)
+	}
+	passwords, err := loadPasswordPolicy(cfg.Password)
+	if err != nil {
+		return nil, err
+	}
+	usernames, err := loadUsernamePolicy(cfg.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	repos := o.datastore
+	if repos == nil {
+		repos = newRepositories(infra)
+	}
+	if err := usePaymentStatusRedis(cfg.Providers.Payment, infra, repos, s.lc); err != nil {
+		return nil, err
+	}
+	for _, override := range o.repositories {
+		override(repos)
+	}
+	uc := newUsecases(cfg, infra, repos, providers, switcher, jwtKeys, passwords, usernames, s.lc)
+	for _, h := range o.hooks {
+		s.lc.append(h)
+	}
+
+	// Public routes declaring a cache TTL are served from memory
+	if cfg.Server.ResponseCacheSize > 0 {
+		s.responses = middleware.NewResponseCache(int64(cfg.Server.ResponseCacheSize), appMetrics)
+	}
+	if s.router, err = newRouter(cfg, infra, providers, uc, jwtKeys, s.responses, o.middleware, o.registrars); err != nil {
+		return nil, err
+	}
+	for _, register := range o.routes {
+		register(s.router)
+	}
+	if _, err := newHTTPServer(cfg.Server, s.router, infra, s.lc); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Handler returns the router, for serving without the built-in HTTP server
+// or testing with httptest.
+func (s *Server) Handler() http.Handler {
+	return s.router
+}
+
+// InvalidateResponses drops the cached responses of the paths starting with
+// prefix, such as "/api/v1/rates", once the data behind a cached public route
+// of an embedding program changed. It returns how many were dropped.
+func (s *Server) InvalidateResponses(prefix string) int {
+	return s.responses.Invalidate(prefix)
+}
+
+// Start starts the background workers and the HTTP server
+func (s *Server) Start(ctx context.Context) error {
+	return s.lc.start(ctx)
+}
+
+// Drain takes the instance out of rotation before it stops: /ready answers
+// 503 and connections are closed after each response for the drain period,
+// or until ctx is done, while requests are still served. It gives load
+// balancers time to stop sending requests, which would fail once the HTTP
+// server stops.
+func (s *Server) Drain(ctx context.Context) {
+	period := s.config.Server.DrainPeriod
+	if period <= 0 {
+		return
+	}
+	s.health.SetDraining(true)
+	s.logger.WithFields(map[string]interface{}{
+		"drain_period": period.String(),
+	}).Info("Draining before shutdown")
+
+	timer := time.NewTimer(period)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// Stop gracefully stops the HTTP server, then the background workers and
+// connections, giving up on components still busy when ctx is done.
+func (s *Server) Stop(ctx context.Context) {
+	s.lc.stop(ctx)
+	s.logger.Info("Application shutdown completed")
+}
+
+// Run starts the server and, on SIGINT or SIGTERM, drains and stops it
+// gracefully
+func (s *Server) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := s.Start(ctx); err != nil {
+		return err
+	}
+
+	// Wait for interrupt signal to gracefully shutdown the server
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	sig := <-quit
+	s.logger.WithFields(map[string]interface{}{
+		"signal": sig.String(),
+	}).Info("Received shutdown signal, starting graceful shutdown")
+
+	// A second signal cuts the drain short
+	drainCtx, stopDrain := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-quit:
+			stopDrain()
+		case <-drainCtx.Done():
+		}
+	}()
+	s.Drain(drainCtx)
+	stopDrain()
+
+	ctx, cancel = context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	s.Stop(ctx)
+	return nil
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"net"
+	"testing"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_InvalidConfig(t *testing.T) {
+	cfg := config.LoadConfig()
+	cfg.Server.AccessLogFormat = "json"
+
+	_, err := New(WithConfig(cfg), WithLogger(logger.NewLogger()))
+	assert.ErrorContains(t, err, "invalid configuration")
+}
+
+func TestNew_ProvidersOverride(t *testing.T) {
+	// A port nothing listens on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	cfg := config.LoadConfig()
+	cfg.Database.Host, cfg.Database.Port = "127.0.0.1", port
+	cfg.Startup.MaxWait = 0
+
+	var overridden *Providers
+	_, err = New(WithConfig(cfg), WithLogger(logger.NewLogger()), WithProviders(func(p *Providers) {
+		overridden = p
+		p.Scanner = nil
+	}))
+
+	// Providers are replaced before connecting to the database
+	assert.ErrorContains(t, err, "failed to connect to database")
+	require.NotNil(t, overridden)
+	assert.NotNil(t, overridden.Payment)
+	assert.NotNil(t, overridden.Email)
+}
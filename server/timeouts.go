@@ -0,0 +1,22 @@
+package server
+
+import (
+	"time"
+
+	"boilerplate-go/config"
+	"boilerplate-go/pkg/timeout"
+)
+
+// newTimeoutPolicy builds the per-operation deadline policy from configuration
+func newTimeoutPolicy(cfg config.TimeoutConfig) *timeout.Policy {
+	return timeout.NewPolicy(map[string]time.Duration{
+		timeout.OperationDefault:      cfg.Default,
+		timeout.OperationDBRead:       cfg.DBRead,
+		timeout.OperationDBWrite:      cfg.DBWrite,
+		timeout.OperationPayment:      cfg.Payment,
+		timeout.OperationFraud:        cfg.Fraud,
+		timeout.OperationGeolocation:  cfg.Geolocation,
+		timeout.OperationNotification: cfg.Notification,
+		timeout.OperationStorage:      cfg.Storage,
+	})
+}
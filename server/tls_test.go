@@ -0,0 +1,111 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"boilerplate-go/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCertificate writes a self-signed certificate for 127.0.0.1 and its key.
+func writeCertificate(t *testing.T) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+// negotiatedProtocol serves over TLS and returns the protocol a client
+// offering HTTP/2 ends up with.
+func negotiatedProtocol(t *testing.T, cfg config.ServerConfig) string {
+	tlsConfig, _, err := newTLSConfig(cfg)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &http.Server{
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		TLSConfig: tlsConfig,
+		Protocols: newProtocols(cfg),
+	}
+	go srv.ServeTLS(listener, "", "")
+	defer srv.Close()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{"h2", "http/1.1"},
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+	return conn.ConnectionState().NegotiatedProtocol
+}
+
+func TestNewTLSConfig_HTTP2(t *testing.T) {
+	certFile, keyFile := writeCertificate(t)
+	cfg := config.ServerConfig{Port: "8443", HTTP2: true}
+	cfg.TLS.CertFile, cfg.TLS.KeyFile = certFile, keyFile
+
+	assert.Equal(t, "h2", negotiatedProtocol(t, cfg))
+
+	cfg.HTTP2 = false
+	assert.Equal(t, "http/1.1", negotiatedProtocol(t, cfg))
+}
+
+func TestNewTLSConfig_InvalidCertificate(t *testing.T) {
+	cfg := config.ServerConfig{}
+	cfg.TLS.CertFile, cfg.TLS.KeyFile = "missing.pem", "missing-key.pem"
+
+	_, _, err := newTLSConfig(cfg)
+	assert.ErrorContains(t, err, "failed to load TLS certificate")
+}
+
+func TestRedirectHTTPS(t *testing.T) {
+	tests := []struct {
+		port, host, target, location string
+	}{
+		{"443", "example.com", "/orders?page=2", "https://example.com/orders?page=2"},
+		{"443", "example.com:80", "/", "https://example.com/"},
+		{"8443", "example.com:8080", "/health", "https://example.com:8443/health"},
+		{"443", "[::1]:80", "/", "https://[::1]/"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		req.Host = tt.host
+		w := httptest.NewRecorder()
+		redirectHTTPS(tt.port).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusMovedPermanently, w.Code)
+		assert.Equal(t, tt.location, w.Header().Get("Location"))
+	}
+}
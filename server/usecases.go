@@ -0,0 +1,245 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"boilerplate-go/config"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/internal/provider/metered"
+	"boilerplate-go/internal/provider/payment"
+	"boilerplate-go/internal/usecase/auth"
+	"boilerplate-go/internal/usecase/budget"
+	"boilerplate-go/internal/usecase/configuration"
+	"boilerplate-go/internal/usecase/consent"
+	"boilerplate-go/internal/usecase/export"
+	"boilerplate-go/internal/usecase/job"
+	"boilerplate-go/internal/usecase/notification"
+	"boilerplate-go/internal/usecase/order"
+	"boilerplate-go/internal/usecase/organization"
+	"boilerplate-go/internal/usecase/otp"
+	"boilerplate-go/internal/usecase/promotion"
+	"boilerplate-go/internal/usecase/report"
+	"boilerplate-go/internal/usecase/saga"
+	"boilerplate-go/internal/usecase/session"
+	"boilerplate-go/internal/usecase/sso"
+	"boilerplate-go/internal/usecase/stats"
+	"boilerplate-go/internal/usecase/upload"
+	"boilerplate-go/internal/usecase/user"
+	"boilerplate-go/pkg/jwt"
+	"boilerplate-go/pkg/password"
+	"boilerplate-go/pkg/username"
+	"boilerplate-go/pkg/workerpool"
+)
+
+// usecases holds the application use cases
+type usecases struct {
+	auth         *auth.AuthUsecase
+	user         *user.UserUsecase
+	session      *session.SessionUsecase
+	promotion    *promotion.PromotionUsecase
+	job          *job.JobUsecase
+	notification *notification.NotificationUsecase
+	otp          *otp.OTPUsecase
+	order        *order.OrderUsecase
+	upload       *upload.UploadUsecase
+	stats        *stats.StatsUsecase
+	report       *report.ReportUsecase
+	export       *export.ExportUsecase
+	config       *configuration.ConfigUsecase
+	budget       *budget.BudgetUsecase
+	organization *organization.OrganizationUsecase
+	sso          *sso.SSOUsecase
+	consent      *consent.ConsentUsecase
+}
+
+// newUsecases creates the use cases and registers their background jobs.
+// Job workers start with the application, once every job type is registered.
+func newUsecases(cfg *config.Config, infra *infrastructure, repos *Repositories, providers *Providers, switcher configuration.ProviderSwitcher, jwtKeys *jwt.KeySet, passwords *password.Policy, usernames *username.Policy, lc *lifecycle) *usecases {
+	log, metrics := infra.logger, infra.metrics
+	timeouts := newTimeoutPolicy(cfg.Timeouts)
+
+	// Every use case calls the paid providers through their budgets. The
+	// budgets wrap the switchable providers, so they survive profile switches
+	budgets := budget.NewBudgetUsecase(repos.Usage, cfg.Budgets, metrics, log)
+	budgets.SetTimeouts(timeouts)
+	providers.Payment = metered.NewPaymentProvider(providers.Payment, budgets)
+	providers.Notification = metered.NewNotificationProvider(providers.Notification, budgets)
+	providers.Email = metered.NewEmailProvider(providers.Email, budgets)
+	// Usage recorded until the workers stop is flushed at shutdown
+	lc.append(hook{
+		name: "provider budgets",
+		start: func() error {
+			budgets.Start()
+			return nil
+		},
+		stop: budgets.Stop,
+	})
+
+	uc := &usecases{
+		auth:         auth.NewAuthUsecase(repos.User, repos.Session, providers.Geolocation, jwtKeys, cfg.JWT),
+		user:         user.NewUserUsecase(repos.User, log),
+		session:      session.NewSessionUsecase(repos.Session),
+		promotion:    promotion.NewPromotionUsecase(repos.Coupon),
+		job:          job.NewJobUsecase(repos.Job, repos.DeadLetter, cfg.Jobs, log, metrics),
+		notification: notification.NewNotificationUsecase(providers.Notification, cfg.Providers.Notification, log, metrics),
+		otp:          otp.NewOTPUsecase(repos.OTP, providers.Notification, cfg.OTP, log),
+		order:        order.NewOrderUsecase(repos.User, repos.Order, repos.Coupon, providers.Payment, providers.Notification, log),
+		upload:       upload.NewUploadUsecase(repos.Upload, providers.FileStorage, cfg.Upload, log),
+		stats:        stats.NewStatsUsecase(repos.Stats, cfg.Stats.CacheTTL),
+		report:       report.NewReportUsecase(repos.Report, cfg.Reports, log),
+		export:       export.NewExportUsecase(repos.Export, providers.FileStorage, cfg.Exports, log),
+		config:       configuration.NewConfigUsecase(cfg, repos.ConfigChange, switcher, log),
+		budget:       budgets,
+		organization: organization.NewOrganizationUsecase(repos.Organization, repos.User, providers.Notification, cfg.Orgs, log),
+		sso:          sso.NewSSOUsecase(repos.SSO, repos.User, repos.Organization, cfg.SSO, log),
+		consent:      consent.NewConsentUsecase(repos.Consent, cfg.Legal, log),
+	}
+
+	uc.auth.SetClaimsHook(auth.RoleScopes(map[string][]string{
+		entity.RoleUser:    entity.UserScopes,
+		entity.RoleAdmin:   append([]string{entity.ScopeAdmin}, entity.UserScopes...),
+		entity.RoleSupport: append([]string{entity.ScopeAdmin, entity.ScopeImpersonate}, entity.UserScopes...),
+	}))
+	uc.session.AllowLegacyTokens(cfg.JWT.AllowLegacyTokens)
+	uc.auth.SetPasswordPolicy(passwords)
+	uc.auth.SetUsernamePolicy(usernames)
+	uc.auth.SetEmailAddresses(cfg.Email)
+	uc.user.SetPasswordPolicy(passwords)
+
+	uc.auth.SetTimeouts(timeouts)
+	uc.user.SetTimeouts(timeouts)
+	uc.session.SetTimeouts(timeouts)
+	uc.promotion.SetTimeouts(timeouts)
+	uc.notification.SetTimeouts(timeouts)
+	uc.otp.SetTimeouts(timeouts)
+	uc.order.SetTimeouts(timeouts)
+	uc.upload.SetTimeouts(timeouts)
+	uc.stats.SetTimeouts(timeouts)
+	uc.report.SetTimeouts(timeouts)
+	uc.export.SetTimeouts(timeouts)
+	uc.config.SetTimeouts(timeouts)
+	uc.organization.SetTimeouts(timeouts)
+	uc.sso.SetTimeouts(timeouts)
+	uc.consent.SetTimeouts(timeouts)
+
+	jobs := uc.job
+	jobs.SetLocker(infra.locker)
+	jobs.SetLeader(infra.leader)
+	jobs.Register(entity.JobTypeEmail, job.EmailHandler(uc.notification))
+	uc.notification.SetCampaigns(repos.Notification, providers.Email, cfg.Providers.Notification.BulkChunkSize)
+	uc.notification.SetAudience(repos.User)
+	uc.notification.SetJobQueue(jobs)
+	jobs.Register(entity.JobTypeBulkEmail, uc.notification.SendCampaign)
+	jobs.OnDeadLetter(entity.JobTypeBulkEmail, uc.notification.FailCampaign)
+	if interval := cfg.Providers.Notification.StatusSyncInterval; interval > 0 {
+		uc.notification.SetStatusSync(cfg.Providers.Notification.StatusSyncWindow)
+		jobs.Register(entity.JobTypeEmailStatusSync, uc.notification.SyncEmailStatus)
+		jobs.Schedule(entity.JobTypeEmailStatusSync, interval)
+	}
+	uc.report.SetJobQueue(jobs)
+	jobs.Register(entity.JobTypeReportRollup, uc.report.Rollup)
+	if interval := cfg.Reports.RollupInterval; interval > 0 {
+		jobs.Schedule(entity.JobTypeReportRollup, interval)
+	}
+	uc.organization.SetJobQueue(jobs)
+	uc.organization.SetEmailAddresses(cfg.Email)
+	uc.export.SetJobQueue(jobs)
+	jobs.Register(entity.JobTypeExport, uc.export.Generate)
+	jobs.OnDeadLetter(entity.JobTypeExport, uc.export.FailExport)
+	jobs.RefreshDeadLetterDepth(context.Background())
+
+	uc.auth.SetOTP(uc.otp)
+	uc.auth.SetInvitations(uc.organization)
+	uc.auth.SetSSO(uc.sso)
+	uc.auth.SetLoginAlerts(cfg.Login, jobs, log)
+	uc.user.SetOTP(uc.otp)
+
+	uc.order.SetJobQueue(jobs)
+	sagas := saga.NewOrchestrator(repos.Saga, cfg.Sagas, log)
+	sagas.SetTimeouts(timeouts)
+	uc.order.SetSagas(sagas)
+	jobs.Register(entity.JobTypeSagaRecovery, sagas.Recover)
+	if interval := cfg.Sagas.RecoveryInterval; interval > 0 {
+		jobs.Schedule(entity.JobTypeSagaRecovery, interval)
+	}
+	orderEvents := workerpool.NewPartitioned(cfg.Jobs.Partitions, cfg.Jobs.Partitions)
+	uc.order.SetEventOrdering(orderEvents)
+	uc.order.SetOrderLocks(infra.locker, cfg.Locks.TTL, cfg.Locks.Wait)
+	uc.order.SetDeferredPayments(cfg.Features.DeferredPayments)
+	uc.order.SetDryRunProvider(payment.NewMockProvider())
+	jobs.Register(entity.JobTypeDeferredPayment, uc.order.ProcessDeferredPayment)
+	jobs.OnDeadLetter(entity.JobTypeDeferredPayment, uc.order.FailDeferredPayment)
+	// Wait out an open payment circuit before each retry of a deferred payment
+	jobs.SetRetryPolicy(entity.JobTypeDeferredPayment, cfg.Jobs.MaxAttempts, 2*cfg.Providers.Payment.CircuitOpenTimeout)
+	if ttl := cfg.Signing.EventTTL; cfg.Signing.Enabled && ttl > 0 {
+		uc.order.SetWebhookEvents(repos.WebhookEvent, repos.EventBuffer, ttl)
+		jobs.Register(entity.JobTypeWebhookPurge, uc.order.PurgeWebhookEvents)
+		if interval := cfg.Signing.EventPurgeInterval; interval > 0 {
+			jobs.Schedule(entity.JobTypeWebhookPurge, interval)
+		}
+	}
+	if ttl := cfg.Providers.Payment.StatusCacheTTL; ttl > 0 && repos.PaymentCache != nil {
+		uc.order.SetPaymentStatusCache(repos.PaymentCache, ttl)
+	}
+	uc.order.SetCurrencies(cfg.Providers.Payment.Currencies)
+	uc.order.SetPaymentStatusWait(cfg.Providers.Payment.StatusMaxWait, cfg.Providers.Payment.StatusRecheckInterval)
+	if providers.Fraud != nil {
+		uc.order.SetFraudScreening(providers.Fraud, providers.Geolocation, cfg.Providers.Fraud)
+	}
+
+	uc.user.SetErasure(repos.Session, uc.order)
+	uc.user.SetJobQueue(jobs)
+	uc.user.SetEmailChange(cfg.Email)
+	jobs.Register(entity.JobTypeUserErasure, uc.user.EraseUser)
+	uc.user.SetDataExport(providers.FileStorage, user.DataExportSources{
+		Notifications: repos.Notification,
+		Uploads:       repos.Upload,
+		Consents:      repos.Consent,
+		Organizations: repos.Organization,
+	}, cfg.Exports.ArchiveTTL)
+	jobs.Register(entity.JobTypeUserDataExport, uc.user.ExportUserData)
+	uc.user.SetImports(repos.UserImport)
+	uc.user.SetChangeHistory(repos.UserChange)
+	uc.user.SetAvatarStorage(providers.FileStorage, cfg.Avatar)
+	jobs.Register(entity.JobTypeUserImport, uc.user.RunImport)
+	jobs.OnDeadLetter(entity.JobTypeUserImport, uc.user.FailImport)
+
+	uc.upload.SetNotifications(repos.User, providers.Notification)
+	if providers.Scanner != nil {
+		uc.upload.SetScanner(providers.Scanner, jobs)
+		jobs.Register(entity.JobTypeUploadScan, uc.upload.ScanUpload)
+		jobs.OnDeadLetter(entity.JobTypeUploadScan, uc.upload.FailScan)
+	}
+
+	// Emails queued by job workers are delivered after the workers stop
+	lc.append(hook{
+		name: "notification workers",
+		stop: func(ctx context.Context) error {
+			if err := uc.notification.Shutdown(ctx); err != nil {
+				return fmt.Errorf("still delivering at shutdown: %w", err)
+			}
+			return nil
+		},
+	})
+	// Jobs waiting for their order's turn finish before the turns stop
+	lc.append(hook{
+		name: "order event partitions",
+		stop: orderEvents.Shutdown,
+	})
+	lc.append(hook{
+		name: "background job workers",
+		start: func() error {
+			jobs.Start()
+			return nil
+		},
+		stop: func(ctx context.Context) error {
+			if err := jobs.Shutdown(ctx); err != nil {
+				return fmt.Errorf("jobs still running at shutdown will be retried after their lease: %w", err)
+			}
+			return nil
+		},
+	})
+
+	return uc
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"boilerplate-go/config"
+	"boilerplate-go/pkg/username"
+)
+
+// loadUsernamePolicy builds the username policy from configuration
+func loadUsernamePolicy(cfg config.UsernameConfig) (*username.Policy, error) {
+	pattern, err := regexp.Compile(cfg.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid username pattern: %w", err)
+	}
+	policy := &username.Policy{
+		MinLength: cfg.MinLength,
+		MaxLength: cfg.MaxLength,
+		Pattern:   pattern,
+	}
+	if cfg.ReserveDefaults {
+		policy.ReserveDefaults()
+	}
+	policy.Reserve(cfg.Reserved...)
+
+	if cfg.BannedFile != "" {
+		file, err := os.Open(cfg.BannedFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open banned username word list: %w", err)
+		}
+		defer file.Close()
+		if err := policy.BanList(file); err != nil {
+			return nil, fmt.Errorf("failed to read banned username word list: %w", err)
+		}
+	}
+	return policy, nil
+}
@@ -0,0 +1,102 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"testing"
+
+	"boilerplate-go/internal/delivery/http/middleware"
+	"boilerplate-go/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieAuthFlow(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+	user := signUp(t, c, "cookie-user")
+
+	// A browser keeps the cookies of the login and sends no Authorization header
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	browser := &http.Client{Transport: httpClient.Transport, Timeout: httpClient.Timeout, Jar: jar}
+	send := func(method, path string, body interface{}, csrf string) int {
+		var payload bytes.Buffer
+		if body != nil {
+			require.NoError(t, json.NewEncoder(&payload).Encode(body))
+		}
+		req, err := http.NewRequest(method, api.url+path, &payload)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Origin", api.url)
+		if csrf != "" {
+			req.Header.Set(middleware.CSRFHeader, csrf)
+		}
+		resp, err := browser.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Before signing in the browser obtains a CSRF token of no session
+	login := entity.LoginRequest{Username: user.Username, Password: "secret-password"}
+	require.Equal(t, http.StatusForbidden, send(http.MethodPost, "/api/v1/auth/login", login, ""))
+	resp, err := browser.Get(api.url + "/api/v1/auth/csrf")
+	require.NoError(t, err)
+	var anonymous struct {
+		Data entity.CSRFTokenResponse `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&anonymous))
+	resp.Body.Close()
+	require.NotEmpty(t, anonymous.Data.CSRFToken)
+	require.Equal(t, http.StatusOK, send(http.MethodPost, "/api/v1/auth/login", login, anonymous.Data.CSRFToken))
+
+	apiURL, err := url.Parse(api.url)
+	require.NoError(t, err)
+	var session *http.Cookie
+	var csrf string
+	for _, cookie := range jar.Cookies(apiURL) {
+		switch cookie.Name {
+		case "session":
+			session = cookie
+		case "csrf_token":
+			csrf = cookie.Value
+		}
+	}
+	require.NotNil(t, session)
+	require.NotEmpty(t, csrf)
+	assert.NotEqual(t, anonymous.Data.CSRFToken, csrf, "signing in binds a new token to the session")
+
+	t.Run("cookie authenticates reads", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send(http.MethodGet, "/api/v1/user/profile", nil, ""))
+	})
+
+	t.Run("writes need the CSRF token", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, send(http.MethodPost, "/api/v1/auth/logout", nil, ""))
+		assert.Equal(t, http.StatusOK, send(http.MethodPost, "/api/v1/auth/logout", nil, csrf))
+	})
+
+	t.Run("logout ends the session", func(t *testing.T) {
+		assert.Empty(t, jar.Cookies(apiURL), "cookies are cleared")
+
+		// A copy of the cookie kept from before is of a revoked session
+		req, err := http.NewRequest(http.MethodGet, api.url+"/api/v1/user/profile", nil)
+		require.NoError(t, err)
+		req.AddCookie(session)
+		resp, err := httpClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		// The token of the first login is a session of its own
+		_, err = c.GetProfile(ctx)
+		require.NoError(t, err)
+		require.NoError(t, c.Logout(ctx))
+		assert.Empty(t, c.Token())
+	})
+}
@@ -0,0 +1,270 @@
+// Package e2e runs scenarios against the full API: the router, middleware,
+// handlers and use cases built by server.New, with in-memory repositories and
+// fake external providers in place of PostgreSQL and third-party services.
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"boilerplate-go/config"
+	"boilerplate-go/infrastructure/logger"
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/client"
+	"boilerplate-go/pkg/signature"
+	"boilerplate-go/server"
+)
+
+const (
+	signingKeyID  = "payments"
+	signingSecret = "e2e-signing-secret"
+	scimToken     = "e2e-scim-token"
+)
+
+// api is the stack shared by the scenarios. Metrics register globally, so
+// the server is built once per test binary; scenarios use their own users.
+var api struct {
+	url           string
+	payments      *fakePayments
+	notifications *fakeNotifications
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	storage, err := os.MkdirTemp("", "e2e-storage")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer os.RemoveAll(storage)
+
+	cfg := config.LoadConfig()
+	cfg.Signing.Enabled = true
+	cfg.Signing.Keys = map[string]string{signingKeyID: signingSecret}
+	cfg.SCIM.Token = scimToken
+	cfg.SSO.RedirectURL = "https://app.example.com/sso/callback"
+	cfg.SSO.AllowPrivateIssuers = true
+	// Logins also set cookies; the test server speaks plain HTTP
+	cfg.Cookies.Mode = config.AuthModeBoth
+	cfg.Cookies.Secret = "e2e-cookie-secret-0123456789abcdef"
+	cfg.Cookies.Secure = false
+	cfg.Providers.FileStorage.Provider = "local"
+	cfg.Providers.FileStorage.Local.BasePath = storage
+	// Start also listens; requests go through httptest so its URL is known
+	cfg.Server.Network = "tcp"
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Port = "0"
+
+	log := logger.NewLogger()
+	log.SetOutput(io.Discard)
+
+	api.payments = &fakePayments{}
+	api.notifications = &fakeNotifications{}
+	srv, err := server.New(
+		server.WithConfig(cfg),
+		server.WithLogger(log),
+		server.WithDatastore(newDatastore()),
+		server.WithProviders(func(p *server.Providers) {
+			p.Payment = api.payments
+			p.Notification = api.notifications
+			p.Email = api.notifications
+		}),
+	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to build server:", err)
+		return 1
+	}
+
+	if err := srv.Start(context.Background()); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to start server:", err)
+		return 1
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Stop(ctx)
+	}()
+
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	api.url = ts.URL
+
+	return m.Run()
+}
+
+// paced retries requests refused by the router's global rate limit, whose
+// burst of one turns back-to-back calls from a test into 429s.
+type paced struct{}
+
+func (paced) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt == 20 {
+			return resp, err
+		}
+		resp.Body.Close()
+		time.Sleep(10 * time.Millisecond)
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+var httpClient = &http.Client{Transport: paced{}, Timeout: 10 * time.Second}
+
+// newClient returns an API client for a new user
+func newClient(t *testing.T) *client.Client {
+	c, err := client.New(client.Config{BaseURL: api.url, HTTPClient: httpClient})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// deliveries counts the webhook deliveries, signed a second apart counting
+// back from signingStart so no two share a timestamp
+var (
+	deliveries   atomic.Int64
+	signingStart = time.Now()
+)
+
+// sendPaymentEvent delivers a signed event to the payment webhook and returns
+// the response status. Each delivery is signed at a distinct time, as provider
+// retries are, so delivering an event again is not a replayed request.
+func sendPaymentEvent(t *testing.T, event entity.PaymentEvent) int {
+	body := fmt.Sprintf(`{"id":%q,"event":%q,"payment_id":%q,"refund_id":%q}`, event.ID, event.Event, event.PaymentID, event.RefundID)
+	const path = "/api/v1/webhooks/payments"
+	signedAt := signingStart.Add(-time.Duration(deliveries.Add(1)) * time.Second)
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, api.url+path, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature-Key-Id", signingKeyID)
+	req.Header.Set("X-Signature-Timestamp", timestamp)
+	req.Header.Set("X-Signature", signature.Sign(signingSecret, timestamp, http.MethodPost, path, []byte(body)))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// fakePayments charges and refunds every payment
+type fakePayments struct {
+	mu      sync.Mutex
+	seq     int
+	refunds []string
+}
+
+func (p *fakePayments) id(prefix string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seq++
+	return fmt.Sprintf("%s_%d", prefix, p.seq)
+}
+
+// refunded returns the payments refunded through the provider
+func (p *fakePayments) refunded() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.refunds...)
+}
+
+func (p *fakePayments) ProcessPayment(ctx context.Context, req *entity.PaymentRequest) (*entity.PaymentResponse, error) {
+	return &entity.PaymentResponse{
+		ID:        p.id("pay"),
+		Status:    "succeeded",
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func (p *fakePayments) RefundPayment(ctx context.Context, paymentID string) (*entity.RefundResponse, error) {
+	p.mu.Lock()
+	p.refunds = append(p.refunds, paymentID)
+	p.mu.Unlock()
+	return &entity.RefundResponse{ID: p.id("re"), PaymentID: paymentID, Status: "succeeded", CreatedAt: time.Now()}, nil
+}
+
+func (p *fakePayments) GetPaymentStatus(ctx context.Context, paymentID string) (*entity.PaymentStatus, error) {
+	return &entity.PaymentStatus{ID: paymentID, Status: "succeeded", UpdatedAt: time.Now()}, nil
+}
+
+func (p *fakePayments) CreatePaymentIntent(ctx context.Context, req *entity.PaymentIntentRequest) (*entity.PaymentIntent, error) {
+	id := p.id("pi")
+	return &entity.PaymentIntent{ID: id, ClientSecret: id + "_secret", Status: "requires_confirmation"}, nil
+}
+
+func (p *fakePayments) CancelPaymentIntent(ctx context.Context, intentID string) (*entity.PaymentIntent, error) {
+	return &entity.PaymentIntent{ID: intentID, Status: "canceled"}, nil
+}
+
+// fakeNotifications accepts every message and keeps the email subjects sent
+type fakeNotifications struct {
+	mu     sync.Mutex
+	emails map[string][]string
+}
+
+// subjects returns the subjects of the emails sent to an address
+func (n *fakeNotifications) subjects(to string) []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.emails[to]...)
+}
+
+func (n *fakeNotifications) SendEmail(ctx context.Context, req *entity.EmailRequest) (*entity.EmailResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.emails == nil {
+		n.emails = make(map[string][]string)
+	}
+	for _, to := range req.To {
+		n.emails[to] = append(n.emails[to], req.Subject)
+	}
+	return &entity.EmailResponse{ID: "email", Status: "sent", SentAt: time.Now()}, nil
+}
+
+func (n *fakeNotifications) SendSMS(ctx context.Context, req *entity.SMSRequest) (*entity.SMSResponse, error) {
+	return &entity.SMSResponse{ID: "sms", Status: "sent", SentAt: time.Now()}, nil
+}
+
+func (n *fakeNotifications) SendPushNotification(ctx context.Context, req *entity.PushNotificationRequest) (*entity.PushNotificationResponse, error) {
+	return &entity.PushNotificationResponse{ID: "push", Status: "sent", SentAt: time.Now()}, nil
+}
+
+func (n *fakeNotifications) SendTemplatedSMS(ctx context.Context, msg *entity.TemplatedMessage) (*entity.SMSResponse, error) {
+	return &entity.SMSResponse{ID: "sms", Status: "sent", SentAt: time.Now()}, nil
+}
+
+func (n *fakeNotifications) SendTemplatedPush(ctx context.Context, msg *entity.TemplatedMessage) (*entity.PushNotificationResponse, error) {
+	return &entity.PushNotificationResponse{ID: "push", Status: "sent", SentAt: time.Now()}, nil
+}
+
+func (n *fakeNotifications) SendBulkEmail(ctx context.Context, req *entity.BulkEmailRequest) (*entity.BulkEmailResponse, error) {
+	return &entity.BulkEmailResponse{ID: "bulk", Status: "sent", TotalEmails: len(req.Emails), SentEmails: len(req.Emails), CreatedAt: time.Now()}, nil
+}
+
+func (n *fakeNotifications) GetEmailStatus(ctx context.Context, emailID string) (*entity.EmailStatus, error) {
+	return &entity.EmailStatus{ID: emailID, Status: "delivered"}, nil
+}
@@ -0,0 +1,253 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runs makes names unique when the scenarios run more than once, such as
+// with -count, against the same datastore.
+var runs atomic.Int64
+
+// unique returns name with a suffix not used before in the process
+func unique(name string) string {
+	return fmt.Sprintf("%s-%d", name, runs.Add(1))
+}
+
+// signUp registers a user and logs the client in, returning the user
+func signUp(t *testing.T, c *client.Client, name string) *entity.User {
+	ctx := context.Background()
+	username := unique(name)
+	email := username + "@example.com"
+	_, err := c.Register(ctx, entity.RegisterRequest{Username: username, Email: email, Password: "secret-password"})
+	require.NoError(t, err)
+
+	login, err := c.Login(ctx, entity.LoginRequest{Username: username, Password: "secret-password"})
+	require.NoError(t, err)
+	require.NotEmpty(t, c.Token())
+	return login.User
+}
+
+func placeOrder(t *testing.T, c *client.Client, user *entity.User, orderID string) *entity.OrderResponse {
+	order, err := c.CreateOrder(context.Background(), entity.CreateOrderRequest{
+		OrderID:   unique(orderID),
+		UserID:    user.ID,
+		Items:     []entity.OrderItem{{ProductID: "sku-1", Quantity: 2, UnitPrice: 12.50}},
+		Amount:    25,
+		Currency:  "USD",
+		UserEmail: user.Email,
+	})
+	require.NoError(t, err)
+	require.Equal(t, entity.OrderStatusCompleted, order.Status)
+	require.NotEmpty(t, order.PaymentID)
+	return order
+}
+
+// orderStatus returns the status of an order in the user's order history
+func orderStatus(t *testing.T, c *client.Client, orderID string) string {
+	orders, _, err := c.ListOrders(context.Background(), client.ListOrdersOptions{})
+	require.NoError(t, err)
+	for _, order := range orders {
+		if order.OrderID == orderID {
+			return order.Status
+		}
+	}
+	t.Fatalf("order %s not in history", orderID)
+	return ""
+}
+
+func TestOrderFlow(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+	user := signUp(t, c, "buyer")
+
+	t.Run("refund requested by the customer", func(t *testing.T) {
+		order := placeOrder(t, c, user, "ord-e2e-1")
+
+		refund, err := c.RefundOrder(ctx, entity.RefundOrderRequest{PaymentID: order.PaymentID, Reason: "damaged"})
+		require.NoError(t, err)
+		assert.Equal(t, order.PaymentID, refund.PaymentID)
+		assert.Contains(t, api.payments.refunded(), order.PaymentID)
+		assert.Equal(t, entity.OrderStatusRefunded, orderStatus(t, c, order.OrderID))
+
+		// The provider's webhook for the same refund changes nothing
+		assert.Equal(t, http.StatusOK, sendPaymentEvent(t, entity.PaymentEvent{Event: entity.PaymentEventRefunded, PaymentID: order.PaymentID}))
+		assert.Equal(t, entity.OrderStatusRefunded, orderStatus(t, c, order.OrderID))
+
+		_, err = c.RefundOrder(ctx, entity.RefundOrderRequest{PaymentID: order.PaymentID})
+		assert.True(t, client.IsConflict(err), "second refund: %v", err)
+	})
+
+	t.Run("refund issued at the provider", func(t *testing.T) {
+		order := placeOrder(t, c, user, "ord-e2e-2")
+
+		event := entity.PaymentEvent{ID: unique("evt_dashboard"), Event: entity.PaymentEventRefunded, PaymentID: order.PaymentID, RefundID: "re_dashboard"}
+		require.Equal(t, http.StatusOK, sendPaymentEvent(t, event))
+		assert.Equal(t, entity.OrderStatusRefunded, orderStatus(t, c, order.OrderID))
+
+		// The provider delivering the event again is acknowledged
+		assert.Equal(t, http.StatusOK, sendPaymentEvent(t, event))
+		assert.NotContains(t, api.payments.refunded(), order.PaymentID)
+
+		_, err := c.RefundOrder(ctx, entity.RefundOrderRequest{PaymentID: order.PaymentID})
+		assert.True(t, client.IsConflict(err), "refund after webhook: %v", err)
+	})
+
+	t.Run("refund received before the payment is recorded", func(t *testing.T) {
+		// Buffered until an order is paid with the payment
+		status := sendPaymentEvent(t, entity.PaymentEvent{ID: unique("evt_early"), Event: entity.PaymentEventRefunded, PaymentID: unique("pay_not_recorded")})
+		assert.Equal(t, http.StatusAccepted, status)
+	})
+
+	t.Run("confirmation email", func(t *testing.T) {
+		// Emails are delivered by the job workers
+		assert.Eventually(t, func() bool {
+			confirmations := 0
+			for _, subject := range api.notifications.subjects(user.Email) {
+				if subject == "Order Confirmation" {
+					confirmations++
+				}
+			}
+			return confirmations == 2
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestOrderFlow_Isolation(t *testing.T) {
+	ctx := context.Background()
+	owner := newClient(t)
+	order := placeOrder(t, owner, signUp(t, owner, "owner"), "ord-e2e-3")
+
+	other := newClient(t)
+	signUp(t, other, "other")
+
+	_, err := other.RefundOrder(ctx, entity.RefundOrderRequest{PaymentID: order.PaymentID})
+	assert.True(t, client.IsNotFound(err), "refund of another user's order: %v", err)
+
+	orders, _, err := other.ListOrders(ctx, client.ListOrdersOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, orders)
+}
+
+func TestOrderHistoryStream(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+	user := signUp(t, c, "streamer")
+	var placed []string
+	for i := 0; i < 3; i++ {
+		placed = append(placed, placeOrder(t, c, user, "ord-e2e-stream").OrderID)
+	}
+
+	var streamed []string
+	require.NoError(t, c.StreamOrders(ctx, client.ListOrdersOptions{PageSize: 1}, func(order *entity.Order) error {
+		streamed = append(streamed, order.OrderID)
+		return nil
+	}))
+	assert.Equal(t, []string{placed[2], placed[1], placed[0]}, streamed, "every order, newest first")
+
+	err := newClient(t).StreamOrders(ctx, client.ListOrdersOptions{}, func(*entity.Order) error { return nil })
+	assert.True(t, client.IsUnauthorized(err), "without token: %v", err)
+}
+
+func TestAuthentication(t *testing.T) {
+	ctx := context.Background()
+
+	_, _, err := newClient(t).ListOrders(ctx, client.ListOrdersOptions{})
+	assert.True(t, client.IsUnauthorized(err), "without token: %v", err)
+
+	c := newClient(t)
+	user := signUp(t, c, "revoker")
+	_, err = c.GetProfile(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, c.RevokeAllSessions(ctx))
+	_, err = c.GetProfile(ctx)
+	assert.True(t, client.IsUnauthorized(err), "after revoking sessions: %v", err)
+
+	_, err = c.Login(ctx, entity.LoginRequest{Username: user.Username, Password: "wrong-password"})
+	assert.True(t, client.IsUnauthorized(err), "wrong password: %v", err)
+}
+
+func TestPaymentWebhook_RejectsUnsignedEvents(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, api.url+"/api/v1/webhooks/payments", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Signature-Key-Id", signingKeyID)
+	req.Header.Set("X-Signature-Timestamp", fmt.Sprint(time.Now().Unix()))
+	req.Header.Set("X-Signature", "forged")
+
+	resp, err := httpClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestOrderFlow_DryRun(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+	user := signUp(t, c, "dry-runner")
+	req := entity.CreateOrderRequest{
+		OrderID:   unique("ord-e2e-dry"),
+		UserID:    user.ID,
+		Items:     []entity.OrderItem{{ProductID: "sku-1", Quantity: 3, UnitPrice: 10}},
+		Currency:  "USD",
+		UserEmail: user.Email,
+		DryRun:    true,
+	}
+
+	preview, err := c.CreateOrder(ctx, req)
+	require.NoError(t, err)
+	require.NotNil(t, preview.DryRun)
+	assert.Equal(t, 30.0, preview.DryRun.Total)
+	assert.Len(t, preview.DryRun.ProviderCalls, 2)
+	assert.True(t, strings.HasPrefix(preview.PaymentID, "mock_pay_"), "payment %s not simulated", preview.PaymentID)
+
+	orders, _, err := c.ListOrders(ctx, client.ListOrdersOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, orders, "dry run persisted the order")
+
+	// The order ID is still free for the real order
+	req.DryRun = false
+	order, err := c.CreateOrder(ctx, req)
+	require.NoError(t, err)
+	assert.Nil(t, order.DryRun)
+	assert.Equal(t, entity.OrderStatusCompleted, order.Status)
+}
+
+func TestOrderFlow_ReadOnlyToken(t *testing.T) {
+	ctx := context.Background()
+	owner := newClient(t)
+	user := signUp(t, owner, "reporter")
+	order := placeOrder(t, owner, user, "ord-e2e-read")
+
+	issued, err := owner.IssueAccessToken(ctx, entity.AccessTokenRequest{Name: "reporting", Scopes: []string{entity.ScopeOrdersRead}})
+	require.NoError(t, err)
+	machine := newClient(t)
+	machine.SetToken(issued.Token)
+
+	orders, _, err := machine.ListOrders(ctx, client.ListOrdersOptions{})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, order.OrderID, orders[0].OrderID)
+
+	_, err = machine.CancelOrder(ctx, order.OrderID)
+	assert.True(t, client.IsForbidden(err), "cancel with orders:read: %v", err)
+	_, err = machine.GetProfile(ctx)
+	assert.True(t, client.IsForbidden(err), "profile with orders:read: %v", err)
+
+	// A token cannot issue one with more scopes than it holds
+	_, err = machine.IssueAccessToken(ctx, entity.AccessTokenRequest{Name: "wider", Scopes: []string{entity.ScopeOrdersWrite}})
+	assert.True(t, client.IsForbidden(err), "escalation: %v", err)
+	_, err = owner.IssueAccessToken(ctx, entity.AccessTokenRequest{Name: "admin", Scopes: []string{entity.ScopeAdmin}})
+	assert.True(t, client.IsForbidden(err), "admin scope for a user: %v", err)
+}
@@ -0,0 +1,36 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileFlow_StaleVersion(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+	signUp(t, c, "editor")
+
+	profile, err := c.GetProfile(ctx)
+	require.NoError(t, err)
+	read := profile.Version
+
+	first, last := "Ada", "Lovelace"
+	updated, err := c.UpdateProfile(ctx, entity.UpdateProfileRequest{FirstName: &first, Version: &read})
+	require.NoError(t, err)
+	assert.Equal(t, read+1, updated.Version)
+
+	// A second edit made from the same read must not overwrite the first
+	_, err = c.UpdateProfile(ctx, entity.UpdateProfileRequest{LastName: &last, Version: &read})
+	assert.True(t, client.IsConflict(err), "stale version: %v", err)
+
+	profile, err = c.GetProfile(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, first, profile.FirstName)
+	assert.Empty(t, profile.LastName)
+}
@@ -0,0 +1,105 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/scim"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scimRequest sends a SCIM request as an identity provider and decodes the
+// response into out, returning the status
+func scimRequest(t *testing.T, method, path, token string, body, out interface{}) int {
+	var payload bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&payload).Encode(body))
+	}
+	req, err := http.NewRequest(method, api.url+"/scim/v2"+path, &payload)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", scim.MediaType)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := httpClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode < http.StatusMultipleChoices {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp.StatusCode
+}
+
+func TestSCIMFlow(t *testing.T) {
+	ctx := context.Background()
+	username := unique("scim")
+	email := username + "@corp.example"
+
+	var created scim.User
+	status := scimRequest(t, http.MethodPost, "/Users", scimToken, scim.User{
+		Schemas:  []string{scim.SchemaUser},
+		UserName: username,
+		Name:     &scim.Name{GivenName: "Sam", FamilyName: "Provisioned"},
+		Emails:   []scim.Email{{Value: email, Type: "work", Primary: true}},
+		Password: "provisioned-password",
+	}, &created)
+	require.Equal(t, http.StatusCreated, status)
+	require.NotEmpty(t, created.ID)
+	assert.True(t, created.IsActive())
+	assert.NotEmpty(t, created.ExternalID)
+
+	t.Run("rejects other tokens", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, scimRequest(t, http.MethodGet, "/Users", "wrong", nil, nil))
+	})
+
+	t.Run("conflicting userName", func(t *testing.T) {
+		status := scimRequest(t, http.MethodPost, "/Users", scimToken, scim.User{
+			UserName: username,
+			Emails:   []scim.Email{{Value: "other-" + email}},
+		}, nil)
+		assert.Equal(t, http.StatusConflict, status)
+	})
+
+	t.Run("find by userName", func(t *testing.T) {
+		var list scim.ListResponse
+		query := "/Users?filter=" + url.QueryEscape(`userName eq "`+username+`"`)
+		require.Equal(t, http.StatusOK, scimRequest(t, http.MethodGet, query, scimToken, nil, &list))
+		assert.Equal(t, 1, list.TotalResults)
+	})
+
+	t.Run("deactivated users cannot sign in", func(t *testing.T) {
+		c := newClient(t)
+		_, err := c.Login(ctx, entity.LoginRequest{Username: username, Password: "provisioned-password"})
+		require.NoError(t, err)
+
+		var patched scim.User
+		status := scimRequest(t, http.MethodPatch, "/Users/"+created.ID, scimToken, scim.PatchRequest{
+			Schemas:    []string{scim.SchemaPatchOp},
+			Operations: []scim.PatchOperation{{Op: "replace", Path: "active", Value: json.RawMessage("false")}},
+		}, &patched)
+		require.Equal(t, http.StatusOK, status)
+		assert.False(t, patched.IsActive())
+
+		_, err = c.Login(ctx, entity.LoginRequest{Username: username, Password: "provisioned-password"})
+		assert.Error(t, err)
+	})
+
+	t.Run("reactivate and deactivate with DELETE", func(t *testing.T) {
+		created.Active = nil
+		created.Password = ""
+		var replaced scim.User
+		require.Equal(t, http.StatusOK, scimRequest(t, http.MethodPut, "/Users/"+created.ID, scimToken, created, &replaced))
+		assert.True(t, replaced.IsActive())
+
+		require.Equal(t, http.StatusNoContent, scimRequest(t, http.MethodDelete, "/Users/"+created.ID, scimToken, nil, nil))
+		var fetched scim.User
+		require.Equal(t, http.StatusOK, scimRequest(t, http.MethodGet, "/Users/"+created.ID, scimToken, nil, &fetched))
+		assert.False(t, fetched.IsActive())
+	})
+}
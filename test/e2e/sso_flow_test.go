@@ -0,0 +1,145 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/oidc/oidctest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// apiRequest sends a JSON request with the bearer token, when set, and decodes
+// the data of the response into out, returning the status
+func apiRequest(t *testing.T, method, path, token string, body, out interface{}) int {
+	var payload bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&payload).Encode(body))
+	}
+	req, err := http.NewRequest(method, api.url+path, &payload)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := httpClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode < http.StatusMultipleChoices {
+		envelope := struct {
+			Data interface{} `json:"data"`
+		}{Data: out}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&envelope))
+	}
+	return resp.StatusCode
+}
+
+// ssoLogin signs in through the organization's identity provider as the user
+// with the claims, returning the status of the callback
+func ssoLogin(t *testing.T, provider *oidctest.Provider, orgID int, claims map[string]interface{}, out *entity.LoginResponse) int {
+	var start entity.SSOStart
+	status := apiRequest(t, http.MethodPost, "/api/v1/auth/sso/start", "", entity.SSOStartRequest{OrganizationID: orgID}, &start)
+	require.Equal(t, http.StatusOK, status)
+
+	code, err := provider.Authorize(start.AuthorizationURL, claims)
+	require.NoError(t, err)
+	return apiRequest(t, http.MethodPost, "/api/v1/auth/sso/callback", "", entity.SSOCallbackRequest{State: start.State, Code: code}, out)
+}
+
+func TestSSOFlow(t *testing.T) {
+	provider := oidctest.NewProvider("e2e-app")
+	defer provider.Close()
+
+	owner := newClient(t)
+	signUp(t, owner, "sso-owner")
+
+	var org entity.Organization
+	status := apiRequest(t, http.MethodPost, "/api/v1/organizations", owner.Token(), entity.CreateOrganizationRequest{Name: unique("SSO Corp")}, &org)
+	require.Equal(t, http.StatusCreated, status)
+	ssoPath := "/api/v1/organizations/" + strconv.Itoa(org.ID) + "/sso"
+
+	t.Run("issuer without discovery", func(t *testing.T) {
+		status := apiRequest(t, http.MethodPut, ssoPath, owner.Token(), entity.SSOConnectionRequest{
+			Protocol: entity.SSOProtocolOIDC,
+			Issuer:   provider.URL + "/unknown",
+			ClientID: "e2e-app",
+		}, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	secret := "e2e-secret"
+	var conn entity.SSOConnection
+	status = apiRequest(t, http.MethodPut, ssoPath, owner.Token(), entity.SSOConnectionRequest{
+		Protocol:     entity.SSOProtocolOIDC,
+		Issuer:       provider.URL,
+		ClientID:     "e2e-app",
+		ClientSecret: &secret,
+	}, &conn)
+	require.Equal(t, http.StatusOK, status)
+	assert.True(t, conn.JITProvisioning)
+
+	t.Run("only owners configure", func(t *testing.T) {
+		other := newClient(t)
+		signUp(t, other, "sso-outsider")
+		assert.Equal(t, http.StatusNotFound, apiRequest(t, http.MethodGet, ssoPath, other.Token(), nil, nil))
+	})
+
+	username := unique("sso-jit")
+	claims := map[string]interface{}{
+		"sub":            "subject-" + username,
+		"email":          username + "@corp.example",
+		"email_verified": true,
+		"given_name":     "Jit",
+	}
+
+	var first entity.LoginResponse
+	require.Equal(t, http.StatusOK, ssoLogin(t, provider, org.ID, claims, &first))
+	assert.Equal(t, username, first.User.Username)
+	assert.Equal(t, "Jit", first.User.FirstName)
+	require.NotEmpty(t, first.Token)
+
+	t.Run("provisioned user joined the organization", func(t *testing.T) {
+		var got entity.Organization
+		path := "/api/v1/organizations/" + strconv.Itoa(org.ID)
+		require.Equal(t, http.StatusOK, apiRequest(t, http.MethodGet, path, first.Token, nil, &got))
+		assert.Equal(t, entity.OrgRoleMember, got.Role)
+	})
+
+	t.Run("signs in the linked user again", func(t *testing.T) {
+		var again entity.LoginResponse
+		require.Equal(t, http.StatusOK, ssoLogin(t, provider, org.ID, claims, &again))
+		assert.Equal(t, first.User.ID, again.User.ID)
+	})
+
+	t.Run("state is redeemed once", func(t *testing.T) {
+		var start entity.SSOStart
+		require.Equal(t, http.StatusOK, apiRequest(t, http.MethodPost, "/api/v1/auth/sso/start", "", entity.SSOStartRequest{OrganizationID: org.ID}, &start))
+		code, err := provider.Authorize(start.AuthorizationURL, claims)
+		require.NoError(t, err)
+		callback := entity.SSOCallbackRequest{State: start.State, Code: code}
+		require.Equal(t, http.StatusOK, apiRequest(t, http.MethodPost, "/api/v1/auth/sso/callback", "", callback, nil))
+		assert.Equal(t, http.StatusBadRequest, apiRequest(t, http.MethodPost, "/api/v1/auth/sso/callback", "", callback, nil))
+	})
+
+	t.Run("email of an account outside the organization", func(t *testing.T) {
+		outsider := newClient(t)
+		user := signUp(t, outsider, "sso-taken")
+		status := ssoLogin(t, provider, org.ID, map[string]interface{}{
+			"sub":            "subject-" + user.Username,
+			"email":          user.Email,
+			"email_verified": true,
+		}, nil)
+		assert.Equal(t, http.StatusConflict, status)
+	})
+
+	t.Run("organization without single sign-on", func(t *testing.T) {
+		status := apiRequest(t, http.MethodPost, "/api/v1/auth/sso/start", "", entity.SSOStartRequest{OrganizationID: org.ID + 1000}, nil)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
@@ -0,0 +1,1634 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"boilerplate-go/internal/domain/entity"
+	"boilerplate-go/pkg/errors"
+	"boilerplate-go/server"
+)
+
+// memory holds the tables of the in-memory datastore behind one lock, so
+// repositories behave like the PostgreSQL ones within a test process.
+type memory struct {
+	mu          sync.Mutex
+	seq         int
+	users       map[int]entity.User
+	sessions    map[int]entity.Session
+	orders      map[string]entity.Order
+	coupons     map[int]entity.Coupon
+	redemptions []entity.CouponRedemption
+	jobs        map[int]entity.Job
+	deadLetters map[int]entity.DeadLetterJob
+	imports     map[int]entity.UserImportReport
+	importRows  map[int][]entity.UserImportRow
+	campaigns   map[int]entity.Notification
+	otps        map[string]entity.OTPCode
+	uploads     map[int]entity.Upload
+	revenue     map[string]entity.RevenueReportRow
+	signups     map[string]int
+	exports     map[int]entity.Export
+	changes     []entity.ConfigChange
+	webhooks    map[string]time.Time
+	buffered    []entity.PaymentEvent
+	sagas       map[int]entity.Saga
+	usage       map[[2]string]entity.ProviderUsage
+	orgs        map[int]entity.Organization
+	members     map[[2]int]entity.Membership
+	invitations map[int]entity.Invitation
+	connections map[int]entity.SSOConnection
+	ssoLogins   map[string]entity.SSOLogin
+	identities  map[int]entity.UserIdentity
+}
+
+// newDatastore creates the in-memory repositories
+func newDatastore() *server.Repositories {
+	m := &memory{
+		users:       make(map[int]entity.User),
+		sessions:    make(map[int]entity.Session),
+		orders:      make(map[string]entity.Order),
+		coupons:     make(map[int]entity.Coupon),
+		jobs:        make(map[int]entity.Job),
+		deadLetters: make(map[int]entity.DeadLetterJob),
+		imports:     make(map[int]entity.UserImportReport),
+		importRows:  make(map[int][]entity.UserImportRow),
+		campaigns:   make(map[int]entity.Notification),
+		otps:        make(map[string]entity.OTPCode),
+		uploads:     make(map[int]entity.Upload),
+		revenue:     make(map[string]entity.RevenueReportRow),
+		signups:     make(map[string]int),
+		exports:     make(map[int]entity.Export),
+		webhooks:    make(map[string]time.Time),
+		sagas:       make(map[int]entity.Saga),
+		usage:       make(map[[2]string]entity.ProviderUsage),
+		orgs:        make(map[int]entity.Organization),
+		members:     make(map[[2]int]entity.Membership),
+		invitations: make(map[int]entity.Invitation),
+		connections: make(map[int]entity.SSOConnection),
+		ssoLogins:   make(map[string]entity.SSOLogin),
+		identities:  make(map[int]entity.UserIdentity),
+	}
+	return &server.Repositories{
+		User:         userStore{m},
+		Session:      sessionStore{m},
+		Order:        orderStore{m},
+		Coupon:       couponStore{m},
+		DeadLetter:   deadLetterStore{m},
+		Job:          jobStore{m},
+		UserImport:   userImportStore{m},
+		Notification: notificationStore{m},
+		OTP:          otpStore{m},
+		Upload:       uploadStore{m},
+		Stats:        statsStore{m},
+		Report:       reportStore{m},
+		Export:       exportStore{m},
+		ConfigChange: configChangeStore{m},
+		WebhookEvent: webhookEventStore{m},
+		EventBuffer:  eventBufferStore{m},
+		Saga:         sagaStore{m},
+		Usage:        usageStore{m},
+		Organization: organizationStore{m},
+		SSO:          ssoStore{m},
+	}
+}
+
+func (m *memory) nextID() int {
+	m.seq++
+	return m.seq
+}
+
+// page returns the bounds of a page of total items
+func page(total, number, size int) (int, int) {
+	if size <= 0 {
+		return 0, total
+	}
+	if number < 1 {
+		number = 1
+	}
+	start := min((number-1)*size, total)
+	return start, min(start+size, total)
+}
+
+type userStore struct{ *memory }
+
+func (s userStore) Create(ctx context.Context, user *entity.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.users {
+		if strings.EqualFold(existing.Username, user.Username) || strings.EqualFold(existing.Email, user.Email) {
+			return errors.ErrUserAlreadyExists
+		}
+	}
+	user.ID, user.Version = s.nextID(), 1
+	user.CreatedAt, user.UpdatedAt = time.Now(), time.Now()
+	s.users[user.ID] = *user
+	return nil
+}
+
+func (s userStore) CreateBatch(ctx context.Context, users []*entity.User) error {
+	for _, user := range users {
+		if err := s.Create(ctx, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s userStore) Upsert(ctx context.Context, user *entity.User) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, existing := range s.users {
+		if !strings.EqualFold(existing.Email, user.Email) {
+			continue
+		}
+		existing.Username, existing.ExternalID = user.Username, user.ExternalID
+		existing.FirstName, existing.LastName = user.FirstName, user.LastName
+		existing.UpdatedAt = time.Now()
+		existing.Version++
+		s.users[id] = existing
+		*user = existing
+		return false, nil
+	}
+	for _, existing := range s.users {
+		if existing.Username == user.Username {
+			return false, errors.ErrUserAlreadyExists
+		}
+	}
+	user.ID, user.Version = s.nextID(), 1
+	user.CreatedAt, user.UpdatedAt = time.Now(), time.Now()
+	s.users[user.ID] = *user
+	return true, nil
+}
+
+func (s userStore) find(match func(entity.User) bool) (*entity.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, user := range s.users {
+		if match(user) {
+			return &user, nil
+		}
+	}
+	return nil, errors.ErrUserNotFound
+}
+
+func (s userStore) GetByID(ctx context.Context, id int) (*entity.User, error) {
+	return s.find(func(u entity.User) bool { return u.ID == id })
+}
+
+func (s userStore) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return s.find(func(u entity.User) bool { return strings.EqualFold(u.Username, username) })
+}
+
+func (s userStore) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return s.find(func(u entity.User) bool { return strings.EqualFold(u.Email, email) })
+}
+
+func (s userStore) List(ctx context.Context, filter entity.UserFilter) ([]*entity.User, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matched []*entity.User
+	for _, user := range s.users {
+		if (filter.Username == "" || strings.EqualFold(user.Username, filter.Username)) &&
+			(filter.Email == "" || strings.EqualFold(user.Email, filter.Email)) &&
+			(filter.ExternalID == "" || user.ExternalID == filter.ExternalID) {
+			matched = append(matched, &user)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	start, end := min(filter.Offset, len(matched)), min(filter.Offset+filter.Limit, len(matched))
+	return matched[start:end], len(matched), nil
+}
+
+func (s userStore) IterateActiveUsers(ctx context.Context, fn func(*entity.User) error) error {
+	s.mu.Lock()
+	var users []*entity.User
+	for _, user := range s.users {
+		if user.DeactivatedAt == nil && !strings.HasSuffix(user.Email, "@anonymized.invalid") {
+			users = append(users, &user)
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return each(users, fn)
+}
+
+func (s userStore) GetByPhone(ctx context.Context, phone string) (*entity.User, error) {
+	return s.find(func(u entity.User) bool { return u.Phone == phone && u.PhoneVerifiedAt != nil })
+}
+
+func (s userStore) UpdatePhone(ctx context.Context, id int, phone string, verifiedAt *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	if !ok {
+		return errors.ErrUserNotFound
+	}
+	user.Phone, user.PhoneVerifiedAt, user.UpdatedAt = phone, verifiedAt, time.Now()
+	s.users[id] = user
+	return nil
+}
+
+func (s userStore) Update(ctx context.Context, user *entity.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.users[user.ID]
+	if !ok {
+		return errors.ErrUserNotFound
+	}
+	if stored.Version != user.Version {
+		return fmt.Errorf("%w: user %d is no longer at version %d", errors.ErrConflict, user.ID, user.Version)
+	}
+	user.UpdatedAt = time.Now()
+	user.Version++
+	s.users[user.ID] = *user
+	return nil
+}
+
+func (s userStore) Delete(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.users, id)
+	return nil
+}
+
+func (s userStore) Anonymize(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	if !ok {
+		return errors.ErrUserNotFound
+	}
+	s.users[id] = entity.User{
+		ID:        id,
+		Username:  fmt.Sprintf("deleted_user_%d", id),
+		Email:     fmt.Sprintf("deleted_user_%d@anonymized.invalid", id),
+		Role:      user.Role,
+		Version:   user.Version + 1,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: time.Now(),
+	}
+	return nil
+}
+
+type sessionStore struct{ *memory }
+
+func (s sessionStore) Create(ctx context.Context, session *entity.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session.ID = s.nextID()
+	session.CreatedAt, session.LastSeenAt = time.Now(), time.Now()
+	s.sessions[session.ID] = *session
+	return nil
+}
+
+func (s sessionStore) GetByTokenID(ctx context.Context, tokenID string) (*entity.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, session := range s.sessions {
+		if session.TokenID == tokenID {
+			return &session, nil
+		}
+	}
+	return nil, errors.ErrSessionNotFound
+}
+
+func (s sessionStore) ListActiveByUserID(ctx context.Context, userID int) ([]*entity.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sessions []*entity.Session
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && session.ExpiresAt.After(time.Now()) {
+			sessions = append(sessions, &session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt) })
+	return sessions, nil
+}
+
+func (s sessionStore) ListByUserIDSince(ctx context.Context, userID int, since time.Time) ([]*entity.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sessions []*entity.Session
+	for _, session := range s.sessions {
+		if session.UserID == userID && !session.CreatedAt.Before(since) {
+			sessions = append(sessions, &session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].CreatedAt.After(sessions[j].CreatedAt) })
+	return sessions, nil
+}
+
+func (s sessionStore) Revoke(ctx context.Context, userID, sessionID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[sessionID]
+	if !ok || session.UserID != userID || session.RevokedAt != nil {
+		return errors.ErrSessionNotFound
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	s.sessions[sessionID] = session
+	return nil
+}
+
+func (s sessionStore) RevokeAllByUserID(ctx context.Context, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for id, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			s.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+func (s sessionStore) Touch(ctx context.Context, tokenID string, seenAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, session := range s.sessions {
+		if session.TokenID == tokenID && session.RevokedAt == nil {
+			session.LastSeenAt = seenAt
+			s.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+type orderStore struct{ *memory }
+
+func (s orderStore) Create(ctx context.Context, order *entity.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.orders[order.OrderID]; exists {
+		return errors.ErrOrderAlreadyExists
+	}
+	order.ID, order.Version = s.nextID(), 1
+	order.CreatedAt, order.UpdatedAt = time.Now(), time.Now()
+	s.orders[order.OrderID] = *order
+	return nil
+}
+
+func (s orderStore) GetByOrderID(ctx context.Context, orderID string) (*entity.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	order, ok := s.orders[orderID]
+	if !ok {
+		return nil, errors.ErrOrderNotFound
+	}
+	return &order, nil
+}
+
+func (s orderStore) GetByPaymentID(ctx context.Context, paymentID string) (*entity.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, order := range s.orders {
+		if paymentID != "" && order.PaymentID == paymentID {
+			return &order, nil
+		}
+	}
+	return nil, errors.ErrOrderNotFound
+}
+
+func (s orderStore) UpdateStatus(ctx context.Context, order *entity.Order, fromStatus string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.orders[order.OrderID]
+	if !ok || stored.Status != fromStatus {
+		return fmt.Errorf("%w: order is no longer %s", errors.ErrInvalidOrderState, fromStatus)
+	}
+	if stored.Version != order.Version {
+		return fmt.Errorf("%w: order is no longer at version %d", errors.ErrConflict, order.Version)
+	}
+	order.Version++
+	stored.Status = order.Status
+	stored.Version = order.Version
+	stored.PaymentID = order.PaymentID
+	stored.PaymentIntentID = order.PaymentIntentID
+	stored.UpdatedAt = time.Now()
+	s.orders[order.OrderID] = stored
+	return nil
+}
+
+func (s orderStore) List(ctx context.Context, filter entity.OrderFilter) ([]*entity.Order, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var orders []*entity.Order
+	for _, order := range s.orders {
+		switch {
+		case filter.OrganizationID != 0 && (order.OrganizationID == nil || *order.OrganizationID != filter.OrganizationID),
+			filter.OrganizationID == 0 && filter.UserID != 0 && order.UserID != filter.UserID,
+			filter.Status != "" && order.Status != filter.Status,
+			filter.From != nil && order.CreatedAt.Before(*filter.From),
+			filter.To != nil && order.CreatedAt.After(*filter.To),
+			filter.MinAmount != nil && order.Amount < *filter.MinAmount,
+			filter.MaxAmount != nil && order.Amount > *filter.MaxAmount:
+			continue
+		}
+		orders = append(orders, &order)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID > orders[j].ID })
+	start, end := page(len(orders), filter.Page, filter.PageSize)
+	return orders[start:end], len(orders), nil
+}
+
+func (s orderStore) ListAfter(ctx context.Context, filter entity.OrderFilter, after *entity.Order, limit int) ([]*entity.Order, error) {
+	filter.PageSize = 0
+	orders, _, err := s.List(ctx, filter)
+	for len(orders) > 0 && after != nil && orders[0].ID >= after.ID {
+		orders = orders[1:]
+	}
+	return orders[:min(limit, len(orders))], err
+}
+
+type couponStore struct{ *memory }
+
+func (s couponStore) Create(ctx context.Context, coupon *entity.Coupon) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.coupons {
+		if existing.Code == coupon.Code {
+			return errors.ErrCouponAlreadyExists
+		}
+	}
+	coupon.ID = s.nextID()
+	coupon.CreatedAt, coupon.UpdatedAt = time.Now(), time.Now()
+	s.coupons[coupon.ID] = *coupon
+	return nil
+}
+
+func (s couponStore) GetByID(ctx context.Context, id int) (*entity.Coupon, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	coupon, ok := s.coupons[id]
+	if !ok {
+		return nil, errors.ErrCouponNotFound
+	}
+	return &coupon, nil
+}
+
+func (s couponStore) GetByCode(ctx context.Context, code string) (*entity.Coupon, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, coupon := range s.coupons {
+		if coupon.Code == code {
+			return &coupon, nil
+		}
+	}
+	return nil, errors.ErrCouponNotFound
+}
+
+func (s couponStore) List(ctx context.Context) ([]*entity.Coupon, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var coupons []*entity.Coupon
+	for _, coupon := range s.coupons {
+		coupons = append(coupons, &coupon)
+	}
+	sort.Slice(coupons, func(i, j int) bool { return coupons[i].ID < coupons[j].ID })
+	return coupons, nil
+}
+
+func (s couponStore) Update(ctx context.Context, coupon *entity.Coupon) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.coupons[coupon.ID]; !ok {
+		return errors.ErrCouponNotFound
+	}
+	coupon.UpdatedAt = time.Now()
+	s.coupons[coupon.ID] = *coupon
+	return nil
+}
+
+func (s couponStore) Delete(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.coupons[id]; !ok {
+		return errors.ErrCouponNotFound
+	}
+	delete(s.coupons, id)
+	return nil
+}
+
+func (s couponStore) CountRedemptionsByUser(ctx context.Context, couponID, userID int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, redemption := range s.redemptions {
+		if redemption.CouponID == couponID && redemption.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s couponStore) Redeem(ctx context.Context, redemption *entity.CouponRedemption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	coupon, ok := s.coupons[redemption.CouponID]
+	switch {
+	case !ok:
+		return fmt.Errorf("%w: unknown code", errors.ErrCouponInvalid)
+	case !coupon.Active:
+		return fmt.Errorf("%w: coupon is inactive", errors.ErrCouponInvalid)
+	case coupon.MaxRedemptions != nil && coupon.RedeemedCount >= *coupon.MaxRedemptions:
+		return fmt.Errorf("%w: redemption limit reached", errors.ErrCouponInvalid)
+	}
+	coupon.RedeemedCount++
+	s.coupons[coupon.ID] = coupon
+	redemption.ID = s.nextID()
+	redemption.RedeemedAt = time.Now()
+	s.redemptions = append(s.redemptions, *redemption)
+	return nil
+}
+
+func (s couponStore) ReleaseByOrderID(ctx context.Context, orderID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.redemptions[:0]
+	for _, redemption := range s.redemptions {
+		if redemption.OrderID != orderID {
+			kept = append(kept, redemption)
+			continue
+		}
+		coupon := s.coupons[redemption.CouponID]
+		coupon.RedeemedCount--
+		s.coupons[coupon.ID] = coupon
+	}
+	s.redemptions = kept
+	return nil
+}
+
+type jobStore struct{ *memory }
+
+func (s jobStore) Create(ctx context.Context, job *entity.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.ID = s.nextID()
+	job.CreatedAt = time.Now()
+	if job.RunAt.IsZero() {
+		job.RunAt = job.CreatedAt
+	}
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+func (s jobStore) CreateUnlessQueued(ctx context.Context, job *entity.Job) (bool, error) {
+	s.mu.Lock()
+	for _, queued := range s.jobs {
+		if queued.JobType == job.JobType {
+			s.mu.Unlock()
+			return false, nil
+		}
+	}
+	s.mu.Unlock()
+	return true, s.Create(ctx, job)
+}
+
+func (s jobStore) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var due []*entity.Job
+	for id, job := range s.jobs {
+		if len(due) == limit || job.RunAt.After(now) {
+			continue
+		}
+		job.Attempts++
+		job.RunAt = now.Add(lease)
+		s.jobs[id] = job
+		due = append(due, &job)
+	}
+	return due, nil
+}
+
+func (s jobStore) Reschedule(ctx context.Context, id int, runAt time.Time, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil
+	}
+	job.RunAt, job.LastError = runAt, lastError
+	s.jobs[id] = job
+	return nil
+}
+
+func (s jobStore) Delete(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+	return nil
+}
+
+type deadLetterStore struct{ *memory }
+
+func (s deadLetterStore) Create(ctx context.Context, job *entity.DeadLetterJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.ID = s.nextID()
+	job.CreatedAt = time.Now()
+	s.deadLetters[job.ID] = *job
+	return nil
+}
+
+func (s deadLetterStore) GetByID(ctx context.Context, id int) (*entity.DeadLetterJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.deadLetters[id]
+	if !ok {
+		return nil, errors.ErrDeadLetterNotFound
+	}
+	return &job, nil
+}
+
+func (s deadLetterStore) List(ctx context.Context, filter entity.DeadLetterFilter) ([]*entity.DeadLetterJob, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var jobs []*entity.DeadLetterJob
+	for _, job := range s.deadLetters {
+		if filter.JobType == "" || job.JobType == filter.JobType {
+			jobs = append(jobs, &job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID > jobs[j].ID })
+	start, end := page(len(jobs), filter.Page, filter.PageSize)
+	return jobs[start:end], len(jobs), nil
+}
+
+func (s deadLetterStore) Delete(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.deadLetters[id]; !ok {
+		return errors.ErrDeadLetterNotFound
+	}
+	delete(s.deadLetters, id)
+	return nil
+}
+
+func (s deadLetterStore) Count(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.deadLetters), nil
+}
+
+type userImportStore struct{ *memory }
+
+func (s userImportStore) Create(ctx context.Context, report *entity.UserImportReport, rows []entity.UserImportRow) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report.ID = s.nextID()
+	now := time.Now()
+	report.CreatedAt = &now
+	s.imports[report.ID] = *report
+	s.importRows[report.ID] = rows
+	return nil
+}
+
+func (s userImportStore) GetByID(ctx context.Context, id int) (*entity.UserImportReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report, ok := s.imports[id]
+	if !ok {
+		return nil, errors.ErrImportNotFound
+	}
+	return &report, nil
+}
+
+func (s userImportStore) GetRows(ctx context.Context, id int) ([]entity.UserImportRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rows, ok := s.importRows[id]
+	if !ok {
+		return nil, errors.ErrImportNotFound
+	}
+	return rows, nil
+}
+
+func (s userImportStore) Complete(ctx context.Context, report *entity.UserImportReport) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.imports[report.ID] = *report
+	delete(s.importRows, report.ID)
+	return nil
+}
+
+type notificationStore struct{ *memory }
+
+func (s notificationStore) Create(ctx context.Context, notification *entity.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	notification.ID = s.nextID()
+	notification.CreatedAt = time.Now()
+	s.campaigns[notification.ID] = *notification
+	return nil
+}
+
+func (s notificationStore) GetByID(ctx context.Context, id int) (*entity.Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	notification, ok := s.campaigns[id]
+	if !ok {
+		return nil, errors.ErrCampaignNotFound
+	}
+	return &notification, nil
+}
+
+func (s notificationStore) RecordChunk(ctx context.Context, id int, chunk entity.NotificationChunk) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	notification, ok := s.campaigns[id]
+	if !ok {
+		return errors.ErrCampaignNotFound
+	}
+	if chunk.Index != notification.NextChunk {
+		return nil
+	}
+	notification.Chunks = append(notification.Chunks, chunk)
+	notification.NextChunk++
+	notification.Sent += chunk.Sent
+	notification.Failed += chunk.Failed
+	s.campaigns[id] = notification
+	return nil
+}
+
+func (s notificationStore) Complete(ctx context.Context, notification *entity.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.campaigns[notification.ID] = *notification
+	return nil
+}
+
+func (s notificationStore) IterateCompletedSince(ctx context.Context, since time.Time, fn func(*entity.Notification) error) error {
+	s.mu.Lock()
+	var notifications []*entity.Notification
+	for _, notification := range s.campaigns {
+		if notification.CompletedAt != nil && !notification.CompletedAt.Before(since) {
+			notification.Recipients = nil
+			notifications = append(notifications, &notification)
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(notifications, func(i, j int) bool { return notifications[i].ID < notifications[j].ID })
+	return each(notifications, fn)
+}
+
+func (s notificationStore) ListByRecipient(ctx context.Context, email string) ([]*entity.ReceivedNotification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	notifications := make([]*entity.ReceivedNotification, 0)
+	for _, notification := range s.campaigns {
+		if slices.Contains(notification.Recipients, email) {
+			notifications = append(notifications, &entity.ReceivedNotification{
+				ID:          notification.ID,
+				Subject:     notification.Subject,
+				Body:        notification.Body,
+				CreatedAt:   notification.CreatedAt,
+				CompletedAt: notification.CompletedAt,
+			})
+		}
+	}
+	sort.Slice(notifications, func(i, j int) bool { return notifications[i].ID < notifications[j].ID })
+	return notifications, nil
+}
+
+func (s notificationStore) UpdateEngagement(ctx context.Context, notification *entity.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.campaigns[notification.ID]
+	if !ok {
+		return errors.ErrCampaignNotFound
+	}
+	stored.Chunks = notification.Chunks
+	stored.DeliveredAt, stored.OpenedAt, stored.ClickedAt = notification.DeliveredAt, notification.OpenedAt, notification.ClickedAt
+	stored.StatusCheckedAt = notification.StatusCheckedAt
+	s.campaigns[notification.ID] = stored
+	return nil
+}
+
+type otpStore struct{ *memory }
+
+func otpKey(purpose, phone string) string {
+	return purpose + "|" + phone
+}
+
+func (s otpStore) Save(ctx context.Context, code *entity.OTPCode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code.ID = s.nextID()
+	code.CreatedAt = time.Now()
+	s.otps[otpKey(code.Purpose, code.Phone)] = *code
+	return nil
+}
+
+func (s otpStore) Get(ctx context.Context, purpose, phone string) (*entity.OTPCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code, ok := s.otps[otpKey(purpose, phone)]
+	if !ok {
+		return nil, errors.ErrOTPNotFound
+	}
+	return &code, nil
+}
+
+func (s otpStore) ConsumeAttempt(ctx context.Context, purpose, phone string) (*entity.OTPCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code, ok := s.otps[otpKey(purpose, phone)]
+	if !ok {
+		return nil, errors.ErrOTPNotFound
+	}
+	code.Attempts++
+	s.otps[otpKey(purpose, phone)] = code
+	return &code, nil
+}
+
+func (s otpStore) Delete(ctx context.Context, purpose, phone string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.otps, otpKey(purpose, phone))
+	return nil
+}
+
+type uploadStore struct{ *memory }
+
+func (s uploadStore) Create(ctx context.Context, upload *entity.Upload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	upload.ID = s.nextID()
+	upload.CreatedAt, upload.UpdatedAt = time.Now(), time.Now()
+	s.uploads[upload.ID] = *upload
+	return nil
+}
+
+func (s uploadStore) GetByID(ctx context.Context, id int) (*entity.Upload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	upload, ok := s.uploads[id]
+	if !ok {
+		return nil, errors.ErrUploadNotFound
+	}
+	upload.Parts = append([]entity.UploadPart(nil), upload.Parts...)
+	return &upload, nil
+}
+
+func (s uploadStore) ListByUser(ctx context.Context, userID int) ([]*entity.Upload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	uploads := make([]*entity.Upload, 0)
+	for _, upload := range s.uploads {
+		if upload.UserID == userID {
+			upload.Parts = nil
+			uploads = append(uploads, &upload)
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool { return uploads[i].ID < uploads[j].ID })
+	return uploads, nil
+}
+
+func (s uploadStore) SavePart(ctx context.Context, uploadID int, part *entity.UploadPart) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	upload, ok := s.uploads[uploadID]
+	if !ok {
+		return errors.ErrUploadNotFound
+	}
+	part.UploadedAt = time.Now()
+	parts := []entity.UploadPart{*part}
+	for _, existing := range upload.Parts {
+		if existing.PartNumber != part.PartNumber {
+			parts = append(parts, existing)
+		}
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	upload.Parts = parts
+	s.uploads[uploadID] = upload
+	return nil
+}
+
+func (s uploadStore) UpdateStatus(ctx context.Context, upload *entity.Upload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.uploads[upload.ID]
+	if !ok {
+		return errors.ErrUploadNotFound
+	}
+	stored.Status, stored.ScanStatus, stored.CompletedAt = upload.Status, upload.ScanStatus, upload.CompletedAt
+	stored.UpdatedAt = time.Now()
+	s.uploads[upload.ID] = stored
+	return nil
+}
+
+func (s uploadStore) UpdateScan(ctx context.Context, upload *entity.Upload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.uploads[upload.ID]
+	if !ok {
+		return errors.ErrUploadNotFound
+	}
+	stored.ScanStatus, stored.ScanThreat, stored.ScannedAt = upload.ScanStatus, upload.ScanThreat, upload.ScannedAt
+	stored.FileID = upload.FileID
+	stored.UpdatedAt = time.Now()
+	s.uploads[upload.ID] = stored
+	return nil
+}
+
+type statsStore struct{ *memory }
+
+// day returns the UTC day of t as the aggregate queries format it
+func day(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+func (s statsStore) NewUsersByDay(ctx context.Context, since time.Time) ([]entity.DailyCount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[string]int)
+	for _, user := range s.users {
+		if !user.CreatedAt.Before(since) {
+			counts[day(user.CreatedAt)]++
+		}
+	}
+	days := make([]entity.DailyCount, 0, len(counts))
+	for date, count := range counts {
+		days = append(days, entity.DailyCount{Date: date, Count: count})
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days, nil
+}
+
+func (s statsStore) OrdersByDay(ctx context.Context, since time.Time) ([]entity.DailyOrderStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byKey := make(map[string]*entity.DailyOrderStats)
+	for _, order := range s.orders {
+		if order.CreatedAt.Before(since) {
+			continue
+		}
+		key := day(order.CreatedAt) + "|" + order.Currency
+		stats, ok := byKey[key]
+		if !ok {
+			stats = &entity.DailyOrderStats{Date: day(order.CreatedAt), Currency: order.Currency}
+			byKey[key] = stats
+		}
+		stats.Orders++
+		switch order.Status {
+		case entity.OrderStatusCompleted:
+			stats.Completed++
+			stats.Revenue += order.Amount
+		case entity.OrderStatusRefunded:
+			stats.Refunded++
+		}
+	}
+	days := make([]entity.DailyOrderStats, 0, len(byKey))
+	for _, stats := range byKey {
+		days = append(days, *stats)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		if days[i].Date != days[j].Date {
+			return days[i].Date < days[j].Date
+		}
+		return days[i].Currency < days[j].Currency
+	})
+	return days, nil
+}
+
+func (s statsStore) NotificationsByDay(ctx context.Context, since time.Time) ([]entity.DailyDeliveryStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byDay := make(map[string]*entity.DailyDeliveryStats)
+	for _, notification := range s.campaigns {
+		if notification.CreatedAt.Before(since) {
+			continue
+		}
+		stats, ok := byDay[day(notification.CreatedAt)]
+		if !ok {
+			stats = &entity.DailyDeliveryStats{Date: day(notification.CreatedAt)}
+			byDay[stats.Date] = stats
+		}
+		stats.Sent += notification.Sent
+		stats.Failed += notification.Failed
+	}
+	days := make([]entity.DailyDeliveryStats, 0, len(byDay))
+	for _, stats := range byDay {
+		days = append(days, *stats)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days, nil
+}
+
+type reportStore struct{ *memory }
+
+// period returns the first day of the period of the day
+func period(date, groupBy string) string {
+	t, _ := time.Parse("2006-01-02", date)
+	switch groupBy {
+	case entity.ReportGroupWeek:
+		t = t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+	case entity.ReportGroupMonth:
+		t = t.AddDate(0, 0, 1-t.Day())
+	}
+	return day(t)
+}
+
+func (s reportStore) Rollup(ctx context.Context, date time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rolledUp := day(date)
+	for key, row := range s.revenue {
+		if row.Period == rolledUp {
+			delete(s.revenue, key)
+		}
+	}
+	delete(s.signups, rolledUp)
+
+	for _, order := range s.orders {
+		if day(order.CreatedAt) != rolledUp {
+			continue
+		}
+		key := rolledUp + "|" + order.Currency
+		row := s.revenue[key]
+		row.Period, row.Currency = rolledUp, order.Currency
+		row.Orders++
+		switch order.Status {
+		case entity.OrderStatusCompleted:
+			row.Completed++
+			row.Revenue += order.Amount
+		case entity.OrderStatusRefunded:
+			row.Refunded++
+			row.RefundedAmount += order.Amount
+		}
+		s.revenue[key] = row
+	}
+	for _, user := range s.users {
+		if day(user.CreatedAt) == rolledUp {
+			s.signups[rolledUp]++
+		}
+	}
+	return nil
+}
+
+func (s reportStore) Revenue(ctx context.Context, from, to time.Time, groupBy string) ([]entity.RevenueReportRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byKey := make(map[string]*entity.RevenueReportRow)
+	for _, daily := range s.revenue {
+		if daily.Period < day(from) || daily.Period > day(to) {
+			continue
+		}
+		key := period(daily.Period, groupBy) + "|" + daily.Currency
+		row, ok := byKey[key]
+		if !ok {
+			row = &entity.RevenueReportRow{Period: period(daily.Period, groupBy), Currency: daily.Currency}
+			byKey[key] = row
+		}
+		row.Orders += daily.Orders
+		row.Completed += daily.Completed
+		row.Refunded += daily.Refunded
+		row.Revenue += daily.Revenue
+		row.RefundedAmount += daily.RefundedAmount
+	}
+	rows := make([]entity.RevenueReportRow, 0, len(byKey))
+	for _, row := range byKey {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Period != rows[j].Period {
+			return rows[i].Period < rows[j].Period
+		}
+		return rows[i].Currency < rows[j].Currency
+	})
+	return rows, nil
+}
+
+func (s reportStore) Signups(ctx context.Context, from, to time.Time, groupBy string) ([]entity.SignupReportRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byPeriod := make(map[string]int)
+	for date, signups := range s.signups {
+		if date >= day(from) && date <= day(to) {
+			byPeriod[period(date, groupBy)] += signups
+		}
+	}
+	rows := make([]entity.SignupReportRow, 0, len(byPeriod))
+	for p, signups := range byPeriod {
+		rows = append(rows, entity.SignupReportRow{Period: p, Signups: signups})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Period < rows[j].Period })
+	return rows, nil
+}
+
+type exportStore struct{ *memory }
+
+// exported reports whether a row created at matches the filter
+func exported(filter *entity.ExportFilter, createdAt time.Time) bool {
+	return (filter.From == nil || !createdAt.Before(*filter.From)) &&
+		(filter.To == nil || createdAt.Before(*filter.To))
+}
+
+// each passes the rows to fn, stopping at its first error
+func each[T any](rows []*T, fn func(*T) error) error {
+	for _, row := range rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s exportStore) IterateUsers(ctx context.Context, filter *entity.ExportFilter, fn func(*entity.User) error) error {
+	s.mu.Lock()
+	users := make([]*entity.User, 0)
+	for _, user := range s.users {
+		if exported(filter, user.CreatedAt) && (filter.Role == "" || user.Role == filter.Role) {
+			users = append(users, &user)
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return each(users, fn)
+}
+
+func (s exportStore) IterateOrders(ctx context.Context, filter *entity.ExportFilter, fn func(*entity.Order) error) error {
+	s.mu.Lock()
+	orders := make([]*entity.Order, 0)
+	for _, order := range s.orders {
+		if exported(filter, order.CreatedAt) &&
+			(filter.Status == "" || order.Status == filter.Status) &&
+			(filter.UserID == 0 || order.UserID == filter.UserID) {
+			orders = append(orders, &order)
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return each(orders, fn)
+}
+
+func (s exportStore) IterateSessions(ctx context.Context, filter *entity.ExportFilter, fn func(*entity.Session) error) error {
+	s.mu.Lock()
+	sessions := make([]*entity.Session, 0)
+	for _, session := range s.sessions {
+		if exported(filter, session.CreatedAt) && (filter.UserID == 0 || session.UserID == filter.UserID) {
+			sessions = append(sessions, &session)
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID < sessions[j].ID })
+	return each(sessions, fn)
+}
+
+func (s exportStore) Create(ctx context.Context, export *entity.Export) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	export.ID = s.nextID()
+	export.CreatedAt = time.Now()
+	s.exports[export.ID] = *export
+	return nil
+}
+
+func (s exportStore) GetByID(ctx context.Context, id int) (*entity.Export, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	export, ok := s.exports[id]
+	if !ok {
+		return nil, errors.ErrExportNotFound
+	}
+	return &export, nil
+}
+
+func (s exportStore) Complete(ctx context.Context, export *entity.Export) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.exports[export.ID]
+	if !ok {
+		return errors.ErrExportNotFound
+	}
+	now := time.Now()
+	stored.Status, stored.Rows, stored.FileID, stored.Error = export.Status, export.Rows, export.FileID, export.Error
+	stored.CompletedAt = &now
+	s.exports[export.ID] = stored
+	export.CompletedAt = &now
+	return nil
+}
+
+type configChangeStore struct{ *memory }
+
+func (s configChangeStore) Create(ctx context.Context, change *entity.ConfigChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change.ID = s.nextID()
+	change.CreatedAt = time.Now()
+	s.changes = append(s.changes, *change)
+	return nil
+}
+
+func (s configChangeStore) List(ctx context.Context, filter entity.ConfigChangeFilter) ([]*entity.ConfigChange, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changes := make([]*entity.ConfigChange, 0)
+	for i := len(s.changes) - 1; i >= 0; i-- {
+		change := s.changes[i]
+		changes = append(changes, &change)
+	}
+	start := min(len(changes), (filter.Page-1)*filter.PageSize)
+	return changes[start:min(len(changes), start+filter.PageSize)], len(s.changes), nil
+}
+
+type webhookEventStore struct{ *memory }
+
+func (s webhookEventStore) Claim(ctx context.Context, eventID string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if expiry, ok := s.webhooks[eventID]; ok && expiry.After(time.Now()) {
+		return false, nil
+	}
+	s.webhooks[eventID] = expiresAt
+	return true, nil
+}
+
+func (s webhookEventStore) Release(ctx context.Context, eventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.webhooks, eventID)
+	return nil
+}
+
+func (s webhookEventStore) DeleteExpired(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var purged int64
+	for id, expiry := range s.webhooks {
+		if !expiry.After(time.Now()) {
+			delete(s.webhooks, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+type eventBufferStore struct{ *memory }
+
+func (s eventBufferStore) Buffer(ctx context.Context, event *entity.PaymentEvent, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buffered = append(s.buffered, *event)
+	return nil
+}
+
+func (s eventBufferStore) Take(ctx context.Context, paymentID string) ([]*entity.PaymentEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	taken := make([]*entity.PaymentEvent, 0)
+	kept := s.buffered[:0]
+	for _, event := range s.buffered {
+		if event.PaymentID == paymentID {
+			taken = append(taken, &event)
+		} else {
+			kept = append(kept, event)
+		}
+	}
+	s.buffered = kept
+	return taken, nil
+}
+
+func (s eventBufferStore) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+type sagaStore struct{ *memory }
+
+func (s sagaStore) Create(ctx context.Context, saga *entity.Saga) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saga.ID = s.nextID()
+	saga.CreatedAt, saga.UpdatedAt = time.Now(), time.Now()
+	s.sagas[saga.ID] = *saga
+	return nil
+}
+
+func (s sagaStore) Update(ctx context.Context, saga *entity.Saga) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saga.UpdatedAt = time.Now()
+	stored := *saga
+	stored.Steps = append([]string(nil), saga.Steps...)
+	s.sagas[saga.ID] = stored
+	return nil
+}
+
+func (s sagaStore) ClaimStale(ctx context.Context, before time.Time, limit int) ([]*entity.Saga, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	claimed := make([]*entity.Saga, 0)
+	for id, saga := range s.sagas {
+		if len(claimed) == limit || !saga.UpdatedAt.Before(before) {
+			continue
+		}
+		if saga.Status == entity.SagaStatusRunning || saga.Status == entity.SagaStatusCompensating {
+			saga.Status, saga.UpdatedAt = entity.SagaStatusCompensating, time.Now()
+			s.sagas[id] = saga
+			claimed = append(claimed, &saga)
+		}
+	}
+	return claimed, nil
+}
+
+func (s sagaStore) DeleteFinished(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type usageStore struct{ *memory }
+
+func (s usageStore) Add(ctx context.Context, provider, day string, calls int64, cost float64) (*entity.ProviderUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := [2]string{provider, day}
+	usage := s.usage[key]
+	usage.Provider, usage.Day = provider, day
+	usage.Calls += calls
+	usage.Cost += cost
+	s.usage[key] = usage
+	return &usage, nil
+}
+
+func (s usageStore) List(ctx context.Context, since string) ([]*entity.ProviderUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]*entity.ProviderUsage, 0)
+	for _, usage := range s.usage {
+		if usage.Day >= since {
+			list = append(list, &usage)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Day != list[j].Day {
+			return list[i].Day > list[j].Day
+		}
+		return list[i].Provider < list[j].Provider
+	})
+	return list, nil
+}
+
+type organizationStore struct{ *memory }
+
+func (s organizationStore) Create(ctx context.Context, org *entity.Organization) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	org.ID = s.nextID()
+	org.Role = entity.OrgRoleOwner
+	org.CreatedAt, org.UpdatedAt = time.Now(), time.Now()
+	s.orgs[org.ID] = *org
+	s.members[[2]int{org.ID, org.CreatedBy}] = entity.Membership{
+		OrganizationID: org.ID, UserID: org.CreatedBy, Role: entity.OrgRoleOwner,
+		CreatedAt: org.CreatedAt, UpdatedAt: org.UpdatedAt,
+	}
+	return nil
+}
+
+func (s organizationStore) GetByID(ctx context.Context, id int) (*entity.Organization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	org, ok := s.orgs[id]
+	if !ok {
+		return nil, errors.ErrOrgNotFound
+	}
+	return &org, nil
+}
+
+func (s organizationStore) ListByUser(ctx context.Context, userID int) ([]*entity.Organization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	orgs := make([]*entity.Organization, 0)
+	for key, member := range s.members {
+		if key[1] == userID {
+			org := s.orgs[key[0]]
+			org.Role = member.Role
+			orgs = append(orgs, &org)
+		}
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].ID < orgs[j].ID })
+	return orgs, nil
+}
+
+func (s organizationStore) GetMembership(ctx context.Context, orgID, userID int) (*entity.Membership, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	member, ok := s.members[[2]int{orgID, userID}]
+	if !ok {
+		return nil, errors.ErrNotOrgMember
+	}
+	return &member, nil
+}
+
+func (s organizationStore) ListMembers(ctx context.Context, orgID int) ([]*entity.Membership, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	members := make([]*entity.Membership, 0)
+	for key, member := range s.members {
+		if key[0] == orgID {
+			user := s.users[member.UserID]
+			member.Username, member.Email = user.Username, user.Email
+			members = append(members, &member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+	return members, nil
+}
+
+func (s organizationStore) AddMember(ctx context.Context, member *entity.Membership) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addMember(member)
+}
+
+func (s organizationStore) addMember(member *entity.Membership) error {
+	key := [2]int{member.OrganizationID, member.UserID}
+	if _, exists := s.members[key]; exists {
+		return errors.ErrAlreadyOrgMember
+	}
+	member.CreatedAt, member.UpdatedAt = time.Now(), time.Now()
+	s.members[key] = *member
+	return nil
+}
+
+func (s organizationStore) UpdateMemberRole(ctx context.Context, orgID, userID int, role string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := [2]int{orgID, userID}
+	member, ok := s.members[key]
+	if !ok {
+		return errors.ErrNotOrgMember
+	}
+	member.Role, member.UpdatedAt = role, time.Now()
+	s.members[key] = member
+	return nil
+}
+
+func (s organizationStore) RemoveMember(ctx context.Context, orgID, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := [2]int{orgID, userID}
+	if _, ok := s.members[key]; !ok {
+		return errors.ErrNotOrgMember
+	}
+	delete(s.members, key)
+	return nil
+}
+
+func (s organizationStore) CountOwners(ctx context.Context, orgID int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	owners := 0
+	for key, member := range s.members {
+		if key[0] == orgID && member.Role == entity.OrgRoleOwner {
+			owners++
+		}
+	}
+	return owners, nil
+}
+
+func (s organizationStore) CreateInvitation(ctx context.Context, invitation *entity.Invitation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	invitation.ID = s.nextID()
+	invitation.CreatedAt = time.Now()
+	s.invitations[invitation.ID] = *invitation
+	return nil
+}
+
+func (s organizationStore) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*entity.Invitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, invitation := range s.invitations {
+		if invitation.TokenHash == tokenHash {
+			return &invitation, nil
+		}
+	}
+	return nil, errors.ErrInvitationNotFound
+}
+
+func (s organizationStore) AcceptInvitation(ctx context.Context, invitation *entity.Invitation, member *entity.Membership) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.invitations[invitation.ID]
+	if !ok || stored.AcceptedAt != nil {
+		return errors.ErrInvitationNotFound
+	}
+	if err := s.addMember(member); err != nil {
+		return err
+	}
+	now := time.Now()
+	stored.AcceptedAt = &now
+	s.invitations[invitation.ID] = stored
+	invitation.AcceptedAt = &now
+	return nil
+}
+
+type ssoStore struct{ *memory }
+
+func (s ssoStore) UpsertConnection(ctx context.Context, conn *entity.SSOConnection) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	conn.ID, conn.CreatedAt = s.nextID(), now
+	for id, existing := range s.connections {
+		if existing.OrganizationID != conn.OrganizationID {
+			continue
+		}
+		conn.ID, conn.CreatedAt = id, existing.CreatedAt
+		if existing.Issuer != conn.Issuer {
+			for identityID, identity := range s.identities {
+				if identity.ConnectionID == id {
+					delete(s.identities, identityID)
+				}
+			}
+		}
+	}
+	conn.UpdatedAt = now
+	s.connections[conn.ID] = *conn
+	return nil
+}
+
+func (s ssoStore) GetConnection(ctx context.Context, id int) (*entity.SSOConnection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conn, ok := s.connections[id]
+	if !ok {
+		return nil, errors.ErrSSONotConfigured
+	}
+	return &conn, nil
+}
+
+func (s ssoStore) GetConnectionByOrganization(ctx context.Context, orgID int) (*entity.SSOConnection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.connections {
+		if conn.OrganizationID == orgID {
+			return &conn, nil
+		}
+	}
+	return nil, errors.ErrSSONotConfigured
+}
+
+func (s ssoStore) DeleteConnection(ctx context.Context, orgID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, conn := range s.connections {
+		if conn.OrganizationID != orgID {
+			continue
+		}
+		delete(s.connections, id)
+		for identityID, identity := range s.identities {
+			if identity.ConnectionID == id {
+				delete(s.identities, identityID)
+			}
+		}
+		return nil
+	}
+	return errors.ErrSSONotConfigured
+}
+
+func (s ssoStore) CreateLogin(ctx context.Context, login *entity.SSOLogin) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ssoLogins[login.StateHash] = *login
+	return nil
+}
+
+func (s ssoStore) ConsumeLogin(ctx context.Context, stateHash string) (*entity.SSOLogin, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	login, ok := s.ssoLogins[stateHash]
+	delete(s.ssoLogins, stateHash)
+	if !ok || !login.ExpiresAt.After(time.Now()) {
+		return nil, errors.ErrInvalidSSOState
+	}
+	return &login, nil
+}
+
+func (s ssoStore) GetIdentity(ctx context.Context, connectionID int, subject string) (*entity.UserIdentity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, identity := range s.identities {
+		if identity.ConnectionID == connectionID && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, errors.ErrIdentityNotFound
+}
+
+func (s ssoStore) CreateIdentity(ctx context.Context, identity *entity.UserIdentity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	identity.ID = s.nextID()
+	identity.CreatedAt = time.Now()
+	s.identities[identity.ID] = *identity
+	return nil
+}